@@ -0,0 +1,394 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [route...]",
+	Short: "Load test routes of a running server",
+	Long: `Send load to routes of a running dev or production server and report
+latency percentiles per route pattern.
+
+Without arguments every GET route and page in the app directory is
+benchmarked. Arguments select routes by pattern (/users/{id}) or by a
+concrete path (/users/42), optionally prefixed with a method ("POST /api/users").
+Path parameters in patterns are filled in with --param; routes whose
+parameters have no value are skipped. Each route is benchmarked on its
+own for --duration.
+
+Examples:
+  nexo bench
+  nexo bench /api/users /api/users/42
+  nexo bench "/users/{id}" --param id=42 -c 50 -t 30s
+  nexo bench --url https://staging.example.com --json`,
+	Run: runBench,
+}
+
+var (
+	benchURL         string
+	benchAppDir      string
+	benchConcurrency int
+	benchDuration    time.Duration
+	benchTimeout     time.Duration
+	benchParams      []string
+)
+
+func init() {
+	benchCmd.Flags().StringVarP(&benchURL, "url", "u", "http://localhost:3000", "Base URL of the running server")
+	benchCmd.Flags().StringVarP(&benchAppDir, "app-dir", "d", "app", "App directory to discover routes in")
+	benchCmd.Flags().IntVarP(&benchConcurrency, "concurrency", "c", 10, "Number of concurrent connections")
+	benchCmd.Flags().DurationVarP(&benchDuration, "duration", "t", 10*time.Second, "How long to benchmark each route")
+	benchCmd.Flags().DurationVar(&benchTimeout, "timeout", 10*time.Second, "Timeout for a single request")
+	benchCmd.Flags().StringArrayVarP(&benchParams, "param", "p", nil, "Value for a path parameter, as name=value (use *=value for catch-all segments)")
+
+	rootCmd.AddCommand(benchCmd)
+}
+
+// benchTarget is a request to send repeatedly, reported under Pattern.
+type benchTarget struct {
+	Method  string
+	Pattern string
+	Path    string
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	if benchConcurrency < 1 {
+		fail(fmt.Errorf("--concurrency must be at least 1"))
+	}
+
+	params := make(map[string]string)
+	for _, p := range benchParams {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || name == "" {
+			fail(fmt.Errorf("invalid --param %q (use name=value)", p))
+		}
+		params[name] = value
+	}
+
+	var candidates []nexo.RouteInfo
+	if _, err := os.Stat(benchAppDir); err == nil {
+		scanner := newScanner(benchAppDir)
+		routes, err := scanner.ScanRouteInfo()
+		if err != nil {
+			fail(fmt.Errorf("failed to scan routes: %w", err))
+		}
+		pages, err := scanner.ScanPageInfo()
+		if err != nil {
+			fail(fmt.Errorf("failed to scan pages: %w", err))
+		}
+		candidates = benchCandidates(routes, pages)
+	}
+
+	targets, skipped := benchTargets(candidates, args, params)
+	if len(targets) == 0 {
+		fail(fmt.Errorf("no routes to benchmark (pass a path like /api/health, or --param values for dynamic routes)"))
+	}
+
+	baseURL := strings.TrimSuffix(benchURL, "/")
+	client := &http.Client{
+		Timeout: benchTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        benchConcurrency,
+			MaxIdleConnsPerHost: benchConcurrency,
+		},
+	}
+	if resp, err := client.Get(baseURL + "/"); err != nil {
+		fail(fmt.Errorf("cannot reach %s (is the server running? start it with nexo dev): %w", baseURL, err))
+	} else {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s  %s  (%d connections, %s per route)\n\n", cyan("Nexo Bench"), baseURL, benchConcurrency, benchDuration)
+		for _, s := range skipped {
+			fmt.Printf("  %s skipping %s: no value for its parameters\n", yellow("Warning:"), s)
+		}
+		if len(skipped) > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("  %-7s %-32s %9s %10s %9s %9s %9s %9s %7s\n", "METHOD", "PATTERN", "REQUESTS", "REQ/S", "P50", "P90", "P99", "MAX", "ERRORS")
+	}
+
+	output := BenchOutput{
+		URL:         baseURL,
+		Concurrency: benchConcurrency,
+		Duration:    benchDuration.String(),
+		Routes:      make([]BenchRouteOutput, 0, len(targets)),
+		Skipped:     skipped,
+	}
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		result := benchRoute(ctx, client, baseURL, target, benchConcurrency, benchDuration)
+		output.Routes = append(output.Routes, result)
+		if !jsonOutput {
+			errs := fmt.Sprint(result.Errors)
+			if result.Errors > 0 {
+				errs = red(fmt.Sprintf("%7d", result.Errors))
+			}
+			fmt.Printf("  %-7s %-32s %9d %10.1f %9s %9s %9s %9s %7s\n",
+				result.Method, result.Pattern, result.Requests, result.RPS,
+				formatMillis(result.P50), formatMillis(result.P90), formatMillis(result.P99), formatMillis(result.Max), errs)
+		}
+	}
+
+	if jsonOutput {
+		printSuccess(output)
+		return
+	}
+	fmt.Println()
+}
+
+// benchCandidates returns the discovered routes and pages as the routes
+// nexo bench can select, pages as GET routes.
+func benchCandidates(routes []nexo.RouteInfo, pages []nexo.PageInfo) []nexo.RouteInfo {
+	candidates := append([]nexo.RouteInfo{}, routes...)
+	for _, p := range pages {
+		candidates = append(candidates, nexo.RouteInfo{Method: http.MethodGet, Pattern: p.Pattern, FilePath: p.FilePath})
+	}
+	// Static patterns first, so a concrete path is reported under the
+	// pattern the router would pick for it.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return patternWildcards(candidates[i].Pattern) < patternWildcards(candidates[j].Pattern)
+	})
+	return candidates
+}
+
+// benchTargets resolves the routes selected by args, or every GET route
+// when there are none, into requests. Routes with parameters missing from
+// params are returned in skipped.
+func benchTargets(candidates []nexo.RouteInfo, args []string, params map[string]string) (targets []benchTarget, skipped []string) {
+	if len(args) == 0 {
+		seen := make(map[string]bool)
+		for _, c := range candidates {
+			if c.Method != http.MethodGet || seen[c.Pattern] {
+				continue
+			}
+			seen[c.Pattern] = true
+			args = append(args, c.Pattern)
+		}
+	}
+
+	for _, arg := range args {
+		method, path := http.MethodGet, arg
+		if m, p, ok := strings.Cut(strings.TrimSpace(arg), " "); ok {
+			method, path = strings.ToUpper(m), strings.TrimSpace(p)
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		if patternWildcards(path) > 0 {
+			filled, ok := fillPattern(path, params)
+			if !ok {
+				skipped = append(skipped, method+" "+path)
+				continue
+			}
+			targets = append(targets, benchTarget{Method: method, Pattern: path, Path: filled})
+			continue
+		}
+
+		pattern := path
+		for _, c := range candidates {
+			if c.Method == method && patternMatchesPath(c.Pattern, path) {
+				pattern = c.Pattern
+				break
+			}
+		}
+		targets = append(targets, benchTarget{Method: method, Pattern: pattern, Path: path})
+	}
+	return targets, skipped
+}
+
+// patternWildcards counts the {param} and * segments of a route pattern.
+func patternWildcards(pattern string) int {
+	n := 0
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg == "*" || strings.HasPrefix(seg, "{") {
+			n++
+		}
+	}
+	return n
+}
+
+// fillPattern replaces the {param} and * segments of pattern with values
+// from params.
+func fillPattern(pattern string, params map[string]string) (string, bool) {
+	segs := strings.Split(pattern, "/")
+	for i, seg := range segs {
+		name := ""
+		switch {
+		case seg == "*":
+			name = "*"
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name, _, _ = strings.Cut(seg[1:len(seg)-1], ":")
+		default:
+			continue
+		}
+		value, ok := params[name]
+		if !ok {
+			return "", false
+		}
+		segs[i] = strings.Trim(value, "/")
+	}
+	return strings.Join(segs, "/"), true
+}
+
+// patternMatchesPath reports whether the route pattern matches path
+// exactly. {param} matches one segment and * the rest of the path.
+func patternMatchesPath(pattern, path string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range patternSegs {
+		if seg == "*" {
+			return true
+		}
+		if i >= len(pathSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") {
+			if pathSegs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return len(patternSegs) == len(pathSegs)
+}
+
+// benchRoute sends requests for target from concurrency workers until
+// duration passes or ctx is canceled, and summarizes their latencies.
+func benchRoute(ctx context.Context, client *http.Client, baseURL string, target benchTarget, concurrency int, duration time.Duration) BenchRouteOutput {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		failed    int
+		statuses  = make(map[int]int)
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				req, err := http.NewRequestWithContext(ctx, target.Method, baseURL+target.Path, nil)
+				if err != nil {
+					return
+				}
+				began := time.Now()
+				resp, err := client.Do(req)
+				status := 0
+				if err == nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+					status = resp.StatusCode
+				}
+				elapsed := time.Since(began)
+				if ctx.Err() != nil {
+					// Requests cut off at the end of the run aren't counted.
+					return
+				}
+
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if status != 0 {
+					statuses[status]++
+				}
+				if err != nil || status >= 400 {
+					failed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := BenchRouteOutput{
+		Method:   target.Method,
+		Pattern:  target.Pattern,
+		Path:     target.Path,
+		Requests: len(latencies),
+		Errors:   failed,
+		Statuses: statuses,
+	}
+	if len(latencies) == 0 {
+		return result
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	result.RPS = float64(len(latencies)) / elapsed.Seconds()
+	result.Mean = millis(total / time.Duration(len(latencies)))
+	result.P50 = millis(latencyPercentile(latencies, 50))
+	result.P90 = millis(latencyPercentile(latencies, 90))
+	result.P99 = millis(latencyPercentile(latencies, 99))
+	result.Max = millis(latencies[len(latencies)-1])
+	return result
+}
+
+// latencyPercentile returns the p-th percentile of sorted latencies using
+// the nearest-rank method.
+func latencyPercentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// millis converts d to fractional milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// formatMillis formats a latency in milliseconds for the bench table.
+func formatMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.2fms", ms)
+}
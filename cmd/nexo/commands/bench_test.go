@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestBenchTargets(t *testing.T) {
+	candidates := benchCandidates(
+		[]nexo.RouteInfo{
+			{Method: "GET", Pattern: "/api/users/{id}"},
+			{Method: "GET", Pattern: "/api/users/me"},
+			{Method: "POST", Pattern: "/api/users"},
+			{Method: "GET", Pattern: "/docs/*"},
+		},
+		[]nexo.PageInfo{{Pattern: "/about"}},
+	)
+
+	tests := []struct {
+		name        string
+		args        []string
+		params      map[string]string
+		wantTargets []benchTarget
+		wantSkipped []string
+	}{
+		{
+			name: "all GET routes without params",
+			wantTargets: []benchTarget{
+				{"GET", "/api/users/me", "/api/users/me"},
+				{"GET", "/about", "/about"},
+			},
+			wantSkipped: []string{"GET /api/users/{id}", "GET /docs/*"},
+		},
+		{
+			name:   "params fill patterns",
+			params: map[string]string{"id": "42", "*": "guides/intro"},
+			wantTargets: []benchTarget{
+				{"GET", "/api/users/me", "/api/users/me"},
+				{"GET", "/about", "/about"},
+				{"GET", "/api/users/{id}", "/api/users/42"},
+				{"GET", "/docs/*", "/docs/guides/intro"},
+			},
+		},
+		{
+			name: "concrete paths report their pattern",
+			args: []string{"/api/users/7", "/api/users/me", "post /api/users", "/unknown"},
+			wantTargets: []benchTarget{
+				{"GET", "/api/users/{id}", "/api/users/7"},
+				{"GET", "/api/users/me", "/api/users/me"},
+				{"POST", "/api/users", "/api/users"},
+				{"GET", "/unknown", "/unknown"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, skipped := benchTargets(candidates, tt.args, tt.params)
+			if !reflect.DeepEqual(targets, tt.wantTargets) {
+				t.Errorf("targets = %v, want %v", targets, tt.wantTargets)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestBenchRoute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	ok := benchRoute(context.Background(), srv.Client(), srv.URL, benchTarget{"GET", "/ok", "/ok"}, 4, 100*time.Millisecond)
+	if ok.Requests == 0 || ok.Errors != 0 || ok.Statuses[200] != ok.Requests {
+		t.Errorf("ok route = %+v", ok)
+	}
+	if ok.P50 <= 0 || ok.P50 > ok.P99 || ok.P99 > ok.Max || ok.RPS <= 0 {
+		t.Errorf("latencies out of order: %+v", ok)
+	}
+
+	missing := benchRoute(context.Background(), srv.Client(), srv.URL, benchTarget{"GET", "/missing", "/missing"}, 2, 50*time.Millisecond)
+	if missing.Requests == 0 || missing.Errors != missing.Requests {
+		t.Errorf("missing route = %+v, want every request counted as an error", missing)
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[int]time.Duration{50: 50 * time.Millisecond, 90: 90 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := latencyPercentile(latencies, p); got != want {
+			t.Errorf("latencyPercentile(%d) = %v, want %v", p, got, want)
+		}
+	}
+	if got := latencyPercentile(latencies[:1], 99); got != time.Millisecond {
+		t.Errorf("latencyPercentile of one = %v", got)
+	}
+}
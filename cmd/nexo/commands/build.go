@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,7 +11,6 @@ import (
 
 	"github.com/abdul-hamid-achik/nexo/pkg/generator"
 	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
-	"github.com/abdul-hamid-achik/nexo/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -22,61 +22,196 @@ var buildCmd = &cobra.Command{
 
 This command:
   1. Runs templ generate (if .templ files exist)
-  2. Builds an optimized Go binary with ldflags
+  2. Builds Tailwind CSS and the JavaScript bundle (if present)
+  3. Builds an optimized Go binary with ldflags
+
+With --target, one binary is built per os/arch pair, named
+<output>-<os>-<arch>. With --embed, static/ (including the asset
+manifest) and content/ are embedded in the binary with go:embed, so it
+runs without the project directory.
+
+With --lambda, a linux binary named bootstrap is built with everything
+embedded and zipped into a bundle for an AWS Lambda custom runtime
+(provided.al2023), served behind API Gateway or a load balancer.
 
 Examples:
   nexo build
   nexo build --output ./bin/myapp
   nexo build --os linux --arch amd64
+  nexo build --target linux/amd64,linux/arm64,darwin/arm64
+  nexo build --embed
+  nexo build --lambda --arch amd64
   nexo build --json`,
 	Run: runBuild,
 }
 
 var (
-	buildOutput string
-	buildOS     string
-	buildArch   string
+	buildOutput  string
+	buildOS      string
+	buildArch    string
+	buildTargets []string
+	buildEmbed   bool
+	buildLambda  bool
 )
 
 func init() {
 	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "Output binary path (default: ./bin/<project-name>)")
 	buildCmd.Flags().StringVar(&buildOS, "os", "", "Target OS (linux, darwin, windows)")
 	buildCmd.Flags().StringVar(&buildArch, "arch", "", "Target architecture (amd64, arm64)")
+	buildCmd.Flags().StringSliceVarP(&buildTargets, "target", "t", nil, "Build targets as os/arch, repeated or comma-separated (e.g. linux/amd64,darwin/arm64)")
+	buildCmd.Flags().BoolVar(&buildEmbed, "embed", false, "Embed static/ and content/ in the binary")
+	buildCmd.Flags().BoolVar(&buildLambda, "lambda", false, "Build an AWS Lambda bundle (default: bin/<project-name>-lambda.zip, arm64)")
+}
+
+// buildPlatform is a GOOS/GOARCH pair to build for.
+type buildPlatform struct {
+	OS   string
+	Arch string
+}
+
+// parseBuildTargets returns the platforms to build for: the os/arch pairs
+// in targets, or the --os/--arch platform (default: the host).
+func parseBuildTargets(targets []string, goos, goarch string) ([]buildPlatform, error) {
+	if len(targets) == 0 {
+		p := buildPlatform{OS: goos, Arch: goarch}
+		if p.OS == "" {
+			p.OS = runtime.GOOS
+		}
+		if p.Arch == "" {
+			p.Arch = runtime.GOARCH
+		}
+		return []buildPlatform{p}, nil
+	}
+
+	var platforms []buildPlatform
+	seen := make(map[buildPlatform]bool)
+	for _, t := range targets {
+		osName, arch, ok := strings.Cut(strings.TrimSpace(t), "/")
+		if !ok || osName == "" || arch == "" || strings.Contains(arch, "/") {
+			return nil, fmt.Errorf("invalid target %q: want os/arch, e.g. linux/amd64", t)
+		}
+		p := buildPlatform{OS: osName, Arch: arch}
+		if !seen[p] {
+			seen[p] = true
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms, nil
+}
+
+// platformBinaryPath returns the binary path for p. Matrix builds suffix
+// the base path with the platform; Windows binaries get .exe.
+func platformBinaryPath(base string, p buildPlatform, matrix bool) string {
+	path := strings.TrimSuffix(base, ".exe")
+	if matrix {
+		path += "-" + p.OS + "-" + p.Arch
+	} else {
+		path = base
+	}
+	if p.OS == "windows" && !strings.HasSuffix(path, ".exe") {
+		path += ".exe"
+	}
+	return path
+}
+
+// compileBinary runs go build for p, writing the binary to output, with
+// env added to the environment.
+func compileBinary(p buildPlatform, output string, env ...string) error {
+	goBuild := exec.Command("go", "build",
+		"-ldflags", "-s -w", // Strip debug info for smaller binary
+		"-o", output,
+		".",
+	)
+	goBuild.Env = append(append(os.Environ(), "GOOS="+p.OS, "GOARCH="+p.Arch), env...)
+	if !jsonOutput {
+		goBuild.Stdout = os.Stdout
+		goBuild.Stderr = os.Stderr
+	}
+	return goBuild.Run()
+}
+
+// writeEmbedFile writes the generated go:embed file for the project
+// directories that exist, returning them and their total size. It writes
+// nothing when none exist.
+func writeEmbedFile() ([]string, int64, error) {
+	dirs := generator.ExistingDirs(".", generator.DefaultEmbedDirs)
+	if len(dirs) == 0 {
+		return nil, 0, nil
+	}
+
+	content, err := generator.GenerateEmbedFile("main", dirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.WriteFile(generator.EmbedFileName, content, 0644); err != nil {
+		return nil, 0, err
+	}
+
+	var size int64
+	for _, dir := range dirs {
+		_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				size += info.Size()
+			}
+			return nil
+		})
+	}
+	return dirs, size, nil
 }
 
 func runBuild(cmd *cobra.Command, args []string) {
 	// Check for main.go
 	if _, err := os.Stat("main.go"); os.IsNotExist(err) {
 		if jsonOutput {
-			printJSONError(fmt.Errorf("no main.go found in current directory"))
+			printJSONError(noMainGoError())
 		} else {
 			red := color.New(color.FgRed).SprintFunc()
-			fmt.Printf("  %s No main.go found in current directory\n", red("Error:"))
+			fmt.Printf("  %s %v\n", red("Error:"), noMainGoError())
 		}
 		os.Exit(1)
 	}
 
 	// Determine output path
+	cwd, _ := os.Getwd()
+	projectName := filepath.Base(cwd)
 	outputPath := buildOutput
 	if outputPath == "" {
 		// Use current directory name as binary name
-		cwd, _ := os.Getwd()
-		projectName := filepath.Base(cwd)
 		outputPath = filepath.Join("bin", projectName)
 	}
 
-	// Add .exe extension on Windows
-	targetOS := buildOS
-	if targetOS == "" {
-		targetOS = runtime.GOOS
-	}
-	targetArch := buildArch
-	if targetArch == "" {
-		targetArch = runtime.GOARCH
+	// Lambda runs a static linux binary named bootstrap, without the
+	// project directory
+	var bundlePath string
+	var compileEnv []string
+	if buildLambda {
+		if len(buildTargets) > 0 || buildOS != "" && buildOS != "linux" {
+			err := fmt.Errorf("--lambda builds for linux; choose amd64 or arm64 with --arch")
+			if jsonOutput {
+				printJSONError(err)
+			} else {
+				red := color.New(color.FgRed).SprintFunc()
+				fmt.Printf("  %s %v\n", red("Error:"), err)
+			}
+			os.Exit(1)
+		}
+		outputPath, bundlePath = lambdaPaths(buildOutput, projectName)
+		buildOS, buildArch = "linux", cmp.Or(buildArch, "arm64")
+		buildEmbed = true
+		compileEnv = []string{"CGO_ENABLED=0"}
 	}
-	if targetOS == "windows" && !strings.HasSuffix(outputPath, ".exe") {
-		outputPath += ".exe"
+
+	platforms, err := parseBuildTargets(buildTargets, buildOS, buildArch)
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			red := color.New(color.FgRed).SprintFunc()
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		os.Exit(1)
 	}
+	matrix := len(platforms) > 1
 
 	if !jsonOutput {
 		cyan := color.New(color.FgCyan).SprintFunc()
@@ -134,13 +269,12 @@ func runBuild(cmd *cobra.Command, args []string) {
 	}
 
 	// Build Tailwind CSS if styles exist
-	if tools.HasStyles() {
+	if tailwind := loadTailwindProject(); tailwind.hasStyles() {
 		if !jsonOutput {
 			yellow := color.New(color.FgYellow).SprintFunc()
 			fmt.Printf("  %s Building Tailwind CSS...\n", yellow("→"))
 		}
-		tw := tools.NewTailwindCLI()
-		if err := tw.Build(tools.DefaultInputPath(), tools.DefaultOutputPath()); err != nil {
+		if err := tailwind.build(); err != nil {
 			if jsonOutput {
 				printJSONError(fmt.Errorf("tailwind build failed: %w", err))
 			} else {
@@ -155,6 +289,27 @@ func runBuild(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Bundle scripts if an entry point exists
+	if b := loadBundler(false); b.HasEntryPoints() {
+		if !jsonOutput {
+			yellow := color.New(color.FgYellow).SprintFunc()
+			fmt.Printf("  %s Bundling JavaScript...\n", yellow("→"))
+		}
+		if _, err := bundle(b); err != nil {
+			if jsonOutput {
+				printJSONError(fmt.Errorf("bundle failed: %w", err))
+			} else {
+				red := color.New(color.FgRed).SprintFunc()
+				fmt.Printf("  %s Bundle failed: %v\n", red("Error:"), err)
+			}
+			os.Exit(1)
+		}
+		if !jsonOutput {
+			green := color.New(color.FgGreen).SprintFunc()
+			fmt.Printf("  %s JavaScript bundled\n", green("✓"))
+		}
+	}
+
 	// Regenerate routes before building
 	// This ensures the generated routes file is up-to-date with the latest route structure
 	if _, err := os.Stat("app"); !os.IsNotExist(err) {
@@ -177,80 +332,138 @@ func runBuild(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// Build the binary
-	if !jsonOutput {
-		yellow := color.New(color.FgYellow).SprintFunc()
-		fmt.Printf("  %s Building binary...\n", yellow("→"))
-	}
-
-	buildArgs := []string{
-		"build",
-		"-ldflags", "-s -w", // Strip debug info for smaller binary
-		"-o", outputPath,
-		".",
+	// Embed project files for the duration of the build
+	var embedded []string
+	var embeddedSize int64
+	if buildEmbed {
+		embedded, embeddedSize, err = writeEmbedFile()
+		if err != nil {
+			if jsonOutput {
+				printJSONError(fmt.Errorf("failed to write %s: %w", generator.EmbedFileName, err))
+			} else {
+				red := color.New(color.FgRed).SprintFunc()
+				fmt.Printf("  %s Failed to write %s: %v\n", red("Error:"), generator.EmbedFileName, err)
+			}
+			os.Exit(1)
+		}
+		if !jsonOutput {
+			yellow := color.New(color.FgYellow).SprintFunc()
+			if len(embedded) == 0 {
+				fmt.Printf("  %s Nothing to embed: no static/ or content/ directory\n", yellow("Warning:"))
+			} else {
+				fmt.Printf("  %s Embedding %s (%.2f MB)\n", yellow("→"), strings.Join(embedded, ", "), float64(embeddedSize)/1024/1024)
+			}
+		}
 	}
 
-	buildEnv := os.Environ()
-	if buildOS != "" {
-		buildEnv = append(buildEnv, fmt.Sprintf("GOOS=%s", buildOS))
-	}
-	if buildArch != "" {
-		buildEnv = append(buildEnv, fmt.Sprintf("GOARCH=%s", buildArch))
-	}
+	// Build a binary per target
+	var built []BuildTarget
+	for _, p := range platforms {
+		binary := platformBinaryPath(outputPath, p, matrix)
+		if !jsonOutput {
+			yellow := color.New(color.FgYellow).SprintFunc()
+			if matrix {
+				fmt.Printf("  %s Building %s/%s...\n", yellow("→"), p.OS, p.Arch)
+			} else {
+				fmt.Printf("  %s Building binary...\n", yellow("→"))
+			}
+		}
 
-	goBuild := exec.Command("go", buildArgs...)
-	goBuild.Env = buildEnv
-	if !jsonOutput {
-		goBuild.Stdout = os.Stdout
-		goBuild.Stderr = os.Stderr
-	}
+		if err := compileBinary(p, binary, compileEnv...); err != nil {
+			if len(embedded) > 0 {
+				_ = os.Remove(generator.EmbedFileName)
+			}
+			if jsonOutput {
+				printJSONError(fmt.Errorf("build failed for %s/%s: %w", p.OS, p.Arch, err))
+			} else {
+				red := color.New(color.FgRed).SprintFunc()
+				fmt.Printf("  %s Build failed for %s/%s: %v\n", red("Error:"), p.OS, p.Arch, err)
+			}
+			os.Exit(1)
+		}
 
-	if err := goBuild.Run(); err != nil {
-		if jsonOutput {
-			printJSONError(fmt.Errorf("build failed: %w", err))
-		} else {
-			red := color.New(color.FgRed).SprintFunc()
-			fmt.Printf("  %s Build failed: %v\n", red("Error:"), err)
+		// Get binary size
+		var size int64
+		if info, err := os.Stat(binary); err == nil {
+			size = info.Size()
 		}
-		os.Exit(1)
+		absPath, _ := filepath.Abs(binary)
+		built = append(built, BuildTarget{Binary: absPath, OS: p.OS, Arch: p.Arch, Size: size})
+	}
+	if len(embedded) > 0 {
+		_ = os.Remove(generator.EmbedFileName)
 	}
 
-	// Get binary size
-	info, err := os.Stat(outputPath)
-	var size int64
-	if err == nil && info != nil {
-		size = info.Size()
+	var bundleSize int64
+	if bundlePath != "" {
+		bundleSize, err = writeLambdaBundle(outputPath, bundlePath)
+		if err != nil {
+			if jsonOutput {
+				printJSONError(fmt.Errorf("failed to write %s: %w", bundlePath, err))
+			} else {
+				red := color.New(color.FgRed).SprintFunc()
+				fmt.Printf("  %s Failed to write %s: %v\n", red("Error:"), bundlePath, err)
+			}
+			os.Exit(1)
+		}
 	}
 
 	// Output result
 	if jsonOutput {
-		absPath, _ := filepath.Abs(outputPath)
+		first := built[0]
+		bundle := bundlePath
+		if bundle != "" {
+			bundle, _ = filepath.Abs(bundle)
+		}
 		printSuccess(BuildOutput{
-			Binary:  absPath,
-			OS:      targetOS,
-			Arch:    targetArch,
-			Size:    size,
-			Success: true,
+			Binary:       first.Binary,
+			OS:           first.OS,
+			Arch:         first.Arch,
+			Size:         first.Size,
+			Success:      true,
+			Targets:      built,
+			Embedded:     embedded,
+			EmbeddedSize: embeddedSize,
+			Bundle:       bundle,
+			BundleSize:   bundleSize,
 		})
 	} else {
 		cyan := color.New(color.FgCyan).SprintFunc()
 		green := color.New(color.FgGreen).SprintFunc()
 
-		sizeStr := "unknown"
-		if size > 0 {
-			sizeMB := float64(size) / 1024 / 1024
-			sizeStr = fmt.Sprintf("%.2f MB", sizeMB)
+		sizeStr := func(size int64) string {
+			if size <= 0 {
+				return "unknown"
+			}
+			return fmt.Sprintf("%.2f MB", float64(size)/1024/1024)
 		}
 
 		fmt.Printf("  %s Build successful\n\n", green("✓"))
-		fmt.Printf("  Output: %s\n", cyan(outputPath))
-		fmt.Printf("  Size:   %s\n", sizeStr)
+		if matrix {
+			for _, t := range built {
+				rel, _ := filepath.Rel(".", t.Binary)
+				fmt.Printf("  %-14s %s  %s\n", t.OS+"/"+t.Arch, cyan(rel), sizeStr(t.Size))
+			}
+			fmt.Println()
+			return
+		}
+
+		binary := platformBinaryPath(outputPath, platforms[0], false)
+		fmt.Printf("  Output: %s\n", cyan(binary))
+		fmt.Printf("  Size:   %s\n", sizeStr(built[0].Size))
+
+		if bundlePath != "" {
+			fmt.Printf("  Bundle: %s (%s)\n", cyan(bundlePath), sizeStr(bundleSize))
+			fmt.Printf("  Target: %s/%s\n", built[0].OS, built[0].Arch)
+			fmt.Printf("\n  Deploy with: %s\n\n", cyan("aws lambda update-function-code --function-name "+projectName+" --zip-file fileb://"+bundlePath))
+			return
+		}
 
-		if buildOS != "" || buildArch != "" {
-			fmt.Printf("  Target: %s/%s\n", targetOS, targetArch)
+		if buildOS != "" || buildArch != "" || len(buildTargets) > 0 {
+			fmt.Printf("  Target: %s/%s\n", built[0].OS, built[0].Arch)
 		}
 
-		fmt.Printf("\n  Run with: %s\n\n", cyan("./"+outputPath))
+		fmt.Printf("\n  Run with: %s\n\n", cyan("./"+binary))
 	}
 }
 
@@ -274,17 +487,22 @@ func generateRoutesForBuild(appDir string) error {
 		if err != nil {
 			return fmt.Errorf("failed to get module name: %w", err)
 		}
-		gen := scanner.NewGenerator(scanner.GeneratorConfig{
+		gen, err := newGenerator(scanner.GeneratorConfig{
 			ModuleName: moduleName,
 			AppDir:     appDir,
 			OutputDir:  ".nexo/generated",
 		})
+		if err != nil {
+			return err
+		}
 		if _, err := gen.Generate(); err != nil {
 			return fmt.Errorf("next.js-style route generation failed: %w", err)
 		}
 	}
 
 	// Always run legacy generator for backward compatibility
-	_, err := generator.ScanAndGenerateRoutes(appDir, "nexo_routes.go")
-	return err
+	if _, err := generator.ScanAndGenerateRoutes(appDir, "nexo_routes.go"); err != nil {
+		return err
+	}
+	return writeRoutesManifest(appDir)
 }
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lambdaBinary is the executable name of a Lambda custom runtime.
+const lambdaBinary = "bootstrap"
+
+// lambdaPaths returns where nexo build --lambda writes the bootstrap
+// binary and the bundle for the output path, or for the project when
+// output is empty.
+func lambdaPaths(output, project string) (binary, bundle string) {
+	bundle = output
+	if bundle == "" {
+		bundle = filepath.Join("bin", project+"-lambda.zip")
+	}
+	if !strings.HasSuffix(bundle, ".zip") {
+		bundle += ".zip"
+	}
+	return filepath.Join(filepath.Dir(bundle), lambdaBinary), bundle
+}
+
+// writeLambdaBundle zips binary as an executable bootstrap at the root of
+// bundle, the layout of a provided.al2023 function, returning its size.
+func writeLambdaBundle(binary, bundle string) (int64, error) {
+	in, err := os.Open(binary)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(bundle)
+	if err != nil {
+		return 0, err
+	}
+	zw := zip.NewWriter(out)
+	header := &zip.FileHeader{Name: lambdaBinary, Method: zip.Deflate}
+	header.SetMode(0755)
+	if info, err := in.Stat(); err == nil {
+		header.Modified = info.ModTime()
+	}
+	w, err := zw.CreateHeader(header)
+	if err == nil {
+		_, err = io.Copy(w, in)
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(bundle)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
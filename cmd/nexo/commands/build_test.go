@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseBuildTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []string
+		goos    string
+		goarch  string
+		want    []buildPlatform
+		wantErr bool
+	}{
+		{
+			name: "host",
+			want: []buildPlatform{{OS: runtime.GOOS, Arch: runtime.GOARCH}},
+		},
+		{
+			name:   "os and arch flags",
+			goos:   "linux",
+			goarch: "arm64",
+			want:   []buildPlatform{{OS: "linux", Arch: "arm64"}},
+		},
+		{
+			name:    "matrix",
+			targets: []string{"linux/amd64", " darwin/arm64", "linux/amd64", "windows/amd64"},
+			goos:    "ignored",
+			want: []buildPlatform{
+				{OS: "linux", Arch: "amd64"},
+				{OS: "darwin", Arch: "arm64"},
+				{OS: "windows", Arch: "amd64"},
+			},
+		},
+		{name: "missing arch", targets: []string{"linux"}, wantErr: true},
+		{name: "empty os", targets: []string{"/amd64"}, wantErr: true},
+		{name: "extra segment", targets: []string{"linux/arm/v7"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBuildTargets(tt.targets, tt.goos, tt.goarch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBuildTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBuildTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformBinaryPath(t *testing.T) {
+	tests := []struct {
+		base   string
+		p      buildPlatform
+		matrix bool
+		want   string
+	}{
+		{"bin/app", buildPlatform{"linux", "amd64"}, false, "bin/app"},
+		{"bin/app", buildPlatform{"windows", "amd64"}, false, "bin/app.exe"},
+		{"bin/app.exe", buildPlatform{"windows", "amd64"}, false, "bin/app.exe"},
+		{"bin/app", buildPlatform{"darwin", "arm64"}, true, "bin/app-darwin-arm64"},
+		{"bin/app", buildPlatform{"windows", "arm64"}, true, "bin/app-windows-arm64.exe"},
+		{"bin/app.exe", buildPlatform{"linux", "amd64"}, true, "bin/app-linux-amd64"},
+	}
+
+	for _, tt := range tests {
+		if got := platformBinaryPath(tt.base, tt.p, tt.matrix); got != tt.want {
+			t.Errorf("platformBinaryPath(%q, %v, %v) = %q, want %q", tt.base, tt.p, tt.matrix, got, tt.want)
+		}
+	}
+}
+
+func TestLambdaBundle(t *testing.T) {
+	binary, bundle := lambdaPaths("", "myapp")
+	if binary != filepath.Join("bin", "bootstrap") || bundle != filepath.Join("bin", "myapp-lambda.zip") {
+		t.Errorf("lambdaPaths = %s, %s", binary, bundle)
+	}
+	if _, bundle := lambdaPaths("dist/fn", "myapp"); bundle != "dist/fn.zip" {
+		t.Errorf("bundle = %s, want dist/fn.zip", bundle)
+	}
+
+	dir := t.TempDir()
+	binary, bundle = filepath.Join(dir, "bootstrap"), filepath.Join(dir, "fn.zip")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	size, err := writeLambdaBundle(binary, bundle)
+	if err != nil || size == 0 {
+		t.Fatalf("writeLambdaBundle = %d, %v", size, err)
+	}
+
+	zr, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = zr.Close() }()
+	if len(zr.File) != 1 || zr.File[0].Name != "bootstrap" || zr.File[0].Mode()&0111 == 0 {
+		t.Errorf("bundle has %v, want an executable bootstrap", zr.File)
+	}
+}
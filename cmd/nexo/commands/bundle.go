@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Bundle JavaScript and TypeScript with esbuild",
+	Long: `Bundle the app's scripts, starting at assets/js/entry.ts, into static/js.
+
+Production bundles are minified and fingerprinted (entry-5FQXK2VN.js), and
+static/manifest.json maps their stable names to the fingerprinted URLs for
+nexo.Asset. With --dev, bundles keep their names and get source maps.
+
+nexo dev and nexo build run the bundler automatically when an entry point
+exists. Configure it under js: in nexo.yaml:
+
+  js:
+    entry: [assets/js/entry.ts, assets/js/admin.ts]
+    target: es2020
+    external: [htmx.org]
+
+esbuild is built in. To run an installed esbuild instead, set js.esbuild
+to its path.
+
+Examples:
+  nexo bundle
+  nexo bundle --dev --watch`,
+	Run: runBundle,
+}
+
+var (
+	bundleDev   bool
+	bundleWatch bool
+)
+
+func init() {
+	bundleCmd.Flags().BoolVar(&bundleDev, "dev", false, "Development build: source maps, no minification or fingerprints")
+	bundleCmd.Flags().BoolVarP(&bundleWatch, "watch", "w", false, "Rebuild on changes (implies --dev)")
+
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func runBundle(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s bundle\n\n", cyan("Nexo"))
+	}
+
+	b := loadBundler(bundleDev || bundleWatch)
+	if !b.HasEntryPoints() {
+		fail(fmt.Errorf("no entry point found: create %s or set js.entry in nexo.yaml", b.Options().EntryPoints[0]))
+	}
+
+	if bundleWatch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := b.Watch(ctx); err != nil {
+			fail(err)
+		}
+		fmt.Printf("  %s Watching %v\n\n", green("✓"), b.Options().EntryPoints)
+		<-ctx.Done()
+		return
+	}
+
+	result, err := bundle(b)
+	if err != nil {
+		fail(err)
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+	for _, a := range result.Assets {
+		fmt.Printf("  %s %s → %s\n", green("✓"), a.Name, a.URL)
+	}
+	fmt.Printf("\n  Manifest: %s\n\n", cyan(result.Manifest))
+}
+
+// loadBundler creates a bundler from the js: section of nexo.yaml.
+func loadBundler(dev bool) *bundler.Bundler {
+	cfg, err := nexo.LoadConfig("")
+	if err != nil {
+		cfg = nexo.DefaultConfig()
+	}
+	js := cfg.JS
+
+	var engine bundler.Engine
+	if js.ESBuild != "" {
+		engine = &bundler.ESBuild{Binary: js.ESBuild}
+	}
+	return bundler.New(bundler.Options{
+		EntryPoints: js.Entry,
+		OutDir:      js.OutDir,
+		StaticDir:   cfg.StaticDir,
+		StaticURL:   cfg.StaticURL,
+		Manifest:    js.Manifest,
+		Dev:         dev,
+		Target:      js.Target,
+		External:    js.External,
+		Engine:      engine,
+	})
+}
+
+// bundle builds b and describes the assets in its manifest.
+func bundle(b *bundler.Bundler) (*BundleOutput, error) {
+	m, err := b.Build(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BundleOutput{Manifest: b.Options().Manifest, Dev: b.Options().Dev}
+	for name, url := range m.Assets {
+		result.Assets = append(result.Assets, BundleAsset{Name: name, URL: url})
+	}
+	sort.Slice(result.Assets, func(i, j int) bool { return result.Assets[i].Name < result.Assets[j].Name })
+	return result, nil
+}
@@ -2,13 +2,16 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+	"github.com/abdul-hamid-achik/nexo/pkg/deploy"
+	"github.com/abdul-hamid-achik/nexo/pkg/env"
 	"github.com/charmbracelet/huh"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -21,26 +24,36 @@ var (
 	deployApp       string
 	deployEnvFile   string
 	deployNoEnvFile bool
+	deployProvider  string
 )
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
-	Short: "Build and deploy to Nexo Cloud",
-	Long: `Build and deploy the current project to Nexo Cloud.
+	Short: "Build and deploy to Nexo Cloud or Fly.io",
+	Long: `Build and deploy the current project to Nexo Cloud or Fly.io.
+
+The provider is chosen with --provider or deploy.provider in nexo.yaml
+(default: nexo). Fly.io needs an API token in FLY_API_TOKEN:
+
+  deploy:
+    provider: fly
+    fly:
+      org: my-org
 
 This command will:
-1. Read nexo.yaml for app configuration
-2. Build the Go binary (unless --no-build)
-3. Build a Docker image
-4. Push the image to GHCR
-5. Trigger deployment on Nexo Cloud
-6. Stream deployment logs
+1. Read nexo.yaml for app configuration, creating the app if needed
+2. Build a Docker image (unless --no-build), using the project's
+   Dockerfile or the one generated by nexo docker init
+3. Push the image to the provider's registry
+4. Trigger the deployment
+5. Stream deployment logs
 
 Examples:
   nexo deploy                    # Deploy current directory
   nexo deploy --no-build         # Skip build, use existing image
   nexo deploy --env KEY=value    # Set env var for this deployment
   nexo deploy --app my-app       # Deploy to specific app
+  nexo deploy --provider fly     # Deploy to Fly.io
   nexo deploy --env-file .env    # Load env vars from file
   nexo deploy --no-env-file      # Skip auto-loading .env file`,
 	Run: runDeploy,
@@ -52,6 +65,7 @@ func init() {
 	deployCmd.Flags().StringVar(&deployApp, "app", "", "App name (defaults to name in nexo.yaml)")
 	deployCmd.Flags().StringVar(&deployEnvFile, "env-file", "", "Load environment variables from file (default: .env if exists)")
 	deployCmd.Flags().BoolVar(&deployNoEnvFile, "no-env-file", false, "Skip auto-loading .env file")
+	deployCmd.Flags().StringVar(&deployProvider, "provider", "", "Deploy provider: nexo or fly (default: deploy.provider in nexo.yaml, or nexo)")
 
 	rootCmd.AddCommand(deployCmd)
 }
@@ -66,17 +80,6 @@ func runDeploy(cmd *cobra.Command, args []string) {
 		fmt.Printf("\n  %s Deploy\n\n", cyan("Nexo"))
 	}
 
-	// Load credentials
-	client, err := cloud.NewClientFromCredentials()
-	if err != nil {
-		if jsonOutput {
-			printJSONError(err)
-		} else {
-			fmt.Printf("  %s %v\n", red("Error:"), err)
-		}
-		os.Exit(1)
-	}
-
 	// Load nexo.yaml config
 	v := viper.New()
 	v.SetConfigName("nexo")
@@ -84,6 +87,7 @@ func runDeploy(cmd *cobra.Command, args []string) {
 	v.AddConfigPath(".")
 
 	appName := deployApp
+	providerName := deployProvider
 	region := "gdl"
 	size := "starter"
 
@@ -123,6 +127,19 @@ func runDeploy(cmd *cobra.Command, args []string) {
 		if cloudSize := v.GetString("cloud.size"); cloudSize != "" {
 			size = cloudSize
 		}
+		if providerName == "" {
+			providerName = v.GetString("deploy.provider")
+		}
+	}
+
+	provider, err := deploy.New(providerName, deployProviderOptions(v, region, size))
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		os.Exit(1)
 	}
 
 	if appName == "" {
@@ -136,6 +153,7 @@ func runDeploy(cmd *cobra.Command, args []string) {
 	}
 
 	if !jsonOutput {
+		fmt.Printf("  %s Provider: %s\n", dim("->"), provider.Name())
 		fmt.Printf("  %s App: %s\n", dim("->"), cyan(appName))
 		fmt.Printf("  %s Region: %s\n", dim("->"), region)
 		fmt.Printf("  %s Size: %s\n\n", dim("->"), size)
@@ -143,14 +161,14 @@ func runDeploy(cmd *cobra.Command, args []string) {
 
 	// Check if app exists, create if not
 	ctx := context.Background()
-	_, err = client.GetApp(ctx, appName)
+	_, err = provider.GetApp(ctx, appName)
 	if err != nil {
-		if apiErr, ok := err.(*cloud.APIError); ok && apiErr.IsNotFound() {
+		if errors.Is(err, deploy.ErrAppNotFound) {
 			if !jsonOutput {
 				fmt.Printf("  %s App '%s' not found. Creating...\n", yellow("!"), appName)
 			}
 
-			_, err = client.CreateApp(ctx, appName, region, size)
+			_, err = provider.CreateApp(ctx, deploy.AppSpec{Name: appName, Region: region, Size: size})
 			if err != nil {
 				if jsonOutput {
 					printJSONError(fmt.Errorf("failed to create app: %w", err))
@@ -221,7 +239,7 @@ func runDeploy(cmd *cobra.Command, args []string) {
 			fmt.Printf("  %s Setting %d environment variable(s)...\n", yellow("->"), len(envMap))
 		}
 
-		if err := client.SetEnv(ctx, appName, envMap); err != nil {
+		if err := provider.SetEnv(ctx, appName, envMap); err != nil {
 			if jsonOutput {
 				printJSONError(fmt.Errorf("failed to set env vars: %w", err))
 			} else {
@@ -237,116 +255,33 @@ func runDeploy(cmd *cobra.Command, args []string) {
 
 	var imageName string
 
-	if !deployNoBuild {
-		// Step 1: Build Go binary
-		if !jsonOutput {
-			fmt.Printf("  %s Building application...\n", yellow("->"))
-		}
-
-		buildCmd := exec.Command("go", "build", "-o", "app", ".")
-		buildCmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64")
-		buildCmd.Stdout = os.Stdout
-		buildCmd.Stderr = os.Stderr
+	var build *DockerBuildOutput
 
-		if err := buildCmd.Run(); err != nil {
+	if !deployNoBuild {
+		// Generate image tag
+		timestamp := time.Now().Format("20060102150405")
+		imageName, err = provider.ImageRef(ctx, appName, timestamp)
+		if err != nil {
 			if jsonOutput {
-				printJSONError(fmt.Errorf("build failed: %w", err))
+				printJSONError(err)
 			} else {
-				fmt.Printf("  %s Build failed: %v\n", red("Error:"), err)
+				fmt.Printf("  %s %v\n", red("Error:"), err)
 			}
 			os.Exit(1)
 		}
 
-		if !jsonOutput {
-			fmt.Printf("  %s Build complete\n\n", green("OK"))
-		}
-
-		// Step 2: Build Docker image
+		// Step 1: Build the image. The project's Dockerfile is used when
+		// present, otherwise the one nexo docker init generates.
 		if !jsonOutput {
 			fmt.Printf("  %s Building Docker image...\n", yellow("->"))
 		}
 
-		// Check if Docker is available
-		if _, err := exec.LookPath("docker"); err != nil {
-			if jsonOutput {
-				printJSONError(fmt.Errorf("docker not found, please install Docker to deploy"))
-			} else {
-				fmt.Printf("  %s Docker not found. Please install Docker to deploy.\n", red("Error:"))
-			}
-			os.Exit(1)
-		}
-
-		// Create Dockerfile if it doesn't exist
-		dockerfilePath := "Dockerfile"
-		createdDockerfile := false
-		if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
-			// Build a Dockerfile that copies all necessary files
-			var dockerfileBuilder strings.Builder
-			dockerfileBuilder.WriteString(`FROM alpine:latest
-RUN apk --no-cache add ca-certificates tzdata
-WORKDIR /app
-
-# Copy the binary
-COPY app .
-`)
-			// Check for static directory
-			if _, err := os.Stat("static"); err == nil {
-				dockerfileBuilder.WriteString("\n# Copy static files\nCOPY static ./static\n")
-			}
-
-			// Check for styles directory
-			if _, err := os.Stat("styles"); err == nil {
-				dockerfileBuilder.WriteString("\n# Copy styles\nCOPY styles ./styles\n")
-			}
-
-			// Check for templates directory
-			if _, err := os.Stat("templates"); err == nil {
-				dockerfileBuilder.WriteString("\n# Copy templates\nCOPY templates ./templates\n")
-			}
-
-			dockerfileBuilder.WriteString(`
-# Set default port
-ENV PORT=3000
-EXPOSE 3000
-
-CMD ["./app"]
-`)
-			if err := os.WriteFile(dockerfilePath, []byte(dockerfileBuilder.String()), 0644); err != nil {
-				if jsonOutput {
-					printJSONError(fmt.Errorf("failed to create Dockerfile: %w", err))
-				} else {
-					fmt.Printf("  %s Failed to create Dockerfile: %v\n", red("Error:"), err)
-				}
-				os.Exit(1)
-			}
-			createdDockerfile = true
-		}
-
-		// Clean up generated Dockerfile after build
-		if createdDockerfile {
-			defer func() { _ = os.Remove(dockerfilePath) }()
-		}
-
-		// Get username from credentials
-		creds, _ := cloud.LoadCredentials()
-		username := "user"
-		if creds != nil && creds.User != nil {
-			username = creds.User.Username
-		}
-
-		// Generate image tag
-		timestamp := time.Now().Format("20060102150405")
-		imageName = fmt.Sprintf("ghcr.io/%s/%s:%s", username, appName, timestamp)
-
-		dockerBuildCmd := exec.Command("docker", "build", "-t", imageName, ".")
-		dockerBuildCmd.Stdout = os.Stdout
-		dockerBuildCmd.Stderr = os.Stderr
-
-		if err := dockerBuildCmd.Run(); err != nil {
+		build, err = buildDockerImage(imageName, "linux/amd64")
+		if err != nil {
 			if jsonOutput {
-				printJSONError(fmt.Errorf("docker build failed: %w", err))
+				printJSONError(err)
 			} else {
-				fmt.Printf("  %s Docker build failed: %v\n", red("Error:"), err)
+				fmt.Printf("  %s %v\n", red("Error:"), err)
 			}
 			os.Exit(1)
 		}
@@ -355,45 +290,36 @@ CMD ["./app"]
 			fmt.Printf("  %s Docker image built: %s\n\n", green("OK"), dim(imageName))
 		}
 
-		// Step 3: Push to GHCR
+		// Step 2: Push to the provider's registry
 		if !jsonOutput {
 			fmt.Printf("  %s Pushing image to registry...\n", yellow("->"))
 		}
 
-		dockerPushCmd := exec.Command("docker", "push", imageName)
-		dockerPushCmd.Stdout = os.Stdout
-		dockerPushCmd.Stderr = os.Stderr
-
-		if err := dockerPushCmd.Run(); err != nil {
+		if err := registryLogin(ctx, provider); err != nil {
 			if jsonOutput {
-				printJSONError(fmt.Errorf("docker push failed: %w", err))
+				printJSONError(err)
 			} else {
-				fmt.Printf("  %s Docker push failed: %v\n", red("Error:"), err)
-				fmt.Println("  Make sure you're logged in to GHCR: docker login ghcr.io")
+				fmt.Printf("  %s %v\n", red("Error:"), err)
 			}
 			os.Exit(1)
 		}
-
-		if !jsonOutput {
-			fmt.Printf("  %s Image pushed\n\n", green("OK"))
-		}
-
-		// Clean up binary
-		_ = os.Remove("app")
-	} else {
-		// Use existing image - get latest from app info
-		app, err := client.GetApp(ctx, appName)
-		if err != nil {
+		if err := pushDockerImage(imageName); err != nil {
 			if jsonOutput {
-				printJSONError(fmt.Errorf("failed to get app: %w", err))
+				printJSONError(err)
 			} else {
-				fmt.Printf("  %s Failed to get app: %v\n", red("Error:"), err)
+				fmt.Printf("  %s %v\n", red("Error:"), err)
+				fmt.Printf("  Make sure you're logged in to the registry: docker login %s\n", registryHost(imageName))
 			}
 			os.Exit(1)
 		}
+		build.Pushed = true
 
-		// Get the latest deployment to find the image
-		deployments, err := client.ListDeployments(ctx, appName)
+		if !jsonOutput {
+			fmt.Printf("  %s Image pushed\n\n", green("OK"))
+		}
+	} else {
+		// Use existing image - get the latest deployment to find the image
+		deployments, err := provider.Deployments(ctx, appName)
 		if err != nil || len(deployments) == 0 {
 			if jsonOutput {
 				printJSONError(fmt.Errorf("no previous deployments found for --no-build"))
@@ -405,13 +331,7 @@ CMD ["./app"]
 
 		imageName = deployments[0].Image
 		if imageName == "" {
-			// Construct from app info
-			creds, _ := cloud.LoadCredentials()
-			username := "user"
-			if creds != nil && creds.User != nil {
-				username = creds.User.Username
-			}
-			imageName = fmt.Sprintf("ghcr.io/%s/%s:latest", username, app.Name)
+			imageName, _ = provider.ImageRef(ctx, appName, "latest")
 		}
 
 		if !jsonOutput {
@@ -419,12 +339,12 @@ CMD ["./app"]
 		}
 	}
 
-	// Step 4: Trigger deployment
+	// Step 3: Trigger deployment
 	if !jsonOutput {
-		fmt.Printf("  %s Deploying to Nexo Cloud...\n", yellow("->"))
+		fmt.Printf("  %s Deploying to %s...\n", yellow("->"), provider.Name())
 	}
 
-	deployment, err := client.Deploy(ctx, appName, imageName)
+	deployment, err := provider.Deploy(ctx, appName, imageName)
 	if err != nil {
 		if jsonOutput {
 			printJSONError(fmt.Errorf("deployment failed: %w", err))
@@ -438,7 +358,7 @@ CMD ["./app"]
 		fmt.Printf("  %s Deployment %s started\n\n", green("OK"), dim(deployment.ID))
 	}
 
-	// Step 5: Stream deployment logs
+	// Step 4: Stream deployment logs
 	if !jsonOutput {
 		fmt.Printf("  %s Streaming deployment logs...\n\n", yellow("->"))
 	}
@@ -446,9 +366,14 @@ CMD ["./app"]
 	streamCtx, streamCancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer streamCancel()
 
-	logCh, errCh, err := client.StreamLogs(streamCtx, appName, cloud.LogOptions{
-		Tail: 50,
-	})
+	var logCh <-chan deploy.LogLine
+	var errCh <-chan error
+	err = errors.ErrUnsupported
+	if streamer, ok := provider.(deploy.LogStreamer); ok {
+		logCh, errCh, err = streamer.StreamLogs(streamCtx, appName, deploy.LogOptions{
+			Tail: 50,
+		})
+	}
 
 	if err != nil {
 		// Fall back to polling if streaming not supported
@@ -460,7 +385,7 @@ CMD ["./app"]
 		for i := 0; i < 60; i++ {
 			time.Sleep(5 * time.Second)
 
-			dep, err := client.GetDeployment(ctx, appName, deployment.ID)
+			dep, err := provider.Deployment(ctx, appName, deployment.ID)
 			if err != nil {
 				continue
 			}
@@ -516,14 +441,14 @@ CMD ["./app"]
 		}
 
 		// Get final deployment status
-		dep, _ := client.GetDeployment(ctx, appName, deployment.ID)
+		dep, _ := provider.Deployment(ctx, appName, deployment.ID)
 		if dep != nil {
 			deployment = dep
 		}
 	}
 
 	// Get app URL
-	app, _ := client.GetApp(ctx, appName)
+	app, _ := provider.GetApp(ctx, appName)
 	appURL := ""
 	if app != nil {
 		appURL = app.URL
@@ -538,6 +463,7 @@ CMD ["./app"]
 			URL:          appURL,
 			Image:        imageName,
 			Message:      "Deployment successful",
+			Build:        build,
 			Deployment: &DeploymentOutput{
 				ID:        deployment.ID,
 				Version:   deployment.Version,
@@ -555,54 +481,91 @@ CMD ["./app"]
 	}
 }
 
-// loadEnvFile reads a .env file and returns a map of key-value pairs.
-// It supports:
-// - KEY=value
-// - KEY="quoted value"
-// - KEY='single quoted'
-// - # comments
-// - Empty lines
-func loadEnvFile(path string) (map[string]string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+// loadDeployProvider creates the provider called name, or the one set by
+// deploy.provider in nexo.yaml.
+func loadDeployProvider(name string) (deploy.Provider, error) {
+	v := viper.New()
+	v.SetConfigName("nexo")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	_ = v.ReadInConfig()
 
-	envMap := make(map[string]string)
-	lines := strings.Split(string(data), "\n")
+	if name == "" {
+		name = v.GetString("deploy.provider")
+	}
+	region := v.GetString("cloud.region")
+	if region == "" {
+		region = "gdl"
+	}
+	size := v.GetString("cloud.size")
+	if size == "" {
+		size = "starter"
+	}
+	return deploy.New(name, deployProviderOptions(v, region, size))
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+// deployProviderOptions configures the providers from nexo.yaml and the
+// environment.
+func deployProviderOptions(v *viper.Viper, region, size string) deploy.Options {
+	fly := deploy.FlyOptions{
+		Token:  os.Getenv("FLY_API_TOKEN"),
+		Org:    v.GetString("deploy.fly.org"),
+		Region: v.GetString("deploy.fly.region"),
+		Size:   size,
+		Port:   v.GetInt("port"),
+	}
+	if fly.Token == "" {
+		fly.Token = os.Getenv("FLY_ACCESS_TOKEN")
+	}
+	if fly.Region == "" && v.IsSet("cloud.region") {
+		fly.Region = region
+	}
+	return deploy.Options{Fly: fly}
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// registryLogin logs docker in to the provider's registry when it hands out
+// credentials.
+func registryLogin(ctx context.Context, provider deploy.Provider) error {
+	auth, ok := provider.(deploy.RegistryAuthenticator)
+	if !ok {
+		return nil
+	}
+	creds, err := auth.RegistryAuth(ctx)
+	if err != nil {
+		return err
+	}
 
-		// Split on first =
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
+	cmd := exec.CommandContext(ctx, "docker", "login", creds.Server, "--username", creds.Username, "--password-stdin")
+	cmd.Stdin = strings.NewReader(creds.Password)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker login %s failed: %w", creds.Server, err)
+	}
+	return nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// registryHost returns the registry of an image reference.
+func registryHost(image string) string {
+	host, _, _ := strings.Cut(image, "/")
+	return host
+}
 
-		// Remove quotes if present
-		if len(value) >= 2 {
-			if (value[0] == '"' && value[len(value)-1] == '"') ||
-				(value[0] == '\'' && value[len(value)-1] == '\'') {
-				value = value[1 : len(value)-1]
-			}
+// loadEnvFile reads a .env file and returns a map of key-value pairs.
+// Encrypted values are decrypted with the key in NEXO_ENV_KEY or the
+// .env.key file next to it (see nexo env encrypt).
+func loadEnvFile(path string) (map[string]string, error) {
+	vars, err := env.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if env.HasEncrypted(vars) {
+		key, err := env.LoadKey(filepath.Dir(path))
+		if err != nil {
+			return nil, err
 		}
-
-		// Skip empty keys
-		if key == "" {
-			continue
+		if err := env.DecryptValues(key, vars); err != nil {
+			return nil, err
 		}
-
-		envMap[key] = value
 	}
-
-	return envMap, nil
+	return vars, nil
 }
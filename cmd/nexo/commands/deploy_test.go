@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestLoadEnvFile(t *testing.T) {
@@ -220,6 +222,11 @@ func TestDeployCmd_HasFlags(t *testing.T) {
 	if noEnvFileFlag == nil {
 		t.Error("Expected --no-env-file flag")
 	}
+
+	providerFlag := flags.Lookup("provider")
+	if providerFlag == nil {
+		t.Error("Expected --provider flag")
+	}
 }
 
 func TestDeployFlags_DefaultValues(t *testing.T) {
@@ -246,3 +253,37 @@ func TestDeployFlags_DefaultValues(t *testing.T) {
 		t.Error("deployNoEnvFile should default to false")
 	}
 }
+
+func TestDeployProviderOptions(t *testing.T) {
+	t.Setenv("FLY_API_TOKEN", "")
+	t.Setenv("FLY_ACCESS_TOKEN", "fly-token")
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("deploy.fly.org", "acme")
+	v.Set("cloud.region", "gdl")
+
+	opts := deployProviderOptions(v, "gdl", "pro").Fly
+	if opts.Token != "fly-token" || opts.Org != "acme" || opts.Region != "gdl" || opts.Size != "pro" || opts.Port != 8080 {
+		t.Errorf("deployProviderOptions() = %+v", opts)
+	}
+
+	// The default Nexo Cloud region is not a Fly region default
+	opts = deployProviderOptions(viper.New(), "gdl", "starter").Fly
+	if opts.Region != "" {
+		t.Errorf("Region = %q, want empty", opts.Region)
+	}
+}
+
+func TestRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io/user/app:1":        "ghcr.io",
+		"registry.fly.io/shop:v2":   "registry.fly.io",
+		"localhost:5000/app:latest": "localhost:5000",
+	}
+	for image, want := range tests {
+		if got := registryHost(image); got != want {
+			t.Errorf("registryHost(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -15,8 +16,8 @@ import (
 	"time"
 
 	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
 	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
-	"github.com/abdul-hamid-achik/nexo/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
@@ -233,11 +234,14 @@ func generateRoutes(appDir string, verbose bool) error {
 			return fmt.Errorf("failed to get module name: %w", err)
 		}
 
-		gen := scanner.NewGenerator(scanner.GeneratorConfig{
+		gen, err := newGenerator(scanner.GeneratorConfig{
 			ModuleName: moduleName,
 			AppDir:     appDir,
 			OutputDir:  ".nexo/generated",
 		})
+		if err != nil {
+			return err
+		}
 
 		_, err = gen.Generate()
 		if err != nil {
@@ -247,8 +251,10 @@ func generateRoutes(appDir string, verbose bool) error {
 
 	// Always run legacy generator for backward compatibility
 	// It generates nexo_routes.go which the main.go imports
-	_, err := generator.ScanAndGenerateRoutes(appDir, "nexo_routes.go")
-	return err
+	if _, err := generator.ScanAndGenerateRoutes(appDir, "nexo_routes.go"); err != nil {
+		return err
+	}
+	return writeRoutesManifest(appDir)
 }
 
 func runDev(cmd *cobra.Command, args []string) {
@@ -264,7 +270,7 @@ func runDev(cmd *cobra.Command, args []string) {
 
 	// Check for main.go or app directory
 	if _, err := os.Stat("main.go"); os.IsNotExist(err) {
-		fmt.Printf("  %s No main.go found in current directory\n", red("Error:"))
+		fmt.Printf("  %s %v\n", red("Error:"), noMainGoError())
 		fmt.Printf("  Run this command from your project root\n\n")
 		os.Exit(1)
 	}
@@ -307,16 +313,22 @@ func runDev(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Regenerate the wrappers of bracket directories now that their templ
+	// code is generated
+	if err := generator.SyncAppWrappers("app"); err != nil {
+		fmt.Printf("  %s %v\n", yellow("Warning:"), err)
+	}
+
 	// Check for Tailwind and start watch mode
 	var tailwindProcess *exec.Cmd
-	if tools.HasStyles() {
+	tailwind := loadTailwindProject()
+	if tailwind.hasStyles() {
 		fmt.Printf("  %s Starting Tailwind CSS watcher...\n", yellow("→"))
-		tw := tools.NewTailwindCLI()
 
 		// Do initial build if needed
-		if tools.NeedsInitialBuild() {
+		if tailwind.needsInitialBuild() {
 			fmt.Printf("  %s Building initial CSS...\n", yellow("→"))
-			if err := tw.Build(tools.DefaultInputPath(), tools.DefaultOutputPath()); err != nil {
+			if err := tailwind.build(); err != nil {
 				fmt.Printf("  %s Tailwind build failed: %v\n", yellow("Warning:"), err)
 			} else {
 				fmt.Printf("  %s CSS built\n", green("✓"))
@@ -324,7 +336,7 @@ func runDev(cmd *cobra.Command, args []string) {
 		}
 
 		// Start watch mode
-		proc, err := tw.Watch(tools.DefaultInputPath(), tools.DefaultOutputPath())
+		proc, err := tailwind.cli.Watch(tailwind.input, tailwind.output)
 		if err != nil {
 			fmt.Printf("  %s Failed to start Tailwind watcher: %v\n", yellow("Warning:"), err)
 		} else {
@@ -333,6 +345,18 @@ func runDev(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Bundle scripts in watch mode if an entry point exists
+	bundleCtx, stopBundler := context.WithCancel(context.Background())
+	defer stopBundler()
+	if b := loadBundler(true); b.HasEntryPoints() {
+		fmt.Printf("  %s Starting esbuild watcher...\n", yellow("→"))
+		if err := b.Watch(bundleCtx); err != nil {
+			fmt.Printf("  %s Failed to start esbuild watcher: %v\n", yellow("Warning:"), err)
+		} else {
+			fmt.Printf("  %s esbuild watcher started\n", green("✓"))
+		}
+	}
+
 	// Start the server
 	var serverProcess *exec.Cmd
 	serverProcess = startDevServer(devPort)
@@ -369,8 +393,8 @@ func runDev(cmd *cobra.Command, args []string) {
 	}
 
 	// Also watch styles directory for CSS changes
-	if tools.HasStyles() {
-		stylesDir := "styles"
+	if tailwind.hasStyles() {
+		stylesDir := filepath.Dir(tailwind.input)
 		if err := watcher.Add(stylesDir); err == nil {
 			if devVerbose {
 				fmt.Printf("  %s Watching: %s\n", cyan("→"), stylesDir)
@@ -450,12 +474,13 @@ func runDev(cmd *cobra.Command, args []string) {
 				timestamp := time.Now().Format("15:04:05")
 
 				// Regenerate routes if a route/middleware/proxy/page/layout/loader file changed
-				needsRouteRegen := strings.Contains(fileName, "route.go") ||
+				needsRouteRegen := nexo.IsRouteFile(filepath.Base(fileName)) ||
 					strings.Contains(fileName, "middleware.go") ||
 					strings.Contains(fileName, "proxy.go") ||
 					strings.Contains(fileName, "loader.go") ||
 					strings.HasSuffix(fileName, "page.templ") ||
-					strings.HasSuffix(fileName, "layout.templ")
+					strings.HasSuffix(fileName, "layout.templ") ||
+					strings.HasSuffix(fileName, "maintenance.templ")
 
 				if needsRouteRegen {
 					if devVerbose {
@@ -481,16 +506,21 @@ func runDev(cmd *cobra.Command, args []string) {
 
 				// Rebuild Tailwind CSS if templ or css file changed
 				// This ensures new CSS classes used in templ files are included
-				if (fileExt == ".templ" || fileExt == ".css") && tools.HasStyles() {
+				if (fileExt == ".templ" || fileExt == ".css") && tailwind.hasStyles() {
 					if devVerbose {
 						fmt.Printf("  [%s] %s Rebuilding CSS...\n", timestamp, yellow("→"))
 					}
-					tw := tools.NewTailwindCLI()
-					if err := tw.Build(tools.DefaultInputPath(), tools.DefaultOutputPath()); err != nil {
+					if err := tailwind.build(); err != nil {
 						fmt.Printf("  [%s] %s CSS rebuild failed: %v\n", timestamp, yellow("⚠"), err)
 					}
 				}
 
+				// Wrappers in .nexo/generated/wrappers go stale on every change
+				if err := generator.SyncAppWrappers("app"); err != nil {
+					fmt.Printf("  [%s] %s %v\n", timestamp, red("✗"), err)
+					return
+				}
+
 				fmt.Printf("  [%s] %s Rebuilding...\n", timestamp, yellow("→"))
 
 				// Stop old server with graceful shutdown
@@ -538,6 +568,7 @@ func runDev(cmd *cobra.Command, args []string) {
 			if tailwindProcess != nil && tailwindProcess.Process != nil {
 				_ = tailwindProcess.Process.Kill()
 			}
+			stopBundler()
 			if serverProcess != nil && serverProcess.Process != nil {
 				_ = serverProcess.Process.Signal(syscall.SIGTERM)
 				// Wait with timeout for graceful shutdown
@@ -573,6 +604,10 @@ func startDevServer(port string) *exec.Cmd {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Env = append(os.Environ(), fmt.Sprintf("PORT=%s", actualPort))
+	if os.Getenv("NEXO_ENV") == "" {
+		// Run in development mode unless told otherwise
+		cmd.Env = append(cmd.Env, "NEXO_ENV=development")
+	}
 
 	if err := cmd.Start(); err != nil {
 		fmt.Printf("  %s Failed to start server: %v\n", color.RedString("Error:"), err)
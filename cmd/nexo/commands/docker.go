@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// errDockerNotFound is returned when the docker CLI is not on PATH.
+var errDockerNotFound = errors.New("docker not found, please install Docker")
+
+var dockerCmd = &cobra.Command{
+	Use:   "docker",
+	Short: "Generate a Dockerfile and build container images",
+	Long: `Generate a Dockerfile tuned for Nexo apps and build container images.
+
+The generated Dockerfile has two stages. The build stage installs the nexo
+and templ CLIs at the versions in go.mod and runs nexo build --embed, which
+generates templ components, builds Tailwind CSS and the JavaScript bundle,
+and compiles a static binary with static/ and content/ embedded. The final
+stage copies that binary into a distroless image running as nonroot.
+
+Examples:
+  nexo docker init                   # Write Dockerfile and .dockerignore
+  nexo docker build                  # Build <name>:latest
+  nexo docker build -t app:v1 --push # Build and push a tag`,
+}
+
+var dockerInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a Dockerfile and .dockerignore",
+	Long: `Write a multi-stage Dockerfile and a .dockerignore to the project.
+
+Existing files are kept unless --force is given. Commit the Dockerfile to
+customize it; nexo docker build and nexo deploy use it when present.`,
+	Run: runDockerInit,
+}
+
+var dockerBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the container image",
+	Long: `Build the app's container image with docker build.
+
+The project's Dockerfile is used when present; otherwise the Dockerfile that
+nexo docker init would write is generated for the build and removed after.
+The image is tagged <name>:latest by default, where name is the name in
+nexo.yaml or the project directory.`,
+	Run: runDockerBuild,
+}
+
+var (
+	dockerInitForce     bool
+	dockerBuildTag      string
+	dockerBuildPlatform string
+	dockerBuildPush     bool
+)
+
+func init() {
+	dockerInitCmd.Flags().BoolVarP(&dockerInitForce, "force", "f", false, "Overwrite an existing Dockerfile and .dockerignore")
+
+	dockerBuildCmd.Flags().StringVarP(&dockerBuildTag, "tag", "t", "", "Image tag (default: <name>:latest)")
+	dockerBuildCmd.Flags().StringVar(&dockerBuildPlatform, "platform", "", "Target platform, e.g. linux/amd64")
+	dockerBuildCmd.Flags().BoolVar(&dockerBuildPush, "push", false, "Push the image after building")
+
+	dockerCmd.AddCommand(dockerInitCmd)
+	dockerCmd.AddCommand(dockerBuildCmd)
+	rootCmd.AddCommand(dockerCmd)
+}
+
+func runDockerInit(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s docker init\n\n", cyan("Nexo"))
+	}
+
+	result, err := dockerInit(dockerInitForce)
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+	for _, f := range result.Files {
+		fmt.Printf("  %s Wrote %s\n", green("✓"), f)
+	}
+	for _, f := range result.Skipped {
+		fmt.Printf("  %s Kept existing %s (use --force to overwrite)\n", yellow("!"), f)
+	}
+	fmt.Printf("\n  Build the image with: %s\n\n", cyan("nexo docker build"))
+}
+
+// dockerInit writes the Dockerfile and .dockerignore, keeping existing
+// files unless force is set.
+func dockerInit(force bool) (*DockerInitOutput, error) {
+	dockerfile, err := projectDockerfile()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DockerInitOutput{}
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"Dockerfile", dockerfile},
+		{".dockerignore", []byte(generator.Dockerignore)},
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f.name); err == nil && !force {
+			result.Skipped = append(result.Skipped, f.name)
+			continue
+		}
+		if err := os.WriteFile(f.name, f.content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		result.Files = append(result.Files, f.name)
+	}
+	return result, nil
+}
+
+func runDockerBuild(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s docker build\n\n", cyan("Nexo"))
+	}
+
+	tag := dockerBuildTag
+	if tag == "" {
+		tag = defaultImageName() + ":latest"
+	}
+
+	if !jsonOutput {
+		fmt.Printf("  %s Building %s...\n", yellow("→"), tag)
+	}
+	result, err := buildDockerImage(tag, dockerBuildPlatform)
+	if err != nil {
+		fail(err)
+	}
+	if !jsonOutput {
+		fmt.Printf("  %s Image built: %s\n", green("✓"), dim(result.Image))
+	}
+
+	if dockerBuildPush {
+		if !jsonOutput {
+			fmt.Printf("  %s Pushing %s...\n", yellow("→"), tag)
+		}
+		if err := pushDockerImage(tag); err != nil {
+			fail(err)
+		}
+		result.Pushed = true
+		if !jsonOutput {
+			fmt.Printf("  %s Image pushed\n", green("✓"))
+		}
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+	fmt.Println()
+}
+
+// projectDockerfile renders the Dockerfile for the project in the working
+// directory. The exposed port is taken from nexo.yaml.
+func projectDockerfile() ([]byte, error) {
+	opts := generator.DetectDockerfileOptions(".")
+	if cfg, err := nexo.LoadConfig(""); err == nil {
+		if port, err := strconv.Atoi(cfg.Port); err == nil {
+			opts.Port = port
+		}
+	}
+	return generator.GenerateDockerfile(opts)
+}
+
+// buildDockerImage runs docker build for tag. Without a Dockerfile in the
+// project, a generated one is used for the build and removed after.
+func buildDockerImage(tag, platform string) (*DockerBuildOutput, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, errDockerNotFound
+	}
+
+	result := &DockerBuildOutput{Image: tag, Dockerfile: "Dockerfile", Platform: platform}
+	dockerfile := ""
+	if _, err := os.Stat("Dockerfile"); os.IsNotExist(err) {
+		content, err := projectDockerfile()
+		if err != nil {
+			return nil, err
+		}
+		dir, err := os.MkdirTemp("", "nexo-docker-*")
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		// BuildKit reads <Dockerfile>.dockerignore next to a Dockerfile
+		// outside the build context.
+		dockerfile = filepath.Join(dir, "Dockerfile")
+		if err := os.WriteFile(dockerfile, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to create Dockerfile: %w", err)
+		}
+		if _, err := os.Stat(".dockerignore"); os.IsNotExist(err) {
+			if err := os.WriteFile(dockerfile+".dockerignore", []byte(generator.Dockerignore), 0644); err != nil {
+				return nil, fmt.Errorf("failed to create .dockerignore: %w", err)
+			}
+		}
+		result.Dockerfile = ""
+		result.Generated = true
+	}
+
+	cmd := exec.Command("docker", dockerBuildArgs(tag, dockerfile, platform)...)
+	cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if jsonOutput {
+		cmd.Stdout = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("docker build failed: %w", err)
+	}
+	return result, nil
+}
+
+// dockerBuildArgs returns the docker build command line. An empty
+// dockerfile uses the Dockerfile in the build context.
+func dockerBuildArgs(tag, dockerfile, platform string) []string {
+	args := []string{"build", "-t", tag}
+	if dockerfile != "" {
+		args = append(args, "-f", dockerfile)
+	}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	return append(args, ".")
+}
+
+// pushDockerImage runs docker push for tag.
+func pushDockerImage(tag string) error {
+	cmd := exec.Command("docker", "push", tag)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if jsonOutput {
+		cmd.Stdout = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker push failed: %w", err)
+	}
+	return nil
+}
+
+// defaultImageName is the name in nexo.yaml, or the project directory,
+// reduced to the characters allowed in image names.
+func defaultImageName() string {
+	v := viper.New()
+	v.SetConfigName("nexo")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+
+	name := ""
+	if err := v.ReadInConfig(); err == nil {
+		name = v.GetString("name")
+	}
+	if name == "" {
+		if wd, err := os.Getwd(); err == nil {
+			name = filepath.Base(wd)
+		}
+	}
+	return imageName(name)
+}
+
+// imageName lowercases name and replaces the characters not allowed in an
+// image name with dashes.
+func imageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	name = strings.Trim(name, ".-_")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
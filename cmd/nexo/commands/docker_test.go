@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDockerInit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".dockerignore", []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("nexo.yaml", []byte("port: \"8080\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := dockerInit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Files, []string{"Dockerfile"}) || !reflect.DeepEqual(result.Skipped, []string{".dockerignore"}) {
+		t.Errorf("dockerInit() = %+v", result)
+	}
+
+	dockerfile, err := os.ReadFile("Dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "EXPOSE 8080\n") {
+		t.Errorf("Dockerfile does not use the configured port:\n%s", dockerfile)
+	}
+	if ignore, _ := os.ReadFile(".dockerignore"); string(ignore) != "custom\n" {
+		t.Errorf(".dockerignore overwritten without --force: %q", ignore)
+	}
+
+	result, err = dockerInit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 2 || len(result.Skipped) != 0 {
+		t.Errorf("dockerInit(force) = %+v", result)
+	}
+}
+
+func TestDockerBuildArgs(t *testing.T) {
+	tests := []struct {
+		dockerfile string
+		platform   string
+		want       []string
+	}{
+		{"", "", []string{"build", "-t", "app:latest", "."}},
+		{"/tmp/Dockerfile", "linux/amd64", []string{"build", "-t", "app:latest", "-f", "/tmp/Dockerfile", "--platform", "linux/amd64", "."}},
+	}
+
+	for _, tt := range tests {
+		if got := dockerBuildArgs("app:latest", tt.dockerfile, tt.platform); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("dockerBuildArgs(%q, %q) = %v, want %v", tt.dockerfile, tt.platform, got, tt.want)
+		}
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := map[string]string{
+		"my-app":      "my-app",
+		"My App":      "my-app",
+		"_site.v2":    "site.v2",
+		"":            "app",
+		"--":          "app",
+		"Café Nexo 2": "caf--nexo-2",
+	}
+	for in, want := range tests {
+		if got := imageName(in); got != want {
+			t.Errorf("imageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/abdul-hamid-achik/nexo/pkg/tools"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorPort string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the development environment",
+	Long: `Check that the tools a Nexo project needs are installed and set up, and
+print how to fix what is not.
+
+Checks:
+  Go         installed, and at least the version go.mod requires
+  go.mod     no replace directives pointing at missing or local directories
+  templ      installed, at the version go.mod requires
+  Tailwind   the CLI is available when the project has a stylesheet
+  Port       the dev server port is free
+
+Exits with status 1 when a check fails.
+
+Examples:
+  nexo doctor
+  nexo doctor --port 8080
+  nexo doctor --json`,
+	Args: cobra.NoArgs,
+	Run:  runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorPort, "port", "p", "", "Port to check (default: port in nexo.yaml, or 3000)")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// Doctor check statuses.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorRun runs a command and returns its trimmed output. Tests replace it.
+var doctorRun = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// doctorLookPath finds an executable. Tests replace it.
+var doctorLookPath = exec.LookPath
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	port := doctorPort
+	if port == "" {
+		if cfg, err := nexo.LoadConfig(""); err == nil && cfg.Port != "" {
+			port = cfg.Port
+		} else {
+			port = "3000"
+		}
+	}
+
+	out := runDoctorChecks(port)
+
+	if jsonOutput {
+		printJSON(JSONResponse{Success: out.Failed == 0, Data: out})
+		if out.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("\n  %s Doctor\n\n", cyan("Nexo"))
+	for _, c := range out.Checks {
+		var mark string
+		switch c.Status {
+		case doctorOK:
+			mark = green("✓")
+		case doctorWarn:
+			mark = yellow("!")
+		case doctorFail:
+			mark = red("✗")
+		default:
+			mark = dim("-")
+		}
+		fmt.Printf("  %s %-9s %s\n", mark, c.Name, c.Message)
+		if c.Fix != "" {
+			fmt.Printf("    %s %s\n", dim("fix:"), c.Fix)
+		}
+	}
+
+	fmt.Printf("\n  %d passed, %d warning(s), %d failed\n", out.Passed, out.Warnings, out.Failed)
+	if out.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runDoctorChecks runs every check in the current directory.
+func runDoctorChecks(port string) *DoctorOutput {
+	var mod *generator.GoMod
+	if data, err := os.ReadFile("go.mod"); err == nil {
+		m := generator.ParseGoMod(data)
+		mod = &m
+	}
+
+	out := &DoctorOutput{Checks: []DoctorCheck{
+		checkGo(mod),
+		checkGoMod(mod),
+		checkTempl(mod),
+		checkTailwind(),
+		checkPort(port),
+	}}
+	for _, c := range out.Checks {
+		switch c.Status {
+		case doctorOK:
+			out.Passed++
+		case doctorWarn:
+			out.Warnings++
+		case doctorFail:
+			out.Failed++
+		}
+	}
+	return out
+}
+
+// checkGo checks that Go is installed and new enough for go.mod.
+func checkGo(mod *generator.GoMod) DoctorCheck {
+	c := DoctorCheck{Name: "Go"}
+	installed, err := doctorRun("go", "env", "GOVERSION")
+	if err != nil || installed == "" {
+		c.Status = doctorFail
+		c.Message = "go not found"
+		c.Fix = "Install Go from https://go.dev/dl"
+		return c
+	}
+
+	c.Status = doctorOK
+	c.Message = installed
+	if mod == nil || mod.Go == "" {
+		return c
+	}
+	c.Message += " (go.mod requires " + mod.Go + ")"
+	if tools.CompareVersions(strings.TrimPrefix(installed, "go"), mod.Go) < 0 {
+		c.Status = doctorFail
+		c.Fix = fmt.Sprintf("Install Go %s or newer, or set GOTOOLCHAIN=auto to download it", mod.Go)
+	}
+	return c
+}
+
+// checkGoMod checks the replace directives of go.mod: a replacement with a
+// missing directory breaks the build, and one with a local directory
+// breaks Docker and CI builds, which cannot see it.
+func checkGoMod(mod *generator.GoMod) DoctorCheck {
+	c := DoctorCheck{Name: "go.mod"}
+	if mod == nil {
+		c.Status = doctorFail
+		c.Message = "no go.mod in the current directory"
+		c.Fix = "Run nexo doctor in the project root, or create a project with nexo new"
+		return c
+	}
+
+	var missing, local []string
+	for module, target := range mod.Replace {
+		if !isLocalPath(target) {
+			continue
+		}
+		if _, err := os.Stat(target); err != nil {
+			missing = append(missing, module+" => "+target)
+		} else {
+			local = append(local, module)
+		}
+	}
+
+	slices.Sort(missing)
+	slices.Sort(local)
+	switch {
+	case len(missing) > 0:
+		c.Status = doctorFail
+		c.Message = "replaced with missing directory: " + strings.Join(missing, ", ")
+		c.Fix = "Fix the path, or drop the replacement with go mod edit -dropreplace=<module>"
+	case len(local) > 0:
+		c.Status = doctorWarn
+		c.Message = "replaced with local directory: " + strings.Join(local, ", ")
+		c.Fix = "Docker and CI builds cannot see it; drop it before deploying with go mod edit -dropreplace=" + local[0]
+	default:
+		c.Status = doctorOK
+		c.Message = "no local replace directives"
+	}
+	return c
+}
+
+// isLocalPath reports whether a replace target is a directory rather than
+// a module.
+func isLocalPath(target string) bool {
+	return strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") ||
+		target == "." || target == ".." || filepath.IsAbs(target)
+}
+
+// checkTempl checks that the templ CLI is installed at the version go.mod
+// requires, so generated code matches the runtime library.
+func checkTempl(mod *generator.GoMod) DoctorCheck {
+	c := DoctorCheck{Name: "templ"}
+	required := ""
+	if mod != nil && !mod.Replaced(generator.TemplModule) {
+		required = mod.Require[generator.TemplModule]
+	}
+	install := "go install github.com/a-h/templ/cmd/templ@" + orLatest(required)
+
+	if _, err := doctorLookPath("templ"); err != nil {
+		if required == "" {
+			c.Status = doctorSkip
+			c.Message = "not installed, not required by go.mod"
+			return c
+		}
+		c.Status = doctorFail
+		c.Message = "templ not found in PATH"
+		c.Fix = install
+		return c
+	}
+
+	installed, err := doctorRun("templ", "version")
+	if err != nil {
+		c.Status = doctorWarn
+		c.Message = "templ version failed: " + err.Error()
+		c.Fix = install
+		return c
+	}
+	if fields := strings.Fields(installed); len(fields) > 0 {
+		installed = fields[0]
+	}
+
+	c.Status = doctorOK
+	c.Message = installed
+	if required == "" {
+		return c
+	}
+	if tools.CompareVersions(installed, required) != 0 {
+		c.Status = doctorWarn
+		c.Message = fmt.Sprintf("%s installed, go.mod requires %s", installed, required)
+		c.Fix = install
+	}
+	return c
+}
+
+// checkTailwind checks that the Tailwind CLI is available when the project
+// has a stylesheet.
+func checkTailwind() DoctorCheck {
+	c := DoctorCheck{Name: "Tailwind"}
+	p := loadTailwindProject()
+	if !p.hasStyles() {
+		c.Status = doctorSkip
+		c.Message = "no " + p.input
+		return c
+	}
+
+	if p.cli.IsInstalled() {
+		c.Status = doctorOK
+		c.Message = "v" + p.cli.Version()
+		return c
+	}
+	if path, err := doctorLookPath("tailwindcss"); err == nil {
+		c.Status = doctorOK
+		c.Message = path
+		return c
+	}
+	c.Status = doctorWarn
+	c.Message = "v" + p.cli.Version() + " not downloaded yet"
+	c.Fix = "Run nexo tailwind install (needs network access), or put tailwindcss on PATH"
+	return c
+}
+
+// checkPort checks that the dev server port is free.
+func checkPort(port string) DoctorCheck {
+	c := DoctorCheck{Name: "Port"}
+	if isPortAvailable(port) {
+		c.Status = doctorOK
+		c.Message = port + " is free"
+		return c
+	}
+	c.Status = doctorWarn
+	c.Message = port + " is in use"
+	c.Fix = "Stop the process using it, or run nexo dev --port " + findAvailablePort(port)
+	return c
+}
+
+// orLatest returns version, or "latest" when it is empty.
+func orLatest(version string) string {
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+)
+
+// fakeDoctorTools replaces the commands doctor runs with fixed outputs.
+func fakeDoctorTools(t *testing.T, outputs map[string]string) {
+	t.Helper()
+	run, lookPath := doctorRun, doctorLookPath
+	t.Cleanup(func() { doctorRun, doctorLookPath = run, lookPath })
+
+	doctorRun = func(name string, args ...string) (string, error) {
+		out, ok := outputs[name]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return out, nil
+	}
+	doctorLookPath = func(name string) (string, error) {
+		if _, ok := outputs[name]; !ok {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}
+}
+
+func TestCheckGo(t *testing.T) {
+	mod := &generator.GoMod{Go: "1.25"}
+	tests := []struct {
+		name      string
+		installed map[string]string
+		mod       *generator.GoMod
+		want      string
+	}{
+		{"new enough", map[string]string{"go": "go1.25.5"}, mod, doctorOK},
+		{"too old", map[string]string{"go": "go1.24.2"}, mod, doctorFail},
+		{"no go.mod", map[string]string{"go": "go1.22.0"}, nil, doctorOK},
+		{"not installed", map[string]string{}, mod, doctorFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDoctorTools(t, tt.installed)
+			c := checkGo(tt.mod)
+			if c.Status != tt.want {
+				t.Errorf("checkGo() = %+v, want status %s", c, tt.want)
+			}
+			if c.Status == doctorFail && c.Fix == "" {
+				t.Error("failed check has no fix")
+			}
+		})
+	}
+}
+
+func TestCheckGoMod(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("nexo-local", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		replace map[string]string
+		want    string
+		message string
+	}{
+		{"none", nil, doctorOK, "no local replace"},
+		{"module replacement", map[string]string{"example.com/a": "example.com/b v1.0.0"}, doctorOK, "no local replace"},
+		{"local directory", map[string]string{generator.NexoModule: "./nexo-local"}, doctorWarn, generator.NexoModule},
+		{"missing directory", map[string]string{generator.NexoModule: "../missing"}, doctorFail, "../missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := checkGoMod(&generator.GoMod{Replace: tt.replace})
+			if c.Status != tt.want || !strings.Contains(c.Message, tt.message) {
+				t.Errorf("checkGoMod() = %+v, want %s containing %q", c, tt.want, tt.message)
+			}
+		})
+	}
+
+	if c := checkGoMod(nil); c.Status != doctorFail {
+		t.Errorf("checkGoMod(nil) = %+v, want fail", c)
+	}
+}
+
+func TestCheckTempl(t *testing.T) {
+	mod := &generator.GoMod{Require: map[string]string{generator.TemplModule: "v0.3.977"}}
+	tests := []struct {
+		name      string
+		installed map[string]string
+		mod       *generator.GoMod
+		want      string
+	}{
+		{"matching", map[string]string{"templ": "v0.3.977"}, mod, doctorOK},
+		{"mismatch", map[string]string{"templ": "v0.2.793"}, mod, doctorWarn},
+		{"missing", map[string]string{}, mod, doctorFail},
+		{"not required", map[string]string{}, &generator.GoMod{}, doctorSkip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDoctorTools(t, tt.installed)
+			c := checkTempl(tt.mod)
+			if c.Status != tt.want {
+				t.Errorf("checkTempl() = %+v, want status %s", c, tt.want)
+			}
+			if c.Status != doctorOK && c.Status != doctorSkip && !strings.Contains(c.Fix, "templ@v0.3.977") {
+				t.Errorf("checkTempl() fix = %q", c.Fix)
+			}
+		})
+	}
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	fakeDoctorTools(t, map[string]string{"go": "go1.25.5"})
+	if err := os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runDoctorChecks(findAvailablePort("39123"))
+	if len(out.Checks) != 5 {
+		t.Fatalf("runDoctorChecks() = %d checks, want 5", len(out.Checks))
+	}
+	if out.Failed != 0 {
+		t.Errorf("runDoctorChecks() failed: %+v", out.Checks)
+	}
+	if entries, _ := os.ReadDir("."); len(entries) != 1 {
+		t.Errorf("doctor left files behind: %v", entries)
+	}
+}
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/edge"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var edgeCmd = &cobra.Command{
+	Use:   "edge",
+	Short: "Run proxy.go rules at the edge",
+	Long: `Compile the decision logic of app/proxy.go, and the redirects and rewrites
+of nexo.yaml, into rules that run at the edge, in front of the app, so
+redirects, rewrites and early responses don't need a round trip to the origin.
+
+Commands:
+  nexo edge check     Report the constructs of proxy.go the edge can't run
+  nexo edge export    Write the rules as a Cloudflare Worker, _redirects or JSON`,
+}
+
+var edgeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report the constructs of proxy.go the edge can't run",
+	Long: `Check that the Proxy function of app/proxy.go compiles to edge rules, and
+list the constructs that don't with their position. Exits 1 when there are
+any, so it can guard CI. The redirects and rewrites of nexo.yaml are checked
+too, like condition values using regular expression syntax JavaScript lacks.
+
+The edge runs conditions on the path, host, method, headers, query
+parameters, cookies and client IP; string concatenation and the strings
+package's HasPrefix, HasSuffix, Contains, TrimPrefix and TrimSuffix; local
+variables, package-level constants, slice and map literals; and returns of
+nexo.Continue, Redirect, Rewrite, Forward and the Response helpers. Calls
+into the app, loops over runtime data and state kept between requests, like
+a rate limiter's, need the origin.
+
+Examples:
+  nexo edge check
+  nexo edge check --app-dir web/app`,
+	Run: runEdgeCheck,
+}
+
+var edgeExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the rules of proxy.go for the edge",
+	Long: `Compile app/proxy.go and write its rules in an edge format, after the
+redirects and rewrites of nexo.yaml, which the app runs before the proxy:
+
+  worker     Cloudflare Worker module that evaluates the rules and sends the
+             requests they continue on to the origin (default)
+  redirects  _redirects file for Cloudflare Pages and Netlify; only
+             redirects and rewrites on a path or path prefix, without
+             has or missing conditions
+  json       The compiled rules, for other CDNs and tooling
+
+Nothing is written when proxy.go uses constructs the edge can't run, or rules
+the format can't express; see nexo edge check. Redirects and rewrites added
+in code with WithRedirects or WithRewrites aren't exported.
+
+The app keeps running proxy.go on the requests the edge passes on. Remove it
+from the app once the edge serves it if its rules don't hold twice, like a
+rewrite to a path the proxy matches again.
+
+Examples:
+  nexo edge export --origin https://app.example.com
+  nexo edge export --format redirects -o public/_redirects
+  nexo edge export --format json`,
+	Run: runEdgeExport,
+}
+
+var (
+	edgeAppDir string
+	edgeFormat string
+	edgeOutput string
+	edgeOrigin string
+)
+
+func init() {
+	edgeCmd.PersistentFlags().StringVarP(&edgeAppDir, "app-dir", "d", "app", "App directory containing proxy.go")
+	edgeExportCmd.Flags().StringVarP(&edgeFormat, "format", "f", edge.FormatWorker, "Format: worker, redirects, json")
+	edgeExportCmd.Flags().StringVarP(&edgeOutput, "output", "o", "", "Output file (default: dist/edge/worker.js, _redirects or rules.json)")
+	edgeExportCmd.Flags().StringVar(&edgeOrigin, "origin", "", "URL of the app the worker sends requests to (default: the worker route's origin)")
+
+	edgeCmd.AddCommand(edgeCheckCmd)
+	edgeCmd.AddCommand(edgeExportCmd)
+	rootCmd.AddCommand(edgeCmd)
+}
+
+func runEdgeCheck(cmd *cobra.Command, args []string) {
+	result, err := compileEdge(edgeAppDir)
+	if err != nil {
+		edgeFail(err)
+	}
+	if jsonOutput {
+		printSuccess(result)
+	} else {
+		printEdgeResult("edge check", result)
+	}
+	if len(result.Issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runEdgeExport(cmd *cobra.Command, args []string) {
+	result, err := exportEdge(edgeAppDir, edgeFormat, edgeOutput, edgeOrigin)
+	if err != nil {
+		edgeFail(err)
+	}
+	if jsonOutput {
+		printSuccess(result)
+	} else {
+		printEdgeResult("edge export", result)
+	}
+	if len(result.Issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+// compileEdge compiles the proxy.go in appDir, with the redirects and
+// rewrites of nexo.yaml run before it as in the app.
+func compileEdge(appDir string) (*EdgeOutput, error) {
+	cfg, err := nexo.LoadConfig("")
+	if err != nil {
+		return nil, err
+	}
+	redirects, rewrites := edgeConfigRules(cfg)
+
+	file := filepath.Join(appDir, "proxy.go")
+	rules, issues := &edge.Rules{Rules: []edge.Rule{}}, []edge.Issue{}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		if len(redirects) == 0 && len(rewrites) == 0 {
+			return nil, fmt.Errorf("no proxy in %s and no redirects or rewrites in nexo.yaml (create one with nexo generate proxy)", appDir)
+		}
+		file = ""
+	} else {
+		if rules, issues, err = edge.CompileFile(file); err != nil {
+			return nil, err
+		}
+	}
+	rules.Redirects, rules.Rewrites = redirects, rewrites
+	issues = append(issues, edge.CheckConfig(rules)...)
+	if issues == nil {
+		issues = []edge.Issue{}
+	}
+	return &EdgeOutput{
+		File:      file,
+		Rules:     len(rules.Rules),
+		Redirects: len(redirects),
+		Rewrites:  len(rewrites),
+		Issues:    issues,
+		rules:     rules,
+	}, nil
+}
+
+// edgeConfigRules returns the redirects and rewrites of cfg as edge rules.
+func edgeConfigRules(cfg *nexo.Config) (redirects, rewrites []edge.ConfigRule) {
+	conditions := func(from []nexo.RuleCondition) []edge.ConfigCondition {
+		var to []edge.ConfigCondition
+		for _, c := range from {
+			to = append(to, edge.ConfigCondition{Type: strings.ToLower(c.Type), Key: c.Key, Value: c.Value})
+		}
+		return to
+	}
+	for _, r := range cfg.Redirects {
+		status := http.StatusTemporaryRedirect
+		if r.Permanent {
+			status = http.StatusPermanentRedirect
+		}
+		redirects = append(redirects, edge.ConfigRule{
+			Source:      r.Source,
+			Destination: r.Destination,
+			Status:      status,
+			Has:         conditions(r.Has),
+			Missing:     conditions(r.Missing),
+		})
+	}
+	for _, r := range cfg.Rewrites {
+		rewrites = append(rewrites, edge.ConfigRule{
+			Source:      r.Source,
+			Destination: r.Destination,
+			Has:         conditions(r.Has),
+			Missing:     conditions(r.Missing),
+		})
+	}
+	return redirects, rewrites
+}
+
+// exportEdge compiles the proxy.go in appDir and writes its rules in
+// format to output. Nothing is written when there are issues.
+func exportEdge(appDir, format, output, origin string) (*EdgeOutput, error) {
+	name, ok := edge.Formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (use worker, redirects or json)", format)
+	}
+	result, err := compileEdge(appDir)
+	if err != nil || len(result.Issues) > 0 {
+		return result, err
+	}
+
+	data, issues, err := edge.Export(result.rules, format, edge.ExportOptions{Origin: origin})
+	if err != nil {
+		return nil, err
+	}
+	result.Format = format
+	if len(issues) > 0 {
+		result.Issues = issues
+		return result, nil
+	}
+
+	result.Output = output
+	if result.Output == "" {
+		result.Output = filepath.Join("dist", "edge", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(result.Output), 0755); err != nil {
+		return nil, err
+	}
+	return result, os.WriteFile(result.Output, data, 0644)
+}
+
+func printEdgeResult(command string, result *EdgeOutput) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fmt.Printf("\n  %s %s\n\n", cyan("Nexo"), command)
+	if len(result.Issues) > 0 {
+		if result.Format != "" {
+			fmt.Printf("  %s The %s format can't express these rules:\n\n", yellow("!"), result.Format)
+		} else {
+			fmt.Printf("  %s %s can't run at the edge:\n\n", yellow("!"), edgeSources(result))
+		}
+		for _, issue := range result.Issues {
+			fmt.Printf("      %s %s\n", dim(issue.Pos+":"), issue.Message)
+		}
+		fmt.Println()
+		return
+	}
+	if result.File != "" {
+		fmt.Printf("  %s %s compiles to %d edge rules\n", green("✓"), result.File, result.Rules)
+	}
+	if result.Redirects+result.Rewrites > 0 {
+		fmt.Printf("  %s nexo.yaml has %d redirects and %d rewrites\n", green("✓"), result.Redirects, result.Rewrites)
+	}
+	if result.Output != "" {
+		fmt.Printf("  %s Wrote %s\n", green("✓"), result.Output)
+	}
+	fmt.Println()
+}
+
+// edgeSources names the files the rules of result come from.
+func edgeSources(result *EdgeOutput) string {
+	switch {
+	case result.File == "":
+		return "nexo.yaml"
+	case result.Redirects+result.Rewrites == 0:
+		return result.File
+	}
+	return result.File + " or nexo.yaml"
+}
+
+func edgeFail(err error) {
+	if jsonOutput {
+		printJSONError(err)
+	} else {
+		red := color.New(color.FgRed).SprintFunc()
+		fmt.Printf("  %s %v\n\n", red("Error:"), err)
+	}
+	os.Exit(1)
+}
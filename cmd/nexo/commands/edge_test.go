@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportEdge(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("app", 0755); err != nil {
+		t.Fatal(err)
+	}
+	proxy := `package app
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	if c.Path() == "/old" {
+		return nexo.Redirect("/new", 301), nil
+	}
+	if c.Query("debug") == "1" {
+		return nexo.ResponseHTML(403, "no"), nil
+	}
+	return nexo.Continue(), nil
+}
+`
+	if err := os.WriteFile(filepath.Join("app", "proxy.go"), []byte(proxy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := exportEdge("app", "worker", "", "https://app.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rules != 3 || len(result.Issues) != 0 || result.Output != filepath.Join("dist", "edge", "worker.js") {
+		t.Errorf("exportEdge() = %+v", result)
+	}
+	if data, err := os.ReadFile(result.Output); err != nil || !strings.Contains(string(data), `"value": "/old"`) {
+		t.Errorf("worker.js = %s, %v", data, err)
+	}
+
+	// _redirects can't match on the query: nothing is written
+	result, err = exportEdge("app", "redirects", "public/_redirects", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Pos != filepath.Join("app", "proxy.go")+":10" {
+		t.Errorf("issues = %v", result.Issues)
+	}
+	if _, err := os.Stat("public/_redirects"); !os.IsNotExist(err) {
+		t.Errorf("_redirects written despite issues: %v", err)
+	}
+
+	if _, err := exportEdge("app", "nginx", "", ""); err == nil {
+		t.Error("exportEdge() accepted an unknown format")
+	}
+	if _, err := exportEdge("web", "json", "", ""); err == nil {
+		t.Error("exportEdge() without a proxy succeeded")
+	}
+}
+
+func TestExportEdge_Config(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := `redirects:
+  - source: /blog/{slug}
+    destination: /posts/{slug}
+    permanent: true
+rewrites:
+  - source: /about
+    destination: /pages/about
+`
+	if err := os.WriteFile("nexo.yaml", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rules of nexo.yaml export without a proxy.go
+	result, err := exportEdge("app", "redirects", "public/_redirects", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Redirects != 1 || result.Rewrites != 1 || len(result.Issues) != 0 {
+		t.Errorf("exportEdge() = %+v", result)
+	}
+	data, err := os.ReadFile("public/_redirects")
+	if err != nil || !strings.Contains(string(data), "/blog/:slug /posts/:slug 308\n/about /pages/about 200\n") {
+		t.Errorf("_redirects = %s, %v", data, err)
+	}
+
+	config += "    has:\n      - type: query\n        key: v\n        value: (?i)beta\n"
+	if err := os.WriteFile("nexo.yaml", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = compileEdge("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Pos != "nexo.yaml: rewrites[0]" {
+		t.Errorf("issues = %v", result.Issues)
+	}
+}
@@ -4,28 +4,52 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+	"github.com/abdul-hamid-achik/nexo/pkg/deploy"
+	"github.com/abdul-hamid-achik/nexo/pkg/env"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
-var envShowValues bool
+var (
+	envShowValues bool
+	envProvider   string
+	envFile       string
+	envEncrypt    bool
+	envForce      bool
+	envKeys       []string
+
+	// envApp is the app named before the subcommand, as in
+	// nexo env <app> set KEY=value.
+	envApp string
+)
 
 var envCmd = &cobra.Command{
 	Use:   "env <app>",
 	Short: "Manage environment variables",
-	Long: `View and manage environment variables for a Nexo Cloud application.
+	Long: `View and manage environment variables for a Nexo Cloud application,
+and sync them with local .env files.
+
+Secrets in .env files can be encrypted in place with a key kept out of git
+(.env.key, or NEXO_ENV_KEY in CI), so the files can be committed.
 
 Examples:
   nexo env my-app                          # List variables (redacted)
   nexo env my-app --show                   # List with values
   nexo env my-app set KEY=value            # Set variable
   nexo env my-app set KEY1=val1 KEY2=val2  # Set multiple
-  nexo env my-app unset KEY                # Remove variable`,
+  nexo env my-app unset KEY                # Remove variable
+  nexo env push --file .env.production     # Upload a .env file
+  nexo env pull --encrypt                  # Download into an encrypted .env
+  nexo env keygen                          # Create .env.key
+  nexo env encrypt .env.production         # Encrypt a file's values`,
 	Args: cobra.MinimumNArgs(1),
 	Run:  runEnvList,
 }
@@ -54,11 +78,86 @@ Examples:
 	Run:  runEnvUnset,
 }
 
+var envPushCmd = &cobra.Command{
+	Use:   "push [app]",
+	Short: "Upload a .env file to the deploy provider",
+	Long: `Upload the variables of a .env file to the app, decrypting encrypted
+values first. The app defaults to the name in nexo.yaml.
+
+Examples:
+  nexo env push                              # Upload .env
+  nexo env push --file .env.production       # Upload another file
+  nexo env push my-app --provider fly        # Store as Fly.io secrets`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runEnvPush,
+}
+
+var envPullCmd = &cobra.Command{
+	Use:   "pull [app]",
+	Short: "Download the app's variables into a .env file",
+	Long: `Download the app's variables into a .env file, readable only by you.
+With --encrypt, values are encrypted with the project's env key so the file
+can be committed. Fly.io secrets cannot be read back, so pull only works with
+Nexo Cloud.
+
+Examples:
+  nexo env pull                              # Write .env
+  nexo env pull --file .env.production -f    # Overwrite another file
+  nexo env pull --encrypt                    # Encrypt the values`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runEnvPull,
+}
+
+var envKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create the key that encrypts .env secrets",
+	Long: `Create .env.key with a new random key and add it to .gitignore.
+
+Share the key through a password manager, and set it as NEXO_ENV_KEY in CI
+and on servers.`,
+	Args: cobra.NoArgs,
+	Run:  runEnvKeygen,
+}
+
+var envEncryptCmd = &cobra.Command{
+	Use:   "encrypt [file]",
+	Short: "Encrypt the values of a .env file in place",
+	Long: `Encrypt the values of a .env file (default: .env) with the project's env
+key, keeping comments and order. Values already encrypted are left as is.
+
+Examples:
+  nexo env encrypt                           # Encrypt every value of .env
+  nexo env encrypt .env.production --keys DATABASE_URL,API_KEY`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runEnvEncrypt,
+}
+
+var envDecryptCmd = &cobra.Command{
+	Use:   "decrypt [file]",
+	Short: "Decrypt the values of a .env file in place",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runEnvDecrypt,
+}
+
 func init() {
 	envCmd.Flags().BoolVar(&envShowValues, "show", false, "Show variable values (not redacted)")
 
+	envCmd.PersistentFlags().StringVar(&envProvider, "provider", "", "Deploy provider: nexo or fly (default: deploy.provider in nexo.yaml, or nexo)")
+
+	envPushCmd.Flags().StringVar(&envFile, "file", ".env", "The .env file to upload")
+	envPullCmd.Flags().StringVar(&envFile, "file", ".env", "The .env file to write")
+	envPullCmd.Flags().BoolVar(&envEncrypt, "encrypt", false, "Encrypt the values with the project's env key")
+	envPullCmd.Flags().BoolVarP(&envForce, "force", "f", false, "Overwrite an existing file")
+	envKeygenCmd.Flags().BoolVarP(&envForce, "force", "f", false, "Replace an existing key")
+	envEncryptCmd.Flags().StringSliceVar(&envKeys, "keys", nil, "Only encrypt these variables (default: all)")
+
 	envCmd.AddCommand(envSetCmd)
 	envCmd.AddCommand(envUnsetCmd)
+	envCmd.AddCommand(envPushCmd)
+	envCmd.AddCommand(envPullCmd)
+	envCmd.AddCommand(envKeygenCmd)
+	envCmd.AddCommand(envEncryptCmd)
+	envCmd.AddCommand(envDecryptCmd)
 
 	rootCmd.AddCommand(envCmd)
 }
@@ -70,10 +169,22 @@ func runEnvList(cmd *cobra.Command, args []string) {
 
 	appName := args[0]
 
-	// Check if this is actually a subcommand call
+	// nexo env <app> set|unset ... runs the subcommand for app
 	if len(args) > 1 {
-		if args[1] == "set" || args[1] == "unset" {
-			// Redirect to subcommand
+		for _, sub := range []*cobra.Command{envSetCmd, envUnsetCmd} {
+			if args[1] != sub.Name() {
+				continue
+			}
+			if err := sub.Args(sub, args[2:]); err != nil {
+				if jsonOutput {
+					printJSONError(err)
+				} else {
+					fmt.Printf("  %s %v\n", red("Error:"), err)
+				}
+				os.Exit(1)
+			}
+			envApp = appName
+			sub.Run(sub, args[2:])
 			return
 		}
 	}
@@ -95,7 +206,7 @@ func runEnvList(cmd *cobra.Command, args []string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	env, err := client.GetEnv(ctx, appName)
+	vars, err := client.GetEnv(ctx, appName)
 	if err != nil {
 		if jsonOutput {
 			printJSONError(fmt.Errorf("failed to get env: %w", err))
@@ -112,7 +223,7 @@ func runEnvList(cmd *cobra.Command, args []string) {
 			Variables: make(map[string]string),
 			Redacted:  !envShowValues,
 		}
-		for k, v := range env {
+		for k, v := range vars {
 			if envShowValues {
 				output.Variables[k] = v
 			} else {
@@ -123,21 +234,21 @@ func runEnvList(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if len(env) == 0 {
+	if len(vars) == 0 {
 		fmt.Printf("  %s No environment variables set\n", dim("(empty)"))
 		fmt.Println("  Run 'nexo env " + appName + " set KEY=value' to add one")
 		return
 	}
 
 	// Sort keys for consistent output
-	keys := make([]string, 0, len(env))
-	for k := range env {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
 	for _, k := range keys {
-		v := env[k]
+		v := vars[k]
 		if envShowValues {
 			fmt.Printf("  %s=%s\n", cyan(k), v)
 		} else {
@@ -145,7 +256,7 @@ func runEnvList(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	fmt.Printf("\n  %s %d variable(s)\n", dim("Total:"), len(env))
+	fmt.Printf("\n  %s %d variable(s)\n", dim("Total:"), len(vars))
 	if !envShowValues {
 		fmt.Printf("  Use --show to reveal values\n")
 	}
@@ -156,18 +267,8 @@ func runEnvSet(cmd *cobra.Command, args []string) {
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
 
-	// Parent command passes app name, then "set", then the key=value pairs
-	// But we're called directly, so args are the key=value pairs
-	// We need to get app name from parent
-	appName := cmd.Parent().Use
-	if strings.HasPrefix(appName, "env") {
-		// Get from grandparent args
-		if len(os.Args) >= 4 {
-			appName = os.Args[2]
-		}
-	}
-
-	if appName == "" || appName == "env" {
+	appName := envAppName()
+	if appName == "" {
 		if jsonOutput {
 			printJSONError(fmt.Errorf("app name required"))
 		} else {
@@ -246,13 +347,8 @@ func runEnvUnset(cmd *cobra.Command, args []string) {
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
 
-	// Get app name
-	appName := ""
-	if len(os.Args) >= 4 {
-		appName = os.Args[2]
-	}
-
-	if appName == "" || appName == "env" {
+	appName := envAppName()
+	if appName == "" {
 		if jsonOutput {
 			printJSONError(fmt.Errorf("app name required"))
 		} else {
@@ -308,6 +404,299 @@ func runEnvUnset(cmd *cobra.Command, args []string) {
 	}
 }
 
+func runEnvPush(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	if len(args) > 0 {
+		envApp = args[0]
+	}
+	appName := envAppName()
+	if appName == "" {
+		envFail(fmt.Errorf("app name required: pass it or set name in nexo.yaml"))
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s Push Environment - %s\n\n", cyan("Nexo"), cyan(appName))
+	}
+
+	vars, err := loadEnvFile(envFile)
+	if err != nil {
+		envFail(fmt.Errorf("failed to load %s: %w", envFile, err))
+	}
+	if len(vars) == 0 {
+		envFail(fmt.Errorf("%s has no variables", envFile))
+	}
+
+	provider, err := loadDeployProvider(envProvider)
+	if err != nil {
+		envFail(err)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("  %s Uploading %d variable(s) from %s to %s...\n", yellow("->"), len(vars), envFile, provider.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := provider.SetEnv(ctx, appName, vars); err != nil {
+		envFail(fmt.Errorf("failed to set env: %w", err))
+	}
+
+	keys := sortedKeys(vars)
+	if jsonOutput {
+		printSuccess(EnvSyncOutput{
+			App:      appName,
+			Provider: provider.Name(),
+			File:     envFile,
+			Keys:     keys,
+		})
+		return
+	}
+	fmt.Printf("  %s Pushed %d variable(s)\n", green("OK"), len(keys))
+	for _, k := range keys {
+		fmt.Printf("    - %s\n", cyan(k))
+	}
+	fmt.Println("\n  Note: Changes take effect on next deployment")
+}
+
+func runEnvPull(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	if len(args) > 0 {
+		envApp = args[0]
+	}
+	appName := envAppName()
+	if appName == "" {
+		envFail(fmt.Errorf("app name required: pass it or set name in nexo.yaml"))
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s Pull Environment - %s\n\n", cyan("Nexo"), cyan(appName))
+	}
+
+	if _, err := os.Stat(envFile); err == nil && !envForce {
+		envFail(fmt.Errorf("%s already exists (use --force to overwrite)", envFile))
+	}
+
+	provider, err := loadDeployProvider(envProvider)
+	if err != nil {
+		envFail(err)
+	}
+	reader, ok := provider.(deploy.EnvReader)
+	if !ok {
+		envFail(fmt.Errorf("%s does not support reading environment variables", provider.Name()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	vars, err := reader.GetEnv(ctx, appName)
+	if err != nil {
+		envFail(fmt.Errorf("failed to get env: %w", err))
+	}
+
+	out, err := pullEnvFile(envFile, vars, envEncrypt)
+	if err != nil {
+		envFail(err)
+	}
+	out.App = appName
+	out.Provider = provider.Name()
+
+	if jsonOutput {
+		printSuccess(out)
+		return
+	}
+	state := ""
+	if out.Encrypted {
+		state = " (encrypted)"
+	}
+	fmt.Printf("  %s Wrote %d variable(s) to %s%s\n", green("OK"), len(out.Keys), envFile, state)
+}
+
+// pullEnvFile writes vars to file, encrypting the values with the
+// project's env key when encrypt is set.
+func pullEnvFile(file string, vars map[string]string, encrypt bool) (*EnvSyncOutput, error) {
+	if encrypt {
+		key, err := env.LoadKey(filepath.Dir(file))
+		if err != nil {
+			return nil, err
+		}
+		if err := env.EncryptValues(key, vars); err != nil {
+			return nil, err
+		}
+	}
+	if err := env.WriteFile(file, vars); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return &EnvSyncOutput{File: file, Keys: sortedKeys(vars), Encrypted: encrypt}, nil
+}
+
+func runEnvKeygen(cmd *cobra.Command, args []string) {
+	green := color.New(color.FgGreen).SprintFunc()
+
+	out, err := envKeygen(envForce)
+	if err != nil {
+		envFail(err)
+	}
+	if jsonOutput {
+		printSuccess(out)
+		return
+	}
+	fmt.Printf("\n  %s Created %s\n", green("OK"), out.File)
+	if out.Gitignore {
+		fmt.Printf("  %s Added %s to .gitignore\n", green("OK"), out.File)
+	}
+	fmt.Printf("\n  Share the key securely and set it as %s in CI.\n", env.KeyEnv)
+}
+
+// envKeygen writes a new key to .env.key and makes sure git ignores it.
+func envKeygen(force bool) (*EnvKeygenOutput, error) {
+	if _, err := os.Stat(env.KeyFile); err == nil && !force {
+		return nil, fmt.Errorf("%s already exists (use --force to replace it; values encrypted with it can no longer be read)", env.KeyFile)
+	}
+	key, err := env.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(env.KeyFile, []byte(key+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	added, err := ensureGitignored(env.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvKeygenOutput{File: env.KeyFile, Gitignore: added}, nil
+}
+
+// ensureGitignored adds name to .gitignore unless it is already listed. It
+// reports whether the file was changed.
+func ensureGitignored(name string) (bool, error) {
+	data, err := os.ReadFile(".gitignore")
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == name || line == "/"+name {
+			return false, nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, name+"\n"...)
+	return true, os.WriteFile(".gitignore", data, 0644)
+}
+
+func runEnvEncrypt(cmd *cobra.Command, args []string) {
+	runEnvCrypt(args, true)
+}
+
+func runEnvDecrypt(cmd *cobra.Command, args []string) {
+	runEnvCrypt(args, false)
+}
+
+func runEnvCrypt(args []string, encrypt bool) {
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	file := ".env"
+	if len(args) > 0 {
+		file = args[0]
+	}
+	out, err := cryptEnvFile(file, encrypt, envKeys)
+	if err != nil {
+		envFail(err)
+	}
+	if jsonOutput {
+		printSuccess(out)
+		return
+	}
+
+	verb := "Decrypted"
+	if encrypt {
+		verb = "Encrypted"
+	}
+	fmt.Printf("\n  %s %s %d value(s) in %s\n", green("OK"), verb, len(out.Keys), file)
+	for _, k := range out.Keys {
+		fmt.Printf("    - %s\n", cyan(k))
+	}
+}
+
+// cryptEnvFile encrypts or decrypts the values of file in place with the
+// project's env key. With keys, only those variables are encrypted.
+func cryptEnvFile(file string, encrypt bool, keys []string) (*EnvCryptOutput, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := env.LoadKey(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &EnvCryptOutput{File: file, Encrypted: encrypt, Keys: []string{}}
+	data, err = env.Rewrite(data, func(name, value string) (string, error) {
+		switch {
+		case encrypt && (env.IsEncrypted(value) || (len(keys) > 0 && !slices.Contains(keys, name))):
+			return value, nil
+		case !encrypt && !env.IsEncrypted(value):
+			return value, nil
+		}
+		out.Keys = append(out.Keys, name)
+		if encrypt {
+			return env.Encrypt(key, name, value)
+		}
+		return env.Decrypt(key, name, value)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// envAppName returns the app named before the subcommand, or the name in
+// nexo.yaml.
+func envAppName() string {
+	if envApp != "" {
+		return envApp
+	}
+	v := viper.New()
+	v.SetConfigName("nexo")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	if err := v.ReadInConfig(); err != nil {
+		return ""
+	}
+	return v.GetString("name")
+}
+
+// envFail prints err and exits.
+func envFail(err error) {
+	red := color.New(color.FgRed).SprintFunc()
+	if jsonOutput {
+		printJSONError(err)
+	} else {
+		fmt.Printf("  %s %v\n", red("Error:"), err)
+	}
+	os.Exit(1)
+}
+
+// sortedKeys returns the keys of vars in order.
+func sortedKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // redactValue replaces all but the first and last characters with asterisks
 func redactValue(s string) string {
 	if len(s) <= 4 {
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/env"
+)
+
+func TestEnvKeygen(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".gitignore", []byte("bin/"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := envKeygen(false)
+	if err != nil {
+		t.Fatalf("envKeygen() error = %v", err)
+	}
+	if !out.Gitignore {
+		t.Error("envKeygen() did not update .gitignore")
+	}
+	data, _ := os.ReadFile(".gitignore")
+	if string(data) != "bin/\n.env.key\n" {
+		t.Errorf(".gitignore = %q", data)
+	}
+	if info, err := os.Stat(env.KeyFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("key file = %v, %v, want mode 0600", info, err)
+	}
+
+	if _, err := envKeygen(false); err == nil {
+		t.Error("envKeygen() replaced an existing key without force")
+	}
+	out, err = envKeygen(true)
+	if err != nil || out.Gitignore {
+		t.Errorf("envKeygen(force) = %+v, %v, want .gitignore unchanged", out, err)
+	}
+}
+
+func TestCryptEnvFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(env.KeyEnv, "")
+	content := "# Database\nDATABASE_URL=postgres://db\nDEBUG=true\n"
+	if err := os.WriteFile(".env", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cryptEnvFile(".env", true, nil); err != env.ErrNoKey {
+		t.Errorf("cryptEnvFile() without key error = %v, want ErrNoKey", err)
+	}
+	if _, err := envKeygen(false); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := cryptEnvFile(".env", true, []string{"DATABASE_URL"})
+	if err != nil {
+		t.Fatalf("cryptEnvFile(encrypt) error = %v", err)
+	}
+	if strings.Join(out.Keys, ",") != "DATABASE_URL" {
+		t.Errorf("encrypted keys = %v", out.Keys)
+	}
+	data, _ := os.ReadFile(".env")
+	if strings.Contains(string(data), "postgres://db") || !strings.Contains(string(data), "# Database\nDATABASE_URL=enc:v1:") {
+		t.Errorf("encrypted file =\n%s", data)
+	}
+
+	// deploy reads encrypted files transparently
+	vars, err := loadEnvFile(".env")
+	if err != nil || vars["DATABASE_URL"] != "postgres://db" || vars["DEBUG"] != "true" {
+		t.Errorf("loadEnvFile() = %v, %v", vars, err)
+	}
+
+	if _, err := cryptEnvFile(".env", false, nil); err != nil {
+		t.Fatalf("cryptEnvFile(decrypt) error = %v", err)
+	}
+	data, _ = os.ReadFile(".env")
+	if string(data) != content {
+		t.Errorf("decrypted file = %q, want %q", data, content)
+	}
+}
+
+func TestPullEnvFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(env.KeyEnv, "")
+	if _, err := envKeygen(false); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := pullEnvFile(".env.production", map[string]string{"B": "2", "A": "one two"}, true)
+	if err != nil {
+		t.Fatalf("pullEnvFile() error = %v", err)
+	}
+	if !out.Encrypted || strings.Join(out.Keys, ",") != "A,B" {
+		t.Errorf("pullEnvFile() = %+v", out)
+	}
+	vars, err := loadEnvFile(".env.production")
+	if err != nil || vars["A"] != "one two" || vars["B"] != "2" {
+		t.Errorf("loadEnvFile() = %v, %v", vars, err)
+	}
+}
+
+func TestEnvAppName(t *testing.T) {
+	t.Chdir(t.TempDir())
+	envApp = ""
+	if got := envAppName(); got != "" {
+		t.Errorf("envAppName() = %q, want empty", got)
+	}
+	if err := os.WriteFile("nexo.yaml", []byte("name: shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := envAppName(); got != "shop" {
+		t.Errorf("envAppName() = %q, want shop", got)
+	}
+	envApp = "other"
+	defer func() { envApp = "" }()
+	if got := envAppName(); got != "other" {
+		t.Errorf("envAppName() = %q, want other", got)
+	}
+}
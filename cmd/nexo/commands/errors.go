@@ -0,0 +1,379 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var errorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "Generate the error catalog",
+	Long: `Find the nexo.ErrorDef definitions in the project and write them as an
+error catalog, so clients can look up the codes an API returns.
+
+Definitions are read from the source: composite literals of nexo.ErrorDef
+whose code is a string literal. Statuses can be integer literals or net/http
+constants. A code defined twice with different statuses or messages is an
+error, so the catalog can guard CI.
+
+Examples:
+  nexo errors
+  nexo errors -o docs/errors.md
+  nexo errors --format json -o public/errors.json`,
+	Run: runErrors,
+}
+
+var (
+	errorsDir    string
+	errorsFormat string
+	errorsOutput string
+)
+
+func init() {
+	errorsCmd.Flags().StringVar(&errorsDir, "dir", ".", "Directory to scan for error definitions")
+	errorsCmd.Flags().StringVarP(&errorsFormat, "format", "f", "markdown", "Format: markdown, json")
+	errorsCmd.Flags().StringVarP(&errorsOutput, "output", "o", "", "Output file (default: stdout)")
+
+	rootCmd.AddCommand(errorsCmd)
+}
+
+// ErrorEntry is an error definition found in the source.
+type ErrorEntry struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Message     string `json:"message"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Pos         string `json:"pos"`
+}
+
+func runErrors(cmd *cobra.Command, args []string) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	entries, err := scanErrorDefs(errorsDir)
+	var data []byte
+	if err == nil {
+		data, err = renderErrorCatalog(entries, errorsFormat)
+	}
+	if err == nil && errorsOutput != "" {
+		err = os.MkdirAll(filepath.Dir(errorsOutput), 0755)
+		if err == nil {
+			err = os.WriteFile(errorsOutput, data, 0644)
+		}
+	}
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	switch {
+	case jsonOutput:
+		printSuccess(ErrorsOutput{Errors: entries, Output: errorsOutput})
+	case errorsOutput == "":
+		os.Stdout.Write(data)
+	default:
+		fmt.Printf("  %s Wrote %d errors to %s\n\n", green("✓"), len(entries), errorsOutput)
+	}
+}
+
+// renderErrorCatalog renders entries as a markdown table or JSON.
+func renderErrorCatalog(entries []ErrorEntry, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(entries, "", "  ")
+		return append(data, '\n'), err
+	case "markdown", "md":
+		var b bytes.Buffer
+		b.WriteString("# Error Catalog\n\n")
+		b.WriteString("| Code | Status | Message | Description |\n")
+		b.WriteString("|------|--------|---------|-------------|\n")
+		for _, e := range entries {
+			status := strconv.Itoa(e.Status)
+			if e.Status == 0 {
+				status = "-"
+			} else if text := http.StatusText(e.Status); text != "" {
+				status += " " + text
+			}
+			code := "`" + e.Code + "`"
+			if e.Type != "" && e.Type != "about:blank" {
+				code = "[" + code + "](" + e.Type + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", code, status, markdownCell(e.Message), markdownCell(e.Description))
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown format %q (use markdown or json)", format)
+}
+
+// markdownCell escapes s for a table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// scanErrorDefs returns the nexo.ErrorDef literals in the Go files under
+// dir, sorted by code. Definitions repeated with the same status and
+// message are listed once, at the first position.
+func scanErrorDefs(dir string) ([]ErrorEntry, error) {
+	byCode := make(map[string]ErrorEntry)
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		for _, e := range errorDefsInFile(fset, file) {
+			prev, ok := byCode[e.Code]
+			switch {
+			case !ok:
+				byCode[e.Code] = e
+			case prev.Status != e.Status || prev.Message != e.Message:
+				return fmt.Errorf("error %q is defined at %s and %s with different statuses or messages", e.Code, prev.Pos, e.Pos)
+			default:
+				// Repeats can document what the first doesn't
+				if prev.Type == "" {
+					prev.Type = e.Type
+				}
+				if prev.Description == "" {
+					prev.Description = e.Description
+				}
+				byCode[e.Code] = prev
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ErrorEntry, 0, len(byCode))
+	for _, e := range byCode {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries, nil
+}
+
+// errorDefsInFile returns the ErrorDef literals of file with a literal code.
+func errorDefsInFile(fset *token.FileSet, file *ast.File) []ErrorEntry {
+	alias := importAlias(file, "github.com/abdul-hamid-achik/nexo/pkg/nexo", "nexo")
+	if alias == "" {
+		return nil
+	}
+	isErrorDef := func(expr ast.Expr) bool {
+		if star, ok := expr.(*ast.StarExpr); ok {
+			expr = star.X
+		}
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "ErrorDef" {
+			return false
+		}
+		x, ok := sel.X.(*ast.Ident)
+		return ok && x.Name == alias
+	}
+
+	var entries []ErrorEntry
+	add := func(lit *ast.CompositeLit) {
+		if e, ok := errorEntry(fset, lit); ok {
+			entries = append(entries, e)
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		switch t := lit.Type.(type) {
+		// []nexo.ErrorDef{{...}} and map[string]nexo.ErrorDef{"k": {...}}
+		case *ast.ArrayType:
+			if isErrorDef(t.Elt) {
+				addElements(lit, add)
+			}
+		case *ast.MapType:
+			if isErrorDef(t.Value) {
+				addElements(lit, add)
+			}
+		default:
+			if isErrorDef(lit.Type) {
+				add(lit)
+			}
+		}
+		return true
+	})
+	return entries
+}
+
+// importAlias returns the name file refers to the package path by, or ""
+// when it doesn't import it.
+func importAlias(file *ast.File, path, name string) string {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return name
+	}
+	return ""
+}
+
+// addElements calls add with the untyped composite literal elements of lit.
+func addElements(lit *ast.CompositeLit, add func(*ast.CompositeLit)) {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		if u, ok := elt.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			elt = u.X
+		}
+		if inner, ok := elt.(*ast.CompositeLit); ok && inner.Type == nil {
+			add(inner)
+		}
+	}
+}
+
+// errorEntry reads the keyed fields of an ErrorDef literal.
+func errorEntry(fset *token.FileSet, lit *ast.CompositeLit) (ErrorEntry, bool) {
+	pos := fset.Position(lit.Pos())
+	e := ErrorEntry{Pos: fmt.Sprintf("%s:%d", filepath.ToSlash(pos.Filename), pos.Line)}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch key.Name {
+		case "Code":
+			e.Code = stringLiteral(kv.Value)
+		case "Status":
+			e.Status = statusLiteral(kv.Value)
+		case "Message":
+			e.Message = stringLiteral(kv.Value)
+		case "Type":
+			e.Type = stringLiteral(kv.Value)
+		case "Description":
+			e.Description = stringLiteral(kv.Value)
+		}
+	}
+	return e, e.Code != ""
+}
+
+// stringLiteral returns the value of a string literal or a concatenation
+// of them, or "" for other expressions.
+func stringLiteral(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.STRING {
+			s, _ := strconv.Unquote(x.Value)
+			return s
+		}
+	case *ast.BinaryExpr:
+		if x.Op == token.ADD {
+			return stringLiteral(x.X) + stringLiteral(x.Y)
+		}
+	case *ast.ParenExpr:
+		return stringLiteral(x.X)
+	}
+	return ""
+}
+
+// statusLiteral returns the value of an integer literal or a net/http
+// error status constant, or 0 for other expressions.
+func statusLiteral(expr ast.Expr) int {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			n, _ := strconv.Atoi(x.Value)
+			return n
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok && pkg.Name == "http" {
+			return errorStatuses[x.Sel.Name]
+		}
+	}
+	return 0
+}
+
+// errorStatuses are the net/http constants of error statuses.
+var errorStatuses = map[string]int{
+	"StatusBadRequest":                    400,
+	"StatusUnauthorized":                  401,
+	"StatusPaymentRequired":               402,
+	"StatusForbidden":                     403,
+	"StatusNotFound":                      404,
+	"StatusMethodNotAllowed":              405,
+	"StatusNotAcceptable":                 406,
+	"StatusProxyAuthRequired":             407,
+	"StatusRequestTimeout":                408,
+	"StatusConflict":                      409,
+	"StatusGone":                          410,
+	"StatusLengthRequired":                411,
+	"StatusPreconditionFailed":            412,
+	"StatusRequestEntityTooLarge":         413,
+	"StatusRequestURITooLong":             414,
+	"StatusUnsupportedMediaType":          415,
+	"StatusRequestedRangeNotSatisfiable":  416,
+	"StatusExpectationFailed":             417,
+	"StatusTeapot":                        418,
+	"StatusMisdirectedRequest":            421,
+	"StatusUnprocessableEntity":           422,
+	"StatusLocked":                        423,
+	"StatusFailedDependency":              424,
+	"StatusTooEarly":                      425,
+	"StatusUpgradeRequired":               426,
+	"StatusPreconditionRequired":          428,
+	"StatusTooManyRequests":               429,
+	"StatusRequestHeaderFieldsTooLarge":   431,
+	"StatusUnavailableForLegalReasons":    451,
+	"StatusInternalServerError":           500,
+	"StatusNotImplemented":                501,
+	"StatusBadGateway":                    502,
+	"StatusServiceUnavailable":            503,
+	"StatusGatewayTimeout":                504,
+	"StatusHTTPVersionNotSupported":       505,
+	"StatusVariantAlsoNegotiates":         506,
+	"StatusInsufficientStorage":           507,
+	"StatusLoopDetected":                  508,
+	"StatusNotExtended":                   510,
+	"StatusNetworkAuthenticationRequired": 511,
+}
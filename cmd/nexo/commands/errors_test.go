@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanErrorDefs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"errors/errors.go": `package errors
+
+import (
+	"net/http"
+
+	nx "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+var ErrUserNotFound = nx.ErrorDef{
+	Code:        "user_not_found",
+	Status:      http.StatusNotFound,
+	Message:     "user %s not found",
+	Description: "No user has the ID | handle.",
+}
+
+var Defs = []nx.ErrorDef{
+	{Code: "email_taken", Status: 409, Message: "email " + "taken", Type: "https://example.com/errors/email_taken"},
+}
+`,
+		"app/api/route.go": `package api
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+var notFound = nexo.ErrorDef{Code: "user_not_found", Status: 404, Message: "user %s not found"}
+`,
+		"vendor/x/x.go": `package x
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+var skipped = nexo.ErrorDef{Code: "vendored", Status: 400}
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := scanErrorDefs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Code != "email_taken" || entries[0].Message != "email taken" || entries[1].Status != 404 {
+		t.Fatalf("scanErrorDefs() = %+v", entries)
+	}
+
+	md, err := renderErrorCatalog(entries, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| [`email_taken`](https://example.com/errors/email_taken) | 409 Conflict | email taken |  |",
+		"| `user_not_found` | 404 Not Found | user %s not found | No user has the ID \\| handle. |",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown doesn't contain %q:\n%s", want, md)
+		}
+	}
+
+	// The same code with another status is an error
+	conflict := "package api\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nvar gone = nexo.ErrorDef{Code: \"user_not_found\", Status: 410}\n"
+	if err := os.WriteFile("app/api/gone.go", []byte(conflict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanErrorDefs("."); err == nil || !strings.Contains(err.Error(), "user_not_found") {
+		t.Errorf("scanErrorDefs() error = %v", err)
+	}
+}
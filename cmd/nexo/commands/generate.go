@@ -1,6 +1,13 @@
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
 
 var generateCmd = &cobra.Command{
 	Use:     "generate",
@@ -8,6 +15,9 @@ var generateCmd = &cobra.Command{
 	Short:   "Generate Nexo components",
 	Long: `Generate routes, middleware, proxy, pages, and loaders for your Nexo project.
 
+With --dry-run, nothing is written: the files that would be created, updated
+or deleted are shown as a diff against disk.
+
 Examples:
   nexo generate routes                           Generate route registration code
   nexo generate route users --methods GET,POST
@@ -15,10 +25,78 @@ Examples:
   nexo generate middleware auth --path api/protected
   nexo generate proxy --template auth-check
   nexo generate page dashboard
-  nexo generate loader dashboard --data-type DashboardData`,
+  nexo generate loader dashboard --data-type DashboardData
+  nexo generate move users/[id] accounts/[id]
+  nexo generate remove users/[id]
+  nexo generate tests users/[id]
+  nexo generate route users --dry-run            Show the changes without writing them`,
 }
 
+var generateDryRun bool
+
 func init() {
+	generateCmd.PersistentFlags().BoolVar(&generateDryRun, "dry-run", false, "Show the changes as a diff without writing them")
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.AddCommand(generateRoutesCmd)
 }
+
+// generateFS returns the filesystem generate commands write to: the disk,
+// or with --dry-run an in-memory overlay of it.
+func generateFS() genfs.WriteFS {
+	if generateDryRun {
+		return genfs.NewMemFS(genfs.Disk)
+	}
+	return genfs.Disk
+}
+
+// printDryRun shows the changes a command generated into fsys, when it is
+// the overlay of a dry run, and reports whether it was.
+func printDryRun(command string, fsys genfs.WriteFS) bool {
+	mem, ok := fsys.(*genfs.MemFS)
+	if !ok {
+		return false
+	}
+	changes := mem.Changes()
+
+	if jsonOutput {
+		out := DryRunOutput{Command: command, Changes: []DryRunChange{}}
+		for _, c := range changes {
+			out.Changes = append(out.Changes, DryRunChange{Op: string(c.Op), Path: c.Path, Diff: c.Diff()})
+		}
+		printSuccess(out)
+		return true
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("\n  %s Dry run: no files were written\n\n", yellow("→"))
+	if len(changes) == 0 {
+		fmt.Printf("    No changes\n\n")
+		return true
+	}
+	for _, c := range changes {
+		fmt.Printf("  %s %s\n", cyan(c.Op), c.Path)
+	}
+	fmt.Println()
+	for _, c := range changes {
+		for _, line := range strings.Split(strings.TrimSuffix(c.Diff(), "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				fmt.Println(line)
+			case strings.HasPrefix(line, "+"):
+				fmt.Println(green(line))
+			case strings.HasPrefix(line, "-"):
+				fmt.Println(red(line))
+			case strings.HasPrefix(line, "@@"):
+				fmt.Println(cyan(line))
+			default:
+				fmt.Println(line)
+			}
+		}
+		fmt.Println()
+	}
+	return true
+}
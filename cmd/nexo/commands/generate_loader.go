@@ -41,10 +41,12 @@ func init() {
 func runGenerateLoader(cmd *cobra.Command, args []string) {
 	path := args[0]
 
+	fsys := generateFS()
 	result, err := generator.GenerateLoader(generator.LoaderConfig{
 		Path:     path,
 		DataType: loaderDataType,
 		AppDir:   loaderAppDir,
+		FS:       fsys,
 	})
 
 	if err != nil {
@@ -57,6 +59,10 @@ func runGenerateLoader(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if printDryRun("generate loader", fsys) {
+		return
+	}
+
 	if jsonOutput {
 		printSuccess(GenerateOutput{
 			Command: "generate loader",
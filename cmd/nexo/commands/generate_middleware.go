@@ -44,11 +44,13 @@ func init() {
 func runGenerateMiddleware(cmd *cobra.Command, args []string) {
 	name := args[0]
 
+	fsys := generateFS()
 	result, err := generator.GenerateMiddleware(generator.MiddlewareConfig{
 		Name:     name,
 		Path:     middlewarePath,
 		Template: middlewareTemplate,
 		AppDir:   middlewareAppDir,
+		FS:       fsys,
 	})
 
 	if err != nil {
@@ -61,6 +63,10 @@ func runGenerateMiddleware(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if printDryRun("generate middleware", fsys) {
+		return
+	}
+
 	if jsonOutput {
 		printSuccess(GenerateOutput{
 			Command: "generate middleware",
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var generateMoveCmd = &cobra.Command{
+	Use:   "move <from> <to>",
+	Short: "Move a route or page directory",
+	Long: `Move a route or page directory, with everything below it, and regenerate
+the routes file.
+
+Packages named after their directory are renamed to match the new one, and
+imports of the moved packages are rewritten across the project. Imports that
+relied on the old package name get it as an alias.
+
+Paths are relative to the app directory; a path not found there is looked up
+in app/api, and the destination then goes in app/api too.
+
+Examples:
+  nexo generate move users/[id] accounts/[id]
+  nexo generate move api/users api/accounts
+  nexo generate move blog/[slug] posts/[slug]`,
+	Args: cobra.ExactArgs(2),
+	Run:  runGenerateMove,
+}
+
+var moveAppDir string
+
+func init() {
+	generateMoveCmd.Flags().StringVarP(&moveAppDir, "app-dir", "d", "app", "App directory")
+	generateCmd.AddCommand(generateMoveCmd)
+}
+
+func runGenerateMove(cmd *cobra.Command, args []string) {
+	red := color.New(color.FgRed).SprintFunc()
+
+	fsys := generateFS()
+	result, err := generator.MoveRoute(generator.MoveConfig{
+		From:   args[0],
+		To:     args[1],
+		AppDir: moveAppDir,
+		FS:     fsys,
+	})
+	// A dry run leaves the routes file as is
+	if err == nil && !generateDryRun {
+		err = generateRoutes(moveAppDir, false)
+	}
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		return
+	}
+
+	if printDryRun("generate move", fsys) {
+		return
+	}
+
+	if jsonOutput {
+		printSuccess(GenerateOutput{
+			Command: "generate move",
+			Path:    args[0],
+			Files:   result.Files,
+			Updated: result.Updated,
+			Pattern: result.Pattern,
+		})
+		return
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("\n  %s Moved %s\n\n", green("✓"), args[0])
+	for _, f := range result.Files {
+		fmt.Printf("    Moved to: %s\n", cyan(f))
+	}
+	for _, f := range result.Updated {
+		fmt.Printf("    Updated: %s\n", cyan(f))
+	}
+	fmt.Printf("    URL: %s\n\n", result.Pattern)
+}
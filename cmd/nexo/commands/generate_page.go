@@ -38,10 +38,12 @@ func init() {
 func runGeneratePage(cmd *cobra.Command, args []string) {
 	path := args[0]
 
+	fsys := generateFS()
 	result, err := generator.GeneratePage(generator.PageConfig{
 		Path:       path,
 		AppDir:     pageAppDir,
 		WithLayout: pageWithLayout,
+		FS:         fsys,
 	})
 
 	if err != nil {
@@ -54,6 +56,10 @@ func runGeneratePage(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if printDryRun("generate page", fsys) {
+		return
+	}
+
 	if jsonOutput {
 		printSuccess(GenerateOutput{
 			Command: "generate page",
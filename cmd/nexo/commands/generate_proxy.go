@@ -19,6 +19,7 @@ Available templates:
   rate-limit   - Simple IP-based rate limiting
   maintenance  - Maintenance mode with allowed IPs
   redirect-www - WWW/non-WWW redirect handling
+  ab-test      - A/B test rewriting visitors to a variant
 
 The proxy runs before route matching and can:
   - Rewrite URLs (A/B testing, feature flags)
@@ -39,15 +40,17 @@ var (
 )
 
 func init() {
-	generateProxyCmd.Flags().StringVarP(&proxyTemplate, "template", "t", "blank", "Template: blank, auth-check, rate-limit, maintenance, redirect-www")
+	generateProxyCmd.Flags().StringVarP(&proxyTemplate, "template", "t", "blank", "Template: blank, auth-check, rate-limit, maintenance, redirect-www, ab-test")
 	generateProxyCmd.Flags().StringVarP(&proxyAppDir, "app-dir", "d", "app", "App directory")
 	generateCmd.AddCommand(generateProxyCmd)
 }
 
 func runGenerateProxy(cmd *cobra.Command, args []string) {
+	fsys := generateFS()
 	result, err := generator.GenerateProxy(generator.ProxyConfig{
 		Template: proxyTemplate,
 		AppDir:   proxyAppDir,
+		FS:       fsys,
 	})
 
 	if err != nil {
@@ -60,6 +63,10 @@ func runGenerateProxy(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if printDryRun("generate proxy", fsys) {
+		return
+	}
+
 	if jsonOutput {
 		printSuccess(GenerateOutput{
 			Command: "generate proxy",
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var generateRemoveCmd = &cobra.Command{
+	Use:     "remove <path>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a route or page directory",
+	Long: `Remove a route or page directory, with everything below it, and regenerate
+the routes file.
+
+The directory is kept when other code imports its packages; --force removes
+it anyway. Paths are resolved like with nexo generate move.
+
+Examples:
+  nexo generate remove users/[id]
+  nexo generate remove dashboard --force`,
+	Args: cobra.ExactArgs(1),
+	Run:  runGenerateRemove,
+}
+
+var (
+	removeAppDir string
+	removeForce  bool
+)
+
+func init() {
+	generateRemoveCmd.Flags().StringVarP(&removeAppDir, "app-dir", "d", "app", "App directory")
+	generateRemoveCmd.Flags().BoolVar(&removeForce, "force", false, "Remove the directory even when other packages import it")
+	generateCmd.AddCommand(generateRemoveCmd)
+}
+
+func runGenerateRemove(cmd *cobra.Command, args []string) {
+	red := color.New(color.FgRed).SprintFunc()
+
+	fsys := generateFS()
+	result, err := generator.RemoveRoute(generator.RemoveConfig{
+		Path:   args[0],
+		AppDir: removeAppDir,
+		Force:  removeForce,
+		FS:     fsys,
+	})
+	// A dry run leaves the routes file as is
+	if err == nil && !generateDryRun {
+		err = generateRoutes(removeAppDir, false)
+	}
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		return
+	}
+
+	if printDryRun("generate remove", fsys) {
+		return
+	}
+
+	if jsonOutput {
+		printSuccess(GenerateOutput{
+			Command: "generate remove",
+			Path:    args[0],
+			Files:   result.Files,
+			Pattern: result.Pattern,
+		})
+		return
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("\n  %s Removed %s\n\n", green("✓"), args[0])
+	for _, f := range result.Files {
+		fmt.Printf("    Removed: %s\n", cyan(f))
+	}
+	fmt.Printf("    URL: %s (no longer served)\n\n", result.Pattern)
+}
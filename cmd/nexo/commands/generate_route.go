@@ -12,7 +12,10 @@ import (
 var generateRouteCmd = &cobra.Command{
 	Use:   "route <path>",
 	Short: "Generate a new route",
-	Long: `Generate a new route file with handler functions.
+	Long: `Generate a new route file with handler functions. With --split, each
+method gets its own file (get.go, post.go, ...) in the route directory.
+With --add-method, methods the route already handles are skipped and the
+others are appended to the existing route file, leaving its code untouched.
 
 The path supports dynamic segments:
   [param]      - Dynamic parameter (e.g., users/[id])
@@ -24,7 +27,9 @@ Examples:
   nexo generate route users              # GET /api/users
   nexo generate route users/[id]         # Dynamic route /api/users/:id
   nexo generate route posts/[...slug]    # Catch-all /api/posts/*
-  nexo generate route users/[id] --methods GET,PUT,DELETE`,
+  nexo generate route users/[id] --methods GET,PUT,DELETE
+  nexo generate route users/[id] --methods GET,PUT,DELETE --split  # get.go, put.go, delete.go
+  nexo generate route users/[id] --methods PUT,DELETE --add-method # add to existing route.go`,
 	Args: cobra.ExactArgs(1),
 	Run:  runGenerateRoute,
 }
@@ -32,11 +37,15 @@ Examples:
 var (
 	routeMethods string
 	routeAppDir  string
+	routeSplit   bool
+	routeAdd     bool
 )
 
 func init() {
 	generateRouteCmd.Flags().StringVarP(&routeMethods, "methods", "m", "GET", "HTTP methods (comma-separated: GET,POST,PUT,DELETE)")
 	generateRouteCmd.Flags().StringVarP(&routeAppDir, "app-dir", "d", "app", "App directory")
+	generateRouteCmd.Flags().BoolVar(&routeSplit, "split", false, "Write one file per method (get.go, post.go) instead of route.go")
+	generateRouteCmd.Flags().BoolVar(&routeAdd, "add-method", false, "Add missing handlers to an existing route instead of failing")
 	generateCmd.AddCommand(generateRouteCmd)
 }
 
@@ -49,10 +58,14 @@ func runGenerateRoute(cmd *cobra.Command, args []string) {
 		methods[i] = strings.TrimSpace(m)
 	}
 
+	fsys := generateFS()
 	result, err := generator.GenerateRoute(generator.RouteConfig{
-		Path:    path,
-		Methods: methods,
-		AppDir:  routeAppDir,
+		Path:      path,
+		Methods:   methods,
+		AppDir:    routeAppDir,
+		Split:     routeSplit,
+		AddMethod: routeAdd,
+		FS:        fsys,
 	})
 
 	if err != nil {
@@ -65,11 +78,17 @@ func runGenerateRoute(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if printDryRun("generate route", fsys) {
+		return
+	}
+
 	if jsonOutput {
 		printSuccess(GenerateOutput{
 			Command: "generate route",
 			Path:    path,
 			Files:   result.Files,
+			Updated: result.Updated,
+			Skipped: result.Skipped,
 			Pattern: result.Pattern,
 			Methods: methods,
 		})
@@ -83,6 +102,12 @@ func runGenerateRoute(cmd *cobra.Command, args []string) {
 	for _, f := range result.Files {
 		fmt.Printf("    Created: %s\n", cyan(f))
 	}
+	for _, f := range result.Updated {
+		fmt.Printf("    Updated: %s\n", cyan(f))
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("    Skipped: %s (already handled)\n", strings.Join(result.Skipped, ", "))
+	}
 	fmt.Printf("    Pattern: %s\n", result.Pattern)
 	fmt.Printf("    Methods: %s\n\n", strings.Join(methods, ", "))
 }
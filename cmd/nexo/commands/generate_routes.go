@@ -25,7 +25,8 @@ Examples:
   nexo generate routes                    Generate routes
   nexo generate routes --app-dir custom   Use custom app directory
   nexo generate routes --output .gen      Output to custom directory
-  nexo generate routes --json             Output JSON for automation`,
+  nexo generate routes --json             Output JSON for automation
+  nexo generate routes --dry-run          Show the changes without writing them`,
 	Run: runGenerateRoutes,
 }
 
@@ -65,11 +66,24 @@ func runGenerateRoutes(cmd *cobra.Command, args []string) {
 	}
 
 	// Create generator
-	gen := scanner.NewGenerator(scanner.GeneratorConfig{
+	fsys := generateFS()
+	gen, err := newGenerator(scanner.GeneratorConfig{
 		ModuleName: moduleName,
 		AppDir:     generateAppDir,
 		OutputDir:  generateOutputDir,
+		FS:         fsys,
 	})
+	if err != nil {
+		if jsonOutput {
+			outputJSON(map[string]any{
+				"error":   "failed to load plugins",
+				"details": err.Error(),
+			})
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
 
 	// Generate
 	if !jsonOutput {
@@ -89,6 +103,10 @@ func runGenerateRoutes(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if printDryRun("generate routes", fsys) {
+		return
+	}
+
 	// Output results
 	if jsonOutput {
 		outputJSON(map[string]any{
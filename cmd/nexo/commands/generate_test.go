@@ -91,8 +91,8 @@ func TestGenerateRoute_CatchAllPath(t *testing.T) {
 	}
 
 	// Check pattern is catch-all
-	if result.Pattern != "/api/docs/*" {
-		t.Errorf("Pattern = %q, want /api/docs/*", result.Pattern)
+	if result.Pattern != "/api/docs/{slug...}" {
+		t.Errorf("Pattern = %q, want /api/docs/{slug...}", result.Pattern)
 	}
 }
 
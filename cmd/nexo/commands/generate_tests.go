@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var generateTestsCmd = &cobra.Command{
+	Use:   "tests <path>",
+	Short: "Generate tests for a route",
+	Long: `Generate route_test.go for the handlers of a route.
+
+Each handler gets a table-driven test whose responses are compared with
+golden files in testdata/. Handlers binding a request body also get a fuzz
+target checking that no body makes them panic or respond with a 5xx, seeded
+with an example of the body type.
+
+Record the golden responses once, then review them:
+  go test ./app/api/users -update
+
+Go can't import directories like users/[id], so their tests run in the
+wrapper package nexo generates for them:
+  go test ./.nexo/generated/wrappers/app_api_users_id -update
+
+Handlers taking injected dependencies are skipped.
+
+Examples:
+  nexo generate tests users
+  nexo generate tests api/users/[id]
+  go test ./app/api/users -fuzz FuzzPost`,
+	Args: cobra.ExactArgs(1),
+	Run:  runGenerateTests,
+}
+
+var testsAppDir string
+
+func init() {
+	generateTestsCmd.Flags().StringVarP(&testsAppDir, "app-dir", "d", "app", "App directory")
+	generateCmd.AddCommand(generateTestsCmd)
+}
+
+func runGenerateTests(cmd *cobra.Command, args []string) {
+	fsys := generateFS()
+	result, err := generator.GenerateTests(generator.TestsConfig{
+		Path:   args[0],
+		AppDir: testsAppDir,
+		FS:     fsys,
+	})
+
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			red := color.New(color.FgRed).SprintFunc()
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		return
+	}
+
+	if printDryRun("generate tests", fsys) {
+		return
+	}
+	// Tests of bracket directories run in their wrapper package
+	if err := generator.SyncAppWrappers(testsAppDir); err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			red := color.New(color.FgRed).SprintFunc()
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		return
+	}
+
+	if jsonOutput {
+		printSuccess(GenerateOutput{
+			Command: "generate tests",
+			Path:    args[0],
+			Files:   result.Files,
+			Skipped: result.Skipped,
+			Pattern: result.Pattern,
+		})
+		return
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("\n  %s Generated tests for %s\n\n", green("✓"), cyan(result.Pattern))
+	for _, f := range result.Files {
+		fmt.Printf("    Created: %s\n", cyan(f))
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("    %s %s (injected dependencies)\n", yellow("Skipped:"), strings.Join(result.Skipped, ", "))
+	}
+	fmt.Printf("\n  Record the golden responses: %s\n\n", cyan("go test ./"+filepath.ToSlash(generator.PackageDir(filepath.Dir(result.Files[0])))+" -update"))
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var generateWebhookCmd = &cobra.Command{
+	Use:   "webhook <provider>",
+	Short: "Generate a webhook receiver",
+	Long: `Generate a webhook receiver in app/webhooks/<provider>.
+
+The generated middleware.go verifies the provider's signature with the
+secret read from the environment, rejects replayed deliveries and skips
+events that were already handled. The generated route.go handles the
+events at POST /webhooks/<provider>.
+
+Known providers:
+  stripe   - Stripe-Signature, secret in STRIPE_WEBHOOK_SECRET
+  github   - X-Hub-Signature-256, secret in GITHUB_WEBHOOK_SECRET
+  slack    - X-Slack-Signature, secret in SLACK_SIGNING_SECRET
+  shopify  - X-Shopify-Hmac-Sha256, secret in SHOPIFY_WEBHOOK_SECRET
+  standard - Standard Webhooks (Svix), secret in WEBHOOK_SECRET
+
+Other providers get an HMAC-SHA256 verifier of the X-Signature header,
+with the secret in <PROVIDER>_WEBHOOK_SECRET.
+
+Examples:
+  nexo generate webhook stripe
+  nexo generate webhook github
+  nexo generate webhook acme`,
+	Args: cobra.ExactArgs(1),
+	Run:  runGenerateWebhook,
+}
+
+var webhookAppDir string
+
+func init() {
+	generateWebhookCmd.Flags().StringVarP(&webhookAppDir, "app-dir", "d", "app", "App directory")
+	generateCmd.AddCommand(generateWebhookCmd)
+}
+
+func runGenerateWebhook(cmd *cobra.Command, args []string) {
+	fsys := generateFS()
+	result, err := generator.GenerateWebhook(generator.WebhookConfig{
+		Provider: args[0],
+		AppDir:   webhookAppDir,
+		FS:       fsys,
+	})
+
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			red := color.New(color.FgRed).SprintFunc()
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		return
+	}
+
+	if printDryRun("generate webhook", fsys) {
+		return
+	}
+
+	if jsonOutput {
+		printSuccess(GenerateOutput{
+			Command: "generate webhook",
+			Pattern: result.Pattern,
+			Files:   result.Files,
+		})
+		return
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("\n  %s Generated webhook: POST %s\n\n", green("✓"), cyan(result.Pattern))
+	for _, f := range result.Files {
+		fmt.Printf("    Created: %s\n", cyan(f))
+	}
+	fmt.Println()
+}
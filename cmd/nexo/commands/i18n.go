@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var i18nCmd = &cobra.Command{
+	Use:   "i18n",
+	Short: "Manage translation catalogs",
+	Long: `Manage the message catalogs in locales/.
+
+Commands:
+  nexo i18n extract    Add translatable strings to the catalogs`,
+}
+
+var i18nExtractCmd = &cobra.Command{
+	Use:   "extract",
+	Short: "Add translatable strings to the catalogs",
+	Long: `Scan templ and Go files for translation calls and add missing keys to the
+JSON catalogs in locales/.
+
+Calls with a string literal key are found: i18n.T(ctx, "home.title") in templ
+components and c.T("orders.created", id) in handlers. New keys are added with
+an empty translation, which falls back to the default locale at runtime.
+TOML catalogs are checked but not rewritten.
+
+Examples:
+  nexo i18n extract
+  nexo i18n extract --locale en --locale es
+  nexo i18n extract --check`,
+	Run: runI18nExtract,
+}
+
+var (
+	i18nSourceDir  string
+	i18nLocalesDir string
+	i18nLocales    []string
+	i18nCheck      bool
+)
+
+func init() {
+	i18nExtractCmd.Flags().StringVar(&i18nSourceDir, "dir", ".", "Directory to scan for translatable strings")
+	i18nExtractCmd.Flags().StringVar(&i18nLocalesDir, "locales-dir", "locales", "Directory containing the catalogs")
+	i18nExtractCmd.Flags().StringSliceVar(&i18nLocales, "locale", nil, "Catalogs to update (default: every catalog, or en)")
+	i18nExtractCmd.Flags().BoolVar(&i18nCheck, "check", false, "Report missing keys without writing, and exit 1 if any")
+
+	i18nCmd.AddCommand(i18nExtractCmd)
+	rootCmd.AddCommand(i18nCmd)
+}
+
+func runI18nExtract(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	result, err := extractCatalogs(i18nSourceDir, i18nLocalesDir, i18nLocales, !i18nCheck)
+	if err != nil {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	missing := 0
+	for _, c := range result.Catalogs {
+		missing += len(c.Missing)
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+	} else {
+		fmt.Printf("\n  %s i18n extract\n\n", cyan("Nexo"))
+		fmt.Printf("  Found %d translatable strings\n\n", result.Keys)
+		for _, c := range result.Catalogs {
+			switch {
+			case len(c.Missing) == 0:
+				fmt.Printf("  %s %s\n", green("✓"), c.File)
+			case c.Updated:
+				fmt.Printf("  %s %s: added %d keys\n", green("✓"), c.File, len(c.Missing))
+			default:
+				fmt.Printf("  %s %s: %d missing keys\n", yellow("!"), c.File, len(c.Missing))
+				for _, key := range c.Missing {
+					fmt.Printf("      %s\n", dim(key))
+				}
+			}
+		}
+		fmt.Println()
+	}
+
+	if i18nCheck && missing > 0 {
+		os.Exit(1)
+	}
+}
+
+// extractCatalogs finds the translation keys under root and adds the missing
+// ones to the catalogs in localesDir when write is set. Only JSON catalogs
+// are written; locales without a catalog get a new JSON file.
+func extractCatalogs(root, localesDir string, locales []string, write bool) (*I18nExtractOutput, error) {
+	messages, err := i18n.Extract(os.DirFS(root))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	files, err := catalogFiles(localesDir, locales)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &I18nExtractOutput{Keys: len(messages), Catalogs: []I18nCatalogOutput{}}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		catalog := map[string]string{}
+		if len(data) > 0 {
+			if catalog, err = i18n.ParseCatalog(file, data); err != nil {
+				return nil, err
+			}
+		}
+
+		missing := i18n.MissingKeys(catalog, messages)
+		out := I18nCatalogOutput{File: file, Missing: make([]string, 0, len(missing))}
+		for _, m := range missing {
+			out.Missing = append(out.Missing, m.Key)
+		}
+
+		if write && len(missing) > 0 && filepath.Ext(file) == ".json" {
+			updated, err := i18n.AddKeys(data, missing)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update %s: %w", file, err)
+			}
+			if err := os.MkdirAll(localesDir, 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(file, updated, 0644); err != nil {
+				return nil, err
+			}
+			out.Updated = true
+		}
+		result.Catalogs = append(result.Catalogs, out)
+	}
+
+	return result, nil
+}
+
+// catalogFiles returns the catalog files for locales, or every catalog in
+// dir when no locales are given (en.json if there are none).
+func catalogFiles(dir string, locales []string) ([]string, error) {
+	existing := map[string]string{} // locale -> file
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && i18n.IsCatalogFile(e.Name()) {
+			existing[i18n.LocaleFromFile(e.Name())] = filepath.Join(dir, e.Name())
+		}
+	}
+
+	if len(locales) == 0 {
+		for l := range existing {
+			locales = append(locales, l)
+		}
+		if len(locales) == 0 {
+			locales = []string{"en"}
+		}
+	}
+
+	files := make([]string, 0, len(locales))
+	for _, l := range locales {
+		if file, ok := existing[i18n.LocaleFromFile(strings.TrimSpace(l))]; ok {
+			files = append(files, file)
+		} else {
+			files = append(files, filepath.Join(dir, strings.TrimSpace(l)+".json"))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+)
+
+func TestExtractCatalogs(t *testing.T) {
+	root := t.TempDir()
+	pageDir := filepath.Join(root, "app")
+	localesDir := filepath.Join(root, "locales")
+	for _, dir := range []string{pageDir, localesDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page := `templ Page() { <h1>{ i18n.T(ctx, "home.title") }</h1><p>{ i18n.T(ctx, "home.intro") }</p> }`
+	files := map[string]string{
+		filepath.Join(pageDir, "page.templ"): page,
+		filepath.Join(localesDir, "en.json"): `{"home": {"title": "Welcome"}}`,
+		filepath.Join(localesDir, "es.toml"): "[home]\ntitle = \"Bienvenido\"\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("check", func(t *testing.T) {
+		result, err := extractCatalogs(root, localesDir, nil, false)
+		if err != nil {
+			t.Fatalf("extractCatalogs() error = %v", err)
+		}
+		if result.Keys != 2 || len(result.Catalogs) != 2 {
+			t.Fatalf("result = %+v, want 2 keys in 2 catalogs", result)
+		}
+		for _, c := range result.Catalogs {
+			if c.Updated || len(c.Missing) != 1 || c.Missing[0] != "home.intro" {
+				t.Errorf("%s = %+v, want home.intro missing and not updated", c.File, c)
+			}
+		}
+	})
+
+	t.Run("write", func(t *testing.T) {
+		result, err := extractCatalogs(root, localesDir, []string{"en", "es", "fr"}, true)
+		if err != nil {
+			t.Fatalf("extractCatalogs() error = %v", err)
+		}
+
+		updated := map[string]bool{}
+		for _, c := range result.Catalogs {
+			updated[filepath.Base(c.File)] = c.Updated
+		}
+		want := map[string]bool{"en.json": true, "es.toml": false, "fr.json": true}
+		for file, w := range want {
+			if updated[file] != w {
+				t.Errorf("%s updated = %v, want %v", file, updated[file], w)
+			}
+		}
+
+		data, err := os.ReadFile(filepath.Join(localesDir, "en.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := i18n.ParseCatalog("en.json", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if catalog["home.title"] != "Welcome" {
+			t.Errorf("home.title = %q, want the existing translation kept", catalog["home.title"])
+		}
+		if v, ok := catalog["home.intro"]; !ok || v != "" {
+			t.Errorf("home.intro = %q, %v; want an empty entry", v, ok)
+		}
+	})
+}
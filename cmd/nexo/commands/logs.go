@@ -5,32 +5,41 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
-	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+	"github.com/abdul-hamid-achik/nexo/pkg/deploy"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logsFollow bool
-	logsTail   int
-	logsSince  string
-	logsLevel  string
+	logsFollow   bool
+	logsTail     int
+	logsSince    string
+	logsLevel    string
+	logsSource   string
+	logsProvider string
 )
 
 var logsCmd = &cobra.Command{
 	Use:   "logs <app>",
 	Short: "View application logs",
-	Long: `View and stream logs from a Nexo Cloud application.
+	Long: `View and stream logs from a deployed application.
+
+With -f, logs are streamed until Ctrl+C. Dropped connections are retried
+with backoff, without repeating lines already shown. With --json, follow
+mode prints one JSON object per line.
 
 Examples:
-  nexo logs my-app              # View recent logs
-  nexo logs my-app -f           # Follow/stream logs
-  nexo logs my-app --tail 100   # Last 100 lines
-  nexo logs my-app --since 1h   # Logs from the last hour
-  nexo logs my-app --level error # Only error logs`,
+  nexo logs my-app                    # View recent logs
+  nexo logs my-app -f                 # Follow/stream logs
+  nexo logs my-app --tail 100         # Last 100 lines
+  nexo logs my-app --since 1h         # Logs from the last hour
+  nexo logs my-app --level warn,error # Only warnings and errors
+  nexo logs my-app -f --source app    # Only the app's own output
+  nexo logs my-app -f --json | jq .   # Stream JSON lines`,
 	Args: cobra.ExactArgs(1),
 	Run:  runLogs,
 }
@@ -39,7 +48,9 @@ func init() {
 	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow/stream logs")
 	logsCmd.Flags().IntVar(&logsTail, "tail", 100, "Number of lines to show")
 	logsCmd.Flags().StringVar(&logsSince, "since", "", "Show logs since duration (e.g., 1h, 30m, 24h)")
-	logsCmd.Flags().StringVar(&logsLevel, "level", "", "Filter by log level (debug, info, warn, error)")
+	logsCmd.Flags().StringVar(&logsLevel, "level", "", "Filter by log level, comma-separated (debug, info, warn, error)")
+	logsCmd.Flags().StringVar(&logsSource, "source", "", "Filter by source, comma-separated (app, system, deploy)")
+	logsCmd.Flags().StringVar(&logsProvider, "provider", "", "Deploy provider: nexo or fly (default: deploy.provider in nexo.yaml, or nexo)")
 
 	rootCmd.AddCommand(logsCmd)
 }
@@ -47,6 +58,7 @@ func init() {
 func runLogs(cmd *cobra.Command, args []string) {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
 
 	appName := args[0]
@@ -55,7 +67,7 @@ func runLogs(cmd *cobra.Command, args []string) {
 		fmt.Printf("\n  %s Logs - %s\n\n", cyan("Nexo"), cyan(appName))
 	}
 
-	client, err := cloud.NewClientFromCredentials()
+	provider, err := loadDeployProvider(logsProvider)
 	if err != nil {
 		if jsonOutput {
 			printJSONError(err)
@@ -80,133 +92,102 @@ func runLogs(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	opts := cloud.LogOptions{
+	filter := deploy.ParseLogFilter(logsLevel, logsSource)
+	opts := deploy.LogOptions{
 		Follow: logsFollow,
 		Tail:   logsTail,
 		Since:  since,
-		Level:  logsLevel,
+	}
+	// A single level can be filtered by the provider too
+	if len(filter.Levels) == 1 {
+		opts.Level = filter.Levels[0]
 	}
 
 	if logsFollow {
-		// Stream logs
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
-		// Handle Ctrl+C
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		go func() {
-			<-sigCh
-			cancel()
-		}()
+		// Stream logs until Ctrl+C
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
 		if !jsonOutput {
 			fmt.Printf("  %s Streaming logs (Ctrl+C to stop)...\n\n", dim("->"))
 		}
 
-		logCh, errCh, err := client.StreamLogs(ctx, appName, opts)
-		if err != nil {
-			if jsonOutput {
-				printJSONError(fmt.Errorf("failed to stream logs: %w", err))
-			} else {
-				fmt.Printf("  %s Failed to stream logs: %v\n", red("Error:"), err)
-			}
-			os.Exit(1)
-		}
-
-		var allLogs []LogLineOutput
-
-		for {
-			select {
-			case log, ok := <-logCh:
-				if !ok {
-					if jsonOutput && len(allLogs) > 0 {
-						printSuccess(LogsOutput{
-							App:  appName,
-							Logs: allLogs,
-						})
-					}
-					return
+		lines := deploy.Follow(ctx, provider, appName, deploy.FollowOptions{
+			LogOptions: opts,
+			Filter:     filter,
+			OnReconnect: func(err error, wait time.Duration) {
+				reason := "stream closed"
+				if err != nil {
+					reason = err.Error()
 				}
-
 				if jsonOutput {
-					allLogs = append(allLogs, LogLineOutput{
-						Timestamp: log.Timestamp.Format(time.RFC3339),
-						Level:     log.Level,
-						Message:   log.Message,
-						Source:    log.Source,
-					})
+					fmt.Fprintf(os.Stderr, "log stream: %s, reconnecting in %s\n", reason, wait)
 				} else {
-					printLogLine(log)
-				}
-
-			case err := <-errCh:
-				if err != nil {
-					if jsonOutput {
-						printJSONError(fmt.Errorf("log stream error: %w", err))
-					} else {
-						fmt.Printf("\n  %s Log stream error: %v\n", red("Error:"), err)
-					}
-				}
-				return
-
-			case <-ctx.Done():
-				if jsonOutput && len(allLogs) > 0 {
-					printSuccess(LogsOutput{
-						App:  appName,
-						Logs: allLogs,
-					})
+					fmt.Printf("  %s %s, reconnecting in %s...\n", yellow("!"), reason, wait)
 				}
-				return
-			}
-		}
-	} else {
-		// Fetch logs (non-streaming)
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		logs, err := client.GetLogs(ctx, appName, opts)
-		if err != nil {
+			},
+		})
+		for log := range lines {
 			if jsonOutput {
-				printJSONError(fmt.Errorf("failed to get logs: %w", err))
+				printJSONLine(logLineOutput(log))
 			} else {
-				fmt.Printf("  %s Failed to get logs: %v\n", red("Error:"), err)
+				printLogLine(log)
 			}
-			os.Exit(1)
 		}
+		return
+	}
+
+	// Fetch logs (non-streaming)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
+	logs, err := provider.Logs(ctx, appName, opts)
+	if err != nil {
 		if jsonOutput {
-			output := LogsOutput{
-				App:  appName,
-				Logs: make([]LogLineOutput, len(logs)),
-			}
-			for i, log := range logs {
-				output.Logs[i] = LogLineOutput{
-					Timestamp: log.Timestamp.Format(time.RFC3339),
-					Level:     log.Level,
-					Message:   log.Message,
-					Source:    log.Source,
-				}
-			}
-			printSuccess(output)
-			return
+			printJSONError(fmt.Errorf("failed to get logs: %w", err))
+		} else {
+			fmt.Printf("  %s Failed to get logs: %v\n", red("Error:"), err)
 		}
+		os.Exit(1)
+	}
+	logs = slices.DeleteFunc(logs, func(log deploy.LogLine) bool { return !filter.Match(log) })
 
-		if len(logs) == 0 {
-			fmt.Printf("  %s No logs found\n", dim("(empty)"))
-			return
+	if jsonOutput {
+		output := LogsOutput{
+			App:  appName,
+			Logs: make([]LogLineOutput, len(logs)),
 		}
-
-		for _, log := range logs {
-			printLogLine(log)
+		for i, log := range logs {
+			output.Logs[i] = logLineOutput(log)
 		}
+		printSuccess(output)
+		return
+	}
+
+	if len(logs) == 0 {
+		fmt.Printf("  %s No logs found\n", dim("(empty)"))
+		return
+	}
+
+	for _, log := range logs {
+		printLogLine(log)
+	}
+
+	fmt.Printf("\n  %s Showing %d log entries\n", dim("Total:"), len(logs))
+	fmt.Printf("  Use -f to stream logs in real-time\n")
+}
 
-		fmt.Printf("\n  %s Showing %d log entries\n", dim("Total:"), len(logs))
-		fmt.Printf("  Use -f to stream logs in real-time\n")
+// logLineOutput converts a log line for JSON output.
+func logLineOutput(log deploy.LogLine) LogLineOutput {
+	return LogLineOutput{
+		Timestamp: log.Timestamp.Format(time.RFC3339),
+		Level:     log.Level,
+		Message:   log.Message,
+		Source:    log.Source,
 	}
 }
 
-func printLogLine(log cloud.LogLine) {
+func printLogLine(log deploy.LogLine) {
 	dim := color.New(color.Faint).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Turn maintenance mode on or off",
+	Long: `Turn maintenance mode on or off without redeploying.
+
+Locally, "on" writes .nexo/maintenance.json (maintenance.file in nexo.yaml).
+A running app checks the file every second and, while it exists, answers
+every request with 503 and the page in app/maintenance.templ, except
+requests from allowlisted IPs and health checks.
+
+With --app, the command sets NEXO_MAINTENANCE on the deploy provider instead,
+which the app reads when it starts, so it applies from the next restart or
+deployment.
+
+Examples:
+  nexo maintenance on
+  nexo maintenance on --allow 203.0.113.7 --allow 10.0.0.0/8 --retry-after 30m
+  nexo maintenance off
+  nexo maintenance status
+  nexo maintenance on --app my-app --provider fly`,
+}
+
+var maintenanceOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn maintenance mode on",
+	Args:  cobra.NoArgs,
+	Run:   func(cmd *cobra.Command, args []string) { runMaintenance(true) },
+}
+
+var maintenanceOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn maintenance mode off",
+	Args:  cobra.NoArgs,
+	Run:   func(cmd *cobra.Command, args []string) { runMaintenance(false) },
+}
+
+var maintenanceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the maintenance file turns maintenance mode on",
+	Args:  cobra.NoArgs,
+	Run:   runMaintenanceStatus,
+}
+
+var (
+	maintenanceAllow      []string
+	maintenanceRetryAfter time.Duration
+	maintenanceFile       string
+	maintenanceApp        string
+	maintenanceProvider   string
+)
+
+func init() {
+	maintenanceCmd.PersistentFlags().StringVar(&maintenanceFile, "file", "", "Maintenance file (default: maintenance.file in nexo.yaml, or "+nexo.DefaultMaintenanceFile+")")
+	maintenanceCmd.PersistentFlags().StringVar(&maintenanceApp, "app", "", "Deployed app to toggle through its provider instead of the local file")
+	maintenanceCmd.PersistentFlags().StringVar(&maintenanceProvider, "provider", "", "Deploy provider: nexo or fly (default: deploy.provider in nexo.yaml, or nexo)")
+	maintenanceOnCmd.Flags().StringSliceVar(&maintenanceAllow, "allow", nil, "IP or CIDR that bypasses maintenance mode (repeatable)")
+	maintenanceOnCmd.Flags().DurationVar(&maintenanceRetryAfter, "retry-after", 0, "Retry-After sent to clients (default: maintenance.retry_after, or 1h)")
+
+	maintenanceCmd.AddCommand(maintenanceOnCmd, maintenanceOffCmd, maintenanceStatusCmd)
+	rootCmd.AddCommand(maintenanceCmd)
+}
+
+func runMaintenance(enabled bool) {
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	var (
+		result *MaintenanceOutput
+		err    error
+	)
+	if maintenanceApp != "" {
+		result, err = setProviderMaintenance(maintenanceApp, maintenanceProvider, enabled)
+	} else if enabled {
+		result, err = enableMaintenance(maintenanceFilePath(), nexo.MaintenanceState{
+			Allow:      maintenanceAllow,
+			RetryAfter: int(maintenanceRetryAfter.Seconds()),
+			Since:      time.Now().UTC(),
+		})
+	} else {
+		result, err = disableMaintenance(maintenanceFilePath())
+	}
+	if err != nil {
+		maintenanceFail(err)
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	if result.App != "" {
+		fmt.Printf("\n  %s Maintenance mode %s for %s on %s\n", green("✓"), state, cyan(result.App), result.Provider)
+		fmt.Println("\n  Note: Changes take effect on next deployment or restart")
+		fmt.Println()
+		return
+	}
+	fmt.Printf("\n  %s Maintenance mode %s (%s)\n", green("✓"), state, cyan(result.File))
+	for _, ip := range result.Allow {
+		fmt.Printf("    Allowed: %s\n", ip)
+	}
+	fmt.Println()
+}
+
+func runMaintenanceStatus(cmd *cobra.Command, args []string) {
+	result, err := maintenanceStatus(maintenanceFilePath())
+	if err != nil {
+		maintenanceFail(err)
+	}
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+
+	state := color.New(color.FgGreen).Sprint("off")
+	if result.Enabled {
+		state = color.New(color.FgYellow).Sprint("on")
+	}
+	fmt.Printf("\n  Maintenance mode: %s (%s)\n", state, result.File)
+	if result.Since != nil {
+		fmt.Printf("    Since: %s\n", result.Since.Local().Format(time.RFC1123))
+	}
+	for _, ip := range result.Allow {
+		fmt.Printf("    Allowed: %s\n", ip)
+	}
+	fmt.Println()
+}
+
+// maintenanceFail prints err and exits.
+func maintenanceFail(err error) {
+	if jsonOutput {
+		printJSONError(err)
+	} else {
+		red := color.New(color.FgRed).SprintFunc()
+		fmt.Printf("  %s %v\n", red("Error:"), err)
+	}
+	os.Exit(1)
+}
+
+// maintenanceFilePath returns the --file flag, maintenance.file in
+// nexo.yaml, or the default file.
+func maintenanceFilePath() string {
+	if maintenanceFile != "" {
+		return maintenanceFile
+	}
+	if cfg, err := nexo.LoadConfig(""); err == nil && cfg.Maintenance.File != "" {
+		return cfg.Maintenance.File
+	}
+	return nexo.DefaultMaintenanceFile
+}
+
+// enableMaintenance writes the maintenance file, turning maintenance mode on
+// in the running app.
+func enableMaintenance(path string, state nexo.MaintenanceState) (*MaintenanceOutput, error) {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// Write atomically so the app never reads a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return maintenanceOutput(path, &state), nil
+}
+
+// disableMaintenance removes the maintenance file.
+func disableMaintenance(path string) (*MaintenanceOutput, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return maintenanceOutput(path, nil), nil
+}
+
+// maintenanceStatus reads the maintenance file.
+func maintenanceStatus(path string) (*MaintenanceOutput, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return maintenanceOutput(path, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state nexo.MaintenanceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid maintenance file %s: %w", path, err)
+	}
+	return maintenanceOutput(path, &state), nil
+}
+
+// maintenanceOutput describes the maintenance file at path; state is nil
+// when maintenance mode is off.
+func maintenanceOutput(path string, state *nexo.MaintenanceState) *MaintenanceOutput {
+	out := &MaintenanceOutput{Enabled: state != nil, File: path}
+	if state != nil {
+		out.Allow = state.Allow
+		out.RetryAfter = state.RetryAfter
+		if !state.Since.IsZero() {
+			out.Since = &state.Since
+		}
+	}
+	return out
+}
+
+// setProviderMaintenance sets NEXO_MAINTENANCE on the app's deploy provider.
+func setProviderMaintenance(app, providerName string, enabled bool) (*MaintenanceOutput, error) {
+	provider, err := loadDeployProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	value := "off"
+	if enabled {
+		value = "on"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := provider.SetEnv(ctx, app, map[string]string{"NEXO_MAINTENANCE": value}); err != nil {
+		return nil, fmt.Errorf("failed to set NEXO_MAINTENANCE: %w", err)
+	}
+	return &MaintenanceOutput{Enabled: enabled, App: app, Provider: provider.Name()}, nil
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestMaintenanceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".nexo", "maintenance.json")
+
+	status, err := maintenanceStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Enabled {
+		t.Error("maintenance mode on without a file")
+	}
+
+	since := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	if _, err := enableMaintenance(path, nexo.MaintenanceState{Allow: []string{"10.0.0.0/8"}, RetryAfter: 1800, Since: since}); err != nil {
+		t.Fatalf("enableMaintenance() error = %v", err)
+	}
+	status, err = maintenanceStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Enabled || !slices.Equal(status.Allow, []string{"10.0.0.0/8"}) || status.RetryAfter != 1800 {
+		t.Errorf("status = %+v after enabling", status)
+	}
+	if status.Since == nil || !status.Since.Equal(since) {
+		t.Errorf("Since = %v, want %v", status.Since, since)
+	}
+
+	for range 2 { // Turning it off twice is fine
+		if _, err := disableMaintenance(path); err != nil {
+			t.Fatalf("disableMaintenance() error = %v", err)
+		}
+	}
+	if status, _ := maintenanceStatus(path); status.Enabled {
+		t.Error("maintenance mode still on after disabling")
+	}
+}
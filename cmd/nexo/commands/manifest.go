@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Print the routes manifest as JSON",
+	Long: `Scan app/ and describe its routes, pages, layouts, loaders, middleware,
+proxy and bundled assets as versioned JSON, for tools outside Nexo such as
+edge proxies, API gateways and docs sites.
+
+The manifest is printed to stdout unless --output is given. To keep a copy
+up to date on every route generation, set generate.manifest in nexo.yaml:
+
+  generate:
+    manifest: routes.manifest.json
+
+Examples:
+  nexo manifest
+  nexo manifest --output routes.manifest.json`,
+	Run: runManifest,
+}
+
+var (
+	manifestOutput string
+	manifestAppDir string
+)
+
+func init() {
+	manifestCmd.Flags().StringVarP(&manifestOutput, "output", "o", "", "Output file path (default: stdout)")
+	manifestCmd.Flags().StringVarP(&manifestAppDir, "app-dir", "d", "app", "App directory to scan")
+
+	rootCmd.AddCommand(manifestCmd)
+}
+
+func runManifest(cmd *cobra.Command, args []string) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %v\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	m, err := buildRoutesManifest(manifestAppDir)
+	if err != nil {
+		fail(err)
+	}
+
+	if manifestOutput == "" {
+		data, err := m.Marshal()
+		if err != nil {
+			fail(err)
+		}
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+
+	if err := m.WriteFile(genfs.Disk, manifestOutput); err != nil {
+		fail(err)
+	}
+	if jsonOutput {
+		printSuccess(ManifestOutput{File: manifestOutput, Routes: len(m.Routes), Pages: len(m.Pages)})
+		return
+	}
+	fmt.Printf("%s Wrote %s (%d routes, %d pages)\n", green("✓"), manifestOutput, len(m.Routes), len(m.Pages))
+}
+
+// buildRoutesManifest scans appDir and returns its routes manifest, with the
+// assets of the bundler's asset manifest when one has been built.
+func buildRoutesManifest(appDir string) (*scanner.RouteManifest, error) {
+	m, err := newScanner(appDir).Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := loadBundler(false).Options()
+	assets, err := bundler.LoadManifest(os.DirFS("."), opts.Manifest, opts.StaticURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(assets.Assets) > 0 {
+		m.Assets = assets.Assets
+	}
+	return m, nil
+}
+
+// writeRoutesManifest writes the routes manifest of appDir to the path set
+// by generate.manifest in nexo.yaml, if any.
+func writeRoutesManifest(appDir string) error {
+	cfg, err := nexo.LoadConfig("")
+	if err != nil || cfg.Generate.Manifest == "" {
+		return nil
+	}
+	m, err := buildRoutesManifest(appDir)
+	if err != nil {
+		return fmt.Errorf("routes manifest: %w", err)
+	}
+	return m.WriteFile(genfs.Disk, cfg.Generate.Manifest)
+}
@@ -3,13 +3,20 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"go/format"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
 
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/charmbracelet/huh"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +25,19 @@ var newCmd = &cobra.Command{
 	Short: "Create a new Nexo project",
 	Long: `Create a new Nexo project with the recommended directory structure.
 
+Projects start from a template:
+  full      templ pages with HTMX and Tailwind, and an API (default)
+  api-only  JSON API without templ
+  static    content site of templ pages and Markdown posts
+
+A template can also be a git repository, cloned at an optional @ref. Its
+nexo-template.yaml manifest lists the text replaced with the project's
+name and module, and nexo.yaml records the template and its version.
+
+Without flags, nexo new asks for the template and options in the terminal.
+Every project has a Makefile with dev, build, test, vet and fmt targets,
+and a test of its health route.
+
 Supports Next.js-style routing with actual bracket notation:
   app/api/users/[id]/route.go  → Dynamic route
   app/api/docs/[...slug]/route.go → Catch-all route
@@ -25,7 +45,9 @@ Supports Next.js-style routing with actual bracket notation:
 
 Examples:
   nexo new myapp
-  nexo new myapp --api-only
+  nexo new myapp --template api-only --db postgres --auth
+  nexo new blog --template static --docker
+  nexo new myapp --template github.com/org/nexo-template-saas@v1.2.0
   nexo new myapp --skip-prompts`,
 	Args: cobra.ExactArgs(1),
 	Run:  runNew,
@@ -34,11 +56,164 @@ Examples:
 var (
 	apiOnly     bool
 	skipPrompts bool
+	newTemplate string
+	newDatabase string
+	newAuth     bool
+	newTailwind bool
+	newDocker   bool
+	newFromGit  string
+)
+
+// Project templates of nexo new.
+const (
+	templateFull    = "full"
+	templateAPIOnly = "api-only"
+	templateStatic  = "static"
+)
+
+var (
+	projectTemplates = []string{templateFull, templateAPIOnly, templateStatic}
+	projectDatabases = []string{"none", "postgres", "sqlite"}
 )
 
 func init() {
-	newCmd.Flags().BoolVar(&apiOnly, "api-only", false, "Create API-only project without templ")
+	newCmd.Flags().BoolVar(&apiOnly, "api-only", false, "Create API-only project without templ (same as --template api-only)")
 	newCmd.Flags().BoolVar(&skipPrompts, "skip-prompts", false, "Skip prompts and use defaults")
+	newCmd.Flags().StringVarP(&newTemplate, "template", "t", templateFull, "Project template: full, api-only, static or a git repository")
+	newCmd.Flags().StringVar(&newFromGit, "from-git", "", "Create the project from a template repository, like github.com/org/repo@v1.2.0")
+	newCmd.Flags().StringVar(&newDatabase, "db", "none", "Database: none, postgres or sqlite")
+	newCmd.Flags().BoolVar(&newAuth, "auth", false, "Add bearer token authentication for the API")
+	newCmd.Flags().BoolVar(&newTailwind, "tailwind", true, "Style pages with Tailwind CSS (full and static)")
+	newCmd.Flags().BoolVar(&newDocker, "docker", false, "Add a Dockerfile")
+}
+
+// projectOptions are the choices a new project is generated from. They
+// are also the data of the project's file templates.
+type projectOptions struct {
+	Name       string
+	ModuleName string
+	Template   string // full, api-only, static or a repository
+	Database   string // "", postgres or sqlite
+	Auth       bool
+	Tailwind   bool
+	Docker     bool
+
+	// Repo is the template repository, if Template is one
+	Repo templateSource
+}
+
+// Remote reports whether the project is created from a template
+// repository.
+func (o projectOptions) Remote() bool {
+	return o.Repo.URL != ""
+}
+
+// Templ reports whether the project has templ pages.
+func (o projectOptions) Templ() bool {
+	return o.Template != templateAPIOnly
+}
+
+// Static reports whether the project is a content site.
+func (o projectOptions) Static() bool {
+	return o.Template == templateStatic
+}
+
+// newProjectOptions returns the options set with nexo new's flags.
+func newProjectOptions(name string) (projectOptions, error) {
+	opts := projectOptions{
+		Name:       filepath.Base(name),
+		ModuleName: filepath.Base(name),
+		Template:   newTemplate,
+		Auth:       newAuth,
+		Tailwind:   newTailwind,
+		Docker:     newDocker,
+	}
+	if apiOnly {
+		opts.Template = templateAPIOnly
+	}
+	if newFromGit != "" {
+		opts.Template = newFromGit
+	}
+	if newDatabase != "none" {
+		opts.Database = newDatabase
+	}
+	return opts, opts.validate()
+}
+
+// validate checks the template and database names.
+func (o *projectOptions) validate() error {
+	if repo, ok := parseTemplateSource(o.Template); ok {
+		o.Repo = repo
+		return nil
+	}
+	if !slices.Contains(projectTemplates, o.Template) {
+		return fmt.Errorf("unknown template %q (want %s)", o.Template, strings.Join(projectTemplates, ", "))
+	}
+	if o.Database != "" && !slices.Contains(projectDatabases[1:], o.Database) {
+		return fmt.Errorf("unknown database %q (want %s)", o.Database, strings.Join(projectDatabases, ", "))
+	}
+	if !o.Templ() {
+		o.Tailwind = false
+	}
+	return nil
+}
+
+// shouldPromptNew reports whether nexo new asks for the options: in a
+// terminal, unless prompts are skipped or any option is set with a flag.
+func shouldPromptNew(cmd *cobra.Command) bool {
+	if skipPrompts || jsonOutput || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	for _, flag := range []string{"api-only", "template", "from-git", "db", "auth", "tailwind", "docker"} {
+		if cmd.Flags().Changed(flag) {
+			return false
+		}
+	}
+	return true
+}
+
+// promptProjectOptions asks for the project's template and options.
+func promptProjectOptions(opts *projectOptions) error {
+	database := "none"
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Template").
+				Options(
+					huh.NewOption("Full-stack: templ pages, HTMX and an API", templateFull),
+					huh.NewOption("API only: JSON routes, no templ", templateAPIOnly),
+					huh.NewOption("Static site: templ pages and Markdown posts", templateStatic),
+				).
+				Value(&opts.Template),
+			huh.NewSelect[string]().
+				Title("Database").
+				Options(
+					huh.NewOption("None", "none"),
+					huh.NewOption("PostgreSQL", "postgres"),
+					huh.NewOption("SQLite", "sqlite"),
+				).
+				Value(&database),
+			huh.NewConfirm().
+				Title("Add bearer token authentication?").
+				Value(&opts.Auth),
+			huh.NewConfirm().
+				Title("Add a Dockerfile?").
+				Value(&opts.Docker),
+		),
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Style pages with Tailwind CSS?").
+				Value(&opts.Tailwind),
+		).WithHideFunc(func() bool { return opts.Template == templateAPIOnly }),
+	)
+	if err := form.Run(); err != nil {
+		return err
+	}
+	opts.Database = ""
+	if database != "none" {
+		opts.Database = database
+	}
+	return opts.validate()
 }
 
 func runNew(cmd *cobra.Command, args []string) {
@@ -47,100 +222,57 @@ func runNew(cmd *cobra.Command, args []string) {
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 
-	if !jsonOutput {
-		fmt.Printf("\n  %s Creating new project: %s\n\n", cyan("Nexo"), name)
-	}
-
-	// Check if directory exists
-	if _, err := os.Stat(name); !os.IsNotExist(err) {
+	fail := func(err error) {
 		if jsonOutput {
-			printJSONError(fmt.Errorf("directory %s already exists", name))
+			printJSONError(err)
 		} else {
-			fmt.Printf("  %s Directory %s already exists\n\n", color.RedString("Error:"), name)
+			fmt.Printf("  %s %v\n\n", color.RedString("Error:"), err)
 		}
 		os.Exit(1)
 	}
 
-	// Determine project type
-	useTempl := !apiOnly
+	if !jsonOutput {
+		fmt.Printf("\n  %s Creating new project: %s\n\n", cyan("Nexo"), name)
+	}
 
-	// Create directories
-	dirs := []string{
-		filepath.Join(name, "app", "api", "health"),
-		filepath.Join(name, ".vscode"),
+	// Check if directory exists
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		fail(fmt.Errorf("directory %s already exists", name))
 	}
 
-	if useTempl {
-		dirs = append(dirs,
-			filepath.Join(name, "styles"),
-			filepath.Join(name, "static", "css"),
-		)
+	opts, err := newProjectOptions(name)
+	if err != nil {
+		fail(err)
+	}
+	if shouldPromptNew(cmd) {
+		if err := promptProjectOptions(&opts); err != nil {
+			fmt.Printf("  %s Cancelled\n\n", yellow("!"))
+			return
+		}
 	}
 
 	var createdFiles []string
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			if jsonOutput {
-				printJSONError(fmt.Errorf("failed to create %s: %w", dir, err))
-			} else {
-				fmt.Printf("  %s Failed to create %s: %v\n", color.RedString("Error:"), dir, err)
-			}
-			os.Exit(1)
-		}
+	var record nexo.TemplateConfig
+	if opts.Remote() {
 		if !jsonOutput {
-			green := color.New(color.FgGreen).SprintFunc()
-			fmt.Printf("  %s Created %s/\n", green("✓"), dir)
+			fmt.Printf("  %s Cloning %s...\n", yellow("→"), opts.Template)
 		}
-	}
-
-	// Template data
-	data := struct {
-		Name       string
-		ModuleName string
-	}{
-		Name:       name,
-		ModuleName: name,
-	}
-
-	// Create files from templates
-	files := map[string]string{
-		filepath.Join(name, "go.mod"):                           goModTmpl,
-		filepath.Join(name, "nexo.yaml"):                        nexoYamlTmpl,
-		filepath.Join(name, ".gitignore"):                       gitignoreTmpl,
-		filepath.Join(name, ".vscode", "settings.json"):         vscodeSettingsTmpl,
-		filepath.Join(name, "app", "api", "health", "route.go"): healthRouteTmpl,
-	}
-
-	// Choose main.go template based on project type
-	if useTempl {
-		files[filepath.Join(name, "main.go")] = mainGoTemplTmpl
-		files[filepath.Join(name, "app", "layout.templ")] = layoutTemplTmpl
-		files[filepath.Join(name, "app", "page.templ")] = pageTemplTmpl
-		files[filepath.Join(name, "styles", "input.css")] = tailwindInputCssTmpl
-		// Create .gitkeep for static/css (output.css will be generated)
-		files[filepath.Join(name, "static", "css", ".gitkeep")] = ""
+		createdFiles, record, err = scaffoldFromGit(name, opts.Repo, opts)
 	} else {
-		files[filepath.Join(name, "main.go")] = mainGoAPIOnlyTmpl
+		createdFiles, err = scaffoldProject(name, opts)
 	}
-
-	for path, tmplContent := range files {
-		if err := createFileFromTemplate(path, tmplContent, data); err != nil {
-			if jsonOutput {
-				printJSONError(fmt.Errorf("failed to create %s: %w", path, err))
-			} else {
-				fmt.Printf("  %s Failed to create %s: %v\n", color.RedString("Error:"), path, err)
-			}
-			os.Exit(1)
-		}
-		createdFiles = append(createdFiles, path)
-		if !jsonOutput {
-			green := color.New(color.FgGreen).SprintFunc()
+	if err != nil {
+		_ = os.RemoveAll(name)
+		fail(err)
+	}
+	if !jsonOutput {
+		for _, path := range createdFiles {
 			fmt.Printf("  %s Created %s\n", green("✓"), path)
 		}
 	}
 
 	// Install templ CLI if using templ
-	if useTempl && !skipPrompts {
+	if opts.Templ() && !opts.Remote() && !skipPrompts {
 		if !jsonOutput {
 			fmt.Printf("\n  %s Installing templ CLI...\n", yellow("→"))
 		}
@@ -164,12 +296,7 @@ func runNew(cmd *cobra.Command, args []string) {
 	// Change to project directory and run go mod tidy
 	origDir, _ := os.Getwd()
 	if err := os.Chdir(name); err != nil {
-		if jsonOutput {
-			printJSONError(err)
-		} else {
-			fmt.Printf("  %s Failed to change directory: %v\n", color.RedString("Error:"), err)
-		}
-		os.Exit(1)
+		fail(fmt.Errorf("failed to change directory: %w", err))
 	}
 
 	// Fetch nexo module
@@ -177,10 +304,13 @@ func runNew(cmd *cobra.Command, args []string) {
 		fmt.Printf("  %s Fetching nexo module...\n", yellow("→"))
 	}
 
-	getCmd := exec.Command("go", "get", "github.com/abdul-hamid-achik/nexo@latest")
-	if err := getCmd.Run(); err != nil {
-		if !jsonOutput {
-			fmt.Printf("  %s Failed to fetch nexo module: %v\n", yellow("Warning:"), err)
+	// Template repositories keep the nexo version they pin
+	if !opts.Remote() {
+		getCmd := exec.Command("go", "get", "github.com/abdul-hamid-achik/nexo@latest")
+		if err := getCmd.Run(); err != nil {
+			if !jsonOutput {
+				fmt.Printf("  %s Failed to fetch nexo module: %v\n", yellow("Warning:"), err)
+			}
 		}
 	}
 
@@ -191,6 +321,31 @@ func runNew(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Compile templates and write nexo_routes.go, so the project builds
+	// with go build right away
+	if opts.Templ() {
+		_ = exec.Command("templ", "generate").Run()
+	}
+	if _, err := generator.ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil && !jsonOutput {
+		fmt.Printf("  %s Route generation failed: %v\n", yellow("Warning:"), err)
+	}
+
+	// The Dockerfile pins the nexo and templ versions of go.mod
+	if opts.Docker {
+		result, err := dockerInit(false)
+		if err != nil {
+			_ = os.Chdir(origDir)
+			fail(err)
+		}
+		for _, f := range result.Files {
+			path := filepath.Join(name, f)
+			createdFiles = append(createdFiles, path)
+			if !jsonOutput {
+				fmt.Printf("  %s Created %s\n", green("✓"), path)
+			}
+		}
+	}
+
 	// Change back
 	_ = os.Chdir(origDir)
 
@@ -199,248 +354,139 @@ func runNew(cmd *cobra.Command, args []string) {
 		result := map[string]any{
 			"name":      name,
 			"files":     createdFiles,
-			"type":      "full",
+			"type":      opts.Template,
+			"database":  opts.Database,
+			"auth":      opts.Auth,
+			"tailwind":  opts.Tailwind,
+			"docker":    opts.Docker,
 			"nextSteps": []string{"cd " + name, "nexo dev"},
 		}
-		if apiOnly {
-			result["type"] = "api-only"
+		if opts.Remote() {
+			result["type"] = "git"
+			result["template"] = map[string]string{"source": record.Source, "version": record.Version, "commit": record.Commit}
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		_ = enc.Encode(result)
 	} else {
 		fmt.Printf("\n  %s Project created successfully!\n\n", green("✓"))
+		if opts.Remote() {
+			fmt.Printf("  Template %s %s\n\n", cyan(record.Source), record.Version)
+		}
 		fmt.Printf("  Next steps:\n")
 		fmt.Printf("    %s cd %s\n", cyan("$"), name)
 		fmt.Printf("    %s nexo dev\n\n", cyan("$"))
+		fmt.Printf("  Run the tests with %s\n\n", cyan("make test"))
 	}
 }
 
-func createFileFromTemplate(path, tmplContent string, data any) error {
-	// Create parent directory if needed
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
+// projectFiles returns the templates of the files of a new project by
+// path relative to the project directory. Empty templates are empty files.
+func projectFiles(opts projectOptions) map[string]string {
+	files := map[string]string{
+		"go.mod":                       goModTmpl,
+		"nexo.yaml":                    nexoYamlTmpl,
+		".gitignore":                   gitignoreTmpl,
+		".vscode/settings.json":        vscodeSettingsTmpl,
+		"Makefile":                     makefileTmpl,
+		"main.go":                      mainGoTmpl,
+		"app/api/health/route.go":      healthRouteTmpl,
+		"app/api/health/route_test.go": healthRouteTestTmpl,
+		"static/.gitkeep":              "",
+
+		// Pages of the full and static templates
+		"app/layout.templ":    layoutTemplTmpl,
+		"app/page.templ":      pageTemplTmpl,
+		"styles/input.css":    tailwindInputCssTmpl,
+		"static/css/.gitkeep": "", // output.css is generated
+
+		// Content of the static template
+		"app/about/page.templ":        aboutPageTemplTmpl,
+		"content/blog/hello-world.md": helloWorldPostTmpl,
+
+		// --db
+		"internal/db/db.go":      dbTmpl,
+		"internal/db/db_test.go": dbTestTmpl,
+		".env":                   envTmpl,
+
+		// --auth
+		"internal/auth/auth.go":      authTmpl,
+		"internal/auth/auth_test.go": authTestTmpl,
+		"app/api/me/route.go":        meRouteTmpl,
 	}
 
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+	if !opts.Templ() {
+		delete(files, "app/layout.templ")
+		delete(files, "app/page.templ")
 	}
-	defer func() { _ = f.Close() }()
-
-	// Empty content means just create the file
-	if tmplContent == "" {
-		return nil
+	if !opts.Tailwind {
+		delete(files, "styles/input.css")
+		delete(files, "static/css/.gitkeep")
 	}
-
-	tmpl, err := template.New("file").Parse(tmplContent)
-	if err != nil {
-		return err
+	if !opts.Static() {
+		delete(files, "app/about/page.templ")
+		delete(files, "content/blog/hello-world.md")
 	}
-
-	return tmpl.Execute(f, data)
-}
-
-// --- Templates ---
-
-var mainGoTemplTmpl = strings.TrimSpace(`
-package main
-
-import (
-	"log"
-	"os"
-
-	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
-)
-
-func main() {
-	app := nexo.New()
-
-	// Serve static files
-	app.Static("/static", "static")
-
-	// Run the application
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	if opts.Database == "" {
+		delete(files, "internal/db/db.go")
+		delete(files, "internal/db/db_test.go")
+		delete(files, ".env")
 	}
-
-	log.Printf("Starting server on http://localhost:%s", port)
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatal(err)
+	if !opts.Auth {
+		delete(files, "internal/auth/auth.go")
+		delete(files, "internal/auth/auth_test.go")
+		delete(files, "app/api/me/route.go")
 	}
+	return files
 }
-`) + "\n"
-
-var mainGoAPIOnlyTmpl = strings.TrimSpace(`
-package main
 
-import (
-	"log"
-	"os"
-
-	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
-)
-
-func main() {
-	app := nexo.New()
-
-	// Run the application
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+// scaffoldProject writes the files of a new project to dir, returning
+// their paths in order.
+func scaffoldProject(dir string, opts projectOptions) ([]string, error) {
+	files := projectFiles(opts)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 
-	log.Printf("Starting server on http://localhost:%s", port)
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatal(err)
+	created := make([]string, 0, len(paths))
+	for _, path := range paths {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := createFileFromTemplate(full, files[path], opts); err != nil {
+			return created, fmt.Errorf("failed to create %s: %w", full, err)
+		}
+		created = append(created, full)
 	}
+	return created, nil
 }
-`) + "\n"
-
-var goModTmpl = strings.TrimSpace(`
-module {{.ModuleName}}
-
-go 1.21
-`) + "\n"
-
-var nexoYamlTmpl = strings.TrimSpace(`
-# Nexo Configuration
-port: 3000
-host: "0.0.0.0"
-
-# Directories
-app_dir: "app"
-static_dir: "static"
-static_path: "/static"
-
-# Development
-dev:
-  hot_reload: true
-  watch_extensions: [".go", ".templ", ".css"]
-  exclude_dirs: ["node_modules", ".git"]
-
-# Middleware
-middleware:
-  logger: true
-  recover: true
-`) + "\n"
-
-var gitignoreTmpl = strings.TrimSpace(`
-# Binaries
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-*.test
-*.out
-
-# Build output
-bin/
-dist/
-tmp/
-
-# IDE
-.idea/
-*.swp
-*.swo
-
-# OS
-.DS_Store
-Thumbs.db
-
-# Go
-vendor/
-go.work
-
-# Generated
-*_templ.go
-nexo_routes.go
-
-# Nexo build directory (import symlinks, cache, etc.)
-.nexo/
-
-# Tailwind CSS output
-static/css/output.css
-
-# Environment
-.env
-.env.local
-`) + "\n"
-
-// VS Code settings for gopls with nexo build tag
-var vscodeSettingsTmpl = strings.TrimSpace(`
-{
-  "gopls": {
-    "build.buildFlags": ["-tags=nexo"]
-  },
-  "go.buildTags": "nexo"
-}
-`) + "\n"
 
-var healthRouteTmpl = strings.TrimSpace(`
-package health
+// createFileFromTemplate renders tmplContent with data to path. Go files
+// are formatted.
+func createFileFromTemplate(path, tmplContent string, data any) error {
+	// Create parent directory if needed
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
 
-import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	// Empty content means just create the file
+	if tmplContent == "" {
+		return os.WriteFile(path, nil, 0644)
+	}
 
-// Get handles GET /api/health
-func Get(c *nexo.Context) error {
-	return c.JSON(200, map[string]string{
-		"status": "ok",
-	})
-}
-`) + "\n"
-
-// Layout template with Tailwind CSS and HTMX
-var layoutTemplTmpl = strings.TrimSpace(`
-package app
-
-templ Layout(title string) {
-	<!DOCTYPE html>
-	<html lang="en">
-		<head>
-			<meta charset="UTF-8"/>
-			<meta name="viewport" content="width=device-width, initial-scale=1.0"/>
-			<title>{ title } | {{.Name}}</title>
-			<link href="/static/css/output.css" rel="stylesheet"/>
-			<script src="https://unpkg.com/htmx.org@1.9.10"></script>
-		</head>
-		<body class="bg-gray-50 min-h-screen">
-			{ children... }
-		</body>
-	</html>
-}
-`) + "\n"
-
-// Home page template
-var pageTemplTmpl = strings.TrimSpace(`
-package app
-
-templ Page() {
-	@Layout("Home") {
-		<main class="container mx-auto px-4 py-16">
-			<div class="max-w-2xl mx-auto text-center">
-				<h1 class="text-4xl font-bold text-gray-900 mb-4">
-					Welcome to {{.Name}}
-				</h1>
-				<p class="text-lg text-gray-600 mb-8">
-					Your Nexo application is ready to go!
-				</p>
-				<div class="space-x-4">
-					<a href="/api/health" class="inline-block px-6 py-3 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition">
-						Check API Health
-					</a>
-				</div>
-			</div>
-		</main>
+	tmpl, err := template.New("file").Parse(tmplContent)
+	if err != nil {
+		return err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	content := []byte(buf.String())
+	if strings.HasSuffix(path, ".go") {
+		if content, err = format.Source(content); err != nil {
+			return err
+		}
 	}
+	return os.WriteFile(path, content, 0644)
 }
-`) + "\n"
-
-// Tailwind CSS input file
-var tailwindInputCssTmpl = strings.TrimSpace(`
-@tailwind base;
-@tailwind components;
-@tailwind utilities;
-`) + "\n"
@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"gopkg.in/yaml.v3"
+)
+
+// templateManifestFile is the manifest at the root of a template
+// repository. It is optional and not copied into the project.
+const templateManifestFile = "nexo-template.yaml"
+
+// templateManifest describes how a template repository becomes a project.
+type templateManifest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// Version is recorded when the template isn't cloned at a ref.
+	Version string `yaml:"version"`
+
+	// Replace maps text of the template to its value in the project, in
+	// file contents and paths. Values are templates of the project
+	// options, like "{{.Name}}" and "{{.ModuleName}}". The module path of
+	// the template's go.mod is always replaced with the project's.
+	Replace map[string]string `yaml:"replace"`
+
+	// Exclude lists files and directories that aren't copied.
+	Exclude []string `yaml:"exclude"`
+}
+
+// templateSource is a template repository and the ref to clone.
+type templateSource struct {
+	Source string // as given, without the ref
+	URL    string
+	Ref    string
+}
+
+// parseTemplateSource parses a template repository like
+// "github.com/org/repo@v1.2.0", a git URL or a local path. It reports
+// false for the names of the built-in templates.
+func parseTemplateSource(s string) (templateSource, bool) {
+	if !strings.ContainsAny(s, "/:") {
+		return templateSource{}, false
+	}
+
+	// The ref follows the last @ after the last slash, so git@host:org/repo
+	// has none
+	src := templateSource{Source: s}
+	if i := strings.LastIndex(s, "@"); i > 0 && i > strings.LastIndex(s, "/") {
+		src.Source, src.Ref = s[:i], s[i+1:]
+	}
+
+	switch {
+	case strings.Contains(src.Source, "://"), strings.HasPrefix(src.Source, "git@"):
+		src.URL = src.Source
+	case filepath.IsAbs(src.Source), strings.HasPrefix(src.Source, "."):
+		src.URL = src.Source
+	default:
+		src.URL = "https://" + src.Source
+	}
+	return src, true
+}
+
+// scaffoldFromGit creates a project in dir from a template repository,
+// returning the paths of the files in order and the recorded template.
+func scaffoldFromGit(dir string, src templateSource, opts projectOptions) ([]string, nexo.TemplateConfig, error) {
+	record := nexo.TemplateConfig{Source: src.Source, Version: src.Ref}
+
+	tmp, err := os.MkdirTemp("", "nexo-template-")
+	if err != nil {
+		return nil, record, err
+	}
+	defer os.RemoveAll(tmp)
+
+	repo := filepath.Join(tmp, "repo")
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+	if out, err := exec.Command("git", append(args, src.URL, repo)...).CombinedOutput(); err != nil {
+		return nil, record, fmt.Errorf("failed to clone %s: %v: %s", src.Source, err, strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output(); err == nil {
+		record.Commit = strings.TrimSpace(string(out))
+	}
+
+	manifest, err := readTemplateManifest(repo)
+	if err != nil {
+		return nil, record, err
+	}
+	if record.Version == "" {
+		record.Version = manifest.Version
+	}
+	if record.Version == "" && len(record.Commit) >= 12 {
+		record.Version = record.Commit[:12]
+	}
+
+	replacer, err := templateReplacer(repo, manifest, opts)
+	if err != nil {
+		return nil, record, err
+	}
+	created, err := copyTemplate(repo, dir, manifest.Exclude, replacer)
+	if err != nil {
+		return created, record, err
+	}
+
+	config := filepath.Join(dir, "nexo.yaml")
+	if err := recordTemplate(config, record); err != nil {
+		return created, record, fmt.Errorf("failed to record the template in %s: %w", config, err)
+	}
+	if !slices.Contains(created, config) {
+		created = append(created, config)
+		sort.Strings(created)
+	}
+	return created, record, nil
+}
+
+// readTemplateManifest reads the manifest of the repository, if any.
+func readTemplateManifest(repo string) (templateManifest, error) {
+	var manifest templateManifest
+	data, err := os.ReadFile(filepath.Join(repo, templateManifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid %s: %w", templateManifestFile, err)
+	}
+	return manifest, nil
+}
+
+// templateReplacer returns the replacer of the template's module path and
+// the manifest's replacements, longest first so a replacement never
+// shadows a longer one it's part of.
+func templateReplacer(repo string, manifest templateManifest, opts projectOptions) (*strings.Replacer, error) {
+	replace := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(repo, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				replace[strings.Trim(strings.TrimSpace(module), `"`)] = opts.ModuleName
+				break
+			}
+		}
+	}
+	for from, to := range manifest.Replace {
+		tmpl, err := template.New(from).Parse(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replacement of %q in %s: %w", from, templateManifestFile, err)
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, opts); err != nil {
+			return nil, fmt.Errorf("invalid replacement of %q in %s: %w", from, templateManifestFile, err)
+		}
+		replace[from] = buf.String()
+	}
+
+	keys := make([]string, 0, len(replace))
+	for from := range replace {
+		if from != "" {
+			keys = append(keys, from)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, from := range keys {
+		pairs = append(pairs, from, replace[from])
+	}
+	return strings.NewReplacer(pairs...), nil
+}
+
+// copyTemplate copies the files of repo to dir, replacing in their paths
+// and in the contents of text files. The .git directory, the manifest and
+// excluded paths are skipped.
+func copyTemplate(repo, dir string, exclude []string, replacer *strings.Replacer) ([]string, error) {
+	var created []string
+	err := filepath.WalkDir(repo, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(repo, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" || rel == templateManifestFile || excludedTemplatePath(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Binary files are copied as they are
+		if !bytes.Contains(data[:min(len(data), 8000)], []byte{0}) {
+			data = []byte(replacer.Replace(string(data)))
+		}
+
+		// A replacement like "../x" must not write outside the project
+		name := filepath.FromSlash(replacer.Replace(rel))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("template file %s is renamed to %s, outside the project", rel, name)
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		created = append(created, target)
+		return nil
+	})
+	sort.Strings(created)
+	return created, err
+}
+
+// excludedTemplatePath reports whether rel is, or is in, an excluded path.
+func excludedTemplatePath(rel string, exclude []string) bool {
+	for _, pattern := range exclude {
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if rel == pattern || strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTemplate writes the template section of nexo.yaml, replacing the
+// one the template itself may have.
+func recordTemplate(path string, record nexo.TemplateConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Drop the existing top-level template key and its indented lines
+	var kept []string
+	inTemplate := false
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if strings.HasPrefix(line, "template:") {
+			inTemplate = true
+			continue
+		}
+		if inTemplate && (line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			continue
+		}
+		inTemplate = false
+		kept = append(kept, line)
+	}
+	content := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if content != "" {
+		content += "\n\n"
+	}
+
+	var section bytes.Buffer
+	enc := yaml.NewEncoder(&section)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]nexo.TemplateConfig{"template": record}); err != nil {
+		return err
+	}
+	content += "# Template this project was created from\n" + section.String()
+	return os.WriteFile(path, []byte(content), 0644)
+}
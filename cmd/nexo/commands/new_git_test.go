@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestParseTemplateSource(t *testing.T) {
+	tests := []struct {
+		in   string
+		want templateSource
+		ok   bool
+	}{
+		{in: "full"},
+		{in: "api-only"},
+		{
+			in:   "github.com/org/nexo-template-saas",
+			want: templateSource{Source: "github.com/org/nexo-template-saas", URL: "https://github.com/org/nexo-template-saas"},
+			ok:   true,
+		},
+		{
+			in:   "github.com/org/nexo-template-saas@v1.2.0",
+			want: templateSource{Source: "github.com/org/nexo-template-saas", URL: "https://github.com/org/nexo-template-saas", Ref: "v1.2.0"},
+			ok:   true,
+		},
+		{
+			in:   "git@github.com:org/repo.git",
+			want: templateSource{Source: "git@github.com:org/repo.git", URL: "git@github.com:org/repo.git"},
+			ok:   true,
+		},
+		{
+			in:   "https://gitlab.com/org/repo@main",
+			want: templateSource{Source: "https://gitlab.com/org/repo", URL: "https://gitlab.com/org/repo", Ref: "main"},
+			ok:   true,
+		},
+		{
+			in:   "./templates/saas",
+			want: templateSource{Source: "./templates/saas", URL: "./templates/saas"},
+			ok:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := parseTemplateSource(tt.in)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseTemplateSource(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScaffoldFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module github.com/acme/starter\n\ngo 1.25\n",
+		"main.go": "package main\n\nimport _ \"github.com/acme/starter/internal/billing\"\n\n// Acme Starter\nfunc main() {}\n",
+		"nexo.yaml": "port: 3000\n\ntemplate:\n  source: github.com/acme/base\n  version: v0.1.0\n\n" +
+			"settings:\n  title: Acme Starter\n",
+		"internal/billing/billing.go": "package billing\n",
+		"cmd/acme-starter/main.go":    "package main\n",
+		"docs/template.md":            "How to maintain the template\n",
+		templateManifestFile: "name: saas\nversion: 1.2.0\n" +
+			"replace:\n  Acme Starter: \"{{.Name}}\"\n  acme-starter: \"{{.Name}}\"\n" +
+			"exclude:\n  - docs/\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(repo, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "template"},
+		{"tag", "v1.3.0"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	for _, tt := range []struct {
+		ref, version string
+	}{
+		{ref: "", version: "1.2.0"},
+		{ref: "@v1.3.0", version: "v1.3.0"},
+	} {
+		t.Run(tt.version, func(t *testing.T) {
+			src, ok := parseTemplateSource(repo + tt.ref)
+			if !ok {
+				t.Fatalf("%s is not a template repository", repo)
+			}
+			dir := filepath.Join(t.TempDir(), "myapp")
+			opts := projectOptions{Name: "myapp", ModuleName: "example.com/myapp", Repo: src}
+
+			created, record, err := scaffoldFromGit(dir, src, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(created) != 5 {
+				t.Errorf("created %v, want 5 files", created)
+			}
+			if record.Version != tt.version || len(record.Commit) != 40 {
+				t.Errorf("record = %+v, want version %s and the commit", record, tt.version)
+			}
+
+			for _, missing := range []string{templateManifestFile, ".git", "docs/template.md", "cmd/acme-starter/main.go"} {
+				if _, err := os.Stat(filepath.Join(dir, missing)); err == nil {
+					t.Errorf("%s was copied", missing)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "cmd/myapp/main.go")); err != nil {
+				t.Errorf("path wasn't renamed: %v", err)
+			}
+			main, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+			if !strings.Contains(string(main), `"example.com/myapp/internal/billing"`) || !strings.Contains(string(main), "// myapp") {
+				t.Errorf("main.go wasn't rewritten:\n%s", main)
+			}
+
+			config, err := nexo.LoadConfig(dir)
+			if err != nil {
+				t.Fatalf("nexo.yaml: %v", err)
+			}
+			if config.Template != record {
+				t.Errorf("nexo.yaml template = %+v, want %+v", config.Template, record)
+			}
+			if config.Port != "3000" || config.String("title", "") != "myapp" {
+				t.Errorf("nexo.yaml lost the template's settings: port %s, title %s", config.Port, config.String("title", ""))
+			}
+		})
+	}
+}
+
+func TestCopyTemplate_OutsideProject(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "cmd", "starter"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "cmd", "starter", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	dir := filepath.Join(root, "myapp")
+
+	for _, to := range []string{"../escaped", "/tmp/escaped"} {
+		_, err := copyTemplate(repo, dir, nil, strings.NewReplacer("cmd/starter", to))
+		if err == nil || !strings.Contains(err.Error(), "outside the project") {
+			t.Errorf("replacing with %q: error = %v, want outside the project", to, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped")); err == nil {
+		t.Error("a file was written outside the project")
+	}
+}
@@ -0,0 +1,530 @@
+package commands
+
+import "strings"
+
+// Templates of the files of nexo new, rendered with projectOptions.
+
+var goModTmpl = strings.TrimSpace(`
+module {{.ModuleName}}
+
+go 1.21
+`) + "\n"
+
+var nexoYamlTmpl = strings.TrimSpace(`
+# Nexo Configuration
+port: 3000
+host: "0.0.0.0"
+
+# Directories
+app_dir: "app"
+static_dir: "static"
+static_path: "/static"
+
+# Development
+dev:
+  hot_reload: true
+  watch_extensions: [".go", ".templ", ".css"{{if .Static}}, ".md"{{end}}]
+  exclude_dirs: ["node_modules", ".git"]
+
+# Middleware
+middleware:
+  logger: true
+  recover: true
+{{- if .Static}}
+
+# SEO
+sitemap:
+  enabled: true
+  base_url: https://{{.Name}}.example.com
+robots:
+  enabled: true
+{{- end}}
+{{- if or .Database .Auth}}
+
+# App settings, read with c.Config() (${VAR} comes from the environment)
+settings:
+{{- if .Database}}
+  database:
+    url: ${DATABASE_URL}
+{{- end}}
+{{- if .Auth}}
+  auth:
+    tokens:
+      dev: ${API_TOKEN}
+{{- end}}
+{{- end}}
+`) + "\n"
+
+var gitignoreTmpl = strings.TrimSpace(`
+# Binaries
+*.exe
+*.exe~
+*.dll
+*.so
+*.dylib
+*.test
+*.out
+
+# Build output
+bin/
+dist/
+tmp/
+
+# IDE
+.idea/
+*.swp
+*.swo
+
+# OS
+.DS_Store
+Thumbs.db
+
+# Go
+vendor/
+go.work
+
+# Generated
+*_templ.go
+nexo_routes.go
+
+# Nexo build directory (generated code, cache, etc.)
+.nexo/
+
+# Tailwind CSS output
+static/css/output.css
+
+# Environment
+.env
+.env.local
+.env.*.local
+.env.key
+{{- if eq .Database "sqlite"}}
+
+# SQLite databases
+*.db
+{{- end}}
+`) + "\n"
+
+// VS Code settings for gopls with nexo build tag
+var vscodeSettingsTmpl = strings.TrimSpace(`
+{
+  "gopls": {
+    "build.buildFlags": ["-tags=nexo"]
+  },
+  "go.buildTags": "nexo"
+}
+`) + "\n"
+
+// Makefile targets that work the same locally and in any CI
+var makefileTmpl = strings.TrimSpace(`
+.PHONY: dev build generate test vet fmt clean{{if .Docker}} docker{{end}}
+
+# Run the dev server with hot reload
+dev:
+	nexo dev
+
+# Build the production binary to bin/
+build:
+	nexo build
+
+# Generate templ components and nexo_routes.go
+generate:
+{{- if .Templ}}
+	templ generate
+{{- end}}
+	nexo generate routes
+
+test: generate
+	go test ./...
+
+vet: generate
+	go vet ./...
+
+fmt:
+	gofmt -w .
+{{- if .Templ}}
+	templ fmt .
+{{- end}}
+
+clean:
+	rm -rf bin dist tmp .nexo
+{{- if .Docker}}
+
+# Build the Docker image
+docker:
+	nexo docker build
+{{- end}}
+`) + "\n"
+
+var mainGoTmpl = strings.TrimSpace(`
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+{{- if .Auth}}
+	"{{.ModuleName}}/internal/auth"
+{{- end}}
+{{- if .Database}}
+	"{{.ModuleName}}/internal/db"
+{{- end}}
+)
+
+func main() {
+	config, err := nexo.LoadConfig("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		config.Port = port
+	}
+
+	app := nexo.New(nexo.WithConfig(config))
+{{- if .Database}}
+
+	// Open the database; handlers receive it as a *sql.DB parameter
+	database, err := db.Open(app.Config().String("database.url", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close()
+	app.Provide(database)
+{{- end}}
+{{- if .Auth}}
+
+	// Authenticate API clients; routes that need one declare
+	// nexo:route auth=required
+	app.Use(auth.Middleware())
+{{- end}}
+{{- if .Templ}}
+
+	// Serve static files
+	app.Static("/static", "static")
+{{- end}}
+
+	// Register the routes of app/, from nexo_routes.go (generated by nexo dev
+	// and nexo build)
+	RegisterRoutes(app)
+
+	if err := app.Listen(); err != nil {
+		log.Fatal(err)
+	}
+}
+`) + "\n"
+
+var healthRouteTmpl = strings.TrimSpace(`
+package health
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// Get handles GET /api/health
+func Get(c *nexo.Context) error {
+	return c.JSON(200, map[string]string{
+		"status": "ok",
+	})
+}
+`) + "\n"
+
+var healthRouteTestTmpl = strings.TrimSpace(`
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestGet(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := nexo.NewContext(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	if err := Get(c); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	if body := w.Body.String(); body != "{\"status\":\"ok\"}\n" {
+		t.Errorf("body = %s", body)
+	}
+}
+`) + "\n"
+
+// Layout template with HTMX, and Tailwind CSS when enabled
+var layoutTemplTmpl = strings.TrimSpace(`
+package app
+
+templ Layout(title string) {
+	<!DOCTYPE html>
+	<html lang="en">
+		<head>
+			<meta charset="UTF-8"/>
+			<meta name="viewport" content="width=device-width, initial-scale=1.0"/>
+			<title>{ title } | {{.Name}}</title>
+{{- if .Tailwind}}
+			<link href="/static/css/output.css" rel="stylesheet"/>
+{{- end}}
+{{- if not .Static}}
+			<script src="https://unpkg.com/htmx.org@1.9.10"></script>
+{{- end}}
+		</head>
+		<body{{if .Tailwind}} class="bg-gray-50 min-h-screen"{{end}}>
+{{- if .Static}}
+			<nav{{if .Tailwind}} class="container mx-auto px-4 py-6 space-x-6"{{end}}>
+				<a href="/">{{.Name}}</a>
+				<a href="/blog/hello-world">Blog</a>
+				<a href="/about">About</a>
+			</nav>
+{{- end}}
+			{ children... }
+		</body>
+	</html>
+}
+`) + "\n"
+
+// Home page template
+var pageTemplTmpl = strings.TrimSpace(`
+package app
+
+templ Page() {
+	@Layout("Home") {
+		<main{{if .Tailwind}} class="container mx-auto px-4 py-16"{{end}}>
+			<div{{if .Tailwind}} class="max-w-2xl mx-auto text-center"{{end}}>
+				<h1{{if .Tailwind}} class="text-4xl font-bold text-gray-900 mb-4"{{end}}>
+					Welcome to {{.Name}}
+				</h1>
+{{- if .Static}}
+				<p{{if .Tailwind}} class="text-lg text-gray-600 mb-8"{{end}}>
+					Write posts as Markdown files in content/blog.
+				</p>
+				<a href="/blog/hello-world"{{if .Tailwind}} class="text-blue-600 hover:underline"{{end}}>
+					Read the first post
+				</a>
+{{- else}}
+				<p{{if .Tailwind}} class="text-lg text-gray-600 mb-8"{{end}}>
+					Your Nexo application is ready to go!
+				</p>
+				<div{{if .Tailwind}} class="space-x-4"{{end}}>
+					<a href="/api/health"{{if .Tailwind}} class="inline-block px-6 py-3 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition"{{end}}>
+						Check API Health
+					</a>
+				</div>
+{{- end}}
+			</div>
+		</main>
+	}
+}
+`) + "\n"
+
+// About page of the static template
+var aboutPageTemplTmpl = strings.TrimSpace(`
+package about
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+var Metadata = nexo.Metadata{Title: "About"}
+
+templ Page() {
+	<main{{if .Tailwind}} class="container mx-auto px-4 py-16 max-w-2xl"{{end}}>
+		<h1{{if .Tailwind}} class="text-3xl font-bold mb-4"{{end}}>About</h1>
+		<p>{{.Name}} is built with Nexo. This page renders inside app/layout.templ.</p>
+	</main>
+}
+`) + "\n"
+
+// First post of the static template
+var helloWorldPostTmpl = strings.TrimSpace(`
+---
+title: Hello, World
+description: The first post of {{.Name}}.
+date: 2026-01-01
+tags: [welcome]
+---
+
+# Hello, World
+
+This page is content/blog/hello-world.md, served at /blog/hello-world
+inside the app layout. Add Markdown files to content/ to add pages.
+`) + "\n"
+
+// Tailwind CSS input file
+var tailwindInputCssTmpl = strings.TrimSpace(`
+@tailwind base;
+@tailwind components;
+@tailwind utilities;
+`) + "\n"
+
+// Local settings of --db, loaded by nexo.New outside production
+var envTmpl = strings.TrimSpace(`
+{{- if eq .Database "postgres"}}
+DATABASE_URL=postgres://localhost:5432/{{.Name}}?sslmode=disable
+{{- else}}
+DATABASE_URL=file:{{.Name}}.db?_pragma=foreign_keys(1)
+{{- end}}
+{{- if .Auth}}
+API_TOKEN=dev-token
+{{- end}}
+`) + "\n"
+
+var dbTmpl = strings.TrimSpace(`
+// Package db opens the app's database.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+{{if eq .Database "postgres"}}
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver
+{{- else}}
+	_ "modernc.org/sqlite" // registers the sqlite driver
+{{- end}}
+)
+
+// Open opens the database at url and checks that it is reachable.
+func Open(url string) (*sql.DB, error) {
+	if url == "" {
+		return nil, errors.New("db: no database url; set DATABASE_URL")
+	}
+	database, err := sql.Open("{{if eq .Database "postgres"}}pgx{{else}}sqlite{{end}}", url)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := database.PingContext(ctx); err != nil {
+		database.Close()
+		return nil, err
+	}
+	return database, nil
+}
+`) + "\n"
+
+var dbTestTmpl = strings.TrimSpace(`
+package db
+
+import (
+{{- if eq .Database "postgres"}}
+	"os"
+{{- end}}
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+{{- if eq .Database "postgres"}}
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+{{- else}}
+	url := "file::memory:"
+{{- end}}
+	database, err := Open(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	var n int
+	if err := database.QueryRow("SELECT 1").Scan(&n); err != nil || n != 1 {
+		t.Errorf("SELECT 1 = %d, %v", n, err)
+	}
+}
+
+func TestOpenWithoutURL(t *testing.T) {
+	if _, err := Open(""); err == nil {
+		t.Error("Open(\"\") succeeded")
+	}
+}
+`) + "\n"
+
+var authTmpl = strings.TrimSpace(`
+// Package auth authenticates API clients by bearer token.
+package auth
+
+import (
+	"crypto/subtle"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Client is the principal of a request with a valid token.
+type Client struct {
+	Name string `+"`json:\"name\"`"+`
+}
+
+// Middleware sets the principal of requests sending a valid
+// "Authorization: Bearer <token>" header. Routes reject requests without
+// one with // nexo:route auth=required.
+func Middleware() nexo.MiddlewareFunc {
+	return func(next nexo.HandlerFunc) nexo.HandlerFunc {
+		return func(c *nexo.Context) error {
+			if client := Authenticate(c.Config(), c.BearerToken()); client != nil {
+				c.SetPrincipal(client)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Authenticate returns the client whose token, from auth.tokens in the
+// settings of nexo.yaml, is token, or nil.
+func Authenticate(config *nexo.Config, token string) *Client {
+	if token == "" {
+		return nil
+	}
+	tokens, _ := config.Setting("auth.tokens")
+	clients, _ := tokens.(map[string]any)
+	for name := range clients {
+		want := config.String("auth.tokens."+name, "")
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return &Client{Name: name}
+		}
+	}
+	return nil
+}
+`) + "\n"
+
+var authTestTmpl = strings.TrimSpace(`
+package auth
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestAuthenticate(t *testing.T) {
+	config := &nexo.Config{Settings: map[string]any{
+		"auth": map[string]any{"tokens": map[string]any{"ci": "secret"}},
+	}}
+
+	if client := Authenticate(config, "secret"); client == nil || client.Name != "ci" {
+		t.Errorf("Authenticate(secret) = %v, want client ci", client)
+	}
+	for _, token := range []string{"", "wrong"} {
+		if client := Authenticate(config, token); client != nil {
+			t.Errorf("Authenticate(%q) = %v, want nil", token, client)
+		}
+	}
+}
+`) + "\n"
+
+var meRouteTmpl = strings.TrimSpace(`
+package me
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// Get handles GET /api/me, returning the authenticated client.
+//
+// nexo:route auth=required
+func Get(c *nexo.Context) error {
+	return c.JSON(200, c.Principal())
+}
+`) + "\n"
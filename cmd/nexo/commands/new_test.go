@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func TestProjectFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    projectOptions
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "full",
+			opts:    projectOptions{Template: templateFull, Tailwind: true},
+			want:    []string{"main.go", "Makefile", "app/page.templ", "app/layout.templ", "styles/input.css", "app/api/health/route_test.go"},
+			notWant: []string{"content/blog/hello-world.md", "internal/db/db.go", "internal/auth/auth.go"},
+		},
+		{
+			name:    "full without tailwind",
+			opts:    projectOptions{Template: templateFull},
+			want:    []string{"app/page.templ"},
+			notWant: []string{"styles/input.css", "static/css/.gitkeep"},
+		},
+		{
+			name:    "api-only",
+			opts:    projectOptions{Template: templateAPIOnly, Database: "postgres", Auth: true},
+			want:    []string{"app/api/health/route.go", "internal/db/db.go", "internal/db/db_test.go", ".env", "internal/auth/auth.go", "app/api/me/route.go"},
+			notWant: []string{"app/page.templ", "app/layout.templ", "styles/input.css"},
+		},
+		{
+			name: "static",
+			opts: projectOptions{Template: templateStatic, Tailwind: true},
+			want: []string{"app/page.templ", "app/about/page.templ", "content/blog/hello-world.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := projectFiles(tt.opts)
+			for _, f := range tt.want {
+				if _, ok := files[f]; !ok {
+					t.Errorf("missing %s", f)
+				}
+			}
+			for _, f := range tt.notWant {
+				if _, ok := files[f]; ok {
+					t.Errorf("unexpected %s", f)
+				}
+			}
+		})
+	}
+}
+
+func TestScaffoldProject(t *testing.T) {
+	for _, opts := range []projectOptions{
+		{Template: templateFull, Tailwind: true, Database: "sqlite", Auth: true, Docker: true},
+		{Template: templateAPIOnly, Database: "postgres"},
+		{Template: templateStatic},
+	} {
+		t.Run(opts.Template, func(t *testing.T) {
+			opts.Name, opts.ModuleName = "myapp", "example.com/myapp"
+			dir := filepath.Join(t.TempDir(), "myapp")
+
+			// Go files are formatted, so they must parse
+			created, err := scaffoldProject(dir, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(created) != len(projectFiles(opts)) {
+				t.Errorf("created %d files, want %d", len(created), len(projectFiles(opts)))
+			}
+
+			main, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+			if !strings.Contains(string(main), "RegisterRoutes(app)") {
+				t.Errorf("main.go doesn't register the routes:\n%s", main)
+			}
+			if opts.Database != "" && !strings.Contains(string(main), `"example.com/myapp/internal/db"`) {
+				t.Errorf("main.go doesn't import the db package:\n%s", main)
+			}
+
+			makefile, _ := os.ReadFile(filepath.Join(dir, "Makefile"))
+			for _, line := range strings.Split(string(makefile), "\n") {
+				if strings.HasPrefix(line, " ") {
+					t.Errorf("Makefile recipe indented with spaces: %q", line)
+				}
+			}
+			if got := strings.Contains(string(makefile), "templ generate"); got != opts.Templ() {
+				t.Errorf("Makefile runs templ generate = %v, want %v", got, opts.Templ())
+			}
+
+			config, err := nexo.LoadConfig(dir)
+			if err != nil {
+				t.Fatalf("nexo.yaml: %v", err)
+			}
+			if _, ok := config.Setting("database.url"); ok != (opts.Database != "") {
+				t.Errorf("nexo.yaml has database.url = %v", ok)
+			}
+			if config.Sitemap.Enabled != opts.Static() {
+				t.Errorf("sitemap enabled = %v", config.Sitemap.Enabled)
+			}
+		})
+	}
+}
+
+func TestProjectOptionsValidate(t *testing.T) {
+	opts := projectOptions{Template: templateAPIOnly, Tailwind: true}
+	if err := opts.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Tailwind {
+		t.Error("api-only project kept Tailwind")
+	}
+	for _, bad := range []projectOptions{{Template: "spa"}, {Template: templateFull, Database: "mongo"}} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded", bad)
+		}
+	}
+}
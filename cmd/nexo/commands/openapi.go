@@ -151,7 +151,7 @@ func runOpenAPIGenerate(cmd *cobra.Command, args []string) {
 	gen := nexo.NewOpenAPIGenerator(openapiAppDir, config)
 
 	// Count routes
-	scanner := nexo.NewScanner(openapiAppDir)
+	scanner := newScanner(openapiAppDir)
 	routes, err := scanner.ScanRouteInfo()
 	if err != nil {
 		if jsonOutput {
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/edge"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
 )
 
 // jsonOutput is the global flag for JSON output mode
@@ -38,14 +41,127 @@ type ProxyOutput struct {
 type MiddlewareOutput struct {
 	Path string `json:"path"`
 	File string `json:"file"`
+	Name string `json:"name,omitempty"`
 }
 
 // RouteOutput represents a single route in JSON output
 type RouteOutput struct {
-	Method   string `json:"method"`
-	Pattern  string `json:"pattern"`
-	File     string `json:"file"`
-	Priority int    `json:"priority,omitempty"`
+	Method           string            `json:"method"`
+	Pattern          string            `json:"pattern"`
+	File             string            `json:"file"`
+	Priority         int               `json:"priority,omitempty"`
+	PriorityOverride bool              `json:"priority_override,omitempty"`
+	Version          string            `json:"version,omitempty"`
+	Middleware       []string          `json:"middleware,omitempty"`
+	Skipped          []string          `json:"skipped_middleware,omitempty"`
+	Doc              *scanner.RouteDoc `json:"doc,omitempty"`
+}
+
+// RouteNodeOutput represents a URL segment in the routes --tree JSON output
+type RouteNodeOutput struct {
+	Segment    string             `json:"segment"`
+	Path       string             `json:"path"`
+	Methods    []string           `json:"methods,omitempty"`
+	Page       string             `json:"page,omitempty"`
+	Middleware string             `json:"middleware,omitempty"`
+	Layout     string             `json:"layout,omitempty"`
+	Proxy      string             `json:"proxy,omitempty"`
+	Children   []*RouteNodeOutput `json:"children,omitempty"`
+}
+
+// BenchOutput represents the JSON output for the bench command
+type BenchOutput struct {
+	URL         string             `json:"url"`
+	Concurrency int                `json:"concurrency"`
+	Duration    string             `json:"duration"` // Per route
+	Routes      []BenchRouteOutput `json:"routes"`
+	Skipped     []string           `json:"skipped,omitempty"`
+}
+
+// BenchRouteOutput is the result of benchmarking a route. Latencies are in
+// milliseconds.
+type BenchRouteOutput struct {
+	Method   string      `json:"method"`
+	Pattern  string      `json:"pattern"`
+	Path     string      `json:"path"`
+	Requests int         `json:"requests"`
+	Errors   int         `json:"errors"` // Failed requests and 4xx/5xx responses
+	Statuses map[int]int `json:"statuses,omitempty"`
+	RPS      float64     `json:"rps"`
+	Mean     float64     `json:"mean_ms"`
+	P50      float64     `json:"p50_ms"`
+	P90      float64     `json:"p90_ms"`
+	P99      float64     `json:"p99_ms"`
+	Max      float64     `json:"max_ms"`
+}
+
+// ProfileOutput represents the JSON output for the profile command
+type ProfileOutput struct {
+	Profile string `json:"profile"`
+	URL     string `json:"url"`
+	File    string `json:"file"`
+	Size    int64  `json:"size"`
+}
+
+// I18nExtractOutput represents the JSON output for the i18n extract command
+type I18nExtractOutput struct {
+	Keys     int                 `json:"keys"`
+	Catalogs []I18nCatalogOutput `json:"catalogs"`
+}
+
+// I18nCatalogOutput represents a single catalog in i18n extract output
+type I18nCatalogOutput struct {
+	File    string   `json:"file"`
+	Missing []string `json:"missing"`
+	Updated bool     `json:"updated,omitempty"`
+}
+
+// SitemapOutput represents the JSON output for the sitemap command
+type SitemapOutput struct {
+	File    string `json:"file"`
+	Pages   int    `json:"pages"`
+	Dynamic int    `json:"dynamic"`
+}
+
+// EdgeOutput represents the JSON output for the edge check and edge
+// export commands
+type EdgeOutput struct {
+	File      string       `json:"file,omitempty"`
+	Rules     int          `json:"rules"`
+	Redirects int          `json:"redirects"`
+	Rewrites  int          `json:"rewrites"`
+	Format    string       `json:"format,omitempty"`
+	Output    string       `json:"output,omitempty"`
+	Issues    []edge.Issue `json:"issues"`
+
+	rules *edge.Rules
+}
+
+// ErrorsOutput represents the JSON output for the errors command
+type ErrorsOutput struct {
+	Errors []ErrorEntry `json:"errors"`
+	Output string       `json:"output,omitempty"`
+}
+
+// ManifestOutput represents the JSON output for the manifest command when
+// it writes a file
+type ManifestOutput struct {
+	File   string `json:"file"`
+	Routes int    `json:"routes"`
+	Pages  int    `json:"pages"`
+}
+
+// BundleOutput represents the JSON output for the bundle command
+type BundleOutput struct {
+	Manifest string        `json:"manifest"`
+	Dev      bool          `json:"dev"`
+	Assets   []BundleAsset `json:"assets"`
+}
+
+// BundleAsset is a bundled file in the asset manifest
+type BundleAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
 // PageOutput represents a single page in JSON output
@@ -71,6 +187,25 @@ type BuildOutput struct {
 	Arch    string `json:"arch"`
 	Size    int64  `json:"size,omitempty"`
 	Success bool   `json:"success"`
+
+	// Targets lists every binary built, one per --target
+	Targets []BuildTarget `json:"targets,omitempty"`
+
+	// Embedded lists the directories embedded with --embed, and their total size
+	Embedded     []string `json:"embedded,omitempty"`
+	EmbeddedSize int64    `json:"embedded_size,omitempty"`
+
+	// Bundle is the Lambda bundle written with --lambda, and its size
+	Bundle     string `json:"bundle,omitempty"`
+	BundleSize int64  `json:"bundle_size,omitempty"`
+}
+
+// BuildTarget is a binary built for one platform
+type BuildTarget struct {
+	Binary string `json:"binary"`
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	Size   int64  `json:"size"`
 }
 
 // DevOutput represents the JSON output for the dev command
@@ -86,10 +221,26 @@ type GenerateOutput struct {
 	Command string   `json:"command"`
 	Path    string   `json:"path,omitempty"`
 	Files   []string `json:"files"`
+	Updated []string `json:"updated,omitempty"`
+	Skipped []string `json:"skipped,omitempty"`
 	Pattern string   `json:"pattern,omitempty"`
 	Methods []string `json:"methods,omitempty"`
 }
 
+// DryRunOutput represents the JSON output for generate commands run with
+// --dry-run
+type DryRunOutput struct {
+	Command string         `json:"command"`
+	Changes []DryRunChange `json:"changes"`
+}
+
+// DryRunChange is a file a dry run would create, update or delete
+type DryRunChange struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
 // ValidateOutput represents the JSON output for the validate command
 type ValidateOutput struct {
 	Valid      bool     `json:"valid"`
@@ -171,14 +322,30 @@ type AppDeleteOutput struct {
 
 // DeployOutput represents the JSON output for the deploy command
 type DeployOutput struct {
-	Success      bool              `json:"success"`
-	DeploymentID string            `json:"deployment_id,omitempty"`
-	Version      string            `json:"version,omitempty"`
-	Status       string            `json:"status,omitempty"`
-	URL          string            `json:"url,omitempty"`
-	Image        string            `json:"image,omitempty"`
-	Message      string            `json:"message,omitempty"`
-	Deployment   *DeploymentOutput `json:"deployment,omitempty"`
+	Success      bool               `json:"success"`
+	DeploymentID string             `json:"deployment_id,omitempty"`
+	Version      string             `json:"version,omitempty"`
+	Status       string             `json:"status,omitempty"`
+	URL          string             `json:"url,omitempty"`
+	Image        string             `json:"image,omitempty"`
+	Message      string             `json:"message,omitempty"`
+	Deployment   *DeploymentOutput  `json:"deployment,omitempty"`
+	Build        *DockerBuildOutput `json:"build,omitempty"`
+}
+
+// DockerInitOutput represents the JSON output for the docker init command
+type DockerInitOutput struct {
+	Files   []string `json:"files"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// DockerBuildOutput represents a built container image in JSON output
+type DockerBuildOutput struct {
+	Image      string `json:"image"`
+	Dockerfile string `json:"dockerfile,omitempty"`
+	Generated  bool   `json:"generated"`
+	Platform   string `json:"platform,omitempty"`
+	Pushed     bool   `json:"pushed"`
 }
 
 // DeploymentOutput represents a deployment in JSON output
@@ -225,6 +392,17 @@ type MetricsOutput struct {
 	RequestsMin   int64   `json:"requests_min"`
 }
 
+// MaintenanceOutput represents the JSON output for the maintenance commands
+type MaintenanceOutput struct {
+	Enabled    bool       `json:"enabled"`
+	File       string     `json:"file,omitempty"`
+	App        string     `json:"app,omitempty"`
+	Provider   string     `json:"provider,omitempty"`
+	Allow      []string   `json:"allow,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+}
+
 // EnvListOutput represents the JSON output for the env list command
 type EnvListOutput struct {
 	App       string            `json:"app"`
@@ -248,6 +426,44 @@ type EnvUnsetOutput struct {
 	Message string   `json:"message,omitempty"`
 }
 
+// EnvSyncOutput represents the JSON output for the env push and pull commands
+type EnvSyncOutput struct {
+	App       string   `json:"app"`
+	Provider  string   `json:"provider"`
+	File      string   `json:"file"`
+	Keys      []string `json:"keys"`
+	Encrypted bool     `json:"encrypted,omitempty"`
+}
+
+// EnvKeygenOutput represents the JSON output for the env keygen command
+type EnvKeygenOutput struct {
+	File      string `json:"file"`
+	Gitignore bool   `json:"gitignore"`
+}
+
+// EnvCryptOutput represents the JSON output for the env encrypt and decrypt commands
+type EnvCryptOutput struct {
+	File      string   `json:"file"`
+	Encrypted bool     `json:"encrypted"`
+	Keys      []string `json:"keys"`
+}
+
+// DoctorOutput represents the JSON output for the doctor command
+type DoctorOutput struct {
+	Checks   []DoctorCheck `json:"checks"`
+	Passed   int           `json:"passed"`
+	Warnings int           `json:"warnings"`
+	Failed   int           `json:"failed"`
+}
+
+// DoctorCheck represents a single check in doctor output
+type DoctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // ok, warn, fail or skip
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
 // DomainsListOutput represents the JSON output for the domains list command
 type DomainsListOutput struct {
 	App     string         `json:"app"`
@@ -295,6 +511,13 @@ func printJSON(v any) {
 	}
 }
 
+// printJSONLine outputs v as a single line of JSON, for streamed output
+func printJSONLine(v any) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+	}
+}
+
 // printSuccess outputs a successful JSON response
 func printSuccess(data any) {
 	printJSON(JSONResponse{Success: true, Data: data})
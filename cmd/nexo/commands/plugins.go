@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/abdul-hamid-achik/nexo/pkg/plugin"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// loadPlugins returns the plugins enabled in the project's nexo.yaml.
+func loadPlugins() ([]plugin.Plugin, error) {
+	cfg, err := nexo.LoadConfig("")
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := plugin.Load(cfg.Plugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	return plugins, nil
+}
+
+// newGenerator creates a route generator with the project's plugins applied
+// and, unless --no-cache is set, the project's scan cache.
+func newGenerator(cfg scanner.GeneratorConfig) (*scanner.Generator, error) {
+	plugins, err := loadPlugins()
+	if err != nil {
+		return nil, err
+	}
+	plugin.Apply(&cfg, plugins)
+	if !noCache {
+		cfg.Cache = scanner.OpenCache(scanner.CacheFile)
+	}
+	return scanner.NewGenerator(cfg), nil
+}
+
+// newScanner creates a scanner for appDir that, unless --no-cache is set,
+// uses the project's scan cache.
+func newScanner(appDir string) *nexo.Scanner {
+	s := nexo.NewScanner(appDir)
+	if !noCache {
+		s.SetCache(scanner.OpenCache(scanner.CacheFile))
+	}
+	return s
+}
+
+// registerPluginCommands adds the extra commands of enabled plugins to the CLI.
+// Configuration errors are ignored here; they surface when generating routes.
+func registerPluginCommands() {
+	plugins, err := loadPlugins()
+	if err != nil {
+		return
+	}
+	for _, cmd := range plugin.Commands(plugins) {
+		rootCmd.AddCommand(cmd)
+	}
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile [cpu|heap|allocs|goroutine|block|mutex|threadcreate|trace]",
+	Short: "Capture a profile from a running server",
+	Long: `Capture a pprof profile or execution trace from a running app that serves
+the diagnostics endpoints (nexo.WithDebug or debug.enabled in nexo.yaml),
+save it, and open it with go tool pprof or go tool trace.
+
+The default profile is a 30 second CPU profile.
+
+Examples:
+  nexo profile
+  nexo profile heap
+  nexo profile cpu --seconds 10 -o cpu.pprof --open=false
+  nexo profile trace --seconds 5
+  nexo profile heap --url https://app.example.com --user ops:secret`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: profileKinds,
+	Run:       runProfile,
+}
+
+// profileKinds lists the profiles nexo profile can capture.
+var profileKinds = []string{"cpu", "heap", "allocs", "goroutine", "block", "mutex", "threadcreate", "trace"}
+
+var (
+	profileURL     string
+	profilePath    string
+	profileSeconds int
+	profileOutput  string
+	profileUser    string
+	profileHeaders []string
+	profileOpen    bool
+)
+
+func init() {
+	profileCmd.Flags().StringVarP(&profileURL, "url", "u", "http://localhost:3000", "Base URL of the running server")
+	profileCmd.Flags().StringVar(&profilePath, "path", "/_debug", "Path the diagnostics endpoints are served under")
+	profileCmd.Flags().IntVarP(&profileSeconds, "seconds", "s", 30, "Duration of CPU profiles and traces")
+	profileCmd.Flags().StringVarP(&profileOutput, "output", "o", "", "File to save the profile to (default: <profile>-<time>.pprof)")
+	profileCmd.Flags().StringVar(&profileUser, "user", "", "Basic auth credentials, as user:password")
+	profileCmd.Flags().StringArrayVarP(&profileHeaders, "header", "H", nil, "Extra request header, as \"Name: value\"")
+	profileCmd.Flags().BoolVar(&profileOpen, "open", true, "Open the profile with go tool pprof (or go tool trace)")
+
+	rootCmd.AddCommand(profileCmd)
+}
+
+func runProfile(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	kind := "cpu"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+	target, err := profileTargetURL(profileURL, profilePath, kind, profileSeconds)
+	if err != nil {
+		fail(err)
+	}
+	output := profileOutput
+	if output == "" {
+		output = profileFileName(kind, time.Now())
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s Capturing %s profile from %s\n", cyan("Nexo"), kind, target)
+		if kind == "cpu" || kind == "trace" {
+			fmt.Printf("  Waiting %ds...\n", profileSeconds)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	data, err := fetchProfile(ctx, target, profileUser, profileHeaders)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		fail(fmt.Errorf("failed to save profile: %w", err))
+	}
+
+	if jsonOutput {
+		printSuccess(ProfileOutput{
+			Profile: kind,
+			URL:     target,
+			File:    output,
+			Size:    int64(len(data)),
+		})
+		return
+	}
+	fmt.Printf("  %s Saved %s (%s)\n\n", green("✓"), output, formatBytes(int64(len(data))))
+
+	if !profileOpen {
+		return
+	}
+	tool := exec.Command("go", "tool", "pprof", "-http=localhost:0", output)
+	if kind == "trace" {
+		tool = exec.Command("go", "tool", "trace", output)
+	}
+	tool.Stdout = os.Stdout
+	tool.Stderr = os.Stderr
+	tool.Stdin = os.Stdin
+	if err := tool.Run(); err != nil && ctx.Err() == nil {
+		fail(fmt.Errorf("failed to open profile: %w", err))
+	}
+}
+
+// profileTargetURL returns the URL of the kind profile under the
+// diagnostics endpoints at path of baseURL.
+func profileTargetURL(baseURL, path, kind string, seconds int) (string, error) {
+	name := kind
+	switch kind {
+	case "cpu":
+		name = "profile"
+	case "heap", "allocs", "goroutine", "block", "mutex", "threadcreate", "trace":
+	default:
+		return "", fmt.Errorf("unknown profile %q (use one of %s)", kind, strings.Join(profileKinds, ", "))
+	}
+
+	target := strings.TrimSuffix(baseURL, "/") + "/" + strings.Trim(path, "/") + "/pprof/" + name
+	if name == "profile" || name == "trace" {
+		target += "?" + url.Values{"seconds": {strconv.Itoa(seconds)}}.Encode()
+	}
+	return target, nil
+}
+
+// profileFileName returns the default file name for a kind profile taken
+// at t.
+func profileFileName(kind string, t time.Time) string {
+	ext := ".pprof"
+	if kind == "trace" {
+		ext = ".trace"
+	}
+	return kind + "-" + t.Format("20060102-150405") + ext
+}
+
+// fetchProfile downloads a profile from target, authenticating with user
+// (user:password) and headers ("Name: value") when given.
+func fetchProfile(ctx context.Context, target, user string, headers []string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if user != "" {
+		name, password, _ := strings.Cut(user, ":")
+		req.SetBasicAuth(name, password)
+	}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q (use \"Name: value\")", h)
+		}
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach %s (is the server running?): %w", target, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("no diagnostics endpoints at %s (enable them with nexo.WithDebug or debug.enabled)", target)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%s: pass credentials with --user or --header", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfileTargetURL(t *testing.T) {
+	tests := []struct {
+		kind    string
+		want    string
+		wantErr bool
+	}{
+		{"cpu", "http://localhost:3000/_debug/pprof/profile?seconds=10", false},
+		{"trace", "http://localhost:3000/_debug/pprof/trace?seconds=10", false},
+		{"heap", "http://localhost:3000/_debug/pprof/heap", false},
+		{"goroutine", "http://localhost:3000/_debug/pprof/goroutine", false},
+		{"bogus", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			got, err := profileTargetURL("http://localhost:3000/", "/_debug/", tt.kind, 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("url = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileFileName(t *testing.T) {
+	at := time.Date(2025, 3, 4, 15, 6, 7, 0, time.UTC)
+	if got := profileFileName("heap", at); got != "heap-20250304-150607.pprof" {
+		t.Errorf("profileFileName(heap) = %q", got)
+	}
+	if got := profileFileName("trace", at); got != "trace-20250304-150607.trace" {
+		t.Errorf("profileFileName(trace) = %q", got)
+	}
+}
+
+func TestFetchProfile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		switch {
+		case r.URL.Path != "/_debug/pprof/heap":
+			http.NotFound(w, r)
+		case !ok || user != "ops" || pass != "secret" || r.Header.Get("X-Team") != "core":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte("profile"))
+		}
+	}))
+	defer srv.Close()
+
+	data, err := fetchProfile(context.Background(), srv.URL+"/_debug/pprof/heap", "ops:secret", []string{"X-Team: core"})
+	if err != nil || string(data) != "profile" {
+		t.Errorf("fetchProfile() = %q, %v", data, err)
+	}
+
+	if _, err := fetchProfile(context.Background(), srv.URL+"/_debug/pprof/heap", "", nil); err == nil || !strings.Contains(err.Error(), "--user") {
+		t.Errorf("fetchProfile() without credentials error = %v", err)
+	}
+	if _, err := fetchProfile(context.Background(), srv.URL+"/other/pprof/heap", "", nil); err == nil || !strings.Contains(err.Error(), "WithDebug") {
+		t.Errorf("fetchProfile() of missing endpoint error = %v", err)
+	}
+}
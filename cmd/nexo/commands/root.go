@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/abdul-hamid-achik/nexo/internal/version"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
 	"github.com/spf13/cobra"
 )
 
@@ -21,14 +22,21 @@ Quick Start:
   nexo build          Build for production
   nexo routes         List all registered routes
   nexo openapi        Generate OpenAPI specifications
+  nexo doctor         Check the development environment
   nexo upgrade        Upgrade to the latest version
 
 Documentation: https://github.com/abdul-hamid-achik/nexo`,
-	Version: version.GetVersion(),
+	Version:           version.GetVersion(),
+	PersistentPreRunE: enterApp,
 }
 
+// noCache is the global flag disabling the scan cache
+var noCache bool
+
 // Execute runs the root command.
 func Execute() {
+	registerPluginCommands()
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -38,6 +46,8 @@ func Execute() {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for automation and LLM agents)")
+	rootCmd.PersistentFlags().StringVar(&appFlag, "app", "", "Directory of the app to run in, for monorepos (e.g. ./apps/web)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Parse every file instead of reusing unchanged ones from "+scanner.CacheFile)
 
 	// Commands
 	rootCmd.AddCommand(newCmd)
@@ -24,16 +24,29 @@ This command scans the app/ directory and displays:
 Examples:
   nexo routes
   nexo routes --json
+  nexo routes --order
+  nexo routes --tree
+  nexo routes --api-version v1
+  nexo routes --graph mermaid > docs/routes.mmd
+  nexo routes --graph dot | dot -Tsvg > routes.svg
   nexo routes --app-dir custom/app`,
 	Run: runRoutes,
 }
 
 var (
-	routesAppDir string
+	routesAppDir     string
+	routesOrder      bool
+	routesTree       bool
+	routesGraph      string
+	routesAPIVersion string
 )
 
 func init() {
 	routesCmd.Flags().StringVarP(&routesAppDir, "app-dir", "d", "app", "App directory to scan")
+	routesCmd.Flags().BoolVar(&routesOrder, "order", false, "List routes in effective matching order with their priorities")
+	routesCmd.Flags().BoolVar(&routesTree, "tree", false, "Show routes as a tree of URL segments with middleware, layout and proxy attachment points")
+	routesCmd.Flags().StringVar(&routesGraph, "graph", "", "Print the route tree as a graph: dot or mermaid")
+	routesCmd.Flags().StringVar(&routesAPIVersion, "api-version", "", "Only list routes and pages under an API version segment, like v1 for /api/v1/...")
 }
 
 func runRoutes(cmd *cobra.Command, args []string) {
@@ -52,7 +65,7 @@ func runRoutes(cmd *cobra.Command, args []string) {
 	}
 
 	// Scan for routes
-	scanner := nexo.NewScanner(routesAppDir)
+	scanner := newScanner(routesAppDir)
 
 	// Check for proxy
 	proxyInfo, proxyErr := scanner.ScanProxyInfo()
@@ -72,13 +85,17 @@ func runRoutes(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Sort routes by pattern
-	sort.Slice(routes, func(i, j int) bool {
-		if routes[i].Pattern != routes[j].Pattern {
-			return routes[i].Pattern < routes[j].Pattern
-		}
-		return routes[i].Method < routes[j].Method
-	})
+	// Sort routes by matching order, or by pattern
+	if routesOrder {
+		nexo.SortRouteInfo(routes)
+	} else {
+		sort.Slice(routes, func(i, j int) bool {
+			if routes[i].Pattern != routes[j].Pattern {
+				return routes[i].Pattern < routes[j].Pattern
+			}
+			return routes[i].Method < routes[j].Method
+		})
+	}
 
 	// Scan for pages
 	pages, pageErr := scanner.ScanPageInfo()
@@ -104,6 +121,41 @@ func runRoutes(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Keep only the requested API version
+	if routesAPIVersion != "" {
+		routes, pages = filterRoutesByVersion(routes, pages, routesAPIVersion)
+	}
+
+	// Tree and graph output modes
+	if routesTree || routesGraph != "" {
+		if proxyErr != nil {
+			proxyInfo = nil
+		}
+		if mwErr != nil {
+			middlewares = nil
+		}
+		root := buildRouteTree(routes, pages, middlewares, layouts, proxyInfo)
+		switch {
+		case routesGraph != "":
+			if err := writeRouteGraph(os.Stdout, root, routesGraph); err != nil {
+				if jsonOutput {
+					printJSONError(err)
+				} else {
+					red := color.New(color.FgRed).SprintFunc()
+					fmt.Printf("  %s %v\n", red("Error:"), err)
+				}
+				os.Exit(1)
+			}
+		case jsonOutput:
+			printSuccess(root)
+		default:
+			fmt.Printf("\n")
+			printRouteTree(os.Stdout, root)
+			fmt.Printf("\n")
+		}
+		return
+	}
+
 	// JSON output mode
 	if jsonOutput {
 		output := RoutesOutput{
@@ -133,6 +185,7 @@ func runRoutes(cmd *cobra.Command, args []string) {
 				output.Middleware = append(output.Middleware, MiddlewareOutput{
 					Path: path,
 					File: mw.FilePath,
+					Name: mw.Name,
 				})
 			}
 		}
@@ -140,10 +193,15 @@ func runRoutes(cmd *cobra.Command, args []string) {
 		// Add routes
 		for _, r := range routes {
 			output.Routes = append(output.Routes, RouteOutput{
-				Method:   r.Method,
-				Pattern:  r.Pattern,
-				File:     r.FilePath,
-				Priority: r.Priority,
+				Method:           r.Method,
+				Pattern:          r.Pattern,
+				File:             r.FilePath,
+				Priority:         r.Priority,
+				PriorityOverride: r.PriorityOverride,
+				Version:          nexo.PatternVersion(r.Pattern),
+				Middleware:       r.Middleware,
+				Skipped:          r.Skipped,
+				Doc:              r.Doc,
 			})
 		}
 
@@ -194,6 +252,9 @@ func runRoutes(cmd *cobra.Command, args []string) {
 			if path == "" {
 				path = "/"
 			}
+			if mw.Name != "" {
+				path += " (" + mw.Name + ")"
+			}
 			fmt.Printf("        %s  %s\n", fmt.Sprintf("%-30s", path), dim(mw.FilePath))
 		}
 		fmt.Printf("\n")
@@ -221,12 +282,28 @@ func runRoutes(cmd *cobra.Command, args []string) {
 	if len(routes) > 0 {
 		fmt.Printf("  %s\n\n", cyan("API Routes:"))
 		for _, route := range routes {
-			fmt.Printf("  %s %s  %s\n",
+			priority := ""
+			if routesOrder {
+				priority = fmt.Sprintf("%4d  ", route.Priority)
+				if route.PriorityOverride {
+					priority = magenta(fmt.Sprintf("%4d* ", route.Priority))
+				}
+			}
+			skipped := ""
+			if len(route.Skipped) > 0 {
+				skipped = yellow(fmt.Sprintf(" [skips: %s]", strings.Join(route.Skipped, ", ")))
+			}
+			fmt.Printf("  %s%s %s  %s%s\n",
+				priority,
 				methodColor(route.Method),
 				fmt.Sprintf("%-30s", route.Pattern),
 				dim(route.FilePath),
+				skipped,
 			)
 		}
+		if routesOrder {
+			fmt.Printf("\n  %s\n", dim("* priority set by a nexo:priority directive"))
+		}
 	}
 
 	// Print pages section (only if pages exist)
@@ -264,6 +341,27 @@ func runRoutes(cmd *cobra.Command, args []string) {
 	fmt.Printf("\n  Total: %d API routes, %d pages\n\n", len(routes), len(pages))
 }
 
+// filterRoutesByVersion returns the routes and pages whose pattern has the
+// version segment version ("v1" or "1").
+func filterRoutesByVersion(routes []nexo.RouteInfo, pages []nexo.PageInfo, version string) ([]nexo.RouteInfo, []nexo.PageInfo) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	var keptRoutes []nexo.RouteInfo
+	for _, r := range routes {
+		if nexo.PatternVersion(r.Pattern) == version {
+			keptRoutes = append(keptRoutes, r)
+		}
+	}
+	var keptPages []nexo.PageInfo
+	for _, p := range pages {
+		if nexo.PatternVersion(p.Pattern) == version {
+			keptPages = append(keptPages, p)
+		}
+	}
+	return keptRoutes, keptPages
+}
+
 // findLayoutForPage returns the layout file path that applies to a page pattern.
 // It finds the most specific layout that matches the page path.
 func findLayoutForPage(pagePattern string, layouts []nexo.LayoutInfo) string {
@@ -1,8 +1,13 @@
 package commands
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
@@ -68,6 +73,26 @@ func TestFindLayoutForPage(t *testing.T) {
 	}
 }
 
+func TestFilterRoutesByVersion(t *testing.T) {
+	routes := []nexo.RouteInfo{
+		{Method: "GET", Pattern: "/api/v1/users"},
+		{Method: "GET", Pattern: "/api/v2/users"},
+		{Method: "GET", Pattern: "/api/health"},
+	}
+	pages := []nexo.PageInfo{
+		{Pattern: "/docs/v1"},
+		{Pattern: "/about"},
+	}
+
+	gotRoutes, gotPages := filterRoutesByVersion(routes, pages, "1")
+	if len(gotRoutes) != 1 || gotRoutes[0].Pattern != "/api/v1/users" {
+		t.Errorf("routes = %+v, want only /api/v1/users", gotRoutes)
+	}
+	if len(gotPages) != 1 || gotPages[0].Pattern != "/docs/v1" {
+		t.Errorf("pages = %+v, want only /docs/v1", gotPages)
+	}
+}
+
 func TestRoutesScanning_EmptyProject(t *testing.T) {
 	tmpDir := t.TempDir()
 	appDir := filepath.Join(tmpDir, "app")
@@ -232,6 +257,57 @@ func Middleware() nexo.MiddlewareFunc {
 	}
 }
 
+func TestRoutesScanning_SkippedMiddleware(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	files := map[string]string{
+		filepath.Join(appDir, "api", "middleware.go"): `package api
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }
+`,
+		filepath.Join(appDir, "api", "auth", "login", "route.go"): `package login
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// nexo:route skip=/api
+func Post(c *nexo.Context) error { return nil }
+`,
+		filepath.Join(appDir, "api", "users", "route.go"): `package users
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Get(c *nexo.Context) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	routes, err := nexo.NewScanner(appDir).ScanRouteInfo()
+	if err != nil {
+		t.Fatalf("ScanRouteInfo failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, r := range routes {
+		got[r.Pattern] = fmt.Sprintf("runs %v, skips %v", r.Middleware, r.Skipped)
+	}
+	want := map[string]string{
+		"/api/auth/login": "runs [], skips [/api]",
+		"/api/users":      "runs [/api], skips []",
+	}
+	for pattern, w := range want {
+		if got[pattern] != w {
+			t.Errorf("%s %s, want %s", pattern, got[pattern], w)
+		}
+	}
+}
+
 func TestRoutesScanning_WithProxy(t *testing.T) {
 	tmpDir := t.TempDir()
 	appDir := filepath.Join(tmpDir, "app")
@@ -338,7 +414,73 @@ func Get(c *nexo.Context) error {
 		t.Errorf("Expected 1 route, got %d", len(routes))
 	}
 
-	if len(routes) > 0 && routes[0].Pattern != "/api/docs/*" {
-		t.Errorf("Expected pattern /api/docs/*, got %s", routes[0].Pattern)
+	if len(routes) > 0 && routes[0].Pattern != "/api/docs/{slug...}" {
+		t.Errorf("Expected pattern /api/docs/{slug...}, got %s", routes[0].Pattern)
+	}
+}
+
+func TestBuildRouteTree(t *testing.T) {
+	root := buildRouteTree(
+		[]nexo.RouteInfo{
+			{Method: "POST", Pattern: "/api/users"},
+			{Method: "GET", Pattern: "/api/users"},
+			{Method: "DELETE", Pattern: "/api/users/{id}"},
+		},
+		[]nexo.PageInfo{{Pattern: "/dashboard", FilePath: "app/dashboard/page.templ"}},
+		[]nexo.MiddlewareInfo{{Path: "/api", FilePath: "app/api/middleware.go"}},
+		[]nexo.LayoutInfo{{PathPrefix: "/", FilePath: "app/layout.templ"}},
+		&nexo.ProxyInfo{HasProxy: true, FilePath: "app/proxy.go"},
+	)
+
+	var buf bytes.Buffer
+	printRouteTree(&buf, root)
+	want := []string{
+		"  /                                         [proxy] [layout]",
+		"  ├── api                                   [middleware]",
+		"  │   └── users                             GET POST",
+		"  │       └── {id}                          DELETE",
+		"  └── dashboard                             page",
+	}
+	if got := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"); !slices.Equal(got, want) {
+		t.Errorf("printRouteTree() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	users := root.Children[0].Children[0]
+	if users.Path != "/api/users" || users.Children[0].Path != "/api/users/{id}" {
+		t.Errorf("paths = %q, %q", users.Path, users.Children[0].Path)
+	}
+}
+
+func TestWriteRouteGraph(t *testing.T) {
+	root := buildRouteTree(
+		[]nexo.RouteInfo{{Method: "GET", Pattern: "/api/health"}},
+		nil, nil,
+		[]nexo.LayoutInfo{{PathPrefix: "/", FilePath: "app/layout.templ"}},
+		nil,
+	)
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{"digraph routes {", `n0 [label="/\n[layout]"];`, `n2 [label="health\nGET"];`, "n0 -> n1;", "n1 -> n2;"}},
+		{"mermaid", []string{"graph LR", `n0["/<br/>[layout]"]`, `n2["health<br/>GET"]`, "n0 --> n1", "n1 --> n2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeRouteGraph(&buf, root, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("graph missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	if err := writeRouteGraph(io.Discard, root, "svg"); err == nil {
+		t.Error("writeRouteGraph(svg) error = nil, want unknown format")
 	}
 }
@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+)
+
+// buildRouteTree arranges the discovered routes, pages, middleware, layouts
+// and proxy into a tree of URL segments rooted at "/".
+func buildRouteTree(routes []nexo.RouteInfo, pages []nexo.PageInfo, middlewares []nexo.MiddlewareInfo, layouts []nexo.LayoutInfo, proxy *nexo.ProxyInfo) *RouteNodeOutput {
+	root := &RouteNodeOutput{Segment: "/", Path: "/"}
+
+	for _, r := range routes {
+		n := routeTreeNode(root, r.Pattern)
+		n.Methods = append(n.Methods, r.Method)
+	}
+	for _, p := range pages {
+		routeTreeNode(root, p.Pattern).Page = p.FilePath
+	}
+	for _, mw := range middlewares {
+		routeTreeNode(root, mw.Path).Middleware = mw.FilePath
+	}
+	for _, l := range layouts {
+		routeTreeNode(root, l.PathPrefix).Layout = l.FilePath
+	}
+	if proxy != nil && proxy.HasProxy {
+		root.Proxy = proxy.FilePath
+	}
+
+	sortRouteTree(root)
+	return root
+}
+
+// routeTreeNode returns the node for pattern, adding it and any missing
+// parents to root.
+func routeTreeNode(root *RouteNodeOutput, pattern string) *RouteNodeOutput {
+	n := root
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		var child *RouteNodeOutput
+		for _, c := range n.Children {
+			if c.Segment == seg {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = &RouteNodeOutput{Segment: seg, Path: strings.TrimSuffix(n.Path, "/") + "/" + seg}
+			n.Children = append(n.Children, child)
+		}
+		n = child
+	}
+	return n
+}
+
+// sortRouteTree sorts the children and methods of every node in n.
+func sortRouteTree(n *RouteNodeOutput) {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Segment < n.Children[j].Segment
+	})
+	sort.Slice(n.Methods, func(i, j int) bool {
+		return methodOrder(n.Methods[i]) < methodOrder(n.Methods[j])
+	})
+	for _, c := range n.Children {
+		sortRouteTree(c)
+	}
+}
+
+// methodOrder orders HTTP methods the way they are usually listed.
+func methodOrder(method string) int {
+	for i, m := range []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"} {
+		if m == method {
+			return i
+		}
+	}
+	return 100
+}
+
+// routeNodeSummary lists what is served at n and what is attached to it,
+// e.g. "GET POST page" and "[middleware] [layout]".
+func routeNodeSummary(n *RouteNodeOutput) (served, attached []string) {
+	served = append(served, n.Methods...)
+	if n.Page != "" {
+		served = append(served, "page")
+	}
+	if n.Proxy != "" {
+		attached = append(attached, "[proxy]")
+	}
+	if n.Middleware != "" {
+		attached = append(attached, "[middleware]")
+	}
+	if n.Layout != "" {
+		attached = append(attached, "[layout]")
+	}
+	return served, attached
+}
+
+// printRouteTree writes root as an indented tree, one URL segment per line.
+func printRouteTree(w io.Writer, root *RouteNodeOutput) {
+	green := color.New(color.FgGreen).SprintFunc()
+	magenta := color.New(color.FgMagenta).SprintFunc()
+
+	var walk func(n *RouteNodeOutput, prefix, branch string)
+	walk = func(n *RouteNodeOutput, prefix, branch string) {
+		served, attached := routeNodeSummary(n)
+		line := prefix + branch + n.Segment
+		if len(served) > 0 || len(attached) > 0 {
+			line = fmt.Sprintf("%-40s", line)
+		}
+		if len(served) > 0 {
+			line += "  " + green(strings.Join(served, " "))
+		}
+		if len(attached) > 0 {
+			line += "  " + magenta(strings.Join(attached, " "))
+		}
+		_, _ = fmt.Fprintln(w, "  "+strings.TrimRight(line, " "))
+
+		switch branch {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+		for i, c := range n.Children {
+			if i == len(n.Children)-1 {
+				walk(c, prefix, "└── ")
+			} else {
+				walk(c, prefix, "├── ")
+			}
+		}
+	}
+	walk(root, "", "")
+}
+
+// writeRouteGraph writes root as a Graphviz (format "dot") or Mermaid
+// (format "mermaid") graph.
+func writeRouteGraph(w io.Writer, root *RouteNodeOutput, format string) error {
+	var nodes, edges []string
+	ids := make(map[*RouteNodeOutput]string)
+
+	var walk func(n *RouteNodeOutput)
+	walk = func(n *RouteNodeOutput) {
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[n] = id
+
+		served, attached := routeNodeSummary(n)
+		label := []string{n.Segment}
+		if len(served) > 0 {
+			label = append(label, strings.Join(served, " "))
+		}
+		if len(attached) > 0 {
+			label = append(label, strings.Join(attached, " "))
+		}
+
+		switch format {
+		case "dot":
+			nodes = append(nodes, fmt.Sprintf("  %s [label=%q];", id, strings.Join(label, "\n")))
+		case "mermaid":
+			text := strings.ReplaceAll(strings.Join(label, "<br/>"), `"`, "#quot;")
+			nodes = append(nodes, fmt.Sprintf("  %s[\"%s\"]", id, text))
+		}
+
+		for _, c := range n.Children {
+			walk(c)
+			switch format {
+			case "dot":
+				edges = append(edges, fmt.Sprintf("  %s -> %s;", id, ids[c]))
+			case "mermaid":
+				edges = append(edges, fmt.Sprintf("  %s --> %s", id, ids[c]))
+			}
+		}
+	}
+
+	switch format {
+	case "dot":
+		walk(root)
+		_, _ = fmt.Fprintln(w, "digraph routes {")
+		_, _ = fmt.Fprintln(w, "  rankdir=LR;")
+		_, _ = fmt.Fprintln(w, "  node [shape=box, fontname=\"monospace\"];")
+	case "mermaid":
+		walk(root)
+		_, _ = fmt.Fprintln(w, "graph LR")
+	default:
+		return fmt.Errorf("unknown graph format %q (use dot or mermaid)", format)
+	}
+	for _, line := range append(nodes, edges...) {
+		_, _ = fmt.Fprintln(w, line)
+	}
+	if format == "dot" {
+		_, _ = fmt.Fprintln(w, "}")
+	}
+	return nil
+}
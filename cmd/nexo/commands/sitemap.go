@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/generator"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var sitemapCmd = &cobra.Command{
+	Use:   "sitemap",
+	Short: "Generate sitemap.xml from the page tree",
+	Long: `Write a sitemap.xml listing the pages in app/.
+
+Static pages are always listed. Dynamic pages (app/posts/[slug]) are listed
+when their package declares
+
+  func GenerateStaticParams(ctx context.Context) ([]map[string]string, error)
+
+which is called to enumerate their URLs. Annotate a page.templ with
+
+  // nexo:sitemap changefreq=weekly priority=0.8
+
+or leave it out with "// nexo:sitemap exclude".
+
+The base URL defaults to sitemap.base_url in nexo.yaml. To serve the sitemap
+at runtime instead, enable it with nexo.WithSitemap.
+
+Examples:
+  nexo sitemap --base-url https://acme.com
+  nexo sitemap --output public/sitemap.xml`,
+	Run: runSitemap,
+}
+
+var (
+	sitemapBaseURL string
+	sitemapOutput  string
+	sitemapAppDir  string
+)
+
+func init() {
+	sitemapCmd.Flags().StringVar(&sitemapBaseURL, "base-url", "", "URL prefix of every page (default: sitemap.base_url in nexo.yaml)")
+	sitemapCmd.Flags().StringVarP(&sitemapOutput, "output", "o", filepath.Join("static", "sitemap.xml"), "Output file path")
+	sitemapCmd.Flags().StringVarP(&sitemapAppDir, "app-dir", "d", "app", "App directory to scan")
+
+	rootCmd.AddCommand(sitemapCmd)
+}
+
+func runSitemap(cmd *cobra.Command, args []string) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fail := func(err error) {
+		if jsonOutput {
+			printJSONError(err)
+		} else {
+			fmt.Printf("  %s %v\n\n", red("Error:"), err)
+		}
+		os.Exit(1)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("\n  %s sitemap\n\n", cyan("Nexo"))
+	}
+
+	baseURL := sitemapBaseURL
+	if baseURL == "" {
+		cfg, err := nexo.LoadConfig("")
+		if err != nil {
+			fail(err)
+		}
+		baseURL = cfg.Sitemap.BaseURL
+	}
+	if baseURL == "" {
+		fail(fmt.Errorf("no base URL: pass --base-url or set sitemap.base_url in nexo.yaml"))
+	}
+
+	result, err := writeSitemap(sitemapAppDir, baseURL, sitemapOutput)
+	if err != nil {
+		fail(err)
+	}
+
+	if jsonOutput {
+		printSuccess(result)
+		return
+	}
+	fmt.Printf("  %s Wrote %s (%d pages, %d dynamic)\n\n", green("✓"), result.File, result.Pages, result.Dynamic)
+}
+
+// writeSitemap writes the sitemap of the pages in appDir to output. Static
+// pages are written directly; dynamic pages need their GenerateStaticParams
+// functions, so a generated program is run to call them.
+func writeSitemap(appDir, baseURL, output string) (*SitemapOutput, error) {
+	pages, err := generator.ScanSitemapPages(appDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan pages: %w", err)
+	}
+
+	result := &SitemapOutput{File: output, Pages: len(pages)}
+	for _, p := range pages {
+		if len(p.URLParams) > 0 {
+			result.Dynamic++
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, err
+	}
+
+	if result.Dynamic > 0 {
+		return result, runSitemapProgram(pages, baseURL, output)
+	}
+
+	sitemap := nexo.NewSitemap()
+	for _, p := range pages {
+		sitemap.Add(p.Pattern, staticSitemapOptions(p.Sitemap))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+	if err := sitemap.WriteXML(context.Background(), f, baseURL); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return result, f.Close()
+}
+
+// staticSitemapOptions converts the annotations of a static page.
+func staticSitemapOptions(sm generator.PageSitemap) nexo.SitemapOptions {
+	opts := nexo.SitemapOptions{ChangeFreq: sm.ChangeFreq}
+	// The generator validated the priority
+	opts.Priority, _ = strconv.ParseFloat(sm.Priority, 64)
+	return opts
+}
+
+// runSitemapProgram generates a program in .nexo/sitemap that imports the
+// dynamic pages and writes the sitemap, and runs it with go run.
+func runSitemapProgram(pages []generator.PageRegistration, baseURL, output string) error {
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+	src, err := generator.GenerateSitemapProgram(pages, generator.SitemapConfig{BaseURL: baseURL, OutputPath: absOutput})
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(".nexo", "sitemap")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0644); err != nil {
+		return err
+	}
+
+	run := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("failed to run GenerateStaticParams: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSitemap_StaticPages(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"go.mod":               "module testmodule\ngo 1.21\n",
+		"app/page.templ":       "package app\n\n// nexo:sitemap changefreq=daily priority=1.0\ntempl Page() {}\n",
+		"app/about/page.templ": "package about\n\ntempl Page() {}\n",
+		"app/admin/page.templ": "package admin\n\n// nexo:sitemap exclude\ntempl Page() {}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := filepath.Join("static", "sitemap.xml")
+	result, err := writeSitemap("app", "https://acme.test", output)
+	if err != nil {
+		t.Fatalf("writeSitemap() error = %v", err)
+	}
+	if result.Pages != 2 || result.Dynamic != 0 {
+		t.Errorf("result = %+v, want 2 static pages", result)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+	for _, w := range []string{
+		"<url><loc>https://acme.test/</loc><changefreq>daily</changefreq><priority>1.0</priority></url>",
+		"<url><loc>https://acme.test/about</loc></url>",
+	} {
+		if !strings.Contains(got, w) {
+			t.Errorf("sitemap missing %s\n\ngot:\n%s", w, got)
+		}
+	}
+	if strings.Contains(got, "/admin") {
+		t.Errorf("excluded page in sitemap:\n%s", got)
+	}
+}
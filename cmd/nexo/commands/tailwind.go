@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"syscall"
 
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
 	"github.com/abdul-hamid-achik/nexo/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -58,6 +59,12 @@ var tailwindInstallCmd = &cobra.Command{
 	Long: `Download the Tailwind CSS standalone binary.
 
 The binary is cached at ~/.cache/nexo/bin/ and shared across projects.
+Pin a version, checksum or per-project cache under tailwind: in nexo.yaml:
+
+  tailwind:
+    version: 4.1.4
+    sha256: <checksum of the binary for your platform>
+    cache_dir: .nexo/bin
 
 Examples:
   nexo tailwind install`,
@@ -82,11 +89,11 @@ var (
 
 func init() {
 	// Add flags to build and watch commands
-	tailwindBuildCmd.Flags().StringVarP(&tailwindInput, "input", "i", "", "Input CSS file (default: styles/input.css)")
-	tailwindBuildCmd.Flags().StringVarP(&tailwindOutput, "output", "o", "", "Output CSS file (default: static/css/output.css)")
+	tailwindBuildCmd.Flags().StringVarP(&tailwindInput, "input", "i", "", "Input CSS file (default: tailwind.input in nexo.yaml)")
+	tailwindBuildCmd.Flags().StringVarP(&tailwindOutput, "output", "o", "", "Output CSS file (default: tailwind.output in nexo.yaml)")
 
-	tailwindWatchCmd.Flags().StringVarP(&tailwindInput, "input", "i", "", "Input CSS file (default: styles/input.css)")
-	tailwindWatchCmd.Flags().StringVarP(&tailwindOutput, "output", "o", "", "Output CSS file (default: static/css/output.css)")
+	tailwindWatchCmd.Flags().StringVarP(&tailwindInput, "input", "i", "", "Input CSS file (default: tailwind.input in nexo.yaml)")
+	tailwindWatchCmd.Flags().StringVarP(&tailwindOutput, "output", "o", "", "Output CSS file (default: tailwind.output in nexo.yaml)")
 
 	// Add subcommands
 	tailwindCmd.AddCommand(tailwindBuildCmd)
@@ -95,6 +102,63 @@ func init() {
 	tailwindCmd.AddCommand(tailwindInfoCmd)
 }
 
+// tailwindProject is the Tailwind setup configured under tailwind: in
+// nexo.yaml.
+type tailwindProject struct {
+	cli    *tools.TailwindCLI
+	input  string
+	output string
+}
+
+// loadTailwindProject reads the tailwind: section of nexo.yaml, falling back
+// to the default paths and version.
+func loadTailwindProject() *tailwindProject {
+	cfg, err := nexo.LoadConfig("")
+	if err != nil {
+		cfg = nexo.DefaultConfig()
+	}
+	tc := cfg.Tailwind
+
+	input, output := tc.Input, tc.Output
+	if input == "" {
+		input = tools.DefaultInputPath()
+	}
+	if output == "" {
+		output = tools.DefaultOutputPath()
+	}
+
+	return &tailwindProject{
+		cli: tools.NewTailwindCLIWithOptions(tools.TailwindOptions{
+			Version:  tc.Version,
+			SHA256:   tc.SHA256,
+			CacheDir: tc.CacheDir,
+			Binary:   tc.Binary,
+		}),
+		input:  input,
+		output: output,
+	}
+}
+
+// hasStyles reports whether the input stylesheet exists
+func (p *tailwindProject) hasStyles() bool {
+	_, err := os.Stat(p.input)
+	return err == nil
+}
+
+// needsInitialBuild reports whether the output stylesheet has to be built
+func (p *tailwindProject) needsInitialBuild() bool {
+	if !p.hasStyles() {
+		return false
+	}
+	_, err := os.Stat(p.output)
+	return os.IsNotExist(err)
+}
+
+// build compiles the configured stylesheet
+func (p *tailwindProject) build() error {
+	return p.cli.Build(p.input, p.output)
+}
+
 func runTailwindBuild(cmd *cobra.Command, args []string) {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
@@ -105,14 +169,15 @@ func runTailwindBuild(cmd *cobra.Command, args []string) {
 		fmt.Printf("\n  %s Tailwind Build\n\n", cyan("Nexo"))
 	}
 
-	// Determine input/output paths
+	// Determine input/output paths, flags taking precedence over nexo.yaml
+	project := loadTailwindProject()
 	input := tailwindInput
 	if input == "" {
-		input = tools.DefaultInputPath()
+		input = project.input
 	}
 	output := tailwindOutput
 	if output == "" {
-		output = tools.DefaultOutputPath()
+		output = project.output
 	}
 
 	// Check if input exists
@@ -131,8 +196,7 @@ func runTailwindBuild(cmd *cobra.Command, args []string) {
 		fmt.Printf("  %s Building CSS...\n", yellow("→"))
 	}
 
-	tw := tools.NewTailwindCLI()
-	if err := tw.Build(input, output); err != nil {
+	if err := project.cli.Build(input, output); err != nil {
 		if jsonOutput {
 			printJSONError(fmt.Errorf("tailwind build failed: %w", err))
 		} else {
@@ -176,14 +240,15 @@ func runTailwindWatch(cmd *cobra.Command, args []string) {
 
 	fmt.Printf("\n  %s Tailwind Watch\n\n", cyan("Nexo"))
 
-	// Determine input/output paths
+	// Determine input/output paths, flags taking precedence over nexo.yaml
+	project := loadTailwindProject()
 	input := tailwindInput
 	if input == "" {
-		input = tools.DefaultInputPath()
+		input = project.input
 	}
 	output := tailwindOutput
 	if output == "" {
-		output = tools.DefaultOutputPath()
+		output = project.output
 	}
 
 	// Check if input exists
@@ -195,8 +260,7 @@ func runTailwindWatch(cmd *cobra.Command, args []string) {
 
 	fmt.Printf("  %s Starting Tailwind watch mode...\n", yellow("→"))
 
-	tw := tools.NewTailwindCLI()
-	proc, err := tw.Watch(input, output)
+	proc, err := project.cli.Watch(input, output)
 	if err != nil {
 		fmt.Printf("  %s Failed to start Tailwind: %v\n", red("Error:"), err)
 		os.Exit(1)
@@ -227,7 +291,7 @@ func runTailwindInstall(cmd *cobra.Command, args []string) {
 		fmt.Printf("\n  %s Tailwind Install\n\n", cyan("Nexo"))
 	}
 
-	tw := tools.NewTailwindCLI()
+	tw := loadTailwindProject().cli
 
 	// Check if already installed
 	if tw.IsInstalled() {
@@ -281,7 +345,8 @@ func runTailwindInfo(cmd *cobra.Command, args []string) {
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 
-	tw := tools.NewTailwindCLI()
+	project := loadTailwindProject()
+	tw := project.cli
 	installed := tw.IsInstalled()
 	version := ""
 	if installed {
@@ -289,8 +354,8 @@ func runTailwindInfo(cmd *cobra.Command, args []string) {
 	}
 
 	// Check project styles
-	hasStyles := tools.HasStyles()
-	needsBuild := tools.NeedsInitialBuild()
+	hasStyles := project.hasStyles()
+	needsBuild := project.needsInitialBuild()
 
 	if jsonOutput {
 		printSuccess(map[string]any{
@@ -301,8 +366,8 @@ func runTailwindInfo(cmd *cobra.Command, args []string) {
 			"cacheDir":          tw.CacheDir(),
 			"hasStyles":         hasStyles,
 			"needsInitialBuild": needsBuild,
-			"defaultInput":      tools.DefaultInputPath(),
-			"defaultOutput":     tools.DefaultOutputPath(),
+			"input":             project.input,
+			"output":            project.output,
 		})
 	} else {
 		fmt.Printf("\n  %s Tailwind Info\n\n", cyan("Nexo"))
@@ -322,7 +387,7 @@ func runTailwindInfo(cmd *cobra.Command, args []string) {
 		// Project status
 		fmt.Printf("\n  Project:\n")
 		if hasStyles {
-			fmt.Printf("  %s styles/input.css found\n", green("✓"))
+			fmt.Printf("  %s %s found\n", green("✓"), project.input)
 			if needsBuild {
 				fmt.Printf("  %s Output CSS needs to be built\n", yellow("○"))
 				fmt.Printf("  Run: nexo tailwind build\n")
@@ -330,7 +395,7 @@ func runTailwindInfo(cmd *cobra.Command, args []string) {
 				fmt.Printf("  %s Output CSS exists\n", green("✓"))
 			}
 		} else {
-			fmt.Printf("  %s No styles/input.css found\n", yellow("○"))
+			fmt.Printf("  %s No %s found\n", yellow("○"), project.input)
 			fmt.Printf("  This project may not use Tailwind\n")
 		}
 
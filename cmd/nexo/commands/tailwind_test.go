@@ -176,3 +176,46 @@ func TestTailwindCLI_IsInstalled(t *testing.T) {
 		t.Error("Expected IsInstalled() = false in empty cache dir")
 	}
 }
+
+func TestLoadTailwindProject(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	project := loadTailwindProject()
+	if project.input != "styles/input.css" || project.output != "static/css/output.css" {
+		t.Errorf("default paths = %q -> %q", project.input, project.output)
+	}
+	if project.cli.Version() != tools.TailwindVersion {
+		t.Errorf("default version = %q, want %q", project.cli.Version(), tools.TailwindVersion)
+	}
+
+	config := `tailwind:
+  input: assets/app.css
+  output: public/app.css
+  version: 4.1.4
+  cache_dir: .nexo/bin
+`
+	if err := os.WriteFile("nexo.yaml", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	project = loadTailwindProject()
+	if project.input != "assets/app.css" || project.output != "public/app.css" {
+		t.Errorf("configured paths = %q -> %q", project.input, project.output)
+	}
+	if project.cli.Version() != "4.1.4" || project.cli.CacheDir() != ".nexo/bin" {
+		t.Errorf("configured cli = %q in %q", project.cli.Version(), project.cli.CacheDir())
+	}
+	if project.hasStyles() {
+		t.Error("hasStyles() = true without assets/app.css")
+	}
+
+	if err := os.MkdirAll("assets", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("assets/app.css", []byte("@import 'tailwindcss';\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !project.hasStyles() || !project.needsInitialBuild() {
+		t.Errorf("hasStyles() = %v, needsInitialBuild() = %v, want both true", project.hasStyles(), project.needsInitialBuild())
+	}
+}
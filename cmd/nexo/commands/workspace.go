@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+	"github.com/spf13/cobra"
+)
+
+// appFlag is the global --app flag: the directory of the app to run in,
+// for monorepos with several apps
+var appFlag string
+
+// enterApp changes to the directory of --app before any command runs, so
+// the app's nexo.yaml, go.mod and app directory are found as in a
+// single-app project.
+func enterApp(cmd *cobra.Command, args []string) error {
+	if appFlag == "" {
+		return nil
+	}
+	if err := os.Chdir(appFlag); err != nil {
+		return fmt.Errorf("--app: %w", err)
+	}
+	return nil
+}
+
+// workspaceApps returns the modules of the go.work in the working
+// directory that have a main.go, i.e. the apps --app can choose.
+func workspaceApps() []string {
+	modules, err := scanner.WorkspaceModules(".")
+	if err != nil {
+		return nil
+	}
+	var apps []string
+	for _, dir := range modules {
+		if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+			apps = append(apps, dir)
+		}
+	}
+	return apps
+}
+
+// noMainGoError is the error of commands run outside of an app. At the
+// root of a go.work workspace it lists the apps to choose with --app.
+func noMainGoError() error {
+	if apps := workspaceApps(); len(apps) > 0 {
+		return fmt.Errorf("no main.go found in current directory; choose an app of the workspace with --app (%s)", strings.Join(apps, ", "))
+	}
+	return fmt.Errorf("no main.go found in current directory")
+}
@@ -0,0 +1,130 @@
+package bundler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// ESBuildAPI is the default Engine. It bundles with esbuild's Go API, so
+// no esbuild install is needed.
+type ESBuildAPI struct{}
+
+// Build bundles the entry points once and returns the files listed in the
+// metafile.
+func (e *ESBuildAPI) Build(ctx context.Context, opts Options) ([]Output, error) {
+	buildOpts, err := esbuildOptions(opts)
+	if err != nil {
+		return nil, &BuildError{EntryPoints: opts.EntryPoints, Err: err}
+	}
+	esctx, ctxErr := api.Context(buildOpts)
+	if ctxErr != nil {
+		return nil, buildError(opts, ctxErr.Errors)
+	}
+	defer esctx.Dispose()
+
+	stop := context.AfterFunc(ctx, esctx.Cancel)
+	defer stop()
+
+	result := esctx.Rebuild()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, buildError(opts, result.Errors)
+	}
+	return parseMetafile([]byte(result.Metafile))
+}
+
+// Watch starts watching the entry points and their imports. It stops when
+// ctx is done.
+func (e *ESBuildAPI) Watch(ctx context.Context, opts Options) error {
+	buildOpts, err := esbuildOptions(opts)
+	if err != nil {
+		return &BuildError{EntryPoints: opts.EntryPoints, Err: err}
+	}
+	esctx, ctxErr := api.Context(buildOpts)
+	if ctxErr != nil {
+		return buildError(opts, ctxErr.Errors)
+	}
+	if err := esctx.Watch(api.WatchOptions{}); err != nil {
+		esctx.Dispose()
+		return err
+	}
+	context.AfterFunc(ctx, esctx.Dispose)
+	return nil
+}
+
+// esbuildOptions returns the esbuild build options for opts, matching the
+// command line of esbuildArgs.
+func esbuildOptions(opts Options) (api.BuildOptions, error) {
+	target, err := esbuildTarget(opts.Target)
+	if err != nil {
+		return api.BuildOptions{}, err
+	}
+	buildOpts := api.BuildOptions{
+		EntryPoints: opts.EntryPoints,
+		Bundle:      true,
+		Format:      api.FormatESModule,
+		Outdir:      opts.OutDir,
+		Target:      target,
+		External:    opts.External,
+		Define:      opts.Define,
+		LogLevel:    api.LogLevelWarning,
+		Metafile:    true,
+		Write:       true,
+	}
+	if opts.Dev {
+		buildOpts.Sourcemap = api.SourceMapLinked
+		buildOpts.EntryNames = "[dir]/[name]"
+	} else {
+		buildOpts.MinifyWhitespace = true
+		buildOpts.MinifyIdentifiers = true
+		buildOpts.MinifySyntax = true
+		buildOpts.EntryNames = "[dir]/[name]-[hash]"
+	}
+	return buildOpts, nil
+}
+
+// esbuildTargets maps the language targets to the Go API's.
+var esbuildTargets = map[string]api.Target{
+	"":       api.DefaultTarget,
+	"esnext": api.ESNext,
+	"es5":    api.ES5,
+	"es6":    api.ES2015,
+	"es2015": api.ES2015,
+	"es2016": api.ES2016,
+	"es2017": api.ES2017,
+	"es2018": api.ES2018,
+	"es2019": api.ES2019,
+	"es2020": api.ES2020,
+	"es2021": api.ES2021,
+	"es2022": api.ES2022,
+	"es2023": api.ES2023,
+	"es2024": api.ES2024,
+	"es2025": api.ES2025,
+}
+
+// esbuildTarget returns the Go API target of a language target like
+// "es2020". Browser targets like "chrome90" need the esbuild binary.
+func esbuildTarget(target string) (api.Target, error) {
+	t, ok := esbuildTargets[strings.ToLower(target)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported target %q: use es5 to es2025 or esnext, or set js.esbuild to use the esbuild binary", target)
+	}
+	return t, nil
+}
+
+// buildError returns a BuildError with esbuild's messages as its
+// diagnostics.
+func buildError(opts Options, msgs []api.Message) error {
+	formatted := api.FormatMessages(msgs, api.FormatMessagesOptions{Kind: api.ErrorMessage})
+	return &BuildError{
+		EntryPoints: opts.EntryPoints,
+		Stderr:      strings.Join(formatted, ""),
+		Err:         errors.New(msgs[0].Text),
+	}
+}
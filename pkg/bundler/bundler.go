@@ -0,0 +1,211 @@
+// Package bundler bundles the JavaScript and TypeScript of Nexo applications.
+//
+// By convention an app's scripts start at assets/js/entry.ts and are bundled
+// into static/js:
+//
+//	b := bundler.New(bundler.Options{Dev: true})
+//	manifest, err := b.Build(ctx)
+//
+// Development builds keep stable file names and emit source maps; production
+// builds are minified and fingerprinted (entry-5FQXK2VN.js). Every build
+// writes a Manifest mapping the stable names to the fingerprinted URLs, which
+// templates resolve with nexo.Asset:
+//
+//	<script type="module" src={ nexo.Asset(ctx, "js/entry.js") }></script>
+//
+// Bundling is done by an Engine. The default engine uses esbuild's Go API,
+// so apps build without installing esbuild; ESBuild runs an esbuild binary
+// instead, and other engines can be plugged in with Options.Engine.
+package bundler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultEntry is the conventional entry point of an app's scripts.
+	DefaultEntry = "assets/js/entry.ts"
+
+	// DefaultOutDir is where bundles are written.
+	DefaultOutDir = "static/js"
+
+	// DefaultManifest is where the asset manifest is written.
+	DefaultManifest = "static/manifest.json"
+)
+
+// Options configures a Bundler. Zero fields take the defaults.
+type Options struct {
+	// EntryPoints are the files to bundle (default: DefaultEntry).
+	EntryPoints []string
+
+	// OutDir is where bundles are written (default: DefaultOutDir).
+	OutDir string
+
+	// StaticDir and StaticURL locate OutDir on the web: a bundle written to
+	// static/js/entry.js is served at /static/js/entry.js (default: "static"
+	// and "/static").
+	StaticDir string
+	StaticURL string
+
+	// Manifest is where the asset manifest is written (default:
+	// DefaultManifest).
+	Manifest string
+
+	// Dev emits source maps and keeps file names stable. Otherwise bundles
+	// are minified and fingerprinted.
+	Dev bool
+
+	// Target is the JavaScript language target, e.g. "es2020".
+	Target string
+
+	// External lists imports left out of the bundle.
+	External []string
+
+	// Define replaces global identifiers, e.g. {"DEBUG": "false"}.
+	Define map[string]string
+
+	// Engine bundles the entry points (default: ESBuildAPI).
+	Engine Engine
+}
+
+// Output is a file written by an Engine.
+type Output struct {
+	// Path is the file's path, relative to the working directory.
+	Path string
+
+	// EntryPoint is the entry point the file was bundled from (empty for
+	// source maps and chunks).
+	EntryPoint string
+}
+
+// Engine bundles entry points. Build writes the bundles for opts and
+// returns the files it wrote.
+type Engine interface {
+	Build(ctx context.Context, opts Options) ([]Output, error)
+}
+
+// Watcher is an Engine that can rebuild on changes. Watch starts watching
+// and returns immediately; it stops when ctx is done.
+type Watcher interface {
+	Watch(ctx context.Context, opts Options) error
+}
+
+// BuildError is returned when bundling fails. Stderr holds the engine's
+// diagnostics.
+type BuildError struct {
+	EntryPoints []string
+	Stderr      string
+	Err         error
+}
+
+func (e *BuildError) Error() string {
+	msg := fmt.Sprintf("bundle %s failed: %v", strings.Join(e.EntryPoints, ", "), e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += "\n" + stderr
+	}
+	return msg
+}
+
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// Bundler builds an app's scripts and their asset manifest.
+type Bundler struct {
+	opts Options
+}
+
+// New creates a Bundler, filling in the defaults of opts.
+func New(opts Options) *Bundler {
+	if len(opts.EntryPoints) == 0 {
+		opts.EntryPoints = []string{DefaultEntry}
+	}
+	if opts.OutDir == "" {
+		opts.OutDir = DefaultOutDir
+	}
+	if opts.StaticDir == "" {
+		opts.StaticDir = "static"
+	}
+	if opts.StaticURL == "" {
+		opts.StaticURL = "/static"
+	}
+	if opts.Manifest == "" {
+		opts.Manifest = DefaultManifest
+	}
+	if opts.Engine == nil {
+		opts.Engine = &ESBuildAPI{}
+	}
+	return &Bundler{opts: opts}
+}
+
+// Options returns the Bundler's options, with defaults filled in.
+func (b *Bundler) Options() Options {
+	return b.opts
+}
+
+// HasEntryPoints reports whether any entry point exists.
+func (b *Bundler) HasEntryPoints() bool {
+	for _, entry := range b.opts.EntryPoints {
+		if _, err := os.Stat(entry); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Build bundles the entry points and writes the asset manifest.
+func (b *Bundler) Build(ctx context.Context) (*Manifest, error) {
+	if err := os.MkdirAll(b.opts.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputs, err := b.opts.Engine.Build(ctx, b.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m := b.manifest(outputs)
+	if err := m.WriteFile(b.opts.Manifest); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Watch builds once, then rebuilds on changes until ctx is done. Dev builds
+// keep stable names, so the manifest written by the first build stays valid.
+func (b *Bundler) Watch(ctx context.Context) error {
+	w, ok := b.opts.Engine.(Watcher)
+	if !ok {
+		return fmt.Errorf("bundler: engine %T cannot watch", b.opts.Engine)
+	}
+	if _, err := b.Build(ctx); err != nil {
+		return err
+	}
+	return w.Watch(ctx, b.opts)
+}
+
+// manifest maps the entry point outputs to their URLs. The name of an
+// output is its path below the static directory without the fingerprint:
+// static/js/entry-5FQXK2VN.js is js/entry.js.
+func (b *Bundler) manifest(outputs []Output) *Manifest {
+	m := NewManifest(b.opts.StaticURL)
+	for _, out := range outputs {
+		if out.EntryPoint == "" {
+			continue
+		}
+		rel, err := filepath.Rel(b.opts.StaticDir, out.Path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		ext := path.Ext(rel)
+		stem := strings.TrimSuffix(path.Base(out.EntryPoint), path.Ext(out.EntryPoint))
+		name := path.Join(path.Dir(rel), stem+ext)
+		m.Assets[name] = m.Base + "/" + rel
+	}
+	return m
+}
@@ -0,0 +1,290 @@
+package bundler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// fakeEngine reports outputs without running anything.
+type fakeEngine struct {
+	outputs []Output
+	err     error
+	opts    Options
+}
+
+func (e *fakeEngine) Build(ctx context.Context, opts Options) ([]Output, error) {
+	e.opts = opts
+	return e.outputs, e.err
+}
+
+func TestBundler_Build(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	engine := &fakeEngine{outputs: []Output{
+		{Path: "static/js/entry-5FQXK2VN.js", EntryPoint: "assets/js/entry.ts"},
+		{Path: "static/js/entry-5FQXK2VN.js.map"},
+		{Path: "static/js/entry-LJBA2QEL.css", EntryPoint: "assets/js/entry.ts"},
+		{Path: "static/js/admin/main-AAAA.js", EntryPoint: "assets/js/admin/main.tsx"},
+		{Path: "elsewhere/out.js", EntryPoint: "assets/js/other.ts"},
+	}}
+	b := New(Options{Engine: engine})
+
+	m, err := b.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"js/entry.js":      "/static/js/entry-5FQXK2VN.js",
+		"js/entry.css":     "/static/js/entry-LJBA2QEL.css",
+		"js/admin/main.js": "/static/js/admin/main-AAAA.js",
+	}
+	if !reflect.DeepEqual(m.Assets, want) {
+		t.Errorf("Assets = %v, want %v", m.Assets, want)
+	}
+	if got := engine.opts.EntryPoints; !reflect.DeepEqual(got, []string{DefaultEntry}) {
+		t.Errorf("EntryPoints = %v, want the default entry", got)
+	}
+	if _, err := os.Stat(DefaultOutDir); err != nil {
+		t.Errorf("out dir not created: %v", err)
+	}
+
+	loaded, err := LoadManifest(os.DirFS("."), DefaultManifest, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("written manifest = %+v, want %+v", loaded, m)
+	}
+}
+
+func TestBundler_Build_Error(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	engine := &fakeEngine{err: &BuildError{EntryPoints: []string{"a.ts"}, Stderr: "✘ [ERROR] Could not resolve \"x\"", Err: errors.New("exit status 1")}}
+	_, err := New(Options{Engine: engine}).Build(context.Background())
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Build() = %v, want *BuildError", err)
+	}
+	if !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("Error() = %q, want the diagnostics", err.Error())
+	}
+	if _, statErr := os.Stat(DefaultManifest); !os.IsNotExist(statErr) {
+		t.Error("a failed build should not write the manifest")
+	}
+}
+
+func TestBundler_Watch_Unsupported(t *testing.T) {
+	err := New(Options{Engine: &fakeEngine{}}).Watch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "cannot watch") {
+		t.Errorf("Watch() = %v, want a cannot watch error", err)
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ok.json":     {Data: []byte(`{"version":1,"base":"/assets","assets":{"js/entry.js":"/assets/js/entry-A.js"}}`)},
+		"bad.json":    {Data: []byte(`{`)},
+		"future.json": {Data: []byte(`{"version":99}`)},
+	}
+
+	m, err := LoadManifest(fsys, "ok.json", "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Path("js/entry.js"); got != "/assets/js/entry-A.js" {
+		t.Errorf("Path(js/entry.js) = %q", got)
+	}
+	if got := m.Path("/img/logo.svg"); got != "/assets/img/logo.svg" {
+		t.Errorf("Path(/img/logo.svg) = %q, want the manifest base", got)
+	}
+
+	m, err = LoadManifest(fsys, "missing.json", "/static/")
+	if err != nil {
+		t.Fatalf("missing manifest: %v", err)
+	}
+	if got := m.Path("js/entry.js"); got != "/static/js/entry.js" {
+		t.Errorf("Path(js/entry.js) = %q without a manifest", got)
+	}
+
+	for _, name := range []string{"bad.json", "future.json"} {
+		if _, err := LoadManifest(fsys, name, "/static"); err == nil {
+			t.Errorf("LoadManifest(%s) succeeded", name)
+		}
+	}
+
+	var nilManifest *Manifest
+	if got := nilManifest.Path("js/entry.js"); got != "/static/js/entry.js" {
+		t.Errorf("nil Path() = %q", got)
+	}
+}
+
+func TestESBuildArgs(t *testing.T) {
+	opts := Options{
+		EntryPoints: []string{"assets/js/entry.ts"},
+		OutDir:      "static/js",
+		Target:      "es2020",
+		External:    []string{"htmx.org"},
+		Define:      map[string]string{"b": "2", "a": "1"},
+	}
+
+	got := strings.Join(esbuildArgs(opts, "--metafile=m.json"), " ")
+	want := "assets/js/entry.ts --bundle --format=esm --outdir=static/js --log-level=warning " +
+		"--minify --entry-names=[dir]/[name]-[hash] --target=es2020 --external:htmx.org " +
+		"--define:a=1 --define:b=2 --metafile=m.json"
+	if got != want {
+		t.Errorf("production args =\n%s\nwant\n%s", got, want)
+	}
+
+	opts = Options{EntryPoints: []string{"a.ts"}, OutDir: "out", Dev: true}
+	got = strings.Join(esbuildArgs(opts), " ")
+	want = "a.ts --bundle --format=esm --outdir=out --log-level=warning --sourcemap --entry-names=[dir]/[name]"
+	if got != want {
+		t.Errorf("dev args =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseMetafile(t *testing.T) {
+	data := []byte(`{"outputs":{
+		"static/js/entry-A.css":{},
+		"static/js/entry-A.js":{"entryPoint":"assets/js/entry.ts","cssBundle":"static/js/entry-A.css"},
+		"static/js/entry-A.js.map":{},
+		"static/js/chunk-B.js":{}
+	}}`)
+
+	outputs, err := parseMetafile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Output{
+		{Path: filepath.FromSlash("static/js/chunk-B.js")},
+		{Path: filepath.FromSlash("static/js/entry-A.css"), EntryPoint: "assets/js/entry.ts"},
+		{Path: filepath.FromSlash("static/js/entry-A.js"), EntryPoint: "assets/js/entry.ts"},
+		{Path: filepath.FromSlash("static/js/entry-A.js.map")},
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("parseMetafile() = %v, want %v", outputs, want)
+	}
+
+	if _, err := parseMetafile([]byte("nope")); err == nil {
+		t.Error("parseMetafile accepted invalid JSON")
+	}
+}
+
+func TestESBuild_Build(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script binary")
+	}
+	t.Chdir(t.TempDir())
+
+	// A stand-in esbuild that writes one bundle and its metafile
+	script := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --metafile=*) meta="${arg#--metafile=}" ;;
+    bad.ts) echo '✘ [ERROR] Expected ";" but found "}"' >&2; exit 1 ;;
+  esac
+done
+mkdir -p static/js
+echo 'console.log(1)' > static/js/entry-XYZ.js
+echo '{"outputs":{"static/js/entry-XYZ.js":{"entryPoint":"assets/js/entry.ts"}}}' > "$meta"
+`
+	binary := filepath.Join(t.TempDir(), "esbuild")
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(Options{Engine: &ESBuild{Binary: binary}}).Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Path("js/entry.js"); got != "/static/js/entry-XYZ.js" {
+		t.Errorf("Path(js/entry.js) = %q", got)
+	}
+
+	_, err = New(Options{EntryPoints: []string{"bad.ts"}, Engine: &ESBuild{Binary: binary}}).Build(context.Background())
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || !strings.Contains(buildErr.Stderr, `Expected ";"`) {
+		t.Errorf("Build() = %v, want a *BuildError with diagnostics", err)
+	}
+}
+
+func TestESBuildAPI_Build(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"assets/js/entry.ts":  "import { greet } from \"./greet\"\nconsole.log(greet(\"nexo\"))\n",
+		"assets/js/greet.ts":  "export function greet(name: string): string { return `hello ${name}` }\n",
+		"assets/js/broken.ts": "const x = {\n",
+	}
+	for name, data := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	m, err := New(Options{}).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := m.Path("js/entry.js")
+	if !strings.HasPrefix(url, "/static/js/entry-") {
+		t.Fatalf("Path(js/entry.js) = %q, want a fingerprinted bundle", url)
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(url, "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "hello ") || strings.Contains(string(data), "string") {
+		t.Errorf("bundle is not the compiled entry:\n%s", data)
+	}
+
+	m, err = New(Options{Dev: true}).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Path("js/entry.js"); got != "/static/js/entry.js" {
+		t.Errorf("dev Path(js/entry.js) = %q", got)
+	}
+	if _, err := os.Stat("static/js/entry.js.map"); err != nil {
+		t.Errorf("dev build has no source map: %v", err)
+	}
+
+	_, err = New(Options{EntryPoints: []string{"assets/js/broken.ts"}}).Build(ctx)
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || !strings.Contains(buildErr.Stderr, "broken.ts") {
+		t.Errorf("Build() = %v, want a *BuildError with diagnostics", err)
+	}
+
+	if _, err := New(Options{Target: "chrome90"}).Build(ctx); err == nil {
+		t.Error("Build() accepted a browser target")
+	}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	if err := New(Options{Dev: true}).Watch(watchCtx); err != nil {
+		t.Errorf("Watch() = %v", err)
+	}
+}
+
+func TestESBuild_NotFound(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := (&ESBuild{}).Build(context.Background(), New(Options{}).Options())
+	if !errors.Is(err, ErrESBuildNotFound) {
+		t.Errorf("Build() = %v, want ErrESBuildNotFound", err)
+	}
+}
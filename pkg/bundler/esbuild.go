@@ -0,0 +1,152 @@
+package bundler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// ErrESBuildNotFound is returned when no esbuild binary can be found.
+var ErrESBuildNotFound = errors.New("esbuild not found: install it with `npm install --save-dev esbuild`, or unset js.esbuild in nexo.yaml to use the built-in esbuild")
+
+// ESBuild is the Engine that runs the esbuild binary, for a pinned esbuild
+// version or targets the Go API doesn't take. ESBuildAPI is the default.
+type ESBuild struct {
+	// Binary is the esbuild executable. Without it node_modules/.bin/esbuild
+	// is used, then esbuild on PATH.
+	Binary string
+}
+
+// Build runs esbuild once and returns the files listed in its metafile.
+func (e *ESBuild) Build(ctx context.Context, opts Options) ([]Output, error) {
+	binary, err := e.binary()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := os.CreateTemp("", "nexo-esbuild-*.json")
+	if err != nil {
+		return nil, err
+	}
+	metafile := meta.Name()
+	_ = meta.Close()
+	defer func() { _ = os.Remove(metafile) }()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, esbuildArgs(opts, "--metafile="+metafile)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	if err := cmd.Run(); err != nil {
+		return nil, &BuildError{EntryPoints: opts.EntryPoints, Stderr: stderr.String(), Err: err}
+	}
+
+	data, err := os.ReadFile(metafile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read esbuild metafile: %w", err)
+	}
+	return parseMetafile(data)
+}
+
+// Watch starts esbuild in watch mode. The process is killed when ctx is
+// done.
+func (e *ESBuild) Watch(ctx context.Context, opts Options) error {
+	binary, err := e.binary()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, binary, esbuildArgs(opts, "--watch=forever")...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// binary locates the esbuild executable.
+func (e *ESBuild) binary() (string, error) {
+	if e.Binary != "" {
+		return e.Binary, nil
+	}
+	local := filepath.Join("node_modules", ".bin", "esbuild")
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+	if path, err := exec.LookPath("esbuild"); err == nil {
+		return path, nil
+	}
+	return "", ErrESBuildNotFound
+}
+
+// esbuildArgs returns the esbuild command line for opts.
+func esbuildArgs(opts Options, extra ...string) []string {
+	args := append([]string{}, opts.EntryPoints...)
+	args = append(args,
+		"--bundle",
+		"--format=esm",
+		"--outdir="+opts.OutDir,
+		"--log-level=warning",
+	)
+	if opts.Dev {
+		args = append(args, "--sourcemap", "--entry-names=[dir]/[name]")
+	} else {
+		args = append(args, "--minify", "--entry-names=[dir]/[name]-[hash]")
+	}
+	if opts.Target != "" {
+		args = append(args, "--target="+opts.Target)
+	}
+	for _, ext := range opts.External {
+		args = append(args, "--external:"+ext)
+	}
+
+	keys := make([]string, 0, len(opts.Define))
+	for k := range opts.Define {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--define:"+k+"="+opts.Define[k])
+	}
+
+	return append(args, extra...)
+}
+
+// parseMetafile lists the outputs of an esbuild metafile. A CSS bundle
+// takes the entry point of the script that imported it.
+func parseMetafile(data []byte) ([]Output, error) {
+	var meta struct {
+		Outputs map[string]struct {
+			EntryPoint string `json:"entryPoint"`
+			CSSBundle  string `json:"cssBundle"`
+		} `json:"outputs"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid esbuild metafile: %w", err)
+	}
+
+	entries := make(map[string]string)
+	for path, out := range meta.Outputs {
+		if _, ok := entries[path]; !ok || out.EntryPoint != "" {
+			entries[path] = out.EntryPoint
+		}
+		if out.CSSBundle != "" && out.EntryPoint != "" {
+			entries[out.CSSBundle] = out.EntryPoint
+		}
+	}
+
+	outputs := make([]Output, 0, len(entries))
+	for path, entry := range entries {
+		outputs = append(outputs, Output{Path: filepath.FromSlash(path), EntryPoint: entry})
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Path < outputs[j].Path })
+	return outputs, nil
+}
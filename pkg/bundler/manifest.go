@@ -0,0 +1,89 @@
+package bundler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestVersion is the version of the manifest file format.
+const ManifestVersion = 1
+
+// Manifest maps the stable names of bundled assets to their URLs:
+//
+//	{
+//	  "version": 1,
+//	  "base": "/static",
+//	  "assets": {
+//	    "js/entry.js": "/static/js/entry-5FQXK2VN.js",
+//	    "js/entry.css": "/static/js/entry-LJBA2QEL.css"
+//	  }
+//	}
+type Manifest struct {
+	Version int               `json:"version"`
+	Base    string            `json:"base"`
+	Assets  map[string]string `json:"assets"`
+}
+
+// NewManifest creates an empty manifest for assets served below base.
+func NewManifest(base string) *Manifest {
+	return &Manifest{
+		Version: ManifestVersion,
+		Base:    strings.TrimSuffix(base, "/"),
+		Assets:  make(map[string]string),
+	}
+}
+
+// LoadManifest reads the manifest at name in fsys. A missing manifest is
+// not an error: it yields an empty manifest for base.
+func LoadManifest(fsys fs.FS, name, base string) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewManifest(base), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewManifest(base)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid asset manifest %s: %w", name, err)
+	}
+	if m.Version > ManifestVersion {
+		return nil, fmt.Errorf("asset manifest %s has version %d, newer than %d", name, m.Version, ManifestVersion)
+	}
+	if m.Assets == nil {
+		m.Assets = make(map[string]string)
+	}
+	return m, nil
+}
+
+// Path returns the URL of the asset name, e.g. "js/entry.js". Names missing
+// from the manifest resolve below its base, so unbundled files in the
+// static directory work too. A nil Manifest resolves below /static.
+func (m *Manifest) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if m == nil {
+		return "/static/" + name
+	}
+	if p, ok := m.Assets[name]; ok {
+		return p
+	}
+	return m.Base + "/" + name
+}
+
+// WriteFile writes the manifest to name as indented JSON.
+func (m *Manifest) WriteFile(name string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,140 @@
+// Package deploy defines the hosting providers that nexo deploy ships apps
+// to.
+//
+// A Provider creates apps, rolls out container images and manages their
+// environment, logs and domains. Two providers are built in: NexoCloud, the
+// default, and Fly for Fly.io Machines. Both take images pushed to a
+// registry the provider names with ImageRef:
+//
+//	p := deploy.NewFly(deploy.FlyOptions{Token: os.Getenv("FLY_API_TOKEN")})
+//	image, _ := p.ImageRef(ctx, "shop", "20260115120000")
+//	// docker build -t image . && docker push image
+//	d, err := p.Deploy(ctx, "shop", image)
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+)
+
+// ErrAppNotFound is returned by Provider.GetApp for apps that do not exist.
+var ErrAppNotFound = errors.New("app not found")
+
+// The provider types are shared with the Nexo Cloud API.
+type (
+	App        = cloud.App
+	Deployment = cloud.Deployment
+	LogLine    = cloud.LogLine
+	LogOptions = cloud.LogOptions
+	Domain     = cloud.Domain
+)
+
+// Deployment statuses reported by providers.
+const (
+	StatusPending   = "pending"
+	StatusDeploying = "deploying"
+	StatusActive    = "active"
+	StatusFailed    = "failed"
+)
+
+// AppSpec describes an app to create.
+type AppSpec struct {
+	Name   string
+	Region string
+	Size   string
+}
+
+// Provider hosts apps.
+type Provider interface {
+	// Name is the provider's display name, e.g. "Fly.io".
+	Name() string
+
+	// ImageRef returns the registry reference to push app's image to.
+	ImageRef(ctx context.Context, app, tag string) (string, error)
+
+	// GetApp returns the app, or an error wrapping ErrAppNotFound.
+	GetApp(ctx context.Context, app string) (*App, error)
+
+	// CreateApp creates an app.
+	CreateApp(ctx context.Context, spec AppSpec) (*App, error)
+
+	// Deploy rolls out image. The deployment may still be in progress
+	// when Deploy returns; poll it with Deployment.
+	Deploy(ctx context.Context, app, image string) (*Deployment, error)
+
+	// Deployment returns the current state of a deployment.
+	Deployment(ctx context.Context, app, id string) (*Deployment, error)
+
+	// Deployments lists the app's deployments, newest first.
+	Deployments(ctx context.Context, app string) ([]Deployment, error)
+
+	// Logs returns the app's recent log lines.
+	Logs(ctx context.Context, app string, opts LogOptions) ([]LogLine, error)
+
+	// SetEnv sets environment variables. They apply from the next
+	// deployment.
+	SetEnv(ctx context.Context, app string, vars map[string]string) error
+
+	// Domains lists the app's custom domains.
+	Domains(ctx context.Context, app string) ([]Domain, error)
+}
+
+// LogStreamer is a Provider that can stream logs as they are written. The
+// channels close when ctx is done or the stream ends; an error that ends
+// the stream is sent before the line channel closes.
+type LogStreamer interface {
+	StreamLogs(ctx context.Context, app string, opts LogOptions) (<-chan LogLine, <-chan error, error)
+}
+
+// EnvReader is a Provider that can read back the app's environment
+// variables. Providers that store them as write-only secrets, like Fly.io,
+// do not implement it.
+type EnvReader interface {
+	GetEnv(ctx context.Context, app string) (map[string]string, error)
+}
+
+// RegistryAuth holds the credentials for pushing to a provider's registry.
+type RegistryAuth struct {
+	Server   string
+	Username string
+	Password string
+}
+
+// RegistryAuthenticator is a Provider whose registry needs a docker login
+// before pushing.
+type RegistryAuthenticator interface {
+	RegistryAuth(ctx context.Context) (*RegistryAuth, error)
+}
+
+// Providers lists the names accepted by New.
+func Providers() []string {
+	return []string{"nexo", "fly"}
+}
+
+// Options configures the provider created by New.
+type Options struct {
+	// Fly configures the "fly" provider.
+	Fly FlyOptions
+}
+
+// New creates the provider called name (default: "nexo"). The Nexo Cloud
+// provider uses the credentials saved by nexo login.
+func New(name string, opts Options) (Provider, error) {
+	switch name {
+	case "", "nexo":
+		client, err := cloud.NewClientFromCredentials()
+		if err != nil {
+			return nil, err
+		}
+		return NewNexoCloud(client), nil
+	case "fly":
+		if opts.Fly.Token == "" {
+			return nil, errors.New("fly: no API token, set FLY_API_TOKEN (create one with `fly tokens create deploy`)")
+		}
+		return NewFly(opts.Fly), nil
+	}
+	return nil, fmt.Errorf("unknown deploy provider %q (available: nexo, fly)", name)
+}
@@ -0,0 +1,459 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/internal/version"
+	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+)
+
+const (
+	// FlyMachinesURL is the Fly.io Machines API.
+	FlyMachinesURL = "https://api.machines.dev"
+
+	// FlyAPIURL serves the Fly.io GraphQL and logs APIs.
+	FlyAPIURL = "https://api.fly.io"
+
+	// FlyRegistry is the registry Fly.io machines pull images from.
+	FlyRegistry = "registry.fly.io"
+)
+
+// FlyOptions configures the Fly.io provider.
+type FlyOptions struct {
+	// Token is a Fly.io API or deploy token (FLY_API_TOKEN).
+	Token string
+
+	// Org is the organization new apps are created in (default:
+	// "personal").
+	Org string
+
+	// Region is where the first machine is created (default: "iad").
+	Region string
+
+	// Size is the machine size: starter, pro or enterprise (default:
+	// starter).
+	Size string
+
+	// Port is the port the app listens on (default: 3000).
+	Port int
+
+	// MachinesURL and APIURL override the API endpoints, for tests.
+	MachinesURL string
+	APIURL      string
+
+	HTTPClient *http.Client
+}
+
+// Fly is the Provider for Fly.io. Each deploy updates the app's machines to
+// the new image, creating one machine on the first deploy. Environment
+// variables are stored as Fly secrets and custom domains are Fly
+// certificates.
+type Fly struct {
+	opts FlyOptions
+}
+
+// NewFly creates the Fly.io provider, filling in the defaults of opts.
+func NewFly(opts FlyOptions) *Fly {
+	if opts.Org == "" {
+		opts.Org = "personal"
+	}
+	if opts.Region == "" {
+		opts.Region = "iad"
+	}
+	if opts.Port == 0 {
+		opts.Port = 3000
+	}
+	if opts.MachinesURL == "" {
+		opts.MachinesURL = FlyMachinesURL
+	}
+	if opts.APIURL == "" {
+		opts.APIURL = FlyAPIURL
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	return &Fly{opts: opts}
+}
+
+// Name returns "Fly.io".
+func (f *Fly) Name() string { return "Fly.io" }
+
+// ImageRef returns registry.fly.io/<app>:<tag>.
+func (f *Fly) ImageRef(ctx context.Context, app, tag string) (string, error) {
+	return FlyRegistry + "/" + app + ":" + tag, nil
+}
+
+// RegistryAuth returns the credentials for registry.fly.io.
+func (f *Fly) RegistryAuth(ctx context.Context) (*RegistryAuth, error) {
+	return &RegistryAuth{Server: FlyRegistry, Username: "x", Password: f.opts.Token}, nil
+}
+
+// flyApp is an app in the Machines API.
+type flyApp struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// flyMachine is a machine in the Machines API. Config is kept as raw JSON
+// so updates preserve the fields nexo does not manage.
+type flyMachine struct {
+	ID         string         `json:"id"`
+	State      string         `json:"state"`
+	Region     string         `json:"region"`
+	InstanceID string         `json:"instance_id"`
+	Config     map[string]any `json:"config"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+func (m flyMachine) image() string {
+	image, _ := m.Config["image"].(string)
+	return image
+}
+
+// GetApp returns the app.
+func (f *Fly) GetApp(ctx context.Context, app string) (*App, error) {
+	var a flyApp
+	err := f.request(ctx, http.MethodGet, f.opts.MachinesURL+"/v1/apps/"+url.PathEscape(app), nil, &a)
+	var apiErr *cloud.APIError
+	if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+		return nil, fmt.Errorf("%w: %s", ErrAppNotFound, app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.app(a.Name, a.ID, a.Status), nil
+}
+
+// CreateApp creates the app and allocates its public IP addresses.
+func (f *Fly) CreateApp(ctx context.Context, spec AppSpec) (*App, error) {
+	body := map[string]string{"app_name": spec.Name, "org_slug": f.opts.Org}
+	if err := f.request(ctx, http.MethodPost, f.opts.MachinesURL+"/v1/apps", body, nil); err != nil {
+		return nil, err
+	}
+
+	const allocate = `mutation($input: AllocateIPAddressInput!) { allocateIpAddress(input: $input) { ipAddress { address } } }`
+	for _, typ := range []string{"shared_v4", "v6"} {
+		input := map[string]any{"appId": spec.Name, "type": typ}
+		if err := f.graphql(ctx, allocate, map[string]any{"input": input}, nil); err != nil {
+			return nil, fmt.Errorf("failed to allocate %s address: %w", typ, err)
+		}
+	}
+	return f.app(spec.Name, "", "pending"), nil
+}
+
+// app describes a Fly app.
+func (f *Fly) app(name, id, status string) *App {
+	return &App{
+		ID:     id,
+		Name:   name,
+		Status: status,
+		Region: f.opts.Region,
+		Size:   f.opts.Size,
+		URL:    "https://" + name + ".fly.dev",
+	}
+}
+
+// Deploy updates every machine of the app to image, or creates the first
+// machine. The returned deployment's ID is the image.
+func (f *Fly) Deploy(ctx context.Context, app, image string) (*Deployment, error) {
+	machines, err := f.machines(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	base := f.opts.MachinesURL + "/v1/apps/" + url.PathEscape(app) + "/machines"
+	if len(machines) == 0 {
+		body := map[string]any{"region": f.opts.Region, "config": f.machineConfig(image)}
+		if err := f.request(ctx, http.MethodPost, base, body, nil); err != nil {
+			return nil, fmt.Errorf("failed to create machine: %w", err)
+		}
+	}
+	for _, m := range machines {
+		config := m.Config
+		if config == nil {
+			config = f.machineConfig(image)
+		}
+		config["image"] = image
+		body := map[string]any{"config": config}
+		if err := f.request(ctx, http.MethodPost, base+"/"+url.PathEscape(m.ID), body, nil); err != nil {
+			return nil, fmt.Errorf("failed to update machine %s: %w", m.ID, err)
+		}
+	}
+
+	return &Deployment{
+		ID:        image,
+		AppName:   app,
+		Version:   imageTag(image),
+		Status:    StatusDeploying,
+		Image:     image,
+		CreatedAt: time.Now(),
+		StartedAt: time.Now(),
+	}, nil
+}
+
+// machineConfig is the config of a new machine: the app behind Fly's HTTP
+// proxy on ports 80 and 443.
+func (f *Fly) machineConfig(image string) map[string]any {
+	guest := map[string]any{"cpu_kind": "shared", "cpus": 1, "memory_mb": 256}
+	switch f.opts.Size {
+	case "pro":
+		guest = map[string]any{"cpu_kind": "shared", "cpus": 2, "memory_mb": 1024}
+	case "enterprise":
+		guest = map[string]any{"cpu_kind": "performance", "cpus": 2, "memory_mb": 4096}
+	}
+
+	return map[string]any{
+		"image": image,
+		"guest": guest,
+		"services": []any{map[string]any{
+			"protocol":      "tcp",
+			"internal_port": f.opts.Port,
+			"ports": []any{
+				map[string]any{"port": 80, "handlers": []string{"http"}, "force_https": true},
+				map[string]any{"port": 443, "handlers": []string{"tls", "http"}},
+			},
+		}},
+		"restart": map[string]any{"policy": "always"},
+	}
+}
+
+// Deployment reports the rollout of the image id: active once every
+// machine runs it, failed if a machine failed.
+func (f *Fly) Deployment(ctx context.Context, app, id string) (*Deployment, error) {
+	machines, err := f.machines(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Deployment{ID: id, AppName: app, Version: imageTag(id), Image: id, Status: StatusActive}
+	if len(machines) == 0 {
+		d.Status = StatusPending
+	}
+	for _, m := range machines {
+		if m.UpdatedAt.After(d.EndedAt) {
+			d.EndedAt = m.UpdatedAt
+		}
+		switch {
+		case m.State == "failed":
+			d.Status = StatusFailed
+		case d.Status != StatusFailed && (m.image() != id || m.State != "started"):
+			d.Status = StatusDeploying
+		}
+	}
+	if d.Status != StatusActive {
+		d.EndedAt = time.Time{}
+	}
+	return d, nil
+}
+
+// Deployments lists the images the app's machines run, newest first.
+func (f *Fly) Deployments(ctx context.Context, app string) ([]Deployment, error) {
+	machines, err := f.machines(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	byImage := make(map[string]*Deployment)
+	for _, m := range machines {
+		image := m.image()
+		d, ok := byImage[image]
+		if !ok {
+			d = &Deployment{ID: image, AppName: app, Version: imageTag(image), Image: image, Status: StatusActive}
+			byImage[image] = d
+		}
+		if m.UpdatedAt.After(d.CreatedAt) {
+			d.CreatedAt = m.UpdatedAt
+		}
+		if m.State != "started" {
+			d.Status = StatusDeploying
+		}
+	}
+
+	deployments := make([]Deployment, 0, len(byImage))
+	for _, d := range byImage {
+		deployments = append(deployments, *d)
+	}
+	sort.Slice(deployments, func(i, j int) bool { return deployments[i].CreatedAt.After(deployments[j].CreatedAt) })
+	return deployments, nil
+}
+
+// machines lists the app's machines.
+func (f *Fly) machines(ctx context.Context, app string) ([]flyMachine, error) {
+	var machines []flyMachine
+	err := f.request(ctx, http.MethodGet, f.opts.MachinesURL+"/v1/apps/"+url.PathEscape(app)+"/machines", nil, &machines)
+	return machines, err
+}
+
+// Logs returns the app's recent log lines.
+func (f *Fly) Logs(ctx context.Context, app string, opts LogOptions) ([]LogLine, error) {
+	var resp struct {
+		Data []struct {
+			Attributes struct {
+				Timestamp time.Time `json:"timestamp"`
+				Message   string    `json:"message"`
+				Level     string    `json:"level"`
+				Instance  string    `json:"instance"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	u := f.opts.APIURL + "/api/v1/apps/" + url.PathEscape(app) + "/logs"
+	if err := f.request(ctx, http.MethodGet, u, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if opts.Since > 0 {
+		since = time.Now().Add(-opts.Since)
+	}
+	var lines []LogLine
+	for _, d := range resp.Data {
+		a := d.Attributes
+		if a.Timestamp.Before(since) || (opts.Level != "" && a.Level != opts.Level) {
+			continue
+		}
+		lines = append(lines, LogLine{Timestamp: a.Timestamp, Level: a.Level, Message: a.Message, Source: a.Instance})
+	}
+	if opts.Tail > 0 && len(lines) > opts.Tail {
+		lines = lines[len(lines)-opts.Tail:]
+	}
+	return lines, nil
+}
+
+// SetEnv stores vars as Fly secrets.
+func (f *Fly) SetEnv(ctx context.Context, app string, vars map[string]string) error {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	secrets := make([]map[string]string, 0, len(keys))
+	for _, k := range keys {
+		secrets = append(secrets, map[string]string{"key": k, "value": vars[k]})
+	}
+
+	const setSecrets = `mutation($input: SetSecretsInput!) { setSecrets(input: $input) { app { name } } }`
+	input := map[string]any{"appId": app, "secrets": secrets}
+	return f.graphql(ctx, setSecrets, map[string]any{"input": input}, nil)
+}
+
+// Domains lists the app's certificates.
+func (f *Fly) Domains(ctx context.Context, app string) ([]Domain, error) {
+	const certificates = `query($appName: String!) { app(name: $appName) { certificates { nodes { hostname clientStatus configured createdAt dnsValidationTarget } } } }`
+	var data struct {
+		App struct {
+			Certificates struct {
+				Nodes []struct {
+					Hostname            string    `json:"hostname"`
+					ClientStatus        string    `json:"clientStatus"`
+					Configured          bool      `json:"configured"`
+					CreatedAt           time.Time `json:"createdAt"`
+					DNSValidationTarget string    `json:"dnsValidationTarget"`
+				} `json:"nodes"`
+			} `json:"certificates"`
+		} `json:"app"`
+	}
+	if err := f.graphql(ctx, certificates, map[string]any{"appName": app}, &data); err != nil {
+		return nil, err
+	}
+
+	var domains []Domain
+	for _, c := range data.App.Certificates.Nodes {
+		domains = append(domains, Domain{
+			Name:      c.Hostname,
+			Status:    strings.ToLower(c.ClientStatus),
+			DNSRecord: c.DNSValidationTarget,
+			Verified:  c.Configured,
+			SSL:       c.ClientStatus == "Ready",
+			CreatedAt: c.CreatedAt,
+		})
+	}
+	return domains, nil
+}
+
+// graphql runs a query against the Fly.io GraphQL API.
+func (f *Fly) graphql(ctx context.Context, query string, vars map[string]any, data any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	body := map[string]any{"query": query, "variables": vars}
+	if err := f.request(ctx, http.MethodPost, f.opts.APIURL+"/graphql", body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return &cloud.APIError{StatusCode: http.StatusOK, Message: resp.Errors[0].Message}
+	}
+	if data != nil {
+		return json.Unmarshal(resp.Data, data)
+	}
+	return nil
+}
+
+// request performs an authenticated request and decodes the JSON response
+// into result. Error responses become *cloud.APIError.
+func (f *Fly) request(ctx context.Context, method, u string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+f.opts.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "nexo-cli/"+version.GetVersion())
+
+	resp, err := f.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return &cloud.APIError{StatusCode: resp.StatusCode, Message: "fly: HTTP " + strconv.Itoa(resp.StatusCode) + ": " + msg}
+	}
+	if result != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// imageTag returns the tag of an image reference.
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
@@ -0,0 +1,268 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFly serves the parts of the Fly.io APIs the provider uses.
+type fakeFly struct {
+	mu       sync.Mutex
+	apps     map[string]bool
+	machines []flyMachine
+	secrets  []map[string]any
+	ips      []string
+	updated  []string
+}
+
+func newFakeFly(t *testing.T) (*fakeFly, *Fly) {
+	f := &fakeFly{apps: map[string]bool{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fly-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/apps":
+			f.apps[body["app_name"].(string)] = true
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/apps/shop":
+			if !f.apps["shop"] {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"app not found"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"app-1","name":"shop","status":"deployed"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/apps/shop/machines":
+			_ = json.NewEncoder(w).Encode(f.machines)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/apps/shop/machines":
+			f.machines = append(f.machines, flyMachine{
+				ID:     "m1",
+				State:  "started",
+				Config: body["config"].(map[string]any),
+			})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/apps/shop/machines/"):
+			f.updated = append(f.updated, strings.TrimPrefix(r.URL.Path, "/v1/apps/shop/machines/"))
+			for i := range f.machines {
+				f.machines[i].Config = body["config"].(map[string]any)
+			}
+		case r.URL.Path == "/graphql":
+			query := body["query"].(string)
+			vars := body["variables"].(map[string]any)
+			switch {
+			case strings.Contains(query, "allocateIpAddress"):
+				f.ips = append(f.ips, vars["input"].(map[string]any)["type"].(string))
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			case strings.Contains(query, "setSecrets"):
+				for _, s := range vars["input"].(map[string]any)["secrets"].([]any) {
+					f.secrets = append(f.secrets, s.(map[string]any))
+				}
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			case strings.Contains(query, "certificates"):
+				_, _ = w.Write([]byte(`{"data":{"app":{"certificates":{"nodes":[
+					{"hostname":"shop.example.com","clientStatus":"Ready","configured":true,"createdAt":"2026-01-15T12:00:00Z","dnsValidationTarget":"shop.fly.dev"}
+				]}}}}`))
+			default:
+				_, _ = w.Write([]byte(`{"errors":[{"message":"unknown query"}]}`))
+			}
+		case r.URL.Path == "/api/v1/apps/shop/logs":
+			_, _ = w.Write([]byte(`{"data":[
+				{"attributes":{"timestamp":"2026-01-15T12:00:00Z","message":"starting","level":"info","instance":"m1"}},
+				{"attributes":{"timestamp":"2026-01-15T12:00:01Z","message":"boom","level":"error","instance":"m1"}},
+				{"attributes":{"timestamp":"2026-01-15T12:00:02Z","message":"listening","level":"info","instance":"m1"}}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p := NewFly(FlyOptions{
+		Token:       "fly-token",
+		Region:      "gdl",
+		Port:        8080,
+		MachinesURL: server.URL,
+		APIURL:      server.URL,
+	})
+	return f, p
+}
+
+func TestFlyAppLifecycle(t *testing.T) {
+	f, p := newFakeFly(t)
+	ctx := context.Background()
+
+	if _, err := p.GetApp(ctx, "shop"); !errors.Is(err, ErrAppNotFound) {
+		t.Fatalf("GetApp() error = %v, want ErrAppNotFound", err)
+	}
+
+	app, err := p.CreateApp(ctx, AppSpec{Name: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.URL != "https://shop.fly.dev" {
+		t.Errorf("URL = %q", app.URL)
+	}
+	if strings.Join(f.ips, ",") != "shared_v4,v6" {
+		t.Errorf("allocated IPs = %v", f.ips)
+	}
+
+	if _, err := p.GetApp(ctx, "shop"); err != nil {
+		t.Errorf("GetApp() after create: %v", err)
+	}
+}
+
+func TestFlyDeploy(t *testing.T) {
+	f, p := newFakeFly(t)
+	ctx := context.Background()
+
+	// The first deploy creates a machine
+	d, err := p.Deploy(ctx, "shop", "registry.fly.io/shop:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != StatusDeploying || d.Version != "v1" {
+		t.Errorf("Deploy() = %+v", d)
+	}
+	if len(f.machines) != 1 {
+		t.Fatalf("machines = %d, want 1", len(f.machines))
+	}
+	services := f.machines[0].Config["services"].([]any)
+	if port := services[0].(map[string]any)["internal_port"]; port != float64(8080) {
+		t.Errorf("internal_port = %v, want 8080", port)
+	}
+
+	d, err = p.Deployment(ctx, "shop", d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != StatusActive {
+		t.Errorf("Deployment() status = %q, want active", d.Status)
+	}
+
+	// Later deploys update the machines
+	f.machines[0].Config["env"] = map[string]any{"KEEP": "1"}
+	if _, err := p.Deploy(ctx, "shop", "registry.fly.io/shop:v2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.updated) != 1 || f.updated[0] != "m1" {
+		t.Errorf("updated machines = %v", f.updated)
+	}
+	if got := f.machines[0].image(); got != "registry.fly.io/shop:v2" {
+		t.Errorf("image = %q", got)
+	}
+	if f.machines[0].Config["env"] == nil {
+		t.Error("update dropped the machine's env")
+	}
+
+	deployments, err := p.Deployments(ctx, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 1 || deployments[0].Image != "registry.fly.io/shop:v2" {
+		t.Errorf("Deployments() = %+v", deployments)
+	}
+
+	f.machines[0].State = "failed"
+	if d, _ := p.Deployment(ctx, "shop", "registry.fly.io/shop:v2"); d.Status != StatusFailed {
+		t.Errorf("Deployment() status = %q, want failed", d.Status)
+	}
+}
+
+func TestFlySetEnvAndDomains(t *testing.T) {
+	f, p := newFakeFly(t)
+	ctx := context.Background()
+
+	if err := p.SetEnv(ctx, "shop", map[string]string{"B": "2", "A": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.secrets) != 2 || f.secrets[0]["key"] != "A" || f.secrets[1]["value"] != "2" {
+		t.Errorf("secrets = %v", f.secrets)
+	}
+
+	domains, err := p.Domains(ctx, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].Name != "shop.example.com" || !domains[0].SSL || !domains[0].Verified {
+		t.Errorf("Domains() = %+v", domains)
+	}
+}
+
+func TestFlyLogs(t *testing.T) {
+	_, p := newFakeFly(t)
+
+	lines, err := p.Logs(context.Background(), "shop", LogOptions{Tail: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Message != "boom" || lines[1].Source != "m1" {
+		t.Errorf("Logs(tail 2) = %+v", lines)
+	}
+	if !lines[0].Timestamp.Equal(time.Date(2026, 1, 15, 12, 0, 1, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v", lines[0].Timestamp)
+	}
+
+	lines, err = p.Logs(context.Background(), "shop", LogOptions{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].Message != "boom" {
+		t.Errorf("Logs(level error) = %+v", lines)
+	}
+}
+
+func TestFlyRegistry(t *testing.T) {
+	p := NewFly(FlyOptions{Token: "fly-token"})
+
+	image, _ := p.ImageRef(context.Background(), "shop", "20260115")
+	if image != "registry.fly.io/shop:20260115" {
+		t.Errorf("ImageRef() = %q", image)
+	}
+	auth, _ := p.RegistryAuth(context.Background())
+	if auth.Server != FlyRegistry || auth.Password != "fly-token" {
+		t.Errorf("RegistryAuth() = %+v", auth)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("heroku", Options{}); err == nil || !strings.Contains(err.Error(), "unknown deploy provider") {
+		t.Errorf("New(heroku) error = %v", err)
+	}
+	if _, err := New("fly", Options{}); err == nil {
+		t.Error("New(fly) without a token succeeded")
+	}
+	p, err := New("fly", Options{Fly: FlyOptions{Token: "t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "Fly.io" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := map[string]string{
+		"registry.fly.io/shop:v1":   "v1",
+		"localhost:5000/shop":       "latest",
+		"ghcr.io/user/app:20260115": "20260115",
+	}
+	for image, want := range tests {
+		if got := imageTag(image); got != want {
+			t.Errorf("imageTag(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
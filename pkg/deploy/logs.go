@@ -0,0 +1,233 @@
+package deploy
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogFilter selects log lines on the client. Empty fields match everything.
+type LogFilter struct {
+	// Levels are the levels to keep, e.g. ["warn", "error"].
+	Levels []string
+
+	// Sources are the sources to keep, e.g. ["app"].
+	Sources []string
+}
+
+// ParseLogFilter builds a filter from comma-separated level and source
+// lists.
+func ParseLogFilter(levels, sources string) LogFilter {
+	return LogFilter{Levels: splitList(levels), Sources: splitList(sources)}
+}
+
+// Match reports whether line passes the filter.
+func (f LogFilter) Match(line LogLine) bool {
+	if len(f.Levels) > 0 && !slices.Contains(f.Levels, strings.ToLower(line.Level)) {
+		return false
+	}
+	if len(f.Sources) > 0 && !slices.Contains(f.Sources, strings.ToLower(line.Source)) {
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma-separated list into lowercase items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FollowOptions configures Follow.
+type FollowOptions struct {
+	// LogOptions selects the initial lines: Tail and Since apply to the
+	// first connection only.
+	LogOptions
+
+	// Filter drops lines on the client.
+	Filter LogFilter
+
+	// MinBackoff and MaxBackoff bound the wait before reconnecting; it
+	// doubles after each failed attempt (default: 1s and 30s).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// PollInterval is how often providers without streaming are polled
+	// (default: 2s).
+	PollInterval time.Duration
+
+	// OnReconnect is called before waiting to reconnect, with the error
+	// that ended the stream (nil when the server closed it).
+	OnReconnect func(err error, wait time.Duration)
+}
+
+// Follow streams the app's logs until ctx is done, reconnecting with
+// backoff when the stream drops. Providers that are not LogStreamers are
+// polled. Lines already delivered are not repeated after a reconnect. The
+// returned channel closes when ctx is done.
+func Follow(ctx context.Context, p Provider, app string, opts FollowOptions) <-chan LogLine {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(30*time.Second, opts.MinBackoff)
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+
+	out := make(chan LogLine, 100)
+	f := &follower{provider: p, app: app, opts: opts, out: out}
+	go func() {
+		defer close(out)
+		f.run(ctx)
+	}()
+	return out
+}
+
+// follower is the state of one Follow call.
+type follower struct {
+	provider Provider
+	app      string
+	opts     FollowOptions
+	out      chan<- LogLine
+
+	// last is the timestamp of the newest line delivered, and seen the
+	// lines delivered at that timestamp.
+	last time.Time
+	seen map[string]bool
+}
+
+func (f *follower) run(ctx context.Context) {
+	streamer, streaming := f.provider.(LogStreamer)
+	backoff := f.opts.MinBackoff
+	first := true
+
+	for ctx.Err() == nil {
+		opts := f.opts.LogOptions
+		opts.Follow = streaming
+		if !first {
+			opts.Tail = 0
+			opts.Since = 0
+			if !f.last.IsZero() {
+				// Ask for a little overlap; emit drops the repeats.
+				opts.Since = time.Since(f.last) + time.Second
+			}
+		}
+
+		var received bool
+		var err error
+		if streaming {
+			received, err = f.stream(ctx, streamer, opts)
+		} else {
+			received, err = f.poll(ctx, opts)
+		}
+		first = false
+		if ctx.Err() != nil {
+			return
+		}
+
+		if received {
+			backoff = f.opts.MinBackoff
+		}
+		wait := backoff
+		if !streaming && err == nil {
+			wait = f.opts.PollInterval
+		} else {
+			backoff = min(backoff*2, f.opts.MaxBackoff)
+			if f.opts.OnReconnect != nil {
+				f.opts.OnReconnect(err, wait)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// stream reads one streaming connection until it ends.
+func (f *follower) stream(ctx context.Context, s LogStreamer, opts LogOptions) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logCh, errCh, err := s.StreamLogs(ctx, f.app, opts)
+	if err != nil {
+		return false, err
+	}
+
+	// Read until the stream closes, then report any error sent before the
+	// close, so buffered lines are never dropped.
+	received := false
+	for {
+		select {
+		case line, ok := <-logCh:
+			if !ok {
+				select {
+				case err := <-errCh:
+					return received, err
+				default:
+					return received, nil
+				}
+			}
+			received = true
+			if !f.emit(ctx, line) {
+				return received, ctx.Err()
+			}
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
+// poll fetches the lines written since the last poll.
+func (f *follower) poll(ctx context.Context, opts LogOptions) (bool, error) {
+	lines, err := f.provider.Logs(ctx, f.app, opts)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range lines {
+		if !f.emit(ctx, line) {
+			return true, ctx.Err()
+		}
+	}
+	return len(lines) > 0, nil
+}
+
+// emit delivers line unless it was delivered before or is filtered out.
+// It reports false when ctx is done.
+func (f *follower) emit(ctx context.Context, line LogLine) bool {
+	if !line.Timestamp.IsZero() {
+		if line.Timestamp.Before(f.last) {
+			return true
+		}
+		key := line.Level + "\x00" + line.Source + "\x00" + line.Message
+		if line.Timestamp.Equal(f.last) {
+			if f.seen[key] {
+				return true
+			}
+		} else {
+			f.last = line.Timestamp
+			f.seen = make(map[string]bool)
+		}
+		f.seen[key] = true
+	}
+
+	if !f.opts.Filter.Match(line) {
+		return true
+	}
+	select {
+	case f.out <- line:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
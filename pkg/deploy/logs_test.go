@@ -0,0 +1,176 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogFilter(t *testing.T) {
+	filter := ParseLogFilter(" warn, ERROR ,", "app")
+	tests := []struct {
+		line LogLine
+		want bool
+	}{
+		{LogLine{Level: "error", Source: "app"}, true},
+		{LogLine{Level: "WARN", Source: "App"}, true},
+		{LogLine{Level: "info", Source: "app"}, false},
+		{LogLine{Level: "error", Source: "deploy"}, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Match(tt.line); got != tt.want {
+			t.Errorf("Match(%+v) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+	if !(LogFilter{}).Match(LogLine{Level: "debug"}) {
+		t.Error("empty filter dropped a line")
+	}
+}
+
+// flakyStreamer streams batches of lines, dropping the connection after
+// each one.
+type flakyStreamer struct {
+	Provider
+	mu      sync.Mutex
+	batches [][]LogLine
+	opts    []LogOptions
+}
+
+func (s *flakyStreamer) StreamLogs(ctx context.Context, app string, opts LogOptions) (<-chan LogLine, <-chan error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts = append(s.opts, opts)
+	if len(s.batches) == 0 {
+		return nil, nil, errors.New("unavailable")
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+
+	logCh := make(chan LogLine, len(batch))
+	errCh := make(chan error, 1)
+	for _, line := range batch {
+		logCh <- line
+	}
+	errCh <- errors.New("connection reset")
+	close(logCh)
+	close(errCh)
+	return logCh, errCh, nil
+}
+
+func at(sec int) time.Time {
+	return time.Date(2026, 1, 15, 12, 0, sec, 0, time.UTC)
+}
+
+func collect(t *testing.T, lines <-chan LogLine, n int) []LogLine {
+	t.Helper()
+	var got []LogLine
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("channel closed after %d lines", len(got))
+			}
+			got = append(got, line)
+		case <-timeout:
+			t.Fatalf("got %d lines, want %d", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestFollowReconnects(t *testing.T) {
+	s := &flakyStreamer{batches: [][]LogLine{
+		{{Timestamp: at(1), Message: "a", Level: "info"}, {Timestamp: at(2), Message: "b", Level: "error"}},
+		// The reconnect overlaps the last line, which is not repeated
+		{{Timestamp: at(2), Message: "b", Level: "error"}, {Timestamp: at(2), Message: "c", Level: "info"}, {Timestamp: at(3), Message: "d", Level: "info"}},
+	}}
+
+	var mu sync.Mutex
+	var reconnects []error
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := Follow(ctx, s, "shop", FollowOptions{
+		LogOptions: LogOptions{Tail: 10},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+		OnReconnect: func(err error, wait time.Duration) {
+			mu.Lock()
+			reconnects = append(reconnects, err)
+			mu.Unlock()
+		},
+	})
+
+	got := collect(t, lines, 4)
+	var msgs string
+	for _, line := range got {
+		msgs += line.Message
+	}
+	if msgs != "abcd" {
+		t.Errorf("messages = %q, want abcd", msgs)
+	}
+
+	cancel()
+	for range lines {
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opts[0].Tail != 10 || !s.opts[0].Follow {
+		t.Errorf("first connection options = %+v", s.opts[0])
+	}
+	if s.opts[1].Tail != 0 || s.opts[1].Since <= 0 {
+		t.Errorf("reconnect options = %+v, want Since and no Tail", s.opts[1])
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reconnects) < 2 || reconnects[0] == nil {
+		t.Errorf("reconnects = %v", reconnects)
+	}
+}
+
+// pollingProvider returns a growing log on each Logs call.
+type pollingProvider struct {
+	Provider
+	mu    sync.Mutex
+	log   []LogLine
+	calls int
+}
+
+func (p *pollingProvider) Logs(ctx context.Context, app string, opts LogOptions) ([]LogLine, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls == 2 {
+		p.log = append(p.log, LogLine{Timestamp: at(5), Message: "new", Source: "app"})
+	}
+	return append([]LogLine(nil), p.log...), nil
+}
+
+func TestFollowPolls(t *testing.T) {
+	p := &pollingProvider{log: []LogLine{
+		{Timestamp: at(1), Message: "old", Source: "system"},
+		{Timestamp: at(2), Message: "first", Source: "app"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := Follow(ctx, p, "shop", FollowOptions{
+		Filter:       LogFilter{Sources: []string{"app"}},
+		PollInterval: time.Millisecond,
+	})
+
+	got := collect(t, lines, 2)
+	if got[0].Message != "first" || got[1].Message != "new" {
+		t.Errorf("lines = %+v", got)
+	}
+
+	// Later polls return the same lines, which are not repeated
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	for line := range lines {
+		t.Errorf("repeated line %+v", line)
+	}
+}
@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+)
+
+// NexoCloud is the Provider for Nexo Cloud. Images are pushed to GHCR under
+// the logged-in user.
+type NexoCloud struct {
+	Client *cloud.Client
+
+	// Username is the GHCR namespace for images (default: the user saved
+	// by nexo login).
+	Username string
+}
+
+// NewNexoCloud creates the Nexo Cloud provider.
+func NewNexoCloud(client *cloud.Client) *NexoCloud {
+	return &NexoCloud{Client: client}
+}
+
+// Name returns "Nexo Cloud".
+func (n *NexoCloud) Name() string { return "Nexo Cloud" }
+
+// ImageRef returns ghcr.io/<user>/<app>:<tag>.
+func (n *NexoCloud) ImageRef(ctx context.Context, app, tag string) (string, error) {
+	username := n.Username
+	if username == "" {
+		username = "user"
+		if creds, _ := cloud.LoadCredentials(); creds != nil && creds.User != nil {
+			username = creds.User.Username
+		}
+	}
+	return fmt.Sprintf("ghcr.io/%s/%s:%s", username, app, tag), nil
+}
+
+// GetApp returns the app.
+func (n *NexoCloud) GetApp(ctx context.Context, app string) (*App, error) {
+	a, err := n.Client.GetApp(ctx, app)
+	var apiErr *cloud.APIError
+	if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+		return nil, fmt.Errorf("%w: %s", ErrAppNotFound, app)
+	}
+	return a, err
+}
+
+// CreateApp creates the app.
+func (n *NexoCloud) CreateApp(ctx context.Context, spec AppSpec) (*App, error) {
+	return n.Client.CreateApp(ctx, spec.Name, spec.Region, spec.Size)
+}
+
+// Deploy starts a deployment of image.
+func (n *NexoCloud) Deploy(ctx context.Context, app, image string) (*Deployment, error) {
+	return n.Client.Deploy(ctx, app, image)
+}
+
+// Deployment returns a deployment.
+func (n *NexoCloud) Deployment(ctx context.Context, app, id string) (*Deployment, error) {
+	return n.Client.GetDeployment(ctx, app, id)
+}
+
+// Deployments lists the app's deployments.
+func (n *NexoCloud) Deployments(ctx context.Context, app string) ([]Deployment, error) {
+	return n.Client.ListDeployments(ctx, app)
+}
+
+// Logs returns recent log lines.
+func (n *NexoCloud) Logs(ctx context.Context, app string, opts LogOptions) ([]LogLine, error) {
+	return n.Client.GetLogs(ctx, app, opts)
+}
+
+// StreamLogs streams log lines over SSE.
+func (n *NexoCloud) StreamLogs(ctx context.Context, app string, opts LogOptions) (<-chan LogLine, <-chan error, error) {
+	return n.Client.StreamLogs(ctx, app, opts)
+}
+
+// GetEnv returns the app's environment variables.
+func (n *NexoCloud) GetEnv(ctx context.Context, app string) (map[string]string, error) {
+	return n.Client.GetEnv(ctx, app)
+}
+
+// SetEnv sets environment variables.
+func (n *NexoCloud) SetEnv(ctx context.Context, app string, vars map[string]string) error {
+	return n.Client.SetEnv(ctx, app, vars)
+}
+
+// Domains lists custom domains.
+func (n *NexoCloud) Domains(ctx context.Context, app string) ([]Domain, error) {
+	return n.Client.ListDomains(ctx, app)
+}
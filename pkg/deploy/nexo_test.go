@@ -0,0 +1,54 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/cloud"
+)
+
+func TestNexoCloud(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/apps/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"not_found","message":"app not found"}`))
+		case "/api/apps/shop":
+			_, _ = w.Write([]byte(`{"name":"shop","url":"https://shop.nexo.app"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := cloud.NewClient("token")
+	client.BaseURL = server.URL
+	p := NewNexoCloud(client)
+	p.Username = "acme"
+	ctx := context.Background()
+
+	if _, err := p.GetApp(ctx, "missing"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("GetApp(missing) error = %v, want ErrAppNotFound", err)
+	}
+	app, err := p.GetApp(ctx, "shop")
+	if err != nil || app.URL != "https://shop.nexo.app" {
+		t.Errorf("GetApp(shop) = %+v, %v", app, err)
+	}
+
+	image, _ := p.ImageRef(ctx, "shop", "v1")
+	if image != "ghcr.io/acme/shop:v1" {
+		t.Errorf("ImageRef() = %q", image)
+	}
+	if _, ok := Provider(p).(LogStreamer); !ok {
+		t.Error("NexoCloud does not stream logs")
+	}
+	if _, ok := Provider(p).(EnvReader); !ok {
+		t.Error("NexoCloud does not read env")
+	}
+	if _, ok := Provider(NewFly(FlyOptions{})).(EnvReader); ok {
+		t.Error("Fly secrets should not be readable")
+	}
+}
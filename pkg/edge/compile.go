@@ -0,0 +1,1236 @@
+package edge
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+const nexoImport = "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// maxAlternatives bounds the rules one return expands to, which grows with
+// negated and nested || conditions.
+const maxAlternatives = 256
+
+// statusCodes are the net/http status constants proxies use.
+var statusCodes = map[string]int{
+	"StatusOK":                  200,
+	"StatusCreated":             201,
+	"StatusAccepted":            202,
+	"StatusNoContent":           204,
+	"StatusMovedPermanently":    301,
+	"StatusFound":               302,
+	"StatusSeeOther":            303,
+	"StatusNotModified":         304,
+	"StatusTemporaryRedirect":   307,
+	"StatusPermanentRedirect":   308,
+	"StatusBadRequest":          400,
+	"StatusUnauthorized":        401,
+	"StatusPaymentRequired":     402,
+	"StatusForbidden":           403,
+	"StatusNotFound":            404,
+	"StatusMethodNotAllowed":    405,
+	"StatusGone":                410,
+	"StatusTeapot":              418,
+	"StatusTooManyRequests":     429,
+	"StatusInternalServerError": 500,
+	"StatusNotImplemented":      501,
+	"StatusBadGateway":          502,
+	"StatusServiceUnavailable":  503,
+	"StatusGatewayTimeout":      504,
+}
+
+// CompileFile compiles the Proxy function of the proxy.go at path.
+func CompileFile(path string) (*Rules, []Issue, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Compile(path, src)
+}
+
+// Compile compiles the Proxy function of a proxy.go file. Constructs the
+// edge can't run are returned as issues; the rules then don't behave like
+// the proxy and mustn't be deployed.
+func Compile(filename string, src []byte) (*Rules, []Issue, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &compiler{
+		fset:     fset,
+		filename: filename,
+		imports:  make(map[string]string),
+		globals:  make(map[string]ast.Expr),
+		reported: make(map[Issue]bool),
+	}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		c.imports[name] = path
+	}
+
+	var proxy *ast.FuncDecl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name == "Proxy" {
+				proxy = d
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok && len(vs.Values) == len(vs.Names) {
+					for i, name := range vs.Names {
+						c.globals[name.Name] = vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	if proxy == nil || proxy.Body == nil {
+		return nil, nil, fmt.Errorf("no Proxy function in %s", filename)
+	}
+	if params := proxy.Type.Params.List; len(params) == 1 && len(params[0].Names) == 1 {
+		c.ctx = params[0].Names[0].Name
+	}
+
+	rules := &Rules{}
+	if cfg, ok := c.globals["ProxyConfig"]; ok {
+		rules.Matcher = c.matcher(cfg)
+	}
+	c.block(proxy.Body.List, always, &scope{}, nil)
+	rules.Rules = c.rules
+	if rules.Rules == nil {
+		rules.Rules = []Rule{}
+	}
+	return rules, c.issues, nil
+}
+
+// compiler compiles the statements of a Proxy function to rules.
+type compiler struct {
+	fset     *token.FileSet
+	filename string
+	ctx      string              // name of the *nexo.Context parameter
+	imports  map[string]string   // import name -> path
+	globals  map[string]ast.Expr // package-level variables and constants
+	depth    int                 // nesting of global lookups
+	rules    []Rule
+	issues   []Issue
+	reported map[Issue]bool
+}
+
+// ---------- Conditions ----------
+
+// conj holds when all its conditions hold; a dnf when any conj holds. A
+// nil dnf never holds, always always does.
+type (
+	conj []Condition
+	dnf  []conj
+)
+
+var always = dnf{nil}
+
+func (d dnf) and(e dnf) dnf {
+	var out dnf
+	for _, a := range d {
+		for _, b := range e {
+			if k, ok := a.and(b); ok {
+				out = append(out, k)
+			}
+		}
+	}
+	return out
+}
+
+func (d dnf) not() dnf {
+	out := always
+	for _, k := range d {
+		var neg dnf
+		for _, cond := range k {
+			cond.Negate = !cond.Negate
+			neg = append(neg, conj{cond})
+		}
+		out = out.and(neg)
+	}
+	return out
+}
+
+// and returns a ∧ b, or false when they contradict each other.
+func (a conj) and(b conj) (conj, bool) {
+	k := slices.Clip(a)
+	for _, cond := range b {
+		redundant := false
+		for _, prev := range k {
+			if prev.Field != cond.Field || prev.Name != cond.Name {
+				continue
+			}
+			if prev == cond {
+				redundant = true
+				break
+			}
+			if prev.Op == cond.Op && prev.Value == cond.Value {
+				return nil, false // c ∧ !c
+			}
+			if prev.Op == OpEq && cond.Op == OpEq {
+				switch {
+				case !prev.Negate && !cond.Negate:
+					return nil, false // equal to two values
+				case !prev.Negate:
+					redundant = true // == "a" implies != "b"
+				}
+			}
+		}
+		if !redundant {
+			k = append(k, cond)
+		}
+	}
+	return k, true
+}
+
+// ---------- Values ----------
+
+// alt is an alternative of a string value: parts, when its conditions
+// hold. The alternatives of a value are exclusive and one always holds.
+type alt struct {
+	when  dnf
+	parts []Part
+}
+
+type value []alt
+
+func literal(s string) value {
+	return value{{when: always, parts: []Part{{Literal: s}}}}
+}
+
+// literal returns the value of v when it doesn't depend on the request.
+func (v value) literal() (string, bool) {
+	if len(v) != 1 {
+		return "", false
+	}
+	var b strings.Builder
+	for _, p := range v[0].parts {
+		if p.Field != "" {
+			return "", false
+		}
+		b.WriteString(p.Literal)
+	}
+	return b.String(), true
+}
+
+func (v value) concat(w value) value {
+	var out value
+	for _, a := range v {
+		for _, b := range w {
+			when := a.when.and(b.when)
+			if len(when) == 0 {
+				continue
+			}
+			out = append(out, alt{when: when, parts: joinParts(a.parts, b.parts)})
+		}
+	}
+	return out
+}
+
+// joinParts concatenates parts, merging adjacent literals.
+func joinParts(a, b []Part) []Part {
+	out := slices.Clone(a)
+	for _, p := range b {
+		if p.Field == "" {
+			if p.Literal == "" {
+				continue
+			}
+			if n := len(out); n > 0 && out[n-1].Field == "" {
+				out[n-1].Literal += p.Literal
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// variable is a local variable declared when its conditions held.
+type variable struct {
+	val  value
+	when dnf
+}
+
+type scope struct {
+	vars   map[string]*variable
+	parent *scope
+}
+
+func (s *scope) child() *scope {
+	return &scope{parent: s}
+}
+
+func (s *scope) lookup(name string) *variable {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *scope) define(name string, v value, when dnf) {
+	if name == "_" {
+		return
+	}
+	if s.vars == nil {
+		s.vars = make(map[string]*variable)
+	}
+	// A variable always holds its value where it's visible
+	s.vars[name] = &variable{val: v, when: when}
+}
+
+// ---------- Statements ----------
+
+// block compiles stmts, reached when when holds. It reports whether they
+// always return, and returns headers with those set by c.SetHeader.
+func (c *compiler) block(stmts []ast.Stmt, when dnf, sc *scope, headers []Header) (bool, []Header) {
+	if len(when) == 0 {
+		return true, headers // unreachable
+	}
+	for _, stmt := range stmts {
+		done := false
+		switch s := stmt.(type) {
+		case *ast.ReturnStmt:
+			c.ret(s, when, sc, headers)
+			return true, headers
+		case *ast.IfStmt:
+			done = c.ifStmt(s, when, sc.child(), headers)
+		case *ast.BlockStmt:
+			done = c.nested(s, s.List, when, sc.child(), headers)
+		case *ast.SwitchStmt:
+			done = c.switchStmt(s, when, sc.child(), headers)
+		case *ast.RangeStmt:
+			c.rangeStmt(s, when, sc, headers)
+		case *ast.AssignStmt:
+			c.assign(s, when, sc)
+		case *ast.DeclStmt:
+			c.decl(s, sc)
+		case *ast.ExprStmt:
+			if h, ok := c.setHeader(s.X, sc); ok {
+				headers = append(slices.Clip(headers), h)
+			}
+		case *ast.EmptyStmt:
+		default:
+			c.report(s, "%s aren't supported at the edge", statementKind(s))
+		}
+		if done {
+			return true, headers
+		}
+	}
+	return false, headers
+}
+
+// nested compiles the statements of a nested block. Headers it sets can't
+// flow to the statements after it.
+func (c *compiler) nested(node ast.Node, stmts []ast.Stmt, when dnf, sc *scope, headers []Header) bool {
+	done, out := c.block(stmts, when, sc, headers)
+	if !done && len(out) > len(headers) {
+		c.report(node, "c.SetHeader in a block that doesn't return isn't supported at the edge")
+	}
+	return done
+}
+
+// ret compiles a return statement to rules.
+func (c *compiler) ret(s *ast.ReturnStmt, when dnf, sc *scope, headers []Header) {
+	if len(s.Results) != 2 {
+		c.report(s, "return must return a result and an error")
+		return
+	}
+	if !isNil(s.Results[1]) {
+		c.report(s.Results[1], "returning an error isn't supported at the edge: the app renders it")
+		return
+	}
+	action, url, ok := c.action(s.Results[0], sc)
+	if !ok {
+		return
+	}
+	if url == nil {
+		url = value{{when: always}}
+	}
+
+	pos := c.pos(s)
+	for _, u := range url {
+		w := c.limit(s, when.and(u.when))
+		for _, k := range w {
+			a := action
+			a.URL = u.parts
+			c.rules = append(c.rules, Rule{When: k, Action: a, Headers: headers, Pos: pos})
+		}
+	}
+}
+
+func (c *compiler) ifStmt(s *ast.IfStmt, when dnf, sc *scope, headers []Header) bool {
+	if s.Init != nil {
+		if c.mapLookup(s, when, sc, headers) {
+			return false
+		}
+		if assign, ok := s.Init.(*ast.AssignStmt); ok {
+			c.assign(assign, when, sc)
+		} else {
+			c.report(s.Init, "%s aren't supported at the edge", statementKind(s.Init))
+		}
+	}
+
+	cond, ok := c.cond(s.Cond, sc)
+	if !ok {
+		// Compile the branches anyway, for their issues
+		c.nested(s.Body, s.Body.List, when, sc.child(), headers)
+		if s.Else != nil {
+			c.nested(s.Else, []ast.Stmt{s.Else}, when, sc.child(), headers)
+		}
+		return false
+	}
+	thenDone := c.nested(s.Body, s.Body.List, c.limit(s.Cond, when.and(cond)), sc.child(), headers)
+
+	// A branch that returns needs no negated condition in the rules after
+	// it: the first matching rule wins
+	elseWhen := when
+	if !thenDone || s.Else == nil {
+		elseWhen = c.limit(s.Cond, when.and(cond.not()))
+	}
+	if s.Else == nil {
+		return thenDone && len(elseWhen) == 0
+	}
+	elseStmts := []ast.Stmt{s.Else}
+	if b, ok := s.Else.(*ast.BlockStmt); ok {
+		elseStmts = b.List
+	}
+	elseDone := c.nested(s.Else, elseStmts, elseWhen, sc.child(), headers)
+	return thenDone && elseDone
+}
+
+// mapLookup compiles the body of
+//
+//	if to, ok := redirects[c.Path()]; ok {
+//
+// once for each entry of the map literal redirects, and reports whether s
+// has that form.
+func (c *compiler) mapLookup(s *ast.IfStmt, when dnf, sc *scope, headers []Header) bool {
+	assign, ok := s.Init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return false
+	}
+	index, ok := assign.Rhs[0].(*ast.IndexExpr)
+	if !ok {
+		return false
+	}
+	okName, isIdent := assign.Lhs[1].(*ast.Ident)
+	if cond, isCond := s.Cond.(*ast.Ident); !isIdent || !isCond || cond.Name != okName.Name || s.Else != nil {
+		c.report(s, "only if v, ok := m[key]; ok { ... } is supported for map lookups at the edge")
+		return true
+	}
+	entries, ok := c.mapLiteral(index.X)
+	if !ok {
+		c.report(index.X, "%s isn't a package-level map literal", types.ExprString(index.X))
+		return true
+	}
+	key, ok := c.eval(index.Index, sc)
+	if !ok {
+		return true
+	}
+
+	for _, kv := range entries {
+		k, ok := c.eval(kv.Key, sc)
+		if !ok {
+			continue
+		}
+		v, ok := c.eval(kv.Value, sc)
+		if !ok {
+			continue
+		}
+		match, ok := c.compare(s, key, k, OpEq)
+		if !ok {
+			return true
+		}
+		body := sc.child()
+		if name, ok := assign.Lhs[0].(*ast.Ident); ok {
+			body.define(name.Name, v, always)
+		}
+		c.nested(s.Body, s.Body.List, c.limit(s, when.and(match)), body, headers)
+	}
+	return true
+}
+
+func (c *compiler) switchStmt(s *ast.SwitchStmt, when dnf, sc *scope, headers []Header) bool {
+	if s.Init != nil {
+		if assign, ok := s.Init.(*ast.AssignStmt); ok {
+			c.assign(assign, when, sc)
+		} else {
+			c.report(s.Init, "%s aren't supported at the edge", statementKind(s.Init))
+		}
+	}
+	var tag value
+	if s.Tag != nil {
+		var ok bool
+		if tag, ok = c.eval(s.Tag, sc); !ok {
+			return false
+		}
+	}
+
+	// Cases whose body may not return must not match in the cases after
+	// them; the default case runs when no case matches
+	var fallen, matched dnf
+	done := true
+	var def *ast.CaseClause
+	for _, stmt := range s.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			def = clause
+			continue
+		}
+		var cond dnf
+		for _, e := range clause.List {
+			var d dnf
+			var ok bool
+			if tag != nil {
+				var v value
+				if v, ok = c.eval(e, sc); ok {
+					d, ok = c.compare(e, tag, v, OpEq)
+				}
+			} else {
+				d, ok = c.cond(e, sc)
+			}
+			if !ok {
+				d = always
+			}
+			cond = append(cond, d...)
+		}
+		caseWhen := when.and(cond)
+		if fallen != nil {
+			caseWhen = caseWhen.and(fallen.not())
+		}
+		caseDone := c.nested(clause, clause.Body, c.limit(clause, caseWhen), sc.child(), headers)
+		if !caseDone {
+			fallen = append(fallen, cond...)
+			done = false
+		}
+		matched = append(matched, cond...)
+	}
+	if def == nil {
+		return false
+	}
+	defWhen := when
+	if fallen != nil {
+		defWhen = defWhen.and(fallen.not())
+	}
+	if !done {
+		// The default case can't rely on the earlier cases returning
+		defWhen = when.and(matched.not())
+	}
+	return c.nested(def, def.Body, c.limit(def, defWhen), sc.child(), headers) && done
+}
+
+// rangeStmt unrolls a loop over a package-level slice literal.
+func (c *compiler) rangeStmt(s *ast.RangeStmt, when dnf, sc *scope, headers []Header) {
+	if s.Key != nil && !isBlank(s.Key) {
+		c.report(s.Key, "only the values of a slice can be ranged over at the edge")
+		return
+	}
+	elems, ok := c.sliceLiteral(s.X)
+	if !ok {
+		c.report(s.X, "loops are only supported over package-level slice literals at the edge")
+		return
+	}
+	for _, e := range elems {
+		v, ok := c.eval(e, sc)
+		if !ok {
+			return
+		}
+		body := sc.child()
+		if name, ok := s.Value.(*ast.Ident); ok {
+			body.define(name.Name, v, always)
+		}
+		c.nested(s, s.Body.List, when, body, headers)
+	}
+}
+
+func (c *compiler) assign(s *ast.AssignStmt, when dnf, sc *scope) {
+	if (s.Tok != token.DEFINE && s.Tok != token.ASSIGN) || len(s.Lhs) != len(s.Rhs) {
+		c.report(s, "only assignments of strings are supported at the edge")
+		return
+	}
+	for i, lhs := range s.Lhs {
+		name, ok := lhs.(*ast.Ident)
+		if !ok {
+			c.report(lhs, "assigning to %s isn't supported at the edge", types.ExprString(lhs))
+			continue
+		}
+		v, ok := c.eval(s.Rhs[i], sc)
+		if !ok {
+			continue
+		}
+		if s.Tok == token.DEFINE {
+			sc.define(name.Name, v, when)
+			continue
+		}
+
+		variable := sc.lookup(name.Name)
+		if variable == nil {
+			if name.Name != "_" {
+				c.report(lhs, "assigning to package-level variable %s isn't supported at the edge", name.Name)
+			}
+			continue
+		}
+		if reflect.DeepEqual(variable.when, when) {
+			variable.val = v
+			continue
+		}
+		// Assigned in a branch: the new value holds when the branch runs
+		var val value
+		for _, a := range v {
+			if w := when.and(a.when); len(w) > 0 {
+				val = append(val, alt{when: c.limit(s, w), parts: a.parts})
+			}
+		}
+		other := when.not()
+		for _, a := range variable.val {
+			if w := a.when.and(other); len(w) > 0 {
+				val = append(val, alt{when: c.limit(s, w), parts: a.parts})
+			}
+		}
+		variable.val = val
+	}
+}
+
+func (c *compiler) decl(s *ast.DeclStmt, sc *scope) {
+	gen, ok := s.Decl.(*ast.GenDecl)
+	if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+		c.report(s, "declarations other than variables and constants aren't supported at the edge")
+		return
+	}
+	for _, spec := range gen.Specs {
+		vs := spec.(*ast.ValueSpec)
+		if len(vs.Values) != len(vs.Names) {
+			c.report(vs, "declarations without values aren't supported at the edge")
+			continue
+		}
+		for i, name := range vs.Names {
+			if v, ok := c.eval(vs.Values[i], sc); ok {
+				sc.define(name.Name, v, always)
+			}
+		}
+	}
+}
+
+// setHeader compiles a c.SetHeader(name, value) call.
+func (c *compiler) setHeader(e ast.Expr, sc *scope) (Header, bool) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		c.report(e, "%s isn't supported at the edge", types.ExprString(e))
+		return Header{}, false
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || !c.isContext(sel.X) || sel.Sel.Name != "SetHeader" || len(call.Args) != 2 {
+		c.report(call, "call to %s isn't supported at the edge", types.ExprString(call.Fun))
+		return Header{}, false
+	}
+	return c.header(call.Args[0], call.Args[1], sc)
+}
+
+func (c *compiler) header(name, val ast.Expr, sc *scope) (Header, bool) {
+	n, ok := c.literal(name, sc)
+	if !ok {
+		return Header{}, false
+	}
+	v, ok := c.eval(val, sc)
+	if !ok {
+		return Header{}, false
+	}
+	if len(v) != 1 {
+		c.report(val, "header values assigned in branches aren't supported at the edge")
+		return Header{}, false
+	}
+	return Header{Name: n, Value: v[0].parts}, true
+}
+
+// ---------- Results ----------
+
+// action compiles the result of a return statement. The URL of redirects,
+// rewrites and forwards is returned separately.
+func (c *compiler) action(e ast.Expr, sc *scope) (Action, value, bool) {
+	if isNil(e) {
+		return Action{Type: ActionContinue}, nil, true
+	}
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		c.report(e, "%s isn't supported at the edge", types.ExprString(e))
+		return Action{}, nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		c.report(call, "call to %s isn't supported at the edge", types.ExprString(call.Fun))
+		return Action{}, nil, false
+	}
+
+	// Headers
+	if !c.isPackage(sel.X, nexoImport) && (sel.Sel.Name == "WithHeader" || sel.Sel.Name == "WithHeaders") {
+		a, url, ok := c.action(sel.X, sc)
+		if !ok {
+			return a, url, false
+		}
+		if sel.Sel.Name == "WithHeader" && len(call.Args) == 2 {
+			h, ok := c.header(call.Args[0], call.Args[1], sc)
+			a.Headers = append(a.Headers, h)
+			return a, url, ok
+		}
+		entries, ok := c.mapLiteral(call.Args[0])
+		if !ok {
+			c.report(call.Args[0], "WithHeaders needs a map literal at the edge")
+			return a, url, false
+		}
+		for _, kv := range entries {
+			h, ok := c.header(kv.Key, kv.Value, sc)
+			if !ok {
+				return a, url, false
+			}
+			a.Headers = append(a.Headers, h)
+		}
+		return a, url, true
+	}
+
+	if !c.isPackage(sel.X, nexoImport) {
+		c.report(call, "call to %s isn't supported at the edge", types.ExprString(call.Fun))
+		return Action{}, nil, false
+	}
+	args := call.Args
+	switch name := sel.Sel.Name; {
+	case name == "Continue":
+		return Action{Type: ActionContinue}, nil, true
+	case name == "Redirect" && len(args) == 2:
+		url, ok := c.eval(args[0], sc)
+		status, ok2 := c.status(args[1], sc)
+		return Action{Type: ActionRedirect, Status: status}, url, ok && ok2
+	case name == "Rewrite" && len(args) == 1:
+		url, ok := c.eval(args[0], sc)
+		return Action{Type: ActionRewrite}, url, ok
+	case name == "Forward" && len(args) == 1:
+		url, ok := c.eval(args[0], sc)
+		return Action{Type: ActionForward}, url, ok
+	case name == "Response" && len(args) == 3:
+		status, ok := c.status(args[0], sc)
+		body := args[1]
+		if conv, isConv := body.(*ast.CallExpr); isConv && len(conv.Args) == 1 {
+			if _, isSlice := conv.Fun.(*ast.ArrayType); isSlice {
+				body = conv.Args[0]
+			}
+		}
+		b, ok2 := c.literal(body, sc)
+		ct, ok3 := c.literal(args[2], sc)
+		return Action{Type: ActionResponse, Status: status, Body: b, ContentType: ct}, nil, ok && ok2 && ok3
+	case (name == "ResponseJSON" || name == "ResponseHTML") && len(args) == 2:
+		status, ok := c.status(args[0], sc)
+		b, ok2 := c.literal(args[1], sc)
+		ct := "application/json"
+		if name == "ResponseHTML" {
+			ct = "text/html; charset=utf-8"
+		}
+		return Action{Type: ActionResponse, Status: status, Body: b, ContentType: ct}, nil, ok && ok2
+	}
+	c.report(call, "call to %s isn't supported at the edge", types.ExprString(call.Fun))
+	return Action{}, nil, false
+}
+
+// status evaluates a status code.
+func (c *compiler) status(e ast.Expr, sc *scope) (int, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if n, err := strconv.Atoi(e.Value); err == nil && e.Kind == token.INT {
+			return n, true
+		}
+	case *ast.SelectorExpr:
+		if code, ok := statusCodes[e.Sel.Name]; ok && c.isPackage(e.X, "net/http") {
+			return code, true
+		}
+	case *ast.Ident:
+		if g, ok := c.global(e, sc); ok {
+			defer func() { c.depth-- }()
+			c.depth++
+			return c.status(g, sc)
+		}
+	}
+	c.report(e, "status code %s isn't a constant", types.ExprString(e))
+	return 0, false
+}
+
+// ---------- Expressions ----------
+
+// cond compiles a boolean expression.
+func (c *compiler) cond(e ast.Expr, sc *scope) (dnf, bool) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return c.cond(e.X, sc)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			d, ok := c.cond(e.X, sc)
+			return c.limit(e, d.not()), ok
+		}
+	case *ast.Ident:
+		if sc.lookup(e.Name) == nil {
+			if g, ok := c.global(e, sc); ok {
+				defer func() { c.depth-- }()
+				c.depth++
+				return c.cond(g, sc)
+			}
+			switch e.Name {
+			case "true":
+				return always, true
+			case "false":
+				return nil, true
+			}
+		}
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.LAND, token.LOR:
+			x, ok := c.cond(e.X, sc)
+			y, ok2 := c.cond(e.Y, sc)
+			if e.Op == token.LOR {
+				return append(x, y...), ok && ok2
+			}
+			return c.limit(e, x.and(y)), ok && ok2
+		case token.EQL, token.NEQ:
+			d, ok := c.equal(e, sc)
+			if e.Op == token.NEQ {
+				d = d.not()
+			}
+			return d, ok
+		}
+	case *ast.CallExpr:
+		ops := map[string]string{"HasPrefix": OpPrefix, "HasSuffix": OpSuffix, "Contains": OpContains}
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && c.isPackage(sel.X, "strings") && ops[sel.Sel.Name] != "" && len(e.Args) == 2 {
+			x, ok := c.eval(e.Args[0], sc)
+			y, ok2 := c.eval(e.Args[1], sc)
+			if !ok || !ok2 {
+				return nil, false
+			}
+			return c.compare(e, x, y, ops[sel.Sel.Name])
+		}
+	}
+	c.report(e, "condition %s isn't supported at the edge", types.ExprString(e))
+	return nil, false
+}
+
+// equal compiles x == y.
+func (c *compiler) equal(e *ast.BinaryExpr, sc *scope) (dnf, bool) {
+	// The scheme, from c.Request.TLS == nil
+	for _, pair := range [][2]ast.Expr{{e.X, e.Y}, {e.Y, e.X}} {
+		if path, ok := c.contextPath(pair[0]); ok && path == "Request.TLS" && isNil(pair[1]) {
+			return dnf{{{Field: FieldScheme, Op: OpEq, Value: "http"}}}, true
+		}
+	}
+	x, ok := c.eval(e.X, sc)
+	y, ok2 := c.eval(e.Y, sc)
+	if !ok || !ok2 {
+		return nil, false
+	}
+	return c.compare(e, x, y, OpEq)
+}
+
+// compare compiles a comparison of x to y with op.
+func (c *compiler) compare(node ast.Node, x, y value, op string) (dnf, bool) {
+	var out dnf
+	for _, a := range x {
+		for _, b := range y {
+			when := a.when.and(b.when)
+			if len(when) == 0 {
+				continue
+			}
+			atom, ok := c.atom(node, a.parts, b.parts, op)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, when.and(atom)...)
+		}
+	}
+	return c.limit(node, out), true
+}
+
+// atom compiles the comparison of two values without alternatives.
+func (c *compiler) atom(node ast.Node, x, y []Part, op string) (dnf, bool) {
+	xs, xLit := value{{parts: x}}.literal()
+	ys, yLit := value{{parts: y}}.literal()
+	if xLit && yLit {
+		var holds bool
+		switch op {
+		case OpEq:
+			holds = xs == ys
+		case OpPrefix:
+			holds = strings.HasPrefix(xs, ys)
+		case OpSuffix:
+			holds = strings.HasSuffix(xs, ys)
+		default:
+			holds = strings.Contains(xs, ys)
+		}
+		if holds {
+			return always, true
+		}
+		return nil, true
+	}
+	if op == OpEq && xLit {
+		x, y, ys = y, x, xs
+		yLit = true
+	}
+	if yLit && len(x) == 1 && x[0].Field != "" && x[0].TrimPrefix == "" && x[0].TrimSuffix == "" {
+		return dnf{{{Field: x[0].Field, Name: x[0].Name, Op: op, Value: ys}}}, true
+	}
+	c.report(node, "%s compares values the edge can't compare: only request fields to constants", nodeString(node))
+	return nil, false
+}
+
+// eval evaluates a string expression.
+func (c *compiler) eval(e ast.Expr, sc *scope) (value, bool) {
+	if p, ok := c.field(e, sc); ok {
+		return value{{when: always, parts: []Part{p}}}, true
+	}
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			s, err := strconv.Unquote(e.Value)
+			return literal(s), err == nil
+		}
+	case *ast.ParenExpr:
+		return c.eval(e.X, sc)
+	case *ast.Ident:
+		if v := sc.lookup(e.Name); v != nil {
+			return v.val, true
+		}
+		if g, ok := c.global(e, sc); ok {
+			defer func() { c.depth-- }()
+			c.depth++
+			return c.eval(g, &scope{})
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, ok := c.eval(e.X, sc)
+			y, ok2 := c.eval(e.Y, sc)
+			return x.concat(y), ok && ok2
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if ok && c.isPackage(sel.X, "strings") && (sel.Sel.Name == "TrimPrefix" || sel.Sel.Name == "TrimSuffix") && len(e.Args) == 2 {
+			return c.trim(e, sel.Sel.Name, sc)
+		}
+		if ok {
+			c.report(e, "call to %s isn't supported at the edge", types.ExprString(e.Fun))
+			return nil, false
+		}
+	}
+	c.report(e, "%s isn't supported at the edge", types.ExprString(e))
+	return nil, false
+}
+
+// trim evaluates strings.TrimPrefix and strings.TrimSuffix.
+func (c *compiler) trim(e *ast.CallExpr, fn string, sc *scope) (value, bool) {
+	x, ok := c.eval(e.Args[0], sc)
+	if !ok {
+		return nil, false
+	}
+	affix, ok := c.literal(e.Args[1], sc)
+	if !ok {
+		return nil, false
+	}
+
+	var out value
+	for _, a := range x {
+		if s, ok := (value{{parts: a.parts}}).literal(); ok {
+			if fn == "TrimPrefix" {
+				s = strings.TrimPrefix(s, affix)
+			} else {
+				s = strings.TrimSuffix(s, affix)
+			}
+			out = append(out, alt{when: a.when, parts: []Part{{Literal: s}}})
+			continue
+		}
+		if len(a.parts) != 1 || (fn == "TrimPrefix" && a.parts[0].TrimPrefix != "") || (fn == "TrimSuffix" && a.parts[0].TrimSuffix != "") {
+			c.report(e, "%s of %s isn't supported at the edge: only of a request field", fn, types.ExprString(e.Args[0]))
+			return nil, false
+		}
+		p := a.parts[0]
+		if fn == "TrimPrefix" {
+			p.TrimPrefix = affix
+		} else {
+			p.TrimSuffix = affix
+		}
+		out = append(out, alt{when: a.when, parts: []Part{p}})
+	}
+	return out, true
+}
+
+// literal evaluates an expression that doesn't depend on the request.
+func (c *compiler) literal(e ast.Expr, sc *scope) (string, bool) {
+	v, ok := c.eval(e, sc)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.literal()
+	if !ok {
+		c.report(e, "%s must be a constant at the edge", types.ExprString(e))
+	}
+	return s, ok
+}
+
+// field returns the request field e reads.
+func (c *compiler) field(e ast.Expr, sc *scope) (Part, bool) {
+	if path, ok := c.contextPath(e); ok {
+		fields := map[string]string{
+			"Request.URL.Path":     FieldPath,
+			"Request.URL.RawQuery": FieldRawQuery,
+			"Request.Host":         FieldHost,
+			"Request.Method":       FieldMethod,
+			"Request.RequestURI":   FieldURI,
+		}
+		if f, ok := fields[path]; ok {
+			return Part{Field: f}, true
+		}
+		return Part{}, false
+	}
+
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return Part{}, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return Part{}, false
+	}
+	recv, ok := c.contextPath(sel.X)
+	if !ok {
+		// c.Request.URL.Query().Get(name)
+		if inner, isCall := sel.X.(*ast.CallExpr); isCall && sel.Sel.Name == "Get" && len(call.Args) == 1 && len(inner.Args) == 0 {
+			if path, ok := c.contextPath(inner.Fun); ok && path == "Request.URL.Query" {
+				return c.namedField(FieldQuery, call.Args[0], sc)
+			}
+		}
+		return Part{}, false
+	}
+	switch m := sel.Sel.Name; {
+	case recv == "" && len(call.Args) == 0 && m == "Path":
+		return Part{Field: FieldPath}, true
+	case recv == "" && len(call.Args) == 0 && m == "Method":
+		return Part{Field: FieldMethod}, true
+	case recv == "" && len(call.Args) == 0 && m == "ClientIP":
+		return Part{Field: FieldIP}, true
+	case recv == "" && len(call.Args) == 1 && m == "Header",
+		recv == "Request.Header" && len(call.Args) == 1 && m == "Get":
+		return c.namedField(FieldHeader, call.Args[0], sc)
+	case recv == "" && len(call.Args) == 1 && m == "Query":
+		return c.namedField(FieldQuery, call.Args[0], sc)
+	case recv == "" && len(call.Args) == 1 && m == "Cookie":
+		return c.namedField(FieldCookie, call.Args[0], sc)
+	case recv == "Request.URL" && len(call.Args) == 0 && m == "RequestURI":
+		return Part{Field: FieldURI}, true
+	}
+	return Part{}, false
+}
+
+func (c *compiler) namedField(field string, name ast.Expr, sc *scope) (Part, bool) {
+	n, ok := c.literal(name, sc)
+	if !ok {
+		return Part{}, false
+	}
+	return Part{Field: field, Name: n}, true
+}
+
+// ---------- Helpers ----------
+
+// contextPath returns e as a selector path on the context parameter, like
+// "Request.URL.Path"; the parameter itself is "".
+func (c *compiler) contextPath(e ast.Expr) (string, bool) {
+	var names []string
+	for {
+		switch x := e.(type) {
+		case *ast.SelectorExpr:
+			names = append(names, x.Sel.Name)
+			e = x.X
+			continue
+		case *ast.Ident:
+			if c.ctx == "" || x.Name != c.ctx {
+				return "", false
+			}
+			slices.Reverse(names)
+			return strings.Join(names, "."), true
+		}
+		return "", false
+	}
+}
+
+func (c *compiler) isContext(e ast.Expr) bool {
+	path, ok := c.contextPath(e)
+	return ok && path == ""
+}
+
+// isPackage reports whether e names the imported package path.
+func (c *compiler) isPackage(e ast.Expr, path string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && c.imports[id.Name] == path
+}
+
+// global returns the value of a package-level variable or constant.
+func (c *compiler) global(id *ast.Ident, sc *scope) (ast.Expr, bool) {
+	if sc.lookup(id.Name) != nil || c.depth > 8 {
+		return nil, false
+	}
+	g, ok := c.globals[id.Name]
+	return g, ok
+}
+
+func (c *compiler) mapLiteral(e ast.Expr) ([]*ast.KeyValueExpr, bool) {
+	lit, ok := c.compositeLiteral(e)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := lit.Type.(*ast.MapType); !ok {
+		return nil, false
+	}
+	entries := make([]*ast.KeyValueExpr, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, false
+		}
+		entries = append(entries, kv)
+	}
+	return entries, true
+}
+
+func (c *compiler) sliceLiteral(e ast.Expr) ([]ast.Expr, bool) {
+	lit, ok := c.compositeLiteral(e)
+	if !ok {
+		return nil, false
+	}
+	if t, ok := lit.Type.(*ast.ArrayType); !ok || t.Len != nil {
+		return nil, false
+	}
+	return lit.Elts, true
+}
+
+// compositeLiteral resolves e to a composite literal, through
+// package-level variables.
+func (c *compiler) compositeLiteral(e ast.Expr) (*ast.CompositeLit, bool) {
+	if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		e = u.X
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		if e, ok = c.globals[id.Name]; !ok {
+			return nil, false
+		}
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			e = u.X
+		}
+	}
+	lit, ok := e.(*ast.CompositeLit)
+	return lit, ok
+}
+
+// matcher compiles the Matcher patterns of the ProxyConfig literal.
+func (c *compiler) matcher(cfg ast.Expr) []string {
+	lit, ok := c.compositeLiteral(cfg)
+	if !ok {
+		c.report(cfg, "ProxyConfig must be a nexo.ProxyConfig literal at the edge")
+		return nil
+	}
+	var pc nexo.ProxyConfig
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Matcher" {
+			continue
+		}
+		elems, ok := c.sliceLiteral(kv.Value)
+		if !ok {
+			c.report(kv.Value, "ProxyConfig.Matcher must be a slice literal at the edge")
+			return nil
+		}
+		for _, e := range elems {
+			if s, ok := c.literal(e, &scope{}); ok {
+				pc.Matcher = append(pc.Matcher, s)
+			}
+		}
+	}
+	patterns, err := pc.Patterns()
+	if err != nil {
+		c.report(cfg, "invalid matcher: %v", err)
+	}
+	return patterns
+}
+
+// limit reports conditions that expanded to too many alternatives.
+func (c *compiler) limit(node ast.Node, d dnf) dnf {
+	if len(d) > maxAlternatives {
+		c.report(node, "condition expands to more than %d rules", maxAlternatives)
+		return d[:maxAlternatives]
+	}
+	return d
+}
+
+func (c *compiler) pos(node ast.Node) string {
+	p := c.fset.Position(node.Pos())
+	return fmt.Sprintf("%s:%d", c.filename, p.Line)
+}
+
+func (c *compiler) report(node ast.Node, format string, args ...any) {
+	issue := Issue{Pos: c.pos(node), Message: fmt.Sprintf(format, args...)}
+	if !c.reported[issue] {
+		c.reported[issue] = true
+		c.issues = append(c.issues, issue)
+	}
+}
+
+func isNil(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+// statementKind describes a statement the edge doesn't run, for issues.
+func statementKind(s ast.Stmt) string {
+	switch s := s.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		return "loops"
+	case *ast.BranchStmt:
+		return s.Tok.String() + " statements"
+	case *ast.DeferStmt:
+		return "defer statements"
+	case *ast.GoStmt:
+		return "go statements"
+	case *ast.IncDecStmt:
+		return "increments"
+	case *ast.SendStmt, *ast.SelectStmt:
+		return "channel operations"
+	case *ast.TypeSwitchStmt:
+		return "type switches"
+	case *ast.LabeledStmt:
+		return "labeled statements"
+	}
+	return "statements like this"
+}
+
+func nodeString(node ast.Node) string {
+	if e, ok := node.(ast.Expr); ok {
+		return types.ExprString(e)
+	}
+	return "statement"
+}
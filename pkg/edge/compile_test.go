@@ -0,0 +1,254 @@
+package edge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// describe renders a rule like `path == "/old" => redirect 301 /new`.
+func describe(r Rule) string {
+	var b strings.Builder
+	for i, c := range r.When {
+		if i > 0 {
+			b.WriteString(" && ")
+		}
+		b.WriteString(c.String())
+	}
+	if len(r.When) > 0 {
+		b.WriteString(" => ")
+	}
+	b.WriteString(r.Action.Type)
+	if r.Action.Status != 0 {
+		fmt.Fprintf(&b, " %d", r.Action.Status)
+	}
+	if r.Action.URL != nil {
+		b.WriteString(" " + describeParts(r.Action.URL))
+	}
+	if r.Action.Body != "" {
+		fmt.Fprintf(&b, " %q", r.Action.Body)
+	}
+	for _, h := range append(r.Action.Headers, r.Headers...) {
+		fmt.Fprintf(&b, " [%s: %s]", h.Name, describeParts(h.Value))
+	}
+	return b.String()
+}
+
+func describeParts(parts []Part) string {
+	var b strings.Builder
+	for _, p := range parts {
+		switch {
+		case p.Field == "":
+			b.WriteString(p.Literal)
+		case p.TrimPrefix != "":
+			fmt.Fprintf(&b, "{%s-%s}", p.Field, p.TrimPrefix)
+		default:
+			b.WriteString("{" + p.Field + "}")
+		}
+	}
+	return b.String()
+}
+
+const proxyHeader = `package app
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+var _, _, _ = http.StatusOK, strings.ToLower, time.Now
+`
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		wantRules  []string
+		wantIssues []string
+	}{
+		{
+			name: "redirect map",
+			src: `
+var redirects = map[string]string{
+	"/old":  "/new",
+	"/blog": "/posts",
+}
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	if to, ok := redirects[c.Path()]; ok {
+		return nexo.Redirect(to, http.StatusMovedPermanently), nil
+	}
+	return nexo.Continue(), nil
+}`,
+			wantRules: []string{
+				`path == "/old" => redirect 301 /new`,
+				`path == "/blog" => redirect 301 /posts`,
+				`continue`,
+			},
+		},
+		{
+			name: "prefix rewrite",
+			src: `
+const beta = "/beta/"
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	path := c.Request.URL.Path
+	if strings.HasPrefix(path, "/docs/") && c.Header("X-Beta") == "1" {
+		return nexo.Rewrite(beta + strings.TrimPrefix(path, "/docs/")), nil
+	}
+	return nil, nil
+}`,
+			wantRules: []string{
+				`HasPrefix(path, "/docs/") && header("X-Beta") == "1" => rewrite /beta/{path-/docs/}`,
+				`continue`,
+			},
+		},
+		{
+			name: "variable assigned in a branch",
+			src: `
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	scheme := "https"
+	if c.Request.TLS == nil {
+		scheme = "http"
+	}
+	if strings.HasPrefix(c.Request.Host, "www.") {
+		return nexo.Redirect(scheme+"://"+strings.TrimPrefix(c.Request.Host, "www.")+c.Request.RequestURI, 308), nil
+	}
+	return nexo.Continue(), nil
+}`,
+			wantRules: []string{
+				`HasPrefix(host, "www.") && scheme == "http" => redirect 308 http://{host-www.}{uri}`,
+				`HasPrefix(host, "www.") && scheme != "http" => redirect 308 https://{host-www.}{uri}`,
+				`continue`,
+			},
+		},
+		{
+			name: "else and or",
+			src: `
+var maintenance = true
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	if !maintenance {
+		return nexo.Continue(), nil
+	}
+	if c.Path() == "/health" || strings.HasPrefix(c.Path(), "/static/") {
+		c.SetHeader("X-Maintenance", "bypass")
+	} else {
+		return nexo.ResponseHTML(503, "<h1>Back soon</h1>").WithHeader("Retry-After", "3600"), nil
+	}
+	return nexo.Continue(), nil
+}`,
+			wantRules: []string{
+				`path != "/health" && !HasPrefix(path, "/static/") => response 503 "<h1>Back soon</h1>" [Retry-After: 3600]`,
+				`continue`,
+			},
+			wantIssues: []string{"proxy.go:19: c.SetHeader in a block that doesn't return"},
+		},
+		{
+			name: "switch and loop",
+			src: `
+var blocked = []string{"/wp-admin", "/.env"}
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	for _, p := range blocked {
+		if strings.HasPrefix(c.Path(), p) {
+			return nexo.Response(404, []byte("not found"), "text/plain"), nil
+		}
+	}
+	switch c.Method() {
+	case "TRACE", "CONNECT":
+		return nexo.ResponseJSON(405, ` + "`" + `{"error":"method not allowed"}` + "`" + `), nil
+	}
+	return nexo.Continue(), nil
+}`,
+			wantRules: []string{
+				`HasPrefix(path, "/wp-admin") => response 404 "not found"`,
+				`HasPrefix(path, "/.env") => response 404 "not found"`,
+				`method == "TRACE" => response 405 "{\"error\":\"method not allowed\"}"`,
+				`method == "CONNECT" => response 405 "{\"error\":\"method not allowed\"}"`,
+				`continue`,
+			},
+		},
+		{
+			name: "unsupported constructs",
+			src: `
+var hits int
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	hits++
+	if time.Now().Hour() < 6 {
+		return nexo.Continue(), nil
+	}
+	if c.Path() == "/admin" {
+		return nil, checkAdmin(c)
+	}
+	return nexo.Continue(), nil
+}
+
+func checkAdmin(c *nexo.Context) error { return nil }`,
+			wantRules: []string{
+				`continue`,
+				`continue`,
+			},
+			wantIssues: []string{
+				"proxy.go:16: increments aren't supported at the edge",
+				"proxy.go:17: condition time.Now().Hour() < 6 isn't supported at the edge",
+				"proxy.go:21: returning an error isn't supported at the edge",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, issues, err := Compile("proxy.go", []byte(proxyHeader+tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range rules.Rules {
+				got = append(got, describe(r))
+			}
+			if !slices.Equal(got, tt.wantRules) {
+				t.Errorf("rules =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.wantRules, "\n"))
+			}
+
+			if len(issues) != len(tt.wantIssues) {
+				t.Fatalf("issues = %v, want %d", issues, len(tt.wantIssues))
+			}
+			for i, want := range tt.wantIssues {
+				if !strings.HasPrefix(issues[i].String(), want) {
+					t.Errorf("issue %d = %q, want prefix %q", i, issues[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileMatcher(t *testing.T) {
+	src := proxyHeader + `
+var ProxyConfig = &nexo.ProxyConfig{
+	Matcher: []string{"/api/:path*"},
+}
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	return nexo.Forward("https://legacy.example.com"), nil
+}`
+	rules, issues, err := Compile("proxy.go", []byte(src))
+	if err != nil || len(issues) > 0 {
+		t.Fatalf("Compile() = %v, %v", issues, err)
+	}
+	if want := []string{"^/api/.*(/.*)?$"}; !slices.Equal(rules.Matcher, want) {
+		t.Errorf("Matcher = %q, want %q", rules.Matcher, want)
+	}
+	if got := describe(rules.Rules[0]); got != "forward https://legacy.example.com" {
+		t.Errorf("rule = %q", got)
+	}
+
+	if _, _, err := Compile("proxy.go", []byte("package app\n")); err == nil {
+		t.Error("Compile() without a Proxy function succeeded")
+	}
+}
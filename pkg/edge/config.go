@@ -0,0 +1,80 @@
+package edge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CheckConfig reports the redirects and rewrites of r the edge can't run
+// like the app: invalid sources, destinations and conditions, and
+// condition values using Go regular expression syntax JavaScript lacks.
+// Issues are positioned like "nexo.yaml: redirects[0]".
+func CheckConfig(r *Rules) []Issue {
+	var issues []Issue
+	check := func(section string, rules []ConfigRule) {
+		for i, rule := range rules {
+			pos := fmt.Sprintf("nexo.yaml: %s[%d]", section, i)
+			for _, err := range checkConfigRule(section, rule) {
+				issues = append(issues, Issue{Pos: pos, Message: err.Error()})
+			}
+		}
+	}
+	check("redirects", r.Redirects)
+	check("rewrites", r.Rewrites)
+	return issues
+}
+
+// checkConfigRule returns what's wrong with a rule of section.
+func checkConfigRule(section string, rule ConfigRule) []error {
+	var errs []error
+	if err := checkSource(rule.Source); err != nil {
+		errs = append(errs, err)
+	}
+	switch {
+	case rule.Destination == "":
+		errs = append(errs, fmt.Errorf("source %q has no destination", rule.Source))
+	case section == "rewrites" && !strings.HasPrefix(rule.Destination, "/"):
+		errs = append(errs, fmt.Errorf("destination %q must be a path", rule.Destination))
+	}
+	for _, c := range append(rule.Has[:len(rule.Has):len(rule.Has)], rule.Missing...) {
+		switch c.Type {
+		case "header", "cookie", "query":
+			if c.Key == "" {
+				errs = append(errs, fmt.Errorf("%s condition has no key", c.Type))
+			}
+		case "host":
+		default:
+			errs = append(errs, fmt.Errorf("unknown condition type %q", c.Type))
+		}
+		if c.Value == "" {
+			continue
+		}
+		if _, err := regexp.Compile(c.Value); err != nil {
+			errs = append(errs, fmt.Errorf("condition value: %w", err))
+		} else if strings.Contains(c.Value, "(?") || strings.Contains(c.Value, `\z`) || strings.Contains(c.Value, `\A`) {
+			// Flags and named groups are written differently in JavaScript
+			errs = append(errs, fmt.Errorf("condition value %q uses syntax JavaScript regular expressions lack", c.Value))
+		}
+	}
+	return errs
+}
+
+// checkSource checks a source pattern as the app compiles it: a path where
+// * may only be the whole last segment.
+func checkSource(source string) error {
+	if !strings.HasPrefix(source, "/") {
+		return fmt.Errorf("source %q must start with /", source)
+	}
+	trimmed := strings.Trim(source, "/")
+	if rest, ok := strings.CutSuffix(trimmed, "*"); ok {
+		if rest != "" && !strings.HasSuffix(rest, "/") {
+			return fmt.Errorf("source %q: * must be a whole segment", source)
+		}
+		trimmed = rest
+	}
+	if strings.Contains(trimmed, "*") {
+		return fmt.Errorf("source %q: * is only allowed at the end", source)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package edge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		wantPos string
+		want    string
+	}{
+		{
+			name:  "valid",
+			rules: Rules{Redirects: []ConfigRule{{Source: "/blog/{slug}", Destination: "/posts/{slug}", Has: []ConfigCondition{{Type: "host", Value: `.*\.example\.com`}}}}},
+		},
+		{
+			name:    "rewrite to a URL",
+			rules:   Rules{Rewrites: []ConfigRule{{Source: "/docs", Destination: "https://docs.example.com"}}},
+			wantPos: "nexo.yaml: rewrites[0]",
+			want:    "must be a path",
+		},
+		{
+			name:    "source without a slash",
+			rules:   Rules{Redirects: []ConfigRule{{Source: "old", Destination: "/new"}}},
+			wantPos: "nexo.yaml: redirects[0]",
+			want:    "must start with /",
+		},
+		{
+			name:    "star in the middle",
+			rules:   Rules{Redirects: []ConfigRule{{Source: "/a/*/b", Destination: "/new"}}},
+			wantPos: "nexo.yaml: redirects[0]",
+			want:    "only allowed at the end",
+		},
+		{
+			name:    "header without a key",
+			rules:   Rules{Redirects: []ConfigRule{{Source: "/a", Destination: "/b", Missing: []ConfigCondition{{Type: "header"}}}}},
+			wantPos: "nexo.yaml: redirects[0]",
+			want:    "header condition has no key",
+		},
+		{
+			name:    "Go-only regexp",
+			rules:   Rules{Redirects: []ConfigRule{{Source: "/a", Destination: "/b", Has: []ConfigCondition{{Type: "query", Key: "v", Value: "(?i)beta"}}}}},
+			wantPos: "nexo.yaml: redirects[0]",
+			want:    "JavaScript",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckConfig(&tt.rules)
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("CheckConfig() = %v, want none", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Pos != tt.wantPos || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("CheckConfig() = %v, want %s: %s", issues, tt.wantPos, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package edge compiles the decision logic of an app's proxy.go into rules
+// that run at the edge, in front of the origin, so redirects, rewrites and
+// early responses don't need a round trip to the app.
+//
+// Compile statically analyzes the Proxy function. It understands the code
+// proxies are usually made of: conditions on the path, host, method,
+// headers, query parameters, cookies and client IP, string concatenation,
+// strings.HasPrefix, HasSuffix, Contains, TrimPrefix and TrimSuffix, local
+// variables, package-level constants, slices and map literals, and returns
+// of nexo.Continue, Redirect, Rewrite, Forward and the Response helpers.
+// Anything else, like calls into the app or state kept between requests,
+// is reported as an Issue with its position.
+//
+// The redirects and rewrites of nexo.yaml, which the app runs before the
+// proxy, can be added to the rules (see CheckConfig), so the edge runs them
+// too.
+//
+// The rules are exported as a Cloudflare Worker, as a _redirects file for
+// Cloudflare Pages and Netlify, or as JSON for other tooling.
+package edge
+
+import "fmt"
+
+// Rules is the compiled decision logic of a proxy.go. The first rule whose
+// conditions hold decides; requests no rule matches continue to the origin.
+type Rules struct {
+	// Redirects and Rewrites are the redirects and rewrites of nexo.yaml.
+	// They run before the proxy's rules, as in the app: the first matching
+	// redirect responds, then the first matching rewrite changes the path
+	// the rules see.
+	Redirects []ConfigRule `json:"redirects,omitempty"`
+	Rewrites  []ConfigRule `json:"rewrites,omitempty"`
+
+	// Matcher holds the regular expressions of the paths the proxy runs
+	// on, from ProxyConfig. The proxy runs on every path when it's empty.
+	Matcher []string `json:"matcher,omitempty"`
+	Rules   []Rule   `json:"rules"`
+}
+
+// ConfigRule is a redirect or rewrite of nexo.yaml. In Source, {name}
+// matches one path segment and a trailing * the rest of the path;
+// Destination uses the parameters and * of Source.
+type ConfigRule struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+
+	// Status is the redirect's status, 307 or 308. Rewrites have none.
+	Status int `json:"status,omitempty"`
+
+	// Has and Missing are conditions the request must and must not meet.
+	Has     []ConfigCondition `json:"has,omitempty"`
+	Missing []ConfigCondition `json:"missing,omitempty"`
+}
+
+// ConfigCondition is a has or missing condition of a ConfigRule: Type is
+// header, cookie, query or host, Key the name (hosts have none) and Value
+// a regular expression the whole value must match, or "" for any value.
+type ConfigCondition struct {
+	Type  string `json:"type"`
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Rule is one return statement of the Proxy function with the conditions
+// under which it's reached.
+type Rule struct {
+	When   []Condition `json:"when,omitempty"`
+	Action Action      `json:"action"`
+
+	// Headers are the response headers set with c.SetHeader before the
+	// return.
+	Headers []Header `json:"headers,omitempty"`
+
+	// Pos is the position of the return statement, like "proxy.go:12".
+	Pos string `json:"pos"`
+}
+
+// Request fields conditions and values read.
+const (
+	FieldPath     = "path"     // URL path
+	FieldHost     = "host"     // Host header, with the port
+	FieldMethod   = "method"   // request method
+	FieldURI      = "uri"      // path and query, as requested
+	FieldRawQuery = "rawquery" // query without the "?"
+	FieldScheme   = "scheme"   // "http" or "https"
+	FieldHeader   = "header"   // request header Name
+	FieldQuery    = "query"    // query parameter Name
+	FieldCookie   = "cookie"   // cookie Name
+	FieldIP       = "ip"       // client IP
+)
+
+// Condition operators.
+const (
+	OpEq       = "eq"
+	OpPrefix   = "prefix"
+	OpSuffix   = "suffix"
+	OpContains = "contains"
+)
+
+// Condition compares a request field to a value.
+type Condition struct {
+	Field  string `json:"field"`
+	Name   string `json:"name,omitempty"`
+	Op     string `json:"op"`
+	Value  string `json:"value"`
+	Negate bool   `json:"negate,omitempty"`
+}
+
+// String returns the condition as Go-like source, for messages.
+func (c Condition) String() string {
+	field := c.Field
+	if c.Name != "" {
+		field = fmt.Sprintf("%s(%q)", c.Field, c.Name)
+	}
+	not := ""
+	if c.Negate {
+		not = "!"
+	}
+	switch c.Op {
+	case OpEq:
+		if c.Negate {
+			return fmt.Sprintf("%s != %q", field, c.Value)
+		}
+		return fmt.Sprintf("%s == %q", field, c.Value)
+	case OpPrefix:
+		return fmt.Sprintf("%sHasPrefix(%s, %q)", not, field, c.Value)
+	case OpSuffix:
+		return fmt.Sprintf("%sHasSuffix(%s, %q)", not, field, c.Value)
+	default:
+		return fmt.Sprintf("%sContains(%s, %q)", not, field, c.Value)
+	}
+}
+
+// Action types.
+const (
+	ActionContinue = "continue"
+	ActionRedirect = "redirect"
+	ActionRewrite  = "rewrite"
+	ActionResponse = "response"
+	ActionForward  = "forward"
+)
+
+// Action is what a rule does, like nexo.Redirect.
+type Action struct {
+	Type string `json:"type"`
+
+	// URL is the target of a redirect, rewrite or forward.
+	URL []Part `json:"url,omitempty"`
+
+	Status      int    `json:"status,omitempty"`
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+
+	// Headers are added with WithHeader: to the response of redirects and
+	// responses, and to the upstream request of forwards.
+	Headers []Header `json:"headers,omitempty"`
+}
+
+// Header is a header set to a value.
+type Header struct {
+	Name  string `json:"name"`
+	Value []Part `json:"value"`
+}
+
+// Part is a piece of a string built at request time: a literal or a
+// request field, optionally with a prefix or suffix trimmed.
+type Part struct {
+	Literal    string `json:"literal,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Name       string `json:"name,omitempty"`
+	TrimPrefix string `json:"trim_prefix,omitempty"`
+	TrimSuffix string `json:"trim_suffix,omitempty"`
+}
+
+// Issue is a construct of the proxy the edge can't run.
+type Issue struct {
+	Pos     string `json:"pos"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return i.Pos + ": " + i.Message
+}
@@ -0,0 +1,428 @@
+package edge
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Export formats.
+const (
+	FormatWorker    = "worker"
+	FormatRedirects = "redirects"
+	FormatJSON      = "json"
+)
+
+// Formats lists the export formats with the file name they're written to
+// by default.
+var Formats = map[string]string{
+	FormatWorker:    "worker.js",
+	FormatRedirects: "_redirects",
+	FormatJSON:      "rules.json",
+}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Origin is the URL of the app the worker sends requests to. Empty
+	// means the origin of the route the worker runs on.
+	Origin string
+}
+
+// Export renders rules in format. Rules a format can't express are
+// returned as issues, with the output of the rest.
+func Export(r *Rules, format string, opts ExportOptions) ([]byte, []Issue, error) {
+	switch format {
+	case FormatWorker:
+		b, err := Worker(r, opts.Origin)
+		return b, nil, err
+	case FormatRedirects:
+		b, issues := Redirects(r)
+		return b, issues, nil
+	case FormatJSON:
+		b, err := json.MarshalIndent(r, "", "  ")
+		return append(b, '\n'), nil, err
+	}
+	return nil, nil, fmt.Errorf("unknown format %q (use worker, redirects or json)", format)
+}
+
+// ---------- Cloudflare Worker ----------
+
+// Worker renders rules as a Cloudflare Worker module that evaluates them
+// and passes the requests they continue on to origin. The redirects and
+// rewrites of nexo.yaml run first, as in the app.
+func Worker(r *Rules, origin string) ([]byte, error) {
+	if origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid origin %q: want a URL like https://app.example.com", origin)
+		}
+	}
+	matcher, err := json.Marshal(append([]string{}, r.Matcher...))
+	if err != nil {
+		return nil, err
+	}
+	rules, err := json.MarshalIndent(r.Rules, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	redirects, err := json.MarshalIndent(append([]ConfigRule{}, r.Redirects...), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	rewrites, err := json.MarshalIndent(append([]ConfigRule{}, r.Rewrites...), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = workerTemplate.Execute(&buf, map[string]string{
+		"Origin":    origin,
+		"Matcher":   string(matcher),
+		"Rules":     string(rules),
+		"Redirects": string(redirects),
+		"Rewrites":  string(rewrites),
+	})
+	return buf.Bytes(), err
+}
+
+var workerTemplate = template.Must(template.New("worker").Parse(`// Code generated by nexo edge export. DO NOT EDIT.
+//
+// Runs the redirects and rewrites of nexo.yaml and the rules of proxy.go at
+// the edge. Requests they continue on are sent to the origin, where the app
+// handles them.
+
+const ORIGIN = {{printf "%q" .Origin}};
+const REDIRECTS = {{.Redirects}};
+const REWRITES = {{.Rewrites}};
+const MATCHER = {{.Matcher}}.map((re) => new RegExp(re));
+const RULES = {{.Rules}};
+
+function cookie(request, key) {
+  for (const c of (request.headers.get("Cookie") ?? "").split(";")) {
+    const [k, ...v] = c.trim().split("=");
+    if (k === key) return v.join("=");
+  }
+  return null;
+}
+
+function field(request, url, name, key) {
+  switch (name) {
+    case "path":
+      return url.pathname;
+    case "host":
+      return url.host;
+    case "method":
+      return request.method;
+    case "uri":
+      return url.pathname + url.search;
+    case "rawquery":
+      return url.search.slice(1);
+    case "scheme":
+      return url.protocol.slice(0, -1);
+    case "header":
+      return request.headers.get(key) ?? "";
+    case "query":
+      return url.searchParams.get(key) ?? "";
+    case "cookie":
+      return cookie(request, key) ?? "";
+    case "ip":
+      return request.headers.get("CF-Connecting-IP") ?? "";
+  }
+  return "";
+}
+
+function holds(request, url, c) {
+  const v = field(request, url, c.field, c.name);
+  let ok;
+  switch (c.op) {
+    case "eq":
+      ok = v === c.value;
+      break;
+    case "prefix":
+      ok = v.startsWith(c.value);
+      break;
+    case "suffix":
+      ok = v.endsWith(c.value);
+      break;
+    default:
+      ok = v.includes(c.value);
+  }
+  return c.negate ? !ok : ok;
+}
+
+function render(request, url, parts) {
+  return (parts ?? [])
+    .map((p) => {
+      if (!p.field) return p.literal ?? "";
+      let v = field(request, url, p.field, p.name);
+      if (p.trim_prefix && v.startsWith(p.trim_prefix)) v = v.slice(p.trim_prefix.length);
+      if (p.trim_suffix && v.endsWith(p.trim_suffix)) v = v.slice(0, -p.trim_suffix.length);
+      return v;
+    })
+    .join("");
+}
+
+function setHeaders(request, url, headers, target) {
+  for (const h of headers ?? []) target.set(h.name, render(request, url, h.value));
+}
+
+// source returns the parameters of a nexo.yaml source matching the path,
+// with the rest matched by a trailing * as "*", or null.
+function source(pattern, path) {
+  const trim = (s) => s.replace(/^\/+|\/+$/g, "");
+  let p = trim(pattern);
+  const wildcard = p.endsWith("*");
+  if (wildcard) p = trim(p.slice(0, -1));
+  const want = p ? p.split("/") : [];
+  const segments = trim(path) ? trim(path).split("/") : [];
+  if (segments.length < want.length || (!wildcard && segments.length !== want.length)) return null;
+  const params = {};
+  for (let i = 0; i < want.length; i++) {
+    if (want[i].startsWith("{") && want[i].endsWith("}")) params[want[i].slice(1, -1)] = segments[i];
+    else if (want[i] !== segments[i]) return null;
+  }
+  if (wildcard) params["*"] = segments.slice(want.length).filter((s) => s !== "").join("/");
+  return params;
+}
+
+function meets(request, url, c) {
+  let v = null;
+  switch (c.type) {
+    case "header":
+      v = request.headers.get(c.key);
+      break;
+    case "cookie":
+      v = cookie(request, c.key);
+      break;
+    case "query":
+      v = url.searchParams.has(c.key) ? url.searchParams.get(c.key) : null;
+      break;
+    case "host":
+      v = url.hostname;
+      break;
+  }
+  return v !== null && (!c.value || new RegExp("^(?:" + c.value + ")$").test(v));
+}
+
+// route returns the rule of rules matching the request and its
+// destination, with the request's query, or null.
+function route(rules, request, url) {
+  for (const rule of rules) {
+    const params = source(rule.source, url.pathname);
+    if (!params) continue;
+    if (!(rule.has ?? []).every((c) => meets(request, url, c))) continue;
+    if ((rule.missing ?? []).some((c) => meets(request, url, c))) continue;
+    let to = rule.destination;
+    for (const [name, value] of Object.entries(params)) {
+      if (name !== "*") to = to.replaceAll("{" + name + "}", value);
+    }
+    if ("*" in params) {
+      if (params["*"] === "") to = to.replaceAll("/*", "");
+      to = to.replaceAll("*", params["*"]) || "/";
+    }
+    const local = rule.destination.startsWith("/");
+    if (local && (!to.startsWith("/") || to.startsWith("//") || to.startsWith("/\\"))) continue;
+    if (!url.search) return { rule, to };
+    const target = new URL(to, url);
+    const own = new Set(target.searchParams.keys());
+    for (const [key, value] of url.searchParams) {
+      if (!own.has(key)) target.searchParams.append(key, value);
+    }
+    return { rule, to: local ? target.pathname + target.search : target.href };
+  }
+  return null;
+}
+
+function toOrigin(request, url) {
+  const target = ORIGIN ? new URL(url.pathname + url.search, ORIGIN) : url;
+  return fetch(new Request(target, request));
+}
+
+async function apply(request, url, rule) {
+  const a = rule.action;
+  let response;
+  switch (a.type) {
+    case "redirect":
+      response = new Response(null, { status: a.status });
+      response.headers.set("Location", render(request, url, a.url));
+      setHeaders(request, url, a.headers, response.headers);
+      break;
+    case "response":
+      response = new Response(a.body ?? "", { status: a.status });
+      setHeaders(request, url, a.headers, response.headers);
+      if (a.content_type) response.headers.set("Content-Type", a.content_type);
+      break;
+    case "rewrite": {
+      const target = new URL(url);
+      target.pathname = render(request, url, a.url);
+      const res = await toOrigin(request, target);
+      response = new Response(res.body, res);
+      break;
+    }
+    case "forward": {
+      const target = new URL(render(request, url, a.url));
+      target.pathname = target.pathname.replace(/\/$/, "") + url.pathname;
+      target.search = url.search;
+      const upstream = new Request(target, request);
+      setHeaders(request, url, a.headers, upstream.headers);
+      const res = await fetch(upstream);
+      response = new Response(res.body, res);
+      break;
+    }
+    default: {
+      const res = await toOrigin(request, url);
+      response = new Response(res.body, res);
+    }
+  }
+  setHeaders(request, url, rule.headers, response.headers);
+  return response;
+}
+
+export default {
+  async fetch(request) {
+    let url = new URL(request.url);
+    const redirect = route(REDIRECTS, request, url);
+    if (redirect) {
+      return new Response(null, { status: redirect.rule.status, headers: { Location: redirect.to } });
+    }
+    const rewrite = route(REWRITES, request, url);
+    if (rewrite) url = new URL(rewrite.to, url);
+    if (MATCHER.length === 0 || MATCHER.some((re) => re.test(url.pathname))) {
+      for (const rule of RULES) {
+        if ((rule.when ?? []).every((c) => holds(request, url, c))) {
+          return apply(request, url, rule);
+        }
+      }
+    }
+    return toOrigin(request, url);
+  },
+};
+`))
+
+// ---------- _redirects ----------
+
+// Redirects renders rules as a _redirects file for Cloudflare Pages and
+// Netlify. It expresses the redirects and rewrites of nexo.yaml without
+// conditions, and redirects and rewrites on a path or path prefix; other
+// rules are returned as issues.
+func Redirects(r *Rules) ([]byte, []Issue) {
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by nexo edge export. DO NOT EDIT.\n")
+
+	var issues []Issue
+	config := func(section string, rules []ConfigRule) {
+		for i, rule := range rules {
+			line, err := configRedirectLine(rule)
+			if err != nil {
+				issues = append(issues, Issue{Pos: fmt.Sprintf("nexo.yaml: %s[%d]", section, i), Message: err.Error()})
+				continue
+			}
+			buf.WriteString(line + "\n")
+		}
+	}
+	config("redirects", r.Redirects)
+	config("rewrites", r.Rewrites)
+
+	if len(r.Matcher) > 0 {
+		issues = append(issues, Issue{Pos: "ProxyConfig", Message: "_redirects can't limit rules to the paths of a matcher"})
+	}
+
+	// Requests no rule matches are served as requested, so trailing
+	// continues aren't needed
+	rules := r.Rules
+	for len(rules) > 0 && rules[len(rules)-1].Action.Type == ActionContinue && len(rules[len(rules)-1].Headers) == 0 {
+		rules = rules[:len(rules)-1]
+	}
+
+	for _, rule := range rules {
+		line, err := redirectLine(rule)
+		if err != nil {
+			issues = append(issues, Issue{Pos: rule.Pos, Message: err.Error()})
+			continue
+		}
+		buf.WriteString(line + "\n")
+	}
+	return buf.Bytes(), issues
+}
+
+// redirectLine returns the _redirects line of a rule.
+func redirectLine(rule Rule) (string, error) {
+	if len(rule.Headers) > 0 || len(rule.Action.Headers) > 0 {
+		return "", fmt.Errorf("_redirects can't set headers")
+	}
+
+	// The source: every path, a path or a path prefix
+	from, prefix := "/*", "/"
+	switch {
+	case len(rule.When) == 0:
+	case len(rule.When) == 1 && rule.When[0].Field == FieldPath && !rule.When[0].Negate && rule.When[0].Op == OpEq:
+		from, prefix = rule.When[0].Value, ""
+	case len(rule.When) == 1 && rule.When[0].Field == FieldPath && !rule.When[0].Negate && rule.When[0].Op == OpPrefix && strings.HasSuffix(rule.When[0].Value, "/"):
+		from, prefix = rule.When[0].Value+"*", rule.When[0].Value
+	default:
+		conds := make([]string, len(rule.When))
+		for i, c := range rule.When {
+			conds[i] = c.String()
+		}
+		return "", fmt.Errorf("_redirects can only match a path or a path prefix ending in /, not %s", strings.Join(conds, " && "))
+	}
+
+	var to string
+	status := rule.Action.Status
+	switch rule.Action.Type {
+	case ActionContinue:
+		// A rewrite to itself stops at the rule
+		to, status = from, 200
+		if prefix != "" {
+			to = prefix + ":splat"
+		}
+	case ActionRedirect, ActionRewrite:
+		var b strings.Builder
+		for _, p := range rule.Action.URL {
+			switch {
+			case p.Field == "":
+				b.WriteString(p.Literal)
+			case p.Field == FieldPath && prefix != "" && p.TrimPrefix == prefix && p.TrimSuffix == "":
+				b.WriteString(":splat")
+			case p.Field == FieldPath && prefix != "" && p.TrimPrefix == "" && p.TrimSuffix == "":
+				b.WriteString(prefix + ":splat")
+			case p.Field == FieldPath && prefix == "" && p.TrimPrefix == "" && p.TrimSuffix == "":
+				b.WriteString(from)
+			default:
+				return "", fmt.Errorf("_redirects can only build targets from literals and the matched path")
+			}
+		}
+		to = b.String()
+		if rule.Action.Type == ActionRewrite {
+			status = 200
+		}
+	default:
+		return "", fmt.Errorf("_redirects can't %s", map[string]string{
+			ActionResponse: "send responses",
+			ActionForward:  "forward to other origins",
+		}[rule.Action.Type])
+	}
+	return fmt.Sprintf("%s %s %d", from, to, status), nil
+}
+
+// configRedirectLine returns the _redirects line of a redirect or rewrite
+// of nexo.yaml: {name} parameters become :name and * becomes :splat.
+// Rewrites, without a status, are served with 200.
+func configRedirectLine(rule ConfigRule) (string, error) {
+	if len(rule.Has) > 0 || len(rule.Missing) > 0 {
+		return "", fmt.Errorf("_redirects can't check has and missing conditions")
+	}
+	placeholders := func(s, splat string) string {
+		s = configParamRe.ReplaceAllString(s, ":$1")
+		return strings.ReplaceAll(s, "*", splat)
+	}
+	return fmt.Sprintf("%s %s %d", placeholders(rule.Source, "*"), placeholders(rule.Destination, ":splat"), cmp.Or(rule.Status, 200)), nil
+}
+
+// configParamRe matches the {name} parameters of nexo.yaml rules.
+var configParamRe = regexp.MustCompile(`\{(\w+)\}`)
@@ -0,0 +1,113 @@
+package edge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	src := proxyHeader + `
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	if c.Path() == "/old" {
+		return nexo.Redirect("/new", 301), nil
+	}
+	if strings.HasPrefix(c.Path(), "/static/") {
+		return nexo.Continue(), nil
+	}
+	if strings.HasPrefix(c.Path(), "/blog/") {
+		return nexo.Redirect("https://blog.example.com/"+strings.TrimPrefix(c.Path(), "/blog/"), 302), nil
+	}
+	if strings.HasPrefix(c.Path(), "/v1/") {
+		return nexo.Rewrite("/api" + c.Path()), nil
+	}
+	if c.Header("X-Debug") != "" {
+		return nexo.ResponseHTML(403, "no"), nil
+	}
+	return nexo.Continue(), nil
+}`
+	rules, issues, err := Compile("proxy.go", []byte(src))
+	if err != nil || len(issues) > 0 {
+		t.Fatalf("Compile() = %v, %v", issues, err)
+	}
+
+	out, issues := Redirects(rules)
+	want := `# Generated by nexo edge export. DO NOT EDIT.
+/old /new 301
+/static/* /static/:splat 200
+/blog/* https://blog.example.com/:splat 302
+/v1/* /api/v1/:splat 200
+`
+	if string(out) != want {
+		t.Errorf("Redirects() =\n%s\nwant\n%s", out, want)
+	}
+	if len(issues) != 1 || issues[0].Pos != "proxy.go:27" || !strings.Contains(issues[0].Message, `header("X-Debug") != ""`) {
+		t.Errorf("issues = %v", issues)
+	}
+}
+
+func TestRedirects_Config(t *testing.T) {
+	rules := &Rules{
+		Rules: []Rule{},
+		Redirects: []ConfigRule{
+			{Source: "/blog/{slug}", Destination: "/posts/{slug}", Status: 308},
+			{Source: "/docs/v1/*", Destination: "https://v1.docs.example.com/*", Status: 307},
+			{Source: "/beta", Destination: "/new", Status: 307, Has: []ConfigCondition{{Type: "cookie", Key: "beta"}}},
+		},
+		Rewrites: []ConfigRule{{Source: "/about", Destination: "/pages/about"}},
+	}
+
+	out, issues := Redirects(rules)
+	want := `# Generated by nexo edge export. DO NOT EDIT.
+/blog/:slug /posts/:slug 308
+/docs/v1/* https://v1.docs.example.com/:splat 307
+/about /pages/about 200
+`
+	if string(out) != want {
+		t.Errorf("Redirects() =\n%s\nwant\n%s", out, want)
+	}
+	if len(issues) != 1 || issues[0].Pos != "nexo.yaml: redirects[2]" {
+		t.Errorf("issues = %v", issues)
+	}
+}
+
+func TestWorker(t *testing.T) {
+	rules := &Rules{Rules: []Rule{{
+		When:   []Condition{{Field: FieldPath, Op: OpEq, Value: "/old"}},
+		Action: Action{Type: ActionRedirect, Status: 301, URL: []Part{{Literal: "/new"}}},
+		Pos:    "proxy.go:5",
+	}}}
+
+	out, err := Worker(rules, "https://app.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	js := string(out)
+	for _, want := range []string{
+		`const ORIGIN = "https://app.example.com";`,
+		`const MATCHER = [].map(`,
+		`"value": "/old"`,
+		"export default {",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("worker doesn't contain %q:\n%s", want, js)
+		}
+	}
+
+	// The embedded rules are the JSON export
+	start := strings.Index(js, "const RULES = ") + len("const RULES = ")
+	end := strings.Index(js[start:], ";\n")
+	var got []Rule
+	if err := json.Unmarshal([]byte(js[start:start+end]), &got); err != nil || len(got) != 1 || got[0].Action.Status != 301 {
+		t.Errorf("embedded rules = %v, %v", got, err)
+	}
+
+	rules.Redirects = []ConfigRule{{Source: "/blog/{slug}", Destination: "/posts/{slug}", Status: 308}}
+	if out, err = Worker(rules, ""); err != nil || !strings.Contains(string(out), `"destination": "/posts/{slug}"`) {
+		t.Errorf("worker without the nexo.yaml redirects: %v\n%s", err, out)
+	}
+
+	if _, err := Worker(rules, "app.example.com"); err == nil {
+		t.Error("Worker() accepted an origin without a scheme")
+	}
+}
@@ -0,0 +1,349 @@
+// Package env loads environment variables from .env files and keeps the
+// secrets in them encrypted at rest.
+//
+// Load reads the files of a mode, lowest precedence first:
+//
+//	.env                  shared defaults, committed
+//	.env.<mode>           mode defaults, committed
+//	.env.local            local overrides, ignored by git (skipped in test)
+//	.env.<mode>.local     local mode overrides, ignored by git
+//
+// Variables already set in the process environment always win.
+//
+// Values can be encrypted in place, so a .env file with secrets can be
+// committed:
+//
+//	DATABASE_URL=enc:v1:9mJ0c2Vj...
+//
+// Encrypted values are decrypted with the key in NEXO_ENV_KEY or the
+// .env.key file, which must never be committed.
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mode returns the environment the app runs in: NEXO_ENV, then GO_ENV,
+// then "development".
+func Mode() string {
+	if mode := os.Getenv("NEXO_ENV"); mode != "" {
+		return mode
+	}
+	if mode := os.Getenv("GO_ENV"); mode != "" {
+		return mode
+	}
+	return "development"
+}
+
+// Files returns the .env files of mode, lowest precedence first.
+func Files(mode string) []string {
+	files := []string{".env"}
+	if mode != "" {
+		files = append(files, ".env."+mode)
+	}
+	if mode != "test" {
+		files = append(files, ".env.local")
+	}
+	if mode != "" {
+		files = append(files, ".env."+mode+".local")
+	}
+	return files
+}
+
+// Read merges the .env files of mode in dir, later files overriding
+// earlier ones, and decrypts encrypted values. Missing files are skipped.
+// It returns the files it read.
+func Read(dir, mode string) (map[string]string, []string, error) {
+	vars := make(map[string]string)
+	var read []string
+	for _, name := range Files(mode) {
+		path := filepath.Join(dir, name)
+		fileVars, err := ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, read, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+		read = append(read, path)
+	}
+
+	if HasEncrypted(vars) {
+		key, err := LoadKey(dir)
+		if err != nil {
+			return nil, read, err
+		}
+		if err := DecryptValues(key, vars); err != nil {
+			return nil, read, err
+		}
+	}
+	return vars, read, nil
+}
+
+// Load reads the .env files of mode in dir and sets the variables that are
+// not already set in the process environment. It returns the files read.
+func Load(dir, mode string) ([]string, error) {
+	vars, read, err := Read(dir, mode)
+	if err != nil {
+		return read, err
+	}
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+// ReadFile parses the .env file name.
+func ReadFile(name string) (map[string]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return vars, nil
+}
+
+// Parse parses KEY=value lines. Blank lines and # comments are skipped, an
+// "export " prefix is allowed, and values may be single- or double-quoted;
+// double-quoted values support \n, \t, \" and \\ escapes. Unquoted values
+// end at " #".
+func Parse(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// parseValue unquotes a value.
+func parseValue(v string) (string, error) {
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return v[1 : len(v)-1], nil
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		var b strings.Builder
+		inner := v[1 : len(v)-1]
+		for i := 0; i < len(inner); i++ {
+			c := inner[i]
+			if c != '\\' || i == len(inner)-1 {
+				b.WriteByte(c)
+				continue
+			}
+			i++
+			switch inner[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(inner[i])
+			}
+		}
+		return b.String(), nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v, nil
+}
+
+// Rewrite calls fn for each variable of the .env file data and replaces
+// the values it changes, keeping comments, blank lines and order.
+func Rewrite(data []byte, fn func(key, value string) (string, error)) ([]byte, error) {
+	var out bytes.Buffer
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		prefix := ""
+		if strings.HasPrefix(line, "export ") {
+			prefix = "export "
+			line = strings.TrimPrefix(line, prefix)
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.HasPrefix(key, "#") {
+			out.WriteString(raw)
+			continue
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		updated, err := fn(key, value)
+		if err != nil {
+			return nil, err
+		}
+		if updated == value {
+			out.WriteString(raw)
+			continue
+		}
+		out.WriteString(prefix + key + "=" + formatValue(updated))
+		if strings.HasSuffix(raw, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// Write writes vars as a .env file, sorted by key. Values are quoted when
+// needed.
+func Write(w io.Writer, vars map[string]string) error {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", k, formatValue(vars[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes vars to the .env file name, readable only by its owner.
+func WriteFile(name string, vars map[string]string) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, vars); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0600)
+}
+
+// formatValue quotes v if Parse would not read it back unchanged.
+func formatValue(v string) string {
+	if v == "" || !strings.ContainsAny(v, " \t\r\n#\"'\\") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(v) + `"`
+}
+
+// Env reads typed configuration from environment variables.
+type Env struct {
+	lookup func(string) (string, bool)
+}
+
+// New returns an Env reading the process environment.
+func New() *Env {
+	return &Env{lookup: os.LookupEnv}
+}
+
+// FromMap returns an Env reading vars, for tests.
+func FromMap(vars map[string]string) *Env {
+	return &Env{lookup: func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}}
+}
+
+// Lookup returns the variable key and whether it is set.
+func (e *Env) Lookup(key string) (string, bool) {
+	return e.lookup(key)
+}
+
+// String returns the variable key, or def when it is unset or empty.
+func (e *Env) String(key, def string) string {
+	if v, ok := e.lookup(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Int returns the variable key as an int, or def when it is unset or not a
+// number.
+func (e *Env) Int(key string, def int) int {
+	if n, err := strconv.Atoi(e.String(key, "")); err == nil {
+		return n
+	}
+	return def
+}
+
+// Bool returns the variable key as a bool (1, true, yes, on), or def when
+// it is unset or not a bool.
+func (e *Env) Bool(key string, def bool) bool {
+	switch strings.ToLower(e.String(key, "")) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+	return def
+}
+
+// Duration returns the variable key as a time.Duration, e.g. "30s", or def
+// when it is unset or invalid.
+func (e *Env) Duration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(e.String(key, "")); err == nil {
+		return d
+	}
+	return def
+}
+
+// List returns the variable key split on commas, or nil when it is unset.
+func (e *Env) List(key string) []string {
+	var items []string
+	for _, item := range strings.Split(e.String(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Require returns an error naming the keys that are unset or empty.
+func (e *Env) Require(keys ...string) error {
+	var missing []string
+	for _, k := range keys {
+		if e.String(k, "") == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package env
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := `# comment
+FOO=bar
+export EXPORTED=yes
+SPACED = padded
+DOUBLE="a \"quoted\"\nline"
+SINGLE='raw \n value'
+INLINE=value # comment
+HASH=abc#def
+EQUALS=a=b=c
+EMPTY=
+INVALID_LINE
+`
+	got, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"FOO":      "bar",
+		"EXPORTED": "yes",
+		"SPACED":   "padded",
+		"DOUBLE":   "a \"quoted\"\nline",
+		"SINGLE":   `raw \n value`,
+		"INLINE":   "value",
+		"HASH":     "abc#def",
+		"EQUALS":   "a=b=c",
+		"EMPTY":    "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Parse() = %v, want %d keys", got, len(want))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":   "value",
+		"SPACES":  "two words",
+		"NEWLINE": "a\nb",
+		"QUOTES":  `say "hi"`,
+		"HASH":    "a #b",
+		"EMPTY":   "",
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, vars); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "EMPTY=\nHASH=") {
+		t.Errorf("Write() not sorted:\n%s", buf.String())
+	}
+
+	got, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range vars {
+		if got[k] != v {
+			t.Errorf("%s = %q after round trip, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"development", ".env .env.development .env.local .env.development.local"},
+		{"test", ".env .env.test .env.test.local"},
+		{"", ".env .env.local"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(Files(tt.mode), " "); got != tt.want {
+			t.Errorf("Files(%q) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":                   "A=env\nB=env\nC=env\nD=env\nSET=env\n",
+		".env.development":       "B=mode\nC=mode\nD=mode\n",
+		".env.local":             "C=local\nD=local\n",
+		".env.development.local": "D=mode-local\n",
+		".env.production":        "A=production\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, k := range []string{"A", "B", "C", "D"} {
+		t.Setenv(k, "")
+		_ = os.Unsetenv(k)
+	}
+	t.Setenv("SET", "process")
+
+	read, err := Load(dir, "development")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(read) != 4 {
+		t.Errorf("Load() read %v, want 4 files", read)
+	}
+
+	want := map[string]string{"A": "env", "B": "mode", "C": "local", "D": "mode-local", "SET": "process"}
+	for k, v := range want {
+		if got := os.Getenv(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestReadDecrypts(t *testing.T) {
+	dir := t.TempDir()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := Encrypt(key, "TOKEN", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("TOKEN="+secret+"\nPLAIN=x\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(KeyEnv, "")
+
+	if _, _, err := Read(dir, "test"); err != ErrNoKey {
+		t.Errorf("Read() without key error = %v, want ErrNoKey", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, KeyFile), []byte(key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	vars, _, err := Read(dir, "test")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if vars["TOKEN"] != "s3cret" || vars["PLAIN"] != "x" {
+		t.Errorf("Read() = %v", vars)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	key, _ := GenerateKey()
+	other, _ := GenerateKey()
+
+	enc, err := Encrypt(key, "API_KEY", "value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "value") {
+		t.Errorf("Encrypt() = %q", enc)
+	}
+	if again, _ := Encrypt(key, "API_KEY", "value"); again == enc {
+		t.Error("Encrypt() is deterministic, want a random nonce")
+	}
+
+	if got, err := Decrypt(key, "API_KEY", enc); err != nil || got != "value" {
+		t.Errorf("Decrypt() = %q, %v", got, err)
+	}
+	if _, err := Decrypt(other, "API_KEY", enc); err == nil {
+		t.Error("Decrypt() with the wrong key succeeded")
+	}
+	if _, err := Decrypt(key, "OTHER_KEY", enc); err == nil {
+		t.Error("Decrypt() of a value moved to another variable succeeded")
+	}
+	if got, _ := Decrypt(key, "PLAIN", "plain"); got != "plain" {
+		t.Errorf("Decrypt() of a plain value = %q", got)
+	}
+	if _, err := Encrypt("short", "A", "b"); err == nil {
+		t.Error("Encrypt() with an invalid key succeeded")
+	}
+}
+
+func TestEncryptValues(t *testing.T) {
+	key, _ := GenerateKey()
+	vars := map[string]string{"A": "1", "B": "2"}
+	if err := EncryptValues(key, vars, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(vars["A"]) || vars["B"] != "2" {
+		t.Errorf("EncryptValues() = %v", vars)
+	}
+	if err := DecryptValues(key, vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars["A"] != "1" {
+		t.Errorf("DecryptValues() = %v", vars)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	data := "# comment\nexport A=1\n\nB=\"two words\" \nC=3"
+	got, err := Rewrite([]byte(data), func(key, value string) (string, error) {
+		if key == "C" {
+			return value, nil
+		}
+		return value + " x", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# comment\nexport A=\"1 x\"\n\nB=\"two words x\"\nC=3"
+	if string(got) != want {
+		t.Errorf("Rewrite() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	e := FromMap(map[string]string{
+		"NAME":    "shop",
+		"EMPTY":   "",
+		"PORT":    "8080",
+		"BAD":     "x",
+		"DEBUG":   "yes",
+		"TIMEOUT": "30s",
+		"HOSTS":   "a.com, b.com,,",
+	})
+
+	if got := e.String("NAME", "def"); got != "shop" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := e.String("EMPTY", "def"); got != "def" {
+		t.Errorf("String(EMPTY) = %q, want default", got)
+	}
+	if got := e.Int("PORT", 3000); got != 8080 {
+		t.Errorf("Int() = %d", got)
+	}
+	if got := e.Int("BAD", 3000); got != 3000 {
+		t.Errorf("Int(BAD) = %d, want default", got)
+	}
+	if !e.Bool("DEBUG", false) || e.Bool("BAD", false) {
+		t.Error("Bool() mismatch")
+	}
+	if got := e.Duration("TIMEOUT", time.Second); got != 30*time.Second {
+		t.Errorf("Duration() = %v", got)
+	}
+	if got := e.List("HOSTS"); len(got) != 2 || got[1] != "b.com" {
+		t.Errorf("List() = %v", got)
+	}
+	if e.List("MISSING") != nil {
+		t.Error("List(MISSING) should be nil")
+	}
+
+	err := e.Require("NAME", "EMPTY", "MISSING")
+	if err == nil || !strings.Contains(err.Error(), "EMPTY, MISSING") {
+		t.Errorf("Require() error = %v", err)
+	}
+}
+
+func TestMode(t *testing.T) {
+	t.Setenv("NEXO_ENV", "")
+	t.Setenv("GO_ENV", "")
+	if got := Mode(); got != "development" {
+		t.Errorf("Mode() = %q, want development", got)
+	}
+	t.Setenv("GO_ENV", "production")
+	if got := Mode(); got != "production" {
+		t.Errorf("Mode() = %q, want production", got)
+	}
+	t.Setenv("NEXO_ENV", "staging")
+	if got := Mode(); got != "staging" {
+		t.Errorf("Mode() = %q, want staging", got)
+	}
+}
@@ -0,0 +1,149 @@
+package env
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// KeyFile is the file, next to the .env files, holding the key that
+	// decrypts their secrets. Keep it out of version control.
+	KeyFile = ".env.key"
+
+	// KeyEnv is the variable holding the key, which takes precedence over
+	// KeyFile. Set it in CI and on servers.
+	KeyEnv = "NEXO_ENV_KEY"
+
+	// encryptedPrefix marks an encrypted value.
+	encryptedPrefix = "enc:v1:"
+)
+
+// ErrNoKey is returned when encrypted values are found but no key is set.
+var ErrNoKey = errors.New("encrypted values found but no key: set " + KeyEnv + " or create " + KeyFile + " with nexo env keygen")
+
+// GenerateKey returns a new random key, base64-encoded.
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadKey returns the key from NEXO_ENV_KEY, or from the .env.key file in
+// dir.
+func LoadKey(dir string) (string, error) {
+	if key := strings.TrimSpace(os.Getenv(KeyEnv)); key != "" {
+		return key, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, KeyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoKey
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// HasEncrypted reports whether any value of vars is encrypted.
+func HasEncrypted(vars map[string]string) bool {
+	for _, v := range vars {
+		if IsEncrypted(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Encrypt encrypts the value of the variable name with AES-256-GCM. The
+// name is authenticated, so an encrypted value cannot be moved to another
+// variable.
+func Encrypt(key, name, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt for the variable name.
+// Values that are not encrypted are returned unchanged.
+func Decrypt(key, name, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%s: malformed encrypted value", name)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("%s: cannot decrypt value: wrong key or corrupted value", name)
+	}
+	return string(plain), nil
+}
+
+// EncryptValues encrypts the values of vars in place, skipping values that
+// are already encrypted. With keys, only those variables are encrypted.
+func EncryptValues(key string, vars map[string]string, keys ...string) error {
+	for name, v := range vars {
+		if IsEncrypted(v) || (len(keys) > 0 && !slices.Contains(keys, name)) {
+			continue
+		}
+		enc, err := Encrypt(key, name, v)
+		if err != nil {
+			return err
+		}
+		vars[name] = enc
+	}
+	return nil
+}
+
+// DecryptValues decrypts the encrypted values of vars in place.
+func DecryptValues(key string, vars map[string]string) error {
+	for name, v := range vars {
+		plain, err := Decrypt(key, name, v)
+		if err != nil {
+			return err
+		}
+		vars[name] = plain
+	}
+	return nil
+}
+
+// newAEAD creates the cipher for a base64-encoded 32-byte key.
+func newAEAD(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("invalid key: want 32 base64-encoded bytes, as made by nexo env keygen")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
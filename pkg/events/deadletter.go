@@ -0,0 +1,184 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadLetterStore keeps deliveries that ran out of attempts.
+type DeadLetterStore interface {
+	// Put stores d, replacing a delivery with the same ID.
+	Put(ctx context.Context, d Delivery) error
+
+	// List returns the stored deliveries, oldest event first.
+	List(ctx context.Context) ([]Delivery, error)
+
+	// Remove deletes the delivery with the given ID.
+	Remove(ctx context.Context, id string) error
+}
+
+// ErrNotFound is returned for dead letters that don't exist.
+var ErrNotFound = errors.New("events: dead letter not found")
+
+// DeadLetters returns the bus's dead-letter store.
+func (b *Bus) DeadLetters() DeadLetterStore {
+	return b.config.DeadLetters
+}
+
+// Redeliver takes the dead letter with the given ID out of the store and
+// queues it again with fresh attempts, e.g. after the subscriber was
+// fixed.
+func (b *Bus) Redeliver(ctx context.Context, id string) error {
+	letters, err := b.config.DeadLetters.List(ctx)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(letters, func(d Delivery) bool { return d.ID == id })
+	if i < 0 {
+		return ErrNotFound
+	}
+	d := letters[i]
+	d.Attempt, d.LastError, d.NextAttempt = 0, "", time.Now()
+	if err := b.queue.Enqueue(context.WithoutCancel(ctx), d); err != nil {
+		return err
+	}
+	return b.config.DeadLetters.Remove(ctx, id)
+}
+
+// MemoryDeadLetters keeps dead letters in memory, losing them on restart.
+type MemoryDeadLetters struct {
+	mu      sync.Mutex
+	letters map[string]Delivery
+}
+
+// NewMemoryDeadLetters creates an empty MemoryDeadLetters.
+func NewMemoryDeadLetters() *MemoryDeadLetters {
+	return &MemoryDeadLetters{letters: make(map[string]Delivery)}
+}
+
+// Put implements DeadLetterStore.
+func (s *MemoryDeadLetters) Put(_ context.Context, d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[d.ID] = d
+	return nil
+}
+
+// List implements DeadLetterStore.
+func (s *MemoryDeadLetters) List(context.Context) ([]Delivery, error) {
+	s.mu.Lock()
+	letters := make([]Delivery, 0, len(s.letters))
+	for _, d := range s.letters {
+		letters = append(letters, d)
+	}
+	s.mu.Unlock()
+	sortDeliveries(letters)
+	return letters, nil
+}
+
+// Remove implements DeadLetterStore.
+func (s *MemoryDeadLetters) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.letters[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.letters, id)
+	return nil
+}
+
+// FileDeadLetters keeps dead letters as JSON files in a directory, one per
+// delivery, so they survive restarts.
+type FileDeadLetters struct {
+	dir string
+}
+
+// NewFileDeadLetters stores dead letters in dir, creating it if needed.
+func NewFileDeadLetters(dir string) (*FileDeadLetters, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileDeadLetters{dir: dir}, nil
+}
+
+// Put implements DeadLetterStore.
+func (s *FileDeadLetters) Put(_ context.Context, d Delivery) error {
+	path, err := s.path(d.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// List implements DeadLetterStore.
+func (s *FileDeadLetters) List(context.Context) ([]Delivery, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]Delivery, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		letters = append(letters, d)
+	}
+	sortDeliveries(letters)
+	return letters, nil
+}
+
+// Remove implements DeadLetterStore.
+func (s *FileDeadLetters) Remove(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// path returns the file of the delivery with the given ID.
+func (s *FileDeadLetters) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("events: invalid delivery ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// sortDeliveries orders deliveries by event time, then ID.
+func sortDeliveries(letters []Delivery) {
+	slices.SortFunc(letters, func(a, b Delivery) int {
+		if c := a.Event.Time.Compare(b.Event.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
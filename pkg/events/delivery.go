@@ -0,0 +1,204 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Delivery is an event on its way to one subscriber.
+type Delivery struct {
+	ID          string    `json:"id"`
+	Event       Event     `json:"event"`
+	Subscriber  string    `json:"subscriber"`
+	Attempt     int       `json:"attempt"` // attempts made so far
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Queue runs deliveries when they are due by calling Bus.Deliver. The
+// default queue runs them in the app's process; a Queue backed by a job
+// system keeps them across restarts:
+//
+//	type jobQueue struct{}
+//
+//	func (jobQueue) Enqueue(ctx context.Context, d events.Delivery) error {
+//	    return jobs.EnqueueAt(ctx, "deliver-event", d, d.NextAttempt)
+//	}
+//
+//	// The "deliver-event" job makes one attempt. Deliver queues retries
+//	// itself, so the job mustn't retry.
+//	func deliverEvent(ctx context.Context, d events.Delivery) error {
+//	    bus.Deliver(ctx, d)
+//	    return nil
+//	}
+type Queue interface {
+	// Enqueue schedules d to be delivered at d.NextAttempt.
+	Enqueue(ctx context.Context, d Delivery) error
+}
+
+// Deliver makes one attempt at d. When it fails, d is queued again after
+// Config.Backoff or, once Config.MaxAttempts is reached, dead-lettered.
+// The returned error is the attempt's; retrying is already taken care of.
+func (b *Bus) Deliver(ctx context.Context, d Delivery) error {
+	d.Attempt++
+	err := b.attempt(ctx, d)
+	if err == nil {
+		return nil
+	}
+	d.LastError = err.Error()
+
+	if d.Attempt < b.config.MaxAttempts {
+		d.NextAttempt = time.Now().Add(b.config.Backoff(d.Attempt))
+		if qerr := b.queue.Enqueue(ctx, d); qerr == nil {
+			return err
+		}
+	}
+	if derr := b.config.DeadLetters.Put(ctx, d); derr != nil {
+		log.Printf("events: dead-lettering %s: %v", d.ID, derr)
+	}
+	return err
+}
+
+// attempt delivers d's event to its subscriber once.
+func (b *Bus) attempt(ctx context.Context, d Delivery) (err error) {
+	s, ok := b.subscriber(d.Subscriber)
+	if !ok {
+		return fmt.Errorf("unknown subscriber %s", d.Subscriber)
+	}
+	if s.Handler != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return s.Handler(ctx, d.Event)
+	}
+	return b.post(ctx, s, d.Event)
+}
+
+// post sends event to a URL subscriber. Responses other than 2xx are
+// errors.
+func (b *Bus) post(ctx context.Context, s *Subscriber, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nexo-events")
+
+	// Standard Webhooks headers; webhook-id stays the same across retries
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Webhook-Id", event.ID)
+	req.Header.Set("Webhook-Timestamp", timestamp)
+	if s.key != nil {
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write([]byte(event.ID + "." + timestamp + "."))
+		mac.Write(body)
+		req.Header.Set("Webhook-Signature", "v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := b.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+// memoryQueue is the default Queue: it runs deliveries in goroutines of
+// the app's process when they are due.
+type memoryQueue struct {
+	bus *Bus
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	pending map[string]*pendingDelivery
+}
+
+// pendingDelivery is a delivery waiting for its timer.
+type pendingDelivery struct {
+	d     Delivery
+	timer *time.Timer
+}
+
+func newMemoryQueue(bus *Bus, workers int) *memoryQueue {
+	return &memoryQueue{bus: bus, sem: make(chan struct{}, workers), pending: make(map[string]*pendingDelivery)}
+}
+
+// Enqueue implements Queue.
+func (q *memoryQueue) Enqueue(ctx context.Context, d Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	p := &pendingDelivery{d: d}
+	q.pending[d.ID] = p
+	q.wg.Add(1)
+	p.timer = time.AfterFunc(time.Until(d.NextAttempt), func() {
+		defer q.wg.Done()
+		q.mu.Lock()
+		delete(q.pending, d.ID)
+		q.mu.Unlock()
+
+		q.sem <- struct{}{}
+		defer func() { <-q.sem }()
+		_ = q.bus.Deliver(ctx, d)
+	})
+	return nil
+}
+
+// close stops accepting deliveries and returns those still waiting.
+func (q *memoryQueue) close() []Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	var waiting []Delivery
+	for id, p := range q.pending {
+		if p.timer.Stop() {
+			q.wg.Done()
+			waiting = append(waiting, p.d)
+		}
+		delete(q.pending, id)
+	}
+	return waiting
+}
+
+// wait waits for running deliveries until ctx is done.
+func (q *memoryQueue) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,285 @@
+// Package events delivers application events to subscribers: webhook URLs
+// of other services and handlers inside the app.
+//
+// Events are emitted once and delivered to every matching subscriber at
+// least once. Failed deliveries are retried with exponential backoff and,
+// once their attempts run out, kept in a dead-letter store to be inspected
+// and redelivered.
+//
+//	bus := events.New(events.Config{})
+//	bus.Subscribe(events.Subscriber{
+//	    Name:   "billing",
+//	    URL:    "https://billing.internal/hooks",
+//	    Events: []string{"user.*"},
+//	    Secret: os.Getenv("BILLING_HOOK_SECRET"),
+//	})
+//	bus.On("user.created", sendWelcomeEmail)
+//
+//	bus.Emit(ctx, "user.created", user)
+package events
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is something that happened in the app, like "user.created".
+type Event struct {
+	// ID identifies the event. Deliveries of one event share it, so
+	// subscribers can skip redeliveries.
+	ID string `json:"id"`
+
+	// Type names the event, like "user.created".
+	Type string `json:"type"`
+
+	// Time is when the event was emitted.
+	Time time.Time `json:"timestamp"`
+
+	// Data is the JSON payload.
+	Data json.RawMessage `json:"data"`
+}
+
+// Handler handles an event inside the app. A returned error retries the
+// delivery later.
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber receives events, either posted as JSON to URL or passed to
+// Handler.
+type Subscriber struct {
+	// Name identifies the subscriber in deliveries and dead letters.
+	// Default is URL.
+	Name string
+
+	// Events are the event types delivered, like "user.created", "user.*"
+	// or "*". Empty delivers every event.
+	Events []string
+
+	// URL receives a POST per event.
+	URL string
+
+	// Secret signs posted events following the Standard Webhooks spec, so
+	// receivers verify them with nexo.StandardSignature. It is a base64
+	// key, with or without a whsec_ prefix. Empty sends them unsigned.
+	Secret string
+
+	// Header is added to posted events, e.g. an Authorization header.
+	Header http.Header
+
+	// Handler handles events in the app instead of posting them.
+	Handler Handler
+
+	key []byte // decoded Secret
+}
+
+// matches reports whether the subscriber receives events of type typ.
+func (s *Subscriber) matches(typ string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, pattern := range s.Events {
+		if pattern == "*" || pattern == typ {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures a Bus.
+type Config struct {
+	// MaxAttempts is how often a delivery is tried before it is
+	// dead-lettered. Default is 8.
+	MaxAttempts int
+
+	// Backoff returns the delay before retrying a delivery that failed
+	// attempt times. Default is DefaultBackoff.
+	Backoff func(attempt int) time.Duration
+
+	// DeadLetters keeps deliveries that ran out of attempts. Default is a
+	// MemoryDeadLetters.
+	DeadLetters DeadLetterStore
+
+	// Queue runs deliveries when they are due. Default runs them in this
+	// process, with up to Workers at once; set it to hand deliveries to a
+	// job queue that survives restarts.
+	Queue Queue
+
+	// Workers bounds the deliveries the default queue runs at once.
+	// Default is 4.
+	Workers int
+
+	// Client posts events to URL subscribers. Default has a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+// DefaultBackoff doubles the delay from 1 second up to 1 hour, with 10%
+// jitter so retries of many deliveries spread out.
+func DefaultBackoff(attempt int) time.Duration {
+	d := time.Hour
+	if attempt < 13 {
+		d = min(time.Second<<max(attempt-1, 0), time.Hour)
+	}
+	return d + rand.N(d/10+1)
+}
+
+// ErrClosed is returned for events emitted after the Bus was closed.
+var ErrClosed = errors.New("events: bus closed")
+
+// Bus delivers events to its subscribers.
+type Bus struct {
+	config Config
+	queue  Queue
+	local  *memoryQueue // default queue (nil with Config.Queue)
+
+	mu       sync.RWMutex
+	subs     map[string]*Subscriber
+	order    []string
+	handlers int
+}
+
+// New creates a Bus.
+func New(config Config) *Bus {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.Backoff == nil {
+		config.Backoff = DefaultBackoff
+	}
+	if config.DeadLetters == nil {
+		config.DeadLetters = NewMemoryDeadLetters()
+	}
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	b := &Bus{config: config, queue: config.Queue, subs: make(map[string]*Subscriber)}
+	if b.queue == nil {
+		b.local = newMemoryQueue(b, config.Workers)
+		b.queue = b.local
+	}
+	return b
+}
+
+// Subscribe adds a subscriber, replacing any with the same name.
+func (b *Bus) Subscribe(s Subscriber) error {
+	if s.Name == "" {
+		s.Name = s.URL
+	}
+	if s.Name == "" || (s.URL == "") == (s.Handler == nil) {
+		return errors.New("events: a subscriber needs either a URL or a Handler")
+	}
+	if s.Secret != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s.Secret, "whsec_"))
+		if err != nil {
+			return fmt.Errorf("events: secret of %s: %w", s.Name, err)
+		}
+		s.key = key
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[s.Name]; !ok {
+		b.order = append(b.order, s.Name)
+	}
+	b.subs[s.Name] = &s
+	return nil
+}
+
+// On handles events of the given type, or pattern like "user.*", in the
+// app. Handlers are named by the order they are added in, so deliveries
+// left in a durable queue or dead-letter store find them again after a
+// restart that adds them in the same order.
+func (b *Bus) On(pattern string, handler Handler) {
+	b.mu.Lock()
+	b.handlers++
+	name := fmt.Sprintf("on:%s#%d", pattern, b.handlers)
+	b.mu.Unlock()
+	_ = b.Subscribe(Subscriber{Name: name, Events: []string{pattern}, Handler: handler})
+}
+
+// Subscribers returns the names of the subscribers.
+func (b *Bus) Subscribers() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]string(nil), b.order...)
+}
+
+// subscriber returns the subscriber with the given name.
+func (b *Bus) subscriber(name string) (*Subscriber, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	s, ok := b.subs[name]
+	return s, ok
+}
+
+// Emit sends an event of type typ with data, marshaled as JSON, to every
+// matching subscriber. It returns once deliveries are queued; they run in
+// the background, outliving ctx.
+func (b *Bus) Emit(ctx context.Context, typ string, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("events: %s: %w", typ, err)
+	}
+	event := Event{ID: newID("evt_"), Type: typ, Time: time.Now().UTC(), Data: payload}
+
+	b.mu.RLock()
+	var targets []string
+	for _, name := range b.order {
+		if b.subs[name].matches(typ) {
+			targets = append(targets, name)
+		}
+	}
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, name := range targets {
+		d := Delivery{ID: newID("dlv_"), Event: event, Subscriber: name, NextAttempt: event.Time}
+		if err := b.queue.Enqueue(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("events: %s to %s: %w", typ, name, err))
+		}
+	}
+	return event, errors.Join(errs...)
+}
+
+// Close stops the default queue, waiting for running deliveries until ctx
+// is done. Deliveries still waiting for a retry are dead-lettered, so they
+// can be redelivered after a restart.
+func (b *Bus) Close(ctx context.Context) error {
+	if b.local == nil {
+		return nil
+	}
+	var errs []error
+	for _, d := range b.local.close() {
+		d.LastError = "bus closed before the next attempt"
+		if err := b.config.DeadLetters.Put(ctx, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := b.local.wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// newID returns a random ID with prefix.
+func newID(prefix string) string {
+	var b [12]byte
+	_, _ = crand.Read(b[:])
+	return prefix + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,231 @@
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscriber_matches(t *testing.T) {
+	tests := []struct {
+		events []string
+		typ    string
+		want   bool
+	}{
+		{nil, "user.created", true},
+		{[]string{"*"}, "user.created", true},
+		{[]string{"user.created"}, "user.created", true},
+		{[]string{"user.*"}, "user.deleted", true},
+		{[]string{"user.*"}, "order.created", false},
+		{[]string{"order.created", "user.created"}, "user.created", true},
+		{[]string{"user.created"}, "user.created.v2", false},
+	}
+	for _, tt := range tests {
+		s := Subscriber{Events: tt.events}
+		if got := s.matches(tt.typ); got != tt.want {
+			t.Errorf("%v matches %s = %v, want %v", tt.events, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestBus_Emit(t *testing.T) {
+	key := []byte("hook-secret")
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	bus := New(Config{})
+	defer bus.Close(context.Background())
+	if err := bus.Subscribe(Subscriber{
+		Name:   "billing",
+		URL:    srv.URL,
+		Events: []string{"user.*"},
+		Secret: "whsec_" + base64.StdEncoding.EncodeToString(key),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	handled := make(chan Event, 2)
+	bus.On("user.created", func(_ context.Context, e Event) error {
+		handled <- e
+		return nil
+	})
+	bus.On("order.*", func(_ context.Context, e Event) error {
+		t.Errorf("order handler got %s", e.Type)
+		return nil
+	})
+
+	event, err := bus.Emit(context.Background(), "user.created", map[string]int{"id": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-handled:
+		if e.ID != event.ID || string(e.Data) != `{"id":42}` {
+			t.Errorf("handler got %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the handler wasn't called")
+	}
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		var posted Event
+		if err := json.Unmarshal(body, &posted); err != nil || posted.ID != event.ID || posted.Type != "user.created" {
+			t.Errorf("posted %s (%v)", body, err)
+		}
+		id, ts := r.Header.Get("Webhook-Id"), r.Header.Get("Webhook-Timestamp")
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(id + "." + ts + "." + string(body)))
+		if want := "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil)); id != event.ID || r.Header.Get("Webhook-Signature") != want {
+			t.Errorf("Webhook-Id = %q, Webhook-Signature = %q, want %q", id, r.Header.Get("Webhook-Signature"), want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the URL subscriber got nothing")
+	}
+}
+
+func TestBus_Retry(t *testing.T) {
+	bus := New(Config{MaxAttempts: 5, Backoff: func(int) time.Duration { return time.Millisecond }})
+	defer bus.Close(context.Background())
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	bus.On("user.created", func(context.Context, Event) error {
+		if calls.Add(1) < 3 {
+			return errors.New("mail server down")
+		}
+		close(done)
+		return nil
+	})
+	if _, err := bus.Emit(context.Background(), "user.created", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("the handler was called %d times and never succeeded", calls.Load())
+	}
+	if letters, _ := bus.DeadLetters().List(context.Background()); len(letters) != 0 {
+		t.Errorf("dead letters = %v", letters)
+	}
+}
+
+func TestBus_DeadLetters(t *testing.T) {
+	bus := New(Config{MaxAttempts: 2, Backoff: func(int) time.Duration { return time.Millisecond }})
+	defer bus.Close(context.Background())
+
+	var fixed atomic.Bool
+	delivered := make(chan struct{})
+	bus.On("user.created", func(context.Context, Event) error {
+		if !fixed.Load() {
+			return errors.New("mail server down")
+		}
+		close(delivered)
+		return nil
+	})
+	if _, err := bus.Emit(context.Background(), "user.created", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var letters []Delivery
+	for deadline := time.Now().Add(time.Second); len(letters) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		letters, _ = bus.DeadLetters().List(context.Background())
+	}
+	if len(letters) != 1 || letters[0].Attempt != 2 || letters[0].LastError != "mail server down" {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+
+	fixed.Store(true)
+	if err := bus.Redeliver(context.Background(), letters[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("the redelivered event wasn't handled")
+	}
+	if letters, _ := bus.DeadLetters().List(context.Background()); len(letters) != 0 {
+		t.Errorf("dead letters after redelivery = %v", letters)
+	}
+	if err := bus.Redeliver(context.Background(), "dlv_missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Redeliver() of a missing letter = %v", err)
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := New(Config{Backoff: func(int) time.Duration { return time.Hour }})
+	failed := make(chan struct{})
+	bus.On("user.created", func(context.Context, Event) error {
+		defer close(failed)
+		return errors.New("mail server down")
+	})
+	if _, err := bus.Emit(context.Background(), "user.created", nil); err != nil {
+		t.Fatal(err)
+	}
+	<-failed
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	letters, _ := bus.DeadLetters().List(context.Background())
+	if len(letters) != 1 {
+		t.Fatalf("the retry waiting at Close wasn't dead-lettered: %v", letters)
+	}
+	if _, err := bus.Emit(context.Background(), "user.created", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Emit() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestFileDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileDeadLetters(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, id := range []string{"dlv_b", "dlv_a"} {
+		d := Delivery{ID: id, Subscriber: "billing", Attempt: 8, Event: Event{ID: "evt_" + id, Time: now.Add(time.Duration(i) * time.Second)}}
+		if err := store.Put(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new store over the same directory sees the letters
+	store, _ = NewFileDeadLetters(dir)
+	letters, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 || letters[0].ID != "dlv_b" || letters[1].Attempt != 8 {
+		t.Errorf("List() = %+v", letters)
+	}
+
+	if err := store.Remove(ctx, "dlv_b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove(ctx, "dlv_b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Remove() = %v", err)
+	}
+	if err := store.Put(ctx, Delivery{ID: "../escape"}); err == nil {
+		t.Error("a delivery ID with a path was stored")
+	}
+}
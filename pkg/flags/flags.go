@@ -0,0 +1,222 @@
+// Package flags provides feature flags for Nexo applications: boolean
+// switches, percentage rollouts and flags targeted at specific users, read
+// from files, environment variables or a remote service, so features can be
+// rolled out gradually without a redeploy.
+//
+// A flag is off unless Enabled. An enabled flag is on for the keys in its
+// Targets, then for the Percentage of the other keys, bucketed by a hash of
+// the flag name and key so each key keeps its answer:
+//
+//	{
+//	  "new-checkout": {"enabled": true, "percentage": 20, "targets": ["user:42"]},
+//	  "dark-mode":    {"enabled": true},
+//	  "beta-search":  {"enabled": true, "targets": ["user:7", "user:9"]}
+//	}
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flag is the definition of a feature flag.
+type Flag struct {
+	// Enabled turns the flag on. A disabled flag is off for everyone, which
+	// makes it a kill switch.
+	Enabled bool `json:"enabled" toml:"enabled"`
+
+	// Percentage rolls the flag out to a share of keys, from 0 to 100. Nil
+	// turns it on for every key, unless Targets is set.
+	Percentage *int `json:"percentage,omitempty" toml:"percentage,omitempty"`
+
+	// Targets lists keys the flag is always on for, like "user:42". A flag
+	// with targets and no percentage is on for its targets only.
+	Targets []string `json:"targets,omitempty" toml:"targets,omitempty"`
+}
+
+// On reports whether the flag named name is on for key.
+func (f Flag) On(name, key string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if key != "" && slices.Contains(f.Targets, key) {
+		return true
+	}
+	if f.Percentage == nil {
+		return len(f.Targets) == 0
+	}
+	if *f.Percentage >= 100 {
+		return true
+	}
+	return key != "" && bucket(name, key) < *f.Percentage
+}
+
+// bucket deterministically maps key to 0-99 for the flag named name.
+func bucket(name, key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(Name(name) + ":" + key))
+	return int(h.Sum32() % 100)
+}
+
+// Name returns the canonical form of a flag name. Names are compared
+// case-insensitively and with - and _ interchangeable, so new-checkout,
+// NEW_CHECKOUT and New-Checkout are the same flag.
+func Name(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
+
+// Provider loads flag definitions. Implement it to read flags from a remote
+// service; FileProvider and EnvProvider read them locally.
+type Provider interface {
+	// Flags returns the flags by name.
+	Flags(ctx context.Context) (map[string]Flag, error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(ctx context.Context) (map[string]Flag, error)
+
+// Flags implements Provider.
+func (f ProviderFunc) Flags(ctx context.Context) (map[string]Flag, error) {
+	return f(ctx)
+}
+
+// Store holds the flags loaded from its providers and reloads them once
+// they are older than the refresh interval, so changes to a file, the
+// environment or a remote service apply without a restart. Later providers
+// override the flags of earlier ones. It is safe for concurrent use.
+type Store struct {
+	providers []Provider
+	refresh   time.Duration
+
+	mu     sync.RWMutex
+	flags  map[string]Flag // canonical name -> flag
+	loaded time.Time
+	err    error
+	now    func() time.Time
+}
+
+// NewStore creates a Store reading providers, reloading them at most once
+// per refresh interval. Zero reloads them on every lookup, which suits
+// local providers; remote ones usually want a few seconds or more.
+func NewStore(refresh time.Duration, providers ...Provider) *Store {
+	return &Store{providers: providers, refresh: refresh, now: time.Now}
+}
+
+// Load reloads the flags from the providers. When a provider fails, the
+// flags loaded last are kept and the error returned.
+func (s *Store) Load(ctx context.Context) error {
+	flags := make(map[string]Flag)
+	for _, p := range s.providers {
+		loaded, err := p.Flags(ctx)
+		if err != nil {
+			s.mu.Lock()
+			s.err, s.loaded = err, s.now()
+			s.mu.Unlock()
+			return err
+		}
+		for name, f := range loaded {
+			flags[Name(name)] = f
+		}
+	}
+
+	s.mu.Lock()
+	s.flags, s.loaded, s.err = flags, s.now(), nil
+	s.mu.Unlock()
+	return nil
+}
+
+// Err returns the error of the last load, if it failed.
+func (s *Store) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// Flags returns the current flags by canonical name (see Name).
+func (s *Store) Flags(ctx context.Context) map[string]Flag {
+	s.reload(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.flags)
+}
+
+// Lookup returns the flag named name, and false if no provider defines it.
+func (s *Store) Lookup(ctx context.Context, name string) (Flag, bool) {
+	s.reload(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flags[Name(name)]
+	return f, ok
+}
+
+// Enabled reports whether the flag named name is on for key. Undefined
+// flags are off.
+func (s *Store) Enabled(ctx context.Context, name, key string) bool {
+	f, ok := s.Lookup(ctx, name)
+	return ok && f.On(name, key)
+}
+
+// For returns an Evaluator of the flags for key, like a user ID.
+func (s *Store) For(key string) *Evaluator {
+	return &Evaluator{store: s, key: key}
+}
+
+// reload loads the flags when they are older than the refresh interval.
+func (s *Store) reload(ctx context.Context) {
+	s.mu.RLock()
+	fresh := !s.loaded.IsZero() && s.refresh > 0 && s.now().Sub(s.loaded) < s.refresh
+	s.mu.RUnlock()
+	if !fresh {
+		_ = s.Load(ctx)
+	}
+}
+
+// Evaluator evaluates flags for a single key.
+type Evaluator struct {
+	store *Store
+	key   string
+}
+
+// Key returns the key the flags are evaluated for.
+func (e *Evaluator) Key() string {
+	return e.key
+}
+
+// Enabled reports whether the flag named name is on.
+func (e *Evaluator) Enabled(ctx context.Context, name string) bool {
+	return e.store.Enabled(ctx, name, e.key)
+}
+
+// evaluatorKey is the context key for the request's Evaluator.
+type evaluatorKey struct{}
+
+// NewContext returns a copy of ctx carrying e.
+func NewContext(ctx context.Context, e *Evaluator) context.Context {
+	return context.WithValue(ctx, evaluatorKey{}, e)
+}
+
+// FromContext returns the Evaluator carried by ctx, or nil.
+func FromContext(ctx context.Context) *Evaluator {
+	e, _ := ctx.Value(evaluatorKey{}).(*Evaluator)
+	return e
+}
+
+// Enabled reports whether the flag named name is on for the request of
+// ctx. Use it in templ components:
+//
+//	if flags.Enabled(ctx, "new-nav") {
+//	    @NewNav()
+//	}
+//
+// Without an Evaluator every flag is off.
+func Enabled(ctx context.Context, name string) bool {
+	if e := FromContext(ctx); e != nil {
+		return e.Enabled(ctx, name)
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+func percent(n int) *int { return &n }
+
+func TestFlag_On(t *testing.T) {
+	tests := []struct {
+		name string
+		flag Flag
+		key  string
+		want bool
+	}{
+		{"disabled", Flag{}, "user:1", false},
+		{"disabled target", Flag{Targets: []string{"user:1"}}, "user:1", false},
+		{"boolean", Flag{Enabled: true}, "", true},
+		{"target", Flag{Enabled: true, Targets: []string{"user:1"}}, "user:1", true},
+		{"not a target", Flag{Enabled: true, Targets: []string{"user:1"}}, "user:2", false},
+		{"zero percent", Flag{Enabled: true, Percentage: percent(0)}, "user:1", false},
+		{"full rollout", Flag{Enabled: true, Percentage: percent(100)}, "", true},
+		{"rollout without key", Flag{Enabled: true, Percentage: percent(50)}, "", false},
+		{"target outside rollout", Flag{Enabled: true, Percentage: percent(0), Targets: []string{"user:1"}}, "user:1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.On("beta", tt.key); got != tt.want {
+				t.Errorf("On() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlag_OnPercentage(t *testing.T) {
+	f := Flag{Enabled: true, Percentage: percent(20)}
+	on := 0
+	for i := range 1000 {
+		key := fmt.Sprintf("user:%d", i)
+		if f.On("new-checkout", key) {
+			on++
+		}
+		if f.On("new-checkout", key) != f.On("NEW_CHECKOUT", key) {
+			t.Fatalf("%s gets different answers for the same flag", key)
+		}
+	}
+	if on < 150 || on > 250 {
+		t.Errorf("a 20%% rollout is on for %d of 1000 keys", on)
+	}
+}
+
+func TestStore(t *testing.T) {
+	base := map[string]Flag{"new-checkout": {Enabled: true}, "dark-mode": {Enabled: true}}
+	var remoteErr error
+	remote := ProviderFunc(func(context.Context) (map[string]Flag, error) {
+		return map[string]Flag{"DARK_MODE": {}}, remoteErr
+	})
+	now := time.Now()
+	s := NewStore(time.Minute, ProviderFunc(func(context.Context) (map[string]Flag, error) { return base, nil }), remote)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if !s.Enabled(ctx, "new_checkout", "") {
+		t.Error("new_checkout is off, want on")
+	}
+	if s.Enabled(ctx, "dark-mode", "") {
+		t.Error("dark-mode is on, want the later provider to turn it off")
+	}
+	if s.Enabled(ctx, "missing", "") {
+		t.Error("an undefined flag is on")
+	}
+
+	// Flags are reloaded once the refresh interval has passed
+	base = map[string]Flag{}
+	if !s.Enabled(ctx, "new-checkout", "") {
+		t.Error("flags were reloaded before the refresh interval")
+	}
+	now = now.Add(time.Minute)
+	if s.Enabled(ctx, "new-checkout", "") {
+		t.Error("flags weren't reloaded after the refresh interval")
+	}
+
+	// A failing provider keeps the last flags
+	base = map[string]Flag{"new-checkout": {Enabled: true}}
+	remoteErr = errors.New("unavailable")
+	now = now.Add(time.Minute)
+	if s.Enabled(ctx, "new-checkout", "") || s.Err() == nil {
+		t.Errorf("after a failed load: new-checkout on, err = %v", s.Err())
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if flags, err := NewFileProvider(filepath.Join(dir, "missing.json")).Flags(ctx); err != nil || len(flags) != 0 {
+		t.Errorf("missing file: Flags() = %v, %v", flags, err)
+	}
+
+	path := filepath.Join(dir, "flags.toml")
+	write := func(src string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mtime := time.Now().Add(-time.Hour)
+	write("[new-checkout]\nenabled = true\npercentage = 20\ntargets = [\"user:42\"]\n", mtime)
+	p := NewFileProvider(path)
+	flags, err := p.Flags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := flags["new-checkout"]
+	if !f.Enabled || f.Percentage == nil || *f.Percentage != 20 || len(f.Targets) != 1 {
+		t.Errorf("new-checkout = %+v", f)
+	}
+
+	// Changes are picked up
+	write("[new-checkout]\nenabled = false\n", mtime.Add(time.Minute))
+	if flags, _ := p.Flags(ctx); flags["new-checkout"].Enabled {
+		t.Error("the changed file wasn't read again")
+	}
+
+	write("[new-checkout]\nenabled = true\npercentage = 120\n", mtime.Add(2*time.Minute))
+	if _, err := p.Flags(ctx); err == nil {
+		t.Error("a percentage over 100 was accepted")
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("TEST_FLAG_DARK_MODE", "on")
+	t.Setenv("TEST_FLAG_NEW_CHECKOUT", "25%")
+	t.Setenv("TEST_FLAG_BETA_SEARCH", "user:7, user:9")
+	t.Setenv("TEST_FLAG_OLD_NAV", "false")
+
+	flags, err := NewEnvProvider("TEST_FLAG_").Flags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flags["dark_mode"].Enabled {
+		t.Error("dark_mode is off")
+	}
+	if p := flags["new_checkout"].Percentage; p == nil || *p != 25 {
+		t.Errorf("new_checkout percentage = %v", p)
+	}
+	if targets := flags["beta_search"].Targets; len(targets) != 2 || targets[1] != "user:9" {
+		t.Errorf("beta_search targets = %v", targets)
+	}
+	if f, ok := flags["old_nav"]; !ok || f.Enabled {
+		t.Errorf("old_nav = %+v, %v", f, ok)
+	}
+
+	t.Setenv("TEST_FLAG_BROKEN", "150%")
+	if _, err := NewEnvProvider("TEST_FLAG_").Flags(context.Background()); err == nil {
+		t.Error("an invalid percentage was accepted")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	text := func(s string) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		})
+	}
+	s := NewStore(0, ProviderFunc(func(context.Context) (map[string]Flag, error) {
+		return map[string]Flag{"new-nav": {Enabled: true, Targets: []string{"user:1"}}}, nil
+	}))
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		component templ.Component
+		want      string
+	}{
+		{"on", NewContext(context.Background(), s.For("user:1")), Choose("new-nav", text("new"), text("old")), "new"},
+		{"off", NewContext(context.Background(), s.For("user:2")), Choose("new-nav", text("new"), text("old")), "old"},
+		{"no flags", context.Background(), Choose("new-nav", text("new"), text("old")), "old"},
+		{"show off", NewContext(context.Background(), s.For("user:2")), Show("new-nav", text("new")), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.component.Render(tt.ctx, &buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("rendered %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
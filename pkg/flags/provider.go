@@ -0,0 +1,143 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileProvider reads flags from a JSON or TOML file, chosen by its
+// extension, mapping flag names to definitions. The file is read again
+// whenever it changes; a missing file defines no flags.
+type FileProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	flags   map[string]Flag
+}
+
+// NewFileProvider creates a FileProvider for the file at path.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Flags implements Provider.
+func (p *FileProvider) Flags(context.Context) (map[string]Flag, error) {
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.flags != nil && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.flags, nil
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := ParseFile(p.path, data)
+	if err != nil {
+		return nil, err
+	}
+	p.flags, p.modTime, p.size = flags, info.ModTime(), info.Size()
+	return flags, nil
+}
+
+// ParseFile decodes a JSON or TOML flags file, chosen by the extension of
+// name.
+func ParseFile(name string, data []byte) (map[string]Flag, error) {
+	flags := make(map[string]Flag)
+	var err error
+	if filepath.Ext(name) == ".toml" {
+		err = toml.Unmarshal(data, &flags)
+	} else {
+		err = json.Unmarshal(data, &flags)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("flags: failed to parse %s: %w", name, err)
+	}
+	for flag, f := range flags {
+		if f.Percentage != nil && (*f.Percentage < 0 || *f.Percentage > 100) {
+			return nil, fmt.Errorf("flags: %s: percentage of %s must be between 0 and 100", name, flag)
+		}
+	}
+	return flags, nil
+}
+
+// EnvProvider reads flags from environment variables starting with
+// prefix, like NEXO_FLAG_NEW_CHECKOUT for the flag new-checkout. The value
+// is one of:
+//
+//	true, on, false, off    a boolean flag
+//	25%                     a rollout to 25% of keys
+//	user:7,user:9           a flag targeted at these keys
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates an EnvProvider for variables starting with
+// prefix. Empty uses NEXO_FLAG_.
+func NewEnvProvider(prefix string) *EnvProvider {
+	if prefix == "" {
+		prefix = "NEXO_FLAG_"
+	}
+	return &EnvProvider{prefix: prefix}
+}
+
+// Flags implements Provider.
+func (p *EnvProvider) Flags(context.Context) (map[string]Flag, error) {
+	flags := make(map[string]Flag)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, p.prefix) || name == p.prefix {
+			continue
+		}
+		f, err := ParseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("flags: %s: %w", name, err)
+		}
+		flags[Name(strings.TrimPrefix(name, p.prefix))] = f
+	}
+	return flags, nil
+}
+
+// ParseValue parses the value of a flag variable (see EnvProvider).
+func ParseValue(value string) (Flag, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "true", "on":
+		return Flag{Enabled: true}, nil
+	case "false", "off", "":
+		return Flag{}, nil
+	}
+	if n, ok := strings.CutSuffix(value, "%"); ok {
+		percentage, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || percentage < 0 || percentage > 100 {
+			return Flag{}, fmt.Errorf("invalid percentage %q", value)
+		}
+		return Flag{Enabled: true, Percentage: &percentage}, nil
+	}
+
+	var targets []string
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return Flag{Enabled: true, Targets: targets}, nil
+}
@@ -0,0 +1,33 @@
+package flags
+
+import (
+	"context"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// Show renders component when the flag named name is on for the request,
+// and nothing otherwise:
+//
+//	@flags.Show("new-nav", NewNav())
+func Show(name string, component templ.Component) templ.Component {
+	return Choose(name, component, nil)
+}
+
+// Choose renders on when the flag named name is on for the request, and
+// off otherwise. A nil component renders nothing:
+//
+//	@flags.Choose("new-checkout", NewCheckout(cart), Checkout(cart))
+func Choose(name string, on, off templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		component := off
+		if Enabled(ctx, name) {
+			component = on
+		}
+		if component == nil {
+			return nil
+		}
+		return component.Render(ctx, w)
+	})
+}
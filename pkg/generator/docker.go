@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultDockerGoVersion is the Go image tag used when go.mod has no go
+	// directive.
+	DefaultDockerGoVersion = "1.25"
+
+	// DefaultDockerRuntimeImage is the final stage of generated Dockerfiles:
+	// no shell or package manager, running as an unprivileged user.
+	DefaultDockerRuntimeImage = "gcr.io/distroless/static-debian12:nonroot"
+
+	// DefaultDockerPort is the port generated images expose.
+	DefaultDockerPort = 3000
+)
+
+// Module paths of the libraries nexo projects depend on.
+const (
+	NexoModule  = "github.com/abdul-hamid-achik/nexo"
+	TemplModule = "github.com/a-h/templ"
+)
+
+// DockerfileOptions configures GenerateDockerfile.
+type DockerfileOptions struct {
+	// GoVersion is the tag of the golang build image, e.g. "1.25.5".
+	GoVersion string
+
+	// NexoVersion is the version of the nexo CLI installed in the build
+	// stage, e.g. "v0.9.0" (default: "latest").
+	NexoVersion string
+
+	// TemplVersion is the version of the templ CLI installed in the build
+	// stage. Empty skips templ generate.
+	TemplVersion string
+
+	// Config copies nexo.yaml into the final stage, where the app reads it
+	// from its working directory.
+	Config bool
+
+	// Node installs Node.js and runs npm ci before the build, for projects
+	// with a package.json.
+	Node bool
+
+	// RuntimeImage is the final stage's base image (default:
+	// DefaultDockerRuntimeImage).
+	RuntimeImage string
+
+	// Port is the port the app listens on (default: DefaultDockerPort).
+	Port int
+}
+
+// DetectDockerfileOptions reads the project at root: the Go version and the
+// nexo and templ versions from go.mod, and whether it has a nexo.yaml and a
+// package.json. Modules replaced with a local path install the latest
+// release instead.
+func DetectDockerfileOptions(root string) DockerfileOptions {
+	opts := DockerfileOptions{GoVersion: DefaultDockerGoVersion}
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		mod := ParseGoMod(data)
+		if mod.Go != "" {
+			opts.GoVersion = mod.Go
+		}
+		if v, ok := mod.Require[NexoModule]; ok && !mod.Replaced(NexoModule) {
+			opts.NexoVersion = v
+		}
+		if v, ok := mod.Require[TemplModule]; ok {
+			opts.TemplVersion = v
+			if mod.Replaced(TemplModule) {
+				opts.TemplVersion = "latest"
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "nexo.yaml")); err == nil {
+		opts.Config = true
+	}
+	if _, err := os.Stat(filepath.Join(root, "package.json")); err == nil {
+		opts.Node = true
+	}
+	return opts
+}
+
+// GoMod is the part of a go.mod file nexo reads.
+type GoMod struct {
+	// Go is the go directive, e.g. "1.25".
+	Go string
+
+	// Require maps required modules to their versions.
+	Require map[string]string
+
+	// Replace maps replaced modules to their replacement: a local path or
+	// "module version".
+	Replace map[string]string
+}
+
+// Replaced reports whether module is replaced.
+func (m GoMod) Replaced(module string) bool {
+	_, ok := m.Replace[module]
+	return ok
+}
+
+// ParseGoMod reads the go directive, requirements and replacements of a
+// go.mod file.
+func ParseGoMod(data []byte) GoMod {
+	info := GoMod{Require: make(map[string]string), Replace: make(map[string]string)}
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+
+		switch {
+		case fields[0] == "go" && len(fields) >= 2:
+			info.Go = fields[1]
+		case fields[0] == "require" && len(fields) >= 3:
+			info.Require[fields[1]] = fields[2]
+		case fields[0] == "replace" && len(fields) >= 2:
+			_, target, _ := strings.Cut(strings.Join(fields[2:], " "), "=> ")
+			info.Replace[fields[1]] = strings.TrimSpace(target)
+		}
+	}
+	return info
+}
+
+// dockerfileTemplate builds the app with nexo build in a Go image, then
+// copies the single binary, with static files and content embedded, into a
+// distroless image.
+var dockerfileTemplate = `# syntax=docker/dockerfile:1
+# Generated by nexo docker init.
+
+FROM golang:{{.GoVersion}} AS build
+WORKDIR /src
+{{- if .Node}}
+
+RUN apt-get update && apt-get install -y --no-install-recommends nodejs npm \
+    && rm -rf /var/lib/apt/lists/*
+COPY package.json package-lock.json* ./
+RUN npm ci
+{{- end}}
+
+RUN go install github.com/abdul-hamid-achik/nexo/cmd/nexo@{{.NexoVersion}}
+{{- if .TemplVersion}}
+RUN go install github.com/a-h/templ/cmd/templ@{{.TemplVersion}}
+{{- end}}
+
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+ENV CGO_ENABLED=0
+RUN nexo build --embed -o /out/app
+
+FROM {{.RuntimeImage}}
+WORKDIR /app
+COPY --from=build /out/app /app/app
+{{- if .Config}}
+COPY --from=build /src/nexo.yaml /app/nexo.yaml
+{{- end}}
+
+ENV PORT={{.Port}}
+EXPOSE {{.Port}}
+
+USER nonroot:nonroot
+ENTRYPOINT ["/app/app"]
+`
+
+// GenerateDockerfile renders a multi-stage Dockerfile. The build stage runs
+// nexo build --embed, which generates templ components, builds Tailwind CSS
+// and the JavaScript bundle, and compiles a static binary; the final stage
+// holds only that binary.
+func GenerateDockerfile(opts DockerfileOptions) ([]byte, error) {
+	if opts.GoVersion == "" {
+		opts.GoVersion = DefaultDockerGoVersion
+	}
+	if opts.NexoVersion == "" {
+		opts.NexoVersion = "latest"
+	}
+	if opts.RuntimeImage == "" {
+		opts.RuntimeImage = DefaultDockerRuntimeImage
+	}
+	if opts.Port == 0 {
+		opts.Port = DefaultDockerPort
+	}
+	if opts.Port < 0 || opts.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", opts.Port)
+	}
+	return renderTemplate("Dockerfile", dockerfileTemplate, nil, opts)
+}
+
+// Dockerignore is the .dockerignore written by nexo docker init. It keeps
+// build outputs, dependencies and secrets out of the build context.
+const Dockerignore = `.git
+.nexo
+bin
+tmp
+node_modules
+*_templ.go
+.env
+.env.*
+Dockerfile
+.dockerignore
+`
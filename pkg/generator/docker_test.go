@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectDockerfileOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		goMod string
+		files []string
+		want  DockerfileOptions
+	}{
+		{
+			name: "require block",
+			goMod: `module example.com/app
+
+go 1.25.5
+
+require (
+	github.com/a-h/templ v0.3.977 // indirect
+	github.com/abdul-hamid-achik/nexo v0.9.0
+)
+`,
+			files: []string{"nexo.yaml", "package.json"},
+			want: DockerfileOptions{
+				GoVersion:    "1.25.5",
+				NexoVersion:  "v0.9.0",
+				TemplVersion: "v0.3.977",
+				Config:       true,
+				Node:         true,
+			},
+		},
+		{
+			name: "replaced modules",
+			goMod: `module example.com/app
+
+go 1.24
+
+require github.com/abdul-hamid-achik/nexo v0.9.0
+require github.com/a-h/templ v0.3.977
+
+replace github.com/abdul-hamid-achik/nexo => ../nexo
+replace (
+	github.com/a-h/templ => ../templ
+)
+`,
+			want: DockerfileOptions{GoVersion: "1.24", TemplVersion: "latest"},
+		},
+		{
+			name: "no go.mod",
+			want: DockerfileOptions{GoVersion: DefaultDockerGoVersion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tt.goMod != "" {
+				if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(tt.goMod), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, f), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := DetectDockerfileOptions(root); got != tt.want {
+				t.Errorf("DetectDockerfileOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateDockerfile(t *testing.T) {
+	content, err := GenerateDockerfile(DockerfileOptions{GoVersion: "1.25.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(content)
+	for _, want := range []string{
+		"FROM golang:1.25.5 AS build\n",
+		"RUN go install github.com/abdul-hamid-achik/nexo/cmd/nexo@latest\n",
+		"RUN nexo build --embed -o /out/app\n",
+		"FROM " + DefaultDockerRuntimeImage + "\n",
+		"EXPOSE 3000\n",
+		"USER nonroot:nonroot\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Dockerfile missing %q:\n%s", want, src)
+		}
+	}
+	for _, unwanted := range []string{"templ/cmd/templ", "npm ci", "nexo.yaml"} {
+		if strings.Contains(src, unwanted) {
+			t.Errorf("Dockerfile contains %q:\n%s", unwanted, src)
+		}
+	}
+
+	content, err = GenerateDockerfile(DockerfileOptions{
+		NexoVersion:  "v0.9.0",
+		TemplVersion: "v0.3.977",
+		Config:       true,
+		Node:         true,
+		Port:         8080,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = string(content)
+	for _, want := range []string{
+		"nexo/cmd/nexo@v0.9.0\n",
+		"RUN go install github.com/a-h/templ/cmd/templ@v0.3.977\n",
+		"RUN npm ci\n",
+		"COPY --from=build /src/nexo.yaml /app/nexo.yaml\n",
+		"EXPOSE 8080\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Dockerfile missing %q:\n%s", want, src)
+		}
+	}
+
+	if _, err := GenerateDockerfile(DockerfileOptions{Port: 70000}); err == nil {
+		t.Error("GenerateDockerfile() with port 70000 succeeded")
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	mod := ParseGoMod([]byte(`module example.com/shop
+
+go 1.25
+
+require (
+	github.com/a-h/templ v0.3.977 // indirect
+	github.com/abdul-hamid-achik/nexo v0.9.0
+)
+
+replace github.com/abdul-hamid-achik/nexo => ../nexo
+
+replace (
+	example.com/a v1.0.0 => example.com/b v1.2.0
+)
+`))
+	if mod.Go != "1.25" || mod.Require[TemplModule] != "v0.3.977" {
+		t.Errorf("ParseGoMod() = %+v", mod)
+	}
+	if mod.Replace[NexoModule] != "../nexo" || !mod.Replaced(NexoModule) {
+		t.Errorf("Replace[nexo] = %q", mod.Replace[NexoModule])
+	}
+	if mod.Replace["example.com/a"] != "example.com/b v1.2.0" {
+		t.Errorf("Replace[example.com/a] = %q", mod.Replace["example.com/a"])
+	}
+	if mod.Replaced(TemplModule) {
+		t.Error("templ reported as replaced")
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EmbedFileName is the file nexo build --embed writes next to main.go for
+// the duration of the build.
+const EmbedFileName = "nexo_embed.go"
+
+// DefaultEmbedDirs are the project directories embedded by nexo build
+// --embed: static files (including the asset manifest) and content pages.
+var DefaultEmbedDirs = []string{"static", "content"}
+
+// ExistingDirs returns the dirs under root that exist, in order.
+func ExistingDirs(root string, dirs []string) []string {
+	var found []string
+	for _, dir := range dirs {
+		info, err := os.Stat(filepath.Join(root, dir))
+		if err == nil && info.IsDir() {
+			found = append(found, filepath.ToSlash(filepath.Clean(dir)))
+		}
+	}
+	return found
+}
+
+// embedFileTemplate registers the embedded project files with the nexo
+// runtime before main runs.
+var embedFileTemplate = `// Code generated by nexo build. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"embed"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+//go:embed{{range .Dirs}} all:{{.}}{{end}}
+var nexoEmbeddedFS embed.FS
+
+func init() {
+	nexo.SetEmbeddedFS(nexoEmbeddedFS)
+}
+`
+
+// GenerateEmbedFile renders a Go file in package pkg that embeds dirs and
+// registers them with nexo.SetEmbeddedFS.
+func GenerateEmbedFile(pkg string, dirs []string) ([]byte, error) {
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no directories to embed")
+	}
+	data := struct {
+		Package string
+		Dirs    []string
+	}{pkg, dirs}
+	return renderTemplate(EmbedFileName, embedFileTemplate, nil, data)
+}
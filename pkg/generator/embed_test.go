@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExistingDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"static", "content"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "public"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ExistingDirs(root, []string{"static", "missing", "public", "content/"})
+	want := []string{"static", "content"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExistingDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateEmbedFile(t *testing.T) {
+	content, err := GenerateEmbedFile("main", []string{"static", "content"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(content)
+	if !strings.Contains(src, "//go:embed all:static all:content\n") {
+		t.Errorf("missing go:embed directive:\n%s", src)
+	}
+	if !strings.Contains(src, "nexo.SetEmbeddedFS(nexoEmbeddedFS)") {
+		t.Errorf("missing SetEmbeddedFS call:\n%s", src)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), EmbedFileName, content, parser.ParseComments); err != nil {
+		t.Errorf("generated file does not parse: %v\n%s", err, src)
+	}
+
+	if _, err := GenerateEmbedFile("main", nil); err == nil {
+		t.Error("GenerateEmbedFile() with no dirs succeeded")
+	}
+}
@@ -2,7 +2,10 @@
 package generator
 
 import (
-	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -11,54 +14,92 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/abdul-hamid-achik/nexo/pkg/gosource"
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+	"github.com/abdul-hamid-achik/nexo/pkg/markdown"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
 )
 
 // RouteConfig holds configuration for route generation.
 type RouteConfig struct {
-	Path    string   // Route path (e.g., "users/[id]")
-	Methods []string // HTTP methods (e.g., ["GET", "PUT", "DELETE"])
-	AppDir  string   // App directory (default: "app")
+	Path        string   // Route path (e.g., "users/[id]")
+	Methods     []string // HTTP methods (e.g., ["GET", "PUT", "DELETE"])
+	AppDir      string   // App directory (default: "app")
+	TemplateDir string   // Template override directory (default: .nexo/templates next to AppDir)
+	Split       bool     // One file per method (get.go, post.go) instead of route.go
+	AddMethod   bool     // Add missing handlers to an existing route instead of failing
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
 }
 
 // MiddlewareConfig holds configuration for middleware generation.
+// A middleware.go.tmpl override replaces the blank template only.
 type MiddlewareConfig struct {
-	Name     string // Middleware name (e.g., "auth")
-	Path     string // Path prefix (e.g., "api/protected")
-	Template string // Template name (auth, logging, timing, cors, blank)
-	AppDir   string // App directory (default: "app")
+	Name        string // Middleware name (e.g., "auth")
+	Path        string // Path prefix (e.g., "api/protected")
+	Template    string // Template name (auth, logging, timing, cors, blank)
+	AppDir      string // App directory (default: "app")
+	TemplateDir string // Template override directory (default: .nexo/templates next to AppDir)
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
 }
 
 // ProxyConfig holds configuration for proxy generation.
 type ProxyConfig struct {
 	Template string // Template name (auth-check, rate-limit, maintenance, redirect-www, blank)
 	AppDir   string // App directory (default: "app")
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
+}
+
+// WebhookConfig holds configuration for webhook generation.
+type WebhookConfig struct {
+	Provider string // Provider name (stripe, github, slack, shopify, standard, or any other name)
+	AppDir   string // App directory (default: "app")
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
 }
 
 // PageConfig holds configuration for page generation.
 type PageConfig struct {
-	Path       string // Page path (e.g., "dashboard")
-	AppDir     string // App directory (default: "app")
-	WithLayout bool   // Create a layout.templ alongside the page
+	Path        string // Page path (e.g., "dashboard")
+	AppDir      string // App directory (default: "app")
+	WithLayout  bool   // Create a layout.templ alongside the page
+	TemplateDir string // Template override directory (default: .nexo/templates next to AppDir)
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
 }
 
 // Result holds the result of a generation operation.
 type Result struct {
 	Files   []string `json:"files"`
+	Updated []string `json:"updated,omitempty"` // existing files handlers were added to (AddMethod)
+	Skipped []string `json:"skipped,omitempty"` // methods already declared (AddMethod)
 	Pattern string   `json:"pattern,omitempty"`
 }
 
 // Regular expressions for parsing route paths using Next.js-style naming:
-//   - [param]      -> dynamic segment
+//   - [param]      -> dynamic segment, typed like [id:int] or [slug:re(\w+)]
 //   - [...param]   -> catch-all segment
 //   - [[...param]] -> optional catch-all segment
 //   - (group)      -> route group (doesn't affect URL)
+//   - @slot        -> parallel route slot (doesn't affect URL)
+//   - (..)segment  -> intercepting route, resolved like a relative path
 var (
-	dynamicSegmentRe   = regexp.MustCompile(`^\[([a-zA-Z_][a-zA-Z0-9_]*)\]$`)
+	dynamicSegmentRe   = regexp.MustCompile(`^\[([a-zA-Z_][a-zA-Z0-9_]*)(?::(.+))?\]$`)
 	catchAllSegmentRe  = regexp.MustCompile(`^\[\.\.\.([a-zA-Z_][a-zA-Z0-9_]*)\]$`)
 	optionalCatchAllRe = regexp.MustCompile(`^\[\[\.\.\.([a-zA-Z_][a-zA-Z0-9_]*)\]\]$`)
 	routeGroupRe       = regexp.MustCompile(`^\(([a-zA-Z_][a-zA-Z0-9_]*)\)$`)
+	slotSegmentRe      = regexp.MustCompile(`^@([a-zA-Z_][a-zA-Z0-9_]*)$`)
+	interceptMarkerRe  = regexp.MustCompile(`^(\(\.\.\.\)|\(\.\)|(?:\(\.\.\))+)`)
 )
 
 // knownPrivateFolders contains folder prefixes that are private (not routable)
@@ -70,6 +111,7 @@ var knownPrivateFolders = []string{
 	"_helpers",
 	"_private",
 	"_shared",
+	"_middleware",
 }
 
 // isGeneratorPrivateFolder checks if a directory should be skipped during generation
@@ -92,11 +134,25 @@ func isGeneratorPrivateFolder(name, _ string) bool {
 // ParamInfo holds information about a route parameter
 type ParamInfo struct {
 	Name       string
+	Type       string // int or uuid for typed parameters like [id:int]
 	IsCatchAll bool
 	IsOptional bool
 }
 
-// GenerateRoute generates a route file with handlers.
+// Value returns the expression that reads the parameter in a handler,
+// converted to its type: c.ParamInt("id", 0) for [id:int] and
+// c.ParamAll("slug") for [...slug].
+func (p ParamInfo) Value() string {
+	if p.IsCatchAll {
+		return "c.ParamAll(" + strconv.Quote(p.Name) + ")"
+	}
+	return paramValue(p.Name, p.Type)
+}
+
+// GenerateRoute generates a route file with handlers. With Split, each
+// method gets its own file (get.go, post.go) instead of sharing route.go.
+// With AddMethod, methods the route already handles are skipped and the
+// others are appended to the existing route file, keeping its code as is.
 func GenerateRoute(cfg RouteConfig) (*Result, error) {
 	if cfg.AppDir == "" {
 		cfg.AppDir = "app"
@@ -104,6 +160,7 @@ func GenerateRoute(cfg RouteConfig) (*Result, error) {
 	if len(cfg.Methods) == 0 {
 		cfg.Methods = []string{"GET"}
 	}
+	fsys := genfs.Or(cfg.FS)
 
 	// Normalize methods to uppercase
 	for i, m := range cfg.Methods {
@@ -117,20 +174,73 @@ func GenerateRoute(cfg RouteConfig) (*Result, error) {
 	} else {
 		dirPath = filepath.Join(cfg.AppDir, "api", cfg.Path)
 	}
-	filePath := filepath.Join(dirPath, "route.go")
 
-	// Create directory
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create directory: %w", err)
+	// Convert methods to methodInfo with proper function names
+	declared := routeDirHandlers(fsys, dirPath)
+	var methods []methodInfo
+	var skipped []string
+	for _, m := range cfg.Methods {
+		info := methodInfo{
+			Method:   m,
+			FuncName: toTitleCase(m),
+			HasBody:  m == "POST" || m == "PUT" || m == "PATCH",
+			IsList:   m == "GET" && isCollectionPath(cfg.Path),
+		}
+		if _, ok := declared[info.FuncName]; ok && cfg.AddMethod {
+			skipped = append(skipped, m)
+			continue
+		}
+		methods = append(methods, info)
+	}
+	if len(methods) == 0 {
+		return &Result{Skipped: skipped, Pattern: "/api/" + pathToPattern(cfg.Path)}, nil
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); err == nil {
-		return nil, fmt.Errorf("file already exists: %s", filePath)
+	// Group the methods by the file they go in
+	var files []string
+	fileMethods := make(map[string][]methodInfo)
+	for _, m := range methods {
+		name := "route.go"
+		if cfg.Split {
+			if _, ok := scanner.HandlerMethod(m.FuncName); !ok {
+				return nil, fmt.Errorf("unsupported method for --split: %s", m.Method)
+			}
+			name = strings.ToLower(m.Method) + ".go"
+		}
+		path := filepath.Join(dirPath, name)
+		if _, ok := fileMethods[path]; !ok {
+			files = append(files, path)
+		}
+		fileMethods[path] = append(fileMethods[path], m)
+	}
+
+	// Check that neither the files nor the handlers exist yet
+	existing := make(map[string]bool)
+	for _, path := range files {
+		if genfs.Exists(fsys, path) {
+			if !cfg.AddMethod {
+				return nil, fmt.Errorf("file already exists: %s", path)
+			}
+			existing[path] = true
+		}
+	}
+	for _, m := range methods {
+		if path, ok := declared[m.FuncName]; ok {
+			return nil, fmt.Errorf("%s is already declared in %s", m.FuncName, path)
+		}
+	}
+
+	// Create directory
+	if err := fsys.MkdirAll(dirPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Generate package name from last segment (cleaned)
+	// Generate package name from last segment (cleaned), unless the
+	// directory already has a package
 	pkgName := packageNameFromPath(cfg.Path)
+	if name := dirPackageName(fsys, dirPath); name != "" {
+		pkgName = name
+	}
 
 	// Extract parameters from path
 	params := extractParams(cfg.Path)
@@ -138,31 +248,78 @@ func GenerateRoute(cfg RouteConfig) (*Result, error) {
 	// Convert to URL pattern
 	pattern := pathToPattern(cfg.Path)
 
-	// Convert methods to methodInfo with proper function names
-	methods := make([]methodInfo, len(cfg.Methods))
-	for i, m := range cfg.Methods {
-		methods[i] = methodInfo{
-			Method:   m,
-			FuncName: toTitleCase(m),
-		}
+	tmpl, overridden, err := loadTemplate(resolveTemplateDir(cfg.TemplateDir, cfg.AppDir), RouteTemplateName, routeTemplate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate code
-	data := routeTemplateData{
-		Package: pkgName,
-		Methods: methods,
-		Params:  params,
-		Pattern: pattern,
+	// Render every file before writing any
+	outputs := make(map[string][]byte)
+	for _, path := range files {
+		// Generate code
+		data := routeTemplateData{
+			Package: pkgName,
+			Methods: fileMethods[path],
+			Params:  params,
+			Pattern: pattern,
+		}
+
+		content, err := renderTemplate(filepath.Base(path), tmpl, nil, data)
+		if err != nil {
+			return nil, overrideError(RouteTemplateName, overridden, err)
+		}
+
+		if err := checkNewDecls(fsys, dirPath, content); err != nil {
+			return nil, err
+		}
+		if existing[path] {
+			content, err = appendRouteHandlers(fsys, path, content)
+			if err != nil {
+				return nil, err
+			}
+		}
+		outputs[path] = content
 	}
 
-	if err := executeTemplate(filePath, routeTemplate, data); err != nil {
-		return nil, err
+	result := &Result{Skipped: skipped, Pattern: "/api/" + pattern}
+	for _, path := range files {
+		if err := writeGeneratedFile(fsys, path, outputs[path]); err != nil {
+			return nil, err
+		}
+		if existing[path] {
+			result.Updated = append(result.Updated, path)
+		} else {
+			result.Files = append(result.Files, path)
+		}
 	}
+	return result, nil
+}
 
-	return &Result{
-		Files:   []string{filePath},
-		Pattern: "/api/" + pattern,
-	}, nil
+// routeHandlerRe matches the declaration of a route handler function.
+var routeHandlerRe = regexp.MustCompile(`(?m)^func\s+(Get|Post|Put|Patch|Delete|Head|Options)\s*\(`)
+
+// routeDirHandlers returns the handlers already declared by the route files
+// in dir, mapped to the file declaring them.
+func routeDirHandlers(fsys genfs.WriteFS, dir string) map[string]string {
+	handlers := make(map[string]string)
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return handlers
+	}
+	for _, e := range entries {
+		if e.IsDir() || !scanner.IsRouteFile(e.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		content, err := fsys.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, m := range routeHandlerRe.FindAllSubmatch(content, -1) {
+			handlers[string(m[1])] = path
+		}
+	}
+	return handlers
 }
 
 // GenerateMiddleware generates a middleware file.
@@ -173,6 +330,7 @@ func GenerateMiddleware(cfg MiddlewareConfig) (*Result, error) {
 	if cfg.Template == "" {
 		cfg.Template = "blank"
 	}
+	fsys := genfs.Or(cfg.FS)
 
 	// Determine directory path
 	var dirPath string
@@ -184,12 +342,12 @@ func GenerateMiddleware(cfg MiddlewareConfig) (*Result, error) {
 	filePath := filepath.Join(dirPath, "middleware.go")
 
 	// Create directory
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
+	if err := fsys.MkdirAll(dirPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(filePath); err == nil {
+	if genfs.Exists(fsys, filePath) {
 		return nil, fmt.Errorf("file already exists: %s", filePath)
 	}
 
@@ -205,13 +363,28 @@ func GenerateMiddleware(cfg MiddlewareConfig) (*Result, error) {
 		return nil, fmt.Errorf("unknown middleware template: %s", cfg.Template)
 	}
 
+	// Only the blank template can be overridden by the project
+	overridden := false
+	if cfg.Template == "blank" {
+		var err error
+		tmpl, overridden, err = loadTemplate(resolveTemplateDir(cfg.TemplateDir, cfg.AppDir), MiddlewareTemplateName, tmpl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	data := middlewareTemplateData{
 		Package: pkgName,
 		Name:    cfg.Name,
 		Path:    "/" + cfg.Path,
 	}
 
-	if err := executeTemplate(filePath, tmpl, data); err != nil {
+	content, err := renderTemplate(filepath.Base(filePath), tmpl, nil, data)
+	if err != nil {
+		return nil, overrideError(MiddlewareTemplateName, overridden, err)
+	}
+
+	if err := writeGeneratedFile(fsys, filePath, content); err != nil {
 		return nil, err
 	}
 
@@ -220,6 +393,76 @@ func GenerateMiddleware(cfg MiddlewareConfig) (*Result, error) {
 	}, nil
 }
 
+// webhookProvider describes how a provider's webhooks are verified.
+type webhookProvider struct {
+	name     string // display name
+	envVar   string // environment variable holding the secret
+	verifier string // nexo verifier, formatted with the os.Getenv call
+	route    string // key in webhookRouteTemplates
+}
+
+// webhookProviders are the providers GenerateWebhook knows. Other names get
+// a generic HMAC-SHA256 verifier.
+var webhookProviders = map[string]webhookProvider{
+	"stripe":   {"Stripe", "STRIPE_WEBHOOK_SECRET", "nexo.StripeSignature(%s)", "stripe"},
+	"github":   {"GitHub", "GITHUB_WEBHOOK_SECRET", "nexo.GitHubSignature(%s)", "github"},
+	"slack":    {"Slack", "SLACK_SIGNING_SECRET", "nexo.SlackSignature(%s)", "slack"},
+	"shopify":  {"Shopify", "SHOPIFY_WEBHOOK_SECRET", "nexo.ShopifySignature(%s)", "shopify"},
+	"standard": {"Standard Webhooks", "WEBHOOK_SECRET", "nexo.StandardSignature(%s)", "hmac"},
+}
+
+// GenerateWebhook generates a webhook receiver in app/webhooks/<provider>:
+// a middleware.go verifying signatures and a route.go handling events.
+func GenerateWebhook(cfg WebhookConfig) (*Result, error) {
+	if cfg.AppDir == "" {
+		cfg.AppDir = "app"
+	}
+	provider := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if provider == "" || strings.ContainsAny(provider, "/\\.") {
+		return nil, fmt.Errorf("invalid webhook provider: %q", cfg.Provider)
+	}
+
+	p, ok := webhookProviders[provider]
+	if !ok {
+		envVar := strings.ToUpper(strings.ReplaceAll(provider, "-", "_")) + "_WEBHOOK_SECRET"
+		p = webhookProvider{toTitle(strings.ReplaceAll(provider, "-", " ")), envVar, `nexo.HMACSignature("X-Signature", "", %s)`, "hmac"}
+	}
+
+	dirPath := filepath.Join(cfg.AppDir, "webhooks", provider)
+	fsys := genfs.Or(cfg.FS)
+
+	middlewarePath := filepath.Join(dirPath, "middleware.go")
+	routePath := filepath.Join(dirPath, "route.go")
+	for _, path := range []string{middlewarePath, routePath} {
+		if genfs.Exists(fsys, path) {
+			return nil, fmt.Errorf("file already exists: %s", path)
+		}
+	}
+
+	if err := fsys.MkdirAll(dirPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data := webhookTemplateData{
+		Package:  packageNameFromPath(provider),
+		Provider: p.name,
+		Path:     "/webhooks/" + provider,
+		EnvVar:   p.envVar,
+		Verifier: fmt.Sprintf(p.verifier, fmt.Sprintf("os.Getenv(%q)", p.envVar)),
+	}
+	if err := executeTemplate(fsys, middlewarePath, webhookMiddlewareTemplate, data); err != nil {
+		return nil, err
+	}
+	if err := executeTemplate(fsys, routePath, webhookRouteTemplates[p.route], data); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Files:   []string{middlewarePath, routePath},
+		Pattern: data.Path,
+	}, nil
+}
+
 // GenerateProxy generates a proxy.go file.
 func GenerateProxy(cfg ProxyConfig) (*Result, error) {
 	if cfg.AppDir == "" {
@@ -230,14 +473,15 @@ func GenerateProxy(cfg ProxyConfig) (*Result, error) {
 	}
 
 	filePath := filepath.Join(cfg.AppDir, "proxy.go")
+	fsys := genfs.Or(cfg.FS)
 
 	// Create directory
-	if err := os.MkdirAll(cfg.AppDir, 0755); err != nil {
+	if err := fsys.MkdirAll(cfg.AppDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(filePath); err == nil {
+	if genfs.Exists(fsys, filePath) {
 		return nil, fmt.Errorf("file already exists: %s", filePath)
 	}
 
@@ -247,7 +491,7 @@ func GenerateProxy(cfg ProxyConfig) (*Result, error) {
 		return nil, fmt.Errorf("unknown proxy template: %s", cfg.Template)
 	}
 
-	if err := executeTemplate(filePath, tmpl, nil); err != nil {
+	if err := executeTemplate(fsys, filePath, tmpl, nil); err != nil {
 		return nil, err
 	}
 
@@ -270,14 +514,15 @@ func GeneratePage(cfg PageConfig) (*Result, error) {
 		dirPath = cfg.AppDir
 	}
 	pageFilePath := filepath.Join(dirPath, "page.templ")
+	fsys := genfs.Or(cfg.FS)
 
 	// Create directory
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
+	if err := fsys.MkdirAll(dirPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(pageFilePath); err == nil {
+	if genfs.Exists(fsys, pageFilePath) {
 		return nil, fmt.Errorf("file already exists: %s", pageFilePath)
 	}
 
@@ -298,13 +543,13 @@ func GeneratePage(cfg PageConfig) (*Result, error) {
 	// Generate layout if requested
 	if cfg.WithLayout {
 		layoutFilePath := filepath.Join(dirPath, "layout.templ")
-		if _, err := os.Stat(layoutFilePath); os.IsNotExist(err) {
+		if !genfs.Exists(fsys, layoutFilePath) {
 			data := pageTemplateData{
 				Package:  pkgName,
 				Title:    title,
 				FilePath: layoutFilePath,
 			}
-			if err := executeTemplate(layoutFilePath, layoutTemplate, data); err != nil {
+			if err := executeTemplate(fsys, layoutFilePath, layoutTemplate, data); err != nil {
 				return nil, err
 			}
 			files = append(files, layoutFilePath)
@@ -318,7 +563,23 @@ func GeneratePage(cfg PageConfig) (*Result, error) {
 		FilePath: pageFilePath,
 	}
 
-	if err := executeTemplate(pageFilePath, pageTemplate, data); err != nil {
+	tmpl, overridden, err := loadTemplate(resolveTemplateDir(cfg.TemplateDir, cfg.AppDir), PageTemplateName, pageTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := renderTemplate(filepath.Base(pageFilePath), tmpl, nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if overridden {
+		if err := validatePageOutput(PageTemplateName, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeGeneratedFile(fsys, pageFilePath, content); err != nil {
 		return nil, err
 	}
 	files = append(files, pageFilePath)
@@ -334,6 +595,8 @@ type LoaderConfig struct {
 	Path     string // Path relative to app directory (e.g., "dashboard", "users/[id]")
 	DataType string // Name of the data type (e.g., "DashboardData")
 	AppDir   string // App directory (default: "app")
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
 }
 
 // GenerateLoader generates a loader.go file.
@@ -350,14 +613,15 @@ func GenerateLoader(cfg LoaderConfig) (*Result, error) {
 		dirPath = cfg.AppDir
 	}
 	loaderFilePath := filepath.Join(dirPath, "loader.go")
+	fsys := genfs.Or(cfg.FS)
 
 	// Create directory
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
+	if err := fsys.MkdirAll(dirPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(loaderFilePath); err == nil {
+	if genfs.Exists(fsys, loaderFilePath) {
 		return nil, fmt.Errorf("file already exists: %s", loaderFilePath)
 	}
 
@@ -389,7 +653,7 @@ func GenerateLoader(cfg LoaderConfig) (*Result, error) {
 		DataType: dataType,
 	}
 
-	if err := executeTemplate(loaderFilePath, loaderTemplate, data); err != nil {
+	if err := executeTemplate(fsys, loaderFilePath, loaderTemplate, data); err != nil {
 		return nil, err
 	}
 
@@ -449,6 +713,21 @@ func cleanPackageName(name string) string {
 	return strings.ToLower(name)
 }
 
+// isCollectionPath reports whether a route path names a collection, like
+// users or users/[id]/posts, rather than one item, like users/[id]. Route
+// groups and slots don't count as segments.
+func isCollectionPath(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if seg == "" || routeGroupRe.MatchString(seg) || slotSegmentRe.MatchString(seg) {
+			continue
+		}
+		return !dynamicSegmentRe.MatchString(seg) && !catchAllSegmentRe.MatchString(seg) && !optionalCatchAllRe.MatchString(seg)
+	}
+	return false
+}
+
 func extractParams(path string) []ParamInfo {
 	var params []ParamInfo
 	segments := strings.Split(path, "/")
@@ -486,6 +765,7 @@ func extractParams(path string) []ParamInfo {
 			if !isPrivate {
 				params = append(params, ParamInfo{
 					Name: matches[1],
+					Type: scanner.ParamType(matches[2]),
 				})
 			}
 		}
@@ -506,19 +786,19 @@ func pathToPattern(path string) string {
 
 		// Handle optional catch-all [[...param]]
 		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
-			result = append(result, "*")
+			result = append(result, scanner.CatchAllPattern(matches[1], true))
 			continue
 		}
 
 		// Handle catch-all [...param]
 		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			result = append(result, "*")
+			result = append(result, scanner.CatchAllPattern(matches[1], false))
 			continue
 		}
 
 		// Handle dynamic segment [param]
 		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			result = append(result, "{"+matches[1]+"}")
+			result = append(result, scanner.ParamPattern(matches[1], matches[2]))
 			continue
 		}
 
@@ -546,6 +826,154 @@ var routeTemplateFuncs = template.FuncMap{
 		}
 		return strings.Join(args, ", ")
 	},
+	"corsRoutes": func(routes []RouteRegistration) []RouteRegistration {
+		// One route per pattern declaring a CORS policy
+		var cors []RouteRegistration
+		seen := make(map[string]bool)
+		for _, r := range routes {
+			if r.HasCORS && !seen[r.Pattern] {
+				seen[r.Pattern] = true
+				cors = append(cors, r)
+			}
+		}
+		return cors
+	},
+	"headerMap": func(headers map[string]string) string {
+		// Canonical names, sorted so repeated runs produce identical output
+		var pairs []string
+		for key, value := range headers {
+			pairs = append(pairs, fmt.Sprintf("%q: %q", http.CanonicalHeaderKey(key), value))
+		}
+		sort.Strings(pairs)
+		return "map[string]string{" + strings.Join(pairs, ", ") + "}"
+	},
+	"routeMiddleware": func(r RouteRegistration) string {
+		return strings.Join(r.Options.Middleware(), ", ")
+	},
+	"skipMiddleware": func(r RouteRegistration) string {
+		if r.Options == nil {
+			return ""
+		}
+		return quotedList(r.Options.Skip)
+	},
+	"onlyMiddleware": func(r RouteRegistration) string {
+		if r.Options == nil {
+			return ""
+		}
+		return quotedList(r.Options.Only)
+	},
+	"handlerExpr": func(r RouteRegistration) string {
+		handler := r.ImportAlias + "." + r.Handler
+		if r.BodyType != "" {
+			// Decode and validate the typed request body before calling the handler
+			if r.Context {
+				return "nexo.WithBodyContext(" + handler + ")"
+			}
+			return "nexo.WithBody(" + handler + ")"
+		}
+		if len(r.Deps) > 0 {
+			// Resolve dependencies from the app container at registration time
+			if r.Context {
+				return fmt.Sprintf("nexo.InjectContext%d(app, %s)", len(r.Deps), handler)
+			}
+			return fmt.Sprintf("nexo.Inject%d(app, %s)", len(r.Deps), handler)
+		}
+		if r.Context {
+			return "nexo.WithContext(" + handler + ")"
+		}
+		return handler
+	},
+	"renderPage": func(p PageRegistration, comp string, indent int) string {
+		if len(p.Segments) == 0 {
+			return "nexo.TemplComponent(c, 200, " + comp + ")"
+		}
+		return "nexo.RenderPage(c, 200, " + comp + "," + segmentList(p.Segments, indent) + ")"
+	},
+	"pageCall": pageCall,
+	"markdownPage": func(c ContentRegistration, indent int) string {
+		if len(c.Segments) == 0 {
+			return "app.MarkdownPage(" + strconv.Quote(c.FilePath) + ")"
+		}
+		return "app.MarkdownPage(" + strconv.Quote(c.FilePath) + "," + segmentList(c.Segments, indent) + ")"
+	},
+	"sitemapOptions": func(p PageRegistration) string {
+		var fields []string
+		if p.Sitemap.ChangeFreq != "" {
+			fields = append(fields, "ChangeFreq: "+strconv.Quote(p.Sitemap.ChangeFreq))
+		}
+		if p.Sitemap.Priority != "" {
+			fields = append(fields, "Priority: "+p.Sitemap.Priority)
+		}
+		if len(p.URLParams) > 0 {
+			fields = append(fields, "Params: "+p.ImportAlias+".GenerateStaticParams")
+		}
+		return "nexo.SitemapOptions{" + strings.Join(fields, ", ") + "}"
+	},
+	"loaderExpr": func(p PageRegistration) string {
+		loader := p.ImportAlias + ".Loader"
+		if len(p.LoaderDeps) > 0 {
+			if p.LoaderContext {
+				return fmt.Sprintf("nexo.InjectLoaderContext%d(app, %s)", len(p.LoaderDeps), loader)
+			}
+			return fmt.Sprintf("nexo.InjectLoader%d(app, %s)", len(p.LoaderDeps), loader)
+		}
+		if p.LoaderContext {
+			return "nexo.WithLoaderContext(" + loader + ")"
+		}
+		return loader
+	},
+}
+
+// segmentList renders nexo.LayoutSegment arguments, one per line, for a call
+// indented by indent tabs.
+func segmentList(segments []PageSegment, indent int) string {
+	tabs := strings.Repeat("\t", indent)
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, seg := range segments {
+		var fields []string
+		if seg.Layout {
+			adapter := "nexo.TemplLayoutNoTitle"
+			if seg.LayoutTitle {
+				adapter = "nexo.TemplLayout"
+			}
+			fields = append(fields, "Layout: "+adapter+"("+seg.ImportAlias+".Layout)")
+		}
+		if seg.Metadata {
+			fields = append(fields, "Metadata: &"+seg.ImportAlias+".Metadata")
+		}
+		if seg.GenerateMetadata {
+			fields = append(fields, "GenerateMetadata: "+seg.ImportAlias+".GenerateMetadata")
+		}
+		if len(seg.Slots) > 0 {
+			var slots []string
+			for _, slot := range seg.Slots {
+				slots = append(slots, strconv.Quote(slot.Slot)+": "+pageCall(slot))
+			}
+			fields = append(fields, "Slots: nexo.Slots{"+strings.Join(slots, ", ")+"}")
+		}
+		b.WriteString(tabs + "\tnexo.LayoutSegment{" + strings.Join(fields, ", ") + "},\n")
+	}
+	b.WriteString(tabs)
+	return b.String()
+}
+
+// pageCall renders the component of a slot or intercepting page, reading
+// its URL parameters from c: Page(c.Param("id")), or Default() for a slot's
+// default.templ.
+func pageCall(p PageRegistration) string {
+	if p.Default {
+		return p.ImportAlias + ".Default()"
+	}
+	var args []string
+	for _, param := range p.Params {
+		if param.FromPath {
+			args = append(args, param.Value())
+		} else {
+			args = append(args, zeroValue(param.Type))
+		}
+	}
+	return p.ImportAlias + ".Page(" + strings.Join(args, ", ") + ")"
 }
 
 // zeroValue returns the zero value literal for a Go type.
@@ -569,42 +997,46 @@ func zeroValue(typeName string) string {
 	}
 }
 
-func executeTemplate(filePath, tmplContent string, data any) error {
-	tmpl, err := template.New(filepath.Base(filePath)).Parse(tmplContent)
+// executeTemplate renders a template and writes the output to filePath in
+// fsys.
+func executeTemplate(fsys genfs.WriteFS, filePath, tmplContent string, data any) error {
+	content, err := renderTemplate(filepath.Base(filePath), tmplContent, nil, data)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return err
 	}
+	return writeGeneratedFile(fsys, filePath, content)
+}
 
-	f, err := os.Create(filePath)
+// renderTemplate parses and executes a template in memory. Go output is
+// formatted, with its imports fixed, by gosource.Format.
+func renderTemplate(name, tmplContent string, funcs template.FuncMap, data any) ([]byte, error) {
+	tmpl := template.New(name)
+	if funcs != nil {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	tmpl, err := tmpl.Parse(tmplContent)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
-	defer func() { _ = f.Close() }()
 
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return nil
-}
-
-// executeRouteTemplate executes a template with route-specific functions.
-func executeRouteTemplate(filePath, tmplContent string, data any) error {
-	tmpl, err := template.New(filepath.Base(filePath)).Funcs(routeTemplateFuncs).Parse(tmplContent)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+	if filepath.Ext(name) == ".go" {
+		return gosource.Format(name, buf.Bytes())
 	}
+	return buf.Bytes(), nil
+}
 
-	f, err := os.Create(filePath)
-	if err != nil {
+// writeGeneratedFile writes rendered template output to fsys. A file that
+// already holds content is left untouched, so regenerating without changes
+// doesn't dirty git or wake file watchers.
+func writeGeneratedFile(fsys genfs.WriteFS, filePath string, content []byte) error {
+	if err := writeIfChanged(fsys, filePath, content); err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
-	defer func() { _ = f.Close() }()
-
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
-	}
-
 	return nil
 }
 
@@ -632,13 +1064,22 @@ func toTitleCase(s string) string {
 
 // RouteRegistration holds information needed to generate route registration code.
 type RouteRegistration struct {
-	ImportPath  string // Full import path for the package
-	ImportAlias string // Alias for the import (to avoid conflicts)
-	Package     string // Package name
-	Method      string // HTTP method (GET, POST, etc.)
-	Pattern     string // Route pattern (/api/users/{id})
-	Handler     string // Handler function name (Get, Post, etc.)
-	FilePath    string // Source file path (for comments)
+	ImportPath  string   // Full import path for the package
+	ImportAlias string   // Alias for the import (to avoid conflicts)
+	Package     string   // Package name
+	Method      string   // HTTP method (GET, POST, etc.)
+	Pattern     string   // Route pattern (/api/users/{id})
+	Handler     string   // Handler function name (Get, Post, etc.)
+	FilePath    string   // Source file path (for comments)
+	HasConfig   bool     // Whether the file declares a RouteConfig variable
+	HasCORS     bool     // Whether the file declares a CORS variable
+	BodyType    string   // Request body type for func(c *nexo.Context, body T) error handlers
+	Deps        []string // Canonical types of injected handler dependencies (see app.Provide)
+	Context     bool     // Whether the handler takes a context.Context first
+	Priority    int      // Priority override from a nexo:priority directive
+	HasPriority bool     // Whether Priority is set
+
+	Options *scanner.RouteOptions // Options of a nexo:route directive
 }
 
 // MiddlewareRegistration holds information for middleware registration.
@@ -647,7 +1088,9 @@ type MiddlewareRegistration struct {
 	ImportAlias string // Alias for the import
 	Package     string // Package name
 	PathPrefix  string // Path prefix the middleware applies to
+	Scope       string // Filesystem scope, preserving route groups
 	FilePath    string // Source file path
+	Name        string // Registry name, for middleware used with nexo:use
 }
 
 // ProxyRegistration holds information for proxy registration.
@@ -659,6 +1102,28 @@ type ProxyRegistration struct {
 	HasConfig   bool   // Whether ProxyConfig is defined
 }
 
+// MaintenanceRegistration holds information for the maintenance page,
+// discovered from app/maintenance.templ.
+type MaintenanceRegistration struct {
+	ImportPath  string // Full import path
+	ImportAlias string // Alias for the import
+	Package     string // Package name
+	FilePath    string // Source file path (maintenance.templ)
+}
+
+// GraphQLRegistration holds information for a GraphQL endpoint, discovered
+// from a directory containing schema.graphql and resolver.go.
+type GraphQLRegistration struct {
+	ImportPath  string // Full import path
+	ImportAlias string // Alias for the import
+	Package     string // Package name
+	Pattern     string // URL pattern (e.g., "/graphql")
+	FilePath    string // Source file path (resolver.go)
+	SchemaPath  string // Path to schema.graphql
+	SchemaEmbed string // Schema path relative to the routes file, for go:embed (set during generation)
+	SchemaVar   string // Name of the embedded schema variable (set during generation)
+}
+
 // PageParam represents a parameter in a Page() templ function.
 type PageParam struct {
 	Name     string // Parameter name (e.g., "slug")
@@ -666,6 +1131,33 @@ type PageParam struct {
 	FromPath bool   // True if this param comes from URL path
 }
 
+// Value returns the expression that reads the parameter from the URL,
+// converted to its type: c.ParamInt("id", 0) for Page(id int) and
+// c.ParamAll("slug") for the segments of a catch-all, Page(slug []string).
+func (p PageParam) Value() string {
+	switch p.Type {
+	case "[]string":
+		return "c.ParamAll(" + strconv.Quote(p.Name) + ")"
+	case "int":
+		return paramValue(p.Name, scanner.ParamInt)
+	case "uuid.UUID":
+		return paramValue(p.Name, scanner.ParamUUID)
+	}
+	return paramValue(p.Name, "")
+}
+
+// paramValue returns the expression that reads the URL parameter name of
+// type typ (see scanner.ParamType).
+func paramValue(name, typ string) string {
+	switch typ {
+	case scanner.ParamInt:
+		return "c.ParamInt(" + strconv.Quote(name) + ", 0)"
+	case scanner.ParamUUID:
+		return "c.ParamUUID(" + strconv.Quote(name) + ")"
+	}
+	return "c.Param(" + strconv.Quote(name) + ")"
+}
+
 // PageRegistration holds information for page registration.
 type PageRegistration struct {
 	ImportPath  string // Full import path for the generated _templ.go package
@@ -682,9 +1174,62 @@ type PageRegistration struct {
 	ParamSignature string      // Original signature from templ file (for comments)
 
 	// Data loader support
-	HasLoader        bool   // True if a loader.go exists in the same directory
-	LoaderImportPath string // Import path for the loader
-	LoaderPackage    string // Package name for the loader
+	HasLoader        bool     // True if a loader.go exists in the same directory
+	LoaderImportPath string   // Import path for the loader
+	LoaderPackage    string   // Package name for the loader
+	LoaderFilePath   string   // Source file path (loader.go)
+	LoaderDeps       []string // Canonical types of injected loader dependencies
+	LoaderContext    bool     // True if the loader takes a context.Context first
+
+	// Nested layout support
+	SelfLayout bool          // True if Page() renders @Layout itself
+	Segments   []PageSegment // Layouts and metadata wrapping the page, root first
+
+	// Sitemap support
+	Sitemap PageSitemap // Annotations from a nexo:sitemap directive
+
+	// Parallel and intercepting route support
+	Slot          string // Slot name for pages in @slot directories (e.g., "team")
+	SlotParent    string // Directory whose layout renders the slot
+	Default       bool   // default.templ rendered when no page in the slot matches
+	InterceptFrom string // Pattern navigation is intercepted from, for pages in (..)dir directories
+}
+
+// PageSitemap holds the sitemap settings of a page.
+type PageSitemap struct {
+	Exclude      bool   // Leave the page out of the sitemap
+	ChangeFreq   string // changefreq annotation
+	Priority     string // priority annotation, as written
+	StaticParams bool   // Package declares func GenerateStaticParams
+}
+
+// Listed reports whether the page appears in the sitemap: static pages, and
+// dynamic pages whose package lists their parameters.
+func (p PageRegistration) Listed() bool {
+	return !p.Sitemap.Exclude && (len(p.URLParams) == 0 || p.Sitemap.StaticParams)
+}
+
+// PageSegment is a directory between the app root and a page that
+// contributes a layout or metadata to it.
+type PageSegment struct {
+	ImportPath       string // Full import path of the directory's package
+	ImportAlias      string // Alias for the import (set during generation)
+	Package          string // Package name
+	Layout           bool   // Wrap the page in the directory's Layout
+	LayoutTitle      bool   // Layout takes the page title
+	Metadata         bool   // Package declares var Metadata
+	GenerateMetadata bool   // Package declares func GenerateMetadata
+
+	Slots []PageRegistration // Parallel route slot pages rendered into the layout, by name
+}
+
+// ContentRegistration holds information for a Markdown content page.
+type ContentRegistration struct {
+	Pattern  string        // Route pattern (e.g., "/blog/hello")
+	FilePath string        // Source file path, slash-separated (e.g., "content/blog/hello.md")
+	Title    string        // Title from the front matter
+	Draft    bool          // Front matter marks the page as a draft
+	Segments []PageSegment // Layouts and metadata wrapping the page, root first
 }
 
 // LayoutRegistration holds information for layout registration.
@@ -694,6 +1239,8 @@ type LayoutRegistration struct {
 	Package     string // Package name
 	PathPrefix  string // Path prefix this layout applies to
 	FilePath    string // Source file path (layout.templ)
+	TakesTitle  bool   // Layout(title string) rather than Layout()
+	Nestable    bool   // Signature can be wrapped around pages by generated code
 }
 
 // RoutesGenConfig holds configuration for generating the routes file.
@@ -704,9 +1251,31 @@ type RoutesGenConfig struct {
 	Routes      []RouteRegistration      // Discovered routes
 	Middlewares []MiddlewareRegistration // Discovered middlewares
 	Proxy       *ProxyRegistration       // Discovered proxy (optional)
+	Maintenance *MaintenanceRegistration // Discovered maintenance page (optional)
 	Pages       []PageRegistration       // Discovered pages
 	Layouts     []LayoutRegistration     // Discovered layouts
 	Loaders     []LoaderRegistration     // Discovered data loaders
+	GraphQL     []GraphQLRegistration    // Discovered GraphQL endpoints
+	Content     []ContentRegistration    // Discovered Markdown content pages
+	Intercepts  []PageRegistration       // Discovered pages in intercepting directories
+	Headers     []nexo.HeaderRule        // Response header rules (from headers: in nexo.yaml)
+	TemplateDir string                   // Template override directory (default: .nexo/templates next to AppDir)
+
+	// LocalizePages emits app.LocalizeRoutes for every page, so locale
+	// routing can serve them under /{locale}. Set when the project has a
+	// locales/ directory with catalogs.
+	LocalizePages bool
+
+	// Split writes the registrations under each top-level URL segment to
+	// their own file next to OutputPath, e.g. nexo_routes_users.go for
+	// /users and /api/users, called from the RegisterRoutes of OutputPath.
+	// Set by generate.split_routes in nexo.yaml.
+	Split bool
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
+
+	section  *routesSection  // the section a split file registers
+	sections []routesSection // the sections the aggregating file calls
 }
 
 // GenerateRoutesFile generates the nexo_routes.go file that registers all routes.
@@ -714,19 +1283,54 @@ func GenerateRoutesFile(cfg RoutesGenConfig) (*Result, error) {
 	if cfg.OutputPath == "" {
 		cfg.OutputPath = "nexo_routes.go"
 	}
+	if cfg.AppDir == "" {
+		cfg.AppDir = "app"
+	}
+	cfg.TemplateDir = resolveTemplateDir(cfg.TemplateDir, cfg.AppDir)
+
+	cfg.FS = genfs.Or(cfg.FS)
+
+	if cfg.Split {
+		return generateSplitRoutesFiles(cfg)
+	}
+
+	content, err := renderRoutesFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeGeneratedFile(cfg.FS, cfg.OutputPath, content); err != nil {
+		return nil, err
+	}
+	if err := removeStaleSections(cfg.FS, cfg.OutputPath, nil); err != nil {
+		return nil, err
+	}
+
+	return &Result{Files: []string{cfg.OutputPath}}, nil
+}
 
+// renderRoutesFile renders the routes file for cfg without touching disk.
+// cfg.TemplateDir is used as-is; an empty value disables overrides.
+func renderRoutesFile(cfg RoutesGenConfig) ([]byte, error) {
 	// Check if we have any routes to register
-	if len(cfg.Routes) == 0 && len(cfg.Middlewares) == 0 && cfg.Proxy == nil && len(cfg.Pages) == 0 && len(cfg.Layouts) == 0 {
+	if len(cfg.Routes) == 0 && len(cfg.Middlewares) == 0 && cfg.Proxy == nil && cfg.Maintenance == nil && len(cfg.Headers) == 0 && len(cfg.Pages) == 0 && len(cfg.Layouts) == 0 && len(cfg.GraphQL) == 0 && len(cfg.Intercepts) == 0 && len(cfg.sections) == 0 {
 		// No routes found, create a minimal file
-		if err := executeTemplate(cfg.OutputPath, emptyRoutesTemplate, nil); err != nil {
+		content, err := renderTemplate("nexo_routes.go", emptyRoutesTemplate, nil, nil)
+		if err != nil {
 			return nil, err
 		}
-		return &Result{Files: []string{cfg.OutputPath}}, nil
+		return stampContentHash(content), nil
 	}
 
+	// Register everything in a fixed order, whatever order the scan found
+	// it in, so aliases and blocks don't move between runs
+	sortRegistrations(&cfg)
+
 	// Group routes by import path to avoid duplicate imports
 	imports := make(map[string]string) // importPath -> alias
-	aliasCounter := make(map[string]int)
+	// "app" and "nexo" are taken by the RegisterRoutes parameter and the
+	// runtime import, so the root app package is imported as app2.
+	aliasCounter := map[string]int{"app": 1, "nexo": 1}
 
 	for i := range cfg.Routes {
 		r := &cfg.Routes[i]
@@ -773,9 +1377,35 @@ func GenerateRoutesFile(cfg RoutesGenConfig) (*Result, error) {
 		cfg.Proxy.ImportAlias = imports[cfg.Proxy.ImportPath]
 	}
 
-	// Handle page imports
+	if cfg.Maintenance != nil {
+		if _, ok := imports[cfg.Maintenance.ImportPath]; !ok {
+			alias := cfg.Maintenance.Package
+			if count, exists := aliasCounter[alias]; exists {
+				aliasCounter[alias] = count + 1
+				alias = fmt.Sprintf("%s%d", alias, count+1)
+			} else {
+				aliasCounter[alias] = 1
+			}
+			imports[cfg.Maintenance.ImportPath] = alias
+		}
+		cfg.Maintenance.ImportAlias = imports[cfg.Maintenance.ImportPath]
+	}
+
+	// Handle page imports, including pages rendered into slots and
+	// intercepted routes
+	var pages []*PageRegistration
 	for i := range cfg.Pages {
-		p := &cfg.Pages[i]
+		pages = append(pages, &cfg.Pages[i])
+		for j := range cfg.Pages[i].Segments {
+			for k := range cfg.Pages[i].Segments[j].Slots {
+				pages = append(pages, &cfg.Pages[i].Segments[j].Slots[k])
+			}
+		}
+	}
+	for i := range cfg.Intercepts {
+		pages = append(pages, &cfg.Intercepts[i])
+	}
+	for _, p := range pages {
 		if _, ok := imports[p.ImportPath]; !ok {
 			alias := p.Package + "_page"
 			if count, exists := aliasCounter[alias]; exists {
@@ -789,19 +1419,78 @@ func GenerateRoutesFile(cfg RoutesGenConfig) (*Result, error) {
 		p.ImportAlias = imports[p.ImportPath]
 	}
 
-	// Build import list
-	// Note: Layout imports are NOT included here because layouts are used by templ pages
-	// via @Layout() syntax, and templ handles the dependency automatically.
-	type importEntry struct {
-		Alias string
-		Path  string
+	// Handle layout and metadata imports of nested pages and content pages
+	var segments []*PageSegment
+	for i := range cfg.Pages {
+		for j := range cfg.Pages[i].Segments {
+			segments = append(segments, &cfg.Pages[i].Segments[j])
+		}
 	}
-	var importList []importEntry
-	for path, alias := range imports {
-		importList = append(importList, importEntry{Alias: alias, Path: path})
+	for i := range cfg.Content {
+		for j := range cfg.Content[i].Segments {
+			segments = append(segments, &cfg.Content[i].Segments[j])
+		}
 	}
-
-	// Check if we need templ import
+	for _, seg := range segments {
+		if _, ok := imports[seg.ImportPath]; !ok {
+			alias := seg.Package + "_layout"
+			if count, exists := aliasCounter[alias]; exists {
+				aliasCounter[alias] = count + 1
+				alias = fmt.Sprintf("%s%d", alias, count+1)
+			} else {
+				aliasCounter[alias] = 1
+			}
+			imports[seg.ImportPath] = alias
+		}
+		seg.ImportAlias = imports[seg.ImportPath]
+	}
+
+	// Handle GraphQL resolver imports and schema embedding. The files of a
+	// split routes file share package main, so their variables can't clash.
+	hasEmbed := false
+	schemaVarSuffix := ""
+	if cfg.section != nil {
+		schemaVarSuffix = strings.TrimSuffix(strings.TrimPrefix(cfg.section.FuncName, "register"), "Routes")
+	}
+	for i := range cfg.GraphQL {
+		g := &cfg.GraphQL[i]
+		if _, ok := imports[g.ImportPath]; !ok {
+			alias := g.Package
+			if count, exists := aliasCounter[alias]; exists {
+				aliasCounter[alias] = count + 1
+				alias = fmt.Sprintf("%s%d", alias, count+1)
+			} else {
+				aliasCounter[alias] = 1
+			}
+			imports[g.ImportPath] = alias
+		}
+		g.ImportAlias = imports[g.ImportPath]
+
+		// go:embed only accepts paths below the generated file's directory
+		if rel, err := filepath.Rel(filepath.Dir(cfg.OutputPath), g.SchemaPath); err == nil && !strings.HasPrefix(rel, "..") {
+			g.SchemaEmbed = filepath.ToSlash(rel)
+			g.SchemaVar = fmt.Sprintf("graphQLSchema%s%d", schemaVarSuffix, i)
+			hasEmbed = true
+		}
+	}
+
+	// Build import list
+	// Note: Layouts rendered by the page itself via @Layout() are not imported;
+	// templ handles that dependency. Only nested segments above need imports.
+	type importEntry struct {
+		Alias string
+		Path  string
+	}
+	var importList []importEntry
+	for path, alias := range imports {
+		importList = append(importList, importEntry{Alias: alias, Path: path})
+	}
+	// Sort imports so repeated runs produce identical output
+	sort.Slice(importList, func(i, j int) bool {
+		return importList[i].Path < importList[j].Path
+	})
+
+	// Check if we need templ import
 	hasPages := len(cfg.Pages) > 0
 
 	data := struct {
@@ -809,33 +1498,108 @@ func GenerateRoutesFile(cfg RoutesGenConfig) (*Result, error) {
 		Routes      []RouteRegistration
 		Middlewares []MiddlewareRegistration
 		Proxy       *ProxyRegistration
+		Maintenance *MaintenanceRegistration
 		Pages       []PageRegistration
+		Content     []ContentRegistration
+		Intercepts  []PageRegistration
+		GraphQL     []GraphQLRegistration
+		Headers     []nexo.HeaderRule
 		HasPages    bool
+		HasEmbed    bool
+		HasTime     bool
+		Localize    bool
+		Section     string
+		FuncName    string
+		Sections    []routesSection
 	}{
 		Imports:     importList,
 		Routes:      cfg.Routes,
 		Middlewares: cfg.Middlewares,
 		Proxy:       cfg.Proxy,
+		Maintenance: cfg.Maintenance,
 		Pages:       cfg.Pages,
+		Content:     cfg.Content,
+		Intercepts:  cfg.Intercepts,
+		GraphQL:     cfg.GraphQL,
+		Headers:     cfg.Headers,
 		HasPages:    hasPages,
+		HasEmbed:    hasEmbed,
+		HasTime:     slices.ContainsFunc(cfg.Routes, func(r RouteRegistration) bool { return r.Options.NeedsTime() }),
+		Localize:    cfg.LocalizePages && hasPages,
+		Sections:    cfg.sections,
+	}
+	if cfg.section != nil {
+		data.Section = cfg.section.Name
+		data.FuncName = cfg.section.FuncName
 	}
 
-	if err := executeRouteTemplate(cfg.OutputPath, routesGenTemplate, data); err != nil {
+	tmpl, overridden, err := loadTemplate(cfg.TemplateDir, RoutesGenTemplateName, routesGenTemplate)
+	if err != nil {
 		return nil, err
 	}
 
-	return &Result{Files: []string{cfg.OutputPath}}, nil
+	content, err := renderTemplate("nexo_routes.go", tmpl, routeTemplateFuncs, data)
+	if err != nil {
+		return nil, overrideError(RoutesGenTemplateName, overridden, err)
+	}
+	return stampContentHash(content), nil
+}
+
+// sortRegistrations orders the registrations of cfg by pattern, then by
+// method and source file.
+func sortRegistrations(cfg *RoutesGenConfig) {
+	slices.SortStableFunc(cfg.Routes, func(a, b RouteRegistration) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.Method, b.Method), cmp.Compare(a.FilePath, b.FilePath))
+	})
+	slices.SortStableFunc(cfg.Middlewares, func(a, b MiddlewareRegistration) int {
+		if c := cmp.Compare(a.PathPrefix, b.PathPrefix); c != 0 {
+			return c
+		}
+		// Named middleware runs before the directory's own, in the order
+		// of its nexo:use directive
+		switch {
+		case a.Name != "" && b.Name != "":
+			return 0
+		case a.Name != "":
+			return -1
+		case b.Name != "":
+			return 1
+		}
+		return cmp.Compare(a.FilePath, b.FilePath)
+	})
+	byPattern := func(a, b PageRegistration) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.FilePath, b.FilePath))
+	}
+	slices.SortStableFunc(cfg.Pages, byPattern)
+	slices.SortStableFunc(cfg.Intercepts, byPattern)
+	slices.SortStableFunc(cfg.Layouts, func(a, b LayoutRegistration) int {
+		return cmp.Or(cmp.Compare(a.PathPrefix, b.PathPrefix), cmp.Compare(a.FilePath, b.FilePath))
+	})
+	slices.SortStableFunc(cfg.Content, func(a, b ContentRegistration) int {
+		return cmp.Compare(a.Pattern, b.Pattern)
+	})
+	slices.SortStableFunc(cfg.GraphQL, func(a, b GraphQLRegistration) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.FilePath, b.FilePath))
+	})
 }
 
-// HTTP method to function name mapping
-var httpMethods = map[string]string{
-	"Get":     http.MethodGet,
-	"Post":    http.MethodPost,
-	"Put":     http.MethodPut,
-	"Patch":   http.MethodPatch,
-	"Delete":  http.MethodDelete,
-	"Head":    http.MethodHead,
-	"Options": http.MethodOptions,
+// contentHashPrefix starts the header line of the routes file recording
+// the hash of its content.
+const contentHashPrefix = "// Content hash: "
+
+// stampContentHash adds a line with the SHA-256 of content to the end of
+// its leading comment, so a routes file can be compared with a fresh scan
+// by its header alone.
+func stampContentHash(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	line := contentHashPrefix + "sha256:" + hex.EncodeToString(sum[:16]) + "\n"
+
+	// The header comment ends at the first blank line
+	at := bytes.Index(content, []byte("\n\n")) + 1
+	if at == 0 || !bytes.HasPrefix(content, []byte("//")) {
+		at = 0
+	}
+	return slices.Concat(content[:at], []byte(line), content[at:])
 }
 
 // GenerationWarning represents a warning during route generation.
@@ -846,26 +1610,32 @@ type GenerationWarning struct {
 
 // LoaderRegistration holds information for a data loader.
 type LoaderRegistration struct {
-	ImportPath  string // Full import path
-	ImportAlias string // Alias for the import
-	Package     string // Package name
-	FilePath    string // Source file path (loader.go)
-	ReturnType  string // Return type of the Loader function
-	Dir         string // Directory containing the loader
+	ImportPath  string   // Full import path
+	ImportAlias string   // Alias for the import
+	Package     string   // Package name
+	FilePath    string   // Source file path (loader.go)
+	ReturnType  string   // Return type of the Loader function
+	Dir         string   // Directory containing the loader
+	Deps        []string // Canonical types of injected dependencies
+	Context     bool     // Whether the loader takes a context.Context first
 }
 
-// RouteConflict represents a conflict between page.templ and route.go
+// RouteConflict represents a conflict between page.templ and a route file
 type RouteConflict struct {
 	Directory   string
 	PageFile    string
-	RouteFile   string
+	RouteFile   string // The route file declaring Get()
 	Pattern     string
-	HasRouteGet bool // True if route.go has a Get() handler
+	HasRouteGet bool // True if a route file has a Get() handler
 }
 
 // ScanAndGenerateRoutes scans the app directory and generates the routes file.
 func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
-	// Get the module name from go.mod
+	// The app must be in the module of the working directory, whose import
+	// path its packages are imported by
+	if err := scanner.CheckAppModule(cmp.Or(appDir, "app")); err != nil {
+		return nil, err
+	}
 	moduleName, err := getModuleName()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get module name: %w", err)
@@ -875,10 +1645,14 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 		appDir = "app"
 	}
 
+	project := projectConfig(appDir)
 	cfg := RoutesGenConfig{
-		ModuleName: moduleName,
-		AppDir:     appDir,
-		OutputPath: outputPath,
+		ModuleName:    moduleName,
+		AppDir:        appDir,
+		OutputPath:    outputPath,
+		LocalizePages: hasLocaleCatalogs(filepath.Join(filepath.Dir(appDir), "locales")),
+		Split:         project.Generate.SplitRoutes,
+		Headers:       project.Headers,
 	}
 
 	// Check if app directory exists
@@ -886,20 +1660,29 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 		return GenerateRoutesFile(cfg)
 	}
 
-	// With Next.js-style naming ([id], [...slug], (group)), directories need bracket/paren characters removed for Go packages
-	// No symlinks or import sanitization needed
+	// With Next.js-style naming ([id], [...slug], (group)), directories are
+	// imported through their wrapper packages in .nexo/generated/wrappers
+	if err := SyncAppWrappers(appDir); err != nil {
+		return nil, fmt.Errorf("failed to generate wrapper packages: %w", err)
+	}
 
 	fset := token.NewFileSet()
 
 	var warnings []GenerationWarning
 	var conflicts []RouteConflict
 
-	// Track which directories have route.go with Get() handlers
+	// Track which directories have route files with Get() handlers
 	routeGetHandlers := make(map[string]bool) // dir -> hasGetHandler
+	routeGetFiles := make(map[string]string)  // dir -> file declaring Get()
 	// Track which directories have loaders
 	loaderDirs := make(map[string]*LoaderRegistration)
+	// Pages and defaults in @slot directories, rendered into layouts
+	var slots []PageRegistration
+	// Named middleware listed by nexo:use directives, by directory
+	uses := make(map[string][]string)
+	var useDirs []string
 
-	// First pass: scan route.go and loader.go files to detect conflicts
+	// First pass: scan route files and loader.go files to detect conflicts
 	err = filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -911,16 +1694,19 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 
 		dir := filepath.Dir(path)
 
-		switch info.Name() {
-		case "route.go":
-			// Check if this route.go has a Get() handler
+		switch name := info.Name(); {
+		case scanner.IsRouteFile(name):
+			// Check if this route file has a Get() handler
 			hasGet, err := routeFileHasGetHandler(path)
 			if err != nil {
 				return nil // Continue scanning even if we can't parse this file
 			}
-			routeGetHandlers[dir] = hasGet
+			routeGetHandlers[dir] = routeGetHandlers[dir] || hasGet
+			if hasGet {
+				routeGetFiles[dir] = path
+			}
 
-		case "loader.go":
+		case name == "loader.go":
 			// Scan for Loader() function
 			loader, err := scanLoaderFile(fset, path, appDir, moduleName)
 			if err != nil {
@@ -956,19 +1742,46 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 			return filepath.SkipDir
 		}
 
+		// Skip typed parameters with a bad constraint, like [id:float]
 		if info.IsDir() {
+			if seg := scanner.ParseSegment(info.Name()); seg.Type == scanner.SegmentDynamic {
+				if err := scanner.ValidateConstraint(seg.Constraint); err != nil {
+					warnings = append(warnings, GenerationWarning{
+						File:    path,
+						Message: fmt.Sprintf("%s: %v; directory skipped", info.Name(), err),
+					})
+					return filepath.SkipDir
+				}
+			}
 			return nil
 		}
 
-		switch info.Name() {
-		case "route.go":
+		// Any Go file of a directory may name the middleware it uses
+		if name := info.Name(); strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			names, err := scanUseDirective(fset, path)
+			if err != nil {
+				return err
+			}
+			dir := filepath.Dir(path)
+			if len(names) > 0 && uses[dir] == nil {
+				useDirs = append(useDirs, dir)
+			}
+			for _, name := range names {
+				if !slices.Contains(uses[dir], name) {
+					uses[dir] = append(uses[dir], name)
+				}
+			}
+		}
+
+		switch name := info.Name(); {
+		case scanner.IsRouteFile(name):
 			routes, err := scanRouteFile(fset, path, appDir, moduleName)
 			if err != nil {
 				return err
 			}
 			cfg.Routes = append(cfg.Routes, routes...)
 
-		case "middleware.go":
+		case name == "middleware.go":
 			mw, err := scanMiddlewareFile(fset, path, appDir, moduleName)
 			if err != nil {
 				return err
@@ -977,7 +1790,7 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 				cfg.Middlewares = append(cfg.Middlewares, *mw)
 			}
 
-		case "proxy.go":
+		case name == "proxy.go":
 			// Only handle proxy.go in app root
 			if filepath.Dir(path) == appDir {
 				proxy, err := scanProxyFile(fset, path, moduleName)
@@ -987,14 +1800,24 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 				cfg.Proxy = proxy
 			}
 
-		case "loader.go":
+		case name == "maintenance.templ":
+			// Only handle maintenance.templ in app root
+			if filepath.Dir(path) == appDir {
+				page, err := scanMaintenanceFile(path, moduleName)
+				if err != nil {
+					return err
+				}
+				cfg.Maintenance = page
+			}
+
+		case name == "loader.go":
 			// Already scanned in first pass, add to config
 			dir := filepath.Dir(path)
 			if loader, ok := loaderDirs[dir]; ok {
 				cfg.Loaders = append(cfg.Loaders, *loader)
 			}
 
-		case "page.templ":
+		case name == "page.templ":
 			dir := filepath.Dir(path)
 			page, err := scanPageFile(path, appDir, moduleName)
 			if err != nil {
@@ -1004,16 +1827,38 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 				return nil
 			}
 
-			// Check for conflict with route.go
-			routeGoPath := filepath.Join(dir, "route.go")
+			// Pages in intercepting (..)dir and @slot directories render
+			// into other pages instead of registering their own route
+			from, intercepting := interceptFrom(dir, appDir)
+			slot, slotParent, inSlot := slotDir(dir, appDir)
+			if intercepting || inSlot {
+				warnings = append(warnings, validatePageParams(page)...)
+				if hasComplexParams(page.Params) {
+					warnings = append(warnings, GenerationWarning{
+						File:    path,
+						Message: fmt.Sprintf("Page has complex parameters %s, but slot and intercepting pages only take URL parameters.", page.ParamSignature),
+					})
+					return nil
+				}
+				if intercepting {
+					page.InterceptFrom = from
+					cfg.Intercepts = append(cfg.Intercepts, *page)
+				} else {
+					page.Slot, page.SlotParent = slot, slotParent
+					slots = append(slots, *page)
+				}
+				return nil
+			}
+
+			// Check for conflict with route files
 			if hasGetHandler, hasRouteGo := routeGetHandlers[dir]; hasRouteGo {
 				if hasGetHandler {
-					// Conflict: route.go has Get() handler, page.templ would also register GET
+					// Conflict: a route file has a Get() handler, page.templ would also register GET
 					// page.templ takes precedence, but warn about the conflict
 					conflicts = append(conflicts, RouteConflict{
 						Directory:   dir,
 						PageFile:    path,
-						RouteFile:   routeGoPath,
+						RouteFile:   routeGetFiles[dir],
 						Pattern:     page.Pattern,
 						HasRouteGet: true,
 					})
@@ -1030,6 +1875,9 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 				page.HasLoader = true
 				page.LoaderImportPath = loader.ImportPath
 				page.LoaderPackage = loader.Package
+				page.LoaderFilePath = loader.FilePath
+				page.LoaderDeps = loader.Deps
+				page.LoaderContext = loader.Context
 			}
 
 			// Check for parameter mismatches and add warnings
@@ -1050,7 +1898,16 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 
 			cfg.Pages = append(cfg.Pages, *page)
 
-		case "layout.templ":
+		case name == "default.templ":
+			def, err := scanSlotDefault(path, appDir, moduleName)
+			if err != nil {
+				return err
+			}
+			if def != nil {
+				slots = append(slots, *def)
+			}
+
+		case name == "layout.templ":
 			layout, err := scanLayoutFile(path, appDir, moduleName)
 			if err != nil {
 				return err
@@ -1058,6 +1915,15 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 			if layout != nil {
 				cfg.Layouts = append(cfg.Layouts, *layout)
 			}
+
+		case name == "resolver.go":
+			gql, err := scanGraphQLResolver(fset, path, appDir, moduleName)
+			if err != nil {
+				return err
+			}
+			if gql != nil {
+				cfg.GraphQL = append(cfg.GraphQL, *gql)
+			}
 		}
 
 		return nil
@@ -1067,11 +1933,65 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 		return nil, fmt.Errorf("failed to scan app directory: %w", err)
 	}
 
+	for _, dir := range useDirs {
+		named, err := useNamedMiddleware(fset, dir, uses[dir], appDir, moduleName)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Middlewares = append(cfg.Middlewares, named...)
+	}
+	if err := checkMiddlewareFilters(cfg, appDir); err != nil {
+		return nil, err
+	}
+
+	// Wrap pages in the layouts above them
+	if err := assignPageSegments(cfg.Pages, cfg.Layouts, slots, appDir); err != nil {
+		return nil, fmt.Errorf("failed to scan page metadata: %w", err)
+	}
+	warnings = append(warnings, checkSlotsAndIntercepts(cfg, slots)...)
+
+	// Markdown pages in content/ next to the app directory
+	content, err := scanContentDir(filepath.Join(filepath.Dir(appDir), "content"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan content directory: %w", err)
+	}
+	pagePatterns := make(map[string]bool, len(cfg.Pages))
+	for _, p := range cfg.Pages {
+		pagePatterns[p.Pattern] = true
+	}
+	for _, c := range content {
+		if pagePatterns[c.Pattern] {
+			warnings = append(warnings, GenerationWarning{
+				File:    c.FilePath,
+				Message: fmt.Sprintf("Content page %s is shadowed by a page.templ with the same route and was skipped.", c.Pattern),
+			})
+			continue
+		}
+		cfg.Content = append(cfg.Content, c)
+	}
+	if err := assignContentSegments(cfg.Content, cfg.Layouts, appDir); err != nil {
+		return nil, fmt.Errorf("failed to scan page metadata: %w", err)
+	}
+
 	// Print conflict warnings
 	for _, c := range conflicts {
 		printConflictWarning(c)
 	}
 
+	// Check injected dependencies against app.Provide calls in the main package
+	if hasDeps(cfg) {
+		outputDir := filepath.Dir(outputPath)
+		providers, err := scanProviders(outputDir, outputPath, moduleName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan providers: %w", err)
+		}
+		depWarnings, err := checkDependencies(cfg, providers)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, depWarnings...)
+	}
+
 	// Print other warnings
 	for _, w := range warnings {
 		fmt.Printf("Warning: %s: %s\n", w.File, w.Message)
@@ -1080,7 +2000,21 @@ func ScanAndGenerateRoutes(appDir, outputPath string) (*Result, error) {
 	return GenerateRoutesFile(cfg)
 }
 
-// routeFileHasGetHandler checks if a route.go file has a Get() handler function
+// hasLocaleCatalogs reports whether dir holds i18n message catalogs.
+func hasLocaleCatalogs(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && i18n.IsCatalogFile(e.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeFileHasGetHandler checks if a route file has a Get() handler function
 func routeFileHasGetHandler(filePath string) (bool, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -1095,36 +2029,88 @@ func routeFileHasGetHandler(filePath string) (bool, error) {
 
 // scanLoaderFile scans a loader.go file for a Loader() function
 func scanLoaderFile(fset *token.FileSet, filePath, appDir, moduleName string) (*LoaderRegistration, error) {
-	content, err := os.ReadFile(filePath)
+	file, err := parser.ParseFile(fset, filePath, nil, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for Loader function
-	loaderRe := regexp.MustCompile(`func\s+Loader\s*\([^)]*\*nexo\.Context\s*\)\s*\(([^,]+),\s*error\)`)
-	matches := loaderRe.FindSubmatch(content)
-	if len(matches) < 2 {
-		return nil, nil // No Loader function found
+	dir := filepath.Dir(filePath)
+	relDir, err := filepath.Rel(".", dir)
+	if err != nil {
+		return nil, err
+	}
+	importPath := getImportPath(moduleName, relDir)
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != "Loader" {
+			continue
+		}
+
+		// func Loader([ctx context.Context,] c *nexo.Context, deps...) (T, error)
+		returnType, deps, ok := loaderSignature(scanner.WithoutContext(fn), fileImports(file), importPath)
+		if !ok {
+			return nil, nil
+		}
+		if len(deps) > maxInjectedDeps {
+			return nil, fmt.Errorf("%s: Loader has %d dependencies, at most %d are supported", filePath, len(deps), maxInjectedDeps)
+		}
+
+		return &LoaderRegistration{
+			ImportPath: importPath,
+			Package:    packageNameFromDir(dir),
+			FilePath:   filePath,
+			ReturnType: returnType,
+			Dir:        dir,
+			Deps:       deps,
+			Context:    scanner.TakesContext(fn),
+		}, nil
 	}
 
-	returnType := strings.TrimSpace(string(matches[1]))
+	return nil, nil // No Loader function found
+}
 
+// scanGraphQLResolver scans a resolver.go file for an Execute function.
+// The directory must also contain a schema.graphql file.
+func scanGraphQLResolver(fset *token.FileSet, filePath, appDir, moduleName string) (*GraphQLRegistration, error) {
 	dir := filepath.Dir(filePath)
-	relDir, err := filepath.Rel(".", dir)
+	schemaPath := filepath.Join(dir, "schema.graphql")
+	if _, err := os.Stat(schemaPath); err != nil {
+		return nil, nil // Not a GraphQL directory
+	}
+
+	file, err := parser.ParseFile(fset, filePath, nil, 0)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
 	}
 
-	importPath := getImportPath(moduleName, relDir)
-	pkgName := packageNameFromDir(dir)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != "Execute" {
+			continue
+		}
 
-	return &LoaderRegistration{
-		ImportPath: importPath,
-		Package:    pkgName,
-		FilePath:   filePath,
-		ReturnType: returnType,
-		Dir:        dir,
-	}, nil
+		// func Execute(ctx context.Context, req *nexo.GraphQLRequest) *nexo.GraphQLResponse
+		if fn.Type.Params.NumFields() != 2 || fn.Type.Results.NumFields() != 1 {
+			fmt.Printf("Warning: %s: Execute must have signature func(context.Context, *nexo.GraphQLRequest) *nexo.GraphQLResponse\n", filePath)
+			return nil, nil
+		}
+
+		relDir, err := filepath.Rel(".", dir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &GraphQLRegistration{
+			ImportPath: getImportPath(moduleName, relDir),
+			Package:    file.Name.Name,
+			Pattern:    dirToPattern(dir, appDir),
+			FilePath:   filePath,
+			SchemaPath: schemaPath,
+		}, nil
+	}
+
+	return nil, nil
 }
 
 // removeGetHandlerForPattern removes GET handlers for a specific pattern from the routes slice
@@ -1139,11 +2125,13 @@ func removeGetHandlerForPattern(routes []RouteRegistration, pattern string) []Ro
 	return result
 }
 
-// hasComplexParams checks if page params include non-string types (complex types)
+// hasComplexParams checks if page params include types that can't be read
+// from the URL (complex types)
 func hasComplexParams(params []PageParam) bool {
 	for _, p := range params {
 		// Simple types that can be auto-extracted from URL
-		if p.Type == "string" {
+		switch p.Type {
+		case "string", "[]string", "int", "uuid.UUID":
 			continue
 		}
 		// Any other type is "complex" and needs a loader
@@ -1155,13 +2143,14 @@ func hasComplexParams(params []PageParam) bool {
 // printConflictWarning prints a detailed warning about route conflicts
 func printConflictWarning(c RouteConflict) {
 	fmt.Printf("\n⚠ Warning: Route conflict in %s\n", c.Directory)
-	fmt.Printf("  Both route.go and page.templ exist for pattern: %s\n", c.Pattern)
+	routeFile := filepath.Base(c.RouteFile)
+	fmt.Printf("  Both %s and page.templ exist for pattern: %s\n", routeFile, c.Pattern)
 	fmt.Println()
 	fmt.Println("  Resolution: page.templ takes precedence for GET requests.")
-	fmt.Printf("  The Get() handler in route.go will be ignored.\n")
+	fmt.Printf("  The Get() handler in %s will be ignored.\n", routeFile)
 	fmt.Println()
 	fmt.Println("  Alternatives:")
-	fmt.Println("  1. Remove Get() from route.go (it will still handle POST, PUT, DELETE, etc.)")
+	fmt.Printf("  1. Remove Get() from %s (other methods still handle POST, PUT, DELETE, etc.)\n", routeFile)
 	fmt.Println("  2. Move API logic to app/api/ directory")
 	fmt.Println("  3. Use the data loader pattern: create loader.go with Loader() function")
 	fmt.Println("     See: https://nexo.build/docs/routing/data-loaders")
@@ -1212,7 +2201,19 @@ func scanPageFile(filePath, appDir, moduleName string) (*PageRegistration, error
 	pkgName := packageNameFromDir(dir)
 	title := deriveTitle(dir, appDir)
 
+	sitemap, err := parseSitemapDirective(contentStr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	found, err := scanPackageDecls(dir, staticParamsFuncRe)
+	if err != nil {
+		return nil, err
+	}
+	sitemap.StaticParams = found[0]
+
 	return &PageRegistration{
+		Sitemap:        sitemap,
+		SelfLayout:     strings.Contains(contentStr, "@Layout("),
 		ImportPath:     importPath,
 		Package:        pkgName,
 		Pattern:        pattern,
@@ -1293,7 +2294,7 @@ func extractURLParams(dir, appDir string) []string {
 	}
 
 	var params []string
-	segments := strings.Split(rel, string(filepath.Separator))
+	segments := urlDirs(rel)
 
 	for _, seg := range segments {
 		// Skip route groups (group)
@@ -1387,19 +2388,25 @@ func scanLayoutFile(filePath, appDir, moduleName string) (*LayoutRegistration, e
 	}
 
 	contentStr := string(content)
-	if !strings.Contains(contentStr, "templ Layout(") {
+	matches := templLayoutSignatureRe.FindStringSubmatch(contentStr)
+	if matches == nil {
 		return nil, nil // Skip layouts without Layout() function
 	}
 	if !strings.Contains(contentStr, "{ children... }") {
 		return nil, nil // Skip layouts without children support
 	}
 
+	// Generated code can wrap pages in Layout() and Layout(title string)
+	params := parseTemplParams(strings.TrimSpace(matches[1]))
+	takesTitle := len(params) == 1 && params[0].Type == "string"
+	nestable := len(params) == 0 || takesTitle
+
 	// Get the import path and path prefix
 	relDir, err := filepath.Rel(".", filepath.Dir(filePath))
 	if err != nil {
 		return nil, err
 	}
-	// Get import path (uses .nexo/imports/ if sanitization is needed)
+	// Get import path (uses .nexo/generated/wrappers/ for bracket directories)
 	importPath := getImportPath(moduleName, relDir)
 	pathPrefix := layoutPathToPrefix(filepath.Dir(filePath), appDir)
 	pkgName := packageNameFromDir(filepath.Dir(filePath))
@@ -1409,71 +2416,489 @@ func scanLayoutFile(filePath, appDir, moduleName string) (*LayoutRegistration, e
 		Package:    pkgName,
 		PathPrefix: pathPrefix,
 		FilePath:   filePath,
+		TakesTitle: takesTitle,
+		Nestable:   nestable,
 	}, nil
 }
 
-// pagePathToPattern converts a page directory to a route pattern
-func pagePathToPattern(dir, appDir string) string {
-	rel, err := filepath.Rel(appDir, dir)
-	if err != nil || rel == "." {
-		return "/"
-	}
+// templLayoutSignatureRe matches a templ Layout() declaration and its parameters.
+var templLayoutSignatureRe = regexp.MustCompile(`templ\s+Layout\s*\(([^)]*)\)`)
 
-	segments := strings.Split(rel, string(filepath.Separator))
-	var routeSegments []string
+var (
+	metadataVarRe      = regexp.MustCompile(`(?m)^var\s+Metadata\b`)
+	metadataFuncRe     = regexp.MustCompile(`(?m)^func\s+GenerateMetadata\s*\(`)
+	staticParamsFuncRe = regexp.MustCompile(`(?m)^func\s+GenerateStaticParams\s*\(`)
+)
 
-	for _, seg := range segments {
-		// Skip route groups (group) - they don't affect the URL
-		if routeGroupRe.MatchString(seg) {
-			continue
-		}
+// assignPageSegments sets the layouts and metadata wrapping each page: every
+// directory from appDir down to the page that has a layout, plus the page's
+// own directory. Pages that render @Layout themselves keep doing so and only
+// get metadata. Layouts get the slots of their directory that apply to the
+// page.
+func assignPageSegments(pages []PageRegistration, layouts []LayoutRegistration, slots []PageRegistration, appDir string) error {
+	layoutDirs := layoutsByDir(layouts)
 
-		// Skip api directory - pages shouldn't be in api
-		if seg == "api" {
-			continue
+	for i := range pages {
+		page := &pages[i]
+		pageDir := filepath.Dir(page.FilePath)
+
+		dirs, err := segmentDirs(pageDir, appDir)
+		if err != nil {
+			return err
 		}
 
-		// Handle dynamic segments
-		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
-			continue
+		page.Segments = nil
+		for _, dir := range dirs {
+			layout, hasLayout := layoutDirs[dir]
+			if !hasLayout && dir != pageDir {
+				continue
+			}
+			seg := PageSegment{ImportPath: page.ImportPath, Package: page.Package}
+			if hasLayout {
+				seg.ImportPath, seg.Package = layout.ImportPath, layout.Package
+				seg.Layout = layout.Nestable && !page.SelfLayout
+				seg.LayoutTitle = layout.TakesTitle
+				if seg.Layout {
+					seg.Slots = pageSlots(slots, dir, page.Pattern)
+				}
+			}
+			if seg.Metadata, seg.GenerateMetadata, err = scanMetadataDecls(dir); err != nil {
+				return err
+			}
+			if seg.Layout || seg.Metadata || seg.GenerateMetadata {
+				page.Segments = append(page.Segments, seg)
+			}
 		}
-		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
+	}
+	return nil
+}
+
+// pageSlots returns the slots the layout in dir renders for the page at
+// pattern, sorted by name: per slot, the page matching pattern, or else the
+// slot's default.templ. Slots with neither are left out.
+func pageSlots(slots []PageRegistration, dir, pattern string) []PageRegistration {
+	chosen := make(map[string]PageRegistration)
+	for _, slot := range slots {
+		if slot.SlotParent != dir {
 			continue
 		}
-		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "{"+matches[1]+"}")
-			continue
+		switch {
+		case !slot.Default && slot.Pattern == pattern:
+			chosen[slot.Slot] = slot
+		case slot.Default:
+			if _, ok := chosen[slot.Slot]; !ok {
+				chosen[slot.Slot] = slot
+			}
 		}
-
-		routeSegments = append(routeSegments, seg)
 	}
-
-	if len(routeSegments) == 0 {
-		return "/"
+	if len(chosen) == 0 {
+		return nil
 	}
-
-	return "/" + strings.Join(routeSegments, "/")
+	names := make([]string, 0, len(chosen))
+	for name := range chosen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]PageRegistration, 0, len(names))
+	for _, name := range names {
+		result = append(result, chosen[name])
+	}
+	return result
 }
 
-// layoutPathToPrefix converts a layout directory to a path prefix
-func layoutPathToPrefix(dir, appDir string) string {
-	rel, err := filepath.Rel(appDir, dir)
-	if err != nil || rel == "." {
-		return "/"
+// checkSlotsAndIntercepts warns about slots without a layout to render them
+// and intercepting pages without a route to intercept.
+func checkSlotsAndIntercepts(cfg RoutesGenConfig, slots []PageRegistration) []GenerationWarning {
+	var warnings []GenerationWarning
+	layoutDirs := layoutsByDir(cfg.Layouts)
+	for _, slot := range slots {
+		if _, ok := layoutDirs[slot.SlotParent]; !ok {
+			warnings = append(warnings, GenerationWarning{
+				File:    slot.FilePath,
+				Message: fmt.Sprintf("Slot @%s has no layout.templ in %s to render it.", slot.Slot, slot.SlotParent),
+			})
+		}
 	}
 
-	segments := strings.Split(rel, string(filepath.Separator))
-	var routeSegments []string
-
-	for _, seg := range segments {
-		// Skip route groups (group) - they don't affect the URL
-		if routeGroupRe.MatchString(seg) {
-			continue
+	getPatterns := make(map[string]bool)
+	for _, p := range cfg.Pages {
+		getPatterns[p.Pattern] = true
+	}
+	for _, r := range cfg.Routes {
+		if r.Method == http.MethodGet {
+			getPatterns[r.Pattern] = true
 		}
-
-		// Skip api directory
+	}
+	for _, p := range cfg.Intercepts {
+		if !getPatterns[p.Pattern] {
+			warnings = append(warnings, GenerationWarning{
+				File:    p.FilePath,
+				Message: fmt.Sprintf("Intercepting page has no page or GET route at %s to intercept.", p.Pattern),
+			})
+		}
+	}
+	return warnings
+}
+
+// templDefaultSignatureRe matches the templ Default() declaration of a
+// slot's default.templ.
+var templDefaultSignatureRe = regexp.MustCompile(`templ\s+Default\s*\(\s*\)`)
+
+// scanSlotDefault scans the default.templ of a @slot directory, rendered
+// into the slot when no page in it matches the URL. It returns nil for
+// files outside slot directories or without a Default() component.
+func scanSlotDefault(filePath, appDir, moduleName string) (*PageRegistration, error) {
+	dir := filepath.Dir(filePath)
+	if !slotSegmentRe.MatchString(filepath.Base(dir)) {
+		return nil, nil
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !templDefaultSignatureRe.Match(content) {
+		return nil, nil
+	}
+
+	relDir, err := filepath.Rel(".", dir)
+	if err != nil {
+		return nil, err
+	}
+	slot, parent, _ := slotDir(dir, appDir)
+	return &PageRegistration{
+		ImportPath: getImportPath(moduleName, relDir),
+		Package:    packageNameFromDir(dir),
+		Pattern:    pagePathToPattern(dir, appDir),
+		FilePath:   filePath,
+		Slot:       slot,
+		SlotParent: parent,
+		Default:    true,
+	}, nil
+}
+
+// assignContentSegments wraps each content page in the layouts of the app
+// directories along its URL path, as if it were a page.templ there. The
+// page's own metadata comes from its front matter.
+func assignContentSegments(content []ContentRegistration, layouts []LayoutRegistration, appDir string) error {
+	layoutDirs := layoutsByDir(layouts)
+
+	for i := range content {
+		c := &content[i]
+		pageDir := filepath.Join(appDir, filepath.FromSlash(strings.TrimPrefix(c.Pattern, "/")))
+
+		dirs, err := segmentDirs(pageDir, appDir)
+		if err != nil {
+			return err
+		}
+
+		c.Segments = nil
+		for _, dir := range dirs {
+			layout, hasLayout := layoutDirs[dir]
+			if !hasLayout {
+				continue
+			}
+			seg := PageSegment{
+				ImportPath:  layout.ImportPath,
+				Package:     layout.Package,
+				Layout:      layout.Nestable,
+				LayoutTitle: layout.TakesTitle,
+			}
+			if seg.Metadata, seg.GenerateMetadata, err = scanMetadataDecls(dir); err != nil {
+				return err
+			}
+			if seg.Layout || seg.Metadata || seg.GenerateMetadata {
+				c.Segments = append(c.Segments, seg)
+			}
+		}
+	}
+	return nil
+}
+
+// layoutsByDir indexes layouts by their directory.
+func layoutsByDir(layouts []LayoutRegistration) map[string]LayoutRegistration {
+	dirs := make(map[string]LayoutRegistration, len(layouts))
+	for _, l := range layouts {
+		dirs[filepath.Dir(l.FilePath)] = l
+	}
+	return dirs
+}
+
+// urlDirs splits rel, a directory relative to the app directory, into the
+// directory names that make up its URL. Parallel route slots (@team) are
+// dropped and intercepting markers are resolved like relative paths:
+// feed/(..)photos becomes photos.
+func urlDirs(rel string) []string {
+	var dirs []string
+	for _, seg := range strings.Split(rel, string(filepath.Separator)) {
+		if slotSegmentRe.MatchString(seg) {
+			continue
+		}
+		if marker := interceptMarkerRe.FindString(seg); marker != "" && marker != seg {
+			dirs = interceptBase(dirs, marker)
+			seg = seg[len(marker):]
+		}
+		dirs = append(dirs, seg)
+	}
+	return dirs
+}
+
+// interceptBase returns the directories of dirs an intercepting marker
+// resolves against: (.) keeps them, (..) drops the last URL segment,
+// (..)(..) the last two and (...) all of them. Route groups don't count as
+// segments.
+func interceptBase(dirs []string, marker string) []string {
+	var levels int
+	switch marker {
+	case "(.)":
+		return dirs
+	case "(...)":
+		return nil
+	default:
+		levels = strings.Count(marker, "(..)")
+	}
+	for i := len(dirs) - 1; i >= 0 && levels > 0; i-- {
+		if !routeGroupRe.MatchString(dirs[i]) {
+			levels--
+		}
+		dirs = dirs[:i]
+	}
+	return dirs
+}
+
+// interceptFrom returns the route pattern of the page that navigation to
+// an intercepting directory like app/feed/(..)photos/[id] is intercepted
+// from (/feed), and whether dir is in an intercepting directory at all.
+func interceptFrom(dir, appDir string) (string, bool) {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil {
+		return "", false
+	}
+	segments := strings.Split(rel, string(filepath.Separator))
+	for i, seg := range segments {
+		if marker := interceptMarkerRe.FindString(seg); marker != "" && marker != seg {
+			return pagePathToPattern(filepath.Join(appDir, filepath.Join(segments[:i]...)), appDir), true
+		}
+	}
+	return "", false
+}
+
+// slotDir returns the name of the innermost parallel route slot dir is in,
+// like team for app/dashboard/@team/members, and the directory whose
+// layout renders it (app/dashboard).
+func slotDir(dir, appDir string) (name, parent string, ok bool) {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil {
+		return "", "", false
+	}
+	segments := strings.Split(rel, string(filepath.Separator))
+	for i := len(segments) - 1; i >= 0; i-- {
+		if matches := slotSegmentRe.FindStringSubmatch(segments[i]); matches != nil {
+			return matches[1], filepath.Join(appDir, filepath.Join(segments[:i]...)), true
+		}
+	}
+	return "", "", false
+}
+
+// segmentDirs returns appDir and every directory below it down to dir.
+func segmentDirs(dir, appDir string) ([]string, error) {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{appDir}
+	if rel != "." {
+		d := appDir
+		for _, seg := range strings.Split(rel, string(filepath.Separator)) {
+			d = filepath.Join(d, seg)
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs, nil
+}
+
+// scanContentDir returns the Markdown pages in contentDir. Files and
+// directories starting with "_" or "." are skipped.
+func scanContentDir(contentDir string) ([]ContentRegistration, error) {
+	if _, err := os.Stat(contentDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var content []ContentRegistration
+	err := filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") || strings.HasPrefix(info.Name(), "_") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fm, _, err := markdown.ParseFrontMatter(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		content = append(content, ContentRegistration{
+			Pattern:  markdown.ContentPattern(filepath.ToSlash(rel)),
+			FilePath: filepath.ToSlash(path),
+			Title:    fm.Title,
+			Draft:    fm.Draft,
+		})
+		return nil
+	})
+	return content, err
+}
+
+// scanMetadataDecls reports whether the package in dir declares a Metadata
+// variable or a GenerateMetadata function, in Go or templ files.
+func scanMetadataDecls(dir string) (hasVar, hasFunc bool, err error) {
+	found, err := scanPackageDecls(dir, metadataVarRe, metadataFuncRe)
+	if err != nil {
+		return false, false, err
+	}
+	return found[0], found[1], nil
+}
+
+// scanPackageDecls reports, for each of res, whether a Go or templ file of
+// the package in dir matches it.
+func scanPackageDecls(dir string, res ...*regexp.Regexp) ([]bool, error) {
+	found := make([]bool, len(res))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, "_templ.go") || strings.HasSuffix(name, "_test.go") ||
+			(filepath.Ext(name) != ".go" && filepath.Ext(name) != ".templ") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for i, re := range res {
+			found[i] = found[i] || re.Match(content)
+		}
+	}
+	return found, nil
+}
+
+// sitemapDirectiveRe matches "// nexo:sitemap changefreq=weekly priority=0.8".
+var sitemapDirectiveRe = regexp.MustCompile(`(?m)^\s*//\s*nexo:sitemap\b(.*)$`)
+
+// sitemapChangeFreqs are the changefreq values allowed by the sitemap protocol.
+var sitemapChangeFreqs = map[string]bool{
+	"always": true, "hourly": true, "daily": true, "weekly": true,
+	"monthly": true, "yearly": true, "never": true,
+}
+
+// parseSitemapDirective returns the settings of a nexo:sitemap line in a
+// page: "exclude", "changefreq=F" and "priority=P".
+func parseSitemapDirective(content string) (PageSitemap, error) {
+	var sm PageSitemap
+	m := sitemapDirectiveRe.FindStringSubmatch(content)
+	if m == nil {
+		return sm, nil
+	}
+	for _, field := range strings.Fields(m[1]) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "exclude":
+			sm.Exclude = true
+		case "changefreq":
+			if !sitemapChangeFreqs[value] {
+				return sm, fmt.Errorf("nexo:sitemap: invalid changefreq %q", value)
+			}
+			sm.ChangeFreq = value
+		case "priority":
+			p, err := strconv.ParseFloat(value, 64)
+			if err != nil || p < 0 || p > 1 {
+				return sm, fmt.Errorf("nexo:sitemap: priority %q is not between 0.0 and 1.0", value)
+			}
+			sm.Priority = value
+		default:
+			return sm, fmt.Errorf("nexo:sitemap: unknown setting %q", field)
+		}
+	}
+	return sm, nil
+}
+
+// pagePathToPattern converts a page directory to a route pattern
+func pagePathToPattern(dir, appDir string) string {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil || rel == "." {
+		return "/"
+	}
+
+	segments := urlDirs(rel)
+	var routeSegments []string
+
+	for _, seg := range segments {
+		// Skip route groups (group) - they don't affect the URL
+		if routeGroupRe.MatchString(seg) {
+			continue
+		}
+
+		// Skip api directory - pages shouldn't be in api
+		if seg == "api" {
+			continue
+		}
+
+		// Handle dynamic segments
+		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
+			routeSegments = append(routeSegments, scanner.CatchAllPattern(matches[1], true))
+			continue
+		}
+		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
+			routeSegments = append(routeSegments, scanner.CatchAllPattern(matches[1], false))
+			continue
+		}
+		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
+			routeSegments = append(routeSegments, scanner.ParamPattern(matches[1], matches[2]))
+			continue
+		}
+
+		routeSegments = append(routeSegments, seg)
+	}
+
+	if len(routeSegments) == 0 {
+		return "/"
+	}
+
+	return "/" + strings.Join(routeSegments, "/")
+}
+
+// layoutPathToPrefix converts a layout directory to a path prefix
+func layoutPathToPrefix(dir, appDir string) string {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil || rel == "." {
+		return "/"
+	}
+
+	segments := urlDirs(rel)
+	var routeSegments []string
+
+	for _, seg := range segments {
+		// Skip route groups (group) - they don't affect the URL
+		if routeGroupRe.MatchString(seg) {
+			continue
+		}
+
+		// Skip api directory
 		if seg == "api" {
 			continue
 		}
@@ -1508,7 +2933,7 @@ func deriveTitle(dir, appDir string) string {
 	}
 
 	// Get the last non-group segment
-	segments := strings.Split(rel, string(filepath.Separator))
+	segments := urlDirs(rel)
 	for i := len(segments) - 1; i >= 0; i-- {
 		seg := segments[i]
 		// Skip route groups (group)
@@ -1529,26 +2954,14 @@ func deriveTitle(dir, appDir string) string {
 	return "Home"
 }
 
-// getModuleName reads the module name from go.mod
+// getModuleName returns the import path of the working directory, which
+// the app's packages are imported relative to (see scanner.GetModuleName).
 func getModuleName() (string, error) {
-	f, err := os.Open("go.mod")
-	if err != nil {
-		return "", err
-	}
-	defer func() { _ = f.Close() }()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
-		}
-	}
-
-	return "", fmt.Errorf("module name not found in go.mod")
+	return scanner.GetModuleName()
 }
 
-// scanRouteFile scans a route.go file for handler functions
+// scanRouteFile scans a route file (route.go, get.go, post.go, ...) for
+// handler functions
 func scanRouteFile(fset *token.FileSet, filePath, appDir, moduleName string) ([]RouteRegistration, error) {
 	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
 	if err != nil {
@@ -1560,10 +2973,13 @@ func scanRouteFile(fset *token.FileSet, filePath, appDir, moduleName string) ([]
 	if err != nil {
 		return nil, err
 	}
-	// Get import path (uses .nexo/imports/ if sanitization is needed)
+	// Get import path (uses .nexo/generated/wrappers/ for bracket directories)
 	importPath := getImportPath(moduleName, relDir)
 	pattern := dirToPattern(filepath.Dir(filePath), appDir)
 	pkgName := file.Name.Name
+	hasConfig := declaresVar(file, "RouteConfig") || routeDirDeclaresVar(fset, filePath, "RouteConfig")
+	hasCORS := declaresVar(file, "CORS") || routeDirDeclaresVar(fset, filePath, "CORS")
+	imports := fileImports(file)
 
 	var routes []RouteRegistration
 
@@ -1573,28 +2989,103 @@ func scanRouteFile(fset *token.FileSet, filePath, appDir, moduleName string) ([]
 			continue
 		}
 
-		method, ok := httpMethods[fn.Name.Name]
+		method, ok := scanner.HandlerMethod(fn.Name.Name)
 		if !ok {
 			continue
 		}
 
+		var bodyType string
+		var deps []string
 		if !isValidHandlerSignature(fn) {
-			continue
+			if bt, ok := bodyHandlerType(fn); ok {
+				bodyType = bt
+			} else if d, ok := handlerDeps(scanner.WithoutContext(fn), imports, importPath); ok {
+				deps = d
+			} else {
+				continue
+			}
+		}
+		if len(deps) > maxInjectedDeps {
+			return nil, fmt.Errorf("%s: %s has %d dependencies, at most %d are supported", filePath, fn.Name.Name, len(deps), maxInjectedDeps)
 		}
 
-		routes = append(routes, RouteRegistration{
-			ImportPath: importPath,
-			Package:    pkgName,
-			Method:     method,
-			Pattern:    pattern,
-			Handler:    fn.Name.Name,
-			FilePath:   filePath,
-		})
+		priority, hasPriority := scanner.HandlerPriority(file, fn)
+		opts, err := scanner.RouteDirective(fn.Doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", filePath, fn.Name.Name, err)
+		}
+
+		methods := []string{method}
+		if opts != nil && len(opts.Methods) > 0 {
+			methods = opts.Methods
+		}
+		for _, method := range methods {
+			routes = append(routes, RouteRegistration{
+				ImportPath:  importPath,
+				Package:     pkgName,
+				Method:      method,
+				Pattern:     pattern,
+				Handler:     fn.Name.Name,
+				FilePath:    filePath,
+				HasConfig:   hasConfig,
+				HasCORS:     hasCORS,
+				BodyType:    bodyType,
+				Deps:        deps,
+				Context:     scanner.TakesContext(fn),
+				Priority:    priority,
+				HasPriority: hasPriority,
+				Options:     opts,
+			})
+		}
 	}
 
 	return routes, nil
 }
 
+// routeDirDeclaresVar reports whether another route file in the directory of
+// filePath declares a package-level variable named name, so a RouteConfig
+// in route.go applies to handlers split into get.go, post.go and so on.
+func routeDirDeclaresVar(fset *token.FileSet, filePath, name string) bool {
+	dir := filepath.Dir(filePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() || !scanner.IsRouteFile(e.Name()) || path == filePath {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err == nil && declaresVar(file, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaresVar reports whether file declares a package-level variable named name.
+func declaresVar(file *ast.File, name string) bool {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, n := range vs.Names {
+				if n.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // scanMiddlewareFile scans a middleware.go file
 func scanMiddlewareFile(fset *token.FileSet, filePath, appDir, moduleName string) (*MiddlewareRegistration, error) {
 	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
@@ -1607,9 +3098,10 @@ func scanMiddlewareFile(fset *token.FileSet, filePath, appDir, moduleName string
 	if err != nil {
 		return nil, err
 	}
-	// Get import path (uses .nexo/imports/ if sanitization is needed)
+	// Get import path (uses .nexo/generated/wrappers/ for bracket directories)
 	importPath := getImportPath(moduleName, relDir)
 	pathPrefix := dirToPattern(filepath.Dir(filePath), appDir)
+	scope := dirToScope(filepath.Dir(filePath), appDir)
 	pkgName := file.Name.Name
 
 	// Look for Middleware function
@@ -1631,6 +3123,7 @@ func scanMiddlewareFile(fset *token.FileSet, filePath, appDir, moduleName string
 			ImportPath: importPath,
 			Package:    pkgName,
 			PathPrefix: pathPrefix,
+			Scope:      scope,
 			FilePath:   filePath,
 		}, nil
 	}
@@ -1638,6 +3131,86 @@ func scanMiddlewareFile(fset *token.FileSet, filePath, appDir, moduleName string
 	return nil, nil
 }
 
+// checkMiddlewareFilters fails when the skip or only option of a route
+// names middleware the route doesn't inherit, which would silently run.
+func checkMiddlewareFilters(cfg RoutesGenConfig, appDir string) error {
+	m := &scanner.RouteManifest{}
+	for _, mw := range cfg.Middlewares {
+		m.Middlewares = append(m.Middlewares, scanner.MiddlewareFile{
+			URLPattern: mw.PathPrefix,
+			Scope:      mw.Scope,
+			Name:       mw.Name,
+		})
+	}
+	for _, r := range cfg.Routes {
+		inherited := m.InheritedMiddleware(scanner.ManifestRoute{
+			Pattern: r.Pattern,
+			Scope:   dirToScope(filepath.Dir(r.FilePath), appDir),
+		})
+		if _, _, err := r.Options.FilterMiddleware(inherited); err != nil {
+			return fmt.Errorf("%s: %s: %w", r.FilePath, r.Handler, err)
+		}
+	}
+	return nil
+}
+
+// quotedList returns names as quoted Go strings separated by commas.
+func quotedList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// scanUseDirective returns the middleware names listed by the nexo:use
+// directive in the package doc comment of a Go file.
+func scanUseDirective(fset *token.FileSet, filePath string) ([]string, error) {
+	file, err := parser.ParseFile(fset, filePath, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return scanner.UseDirective(file.Doc), nil
+}
+
+// useNamedMiddleware returns the registrations of the named middleware the
+// directory dir uses, from app/_middleware/<name>/middleware.go.
+func useNamedMiddleware(fset *token.FileSet, dir string, names []string, appDir, moduleName string) ([]MiddlewareRegistration, error) {
+	var regs []MiddlewareRegistration
+	for _, name := range names {
+		if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return nil, fmt.Errorf("%s: invalid middleware name %q", dir, name)
+		}
+		filePath := filepath.Join(appDir, scanner.MiddlewareRegistryDir, name, "middleware.go")
+		if _, err := os.Stat(filePath); err != nil {
+			return nil, fmt.Errorf("%s: unknown middleware %q: %s not found", dir, name, filePath)
+		}
+		mw, err := scanMiddlewareFile(fset, filePath, appDir, moduleName)
+		if err != nil {
+			return nil, err
+		}
+		if mw == nil {
+			return nil, fmt.Errorf("%s: middleware %q: %s has no Middleware function", dir, name, filePath)
+		}
+
+		mw.PathPrefix = dirToPattern(dir, appDir)
+		mw.Scope = dirToScope(dir, appDir)
+		mw.Name = name
+		regs = append(regs, *mw)
+	}
+	return regs, nil
+}
+
+// dirToScope converts a directory to the middleware scope of its routes,
+// which keeps route groups, e.g. "(admin)/settings".
+func dirToScope(dir, appDir string) string {
+	rel, err := filepath.Rel(appDir, dir)
+	if err != nil {
+		return ""
+	}
+	return scanner.BuildScope(scanner.ParsePath(rel))
+}
+
 // scanProxyFile scans a proxy.go file
 func scanProxyFile(fset *token.FileSet, filePath, moduleName string) (*ProxyRegistration, error) {
 	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
@@ -1658,7 +3231,7 @@ func scanProxyFile(fset *token.FileSet, filePath, moduleName string) (*ProxyRegi
 	for _, decl := range file.Decls {
 		switch d := decl.(type) {
 		case *ast.FuncDecl:
-			if d.Name.Name == "Proxy" && isValidProxySignature(d) {
+			if d.Name.Name == "Proxy" && scanner.IsProxyFunc(d) {
 				hasProxy = true
 			}
 		case *ast.GenDecl:
@@ -1690,6 +3263,31 @@ func scanProxyFile(fset *token.FileSet, filePath, moduleName string) (*ProxyRegi
 	}, nil
 }
 
+// scanMaintenanceFile scans a maintenance.templ file for a Maintenance()
+// component.
+func scanMaintenanceFile(filePath, moduleName string) (*MaintenanceRegistration, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !templMaintenanceSignatureRe.Match(content) {
+		return nil, nil // Skip files without a parameterless Maintenance()
+	}
+
+	relDir, err := filepath.Rel(".", filepath.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return &MaintenanceRegistration{
+		ImportPath: getImportPath(moduleName, relDir),
+		Package:    packageNameFromDir(filepath.Dir(filePath)),
+		FilePath:   filePath,
+	}, nil
+}
+
+// templMaintenanceSignatureRe matches a templ Maintenance() declaration.
+var templMaintenanceSignatureRe = regexp.MustCompile(`templ\s+Maintenance\s*\(\s*\)`)
+
 // dirToPattern converts a directory path to a route pattern
 func dirToPattern(dir, appDir string) string {
 	rel, err := filepath.Rel(appDir, dir)
@@ -1697,7 +3295,7 @@ func dirToPattern(dir, appDir string) string {
 		return "/"
 	}
 
-	segments := strings.Split(rel, string(filepath.Separator))
+	segments := urlDirs(rel)
 	var routeSegments []string
 
 	for _, seg := range segments {
@@ -1708,19 +3306,19 @@ func dirToPattern(dir, appDir string) string {
 
 		// Handle optional catch-all [[...param]]
 		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
+			routeSegments = append(routeSegments, scanner.CatchAllPattern(matches[1], true))
 			continue
 		}
 
 		// Handle catch-all [...param]
 		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
+			routeSegments = append(routeSegments, scanner.CatchAllPattern(matches[1], false))
 			continue
 		}
 
 		// Handle dynamic segment [param]
 		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "{"+matches[1]+"}")
+			routeSegments = append(routeSegments, scanner.ParamPattern(matches[1], matches[2]))
 			continue
 		}
 
@@ -1734,158 +3332,35 @@ func dirToPattern(dir, appDir string) string {
 	return "/" + strings.Join(routeSegments, "/")
 }
 
-// isValidHandlerSignature checks if a function has the signature: func(c *nexo.Context) error
+// isValidHandlerSignature checks if a function has the signature
+// func(c *nexo.Context) error, with an optional leading context.Context.
 func isValidHandlerSignature(fn *ast.FuncDecl) bool {
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	param := fn.Type.Params.List[0]
-	starExpr, ok := param.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	switch x := starExpr.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name == "nexo" && x.Sel.Name == "Context" {
-				goto checkReturn
-			}
-		}
-	case *ast.Ident:
-		if x.Name == "Context" {
-			goto checkReturn
-		}
-	}
-	return false
-
-checkReturn:
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	result := fn.Type.Results.List[0]
-	if ident, ok := result.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
-	}
-
-	return false
+	kind, ok := scanner.HandlerSignature(fn)
+	return ok && kind == scanner.HandlerPlain
 }
 
-// isValidMiddlewareSignature checks if a function has the correct middleware signature
-func isValidMiddlewareSignature(fn *ast.FuncDecl) bool {
-	// Check for: func(next nexo.HandlerFunc) nexo.HandlerFunc
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	// Check parameter type
-	param := fn.Type.Params.List[0]
-	switch x := param.Type.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name != "nexo" || x.Sel.Name != "HandlerFunc" {
-				return false
-			}
-		} else {
-			return false
-		}
-	case *ast.Ident:
-		if x.Name != "HandlerFunc" {
-			return false
-		}
-	default:
-		return false
-	}
-
-	// Check return type
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	result := fn.Type.Results.List[0]
-	switch x := result.Type.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			return ident.Name == "nexo" && x.Sel.Name == "HandlerFunc"
-		}
-	case *ast.Ident:
-		return x.Name == "HandlerFunc"
-	}
-
-	return false
+// bodyHandlerType checks if a function has the signature
+// func(c *nexo.Context, body T) error, with an optional leading
+// context.Context, where T is a type declared in the route package, and
+// returns T.
+func bodyHandlerType(fn *ast.FuncDecl) (string, bool) {
+	bodyType := scanner.BodyType(fn)
+	return bodyType, bodyType != ""
 }
 
-// isValidProxySignature checks if a function has the correct proxy signature
-func isValidProxySignature(fn *ast.FuncDecl) bool {
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	param := fn.Type.Params.List[0]
-	starExpr, ok := param.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	switch x := starExpr.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name != "nexo" || x.Sel.Name != "Context" {
-				return false
-			}
-		} else {
-			return false
-		}
-	case *ast.Ident:
-		if x.Name != "Context" {
-			return false
-		}
-	default:
-		return false
-	}
-
-	// Check return types: (*ProxyResult, error)
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 2 {
-		return false
-	}
-
-	// First return: *ProxyResult
-	result0 := fn.Type.Results.List[0]
-	starResult, ok := result0.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	switch x := starResult.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name != "nexo" || x.Sel.Name != "ProxyResult" {
-				return false
-			}
-		} else {
-			return false
-		}
-	case *ast.Ident:
-		if x.Name != "ProxyResult" {
-			return false
-		}
-	default:
-		return false
-	}
-
-	// Second return: error
-	result1 := fn.Type.Results.List[1]
-	if ident, ok := result1.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
-	}
-
-	return false
+// isValidMiddlewareSignature checks if a function has the signature the
+// routes file calls: func(next nexo.HandlerFunc) nexo.HandlerFunc
+func isValidMiddlewareSignature(fn *ast.FuncDecl) bool {
+	kind, ok := scanner.MiddlewareSignature(fn)
+	return ok && kind == scanner.MiddlewareDirect
 }
 
 // getImportPath returns the import path for a directory.
-// With Next.js-style naming ([id], [...slug], (group)), directories need bracket/paren characters removed for Go packages.
+// With Next.js-style naming ([id], [...slug], (group)), directories cannot be
+// imported directly, so their wrapper under GeneratedDir is used (see SyncWrappers).
 func getImportPath(moduleName, relDir string) string {
+	if needsWrapper(relDir) {
+		return moduleName + "/" + GeneratedDir + "/" + wrapperName(relDir)
+	}
 	return moduleName + "/" + filepath.ToSlash(relDir)
 }
@@ -1,11 +1,19 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
 )
 
 func TestGenerateRoute(t *testing.T) {
@@ -42,14 +50,14 @@ func TestGenerateRoute(t *testing.T) {
 			path:        "docs/[...slug]",
 			methods:     []string{"GET"},
 			wantFile:    "api/docs/[...slug]/route.go",
-			wantPattern: "/api/docs/*",
+			wantPattern: "/api/docs/{slug...}",
 		},
 		{
 			name:        "optional catch-all",
 			path:        "shop/[[...categories]]",
 			methods:     []string{"GET"},
 			wantFile:    "api/shop/[[...categories]]/route.go",
-			wantPattern: "/api/shop/*",
+			wantPattern: "/api/shop/{categories...?}",
 		},
 		{
 			name:        "nested route",
@@ -108,6 +116,99 @@ func TestGenerateRoute(t *testing.T) {
 	}
 }
 
+func TestGenerateRoute_ListHandler(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantList bool
+	}{
+		{"users", true},
+		{"v1/users/[id]/posts", true},
+		{"(admin)/users", true},
+		{"users/[id]", false},
+		{"docs/[...slug]", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			appDir := filepath.Join(t.TempDir(), "app")
+			result, err := GenerateRoute(RouteConfig{Path: tt.path, Methods: []string{"GET", "POST"}, AppDir: appDir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(result.Files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), "route.go", content, 0); err != nil {
+				t.Fatalf("generated route doesn't parse: %v\n%s", err, content)
+			}
+			if got := strings.Contains(string(content), "nexo.SendPage(c, nexo.NewPage(items, page, total))"); got != tt.wantList {
+				t.Errorf("list handler generated = %v, want %v:\n%s", got, tt.wantList, content)
+			}
+			if strings.Count(string(content), "SendPage") > 1 {
+				t.Errorf("only Get should answer with a page:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestGenerateRoute_TypedParams(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	result, err := GenerateRoute(RouteConfig{Path: "users/[id:int]/orders/[order:uuid]/[slug:re([a-z]+)]", Methods: []string{"GET"}, AppDir: appDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(result.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`id := c.ParamInt("id", 0)`, `order := c.ParamUUID("order")`, `slug := c.Param("slug")`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("generated route lacks %s:\n%s", want, content)
+		}
+	}
+	if got := pathToPattern("users/[id:int]/orders/[order:uuid]/[slug:re([a-z]+)]"); got != "users/{id:int}/orders/{order:uuid}/{slug:[a-z]+}" {
+		t.Errorf("pathToPattern() = %q", got)
+	}
+
+	page := PageRegistration{ImportAlias: "users", Params: []PageParam{
+		{Name: "id", Type: "int", FromPath: true},
+		{Name: "order", Type: "uuid.UUID", FromPath: true},
+		{Name: "tab", Type: "string"},
+	}}
+	if got := pageCall(page); got != `users.Page(c.ParamInt("id", 0), c.ParamUUID("order"), "")` {
+		t.Errorf("pageCall() = %s", got)
+	}
+	if hasComplexParams(page.Params) {
+		t.Error("hasComplexParams() = true for int and uuid.UUID parameters")
+	}
+}
+
+func TestGenerateRoute_CatchAllParams(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	result, err := GenerateRoute(RouteConfig{Path: "docs/[...slug]", Methods: []string{"GET"}, AppDir: appDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(result.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `slug := c.ParamAll("slug")`; !strings.Contains(string(content), want) {
+		t.Errorf("generated route lacks %s:\n%s", want, content)
+	}
+
+	page := PageRegistration{ImportAlias: "docs", Params: []PageParam{
+		{Name: "lang", Type: "string", FromPath: true},
+		{Name: "slug", Type: "[]string", FromPath: true},
+	}}
+	if got := pageCall(page); got != `docs.Page(c.Param("lang"), c.ParamAll("slug"))` {
+		t.Errorf("pageCall() = %s", got)
+	}
+	if hasComplexParams(page.Params) {
+		t.Error("hasComplexParams() = true for a []string catch-all parameter")
+	}
+}
+
 func TestGenerateRoute_AlreadyExists(t *testing.T) {
 	tmpDir := t.TempDir()
 	appDir := filepath.Join(tmpDir, "app")
@@ -136,6 +237,195 @@ func TestGenerateRoute_AlreadyExists(t *testing.T) {
 	}
 }
 
+func TestGenerateRoute_Split(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+
+	result, err := GenerateRoute(RouteConfig{
+		Path:    "users/[id]",
+		Methods: []string{"GET", "delete"},
+		AppDir:  appDir,
+		Split:   true,
+	})
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	dir := filepath.Join(appDir, "api", "users", "[id]")
+	want := []string{filepath.Join(dir, "get.go"), filepath.Join(dir, "delete.go")}
+	if !slices.Equal(result.Files, want) {
+		t.Fatalf("Files = %v, want %v", result.Files, want)
+	}
+	for i, funcName := range []string{"Get", "Delete"} {
+		content, err := os.ReadFile(want[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "func "+funcName+"(") || strings.Count(string(content), "func ") != 1 {
+			t.Errorf("%s should hold only %s:\n%s", want[i], funcName, content)
+		}
+	}
+
+	// Adding a method keeps the existing files; redeclaring one fails
+	if _, err := GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"PUT"}, AppDir: appDir, Split: true}); err != nil {
+		t.Errorf("adding put.go: %v", err)
+	}
+	_, err = GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"GET", "PATCH"}, AppDir: appDir})
+	if err == nil || !strings.Contains(err.Error(), "Get is already declared in") {
+		t.Errorf("redeclaring Get in route.go: error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "route.go")); !os.IsNotExist(err) {
+		t.Error("route.go was written despite the error")
+	}
+
+	_, err = GenerateRoute(RouteConfig{Path: "users", Methods: []string{"CONNECT"}, AppDir: appDir, Split: true})
+	if err == nil || !strings.Contains(err.Error(), "unsupported method") {
+		t.Errorf("CONNECT with Split: error = %v", err)
+	}
+}
+
+func TestGenerateRoute_MemFS(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	fsys := genfs.NewMemFS(genfs.Disk)
+
+	if _, err := GenerateRoute(RouteConfig{Path: "users", Methods: []string{"GET"}, AppDir: appDir, FS: fsys}); err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	// Later generators see the earlier writes
+	result, err := GenerateRoute(RouteConfig{Path: "users", Methods: []string{"GET", "POST"}, AppDir: appDir, FS: fsys, AddMethod: true})
+	if err != nil {
+		t.Fatalf("GenerateRoute() with AddMethod error = %v", err)
+	}
+	if !slices.Equal(result.Skipped, []string{"GET"}) {
+		t.Errorf("Skipped = %v, want GET", result.Skipped)
+	}
+	if _, err := os.Stat(appDir); !os.IsNotExist(err) {
+		t.Error("GenerateRoute wrote to disk")
+	}
+
+	changes := fsys.Changes()
+	routeFile := filepath.Join(appDir, "api", "users", "route.go")
+	if len(changes) != 1 || changes[0].Op != genfs.Create || changes[0].Path != routeFile {
+		t.Fatalf("Changes() = %+v, want %s created", changes, routeFile)
+	}
+	for _, want := range []string{"+func Get(c *nexo.Context) error", "+func Post(c *nexo.Context) error"} {
+		if !strings.Contains(changes[0].Diff(), want) {
+			t.Errorf("diff is missing %q:\n%s", want, changes[0].Diff())
+		}
+	}
+}
+
+func TestGenerateRoute_AddMethod(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	dir := filepath.Join(appDir, "api", "users", "[id]")
+	routeFile := filepath.Join(dir, "route.go")
+
+	// A GET-only route the user has edited
+	userCode := `package users
+
+import (
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Get returns a user.
+func Get(c *nexo.Context) error {
+	return c.JSON(200, map[string]any{"id":   c.Param("id")}) // keep this spacing
+}
+`
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(routeFile, []byte(userCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"GET", "PUT", "DELETE"}, AppDir: appDir, AddMethod: true})
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if !slices.Equal(result.Updated, []string{routeFile}) || len(result.Files) != 0 || !slices.Equal(result.Skipped, []string{"GET"}) {
+		t.Errorf("result = %+v, want route.go updated and GET skipped", result)
+	}
+
+	content, err := os.ReadFile(routeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), userCode) {
+		t.Errorf("existing code changed:\n%s", content)
+	}
+	for _, want := range []string{"type PutInput struct", "func Put(c *nexo.Context) error", "func Delete(c *nexo.Context) error"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("route.go is missing %q", want)
+		}
+	}
+	if strings.Count(string(content), "func Get(") != 1 || strings.Count(string(content), "import") != 1 {
+		t.Errorf("route.go duplicated a declaration:\n%s", content)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), routeFile, content, 0); err != nil {
+		t.Errorf("route.go does not parse: %v", err)
+	}
+
+	// Nothing left to add
+	result, err = GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"PUT"}, AppDir: appDir, AddMethod: true})
+	if err != nil || len(result.Updated)+len(result.Files) != 0 || !slices.Equal(result.Skipped, []string{"PUT"}) {
+		t.Errorf("re-adding PUT: result = %+v, err = %v", result, err)
+	}
+
+	// New split files join the existing package
+	if _, err := GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"PATCH"}, AppDir: appDir, AddMethod: true, Split: true}); err != nil {
+		t.Fatal(err)
+	}
+	patch, _ := os.ReadFile(filepath.Join(dir, "patch.go"))
+	if !strings.HasPrefix(string(patch), "package users\n") {
+		t.Errorf("patch.go package:\n%s", patch)
+	}
+
+	// A type the template declares can't shadow the user's
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package users\n\ntype PostInput struct{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = GenerateRoute(RouteConfig{Path: "users/[id]", Methods: []string{"POST"}, AppDir: appDir, AddMethod: true})
+	if err == nil || !strings.Contains(err.Error(), "PostInput is already declared in") {
+		t.Errorf("POST with a declared PostInput: error = %v", err)
+	}
+}
+
+func TestAddImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "no imports",
+			src:  "package users\n\nfunc helper() {}\n",
+			want: "package users\n\nimport (\n\t\"fmt\"\n)\n\nfunc helper() {}\n",
+		},
+		{
+			name: "single import",
+			src:  "package users\n\nimport \"net/http\"\n",
+			want: "package users\n\nimport (\n\t\"net/http\"\n\t\"fmt\"\n)\n",
+		},
+		{
+			name: "import block",
+			src:  "package users\n\nimport (\n\t\"net/http\"\n)\n",
+			want: "package users\n\nimport (\n\t\"net/http\"\n\t\"fmt\"\n)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "", tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(addImports([]byte(tt.src), fset, file, []string{`"fmt"`})); got != tt.want {
+				t.Errorf("addImports() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGenerateMiddleware(t *testing.T) {
 	templates := []string{"blank", "auth", "logging", "timing", "cors"}
 
@@ -196,7 +486,7 @@ func TestGenerateMiddleware_UnknownTemplate(t *testing.T) {
 }
 
 func TestGenerateProxy(t *testing.T) {
-	templates := []string{"blank", "auth-check", "rate-limit", "maintenance", "redirect-www"}
+	templates := []string{"blank", "auth-check", "rate-limit", "maintenance", "redirect-www", "ab-test"}
 
 	for _, tmpl := range templates {
 		t.Run(tmpl, func(t *testing.T) {
@@ -250,6 +540,62 @@ func TestGenerateProxy_UnknownTemplate(t *testing.T) {
 	}
 }
 
+func TestGenerateWebhook(t *testing.T) {
+	tests := []struct {
+		provider string
+		verifier string
+		handles  string
+	}{
+		{"stripe", `nexo.StripeSignature(os.Getenv("STRIPE_WEBHOOK_SECRET"))`, `case "invoice.paid":`},
+		{"github", `nexo.GitHubSignature(os.Getenv("GITHUB_WEBHOOK_SECRET"))`, `c.Header("X-GitHub-Event")`},
+		{"slack", `nexo.SlackSignature(os.Getenv("SLACK_SIGNING_SECRET"))`, `"url_verification"`},
+		{"shopify", `nexo.ShopifySignature(os.Getenv("SHOPIFY_WEBHOOK_SECRET"))`, `c.Header("X-Shopify-Topic")`},
+		{"acme-pay", `nexo.HMACSignature("X-Signature", "", os.Getenv("ACME_PAY_WEBHOOK_SECRET"))`, `Acme Pay webhooks`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			appDir := filepath.Join(t.TempDir(), "app")
+
+			result, err := GenerateWebhook(WebhookConfig{Provider: tt.provider, AppDir: appDir})
+			if err != nil {
+				t.Fatalf("GenerateWebhook(%s) error = %v", tt.provider, err)
+			}
+			if result.Pattern != "/webhooks/"+tt.provider || len(result.Files) != 2 {
+				t.Errorf("result = %+v", result)
+			}
+
+			dir := filepath.Join(appDir, "webhooks", tt.provider)
+			middleware, err := os.ReadFile(filepath.Join(dir, "middleware.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(middleware), tt.verifier) {
+				t.Errorf("middleware.go doesn't use %s:\n%s", tt.verifier, middleware)
+			}
+			route, err := os.ReadFile(filepath.Join(dir, "route.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(route), "func Post(c *nexo.Context) error") || !strings.Contains(string(route), tt.handles) {
+				t.Errorf("route.go = %s", route)
+			}
+
+			if _, err := GenerateWebhook(WebhookConfig{Provider: tt.provider, AppDir: appDir}); err == nil {
+				t.Error("Expected error when the webhook already exists")
+			}
+		})
+	}
+}
+
+func TestGenerateWebhook_InvalidProvider(t *testing.T) {
+	for _, provider := range []string{"", "../stripe", "a/b"} {
+		if _, err := GenerateWebhook(WebhookConfig{Provider: provider, AppDir: t.TempDir()}); err == nil {
+			t.Errorf("GenerateWebhook(%q) succeeded", provider)
+		}
+	}
+}
+
 func TestGeneratePage(t *testing.T) {
 	t.Run("simple page", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -341,8 +687,8 @@ func TestPathToPattern(t *testing.T) {
 	}{
 		{"users", "users"},
 		{"users/[id]", "users/{id}"},
-		{"docs/[...slug]", "docs/*"},
-		{"shop/[[...cat]]", "shop/*"},
+		{"docs/[...slug]", "docs/{slug...}"},
+		{"shop/[[...cat]]", "shop/{cat...?}"},
 		{"(admin)/settings", "settings"},
 		{"(auth)/login", "login"},
 		{"(dashboard)/apps", "apps"},
@@ -625,7 +971,7 @@ func TestDirToPattern(t *testing.T) {
 	}{
 		{"app/api/users", "app", "/api/users"},
 		{"app/api/users/[id]", "app", "/api/users/{id}"},
-		{"app/api/docs/[...slug]", "app", "/api/docs/*"},
+		{"app/api/docs/[...slug]", "app", "/api/docs/{slug...}"},
 		{"app/api/(admin)/settings", "app", "/api/settings"},
 		{"app/(auth)/login", "app", "/login"},
 		{"app/(auth)/callback", "app", "/callback"},
@@ -654,7 +1000,7 @@ func TestPagePathToPattern(t *testing.T) {
 		{"app", "app", "/"},
 		{"app/about", "app", "/about"},
 		{"app/users/[id]", "app", "/users/{id}"},
-		{"app/docs/[...slug]", "app", "/docs/*"},
+		{"app/docs/[...slug]", "app", "/docs/{slug...}"},
 		{"app/(marketing)/about", "app", "/about"},
 		{"app/(auth)/login", "app", "/login"},
 		{"app/(auth)/callback", "app", "/callback"},
@@ -884,6 +1230,151 @@ func TestValidatePageParams(t *testing.T) {
 	}
 }
 
+func TestGenerateRoutesFile_Deterministic(t *testing.T) {
+	// Two packages named users compete for the alias
+	routes := []RouteRegistration{
+		{ImportPath: "testapp/app/api/users", Package: "users", Method: "POST", Pattern: "/api/users", Handler: "Post", FilePath: "app/api/users/route.go"},
+		{ImportPath: "testapp/app/api/users", Package: "users", Method: "GET", Pattern: "/api/users", Handler: "Get", FilePath: "app/api/users/route.go"},
+		{ImportPath: "testapp/app/admin/users", Package: "users", Method: "GET", Pattern: "/admin/users", Handler: "Get", FilePath: "app/admin/users/route.go"},
+	}
+	middlewares := []MiddlewareRegistration{
+		{ImportPath: "testapp/app/api/users", Package: "users", PathPrefix: "/api/users", FilePath: "app/api/users/middleware.go"},
+		{ImportPath: "testapp/app/api", Package: "api", PathPrefix: "/api", FilePath: "app/api/middleware.go"},
+	}
+
+	render := func(reverse bool) []byte {
+		t.Helper()
+		cfg := RoutesGenConfig{ModuleName: "testapp", Routes: slices.Clone(routes), Middlewares: slices.Clone(middlewares)}
+		if reverse {
+			slices.Reverse(cfg.Routes)
+			slices.Reverse(cfg.Middlewares)
+		}
+		content, err := renderRoutesFile(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	first := render(false)
+	if second := render(true); !bytes.Equal(first, second) {
+		t.Errorf("output depends on scan order:\n%s\n---\n%s", first, second)
+	}
+	if !bytes.Contains(first, []byte("\n// Content hash: sha256:")) {
+		t.Errorf("missing content hash header:\n%s", first)
+	}
+	admin, api := bytes.Index(first, []byte(`"/admin/users"`)), bytes.Index(first, []byte(`"GET", "/api/users"`))
+	if admin < 0 || api < 0 || admin > api || !bytes.Contains(first, []byte(`users "testapp/app/admin/users"`)) {
+		t.Errorf("routes are not registered in pattern order:\n%s", first)
+	}
+
+	// Regenerating without changes leaves the file alone
+	outputPath := filepath.Join(t.TempDir(), "nexo_routes.go")
+	cfg := RoutesGenConfig{ModuleName: "testapp", OutputPath: outputPath, Routes: routes}
+	if _, err := GenerateRoutesFile(cfg); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(outputPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateRoutesFile(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(outputPath); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("unchanged routes file was rewritten")
+	}
+}
+
+func TestGenerateRoutesFile_Split(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "nexo_routes.go")
+	route := func(pkg, method, pattern string) RouteRegistration {
+		return RouteRegistration{ImportPath: "testapp/app" + pattern, Package: pkg, Method: method, Pattern: pattern, Handler: "Get", FilePath: "app" + pattern + "/route.go"}
+	}
+	cfg := RoutesGenConfig{
+		ModuleName: "testapp",
+		OutputPath: outputPath,
+		Split:      true,
+		Routes: []RouteRegistration{
+			route("users", "GET", "/api/users"),
+			route("id", "GET", "/api/users/{id}"),
+			route("settings", "GET", "/admin/settings"),
+			route("linux", "GET", "/linux"),
+		},
+		Pages: []PageRegistration{
+			{ImportPath: "testapp/app", Package: "app", Pattern: "/", FilePath: "app/page.templ"},
+		},
+	}
+	// A file of the user's next to the routes file
+	userFile := filepath.Join(dir, "nexo_routes_extra.go")
+	if err := os.WriteFile(userFile, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := GenerateRoutesFile(cfg)
+	if err != nil {
+		t.Fatalf("GenerateRoutesFile() error = %v", err)
+	}
+	want := []string{
+		outputPath,
+		filepath.Join(dir, "nexo_routes_admin.go"),
+		filepath.Join(dir, "nexo_routes_linux_section.go"),
+		filepath.Join(dir, "nexo_routes_users.go"),
+	}
+	if !slices.Equal(result.Files, want) {
+		t.Errorf("Files = %v, want %v", result.Files, want)
+	}
+
+	contents := make(map[string]string)
+	for _, path := range result.Files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), path, content, 0); err != nil {
+			t.Errorf("%s does not parse: %v", path, err)
+		}
+		contents[filepath.Base(path)] = string(content)
+	}
+	for file, wants := range map[string][]string{
+		"nexo_routes.go": {
+			"func RegisterRoutes(app *nexo.App) {",
+			`app.Get("/", func(c *nexo.Context) error {`,
+			"registerAdminRoutes(app)",
+			"registerUsersRoutes(app)",
+		},
+		"nexo_routes_users.go": {
+			"func registerUsersRoutes(app *nexo.App) {",
+			`app.RegisterRoute("GET", "/api/users", users.Get)`,
+			`app.RegisterRoute("GET", "/api/users/{id}", id.Get)`,
+		},
+		"nexo_routes_linux_section.go": {"func registerLinuxRoutes(app *nexo.App) {"},
+	} {
+		for _, w := range wants {
+			if !strings.Contains(contents[file], w) {
+				t.Errorf("%s is missing %q:\n%s", file, w, contents[file])
+			}
+		}
+	}
+	if strings.Contains(contents["nexo_routes.go"], "/api/users") {
+		t.Errorf("nexo_routes.go registers a sectioned route:\n%s", contents["nexo_routes.go"])
+	}
+
+	// Turning splitting off removes the generated sections only
+	cfg.Split = false
+	if _, err := GenerateRoutesFile(cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range want[1:] {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", path)
+		}
+	}
+	if _, err := os.Stat(userFile); err != nil {
+		t.Errorf("user file removed: %v", err)
+	}
+}
+
 func TestGenerateRoutesFile_WithDynamicPages(t *testing.T) {
 	t.Run("page with params", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -1407,8 +1898,9 @@ func TestGetImportPath(t *testing.T) {
 	}{
 		{"myapp", "app/api/users", "myapp/app/api/users"},
 		{"github.com/user/project", "app/api", "github.com/user/project/app/api"},
-		{"myapp", "app/api/users/[id]", "myapp/app/api/users/[id]"},
-		{"myapp", "app/(admin)/settings", "myapp/app/(admin)/settings"},
+		{"myapp", "app/api/users/[id]", "myapp/.nexo/generated/wrappers/app_api_users_id"},
+		{"myapp", "app/(admin)/settings", "myapp/.nexo/generated/wrappers/app_admin_settings"},
+		{"myapp", "app/docs/[[...slug]]", "myapp/.nexo/generated/wrappers/app_docs_slug"},
 	}
 
 	for _, tt := range tests {
@@ -1569,3 +2061,926 @@ func TestRemoveGetHandlerForPattern(t *testing.T) {
 		t.Error("DELETE /dashboard should be preserved")
 	}
 }
+
+func TestScanGraphQLResolver(t *testing.T) {
+	resolver := `package graphql
+
+import (
+	"context"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func Execute(ctx context.Context, req *nexo.GraphQLRequest) *nexo.GraphQLResponse {
+	return &nexo.GraphQLResponse{}
+}
+`
+
+	t.Run("schema and resolver", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		appDir := "app"
+		dir := filepath.Join(appDir, "graphql")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.WriteFile(filepath.Join(dir, "resolver.go"), []byte(resolver), 0644)
+		_ = os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte("type Query { hello: String }"), 0644)
+
+		gql, err := scanGraphQLResolver(token.NewFileSet(), filepath.Join(dir, "resolver.go"), appDir, "example.com/app")
+		if err != nil {
+			t.Fatalf("scanGraphQLResolver() error = %v", err)
+		}
+		if gql == nil {
+			t.Fatal("expected GraphQL registration")
+		}
+		if gql.Pattern != "/graphql" {
+			t.Errorf("Pattern = %q, want /graphql", gql.Pattern)
+		}
+		if gql.Package != "graphql" {
+			t.Errorf("Package = %q, want graphql", gql.Package)
+		}
+		if gql.SchemaPath != filepath.Join(dir, "schema.graphql") {
+			t.Errorf("SchemaPath = %q", gql.SchemaPath)
+		}
+	})
+
+	t.Run("missing schema", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		appDir := "app"
+		dir := filepath.Join(appDir, "graphql")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.WriteFile(filepath.Join(dir, "resolver.go"), []byte(resolver), 0644)
+
+		gql, err := scanGraphQLResolver(token.NewFileSet(), filepath.Join(dir, "resolver.go"), appDir, "example.com/app")
+		if err != nil || gql != nil {
+			t.Errorf("scanGraphQLResolver() = %v, %v; want nil, nil", gql, err)
+		}
+	})
+}
+
+func TestScanRouteFile_RouteConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "api", "orders")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{
+			name: "with RouteConfig",
+			source: `package orders
+
+var RouteConfig = nexo.RouteConfig{Timeout: 5 * time.Second}
+
+func Post(c *nexo.Context) error { return nil }
+`,
+			want: true,
+		},
+		{
+			name: "body handler with RouteConfig",
+			source: `package orders
+
+var RouteConfig = nexo.RouteConfig{}
+
+type PostBody struct{ ID string }
+
+func Post(c *nexo.Context, body PostBody) error { return nil }
+`,
+			want: true,
+		},
+		{
+			name: "without RouteConfig",
+			source: `package orders
+
+func Post(c *nexo.Context) error { return nil }
+`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "route.go")
+			if err := os.WriteFile(path, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			routes, err := scanRouteFile(token.NewFileSet(), path, "app", "example.com/app")
+			if err != nil {
+				t.Fatalf("scanRouteFile() error = %v", err)
+			}
+			if len(routes) != 1 {
+				t.Fatalf("expected 1 route, got %d", len(routes))
+			}
+			if routes[0].HasConfig != tt.want {
+				t.Errorf("HasConfig = %v, want %v", routes[0].HasConfig, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRouteFile_CORS(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "api", "public")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"route.go": "package public\n\nvar CORS = nexo.CORSConfig{AllowOrigins: []string{\"*\"}}\n",
+		"get.go":   "package public\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The policy in route.go applies to the handler in get.go
+	routes, err := scanRouteFile(token.NewFileSet(), filepath.Join(dir, "get.go"), "app", "example.com/app")
+	if err != nil {
+		t.Fatalf("scanRouteFile() error = %v", err)
+	}
+	if len(routes) != 1 || !routes[0].HasCORS || routes[0].HasConfig {
+		t.Errorf("routes = %+v, want one with HasCORS", routes)
+	}
+}
+
+func TestScanAndGenerateRoutes_MethodFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "api", "orders")
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		filepath.Join(dir, "route.go"): `package orders
+
+var RouteConfig = nexo.RouteConfig{Timeout: 5 * time.Second}
+`,
+		filepath.Join(dir, "get.go"): `package orders
+
+func Get(c *nexo.Context) error { return nil }
+`,
+		filepath.Join(dir, "post.go"): `package orders
+
+func Post(c *nexo.Context) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(out)
+
+	for _, want := range []string{"orders.Get", "orders.Post", "orders.RouteConfig"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated routes missing %q", want)
+		}
+	}
+	if n := strings.Count(content, `"example.com/app/app/api/orders"`); n != 1 {
+		t.Errorf("orders package imported %d times, want 1", n)
+	}
+}
+
+func TestScanAndGenerateRoutes_ContextHandlers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "api", "orders")
+	files := map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.22\n",
+		"main.go": "package main\n\nimport \"database/sql\"\n\nfunc main() {\n\tvar db *sql.DB\n\tapp.Provide(db)\n}\n",
+		filepath.Join(dir, "route.go"): `package orders
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+type CreateOrder struct{ Item string }
+
+func Get(ctx context.Context, c *nexo.Context) error { return nil }
+
+func Post(ctx context.Context, c *nexo.Context, body CreateOrder) error { return nil }
+
+func Delete(ctx context.Context, c *nexo.Context, db *sql.DB) error { return nil }
+
+func Put(ctx, other context.Context, c *nexo.Context) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(out)
+
+	for _, want := range []string{
+		"nexo.WithContext(orders.Get)",
+		"nexo.WithBodyContext(orders.Post)",
+		"nexo.InjectContext1(app, orders.Delete)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated routes missing %q", want)
+		}
+	}
+	if strings.Contains(content, "orders.Put") {
+		t.Error("generated routes register Put, which takes two contexts")
+	}
+
+	loaderExpr := routeTemplateFuncs["loaderExpr"].(func(PageRegistration) string)
+	tests := []struct {
+		page PageRegistration
+		want string
+	}{
+		{PageRegistration{ImportAlias: "users"}, "users.Loader"},
+		{PageRegistration{ImportAlias: "users", LoaderContext: true}, "nexo.WithLoaderContext(users.Loader)"},
+		{PageRegistration{ImportAlias: "users", LoaderContext: true, LoaderDeps: []string{"*database/sql.DB"}}, "nexo.InjectLoaderContext1(app, users.Loader)"},
+	}
+	for _, tt := range tests {
+		if got := loaderExpr(tt.page); got != tt.want {
+			t.Errorf("loaderExpr(%+v) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestScanAndGenerateRoutes_NamedMiddleware(t *testing.T) {
+	t.Chdir(t.TempDir())
+	mw := func(pkg string) string {
+		return "package " + pkg + "\n\nfunc Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }\n"
+	}
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		filepath.Join("app", "_middleware", "auth", "middleware.go"):      mw("auth"),
+		filepath.Join("app", "_middleware", "ratelimit", "middleware.go"): mw("ratelimit"),
+		filepath.Join("app", "api", "orders", "middleware.go"): "// nexo:use ratelimit, auth\n" + mw("orders"),
+		filepath.Join("app", "(admin)", "reports", "route.go"): `// nexo:use auth
+package reports
+
+func Get(c *nexo.Context) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(out)
+
+	// Named middleware runs first, in the order listed, and the registry
+	// itself is no route
+	var got []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, "AddMiddleware(") || strings.Contains(line, "AddNamedMiddleware(") {
+			got = append(got, strings.TrimSpace(line))
+		}
+	}
+	want := []string{
+		`app.RouteTree().AddNamedMiddleware("/api/orders", "api/orders", "ratelimit", ratelimit.Middleware)`,
+		`app.RouteTree().AddNamedMiddleware("/api/orders", "api/orders", "auth", auth.Middleware)`,
+		`app.RouteTree().AddMiddleware("/api/orders", "api/orders", orders.Middleware)`,
+		`app.RouteTree().AddNamedMiddleware("/reports", "(admin)/reports", "auth", auth.Middleware)`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("middleware registrations =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if n := strings.Count(content, `"example.com/app/app/_middleware/auth"`); n != 1 {
+		t.Errorf("auth package imported %d times, want 1", n)
+	}
+
+	// Unknown names fail the generation
+	if err := os.WriteFile(filepath.Join("app", "(admin)", "reports", "doc.go"), []byte("// nexo:use audit\npackage reports\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err == nil || !strings.Contains(err.Error(), `unknown middleware "audit"`) {
+		t.Errorf("ScanAndGenerateRoutes() with an unknown middleware error = %v", err)
+	}
+}
+
+func TestScanAndGenerateRoutes_MiddlewareFilters(t *testing.T) {
+	t.Chdir(t.TempDir())
+	login := func(directive string) string {
+		return "package login\n\n// " + directive + "\nfunc Post(c *nexo.Context) error { return nil }\n"
+	}
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		filepath.Join("app", "_middleware", "auth", "middleware.go"): "package auth\n\nfunc Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }\n",
+		filepath.Join("app", "api", "middleware.go"):                 "// nexo:use auth\npackage api\n\nfunc Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }\n",
+		filepath.Join("app", "api", "auth", "login", "route.go"):     login("nexo:route skip=auth"),
+		filepath.Join("app", "api", "health", "route.go"):            "package health\n\n// nexo:route only=/api\nfunc Get(c *nexo.Context) error { return nil }\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`app.SkipRouteMiddleware("POST", "/api/auth/login", "auth")`,
+		`app.OnlyRouteMiddleware("GET", "/api/health", "/api")`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated code lacks %s:\n%s", want, out)
+		}
+	}
+
+	// Names the route doesn't inherit fail the generation
+	if err := os.WriteFile(filepath.Join("app", "api", "auth", "login", "route.go"), []byte(login("nexo:route skip=/admin")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err == nil || !strings.Contains(err.Error(), `no inherited middleware "/admin"`) {
+		t.Errorf("ScanAndGenerateRoutes() skipping middleware that doesn't apply: error = %v", err)
+	}
+}
+
+func TestScanAndGenerateRoutes_MaintenancePage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		filepath.Join("app", "maintenance.templ"): `package app
+
+templ Maintenance() {
+	<h1>Back soon</h1>
+}
+`,
+		// Only the maintenance page of the app root is registered
+		filepath.Join("app", "docs", "maintenance.templ"): `package docs
+
+templ Maintenance() {
+	<h1>Docs are back soon</h1>
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(out)
+
+	if !strings.Contains(content, "app.SetMaintenancePage(app2.Maintenance())") {
+		t.Errorf("generated routes don't register the maintenance page:\n%s", content)
+	}
+	if strings.Contains(content, "docs.Maintenance") {
+		t.Error("generated routes register a nested maintenance page")
+	}
+}
+
+func TestScanAndGenerateRoutes_SlotsAndIntercepts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod":                              "module example.com/app\n\ngo 1.22\n",
+		"app/dashboard/layout.templ":          "package dashboard\n\ntempl Layout() {\n\t{ children... }\n}\n",
+		"app/dashboard/page.templ":            "package dashboard\n\ntempl Page() {}\n",
+		"app/dashboard/settings/page.templ":   "package settings\n\ntempl Page() {}\n",
+		"app/dashboard/@team/page.templ":      "package team\n\ntempl Page() {}\n",
+		"app/dashboard/@team/default.templ":   "package team\n\ntempl Default() {}\n",
+		"app/dashboard/@stats/page.templ":     "package stats\n\ntempl Page() {}\n",
+		"app/photos/[id]/page.templ":          "package id\n\ntempl Page(id string) {}\n",
+		"app/feed/page.templ":                 "package feed\n\ntempl Page() {}\n",
+		"app/feed/(..)photos/[id]/page.templ": "package id\n\ntempl Page(id string) {}\n",
+		"app/feed/(..)videos/page.templ":      "package videos\n\ntempl Page() {}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(out)
+
+	for _, want := range []string{
+		`Slots: nexo.Slots{"stats": stats_page.Page(), "team": team_page.Page()}`,
+		`Slots: nexo.Slots{"team": team_page.Default()}`,
+		`app.InterceptRoute("/photos/{id}", "/feed", func(c *nexo.Context) error {`,
+		`return nexo.TemplComponent(c, 200, id_page2.Page(c.Param("id")))`,
+		`"example.com/app/.nexo/generated/wrappers/app_feed_photos_id"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated routes missing %q:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{`app.Get("/photos/{id}", func(c *nexo.Context) error {
+		id := c.Param("id")
+		return nexo.TemplComponent(c, 200, id_page2`, `app.Get("/videos"`} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("slot or intercepting page registered as a route: %q", unwanted)
+		}
+	}
+	if n := strings.Count(content, `app.Get("/dashboard"`); n != 1 {
+		t.Errorf("/dashboard registered %d times, want 1", n)
+	}
+}
+
+func TestScanRouteFile_PriorityDirective(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "docs", "changelog")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	source := `// nexo:priority 110
+package changelog
+
+// nexo:priority 120
+func Get(c *nexo.Context) error { return nil }
+
+func Post(c *nexo.Context) error { return nil }
+`
+	path := filepath.Join(dir, "route.go")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := scanRouteFile(token.NewFileSet(), path, "app", "example.com/app")
+	if err != nil {
+		t.Fatalf("scanRouteFile() error = %v", err)
+	}
+
+	want := map[string]int{"GET": 120, "POST": 110}
+	for _, r := range routes {
+		if !r.HasPriority || r.Priority != want[r.Method] {
+			t.Errorf("%s priority = %d (set %v), want %d", r.Method, r.Priority, r.HasPriority, want[r.Method])
+		}
+	}
+}
+
+func TestScanRouteFile_RouteDirective(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("app", "posts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	source := `package posts
+
+// nexo:route methods=GET,HEAD cache=60s
+func Get(c *nexo.Context) error { return nil }
+
+// nexo:route auth=required
+func Post(c *nexo.Context) error { return nil }
+`
+	path := filepath.Join(dir, "route.go")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := scanRouteFile(token.NewFileSet(), path, "app", "example.com/app")
+	if err != nil {
+		t.Fatalf("scanRouteFile() error = %v", err)
+	}
+
+	var got []string
+	for _, r := range routes {
+		got = append(got, r.Method+" "+r.Handler+" "+strings.Join(r.Options.Middleware(), ", "))
+	}
+	want := []string{
+		"GET Get nexo.CacheResponse(1 * time.Minute)",
+		"HEAD Get nexo.CacheResponse(1 * time.Minute)",
+		"POST Post nexo.RequireAuth()",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("routes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := os.WriteFile(path, []byte("package posts\n\n// nexo:route cache=later\nfunc Get(c *nexo.Context) error { return nil }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanRouteFile(token.NewFileSet(), path, "app", "example.com/app"); err == nil {
+		t.Error("scanRouteFile() with an invalid directive succeeded")
+	}
+}
+
+func TestBodyHandlerType(t *testing.T) {
+	tests := []struct {
+		source   string
+		wantType string
+		wantOK   bool
+	}{
+		{"func Post(c *nexo.Context, body PostBody) error { return nil }", "PostBody", true},
+		{"func Post(c *Context, body PostBody) error { return nil }", "PostBody", true},
+		{"func Post(c *nexo.Context) error { return nil }", "", false},
+		{"func Post(c *nexo.Context, body string) error { return nil }", "", false},
+		{"func Post(c *nexo.Context, body PostBody) { }", "", false},
+		{"func Post(c *nexo.Context, body *PostBody) error { return nil }", "", false},
+		{"func Post(c, d *nexo.Context) error { return nil }", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), "route.go", "package p\n"+tt.source, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fn := file.Decls[0].(*ast.FuncDecl)
+
+			gotType, gotOK := bodyHandlerType(fn)
+			if gotType != tt.wantType || gotOK != tt.wantOK {
+				t.Errorf("bodyHandlerType() = %q, %v; want %q, %v", gotType, gotOK, tt.wantType, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScanAndGenerateRoutes_NestedLayouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	files := map[string]string{
+		"go.mod": "module testmodule\ngo 1.21\n",
+		"app/layout.templ": `package app
+
+var Metadata = nexo.Metadata{TitleTemplate: "%s | Acme"}
+
+templ Layout(title string) {
+	<html><title>{ title }</title><body>{ children... }</body></html>
+}
+`,
+		"app/dashboard/layout.templ": `package dashboard
+
+templ Layout() {
+	<nav></nav>{ children... }
+}
+`,
+		"app/dashboard/settings/page.templ": `package settings
+
+templ Page() {
+	<h1>Settings</h1>
+}
+`,
+		"app/dashboard/settings/metadata.go": `package settings
+
+func GenerateMetadata(c *nexo.Context) (nexo.Metadata, error) {
+	return nexo.Metadata{Title: "Settings"}, nil
+}
+`,
+		"app/legacy/page.templ": `package legacy
+
+templ Page() {
+	@Layout("Legacy") {
+		<h1>Legacy</h1>
+	}
+}
+`,
+		"app/legacy/layout.templ": `package legacy
+
+templ Layout(title string) {
+	<html>{ children... }</html>
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	content, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+
+	want := []string{
+		`app_layout "testmodule/app"`,
+		`dashboard_layout "testmodule/app/dashboard"`,
+		`return nexo.RenderPage(c, 200, settings_page.Page(),
+			nexo.LayoutSegment{Layout: nexo.TemplLayout(app_layout.Layout), Metadata: &app_layout.Metadata},
+			nexo.LayoutSegment{Layout: nexo.TemplLayoutNoTitle(dashboard_layout.Layout)},
+			nexo.LayoutSegment{GenerateMetadata: settings_page.GenerateMetadata},
+		)`,
+		// Pages that render @Layout themselves only get the root metadata
+		`return nexo.RenderPage(c, 200, legacy_page.Page(),
+			nexo.LayoutSegment{Metadata: &app_layout.Metadata},
+		)`,
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("generated file missing:\n%s\n\ngot:\n%s", w, got)
+		}
+	}
+}
+
+func TestParseSitemapDirective(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    PageSitemap
+		wantErr bool
+	}{
+		{name: "none", content: "templ Page() {}"},
+		{
+			name:    "annotations",
+			content: "// nexo:sitemap changefreq=weekly priority=0.8\ntempl Page() {}",
+			want:    PageSitemap{ChangeFreq: "weekly", Priority: "0.8"},
+		},
+		{name: "exclude", content: "//nexo:sitemap exclude\n", want: PageSitemap{Exclude: true}},
+		{name: "invalid changefreq", content: "// nexo:sitemap changefreq=often", wantErr: true},
+		{name: "priority out of range", content: "// nexo:sitemap priority=2", wantErr: true},
+		{name: "unknown setting", content: "// nexo:sitemap lastmod=today", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSitemapDirective(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSitemapDirective() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseSitemapDirective() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAndGenerateRoutes_Sitemap(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	files := map[string]string{
+		"go.mod": "module testmodule\ngo 1.21\n",
+		"app/about/page.templ": `package about
+
+// nexo:sitemap changefreq=monthly priority=0.5
+templ Page() {
+	<h1>About</h1>
+}
+`,
+		"app/admin/page.templ": `package admin
+
+// nexo:sitemap exclude
+templ Page() {
+	<h1>Admin</h1>
+}
+`,
+		"app/posts/[slug]/page.templ": `package slug
+
+templ Page(slug string) {
+	<h1>{ slug }</h1>
+}
+`,
+		"app/posts/[slug]/params.go": `package slug
+
+func GenerateStaticParams(ctx context.Context) ([]map[string]string, error) {
+	return []map[string]string{{"slug": "hello"}}, nil
+}
+`,
+		"app/users/[id]/page.templ": `package id
+
+templ Page(id string) {
+	<h1>{ id }</h1>
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	content, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+
+	want := []string{
+		`app.Sitemap().Add("/about", nexo.SitemapOptions{ChangeFreq: "monthly", Priority: 0.5})`,
+		`app.Sitemap().Add("/posts/{slug}", nexo.SitemapOptions{Params: slug_page.GenerateStaticParams})`,
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("generated file missing:\n%s\n\ngot:\n%s", w, got)
+		}
+	}
+	for _, pattern := range []string{"/admin", "/users/{id}"} {
+		if strings.Contains(got, `app.Sitemap().Add("`+pattern+`"`) {
+			t.Errorf("%s should not be in the sitemap", pattern)
+		}
+	}
+}
+
+func TestScanAndGenerateRoutes_Content(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	files := map[string]string{
+		"go.mod": "module testmodule\ngo 1.21\n",
+		"app/layout.templ": `package app
+
+templ Layout(title string) {
+	<html>{ children... }</html>
+}
+`,
+		"app/blog/layout.templ": `package blog
+
+templ Layout() {
+	<article>{ children... }</article>
+}
+`,
+		"app/about/page.templ": `package about
+
+templ Page() {
+	<h1>About</h1>
+}
+`,
+		"content/blog/hello.md":  "---\ntitle: Hello\n---\n# Hello\n",
+		"content/blog/draft.md":  "---\ndraft: true\n---\nSoon.\n",
+		"content/about.md":       "Shadowed by app/about/page.templ\n",
+		"content/_drafts/old.md": "Skipped\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	content, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+
+	want := []string{
+		`app.Get("/blog/hello", app.MarkdownPage("content/blog/hello.md",
+		nexo.LayoutSegment{Layout: nexo.TemplLayout(app_layout.Layout)},
+		nexo.LayoutSegment{Layout: nexo.TemplLayoutNoTitle(blog_layout.Layout)},
+	))`,
+		`app.Get("/blog/draft", app.MarkdownPage("content/blog/draft.md",`,
+		`app.Sitemap().Add("/blog/hello", nexo.SitemapOptions{})`,
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("generated file missing:\n%s\n\ngot:\n%s", w, got)
+		}
+	}
+	for _, unwanted := range []string{`"content/about.md"`, "old.md", `app.Sitemap().Add("/blog/draft"`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("generated file contains %s:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestScanAndGenerateRoutes_Monorepo(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                                   "module example.com/mono\n\ngo 1.22\n",
+		"apps/web/app/api/orders/[id]/route.go":    "package id\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+		"apps/web/app/api/health/route.go":         "package health\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+		"services/billing/go.mod":                  "module example.com/billing\n\ngo 1.22\n",
+		"services/billing/app/api/charge/route.go": "package charge\n\nfunc Post(c *nexo.Context) error { return nil }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An app below the module root imports its packages by their path in
+	// the module
+	t.Chdir(filepath.Join(root, "apps", "web"))
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"example.com/mono/apps/web/app/api/health"`, `"example.com/mono/apps/web/.nexo/generated/wrappers/`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated routes missing import %s:\n%s", want, out)
+		}
+	}
+
+	// The app of another workspace module can't be imported from here
+	t.Chdir(root)
+	if _, err := ScanAndGenerateRoutes("services/billing/app", "nexo_routes.go"); err == nil || !strings.Contains(err.Error(), "example.com/billing") {
+		t.Errorf("ScanAndGenerateRoutes() of another module error = %v", err)
+	}
+}
+
+func TestScanAndGenerateRoutes_Headers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.25\n",
+		"nexo.yaml": `headers:
+  - source: /static/*
+    headers:
+      Cache-Control: public, max-age=31536000, immutable
+`,
+		filepath.Join("app", "api", "health", "route.go"): `package health
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Get(c *nexo.Context) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+		t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+	}
+	out, err := os.ReadFile("nexo_routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `nexo.HeaderRule{Source: "/static/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("generated routes missing %s:\n%s", want, out)
+	}
+}
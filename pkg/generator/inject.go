@@ -0,0 +1,443 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxInjectedDeps is the number of dependencies supported by the
+// nexo.InjectN and nexo.InjectLoaderN helpers.
+const maxInjectedDeps = 3
+
+// Dependency injection
+//
+// Handlers and loaders may declare extra parameters after *nexo.Context:
+//
+//	func Get(c *nexo.Context, db *sql.DB) error
+//	func Loader(c *nexo.Context, db *sql.DB) (Data, error)
+//
+// The generator records each parameter type in canonical form (package
+// names replaced by import paths, e.g. "*database/sql.DB") and checks it
+// against the app.Provide and nexo.ProvideAs calls in the main package.
+
+// fileImports maps the names a file uses for its imports to import paths.
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// importName guesses the package name of an unaliased import from its path,
+// skipping major version suffixes such as /v2.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		if dir := path.Dir(importPath); dir != "." {
+			name = path.Base(dir)
+		}
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// canonicalType renders a type expression with package names replaced by
+// import paths. Exported identifiers are qualified with pkgPath, the import
+// path of the file's own package. It reports false for unsupported types.
+func canonicalType(expr ast.Expr, imports map[string]string, pkgPath string) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.IsExported() {
+			return pkgPath + "." + t.Name, true
+		}
+		return t.Name, true
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		importPath, ok := imports[pkg.Name]
+		if !ok {
+			return "", false
+		}
+		return importPath + "." + t.Sel.Name, true
+	case *ast.StarExpr:
+		elem, ok := canonicalType(t.X, imports, pkgPath)
+		return "*" + elem, ok
+	case *ast.ParenExpr:
+		return canonicalType(t.X, imports, pkgPath)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return "", false
+		}
+		elem, ok := canonicalType(t.Elt, imports, pkgPath)
+		return "[]" + elem, ok
+	case *ast.MapType:
+		key, ok := canonicalType(t.Key, imports, pkgPath)
+		if !ok {
+			return "", false
+		}
+		value, ok := canonicalType(t.Value, imports, pkgPath)
+		return "map[" + key + "]" + value, ok
+	}
+	return "", false
+}
+
+// hasContextParam reports whether the first parameter of fn is a single
+// *nexo.Context and fn returns only an error.
+func hasContextParam(fn *ast.FuncDecl) bool {
+	if fn.Type.Params == nil || len(fn.Type.Params.List) == 0 || len(fn.Type.Params.List[0].Names) > 1 {
+		return false
+	}
+	ctxOnly := &ast.FuncDecl{Type: &ast.FuncType{
+		Params:  &ast.FieldList{List: fn.Type.Params.List[:1]},
+		Results: fn.Type.Results,
+	}}
+	return isValidHandlerSignature(ctxOnly)
+}
+
+// paramDeps returns the canonical types of params, one entry per declared
+// name. It reports false if a type is unsupported or predeclared (string,
+// int, ...), since those are never injected.
+func paramDeps(params []*ast.Field, imports map[string]string, pkgPath string) ([]string, bool) {
+	var deps []string
+	for _, field := range params {
+		if ident, ok := field.Type.(*ast.Ident); ok && !ident.IsExported() {
+			return nil, false
+		}
+		dep, ok := canonicalType(field.Type, imports, pkgPath)
+		if !ok {
+			return nil, false
+		}
+		for range max(len(field.Names), 1) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, true
+}
+
+// handlerDeps checks if a function has the signature
+// func(c *nexo.Context, deps...) error and returns the dependency types.
+func handlerDeps(fn *ast.FuncDecl, imports map[string]string, pkgPath string) ([]string, bool) {
+	if len(fn.Type.Params.List) < 2 || !hasContextParam(fn) {
+		return nil, false
+	}
+	return paramDeps(fn.Type.Params.List[1:], imports, pkgPath)
+}
+
+// loaderSignature checks if a function has the signature
+// func(c *nexo.Context, deps...) (T, error) and returns T as written in
+// the source along with the dependency types.
+func loaderSignature(fn *ast.FuncDecl, imports map[string]string, pkgPath string) (string, []string, bool) {
+	results := fn.Type.Results
+	if results == nil || len(results.List) != 2 || len(results.List[0].Names) > 1 {
+		return "", nil, false
+	}
+	errOnly := &ast.FuncDecl{Type: &ast.FuncType{
+		Params:  fn.Type.Params,
+		Results: &ast.FieldList{List: results.List[1:]},
+	}}
+	if !hasContextParam(errOnly) {
+		return "", nil, false
+	}
+
+	deps, ok := paramDeps(fn.Type.Params.List[1:], imports, pkgPath)
+	if !ok {
+		return "", nil, false
+	}
+	return types.ExprString(results.List[0].Type), deps, true
+}
+
+// ProviderSet is the set of dependency types provided by the main package.
+type ProviderSet struct {
+	Types      map[string]bool // Canonical types passed to app.Provide or nexo.ProvideAs
+	Unresolved []string        // Provide calls whose argument type could not be inferred
+}
+
+// Provides reports whether typ is provided.
+func (p *ProviderSet) Provides(typ string) bool {
+	return p.Types[typ]
+}
+
+// Complete reports whether the type of every Provide call is known, in
+// which case a dependency missing from the set is definitely not provided.
+func (p *ProviderSet) Complete() bool {
+	return len(p.Unresolved) == 0
+}
+
+// scanProviders finds the app.Provide and nexo.ProvideAs calls in the Go
+// files of dir, skipping tests and the file at skip (the generated routes
+// file). Argument types are inferred syntactically from composite
+// literals, conversions, local variable declarations and local functions.
+func scanProviders(dir, skip, moduleName string) (*ProviderSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filePath := filepath.Join(dir, name)
+		if skip != "" && filepath.Clean(filePath) == filepath.Clean(skip) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filePath, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		files = append(files, file)
+	}
+
+	pkgPath := moduleName
+	if relDir, err := filepath.Rel(".", dir); err == nil && relDir != "." {
+		pkgPath = getImportPath(moduleName, relDir)
+	}
+
+	set := &ProviderSet{Types: make(map[string]bool)}
+	for _, file := range files {
+		inf := newTypeInferrer(file, pkgPath)
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				// app.Provide(v)
+				if fun.Sel.Name != "Provide" || len(call.Args) != 1 {
+					return true
+				}
+				if typ, ok := inf.exprType(call.Args[0]); ok {
+					set.Types[typ] = true
+				} else {
+					set.Unresolved = append(set.Unresolved, fset.Position(call.Pos()).String())
+				}
+			case *ast.IndexExpr:
+				// nexo.ProvideAs[T](app, v)
+				sel, ok := fun.X.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "ProvideAs" {
+					return true
+				}
+				if typ, ok := canonicalType(fun.Index, inf.imports, pkgPath); ok {
+					set.Types[typ] = true
+				} else {
+					set.Unresolved = append(set.Unresolved, fset.Position(call.Pos()).String())
+				}
+			}
+			return true
+		})
+	}
+
+	return set, nil
+}
+
+// typeInferrer infers the types of simple expressions in a file without
+// type-checking it. Only declarations in the same file are considered.
+type typeInferrer struct {
+	imports map[string]string
+	pkgPath string
+	vars    map[string]ast.Expr   // variable name -> declared type or initializer
+	funcs   map[string]*ast.Field // function name -> first result
+	visited map[string]bool       // guards against cyclic variable lookups
+}
+
+func newTypeInferrer(file *ast.File, pkgPath string) *typeInferrer {
+	inf := &typeInferrer{
+		imports: fileImports(file),
+		pkgPath: pkgPath,
+		vars:    make(map[string]ast.Expr),
+		funcs:   make(map[string]*ast.Field),
+		visited: make(map[string]bool),
+	}
+
+	// Variables declared more than once with different definitions are
+	// ambiguous without scope information; drop them.
+	ambiguous := make(map[string]bool)
+	record := func(name string, expr ast.Expr) {
+		if name == "_" || expr == nil {
+			return
+		}
+		if prev, ok := inf.vars[name]; ok && prev != expr {
+			ambiguous[name] = true
+			return
+		}
+		inf.vars[name] = expr
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Recv == nil && n.Type.Results != nil && len(n.Type.Results.List) > 0 {
+				inf.funcs[n.Name.Name] = n.Type.Results.List[0]
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if n.Type != nil {
+					record(name.Name, n.Type)
+				} else if len(n.Values) == len(n.Names) || i == 0 {
+					record(name.Name, n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE || len(n.Rhs) == 0 {
+				return true
+			}
+			// Only the first value of a multi-value call (v, err := f()) is inferred
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						record(ident.Name, n.Rhs[i])
+					}
+				}
+			} else if ident, ok := n.Lhs[0].(*ast.Ident); ok {
+				record(ident.Name, n.Rhs[0])
+			}
+		}
+		return true
+	})
+
+	for name := range ambiguous {
+		delete(inf.vars, name)
+	}
+	return inf
+}
+
+// exprType returns the canonical type of expr.
+func (inf *typeInferrer) exprType(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		if e.Type == nil {
+			return "", false
+		}
+		return canonicalType(e.Type, inf.imports, inf.pkgPath)
+	case *ast.UnaryExpr:
+		if e.Op != token.AND {
+			return "", false
+		}
+		if _, ok := e.X.(*ast.CompositeLit); !ok {
+			return "", false
+		}
+		elem, ok := inf.exprType(e.X)
+		return "*" + elem, ok
+	case *ast.ParenExpr:
+		return inf.exprType(e.X)
+	case *ast.CallExpr:
+		switch fun := e.Fun.(type) {
+		case *ast.ParenExpr:
+			// Conversion: (*sql.DB)(v)
+			return canonicalType(fun.X, inf.imports, inf.pkgPath)
+		case *ast.Ident:
+			// Local function: newStore()
+			if result, ok := inf.funcs[fun.Name]; ok {
+				return canonicalType(result.Type, inf.imports, inf.pkgPath)
+			}
+		}
+		return "", false
+	case *ast.Ident:
+		def, ok := inf.vars[e.Name]
+		if !ok || inf.visited[e.Name] {
+			return "", false
+		}
+		inf.visited[e.Name] = true
+		defer delete(inf.visited, e.Name)
+
+		// def is either a declared type or an initializer expression
+		if typ, ok := inf.exprType(def); ok {
+			return typ, true
+		}
+		if isTypeExpr(def) {
+			return canonicalType(def, inf.imports, inf.pkgPath)
+		}
+	}
+	return "", false
+}
+
+// isTypeExpr reports whether expr can only be a type expression.
+func isTypeExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.StarExpr, *ast.ArrayType, *ast.MapType:
+		return true
+	case *ast.SelectorExpr:
+		_, ok := e.X.(*ast.Ident)
+		return ok
+	case *ast.Ident:
+		return true
+	}
+	return false
+}
+
+// checkDependencies returns an error listing every handler or loader
+// dependency of cfg that providers does not provide. When providers is
+// incomplete, missing dependencies are reported as warnings instead, since
+// they may come from a Provide call whose type could not be inferred.
+func checkDependencies(cfg RoutesGenConfig, providers *ProviderSet) ([]GenerationWarning, error) {
+	var missing []GenerationWarning
+	add := func(file, fn string, deps []string) {
+		for _, dep := range deps {
+			if !providers.Provides(dep) {
+				missing = append(missing, GenerationWarning{
+					File:    file,
+					Message: fmt.Sprintf("%s requires %s", fn, dep),
+				})
+			}
+		}
+	}
+	for _, r := range cfg.Routes {
+		add(r.FilePath, r.Handler, r.Deps)
+	}
+	for _, p := range cfg.Pages {
+		add(p.LoaderFilePath, "Loader", p.LoaderDeps)
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	if !providers.Complete() {
+		for i := range missing {
+			missing[i].Message += " (not found among inferred app.Provide calls)"
+		}
+		return missing, nil
+	}
+
+	lines := make([]string, len(missing))
+	for i, m := range missing {
+		lines[i] = m.File + ": " + m.Message
+	}
+	return nil, fmt.Errorf("missing dependencies (register them with app.Provide or nexo.ProvideAs):\n  %s", strings.Join(lines, "\n  "))
+}
+
+// hasDeps reports whether any handler or loader in cfg declares dependencies.
+func hasDeps(cfg RoutesGenConfig) bool {
+	for _, r := range cfg.Routes {
+		if len(r.Deps) > 0 {
+			return true
+		}
+	}
+	for _, p := range cfg.Pages {
+		if len(p.LoaderDeps) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandlerDeps(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantDeps []string
+		wantOK   bool
+	}{
+		{
+			name:     "pointer dependency",
+			source:   "func Get(c *nexo.Context, db *sql.DB) error",
+			wantDeps: []string{"*database/sql.DB"},
+			wantOK:   true,
+		},
+		{
+			name:     "aliased import and grouped params",
+			source:   "func Get(c *nexo.Context, a, b *rdb.Client) error",
+			wantDeps: []string{"*github.com/redis/go-redis/v9.Client", "*github.com/redis/go-redis/v9.Client"},
+			wantOK:   true,
+		},
+		{
+			name:     "local type",
+			source:   "func Get(c *nexo.Context, repo *Repo) error",
+			wantDeps: []string{"*example.com/app/app/users.Repo"},
+			wantOK:   true,
+		},
+		{
+			name:   "no dependencies",
+			source: "func Get(c *nexo.Context) error",
+		},
+		{
+			name:   "missing context",
+			source: "func Get(db *sql.DB) error",
+		},
+		{
+			name:   "predeclared type",
+			source: "func Get(c *nexo.Context, name string) error",
+		},
+		{
+			name:   "unsupported type",
+			source: "func Get(c *nexo.Context, fn func()) error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package users\n\nimport (\n\t\"database/sql\"\n\trdb \"github.com/redis/go-redis/v9\"\n)\n\n" + tt.source + " { return nil }\n"
+			file, err := parser.ParseFile(token.NewFileSet(), "route.go", src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fn := file.Decls[len(file.Decls)-1].(*ast.FuncDecl)
+
+			deps, ok := handlerDeps(fn, fileImports(file), "example.com/app/app/users")
+			if ok != tt.wantOK || !reflect.DeepEqual(deps, tt.wantDeps) {
+				t.Errorf("handlerDeps() = %v, %v; want %v, %v", deps, ok, tt.wantDeps, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"database/sql":                 "sql",
+		"github.com/redis/go-redis/v9": "redis",
+		"github.com/go-chi/chi/v5":     "chi",
+		"example.com/app/cache":        "cache",
+	}
+	for path, want := range tests {
+		if got := importName(path); got != want {
+			t.Errorf("importName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestScanProviders(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	main := `package main
+
+import (
+	"database/sql"
+
+	"example.com/app/cache"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+type Config struct{}
+
+func newMailer() *Mailer { return nil }
+
+func main() {
+	app := nexo.New()
+	var db *sql.DB
+	mailer := newMailer()
+	app.Provide(db)
+	app.Provide(mailer)
+	app.Provide(&Config{})
+	app.Provide((*cache.Client)(nil))
+	nexo.ProvideAs[cache.Store](app, nil)
+	RegisterRoutes(app)
+}
+`
+	_ = os.WriteFile("main.go", []byte(main), 0644)
+	_ = os.WriteFile("nexo_routes.go", []byte("package main\n\nfunc init() { app.Provide(unknown()) }\n"), 0644)
+
+	providers, err := scanProviders(".", "nexo_routes.go", "example.com/app")
+	if err != nil {
+		t.Fatalf("scanProviders() error = %v", err)
+	}
+
+	for _, typ := range []string{
+		"*database/sql.DB",
+		"*example.com/app.Mailer",
+		"*example.com/app.Config",
+		"*example.com/app/cache.Client",
+		"example.com/app/cache.Store",
+	} {
+		if !providers.Provides(typ) {
+			t.Errorf("expected %s to be provided, got %v", typ, providers.Types)
+		}
+	}
+	if !providers.Complete() {
+		t.Errorf("expected complete provider set, unresolved: %v", providers.Unresolved)
+	}
+
+	// Types that cannot be inferred make the set incomplete
+	_ = os.WriteFile("extra.go", []byte("package main\n\nfunc setup(app any) { app.Provide(openDB()) }\n"), 0644)
+	providers, err = scanProviders(".", "nexo_routes.go", "example.com/app")
+	if err != nil {
+		t.Fatalf("scanProviders() error = %v", err)
+	}
+	if providers.Complete() {
+		t.Error("expected incomplete provider set")
+	}
+}
+
+func TestScanAndGenerateRoutes_Dependencies(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	_ = os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.25\n"), 0644)
+	dir := filepath.Join("app", "api", "users")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	route := `package users
+
+import (
+	"database/sql"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+func Get(c *nexo.Context, db *sql.DB) error {
+	return c.JSON(200, nil)
+}
+`
+	_ = os.WriteFile(filepath.Join(dir, "route.go"), []byte(route), 0644)
+
+	writeMain := func(provide string) {
+		src := "package main\n\nimport \"database/sql\"\n\nfunc main() {\n\tvar db *sql.DB\n\t" + provide + "\n}\n"
+		_ = os.WriteFile("main.go", []byte(src), 0644)
+	}
+
+	t.Run("missing dependency fails", func(t *testing.T) {
+		writeMain("_ = db")
+		_, err := ScanAndGenerateRoutes("app", "nexo_routes.go")
+		if err == nil || !strings.Contains(err.Error(), "Get requires *database/sql.DB") {
+			t.Fatalf("ScanAndGenerateRoutes() error = %v, want missing dependency", err)
+		}
+	})
+
+	t.Run("provided dependency is injected", func(t *testing.T) {
+		writeMain("app.Provide(db)")
+		if _, err := ScanAndGenerateRoutes("app", "nexo_routes.go"); err != nil {
+			t.Fatalf("ScanAndGenerateRoutes() error = %v", err)
+		}
+		content, err := os.ReadFile("nexo_routes.go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "nexo.Inject1(app, users.Get)") {
+			t.Errorf("generated routes missing injected handler:\n%s", content)
+		}
+	})
+}
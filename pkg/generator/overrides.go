@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultTemplateDir is the directory, relative to the project root, where
+// projects can place templates that override the built-in ones.
+const DefaultTemplateDir = ".nexo/templates"
+
+// Template override file names recognized in the template directory.
+const (
+	RouteTemplateName      = "route.go.tmpl"      // Overrides route stubs (GenerateRoute)
+	MiddlewareTemplateName = "middleware.go.tmpl" // Overrides the blank middleware template (GenerateMiddleware)
+	PageTemplateName       = "page.templ.tmpl"    // Overrides page stubs (GeneratePage)
+	RoutesGenTemplateName  = "routes_gen.go.tmpl" // Overrides the routes file (GenerateRoutesFile)
+)
+
+// resolveTemplateDir returns the override directory to use for a generation call.
+// An explicit dir wins; otherwise .nexo/templates next to the app directory is used.
+func resolveTemplateDir(dir, appDir string) string {
+	if dir != "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(appDir), DefaultTemplateDir)
+}
+
+// loadTemplate returns the override template named name from dir if it exists,
+// falling back to builtin. The second return value reports whether an override
+// was used. An empty dir disables overrides.
+func loadTemplate(dir, name, builtin string) (string, bool, error) {
+	if dir == "" {
+		return builtin, false, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return builtin, false, nil
+		}
+		return "", false, fmt.Errorf("failed to read template override %s: %w", name, err)
+	}
+
+	return string(content), true, nil
+}
+
+// overrideError attributes an error rendering a template to the project's
+// override, when there is one. Rendered Go that doesn't parse fails here, at
+// generation time, instead of when the project is compiled.
+func overrideError(templateName string, overridden bool, err error) error {
+	if overridden {
+		return fmt.Errorf("template override %s: %w", templateName, err)
+	}
+	return err
+}
+
+// validatePageOutput checks that a rendered page override still exports a
+// Page() component, which the route generator relies on.
+func validatePageOutput(templateName string, content []byte) error {
+	if !templPageSignatureRe.Match(content) {
+		return fmt.Errorf("template override %s must define a templ Page() component", templateName)
+	}
+	return nil
+}
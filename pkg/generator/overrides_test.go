@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeOverride(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveTemplateDir(t *testing.T) {
+	tests := []struct {
+		dir    string
+		appDir string
+		want   string
+	}{
+		{"custom", "app", "custom"},
+		{"", "app", DefaultTemplateDir},
+		{"", "/tmp/project/app", filepath.Join("/tmp/project", DefaultTemplateDir)},
+	}
+
+	for _, tt := range tests {
+		if got := resolveTemplateDir(tt.dir, tt.appDir); got != tt.want {
+			t.Errorf("resolveTemplateDir(%q, %q) = %q, want %q", tt.dir, tt.appDir, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRoute_TemplateOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	writeOverride(t, filepath.Join(tmpDir, DefaultTemplateDir), RouteTemplateName, `package {{.Package}}
+
+// custom route template
+{{range .Methods}}
+func {{.FuncName}}() {}
+{{end}}`)
+
+	result, err := GenerateRoute(RouteConfig{Path: "users", Methods: []string{"GET"}, AppDir: appDir})
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	content, err := os.ReadFile(result.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "// custom route template") {
+		t.Errorf("override template was not used:\n%s", content)
+	}
+	if !strings.Contains(string(content), "func Get() {}") {
+		t.Errorf("override template was not rendered with route data:\n%s", content)
+	}
+}
+
+func TestGenerateRoute_InvalidOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	writeOverride(t, filepath.Join(tmpDir, DefaultTemplateDir), RouteTemplateName, "package {{.Package}}\n\nfunc broken( {\n")
+
+	_, err := GenerateRoute(RouteConfig{Path: "users", Methods: []string{"GET"}, AppDir: appDir})
+	if err == nil {
+		t.Fatal("expected error for override producing invalid Go")
+	}
+	if !strings.Contains(err.Error(), RouteTemplateName) {
+		t.Errorf("error should mention the template name, got: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(appDir, "api", "users", "route.go")); !os.IsNotExist(statErr) {
+		t.Error("no file should be written when the override is invalid")
+	}
+}
+
+func TestGenerateMiddleware_TemplateOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	templateDir := filepath.Join(tmpDir, "templates")
+	writeOverride(t, templateDir, MiddlewareTemplateName, "package {{.Package}}\n\n// custom {{.Name}} middleware\n")
+
+	// The blank template is replaced by the override
+	result, err := GenerateMiddleware(MiddlewareConfig{Name: "audit", Path: "api", Template: "blank", AppDir: appDir, TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("GenerateMiddleware() error = %v", err)
+	}
+	content, _ := os.ReadFile(result.Files[0])
+	if !strings.Contains(string(content), "// custom audit middleware") {
+		t.Errorf("override template was not used:\n%s", content)
+	}
+
+	// Named templates are never overridden
+	result, err = GenerateMiddleware(MiddlewareConfig{Name: "auth", Path: "admin", Template: "auth", AppDir: appDir, TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("GenerateMiddleware() error = %v", err)
+	}
+	content, _ = os.ReadFile(result.Files[0])
+	if strings.Contains(string(content), "// custom") {
+		t.Errorf("auth template should not use the override:\n%s", content)
+	}
+}
+
+func TestGeneratePage_TemplateOverride(t *testing.T) {
+	t.Run("valid override", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		appDir := filepath.Join(tmpDir, "app")
+		writeOverride(t, filepath.Join(tmpDir, DefaultTemplateDir), PageTemplateName, "package {{.Package}}\n\ntempl Page() {\n\t<h1>Custom {{.Title}}</h1>\n}\n")
+
+		result, err := GeneratePage(PageConfig{Path: "about", AppDir: appDir})
+		if err != nil {
+			t.Fatalf("GeneratePage() error = %v", err)
+		}
+		content, _ := os.ReadFile(result.Files[0])
+		if !strings.Contains(string(content), "Custom About") {
+			t.Errorf("override template was not used:\n%s", content)
+		}
+	})
+
+	t.Run("missing Page component", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		appDir := filepath.Join(tmpDir, "app")
+		writeOverride(t, filepath.Join(tmpDir, DefaultTemplateDir), PageTemplateName, "package {{.Package}}\n\ntempl Other() {\n}\n")
+
+		if _, err := GeneratePage(PageConfig{Path: "about", AppDir: appDir}); err == nil {
+			t.Fatal("expected error for override without Page()")
+		}
+	})
+}
+
+func TestGenerateRoutesFile_TemplateOverride(t *testing.T) {
+	cfg := snapshotRoutesConfig()
+
+	t.Run("valid override", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfg.TemplateDir = tmpDir
+		cfg.OutputPath = filepath.Join(tmpDir, "nexo_routes.go")
+		writeOverride(t, tmpDir, RoutesGenTemplateName, `package main
+
+// custom routes file
+{{range .Routes}}// {{.Method}} {{.Pattern}}
+{{end}}`)
+
+		if _, err := GenerateRoutesFile(cfg); err != nil {
+			t.Fatalf("GenerateRoutesFile() error = %v", err)
+		}
+		content, _ := os.ReadFile(cfg.OutputPath)
+		if !strings.Contains(string(content), "// GET /api/users") {
+			t.Errorf("override template was not used:\n%s", content)
+		}
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfg.TemplateDir = tmpDir
+		cfg.OutputPath = filepath.Join(tmpDir, "nexo_routes.go")
+		writeOverride(t, tmpDir, RoutesGenTemplateName, "not go code\n")
+
+		if _, err := GenerateRoutesFile(cfg); err == nil {
+			t.Fatal("expected error for override producing invalid Go")
+		}
+	})
+
+	t.Run("fallback to built-in", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfg.TemplateDir = tmpDir
+		cfg.OutputPath = filepath.Join(tmpDir, "nexo_routes.go")
+
+		if _, err := GenerateRoutesFile(cfg); err != nil {
+			t.Fatalf("GenerateRoutesFile() error = %v", err)
+		}
+		builtin := cfg
+		builtin.TemplateDir = ""
+		want, err := renderRoutesFile(builtin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := os.ReadFile(cfg.OutputPath)
+		if string(got) != string(want) {
+			t.Error("expected built-in template output when no override exists")
+		}
+	})
+}
@@ -0,0 +1,429 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+)
+
+// MoveConfig holds configuration for moving a route or page directory
+type MoveConfig struct {
+	From   string // e.g., "users/[id]" or "api/users/[id]"
+	To     string // e.g., "accounts/[id]"
+	AppDir string // default: "app"
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
+}
+
+// RemoveConfig holds configuration for removing a route or page directory
+type RemoveConfig struct {
+	Path   string // e.g., "users/[id]"
+	AppDir string // default: "app"
+	Force  bool   // remove even when other packages import the directory
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
+}
+
+var (
+	// templPackageRe matches the package clause of a templ file.
+	templPackageRe = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+
+	// generatedFileRe matches the header of a generated Go file.
+	generatedFileRe = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+)
+
+// MoveRoute moves a route or page directory, with everything below it, to
+// a new path in the app directory. Packages keeping their default name get
+// the name of their new directory, and imports of the moved packages are
+// rewritten across the project; an import that relied on the old package
+// name gets it as an alias, so the code using it keeps compiling.
+//
+// Paths are relative to the app directory. A path that isn't found there
+// is looked up in app/api, like `nexo generate route` places it, and the
+// destination then goes in app/api too.
+//
+// The routes file is not regenerated; callers run the route generator
+// afterwards.
+func MoveRoute(cfg MoveConfig) (*Result, error) {
+	if cfg.AppDir == "" {
+		cfg.AppDir = "app"
+	}
+	fsys := genfs.Or(cfg.FS)
+	from, inAPI, err := resolveRouteDir(fsys, cfg.AppDir, cfg.From)
+	if err != nil {
+		return nil, err
+	}
+	toPath, err := cleanRoutePath(cfg.To)
+	if err != nil {
+		return nil, err
+	}
+	if inAPI && toPath != "api" && !strings.HasPrefix(filepath.ToSlash(toPath), "api/") {
+		toPath = filepath.Join("api", toPath)
+	}
+	to := filepath.Join(cfg.AppDir, toPath)
+	if to == from {
+		return nil, fmt.Errorf("%s is already at %s", cfg.From, to)
+	}
+	if rel, err := filepath.Rel(from, to); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("cannot move %s into itself", from)
+	}
+	if genfs.Exists(fsys, to) {
+		return nil, fmt.Errorf("%s already exists", to)
+	}
+
+	moduleName, err := getModuleName()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module name: %w", err)
+	}
+
+	// Record the import paths and package names before anything moves
+	moved, err := movedPackages(fsys, moduleName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := fsys.Rename(from, to); err != nil {
+		return nil, fmt.Errorf("failed to move %s: %w", from, err)
+	}
+
+	var updated []string
+	for _, pkg := range moved {
+		files, err := renamePackage(fsys, pkg)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, files...)
+	}
+	files, err := rewriteImports(fsys, ".", moved)
+	if err != nil {
+		return nil, err
+	}
+	updated = append(updated, files...)
+	slices.Sort(updated)
+
+	return &Result{
+		Files:   []string{to},
+		Updated: slices.Compact(updated),
+		Pattern: dirToPattern(to, cfg.AppDir),
+	}, nil
+}
+
+// RemoveRoute deletes a route or page directory and everything below it.
+// Paths are resolved like MoveRoute's. It fails when Go files outside the
+// directory import one of its packages, unless Force is set.
+//
+// The routes file is not regenerated; callers run the route generator
+// afterwards.
+func RemoveRoute(cfg RemoveConfig) (*Result, error) {
+	if cfg.AppDir == "" {
+		cfg.AppDir = "app"
+	}
+	fsys := genfs.Or(cfg.FS)
+	dir, _, err := resolveRouteDir(fsys, cfg.AppDir, cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	pattern := dirToPattern(dir, cfg.AppDir)
+
+	if !cfg.Force {
+		moduleName, err := getModuleName()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get module name: %w", err)
+		}
+		pkgs, err := movedPackages(fsys, moduleName, dir, dir)
+		if err != nil {
+			return nil, err
+		}
+		importers, err := importersOf(fsys, ".", dir, pkgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(importers) > 0 {
+			return nil, fmt.Errorf("%s is imported by %s (use --force to remove it anyway)", dir, strings.Join(importers, ", "))
+		}
+	}
+
+	if err := fsys.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return &Result{Files: []string{dir}, Pattern: pattern}, nil
+}
+
+// resolveRouteDir returns the directory of the route path in appDir, and
+// whether it was found in appDir/api rather than appDir itself.
+func resolveRouteDir(fsys genfs.WriteFS, appDir, path string) (string, bool, error) {
+	path, err := cleanRoutePath(path)
+	if err != nil {
+		return "", false, err
+	}
+	dir := filepath.Join(appDir, path)
+	if isDir(fsys, dir) {
+		return dir, false, nil
+	}
+	if apiDir := filepath.Join(appDir, "api", path); isDir(fsys, apiDir) {
+		return apiDir, true, nil
+	}
+	return "", false, fmt.Errorf("no route or page directory %s in %s", path, appDir)
+}
+
+// cleanRoutePath cleans a path relative to the app directory, rejecting
+// paths that are empty, absolute or lead out of it.
+func cleanRoutePath(path string) (string, error) {
+	path = filepath.Clean(path)
+	if path == "." || filepath.IsAbs(path) || strings.HasPrefix(path, "..") {
+		return "", fmt.Errorf("invalid route path %q", path)
+	}
+	return path, nil
+}
+
+func isDir(fsys genfs.WriteFS, path string) bool {
+	info, err := fsys.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// movedPackage is a package in a moved directory tree.
+type movedPackage struct {
+	dir       string // new directory
+	oldImport string
+	newImport string
+	oldName   string // package name before the move, "" without Go files
+	newName   string // package name after the move
+}
+
+// movedPackages lists the packages in the tree at from, which is moving to
+// to.
+func movedPackages(fsys genfs.WriteFS, moduleName, from, to string) ([]movedPackage, error) {
+	var pkgs []movedPackage
+	err := genfs.WalkDir(fsys, from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		newDir := filepath.Join(to, rel)
+		pkg := movedPackage{
+			dir:       newDir,
+			oldImport: getImportPath(moduleName, path),
+			newImport: getImportPath(moduleName, newDir),
+			oldName:   dirPackageName(fsys, path),
+		}
+		if pkg.oldName == "" {
+			pkg.oldName = templPackageName(fsys, path)
+		}
+		pkg.newName = pkg.oldName
+		// Packages named after their directory follow it
+		if pkg.oldName == packageNameFromPath(filepath.ToSlash(path)) {
+			pkg.newName = packageNameFromPath(filepath.ToSlash(newDir))
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	return pkgs, err
+}
+
+// templPackageName returns the package of the templ files in dir, for a
+// page directory whose templates haven't been generated yet.
+func templPackageName(fsys genfs.WriteFS, dir string) string {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".templ" {
+			continue
+		}
+		src, err := fsys.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if m := templPackageRe.FindSubmatch(src); m != nil {
+			return string(m[1])
+		}
+	}
+	return ""
+}
+
+// renamePackage rewrites the package clause of the Go and templ files of a
+// moved package whose name changed, returning the files it rewrote.
+func renamePackage(fsys genfs.WriteFS, pkg movedPackage) ([]string, error) {
+	if pkg.oldName == pkg.newName {
+		return nil, nil
+	}
+	entries, err := fsys.ReadDir(pkg.dir)
+	if err != nil {
+		return nil, err
+	}
+	var updated []string
+	for _, e := range entries {
+		path := filepath.Join(pkg.dir, e.Name())
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".go" && ext != ".templ") {
+			continue
+		}
+		src, err := fsys.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var start, end int
+		var name string
+		switch ext {
+		case ".go":
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, path, src, parser.PackageClauseOnly)
+			if err != nil {
+				continue
+			}
+			name = file.Name.Name
+			start, end = fset.Position(file.Name.Pos()).Offset, fset.Position(file.Name.End()).Offset
+		case ".templ":
+			m := templPackageRe.FindSubmatchIndex(src)
+			if m == nil {
+				continue
+			}
+			name = string(src[m[2]:m[3]])
+			start, end = m[2], m[3]
+		}
+
+		// External test packages keep their _test suffix
+		newName := pkg.newName
+		if name == pkg.oldName+"_test" {
+			newName += "_test"
+		} else if name != pkg.oldName {
+			continue
+		}
+		out := slices.Concat(src[:start], []byte(newName), src[end:])
+		if err := fsys.WriteFile(path, out, 0644); err != nil {
+			return nil, err
+		}
+		updated = append(updated, path)
+	}
+	return updated, nil
+}
+
+// rewriteImports points the imports of moved packages, in the Go and templ
+// files under root, at their new import paths, returning the files it
+// rewrote.
+func rewriteImports(fsys genfs.WriteFS, root string, moved []movedPackage) ([]string, error) {
+	byImport := make(map[string]movedPackage, len(moved))
+	for _, pkg := range moved {
+		if pkg.oldImport != pkg.newImport {
+			byImport[pkg.oldImport] = pkg
+		}
+	}
+	if len(byImport) == 0 {
+		return nil, nil
+	}
+
+	var updated []string
+	err := walkProjectFiles(fsys, root, func(path string, src []byte) error {
+		var out []byte
+		if strings.HasSuffix(path, ".go") {
+			out = rewriteGoImports(path, src, byImport)
+		} else {
+			out = src
+			for oldImport, pkg := range byImport {
+				out = bytes.ReplaceAll(out, []byte(strconv.Quote(oldImport)), []byte(strconv.Quote(pkg.newImport)))
+			}
+		}
+		if bytes.Equal(out, src) {
+			return nil
+		}
+		updated = append(updated, path)
+		return fsys.WriteFile(path, out, 0644)
+	})
+	return updated, err
+}
+
+// rewriteGoImports returns src with the import paths of byImport replaced.
+func rewriteGoImports(path string, src []byte, byImport map[string]movedPackage) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+	// Splice from the end so earlier offsets stay valid
+	out := src
+	for i := len(file.Imports) - 1; i >= 0; i-- {
+		spec := file.Imports[i]
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		pkg, ok := byImport[importPath]
+		if !ok {
+			continue
+		}
+		replacement := strconv.Quote(pkg.newImport)
+		if spec.Name == nil && pkg.oldName != "" && pkg.oldName != pkg.newName {
+			replacement = pkg.oldName + " " + replacement
+		}
+		start, end := fset.Position(spec.Path.Pos()).Offset, fset.Position(spec.Path.End()).Offset
+		out = slices.Concat(out[:start], []byte(replacement), out[end:])
+	}
+	return out
+}
+
+// importersOf returns the Go and templ files under root, outside dir, that
+// import one of pkgs. Generated files, like the routes file, don't count.
+func importersOf(fsys genfs.WriteFS, root, dir string, pkgs []movedPackage) ([]string, error) {
+	var importers []string
+	err := walkProjectFiles(fsys, root, func(path string, src []byte) error {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return nil
+		}
+		if generatedFileRe.Match(src) {
+			return nil
+		}
+		for _, pkg := range pkgs {
+			if bytes.Contains(src, []byte(strconv.Quote(pkg.oldImport))) {
+				importers = append(importers, path)
+				return nil
+			}
+		}
+		return nil
+	})
+	return importers, err
+}
+
+// walkProjectFiles calls fn with the Go and templ files under root,
+// skipping hidden directories like .nexo, vendor and node_modules.
+func walkProjectFiles(fsys genfs.WriteFS, root string, fn func(path string, src []byte) error) error {
+	return genfs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(name); ext != ".go" && ext != ".templ" {
+			return nil
+		}
+		src, err := fsys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return fn(path, src)
+	})
+}
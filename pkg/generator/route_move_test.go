@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeProject writes files, relative to the current directory, into a
+// module named example.com/shop.
+func writeProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	files["go.mod"] = "module example.com/shop\n\ngo 1.25\n"
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestMoveRoute(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProject(t, map[string]string{
+		"app/api/users/route.go":          "package users\n\nfunc Get() {}\n",
+		"app/api/users/route_test.go":     "package users_test\n",
+		"app/api/users/[id]/route.go":     "package id\n\nimport \"example.com/shop/app/api/users\"\n\nvar _ = users.Get\n",
+		"app/api/users/settings/route.go": "package prefs\n",
+		"lib/report.go": `package lib
+
+import (
+	"fmt"
+
+	"example.com/shop/app/api/users"
+	prefs "example.com/shop/app/api/users/settings"
+)
+
+var _, _ = users.Get, fmt.Sprint
+`,
+		"app/dashboard/page.templ": "package dashboard\n\nimport \"example.com/shop/app/api/users\"\n",
+	})
+
+	result, err := MoveRoute(MoveConfig{From: "users", To: "accounts"})
+	if err != nil {
+		t.Fatalf("MoveRoute() error = %v", err)
+	}
+	moved := filepath.Join("app", "api", "accounts")
+	if !slices.Equal(result.Files, []string{moved}) || result.Pattern != "/api/accounts" {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join("app", "api", "users")); !os.IsNotExist(err) {
+		t.Error("app/api/users still exists")
+	}
+
+	want := map[string]string{
+		// Packages named after their directory follow it
+		"app/api/accounts/route.go":          "package accounts\n",
+		"app/api/accounts/route_test.go":     "package accounts_test\n",
+		"app/api/accounts/settings/route.go": "package prefs\n",
+		// Nested packages importing the moved one keep compiling
+		"app/api/accounts/[id]/route.go": "import users \"example.com/shop/app/api/accounts\"",
+		"lib/report.go":                  "\tusers \"example.com/shop/app/api/accounts\"\n\tprefs \"example.com/shop/app/api/accounts/settings\"\n",
+		"app/dashboard/page.templ":       "import \"example.com/shop/app/api/accounts\"",
+	}
+	for path, content := range want {
+		if got := readFile(t, path); !strings.Contains(got, content) {
+			t.Errorf("%s =\n%s\nwant it to contain %q", path, got, content)
+		}
+	}
+	for _, path := range []string{"app/api/accounts/route.go", "lib/report.go", "app/dashboard/page.templ"} {
+		if !slices.Contains(result.Updated, filepath.FromSlash(path)) {
+			t.Errorf("Updated = %v, want %s", result.Updated, path)
+		}
+	}
+}
+
+func TestMoveRoute_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProject(t, map[string]string{
+		"app/api/users/route.go":  "package users\n",
+		"app/api/orders/route.go": "package orders\n",
+	})
+
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{from: "products", to: "items", want: "no route or page directory products"},
+		{from: "users", to: "orders", want: "already exists"},
+		{from: "users", to: "users/[id]", want: "into itself"},
+		{from: "../users", to: "accounts", want: "invalid route path"},
+		{from: "users", to: "", want: "invalid route path"},
+		{from: "users", to: ".", want: "invalid route path"},
+		{from: "users", to: "..", want: "invalid route path"},
+		{from: "users", to: "../../outside", want: "invalid route path"},
+		{from: "users", to: "accounts/../../..", want: "invalid route path"},
+		{from: "users", to: "/tmp/users", want: "invalid route path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			_, err := MoveRoute(MoveConfig{From: tt.from, To: tt.to})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("MoveRoute() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveRoute(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProject(t, map[string]string{
+		"app/api/users/[id]/route.go": "package id\n",
+		"app/api/users/route.go":      "package users\n",
+		"lib/report.go":               "package lib\n\nimport _ \"example.com/shop/app/api/users\"\n",
+		"nexo_routes.go":              "// Code generated by nexo. DO NOT EDIT.\n\npackage main\n\nimport _ \"example.com/shop/.nexo/generated/wrappers/app_api_users_id\"\n",
+	})
+
+	// The routes file is regenerated, so it doesn't hold a directory back
+	result, err := RemoveRoute(RemoveConfig{Path: "users/[id]"})
+	if err != nil {
+		t.Fatalf("RemoveRoute() error = %v", err)
+	}
+	if result.Pattern != "/api/users/{id}" {
+		t.Errorf("Pattern = %q", result.Pattern)
+	}
+	if _, err := os.Stat(filepath.Join("app", "api", "users", "[id]")); !os.IsNotExist(err) {
+		t.Error("app/api/users/[id] still exists")
+	}
+
+	_, err = RemoveRoute(RemoveConfig{Path: "users"})
+	if err == nil || !strings.Contains(err.Error(), "is imported by "+filepath.Join("lib", "report.go")) {
+		t.Fatalf("removing an imported directory: error = %v", err)
+	}
+	if _, err := RemoveRoute(RemoveConfig{Path: "users", Force: true}); err != nil {
+		t.Fatalf("RemoveRoute() with Force error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("app", "api", "users")); !os.IsNotExist(err) {
+		t.Error("app/api/users still exists")
+	}
+}
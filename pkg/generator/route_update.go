@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+)
+
+// appendRouteHandlers adds the declarations of rendered, a route file
+// rendered for the missing methods, to the end of the existing route file
+// at path, and the imports they need to its imports. The existing code is
+// kept byte for byte.
+func appendRouteHandlers(fsys genfs.WriteFS, path string, rendered []byte) ([]byte, error) {
+	src, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("cannot add handlers to %s: %w", path, err)
+	}
+	addition, err := parser.ParseFile(fset, "", rendered, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated handlers: %w", err)
+	}
+
+	// The declarations follow the rendered file's imports
+	declStart := fset.Position(addition.Name.End()).Offset
+	for _, decl := range addition.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			declStart = fset.Position(gen.End()).Offset
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimRight(src, "\n"))
+	buf.WriteString("\n\n")
+	buf.Write(bytes.TrimLeft(rendered[declStart:], "\n"))
+	out := addImports(buf.Bytes(), fset, file, missingImports(file, addition))
+
+	if _, err := parser.ParseFile(token.NewFileSet(), path, out, parser.AllErrors); err != nil {
+		return nil, fmt.Errorf("adding handlers to %s produced invalid Go: %w", path, err)
+	}
+	return out, nil
+}
+
+// missingImports returns the import specs of addition, as source, that
+// file doesn't import.
+func missingImports(file, addition *ast.File) []string {
+	have := make(map[string]bool)
+	for _, spec := range file.Imports {
+		have[spec.Path.Value] = true
+	}
+	var missing []string
+	for _, spec := range addition.Imports {
+		if have[spec.Path.Value] {
+			continue
+		}
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+		missing = append(missing, line)
+	}
+	return missing
+}
+
+// addImports inserts import specs into src, the source file was parsed
+// from: into its last import block, into a block replacing a single-line
+// import, or after the package clause.
+func addImports(src []byte, fset *token.FileSet, file *ast.File, specs []string) []byte {
+	if len(specs) == 0 {
+		return src
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	var start, end int
+	var block string
+	switch {
+	case last == nil:
+		start = offset(file.Name.End())
+		end = start
+		block = "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+	case last.Lparen.IsValid():
+		start = offset(last.Rparen)
+		end = start
+		block = "\t" + strings.Join(specs, "\n\t") + "\n"
+	default:
+		start, end = offset(last.Pos()), offset(last.End())
+		existing := strings.TrimSpace(strings.TrimPrefix(string(src[start:end]), "import"))
+		block = "import (\n\t" + existing + "\n\t" + strings.Join(specs, "\n\t") + "\n)"
+	}
+
+	out := make([]byte, 0, len(src)+len(block))
+	out = append(out, src[:start]...)
+	out = append(out, block...)
+	return append(out, src[end:]...)
+}
+
+// checkNewDecls returns an error when a top-level name declared by content
+// is already declared by another Go file in dir.
+func checkNewDecls(fsys genfs.WriteFS, dir string, content []byte) error {
+	file, err := parser.ParseFile(token.NewFileSet(), "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil // reported when the file is written or built
+	}
+	declared := dirDecls(fsys, dir)
+	for _, name := range topLevelNames(file) {
+		if path, ok := declared[name]; ok {
+			return fmt.Errorf("%s is already declared in %s", name, path)
+		}
+	}
+	return nil
+}
+
+// dirDecls maps the top-level names declared by the Go files in dir to the
+// file declaring them.
+func dirDecls(fsys genfs.WriteFS, dir string) map[string]string {
+	decls := make(map[string]string)
+	for _, path := range goFiles(fsys, dir) {
+		src, err := fsys.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, name := range topLevelNames(file) {
+			decls[name] = path
+		}
+	}
+	return decls
+}
+
+// topLevelNames returns the functions, types, variables and constants a
+// file declares, skipping methods and blank names.
+func topLevelNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						names = append(names, n.Name)
+					}
+				}
+			}
+		}
+	}
+	return slices.DeleteFunc(names, func(n string) bool { return n == "_" })
+}
+
+// dirPackageName returns the package of the Go files in dir, or "" when
+// there are none.
+func dirPackageName(fsys genfs.WriteFS, dir string) string {
+	for _, path := range goFiles(fsys, dir) {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := fsys.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, src, parser.PackageClauseOnly)
+		if err == nil {
+			return file.Name.Name
+		}
+	}
+	return ""
+}
+
+// goFiles returns the Go files in dir. Route directories like [id] can't
+// be globbed.
+func goFiles(fsys genfs.WriteFS, dir string) []string {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	return paths
+}
@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// routesSection is one file of a split routes file, registering the routes
+// under a top-level URL segment.
+type routesSection struct {
+	Name     string // e.g., "users" for /users/... and /api/users/...
+	FuncName string // e.g., "registerUsersRoutes"
+	File     string // e.g., "nexo_routes_users.go"
+}
+
+// buildConstraintSuffixes are file name suffixes Go treats as build
+// constraints (or tests), which a section file must not end with.
+var buildConstraintSuffixes = map[string]bool{
+	"test": true,
+	// GOOS
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+	// GOARCH
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true,
+	"riscv": true, "riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true,
+	"wasm": true,
+}
+
+// projectConfig returns the nexo.yaml of the project holding appDir, or
+// the defaults when it can't be read.
+func projectConfig(appDir string) *nexo.Config {
+	cfg, err := nexo.LoadConfig(filepath.Dir(appDir))
+	if err != nil {
+		return nexo.DefaultConfig()
+	}
+	return cfg
+}
+
+// sectionName returns the section of the split routes file a URL pattern
+// goes in: its first segment, or its second under /api, reduced to
+// lowercase letters, digits and underscores. It is "" for patterns at the
+// root, which stay in the aggregating file.
+func sectionName(pattern string) string {
+	segs := strings.Split(strings.Trim(pattern, "/"), "/")
+	if segs[0] == "api" && len(segs) > 1 {
+		segs = segs[1:]
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, segs[0])
+	return strings.Trim(name, "_")
+}
+
+// newRoutesSection names the file and function of a section of the routes
+// file at outputPath.
+func newRoutesSection(outputPath, name string) routesSection {
+	var funcName strings.Builder
+	funcName.WriteString("register")
+	for part := range strings.SplitSeq(name, "_") {
+		if part != "" {
+			funcName.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	funcName.WriteString("Routes")
+
+	suffix := name
+	if parts := strings.Split(name, "_"); buildConstraintSuffixes[parts[len(parts)-1]] {
+		suffix += "_section" // nexo_routes_linux.go would only build on Linux
+	}
+	base := strings.TrimSuffix(filepath.Base(outputPath), ".go")
+	return routesSection{
+		Name:     name,
+		FuncName: funcName.String(),
+		File:     filepath.Join(filepath.Dir(outputPath), base+"_"+suffix+".go"),
+	}
+}
+
+// splitRoutesConfig divides the registrations of cfg between sections by
+// the first segment of their pattern. The returned root config keeps the
+// app-wide registrations, like the proxy, and those at the root.
+func splitRoutesConfig(cfg RoutesGenConfig) (RoutesGenConfig, []RoutesGenConfig) {
+	root := cfg
+	root.Routes, root.Middlewares, root.Pages, root.Intercepts, root.Content, root.GraphQL = nil, nil, nil, nil, nil, nil
+
+	var sections []RoutesGenConfig
+	index := make(map[string]int)
+	section := func(pattern string) *RoutesGenConfig {
+		name := sectionName(pattern)
+		if name == "" {
+			return &root
+		}
+		i, ok := index[name]
+		if !ok {
+			sec := newRoutesSection(cfg.OutputPath, name)
+			i = len(sections)
+			index[name] = i
+			sections = append(sections, RoutesGenConfig{
+				ModuleName:    cfg.ModuleName,
+				AppDir:        cfg.AppDir,
+				OutputPath:    sec.File,
+				TemplateDir:   cfg.TemplateDir,
+				LocalizePages: cfg.LocalizePages,
+				section:       &sec,
+			})
+		}
+		return &sections[i]
+	}
+
+	for _, r := range cfg.Routes {
+		s := section(r.Pattern)
+		s.Routes = append(s.Routes, r)
+	}
+	for _, m := range cfg.Middlewares {
+		s := section(m.PathPrefix)
+		s.Middlewares = append(s.Middlewares, m)
+	}
+	for _, p := range cfg.Pages {
+		s := section(p.Pattern)
+		s.Pages = append(s.Pages, p)
+	}
+	for _, p := range cfg.Intercepts {
+		s := section(p.Pattern)
+		s.Intercepts = append(s.Intercepts, p)
+	}
+	for _, c := range cfg.Content {
+		s := section(c.Pattern)
+		s.Content = append(s.Content, c)
+	}
+	for _, g := range cfg.GraphQL {
+		s := section(g.Pattern)
+		s.GraphQL = append(s.GraphQL, g)
+	}
+
+	slices.SortFunc(sections, func(a, b RoutesGenConfig) int {
+		return strings.Compare(a.section.Name, b.section.Name)
+	})
+	for _, s := range sections {
+		root.sections = append(root.sections, *s.section)
+	}
+	return root, sections
+}
+
+// generateSplitRoutesFiles writes a routes file per section and the
+// aggregating routes file calling them.
+func generateSplitRoutesFiles(cfg RoutesGenConfig) (*Result, error) {
+	sortRegistrations(&cfg)
+	root, sections := splitRoutesConfig(cfg)
+
+	// Render every file before writing any
+	files := []string{cfg.OutputPath}
+	outputs := map[string][]byte{}
+	content, err := renderRoutesFile(root)
+	if err != nil {
+		return nil, err
+	}
+	outputs[cfg.OutputPath] = content
+	for _, sec := range sections {
+		content, err := renderRoutesFile(sec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sec.OutputPath, err)
+		}
+		outputs[sec.OutputPath] = content
+		files = append(files, sec.OutputPath)
+	}
+
+	for _, path := range files {
+		if err := writeGeneratedFile(cfg.FS, path, outputs[path]); err != nil {
+			return nil, err
+		}
+	}
+	if err := removeStaleSections(cfg.FS, cfg.OutputPath, files); err != nil {
+		return nil, err
+	}
+	return &Result{Files: files}, nil
+}
+
+// removeStaleSections deletes the generated section files of the routes
+// file at outputPath that aren't in keep, like those of a section whose
+// routes were removed or all of them once splitting is turned off.
+func removeStaleSections(fsys genfs.WriteFS, outputPath string, keep []string) error {
+	base := strings.TrimSuffix(filepath.Base(outputPath), ".go")
+	entries, err := fsys.ReadDir(filepath.Dir(outputPath))
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+"_") || filepath.Ext(name) != ".go" {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(outputPath), name)
+		if slices.Contains(keep, path) || !isGeneratedRoutesFile(fsys, path) {
+			continue
+		}
+		if err := fsys.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove stale routes file: %w", err)
+		}
+	}
+	return nil
+}
+
+// isGeneratedRoutesFile reports whether the file at path starts with the
+// header of a generated routes file.
+func isGeneratedRoutesFile(fsys genfs.WriteFS, path string) bool {
+	src, err := fsys.ReadFile(path)
+	return err == nil && bytes.HasPrefix(src, []byte("// Code generated by nexo. DO NOT EDIT.\n"))
+}
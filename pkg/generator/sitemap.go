@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SitemapConfig configures the program generated by GenerateSitemapProgram.
+type SitemapConfig struct {
+	BaseURL    string // Prefix of every URL, e.g. "https://acme.com"
+	OutputPath string // Where the program writes sitemap.xml
+}
+
+// ScanSitemapPages returns the pages under appDir that belong in the
+// sitemap: static pages, and dynamic pages that declare GenerateStaticParams,
+// minus pages with a "// nexo:sitemap exclude" directive, followed by the
+// Markdown pages in content/ that aren't drafts.
+func ScanSitemapPages(appDir string) ([]PageRegistration, error) {
+	moduleName, err := getModuleName()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module name: %w", err)
+	}
+
+	var pages []PageRegistration
+	err = filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if isGeneratorPrivateFolder(info.Name(), path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() != "page.templ" {
+			return nil
+		}
+
+		page, err := scanPageFile(path, appDir, moduleName)
+		if err != nil {
+			return err
+		}
+		if page != nil && page.Listed() {
+			pages = append(pages, *page)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Markdown content pages, unless a page.templ has the same route
+	content, err := scanContentDir(filepath.Join(filepath.Dir(appDir), "content"))
+	if err != nil {
+		return nil, err
+	}
+	patterns := make(map[string]bool, len(pages))
+	for _, p := range pages {
+		patterns[p.Pattern] = true
+	}
+	for _, c := range content {
+		if !c.Draft && !patterns[c.Pattern] {
+			pages = append(pages, PageRegistration{Pattern: c.Pattern, FilePath: c.FilePath, Title: c.Title})
+		}
+	}
+	return pages, nil
+}
+
+// sitemapProgramTemplate is a main package that writes the sitemap of pages,
+// calling the GenerateStaticParams functions of dynamic pages.
+var sitemapProgramTemplate = `// Code generated by nexo sitemap. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+{{- range .Pages}}
+{{- if .URLParams}}
+	{{.ImportAlias}} "{{.ImportPath}}"
+{{- end}}
+{{- end}}
+)
+
+func main() {
+	sitemap := nexo.NewSitemap()
+{{- range .Pages}}
+	sitemap.Add("{{.Pattern}}", {{sitemapOptions .}})
+{{- end}}
+
+	f, err := os.Create({{printf "%q" .OutputPath}})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := sitemap.WriteXML(context.Background(), f, {{printf "%q" .BaseURL}}); err != nil {
+		f.Close()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
+
+// GenerateSitemapProgram renders a Go program that writes the sitemap of
+// pages to cfg.OutputPath. nexo sitemap runs it when dynamic pages need their
+// GenerateStaticParams functions called.
+func GenerateSitemapProgram(pages []PageRegistration, cfg SitemapConfig) ([]byte, error) {
+	pages = append([]PageRegistration(nil), pages...)
+	for i := range pages {
+		pages[i].ImportAlias = fmt.Sprintf("page%d", i)
+	}
+
+	data := struct {
+		SitemapConfig
+		Pages []PageRegistration
+	}{cfg, pages}
+	return renderTemplate("sitemap.go", sitemapProgramTemplate, routeTemplateFuncs, data)
+}
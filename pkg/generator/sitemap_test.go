@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSitemapProgram(t *testing.T) {
+	pages := []PageRegistration{
+		{Pattern: "/", ImportPath: "example.com/app/app", Sitemap: PageSitemap{Priority: "1.0"}},
+		{
+			Pattern:    "/posts/{slug}",
+			ImportPath: "example.com/app/app/posts/[slug]",
+			URLParams:  []string{"slug"},
+			Sitemap:    PageSitemap{ChangeFreq: "weekly", StaticParams: true},
+		},
+	}
+
+	src, err := GenerateSitemapProgram(pages, SitemapConfig{BaseURL: "https://acme.test", OutputPath: "static/sitemap.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+		t.Fatalf("generated program does not parse: %v\n%s", err, src)
+	}
+
+	got := string(src)
+	want := []string{
+		`page1 "example.com/app/app/posts/[slug]"`,
+		`sitemap.Add("/", nexo.SitemapOptions{Priority: 1.0})`,
+		`sitemap.Add("/posts/{slug}", nexo.SitemapOptions{ChangeFreq: "weekly", Params: page1.GenerateStaticParams})`,
+		`os.Create("static/sitemap.xml")`,
+		`sitemap.WriteXML(context.Background(), f, "https://acme.test")`,
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("program missing %s\n\ngot:\n%s", w, got)
+		}
+	}
+	// Static pages aren't imported
+	if strings.Contains(got, `page0 "`) {
+		t.Errorf("program imports a static page:\n%s", got)
+	}
+}
+
+func TestScanSitemapPages(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"go.mod":                      "module testmodule\ngo 1.21\n",
+		"app/page.templ":              "package app\n\ntempl Page() {}\n",
+		"app/admin/page.templ":        "package admin\n\n// nexo:sitemap exclude\ntempl Page() {}\n",
+		"app/users/[id]/page.templ":   "package id\n\ntempl Page(id string) {}\n",
+		"app/posts/[slug]/page.templ": "package slug\n\ntempl Page(slug string) {}\n",
+		"app/posts/[slug]/params.go":  "package slug\n\nfunc GenerateStaticParams(ctx context.Context) ([]map[string]string, error) {\n\treturn nil, nil\n}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages, err := ScanSitemapPages("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range pages {
+		got = append(got, p.Pattern)
+	}
+	if want := "/ /posts/{slug}"; strings.Join(got, " ") != want {
+		t.Errorf("patterns = %v, want %s", got, want)
+	}
+}
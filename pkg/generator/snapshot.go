@@ -0,0 +1,370 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// RenderSnapshot renders every built-in template with representative fixture
+// data into an in-memory filesystem. File paths mirror where the generator
+// would write each file, so the result can be compared against golden files
+// with CompareGolden.
+//
+// Example:
+//
+//	fsys, err := generator.RenderSnapshot()
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	if err := generator.CompareGolden(fsys, "testdata/golden", *update); err != nil {
+//	    t.Fatal(err)
+//	}
+func RenderSnapshot() (fstest.MapFS, error) {
+	fsys := fstest.MapFS{}
+
+	add := func(name string, content []byte, err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fsys[name] = &fstest.MapFile{Data: content, Mode: 0644}
+		return nil
+	}
+
+	// Route stub
+	routeData := routeTemplateData{
+		Package: "id",
+		Methods: []methodInfo{
+			{Method: "GET", FuncName: "Get"},
+			{Method: "PUT", FuncName: "Put", HasBody: true},
+			{Method: "DELETE", FuncName: "Delete"},
+		},
+		Params:  []ParamInfo{{Name: "id"}},
+		Pattern: "users/{id}",
+	}
+	content, err := renderTemplate("route.go", routeTemplate, nil, routeData)
+	if err := add("app/api/users/[id]/route.go", content, err); err != nil {
+		return nil, err
+	}
+
+	// Middleware templates
+	for _, name := range sortedKeys(middlewareTemplates) {
+		data := middlewareTemplateData{
+			Package: "protected",
+			Name:    name,
+			Path:    "/api/protected",
+		}
+		content, err := renderTemplate("middleware.go", middlewareTemplates[name], nil, data)
+		if err := add("middleware/"+name+"/middleware.go", content, err); err != nil {
+			return nil, err
+		}
+	}
+
+	// Proxy templates
+	for _, name := range sortedKeys(proxyTemplates) {
+		content, err := renderTemplate("proxy.go", proxyTemplates[name], nil, nil)
+		if err := add("proxy/"+name+"/proxy.go", content, err); err != nil {
+			return nil, err
+		}
+	}
+
+	// Page, layout and loader
+	pageData := pageTemplateData{
+		Package:  "dashboard",
+		Title:    "Dashboard",
+		FilePath: "app/dashboard/page.templ",
+	}
+	content, err = renderTemplate("page.templ", pageTemplate, nil, pageData)
+	if err := add("app/dashboard/page.templ", content, err); err != nil {
+		return nil, err
+	}
+
+	pageData.FilePath = "app/dashboard/layout.templ"
+	content, err = renderTemplate("layout.templ", layoutTemplate, nil, pageData)
+	if err := add("app/dashboard/layout.templ", content, err); err != nil {
+		return nil, err
+	}
+
+	loaderData := struct {
+		Package  string
+		DataType string
+	}{
+		Package:  "dashboard",
+		DataType: "DashboardData",
+	}
+	content, err = renderTemplate("loader.go", loaderTemplate, nil, loaderData)
+	if err := add("app/dashboard/loader.go", content, err); err != nil {
+		return nil, err
+	}
+
+	// Routes file (populated and empty)
+	content, err = renderRoutesFile(snapshotRoutesConfig())
+	if err := add("nexo_routes.go", content, err); err != nil {
+		return nil, err
+	}
+
+	content, err = renderRoutesFile(RoutesGenConfig{})
+	if err := add("empty/nexo_routes.go", content, err); err != nil {
+		return nil, err
+	}
+
+	return fsys, nil
+}
+
+// snapshotRoutesConfig returns a routes file configuration that exercises
+// every branch of the routes template.
+func snapshotRoutesConfig() RoutesGenConfig {
+	const module = "example.com/app"
+
+	return RoutesGenConfig{
+		ModuleName:    module,
+		AppDir:        "app",
+		LocalizePages: true,
+		Headers: []nexo.HeaderRule{
+			{Source: "/*", Headers: map[string]string{"x-frame-options": "DENY", "x-content-type-options": "nosniff"}},
+			{Source: "/static/*", Headers: map[string]string{"cache-control": "public, max-age=31536000, immutable"}},
+		},
+		Proxy: &ProxyRegistration{
+			ImportPath: module + "/app",
+			Package:    "app",
+			FilePath:   "app/proxy.go",
+			HasConfig:  true,
+		},
+		Middlewares: []MiddlewareRegistration{
+			{
+				ImportPath: module + "/app/api",
+				Package:    "api",
+				PathPrefix: "/api",
+				Scope:      "api",
+				FilePath:   "app/api/middleware.go",
+			},
+			{
+				ImportPath: module + "/app/_middleware/auth",
+				Package:    "auth",
+				PathPrefix: "/api/orders",
+				Scope:      "api/orders",
+				FilePath:   "app/_middleware/auth/middleware.go",
+				Name:       "auth",
+			},
+		},
+		Routes: []RouteRegistration{
+			{
+				ImportPath: module + "/app/api/users",
+				Package:    "users",
+				Method:     "GET",
+				Pattern:    "/api/users",
+				Handler:    "Get",
+				FilePath:   "app/api/users/route.go",
+				HasCORS:    true,
+			},
+			{
+				ImportPath: module + "/app/api/users",
+				Package:    "users",
+				Method:     "POST",
+				Pattern:    "/api/users",
+				Handler:    "Post",
+				FilePath:   "app/api/users/route.go",
+				HasCORS:    true,
+			},
+			{
+				ImportPath: module + "/app/api/orders",
+				Package:    "orders",
+				Method:     "POST",
+				Pattern:    "/api/orders",
+				Handler:    "Post",
+				FilePath:   "app/api/orders/route.go",
+				HasConfig:  true,
+				BodyType:   "PostBody",
+			},
+			{
+				ImportPath: module + "/app/api/reports",
+				Package:    "reports",
+				Method:     "GET",
+				Pattern:    "/api/reports",
+				Handler:    "Get",
+				FilePath:   "app/api/reports/route.go",
+				Deps:       []string{"*database/sql.DB"},
+				Options:    &scanner.RouteOptions{Cache: time.Minute, Auth: true},
+			},
+			{
+				ImportPath:  module + "/app/docs/changelog",
+				Package:     "changelog",
+				Method:      "GET",
+				Pattern:     "/docs/changelog",
+				Handler:     "Get",
+				FilePath:    "app/docs/changelog/route.go",
+				Priority:    120,
+				HasPriority: true,
+			},
+		},
+		Pages: []PageRegistration{
+			{
+				ImportPath: module + "/app",
+				Package:    "app",
+				Pattern:    "/",
+				Title:      "Home",
+				FilePath:   "app/page.templ",
+				Sitemap:    PageSitemap{ChangeFreq: "daily", Priority: "1.0"},
+			},
+			{
+				ImportPath:     module + "/" + GeneratedDir + "/app_posts_slug",
+				Package:        "slug",
+				Pattern:        "/posts/{slug}",
+				Title:          "Posts",
+				FilePath:       "app/posts/[slug]/page.templ",
+				Params:         []PageParam{{Name: "slug", Type: "string", FromPath: true}},
+				URLParams:      []string{"slug"},
+				HasParams:      true,
+				ParamSignature: "Page(slug string)",
+				Sitemap:        PageSitemap{ChangeFreq: "weekly", StaticParams: true},
+				Segments: []PageSegment{
+					{ImportPath: module + "/app", Package: "app", Layout: true, LayoutTitle: true, Metadata: true},
+					{ImportPath: module + "/" + GeneratedDir + "/app_posts_slug", Package: "slug", GenerateMetadata: true},
+				},
+			},
+			{
+				ImportPath:       module + "/app/dashboard",
+				Package:          "dashboard",
+				Pattern:          "/dashboard",
+				Title:            "Dashboard",
+				FilePath:         "app/dashboard/page.templ",
+				HasLoader:        true,
+				LoaderImportPath: module + "/app/dashboard",
+				LoaderPackage:    "dashboard",
+				Sitemap:          PageSitemap{Exclude: true},
+			},
+			{
+				ImportPath:       module + "/app/reports",
+				Package:          "reports",
+				Pattern:          "/reports",
+				Title:            "Reports",
+				FilePath:         "app/reports/page.templ",
+				HasLoader:        true,
+				LoaderImportPath: module + "/app/reports",
+				LoaderPackage:    "reports",
+				LoaderFilePath:   "app/reports/loader.go",
+				LoaderDeps:       []string{"*database/sql.DB", "example.com/app/cache.Store"},
+				Segments: []PageSegment{
+					{ImportPath: module + "/app", Package: "app", Layout: true, LayoutTitle: true, Metadata: true},
+					{ImportPath: module + "/app/reports", Package: "reports", Layout: true},
+				},
+			},
+		},
+		Content: []ContentRegistration{
+			{
+				Pattern:  "/blog/hello",
+				FilePath: "content/blog/hello.md",
+				Title:    "Hello",
+				Segments: []PageSegment{
+					{ImportPath: module + "/app", Package: "app", Layout: true, LayoutTitle: true, Metadata: true},
+				},
+			},
+			{
+				Pattern:  "/blog/draft",
+				FilePath: "content/blog/draft.md",
+				Draft:    true,
+			},
+		},
+		GraphQL: []GraphQLRegistration{
+			{
+				ImportPath: module + "/app/graphql",
+				Package:    "graphql",
+				Pattern:    "/graphql",
+				FilePath:   "app/graphql/resolver.go",
+				SchemaPath: "app/graphql/schema.graphql",
+			},
+		},
+	}
+}
+
+// CompareGolden compares every file in fsys against a golden copy stored in
+// goldenDir under the same relative path with a ".golden" suffix.
+// When update is true, golden files are (re)written instead of compared.
+// All mismatches are reported together in the returned error.
+func CompareGolden(fsys fs.FS, goldenDir string, update bool) error {
+	var mismatches []error
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		goldenPath := filepath.Join(goldenDir, filepath.FromSlash(name)+".golden")
+
+		if update {
+			if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+				return fmt.Errorf("failed to create golden directory: %w", err)
+			}
+			return os.WriteFile(goldenPath, got, 0644)
+		}
+
+		want, err := os.ReadFile(goldenPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				mismatches = append(mismatches, fmt.Errorf("%s: missing golden file %s (run with -update)", name, goldenPath))
+				return nil
+			}
+			return err
+		}
+
+		if !bytes.Equal(got, want) {
+			mismatches = append(mismatches, fmt.Errorf("%s: %s", name, firstDifference(string(want), string(got))))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(mismatches...)
+}
+
+// firstDifference describes the first line that differs between want and got.
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d differs\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+
+	return "content differs"
+}
+
+// sortedKeys returns the keys of a template map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
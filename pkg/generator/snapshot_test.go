@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestTemplateSnapshots(t *testing.T) {
+	fsys, err := RenderSnapshot()
+	if err != nil {
+		t.Fatalf("RenderSnapshot() error = %v", err)
+	}
+
+	if err := CompareGolden(fsys, filepath.Join("testdata", "golden"), *update); err != nil {
+		t.Errorf("generated code does not match golden files (run go test ./pkg/generator -update):\n%v", err)
+	}
+}
+
+func TestRenderSnapshot_ContainsAllTemplates(t *testing.T) {
+	fsys, err := RenderSnapshot()
+	if err != nil {
+		t.Fatalf("RenderSnapshot() error = %v", err)
+	}
+
+	want := []string{
+		"app/api/users/[id]/route.go",
+		"app/dashboard/page.templ",
+		"app/dashboard/layout.templ",
+		"app/dashboard/loader.go",
+		"nexo_routes.go",
+		"empty/nexo_routes.go",
+	}
+	for name := range middlewareTemplates {
+		want = append(want, "middleware/"+name+"/middleware.go")
+	}
+	for name := range proxyTemplates {
+		want = append(want, "proxy/"+name+"/proxy.go")
+	}
+
+	for _, name := range want {
+		if _, ok := fsys[name]; !ok {
+			t.Errorf("snapshot missing %s", name)
+		}
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	goldenDir := t.TempDir()
+	fsys := fstest.MapFS{
+		"a/route.go": &fstest.MapFile{Data: []byte("package a\n\nfunc Get() {}\n")},
+	}
+
+	// Missing golden files are reported
+	err := CompareGolden(fsys, goldenDir, false)
+	if err == nil || !strings.Contains(err.Error(), "missing golden file") {
+		t.Fatalf("expected missing golden error, got %v", err)
+	}
+
+	// Update writes golden files
+	if err := CompareGolden(fsys, goldenDir, true); err != nil {
+		t.Fatalf("CompareGolden(update) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(goldenDir, "a", "route.go.golden")); err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+
+	// Matching content passes
+	if err := CompareGolden(fsys, goldenDir, false); err != nil {
+		t.Fatalf("CompareGolden() error = %v", err)
+	}
+
+	// Changed content is reported with the differing line
+	fsys["a/route.go"] = &fstest.MapFile{Data: []byte("package a\n\nfunc Post() {}\n")}
+	err = CompareGolden(fsys, goldenDir, false)
+	if err == nil || !strings.Contains(err.Error(), "line 3 differs") {
+		t.Fatalf("expected line 3 mismatch, got %v", err)
+	}
+}
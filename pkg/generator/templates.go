@@ -12,6 +12,8 @@ type routeTemplateData struct {
 type methodInfo struct {
 	Method   string // HTTP method (GET, POST, etc.)
 	FuncName string // Go function name (Get, Post, etc.)
+	HasBody  bool   // method takes a request body (POST, PUT, PATCH)
+	IsList   bool   // GET of a collection, answered with a page of items
 }
 
 type middlewareTemplateData struct {
@@ -20,6 +22,14 @@ type middlewareTemplateData struct {
 	Path    string
 }
 
+type webhookTemplateData struct {
+	Package  string
+	Provider string // display name, e.g. "Stripe"
+	Path     string // URL path, e.g. "/webhooks/stripe"
+	EnvVar   string // environment variable holding the secret
+	Verifier string // nexo verifier call reading EnvVar
+}
+
 type pageTemplateData struct {
 	Package  string
 	Title    string
@@ -32,18 +42,43 @@ var routeTemplate = `package {{.Package}}
 
 import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
 {{range .Methods}}
+{{- if .HasBody}}
+// {{.FuncName}}Input is the request body of {{.FuncName}}.
+// c.Bind checks the validate tags and responds with 422 listing invalid fields.
+type {{.FuncName}}Input struct {
+	// TODO: Add your fields
+	Name  string ` + "`" + `json:"name" validate:"required,max=100"` + "`" + `
+	Email string ` + "`" + `json:"email" validate:"omitempty,email"` + "`" + `
+}
+{{end}}
 // {{.FuncName}} handles {{.Method}} /api/{{$.Pattern}}
 func {{.FuncName}}(c *nexo.Context) error {
 {{- range $.Params}}
-	{{.Name}} := c.Param("{{.Name}}")
+	{{.Name}} := {{.Value}}
 	_ = {{.Name}} // TODO: use this parameter
 {{- end}}
+{{- if .HasBody}}
+	var input {{.FuncName}}Input
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+	_ = input // TODO: use the input
+{{- end}}
+{{- if .IsList}}
+	// ?page=2&limit=20, capped at nexo.MaxPageLimit
+	page := c.Pagination()
+	// TODO: Load the page, e.g. db.List(c.Context(), page.Limit, page.Offset)
+	var items []map[string]any
+	total := 0
+	return nexo.SendPage(c, nexo.NewPage(items, page, total))
+{{- else}}
 	return c.JSON(200, map[string]any{
 {{- range $.Params}}
 		"{{.Name}}": {{.Name}},
 {{- end}}
 		// TODO: Implement {{.FuncName}} handler
 	})
+{{- end}}
 }
 {{end}}`
 
@@ -415,6 +450,226 @@ func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
 
 	return nexo.Continue(), nil
 }
+`,
+	"ab-test": `package app
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// homepage splits visitors between the current home page (control) and a
+// redesign served from app/redesign/. Visitors keep their variant in the
+// ab_homepage cookie; send X-Variant: redesign to preview it.
+var homepage = nexo.Experiment{
+	Name:     "homepage",
+	Variants: []string{"control", "redesign"},
+	Weights:  []int{90, 10}, // 10% of visitors see the redesign
+	Header:   "X-Variant",
+}
+
+// ProxyConfig limits the experiment to the home page.
+var ProxyConfig = &nexo.ProxyConfig{
+	Matcher: []string{"/"},
+}
+
+// Proxy rewrites visitors in the redesign variant to /redesign.
+// Handlers read the variant with homepage.Variant(c), e.g. for analytics.
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+	return homepage.Rewrite(c), nil
+}
+`,
+}
+
+// Webhook templates
+
+var webhookMiddlewareTemplate = `package {{.Package}}
+
+import (
+	"os"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Middleware verifies that requests to {{.Path}} were sent by {{.Provider}},
+// rejects replayed deliveries and answers redeliveries of events that were
+// already handled without running the handler again.
+func Middleware(next nexo.HandlerFunc) nexo.HandlerFunc {
+	return nexo.Webhook({{.Verifier}})(next)
+}
+`
+
+// webhookRouteTemplates are the route.go templates by provider; providers
+// without one use "hmac".
+var webhookRouteTemplates = map[string]string{
+	"stripe": `package {{.Package}}
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Event is a Stripe event.
+type Event struct {
+	ID   string ` + "`" + `json:"id"` + "`" + `
+	Type string ` + "`" + `json:"type"` + "`" + `
+	Data struct {
+		Object json.RawMessage ` + "`" + `json:"object"` + "`" + `
+	} ` + "`" + `json:"data"` + "`" + `
+}
+
+// Post receives Stripe events at {{.Path}}.
+// Return an error to have Stripe retry the delivery later.
+func Post(c *nexo.Context) error {
+	var event Event
+	if err := c.Bind(&event); err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case "checkout.session.completed":
+		// TODO: Fulfill the order in event.Data.Object
+	case "invoice.paid":
+		// TODO: Extend the subscription
+	default:
+		log.Printf("stripe: unhandled event %s", event.Type)
+	}
+	return c.NoContent()
+}
+`,
+	"github": `package {{.Package}}
+
+import (
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Event holds the fields shared by GitHub webhook payloads.
+type Event struct {
+	Action     string ` + "`" + `json:"action"` + "`" + `
+	Repository struct {
+		FullName string ` + "`" + `json:"full_name"` + "`" + `
+	} ` + "`" + `json:"repository"` + "`" + `
+}
+
+// Post receives GitHub webhooks at {{.Path}}.
+// Return an error to mark the delivery as failed in GitHub.
+func Post(c *nexo.Context) error {
+	var event Event
+	if err := c.Bind(&event); err != nil {
+		return err
+	}
+
+	switch c.Header("X-GitHub-Event") {
+	case "ping":
+	case "push":
+		// TODO: Handle the push to event.Repository.FullName
+	case "pull_request":
+		// TODO: Handle the pull request event.Action
+	default:
+		log.Printf("github: unhandled event %s", c.Header("X-GitHub-Event"))
+	}
+	return c.NoContent()
+}
+`,
+	"slack": `package {{.Package}}
+
+import (
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Event is a Slack Events API request.
+type Event struct {
+	Type      string ` + "`" + `json:"type"` + "`" + `
+	Challenge string ` + "`" + `json:"challenge"` + "`" + `
+	Event     struct {
+		Type    string ` + "`" + `json:"type"` + "`" + `
+		User    string ` + "`" + `json:"user"` + "`" + `
+		Channel string ` + "`" + `json:"channel"` + "`" + `
+		Text    string ` + "`" + `json:"text"` + "`" + `
+	} ` + "`" + `json:"event"` + "`" + `
+}
+
+// Post receives Slack events at {{.Path}}.
+func Post(c *nexo.Context) error {
+	var event Event
+	if err := c.Bind(&event); err != nil {
+		return err
+	}
+
+	// Slack checks the URL with a challenge when it's configured
+	if event.Type == "url_verification" {
+		return c.JSON(200, map[string]string{"challenge": event.Challenge})
+	}
+
+	switch event.Event.Type {
+	case "app_mention":
+		// TODO: Reply to event.Event.User in event.Event.Channel
+	default:
+		log.Printf("slack: unhandled event %s", event.Event.Type)
+	}
+	return c.NoContent()
+}
+`,
+	"shopify": `package {{.Package}}
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Post receives Shopify webhooks at {{.Path}}.
+// Return an error to have Shopify retry the delivery later.
+func Post(c *nexo.Context) error {
+	var payload map[string]json.RawMessage
+	if err := c.Bind(&payload); err != nil {
+		return err
+	}
+
+	switch c.Header("X-Shopify-Topic") {
+	case "orders/create":
+		// TODO: Handle the new order
+	case "app/uninstalled":
+		// TODO: Remove the shop's data
+	default:
+		log.Printf("shopify: unhandled topic %s", c.Header("X-Shopify-Topic"))
+	}
+	return c.NoContent()
+}
+`,
+	"hmac": `package {{.Package}}
+
+import (
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Event is a {{.Provider}} webhook event.
+// TODO: Match the fields of the provider's payload
+type Event struct {
+	ID   string ` + "`" + `json:"id"` + "`" + `
+	Type string ` + "`" + `json:"type"` + "`" + `
+}
+
+// Post receives {{.Provider}} webhooks at {{.Path}}.
+// Return an error to have the provider retry the delivery later.
+func Post(c *nexo.Context) error {
+	var event Event
+	if err := c.Bind(&event); err != nil {
+		return err
+	}
+
+	switch event.Type {
+	default:
+		log.Printf("{{.Package}}: unhandled event %s", event.Type)
+	}
+	return c.NoContent()
+}
 `,
 }
 
@@ -441,6 +696,9 @@ func Loader(c *nexo.Context) ({{.DataType}}, error) {
 	// - Call external API
 	// - Read from cache
 	//
+	// Pass c.Context() to queries and API calls so they stop when the
+	// client goes away or the route times out.
+	//
 	// Return an error to stop page rendering:
 	// if notFound {
 	//     return {{.DataType}}{}, nexo.NotFound("Resource not found")
@@ -512,14 +770,38 @@ var routesGenTemplate = `// Code generated by nexo. DO NOT EDIT.
 package main
 
 import (
+{{- if .HasEmbed}}
+	_ "embed"
+{{- end}}
+{{- if .HasTime}}
+	"time"
+{{- end}}
+{{- if or .HasEmbed .HasTime}}
+{{end}}
 	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
 {{range .Imports}}
 	{{.Alias}} "{{.Path}}"
 {{- end}}
 )
-
+{{range .GraphQL}}{{if .SchemaEmbed}}
+//go:embed {{.SchemaEmbed}}
+var {{.SchemaVar}} string
+{{end}}{{end}}
+{{- if .Section}}
+// {{.FuncName}} registers the file-based routes of the {{.Section}} section.
+func {{.FuncName}}(app *nexo.App) {
+{{- else}}
 // RegisterRoutes registers all file-based routes with the app.
 func RegisterRoutes(app *nexo.App) {
+{{- end}}
+{{- if .Headers}}
+	// Response headers (from nexo.yaml)
+	app.UseHeaders(
+	{{- range .Headers}}
+		nexo.HeaderRule{Source: {{printf "%q" .Source}}, Headers: {{headerMap .Headers}}},
+	{{- end}}
+	)
+{{end}}
 {{- if .Proxy}}
 	// Register proxy (from {{.Proxy.FilePath}})
 	{{- if .Proxy.HasConfig}}
@@ -528,24 +810,65 @@ func RegisterRoutes(app *nexo.App) {
 	_ = app.SetProxy({{.Proxy.ImportAlias}}.Proxy, nil)
 	{{- end}}
 {{end}}
+{{- if .Maintenance}}
+	// Register maintenance page (from {{.Maintenance.FilePath}})
+	app.SetMaintenancePage({{.Maintenance.ImportAlias}}.Maintenance())
+{{end}}
 {{- range .Middlewares}}
-	// Middleware for {{.PathPrefix}} (from {{.FilePath}})
-	app.RouteTree().AddMiddleware("{{.PathPrefix}}", {{.ImportAlias}}.Middleware)
+	// {{if .Name}}Middleware {{.Name}}{{else}}Middleware{{end}} for {{.PathPrefix}} (from {{.FilePath}})
+	{{- if .Name}}
+	app.RouteTree().AddNamedMiddleware("{{.PathPrefix}}", "{{.Scope}}", "{{.Name}}", {{.ImportAlias}}.Middleware)
+	{{- else}}
+	app.RouteTree().AddMiddleware("{{.PathPrefix}}", "{{.Scope}}", {{.ImportAlias}}.Middleware)
+	{{- end}}
 {{- end}}
 {{range .Routes}}
 	// {{.Method}} {{.Pattern}} (from {{.FilePath}})
-	app.RegisterRoute("{{.Method}}", "{{.Pattern}}", {{.ImportAlias}}.{{.Handler}})
+	{{- if .HasConfig}}
+	app.RegisterRouteWithConfig("{{.Method}}", "{{.Pattern}}", {{handlerExpr .}}, {{.ImportAlias}}.RouteConfig)
+	{{- else}}
+	app.RegisterRoute("{{.Method}}", "{{.Pattern}}", {{handlerExpr .}})
+	{{- end}}
+	{{- if .HasPriority}}
+	app.SetRoutePriority("{{.Method}}", "{{.Pattern}}", {{.Priority}})
+	{{- end}}
+	{{- if routeMiddleware .}}
+	app.AddRouteMiddleware("{{.Method}}", "{{.Pattern}}", {{routeMiddleware .}})
+	{{- end}}
+	{{- if skipMiddleware .}}
+	app.SkipRouteMiddleware("{{.Method}}", "{{.Pattern}}", {{skipMiddleware .}})
+	{{- end}}
+	{{- if onlyMiddleware .}}
+	app.OnlyRouteMiddleware("{{.Method}}", "{{.Pattern}}", {{onlyMiddleware .}})
+	{{- end}}
+{{- end}}
+{{- range corsRoutes .Routes}}
+	// CORS policy for {{.Pattern}}
+	app.SetRouteCORS("{{.Pattern}}", {{.ImportAlias}}.CORS)
 {{- end}}
 {{- range .Pages}}
-{{- if .HasLoader}}
+{{- if and .HasLoader .LoaderDeps}}
+	// Page: {{.Pattern}} (from {{.FilePath}})
+	// Data loaded by: {{.LoaderPackage}}.Loader() with injected dependencies
+	{
+		loader := {{loaderExpr .}}
+		app.Get("{{.Pattern}}", func(c *nexo.Context) error {
+			data, err := nexo.Load(c, loader)
+			if err != nil {
+				return err
+			}
+			return {{renderPage . (printf "%s.Page(data)" .ImportAlias) 3}}
+		})
+	}
+{{- else if .HasLoader}}
 	// Page: {{.Pattern}} (from {{.FilePath}})
 	// Data loaded by: {{.LoaderPackage}}.Loader()
 	app.Get("{{.Pattern}}", func(c *nexo.Context) error {
-		data, err := {{.ImportAlias}}.Loader(c)
+		data, err := nexo.Load(c, {{.ImportAlias}}.Loader)
 		if err != nil {
 			return err
 		}
-		return nexo.TemplComponent(c, 200, {{.ImportAlias}}.Page(data))
+		return {{renderPage . (printf "%s.Page(data)" .ImportAlias) 2}}
 	})
 {{- else if .HasParams}}
 	// Page: {{.Pattern}} (from {{.FilePath}})
@@ -553,17 +876,66 @@ func RegisterRoutes(app *nexo.App) {
 	app.Get("{{.Pattern}}", func(c *nexo.Context) error {
 		{{- range .Params}}
 		{{- if .FromPath}}
-		{{.Name}} := c.Param("{{.Name}}")
+		{{.Name}} := {{.Value}}
 		{{- end}}
 		{{- end}}
-		return nexo.TemplComponent(c, 200, {{.ImportAlias}}.Page({{paramArgs .Params}}))
+		return {{renderPage . (printf "%s.Page(%s)" .ImportAlias (paramArgs .Params)) 2}}
 	})
 {{- else}}
 	// Page: {{.Pattern}} (from {{.FilePath}})
 	app.Get("{{.Pattern}}", func(c *nexo.Context) error {
-		return nexo.TemplComponent(c, 200, {{.ImportAlias}}.Page())
+		return {{renderPage . (printf "%s.Page()" .ImportAlias) 2}}
+	})
+{{- end}}
+{{- end}}
+{{- range .Intercepts}}
+	// Intercepted: {{.Pattern}} for HTMX navigation from {{.InterceptFrom}} (from {{.FilePath}})
+	app.InterceptRoute("{{.Pattern}}", "{{.InterceptFrom}}", func(c *nexo.Context) error {
+		return nexo.TemplComponent(c, 200, {{pageCall .}})
 	})
 {{- end}}
+{{- range .Content}}
+	// Content: {{.Pattern}} (from {{.FilePath}})
+	app.Get("{{.Pattern}}", {{markdownPage . 1}})
+{{- end}}
+{{- if or .Pages .Content}}
+
+	// Sitemap (served with nexo.WithSitemap)
+{{- range .Pages}}
+{{- if .Listed}}
+	app.Sitemap().Add("{{.Pattern}}", {{sitemapOptions .}})
+{{- end}}
+{{- end}}
+{{- range .Content}}
+{{- if not .Draft}}
+	app.Sitemap().Add("{{.Pattern}}", nexo.SitemapOptions{})
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Localize}}
+
+	// Locale-prefixed pages (enabled with nexo.WithLocaleRouting)
+	app.LocalizeRoutes({{range $i, $p := .Pages}}{{if $i}}, {{end}}"{{$p.Pattern}}"{{end}})
+{{- end}}
+{{- range .GraphQL}}
+
+	// GraphQL: {{.Pattern}} (from {{.FilePath}})
+	{
+		handler := nexo.GraphQL(nexo.GraphQLConfig{
+			{{- if .SchemaEmbed}}
+			Schema:     {{.SchemaVar}},
+			{{- end}}
+			Executor:   {{.ImportAlias}}.Execute,
+			Playground: app.Mode() == nexo.ModeDevelopment,
+		})
+		app.Get("{{.Pattern}}", handler)
+		app.Post("{{.Pattern}}", handler)
+	}
+{{- end}}
+{{- if .Sections}}
+{{range .Sections}}
+	{{.FuncName}}(app) // {{.File}}
+{{- end}}
 {{- end}}
 }
 `
@@ -0,0 +1,381 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// TestsConfig holds configuration for test generation.
+type TestsConfig struct {
+	Path   string // Route path (e.g., "api/users/[id]")
+	AppDir string // App directory (default: "app")
+
+	FS genfs.WriteFS // Filesystem written to (default: genfs.Disk)
+}
+
+// testsTemplateData is the data of testsTemplate.
+type testsTemplateData struct {
+	Package  string
+	Pattern  string // registered pattern, like /api/users/{id}
+	Path     string // request path matching Pattern, like /api/users/1
+	TestDir  string // package directory go test runs, see PackageDir
+	Handlers []testedHandler
+}
+
+// testedHandler is a handler tests are generated for.
+type testedHandler struct {
+	Name    string // Get, Post, ...
+	Body    string // body type of a HandlerBody, or ""
+	Seed    string // example JSON body of Body
+	Context bool   // takes a context.Context first
+}
+
+// Register returns the expression registering the handler.
+func (h testedHandler) Register() string {
+	switch {
+	case h.Body != "" && h.Context:
+		return "nexo.WithBodyContext(" + h.Name + ")"
+	case h.Body != "":
+		return "nexo.WithBody(" + h.Name + ")"
+	case h.Context:
+		return "nexo.WithContext(" + h.Name + ")"
+	}
+	return h.Name
+}
+
+// GenerateTests generates route_test.go for the route at cfg.Path: a
+// table-driven test per handler whose responses are compared with golden
+// files in testdata/, and a fuzz target per handler binding a body, which
+// checks that no body makes it panic or fail with a server error.
+//
+// Handlers taking injected dependencies are listed in Result.Skipped, as
+// their dependencies can't be built without the app.
+func GenerateTests(cfg TestsConfig) (*Result, error) {
+	if cfg.AppDir == "" {
+		cfg.AppDir = "app"
+	}
+	fsys := genfs.Or(cfg.FS)
+	dir, _, err := resolveRouteDir(fsys, cfg.AppDir, cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	testPath := filepath.Join(dir, "route_test.go")
+	if genfs.Exists(fsys, testPath) {
+		return nil, fmt.Errorf("file already exists: %s", testPath)
+	}
+
+	pkg, handlers, skipped, err := scanTestedHandlers(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(handlers) == 0 {
+		if len(skipped) > 0 {
+			return nil, fmt.Errorf("%s only has handlers with injected dependencies", dir)
+		}
+		return nil, fmt.Errorf("no route handlers in %s", dir)
+	}
+
+	rel, err := filepath.Rel(cfg.AppDir, dir)
+	if err != nil {
+		return nil, err
+	}
+	pattern := "/" + pathToPattern(filepath.ToSlash(rel))
+	data := testsTemplateData{
+		Package:  pkg,
+		Pattern:  pattern,
+		Path:     examplePath(pattern),
+		TestDir:  filepath.ToSlash(PackageDir(dir)),
+		Handlers: handlers,
+	}
+	if err := executeTemplate(fsys, testPath, testsTemplate, data); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Files:   []string{testPath},
+		Skipped: skipped,
+		Pattern: pattern,
+	}, nil
+}
+
+// scanTestedHandlers returns the package name of the route files in dir,
+// the handlers tests can be generated for, and the names of those they
+// can't.
+func scanTestedHandlers(fsys genfs.WriteFS, dir string) (string, []testedHandler, []string, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var (
+		pkg      string
+		handlers []testedHandler
+		skipped  []string
+		structs  = make(map[string]*ast.StructType)
+	)
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		src, err := fsys.ReadFile(path)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		pkg = file.Name.Name
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						if st, ok := ts.Type.(*ast.StructType); ok {
+							structs[ts.Name.Name] = st
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil || !scanner.IsRouteFile(e.Name()) {
+					continue
+				}
+				if _, ok := scanner.HandlerMethod(d.Name.Name); !ok {
+					continue
+				}
+				kind, ok := scanner.HandlerSignature(d)
+				if !ok {
+					continue
+				}
+				switch kind {
+				case scanner.HandlerInjected:
+					skipped = append(skipped, d.Name.Name)
+				case scanner.HandlerBody:
+					handlers = append(handlers, testedHandler{Name: d.Name.Name, Body: scanner.BodyType(d), Context: scanner.TakesContext(d)})
+				default:
+					handlers = append(handlers, testedHandler{Name: d.Name.Name, Context: scanner.TakesContext(d)})
+				}
+			}
+		}
+	}
+
+	for i, h := range handlers {
+		if h.Body != "" {
+			handlers[i].Seed = exampleJSON(structs[h.Body])
+		}
+	}
+	order := []string{"Get", "Head", "Post", "Put", "Patch", "Delete", "Options"}
+	sort.Slice(handlers, func(i, j int) bool {
+		return slices.Index(order, handlers[i].Name) < slices.Index(order, handlers[j].Name)
+	})
+	return pkg, handlers, skipped, nil
+}
+
+// examplePath returns a request path matching pattern, with example values
+// for its parameters.
+func examplePath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		_, catchAll := scanner.CatchAllName(seg)
+		switch {
+		case catchAll:
+			segments[i] = "a/b"
+		case strings.HasPrefix(seg, "{"):
+			segments[i] = "1"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// exampleJSON returns a JSON object with an example value for each field of
+// st, by JSON name. Fields of types without an obvious example are left
+// out.
+func exampleJSON(st *ast.StructType) string {
+	if st == nil {
+		return "{}"
+	}
+	var fields []string
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			continue
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			s, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+		value := exampleValue(field.Type, tag)
+		if value == "" {
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			key := name.Name
+			if jsonName, _, _ := strings.Cut(tag.Get("json"), ","); jsonName == "-" {
+				continue
+			} else if jsonName != "" {
+				key = jsonName
+			}
+			fields = append(fields, strconv.Quote(key)+": "+value)
+		}
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// exampleValue returns an example JSON value of a field's type, or "".
+func exampleValue(expr ast.Expr, tag reflect.StructTag) string {
+	validate := tag.Get("validate")
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return exampleValue(t.X, tag)
+	case *ast.ArrayType:
+		return "[]"
+	case *ast.MapType:
+		return "{}"
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" {
+			return `"2026-01-02T15:04:05Z"`
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			switch {
+			case strings.Contains(validate, "email"):
+				return `"user@example.com"`
+			case strings.Contains(validate, "url"):
+				return `"https://example.com"`
+			}
+			return `"example"`
+		case "bool":
+			return "true"
+		case "float32", "float64":
+			return "1.5"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64":
+			return "1"
+		}
+	}
+	return ""
+}
+
+// testsTemplate is the route_test.go written by GenerateTests.
+var testsTemplate = `package {{.Package}}
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// Tests of {{.Pattern}}, generated by nexo generate tests.
+// Add cases to the tables, then record their responses in testdata/ with:
+//
+//	go test ./{{.TestDir}} -update
+//
+// Handlers are served by an app without the route's middleware, so set
+// what it would (like the principal) in the handler under test's cases.
+
+var update = flag.Bool("update", false, "rewrite the golden responses in testdata/")
+{{range .Handlers}}
+func Test{{.Name}}(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "ok", path: "{{$.Path}}"{{if .Body}}, body: ` + "`{{.Seed}}`" + `{{end}}},
+{{- if .Body}}
+		{name: "invalid json", path: "{{$.Path}}", body: "{"},
+{{- end}}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve({{.Register}}, http.Method{{.Name}}, tt.path, tt.body)
+			golden(t, "{{.Name}}_"+strings.ReplaceAll(tt.name, " ", "_"), w)
+		})
+	}
+}
+{{if .Body}}
+// Fuzz{{.Name}} checks that no body makes {{.Name}} panic or respond with a
+// server error.
+func Fuzz{{.Name}}(f *testing.F) {
+	f.Add(` + "`{{.Seed}}`" + `)
+	f.Add("{}")
+	f.Add("null")
+	f.Add("[]")
+	f.Fuzz(func(t *testing.T, body string) {
+		w := serve({{.Register}}, http.Method{{.Name}}, "{{$.Path}}", body)
+		if w.Code >= http.StatusInternalServerError {
+			t.Errorf("body %q: status %d: %s", body, w.Code, w.Body)
+		}
+	})
+}
+{{end}}{{end}}
+// serve registers handler at {{.Pattern}} in a new app and returns its
+// response to a request.
+func serve(handler nexo.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+	app := nexo.New(nexo.WithMode(nexo.ModeTest))
+	app.RegisterRoute(method, "{{.Pattern}}", handler)
+	app.Mount()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+// golden compares the status and body of w with testdata/<name>.golden,
+// or rewrites the file with -update. testdata/ is found next to this file,
+// also when the tests run in the route's wrapper package.
+func golden(t *testing.T, name string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	got := []byte(fmt.Sprintf("%d %s\n\n%s", w.Code, http.StatusText(w.Code), w.Body))
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "testdata")
+	path := filepath.Join(dir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (record it with go test -update)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("response differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+`
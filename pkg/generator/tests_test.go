@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTests(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	dir := filepath.Join(appDir, "api", "users", "[id]")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	route := `package id
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+type PutBody struct {
+	Name    string   ` + "`json:\"name\"`" + `
+	Email   string   ` + "`json:\"email\" validate:\"required,email\"`" + `
+	Age     int
+	Tags    []string ` + "`json:\"tags,omitempty\"`" + `
+	Secret  string   ` + "`json:\"-\"`" + `
+	private string
+}
+
+func Get(c *nexo.Context) error { return nil }
+
+func Put(c *nexo.Context, body PutBody) error { return nil }
+`
+	deleteFile := `package id
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Delete(c *nexo.Context, db *DB) error { return nil }
+`
+	for name, content := range map[string]string{"route.go": route, "delete.go": deleteFile} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := GenerateTests(TestsConfig{Path: "users/[id]", AppDir: appDir})
+	if err != nil {
+		t.Fatalf("GenerateTests() error = %v", err)
+	}
+	if result.Pattern != "/api/users/{id}" || !slices.Equal(result.Skipped, []string{"Delete"}) {
+		t.Errorf("result = %+v", result)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "route_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package id",
+		"func TestGet(t *testing.T)",
+		`serve(Get, http.MethodGet, tt.path, tt.body)`,
+		"func TestPut(t *testing.T)",
+		"func FuzzPut(f *testing.F)",
+		`serve(nexo.WithBody(Put), http.MethodPut, "/api/users/1", body)`,
+		"f.Add(`{\"name\": \"example\", \"email\": \"user@example.com\", \"Age\": 1, \"tags\": []}`)",
+		`app.RegisterRoute(method, "/api/users/{id}", handler)`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("route_test.go missing %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "TestDelete") || strings.Contains(string(data), "FuzzGet") {
+		t.Errorf("route_test.go tests the wrong handlers:\n%s", data)
+	}
+
+	if _, err := GenerateTests(TestsConfig{Path: "users/[id]", AppDir: appDir}); err == nil {
+		t.Error("GenerateTests() overwrote route_test.go")
+	}
+	if _, err := GenerateTests(TestsConfig{Path: "missing", AppDir: appDir}); err == nil {
+		t.Error("GenerateTests(missing) succeeded")
+	}
+}
+
+func TestExamplePath(t *testing.T) {
+	tests := map[string]string{
+		"/api/users":         "/api/users",
+		"/api/users/{id}":    "/api/users/1",
+		"/orgs/{org}/docs/*": "/orgs/1/docs/a/b",
+		"/docs/{slug...}":    "/docs/a/b",
+	}
+	for pattern, want := range tests {
+		if got := examplePath(pattern); got != want {
+			t.Errorf("examplePath(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
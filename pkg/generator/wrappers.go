@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+)
+
+// GeneratedDir is the directory, relative to the project root, holding the
+// wrapper packages the generator writes for directories Go cannot import,
+// like app/posts/[slug] or app/(admin)/settings. Generated code imports
+// .nexo/generated/wrappers/app_posts_slug instead of the directory itself.
+//
+// A wrapper package is a generated copy of the directory's package. Its Go
+// files carry //line directives, so compile errors, stack traces and
+// coverage point at the original files. Wrappers are rewritten on every
+// generation and never need cleaning up. The directory's tests are copied
+// too, so go test runs them through the wrapper.
+const GeneratedDir = ".nexo/generated/wrappers"
+
+// legacyImportsDir held the symlinks older versions made for bracket
+// directories. It is removed when wrappers are synced.
+const legacyImportsDir = ".nexo/imports"
+
+// needsWrapper reports whether the directory relDir has characters Go
+// rejects in import paths, such as brackets and parentheses.
+func needsWrapper(relDir string) bool {
+	for _, r := range filepath.ToSlash(relDir) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("/-._~+", r):
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// wrapperName is the name of relDir's wrapper package in GeneratedDir: its
+// path with brackets, parentheses, slot and intercepting markers removed and
+// separators replaced, e.g. app/posts/[...slug] becomes app_posts_slug,
+// app/users/[id:int] becomes app_users_id and app/feed/@modal/(..)photos
+// becomes app_feed_modal_photos.
+func wrapperName(relDir string) string {
+	elems := strings.Split(filepath.ToSlash(filepath.Clean(relDir)), "/")
+	for i, elem := range elems {
+		elem = interceptMarkerRe.ReplaceAllString(elem, "")
+		elem = strings.TrimPrefix(elem, "@")
+		if matches := dynamicSegmentRe.FindStringSubmatch(elem); len(matches) > 1 {
+			elem = matches[1]
+		}
+		elem = strings.Trim(elem, "[]()")
+		elem = strings.TrimPrefix(elem, "...")
+		elems[i] = strings.Map(func(r rune) rune {
+			if needsWrapper(string(r)) || r == '.' {
+				return '_'
+			}
+			return r
+		}, elem)
+	}
+	return strings.Join(elems, "_")
+}
+
+// WrapperDirs returns the directories under appDir, relative to the
+// current directory, that hold Go or templ files and need a wrapper
+// package.
+func WrapperDirs(appDir string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(appDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(".", path)
+		if err != nil || !needsWrapper(rel) {
+			return err
+		}
+		// Not filepath.Glob: brackets in path are glob syntax
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".go" || ext == ".templ") {
+				dirs = append(dirs, rel)
+				break
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return dirs, err
+}
+
+// SyncWrappers makes GeneratedDir in root hold a wrapper package for each
+// of dirs, relative to root, and removes the wrappers of directories that
+// no longer need one. Files are only written when their content changed,
+// so file watchers and the build cache are not disturbed.
+func SyncWrappers(root string, dirs []string) error {
+	if err := os.RemoveAll(filepath.Join(root, legacyImportsDir)); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", legacyImportsDir, err)
+	}
+
+	generatedDir := filepath.Join(root, GeneratedDir)
+	want := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		name := wrapperName(dir)
+		if other, ok := want[name]; ok && other != dir {
+			return fmt.Errorf("%s and %s both map to %s/%s: rename one of them", other, dir, GeneratedDir, name)
+		}
+		want[name] = dir
+	}
+
+	entries, err := os.ReadDir(generatedDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, e := range entries {
+		if _, ok := want[e.Name()]; !ok {
+			if err := os.RemoveAll(filepath.Join(generatedDir, e.Name())); err != nil {
+				return fmt.Errorf("failed to remove stale wrapper: %w", err)
+			}
+		}
+	}
+
+	for name, dir := range want {
+		if err := writeWrapper(root, filepath.Join(generatedDir, name), dir); err != nil {
+			return fmt.Errorf("failed to generate wrapper for %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// SyncAppWrappers syncs the wrapper packages of appDir in the current
+// directory.
+func SyncAppWrappers(appDir string) error {
+	dirs, err := WrapperDirs(appDir)
+	if err != nil {
+		return err
+	}
+	return SyncWrappers(".", dirs)
+}
+
+// writeWrapper writes the wrapper package dst for the directory dir,
+// relative to root. Go files, tests included, get a generated header and a
+// //line directive pointing back at their original. Other files are copied
+// as is, so go:embed patterns keep working. Files no longer in dir are
+// removed.
+func writeWrapper(root, dst, dir string) error {
+	src := filepath.Join(root, dir)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(name) == ".go" {
+			original, err := filepath.Rel(dst, filepath.Join(src, name))
+			if err != nil {
+				return err
+			}
+			header := fmt.Sprintf("// Code generated by nexo from %s. DO NOT EDIT.\n\n//line %s:1\n",
+				filepath.ToSlash(filepath.Join(dir, name)), filepath.ToSlash(original))
+			data = append([]byte(header), data...)
+		}
+		if err := writeIfChanged(genfs.Disk, filepath.Join(dst, name), data); err != nil {
+			return err
+		}
+		keep[name] = true
+	}
+
+	existing, err := os.ReadDir(dst)
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if !keep[e.Name()] {
+			if err := os.RemoveAll(filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PackageDir returns the directory go commands reach the package in dir
+// by: dir itself, or its wrapper package when Go can't import dir.
+func PackageDir(dir string) string {
+	if !needsWrapper(dir) {
+		return dir
+	}
+	return filepath.Join(GeneratedDir, wrapperName(dir))
+}
+
+// writeIfChanged writes data to name in fsys unless it already holds it.
+func writeIfChanged(fsys genfs.WriteFS, name string, data []byte) error {
+	if old, err := fsys.ReadFile(name); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	return fsys.WriteFile(name, data, 0644)
+}
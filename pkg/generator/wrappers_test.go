@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNeedsWrapper(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"app/api/users", false},
+		{"app/my-page_v2.0", false},
+		{"app/api/users/[id]", true},
+		{"app/api/users/[id]/posts", true},
+		{"app/(marketing)/about", true},
+		{"app/docs/[[...slug]]", true},
+		{"app/dashboard/@team", true},
+	}
+	for _, tt := range tests {
+		if got := needsWrapper(tt.dir); got != tt.want {
+			t.Errorf("needsWrapper(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestWrapperName(t *testing.T) {
+	tests := map[string]string{
+		"app/posts/[slug]":            "app_posts_slug",
+		"app/users/[id]/posts/[post]": "app_users_id_posts_post",
+		"app/docs/[...path]":          "app_docs_path",
+		"app/shop/[[...filters]]":     "app_shop_filters",
+		"app/(marketing)/about":       "app_marketing_about",
+		"app/feed/@modal/(..)photos":  "app_feed_modal_photos",
+		"app/(.)[id]":                 "app_id",
+		"app/users/[id:int]":          "app_users_id",
+		"app/tags/[slug:re(\\w+)]":    "app_tags_slug",
+	}
+	for dir, want := range tests {
+		if got := wrapperName(dir); got != want {
+			t.Errorf("wrapperName(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+// writeBracketProject writes a module whose main package imports
+// app/posts/[slug] through its wrapper package.
+func writeBracketProject(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod":                        "module example.com/site\n\ngo 1.22\n",
+		"app/posts/[slug]/page.go":      "package slug\n\nconst Title = \"post\"\n",
+		"app/posts/[slug]/page_test.go": "package slug\n",
+		"app/about/page.go":             "package about\n",
+		"main.go": "package main\n\nimport slug \"example.com/site/" + GeneratedDir + "/app_posts_slug\"\n\n" +
+			"func main() { println(slug.Title) }\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWrapperDirs(t *testing.T) {
+	writeBracketProject(t)
+	if err := os.MkdirAll("app/(empty)", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs, err := WrapperDirs("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join("app", "posts", "[slug]")}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("WrapperDirs() = %v, want %v", dirs, want)
+	}
+
+	if dirs, err := WrapperDirs("missing"); err != nil || dirs != nil {
+		t.Errorf("WrapperDirs(missing) = %v, %v", dirs, err)
+	}
+}
+
+func TestSyncWrappers(t *testing.T) {
+	writeBracketProject(t)
+	stale := filepath.Join(GeneratedDir, "app_old_id")
+	legacy := filepath.Join(legacyImportsDir, "app_posts_slug")
+	for _, dir := range []string{stale, legacy} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := SyncAppWrappers("app"); err != nil {
+		t.Fatalf("SyncAppWrappers() error = %v", err)
+	}
+	for _, dir := range []string{stale, legacyImportsDir} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", dir)
+		}
+	}
+
+	wrapper := filepath.Join(GeneratedDir, "app_posts_slug")
+	data, err := os.ReadFile(filepath.Join(wrapper, "page.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// Code generated by nexo from app/posts/[slug]/page.go. DO NOT EDIT.",
+		"//line ../../../../app/posts/[slug]/page.go:1\npackage slug",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("wrapper page.go missing %q:\n%s", want, data)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(wrapper, "page_test.go")); err != nil ||
+		!strings.Contains(string(data), "//line ../../../../app/posts/[slug]/page_test.go:1\npackage slug") {
+		t.Errorf("wrapper page_test.go = %q, %v", data, err)
+	}
+
+	// Changes and removals reach the wrapper after the next sync
+	if err := os.WriteFile("app/posts/[slug]/page.go", []byte("package slug\n\nconst Title = \"edited\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wrapper, "gone.go"), []byte("package slug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncAppWrappers("app"); err != nil {
+		t.Fatalf("SyncAppWrappers() again error = %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(wrapper, "page.go"))
+	if err != nil || !strings.Contains(string(data), "edited") {
+		t.Errorf("wrapper page.go = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(wrapper, "gone.go")); !os.IsNotExist(err) {
+		t.Error("file missing from the source was not removed")
+	}
+
+	if testing.Short() {
+		return
+	}
+	out, err := exec.Command("go", "run", ".").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "edited" {
+		t.Errorf("go run = %q, %v", out, err)
+	}
+
+	// Compile errors point at the original file
+	if err := os.WriteFile("app/posts/[slug]/page.go", []byte("package slug\n\nconst Title = undefined\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncAppWrappers("app"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = exec.Command("go", "build", ".").CombinedOutput()
+	if !strings.Contains(string(out), "app/posts/[slug]/page.go:3") {
+		t.Errorf("go build error does not name the original file:\n%s", out)
+	}
+}
+
+func TestSyncWrappersCollision(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dirs := []string{"app/[id]", "app/(id)"}
+	err := SyncWrappers(".", dirs)
+	if err == nil || !strings.Contains(err.Error(), "both map to") {
+		t.Errorf("SyncWrappers() error = %v, want collision", err)
+	}
+}
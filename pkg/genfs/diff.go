@@ -0,0 +1,167 @@
+package genfs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changed ones.
+const diffContext = 3
+
+// maxDiffCells bounds the table of the line diff; larger files are shown
+// as replaced wholesale.
+const maxDiffCells = 25_000_000
+
+// Diff returns the change as a unified diff, like git diff shows it.
+func (c Change) Diff() string {
+	oldName, newName := "a/"+c.Path, "b/"+c.Path
+	switch c.Op {
+	case Create:
+		oldName = "/dev/null"
+	case Delete:
+		newName = "/dev/null"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	writeHunks(&b, splitLines(c.Old), splitLines(c.New))
+	return b.String()
+}
+
+// splitLines splits data into lines, keeping their newlines.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edit is a line of a diff: ' ' kept, '-' removed or '+' added.
+type edit struct {
+	op   byte
+	line string
+}
+
+// lineEdits returns the edits turning a into b, through their longest
+// common subsequence of lines.
+func lineEdits(a, b []string) []edit {
+	// Trim the common prefix and suffix, which is most of a regenerated file
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var edits []edit
+	for _, l := range a[:pre] {
+		edits = append(edits, edit{' ', l})
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if (len(ma)+1)*(len(mb)+1) > maxDiffCells {
+		for _, l := range ma {
+			edits = append(edits, edit{'-', l})
+		}
+		for _, l := range mb {
+			edits = append(edits, edit{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the length of the LCS of ma[i:] and mb[j:]
+		lcs := make([][]int32, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				edits = append(edits, edit{' ', ma[i]})
+				i++
+				j++
+			case j < len(mb) && (i == len(ma) || lcs[i][j+1] > lcs[i+1][j]):
+				edits = append(edits, edit{'+', mb[j]})
+				j++
+			default:
+				edits = append(edits, edit{'-', ma[i]})
+				i++
+			}
+		}
+	}
+	for _, l := range a[len(a)-suf:] {
+		edits = append(edits, edit{' ', l})
+	}
+	return edits
+}
+
+// writeHunks writes the hunks of the diff from a to b.
+func writeHunks(b *strings.Builder, a, bl []string) {
+	edits := lineEdits(a, bl)
+	for start := 0; start < len(edits); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			return
+		}
+		end := first
+		for i := first; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from, to := max(first-diffContext, start), min(end+diffContext, len(edits))
+
+		// Line numbers are 1-based, and those of an empty side 0
+		oldLine, newLine := 1, 1
+		for _, e := range edits[:from] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+		fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, e := range edits[from:to] {
+			b.WriteByte(e.op)
+			b.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+}
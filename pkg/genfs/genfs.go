@@ -0,0 +1,97 @@
+// Package genfs provides the filesystems Nexo's generators write through:
+// the disk, and an in-memory overlay of it that dry runs, tests and editor
+// tooling generate into to see what would change without touching disk.
+package genfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFS is a filesystem generators read and write. Names are operating
+// system paths, like those of package os, relative to the working directory
+// or absolute. Reads go through it too, so a generator sees its own writes.
+type WriteFS interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	MkdirAll(name string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Rename(oldname, newname string) error
+	RemoveAll(name string) error
+}
+
+// Disk is the WriteFS of the operating system's filesystem.
+var Disk WriteFS = diskFS{}
+
+type diskFS struct{}
+
+func (diskFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (diskFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (diskFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (diskFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+func (diskFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (diskFS) Rename(oldname, newname string) error { return os.Rename(oldname, newname) }
+func (diskFS) RemoveAll(name string) error          { return os.RemoveAll(name) }
+
+// Or returns fsys, or Disk when fsys is nil.
+func Or(fsys WriteFS) WriteFS {
+	if fsys == nil {
+		return Disk
+	}
+	return fsys
+}
+
+// Exists reports whether name exists in fsys.
+func Exists(fsys WriteFS, name string) bool {
+	_, err := fsys.Stat(name)
+	return err == nil
+}
+
+// WalkDir walks the tree at root in fsys like filepath.WalkDir, calling fn
+// for root and every file and directory below it in lexical order.
+func WalkDir(fsys WriteFS, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walkDir(fsys WriteFS, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, filepath.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		if err = fn(path, d, err); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, e := range entries {
+		if err := walkDir(fsys, filepath.Join(path, e.Name()), e, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
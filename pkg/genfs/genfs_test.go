@@ -0,0 +1,100 @@
+package genfs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMemFS(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for path, content := range map[string]string{
+		"app/api/users/route.go":      "package users\n",
+		"app/api/users/[id]/route.go": "package id\n",
+		"app/page.templ":              "package app\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewMemFS(Disk)
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(m.WriteFile(filepath.Join("app", "page.templ"), []byte("package app\n"), 0644))
+	must(m.WriteFile(filepath.Join("app", "api", "orders", "route.go"), []byte("package orders\n"), 0644))
+	must(m.Rename(filepath.Join("app", "api", "users"), filepath.Join("app", "api", "accounts")))
+	must(m.WriteFile(filepath.Join("app", "api", "accounts", "route.go"), []byte("package accounts\n"), 0644))
+
+	// Reads see the writes
+	if Exists(m, filepath.Join("app", "api", "users")) {
+		t.Error("app/api/users exists after the rename")
+	}
+	if got, err := m.ReadFile(filepath.Join("app", "api", "accounts", "[id]", "route.go")); err != nil || string(got) != "package id\n" {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+	entries, err := m.ReadDir(filepath.Join("app", "api"))
+	must(err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"accounts", "orders"}) {
+		t.Errorf("ReadDir() = %v", names)
+	}
+
+	// The disk is untouched
+	if _, err := os.Stat(filepath.Join("app", "api", "orders")); !os.IsNotExist(err) {
+		t.Error("app/api/orders was written to disk")
+	}
+
+	var got []string
+	for _, c := range m.Changes() {
+		got = append(got, string(c.Op)+" "+filepath.ToSlash(c.Path))
+	}
+	want := []string{
+		"create app/api/accounts/[id]/route.go",
+		"create app/api/accounts/route.go",
+		"create app/api/orders/route.go",
+		"delete app/api/users/[id]/route.go",
+		"delete app/api/users/route.go",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Changes() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestChange_Diff(t *testing.T) {
+	old := "package users\n\nimport \"net/http\"\n\nfunc Get() {}\n\nfunc Post() {}\n"
+	c := Change{
+		Op:   Update,
+		Path: "app/api/users/route.go",
+		Old:  []byte(old),
+		New:  []byte(strings.Replace(old, "func Post() {}\n", "func Put() {}\n", 1)),
+	}
+	want := "--- a/app/api/users/route.go\n" +
+		"+++ b/app/api/users/route.go\n" +
+		"@@ -4,4 +4,4 @@\n" +
+		" \n" +
+		" func Get() {}\n" +
+		" \n" +
+		"-func Post() {}\n" +
+		"+func Put() {}\n"
+	if got := c.Diff(); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+
+	created := Change{Op: Create, Path: "proxy.go", New: []byte("package app\n")}
+	if got := created.Diff(); got != "--- /dev/null\n+++ b/proxy.go\n@@ -0,0 +1,1 @@\n+package app\n" {
+		t.Errorf("Diff() of a created file = %q", got)
+	}
+}
@@ -0,0 +1,324 @@
+package genfs
+
+import (
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is a WriteFS keeping writes in memory, on top of a base filesystem
+// it reads through to and never modifies. Changes lists what the writes
+// would do to the base.
+type MemFS struct {
+	base WriteFS
+
+	mu      sync.Mutex
+	files   map[string][]byte // written files
+	dirs    map[string]bool   // created directories
+	removed map[string]bool   // files and directories removed from base
+}
+
+// NewMemFS returns an in-memory filesystem over base. A nil base starts
+// empty.
+func NewMemFS(base WriteFS) *MemFS {
+	return &MemFS{
+		base:    base,
+		files:   make(map[string][]byte),
+		dirs:    make(map[string]bool),
+		removed: make(map[string]bool),
+	}
+}
+
+// Stat returns the FileInfo of name.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stat(filepath.Clean(name))
+}
+
+func (m *MemFS) stat(name string) (fs.FileInfo, error) {
+	if data, ok := m.files[name]; ok {
+		return memInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	if m.dirs[name] {
+		return memInfo{name: filepath.Base(name), dir: true}, nil
+	}
+	if m.base == nil || m.removed[name] {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.base.Stat(name)
+}
+
+// ReadFile returns the content of name.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if data, ok := m.files[name]; ok {
+		return slices.Clone(data), nil
+	}
+	if m.base == nil || m.removed[name] || m.dirs[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.base.ReadFile(name)
+}
+
+// ReadDir returns the entries of the directory name, sorted by name.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	info, err := m.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries := make(map[string]fs.DirEntry)
+	if m.base != nil {
+		base, _ := m.base.ReadDir(name)
+		for _, e := range base {
+			if !m.removed[filepath.Join(name, e.Name())] {
+				entries[e.Name()] = e
+			}
+		}
+	}
+	for path, data := range m.files {
+		if filepath.Dir(path) == name {
+			entries[filepath.Base(path)] = fs.FileInfoToDirEntry(memInfo{name: filepath.Base(path), size: int64(len(data))})
+		}
+	}
+	for path := range m.dirs {
+		if filepath.Dir(path) == name && path != name {
+			entries[filepath.Base(path)] = fs.FileInfoToDirEntry(memInfo{name: filepath.Base(path), dir: true})
+		}
+	}
+
+	list := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	slices.SortFunc(list, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return list, nil
+}
+
+// MkdirAll creates the directory name and its missing parents.
+func (m *MemFS) MkdirAll(name string, _ fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(name))
+}
+
+func (m *MemFS) mkdirAll(name string) error {
+	for dir := name; ; dir = filepath.Dir(dir) {
+		if info, err := m.stat(dir); err == nil {
+			if !info.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+			}
+			break
+		}
+		m.dirs[dir] = true
+		delete(m.removed, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return nil
+}
+
+// WriteFile writes data to name, creating its parent directories.
+func (m *MemFS) WriteFile(name string, data []byte, _ fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if info, err := m.stat(name); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := m.mkdirAll(filepath.Dir(name)); err != nil {
+		return err
+	}
+	m.files[name] = slices.Clone(data)
+	delete(m.removed, name)
+	return nil
+}
+
+// Rename moves the file or directory tree oldname to newname.
+func (m *MemFS) Rename(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+	info, err := m.stat(oldname)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	if _, err := m.stat(newname); err == nil {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+	}
+
+	// Collect the tree at oldname from memory and the base
+	below := func(path string) bool {
+		return path == oldname || strings.HasPrefix(path, oldname+string(filepath.Separator))
+	}
+	tree := make(map[string][]byte)
+	var dirs []string
+	if info.IsDir() {
+		if m.base != nil {
+			_ = WalkDir(m.base, oldname, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || m.removed[path] {
+					return nil
+				}
+				if d.IsDir() {
+					dirs = append(dirs, path)
+				} else if data, err := m.base.ReadFile(path); err == nil {
+					tree[path] = data
+				}
+				return nil
+			})
+		}
+		for path := range m.dirs {
+			if below(path) {
+				dirs = append(dirs, path)
+			}
+		}
+	}
+	for path, data := range m.files {
+		if below(path) {
+			tree[path] = data
+		}
+	}
+	if !info.IsDir() && len(tree) == 0 {
+		data, err := m.base.ReadFile(oldname)
+		if err != nil {
+			return err
+		}
+		tree[oldname] = data
+	}
+
+	m.removeAll(oldname)
+	if err := m.mkdirAll(filepath.Dir(newname)); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		m.dirs[moved(dir, oldname, newname)] = true
+	}
+	for path, data := range tree {
+		m.files[moved(path, oldname, newname)] = data
+	}
+	return nil
+}
+
+// moved returns path, which is oldname or below it, under newname.
+func moved(path, oldname, newname string) string {
+	return newname + strings.TrimPrefix(path, oldname)
+}
+
+// RemoveAll removes name and, for a directory, everything below it.
+func (m *MemFS) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeAll(filepath.Clean(name))
+	return nil
+}
+
+func (m *MemFS) removeAll(name string) {
+	below := func(path string) bool {
+		return path == name || strings.HasPrefix(path, name+string(filepath.Separator))
+	}
+	for path := range m.files {
+		if below(path) {
+			delete(m.files, path)
+		}
+	}
+	for path := range m.dirs {
+		if below(path) {
+			delete(m.dirs, path)
+		}
+	}
+	if m.base == nil {
+		return
+	}
+	// Everything of the base below name is hidden one by one, so a file
+	// written there later doesn't bring its siblings back
+	_ = WalkDir(m.base, name, func(path string, _ fs.DirEntry, err error) error {
+		if err == nil {
+			m.removed[path] = true
+		}
+		return nil
+	})
+}
+
+// memInfo is the FileInfo of a file or directory held in memory.
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string { return i.name }
+func (i memInfo) Size() int64  { return i.size }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+// Op is the kind of change a write makes to the base filesystem.
+type Op string
+
+const (
+	Create Op = "create"
+	Update Op = "update"
+	Delete Op = "delete"
+)
+
+// Change is a file the writes to a MemFS create, update or delete in its
+// base.
+type Change struct {
+	Op   Op     `json:"op"`
+	Path string `json:"path"`
+	Old  []byte `json:"-"` // content in the base, nil for Create
+	New  []byte `json:"-"` // content written, nil for Delete
+}
+
+// Changes returns the files the writes so far would change in the base,
+// sorted by path. Files rewritten with the content they already have don't
+// count.
+func (m *MemFS) Changes() []Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var changes []Change
+	for path, data := range m.files {
+		var old []byte
+		err := fs.ErrNotExist
+		if m.base != nil {
+			old, err = m.base.ReadFile(path)
+		}
+		switch {
+		case err != nil:
+			changes = append(changes, Change{Op: Create, Path: path, New: data})
+		case !bytes.Equal(old, data):
+			changes = append(changes, Change{Op: Update, Path: path, Old: old, New: data})
+		}
+	}
+	for path := range m.removed {
+		if _, written := m.files[path]; written {
+			continue
+		}
+		if info, err := m.base.Stat(path); err == nil && !info.IsDir() {
+			old, _ := m.base.ReadFile(path)
+			changes = append(changes, Change{Op: Delete, Path: path, Old: old})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	return changes
+}
@@ -0,0 +1,258 @@
+// Package gosource formats the Go files Nexo generates, the way gofmt and
+// goimports would, so templates don't have to render perfectly formatted
+// code.
+package gosource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// knownImports are the packages Format adds when a file uses them without
+// importing them: the standard library packages and dependencies generated
+// code refers to.
+var knownImports = map[string]string{
+	"bytes":   "bytes",
+	"chi":     "github.com/go-chi/chi/v5",
+	"context": "context",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"http":    "net/http",
+	"io":      "io",
+	"json":    "encoding/json",
+	"log":     "log",
+	"nexo":    "github.com/abdul-hamid-achik/nexo/pkg/nexo",
+	"os":      "os",
+	"slog":    "log/slog",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+	"templ":   "github.com/a-h/templ",
+	"time":    "time",
+}
+
+// SyntaxError reports generated source that isn't valid Go.
+type SyntaxError struct {
+	// Name is the generated file.
+	Name string
+
+	// Src is the source that failed to parse.
+	Src []byte
+
+	// Err is the parser's error, usually a scanner.ErrorList.
+	Err error
+}
+
+// Error lists the parse errors and the generated lines around the first.
+func (e *SyntaxError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: generated code is not valid Go:", e.Name)
+
+	var list scanner.ErrorList
+	if !errors.As(e.Err, &list) || len(list) == 0 {
+		fmt.Fprintf(&b, " %v", e.Err)
+		return b.String()
+	}
+	for i, err := range list {
+		if i == 10 {
+			fmt.Fprintf(&b, "\n  (and %d more errors)", len(list)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %d:%d: %s", err.Pos.Line, err.Pos.Column, err.Msg)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(e.Src), "\n"), "\n")
+	errLine := list[0].Pos.Line
+	b.WriteString("\n")
+	for n := max(errLine-3, 1); n <= min(errLine+3, len(lines)); n++ {
+		marker := " "
+		if n == errLine {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "\n  %s %4d | %s", marker, n, lines[n-1])
+	}
+	return b.String()
+}
+
+// Unwrap returns the parser's error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Format returns src, the generated Go file name, formatted by gofmt, with
+// the imports it doesn't use removed and those of knownImports it uses but
+// doesn't import added. Source that doesn't parse returns a *SyntaxError.
+func Format(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, &SyntaxError{Name: name, Src: src, Err: err}
+	}
+
+	src = fixImports(fset, file, src)
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, &SyntaxError{Name: name, Src: src, Err: err}
+	}
+	return out, nil
+}
+
+// fixImports returns src with unused imports removed and missing known
+// imports added.
+func fixImports(fset *token.FileSet, file *ast.File, src []byte) []byte {
+	used := qualifiers(file)
+	imported := make(map[string]bool)
+	var unused []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		name := importName(spec)
+		imported[name] = true
+		if name != "_" && name != "." && !used[name] {
+			unused = append(unused, spec)
+		}
+	}
+
+	var missing []string
+	unknown := false
+	for name := range used {
+		if imported[name] {
+			continue
+		}
+		if p, ok := knownImports[name]; ok {
+			missing = append(missing, strconv.Quote(p))
+		} else {
+			unknown = true
+		}
+	}
+	slices.Sort(missing)
+
+	// A qualifier no import accounts for may come from an import whose
+	// name differs from its path, which must not be mistaken for unused
+	if unknown {
+		unused = nil
+	}
+	if len(unused) == 0 && len(missing) == 0 {
+		return src
+	}
+
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+
+	// The missing imports go in the first block that is kept
+	var block *ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var remove []ast.Spec
+		for _, spec := range gen.Specs {
+			if slices.Contains(unused, spec.(*ast.ImportSpec)) {
+				remove = append(remove, spec)
+			}
+		}
+		if len(remove) == len(gen.Specs) {
+			edits = append(edits, edit{start: offset(gen.Pos()), end: lineEnd(src, offset(gen.End()))})
+			continue
+		}
+		for _, spec := range remove {
+			edits = append(edits, edit{start: lineStart(src, offset(spec.Pos())), end: lineEnd(src, offset(spec.End()))})
+		}
+		if block == nil && gen.Lparen.IsValid() {
+			block = gen
+		}
+	}
+
+	if len(missing) > 0 {
+		if block != nil {
+			at := offset(block.Rparen)
+			edits = append(edits, edit{start: at, end: at, text: "\t" + strings.Join(missing, "\n\t") + "\n"})
+		} else {
+			at := offset(file.Name.End())
+			edits = append(edits, edit{start: at, end: at, text: "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"})
+		}
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	slices.SortFunc(edits, func(a, b edit) int { return b.start - a.start })
+	out := slices.Clone(src)
+	for _, e := range edits {
+		out = slices.Concat(out[:e.start], []byte(e.text), out[e.end:])
+	}
+	return out
+}
+
+// qualifiers returns the identifiers file uses to qualify names, like fmt
+// in fmt.Sprintf, that aren't declared in the file itself.
+func qualifiers(file *ast.File) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+			used[id.Name] = true
+		}
+		return true
+	})
+	return used
+}
+
+// importName returns the name spec's package is referred to by: its
+// explicit name, or the last element of its path without a major version
+// suffix or go- prefix.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(p)
+	if isMajorVersion(name) {
+		name = path.Base(path.Dir(p))
+	}
+	if i := strings.LastIndex(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i] // gopkg.in/yaml.v3
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// isMajorVersion reports whether elem is like "v2".
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// lineStart returns the offset of the start of the line holding offset.
+func lineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line holding
+// offset.
+func lineEnd(src []byte, offset int) int {
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(src)
+}
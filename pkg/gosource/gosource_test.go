@@ -0,0 +1,84 @@
+package gosource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "formats",
+			src:  "package users\nfunc Get(c *nexo.Context) error {\nreturn   nil }\n",
+			want: "package users\n\nimport (\n\t\"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n)\n\nfunc Get(c *nexo.Context) error {\n\treturn nil\n}\n",
+		},
+		{
+			name: "removes unused imports",
+			src:  "package users\n\nimport (\n\t\"fmt\"\n\t\"time\"\n\n\t\"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n)\n\nvar _ = time.Second\n",
+			want: "package users\n\nimport (\n\t\"time\"\n)\n\nvar _ = time.Second\n",
+		},
+		{
+			name: "removes an unused single import",
+			src:  "package users\n\nimport \"fmt\"\n\nvar x = 1\n",
+			want: "package users\n\nvar x = 1\n",
+		},
+		{
+			name: "adds to the import block",
+			src:  "package users\n\nimport (\n\t\"time\"\n)\n\nvar _, _ = time.Second, http.StatusOK\n",
+			want: "package users\n\nimport (\n\t\"net/http\"\n\t\"time\"\n)\n\nvar _, _ = time.Second, http.StatusOK\n",
+		},
+		{
+			name: "keeps blank, dot and renamed imports in use",
+			src:  "package users\n\nimport (\n\t_ \"embed\"\n\tu \"example.com/shop/app/api/users\"\n)\n\nvar _ = u.Get\n",
+			want: "package users\n\nimport (\n\t_ \"embed\"\n\tu \"example.com/shop/app/api/users\"\n)\n\nvar _ = u.Get\n",
+		},
+		{
+			name: "versioned paths",
+			src:  "package app\n\nimport (\n\t\"github.com/go-chi/chi/v5\"\n\t\"gopkg.in/yaml.v3\"\n)\n\nvar _, _ = chi.NewRouter, yaml.Marshal\n",
+			want: "package app\n\nimport (\n\t\"github.com/go-chi/chi/v5\"\n\t\"gopkg.in/yaml.v3\"\n)\n\nvar _, _ = chi.NewRouter, yaml.Marshal\n",
+		},
+		{
+			// handlers may be the name of example.com/shop/app/api/users
+			name: "unknown qualifiers keep every import",
+			src:  "package app\n\nimport (\n\t\"fmt\"\n\t\"example.com/shop/app/api/users\"\n)\n\nvar _ = handlers.Get\n",
+			want: "package app\n\nimport (\n\t\"example.com/shop/app/api/users\"\n\t\"fmt\"\n)\n\nvar _ = handlers.Get\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format("route.go", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	src := "package users\n\nfunc Get() {\n\treturn c.JSON(200, nil\n}\n"
+	_, err := Format("route.go", []byte(src))
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Format() error = %v, want a *SyntaxError", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"route.go: generated code is not valid Go:",
+		"\n  4:24: missing ','",
+		"\n       3 | func Get() {",
+		"\n  >    4 | \treturn c.JSON(200, nil",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error = %q, want it to contain %q", msg, want)
+		}
+	}
+}
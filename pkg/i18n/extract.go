@@ -0,0 +1,147 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Message is a translatable string found by Extract.
+type Message struct {
+	Key  string `json:"key"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// translateCallRe matches T("key"), c.T("key") and i18n.T(ctx, "key").
+var translateCallRe = regexp.MustCompile(`\bT\(\s*(?:[A-Za-z_][A-Za-z0-9_.]*\s*,\s*)?("(?:[^"\\]|\\.)*")`)
+
+// skippedDirs are never scanned by Extract.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Extract scans the .templ and .go files in fsys for translation calls with a
+// string literal key and returns one Message per key, sorted by key. The
+// position is that of the first occurrence. Hidden directories,
+// node_modules, vendor and generated *_templ.go files are skipped.
+func Extract(fsys fs.FS) ([]Message, error) {
+	seen := make(map[string]Message)
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := path.Ext(name)
+		if (ext != ".templ" && ext != ".go") || strings.HasSuffix(name, "_templ.go") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		for _, m := range extractFile(p, data) {
+			if _, ok := seen[m.Key]; !ok {
+				seen[m.Key] = m
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(seen))
+	for _, m := range seen {
+		messages = append(messages, m)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Key < messages[j].Key
+	})
+	return messages, nil
+}
+
+// extractFile returns the translation keys used in a single file.
+func extractFile(name string, data []byte) []Message {
+	var messages []Message
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		for _, m := range translateCallRe.FindAllStringSubmatch(sc.Text(), -1) {
+			key, err := strconv.Unquote(m[1])
+			if err != nil || key == "" {
+				continue
+			}
+			messages = append(messages, Message{Key: key, File: name, Line: line})
+		}
+	}
+	return messages
+}
+
+// MissingKeys returns the extracted messages with no entry in catalog.
+func MissingKeys(catalog map[string]string, messages []Message) []Message {
+	var missing []Message
+	for _, m := range messages {
+		if _, ok := catalog[m.Key]; !ok {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// AddKeys adds the missing messages to a JSON catalog with an empty
+// translation and returns the updated file. Dotted keys are nested under
+// existing tables, so a nested catalog keeps its shape; anything else is
+// added as a flat key.
+func AddKeys(data []byte, missing []Message) ([]byte, error) {
+	raw := make(map[string]any)
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range missing {
+		insertKey(raw, m.Key)
+	}
+
+	out, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// insertKey adds key with an empty value, descending into existing tables
+// for each dotted prefix.
+func insertKey(table map[string]any, key string) {
+	for {
+		prefix, rest, ok := strings.Cut(key, ".")
+		if !ok {
+			break
+		}
+		sub, isTable := table[prefix].(map[string]any)
+		if !isTable {
+			break
+		}
+		table, key = sub, rest
+	}
+	if _, exists := table[key]; !exists {
+		table[key] = ""
+	}
+}
@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExtract(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/page.templ": {Data: []byte(`templ Page() {
+	<h1>{ i18n.T(ctx, "home.title") }</h1>
+	<p>{ i18n.T(ctx, "home.intro") } { i18n.T(ctx, "home.title") }</p>
+}
+`)},
+		"app/api/orders/route.go": {Data: []byte(`package orders
+
+func Post(c *nexo.Context) error {
+	return c.String(201, c.T("orders.created", 42))
+}
+`)},
+		"app/page_templ.go":        {Data: []byte(`templ_7745c5c3_Var := i18n.T(ctx, "generated.only")`)},
+		"node_modules/x/a.go":      {Data: []byte(`T("vendored")`)},
+		"app/api/users/route.go":   {Data: []byte(`c.T(key) // not a literal`)},
+		"app/dashboard/page.templ": {Data: []byte(`{ i18n.T(ctx, "dash.\"quoted\"") }`)},
+	}
+
+	messages, err := Extract(fsys)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	want := []Message{
+		{Key: `dash."quoted"`, File: "app/dashboard/page.templ", Line: 1},
+		{Key: "home.intro", File: "app/page.templ", Line: 3},
+		{Key: "home.title", File: "app/page.templ", Line: 2},
+		{Key: "orders.created", File: "app/api/orders/route.go", Line: 4},
+	}
+	if len(messages) != len(want) {
+		t.Fatalf("Extract() = %+v, want %+v", messages, want)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("messages[%d] = %+v, want %+v", i, messages[i], want[i])
+		}
+	}
+}
+
+func TestAddKeys(t *testing.T) {
+	data := []byte(`{"home": {"title": "Welcome"}, "bye": "Goodbye"}`)
+	missing := MissingKeys(map[string]string{"home.title": "Welcome", "bye": "Goodbye"}, []Message{
+		{Key: "home.title"},
+		{Key: "home.intro"},
+		{Key: "orders.created"},
+	})
+	if len(missing) != 2 {
+		t.Fatalf("MissingKeys() = %+v, want 2 keys", missing)
+	}
+
+	out, err := AddKeys(data, missing)
+	if err != nil {
+		t.Fatalf("AddKeys() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	home := got["home"].(map[string]any)
+	if home["title"] != "Welcome" || home["intro"] != "" {
+		t.Errorf("home = %v, want the existing title and an empty intro", home)
+	}
+	if v, ok := got["orders.created"]; !ok || v != "" {
+		t.Errorf("orders.created = %v, %v; want a flat empty key", v, ok)
+	}
+}
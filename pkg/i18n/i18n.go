@@ -0,0 +1,293 @@
+// Package i18n provides message catalogs, locale negotiation and
+// translation helpers for Nexo applications.
+//
+// Catalogs are JSON or TOML files named after their locale (en.json,
+// es-MX.toml). Nested tables are flattened into dotted keys:
+//
+//	{"home": {"title": "Welcome", "greeting": "Hello, %s!"}}
+//
+// is looked up as "home.title" and "home.greeting".
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Bundle holds the message catalogs of every supported locale.
+// It is safe for concurrent use.
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	catalogs      map[string]map[string]string // canonical locale -> key -> message
+}
+
+// NewBundle creates an empty Bundle. Messages missing from a locale fall
+// back to defaultLocale.
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: canonicalLocale(defaultLocale),
+		catalogs:      make(map[string]map[string]string),
+	}
+}
+
+// DefaultLocale returns the fallback locale.
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// AddMessages adds messages to a locale's catalog, replacing existing keys.
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	locale = canonicalLocale(locale)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	catalog, ok := b.catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		b.catalogs[locale] = catalog
+	}
+	for k, v := range messages {
+		catalog[k] = v
+	}
+}
+
+// LoadDir loads every catalog file in dir. See LoadFS.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir))
+}
+
+// LoadFS loads every *.json and *.toml catalog at the root of fsys, using the
+// file name as the locale. It works with embedded catalogs:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	sub, _ := fs.Sub(locales, "locales")
+//	err := bundle.LoadFS(sub)
+func (b *Bundle) LoadFS(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !IsCatalogFile(e.Name()) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return err
+		}
+		messages, err := ParseCatalog(e.Name(), data)
+		if err != nil {
+			return err
+		}
+		b.AddMessages(LocaleFromFile(e.Name()), messages)
+	}
+	return nil
+}
+
+// Locales returns the loaded locales, default locale first.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.catalogs))
+	for l := range b.catalogs {
+		if l != b.defaultLocale {
+			locales = append(locales, l)
+		}
+	}
+	sort.Strings(locales)
+	if _, ok := b.catalogs[b.defaultLocale]; ok {
+		locales = append([]string{b.defaultLocale}, locales...)
+	}
+	return locales
+}
+
+// HasLocale reports whether a catalog is loaded for locale.
+func (b *Bundle) HasLocale(locale string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.catalogs[canonicalLocale(locale)]
+	return ok
+}
+
+// T translates key into locale. Lookup falls back from a regional locale to
+// its base language (es-MX to es) and then to the default locale; a missing
+// key is returned as-is. Arguments are applied with fmt.Sprintf.
+func (b *Bundle) T(locale, key string, args ...any) string {
+	msg, ok := b.lookup(canonicalLocale(locale), key)
+	if !ok {
+		msg = key
+	}
+	return format(msg, args)
+}
+
+// format applies args to msg. Taking args as a slice keeps go vet from
+// treating the T functions as printf wrappers whose keys must be format
+// strings.
+func format(msg string, args []any) string {
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// lookup finds key in locale or one of its fallbacks. Empty messages count
+// as missing, so untranslated entries written by extract fall back.
+func (b *Bundle) lookup(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, l := range [...]string{locale, baseLanguage(locale), b.defaultLocale} {
+		if msg := b.catalogs[l][key]; msg != "" {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Localizer returns a Localizer that translates into locale.
+func (b *Bundle) Localizer(locale string) *Localizer {
+	return &Localizer{bundle: b, locale: canonicalLocale(locale)}
+}
+
+// Localizer translates messages into a single locale.
+type Localizer struct {
+	bundle *Bundle
+	locale string
+}
+
+// Locale returns the Localizer's locale.
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T translates key. See Bundle.T.
+func (l *Localizer) T(key string, args ...any) string {
+	return l.bundle.T(l.locale, key, args...)
+}
+
+// localizerKey is the context key for the request's Localizer.
+type localizerKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the Localizer carried by ctx, or nil.
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return l
+}
+
+// T translates key with the Localizer carried by ctx. Use it in templ
+// components:
+//
+//	<h1>{ i18n.T(ctx, "home.title") }</h1>
+//
+// Without a Localizer the key is returned as-is.
+func T(ctx context.Context, key string, args ...any) string {
+	if l := FromContext(ctx); l != nil {
+		return l.T(key, args...)
+	}
+	return format(key, args)
+}
+
+// Locale returns the locale of the Localizer carried by ctx, or "".
+func Locale(ctx context.Context) string {
+	if l := FromContext(ctx); l != nil {
+		return l.locale
+	}
+	return ""
+}
+
+// IsCatalogFile reports whether name is a JSON or TOML catalog file.
+func IsCatalogFile(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".json" || ext == ".toml"
+}
+
+// LocaleFromFile returns the locale of a catalog file (es-MX.toml -> es-MX).
+func LocaleFromFile(name string) string {
+	base := path.Base(name)
+	return canonicalLocale(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+// ParseCatalog decodes a JSON or TOML catalog, chosen by the file
+// extension, into flat dotted keys.
+func ParseCatalog(name string, data []byte) (map[string]string, error) {
+	var raw map[string]any
+	var err error
+	if path.Ext(name) == ".toml" {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("i18n: failed to parse %s: %w", name, err)
+	}
+
+	messages := make(map[string]string)
+	if err := flatten("", raw, messages); err != nil {
+		return nil, fmt.Errorf("i18n: %s: %w", name, err)
+	}
+	return messages, nil
+}
+
+// flatten copies the messages in raw into out with dotted keys.
+func flatten(prefix string, raw map[string]any, out map[string]string) error {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			out[key] = v
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q is a %T, want a string or table", key, v)
+		}
+	}
+	return nil
+}
+
+// canonicalLocale normalizes a locale tag: "es_mx" and "ES-mx" become "es-MX".
+func canonicalLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p) // region
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:]) // script
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// baseLanguage returns the language subtag of locale (es-MX -> es).
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
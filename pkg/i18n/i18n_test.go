@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle("en")
+	fsys := fstest.MapFS{
+		"en.json":    {Data: []byte(`{"home": {"title": "Welcome", "greeting": "Hello, %s!"}, "bye": "Goodbye"}`)},
+		"es.toml":    {Data: []byte("bye = \"Adiós\"\n\n[home]\ntitle = \"Bienvenido\"\n")},
+		"es-MX.json": {Data: []byte(`{"home": {"title": "Bienvenido, compa"}}`)},
+		"README.md":  {Data: []byte("not a catalog")},
+	}
+	if err := b.LoadFS(fsys); err != nil {
+		t.Fatalf("LoadFS() error = %v", err)
+	}
+	return b
+}
+
+func TestBundle_T(t *testing.T) {
+	b := newTestBundle(t)
+
+	tests := []struct {
+		locale string
+		key    string
+		args   []any
+		want   string
+	}{
+		{"en", "home.title", nil, "Welcome"},
+		{"es", "home.title", nil, "Bienvenido"},
+		{"es-MX", "home.title", nil, "Bienvenido, compa"},
+		{"es-MX", "bye", nil, "Adiós"},                       // falls back to es
+		{"es", "home.greeting", []any{"Ana"}, "Hello, Ana!"}, // falls back to en
+		{"fr", "bye", nil, "Goodbye"},                        // unknown locale uses the default
+		{"en", "missing.key", nil, "missing.key"},
+		{"es_mx", "home.title", nil, "Bienvenido, compa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.key, func(t *testing.T) {
+			if got := b.T(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle_EmptyMessageFallsBack(t *testing.T) {
+	b := NewBundle("en")
+	b.AddMessages("en", map[string]string{"title": "Welcome"})
+	b.AddMessages("es", map[string]string{"title": ""})
+
+	if got := b.T("es", "title"); got != "Welcome" {
+		t.Errorf("T() = %q, want the default locale's message", got)
+	}
+}
+
+func TestBundle_Locales(t *testing.T) {
+	got := newTestBundle(t).Locales()
+	want := []string{"en", "es", "es-MX"}
+	if len(got) != len(want) {
+		t.Fatalf("Locales() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Locales() = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	b := newTestBundle(t)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"es-MX,es;q=0.9,en;q=0.8", "es-MX"},
+		{"es-AR,en;q=0.5", "es"},
+		{"fr-CA,fr;q=0.9,es;q=0.5", "es"},
+		{"en;q=0.2,es;q=0.9", "es"},
+		{"de", "en"},
+		{"", "en"},
+		{"es;q=0,*", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := b.Match(tt.header); got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCatalog_Errors(t *testing.T) {
+	if _, err := ParseCatalog("en.json", []byte(`{"count": 3}`)); err == nil {
+		t.Error("expected an error for a non-string message")
+	}
+	if _, err := ParseCatalog("en.toml", []byte(`title = `)); err == nil {
+		t.Error("expected an error for invalid TOML")
+	}
+}
+
+func TestContextT(t *testing.T) {
+	b := newTestBundle(t)
+	ctx := NewContext(context.Background(), b.Localizer("es"))
+
+	if got := T(ctx, "home.title"); got != "Bienvenido" {
+		t.Errorf("T() = %q, want %q", got, "Bienvenido")
+	}
+	if got := Locale(ctx); got != "es" {
+		t.Errorf("Locale() = %q, want %q", got, "es")
+	}
+	if got := T(context.Background(), "home.title"); got != "home.title" {
+		t.Errorf("T() without a Localizer = %q, want the key", got)
+	}
+}
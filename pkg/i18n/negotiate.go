@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageRange is one entry of an Accept-Language header.
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, most preferred first. Tags with q=0 and the "*" wildcard are
+// dropped.
+func ParseAcceptLanguage(header string) []string {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(name) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{tag: canonicalLocale(tag), q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	tags := make([]string, len(ranges))
+	for i, r := range ranges {
+		tags[i] = r.tag
+	}
+	return tags
+}
+
+// Match returns the loaded locale that best fits an Accept-Language header,
+// or the default locale. For each preferred tag, in order, an exact match
+// wins, then the tag's base language (es-MX matches es), then any region of
+// the same language (es matches es-MX).
+func (b *Bundle) Match(acceptLanguage string) string {
+	locales := b.Locales()
+
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		if b.HasLocale(tag) {
+			return tag
+		}
+		base := baseLanguage(tag)
+		if b.HasLocale(base) {
+			return base
+		}
+		for _, l := range locales {
+			if baseLanguage(l) == base {
+				return l
+			}
+		}
+	}
+	return b.defaultLocale
+}
@@ -0,0 +1,528 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// converter is the built-in Markdown converter: the block and inline syntax
+// of CommonMark that content sites use, without tables or footnotes. Raw
+// HTML passes through, so templ-rendered snippets can be embedded.
+type converter struct {
+	highlighter Highlighter
+}
+
+// Convert renders src as HTML.
+func (c *converter) Convert(src []byte, w io.Writer) error {
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	r := &render{highlighter: c.highlighter, ids: make(map[string]int)}
+	if err := r.blocks(strings.Split(text, "\n"), false); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, r.b.String())
+	return err
+}
+
+var (
+	headingRe   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	ruleRe      = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	listItemRe  = regexp.MustCompile(`^( {0,3})([-*+]|(\d{1,9})[.)])([ \t]+|$)`)
+	setextH1Re  = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2Re  = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	htmlBlockRe = regexp.MustCompile(`^ {0,3}<(?:[A-Za-z][A-Za-z0-9-]*|/[A-Za-z]|!--)`)
+	inlineTagRe = regexp.MustCompile(`^</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`)
+	autolinkRe  = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+)
+
+// render holds the state of one conversion.
+type render struct {
+	b           strings.Builder
+	highlighter Highlighter
+	ids         map[string]int // heading ids in use
+}
+
+// blocks renders lines as block elements. Paragraphs of tight list items
+// are rendered without <p>.
+func (r *render) blocks(lines []string, tight bool) error {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fenceRe.MatchString(line):
+			n, err := r.fencedCode(lines[i:])
+			if err != nil {
+				return err
+			}
+			i += n
+
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			r.heading(len(m[1]), m[2])
+			i++
+
+		case ruleRe.MatchString(line):
+			r.b.WriteString("<hr>\n")
+			i++
+
+		case isBlockquote(line):
+			var quoted []string
+			for ; i < len(lines) && isBlockquote(lines[i]); i++ {
+				l := strings.TrimLeft(lines[i], " ")[1:]
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+			}
+			r.b.WriteString("<blockquote>\n")
+			if err := r.blocks(quoted, false); err != nil {
+				return err
+			}
+			r.b.WriteString("</blockquote>\n")
+
+		case listItemRe.MatchString(line):
+			n, err := r.list(lines[i:])
+			if err != nil {
+				return err
+			}
+			i += n
+
+		case htmlBlockRe.MatchString(line):
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				r.b.WriteString(lines[i] + "\n")
+			}
+
+		default:
+			i += r.paragraph(lines[i:], tight)
+		}
+	}
+	return nil
+}
+
+// isBlockquote reports whether line starts a blockquote line.
+func isBlockquote(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	return len(line)-len(trimmed) <= 3 && strings.HasPrefix(trimmed, ">")
+}
+
+// interrupts reports whether line starts a block that ends a paragraph.
+func interrupts(line string) bool {
+	return fenceRe.MatchString(line) || headingRe.MatchString(line) || ruleRe.MatchString(line) ||
+		isBlockquote(line) || listItemRe.MatchString(line) || htmlBlockRe.MatchString(line)
+}
+
+// paragraph renders a paragraph, or a setext heading, and returns the
+// number of lines it used.
+func (r *render) paragraph(lines []string, tight bool) int {
+	var text []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		if i > 0 && setextH1Re.MatchString(line) {
+			r.heading(1, strings.Join(text, "\n"))
+			return i + 1
+		}
+		if i > 0 && setextH2Re.MatchString(line) {
+			r.heading(2, strings.Join(text, "\n"))
+			return i + 1
+		}
+		if i > 0 && interrupts(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+
+	content := inline(strings.Join(text, "\n"))
+	if tight {
+		r.b.WriteString(content + "\n")
+	} else {
+		r.b.WriteString("<p>" + content + "</p>\n")
+	}
+	return i
+}
+
+// heading renders <hN id="slug">.
+func (r *render) heading(level int, text string) {
+	id := slugify(text)
+	if n := r.ids[id]; n > 0 {
+		r.ids[id]++
+		id = fmt.Sprintf("%s-%d", id, n)
+	} else {
+		r.ids[id] = 1
+	}
+	fmt.Fprintf(&r.b, "<h%d id=\"%s\">%s</h%d>\n", level, id, inline(text), level)
+}
+
+// slugify returns the anchor id of a heading: lower case letters and digits
+// joined by hyphens.
+func slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, ch := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(ch) || unicode.IsDigit(ch):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(ch)
+		case ch == ' ' || ch == '-' || ch == '_':
+			hyphen = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// fencedCode renders a fenced code block and returns the number of lines it
+// used. An unclosed fence runs to the end of the document.
+func (r *render) fencedCode(lines []string) (int, error) {
+	m := fenceRe.FindStringSubmatch(lines[0])
+	indent, fence, lang := len(m[1]), m[2], m[3]
+
+	var code strings.Builder
+	i := 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(trimmed) <= 3 && strings.HasPrefix(trimmed, fence) &&
+			strings.Trim(strings.TrimSpace(trimmed), fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code.WriteString(line + "\n")
+	}
+
+	return i, r.highlighter.Highlight(&r.b, code.String(), html.UnescapeString(lang))
+}
+
+// list renders a bullet or ordered list and returns the number of lines it
+// used. Item content indented past the marker belongs to the item, and a
+// blank line between items makes the list loose.
+func (r *render) list(lines []string) (int, error) {
+	first := listItemRe.FindStringSubmatch(lines[0])
+	ordered := first[3] != ""
+	marker := first[2][len(first[2])-1:]
+
+	type item struct{ lines []string }
+	var items []item
+	loose := false
+	i := 0
+	for i < len(lines) {
+		m := listItemRe.FindStringSubmatch(lines[i])
+		if m == nil || (m[3] != "") != ordered || m[2][len(m[2])-1:] != marker {
+			break
+		}
+		width := len(m[0])
+		if m[4] == "" {
+			width++ // empty item: content starts after one space
+		}
+		it := item{lines: []string{lines[i][len(m[0]):]}}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line continues the item when indented content follows
+				j := i
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j < len(lines) && indentOf(lines[j]) >= width {
+					it.lines = append(it.lines, "")
+					loose = true
+					i++
+					continue
+				}
+				break
+			}
+			if indentOf(line) >= width {
+				it.lines = append(it.lines, line[width:])
+				i++
+				continue
+			}
+			if listItemRe.MatchString(line) || interrupts(line) {
+				break
+			}
+			// Lazy continuation of the item's paragraph
+			it.lines = append(it.lines, strings.TrimLeft(line, " \t"))
+			i++
+		}
+		items = append(items, it)
+
+		// Blank lines between items
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		if j > i && j < len(lines) && listItemRe.MatchString(lines[j]) {
+			if m := listItemRe.FindStringSubmatch(lines[j]); (m[3] != "") == ordered && m[2][len(m[2])-1:] == marker {
+				loose = true
+				i = j
+			}
+		}
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if start := strings.TrimLeft(first[3], "0"); start != "1" {
+			if start == "" {
+				start = "0"
+			}
+			r.b.WriteString(`<ol start="` + start + `">` + "\n")
+		} else {
+			r.b.WriteString("<ol>\n")
+		}
+	} else {
+		r.b.WriteString("<ul>\n")
+	}
+	for _, it := range items {
+		r.b.WriteString("<li>")
+		if loose {
+			r.b.WriteString("\n")
+		}
+		if err := r.blocks(it.lines, !loose); err != nil {
+			return 0, err
+		}
+		r.b.WriteString("</li>\n")
+	}
+	r.b.WriteString("</" + tag + ">\n")
+	return i, nil
+}
+
+// indentOf returns the number of leading spaces of line, counting tabs as 4.
+func indentOf(line string) int {
+	n := 0
+	for _, ch := range line {
+		switch ch {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// inline renders the inline syntax of text: code spans, emphasis, links,
+// images, autolinks, raw tags, escapes and hard line breaks.
+func inline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		ch := text[i]
+		switch {
+		case ch == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			b.WriteString("<br>\n")
+			i += 2
+			continue
+
+		case ch == '\\' && i+1 < len(text) && isPunct(text[i+1]):
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case ch == '`':
+			if n, ok := codeSpan(&b, text[i:]); ok {
+				i += n
+				continue
+			}
+
+		case ch == '!' && strings.HasPrefix(text[i:], "!["):
+			if label, dest, title, n, ok := linkParts(text[i+1:]); ok {
+				b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				i += 1 + n
+				continue
+			}
+
+		case ch == '[':
+			if label, dest, title, n, ok := linkParts(text[i:]); ok {
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">" + inline(label) + "</a>")
+				i += n
+				continue
+			}
+
+		case ch == '<':
+			if m := autolinkRe.FindStringSubmatch(text[i:]); m != nil {
+				url := html.EscapeString(m[1])
+				b.WriteString(`<a href="` + url + `">` + html.EscapeString(strings.TrimPrefix(m[1], "mailto:")) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if tag := inlineTagRe.FindString(text[i:]); tag != "" {
+				b.WriteString(tag)
+				i += len(tag)
+				continue
+			}
+
+		case ch == '*' || ch == '_':
+			if n, ok := emphasis(&b, text, i); ok {
+				i += n
+				continue
+			}
+
+		case ch == '\n':
+			// Two trailing spaces make a hard break
+			out := b.String()
+			if strings.HasSuffix(out, "  ") {
+				trimmed := strings.TrimRight(out, " ")
+				b.Reset()
+				b.WriteString(trimmed + "<br>\n")
+				i++
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// isPunct reports whether ch is ASCII punctuation, which can be escaped.
+func isPunct(ch byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", ch) >= 0
+}
+
+// codeSpan renders a code span at the start of s, opened and closed by
+// backtick runs of the same length.
+func codeSpan(b *strings.Builder, s string) (int, bool) {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	fence := s[:n]
+	for j := n; j < len(s); {
+		k := strings.Index(s[j:], fence)
+		if k < 0 {
+			return 0, false
+		}
+		k += j
+		end := k + n
+		if end < len(s) && s[end] == '`' {
+			// Longer run: not the closing fence
+			for end < len(s) && s[end] == '`' {
+				end++
+			}
+			j = end
+			continue
+		}
+		code := strings.ReplaceAll(s[n:k], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+		return end, true
+	}
+	return 0, false
+}
+
+// linkParts parses [label](dest "title") at the start of s and returns its
+// parts and length.
+func linkParts(s string) (label, dest, title string, n int, ok bool) {
+	depth := 0
+	end := -1
+	for i := 0; i < len(s) && end < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return "", "", "", 0, false
+	}
+	close := strings.IndexByte(s[end+2:], ')')
+	if close < 0 {
+		return "", "", "", 0, false
+	}
+	inner := strings.TrimSpace(s[end+2 : end+2+close])
+	dest = inner
+	if sp := strings.IndexAny(inner, " \t"); sp >= 0 {
+		rest := strings.TrimSpace(inner[sp:])
+		if len(rest) >= 2 && (rest[0] == '"' || rest[0] == '\'') && rest[len(rest)-1] == rest[0] {
+			dest, title = inner[:sp], rest[1:len(rest)-1]
+		}
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	return s[1:end], dest, title, end + 3 + close, true
+}
+
+// plainText strips inline markup from text, for image alt text.
+func plainText(text string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "").Replace(text)
+}
+
+// emphasis renders *em*, **strong** or ***both*** starting at text[i], and
+// returns the number of bytes used. Underscores inside words are literal.
+func emphasis(b *strings.Builder, text string, i int) (int, bool) {
+	ch := text[i]
+	n := 0
+	for i+n < len(text) && text[i+n] == ch && n < 3 {
+		n++
+	}
+	if ch == '_' && i > 0 && isWordByte(text[i-1]) {
+		return 0, false
+	}
+	if i+n >= len(text) || text[i+n] == ' ' || text[i+n] == '\n' {
+		return 0, false
+	}
+
+	delim := text[i : i+n]
+	for j := i + n; j < len(text); {
+		k := strings.Index(text[j:], delim)
+		if k < 0 {
+			return 0, false
+		}
+		k += j
+		after := k + n
+		if text[k-1] != ' ' && text[k-1] != '\n' &&
+			(ch != '_' || after >= len(text) || !isWordByte(text[after])) &&
+			(after >= len(text) || text[after] != ch) {
+			inner := inline(text[i+n : k])
+			switch n {
+			case 1:
+				b.WriteString("<em>" + inner + "</em>")
+			case 2:
+				b.WriteString("<strong>" + inner + "</strong>")
+			default:
+				b.WriteString("<em><strong>" + inner + "</strong></em>")
+			}
+			return after - i, true
+		}
+		j = k + 1
+	}
+	return 0, false
+}
+
+// isWordByte reports whether ch is an ASCII letter or digit.
+func isWordByte(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,273 @@
+// Package markdown renders Markdown content files for Nexo applications.
+//
+// A content file starts with optional YAML front matter:
+//
+//	---
+//	title: Hello, World
+//	description: The first post.
+//	date: 2026-01-15
+//	tags: [news]
+//	---
+//
+//	# Hello
+//
+//	Welcome to the blog.
+//
+// Front matter fields other than the known ones are kept in Params. The body
+// is converted to HTML by a Pipeline, whose converter and code highlighter
+// can be replaced.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the metadata block at the top of a content file.
+type FrontMatter struct {
+	Title       string
+	Description string
+	Date        time.Time
+	Draft       bool
+	Tags        []string
+
+	// Params holds the remaining front matter fields.
+	Params map[string]any
+}
+
+// Document is a parsed content file.
+type Document struct {
+	FrontMatter
+
+	// Source is the Markdown body, without front matter.
+	Source []byte
+
+	// HTML is the rendered body.
+	HTML string
+}
+
+// Converter converts a Markdown body to HTML. Adapt a third-party renderer
+// such as goldmark with ConverterFunc:
+//
+//	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+//	p := markdown.New(markdown.WithConverter(markdown.ConverterFunc(
+//	    func(src []byte, w io.Writer) error { return md.Convert(src, w) },
+//	)))
+type Converter interface {
+	Convert(src []byte, w io.Writer) error
+}
+
+// ConverterFunc adapts a function to a Converter.
+type ConverterFunc func(src []byte, w io.Writer) error
+
+// Convert calls f.
+func (f ConverterFunc) Convert(src []byte, w io.Writer) error {
+	return f(src, w)
+}
+
+// Highlighter renders a fenced code block. lang is the block's info string
+// ("go" for ```go), or empty.
+type Highlighter interface {
+	Highlight(w io.Writer, code, lang string) error
+}
+
+// HighlighterFunc adapts a function to a Highlighter.
+type HighlighterFunc func(w io.Writer, code, lang string) error
+
+// Highlight calls f.
+func (f HighlighterFunc) Highlight(w io.Writer, code, lang string) error {
+	return f(w, code, lang)
+}
+
+// ClassHighlighter renders code blocks as <pre><code class="language-go">,
+// for client-side highlighters such as Prism or highlight.js. It is the
+// default Highlighter.
+var ClassHighlighter Highlighter = HighlighterFunc(func(w io.Writer, code, lang string) error {
+	class := ""
+	if lang != "" {
+		class = ` class="language-` + html.EscapeString(lang) + `"`
+	}
+	_, err := io.WriteString(w, "<pre><code"+class+">"+html.EscapeString(code)+"</code></pre>\n")
+	return err
+})
+
+// Pipeline parses content files and renders them to HTML. It is safe for
+// concurrent use.
+type Pipeline struct {
+	converter   Converter
+	highlighter Highlighter
+}
+
+// Option configures a Pipeline.
+type Option func(*Pipeline)
+
+// WithConverter replaces the built-in Markdown converter. Highlighting is
+// then left to the converter.
+func WithConverter(c Converter) Option {
+	return func(p *Pipeline) {
+		p.converter = c
+	}
+}
+
+// WithHighlighter sets how the built-in converter renders fenced code
+// blocks (default: ClassHighlighter).
+func WithHighlighter(h Highlighter) Option {
+	return func(p *Pipeline) {
+		p.highlighter = h
+	}
+}
+
+// New creates a Pipeline. Without options it uses the built-in converter,
+// which supports headings, paragraphs, emphasis, links, images, lists,
+// blockquotes, fenced code blocks, rules and raw HTML.
+func New(opts ...Option) *Pipeline {
+	p := &Pipeline{highlighter: ClassHighlighter}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.converter == nil {
+		p.converter = &converter{highlighter: p.highlighter}
+	}
+	return p
+}
+
+// Parse parses a content file: its front matter and HTML body.
+func (p *Pipeline) Parse(src []byte) (*Document, error) {
+	fm, body, err := ParseFrontMatter(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := p.converter.Convert(body, &buf); err != nil {
+		return nil, err
+	}
+	return &Document{FrontMatter: fm, Source: body, HTML: buf.String()}, nil
+}
+
+// ParseFile parses the content file name in fsys.
+func (p *Pipeline) ParseFile(fsys fs.FS, name string) (*Document, error) {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := p.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// frontMatterDelim opens and closes a YAML front matter block.
+var frontMatterDelim = []byte("---")
+
+// ParseFrontMatter splits src into its front matter and Markdown body. A
+// file without front matter has an empty FrontMatter.
+func ParseFrontMatter(src []byte) (FrontMatter, []byte, error) {
+	var fm FrontMatter
+
+	src = bytes.TrimPrefix(src, []byte("\ufeff")) // byte order mark
+	first, rest, _ := bytes.Cut(src, []byte("\n"))
+	if !bytes.Equal(bytes.TrimSpace(first), frontMatterDelim) {
+		return fm, src, nil
+	}
+
+	var block []byte
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelim) {
+			err := decodeFrontMatter(block, &fm)
+			return fm, rest, err
+		}
+		block = append(block, line...)
+		block = append(block, '\n')
+	}
+	return fm, nil, fmt.Errorf("front matter is not closed with ---")
+}
+
+// decodeFrontMatter decodes a YAML front matter block into fm.
+func decodeFrontMatter(block []byte, fm *FrontMatter) error {
+	var fields map[string]any
+	if err := yaml.Unmarshal(block, &fields); err != nil {
+		return fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	for key, v := range fields {
+		var ok bool
+		switch key {
+		case "title":
+			fm.Title, ok = v.(string)
+		case "description":
+			fm.Description, ok = v.(string)
+		case "draft":
+			fm.Draft, ok = v.(bool)
+		case "date":
+			fm.Date, ok = parseDate(v)
+		case "tags":
+			fm.Tags, ok = stringList(v)
+		default:
+			if fm.Params == nil {
+				fm.Params = make(map[string]any)
+			}
+			fm.Params[key], ok = v, true
+		}
+		if !ok {
+			return fmt.Errorf("invalid front matter: %s has the wrong type", key)
+		}
+	}
+	return nil
+}
+
+// parseDate accepts YAML timestamps and RFC 3339 or YYYY-MM-DD strings.
+func parseDate(v any) (time.Time, bool) {
+	switch d := v.(type) {
+	case time.Time:
+		return d, true
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, d); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// stringList accepts a YAML list of strings or a single string.
+func stringList(v any) ([]string, bool) {
+	switch l := v.(type) {
+	case string:
+		return []string{l}, true
+	case []any:
+		out := make([]string, 0, len(l))
+		for _, item := range l {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// ContentPattern returns the route pattern of a content file, given its
+// slash-separated path relative to the content directory: blog/hello.md is
+// served at /blog/hello, and index.md files at their directory.
+func ContentPattern(rel string) string {
+	rel = strings.TrimSuffix(rel, path.Ext(rel))
+	if rel == "index" {
+		return "/"
+	}
+	return "/" + strings.TrimSuffix(rel, "/index")
+}
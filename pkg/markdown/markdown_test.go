@@ -0,0 +1,172 @@
+package markdown
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "headings and paragraphs",
+			src:  "# Hello *World*\n\nFirst line\nsecond line.\n\nSetext\n---\n",
+			want: "<h1 id=\"hello-world\">Hello <em>World</em></h1>\n<p>First line\nsecond line.</p>\n<h2 id=\"setext\">Setext</h2>\n",
+		},
+		{
+			name: "duplicate heading ids",
+			src:  "## Intro\n\n## Intro\n",
+			want: "<h2 id=\"intro\">Intro</h2>\n<h2 id=\"intro-1\">Intro</h2>\n",
+		},
+		{
+			name: "inline",
+			src:  "**bold**, _em_, snake_case, `a < b`, [docs](/docs \"Docs\"), ![logo](/logo.png), <https://nexo.build>, a\\*b",
+			want: `<p><strong>bold</strong>, <em>em</em>, snake_case, <code>a &lt; b</code>, <a href="/docs" title="Docs">docs</a>, <img src="/logo.png" alt="logo">, <a href="https://nexo.build">https://nexo.build</a>, a*b</p>` + "\n",
+		},
+		{
+			name: "hard break",
+			src:  "one  \ntwo",
+			want: "<p>one<br>\ntwo</p>\n",
+		},
+		{
+			name: "fenced code",
+			src:  "```go\nfmt.Println(\"<hi>\")\n```\n",
+			want: "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>\n",
+		},
+		{
+			name: "tight list with nested list",
+			src:  "- one\n- two\n  - nested\n- three\n",
+			want: "<ul>\n<li>one\n</li>\n<li>two\n<ul>\n<li>nested\n</li>\n</ul>\n</li>\n<li>three\n</li>\n</ul>\n",
+		},
+		{
+			name: "loose ordered list",
+			src:  "3. one\n\n4. two\n",
+			want: "<ol start=\"3\">\n<li>\n<p>one</p>\n</li>\n<li>\n<p>two</p>\n</li>\n</ol>\n",
+		},
+		{
+			name: "blockquote and rule",
+			src:  "> quoted\n> text\n\n***\n",
+			want: "<blockquote>\n<p>quoted\ntext</p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "raw html",
+			src:  "<div class=\"note\">\n  Note\n</div>\n\nSee <kbd>Ctrl</kbd>.",
+			want: "<div class=\"note\">\n  Note\n</div>\n<p>See <kbd>Ctrl</kbd>.</p>\n",
+		},
+		{
+			name: "escaped text",
+			src:  "Tom & Jerry <3",
+			want: "<p>Tom &amp; Jerry &lt;3</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := New().Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.HTML != tt.want {
+				t.Errorf("HTML =\n%q\nwant\n%q", doc.HTML, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		want     FrontMatter
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "none",
+			src:      "# Hi\n",
+			wantBody: "# Hi\n",
+		},
+		{
+			name: "fields",
+			src:  "---\ntitle: Hello\ndescription: First post\ndate: 2026-01-15\ndraft: true\ntags: [news, go]\nauthor: ana\n---\n# Hi\n",
+			want: FrontMatter{
+				Title:       "Hello",
+				Description: "First post",
+				Date:        time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+				Draft:       true,
+				Tags:        []string{"news", "go"},
+				Params:      map[string]any{"author": "ana"},
+			},
+			wantBody: "# Hi\n",
+		},
+		{name: "unclosed", src: "---\ntitle: Hello\n", wantErr: true},
+		{name: "wrong type", src: "---\ndraft: maybe\n---\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter([]byte(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFrontMatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(fm, tt.want) {
+				t.Errorf("front matter = %+v, want %+v", fm, tt.want)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPipeline_Options(t *testing.T) {
+	fsys := fstest.MapFS{"post.md": {Data: []byte("---\ntitle: Post\n---\n```go\nx := 1\n```\n")}}
+
+	highlight := HighlighterFunc(func(w io.Writer, code, lang string) error {
+		_, err := io.WriteString(w, "<pre data-lang=\""+lang+"\">"+strings.TrimSpace(code)+"</pre>\n")
+		return err
+	})
+	doc, err := New(WithHighlighter(highlight)).ParseFile(fsys, "post.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Post" || doc.HTML != "<pre data-lang=\"go\">x := 1</pre>\n" {
+		t.Errorf("doc = %q %q", doc.Title, doc.HTML)
+	}
+
+	upper := ConverterFunc(func(src []byte, w io.Writer) error {
+		_, err := io.WriteString(w, strings.ToUpper(string(src)))
+		return err
+	})
+	doc, err = New(WithConverter(upper)).Parse([]byte("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.HTML != "HI" {
+		t.Errorf("HTML = %q, want HI", doc.HTML)
+	}
+}
+
+func TestContentPattern(t *testing.T) {
+	tests := map[string]string{
+		"index.md":          "/",
+		"about.md":          "/about",
+		"blog/index.md":     "/blog",
+		"blog/2026/post.md": "/blog/2026/post",
+	}
+	for rel, want := range tests {
+		if got := ContentPattern(rel); got != want {
+			t.Errorf("ContentPattern(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
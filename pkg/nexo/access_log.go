@@ -0,0 +1,330 @@
+package nexo
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ---------- Access Log ----------
+
+// Access log formats.
+const (
+	// AccessLogCommon is the Common Log Format:
+	//
+	//	127.0.0.1 - ada [10/Oct/2026:13:55:36 -0700] "GET /users HTTP/1.1" 200 2326
+	AccessLogCommon = "common"
+
+	// AccessLogCombined is the Combined Log Format, the Common Log Format
+	// with the referer and user agent:
+	//
+	//	127.0.0.1 - ada [10/Oct/2026:13:55:36 -0700] "GET /users HTTP/1.1" 200 2326 "https://example.com/" "Mozilla/5.0"
+	AccessLogCombined = "combined"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogConfig configures the access log (see WithAccessLog).
+type AccessLogConfig struct {
+	// Format is AccessLogCommon or AccessLogCombined (default).
+	Format string
+
+	// Output receives one line per request. Open a RotatingFile to rotate
+	// it by size or age; it's reopened on SIGHUP, so external tools like
+	// logrotate can move it too.
+	Output io.Writer
+}
+
+// accessLog writes the app's access log.
+type accessLog struct {
+	mu       sync.Mutex
+	out      io.Writer
+	combined bool
+}
+
+// WithAccessLog writes a line per request in the Common or Combined Log
+// Format, for log appliances that expect it. It's independent of the
+// request logger and includes requests the proxy answers.
+//
+// Example:
+//
+//	access, err := nexo.OpenRotatingFile("/var/log/app/access.log", nexo.RotateConfig{
+//	    MaxSize:    100 << 20,
+//	    MaxAge:     24 * time.Hour,
+//	    MaxBackups: 7,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app := nexo.New(nexo.WithAccessLog(nexo.AccessLogConfig{Output: access}))
+func WithAccessLog(config AccessLogConfig) Option {
+	return func(a *App) {
+		out := config.Output
+		if out == nil {
+			out = os.Stdout
+		}
+		a.accessLog = &accessLog{out: out, combined: config.Format != AccessLogCommon}
+	}
+}
+
+// write logs a request as received, from the client IP trust resolves,
+// with the status and size of its response.
+func (al *accessLog) write(r *http.Request, trust *proxyTrust, status int, size int64, start time.Time) {
+	var b strings.Builder
+	host := requestIP(r, trust)
+	if host == "" {
+		host = "-"
+	}
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	} else if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.FormatInt(size, 10)
+	}
+
+	fmt.Fprintf(&b, "%s - %s [%s] \"%s %s %s\" %d %s",
+		host, clfEscape(user), start.Format(clfTimeFormat),
+		clfEscape(r.Method), clfEscape(uri), clfEscape(r.Proto), status, bytes)
+	if al.combined {
+		fmt.Fprintf(&b, " \"%s\" \"%s\"", clfField(r.Referer()), clfField(r.UserAgent()))
+	}
+	b.WriteByte('\n')
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if _, err := io.WriteString(al.out, b.String()); err != nil {
+		log.Printf("nexo: access log: %v", err)
+	}
+}
+
+// clfField returns an escaped quoted field, or "-" when empty.
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return clfEscape(s)
+}
+
+// clfEscape escapes quotes, backslashes and control characters, as Apache
+// does, so a request can't forge log lines.
+func clfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ReopenLogs reopens the access log's file, after a tool like logrotate
+// moved it. Listen calls it on SIGHUP; call it yourself when serving the
+// app with your own http.Server.
+func (a *App) ReopenLogs() error {
+	if a.accessLog == nil {
+		return nil
+	}
+	if r, ok := a.accessLog.out.(interface{ Reopen() error }); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// reopenLogsOnSIGHUP reopens the logs on SIGHUP until the returned func is
+// called.
+func (a *App) reopenLogsOnSIGHUP() (stop func()) {
+	if a.accessLog == nil {
+		return func() {}
+	}
+	hup := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := a.ReopenLogs(); err != nil {
+					log.Printf("nexo: access log: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(hup)
+		close(done)
+	}
+}
+
+// ---------- Rotating File ----------
+
+// RotateConfig configures when a RotatingFile rotates.
+type RotateConfig struct {
+	// MaxSize rotates the file before it grows past this many bytes (0: no
+	// limit).
+	MaxSize int64
+
+	// MaxAge rotates the file once it has been written to for this long,
+	// like 24h for daily files (0: no limit).
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated files kept; older ones are
+	// removed (0: keep all).
+	MaxBackups int
+}
+
+// RotatingFile is a log file that rotates by size and age. Rotated files
+// are renamed with the time of rotation, like access.log.2026-10-16T15-04-05.000.
+// It's safe for concurrent use.
+type RotatingFile struct {
+	mu     sync.Mutex
+	path   string
+	config RotateConfig
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens the log file at path for appending, creating it
+// and its directory when needed.
+func OpenRotatingFile(path string, config RotateConfig) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, config: config}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file at f.path. f.mu must be held, or f unshared.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.opened = file, info.Size(), time.Now()
+	return nil
+}
+
+// Write appends p to the file, rotating it first when p would take it past
+// MaxSize or it's older than MaxAge.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	tooBig := f.config.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.config.MaxSize
+	tooOld := f.config.MaxAge > 0 && time.Since(f.opened) >= f.config.MaxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames the file with the current time and starts a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	backup := f.path + "." + time.Now().Format("2006-01-02T15-04-05.000")
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune removes the oldest backups beyond MaxBackups.
+func (f *RotatingFile) prune() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return err
+	}
+	// The timestamps sort in rotation order
+	sort.Strings(backups)
+	for len(backups) > f.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Reopen closes the file and opens the file at its path again, after a
+// tool like logrotate moved it.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = nil
+	return f.open()
+}
+
+// Close closes the file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
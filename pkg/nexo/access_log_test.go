@@ -0,0 +1,114 @@
+package nexo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		setup  func(r *http.Request)
+		path   string
+		want   string
+	}{
+		{
+			name:   "combined",
+			format: "",
+			path:   "/users?page=2",
+			setup: func(r *http.Request) {
+				r.SetBasicAuth("ada", "secret")
+				r.Header.Set("Referer", "https://example.com/")
+				r.Header.Set("User-Agent", "curl/8.0")
+			},
+			want: `^192\.0\.2\.1 - ada \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] "GET /users\?page=2 HTTP/1\.1" 200 5 "https://example\.com/" "curl/8\.0"\n$`,
+		},
+		{
+			name:   "common",
+			format: AccessLogCommon,
+			path:   "/missing",
+			want:   `^192\.0\.2\.1 - - \[[^\]]+\] "GET /missing HTTP/1\.1" 404 \d+\n$`,
+		},
+		{
+			name:   "escaped",
+			format: AccessLogCombined,
+			path:   "/users",
+			setup: func(r *http.Request) {
+				r.Header.Set("User-Agent", "evil\" \"x\n")
+			},
+			want: `"-" "evil\\" \\"x\\x0a"\n$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			app := New(WithAccessLog(AccessLogConfig{Format: tt.format, Output: &buf}))
+			app.DisableLogger()
+			app.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "users") })
+			app.Mount()
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !regexp.MustCompile(tt.want).MatchString(buf.String()) {
+				t.Errorf("line = %q, want match %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	f, err := OpenRotatingFile(path, RotateConfig{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Each line after the first rotates the file
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct backup names
+	}
+	if data, _ := os.ReadFile(path); string(data) != "fourth\n" {
+		t.Errorf("current file = %q", data)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2", backups)
+	}
+	if data, _ := os.ReadFile(backups[1]); string(data) != "third\n" {
+		t.Errorf("newest backup = %q", data)
+	}
+
+	// After an external tool moves the file, Reopen starts a new one
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("fifth\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "fifth\n" {
+		t.Errorf("reopened file = %q", data)
+	}
+	if data, _ := os.ReadFile(path + ".moved"); !strings.HasPrefix(string(data), "fourth") {
+		t.Errorf("moved file = %q", data)
+	}
+}
@@ -0,0 +1,97 @@
+package nexo
+
+import "net/http"
+
+// ---------- net/http Adapters ----------
+
+// WrapHandler adapts an http.Handler to a HandlerFunc, for serving
+// existing handlers, like a metrics or pprof handler, as Nexo routes.
+//
+// Example:
+//
+//	app.Get("/metrics", nexo.WrapHandler(promhttp.Handler()))
+func WrapHandler(h http.Handler) HandlerFunc {
+	return func(c *Context) error {
+		h.ServeHTTP(c.Response, c.Request)
+		return nil
+	}
+}
+
+// WrapHandlerFunc adapts an http.HandlerFunc to a HandlerFunc.
+func WrapHandlerFunc(h http.HandlerFunc) HandlerFunc {
+	return WrapHandler(h)
+}
+
+// WrapMiddleware adapts net/http middleware to a MiddlewareFunc, so the
+// middleware of the net/http ecosystem, like a company's existing auth,
+// runs in the Nexo middleware chain unchanged.
+//
+// The rest of the chain sees the request and response writer the
+// middleware passes on, so context values it adds are in c.Context(). When
+// the middleware doesn't call the next handler, its response is the
+// request's. Errors of the rest of the chain are returned to the
+// middleware before it, unless the middleware replaced the response
+// writer, like compression does: then the error response is written
+// through the replacement, while the middleware is still running.
+//
+// Example:
+//
+//	app.Use(nexo.WrapMiddleware(corpauth.Middleware))
+func WrapMiddleware(mw func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			var err error
+			h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resp := c.Response
+				c.Request, c.Response = r, w
+				err = next(c)
+				if err != nil && w != resp {
+					handleError(c, err)
+					err = nil
+				}
+				c.Response = resp
+			}))
+			h.ServeHTTP(c.Response, c.Request)
+			return err
+		}
+	}
+}
+
+// HTTPHandler adapts a HandlerFunc to an http.Handler, for serving Nexo
+// handlers from a plain net/http server or another router. Errors are
+// written like in a Nexo app. The app's services, like the cache and
+// feature flags, aren't available to the handler.
+//
+// Example:
+//
+//	mux.Handle("/users", nexo.HTTPHandler(func(c *nexo.Context) error {
+//	    return c.JSON(200, users)
+//	}))
+func HTTPHandler(h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := acquireContext(w, r)
+		defer releaseContext(c)
+		if err := h(c); err != nil {
+			handleError(c, err)
+		}
+		c.commitBuffer()
+	})
+}
+
+// HTTPMiddleware adapts a MiddlewareFunc to net/http middleware, so Nexo
+// middleware, like RateLimiter or Timeout, can wrap any http.Handler.
+// The next handler gets the request the middleware passes on, with
+// context values it added.
+//
+// Example:
+//
+//	mux.Handle("/api/", nexo.HTTPMiddleware(nexo.RateLimiter(100, time.Minute))(api))
+func HTTPMiddleware(mw MiddlewareFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		h := mw(func(c *Context) error {
+			next.ServeHTTP(c.Response, c.Request)
+			return nil
+		})
+		return HTTPHandler(h)
+	}
+}
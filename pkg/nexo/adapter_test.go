@@ -0,0 +1,163 @@
+package nexo
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type userKey struct{}
+
+// authMiddleware is net/http middleware adding the user of the token.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, "ana")))
+	})
+}
+
+// gzipWriter compresses the response, like net/http compression middleware.
+type gzipWriter struct {
+	http.ResponseWriter
+	zw *gzip.Writer
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) { return w.zw.Write(b) }
+
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		next.ServeHTTP(&gzipWriter{ResponseWriter: w, zw: zw}, r)
+	})
+}
+
+func TestWrapMiddleware(t *testing.T) {
+	app := New()
+	app.DisableLogger()
+	var chainErr error
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			chainErr = next(c)
+			return chainErr
+		}
+	})
+	app.Use(WrapMiddleware(authMiddleware))
+	app.Get("/me", func(c *Context) error {
+		return c.String(http.StatusOK, c.Context().Value(userKey{}).(string))
+	})
+	app.Get("/fail", func(c *Context) error {
+		return NotFound("no such user")
+	})
+	app.Get("/metrics", WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, "up 1")
+	})))
+	app.Get("/zipped", WrapMiddleware(gzipMiddleware)(func(c *Context) error {
+		return BadRequest("bad zip")
+	}))
+	app.Mount()
+
+	tests := []struct {
+		name    string
+		path    string
+		token   string
+		code    int
+		body    string
+		wantErr bool
+	}{
+		{"authenticated", "/me", "secret", http.StatusOK, "ana", false},
+		{"rejected", "/me", "", http.StatusUnauthorized, "unauthorized\n", false},
+		{"error reaches outer middleware", "/fail", "secret", http.StatusNotFound, "", true},
+		{"wrapped handler", "/metrics", "secret", http.StatusAccepted, "up 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chainErr = nil
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+			if (chainErr != nil) != tt.wantErr {
+				t.Errorf("outer middleware got error %v, want error = %v", chainErr, tt.wantErr)
+			}
+		})
+	}
+
+	// Errors are written through a replaced writer before it's closed
+	r := httptest.NewRequest(http.MethodGet, "/zipped", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !strings.Contains(string(body), "bad zip") {
+		t.Errorf("body = %q, want the error", body)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	h := HTTPHandler(func(c *Context) error {
+		if c.Query("id") == "" {
+			return BadRequest("id is required")
+		}
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Query("id")})
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?id=7", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"7"`) {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "id is required") {
+		t.Errorf("got %d %q, want the error", w.Code, w.Body.String())
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+	h := HTTPMiddleware(RateLimiter(1, time.Minute))(HTTPMiddleware(RequestID())(next))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Request-ID") == "" {
+		t.Error("request ID middleware didn't run")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want the limit to apply", w.Code)
+	}
+}
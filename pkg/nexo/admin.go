@@ -0,0 +1,421 @@
+package nexo
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// AdminConfig configures the admin dashboard, under admin: in nexo.yaml.
+type AdminConfig struct {
+	// Enabled serves the dashboard under Path.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path is where the dashboard is served (default: /_admin).
+	Path string `mapstructure:"path"`
+
+	// Username and Password require HTTP basic auth for the dashboard when
+	// Username is set. An empty Password is read from NEXO_ADMIN_PASSWORD,
+	// to keep it out of nexo.yaml.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// RecentErrors is how many failed requests the dashboard keeps
+	// (default: 50).
+	RecentErrors int `mapstructure:"recent_errors"`
+}
+
+// AdminSnapshot is what the admin dashboard shows, as served by
+// /_admin/api.
+type AdminSnapshot struct {
+	Mode       Mode      `json:"mode"`
+	GoVersion  string    `json:"go_version"`
+	Started    time.Time `json:"started"`
+	Uptime     string    `json:"uptime"`
+	Goroutines int       `json:"goroutines"`
+	HeapAlloc  uint64    `json:"heap_alloc"`
+
+	// Debug is the path of the diagnostics endpoints, when enabled.
+	Debug string `json:"debug,omitempty"`
+
+	Routes          []RouteTableEntry     `json:"routes"`
+	Metrics         []RouteMetrics        `json:"metrics"`
+	Cache           *CacheStats           `json:"cache,omitempty"`
+	CircuitBreakers []CircuitBreakerStats `json:"circuit_breakers"`
+	Errors          []AdminError          `json:"recent_errors"`
+	Panels          []AdminPanelData      `json:"panels"`
+}
+
+// RouteMetrics counts the requests served by a route since the dashboard
+// was mounted.
+type RouteMetrics struct {
+	Route    string  `json:"route"` // "METHOD /pattern"
+	Requests uint64  `json:"requests"`
+	Errors   uint64  `json:"errors"` // 5xx responses
+	AvgMs    float64 `json:"avg_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// AdminError is a request that failed with a 5xx response.
+type AdminError struct {
+	Time    time.Time `json:"time"`
+	Route   string    `json:"route"` // "METHOD /pattern"
+	Path    string    `json:"path"`
+	Status  int       `json:"status"`
+	Message string    `json:"message"`
+}
+
+// AdminPanelData is a custom panel of the dashboard (see AdminPanel).
+type AdminPanelData struct {
+	Name  string `json:"name"`
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// adminPanel is a panel registered with AdminPanel.
+type adminPanel struct {
+	name string
+	data func(ctx context.Context) (any, error)
+}
+
+// AdminPanel adds a panel to the admin dashboard. data is called on every
+// view and its result shown as JSON, which suits job queues, connection
+// pools and other state the app owns.
+//
+// Example:
+//
+//	app.AdminPanel("Jobs", func(ctx context.Context) (any, error) {
+//	    return queue.Stats(ctx)
+//	})
+func (a *App) AdminPanel(name string, data func(ctx context.Context) (any, error)) {
+	a.adminPanels = append(a.adminPanels, adminPanel{name: name, data: data})
+}
+
+// mountAdmin registers the admin dashboard enabled by WithAdmin or
+// admin.enabled:
+//
+//	GET /_admin        the dashboard
+//	GET /_admin/api    the dashboard as JSON (AdminSnapshot)
+//
+// They run behind the app's middleware, then basic auth from the config,
+// then the middleware given to WithAdmin. In production, a dashboard with
+// neither isn't served. Mounting the dashboard starts counting requests
+// per route.
+func (a *App) mountAdmin() {
+	cfg := a.config.Admin
+	base := strings.TrimSuffix(orDefault(cfg.Path, "/_admin"), "/")
+	if !cfg.Enabled || a.hasRoute(http.MethodGet, base+"/api") {
+		return
+	}
+	if cfg.Username == "" && len(a.adminMiddleware) == 0 && a.Mode() == ModeProduction {
+		log.Printf("nexo: the admin dashboard has no admin.username or WithAdmin middleware; not serving it in production")
+		return
+	}
+	if a.routeTree.stats == nil {
+		a.routeTree.stats = newRouteStats(cfg.RecentErrors)
+	}
+
+	var mws []MiddlewareFunc
+	if cfg.Username != "" {
+		password := orDefault(cfg.Password, os.Getenv("NEXO_ADMIN_PASSWORD"))
+		mws = append(mws, configBasicAuth("Admin", cfg.Username, password))
+	}
+	mws = append(mws, a.adminMiddleware...)
+
+	add := func(pattern string, handler HandlerFunc) {
+		a.routeTree.AddRoute(&Route{
+			Method:      http.MethodGet,
+			Pattern:     pattern,
+			Handler:     handler,
+			Priority:    CalculatePriority(pattern),
+			Middlewares: mws,
+		})
+	}
+	add(base, func(c *Context) error {
+		c.SetHeader("Cache-Control", "no-store")
+		return c.RenderOK(AdminPage(a.AdminSnapshot(c.Context())))
+	})
+	add(base+"/api", func(c *Context) error {
+		c.SetHeader("Cache-Control", "no-store")
+		return c.JSON(http.StatusOK, a.AdminSnapshot(c.Context()))
+	})
+}
+
+// configBasicAuth requires HTTP basic auth with the username and password
+// of an endpoint's config. An empty password rejects every request.
+func configBasicAuth(realm, username, password string) MiddlewareFunc {
+	return BasicAuthWithConfig(BasicAuthConfig{
+		Realm: realm,
+		Validator: func(user, pass string) bool {
+			return password != "" &&
+				subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+		},
+	})
+}
+
+// AdminSnapshot returns what the admin dashboard shows.
+func (a *App) AdminSnapshot(ctx context.Context) AdminSnapshot {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	s := AdminSnapshot{
+		Mode:            a.Mode(),
+		GoVersion:       runtime.Version(),
+		Goroutines:      runtime.NumGoroutine(),
+		HeapAlloc:       mem.HeapAlloc,
+		Routes:          a.RouteTable().Routes,
+		Metrics:         []RouteMetrics{},
+		CircuitBreakers: a.routeTree.CircuitBreakerStats(),
+		Errors:          []AdminError{},
+		Panels:          []AdminPanelData{},
+	}
+	if s.CircuitBreakers == nil {
+		s.CircuitBreakers = []CircuitBreakerStats{}
+	}
+	if a.config.Debug.Enabled {
+		s.Debug = strings.TrimSuffix(orDefault(a.config.Debug.Path, "/_debug"), "/")
+	}
+	if stats := a.routeTree.stats; stats != nil {
+		s.Started = stats.started
+		s.Uptime = time.Since(stats.started).Round(time.Second).String()
+		s.Metrics, s.Errors = stats.snapshot()
+	}
+	if cache, ok := a.routeTree.cache.(CacheStatsReporter); ok {
+		stats := cache.Stats()
+		s.Cache = &stats
+	}
+	for _, p := range a.adminPanels {
+		panel := AdminPanelData{Name: p.name}
+		data, err := p.data(ctx)
+		if err != nil {
+			panel.Error = err.Error()
+		} else {
+			panel.Data = data
+		}
+		s.Panels = append(s.Panels, panel)
+	}
+	return s
+}
+
+// routeStats counts the requests served by each route and keeps the most
+// recent failures.
+type routeStats struct {
+	mu      sync.Mutex
+	started time.Time
+	routes  map[string]*routeCounter // "METHOD /pattern" -> counter
+	errors  []AdminError             // ring buffer, oldest at next once full
+	next    int
+	max     int
+}
+
+// routeCounter holds the counters of a route.
+type routeCounter struct {
+	requests, errors uint64
+	total, slowest   time.Duration
+}
+
+// newRouteStats creates a routeStats keeping up to maxErrors failures.
+// Zero keeps 50.
+func newRouteStats(maxErrors int) *routeStats {
+	if maxErrors <= 0 {
+		maxErrors = 50
+	}
+	return &routeStats{
+		started: time.Now(),
+		routes:  make(map[string]*routeCounter),
+		max:     maxErrors,
+	}
+}
+
+// record counts a request served by route, which returned err.
+func (s *routeStats) record(route *Route, c *Context, err error, elapsed time.Duration) {
+	key := route.Method + " " + route.Pattern
+	status := c.StatusCode()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter := s.routes[key]
+	if counter == nil {
+		counter = &routeCounter{}
+		s.routes[key] = counter
+	}
+	counter.requests++
+	counter.total += elapsed
+	counter.slowest = max(counter.slowest, elapsed)
+	if status < http.StatusInternalServerError {
+		return
+	}
+	counter.errors++
+
+	message := http.StatusText(status)
+	if err != nil {
+		message = err.Error()
+	}
+	e := AdminError{Time: time.Now(), Route: key, Path: c.Path(), Status: status, Message: message}
+	if len(s.errors) < s.max {
+		s.errors = append(s.errors, e)
+		return
+	}
+	s.errors[s.next] = e
+	s.next = (s.next + 1) % s.max
+}
+
+// snapshot returns the route counters, busiest first, and the recent
+// failures, newest first.
+func (s *routeStats) snapshot() ([]RouteMetrics, []AdminError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metrics := make([]RouteMetrics, 0, len(s.routes))
+	for key, counter := range s.routes {
+		metrics = append(metrics, RouteMetrics{
+			Route:    key,
+			Requests: counter.requests,
+			Errors:   counter.errors,
+			AvgMs:    durationMs(counter.total / time.Duration(counter.requests)),
+			MaxMs:    durationMs(counter.slowest),
+		})
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Requests != metrics[j].Requests {
+			return metrics[i].Requests > metrics[j].Requests
+		}
+		return metrics[i].Route < metrics[j].Route
+	})
+
+	errs := make([]AdminError, 0, len(s.errors))
+	for i := range s.errors {
+		errs = append(errs, s.errors[(s.next+len(s.errors)-1-i)%len(s.errors)])
+	}
+	return metrics, errs
+}
+
+// durationMs returns d in milliseconds, rounded to microseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Round(time.Microsecond)) / float64(time.Millisecond)
+}
+
+// AdminPage renders s as the admin dashboard. Apps serving their own
+// dashboard can render it inside their layout.
+func AdminPage(s AdminSnapshot) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		return adminPageTemplate.Execute(w, s)
+	})
+}
+
+// adminPageTemplate renders an AdminSnapshot as the dashboard. The page
+// reloads itself every 10 seconds.
+var adminPageTemplate = template.Must(template.New("admin").Funcs(template.FuncMap{
+	"json": func(v any) string {
+		data, _ := json.MarshalIndent(v, "", "  ")
+		return string(data)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<meta http-equiv="refresh" content="10">
+<title>Nexo Admin</title>
+<style>
+  body { font: 14px/1.5 system-ui, sans-serif; margin: 0; color: #1f2937; background: #f9fafb; }
+  header { background: #111827; color: #fff; padding: 1rem 2rem; display: flex; gap: 2rem; align-items: baseline; flex-wrap: wrap; }
+  header h1 { font-size: 1.125rem; margin: 0; }
+  header span { opacity: .75; }
+  header a { color: #93c5fd; }
+  main { padding: 1rem 2rem 2rem; }
+  section { margin-top: 1.5rem; }
+  h2 { font-size: 1rem; margin: 0 0 .5rem; }
+  table { border-collapse: collapse; width: 100%; background: #fff; border: 1px solid #e5e7eb; }
+  th, td { text-align: left; padding: .375rem .75rem; border-bottom: 1px solid #e5e7eb; vertical-align: top; }
+  th { background: #f3f4f6; font-weight: 600; }
+  td.num { text-align: right; font-variant-numeric: tabular-nums; }
+  code, pre { font: 12px/1.5 ui-monospace, monospace; }
+  pre { background: #fff; border: 1px solid #e5e7eb; padding: .75rem; overflow: auto; margin: 0; }
+  .empty { color: #6b7280; }
+  .bad { color: #b91c1c; }
+</style>
+</head>
+<body>
+<header>
+  <h1>Nexo Admin</h1>
+  <span>{{with .Mode}}{{.}}{{else}}production{{end}}</span>
+  {{with .Uptime}}<span>up {{.}}</span>{{end}}
+  <span>{{.GoVersion}}</span>
+  <span>{{.Goroutines}} goroutines</span>
+  <span>{{.HeapAlloc}} B heap</span>
+  {{with .Debug}}<a href="{{.}}/pprof/">pprof</a> <a href="{{.}}/vars">expvar</a>{{end}}
+</header>
+<main>
+<section>
+  <h2>Requests</h2>
+  {{if .Metrics}}
+  <table>
+    <tr><th>Route</th><th>Requests</th><th>5xx</th><th>Avg (ms)</th><th>Max (ms)</th></tr>
+    {{range .Metrics}}
+    <tr><td><code>{{.Route}}</code></td><td class="num">{{.Requests}}</td><td class="num{{if .Errors}} bad{{end}}">{{.Errors}}</td><td class="num">{{printf "%.2f" .AvgMs}}</td><td class="num">{{printf "%.2f" .MaxMs}}</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p class="empty">No requests yet.</p>{{end}}
+</section>
+<section>
+  <h2>Recent errors</h2>
+  {{if .Errors}}
+  <table>
+    <tr><th>Time</th><th>Route</th><th>Path</th><th>Status</th><th>Error</th></tr>
+    {{range .Errors}}
+    <tr><td>{{.Time.Format "15:04:05"}}</td><td><code>{{.Route}}</code></td><td><code>{{.Path}}</code></td><td class="num bad">{{.Status}}</td><td>{{.Message}}</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p class="empty">No errors.</p>{{end}}
+</section>
+{{with .Cache}}
+<section>
+  <h2>Cache</h2>
+  <table>
+    <tr><th>Entries</th><th>Hits</th><th>Misses</th></tr>
+    <tr><td class="num">{{.Entries}}</td><td class="num">{{.Hits}}</td><td class="num">{{.Misses}}</td></tr>
+  </table>
+</section>
+{{end}}
+{{if .CircuitBreakers}}
+<section>
+  <h2>Circuit breakers</h2>
+  <table>
+    <tr><th>Route</th><th>State</th><th>Failures</th><th>Requests</th><th>Rejected</th><th>Trips</th></tr>
+    {{range .CircuitBreakers}}
+    <tr><td><code>{{.Route}}</code></td><td{{if ne .StateName "closed"}} class="bad"{{end}}>{{.StateName}}</td><td class="num">{{.Failures}}</td><td class="num">{{.Requests}}</td><td class="num">{{.Rejected}}</td><td class="num">{{.Trips}}</td></tr>
+    {{end}}
+  </table>
+</section>
+{{end}}
+{{range .Panels}}
+<section>
+  <h2>{{.Name}}</h2>
+  {{if .Error}}<p class="bad">{{.Error}}</p>{{else}}<pre>{{json .Data}}</pre>{{end}}
+</section>
+{{end}}
+<section>
+  <h2>Routes</h2>
+  <table>
+    <tr><th>Method</th><th>Pattern</th><th>File</th><th>Middleware</th></tr>
+    {{range .Routes}}
+    <tr><td>{{.Method}}</td><td><code>{{.Pattern}}</code></td><td><code>{{.File}}</code></td><td><code>{{range $i, $m := .Middleware}}{{if $i}} → {{end}}{{$m}}{{end}}</code></td></tr>
+    {{end}}
+  </table>
+</section>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,157 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminDashboard(t *testing.T) {
+	app := New(WithAdmin(), WithMode(ModeTest))
+	app.Get("/ok", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	app.Get("/boom", func(c *Context) error { return errors.New("database is down") })
+	app.Get("/missing", func(c *Context) error { return NotFound("no such thing") })
+	app.AdminPanel("Jobs", func(ctx context.Context) (any, error) {
+		return map[string]int{"pending": 3}, nil
+	})
+	app.AdminPanel("Broken", func(ctx context.Context) (any, error) {
+		return nil, errors.New("queue unreachable")
+	})
+	app.Mount()
+
+	for _, path := range []string{"/ok", "/ok", "/boom", "/missing"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	_, _, _ = app.Cache().Get(context.Background(), "absent")
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_admin/api", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var s AdminSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := map[string]RouteMetrics{}
+	for _, m := range s.Metrics {
+		requests[m.Route] = m
+	}
+	if m := requests["GET /ok"]; m.Requests != 2 || m.Errors != 0 {
+		t.Errorf("GET /ok metrics = %+v", m)
+	}
+	if m := requests["GET /boom"]; m.Requests != 1 || m.Errors != 1 {
+		t.Errorf("GET /boom metrics = %+v", m)
+	}
+	if m := requests["GET /missing"]; m.Requests != 1 || m.Errors != 0 {
+		t.Errorf("GET /missing metrics = %+v", m)
+	}
+
+	if len(s.Errors) != 1 || s.Errors[0].Path != "/boom" || s.Errors[0].Status != http.StatusInternalServerError || s.Errors[0].Message != "database is down" {
+		t.Errorf("recent errors = %+v", s.Errors)
+	}
+	if s.Cache == nil || s.Cache.Misses != 1 {
+		t.Errorf("cache = %+v", s.Cache)
+	}
+	if len(s.Panels) != 2 || s.Panels[0].Name != "Jobs" || s.Panels[1].Error != "queue unreachable" {
+		t.Errorf("panels = %+v", s.Panels)
+	}
+	if len(s.Routes) == 0 {
+		t.Error("routes are empty")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_admin", nil))
+	body := w.Body.String()
+	for _, want := range []string{"Nexo Admin", "GET /boom", "database is down", "Jobs", "&#34;pending&#34;: 3"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("dashboard does not contain %q", want)
+		}
+	}
+}
+
+func TestAdminDashboard_Auth(t *testing.T) {
+	config := DefaultConfig()
+	config.Admin = AdminConfig{Enabled: true, Path: "/ops/", Username: "ops"}
+	t.Setenv("NEXO_ADMIN_PASSWORD", "secret")
+	app := New(WithConfig(config))
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ops/api", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("without credentials: status = %d, want 401", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ops", nil)
+	req.SetBasicAuth("ops", "secret")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("with credentials: status = %d, want 200", w.Code)
+	}
+}
+
+func TestAdminDashboard_Disabled(t *testing.T) {
+	app := New()
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_admin", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if app.RouteTree().stats != nil {
+		t.Error("requests are counted without the dashboard")
+	}
+}
+
+func TestAdminDashboard_Unguarded(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		want int
+	}{
+		{ModeProduction, http.StatusNotFound},
+		{ModeTest, http.StatusOK},
+		{ModeDevelopment, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			app := New(WithAdmin(), WithMode(tt.mode), WithEnvFiles(false))
+			app.Mount()
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_admin/api", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteStats_RecentErrors(t *testing.T) {
+	s := newRouteStats(2)
+	route := &Route{Method: http.MethodGet, Pattern: "/jobs/{id}"}
+	for _, path := range []string{"/jobs/1", "/jobs/2", "/jobs/3"} {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		c.status = http.StatusBadGateway
+		s.record(route, c, nil, 0)
+	}
+
+	metrics, errs := s.snapshot()
+	if len(metrics) != 1 || metrics[0].Requests != 3 || metrics[0].Errors != 3 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	if strings.Join(paths, " ") != "/jobs/3 /jobs/2" {
+		t.Errorf("recent errors = %v, want the newest two, newest first", paths)
+	}
+}
@@ -0,0 +1,221 @@
+package nexo
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// APIDocsConfig configures the interactive API docs, under api_docs: in
+// nexo.yaml:
+//
+//	api_docs:
+//	  enabled: true
+//	  ui: elements
+//	  modes: [development, test]
+type APIDocsConfig struct {
+	// Enabled serves the docs under Path.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path is where the docs are served (default: /docs).
+	Path string `mapstructure:"path"`
+
+	// UI is the docs viewer: swagger for Swagger UI (default) or elements
+	// for Stoplight Elements.
+	UI string `mapstructure:"ui"`
+
+	// Modes lists the modes the docs are served in, like development or
+	// test (default: all), so they can stay off in production.
+	Modes []string `mapstructure:"modes"`
+
+	// Title, Version and Description describe the API in the spec.
+	Title       string `mapstructure:"title"`
+	Version     string `mapstructure:"version"`
+	Description string `mapstructure:"description"`
+
+	// Username and Password require HTTP basic auth for the docs when
+	// Username is set. An empty Password is read from
+	// NEXO_API_DOCS_PASSWORD, to keep it out of nexo.yaml.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// servedIn reports whether the docs are served in mode m.
+func (cfg APIDocsConfig) servedIn(m Mode) bool {
+	if len(cfg.Modes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(cfg.Modes, func(name string) bool { return ParseMode(name) == m })
+}
+
+// mountAPIDocs registers the API docs enabled by WithAPIDocs or
+// api_docs.enabled, in the modes of api_docs.modes:
+//
+//	GET /docs                 the docs UI
+//	GET /docs/openapi.json    the OpenAPI spec the UI renders
+//	GET /docs/routes.json     the live route table (RouteTable)
+//
+// The route table lists every route, including those the spec can't
+// describe, like routes registered with app.Get. The endpoints run behind
+// the app's middleware, then basic auth from the config, then the
+// middleware given to WithAPIDocs.
+func (a *App) mountAPIDocs() {
+	cfg := a.config.APIDocs
+	base := strings.TrimSuffix(orDefault(cfg.Path, "/docs"), "/")
+	if !cfg.Enabled || !cfg.servedIn(a.Mode()) || a.hasRoute(http.MethodGet, base+"/openapi.json") {
+		return
+	}
+	if a.openAPIConfig != nil && strings.TrimSuffix(a.openAPIConfig.DocsPath, "/") == base {
+		return // ServeOpenAPI serves its own docs there
+	}
+
+	var mws []MiddlewareFunc
+	if cfg.Username != "" {
+		password := orDefault(cfg.Password, os.Getenv("NEXO_API_DOCS_PASSWORD"))
+		mws = append(mws, configBasicAuth("API Docs", cfg.Username, password))
+	}
+	mws = append(mws, a.apiDocsMiddleware...)
+
+	add := func(pattern string, handler HandlerFunc) {
+		a.routeTree.AddRoute(&Route{
+			Method:      http.MethodGet,
+			Pattern:     pattern,
+			Handler:     handler,
+			Priority:    CalculatePriority(pattern),
+			Middlewares: mws,
+		})
+	}
+	page := apiDocsPage{
+		Title:     orDefault(cfg.Title, "API"),
+		SpecURL:   base + "/openapi.json",
+		RoutesURL: base + "/routes.json",
+	}
+	add(orDefault(base, "/"), func(c *Context) error {
+		c.SetHeader("Cache-Control", "no-store")
+		return c.HTML(http.StatusOK, apiDocsHTML(cfg.UI, page))
+	})
+	add(page.SpecURL, func(c *Context) error {
+		spec, err := a.openAPISpec(OpenAPIConfig{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description})
+		if err != nil {
+			return err
+		}
+		c.SetHeader("Cache-Control", "no-store")
+		return c.Blob(http.StatusOK, "application/json", spec)
+	})
+	add(page.RoutesURL, func(c *Context) error {
+		c.SetHeader("Cache-Control", "no-store")
+		return c.JSON(http.StatusOK, a.RouteTable().Routes)
+	})
+}
+
+// openAPISpec generates the OpenAPI spec of the app as JSON, with the
+// typed handlers registered with Handle.
+func (a *App) openAPISpec(config OpenAPIConfig) ([]byte, error) {
+	generator := NewOpenAPIGenerator(a.config.AppDir, config)
+	generator.operations = a.operations
+	return generator.GenerateJSON()
+}
+
+// apiDocsPage is the data of the API docs page.
+type apiDocsPage struct {
+	Title     string
+	SpecURL   string
+	RoutesURL string
+}
+
+// apiDocsHTML returns the API docs page for ui, swagger or elements.
+func apiDocsHTML(ui string, page apiDocsPage) string {
+	tmpl := swaggerDocsTemplate
+	if strings.EqualFold(ui, "elements") {
+		tmpl = elementsDocsTemplate
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, page); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// apiDocsRoutesHTML lists the live route table below the docs viewer.
+const apiDocsRoutesHTML = `
+<details id="nexo-routes">
+  <summary>Routes</summary>
+  <table><tbody></tbody></table>
+</details>
+<style>
+  #nexo-routes { font: 13px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; margin: 1rem 2rem 2rem; color: #1f2937; }
+  #nexo-routes summary { cursor: pointer; color: #6b7280; }
+  #nexo-routes td { padding: .2rem .8rem .2rem 0; }
+</style>
+<script>
+  fetch({{.RoutesURL}}).then(function (r) { return r.json(); }).then(function (routes) {
+    var body = document.querySelector("#nexo-routes tbody");
+    document.querySelector("#nexo-routes summary").textContent = "Routes (" + routes.length + ")";
+    routes.forEach(function (route) {
+      var row = body.insertRow();
+      [route.method, (route.host || "") + route.pattern, route.file || ""].forEach(function (text) {
+        row.insertCell().textContent = text;
+      });
+    });
+  });
+</script>`
+
+// The docs viewers are loaded from unpkg at exact versions, with subresource
+// integrity hashes, so a changed or compromised package isn't run on a page
+// that can send authenticated requests to the API.
+const (
+	swaggerUICSS = `<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.31.1/swagger-ui.css" integrity="sha384-KX9Rx9vM1AmUNAn07bPAiZhFD4C8jdNgG6f5MRNvR+EfAxs2PmMFtUUazui7ryZQ" crossorigin="anonymous">`
+	swaggerUIJS  = `<script src="https://unpkg.com/swagger-ui-dist@5.31.1/swagger-ui-bundle.js" integrity="sha384-o9idN8HE6/V6SAewgnr6/5nz7+Npt5J0Cb4tNyXK8pycsVmgl1ZNbRS7tlEGxd+J" crossorigin="anonymous"></script>`
+	elementsCSS  = `<link rel="stylesheet" href="https://unpkg.com/@stoplight/elements@9.0.15/styles.min.css" integrity="sha384-iVQBHadsD+eV0M5+ubRCEVXrXEBj+BqcuwjUwPoVJc0Pb1fmrhYSAhL+BFProHdV" crossorigin="anonymous">`
+	elementsJS   = `<script src="https://unpkg.com/@stoplight/elements@9.0.15/web-components.min.js" integrity="sha384-xjOcq9PZ/k+pGtPS/xcsCRXGjKKfTlIa4H1IYEnC+97jNa6sAMWTNrV6hY08W3GL" crossorigin="anonymous"></script>`
+)
+
+// swaggerDocsTemplate renders the spec with Swagger UI.
+var swaggerDocsTemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+` + swaggerUICSS + `
+<style>
+  body { margin: 0; }
+  .swagger-ui .topbar { display: none; }
+</style>
+</head>
+<body>
+<div id="swagger-ui"></div>` + apiDocsRoutesHTML + `
+` + swaggerUIJS + `
+<script>
+  SwaggerUIBundle({
+    url: {{.SpecURL}},
+    dom_id: "#swagger-ui",
+    presets: [SwaggerUIBundle.presets.apis],
+    deepLinking: true,
+    displayRequestDuration: true,
+    tryItOutEnabled: true
+  });
+</script>
+</body>
+</html>`))
+
+// elementsDocsTemplate renders the spec with Stoplight Elements.
+var elementsDocsTemplate = template.Must(template.New("elements").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+` + elementsCSS + `
+` + elementsJS + `
+<style>
+  body { margin: 0; }
+  elements-api { display: block; height: 85vh; }
+</style>
+</head>
+<body>
+<elements-api apiDescriptionUrl="{{.SpecURL}}" router="hash" layout="sidebar"></elements-api>` + apiDocsRoutesHTML + `
+</body>
+</html>`))
@@ -0,0 +1,160 @@
+package nexo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAPIDocs(t *testing.T) {
+	app := New(WithAPIDocs())
+	app.Get("/users/{id}", func(c *Context) error { return c.NoContent() })
+	app.Mount()
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/docs", "swagger-ui-bundle.js"},
+		{"/docs", `"/docs/routes.json"`},
+		{"/docs/openapi.json", `"openapi"`},
+		{"/docs/routes.json", `"pattern":"/users/{id}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("body does not contain %s:\n%s", tt.contains, w.Body)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/routes.json", nil))
+	var routes []RouteTableEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 4 {
+		t.Errorf("routes = %+v, want the app's route and the docs' own", routes)
+	}
+}
+
+func TestAPIDocsHTML_PinnedAssets(t *testing.T) {
+	tagRe := regexp.MustCompile(`<(?:script|link)[^>]*https://[^>]*>`)
+	page := apiDocsPage{Title: "API", SpecURL: "/docs/openapi.json", RoutesURL: "/docs/routes.json"}
+	pages := map[string]string{
+		"swagger":      apiDocsHTML("swagger", page),
+		"elements":     apiDocsHTML("elements", page),
+		"ServeOpenAPI": getSwaggerUIHTML("/openapi.json"),
+	}
+	for name, html := range pages {
+		tags := tagRe.FindAllString(html, -1)
+		if len(tags) != 2 {
+			t.Errorf("%s: %d external assets, want 2", name, len(tags))
+		}
+		for _, tag := range tags {
+			if !regexp.MustCompile(`@\d+\.\d+\.\d+/`).MatchString(tag) || !strings.Contains(tag, `integrity="sha384-`) {
+				t.Errorf("%s: asset without an exact version and integrity hash: %s", name, tag)
+			}
+		}
+	}
+}
+
+func TestAPIDocs_Modes(t *testing.T) {
+	tests := []struct {
+		env   string
+		modes []string
+		want  int
+	}{
+		{"production", nil, http.StatusOK},
+		{"production", []string{"development", "test"}, http.StatusNotFound},
+		{"dev", []string{"development", "test"}, http.StatusOK},
+		{"test", []string{"test"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"/"+strings.Join(tt.modes, ","), func(t *testing.T) {
+			t.Setenv("NEXO_ENV", tt.env)
+			config := DefaultConfig()
+			config.APIDocs = APIDocsConfig{Enabled: true, Modes: tt.modes}
+			app := New(WithConfig(config))
+			app.Mount()
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIDocs_Auth(t *testing.T) {
+	config := DefaultConfig()
+	config.APIDocs = APIDocsConfig{Enabled: true, Username: "dev"}
+	t.Setenv("NEXO_API_DOCS_PASSWORD", "secret")
+	app := New(WithConfig(config))
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("without credentials: status = %d, want 401", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil)
+	req.SetBasicAuth("dev", "secret")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("with credentials: status = %d, want 200", w.Code)
+	}
+}
+
+func TestAPIDocs_Disabled(t *testing.T) {
+	app := New()
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestLoadConfig_APIDocs(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "api_docs:\n  enabled: true\n  path: /reference\n  ui: elements\n  title: Shop API\n  modes: [development]\n"
+	if err := os.WriteFile(filepath.Join(dir, "nexo.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NEXO_ENV", "development")
+	app := New(WithConfig(config))
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reference", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `apiDescriptionUrl="/reference/openapi.json"`) || !strings.Contains(body, "<title>Shop API</title>") {
+		t.Errorf("status = %d, body:\n%s", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reference/openapi.json", nil))
+	if !strings.Contains(w.Body.String(), `"title": "Shop API"`) {
+		t.Errorf("spec = %s", w.Body)
+	}
+}
@@ -2,13 +2,21 @@ package nexo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"syscall"
 	"time"
 
+	"github.com/abdul-hamid-achik/nexo/pkg/env"
+	"github.com/abdul-hamid-achik/nexo/pkg/markdown"
+	"github.com/abdul-hamid-achik/nexo/pkg/serverless"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -53,8 +61,80 @@ type App struct {
 	// loggerEnabled indicates if the app-level logger is enabled
 	loggerEnabled bool
 
+	// accessLog writes the access log (see WithAccessLog)
+	accessLog *accessLog
+
+	// contracts records contract fixtures (see WithContractRecording)
+	contracts *contractRecorder
+
 	// openAPIConfig holds OpenAPI configuration
 	openAPIConfig *OpenAPIOptions
+
+	// operations are the typed handlers registered with Handle, for the
+	// OpenAPI spec
+	operations []typedOperation
+
+	// container holds services registered with Provide
+	container *container
+
+	// localeRouting enables locale-prefixed page routes (see LocalizeRoutes)
+	localeRouting bool
+
+	// sitemap holds the pages listed in sitemap.xml (see Sitemap)
+	sitemap *Sitemap
+
+	// markdown renders content pages (see MarkdownPage)
+	markdown *markdown.Pipeline
+
+	// debugMiddleware guards the diagnostics endpoints (see WithDebug)
+	debugMiddleware []MiddlewareFunc
+
+	// adminMiddleware guards the admin dashboard (see WithAdmin)
+	adminMiddleware []MiddlewareFunc
+
+	// apiDocsMiddleware guards the API docs (see WithAPIDocs)
+	apiDocsMiddleware []MiddlewareFunc
+
+	// batchMiddleware runs before the batch endpoint (see WithBatch)
+	batchMiddleware []MiddlewareFunc
+
+	// serverHooks configure the http.Server of Listen (see WithServer)
+	serverHooks []func(*http.Server)
+
+	// adminPanels holds the dashboard's custom panels (see AdminPanel)
+	adminPanels []adminPanel
+
+	// tenantResolver and tenantLookup replace those of the tenancy config
+	tenantResolver TenantResolver
+	tenantLookup   TenantLookup
+
+	// files holds static files, content pages and the asset manifest (see WithFS)
+	files fs.FS
+
+	// env reads typed configuration from the environment (see Env)
+	env *env.Env
+
+	// envFiles enables loading .env files in New (see WithEnvFiles)
+	envFiles bool
+
+	// mode is the mode set with WithMode
+	mode Mode
+
+	// maintenance holds maintenance mode (see SetMaintenance)
+	maintenance *maintenance
+
+	// headers sets the response headers of the headers: rules
+	headers *headerPolicy
+
+	// headerRules are the header rules added with WithHeaders, applied
+	// after those of the config
+	headerRules []HeaderRule
+
+	// routing holds the redirects: and rewrites: rules
+	routing *routingRules
+
+	// mounted is set by Mount, so Handler mounts the routes only once
+	mounted bool
 }
 
 // New creates a new Nexo application with the given options.
@@ -64,8 +144,10 @@ func New(opts ...Option) *App {
 		config:        DefaultConfig(),
 		middlewares:   make([]MiddlewareFunc, 0),
 		routeTree:     NewRouteTree(),
-		logger:        NewRequestLogger(DefaultRequestLoggerConfig()),
 		loggerEnabled: true, // Enabled by default
+		container:     newContainer(),
+		files:         defaultFS(),
+		envFiles:      true,
 	}
 
 	// Apply options
@@ -73,9 +155,95 @@ func New(opts ...Option) *App {
 		opt(app)
 	}
 
+	// Load .env files before anything reads the environment
+	app.loadEnvFiles()
+
+	// Log at the level of the mode, or NEXO_LOG_LEVEL from .env
+	app.logger = NewRequestLogger(requestLoggerConfig(app.configuredMode()))
+	if app.config.Log.Level != "" {
+		app.applyLogLevel(app.config.Log)
+	}
+
+	// Requests read the config through UpdateConfig's swaps
+	app.routeTree.config = newLiveConfig(app.config)
+
 	// Create scanner with app directory
 	app.scanner = NewScanner(app.config.AppDir)
 
+	// Request contexts read <head> defaults from the final config
+	app.routeTree.head = &app.config.Head
+
+	// Read the asset manifest written by the last bundle
+	if app.routeTree.assets == nil {
+		app.routeTree.assets = loadAssetManifest(app.files, app.config)
+	}
+
+	// Open the cache backend shared by the response cache and rate limiter
+	if app.routeTree.cache == nil {
+		app.routeTree.cache = openAppCache(app.config.Cache)
+	}
+
+	// Feature flags come from the flags file and NEXO_FLAG_ variables
+	if app.routeTree.flags == nil {
+		app.routeTree.flags = openAppFlags(app.config.Flags, func() map[string]any {
+			return app.routeTree.config.current.Load().Flags.Set
+		})
+	}
+
+	// Events go to the subscribers listed under events:
+	if app.routeTree.events == nil {
+		app.routeTree.events = openAppEvents(app.config.Events)
+	}
+
+	// Object storage is opened as configured under storage:
+	if app.routeTree.storage == nil {
+		app.routeTree.storage = openAppStorage(app.config.Storage)
+	}
+
+	// Tenants are resolved as configured under tenancy:
+	tenancy, err := newTenancy(app.config.Tenancy, app.tenantResolver, app.tenantLookup)
+	if err != nil {
+		log.Printf("nexo: %v; tenancy is off", err)
+	}
+	app.routeTree.tenancy = tenancy
+
+	// Forwarding headers are honored from the proxies under
+	// trusted_proxies:
+	trust, err := newProxyTrust(app.config.TrustedProxies)
+	if err != nil {
+		log.Printf("nexo: %v; forwarding headers are ignored", err)
+		trust = &proxyTrust{}
+	}
+	app.routeTree.proxyTrust = trust
+	app.logger.trust = trust
+
+	// Each app gets its own rules and errors, so RegisterValidation and
+	// RegisterErrors don't leak
+	if app.routeTree.validator == nil {
+		app.routeTree.validator = NewStructValidator()
+	}
+	if app.routeTree.errors == nil {
+		app.routeTree.errors = NewErrorCatalog()
+	}
+
+	// Maintenance mode is read from the config, NEXO_MAINTENANCE and the
+	// maintenance file
+	app.maintenance = newMaintenance(app.config.Maintenance)
+
+	// Response headers are set by the rules under headers:
+	headers, err := newHeaderPolicy(slices.Concat(app.config.Headers, app.headerRules))
+	if err != nil {
+		log.Printf("nexo: %v; header rules are ignored", err)
+	}
+	app.headers = headers
+
+	// So are the redirects and rewrites under redirects: and rewrites:
+	routing, err := newRoutingRules(app.config.Redirects, app.config.Rewrites)
+	if err != nil {
+		log.Printf("nexo: %v; redirects and rewrites are ignored", err)
+	}
+	app.routing = routing
+
 	return app
 }
 
@@ -91,6 +259,7 @@ func New(opts ...Option) *App {
 //	})
 func (a *App) SetLogger(config RequestLoggerConfig) {
 	a.logger = NewRequestLogger(config)
+	a.logger.trust = a.routeTree.proxyTrust
 	a.loggerEnabled = true
 }
 
@@ -103,7 +272,8 @@ func (a *App) DisableLogger() {
 // EnableLogger enables the app-level request logger with default configuration.
 func (a *App) EnableLogger() {
 	if a.logger == nil {
-		a.logger = NewRequestLogger(DefaultRequestLoggerConfig())
+		a.logger = NewRequestLogger(requestLoggerConfig(a.configuredMode()))
+		a.logger.trust = a.routeTree.proxyTrust
 	}
 	a.loggerEnabled = true
 }
@@ -119,9 +289,15 @@ func (a *App) Router() chi.Router {
 	return a.router
 }
 
-// Config returns the application configuration.
+// Config returns the application configuration, with the changes of the
+// last UpdateConfig.
 func (a *App) Config() *Config {
-	return a.config
+	return a.routeTree.config.current.Load()
+}
+
+// Cache returns the app's cache backend (see WithCache).
+func (a *App) Cache() Cache {
+	return a.routeTree.cache
 }
 
 // RouteTree returns the route tree for inspection.
@@ -136,58 +312,117 @@ func (a *App) Scan() error {
 
 // Mount registers all routes with the chi router.
 func (a *App) Mount() {
-	a.routeTree.Mount(a.router, a.middlewares)
+	a.mountSEO()
+	a.mountInspector()
+	a.mountDebug()
+	a.mountAdmin()
+	a.mountAPIDocs()
+	a.mountRevalidate()
+	a.mountStorage()
+	a.mountBatch()
+	a.routeTree.Mount(a.router, a.globalMiddlewares())
+	a.mounted = true
+}
+
+// globalMiddlewares returns the middleware run before every route: the
+// app's, after the tenant is resolved when tenancy is on.
+func (a *App) globalMiddlewares() []MiddlewareFunc {
+	if a.routeTree.tenancy == nil {
+		return a.middlewares
+	}
+	return append([]MiddlewareFunc{tenantMiddleware(a.routeTree.tenancy.required)}, a.middlewares...)
 }
 
 // ServeHTTP implements http.Handler interface.
-// Request flow: Logger → Proxy → Router (with middlewares → handlers)
+// Request flow: Logger → Maintenance → Proxy → Router (with middlewares → handlers)
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
-	// Wrap response writer to capture status and size
+	if a.contracts != nil {
+		var done func()
+		w, r, done = a.contracts.capture(w, r)
+		defer done()
+	}
+
+	// Wrap response writer to capture status and size, and the bodies the
+	// logger shows
 	rw := newResponseWriter(w)
+	var bodies *loggedBodies
+	if a.loggerEnabled && a.logger != nil {
+		r, bodies = a.logger.capture(r, rw)
+	}
+	if a.accessLog != nil {
+		received := r
+		defer func() { a.accessLog.write(received, a.routeTree.proxyTrust, rw.Status(), rw.Size(), start) }()
+	}
+
+	// Serve the maintenance page before anything else
+	if a.maintenance.serve(rw, r, a.routeTree.proxyTrust) {
+		a.logRequest(r, rw, start, nil, nil, bodies)
+		return
+	}
+
+	// Set the headers of the matching header rules, before the proxy can
+	// rewrite the path
+	a.headers.apply(rw.Header(), r.URL.Path)
 
+	// Redirect or rewrite the request as configured, before the proxy
 	var proxyAction *ProxyAction
+	if target, status, ok := a.routing.redirect(r); ok {
+		http.Redirect(rw, r, target, status)
+		a.logRequest(r, rw, start, &ProxyAction{Type: "redirect", Target: target}, nil, bodies)
+		return
+	}
+	if target, ok := a.routing.rewrite(r); ok {
+		r = r.Clone(r.Context())
+		r.URL.Path, r.URL.RawPath, r.URL.RawQuery = target.Path, target.RawPath, target.RawQuery
+		r.RequestURI = target.RequestURI()
+		proxyAction = &ProxyAction{Type: "rewrite", Target: target.String()}
+	}
 
 	// Execute proxy if configured
 	if a.routeTree.HasProxy() {
-		ctx := NewContext(rw, r)
+		ctx := acquireContext(rw, r)
+		a.routeTree.initContext(ctx)
 		result := executeProxy(ctx, a.routeTree.Proxy(), a.routeTree.ProxyConfiguration())
+		rewritten := ctx.Request
+		releaseContext(ctx)
 
 		proxyAction = result.Action
 
 		if result.Error != nil {
 			// Proxy error - return 500
 			http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
-			a.logRequest(r, rw, start, proxyAction, result.Error)
+			a.logRequest(r, rw, start, proxyAction, result.Error, bodies)
 			return
 		}
 
 		if !result.ContinueToRouter {
 			// Proxy handled the request (redirect or response) - log and return
-			a.logRequest(r, rw, start, proxyAction, nil)
+			a.logRequest(r, rw, start, proxyAction, nil, bodies)
 			return
 		}
 
 		// Use potentially rewritten request
-		r = ctx.Request
+		r = rewritten
 	}
 
-	// Continue to router
-	a.router.ServeHTTP(rw, r)
+	// Continue to the router, or the router of a host-scoped route
+	router, r := a.routeTree.routerFor(r, a.router)
+	router.ServeHTTP(rw, r)
 
 	// Log the request
-	a.logRequest(r, rw, start, proxyAction, nil)
+	a.logRequest(r, rw, start, proxyAction, nil, bodies)
 }
 
 // logRequest logs a request using the app-level logger if enabled.
-func (a *App) logRequest(r *http.Request, rw *responseWriter, start time.Time, proxyAction *ProxyAction, err error) {
+func (a *App) logRequest(r *http.Request, rw *responseWriter, start time.Time, proxyAction *ProxyAction, err error, bodies *loggedBodies) {
 	if !a.loggerEnabled || a.logger == nil {
 		return
 	}
 
 	latency := time.Since(start)
-	a.logger.Log(r, rw.Status(), rw.Size(), latency, proxyAction, err)
+	a.logger.log(r, rw.Status(), rw.Size(), latency, proxyAction, err, bodies)
 }
 
 // Listen starts the HTTP server and listens for requests.
@@ -210,20 +445,22 @@ func (a *App) Listen(addr ...string) error {
 	// Mount routes to router
 	a.Mount()
 
-	// Create server - use App as handler to enable proxy
-	a.server = &http.Server{
-		Addr:              address,
-		Handler:           a,
-		ReadTimeout:       15 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
-		WriteTimeout:      15 * time.Second,
-		IdleTimeout:       60 * time.Second,
+	// In AWS Lambda, serve invocations instead of listening on a port
+	if serverless.IsLambda() {
+		return serverless.Start(a)
 	}
 
+	// Create server - use App as handler to enable proxy
+	a.server = a.newServer(address)
+
 	// Channel for shutdown signals
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	// Reopen the access log on SIGHUP
+	stopReopen := a.reopenLogsOnSIGHUP()
+	defer stopReopen()
+
 	// Channel for server errors
 	serverErr := make(chan error, 1)
 
@@ -244,23 +481,30 @@ func (a *App) Listen(addr ...string) error {
 	}
 
 	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
 	defer cancel()
 
 	if err := a.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown gracefully: %w", err)
 	}
+	if err := a.closeEvents(ctx); err != nil {
+		log.Printf("nexo: events: %v", err)
+	}
+	a.stopWatchingConfig()
 
 	fmt.Println("  Server stopped")
 	return nil
 }
 
 // Shutdown gracefully shuts down the server.
+// Events waiting for a retry are dead-lettered.
 func (a *App) Shutdown(ctx context.Context) error {
-	if a.server == nil {
-		return nil
+	a.stopWatchingConfig()
+	var err error
+	if a.server != nil {
+		err = a.server.Shutdown(ctx)
 	}
-	return a.server.Shutdown(ctx)
+	return errors.Join(err, a.closeEvents(ctx))
 }
 
 // Addr returns the address the server is listening on.
@@ -302,6 +546,50 @@ func (a *App) RegisterRoute(method, pattern string, handler HandlerFunc) {
 	})
 }
 
+// RegisterRouteWithConfig registers a route with timeout, retry and
+// circuit-breaker settings. Generated code uses it for route.go files that
+// declare a RouteConfig variable.
+func (a *App) RegisterRouteWithConfig(method, pattern string, handler HandlerFunc, config RouteConfig) {
+	route := &Route{
+		Method:         method,
+		Pattern:        pattern,
+		Handler:        handler,
+		Priority:       CalculatePriority(pattern),
+		Config:         &config,
+		SkipMiddleware: config.SkipMiddleware,
+		OnlyMiddleware: config.OnlyMiddleware,
+	}
+	if config.Priority != 0 {
+		route.Priority = config.Priority
+		route.PriorityOverride = true
+	}
+	a.routeTree.AddRoute(route)
+}
+
+// SetRoutePriority overrides the priority of a registered route.
+// See RouteTree.SetPriority.
+func (a *App) SetRoutePriority(method, pattern string, priority int) bool {
+	return a.routeTree.SetPriority(method, pattern, priority)
+}
+
+// AddRouteMiddleware adds middleware to the routes matching method and
+// pattern. See RouteTree.AddRouteMiddleware.
+func (a *App) AddRouteMiddleware(method, pattern string, mws ...MiddlewareFunc) bool {
+	return a.routeTree.AddRouteMiddleware(method, pattern, mws...)
+}
+
+// SkipRouteMiddleware makes the routes matching method and pattern skip
+// the named path middleware. See RouteTree.SkipMiddleware.
+func (a *App) SkipRouteMiddleware(method, pattern string, names ...string) bool {
+	return a.routeTree.SkipMiddleware(method, pattern, names...)
+}
+
+// OnlyRouteMiddleware makes the routes matching method and pattern skip
+// every path middleware but the named ones. See RouteTree.OnlyMiddleware.
+func (a *App) OnlyRouteMiddleware(method, pattern string, names ...string) bool {
+	return a.routeTree.OnlyMiddleware(method, pattern, names...)
+}
+
 // Get registers a GET route.
 func (a *App) Get(pattern string, handler HandlerFunc) {
 	a.RegisterRoute(http.MethodGet, pattern, handler)
@@ -371,13 +659,11 @@ func (a *App) ServeOpenAPI(opts OpenAPIOptions) {
 
 // handleOpenAPISpec serves the OpenAPI specification as JSON.
 func (a *App) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
-	generator := NewOpenAPIGenerator(a.config.AppDir, OpenAPIConfig{
+	jsonBytes, err := a.openAPISpec(OpenAPIConfig{
 		Title:       a.openAPIConfig.Title,
 		Version:     a.openAPIConfig.Version,
 		Description: a.openAPIConfig.Description,
 	})
-
-	jsonBytes, err := generator.GenerateJSON()
 	if err != nil {
 		http.Error(w, "Failed to generate spec", http.StatusInternalServerError)
 		return
@@ -404,7 +690,7 @@ func getSwaggerUIHTML(specURL string) string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>API Documentation</title>
-    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+    `+swaggerUICSS+`
     <style>
         body { margin: 0; padding: 0; }
         .swagger-ui .topbar { display: none; }
@@ -412,7 +698,7 @@ func getSwaggerUIHTML(specURL string) string {
 </head>
 <body>
     <div id="swagger-ui"></div>
-    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    `+swaggerUIJS+`
     <script>
         window.onload = function() {
             SwaggerUIBundle({
@@ -433,7 +719,8 @@ func getSwaggerUIHTML(specURL string) string {
 }
 
 // Static serves static files from a directory.
-// The path is the URL path prefix, and dir is the file system directory.
+// The path is the URL path prefix, and dir is the directory in the app's
+// file system (see WithFS). Directories outside it are read from disk.
 func (a *App) Static(path string, dir string) {
 	if path == "" {
 		path = "/"
@@ -450,11 +737,21 @@ func (a *App) Static(path string, dir string) {
 	pattern += "*"
 
 	// Create a file server
-	fs := http.StripPrefix(path, http.FileServer(http.Dir(dir)))
+	var files http.FileSystem = http.Dir(dir)
+	if name := filepath.ToSlash(filepath.Clean(dir)); fs.ValidPath(name) {
+		if sub, err := fs.Sub(a.files, name); err == nil {
+			files = http.FS(sub)
+		}
+	}
+	fileServer := http.StripPrefix(path, http.FileServer(files))
 
 	// Register the handler directly with chi
 	a.router.Get(pattern, func(w http.ResponseWriter, r *http.Request) {
-		fs.ServeHTTP(w, r)
+		if a.Mode() == ModeDevelopment {
+			// Revalidate every time, so edits show up on reload
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		fileServer.ServeHTTP(w, r)
 	})
 }
 
@@ -468,10 +765,11 @@ func (a *App) Group(pattern string, fn func(g *RouteGroup)) {
 	fn(g)
 }
 
-// RouteGroup is a group of routes with shared prefix and middleware.
+// RouteGroup is a group of routes with shared prefix, host and middleware.
 type RouteGroup struct {
 	app         *App
 	prefix      string
+	host        string
 	middlewares []MiddlewareFunc
 }
 
@@ -480,6 +778,17 @@ func (g *RouteGroup) Use(mw MiddlewareFunc) {
 	g.middlewares = append(g.middlewares, mw)
 }
 
+// Group creates a nested route group. It inherits the group's prefix, host
+// and middleware.
+func (g *RouteGroup) Group(pattern string, fn func(g *RouteGroup)) {
+	fn(&RouteGroup{
+		app:         g.app,
+		prefix:      g.prefix + pattern,
+		host:        g.host,
+		middlewares: append([]MiddlewareFunc{}, g.middlewares...),
+	})
+}
+
 // Get registers a GET route in the group.
 func (g *RouteGroup) Get(pattern string, handler HandlerFunc) {
 	g.app.routeTree.AddRoute(&Route{
@@ -487,6 +796,7 @@ func (g *RouteGroup) Get(pattern string, handler HandlerFunc) {
 		Pattern:     g.prefix + pattern,
 		Handler:     handler,
 		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
 		Middlewares: g.middlewares,
 	})
 }
@@ -498,6 +808,7 @@ func (g *RouteGroup) Post(pattern string, handler HandlerFunc) {
 		Pattern:     g.prefix + pattern,
 		Handler:     handler,
 		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
 		Middlewares: g.middlewares,
 	})
 }
@@ -509,6 +820,7 @@ func (g *RouteGroup) Put(pattern string, handler HandlerFunc) {
 		Pattern:     g.prefix + pattern,
 		Handler:     handler,
 		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
 		Middlewares: g.middlewares,
 	})
 }
@@ -520,6 +832,7 @@ func (g *RouteGroup) Patch(pattern string, handler HandlerFunc) {
 		Pattern:     g.prefix + pattern,
 		Handler:     handler,
 		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
 		Middlewares: g.middlewares,
 	})
 }
@@ -531,6 +844,7 @@ func (g *RouteGroup) Delete(pattern string, handler HandlerFunc) {
 		Pattern:     g.prefix + pattern,
 		Handler:     handler,
 		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
 		Middlewares: g.middlewares,
 	})
 }
@@ -0,0 +1,79 @@
+package nexo
+
+import (
+	"context"
+	"io/fs"
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+)
+
+// JSConfig configures the script bundle built by nexo dev and nexo build,
+// under js: in nexo.yaml.
+type JSConfig struct {
+	// Entry lists the entry points (default: assets/js/entry.ts).
+	Entry []string `mapstructure:"entry"`
+
+	// OutDir is where bundles are written (default: static/js).
+	OutDir string `mapstructure:"out_dir"`
+
+	// Manifest is the asset manifest mapping bundle names to fingerprinted
+	// URLs (default: static/manifest.json).
+	Manifest string `mapstructure:"manifest"`
+
+	// Target is the JavaScript language target, e.g. "es2020".
+	Target string `mapstructure:"target"`
+
+	// External lists imports left out of the bundle.
+	External []string `mapstructure:"external"`
+
+	// ESBuild is an esbuild executable to bundle with instead of the
+	// built-in esbuild, like node_modules/.bin/esbuild.
+	ESBuild string `mapstructure:"esbuild"`
+}
+
+// Assets returns the app's asset manifest, read from js.manifest when the
+// app is created or set with WithAssetManifest.
+func (a *App) Assets() *bundler.Manifest {
+	return a.routeTree.assets
+}
+
+// loadAssetManifest reads the asset manifest of config from fsys. A missing
+// manifest yields an empty one, so assets resolve to their unfingerprinted
+// names.
+func loadAssetManifest(fsys fs.FS, config *Config) *bundler.Manifest {
+	name := orDefault(config.JS.Manifest, bundler.DefaultManifest)
+	m, err := bundler.LoadManifest(fsys, name, config.StaticURL)
+	if err != nil {
+		log.Printf("nexo: %v", err)
+		return bundler.NewManifest(config.StaticURL)
+	}
+	return m
+}
+
+// Asset returns the URL of a bundled asset, e.g. "js/entry.js", from the
+// asset manifest: /static/js/entry-5FQXK2VN.js after a production build,
+// /static/js/entry.js in development.
+func (c *Context) Asset(name string) string {
+	return c.assets.Path(name)
+}
+
+// assetsKey is the context key for the asset manifest of a rendered response.
+type assetsKey struct{}
+
+// attachAssets makes the asset manifest available to Asset in templates.
+func (c *Context) attachAssets() {
+	if c.assets == nil || c.assetsAttached {
+		return
+	}
+	c.assetsAttached = true
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), assetsKey{}, c.assets))
+}
+
+// Asset returns the URL of a bundled asset in a templ component:
+//
+//	<script type="module" src={ nexo.Asset(ctx, "js/entry.js") }></script>
+func Asset(ctx context.Context, name string) string {
+	m, _ := ctx.Value(assetsKey{}).(*bundler.Manifest)
+	return m.Path(name)
+}
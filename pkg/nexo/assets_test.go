@@ -0,0 +1,55 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+)
+
+func TestAsset(t *testing.T) {
+	manifest := bundler.NewManifest("/static")
+	manifest.Assets["js/entry.js"] = "/static/js/entry-5FQXK2VN.js"
+
+	app := New(WithAssetManifest(manifest))
+	app.Get("/", func(c *Context) error {
+		script := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, Asset(ctx, "js/entry.js")+" "+c.Asset("css/site.css"))
+			return err
+		})
+		return c.Render(http.StatusOK, script)
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := "/static/js/entry-5FQXK2VN.js /static/css/site.css"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestNew_LoadsAssetManifest(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if got := New(WithStaticURL("/assets")).Assets().Path("js/entry.js"); got != "/assets/js/entry.js" {
+		t.Errorf("without a manifest Path() = %q, want /assets/js/entry.js", got)
+	}
+
+	if err := os.MkdirAll("static", 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"version":1,"base":"/static","assets":{"js/entry.js":"/static/js/entry-A.js"}}`
+	if err := os.WriteFile(bundler.DefaultManifest, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := New().Assets().Path("js/entry.js"); got != "/static/js/entry-A.js" {
+		t.Errorf("Path() = %q, want the fingerprinted URL", got)
+	}
+}
@@ -0,0 +1,380 @@
+package nexo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ---------- Audit Middleware ----------
+
+// AuditEntry records who did what: an authenticated actor's request to
+// change an entity, and its outcome.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+
+	// Actor identifies who made the request (see AuditConfig.ActorFunc).
+	Actor string `json:"actor,omitempty"`
+
+	// Action is the route, like "DELETE /api/users/{id}".
+	Action string `json:"action"`
+	Path   string `json:"path"`
+
+	// Entity holds the route parameters, which identify what was changed,
+	// like {"id": "42"}.
+	Entity map[string]string `json:"entity,omitempty"`
+
+	Status int    `json:"status"`
+	IP     string `json:"ip"`
+
+	// PayloadHash is the SHA-256 of the request body, like "sha256:9f86…",
+	// to prove later what was sent without storing it.
+	PayloadHash string `json:"payload_hash,omitempty"`
+
+	// Payload is the request body with sensitive fields redacted, for JSON
+	// and form bodies when AuditConfig.IncludePayload is set.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Error is the error the handler returned, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink stores audit entries, e.g. in a database table, a file or a
+// SIEM.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, entry AuditEntry) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+//
+// Example:
+//
+//	sink := nexo.AuditSinkFunc(func(ctx context.Context, e nexo.AuditEntry) error {
+//	    _, err := db.ExecContext(ctx,
+//	        "INSERT INTO audit_log (time, actor, action, entity, status) VALUES ($1, $2, $3, $4, $5)",
+//	        e.Time, e.Actor, e.Action, e.Entity["id"], e.Status)
+//	    return err
+//	})
+type AuditSinkFunc func(ctx context.Context, entry AuditEntry) error
+
+// WriteAudit implements AuditSink.
+func (f AuditSinkFunc) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	return f(ctx, entry)
+}
+
+// AuditConfig holds configuration for the audit middleware.
+type AuditConfig struct {
+	// Sink stores the entries. Required.
+	Sink AuditSink
+
+	// Methods are the audited methods. Default is POST, PUT, PATCH and
+	// DELETE, the requests that change something.
+	Methods []string
+
+	// ActorFunc identifies who made the request. Default is the principal
+	// set with SetPrincipal when it is a string or fmt.Stringer, then the
+	// user authenticated by BasicAuth.
+	ActorFunc func(c *Context) string
+
+	// Redact lists the payload fields replaced with "[redacted]", matched
+	// case-insensitively at any depth. Default is DefaultAuditRedact.
+	Redact []string
+
+	// IncludePayload adds the redacted request body to entries.
+	IncludePayload bool
+
+	// MaxPayload bounds the bytes of the body that are hashed and recorded.
+	// Default is 1 MB.
+	MaxPayload int64
+}
+
+// DefaultAuditRedact lists the payload fields redacted by default.
+var DefaultAuditRedact = []string{
+	"password", "password_confirmation", "current_password", "new_password",
+	"token", "access_token", "refresh_token", "secret", "api_key",
+	"authorization", "credit_card", "card_number", "cvv", "ssn",
+}
+
+// Audit returns a middleware that writes an AuditEntry to sink for every
+// POST, PUT, PATCH and DELETE request.
+func Audit(sink AuditSink) MiddlewareFunc {
+	return AuditWithConfig(AuditConfig{Sink: sink})
+}
+
+// AuditWithConfig returns an audit middleware with custom configuration.
+// Entries are written after the handler returns; a failing sink is
+// logged and doesn't fail the request.
+func AuditWithConfig(config AuditConfig) MiddlewareFunc {
+	if config.Sink == nil {
+		panic("nexo: AuditConfig.Sink is required")
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if config.ActorFunc == nil {
+		config.ActorFunc = defaultAuditActor
+	}
+	if config.Redact == nil {
+		config.Redact = DefaultAuditRedact
+	}
+	if config.MaxPayload <= 0 {
+		config.MaxPayload = 1 << 20
+	}
+	redact := make(map[string]bool, len(config.Redact))
+	for _, field := range config.Redact {
+		redact[strings.ToLower(field)] = true
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if !slices.Contains(config.Methods, c.Method()) {
+				return next(c)
+			}
+
+			entry := AuditEntry{Time: time.Now(), Path: c.Path(), IP: c.ClientIP()}
+			body, err := peekBody(c.Request, config.MaxPayload)
+			if err != nil {
+				return BadRequest("failed to read request body")
+			}
+			if len(body) > 0 {
+				sum := sha256.Sum256(body)
+				entry.PayloadHash = "sha256:" + hex.EncodeToString(sum[:])
+				if config.IncludePayload {
+					entry.Payload = redactPayload(c.ContentType(), body, redact)
+				}
+			}
+
+			err = next(c)
+
+			entry.RequestID = c.GetString("requestId")
+			entry.Actor = config.ActorFunc(c)
+			entry.Action = c.Method() + " " + c.Path()
+			if rctx := chi.RouteContext(c.Request.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					entry.Action = c.Method() + " " + pattern
+				}
+				for _, key := range rctx.URLParams.Keys {
+					if key == "*" {
+						continue
+					}
+					if entry.Entity == nil {
+						entry.Entity = make(map[string]string)
+					}
+					entry.Entity[key] = c.Param(key)
+				}
+			}
+			entry.Status = c.StatusCode()
+			if err != nil {
+				entry.Error = err.Error()
+				entry.Status = errorStatus(err)
+			}
+
+			if werr := config.Sink.WriteAudit(c.Context(), entry); werr != nil {
+				log.Printf("nexo: audit: %v", werr)
+			}
+			return err
+		}
+	}
+}
+
+// defaultAuditActor identifies the actor by the principal, then by the
+// BasicAuth username.
+func defaultAuditActor(c *Context) string {
+	switch p := c.Principal().(type) {
+	case string:
+		return p
+	case fmt.Stringer:
+		return p.String()
+	}
+	return c.GetString("username")
+}
+
+// errorStatus returns the status handleError answers a handler error with.
+func errorStatus(err error) int {
+	if _, ok := asValidationErrors(err); ok {
+		return http.StatusUnprocessableEntity
+	}
+	if httpErr, ok := IsHTTPError(err); ok {
+		return httpErr.Code
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// peekBody reads up to limit bytes of the request body and puts them
+// back, so handlers still read the whole body.
+func peekBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit))
+	if err != nil {
+		return nil, err
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	return body, nil
+}
+
+// redactPayload returns a JSON or form body as JSON with the fields in
+// redact replaced, or nil for other bodies.
+func redactPayload(contentType string, body []byte, redact map[string]bool) json.RawMessage {
+	var v any
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		if json.Unmarshal(body, &v) != nil {
+			return nil
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+		form := make(map[string]any, len(values))
+		for key, vals := range values {
+			if len(vals) == 1 {
+				form[key] = vals[0]
+			} else {
+				form[key] = vals
+			}
+		}
+		v = form
+	default:
+		return nil
+	}
+	data, err := json.Marshal(redactValue(v, redact))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redactValue replaces the fields in redact at any depth of v.
+func redactValue(v any, redact map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = "[redacted]"
+			} else {
+				v[key] = redactValue(val, redact)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = redactValue(val, redact)
+		}
+	}
+	return v
+}
+
+// ---------- Audit Sinks ----------
+
+// AuditFileSink appends audit entries to a file as JSON lines.
+type AuditFileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// NewAuditFileSink opens the file at path for appending entries, creating
+// it if needed.
+func NewAuditFileSink(path string) (*AuditFileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditFileSink{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// WriteAudit implements AuditSink. Each entry is flushed to the file
+// before it returns.
+func (s *AuditFileSink) WriteAudit(_ context.Context, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// Close closes the file.
+func (s *AuditFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// AuditWebhookSink posts audit entries as JSON to a URL.
+type AuditWebhookSink struct {
+	// URL receives a POST per entry.
+	URL string
+
+	// Header is added to every request, e.g. an Authorization header.
+	Header http.Header
+
+	// Client sends the requests. Default has a 5 second timeout.
+	Client *http.Client
+}
+
+// WriteAudit implements AuditSink. Responses other than 2xx are errors.
+func (s *AuditWebhookSink) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = auditWebhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook %s: %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+// auditWebhookClient is the default client of AuditWebhookSink.
+var auditWebhookClient = &http.Client{Timeout: 5 * time.Second}
@@ -0,0 +1,138 @@
+package nexo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	var entries []AuditEntry
+	sink := AuditSinkFunc(func(_ context.Context, e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+
+	var got string
+	app := New()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetPrincipal("user:42")
+			return next(c)
+		}
+	})
+	app.Use(AuditWithConfig(AuditConfig{Sink: sink, IncludePayload: true}))
+	app.Put("/api/users/{id}", func(c *Context) error {
+		body, _ := io.ReadAll(c.Request.Body)
+		got = string(body)
+		return c.NoContent()
+	})
+	app.Delete("/api/users/{id}", func(c *Context) error { return Forbidden("not yours") })
+	app.Get("/api/users/{id}", func(c *Context) error { return c.NoContent() })
+	app.Mount()
+
+	body := `{"name":"Ana","password":"hunter2","cards":[{"cvv":"123"}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/users/7", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/users/8", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/9", nil))
+
+	if got != body {
+		t.Errorf("handler read %q, want the whole body", got)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (GET isn't audited)", len(entries))
+	}
+
+	put := entries[0]
+	if put.Actor != "user:42" || put.Action != "PUT /api/users/{id}" || put.Entity["id"] != "7" || put.Status != http.StatusNoContent {
+		t.Errorf("PUT entry = %+v", put)
+	}
+	if !strings.HasPrefix(put.PayloadHash, "sha256:") {
+		t.Errorf("PayloadHash = %q", put.PayloadHash)
+	}
+	if want := `{"cards":[{"cvv":"[redacted]"}],"name":"Ana","password":"[redacted]"}`; string(put.Payload) != want {
+		t.Errorf("Payload = %s, want %s", put.Payload, want)
+	}
+
+	del := entries[1]
+	if del.Entity["id"] != "8" || del.Status != http.StatusForbidden || del.Error != "403: not yours" || del.PayloadHash != "" {
+		t.Errorf("DELETE entry = %+v", del)
+	}
+}
+
+func TestRedactPayload_Form(t *testing.T) {
+	redact := map[string]bool{"token": true}
+	got := redactPayload("application/x-www-form-urlencoded", []byte("email=a%40b.c&Token=abc"), redact)
+	if want := `{"Token":"[redacted]","email":"a@b.c"}`; string(got) != want {
+		t.Errorf("redactPayload() = %s, want %s", got, want)
+	}
+	if got := redactPayload("text/plain", []byte("password"), redact); got != nil {
+		t.Errorf("redactPayload() of a text body = %s, want nil", got)
+	}
+}
+
+func TestAuditFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewAuditFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []string{"POST /orders", "DELETE /orders/{id}"} {
+		if err := sink.WriteAudit(context.Background(), AuditEntry{Action: action}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var actions []string
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, e.Action)
+	}
+	if strings.Join(actions, ", ") != "POST /orders, DELETE /orders/{id}" {
+		t.Errorf("logged actions = %v", actions)
+	}
+}
+
+func TestAuditWebhookSink(t *testing.T) {
+	var received AuditEntry
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		if received.Action == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	sink := &AuditWebhookSink{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer s3cret"}}}
+	if err := sink.WriteAudit(context.Background(), AuditEntry{Action: "POST /orders", Actor: "user:1"}); err != nil {
+		t.Fatal(err)
+	}
+	if received.Actor != "user:1" || auth != "Bearer s3cret" {
+		t.Errorf("webhook received %+v with Authorization %q", received, auth)
+	}
+	if err := sink.WriteAudit(context.Background(), AuditEntry{Action: "fail"}); err == nil {
+		t.Error("a 502 from the webhook wasn't an error")
+	}
+}
@@ -0,0 +1,218 @@
+package nexo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BatchConfig configures the batch endpoint, which runs several requests
+// sent in one, to save mobile clients round trips.
+type BatchConfig struct {
+	// Enabled serves the endpoint under Path.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path is where the endpoint is served (default: /api/_batch).
+	Path string `mapstructure:"path"`
+
+	// MaxRequests caps the requests of one batch (default: 20).
+	MaxRequests int `mapstructure:"max_requests"`
+
+	// Concurrency is how many requests of a batch run at once
+	// (default: 4).
+	Concurrency int `mapstructure:"concurrency"`
+}
+
+// BatchRequest is a request of a batch.
+type BatchRequest struct {
+	// ID is echoed in the response, to match responses to requests.
+	ID string `json:"id,omitempty"`
+
+	// Method is the HTTP method (default: GET).
+	Method string `json:"method,omitempty"`
+
+	// Path is the URL path and query, like /api/users?page=2.
+	Path string `json:"path"`
+
+	// Headers are added to the headers of the batch request, which every
+	// request of the batch shares, like Authorization and Cookie.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is sent as JSON, unless Headers sets another Content-Type and
+	// Body is a string, which is sent as is.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResponse is the response to a request of a batch.
+type BatchResponse struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the response body: JSON responses as is, others as a JSON
+	// string.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// batchSkipHeaders are headers of the batch request its requests don't
+// share: they describe the batch's own body and encoding.
+var batchSkipHeaders = []string{"Content-Type", "Content-Length", "Content-Encoding", "Accept-Encoding"}
+
+// mountBatch registers the batch endpoint when it's enabled (see
+// WithBatch):
+//
+//	POST /api/_batch
+//	[{"id": "me", "path": "/api/me"}, {"id": "feed", "path": "/api/feed?limit=10"}]
+//
+// It responds with one BatchResponse per request, in order.
+func (a *App) mountBatch() {
+	cfg := a.config.Batch
+	path := orDefault(cfg.Path, "/api/_batch")
+	if !cfg.Enabled || a.hasRoute(http.MethodPost, path) {
+		return
+	}
+	a.routeTree.AddRoute(&Route{
+		Method:      http.MethodPost,
+		Pattern:     path,
+		Handler:     a.handleBatch(path),
+		Priority:    CalculatePriority(path),
+		Middlewares: a.batchMiddleware,
+	})
+}
+
+// handleBatch returns the handler of the batch endpoint served at path.
+func (a *App) handleBatch(path string) HandlerFunc {
+	cfg := a.config.Batch
+	maxRequests := cfg.MaxRequests
+	if maxRequests <= 0 {
+		maxRequests = 20
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	return func(c *Context) error {
+		// JSON only, so a cross-site form can't post a batch
+		if !isJSONMediaType(c.ContentType()) {
+			return NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		}
+		data, err := c.RawBody()
+		if err != nil {
+			return bodyReadError(err, "failed to read request body")
+		}
+		var requests []BatchRequest
+		if err := json.Unmarshal(data, &requests); err != nil {
+			return BadRequest("batch body must be a JSON array of requests")
+		}
+		if len(requests) == 0 {
+			return BadRequest("batch has no requests")
+		}
+		if len(requests) > maxRequests {
+			return BadRequest(fmt.Sprintf("batch has %d requests, the limit is %d", len(requests), maxRequests))
+		}
+
+		responses := make([]BatchResponse, len(requests))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, req := range requests {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				responses[i] = a.serveBatchRequest(c.Request, path, req)
+			}()
+		}
+		wg.Wait()
+
+		c.SetHeader("Cache-Control", "no-store")
+		return c.JSON(http.StatusOK, responses)
+	}
+}
+
+// serveBatchRequest runs a request of a batch through the app, sharing the
+// headers and context of the batch request parent.
+func (a *App) serveBatchRequest(parent *http.Request, batchPath string, req BatchRequest) BatchResponse {
+	fail := func(status int, message string) BatchResponse {
+		body, _ := json.Marshal(map[string]any{"error": map[string]any{"code": status, "message": message}})
+		return BatchResponse{ID: req.ID, Status: status, Body: body}
+	}
+
+	method := strings.ToUpper(orDefault(req.Method, http.MethodGet))
+	if !isLocalPath(req.Path) {
+		return fail(http.StatusBadRequest, "path must start with a single /")
+	}
+
+	// Drop the batch's route context, or the router would reuse it
+	ctx := context.WithValue(parent.Context(), chi.RouteCtxKey, nil)
+	body, contentType := batchBody(req)
+	r, err := http.NewRequestWithContext(ctx, method, req.Path, bytes.NewReader(body))
+	if err != nil {
+		return fail(http.StatusBadRequest, "invalid request: "+err.Error())
+	}
+	// Cleaned, so "//api/_batch" or "/api/_batch/" aren't nested either
+	if path.Clean(r.URL.Path) == path.Clean(batchPath) {
+		return fail(http.StatusBadRequest, "batches can't be nested")
+	}
+	r.Header = parent.Header.Clone()
+	for _, name := range batchSkipHeaders {
+		r.Header.Del(name)
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range req.Headers {
+		r.Header.Set(name, value)
+	}
+	r.Host = parent.Host
+	r.RemoteAddr = parent.RemoteAddr
+	r.TLS = parent.TLS
+
+	rec := &responseRecorder{header: make(http.Header)}
+	a.ServeHTTP(rec, r)
+
+	resp := BatchResponse{ID: req.ID, Status: rec.Status()}
+	if len(rec.header) > 0 {
+		resp.Headers = make(map[string]string, len(rec.header))
+		for name := range rec.header {
+			resp.Headers[name] = rec.header.Get(name)
+		}
+	}
+	if out := rec.body.Bytes(); len(out) > 0 {
+		if isJSONMediaType(rec.header.Get("Content-Type")) && json.Valid(out) {
+			resp.Body = bytes.TrimSpace(out)
+		} else {
+			resp.Body, _ = json.Marshal(string(out))
+		}
+	}
+	return resp
+}
+
+// batchBody returns the body of a request of a batch and its content type.
+// A JSON string body with a non-JSON Content-Type header is sent as the
+// string's text.
+func batchBody(req BatchRequest) ([]byte, string) {
+	if len(req.Body) == 0 || string(req.Body) == "null" {
+		return nil, ""
+	}
+	for name, value := range req.Headers {
+		if !strings.EqualFold(name, "Content-Type") || isJSONMediaType(value) {
+			continue
+		}
+		var text string
+		if json.Unmarshal(req.Body, &text) == nil {
+			return []byte(text), ""
+		}
+	}
+	return req.Body, "application/json"
+}
@@ -0,0 +1,157 @@
+package nexo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func batchRequest(app *App, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/_batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestBatch(t *testing.T) {
+	auth := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Header("Authorization") != "Bearer token" {
+				return Unauthorized("missing token")
+			}
+			return next(c)
+		}
+	}
+	app := New(WithBatch())
+	app.Get("/api/users/{id}", auth(func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id"), "lang": c.Header("Accept-Language")})
+	}))
+	app.Post("/api/echo", auth(func(c *Context) error {
+		var in struct {
+			Name string `json:"name"`
+		}
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, in)
+	}))
+	app.Post("/api/text", func(c *Context) error {
+		data, _ := c.RawBody()
+		return c.String(http.StatusOK, c.Header("Content-Type")+": "+string(data))
+	})
+	app.Mount()
+
+	w := batchRequest(app, `[
+		{"id": "user", "path": "/api/users/42", "headers": {"Accept-Language": "es"}},
+		{"id": "echo", "method": "post", "path": "/api/echo", "body": {"name": "Ada"}},
+		{"id": "text", "method": "POST", "path": "/api/text", "headers": {"Content-Type": "text/plain"}, "body": "hi"},
+		{"id": "missing", "path": "/api/nope"},
+		{"id": "nested", "method": "POST", "path": "/api/_batch"},
+		{"id": "nested slashes", "method": "POST", "path": "/api//_batch/"},
+		{"id": "nested dots", "method": "POST", "path": "/api/x/../_batch?x=1"},
+		{"id": "relative", "path": "api/users/1"},
+		{"id": "host", "path": "//api/users/1"}
+	]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch = %d: %s", w.Code, w.Body)
+	}
+	var got []BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id     string
+		status int
+		body   string
+	}{
+		{"user", http.StatusOK, `{"id":"42","lang":"es"}`},
+		{"echo", http.StatusCreated, `{"name":"Ada"}`},
+		{"text", http.StatusOK, `"text/plain: hi"`},
+		{"missing", http.StatusNotFound, ""},
+		{"nested", http.StatusBadRequest, ""},
+		{"nested slashes", http.StatusBadRequest, ""},
+		{"nested dots", http.StatusBadRequest, ""},
+		{"relative", http.StatusBadRequest, ""},
+		{"host", http.StatusBadRequest, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d responses, want %d: %s", len(got), len(want), w.Body)
+	}
+	for i, tt := range want {
+		if got[i].ID != tt.id || got[i].Status != tt.status {
+			t.Errorf("response %d = %s %d, want %s %d", i, got[i].ID, got[i].Status, tt.id, tt.status)
+		}
+		if tt.body != "" && string(got[i].Body) != tt.body {
+			t.Errorf("response %s body = %s, want %s", tt.id, got[i].Body, tt.body)
+		}
+	}
+	if ct := got[0].Headers["Content-Type"]; !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("response headers = %v", got[0].Headers)
+	}
+}
+
+func TestBatch_Limits(t *testing.T) {
+	var running, peak atomic.Int32
+	app := New(WithConfig(&Config{Batch: BatchConfig{Enabled: true, MaxRequests: 5, Concurrency: 2}}))
+	app.Get("/api/slow", func(c *Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return c.NoContent()
+	})
+	app.Mount()
+
+	w := batchRequest(app, `{"path": "/api/slow"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("malformed batch = %d, want 400", w.Code)
+	}
+
+	w = batchRequest(app, `[`+strings.TrimSuffix(strings.Repeat(`{"path": "/api/slow"},`, 5), ",")+`]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch = %d: %s", w.Code, w.Body)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d requests ran at once, want at most 2", p)
+	}
+
+	w = batchRequest(app, `[`+strings.TrimSuffix(strings.Repeat(`{"path": "/api/slow"},`, 6), ",")+`]`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("batch over MaxRequests = %d, want 400", w.Code)
+	}
+	if w = batchRequest(app, `[]`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty batch = %d, want 400", w.Code)
+	}
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		r := httptest.NewRequest(http.MethodPost, "/api/_batch", strings.NewReader(`[{"path": "/api/slow"}]`))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("batch sent as %q = %d, want 415", contentType, w.Code)
+		}
+	}
+}
+
+func TestBatch_Disabled(t *testing.T) {
+	app := New()
+	app.Mount()
+	if w := batchRequest(app, `[{"path": "/"}]`); w.Code != http.StatusNotFound && w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("batch without WithBatch = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,374 @@
+package nexo
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BindError reports a query, form or path value that can't be bound to a
+// struct field. Bind* wrap it in a 400 HTTPError; use errors.As to get it.
+type BindError struct {
+	// Field is the struct field, like "Page".
+	Field string
+
+	// Source is where the value came from: "query", "form" or "path".
+	Source string
+
+	// Key is the parameter name, like "page".
+	Key string
+
+	// Value is the rejected value.
+	Value string
+
+	// Err describes why the value was rejected.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BindError) Error() string {
+	return fmt.Sprintf("invalid %s parameter %q (field %s): %q %v", e.Source, e.Key, e.Field, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// BindQuery binds the query string into the struct pointed to by v. Fields
+// are matched by their `query` tag, then their `json` tag, then their name;
+// a tag of "-" skips the field.
+//
+// Besides strings, numbers and bools, fields can be:
+//   - slices, from repeated keys (?tag=a&tag=b, or tag[]=a&tag[]=b)
+//   - maps with string keys, from bracketed keys (?filter[status]=open)
+//   - time.Time, parsed with the `layout` tag (default RFC 3339)
+//   - time.Duration, like "1h30m"
+//   - pointers, left nil when the key is absent
+//   - any encoding.TextUnmarshaler, like uuid.UUID
+//
+// Fields without a value keep their current value, so defaults can be set
+// before binding. A value that doesn't parse returns a 400 naming the field.
+// The bound fields are then checked against their `validate` tags (see
+// Context.Validate); fields tagged only for another source, like `path`,
+// are left to that source's Bind.
+//
+// Example:
+//
+//	type ListParams struct {
+//	    Page   int               `query:"page"`
+//	    Tags   []string          `query:"tag"`
+//	    Filter map[string]string `query:"filter"`
+//	    Since  time.Time         `query:"since" layout:"2006-01-02"`
+//	}
+//
+//	params := ListParams{Page: 1}
+//	if err := c.BindQuery(&params); err != nil {
+//	    return err
+//	}
+func (c *Context) BindQuery(v any) error {
+	if err := bindValues(v, "query", c.queryValues()); err != nil {
+		return err
+	}
+	return c.validate(v, "query")
+}
+
+// BindForm binds URL-encoded or multipart form values into the struct
+// pointed to by v, matching fields by their `form` tag. See BindQuery for
+// the supported field types.
+func (c *Context) BindForm(v any) error {
+	if err := c.parseForm(); err != nil {
+		return bodyReadError(err, "invalid form data")
+	}
+	if err := bindValues(v, "form", c.Request.PostForm); err != nil {
+		return err
+	}
+	return c.validate(v, "form")
+}
+
+// BindPath binds the URL parameters of the route into the struct pointed to
+// by v, matching fields by their `path` tag. A slice field gets the segments
+// of a catch-all parameter. See BindQuery for the supported field types.
+//
+// Example:
+//
+//	// app/orgs/[org]/users/[id]/route.go
+//	var p struct {
+//	    Org string    `path:"org"`
+//	    ID  uuid.UUID `path:"id"`
+//	}
+//	if err := c.BindPath(&p); err != nil {
+//	    return err
+//	}
+func (c *Context) BindPath(v any) error {
+	values := url.Values{}
+	if rctx := chi.RouteContext(c.Request.Context()); rctx != nil {
+		for i, key := range rctx.URLParams.Keys {
+			values.Set(key, rctx.URLParams.Values[i])
+		}
+	}
+	for key, value := range c.params {
+		values.Set(key, value)
+	}
+	if err := bindValues(v, "path", values); err != nil {
+		return err
+	}
+	return c.validate(v, "path")
+}
+
+// parseForm parses the request body as a multipart or URL-encoded form.
+// URL-encoded bodies are buffered through RawBody first, so they can still
+// be read afterwards; multipart bodies are streamed and consumed.
+func (c *Context) parseForm() error {
+	mediaType, _, _ := mime.ParseMediaType(c.Header("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return c.Request.ParseMultipartForm(32 << 20)
+	}
+	if c.Request.Body != nil && c.Request.PostForm == nil {
+		if _, err := c.RawBody(); err != nil {
+			return err
+		}
+	}
+	if err := c.Request.ParseForm(); err != nil {
+		return err
+	}
+	// ParseForm drained the rewound body; rewind it once more for later readers.
+	if c.rawBody != nil {
+		_, _ = c.RawBody()
+	}
+	return nil
+}
+
+// isFormContentType reports whether the request body is a form.
+func (c *Context) isFormContentType() bool {
+	mediaType, _, _ := mime.ParseMediaType(c.Header("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// bindValues binds values into the struct pointed to by v, matching fields
+// by their source tag.
+func bindValues(v any, source string, values url.Values) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("nexo: bind %s: want a pointer to a struct, got %T", source, v)
+	}
+	return bindStruct(rv.Elem(), source, values)
+}
+
+// bindStruct binds the fields of the struct rv.
+func bindStruct(rv reflect.Value, source string, values url.Values) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, ok := bindKey(field, source)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+
+		// Embedded structs without a tag of their own are flattened
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(source) == "" {
+			if err := bindStruct(fv, source, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f := &bindField{name: field.Name, source: source, key: key, layout: field.Tag.Get("layout")}
+		if err := f.bind(fv, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindKey returns the parameter name of field, and false when the field is
+// skipped.
+func bindKey(field reflect.StructField, source string) (string, bool) {
+	for _, tag := range []string{source, "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, true
+}
+
+// bindField binds the values of one parameter to a struct field.
+type bindField struct {
+	name   string
+	source string
+	key    string
+	layout string
+}
+
+// bind sets fv from the values of the field's key.
+func (f *bindField) bind(fv reflect.Value, values url.Values) error {
+	t := fv.Type()
+
+	switch {
+	case t.Kind() == reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return f.unsupported(t)
+		}
+		prefix := f.key + "["
+		for key, vals := range values {
+			if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "]") || len(vals) == 0 {
+				continue
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(t))
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := f.set(elem, vals[len(vals)-1]); err != nil {
+				return err
+			}
+			mapKey := reflect.ValueOf(key[len(prefix) : len(key)-1]).Convert(t.Key())
+			fv.SetMapIndex(mapKey, elem)
+		}
+		return nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !reflect.PointerTo(t).Implements(textUnmarshalerType):
+		vals := slices.Concat(values[f.key], values[f.key+"[]"])
+		if f.source == "path" && len(vals) == 1 {
+			vals = strings.Split(vals[0], "/") // the segments of a catch-all parameter
+		}
+		if len(vals) == 0 {
+			return nil
+		}
+		slice := reflect.MakeSlice(t, len(vals), len(vals))
+		for i, s := range vals {
+			if err := f.set(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	vals := values[f.key]
+	if len(vals) == 0 {
+		return nil
+	}
+	return f.set(fv, vals[0])
+}
+
+// set parses s into fv.
+func (f *bindField) set(fv reflect.Value, s string) error {
+	t := fv.Type()
+	if t.Kind() == reflect.Pointer {
+		ptr := reflect.New(t.Elem())
+		if err := f.set(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+
+	// time.Time implements TextUnmarshaler, but only for RFC 3339
+	if t == timeType {
+		layout := orDefault(f.layout, time.RFC3339)
+		tm, err := time.Parse(layout, s)
+		if err != nil {
+			return f.invalid(s, fmt.Errorf("is not a time in the layout %s", layout))
+		}
+		fv.Set(reflect.ValueOf(tm))
+		return nil
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return f.invalid(s, errors.New("is not a duration"))
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return f.invalid(s, fmt.Errorf("is not a valid %s: %w", t.Name(), err))
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		if s == "on" { // an HTML checkbox
+			s = "true"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return f.invalid(s, errors.New("is not a boolean"))
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return f.invalid(s, numError(err, "an integer"))
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return f.invalid(s, numError(err, "a non-negative integer"))
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return f.invalid(s, numError(err, "a number"))
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return f.unsupported(t)
+		}
+		fv.SetBytes([]byte(s))
+	default:
+		return f.unsupported(t)
+	}
+	return nil
+}
+
+// invalid returns the 400 for a value that doesn't parse.
+func (f *bindField) invalid(value string, err error) error {
+	bindErr := &BindError{Field: f.name, Source: f.source, Key: f.key, Value: value, Err: err}
+	return NewHTTPErrorWithCause(http.StatusBadRequest, bindErr.Error(), bindErr)
+}
+
+// unsupported returns the error for a field type the binder can't set, a
+// bug in the handler rather than a bad request.
+func (f *bindField) unsupported(t reflect.Type) error {
+	return fmt.Errorf("nexo: bind %s parameter %q: field %s has unsupported type %s", f.source, f.key, f.name, t)
+}
+
+// numError describes a strconv error for a value that should be want.
+func numError(err error, want string) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return errors.New("is out of range")
+	}
+	return fmt.Errorf("is not %s", want)
+}
@@ -0,0 +1,188 @@
+package nexo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hexID is a TextUnmarshaler, like uuid.UUID.
+type hexID [2]byte
+
+func (id *hexID) UnmarshalText(text []byte) error {
+	if _, err := fmt.Sscanf(string(text), "%02x%02x", &id[0], &id[1]); err != nil || len(text) != 4 {
+		return errors.New("want 4 hex digits")
+	}
+	return nil
+}
+
+type bindParams struct {
+	Page    int               `query:"page" form:"page"`
+	Tags    []string          `query:"tag"`
+	IDs     []int             `query:"id"`
+	Filter  map[string]string `query:"filter"`
+	Since   time.Time         `query:"since" layout:"2006-01-02"`
+	At      time.Time         `query:"at"`
+	TTL     time.Duration     `query:"ttl"`
+	Limit   *int              `query:"limit"`
+	Key     hexID             `query:"key"`
+	Name    string            `json:"name"`
+	Active  bool              `query:"active" form:"active"`
+	Ignored string            `query:"-"`
+	hidden  string
+}
+
+func TestBindQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?"+url.Values{
+		"page":           {"3"},
+		"tag":            {"go", "web"},
+		"id[]":           {"1", "2"},
+		"filter[status]": {"open"},
+		"filter[owner]":  {"me"},
+		"since":          {"2024-05-01"},
+		"at":             {"2024-05-01T10:00:00Z"},
+		"ttl":            {"90s"},
+		"limit":          {"10"},
+		"key":            {"beef"},
+		"name":           {"ada"},
+		"active":         {"true"},
+		"Ignored":        {"x"},
+		"hidden":         {"x"},
+	}.Encode(), nil)
+	c := NewContext(httptest.NewRecorder(), req)
+
+	var got bindParams
+	if err := c.BindQuery(&got); err != nil {
+		t.Fatal(err)
+	}
+	limit := 10
+	want := bindParams{
+		Page:   3,
+		Tags:   []string{"go", "web"},
+		IDs:    []int{1, 2},
+		Filter: map[string]string{"status": "open", "owner": "me"},
+		Since:  time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		At:     time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		TTL:    90 * time.Second,
+		Limit:  &limit,
+		Key:    hexID{0xbe, 0xef},
+		Name:   "ada",
+		Active: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BindQuery() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestBindQuery_KeepsDefaults(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	got := bindParams{Page: 1}
+	if err := c.BindQuery(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Page != 1 || got.Limit != nil || got.Tags != nil {
+		t.Errorf("BindQuery() without values = %+v", got)
+	}
+}
+
+func TestBindQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+		want  string
+	}{
+		{query: "page=abc", field: "Page", want: `invalid query parameter "page" (field Page): "abc" is not an integer`},
+		{query: "id=1&id=x", field: "IDs", want: `"x" is not an integer`},
+		{query: "since=May", field: "Since", want: "is not a time in the layout 2006-01-02"},
+		{query: "ttl=soon", field: "TTL", want: "is not a duration"},
+		{query: "key=zz", field: "Key", want: "is not a valid hexID"},
+		{query: "active=maybe", field: "Active", want: "is not a boolean"},
+		{query: "limit=99999999999999999999", field: "Limit", want: "is out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+			err := c.BindQuery(&bindParams{})
+
+			httpErr, ok := IsHTTPError(err)
+			if !ok || httpErr.Code != http.StatusBadRequest {
+				t.Fatalf("err = %v, want a 400", err)
+			}
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) || bindErr.Field != tt.field {
+				t.Errorf("err = %v, want a BindError for field %s", err, tt.field)
+			}
+			if !strings.Contains(httpErr.Message, tt.want) {
+				t.Errorf("message = %q, want %q", httpErr.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindQuery_Unsupported(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?ch=1", nil))
+	var v struct {
+		Ch chan int `query:"ch"`
+	}
+	err := c.BindQuery(&v)
+	if err == nil || !strings.Contains(err.Error(), "field Ch has unsupported type chan int") {
+		t.Errorf("err = %v", err)
+	}
+	if _, ok := IsHTTPError(err); ok {
+		t.Error("unsupported field type reported as a bad request")
+	}
+	if err := c.BindQuery(v); err == nil {
+		t.Error("BindQuery accepted a struct value")
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	body := url.Values{"page": {"2"}, "active": {"on"}, "name": {"ada"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/?page=9", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := NewContext(httptest.NewRecorder(), req)
+
+	// Bind dispatches on the content type
+	var got bindParams
+	if err := c.Bind(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Page != 2 || !got.Active || got.Name != "ada" {
+		t.Errorf("Bind() form = %+v", got)
+	}
+}
+
+func TestBindPath(t *testing.T) {
+	var got struct {
+		Org  string   `path:"org"`
+		ID   int      `path:"id"`
+		Slug []string `path:"slug"`
+	}
+	app := New()
+	app.Get("/orgs/{org}/users/{id}/docs/*", func(c *Context) error {
+		c.SetParam("slug", c.Param("*"))
+		return c.BindPath(&got)
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/acme/users/7/docs/guides/intro", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if got.Org != "acme" || got.ID != 7 || !reflect.DeepEqual(got.Slug, []string{"guides", "intro"}) {
+		t.Errorf("BindPath() = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/acme/users/seven/docs/x", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "field ID") {
+		t.Errorf("invalid path param: status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,59 @@
+package nexo
+
+import "net/http"
+
+// Validator is implemented by request body types that validate themselves.
+// WithBody calls Validate after decoding and responds 400 if it fails.
+type Validator interface {
+	Validate() error
+}
+
+// WithBody adapts a handler that takes a typed request body to a HandlerFunc.
+// The JSON request body is decoded into T and validated before h runs:
+// decoding failures respond with 400 Bad Request, failed `validate` tags
+// with a 422 listing the fields (see Context.Validate), and a failed
+// Validate method with 400.
+//
+// Generated route registrations use WithBody for route.go handlers with the
+// signature func(c *nexo.Context, body T) error.
+//
+// Example:
+//
+//	type PostBody struct {
+//	    Name  string `json:"name"`
+//	    Email string `json:"email"`
+//	}
+//
+//	func (b PostBody) Validate() error {
+//	    if b.Email == "" {
+//	        return errors.New("email is required")
+//	    }
+//	    return nil
+//	}
+//
+//	func Post(c *nexo.Context, body PostBody) error {
+//	    return c.JSON(201, createUser(body.Name, body.Email))
+//	}
+func WithBody[T any](h func(c *Context, body T) error) HandlerFunc {
+	return func(c *Context) error {
+		var body T
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+
+		if err := validateBody(&body); err != nil {
+			return NewHTTPErrorWithCause(http.StatusBadRequest, err.Error(), err)
+		}
+
+		return h(c, body)
+	}
+}
+
+// validateBody runs Validate if the body implements Validator with either
+// a value or pointer receiver.
+func validateBody(body any) error {
+	if v, ok := body.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package nexo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testBody struct {
+	Name string `json:"name"`
+}
+
+func (b testBody) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type unvalidatedBody struct {
+	Count int `json:"count"`
+}
+
+func TestWithBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantName   string
+	}{
+		{"valid body", `{"name":"nexo"}`, http.StatusOK, "nexo"},
+		{"invalid JSON", `{"name":`, http.StatusBadRequest, ""},
+		{"validation failure", `{"name":""}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			called := false
+			handler := WithBody(func(c *Context, body testBody) error {
+				called = true
+				got = body.Name
+				return c.NoContent()
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			err := handler(NewContext(httptest.NewRecorder(), req))
+
+			if tt.wantStatus == http.StatusOK {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tt.wantName {
+					t.Errorf("body.Name = %q, want %q", got, tt.wantName)
+				}
+				return
+			}
+
+			httpErr, ok := IsHTTPError(err)
+			if !ok || httpErr.Code != tt.wantStatus {
+				t.Fatalf("expected HTTPError %d, got %v", tt.wantStatus, err)
+			}
+			if called {
+				t.Error("handler should not run when the body is rejected")
+			}
+		})
+	}
+}
+
+func TestWithBody_ValidationMessage(t *testing.T) {
+	handler := WithBody(func(c *Context, body testBody) error { return nil })
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	err := handler(NewContext(httptest.NewRecorder(), req))
+
+	httpErr, ok := IsHTTPError(err)
+	if !ok || httpErr.Message != "name is required" {
+		t.Errorf("expected validation message, got %v", err)
+	}
+}
+
+func TestWithBody_WithoutValidator(t *testing.T) {
+	var got int
+	handler := WithBody(func(c *Context, body unvalidatedBody) error {
+		got = body.Count
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"count":3}`))
+	if err := handler(NewContext(httptest.NewRecorder(), req)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 3 {
+		t.Errorf("body.Count = %d, want 3", got)
+	}
+}
+
+func TestWithBody_Routed(t *testing.T) {
+	app := New()
+	app.Post("/users", WithBody(func(c *Context, body testBody) error {
+		return c.JSON(http.StatusCreated, map[string]string{"name": body.Name})
+	}))
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"a"}`)))
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", w.Code)
+	}
+}
@@ -0,0 +1,283 @@
+package nexo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// statusWriter is implemented by response writers that track the status
+// of the response written through them.
+type statusWriter interface {
+	Status() int
+	Written() bool
+}
+
+// errHijackBuffered is returned when hijacking a connection with a
+// buffered response pending.
+var errHijackBuffered = errors.New("nexo: cannot hijack a connection with a buffered response")
+
+// bufferedWriter holds the status and body of a response in memory until it
+// is committed, so headers can still be changed after the handler writes.
+// Once committed (by Flush or at the end of the request) it streams writes
+// straight through to the underlying writer.
+type bufferedWriter struct {
+	w           http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	committed   bool
+}
+
+// Header returns the underlying header map, which is sent on commit.
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.w.Header()
+}
+
+// WriteHeader records the status code until the response is committed.
+func (bw *bufferedWriter) WriteHeader(code int) {
+	if bw.committed {
+		bw.w.WriteHeader(code)
+		return
+	}
+	if !bw.wroteHeader {
+		bw.status = code
+		bw.wroteHeader = true
+	}
+}
+
+// Write buffers b until the response is committed.
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.committed {
+		return bw.w.Write(b)
+	}
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+	return bw.buf.Write(b)
+}
+
+// commit writes the buffered status and body and switches to streaming.
+// Nothing is written if the handler produced no response.
+func (bw *bufferedWriter) commit() error {
+	if bw.committed {
+		return nil
+	}
+	bw.committed = true
+	if !bw.wroteHeader {
+		return nil
+	}
+	bw.w.WriteHeader(bw.status)
+	_, err := bw.w.Write(bw.buf.Bytes())
+	bw.buf.Reset()
+	return err
+}
+
+// discard drops the buffered response, so another one can be written.
+// It reports false if the response was already committed.
+func (bw *bufferedWriter) discard() bool {
+	if bw.committed {
+		return false
+	}
+	bw.buf.Reset()
+	bw.status = 0
+	bw.wroteHeader = false
+	return true
+}
+
+// FlushError commits the buffered response and flushes the underlying writer.
+func (bw *bufferedWriter) FlushError() error {
+	if err := bw.commit(); err != nil {
+		return err
+	}
+	return http.NewResponseController(bw.w).Flush()
+}
+
+// Flush implements http.Flusher.
+func (bw *bufferedWriter) Flush() {
+	_ = bw.FlushError()
+}
+
+// Hijack implements http.Hijacker. It fails while a buffered response is pending.
+func (bw *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if bw.wroteHeader && !bw.committed {
+		return nil, nil, errHijackBuffered
+	}
+	return http.NewResponseController(bw.w).Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (bw *bufferedWriter) Unwrap() http.ResponseWriter {
+	return bw.w
+}
+
+// Status returns the response status code (200 until one is written).
+func (bw *bufferedWriter) Status() int {
+	if !bw.wroteHeader {
+		return http.StatusOK
+	}
+	return bw.status
+}
+
+// Written reports whether the handler has written a response, committed or not.
+func (bw *bufferedWriter) Written() bool {
+	return bw.wroteHeader
+}
+
+// ---------- Context Buffering ----------
+
+// Buffer switches the response to buffered mode: the status and body are
+// held in memory until the request completes (or Flush is called), so
+// middleware can still change headers, or rewrite the body, after the
+// handler has written. It has no effect once the response has been written.
+//
+// Example (an ETag middleware):
+//
+//	return func(c *nexo.Context) error {
+//	    c.Buffer()
+//	    if err := next(c); err != nil {
+//	        return err
+//	    }
+//	    c.SetHeader("ETag", etagOf(c.BufferedBody()))
+//	    return nil
+//	}
+func (c *Context) Buffer() {
+	if c.buffer != nil || c.Written() {
+		return
+	}
+	c.buffer = &bufferedWriter{w: c.Response}
+	c.Response = c.buffer
+}
+
+// Buffered reports whether the response is buffered and not yet committed.
+func (c *Context) Buffered() bool {
+	return c.buffer != nil && !c.buffer.committed
+}
+
+// BufferedBody returns the buffered response body, or nil if the response
+// isn't buffered or has been committed.
+func (c *Context) BufferedBody() []byte {
+	if !c.Buffered() {
+		return nil
+	}
+	return c.buffer.buf.Bytes()
+}
+
+// SetBufferedBody replaces the buffered response body, e.g. with a
+// compressed version. It reports false if the response isn't buffered.
+func (c *Context) SetBufferedBody(body []byte) bool {
+	if !c.Buffered() {
+		return false
+	}
+	c.buffer.buf.Reset()
+	c.buffer.buf.Write(body)
+	return true
+}
+
+// Flush sends any buffered response and flushes the connection, switching a
+// buffered response to streaming. It returns http.ErrNotSupported if the
+// underlying writer can't flush.
+func (c *Context) Flush() error {
+	return http.NewResponseController(c.Response).Flush()
+}
+
+// Hijack takes over the underlying connection, e.g. for a custom protocol
+// upgrade. The response counts as written afterwards.
+func (c *Context) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(c.Response).Hijack()
+	if err == nil {
+		c.written = true
+	}
+	return conn, rw, err
+}
+
+// commitBuffer sends a pending buffered response. The router calls it once
+// the handler chain has returned.
+func (c *Context) commitBuffer() {
+	if c.buffer != nil {
+		_ = c.buffer.commit()
+	}
+}
+
+// ---------- Response Recording ----------
+
+// responseRecorder records a response in memory, for middleware that
+// shares or caches responses.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns the recorded header map.
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the first status code.
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+// Write records b.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// Status returns the recorded status (200 until one is written).
+func (r *responseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Written reports whether the handler has written a response.
+func (r *responseRecorder) Written() bool {
+	return r.status != 0
+}
+
+// recordResponse runs next with its response recorded instead of written,
+// and restores c's response writer afterwards, even if next panics. The
+// recorded status is zero when next wrote nothing.
+func recordResponse(c *Context, next HandlerFunc) (*responseRecorder, error) {
+	rec := &responseRecorder{header: make(http.Header)}
+	response, buffer, written, status := c.Response, c.buffer, c.written, c.status
+	c.Response = rec
+	defer func() {
+		c.Response, c.buffer, c.written, c.status = response, buffer, written, status
+	}()
+
+	err := next(c)
+	if c.buffer != buffer {
+		// The handler buffered its response into the recorder
+		_ = c.buffer.commit()
+	}
+	return rec, err
+}
+
+// writeRecorded writes a recorded response to c. Set-Cookie headers are
+// only written when cookies is true, so a response recorded for one client
+// never sets another's cookies.
+func writeRecorded(c *Context, status int, header http.Header, body []byte, cookies bool) {
+	dst := c.Response.Header()
+	for key, values := range header {
+		if !cookies && key == "Set-Cookie" {
+			continue
+		}
+		dst[key] = append([]string(nil), values...)
+	}
+	c.Response.WriteHeader(status)
+	c.written = true
+	c.status = status
+	if c.Request.Method != http.MethodHead {
+		_, _ = c.Response.Write(body)
+	}
+}
@@ -0,0 +1,157 @@
+package nexo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// serveRoute mounts handler at GET / with the given middleware and serves one request.
+func serveRoute(handler HandlerFunc, middlewares ...MiddlewareFunc) *httptest.ResponseRecorder {
+	tree := NewRouteTree()
+	tree.AddRoute(&Route{Pattern: "/", Method: http.MethodGet, Handler: handler})
+	router := chi.NewRouter()
+	tree.Mount(router, middlewares)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestBuffer_HeadersAfterWrite(t *testing.T) {
+	etag := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if err := next(c); err != nil {
+				return err
+			}
+			if !c.Written() || c.StatusCode() != http.StatusCreated {
+				t.Errorf("Written() = %v, StatusCode() = %d; want true, 201", c.Written(), c.StatusCode())
+			}
+			c.SetHeader("ETag", `"`+string(c.BufferedBody())+`"`)
+			return nil
+		}
+	}
+
+	rec := serveRoute(func(c *Context) error {
+		return c.String(http.StatusCreated, "v1")
+	}, Buffer(), etag)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("ETag"); got != `"v1"` {
+		t.Errorf("ETag = %q, want %q", got, `"v1"`)
+	}
+	if rec.Body.String() != "v1" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "v1")
+	}
+}
+
+func TestBuffer_SetBufferedBody(t *testing.T) {
+	rewrite := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			if !c.SetBufferedBody([]byte("rewritten")) {
+				t.Error("SetBufferedBody() = false for a buffered response")
+			}
+			return err
+		}
+	}
+
+	rec := serveRoute(func(c *Context) error {
+		return c.String(http.StatusOK, "original")
+	}, Buffer(), rewrite)
+
+	if rec.Body.String() != "rewritten" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "rewritten")
+	}
+}
+
+func TestBuffer_ErrorReplacesResponse(t *testing.T) {
+	rec := serveRoute(func(c *Context) error {
+		_ = c.String(http.StatusOK, "partial")
+		return NewHTTPError(http.StatusConflict, "conflict")
+	}, Buffer())
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if body := rec.Body.String(); body == "partial" || len(body) == 0 {
+		t.Errorf("body = %q, want the error response", body)
+	}
+}
+
+func TestBuffer_FlushStreams(t *testing.T) {
+	rec := serveRoute(func(c *Context) error {
+		_ = c.String(http.StatusOK, "chunk1")
+		if err := c.Flush(); err != nil {
+			return err
+		}
+		if c.Buffered() {
+			t.Error("Buffered() = true after Flush")
+		}
+		if !bodySent(c) {
+			t.Error("expected body to be sent on Flush")
+		}
+		c.SetHeader("X-Late", "ignored")
+		_, err := c.Response.Write([]byte("chunk2"))
+		return err
+	}, Buffer())
+
+	if rec.Body.String() != "chunk1chunk2" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "chunk1chunk2")
+	}
+	if !rec.Flushed {
+		t.Error("underlying writer was not flushed")
+	}
+	if rec.Result().Header.Get("X-Late") != "" {
+		t.Error("header set after Flush should not be sent")
+	}
+}
+
+// bodySent reports whether the writer behind c's buffer has received the body.
+func bodySent(c *Context) bool {
+	bw := c.Response.(*bufferedWriter)
+	tracker, ok := bw.w.(*responseWriter)
+	return ok && tracker.Size() > 0
+}
+
+func TestBuffer_HijackPending(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Buffer()
+	_ = c.String(http.StatusOK, "pending")
+
+	if _, _, err := c.Hijack(); !errors.Is(err, errHijackBuffered) {
+		t.Errorf("Hijack() error = %v, want %v", err, errHijackBuffered)
+	}
+}
+
+func TestContext_WrittenTracksDirectWrites(t *testing.T) {
+	rec := serveRoute(func(c *Context) error {
+		if c.Written() {
+			t.Error("Written() = true before writing")
+		}
+		http.Error(c.Response, "teapot", http.StatusTeapot)
+		if !c.Written() || c.StatusCode() != http.StatusTeapot {
+			t.Errorf("Written() = %v, StatusCode() = %d; want true, 418", c.Written(), c.StatusCode())
+		}
+		return errors.New("ignored: response already written")
+	})
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
+
+func TestContext_FlushNotSupported(t *testing.T) {
+	// A writer without Flush support
+	w := struct{ http.ResponseWriter }{httptest.NewRecorder()}
+	c := NewContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := c.Flush(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Flush() error = %v, want http.ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,467 @@
+package nexo
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Cache is a key-value store with expiring entries, shared by the response
+// cache, the rate limiter and app code. Configure one backend for the app
+// (WithCache or cache: in nexo.yaml) and every part of it uses it, so with
+// Redis all instances of the app share state.
+type Cache interface {
+	// Get returns the value of key, and false if it is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key. A ttl of zero keeps it until evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// TTL returns the time left before key expires, and false if it is
+	// missing. Keys without expiry have a TTL of zero.
+	TTL(ctx context.Context, key string) (time.Duration, bool, error)
+}
+
+// CacheCounter is implemented by caches that increment counters atomically.
+// The rate limiter uses it to count requests.
+type CacheCounter interface {
+	// Incr adds one to the counter at key and returns the new value. A new
+	// counter starts at 1 and expires after ttl.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// CacheStats is a snapshot of a cache's counters, as shown by the admin
+// dashboard.
+type CacheStats struct {
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+// CacheStatsReporter is implemented by caches that count their hits and
+// misses.
+type CacheStatsReporter interface {
+	Stats() CacheStats
+}
+
+// CacheConfig selects the app's cache backend, under cache: in nexo.yaml.
+type CacheConfig struct {
+	// Driver is "memory" (default) or "redis".
+	Driver string `mapstructure:"driver"`
+
+	// URL is the Redis URL, like redis://:password@localhost:6379/0.
+	// Empty reads REDIS_URL.
+	URL string `mapstructure:"url"`
+
+	// MaxEntries bounds the memory cache (default: 10000).
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// OpenCache creates the cache backend selected by config.
+func OpenCache(config CacheConfig) (Cache, error) {
+	switch config.Driver {
+	case "", "memory":
+		return NewMemoryCache(config.MaxEntries), nil
+	case "redis":
+		return NewRedisCache(orDefault(config.URL, os.Getenv("REDIS_URL")))
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q (use memory or redis)", config.Driver)
+	}
+}
+
+// openAppCache opens the cache configured for the app, falling back to a
+// memory cache when the configuration is invalid.
+func openAppCache(config CacheConfig) Cache {
+	cache, err := OpenCache(config)
+	if err != nil {
+		log.Printf("nexo: %v; using the memory cache", err)
+		return NewMemoryCache(config.MaxEntries)
+	}
+	return cache
+}
+
+var (
+	defaultCacheOnce sync.Once
+	defaultCache     Cache
+)
+
+// Cache returns the app's cache backend (see WithCache).
+func (c *Context) Cache() Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	// Contexts created outside an app share a process-wide memory cache
+	defaultCacheOnce.Do(func() { defaultCache = NewMemoryCache(0) })
+	return defaultCache
+}
+
+// ---------- Typed Helpers ----------
+
+// CacheGet returns the JSON-encoded value of key decoded as a T, and false
+// if key is missing.
+//
+// Example:
+//
+//	user, ok, err := nexo.CacheGet[User](ctx, c.Cache(), "user:"+id)
+func CacheGet[T any](ctx context.Context, cache Cache, key string) (T, bool, error) {
+	var value T
+	data, ok, err := cache.Get(ctx, key)
+	if err != nil || !ok {
+		return value, false, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("cache: decode %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// CacheSet stores value under key, JSON-encoded.
+func CacheSet[T any](ctx context.Context, cache Cache, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: encode %s: %w", key, err)
+	}
+	return cache.Set(ctx, key, data, ttl)
+}
+
+// CacheRemember returns the cached value of key, or calls fn and caches its
+// result for ttl when key is missing. Errors from fn aren't cached.
+//
+// Example:
+//
+//	posts, err := nexo.CacheRemember(ctx, c.Cache(), "posts:recent", time.Minute,
+//	    func() ([]Post, error) { return db.RecentPosts(ctx) })
+func CacheRemember[T any](ctx context.Context, cache Cache, key string, ttl time.Duration, fn func() (T, error)) (T, error) {
+	if value, ok, err := CacheGet[T](ctx, cache, key); err == nil && ok {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return value, err
+	}
+	return value, CacheSet(ctx, cache, key, value, ttl)
+}
+
+// ---------- Response Cache ----------
+
+// CacheResponseConfig holds configuration for the response cache middleware.
+type CacheResponseConfig struct {
+	// TTL is how long responses are fresh. Default is one minute.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after TTL a stale response is still
+	// served while it is re-rendered in the background. Zero re-renders
+	// expired responses during the request.
+	StaleWhileRevalidate time.Duration
+
+	// Tags are cache tags of every response, for RevalidateTag. Handlers
+	// add more with c.CacheTag.
+	Tags []string
+
+	// KeyFunc returns the cache key of a request. The default key is the
+	// tenant (see Context.Tenant), the URL, and the Accept,
+	// Accept-Language and HX-Request headers.
+	KeyFunc func(c *Context) string
+
+	// Cache stores the responses. Default is the app's cache (see WithCache).
+	Cache Cache
+}
+
+// cachedResponse is a response stored by the response cache.
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	Path     string      `json:"path"`
+	Route    string      `json:"route,omitempty"`
+	Tags     []string    `json:"tags,omitempty"`
+	StoredAt int64       `json:"stored_at"` // UnixNano
+}
+
+// CacheResponse returns a middleware that caches successful GET and HEAD
+// responses in the app's cache for ttl, marking them with an X-Cache: HIT,
+// STALE or MISS header. Requests with an Authorization header and
+// responses that set cookies or Cache-Control: private or no-store are
+// never cached; the default key ignores cookies, so only cache responses
+// that are the same for every user.
+//
+// Cached responses are dropped early by RevalidatePath and RevalidateTag.
+//
+// Example:
+//
+//	app.Group("/blog", func(g *nexo.RouteGroup) {
+//	    g.Use(nexo.CacheResponse(5 * time.Minute))
+//	    g.Get("/{slug}", showPost)
+//	})
+func CacheResponse(ttl time.Duration) MiddlewareFunc {
+	return CacheResponseWithConfig(CacheResponseConfig{TTL: ttl})
+}
+
+// CacheResponseWithConfig returns a response cache middleware with custom
+// configuration.
+func CacheResponseWithConfig(config CacheResponseConfig) MiddlewareFunc {
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultResponseCacheKey
+	}
+	var refreshing sync.Map // keys being re-rendered in the background
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			r := c.Request
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Authorization") != "" {
+				return next(c)
+			}
+			cache := config.Cache
+			if cache == nil {
+				cache = c.Cache()
+			}
+			key := config.KeyFunc(c)
+
+			cached, ok, err := CacheGet[cachedResponse](c.Context(), cache, key)
+			if err == nil && ok && !revalidatedSince(c.Context(), cache, cached) {
+				age := time.Duration(time.Now().UnixNano() - cached.StoredAt)
+				switch {
+				case age < config.TTL:
+					c.SetHeader("X-Cache", "HIT")
+				case config.StaleWhileRevalidate > 0:
+					c.SetHeader("X-Cache", "STALE")
+					if _, busy := refreshing.LoadOrStore(key, true); !busy {
+						bg := c.detached()
+						go func() {
+							defer refreshing.Delete(key)
+							_, _ = renderToCache(bg, next, cache, key, config)
+						}()
+					}
+				default:
+					ok = false
+				}
+				if ok {
+					writeRecorded(c, cached.Status, cached.Header, cached.Body, false)
+					return nil
+				}
+			}
+
+			rec, err := renderToCache(c, next, cache, key, config)
+			if rec.status == 0 {
+				return err
+			}
+			rec.header.Set("X-Cache", "MISS")
+			writeRecorded(c, rec.status, rec.header, rec.body.Bytes(), true)
+			return err
+		}
+	}
+}
+
+// renderToCache runs next, recording its response, and caches it when it
+// can be shared.
+func renderToCache(c *Context, next HandlerFunc, cache Cache, key string, config CacheResponseConfig) (*responseRecorder, error) {
+	c.CacheTag(config.Tags...)
+	rec, err := recordResponse(c, next)
+	if err != nil || !cacheable(rec) {
+		return rec, err
+	}
+
+	cached := cachedResponse{
+		Status:   rec.status,
+		Header:   rec.header.Clone(),
+		Body:     rec.body.Bytes(),
+		Path:     c.Request.URL.Path,
+		Tags:     c.cacheTags(),
+		StoredAt: time.Now().UnixNano(),
+	}
+	if rctx := chi.RouteContext(c.Request.Context()); rctx != nil {
+		cached.Route = rctx.RoutePattern()
+	}
+	if err := CacheSet(c.Context(), cache, key, cached, config.TTL+config.StaleWhileRevalidate); err != nil {
+		log.Printf("nexo: response cache: %v", err)
+	}
+	return rec, nil
+}
+
+// defaultResponseCacheKey identifies a request by tenant, host, URL and
+// the headers that select the response representation.
+func defaultResponseCacheKey(c *Context) string {
+	r := c.Request
+	var tenant string
+	if t := c.Tenant(); t != nil {
+		tenant = "tenant:" + t.ID + ":"
+	}
+	return "response:" + tenant + r.Host + r.URL.RequestURI() + "|" + r.Header.Get("Accept") + "|" +
+		r.Header.Get("Accept-Language") + "|" + r.Header.Get("HX-Request")
+}
+
+// cacheable reports whether a recorded response may be shared.
+func cacheable(rec *responseRecorder) bool {
+	if rec.status != http.StatusOK || len(rec.header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	cc := strings.ToLower(rec.header.Get("Cache-Control"))
+	return !strings.Contains(cc, "private") && !strings.Contains(cc, "no-store")
+}
+
+// ---------- Memory Cache ----------
+
+// MemoryCache is an in-process LRU cache. It is the default backend; use
+// RedisCache to share a cache between instances.
+type MemoryCache struct {
+	mu    sync.Mutex
+	max   int
+	ll    *list.List // most recently used first
+	items map[string]*list.Element
+	now   func() time.Time
+
+	hits, misses uint64 // Get results
+}
+
+// memoryEntry is an entry of a MemoryCache.
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // zero never expires
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries entries,
+// evicting the least recently used. Zero uses 10000.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &MemoryCache{
+		max:   maxEntries,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		now:   time.Now,
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil {
+		m.misses++
+		return nil, false, nil
+	}
+	m.hits++
+	return append([]byte(nil), e.value...), true, nil
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+// Delete implements Cache.
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	return nil
+}
+
+// TTL implements Cache.
+func (m *MemoryCache) TTL(_ context.Context, key string) (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil {
+		return 0, false, nil
+	}
+	if e.expires.IsZero() {
+		return 0, true, nil
+	}
+	return e.expires.Sub(m.now()), true, nil
+}
+
+// Incr implements CacheCounter.
+func (m *MemoryCache) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil {
+		m.store(key, []byte("1"), ttl)
+		return 1, nil
+	}
+	n, err := strconv.ParseInt(string(e.value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cache: %s is not a counter", key)
+	}
+	n++
+	e.value = strconv.AppendInt(e.value[:0], n, 10)
+	return n, nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// Stats implements CacheStatsReporter.
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return CacheStats{Entries: m.ll.Len(), Hits: m.hits, Misses: m.misses}
+}
+
+// lookup returns the live entry of key, marking it recently used.
+func (m *MemoryCache) lookup(key string) *memoryEntry {
+	el, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && !m.now().Before(e.expires) {
+		m.remove(el)
+		return nil
+	}
+	m.ll.MoveToFront(el)
+	return e
+}
+
+// store sets key, evicting the least recently used entry when full.
+func (m *MemoryCache) store(key string, value []byte, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = m.now().Add(ttl)
+	}
+	if el, ok := m.items[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value, e.expires = value, expires
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.ll.Len() > m.max {
+		m.remove(m.ll.Back())
+	}
+}
+
+// remove deletes an entry.
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
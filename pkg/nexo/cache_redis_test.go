@@ -0,0 +1,237 @@
+package nexo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the Redis commands RedisCache uses from a MemoryCache.
+func fakeRedis(t *testing.T, password string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	store := NewMemoryCache(0)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn, store, password)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveFakeRedis(conn net.Conn, store *MemoryCache, password string) {
+	defer func() { _ = conn.Close() }()
+	ctx := context.Background()
+	r := bufio.NewReader(conn)
+	authed := password == ""
+	for {
+		reply, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, a := range reply.([]any) {
+			args = append(args, string(a.([]byte)))
+		}
+
+		var out string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required\r\n"
+		case cmd == "SELECT":
+			out = "+OK\r\n"
+		case cmd == "GET":
+			if v, ok, _ := store.Get(ctx, args[1]); ok {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				out = "$-1\r\n"
+			}
+		case cmd == "SET":
+			var ttl time.Duration
+			nx := false
+			for i := 3; i < len(args); i++ {
+				switch strings.ToUpper(args[i]) {
+				case "PX":
+					ms, _ := strconv.Atoi(args[i+1])
+					ttl = time.Duration(ms) * time.Millisecond
+					i++
+				case "NX":
+					nx = true
+				}
+			}
+			if _, exists, _ := store.Get(ctx, args[1]); nx && exists {
+				out = "$-1\r\n"
+				break
+			}
+			_ = store.Set(ctx, args[1], []byte(args[2]), ttl)
+			out = "+OK\r\n"
+		case cmd == "DEL":
+			_ = store.Delete(ctx, args[1])
+			out = ":1\r\n"
+		case cmd == "PTTL":
+			ttl, ok, _ := store.TTL(ctx, args[1])
+			switch {
+			case !ok:
+				out = ":-2\r\n"
+			case ttl == 0:
+				out = ":-1\r\n"
+			default:
+				out = fmt.Sprintf(":%d\r\n", ttl.Milliseconds())
+			}
+		case cmd == "EVAL" && args[1] == incrScript:
+			n, err := store.Incr(ctx, args[3], 0)
+			if err != nil {
+				out = "-ERR value is not an integer\r\n"
+				break
+			}
+			if ms, _ := strconv.Atoi(args[4]); ms > 0 {
+				if ttl, _, _ := store.TTL(ctx, args[3]); ttl == 0 {
+					v, _, _ := store.Get(ctx, args[3])
+					_ = store.Set(ctx, args[3], v, time.Duration(ms)*time.Millisecond)
+				}
+			}
+			out = fmt.Sprintf(":%d\r\n", n)
+		case cmd == "INCR":
+			n, err := store.Incr(ctx, args[1], 0)
+			out = fmt.Sprintf(":%d\r\n", n)
+			if err != nil {
+				out = "-ERR value is not an integer\r\n"
+			}
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	addr := fakeRedis(t, "secret")
+	cache, err := NewRedisCache("redis://:secret@" + addr + "/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cache.Close() }()
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "greeting", []byte("hello\r\nworld"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	v, ok, err := cache.Get(ctx, "greeting")
+	if err != nil || !ok || string(v) != "hello\r\nworld" {
+		t.Fatalf("Get = %q, %v, %v", v, ok, err)
+	}
+	if ttl, ok, err := cache.TTL(ctx, "greeting"); err != nil || !ok || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL = %v, %v, %v", ttl, ok, err)
+	}
+	if err := cache.Delete(ctx, "greeting"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := cache.Get(ctx, "greeting"); ok || err != nil {
+		t.Errorf("Get after Delete = %v, %v", ok, err)
+	}
+	if _, ok, _ := cache.TTL(ctx, "greeting"); ok {
+		t.Error("TTL of a deleted key reports it exists")
+	}
+
+	for want := int64(1); want <= 2; want++ {
+		if n, err := cache.Incr(ctx, "hits", time.Minute); err != nil || n != want {
+			t.Fatalf("Incr = %d, %v, want %d", n, err, want)
+		}
+	}
+	if ttl, ok, err := cache.TTL(ctx, "hits"); err != nil || !ok || ttl <= 0 {
+		t.Errorf("TTL of a counter = %v, %v, %v, want an expiry", ttl, ok, err)
+	}
+	// A counter left without an expiry gets one on the next increment
+	_ = cache.Set(ctx, "stuck", []byte("5"), 0)
+	if n, err := cache.Incr(ctx, "stuck", time.Minute); err != nil || n != 6 {
+		t.Fatalf("Incr = %d, %v, want 6", n, err)
+	}
+	if ttl, _, _ := cache.TTL(ctx, "stuck"); ttl <= 0 {
+		t.Errorf("TTL of a healed counter = %v, want an expiry", ttl)
+	}
+	_ = cache.Set(ctx, "nan", []byte("abc"), 0)
+	if _, err := cache.Incr(ctx, "nan", 0); err == nil {
+		t.Error("Incr of a non-counter: no error")
+	}
+	// Error replies leave the connection usable
+	if _, _, err := cache.Get(ctx, "hits"); err != nil {
+		t.Errorf("Get after an error reply: %v", err)
+	}
+
+	bad, _ := NewRedisCache("redis://:wrong@" + addr)
+	if _, _, err := bad.Get(ctx, "hits"); err == nil {
+		t.Error("wrong password: no error")
+	}
+}
+
+func TestRedisCache_UnexpectedReply(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				r := bufio.NewReader(conn)
+				for {
+					if _, err := readRESP(r); err != nil {
+						return
+					}
+					_, _ = conn.Write([]byte("+OK\r\n"))
+				}
+			}()
+		}
+	}()
+
+	cache, err := NewRedisCache("redis://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cache.Close() }()
+	ctx := context.Background()
+	if _, _, err := cache.Get(ctx, "key"); err == nil {
+		t.Error("Get: no error for a status reply")
+	}
+	if _, _, err := cache.TTL(ctx, "key"); err == nil {
+		t.Error("TTL: no error for a status reply")
+	}
+	if _, err := cache.Incr(ctx, "key", time.Minute); err == nil {
+		t.Error("Incr: no error for a status reply")
+	}
+}
+
+func TestNewRedisCache_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "http://localhost", "redis://localhost/x"} {
+		if _, err := NewRedisCache(u); err == nil {
+			t.Errorf("NewRedisCache(%q): no error", u)
+		}
+	}
+}
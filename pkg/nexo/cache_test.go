@@ -0,0 +1,198 @@
+package nexo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(2)
+	cache.now = func() time.Time { return now }
+
+	_ = cache.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = cache.Set(ctx, "b", []byte("2"), 0)
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	// b is now least recently used and gets evicted
+	_ = cache.Set(ctx, "c", []byte("3"), 0)
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("b not evicted")
+	}
+	if ttl, ok, _ := cache.TTL(ctx, "a"); !ok || ttl != time.Minute {
+		t.Errorf("TTL(a) = %v, %v", ttl, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := cache.Get(ctx, "a"); ok {
+		t.Error("a not expired")
+	}
+	if ttl, ok, _ := cache.TTL(ctx, "c"); !ok || ttl != 0 {
+		t.Errorf("TTL(c) = %v, %v, want no expiry", ttl, ok)
+	}
+
+	_ = cache.Delete(ctx, "c")
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", cache.Len())
+	}
+
+	for want := int64(1); want <= 3; want++ {
+		if n, err := cache.Incr(ctx, "hits", time.Second); err != nil || n != want {
+			t.Fatalf("Incr = %d, %v, want %d", n, err, want)
+		}
+	}
+	now = now.Add(time.Second)
+	if n, _ := cache.Incr(ctx, "hits", time.Second); n != 1 {
+		t.Errorf("Incr after expiry = %d, want 1", n)
+	}
+}
+
+func TestCacheHelpers(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(0)
+	type post struct{ Title string }
+
+	if err := CacheSet(ctx, cache, "post:1", post{"Hello"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := CacheGet[post](ctx, cache, "post:1")
+	if err != nil || !ok || got.Title != "Hello" {
+		t.Errorf("CacheGet = %+v, %v, %v", got, ok, err)
+	}
+
+	calls := 0
+	load := func() ([]post, error) {
+		calls++
+		return []post{{"A"}, {"B"}}, nil
+	}
+	for i := 0; i < 2; i++ {
+		posts, err := CacheRemember(ctx, cache, "posts", time.Minute, load)
+		if err != nil || len(posts) != 2 {
+			t.Fatalf("CacheRemember = %v, %v", posts, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader ran %d times, want 1", calls)
+	}
+
+	_, err = CacheRemember(ctx, cache, "failing", time.Minute, func() (int, error) { return 0, errors.New("db down") })
+	if err == nil {
+		t.Error("CacheRemember did not return the loader error")
+	}
+	if _, ok, _ := cache.Get(ctx, "failing"); ok {
+		t.Error("loader error was cached")
+	}
+}
+
+func TestOpenCache(t *testing.T) {
+	if c, err := OpenCache(CacheConfig{}); err != nil || c == nil {
+		t.Errorf("memory: %v", err)
+	}
+	if _, err := OpenCache(CacheConfig{Driver: "redis", URL: "redis://localhost:6379/2"}); err != nil {
+		t.Errorf("redis: %v", err)
+	}
+	if _, err := OpenCache(CacheConfig{Driver: "memcached"}); err == nil {
+		t.Error("unknown driver: no error")
+	}
+}
+
+func TestCacheResponse(t *testing.T) {
+	cache := NewMemoryCache(0)
+	calls := 0
+	app := New(WithCache(cache))
+	app.Group("/blog", func(g *RouteGroup) {
+		g.Use(CacheResponse(time.Minute))
+		g.Get("/{slug}", func(c *Context) error {
+			calls++
+			c.SetHeader("X-Post", c.Param("slug"))
+			return c.String(http.StatusOK, "post "+c.Param("slug"))
+		})
+		g.Get("/private", func(c *Context) error {
+			calls++
+			c.SetHeader("Cache-Control", "private")
+			return c.String(http.StatusOK, "private")
+		})
+	})
+	app.Mount()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	for i, want := range []string{"MISS", "HIT"} {
+		w := get("/blog/hello")
+		if w.Body.String() != "post hello" || w.Header().Get("X-Post") != "hello" {
+			t.Errorf("request %d: %q, X-Post %q", i, w.Body.String(), w.Header().Get("X-Post"))
+		}
+		if got := w.Header().Get("X-Cache"); got != want {
+			t.Errorf("request %d: X-Cache = %q, want %q", i, got, want)
+		}
+	}
+	get("/blog/private")
+	get("/blog/private")
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3", calls)
+	}
+	if app.Cache() != Cache(cache) {
+		t.Error("App.Cache() is not the WithCache backend")
+	}
+}
+
+func TestCacheResponse_Hosts(t *testing.T) {
+	app := New()
+	tenants := app.Host("{tenant}.example.com")
+	tenants.Use(CacheResponse(time.Minute))
+	tenants.Get("/", func(c *Context) error {
+		return c.String(http.StatusOK, "home of "+c.HostParam("tenant"))
+	})
+	app.Mount()
+
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = tenant + ".example.com"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if got := w.Body.String(); got != "home of "+tenant {
+			t.Errorf("%s: body = %q", req.Host, got)
+		}
+	}
+}
+
+func TestRateLimiterWithConfig_SharedCache(t *testing.T) {
+	cache := NewMemoryCache(0)
+	config := RateLimiterConfig{
+		Max:     1,
+		Window:  time.Minute,
+		KeyFunc: func(c *Context) string { return c.Header("X-API-Key") },
+		Cache:   cache,
+	}
+	// Two instances of the app share the limit through the cache
+	handler := func(c *Context) error { return c.NoContent() }
+	first := RateLimiterWithConfig(config)(handler)
+	second := RateLimiterWithConfig(config)(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "k1")
+	w := httptest.NewRecorder()
+	_ = first(NewContext(w, req))
+	if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("first request: %d, remaining %q", w.Code, w.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	w = httptest.NewRecorder()
+	_ = second(NewContext(w, req))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+}
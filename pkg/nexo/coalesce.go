@@ -0,0 +1,135 @@
+package nexo
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CoalesceConfig holds configuration for the request coalescing middleware.
+type CoalesceConfig struct {
+	// KeyFunc returns the key of identical requests. The default key is the
+	// method, tenant, host and URL plus the Accept, Accept-Encoding,
+	// Accept-Language, Authorization, Cookie and HX-Request headers, so
+	// clients never get a response rendered for another tenant, user or
+	// representation.
+	KeyFunc func(c *Context) string
+}
+
+// Coalesce returns a middleware that deduplicates concurrent identical GET
+// and HEAD requests: the first runs the handler while the others wait for
+// it and get a copy of its response. Under burst traffic an expensive
+// loader then runs once per burst instead of once per request.
+//
+// The response of the leading request is held in memory until the handler
+// returns, so don't coalesce streaming routes (SSE, large downloads).
+// Set-Cookie headers aren't copied to the waiting requests.
+//
+// Enable it for a route with RouteConfig.Coalesce, or for a group:
+//
+//	app.Group("/reports", func(g *nexo.RouteGroup) {
+//	    g.Use(nexo.Coalesce())
+//	    g.Get("/summary", summary)
+//	})
+func Coalesce() MiddlewareFunc {
+	return CoalesceWithConfig(CoalesceConfig{})
+}
+
+// CoalesceWithConfig returns a request coalescing middleware with custom
+// configuration.
+func CoalesceWithConfig(config CoalesceConfig) MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultCoalesceKey
+	}
+	g := &coalesceGroup{calls: make(map[string]*coalesceCall)}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				return next(c)
+			}
+			key := config.KeyFunc(c)
+
+			g.mu.Lock()
+			if call, ok := g.calls[key]; ok {
+				g.mu.Unlock()
+				select {
+				case <-call.done:
+					return call.writeTo(c, false)
+				case <-c.Context().Done():
+					return c.Context().Err()
+				}
+			}
+			call := &coalesceCall{done: make(chan struct{})}
+			g.calls[key] = call
+			g.mu.Unlock()
+
+			g.run(key, call, c, next)
+			return call.writeTo(c, true)
+		}
+	}
+}
+
+// defaultCoalesceKey identifies a request by method, tenant, host, URL and
+// the headers that select the response representation or the user.
+func defaultCoalesceKey(c *Context) string {
+	r := c.Request
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	if t := c.Tenant(); t != nil {
+		b.WriteString("tenant:" + t.ID + ":")
+	}
+	b.WriteString(r.Host)
+	b.WriteString(r.URL.RequestURI())
+	for _, h := range []string{"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cookie", "HX-Request"} {
+		b.WriteByte('\n')
+		b.WriteString(r.Header.Get(h))
+	}
+	return b.String()
+}
+
+// coalesceGroup tracks the in-flight request of each key.
+type coalesceGroup struct {
+	mu    sync.Mutex
+	calls map[string]*coalesceCall
+}
+
+// coalesceCall is the shared outcome of an in-flight request.
+type coalesceCall struct {
+	done   chan struct{}
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// run executes next for the leading request of key, recording its response
+// into call, and releases the waiting requests.
+func (g *coalesceGroup) run(key string, call *coalesceCall, c *Context, next HandlerFunc) {
+	finished := false
+	defer func() {
+		if !finished {
+			// The handler panicked: fail the waiting requests too
+			call.err = InternalServerError("internal server error")
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	rec, err := recordResponse(c, next)
+	call.status, call.header, call.body, call.err = rec.status, rec.header, rec.body.Bytes(), err
+	finished = true
+}
+
+// writeTo writes the shared response to c, or returns the shared error when
+// the handler didn't write one. Waiting requests don't get Set-Cookie.
+func (call *coalesceCall) writeTo(c *Context, leader bool) error {
+	if call.status == 0 {
+		return call.err
+	}
+	writeRecorded(c, call.status, call.header, call.body, leader)
+	return call.err
+}
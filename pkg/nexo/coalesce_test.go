@@ -0,0 +1,194 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	const requests = 5
+	var calls atomic.Int32
+	var keyed sync.WaitGroup
+	keyed.Add(requests)
+	release := make(chan struct{})
+
+	app := New()
+	app.Group("/reports", func(g *RouteGroup) {
+		g.Use(CoalesceWithConfig(CoalesceConfig{
+			KeyFunc: func(c *Context) string {
+				defer keyed.Done()
+				return defaultCoalesceKey(c)
+			},
+		}))
+		g.Get("/summary", func(c *Context) error {
+			calls.Add(1)
+			<-release
+			c.SetCookie(&http.Cookie{Name: "session", Value: "leader"})
+			return c.String(http.StatusOK, "summary")
+		})
+	})
+	app.Mount()
+
+	recorders := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports/summary", nil))
+		}(recorders[i])
+	}
+	keyed.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+	cookies := 0
+	for _, w := range recorders {
+		if w.Code != http.StatusOK || w.Body.String() != "summary" {
+			t.Errorf("response = %d %q, want 200 summary", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Set-Cookie") != "" {
+			cookies++
+		}
+	}
+	if cookies != 1 {
+		t.Errorf("%d responses set the cookie, want only the leader's", cookies)
+	}
+}
+
+func TestCoalesce_SequentialAndErrors(t *testing.T) {
+	var calls atomic.Int32
+	app := New()
+	app.RegisterRouteWithConfig(http.MethodGet, "/items", func(c *Context) error {
+		calls.Add(1)
+		c.Buffer()
+		return c.String(http.StatusOK, "items")
+	}, RouteConfig{Coalesce: true})
+	app.RegisterRouteWithConfig(http.MethodGet, "/missing", func(c *Context) error {
+		return NotFound("no such item")
+	}, RouteConfig{Coalesce: true})
+	app.Mount()
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "items" {
+			t.Fatalf("response = %d %q", w.Code, w.Body.String())
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times for sequential requests, want 2", n)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestCoalesce_Hosts(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	keyed, coalesce := keyedCoalesce(2)
+
+	app := New()
+	tenants := app.Host("{tenant}.example.com")
+	tenants.Use(coalesce)
+	tenants.Get("/", func(c *Context) error {
+		calls.Add(1)
+		<-release
+		return c.String(http.StatusOK, "home of "+c.HostParam("tenant"))
+	})
+	app.Mount()
+
+	hosts := []string{"acme.example.com", "globex.example.com"}
+	reqs := make([]*http.Request, len(hosts))
+	for i, host := range hosts {
+		reqs[i] = httptest.NewRequest(http.MethodGet, "/", nil)
+		reqs[i].Host = host
+	}
+	recorders := serveConcurrently(app, keyed, release, reqs)
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want once per host", n)
+	}
+	for i, want := range []string{"home of acme", "home of globex"} {
+		if got := recorders[i].Body.String(); got != want {
+			t.Errorf("%s: body = %q, want %q", hosts[i], got, want)
+		}
+	}
+}
+
+func TestCoalesce_Tenants(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	keyed, coalesce := keyedCoalesce(2)
+
+	app := New(WithTenantResolver(TenantFromHeader("X-Tenant-ID")))
+	app.Get("/dashboard", coalesce(func(c *Context) error {
+		calls.Add(1)
+		<-release
+		return c.String(http.StatusOK, "dashboard of "+c.Tenant().ID)
+	}))
+	app.Mount()
+
+	tenants := []string{"acme", "globex"}
+	reqs := make([]*http.Request, len(tenants))
+	for i, tenant := range tenants {
+		reqs[i] = httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+		reqs[i].Header.Set("X-Tenant-ID", tenant)
+	}
+	recorders := serveConcurrently(app, keyed, release, reqs)
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want once per tenant", n)
+	}
+	for i, tenant := range tenants {
+		if got := recorders[i].Body.String(); got != "dashboard of "+tenant {
+			t.Errorf("%s: body = %q", tenant, got)
+		}
+	}
+}
+
+// keyedCoalesce returns a coalescing middleware with the default key and a
+// WaitGroup that is done once n requests have computed their key.
+func keyedCoalesce(n int) (*sync.WaitGroup, MiddlewareFunc) {
+	var keyed sync.WaitGroup
+	keyed.Add(n)
+	return &keyed, CoalesceWithConfig(CoalesceConfig{
+		KeyFunc: func(c *Context) string {
+			defer keyed.Done()
+			return defaultCoalesceKey(c)
+		},
+	})
+}
+
+// serveConcurrently serves reqs at the same time and closes release once
+// they have all reached the coalescing middleware.
+func serveConcurrently(app *App, keyed *sync.WaitGroup, release chan struct{}, reqs []*http.Request) []*httptest.ResponseRecorder {
+	recorders := make([]*httptest.ResponseRecorder, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			app.ServeHTTP(w, req)
+		}(recorders[i])
+	}
+	keyed.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return recorders
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,6 +17,9 @@ type Config struct {
 	Port string `mapstructure:"port"`
 	Host string `mapstructure:"host"`
 
+	// Server tunes the timeouts and limits of the HTTP server
+	Server ServerConfig `mapstructure:"server"`
+
 	// Directory configuration
 	AppDir    string `mapstructure:"app_dir"`
 	StaticDir string `mapstructure:"static_dir"`
@@ -24,6 +30,114 @@ type Config struct {
 
 	// Middleware configuration
 	Middleware MiddlewareConfig `mapstructure:"middleware"`
+
+	// Plugins lists the names of generator/CLI plugins to enable
+	Plugins []string `mapstructure:"plugins"`
+
+	// Head sets <head> defaults for every page
+	Head HeadConfig `mapstructure:"head"`
+
+	// Sitemap serves /sitemap.xml
+	Sitemap SitemapConfig `mapstructure:"sitemap"`
+
+	// Robots serves /robots.txt
+	Robots RobotsConfig `mapstructure:"robots"`
+
+	// Tailwind configures the CSS build run by nexo dev and nexo build
+	Tailwind TailwindConfig `mapstructure:"tailwind"`
+
+	// JS configures the script bundle built by nexo dev and nexo build
+	JS JSConfig `mapstructure:"js"`
+
+	// Debug serves pprof and expvar under /_debug
+	Debug DebugConfig `mapstructure:"debug"`
+
+	// Admin serves the admin dashboard under /_admin
+	Admin AdminConfig `mapstructure:"admin"`
+
+	// APIDocs serves interactive API docs under /docs
+	APIDocs APIDocsConfig `mapstructure:"api_docs"`
+
+	// Log sets the request log level
+	Log LogConfig `mapstructure:"log"`
+
+	// Flags configures feature flags
+	Flags FlagsConfig `mapstructure:"flags"`
+
+	// RateLimits holds the named limits of RateLimiterFromConfig
+	RateLimits map[string]RateLimitConfig `mapstructure:"rate_limits"`
+
+	// Settings holds the app's own settings, read with String, Int, Bool,
+	// Duration and Strings
+	Settings map[string]any `mapstructure:"settings"`
+
+	// Events lists the subscribers of events sent with c.Emit
+	Events EventsConfig `mapstructure:"events"`
+
+	// Tenancy resolves the tenant of each request
+	Tenancy TenancyConfig `mapstructure:"tenancy"`
+
+	// Cache selects the cache backend (memory or redis)
+	Cache CacheConfig `mapstructure:"cache"`
+
+	// Storage selects the object storage of c.Storage (local, s3 or gcs)
+	Storage StorageConfig `mapstructure:"storage"`
+
+	// Revalidate serves the on-demand revalidation endpoint
+	Revalidate RevalidateConfig `mapstructure:"revalidate"`
+
+	// Batch serves the batch endpoint under /api/_batch
+	Batch BatchConfig `mapstructure:"batch"`
+
+	// Headers sets response headers by path (see HeaderRule)
+	Headers []HeaderRule `mapstructure:"headers"`
+
+	// Redirects and Rewrites route paths elsewhere without a proxy.go
+	// (see RedirectRule and RewriteRule)
+	Redirects []RedirectRule `mapstructure:"redirects"`
+	Rewrites  []RewriteRule  `mapstructure:"rewrites"`
+
+	// Maintenance configures maintenance mode
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+
+	// TrustedProxies lists the proxies whose forwarding headers ClientIP
+	// honors
+	TrustedProxies TrustedProxiesConfig `mapstructure:"trusted_proxies"`
+
+	// Generate configures the code nexo dev and nexo build generate
+	Generate GenerateConfig `mapstructure:"generate"`
+
+	// Template records the template repository the project was created
+	// from with nexo new --template
+	Template TemplateConfig `mapstructure:"template"`
+}
+
+// TemplateConfig identifies the template repository and version a project
+// was created from.
+type TemplateConfig struct {
+	// Source is the repository, e.g. "github.com/org/nexo-template-saas".
+	Source string `mapstructure:"source"`
+
+	// Version is the ref the project was created from: the requested tag
+	// or branch, the manifest version or the short commit.
+	Version string `mapstructure:"version"`
+
+	// Commit is the full commit hash of the template.
+	Commit string `mapstructure:"commit"`
+}
+
+// GenerateConfig configures code generation.
+type GenerateConfig struct {
+	// SplitRoutes writes the routes of each top-level directory to its own
+	// file, like nexo_routes_users.go, next to an aggregating
+	// nexo_routes.go, which keeps compile times and merge conflicts down
+	// in apps with hundreds of routes.
+	SplitRoutes bool `mapstructure:"split_routes"`
+
+	// Manifest, when set, is where the routes manifest is written on every
+	// route generation, e.g. routes.manifest.json. It describes the routes,
+	// pages, middleware and assets of the app for tools outside Nexo.
+	Manifest string `mapstructure:"manifest"`
 }
 
 // DevConfig holds development-specific configuration.
@@ -31,6 +145,44 @@ type DevConfig struct {
 	HotReload       bool     `mapstructure:"hot_reload"`
 	WatchExtensions []string `mapstructure:"watch_extensions"`
 	ExcludeDirs     []string `mapstructure:"exclude_dirs"`
+
+	// Inspector serves the route inspector at /_nexo in dev mode
+	Inspector bool `mapstructure:"inspector"`
+}
+
+// TailwindConfig holds the Tailwind CSS build configuration.
+type TailwindConfig struct {
+	// Input and Output are the source and compiled stylesheets.
+	Input  string `mapstructure:"input"`
+	Output string `mapstructure:"output"`
+
+	// Version pins the Tailwind standalone release, e.g. "4.1.4".
+	Version string `mapstructure:"version"`
+
+	// SHA256 is the expected checksum of the binary for this platform.
+	SHA256 string `mapstructure:"sha256"`
+
+	// CacheDir is where the binary is downloaded (default: ~/.cache/nexo/bin).
+	CacheDir string `mapstructure:"cache_dir"`
+
+	// Binary uses an existing Tailwind executable instead of downloading one.
+	Binary string `mapstructure:"binary"`
+}
+
+// LogConfig configures the request log.
+type LogConfig struct {
+	// Level is debug, info, warn, error or off (default: the mode's
+	// level). NEXO_LOG_LEVEL overrides it.
+	Level string `mapstructure:"level"`
+}
+
+// RateLimitConfig is a named rate limit, under rate_limits: in nexo.yaml.
+type RateLimitConfig struct {
+	// Max requests per window per client IP.
+	Max int `mapstructure:"max"`
+
+	// Window duration, like 1m.
+	Window time.Duration `mapstructure:"window"`
 }
 
 // MiddlewareConfig holds middleware-specific configuration.
@@ -51,11 +203,16 @@ func DefaultConfig() *Config {
 			HotReload:       true,
 			WatchExtensions: []string{".go", ".templ"},
 			ExcludeDirs:     []string{"node_modules", ".git"},
+			Inspector:       true,
 		},
 		Middleware: MiddlewareConfig{
 			Logger:  true,
 			Recover: true,
 		},
+		Tailwind: TailwindConfig{
+			Input:  "styles/input.css",
+			Output: "static/css/output.css",
+		},
 	}
 }
 
@@ -80,6 +237,127 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Setting returns the app setting key, from settings: in nexo.yaml. Keys
+// are case-insensitive and dots reach into nested settings, like
+// "stripe.public_key".
+func (c *Config) Setting(key string) (any, bool) {
+	return lookupSetting(c.Settings, key)
+}
+
+// String returns the setting key as a string, or def if it is unset.
+// Environment variables in it are expanded, so secrets can stay in the
+// environment: stripe: { secret_key: ${STRIPE_SECRET_KEY} }.
+func (c *Config) String(key, def string) string {
+	if v, ok := c.Setting(key); ok && v != nil {
+		return os.ExpandEnv(fmt.Sprint(v))
+	}
+	return def
+}
+
+// Int returns the setting key as an int, or def if it is unset or not a
+// number.
+func (c *Config) Int(key string, def int) int {
+	v, _ := c.Setting(key)
+	return settingInt(v, def)
+}
+
+// Bool returns the setting key as a bool, or def if it is unset or not a
+// boolean.
+func (c *Config) Bool(key string, def bool) bool {
+	v, _ := c.Setting(key)
+	return settingBool(v, def)
+}
+
+// Duration returns the setting key as a duration, like "30s", or def if it
+// is unset or not a duration.
+func (c *Config) Duration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(c.String(key, "")); err == nil {
+		return d
+	}
+	return def
+}
+
+// Strings returns the setting key as a list. A string is split on commas.
+func (c *Config) Strings(key string) []string {
+	v, _ := c.Setting(key)
+	switch list := v.(type) {
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			out = append(out, os.ExpandEnv(fmt.Sprint(item)))
+		}
+		return out
+	case string:
+		var out []string
+		for _, item := range strings.Split(os.ExpandEnv(list), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// lookupSetting returns the value of the dotted key in settings.
+func lookupSetting(settings map[string]any, key string) (any, bool) {
+	var v any = settings
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = lookupFold(m, part); !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// lookupFold returns the value of key in m, ignoring case, as viper
+// lowercases the keys it reads.
+func lookupFold(m map[string]any, key string) (any, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// settingInt converts a setting to an int, or returns def.
+func settingInt(v any, def int) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case string:
+		if i, err := strconv.Atoi(n); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+// settingBool converts a setting to a bool, or returns def.
+func settingBool(v any, def bool) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		if parsed, err := strconv.ParseBool(b); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
 // LoadConfig loads configuration from nexo.yaml if it exists.
 func LoadConfig(path string) (*Config, error) {
 	config := DefaultConfig()
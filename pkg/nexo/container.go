@@ -0,0 +1,202 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// container holds the services registered with App.Provide, keyed by type.
+type container struct {
+	mu       sync.RWMutex
+	services map[reflect.Type]reflect.Value
+}
+
+func newContainer() *container {
+	return &container{services: make(map[reflect.Type]reflect.Value)}
+}
+
+func (ct *container) provide(t reflect.Type, v reflect.Value) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.services[t] = v
+}
+
+// resolve returns the service registered for exactly t.
+func (ct *container) resolve(t reflect.Type) (reflect.Value, error) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	if v, ok := ct.services[t]; ok {
+		return v, nil
+	}
+	return reflect.Value{}, fmt.Errorf("no provider for %s (register it with app.Provide or nexo.ProvideAs)", t)
+}
+
+// Provide registers a service under its dynamic type so handlers and loaders
+// can declare it as an extra parameter. Providing a value of the same type
+// again replaces it. Call Provide before registering routes.
+//
+// Example:
+//
+//	db, _ := sql.Open("postgres", dsn)
+//	app.Provide(db) // handlers can accept db *sql.DB
+//	RegisterRoutes(app)
+func (a *App) Provide(service any) {
+	if service == nil {
+		panic("nexo: Provide called with nil service")
+	}
+	a.container.provide(reflect.TypeOf(service), reflect.ValueOf(service))
+}
+
+// ProvideAs registers a service under the type T. Use it to provide
+// interface types, since Provide registers the concrete type.
+//
+// Example:
+//
+//	nexo.ProvideAs[cache.Store](app, redisStore)
+func ProvideAs[T any](a *App, service T) {
+	a.container.provide(reflect.TypeFor[T](), reflect.ValueOf(&service).Elem())
+}
+
+// Resolve returns the service registered for T.
+func Resolve[T any](a *App) (T, error) {
+	var zero T
+	v, err := a.container.resolve(reflect.TypeFor[T]())
+	if err != nil {
+		return zero, err
+	}
+	return v.Interface().(T), nil
+}
+
+// mustResolve resolves T or panics with a message naming the dependent function.
+func mustResolve[T any](a *App, fn any) T {
+	v, err := Resolve[T](a)
+	if err != nil {
+		panic(fmt.Sprintf("nexo: cannot inject %s: %v", reflect.TypeOf(fn), err))
+	}
+	return v
+}
+
+// ---------- Handler and Loader Injection ----------
+
+// Inject1 adapts a handler with one dependency to a HandlerFunc. The
+// dependency is resolved once, when Inject1 is called, and Inject1 panics if
+// it has not been provided. Generated route registrations use the InjectN
+// helpers for handlers such as func Get(c *nexo.Context, db *sql.DB) error.
+func Inject1[D1 any](a *App, h func(*Context, D1) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	return func(c *Context) error {
+		return h(c, d1)
+	}
+}
+
+// Inject2 adapts a handler with two dependencies to a HandlerFunc. See Inject1.
+func Inject2[D1, D2 any](a *App, h func(*Context, D1, D2) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	d2 := mustResolve[D2](a, h)
+	return func(c *Context) error {
+		return h(c, d1, d2)
+	}
+}
+
+// Inject3 adapts a handler with three dependencies to a HandlerFunc. See Inject1.
+func Inject3[D1, D2, D3 any](a *App, h func(*Context, D1, D2, D3) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	d2 := mustResolve[D2](a, h)
+	d3 := mustResolve[D3](a, h)
+	return func(c *Context) error {
+		return h(c, d1, d2, d3)
+	}
+}
+
+// InjectContext1 is Inject1 for handlers taking the request's
+// context.Context first, like
+// func Get(ctx context.Context, c *nexo.Context, db *sql.DB) error.
+func InjectContext1[D1 any](a *App, h func(context.Context, *Context, D1) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	return func(c *Context) error {
+		return h(c.Context(), c, d1)
+	}
+}
+
+// InjectContext2 is Inject2 for handlers taking the request's
+// context.Context first. See InjectContext1.
+func InjectContext2[D1, D2 any](a *App, h func(context.Context, *Context, D1, D2) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	d2 := mustResolve[D2](a, h)
+	return func(c *Context) error {
+		return h(c.Context(), c, d1, d2)
+	}
+}
+
+// InjectContext3 is Inject3 for handlers taking the request's
+// context.Context first. See InjectContext1.
+func InjectContext3[D1, D2, D3 any](a *App, h func(context.Context, *Context, D1, D2, D3) error) HandlerFunc {
+	d1 := mustResolve[D1](a, h)
+	d2 := mustResolve[D2](a, h)
+	d3 := mustResolve[D3](a, h)
+	return func(c *Context) error {
+		return h(c.Context(), c, d1, d2, d3)
+	}
+}
+
+// InjectLoader1 adapts a page loader with one dependency to a plain loader.
+// Dependencies are resolved as in Inject1.
+func InjectLoader1[D1, T any](a *App, l func(*Context, D1) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	return func(c *Context) (T, error) {
+		return l(c, d1)
+	}
+}
+
+// InjectLoader2 adapts a page loader with two dependencies. See InjectLoader1.
+func InjectLoader2[D1, D2, T any](a *App, l func(*Context, D1, D2) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	d2 := mustResolve[D2](a, l)
+	return func(c *Context) (T, error) {
+		return l(c, d1, d2)
+	}
+}
+
+// InjectLoader3 adapts a page loader with three dependencies. See InjectLoader1.
+func InjectLoader3[D1, D2, D3, T any](a *App, l func(*Context, D1, D2, D3) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	d2 := mustResolve[D2](a, l)
+	d3 := mustResolve[D3](a, l)
+	return func(c *Context) (T, error) {
+		return l(c, d1, d2, d3)
+	}
+}
+
+// InjectLoaderContext1 is InjectLoader1 for loaders taking the request's
+// context.Context first, like
+// func Loader(ctx context.Context, c *nexo.Context, db *sql.DB) (Data, error).
+func InjectLoaderContext1[D1, T any](a *App, l func(context.Context, *Context, D1) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	return func(c *Context) (T, error) {
+		return l(c.Context(), c, d1)
+	}
+}
+
+// InjectLoaderContext2 is InjectLoader2 for loaders taking the request's
+// context.Context first. See InjectLoaderContext1.
+func InjectLoaderContext2[D1, D2, T any](a *App, l func(context.Context, *Context, D1, D2) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	d2 := mustResolve[D2](a, l)
+	return func(c *Context) (T, error) {
+		return l(c.Context(), c, d1, d2)
+	}
+}
+
+// InjectLoaderContext3 is InjectLoader3 for loaders taking the request's
+// context.Context first. See InjectLoaderContext1.
+func InjectLoaderContext3[D1, D2, D3, T any](a *App, l func(context.Context, *Context, D1, D2, D3) (T, error)) func(*Context) (T, error) {
+	d1 := mustResolve[D1](a, l)
+	d2 := mustResolve[D2](a, l)
+	d3 := mustResolve[D3](a, l)
+	return func(c *Context) (T, error) {
+		return l(c.Context(), c, d1, d2, d3)
+	}
+}
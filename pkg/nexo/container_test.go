@@ -0,0 +1,137 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testDB struct{ name string }
+
+type testStore interface{ Get(key string) string }
+
+type mapStore map[string]string
+
+func (m mapStore) Get(key string) string { return m[key] }
+
+func TestProvideAndResolve(t *testing.T) {
+	app := New()
+	db := &testDB{name: "primary"}
+	app.Provide(db)
+	ProvideAs[testStore](app, mapStore{"k": "v"})
+
+	gotDB, err := Resolve[*testDB](app)
+	if err != nil {
+		t.Fatalf("Resolve[*testDB]() error = %v", err)
+	}
+	if gotDB != db {
+		t.Errorf("Resolve[*testDB]() = %v, want %v", gotDB, db)
+	}
+
+	store, err := Resolve[testStore](app)
+	if err != nil {
+		t.Fatalf("Resolve[testStore]() error = %v", err)
+	}
+	if got := store.Get("k"); got != "v" {
+		t.Errorf("store.Get() = %q, want %q", got, "v")
+	}
+
+	// Interfaces are not matched against concrete providers
+	if _, err := Resolve[fmt.Stringer](app); err == nil {
+		t.Error("Resolve[fmt.Stringer]() expected error for missing provider")
+	}
+
+	// Providing the same type again replaces the service
+	replacement := &testDB{name: "replica"}
+	app.Provide(replacement)
+	if gotDB, _ := Resolve[*testDB](app); gotDB != replacement {
+		t.Errorf("Resolve[*testDB]() after re-provide = %v, want %v", gotDB, replacement)
+	}
+}
+
+func TestInject(t *testing.T) {
+	app := New()
+	app.Provide(&testDB{name: "primary"})
+	ProvideAs[testStore](app, mapStore{"greeting": "hello"})
+
+	handler := Inject2(app, func(c *Context, db *testDB, store testStore) error {
+		return c.String(http.StatusOK, db.name+" "+store.Get("greeting"))
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := handler(NewContext(rec, req)); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if got := rec.Body.String(); got != "primary hello" {
+		t.Errorf("body = %q, want %q", got, "primary hello")
+	}
+}
+
+func TestInjectLoader(t *testing.T) {
+	app := New()
+	app.Provide(&testDB{name: "primary"})
+
+	loader := InjectLoader1(app, func(c *Context, db *testDB) (string, error) {
+		return db.name, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	got, err := loader(NewContext(httptest.NewRecorder(), req))
+	if err != nil {
+		t.Fatalf("loader error = %v", err)
+	}
+	if got != "primary" {
+		t.Errorf("loader() = %q, want %q", got, "primary")
+	}
+}
+
+func TestInjectContext(t *testing.T) {
+	app := New()
+	app.Provide(&testDB{name: "primary"})
+
+	type ctxKey struct{}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "request"))
+
+	handler := InjectContext1(app, func(ctx context.Context, c *Context, db *testDB) error {
+		return c.String(http.StatusOK, db.name+" "+ctx.Value(ctxKey{}).(string))
+	})
+	rec := httptest.NewRecorder()
+	if err := handler(NewContext(rec, req)); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if got := rec.Body.String(); got != "primary request" {
+		t.Errorf("body = %q, want %q", got, "primary request")
+	}
+
+	loader := InjectLoaderContext1(app, func(ctx context.Context, c *Context, db *testDB) (string, error) {
+		return db.name + " " + ctx.Value(ctxKey{}).(string), nil
+	})
+	got, err := loader(NewContext(httptest.NewRecorder(), req))
+	if err != nil {
+		t.Fatalf("loader error = %v", err)
+	}
+	if got != "primary request" {
+		t.Errorf("loader() = %q, want %q", got, "primary request")
+	}
+}
+
+func TestInject_MissingDependencyPanics(t *testing.T) {
+	app := New()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected panic for missing dependency")
+		}
+		if msg := fmt.Sprint(r); !strings.Contains(msg, "*nexo.testDB") {
+			t.Errorf("panic message %q does not name the missing type", msg)
+		}
+	}()
+
+	Inject1(app, func(c *Context, db *testDB) error { return nil })
+}
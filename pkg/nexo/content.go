@@ -0,0 +1,82 @@
+package nexo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/markdown"
+)
+
+// Markdown returns the pipeline that renders the app's content pages,
+// configured with WithMarkdown (default: markdown.New()).
+func (a *App) Markdown() *markdown.Pipeline {
+	if a.markdown == nil {
+		a.markdown = markdown.New()
+	}
+	return a.markdown
+}
+
+// MarkdownPage returns a handler that renders the Markdown file as a page,
+// inside the layouts of segments. The front matter's title, description and
+// tags become the page metadata, and the document is available to layouts
+// through GetContent. Generated route files register content/*.md files with
+// it:
+//
+//	// content/blog/hello.md
+//	app.Get("/blog/hello", app.MarkdownPage("content/blog/hello.md",
+//	    nexo.LayoutSegment{Layout: nexo.TemplLayout(app_layout.Layout)},
+//	))
+//
+// The file is read once, or on every request in development mode. Drafts
+// are only served in development mode.
+func (a *App) MarkdownPage(file string, segments ...LayoutSegment) HandlerFunc {
+	var (
+		once   sync.Once
+		doc    *markdown.Document
+		docErr error
+	)
+	load := func() (*markdown.Document, error) {
+		if a.Mode() == ModeDevelopment {
+			return a.Markdown().ParseFile(a.files, file)
+		}
+		once.Do(func() {
+			doc, docErr = a.Markdown().ParseFile(a.files, file)
+		})
+		return doc, docErr
+	}
+
+	return func(c *Context) error {
+		doc, err := load()
+		if err != nil {
+			return err
+		}
+		if doc.Draft && a.Mode() != ModeDevelopment {
+			return NotFound("page not found")
+		}
+
+		md := &Metadata{Title: doc.Title, Description: doc.Description, Keywords: doc.Tags}
+		segs := append(segments[:len(segments):len(segments)], LayoutSegment{Metadata: md})
+		c.Request = c.Request.WithContext(WithContent(c.Request.Context(), doc))
+		return RenderPage(c, 200, templ.Raw(doc.HTML), segs...)
+	}
+}
+
+// contentKey is the context key for the Markdown document being rendered.
+type contentKey struct{}
+
+// WithContent returns a copy of ctx carrying doc.
+func WithContent(ctx context.Context, doc *markdown.Document) context.Context {
+	return context.WithValue(ctx, contentKey{}, doc)
+}
+
+// GetContent returns the Markdown document of the content page being
+// rendered, or nil. Use it in layouts for front matter such as the date:
+//
+//	if doc := nexo.GetContent(ctx); doc != nil {
+//	    <time>{ doc.Date.Format("January 2, 2006") }</time>
+//	}
+func GetContent(ctx context.Context) *markdown.Document {
+	doc, _ := ctx.Value(contentKey{}).(*markdown.Document)
+	return doc
+}
@@ -0,0 +1,66 @@
+package nexo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestApp_MarkdownPage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("NEXO_DEV", "")
+	t.Setenv("GO_ENV", "")
+
+	files := map[string]string{
+		"content/post.md":  "---\ntitle: Hello\ndescription: First post\n---\n# Hello\n\nWelcome.\n",
+		"content/draft.md": "---\ntitle: Soon\ndraft: true\n---\nSoon.\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := New()
+	root := &Metadata{TitleTemplate: "%s | Blog"}
+	// The layout reads the document through GetContent
+	layout := func(title string, children templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<main title=\""+title+"\" data-doc=\""+GetContent(ctx).Description+"\">")
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</main>")
+			return err
+		})
+	}
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest(http.MethodGet, "/post", nil))
+	err := app.MarkdownPage("content/post.md", LayoutSegment{Layout: layout, Metadata: root})(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<main title="Hello | Blog" data-doc="First post"><h1 id="hello">Hello</h1>` + "\n<p>Welcome.</p>\n</main>"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body =\n%s\nwant\n%s", got, want)
+	}
+
+	// Drafts are only served in development mode
+	c = NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/draft", nil))
+	err = app.MarkdownPage("content/draft.md")(c)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+		t.Errorf("draft err = %v, want 404", err)
+	}
+}
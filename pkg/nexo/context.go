@@ -1,17 +1,25 @@
 package nexo
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+	"github.com/abdul-hamid-achik/nexo/pkg/events"
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+	"github.com/abdul-hamid-achik/nexo/pkg/storage"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 // Context wraps the HTTP request and response with helper methods.
@@ -22,20 +30,136 @@ type Context struct {
 	// Response is the underlying HTTP response writer.
 	Response http.ResponseWriter
 
-	// params stores URL parameters extracted from the path.
+	// params stores URL parameters extracted from the path (allocated on first SetParam).
 	params map[string]string
 
-	// query caches the parsed query string.
+	// query caches the parsed query string (parsed on first access).
 	query url.Values
 
-	// store holds request-scoped values.
-	store map[string]any
+	// store holds request-scoped values (allocated on first Set), keyed by
+	// strings or by *ContextKey.
+	store map[any]any
 
 	// written tracks if a response has been written.
 	written bool
 
 	// status holds the response status code.
 	status int
+
+	// retained prevents a pooled Context from being reused (see Retain).
+	retained bool
+
+	// codec encodes and decodes JSON (nil uses the default codec).
+	codec JSONCodec
+
+	// i18n holds the app's message catalogs (nil when not configured).
+	i18n *i18n.Bundle
+
+	// locale is the request's locale (negotiated on first Locale call).
+	locale string
+
+	// localized tracks whether the request context carries a Localizer.
+	localized bool
+
+	// secret signs flash cookies (nil uses a per-process key).
+	secret []byte
+
+	// flashIn holds the flash data sent by the previous request (read once).
+	flashIn *flashData
+
+	// flashOut holds the flash data for the next request.
+	flashOut *flashData
+
+	// flashRead tracks whether the flash cookie has been read.
+	flashRead bool
+
+	// flashAttached tracks whether the request context carries flashIn.
+	flashAttached bool
+
+	// headConfig holds the app's <head> defaults (nil when not configured).
+	headConfig *HeadConfig
+
+	// head collects the response's <head> tags (created on first Head call).
+	head *Head
+
+	// headAttached tracks whether the request context carries head.
+	headAttached bool
+
+	// assets resolves bundled asset URLs (nil when not configured).
+	assets *bundler.Manifest
+
+	// assetsAttached tracks whether the request context carries assets.
+	assetsAttached bool
+
+	// cache is the app's cache backend (nil uses a process-wide memory cache).
+	cache Cache
+
+	// validator checks bound input (nil uses the built-in rules).
+	validator *StructValidator
+
+	// errors holds the error definitions of c.Fail (nil uses the
+	// process-wide catalog).
+	errors *ErrorCatalog
+
+	// proxyTrust resolves ClientIP through trusted proxies (nil trusts
+	// every client's forwarding headers).
+	proxyTrust *proxyTrust
+
+	// flags is the app's feature flag store (nil turns every flag off).
+	flags *flags.Store
+
+	// flagKey returns the key flags are evaluated for (nil uses the client).
+	flagKey func(*Context) string
+
+	// events is the app's event bus (nil drops emitted events).
+	events *events.Bus
+
+	// storage is the app's object storage (nil when none is configured).
+	storage storage.Driver
+
+	// config is the app's current config (nil for standalone contexts).
+	config *liveConfig
+
+	// tenancy resolves the request's tenant (nil when tenancy is off).
+	tenancy *tenancy
+
+	// tenant, tenantErr and tenantResolved cache the resolved tenant.
+	tenant         *Tenant
+	tenantErr      error
+	tenantResolved bool
+
+	// flagsFor evaluates flags for the request (created on first Flags call).
+	flagsFor *flags.Evaluator
+
+	// flagsAttached tracks whether the request context carries flagsFor.
+	flagsAttached bool
+
+	// cacheAttached tracks whether the request context carries the cache,
+	// for CacheFragment.
+	cacheAttached bool
+
+	// corsHandled tracks whether a CORS policy handled the request, so a
+	// route's policy wins over the app's CORS middleware.
+	corsHandled bool
+
+	// rawBody holds the request body once RawBody has read it.
+	rawBody []byte
+
+	// bodyLimit caps the bytes RawBody reads (0 uses DefaultBodyLimit).
+	bodyLimit int64
+
+	// hxTriggers holds the events sent per HX-Trigger header.
+	hxTriggers map[string][]hxEvent
+
+	// oob holds the out-of-band swaps written after the next render.
+	oob []templ.Component
+
+	// buffer holds the response in buffered mode (see Buffer).
+	buffer *bufferedWriter
+
+	// tracker tracks the response status for writers that don't, so pooled
+	// Contexts need no extra allocation to report Written and StatusCode.
+	tracker responseWriter
 }
 
 // NewContext creates a new Context from an HTTP request and response.
@@ -43,18 +167,154 @@ func NewContext(w http.ResponseWriter, r *http.Request) *Context {
 	return &Context{
 		Request:  r,
 		Response: w,
-		params:   make(map[string]string),
-		query:    r.URL.Query(),
-		store:    make(map[string]any),
 		status:   http.StatusOK,
 	}
 }
 
-// Context returns the request's context.Context.
+// contextPool recycles Contexts between requests served by the router.
+var contextPool = sync.Pool{
+	New: func() any { return new(Context) },
+}
+
+// maxPooledMapSize bounds the maps kept by a recycled Context, so one large
+// request doesn't pin memory for the lifetime of the pool.
+const maxPooledMapSize = 64
+
+// acquireContext returns a Context from the pool, reset for w and r.
+// Release it with releaseContext once the request is done.
+func acquireContext(w http.ResponseWriter, r *http.Request) *Context {
+	c := contextPool.Get().(*Context)
+	if _, ok := w.(statusWriter); !ok {
+		c.tracker = responseWriter{ResponseWriter: w, status: http.StatusOK}
+		w = &c.tracker
+	}
+	c.Request = r
+	c.Response = w
+	c.status = http.StatusOK
+	return c
+}
+
+// releaseContext resets c and returns it to the pool, unless it was retained.
+func releaseContext(c *Context) {
+	if c.retained {
+		return
+	}
+	c.reset()
+	contextPool.Put(c)
+}
+
+// reset clears all request state while keeping small maps for reuse.
+func (c *Context) reset() {
+	c.Request = nil
+	c.Response = nil
+	c.query = nil
+	c.written = false
+	c.status = 0
+	c.codec = nil
+	c.i18n = nil
+	c.locale = ""
+	c.localized = false
+	c.secret = nil
+	c.flashIn = nil
+	c.flashOut = nil
+	c.flashRead = false
+	c.flashAttached = false
+	c.headConfig = nil
+	c.head = nil
+	c.headAttached = false
+	c.assets = nil
+	c.assetsAttached = false
+	c.cache = nil
+	c.validator = nil
+	c.errors = nil
+	c.proxyTrust = nil
+	c.flags = nil
+	c.flagKey = nil
+	c.events = nil
+	c.storage = nil
+	c.config = nil
+	c.tenancy = nil
+	c.tenant = nil
+	c.tenantErr = nil
+	c.tenantResolved = false
+	c.flagsFor = nil
+	c.flagsAttached = false
+	c.cacheAttached = false
+	c.corsHandled = false
+	c.rawBody = nil
+	c.bodyLimit = 0
+	c.hxTriggers = nil
+	c.oob = nil
+	c.buffer = nil
+	c.tracker = responseWriter{}
+	if len(c.params) > maxPooledMapSize {
+		c.params = nil
+	} else {
+		clear(c.params)
+	}
+	if len(c.store) > maxPooledMapSize {
+		c.store = nil
+	} else {
+		clear(c.store)
+	}
+}
+
+// Retain marks the Context as still in use after the handler returns, so the
+// router does not recycle it. Call it before handing c to a goroutine that
+// may outlive the request.
+//
+// Example:
+//
+//	func Post(c *nexo.Context) error {
+//	    c.Retain()
+//	    go audit(c)
+//	    return c.NoContent()
+//	}
+func (c *Context) Retain() {
+	c.retained = true
+}
+
+// Context returns the request's context.Context. With catalogs configured
+// it carries the request's Localizer (see i18n.T).
 func (c *Context) Context() context.Context {
+	c.localize()
 	return c.Request.Context()
 }
 
+// Deadline returns when the request context is canceled by Timeout or a
+// route's timeout, and false when the request has no deadline.
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.Request.Context().Deadline()
+}
+
+// TimeLeft returns the time until the request deadline, and false when the
+// request has no deadline. It is never negative.
+//
+// Example:
+//
+//	if left, ok := c.TimeLeft(); ok && left < time.Second {
+//	    return c.JSON(200, cachedReport) // not enough time to rebuild it
+//	}
+func (c *Context) TimeLeft() (time.Duration, bool) {
+	deadline, ok := c.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
+
+// TimeoutContext returns a context for a single operation, like a database
+// query, canceled after d or at the request deadline, whichever comes first.
+//
+// Example:
+//
+//	ctx, cancel := c.TimeoutContext(2 * time.Second)
+//	defer cancel()
+//	rows, err := db.QueryContext(ctx, query)
+func (c *Context) TimeoutContext(d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Context(), d)
+}
+
 // WithContext returns a shallow copy of Context with a new context.Context.
 func (c *Context) WithContext(ctx context.Context) *Context {
 	c.Request = c.Request.WithContext(ctx)
@@ -84,6 +344,16 @@ func (c *Context) ParamInt(key string, def int) int {
 	return def
 }
 
+// ParamUUID returns a URL parameter as a UUID, or uuid.Nil when it isn't
+// one. Typed parameters like [id:uuid] are always valid UUIDs.
+func (c *Context) ParamUUID(key string) uuid.UUID {
+	id, err := uuid.Parse(c.Param(key))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
 // ParamAll returns all segments for catch-all routes.
 // For a catch-all param like [...slug], this returns the segments split by "/".
 func (c *Context) ParamAll(key string) []string {
@@ -96,19 +366,30 @@ func (c *Context) ParamAll(key string) []string {
 
 // SetParam sets a URL parameter (used internally by the router).
 func (c *Context) SetParam(key, value string) {
+	if c.params == nil {
+		c.params = make(map[string]string)
+	}
 	c.params[key] = value
 }
 
 // ---------- Query Parameters ----------
 
+// queryValues returns the parsed query string, parsing it on first use.
+func (c *Context) queryValues() url.Values {
+	if c.query == nil {
+		c.query = c.Request.URL.Query()
+	}
+	return c.query
+}
+
 // Query returns a query string parameter.
 func (c *Context) Query(key string) string {
-	return c.query.Get(key)
+	return c.queryValues().Get(key)
 }
 
 // QueryInt returns a query param as an int with a default value.
 func (c *Context) QueryInt(key string, def int) int {
-	val := c.query.Get(key)
+	val := c.queryValues().Get(key)
 	if val == "" {
 		return def
 	}
@@ -120,7 +401,7 @@ func (c *Context) QueryInt(key string, def int) int {
 
 // QueryBool returns a query param as a bool with a default value.
 func (c *Context) QueryBool(key string, def bool) bool {
-	val := c.query.Get(key)
+	val := c.queryValues().Get(key)
 	if val == "" {
 		return def
 	}
@@ -136,7 +417,7 @@ func (c *Context) QueryBool(key string, def bool) bool {
 
 // QueryDefault returns a query param with a default value if empty.
 func (c *Context) QueryDefault(key, def string) string {
-	val := c.query.Get(key)
+	val := c.queryValues().Get(key)
 	if val == "" {
 		return def
 	}
@@ -145,7 +426,7 @@ func (c *Context) QueryDefault(key, def string) string {
 
 // QueryAll returns all values for a query parameter.
 func (c *Context) QueryAll(key string) []string {
-	return c.query[key]
+	return c.queryValues()[key]
 }
 
 // ---------- Headers ----------
@@ -179,15 +460,26 @@ func (c *Context) FormFile(key string) (*multipart.FileHeader, error) {
 	return fh, err
 }
 
-// Bind parses the JSON request body into the provided struct.
+// Bind parses the request body into the provided struct: form values for
+// URL-encoded and multipart forms (see BindForm), JSON otherwise. The struct
+// is then checked against its `validate` tags (see Validate). The JSON body
+// is read through RawBody, so it can be bound and still verified or read
+// again afterwards.
 func (c *Context) Bind(v any) error {
-	if c.Request.Body == nil {
+	if c.isFormContentType() {
+		return c.BindForm(v)
+	}
+	if c.Request.Body == nil && c.rawBody == nil {
 		return NewHTTPError(http.StatusBadRequest, "empty request body")
 	}
-	if err := json.NewDecoder(c.Request.Body).Decode(v); err != nil {
+	data, err := c.RawBody()
+	if err != nil {
+		return bodyReadError(err, "failed to read request body")
+	}
+	if err := c.jsonCodec().Decode(bytes.NewReader(data), v); err != nil {
 		return NewHTTPErrorWithCause(http.StatusBadRequest, "invalid JSON", err)
 	}
-	return nil
+	return c.validate(v, "json")
 }
 
 // ---------- Response Methods ----------
@@ -204,7 +496,7 @@ func (c *Context) JSON(status int, data any) error {
 	c.Response.WriteHeader(status)
 	c.written = true
 	c.status = status
-	return json.NewEncoder(c.Response).Encode(data)
+	return c.jsonCodec().Encode(c.Response, data)
 }
 
 // String sends a plain text response.
@@ -245,12 +537,16 @@ func (c *Context) NoContent() error {
 	return nil
 }
 
-// Redirect performs an HTTP redirect.
+// Redirect performs an HTTP redirect. Absolute paths are prefixed with
+// the base path of an app mounted with MountUnder.
 func (c *Context) Redirect(url string, status ...int) error {
 	code := http.StatusFound
 	if len(status) > 0 {
 		code = status[0]
 	}
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") {
+		url = c.BasePath() + url
+	}
 	http.Redirect(c.Response, c.Request, url, code)
 	c.written = true
 	c.status = code
@@ -271,6 +567,9 @@ func (c *Context) Error(status int, message string) error {
 
 // Set stores a value in the request context.
 func (c *Context) Set(key string, value any) {
+	if c.store == nil {
+		c.store = make(map[any]any)
+	}
 	c.store[key] = value
 }
 
@@ -303,6 +602,63 @@ func (c *Context) MustGet(key string) any {
 	panic(fmt.Sprintf("key %q not found in context", key))
 }
 
+// ContextKey is a typed key for request-scoped values. Unlike string keys,
+// a key is only equal to itself, so packages can't overwrite each other's
+// values, and its values need no type assertion.
+//
+// Example:
+//
+//	var userKey = nexo.NewContextKey[*User]("user")
+//
+//	// In middleware
+//	userKey.Set(c, user)
+//
+//	// In a handler
+//	user, ok := userKey.Get(c)
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey returns a new key for values of type T. The name is only
+// used in messages; declare each key once, as a package-level variable.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// String returns the key's name.
+func (k *ContextKey[T]) String() string {
+	return k.name
+}
+
+// Set stores value for the current request.
+func (k *ContextKey[T]) Set(c *Context, value T) {
+	if c.store == nil {
+		c.store = make(map[any]any)
+	}
+	c.store[k] = value
+}
+
+// Get returns the value stored with Set, and false if there's none.
+func (k *ContextKey[T]) Get(c *Context) (T, bool) {
+	value, ok := c.store[k].(T)
+	return value, ok
+}
+
+// Value returns the value stored with Set, or the zero value of T.
+func (k *ContextKey[T]) Value(c *Context) T {
+	value, _ := k.Get(c)
+	return value
+}
+
+// MustGet returns the value stored with Set, or panics if there's none.
+func (k *ContextKey[T]) MustGet(c *Context) T {
+	value, ok := k.Get(c)
+	if !ok {
+		panic(fmt.Sprintf("key %q not found in context", k.name))
+	}
+	return value
+}
+
 // ---------- Request Helpers ----------
 
 // Method returns the HTTP method of the request.
@@ -332,8 +688,13 @@ func (c *Context) IsWebSocket() bool {
 	return strings.EqualFold(upgrade, "websocket")
 }
 
-// ClientIP returns the client's IP address.
+// ClientIP returns the client's IP address. With trusted proxies
+// configured (see WithTrustedProxies), forwarding headers are honored only
+// from them and the IP has no port.
 func (c *Context) ClientIP() string {
+	if c.proxyTrust != nil {
+		return c.proxyTrust.clientIP(c.Request)
+	}
 	// Check X-Forwarded-For header first
 	if ip := c.Request.Header.Get("X-Forwarded-For"); ip != "" {
 		return strings.TrimSpace(strings.Split(ip, ",")[0])
@@ -356,13 +717,25 @@ func (c *Context) ContentType() string {
 	return c.Request.Header.Get("Content-Type")
 }
 
-// Written returns whether a response has been written.
+// Written returns whether a response has been written, including writes
+// made directly to c.Response. In buffered mode it reports whether the
+// handler has written a response, even if it hasn't been sent yet.
 func (c *Context) Written() bool {
-	return c.written
+	if c.written {
+		return true
+	}
+	if sw, ok := c.Response.(statusWriter); ok {
+		return sw.Written()
+	}
+	return false
 }
 
-// StatusCode returns the response status code.
+// StatusCode returns the response status code: the written status once a
+// response has been written, otherwise the one set with Status.
 func (c *Context) StatusCode() int {
+	if sw, ok := c.Response.(statusWriter); ok && sw.Written() {
+		return sw.Status()
+	}
 	return c.status
 }
 
@@ -370,11 +743,12 @@ func (c *Context) StatusCode() int {
 
 // Render renders a templ component as the HTTP response.
 func (c *Context) Render(status int, component templ.Component) error {
+	ctx := c.renderContext()
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.Response.WriteHeader(status)
 	c.written = true
 	c.status = status
-	return component.Render(c.Context(), c.Response)
+	return c.renderComponent(ctx, component)
 }
 
 // RenderOK renders a templ component with a 200 OK status.
@@ -382,6 +756,26 @@ func (c *Context) RenderOK(component templ.Component) error {
 	return c.Render(http.StatusOK, component)
 }
 
+// renderComponent renders component to the response, injecting head tags
+// before </head> and appending the queued out-of-band swaps.
+func (c *Context) renderComponent(ctx context.Context, component templ.Component) error {
+	if headFromContext(ctx) == nil {
+		if err := component.Render(ctx, c.Response); err != nil {
+			return err
+		}
+		return c.writeOOB(ctx)
+	}
+
+	hw := &headWriter{w: c.Response, ctx: ctx}
+	if err := component.Render(ctx, hw); err != nil {
+		return err
+	}
+	if err := hw.flush(); err != nil {
+		return err
+	}
+	return c.writeOOB(ctx)
+}
+
 // ---------- Cookies ----------
 
 // Cookie returns a cookie value by name.
@@ -433,7 +827,7 @@ func (c *Context) SSE() (*SSEWriter, error) {
 	c.SetHeader("X-Accel-Buffering", "no") // Disable nginx buffering
 	c.written = true
 
-	return &SSEWriter{w: c.Response, flusher: flusher}, nil
+	return &SSEWriter{w: c.Response, flusher: flusher, codec: c.jsonCodec()}, nil
 }
 
 // ---------- Additional Context Helpers ----------
@@ -446,3 +840,63 @@ func (c *Context) GetBool(key string) bool {
 	}
 	return false
 }
+
+// ---------- Auth ----------
+
+// principalKey is the store and context.Context key for the authenticated principal.
+const principalKey = "nexo.principal"
+
+type authContextKey int
+
+const (
+	principalContextKey authContextKey = iota
+	bearerTokenContextKey
+)
+
+// SetPrincipal stores the authenticated principal (user, API client, ...)
+// for the current request. Auth middleware should call this so handlers
+// and GraphQL resolvers can access it.
+func (c *Context) SetPrincipal(principal any) {
+	c.Set(principalKey, principal)
+}
+
+// Principal returns the principal set by SetPrincipal, or nil.
+func (c *Context) Principal() any {
+	return c.Get(principalKey)
+}
+
+// BearerToken returns the token from an "Authorization: Bearer <token>" header.
+// Returns empty string if the header is missing or uses another scheme.
+func (c *Context) BearerToken() string {
+	auth := c.Header("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// ResolverContext returns the request's context.Context enriched with the
+// principal and bearer token, for passing to GraphQL resolvers or other code
+// that has no access to the nexo Context.
+func (c *Context) ResolverContext() context.Context {
+	ctx := c.Context()
+	if principal := c.Principal(); principal != nil {
+		ctx = context.WithValue(ctx, principalContextKey, principal)
+	}
+	if token := c.BearerToken(); token != "" {
+		ctx = context.WithValue(ctx, bearerTokenContextKey, token)
+	}
+	return ctx
+}
+
+// PrincipalFromContext returns the principal stored by ResolverContext.
+func PrincipalFromContext(ctx context.Context) (any, bool) {
+	principal := ctx.Value(principalContextKey)
+	return principal, principal != nil
+}
+
+// BearerTokenFromContext returns the bearer token stored by ResolverContext.
+func BearerTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(bearerTokenContextKey).(string)
+	return token, ok
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewContext(t *testing.T) {
@@ -350,6 +351,43 @@ func TestContext_Store(t *testing.T) {
 	}
 }
 
+func TestContextKey(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	userKey := NewContextKey[string]("user")
+	otherKey := NewContextKey[string]("user")
+	countKey := NewContextKey[int]("count")
+
+	if _, ok := userKey.Get(c); ok {
+		t.Error("Get before Set found a value")
+	}
+	userKey.Set(c, "ada")
+	c.Set("user", "string key")
+	countKey.Set(c, 42)
+
+	if got, ok := userKey.Get(c); !ok || got != "ada" {
+		t.Errorf("Get = %q, %v; want ada", got, ok)
+	}
+	if _, ok := otherKey.Get(c); ok {
+		t.Error("keys with the same name share values")
+	}
+	if got := c.GetString("user"); got != "string key" {
+		t.Errorf("string key = %q, overwritten by a typed key", got)
+	}
+	if got := countKey.Value(c); got != 42 {
+		t.Errorf("Value = %d, want 42", got)
+	}
+	if got := otherKey.Value(c); got != "" {
+		t.Errorf("Value without Set = %q, want zero", got)
+	}
+
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, `"user"`) {
+			t.Errorf("MustGet panic = %q, want the key's name", msg)
+		}
+	}()
+	otherKey.MustGet(c)
+}
+
 func TestContext_MustGet_Panic(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	w := httptest.NewRecorder()
@@ -810,3 +848,133 @@ func TestContext_GetBool(t *testing.T) {
 		t.Error("expected GetBool('text') to be false for non-bool value")
 	}
 }
+
+func TestContextPool_Reset(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/first?a=1", nil)
+	c := acquireContext(httptest.NewRecorder(), req)
+	c.SetParam("id", "1")
+	c.Set("user", "alice")
+	_ = c.Query("a")
+	_ = c.NoContent()
+	releaseContext(c)
+
+	// A recycled Context must not leak state from the previous request
+	c.reset()
+	req = httptest.NewRequest(http.MethodGet, "/second?b=2", nil)
+	c = acquireContext(httptest.NewRecorder(), req)
+	defer releaseContext(c)
+
+	if c.Param("id") != "" {
+		t.Errorf("Param(id) = %q, want empty", c.Param("id"))
+	}
+	if c.Get("user") != nil {
+		t.Errorf("Get(user) = %v, want nil", c.Get("user"))
+	}
+	if c.Query("a") != "" || c.Query("b") != "2" {
+		t.Errorf("Query() returned stale values: a=%q b=%q", c.Query("a"), c.Query("b"))
+	}
+	if c.Written() || c.StatusCode() != http.StatusOK {
+		t.Errorf("Written() = %v, StatusCode() = %d; want false, 200", c.Written(), c.StatusCode())
+	}
+}
+
+func TestContextPool_Retain(t *testing.T) {
+	c := acquireContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set("key", "value")
+	c.Retain()
+	releaseContext(c)
+
+	// A retained Context keeps its state for goroutines still using it
+	if c.Request == nil || c.GetString("key") != "value" {
+		t.Error("retained Context was reset on release")
+	}
+}
+
+func TestContextPool_Allocations(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1?page=2", nil)
+	w := httptest.NewRecorder()
+
+	// Warm the pool so the measured runs reuse a Context
+	releaseContext(acquireContext(w, req))
+
+	pooled := testing.AllocsPerRun(100, func() {
+		c := acquireContext(w, req)
+		c.SetParam("id", "1")
+		c.Set("user", "alice")
+		releaseContext(c)
+	})
+	fresh := testing.AllocsPerRun(100, func() {
+		c := NewContext(w, req)
+		c.SetParam("id", "1")
+		c.Set("user", "alice")
+	})
+
+	// The pool may drop items (always under -race), so compare instead of expecting zero
+	if pooled >= fresh {
+		t.Errorf("pooled Context allocated %.1f times per request, NewContext %.1f", pooled, fresh)
+	}
+}
+
+func BenchmarkNewContext(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1?page=2", nil)
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c := NewContext(w, req)
+			c.SetParam("id", "1")
+			c.Set("user", "alice")
+			_ = c.Query("page")
+		}
+	})
+}
+
+func BenchmarkAcquireContext(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1?page=2", nil)
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c := acquireContext(w, req)
+			c.SetParam("id", "1")
+			c.Set("user", "alice")
+			_ = c.Query("page")
+			releaseContext(c)
+		}
+	})
+}
+
+func TestContext_Deadline(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := NewContext(httptest.NewRecorder(), req)
+	if _, ok := c.Deadline(); ok {
+		t.Error("Deadline() ok without a deadline")
+	}
+	if _, ok := c.TimeLeft(); ok {
+		t.Error("TimeLeft() ok without a deadline")
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), time.Minute)
+	defer cancel()
+	c = NewContext(httptest.NewRecorder(), req.WithContext(ctx))
+	if left, ok := c.TimeLeft(); !ok || left <= 50*time.Second || left > time.Minute {
+		t.Errorf("TimeLeft() = %v, %v; want about a minute", left, ok)
+	}
+
+	// TimeoutContext keeps the earlier request deadline
+	opCtx, opCancel := c.TimeoutContext(time.Hour)
+	defer opCancel()
+	want, _ := c.Deadline()
+	if got, _ := opCtx.Deadline(); !got.Equal(want) {
+		t.Errorf("TimeoutContext deadline = %v, want %v", got, want)
+	}
+
+	expired, cancelExpired := context.WithTimeout(req.Context(), -time.Second)
+	defer cancelExpired()
+	c = NewContext(httptest.NewRecorder(), req.WithContext(expired))
+	if left, ok := c.TimeLeft(); !ok || left != 0 {
+		t.Errorf("TimeLeft() past the deadline = %v, %v; want 0, true", left, ok)
+	}
+}
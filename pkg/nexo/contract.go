@@ -0,0 +1,471 @@
+package nexo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// ---------- Contract Fixtures ----------
+
+// ContractFixture is a recorded request and the response it got, replayed
+// by VerifyContracts.
+type ContractFixture struct {
+	Request  ContractRequest  `json:"request"`
+	Response ContractResponse `json:"response"`
+}
+
+// ContractRequest is the request of a ContractFixture.
+type ContractRequest struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"` // path and query
+	Header map[string]string `json:"header,omitempty"`
+
+	// Body is the JSON body, or the body as a JSON string when it isn't
+	// JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// ContractResponse is the response of a ContractFixture.
+type ContractResponse struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"` // as ContractRequest.Body
+}
+
+// encodeContractBody returns body as stored in a fixture.
+func encodeContractBody(body []byte, contentType string) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if isJSONMediaType(contentType) && json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	text, _ := json.Marshal(string(body))
+	return text
+}
+
+// decodeContractBody returns the body a fixture stores.
+func decodeContractBody(body json.RawMessage, contentType string) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	if isJSONMediaType(contentType) {
+		return body
+	}
+	var text string
+	if err := json.Unmarshal(body, &text); err != nil {
+		return body
+	}
+	return []byte(text)
+}
+
+// isJSONMediaType reports whether contentType is JSON, like
+// application/json or application/problem+json.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// ---------- Recording ----------
+
+// defaultContractHeaders are the request headers recorded by default.
+var defaultContractHeaders = []string{"Accept", "Accept-Language", "Content-Type"}
+
+// ContractRecordConfig configures the recording of contract fixtures.
+type ContractRecordConfig struct {
+	// Dir receives one fixture file per distinct request (default:
+	// testdata/contracts). Recording the same request again replaces its
+	// fixture.
+	Dir string
+
+	// Headers are the request headers recorded (default: Accept,
+	// Accept-Language, Content-Type). Add the ones replays need, like a
+	// test account's Authorization.
+	Headers []string
+
+	// SkipPaths are paths, with what's below them, not recorded.
+	SkipPaths []string
+
+	// MaxBody is the largest request or response body recorded, in bytes
+	// (default: 1 MB). Larger exchanges aren't recorded.
+	MaxBody int
+}
+
+// contractRecorder records the requests an app serves as fixtures.
+type contractRecorder struct {
+	config ContractRecordConfig
+}
+
+func newContractRecorder(config ContractRecordConfig) *contractRecorder {
+	if config.Dir == "" {
+		config.Dir = filepath.Join("testdata", "contracts")
+	}
+	if config.Headers == nil {
+		config.Headers = defaultContractHeaders
+	}
+	if config.MaxBody <= 0 {
+		config.MaxBody = 1 << 20
+	}
+	return &contractRecorder{config: config}
+}
+
+// WithContractRecording records every request the app serves, and its
+// response, as a contract fixture, to replay with VerifyContracts. Enable
+// it while exercising the app by hand or with an end-to-end suite, not in
+// production.
+//
+// Example:
+//
+//	var opts []nexo.Option
+//	if os.Getenv("RECORD_CONTRACTS") != "" {
+//	    opts = append(opts, nexo.WithContractRecording(nexo.ContractRecordConfig{
+//	        Headers: []string{"Accept", "Content-Type", "Authorization"},
+//	    }))
+//	}
+//	app := nexo.New(opts...)
+func WithContractRecording(config ContractRecordConfig) Option {
+	return func(a *App) {
+		a.contracts = newContractRecorder(config)
+	}
+}
+
+// RecordContracts returns handler recording the requests it serves as
+// contract fixtures (see WithContractRecording), for handlers that aren't
+// an App.
+func RecordContracts(handler http.Handler, config ContractRecordConfig) http.Handler {
+	cr := newContractRecorder(config)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w, r, done := cr.capture(w, r)
+		defer done()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// capture starts recording r. It returns the writer and request to serve
+// it with, and a func saving the fixture once it's served.
+func (cr *contractRecorder) capture(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	for _, skip := range cr.config.SkipPaths {
+		if r.URL.Path == skip || strings.HasPrefix(r.URL.Path, strings.TrimSuffix(skip, "/")+"/") {
+			return w, r, func() {}
+		}
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		data, err := io.ReadAll(io.LimitReader(r.Body, int64(cr.config.MaxBody)+1))
+		rest := r.Body
+		r = r.WithContext(r.Context())
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), rest), rest}
+		if err != nil || len(data) > cr.config.MaxBody {
+			return w, r, func() {}
+		}
+		body = data
+	}
+
+	fixture := ContractFixture{Request: ContractRequest{
+		Method: r.Method,
+		URL:    r.URL.RequestURI(),
+		Body:   encodeContractBody(body, r.Header.Get("Content-Type")),
+	}}
+	for _, name := range cr.config.Headers {
+		if value := r.Header.Get(name); value != "" {
+			if fixture.Request.Header == nil {
+				fixture.Request.Header = make(map[string]string)
+			}
+			fixture.Request.Header[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+
+	rw := newResponseWriter(w)
+	rw.body = &cappedBuffer{max: cr.config.MaxBody}
+	return rw, r, func() {
+		if rw.body.total > int64(cr.config.MaxBody) {
+			return
+		}
+		contentType := rw.Header().Get("Content-Type")
+		fixture.Response = ContractResponse{
+			Status:      rw.Status(),
+			ContentType: contentType,
+			Body:        encodeContractBody(rw.body.data, contentType),
+		}
+		if err := cr.save(fixture); err != nil {
+			log.Printf("nexo: contracts: %v", err)
+		}
+	}
+}
+
+// save writes fixture to a file named after its request.
+func (cr *contractRecorder) save(fixture ContractFixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cr.config.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cr.config.Dir, contractFileName(fixture.Request)), append(data, '\n'), 0644)
+}
+
+// contractFileName names the fixture of req by its method and path, with a
+// hash telling requests to the same path apart.
+func contractFileName(req ContractRequest) string {
+	path, _, _ := strings.Cut(req.URL, "?")
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.Trim(path, "/"))
+	if slug == "" {
+		slug = "root"
+	}
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL + "\n" + string(req.Body)))
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(req.Method), slug, hex.EncodeToString(sum[:4]))
+}
+
+// ---------- Verification ----------
+
+// ContractConfig configures VerifyContracts.
+type ContractConfig struct {
+	// Spec explains mismatches by validating the response against the
+	// OpenAPI spec, like the one OpenAPIGenerator generates.
+	Spec *openapi3.T
+}
+
+// ContractMismatch is a fixture whose replayed response drifted from the
+// recorded one.
+type ContractMismatch struct {
+	Fixture  string   // fixture file
+	Request  string   // method and URL
+	Problems []string // what drifted, then what the spec says about it
+}
+
+// Error describes the mismatch.
+func (m ContractMismatch) Error() string {
+	return fmt.Sprintf("%s (%s):\n\t%s", m.Request, filepath.Base(m.Fixture), strings.Join(m.Problems, "\n\t"))
+}
+
+// VerifyContracts replays the fixtures in dir against handler and returns
+// the responses that drifted from the recorded ones: a different status or
+// content type, or a JSON body of a different shape. Values aren't
+// compared, so IDs and timestamps can change, and fields added since the
+// recording are accepted, as they don't break clients.
+//
+// Example:
+//
+//	func TestContracts(t *testing.T) {
+//	    mismatches, err := nexo.VerifyContracts(newApp(), "testdata/contracts", nexo.ContractConfig{})
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    for _, m := range mismatches {
+//	        t.Error(m)
+//	    }
+//	}
+func VerifyContracts(handler http.Handler, dir string, config ContractConfig) ([]ContractMismatch, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no contract fixtures in %s", dir)
+	}
+	sort.Strings(files)
+
+	var validator *contractValidator
+	if config.Spec != nil {
+		if validator, err = newContractValidator(config.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	var mismatches []ContractMismatch
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fixture ContractFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		req := fixture.Request
+		body := decodeContractBody(req.Body, req.Header["Content-Type"])
+		r := httptest.NewRequest(req.Method, req.URL, bytes.NewReader(body))
+		for name, value := range req.Header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		problems := compareContract(fixture.Response, w)
+		if len(problems) == 0 {
+			continue
+		}
+		if validator != nil {
+			r := httptest.NewRequest(req.Method, req.URL, bytes.NewReader(body))
+			for name, value := range req.Header {
+				r.Header.Set(name, value)
+			}
+			problems = append(problems, validator.explain(r, w))
+		}
+		mismatches = append(mismatches, ContractMismatch{
+			Fixture:  file,
+			Request:  req.Method + " " + req.URL,
+			Problems: problems,
+		})
+	}
+	return mismatches, nil
+}
+
+// compareContract returns how the response w drifted from recorded.
+func compareContract(recorded ContractResponse, w *httptest.ResponseRecorder) []string {
+	var problems []string
+	if w.Code != recorded.Status {
+		problems = append(problems, fmt.Sprintf("status %d, recorded %d", w.Code, recorded.Status))
+	}
+	contentType := w.Header().Get("Content-Type")
+	got, _, _ := mime.ParseMediaType(contentType)
+	want, _, _ := mime.ParseMediaType(recorded.ContentType)
+	if got != want {
+		problems = append(problems, fmt.Sprintf("content type %q, recorded %q", got, want))
+		return problems
+	}
+	if !isJSONMediaType(contentType) || len(recorded.Body) == 0 {
+		return problems
+	}
+
+	var before, after any
+	if err := json.Unmarshal(recorded.Body, &before); err != nil {
+		return append(problems, "recorded body: "+err.Error())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &after); err != nil {
+		return append(problems, "body isn't JSON: "+err.Error())
+	}
+	return append(problems, compareShape("$", before, after)...)
+}
+
+// compareShape returns where the JSON value after lost a field or changed
+// the type of a value of before.
+func compareShape(path string, before, after any) []string {
+	if jsonType(before) != jsonType(after) {
+		return []string{fmt.Sprintf("%s is %s, recorded %s", path, jsonType(after), jsonType(before))}
+	}
+	var problems []string
+	switch b := before.(type) {
+	case map[string]any:
+		a := after.(map[string]any)
+		keys := make([]string, 0, len(b))
+		for key := range b {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := a[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is missing", path, key))
+				continue
+			}
+			problems = append(problems, compareShape(path+"."+key, b[key], value)...)
+		}
+	case []any:
+		// Elements are compared with the first recorded one
+		a := after.([]any)
+		if len(b) > 0 && len(a) > 0 {
+			for i, value := range a {
+				if p := compareShape(fmt.Sprintf("%s[%d]", path, i), b[0], value); len(p) > 0 {
+					return p
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// jsonType names the type of a decoded JSON value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
+
+// contractValidator checks responses against an OpenAPI spec.
+type contractValidator struct {
+	router *legacy.Router
+}
+
+func newContractValidator(spec *openapi3.T) (*contractValidator, error) {
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return &contractValidator{router: router.(*legacy.Router)}, nil
+}
+
+// explain returns what the spec says about the response w to r.
+func (v *contractValidator) explain(r *http.Request, w *httptest.ResponseRecorder) string {
+	route, params, err := v.router.FindRoute(r)
+	if err != nil {
+		return fmt.Sprintf("openapi: %s %s isn't in the spec", r.Method, r.URL.Path)
+	}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+		},
+		Status: w.Code,
+		Header: w.Header(),
+		Body:   io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	err = openapi3filter.ValidateResponse(context.Background(), input)
+	if err == nil {
+		return "openapi: the response matches the spec, so the recording is outdated or the spec is too loose"
+	}
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		msgs := make([]string, len(multi))
+		for i, e := range multi {
+			msgs[i] = e.Error()
+		}
+		return "openapi: " + strings.Join(msgs, "; ")
+	}
+	return "openapi: " + err.Error()
+}
@@ -0,0 +1,222 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// contractApp returns an app serving users, shaped by user.
+func contractApp(user func(id string) map[string]any, opts ...Option) *App {
+	app := New(opts...)
+	app.RegisterRoute(http.MethodGet, "/users/{id}", func(c *Context) error {
+		return c.JSON(http.StatusOK, user(c.Param("id")))
+	})
+	app.RegisterRoute(http.MethodPost, "/users", func(c *Context) error {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, body)
+	})
+	app.RegisterRoute(http.MethodGet, "/health", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	app.Mount()
+	return app
+}
+
+func TestContracts_RecordAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	user := func(id string) map[string]any {
+		return map[string]any{"id": id, "name": "Ada", "tags": []any{"admin"}}
+	}
+	app := contractApp(user, WithContractRecording(ContractRecordConfig{
+		Dir:       dir,
+		SkipPaths: []string{"/health"},
+	}))
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1?expand=tags", nil),
+		httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Ada"}`)),
+		httptest.NewRequest(http.MethodGet, "/health", nil),
+	}
+	requests[1].Header.Set("Content-Type", "application/json")
+	for _, r := range requests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code >= 400 {
+			t.Fatalf("%s %s: status %d: %s", r.Method, r.URL, w.Code, w.Body)
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("recorded %v, want 2 fixtures (/health skipped)", files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, contractFileName(ContractRequest{
+		Method: http.MethodPost,
+		URL:    "/users",
+		Body:   []byte(`{"name":"Ada"}`),
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"status": 201`) || !strings.Contains(string(data), `"name": "Ada"`) {
+		t.Errorf("fixture = %s", data)
+	}
+
+	tests := []struct {
+		name     string
+		user     func(id string) map[string]any
+		problems []string
+	}{
+		{name: "same", user: user},
+		{name: "new values and fields", user: func(id string) map[string]any {
+			return map[string]any{"id": "2", "name": "Grace", "tags": []any{}, "email": "grace@example.com"}
+		}},
+		{name: "missing field", user: func(id string) map[string]any {
+			return map[string]any{"id": id, "tags": []any{"admin"}}
+		}, problems: []string{"$.name is missing"}},
+		{name: "changed type", user: func(id string) map[string]any {
+			return map[string]any{"id": 1, "name": "Ada", "tags": []any{1}}
+		}, problems: []string{"$.id is a number, recorded a string", "$.tags[0] is a number, recorded a string"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches, err := VerifyContracts(contractApp(tt.user), dir, ContractConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.problems == nil {
+				if len(mismatches) > 0 {
+					t.Errorf("mismatches = %v", mismatches)
+				}
+				return
+			}
+			if len(mismatches) != 1 || mismatches[0].Request != "GET /users/1?expand=tags" {
+				t.Fatalf("mismatches = %v, want GET /users/1?expand=tags", mismatches)
+			}
+			if got := strings.Join(mismatches[0].Problems, "\n"); got != strings.Join(tt.problems, "\n") {
+				t.Errorf("problems = %q, want %q", mismatches[0].Problems, tt.problems)
+			}
+		})
+	}
+}
+
+func TestVerifyContracts_Status(t *testing.T) {
+	dir := t.TempDir()
+	handler := RecordContracts(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}), ContractRecordConfig{Dir: dir})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	gone := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	mismatches, err := VerifyContracts(gone, dir, ContractConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 {
+		t.Fatalf("mismatches = %v, want 1", mismatches)
+	}
+	want := []string{"status 404, recorded 200", `content type "text/plain", recorded "application/json"`}
+	if got := mismatches[0].Problems; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+
+	if _, err := VerifyContracts(gone, t.TempDir(), ContractConfig{}); err == nil {
+		t.Error("VerifyContracts without fixtures succeeded")
+	}
+}
+
+func TestVerifyContracts_Spec(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(`{
+		"openapi": "3.0.3",
+		"info": {"title": "API", "version": "1.0.0"},
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {
+						"200": {
+							"description": "A user",
+							"content": {"application/json": {"schema": {
+								"type": "object",
+								"required": ["id", "name"],
+								"properties": {"id": {"type": "string"}, "name": {"type": "string"}}
+							}}}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	record := func(path, body string) {
+		handler := RecordContracts(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}), ContractRecordConfig{Dir: dir})
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	record("/users/1", `{"id":"1","name":"Ada"}`)
+	record("/teams/1", `{"id":"1"}`)
+
+	drifted := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	mismatches, err := VerifyContracts(drifted, dir, ContractConfig{Spec: spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("mismatches = %v, want 2", mismatches)
+	}
+	// Fixtures replay in file name order
+	teams, users := mismatches[0].Problems, mismatches[1].Problems
+	if want := []string{"$.id is missing", "openapi: GET /teams/1 isn't in the spec"}; strings.Join(teams, "\n") != strings.Join(want, "\n") {
+		t.Errorf("teams problems = %q, want %q", teams, want)
+	}
+	if len(users) != 3 || users[0] != "$.id is missing" || users[1] != "$.name is missing" {
+		t.Fatalf("users problems = %q", users)
+	}
+	if !strings.HasPrefix(users[2], "openapi: ") || !strings.Contains(users[2], `"id"`) {
+		t.Errorf("explanation = %q, want the spec's required id", users[2])
+	}
+}
+
+func TestContractFileName(t *testing.T) {
+	tests := []struct {
+		req  ContractRequest
+		want string
+	}{
+		{ContractRequest{Method: "GET", URL: "/"}, "get_root_"},
+		{ContractRequest{Method: "GET", URL: "/users/1?expand=tags"}, "get_users_1_"},
+		{ContractRequest{Method: "POST", URL: "/api/v1/sign-up"}, "post_api_v1_sign-up_"},
+	}
+	for _, tt := range tests {
+		got := contractFileName(tt.req)
+		if !strings.HasPrefix(got, tt.want) || !strings.HasSuffix(got, ".json") {
+			t.Errorf("contractFileName(%s %s) = %q, want %s<hash>.json", tt.req.Method, tt.req.URL, got, tt.want)
+		}
+	}
+	a := contractFileName(ContractRequest{Method: "POST", URL: "/users", Body: []byte(`{"name":"Ada"}`)})
+	b := contractFileName(ContractRequest{Method: "POST", URL: "/users", Body: []byte(`{"name":"Grace"}`)})
+	if a == b {
+		t.Errorf("requests with different bodies share fixture %s", a)
+	}
+}
@@ -0,0 +1,111 @@
+package nexo
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugConfig configures the runtime diagnostics endpoints, under debug: in
+// nexo.yaml.
+type DebugConfig struct {
+	// Enabled serves net/http/pprof and expvar under Path.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path is where the endpoints are served (default: /_debug).
+	Path string `mapstructure:"path"`
+
+	// Username and Password require HTTP basic auth for the endpoints when
+	// Username is set. An empty Password is read from NEXO_DEBUG_PASSWORD,
+	// to keep it out of nexo.yaml.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// mountDebug registers the diagnostics endpoints enabled by WithDebug or
+// debug.enabled:
+//
+//	GET /_debug/vars               expvar
+//	GET /_debug/pprof/             profile index
+//	GET /_debug/pprof/{profile}    a profile, e.g. heap or profile?seconds=30
+//
+// They run behind the app's middleware, then basic auth from the config,
+// then the middleware given to WithDebug. Serving them in production
+// without either logs a warning.
+func (a *App) mountDebug() {
+	cfg := a.config.Debug
+	base := strings.TrimSuffix(orDefault(cfg.Path, "/_debug"), "/")
+	if !cfg.Enabled || a.hasRoute(http.MethodGet, base+"/pprof/") {
+		return
+	}
+	if cfg.Username == "" && len(a.debugMiddleware) == 0 && a.Mode() == ModeProduction {
+		log.Printf("nexo: warning: %s serves pprof and expvar without debug.username or WithDebug middleware", base)
+	}
+
+	var mws []MiddlewareFunc
+	if cfg.Username != "" {
+		password := orDefault(cfg.Password, os.Getenv("NEXO_DEBUG_PASSWORD"))
+		mws = append(mws, configBasicAuth("Debug", cfg.Username, password))
+	}
+	mws = append(mws, a.debugMiddleware...)
+
+	add := func(method, pattern string, handler HandlerFunc) {
+		a.routeTree.AddRoute(&Route{
+			Method:      method,
+			Pattern:     pattern,
+			Handler:     handler,
+			Priority:    CalculatePriority(pattern),
+			Middlewares: mws,
+		})
+	}
+	std := func(handler http.HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			handler(c.Response, c.Request)
+			return nil
+		}
+	}
+	add(http.MethodGet, base, func(c *Context) error {
+		return c.Redirect(base+"/pprof/", http.StatusFound)
+	})
+	add(http.MethodGet, base+"/vars", std(expvar.Handler().ServeHTTP))
+	add(http.MethodGet, base+"/pprof/", std(pprof.Index))
+	add(http.MethodGet, base+"/pprof/{profile}", serveProfile)
+	add(http.MethodPost, base+"/pprof/symbol", std(pprof.Symbol))
+}
+
+// serveProfile serves the pprof profile named by the profile URL parameter.
+func serveProfile(c *Context) error {
+	w, r := c.Response, c.Request
+	switch c.Param("profile") {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "profile":
+		pprof.Profile(w, longRunningProfile(w, r, 30))
+	case "trace":
+		pprof.Trace(w, longRunningProfile(w, r, 1))
+	default:
+		pprof.Handler(c.Param("profile")).ServeHTTP(w, r)
+	}
+	return nil
+}
+
+// longRunningProfile lets a CPU profile or trace run past the server's
+// WriteTimeout: it extends the write deadline to cover ?seconds= (default
+// defaultSeconds) and hides the timeout from pprof, which would otherwise
+// refuse profiles longer than it.
+func longRunningProfile(w http.ResponseWriter, r *http.Request, defaultSeconds int) *http.Request {
+	seconds, err := strconv.Atoi(r.URL.Query().Get("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Duration(seconds)*time.Second + 10*time.Second))
+	return r.WithContext(context.WithValue(r.Context(), http.ServerContextKey, &http.Server{}))
+}
@@ -0,0 +1,105 @@
+package nexo
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	app := New(WithDebug())
+	app.Mount()
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/_debug/vars", http.StatusOK, `"memstats"`},
+		{"/_debug/pprof/", http.StatusOK, "goroutine"},
+		{"/_debug/pprof/goroutine?debug=1", http.StatusOK, "goroutine profile"},
+		{"/_debug/pprof/cmdline", http.StatusOK, ""},
+		{"/_debug", http.StatusFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("body does not contain %q", tt.contains)
+			}
+		})
+	}
+}
+
+func TestDebugEndpoints_Auth(t *testing.T) {
+	config := DefaultConfig()
+	config.Debug = DebugConfig{Enabled: true, Path: "/ops/", Username: "ops"}
+	t.Setenv("NEXO_DEBUG_PASSWORD", "secret")
+	var guarded bool
+	app := New(WithConfig(config), WithDebug(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			guarded = true
+			return next(c)
+		}
+	}))
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ops/vars", nil))
+	if w.Code != http.StatusUnauthorized || guarded {
+		t.Errorf("without credentials: status = %d, middleware ran = %v", w.Code, guarded)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ops/vars", nil)
+	req.SetBasicAuth("ops", "secret")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !guarded {
+		t.Errorf("with credentials: status = %d, middleware ran = %v", w.Code, guarded)
+	}
+}
+
+func TestDebugEndpoints_Disabled(t *testing.T) {
+	app := New()
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_debug/vars", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestDebugEndpoints_UnguardedWarning(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	tests := []struct {
+		name string
+		opts []Option
+		warn bool
+	}{
+		{"production", []Option{WithDebug()}, true},
+		{"development", []Option{WithDebug(), WithMode(ModeDevelopment)}, false},
+		{"guarded", []Option{WithDebug(BasicAuth(func(user, pass string) bool { return false }))}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			app := New(append(tt.opts, WithEnvFiles(false))...)
+			app.Mount()
+			if got := strings.Contains(buf.String(), "/_debug serves pprof"); got != tt.warn {
+				t.Errorf("warned = %v, want %v: %s", got, tt.warn, buf.String())
+			}
+		})
+	}
+}
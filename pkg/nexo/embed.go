@@ -0,0 +1,44 @@
+package nexo
+
+import (
+	"io/fs"
+	"os"
+)
+
+// embeddedFS holds the project files embedded by nexo build --embed.
+var embeddedFS fs.FS
+
+// SetEmbeddedFS registers project files embedded in the binary. Apps created
+// afterwards read static files, content pages and the asset manifest from
+// fsys instead of the working directory. nexo build --embed generates the
+// call:
+//
+//	//go:embed all:static all:content
+//	var nexoEmbeddedFS embed.FS
+//
+//	func init() { nexo.SetEmbeddedFS(nexoEmbeddedFS) }
+func SetEmbeddedFS(fsys fs.FS) {
+	embeddedFS = fsys
+}
+
+// WithFS sets the file system the app reads static files, content pages and
+// the asset manifest from (default: the files embedded with SetEmbeddedFS,
+// or the working directory).
+func WithFS(fsys fs.FS) Option {
+	return func(a *App) {
+		a.files = fsys
+	}
+}
+
+// FS returns the file system the app reads project files from.
+func (a *App) FS() fs.FS {
+	return a.files
+}
+
+// defaultFS returns the embedded project files, or the working directory.
+func defaultFS() fs.FS {
+	if embeddedFS != nil {
+		return embeddedFS
+	}
+	return os.DirFS(".")
+}
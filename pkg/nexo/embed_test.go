@@ -0,0 +1,49 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApp_FS(t *testing.T) {
+	files := fstest.MapFS{
+		"static/css/site.css":  {Data: []byte("body{}")},
+		"static/manifest.json": {Data: []byte(`{"version":1,"base":"/static","assets":{"js/entry.js":"/static/js/entry-A.js"}}`)},
+		"content/about.md":     {Data: []byte("---\ntitle: About\n---\n\nEmbedded.\n")},
+	}
+
+	SetEmbeddedFS(files)
+	defer SetEmbeddedFS(nil)
+
+	app := New()
+	app.Static("/static", "static")
+	app.Get("/about", app.MarkdownPage("content/about.md"))
+	app.Mount()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/static/css/site.css", "body{}"},
+		{"/about", "<p>Embedded.</p>\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("GET %s = %d %q, want %q", tt.path, w.Code, w.Body.String(), tt.want)
+		}
+	}
+
+	if got := app.Assets().Path("js/entry.js"); got != "/static/js/entry-A.js" {
+		t.Errorf("Assets().Path() = %q, want the embedded manifest's URL", got)
+	}
+
+	// WithFS takes precedence over the embedded files
+	app = New(WithFS(fstest.MapFS{}))
+	if got := app.Assets().Path("js/entry.js"); got != "/static/js/entry.js" {
+		t.Errorf("Assets().Path() = %q with an empty FS", got)
+	}
+}
@@ -0,0 +1,45 @@
+package nexo
+
+import (
+	"log"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/env"
+)
+
+// Env returns the app's typed view of the environment. Outside production,
+// New first loads the project's .env files into it (see package env):
+//
+//	dbURL := app.Env().String("DATABASE_URL", "postgres://localhost/dev")
+//	workers := app.Env().Int("WORKERS", 4)
+//	if err := app.Env().Require("SESSION_SECRET"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *App) Env() *env.Env {
+	if a.env == nil {
+		a.env = env.New()
+	}
+	return a.env
+}
+
+// WithEnvFiles enables or disables loading .env files on New (default:
+// enabled unless the mode is production).
+func WithEnvFiles(enabled bool) Option {
+	return func(a *App) {
+		a.envFiles = enabled
+	}
+}
+
+// loadEnvFiles loads the .env files of the current mode from the working
+// directory. Variables already set in the process environment are kept.
+func (a *App) loadEnvFiles() {
+	mode := env.Mode()
+	if a.mode != "" {
+		mode = string(a.mode)
+	}
+	if !a.envFiles || mode == string(ModeProduction) {
+		return
+	}
+	if _, err := env.Load(".", mode); err != nil {
+		log.Printf("nexo: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package nexo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewLoadsEnvFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", []byte("NEXO_TEST_GREETING=hello\nNEXO_TEST_SET=file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NEXO_ENV", "development")
+	t.Setenv("NEXO_TEST_SET", "process")
+	t.Setenv("NEXO_TEST_GREETING", "")
+	_ = os.Unsetenv("NEXO_TEST_GREETING")
+
+	app := New()
+	if got := app.Env().String("NEXO_TEST_GREETING", ""); got != "hello" {
+		t.Errorf("NEXO_TEST_GREETING = %q, want hello", got)
+	}
+	if got := app.Env().String("NEXO_TEST_SET", ""); got != "process" {
+		t.Errorf("NEXO_TEST_SET = %q, want the process value", got)
+	}
+}
+
+func TestNewSkipsEnvFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", []byte("NEXO_TEST_SKIPPED=loaded\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NEXO_TEST_SKIPPED", "")
+	_ = os.Unsetenv("NEXO_TEST_SKIPPED")
+
+	t.Setenv("NEXO_ENV", "production")
+	New()
+	if _, ok := os.LookupEnv("NEXO_TEST_SKIPPED"); ok {
+		t.Error("production app loaded .env")
+	}
+
+	t.Setenv("NEXO_ENV", "development")
+	New(WithEnvFiles(false))
+	if _, ok := os.LookupEnv("NEXO_TEST_SKIPPED"); ok {
+		t.Error("WithEnvFiles(false) loaded .env")
+	}
+}
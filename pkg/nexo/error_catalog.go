@@ -0,0 +1,225 @@
+package nexo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrorDef defines an error an API returns, so every handler responds to
+// it with the same status and body.
+//
+// Example:
+//
+//	var ErrUserNotFound = nexo.ErrorDef{
+//	    Code:    "user_not_found",
+//	    Status:  http.StatusNotFound,
+//	    Message: "user %s not found",
+//	}
+type ErrorDef struct {
+	// Code identifies the error in responses, like "user_not_found".
+	Code string `json:"code"`
+
+	// Status is the HTTP status of the response, 400 to 599.
+	Status int `json:"status"`
+
+	// Message is the detail of the response. Arguments are applied with
+	// fmt.Sprintf, and catalogs translate it with the key "errors.<code>".
+	Message string `json:"message"`
+
+	// Type is the URI of a page documenting the error (default
+	// "about:blank").
+	Type string `json:"type,omitempty"`
+
+	// Description documents when the error is returned, for the error
+	// catalog (see nexo errors).
+	Description string `json:"description,omitempty"`
+}
+
+// New returns an error for d with args applied to its message. Returned
+// from a handler, it's written as a problem response.
+//
+// Example:
+//
+//	return ErrUserNotFound.New(id)
+func (d ErrorDef) New(args ...any) *CatalogError {
+	return &CatalogError{Def: d, Args: args}
+}
+
+// CatalogError is an error of an ErrorDef. Handlers that return one
+// respond with an RFC 9457 problem response:
+//
+//	{"type": "about:blank", "title": "Not Found", "status": 404,
+//	 "detail": "user 42 not found", "code": "user_not_found"}
+type CatalogError struct {
+	Def  ErrorDef
+	Args []any
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *CatalogError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Def.Code, e.Message(), e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Def.Code, e.Message())
+}
+
+// Unwrap returns the underlying error.
+func (e *CatalogError) Unwrap() error {
+	return e.Err
+}
+
+// Message returns the definition's message with the arguments applied.
+func (e *CatalogError) Message() string {
+	return formatMessage(e.Def.Message, e.Args)
+}
+
+// WithCause sets the underlying error, which is logged but not sent to the
+// client.
+func (e *CatalogError) WithCause(err error) *CatalogError {
+	e.Err = err
+	return e
+}
+
+// formatMessage applies args to message, leaving it as-is without args.
+func formatMessage(message string, args []any) string {
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// ErrorCatalog is a registry of error definitions by code. It's safe for
+// concurrent use.
+type ErrorCatalog struct {
+	mu   sync.RWMutex
+	defs map[string]ErrorDef
+}
+
+// NewErrorCatalog creates an empty ErrorCatalog.
+func NewErrorCatalog() *ErrorCatalog {
+	return &ErrorCatalog{defs: make(map[string]ErrorDef)}
+}
+
+// Register adds error definitions to the catalog. It panics on a
+// definition without a code, with a status outside 400 to 599, or with a
+// code already registered, so codes don't drift apart at runtime.
+func (ec *ErrorCatalog) Register(defs ...ErrorDef) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	for _, d := range defs {
+		if d.Code == "" {
+			panic("nexo: error definition without a code")
+		}
+		if d.Status < 400 || d.Status > 599 {
+			panic(fmt.Sprintf("nexo: error %q has status %d, want 400 to 599", d.Code, d.Status))
+		}
+		if _, ok := ec.defs[d.Code]; ok {
+			panic(fmt.Sprintf("nexo: error %q registered twice", d.Code))
+		}
+		ec.defs[d.Code] = d
+	}
+}
+
+// Lookup returns the definition of code.
+func (ec *ErrorCatalog) Lookup(code string) (ErrorDef, bool) {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	d, ok := ec.defs[code]
+	return d, ok
+}
+
+// Defs returns the definitions sorted by code.
+func (ec *ErrorCatalog) Defs() []ErrorDef {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	defs := make([]ErrorDef, 0, len(ec.defs))
+	for _, d := range ec.defs {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Code < defs[j].Code
+	})
+	return defs
+}
+
+// defaultErrorCatalog holds the errors of contexts not created by an App.
+var defaultErrorCatalog = NewErrorCatalog()
+
+// RegisterErrors adds error definitions for c.Fail. See
+// ErrorCatalog.Register.
+//
+// Example:
+//
+//	app.RegisterErrors(
+//	    nexo.ErrorDef{Code: "user_not_found", Status: 404, Message: "user %s not found"},
+//	    nexo.ErrorDef{Code: "email_taken", Status: 409, Message: "email %s is already registered"},
+//	)
+func (a *App) RegisterErrors(defs ...ErrorDef) {
+	a.routeTree.errors.Register(defs...)
+}
+
+// Errors returns the app's error catalog.
+func (a *App) Errors() *ErrorCatalog {
+	return a.routeTree.errors
+}
+
+// Fail returns the error registered under code with args applied to its
+// message. Return it from the handler to respond with a problem response.
+// An unknown code responds 500.
+//
+// Example:
+//
+//	user, ok := users[id]
+//	if !ok {
+//	    return c.Fail("user_not_found", id)
+//	}
+func (c *Context) Fail(code string, args ...any) error {
+	catalog := c.errors
+	if catalog == nil {
+		catalog = defaultErrorCatalog
+	}
+	d, ok := catalog.Lookup(code)
+	if !ok {
+		return fmt.Errorf("nexo: unknown error code %q", code)
+	}
+	return d.New(args...)
+}
+
+// ---------- Problem Responses ----------
+
+// catalogProblem is the RFC 9457 problem response for a CatalogError.
+type catalogProblem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+}
+
+// writeCatalogError responds with the problem response of err, with the
+// detail translated into the request's locale.
+func writeCatalogError(c *Context, err *CatalogError) {
+	d := err.Def
+	detail := err.Message()
+	if key := "errors." + d.Code; c.T(key) != key {
+		detail = c.T(key, err.Args...)
+	}
+	problemType := d.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	c.SetHeader("Content-Type", "application/problem+json")
+	c.Response.WriteHeader(d.Status)
+	c.written = true
+	c.status = d.Status
+	_ = c.jsonCodec().Encode(c.Response, catalogProblem{
+		Type:   problemType,
+		Title:  http.StatusText(d.Status),
+		Status: d.Status,
+		Detail: detail,
+		Code:   d.Code,
+	})
+}
@@ -0,0 +1,131 @@
+package nexo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFail(t *testing.T) {
+	app := New()
+	app.RegisterErrors(
+		ErrorDef{Code: "user_not_found", Status: http.StatusNotFound, Message: "user %s not found"},
+		ErrorDef{Code: "email_taken", Status: http.StatusConflict, Message: "email is already registered", Type: "https://example.com/errors/email_taken"},
+	)
+	app.Get("/users/{id}", func(c *Context) error {
+		return c.Fail("user_not_found", c.Param("id"))
+	})
+	app.Post("/users", func(c *Context) error {
+		// Wrapped catalog errors keep their response
+		return fmt.Errorf("create user: %w", c.Fail("email_taken"))
+	})
+	app.Get("/unknown", func(c *Context) error {
+		return c.Fail("no_such_code")
+	})
+	app.Mount()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   map[string]any
+	}{
+		{
+			name:       "message with arguments",
+			method:     http.MethodGet,
+			path:       "/users/42",
+			wantStatus: http.StatusNotFound,
+			wantBody: map[string]any{
+				"type":   "about:blank",
+				"title":  "Not Found",
+				"status": float64(404),
+				"detail": "user 42 not found",
+				"code":   "user_not_found",
+			},
+		},
+		{
+			name:       "wrapped with type",
+			method:     http.MethodPost,
+			path:       "/users",
+			wantStatus: http.StatusConflict,
+			wantBody: map[string]any{
+				"type":   "https://example.com/errors/email_taken",
+				"title":  "Conflict",
+				"status": float64(409),
+				"detail": "email is already registered",
+				"code":   "email_taken",
+			},
+		},
+		{
+			name:       "unknown code",
+			method:     http.MethodGet,
+			path:       "/unknown",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody == nil {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for k, want := range tt.wantBody {
+				if body[k] != want {
+					t.Errorf("%s = %v, want %v", k, body[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	catalog := NewErrorCatalog()
+	catalog.Register(
+		ErrorDef{Code: "b", Status: 400, Message: "b"},
+		ErrorDef{Code: "a", Status: 404, Message: "a"},
+	)
+	if defs := catalog.Defs(); len(defs) != 2 || defs[0].Code != "a" || defs[1].Code != "b" {
+		t.Errorf("Defs() = %v", defs)
+	}
+	if d, ok := catalog.Lookup("a"); !ok || d.Status != 404 {
+		t.Errorf("Lookup(a) = %v, %v", d, ok)
+	}
+
+	for _, def := range []ErrorDef{
+		{Status: 400},
+		{Code: "ok", Status: 200},
+		{Code: "a", Status: 404},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%+v) didn't panic", def)
+				}
+			}()
+			catalog.Register(def)
+		}()
+	}
+
+	cause := errors.New("db down")
+	err := ErrorDef{Code: "a", Status: 503, Message: "item %d unavailable"}.New(7).WithCause(cause)
+	if err.Error() != "a: item 7 unavailable: db down" || !errors.Is(err, cause) {
+		t.Errorf("error = %v", err)
+	}
+}
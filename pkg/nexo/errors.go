@@ -16,6 +16,10 @@ var (
 	ErrNoAppDir         = errors.New("app directory not found")
 )
 
+// StatusClientClosedRequest is the status of requests canceled because the
+// client went away, as logged by nginx. The client never sees it.
+const StatusClientClosedRequest = 499
+
 // HTTPError represents an HTTP error with a status code and message.
 type HTTPError struct {
 	Code    int    `json:"code"`
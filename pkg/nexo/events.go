@@ -0,0 +1,100 @@
+package nexo
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/events"
+)
+
+// EventsConfig configures the app's event bus, under events: in
+// nexo.yaml.
+type EventsConfig struct {
+	// Subscribers receive emitted events as signed POST requests.
+	Subscribers []EventSubscriberConfig `mapstructure:"subscribers"`
+
+	// MaxAttempts is how often a delivery is tried before it is
+	// dead-lettered (default: 8).
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// DeadLetterDir keeps deliveries that ran out of attempts as JSON
+	// files, so they survive restarts (default: kept in memory).
+	DeadLetterDir string `mapstructure:"dead_letter_dir"`
+}
+
+// EventSubscriberConfig is a webhook URL receiving events.
+type EventSubscriberConfig struct {
+	// Name identifies the subscriber in dead letters (default: URL).
+	Name string `mapstructure:"name"`
+
+	// URL receives a POST per event.
+	URL string `mapstructure:"url"`
+
+	// Events are the event types sent, like "user.*" (default: all).
+	Events []string `mapstructure:"events"`
+
+	// Secret signs the requests (Standard Webhooks, whsec_...).
+	// Environment variables are expanded, e.g. ${BILLING_HOOK_SECRET}.
+	Secret string `mapstructure:"secret"`
+}
+
+// openAppEvents creates the event bus configured for the app. Invalid
+// subscribers are logged and skipped.
+func openAppEvents(config EventsConfig) *events.Bus {
+	busConfig := events.Config{MaxAttempts: config.MaxAttempts}
+	if config.DeadLetterDir != "" {
+		dead, err := events.NewFileDeadLetters(config.DeadLetterDir)
+		if err != nil {
+			log.Printf("nexo: events: %v; keeping dead letters in memory", err)
+		} else {
+			busConfig.DeadLetters = dead
+		}
+	}
+	bus := events.New(busConfig)
+	for _, sub := range config.Subscribers {
+		err := bus.Subscribe(events.Subscriber{
+			Name:   sub.Name,
+			URL:    sub.URL,
+			Events: sub.Events,
+			Secret: os.ExpandEnv(sub.Secret),
+		})
+		if err != nil {
+			log.Printf("nexo: %v", err)
+		}
+	}
+	return bus
+}
+
+// Emit sends an event of type typ with data, marshaled as JSON, to the
+// subscribers of the app's event bus. Deliveries run in the background and
+// are retried until they succeed, so Emit only fails when the event can't
+// be queued.
+//
+// Example:
+//
+//	if err := c.Emit("user.created", user); err != nil {
+//	    return err
+//	}
+func (c *Context) Emit(typ string, data any) error {
+	if c.events == nil {
+		return nil
+	}
+	_, err := c.events.Emit(c.Context(), typ, data)
+	return err
+}
+
+// Events returns the app's event bus (see WithEvents), e.g. to handle
+// events inside the app with Events().On.
+func (a *App) Events() *events.Bus {
+	return a.routeTree.events
+}
+
+// closeEvents stops the event bus, dead-lettering deliveries still waiting
+// for a retry.
+func (a *App) closeEvents(ctx context.Context) error {
+	if a.routeTree.events == nil {
+		return nil
+	}
+	return a.routeTree.events.Close(ctx)
+}
@@ -0,0 +1,68 @@
+package nexo
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/events"
+)
+
+func TestContext_Emit(t *testing.T) {
+	secret := "whsec_" + base64.StdEncoding.EncodeToString([]byte("billing-secret"))
+	t.Setenv("BILLING_HOOK_SECRET", secret)
+
+	// The subscriber is a nexo app verifying the signature
+	received := make(chan string, 1)
+	billing := New()
+	billing.Post("/hooks", Webhook(StandardSignature(secret))(func(c *Context) error {
+		var event events.Event
+		if err := c.Bind(&event); err != nil {
+			return err
+		}
+		received <- event.Type + " " + string(event.Data)
+		return c.NoContent()
+	}))
+	billing.Mount()
+	srv := httptest.NewServer(billing)
+	defer srv.Close()
+
+	config := DefaultConfig()
+	config.Events.Subscribers = []EventSubscriberConfig{
+		{Name: "billing", URL: srv.URL + "/hooks", Events: []string{"user.*"}, Secret: "${BILLING_HOOK_SECRET}"},
+	}
+	app := New(WithConfig(config))
+	defer app.Shutdown(context.Background())
+	handled := make(chan string, 1)
+	app.Events().On("user.created", func(_ context.Context, e events.Event) error {
+		handled <- e.ID
+		return nil
+	})
+	app.Post("/users", func(c *Context) error {
+		if err := c.Emit("user.created", map[string]int{"id": 42}); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("POST /users = %d", w.Code)
+	}
+
+	for _, ch := range []chan string{received, handled} {
+		select {
+		case got := <-ch:
+			if ch == received && got != `user.created {"id":42}` {
+				t.Errorf("subscriber received %q", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("the event wasn't delivered")
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package nexo
+
+import (
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Experiment buckets visitors into the variants of an A/B test, so a proxy
+// can rewrite them to a variant's pages. The variant of a visitor is kept in
+// a cookie; new visitors are bucketed by a hash of their client IP and
+// User-Agent, so the same visitor gets the same variant even before the
+// cookie is set.
+//
+// Example:
+//
+//	var pricing = nexo.Experiment{
+//	    Name:     "pricing",
+//	    Variants: []string{"control", "annual"},
+//	}
+//
+//	func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+//	    if c.Path() == "/pricing" {
+//	        return pricing.Rewrite(c), nil // /pricing or /annual/pricing
+//	    }
+//	    return nexo.Continue(), nil
+//	}
+type Experiment struct {
+	// Name identifies the experiment. Visitors are bucketed independently
+	// in each experiment.
+	Name string
+
+	// Variants lists the variants; the first is the control, which isn't
+	// rewritten.
+	Variants []string
+
+	// Weights are the relative weights of Variants, like []int{90, 10}.
+	// Empty (or of another length) splits traffic evenly.
+	Weights []int
+
+	// Cookie is the cookie holding the variant. Default is "ab_" + Name.
+	Cookie string
+
+	// MaxAge is how long the variant cookie lasts. Default is 30 days.
+	MaxAge time.Duration
+
+	// Header is a request header that forces a variant, like "X-Variant",
+	// for QA or a CDN that buckets visitors itself. Forced variants aren't
+	// stored in the cookie. Empty disables it.
+	Header string
+
+	// KeyFunc returns the key new visitors are bucketed by. Default is the
+	// client IP and User-Agent.
+	KeyFunc func(c *Context) string
+
+	// PathFunc returns the path a variant of path is rewritten to. Default
+	// is "/" + variant + path, and "/" + variant for the root.
+	PathFunc func(variant, path string) string
+}
+
+// Variant returns the visitor's variant, assigning one on the first visit.
+// A new assignment is set as a response cookie and added to the request, so
+// handlers after a proxy rewrite see the same variant. It returns "" when
+// the experiment has no variants.
+func (e Experiment) Variant(c *Context) string {
+	if len(e.Variants) == 0 {
+		return ""
+	}
+	if e.Header != "" {
+		if v := c.Header(e.Header); slices.Contains(e.Variants, v) {
+			return v
+		}
+	}
+	cookie := e.cookieName()
+	if v := c.Cookie(cookie); slices.Contains(e.Variants, v) {
+		return v
+	}
+
+	key := c.ClientIP() + "|" + c.Header("User-Agent")
+	if e.KeyFunc != nil {
+		key = e.KeyFunc(c)
+	}
+	variant := Bucket(e.Name+":"+key, e.Variants, e.Weights)
+
+	maxAge := e.MaxAge
+	if maxAge <= 0 {
+		maxAge = 30 * 24 * time.Hour
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     cookie,
+		Value:    variant,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   c.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Request.AddCookie(&http.Cookie{Name: cookie, Value: variant})
+	return variant
+}
+
+// Rewrite returns a proxy result that rewrites the request to the visitor's
+// variant of the path, or continues for the control variant. Responses get
+// Vary: Cookie, so shared caches keep each variant apart.
+func (e Experiment) Rewrite(c *Context) *ProxyResult {
+	variant := e.Variant(c)
+	c.AddHeader("Vary", "Cookie")
+	if variant == "" || variant == e.Variants[0] {
+		return Continue()
+	}
+	if e.PathFunc != nil {
+		return Rewrite(e.PathFunc(variant, c.Path()))
+	}
+	if c.Path() == "/" {
+		return Rewrite("/" + variant)
+	}
+	return Rewrite("/" + variant + c.Path())
+}
+
+// cookieName returns the cookie holding the variant.
+func (e Experiment) cookieName() string {
+	if e.Cookie != "" {
+		return e.Cookie
+	}
+	return "ab_" + e.Name
+}
+
+// Bucket deterministically assigns key to one of variants, weighted by
+// weights (even when weights is empty or of another length). The same key
+// always gets the same variant, so it can bucket by a user ID or session
+// cookie without storing the assignment.
+//
+// Example:
+//
+//	variant := nexo.Bucket(c.Cookie("session_id"), []string{"a", "b"}, nil)
+func Bucket(key string, variants []string, weights []int) string {
+	if len(variants) == 0 {
+		return ""
+	}
+	if len(weights) != len(variants) {
+		weights = nil
+	}
+	total := 0
+	for i := range variants {
+		total += bucketWeight(weights, i)
+	}
+	if total == 0 {
+		return variants[0]
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	n := int(h.Sum64() % uint64(total))
+	for i, v := range variants {
+		if n -= bucketWeight(weights, i); n < 0 {
+			return v
+		}
+	}
+	return variants[len(variants)-1]
+}
+
+// bucketWeight returns the weight of variant i; 1 without weights.
+func bucketWeight(weights []int, i int) int {
+	if weights == nil {
+		return 1
+	}
+	return max(weights[i], 0)
+}
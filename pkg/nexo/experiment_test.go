@@ -0,0 +1,134 @@
+package nexo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	variants := []string{"a", "b"}
+	if Bucket("user-1", variants, nil) != Bucket("user-1", variants, nil) {
+		t.Error("Bucket is not deterministic")
+	}
+	if got := Bucket("user-1", nil, nil); got != "" {
+		t.Errorf("Bucket without variants = %q, want empty", got)
+	}
+
+	tests := []struct {
+		name    string
+		weights []int
+		wantA   [2]int // range of "a" out of 1000 keys
+	}{
+		{name: "even", weights: nil, wantA: [2]int{420, 580}},
+		{name: "weighted", weights: []int{9, 1}, wantA: [2]int{860, 940}},
+		{name: "zero weight", weights: []int{0, 1}, wantA: [2]int{0, 0}},
+		{name: "mismatched weights", weights: []int{1}, wantA: [2]int{420, 580}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := 0
+			for i := range 1000 {
+				if Bucket(fmt.Sprintf("user-%d", i), variants, tt.weights) == "a" {
+					a++
+				}
+			}
+			if a < tt.wantA[0] || a > tt.wantA[1] {
+				t.Errorf("%d of 1000 keys got a, want %d-%d", a, tt.wantA[0], tt.wantA[1])
+			}
+		})
+	}
+}
+
+func TestExperimentVariant(t *testing.T) {
+	exp := Experiment{Name: "pricing", Variants: []string{"control", "annual"}, Header: "X-Variant"}
+
+	tests := []struct {
+		name       string
+		cookie     string
+		header     string
+		want       string
+		wantCookie bool
+	}{
+		{name: "cookie", cookie: "annual", want: "annual"},
+		{name: "header forces variant", cookie: "annual", header: "control", want: "control"},
+		{name: "unknown cookie is reassigned", cookie: "gone", wantCookie: true},
+		{name: "new visitor", wantCookie: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/pricing", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "ab_pricing", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Variant", tt.header)
+			}
+			w := httptest.NewRecorder()
+			c := NewContext(w, req)
+
+			got := exp.Variant(c)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Variant() = %q, want %q", got, tt.want)
+			}
+			setCookie := w.Header().Get("Set-Cookie")
+			if tt.wantCookie != (setCookie != "") {
+				t.Errorf("Set-Cookie = %q, want cookie: %v", setCookie, tt.wantCookie)
+			}
+			if tt.wantCookie {
+				if !strings.HasPrefix(setCookie, "ab_pricing="+got+";") {
+					t.Errorf("Set-Cookie = %q, want variant %q", setCookie, got)
+				}
+				if exp.Variant(c) != got {
+					t.Error("variant changed within the request")
+				}
+			}
+		})
+	}
+}
+
+func TestExperimentRewrite(t *testing.T) {
+	exp := Experiment{Name: "pricing", Variants: []string{"control", "annual"}}
+
+	app := New()
+	_ = app.SetProxy(func(c *Context) (*ProxyResult, error) {
+		if c.Path() == "/pricing" {
+			return exp.Rewrite(c), nil
+		}
+		return Continue(), nil
+	}, nil)
+	app.Get("/pricing", func(c *Context) error {
+		return c.String(http.StatusOK, "control "+exp.Variant(c))
+	})
+	app.Get("/annual/pricing", func(c *Context) error {
+		return c.String(http.StatusOK, "annual "+exp.Variant(c))
+	})
+	app.Mount()
+
+	for _, variant := range exp.Variants {
+		req := httptest.NewRequest(http.MethodGet, "/pricing", nil)
+		req.AddCookie(&http.Cookie{Name: "ab_pricing", Value: variant})
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if want := variant + " " + variant; w.Body.String() != want {
+			t.Errorf("variant %s: body = %q, want %q", variant, w.Body.String(), want)
+		}
+		if w.Header().Get("Vary") != "Cookie" {
+			t.Errorf("variant %s: Vary = %q, want Cookie", variant, w.Header().Get("Vary"))
+		}
+	}
+
+	// A new visitor keeps the variant assigned by the proxy
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pricing", nil))
+	if got := w.Header().Values("Set-Cookie"); len(got) != 1 {
+		t.Fatalf("Set-Cookie = %q, want one cookie", got)
+	}
+	kind, variant, _ := strings.Cut(w.Body.String(), " ")
+	if kind != variant {
+		t.Errorf("rewritten to %s pages for variant %s", kind, variant)
+	}
+}
@@ -0,0 +1,117 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+)
+
+// FlagsConfig configures the app's feature flags, under flags: in
+// nexo.yaml. Flags are read from Set, then File, then environment
+// variables starting with EnvPrefix, each overriding the ones before.
+type FlagsConfig struct {
+	// Set defines flags in nexo.yaml, with values like those of flag
+	// variables: true, 25% or user:7,user:9. It is applied on reload
+	// (see App.WatchConfig).
+	Set map[string]any `mapstructure:"set"`
+
+	// File is a JSON or TOML flags file, like flags.json (optional).
+	File string `mapstructure:"file"`
+
+	// EnvPrefix is the prefix of flag variables (default: NEXO_FLAG_).
+	EnvPrefix string `mapstructure:"env_prefix"`
+
+	// Refresh is how often the flags are reloaded (default: 1s).
+	Refresh time.Duration `mapstructure:"refresh"`
+}
+
+// openAppFlags creates the flag store configured for the app. set returns
+// the current flags.set, which changes on reload.
+func openAppFlags(config FlagsConfig, set func() map[string]any) *flags.Store {
+	refresh := config.Refresh
+	if refresh <= 0 {
+		refresh = time.Second
+	}
+	providers := []flags.Provider{configFlags(set)}
+	if config.File != "" {
+		providers = append(providers, flags.NewFileProvider(config.File))
+	}
+	providers = append(providers, flags.NewEnvProvider(config.EnvPrefix))
+	return flags.NewStore(refresh, providers...)
+}
+
+// configFlags provides the flags of flags.set.
+func configFlags(set func() map[string]any) flags.Provider {
+	return flags.ProviderFunc(func(context.Context) (map[string]flags.Flag, error) {
+		defined := make(map[string]flags.Flag)
+		for name, v := range set() {
+			value := fmt.Sprint(v)
+			if list, ok := v.([]any); ok {
+				targets := make([]string, len(list))
+				for i, t := range list {
+					targets[i] = fmt.Sprint(t)
+				}
+				value = strings.Join(targets, ",")
+			}
+			f, err := flags.ParseValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("flags: flags.set.%s: %w", name, err)
+			}
+			defined[name] = f
+		}
+		return defined, nil
+	})
+}
+
+// Flag reports whether the feature flag name is on for the request. Flags
+// with a percentage or targets are evaluated for the request's flag key
+// (see WithFlagKey). Undefined flags are off.
+//
+// Example:
+//
+//	if c.Flag("new-checkout") {
+//	    return c.Render(200, NewCheckout(cart))
+//	}
+func (c *Context) Flag(name string) bool {
+	return c.Flags().Enabled(c.Request.Context(), name)
+}
+
+// Flags returns the evaluator of the app's feature flags for the request.
+func (c *Context) Flags() *flags.Evaluator {
+	if c.flagsFor == nil {
+		store := c.flags
+		if store == nil {
+			store = flags.NewStore(0)
+		}
+		key := defaultFlagKey
+		if c.flagKey != nil {
+			key = c.flagKey
+		}
+		c.flagsFor = store.For(key(c))
+	}
+	return c.flagsFor
+}
+
+// defaultFlagKey keys flags by the client IP and User-Agent, which keeps a
+// visitor's percentage rollouts stable without a login.
+func defaultFlagKey(c *Context) string {
+	return c.ClientIP() + "|" + c.Header("User-Agent")
+}
+
+// attachFlags makes the request's flags available to flags.Enabled in
+// templ components.
+func (c *Context) attachFlags() {
+	if c.flags == nil || c.flagsAttached {
+		return
+	}
+	c.flagsAttached = true
+	c.Request = c.Request.WithContext(flags.NewContext(c.Request.Context(), c.Flags()))
+}
+
+// Flags returns the app's feature flag store (see WithFlags).
+func (a *App) Flags() *flags.Store {
+	return a.routeTree.flags
+}
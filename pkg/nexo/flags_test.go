@@ -0,0 +1,84 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+)
+
+func TestContext_Flag(t *testing.T) {
+	store := flags.NewStore(0, flags.ProviderFunc(func(context.Context) (map[string]flags.Flag, error) {
+		return map[string]flags.Flag{
+			"new-checkout": {Enabled: true, Targets: []string{"user:42"}},
+			"dark-mode":    {Enabled: true},
+		}, nil
+	}))
+	app := New(WithFlags(store), WithFlagKey(func(c *Context) string {
+		return c.Header("X-User")
+	}))
+	app.Get("/", func(c *Context) error {
+		return c.Render(http.StatusOK, templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			out := "old"
+			if flags.Enabled(ctx, "new-checkout") {
+				out = "new"
+			}
+			if c.Flag("dark-mode") {
+				out += " dark"
+			}
+			_, err := io.WriteString(w, out)
+			return err
+		}))
+	})
+	app.Mount()
+
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"user:42", "new dark"},
+		{"user:7", "old dark"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", tt.user)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagsConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("flags.json", []byte(`{"beta": {"enabled": true}, "new-nav": {"enabled": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NEXO_FLAG_NEW_NAV", "off")
+
+	config := DefaultConfig()
+	config.Flags.File = "flags.json"
+	app := New(WithConfig(config))
+	var beta, newNav bool
+	app.Get("/", func(c *Context) error {
+		beta, newNav = c.Flag("beta"), c.Flag("new-nav")
+		return c.NoContent()
+	})
+	app.Mount()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !beta {
+		t.Error("beta from flags.json is off")
+	}
+	if newNav {
+		t.Error("new-nav is on, want NEXO_FLAG_NEW_NAV to override flags.json")
+	}
+}
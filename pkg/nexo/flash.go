@@ -0,0 +1,360 @@
+package nexo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// FlashMessage is a one-time message shown on the next request, e.g.
+// "Profile saved" after a POST-redirect-GET.
+type FlashMessage struct {
+	Kind    string `json:"k"` // e.g. "success", "error", "info"
+	Message string `json:"m"`
+}
+
+// flashData is the payload of the flash cookie.
+type flashData struct {
+	Messages []FlashMessage    `json:"f,omitempty"`
+	Input    url.Values        `json:"i,omitempty"`
+	Errors   map[string]string `json:"e,omitempty"`
+}
+
+// empty reports whether d carries nothing worth a cookie.
+func (d *flashData) empty() bool {
+	return d == nil || (len(d.Messages) == 0 && len(d.Input) == 0 && len(d.Errors) == 0)
+}
+
+const (
+	// flashCookieName is the cookie that carries flash data to the next request.
+	flashCookieName = "nexo_flash"
+
+	// maxFlashCookieSize keeps the cookie under the 4KB browsers accept.
+	// Old input is dropped when it doesn't fit.
+	maxFlashCookieSize = 3800
+)
+
+var (
+	defaultSecretOnce sync.Once
+	defaultSecret     []byte
+)
+
+// secretKey returns the key that signs flash cookies. Without WithSecret a
+// random per-process key is used, so flashes don't survive a restart and
+// aren't shared between instances.
+func (c *Context) secretKey() []byte {
+	if len(c.secret) > 0 {
+		return c.secret
+	}
+	defaultSecretOnce.Do(func() {
+		defaultSecret = make([]byte, 32)
+		_, _ = rand.Read(defaultSecret)
+	})
+	return defaultSecret
+}
+
+// ---------- Context Flash and Old Input ----------
+
+// Flash adds a message for the next request. Call it before writing the
+// response, typically right before a redirect.
+//
+// Example:
+//
+//	func Post(c *nexo.Context) error {
+//	    // save...
+//	    c.Flash("success", "Profile saved")
+//	    return c.Redirect("/profile", http.StatusSeeOther)
+//	}
+func (c *Context) Flash(kind, message string) {
+	out := c.outgoingFlash()
+	out.Messages = append(out.Messages, FlashMessage{Kind: kind, Message: message})
+	c.writeFlash()
+}
+
+// FlashInput keeps the submitted form values for the next request, so the
+// form can be repopulated with OldInput. Fields whose name contains
+// "password", and fields starting with "_" (such as CSRF tokens), are left out.
+func (c *Context) FlashInput() {
+	_ = c.Request.ParseForm()
+
+	out := c.outgoingFlash()
+	for key, values := range c.Request.PostForm {
+		if strings.HasPrefix(key, "_") || strings.Contains(strings.ToLower(key), "password") {
+			continue
+		}
+		if out.Input == nil {
+			out.Input = make(url.Values)
+		}
+		out.Input[key] = values
+	}
+	c.writeFlash()
+}
+
+// FlashErrors keeps field validation errors for the next request, where
+// they are read with FieldError.
+//
+// Example:
+//
+//	if errs := validate(form); len(errs) > 0 {
+//	    c.FlashInput()
+//	    c.FlashErrors(errs)
+//	    return c.Redirect("/signup", http.StatusSeeOther)
+//	}
+func (c *Context) FlashErrors(errs map[string]string) {
+	out := c.outgoingFlash()
+	for field, msg := range errs {
+		if out.Errors == nil {
+			out.Errors = make(map[string]string, len(errs))
+		}
+		out.Errors[field] = msg
+	}
+	c.writeFlash()
+}
+
+// Flashes returns the messages flashed by the previous request.
+func (c *Context) Flashes() []FlashMessage {
+	if d := c.incomingFlash(); d != nil {
+		return d.Messages
+	}
+	return nil
+}
+
+// OldInput returns a form value submitted in the previous request and kept
+// with FlashInput, or "".
+func (c *Context) OldInput(key string) string {
+	if d := c.incomingFlash(); d != nil {
+		return d.Input.Get(key)
+	}
+	return ""
+}
+
+// FieldError returns the validation error flashed for a field, or "".
+func (c *Context) FieldError(field string) string {
+	if d := c.incomingFlash(); d != nil {
+		return d.Errors[field]
+	}
+	return ""
+}
+
+// FieldErrors returns all validation errors flashed by the previous request.
+func (c *Context) FieldErrors() map[string]string {
+	if d := c.incomingFlash(); d != nil {
+		return d.Errors
+	}
+	return nil
+}
+
+// outgoingFlash returns the flash data for the next request.
+func (c *Context) outgoingFlash() *flashData {
+	if c.flashOut == nil {
+		c.flashOut = &flashData{}
+	}
+	return c.flashOut
+}
+
+// incomingFlash reads the flash data sent by the previous request once, and
+// clears the cookie so it is shown only once.
+func (c *Context) incomingFlash() *flashData {
+	if c.flashRead {
+		return c.flashIn
+	}
+	c.flashRead = true
+
+	cookie, err := c.Request.Cookie(flashCookieName)
+	if err != nil {
+		return nil
+	}
+	c.flashIn = decodeFlash(cookie.Value, c.secretKey())
+	if c.flashOut == nil {
+		c.setFlashCookie(&http.Cookie{Name: flashCookieName, Path: "/", MaxAge: -1})
+	}
+	return c.flashIn
+}
+
+// writeFlash replaces the outgoing flash cookie with the current data.
+func (c *Context) writeFlash() {
+	value := encodeFlash(c.flashOut, c.secretKey())
+	if len(value) > maxFlashCookieSize && len(c.flashOut.Input) > 0 {
+		trimmed := *c.flashOut
+		trimmed.Input = nil
+		value = encodeFlash(&trimmed, c.secretKey())
+	}
+
+	c.setFlashCookie(&http.Cookie{
+		Name:     flashCookieName,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// setFlashCookie sets cookie, replacing any flash cookie already set on the
+// response.
+func (c *Context) setFlashCookie(cookie *http.Cookie) {
+	header := c.Response.Header()
+	kept := header["Set-Cookie"][:0]
+	for _, v := range header["Set-Cookie"] {
+		if !strings.HasPrefix(v, flashCookieName+"=") {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		header.Del("Set-Cookie")
+	} else {
+		header["Set-Cookie"] = kept
+	}
+	http.SetCookie(c.Response, cookie)
+}
+
+// encodeFlash serializes and signs d: base64(json) "." base64(hmac).
+func encodeFlash(d *flashData, key []byte) string {
+	payload, _ := json.Marshal(d)
+	p := base64.RawURLEncoding.EncodeToString(payload)
+	return p + "." + base64.RawURLEncoding.EncodeToString(signFlash(p, key))
+}
+
+// decodeFlash verifies and decodes a flash cookie, returning nil if it has
+// been tampered with.
+func decodeFlash(value string, key []byte) *flashData {
+	p, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, signFlash(p, key)) {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return nil
+	}
+	var d flashData
+	if err := json.Unmarshal(payload, &d); err != nil || d.empty() {
+		return nil
+	}
+	return &d
+}
+
+// signFlash returns the HMAC-SHA256 of payload.
+func signFlash(payload string, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// ---------- Form Templ Helpers ----------
+
+// flashContextKey is the context key for the flash data of a rendered request.
+type flashContextKey struct{}
+
+// renderContext returns the context for rendering templ components. It
+// carries the previous request's flash data for the form helpers, the
+// response's Head, and the cache for CacheFragment. Call it
+// before writing headers, since reading flash data clears its cookie.
+func (c *Context) renderContext() context.Context {
+	if d := c.incomingFlash(); d != nil && !c.flashAttached {
+		c.flashAttached = true
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), flashContextKey{}, d))
+	}
+	c.attachHead()
+	c.attachAssets()
+	c.attachFlags()
+	c.attachCache()
+	return c.Context()
+}
+
+// flashFromContext returns the flash data carried by ctx, or nil.
+func flashFromContext(ctx context.Context) *flashData {
+	d, _ := ctx.Value(flashContextKey{}).(*flashData)
+	return d
+}
+
+// Old returns a form value kept with FlashInput, for repopulating a form
+// in a templ component:
+//
+//	<input name="email" value={ nexo.Old(ctx, "email") }/>
+func Old(ctx context.Context, field string) string {
+	if d := flashFromContext(ctx); d != nil {
+		return d.Input.Get(field)
+	}
+	return ""
+}
+
+// HasFieldError reports whether a validation error was flashed for field.
+func HasFieldError(ctx context.Context, field string) bool {
+	d := flashFromContext(ctx)
+	return d != nil && d.Errors[field] != ""
+}
+
+// FieldErrorMessage renders the validation error flashed for field as
+// <p class="field-error">, or nothing:
+//
+//	<input name="email" value={ nexo.Old(ctx, "email") }/>
+//	@nexo.FieldErrorMessage("email")
+func FieldErrorMessage(field string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		d := flashFromContext(ctx)
+		if d == nil || d.Errors[field] == "" {
+			return nil
+		}
+		_, err := io.WriteString(w, `<p class="field-error" id="`+html.EscapeString(field)+`-error">`+
+			html.EscapeString(d.Errors[field])+`</p>`)
+		return err
+	})
+}
+
+// FormErrors renders all flashed validation errors as a list, or nothing.
+func FormErrors() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		d := flashFromContext(ctx)
+		if d == nil || len(d.Errors) == 0 {
+			return nil
+		}
+		fields := make([]string, 0, len(d.Errors))
+		for f := range d.Errors {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+
+		var b strings.Builder
+		b.WriteString(`<ul class="form-errors" role="alert">`)
+		for _, f := range fields {
+			b.WriteString(`<li>` + html.EscapeString(d.Errors[f]) + `</li>`)
+		}
+		b.WriteString(`</ul>`)
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+}
+
+// FlashMessages renders the flashed messages as
+// <div class="flash flash-{kind}" role="alert">, or nothing.
+func FlashMessages() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		d := flashFromContext(ctx)
+		if d == nil {
+			return nil
+		}
+		var b strings.Builder
+		for _, m := range d.Messages {
+			b.WriteString(`<div class="flash flash-` + html.EscapeString(m.Kind) + `" role="alert">` +
+				html.EscapeString(m.Message) + `</div>`)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+}
@@ -0,0 +1,126 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+// newFlashApp mounts a signup form using the post-redirect-get pattern.
+func newFlashApp() *App {
+	app := New(WithSecret([]byte("test-secret")))
+	app.Post("/signup", func(c *Context) error {
+		c.Flash("error", "Please fix the errors below")
+		c.FlashInput()
+		c.FlashErrors(map[string]string{"email": "Email is <invalid>"})
+		return c.Redirect("/signup", http.StatusSeeOther)
+	})
+	app.Get("/signup", func(c *Context) error {
+		return c.Render(http.StatusOK, templ.Join(
+			FlashMessages(),
+			templ.Raw(`<input name="email" value="`+c.OldInput("email")+`">`),
+			FieldErrorMessage("email"),
+			templ.Raw(`password=`+c.OldInput("password")),
+		))
+	})
+	app.Mount()
+	return app
+}
+
+// flashCookie returns the flash cookie set by a response, or nil.
+func flashCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == flashCookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestFlash_PostRedirectGet(t *testing.T) {
+	app := newFlashApp()
+
+	form := url.Values{"email": {"ana@"}, "password": {"hunter2"}, "_csrf": {"token"}}
+	r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	cookie := flashCookie(w)
+	if w.Code != http.StatusSeeOther || cookie == nil {
+		t.Fatalf("POST = %d with flash cookie %v, want 303 and a cookie", w.Code, cookie)
+	}
+	if n := len(w.Result().Header.Values("Set-Cookie")); n != 1 {
+		t.Errorf("POST set %d cookies, want the flash cookie once", n)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/signup", nil)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`<div class="flash flash-error" role="alert">Please fix the errors below</div>`,
+		`value="ana@"`,
+		`<p class="field-error" id="email-error">Email is &lt;invalid&gt;</p>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "hunter2") {
+		t.Error("password was kept as old input")
+	}
+	if c := flashCookie(w); c == nil || c.MaxAge >= 0 {
+		t.Errorf("GET flash cookie = %v, want it cleared", c)
+	}
+}
+
+func TestFlash_TamperedCookieIgnored(t *testing.T) {
+	app := newFlashApp()
+
+	forged := encodeFlash(&flashData{Messages: []FlashMessage{{Kind: "info", Message: "forged"}}}, []byte("other-secret"))
+	r := httptest.NewRequest(http.MethodGet, "/signup", nil)
+	r.AddCookie(&http.Cookie{Name: flashCookieName, Value: forged})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if strings.Contains(w.Body.String(), "forged") {
+		t.Error("flash signed with another secret was rendered")
+	}
+}
+
+func TestFlash_WithoutCookie(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if c.Flashes() != nil || c.OldInput("email") != "" || c.FieldError("email") != "" || c.FieldErrors() != nil {
+		t.Error("expected no flash data without a cookie")
+	}
+	if len(c.Response.Header().Values("Set-Cookie")) != 0 {
+		t.Error("reading absent flash data set a cookie")
+	}
+}
+
+func TestFormErrors(t *testing.T) {
+	d := &flashData{Errors: map[string]string{"name": "Name is required", "email": "Email is invalid"}}
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Request.AddCookie(&http.Cookie{Name: flashCookieName, Value: encodeFlash(d, c.secretKey())})
+
+	var b strings.Builder
+	if err := FormErrors().Render(c.renderContext(), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<ul class="form-errors" role="alert"><li>Email is invalid</li><li>Name is required</li></ul>`
+	if b.String() != want {
+		t.Errorf("FormErrors() = %q, want %q", b.String(), want)
+	}
+	if !HasFieldError(c.renderContext(), "name") || HasFieldError(c.renderContext(), "age") {
+		t.Error("HasFieldError() reported the wrong fields")
+	}
+}
@@ -0,0 +1,123 @@
+package nexo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// FragmentCacheConfig configures CacheFragmentWithConfig.
+type FragmentCacheConfig struct {
+	// Key identifies the fragment in the cache. Fragments rendered for a
+	// tenant are cached per tenant.
+	Key string
+
+	// TTL is how long the fragment is served from the cache. Zero keeps it
+	// until it is evicted or revalidated.
+	TTL time.Duration
+
+	// Tags let RevalidateTag drop the fragment, like the tags of cached
+	// responses.
+	Tags []string
+
+	// Cache stores the fragment (default: the app's cache backend).
+	Cache Cache
+}
+
+// cachedFragment is a fragment stored by CacheFragment.
+type cachedFragment struct {
+	HTML     []byte   `json:"html"`
+	Tags     []string `json:"tags,omitempty"`
+	StoredAt int64    `json:"stored_at"` // UnixNano
+}
+
+// cacheContextKey is the context key for the cache of a rendered request.
+type cacheContextKey struct{}
+
+// attachCache carries the app's cache in the request context, so
+// CacheFragment can use it while templ components render.
+func (c *Context) attachCache() {
+	if c.cacheAttached {
+		return
+	}
+	c.cacheAttached = true
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), cacheContextKey{}, c.Cache()))
+}
+
+// CacheFragment renders component once and serves its HTML from the app's
+// cache for ttl, without running it again. Use it for expensive partials,
+// like a navigation menu built from the database.
+//
+// Example:
+//
+//	templ Layout() {
+//	    @nexo.CacheFragment("nav", 10*time.Minute, Nav())
+//	    { children... }
+//	}
+func CacheFragment(key string, ttl time.Duration, component templ.Component) templ.Component {
+	return CacheFragmentWithConfig(component, FragmentCacheConfig{Key: key, TTL: ttl})
+}
+
+// CacheFragmentWithConfig renders component through the cache, as
+// configured by config. Outside a request rendered by the app, and without
+// config.Cache, the component is rendered as is. Cache errors are ignored,
+// so a failing cache only costs the render.
+func CacheFragmentWithConfig(component templ.Component, config FragmentCacheConfig) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		cache := config.Cache
+		if cache == nil {
+			cache, _ = ctx.Value(cacheContextKey{}).(Cache)
+		}
+		if cache == nil {
+			return component.Render(ctx, w)
+		}
+
+		key := fragmentCacheKey(ctx, config.Key)
+		if data, ok, err := cache.Get(ctx, key); err == nil && ok {
+			var cached cachedFragment
+			if json.Unmarshal(data, &cached) == nil && !fragmentRevalidated(ctx, cache, cached) {
+				_, err := w.Write(cached.HTML)
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := component.Render(ctx, &buf); err != nil {
+			return err
+		}
+		cached := cachedFragment{
+			HTML:     buf.Bytes(),
+			Tags:     config.Tags,
+			StoredAt: time.Now().UnixNano(),
+		}
+		if data, err := json.Marshal(cached); err == nil {
+			_ = cache.Set(ctx, key, data, config.TTL)
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+}
+
+// fragmentCacheKey returns the cache key of a fragment, scoped to the
+// tenant rendering it.
+func fragmentCacheKey(ctx context.Context, key string) string {
+	var tenant string
+	if t, ok := TenantFromContext(ctx); ok && t != nil {
+		tenant = "tenant:" + t.ID + ":"
+	}
+	return "fragment:" + tenant + key
+}
+
+// fragmentRevalidated reports whether a tag of a cached fragment was
+// revalidated after it was stored.
+func fragmentRevalidated(ctx context.Context, cache Cache, cached cachedFragment) bool {
+	targets := make([]string, len(cached.Tags))
+	for i, tag := range cached.Tags {
+		targets[i] = "tag:" + tag
+	}
+	return revalidatedAfter(ctx, cache, targets, cached.StoredAt)
+}
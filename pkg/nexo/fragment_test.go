@@ -0,0 +1,93 @@
+package nexo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// countingComponent renders how many times it has rendered.
+func countingComponent(renders *int) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		*renders++
+		_, err := fmt.Fprintf(w, "<nav>%d</nav>", *renders)
+		return err
+	})
+}
+
+func renderFragment(t *testing.T, ctx context.Context, component templ.Component) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := component.Render(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestCacheFragment(t *testing.T) {
+	cache := NewMemoryCache(0)
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.cache = cache
+	ctx := c.renderContext()
+
+	var renders int
+	nav := CacheFragmentWithConfig(countingComponent(&renders), FragmentCacheConfig{
+		Key:  "nav",
+		TTL:  time.Minute,
+		Tags: []string{"menu"},
+	})
+
+	if got := renderFragment(t, ctx, nav); got != "<nav>1</nav>" {
+		t.Errorf("first render = %q", got)
+	}
+	if got := renderFragment(t, ctx, nav); got != "<nav>1</nav>" || renders != 1 {
+		t.Errorf("cached render = %q after %d renders, want the first render", got, renders)
+	}
+	if _, ok, _ := cache.Get(ctx, "fragment:nav"); !ok {
+		t.Error("fragment not stored under fragment:nav")
+	}
+
+	if err := RevalidateTag(ctx, cache, "menu"); err != nil {
+		t.Fatal(err)
+	}
+	if got := renderFragment(t, ctx, nav); got != "<nav>2</nav>" {
+		t.Errorf("render after RevalidateTag = %q, want a fresh render", got)
+	}
+
+	tenantCtx := context.WithValue(ctx, tenantContextKey{}, &Tenant{ID: "acme"})
+	if got := renderFragment(t, tenantCtx, nav); got != "<nav>3</nav>" {
+		t.Errorf("tenant render = %q, want a render of its own", got)
+	}
+}
+
+func TestCacheFragment_TTL(t *testing.T) {
+	cache := NewMemoryCache(0)
+	var renders int
+	nav := CacheFragmentWithConfig(countingComponent(&renders), FragmentCacheConfig{
+		Key:   "nav",
+		TTL:   time.Millisecond,
+		Cache: cache,
+	})
+
+	renderFragment(t, context.Background(), nav)
+	time.Sleep(5 * time.Millisecond)
+	if got := renderFragment(t, context.Background(), nav); got != "<nav>2</nav>" {
+		t.Errorf("render after TTL = %q, want a fresh render", got)
+	}
+}
+
+func TestCacheFragment_NoCache(t *testing.T) {
+	var renders int
+	nav := CacheFragment("nav", time.Minute, countingComponent(&renders))
+
+	renderFragment(t, context.Background(), nav)
+	if got := renderFragment(t, context.Background(), nav); got != "<nav>2</nav>" {
+		t.Errorf("render without a cache = %q, want the component to run every time", got)
+	}
+}
@@ -0,0 +1,140 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// GraphQLRequest is a GraphQL operation received over HTTP.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLError is an error returned in a GraphQL response.
+type GraphQLError struct {
+	Message string         `json:"message"`
+	Path    []any          `json:"path,omitempty"`
+	Ext     map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse is the result of executing a GraphQL operation.
+type GraphQLResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLExecutor executes a GraphQL operation against a schema.
+// It is the integration point for GraphQL libraries: resolver.go files
+// adapt their library of choice to this signature.
+//
+// ctx is the resolver context built by Context.ResolverContext, so auth
+// information set by middleware is available via PrincipalFromContext and
+// BearerTokenFromContext.
+type GraphQLExecutor func(ctx context.Context, req *GraphQLRequest) *GraphQLResponse
+
+// GraphQLConfig configures a GraphQL endpoint.
+type GraphQLConfig struct {
+	// Schema is the GraphQL SDL served at GET ?sdl (optional)
+	Schema string
+
+	// Executor runs operations (required)
+	Executor GraphQLExecutor
+
+	// Playground serves an interactive GraphiQL page for browser GET requests
+	Playground bool
+}
+
+// GraphQL returns a handler that serves GraphQL over HTTP.
+// POST requests accept a JSON body; GET requests accept the query,
+// operationName and variables query parameters.
+//
+// Example:
+//
+//	app.Post("/graphql", nexo.GraphQL(nexo.GraphQLConfig{
+//	    Schema:     schema,
+//	    Executor:   graphql.Execute,
+//	    Playground: nexo.IsDevMode(),
+//	}))
+func GraphQL(config GraphQLConfig) HandlerFunc {
+	return func(c *Context) error {
+		if config.Executor == nil {
+			return NewHTTPError(http.StatusNotImplemented, "GraphQL executor not configured")
+		}
+
+		var req GraphQLRequest
+
+		switch c.Method() {
+		case http.MethodGet:
+			if _, ok := c.Request.URL.Query()["sdl"]; ok && config.Schema != "" {
+				c.SetHeader("Content-Type", "application/graphql; charset=utf-8")
+				return c.String(http.StatusOK, config.Schema)
+			}
+
+			req.Query = c.Query("query")
+			if req.Query == "" {
+				if config.Playground && strings.Contains(c.Header("Accept"), "text/html") {
+					return c.HTML(http.StatusOK, graphQLPlaygroundHTML(c.Path()))
+				}
+				return BadRequest("missing query")
+			}
+			req.OperationName = c.Query("operationName")
+			if vars := c.Query("variables"); vars != "" {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					return BadRequest("invalid variables")
+				}
+			}
+
+		case http.MethodPost:
+			if err := c.Bind(&req); err != nil {
+				return BadRequest("invalid GraphQL request body")
+			}
+			if req.Query == "" {
+				return BadRequest("missing query")
+			}
+
+		default:
+			return NewHTTPError(http.StatusMethodNotAllowed, "GraphQL supports GET and POST")
+		}
+
+		resp := config.Executor(c.ResolverContext(), &req)
+		if resp == nil {
+			resp = &GraphQLResponse{}
+		}
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// graphQLPlaygroundHTML returns the HTML for the GraphiQL playground.
+func graphQLPlaygroundHTML(endpoint string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>GraphQL Playground</title>
+    <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+    <style>
+        body { margin: 0; height: 100vh; }
+        #graphiql { height: 100vh; }
+    </style>
+</head>
+<body>
+    <div id="graphiql"></div>
+    <script src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+    <script src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+    <script src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+    <script>
+        const fetcher = GraphiQL.createFetcher({ url: "%s" });
+        ReactDOM.createRoot(document.getElementById('graphiql')).render(
+            React.createElement(GraphiQL, { fetcher: fetcher })
+        );
+    </script>
+</body>
+</html>`, html.EscapeString(endpoint))
+}
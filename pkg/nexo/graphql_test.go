@@ -0,0 +1,180 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoExecutor returns the request and auth information it received.
+func echoExecutor(ctx context.Context, req *GraphQLRequest) *GraphQLResponse {
+	principal, _ := PrincipalFromContext(ctx)
+	token, _ := BearerTokenFromContext(ctx)
+	return &GraphQLResponse{Data: map[string]any{
+		"query":     req.Query,
+		"operation": req.OperationName,
+		"variables": req.Variables,
+		"principal": principal,
+		"token":     token,
+	}}
+}
+
+func decodeGraphQLData(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v (%s)", err, w.Body.String())
+	}
+	return resp.Data
+}
+
+func TestGraphQL_Post(t *testing.T) {
+	handler := GraphQL(GraphQLConfig{Executor: echoExecutor})
+
+	body := `{"query":"query Q($id: ID!) { user(id: $id) { name } }","operationName":"Q","variables":{"id":"1"}}`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	c := NewContext(w, req)
+	c.SetPrincipal("user-1")
+
+	if err := handler(c); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	data := decodeGraphQLData(t, w)
+	if data["operation"] != "Q" {
+		t.Errorf("operation = %v, want Q", data["operation"])
+	}
+	if vars, _ := data["variables"].(map[string]any); vars["id"] != "1" {
+		t.Errorf("variables = %v", data["variables"])
+	}
+	if data["principal"] != "user-1" {
+		t.Errorf("principal = %v, want user-1", data["principal"])
+	}
+	if data["token"] != "secret" {
+		t.Errorf("token = %v, want secret", data["token"])
+	}
+}
+
+func TestGraphQL_Get(t *testing.T) {
+	handler := GraphQL(GraphQLConfig{Executor: echoExecutor})
+
+	req := httptest.NewRequest(http.MethodGet, `/graphql?query={hello}&variables={"a":1}`, nil)
+	w := httptest.NewRecorder()
+
+	if err := handler(NewContext(w, req)); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	data := decodeGraphQLData(t, w)
+	if data["query"] != "{hello}" {
+		t.Errorf("query = %v, want {hello}", data["query"])
+	}
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     GraphQLConfig
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"no executor", GraphQLConfig{}, http.MethodPost, "/graphql", `{"query":"{a}"}`, http.StatusNotImplemented},
+		{"missing query", GraphQLConfig{Executor: echoExecutor}, http.MethodPost, "/graphql", `{}`, http.StatusBadRequest},
+		{"invalid body", GraphQLConfig{Executor: echoExecutor}, http.MethodPost, "/graphql", `{`, http.StatusBadRequest},
+		{"invalid variables", GraphQLConfig{Executor: echoExecutor}, http.MethodGet, "/graphql?query={a}&variables=nope", "", http.StatusBadRequest},
+		{"unsupported method", GraphQLConfig{Executor: echoExecutor}, http.MethodPut, "/graphql", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			err := GraphQL(tt.config)(NewContext(httptest.NewRecorder(), req))
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected HTTPError, got %v", err)
+			}
+			if httpErr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", httpErr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGraphQL_PlaygroundAndSDL(t *testing.T) {
+	schema := "type Query { hello: String }"
+	handler := GraphQL(GraphQLConfig{Schema: schema, Executor: echoExecutor, Playground: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	if err := handler(NewContext(w, req)); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if !strings.Contains(w.Body.String(), "GraphiQL") {
+		t.Error("expected playground HTML")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql?sdl", nil)
+	w = httptest.NewRecorder()
+	if err := handler(NewContext(w, req)); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if w.Body.String() != schema {
+		t.Errorf("sdl = %q, want %q", w.Body.String(), schema)
+	}
+
+	// Playground disabled: browser GET without query is a bad request
+	handler = GraphQL(GraphQLConfig{Executor: echoExecutor})
+	req = httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	if err := handler(NewContext(httptest.NewRecorder(), req)); err == nil {
+		t.Error("expected error when playground is disabled")
+	}
+}
+
+func TestContext_BearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Bearer abc", "abc"},
+		{"bearer abc", "abc"},
+		{"Basic abc", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		if got := NewContext(httptest.NewRecorder(), req).BearerToken(); got != tt.want {
+			t.Errorf("BearerToken() with %q = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestContext_ResolverContext_Empty(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	ctx := c.ResolverContext()
+
+	if _, ok := PrincipalFromContext(ctx); ok {
+		t.Error("expected no principal")
+	}
+	if _, ok := BearerTokenFromContext(ctx); ok {
+		t.Error("expected no bearer token")
+	}
+}
@@ -0,0 +1,83 @@
+package nexo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Handler returns the app as a plain http.Handler, for serving it from an
+// existing server or router instead of Listen. It mounts the routes
+// registered so far, once, so register them first, e.g. with the generated
+// RegisterRoutes(app).
+//
+// Mounted on a chi router, the app's routes are matched below the mount
+// pattern:
+//
+//	RegisterRoutes(app)
+//	r := chi.NewRouter()
+//	r.Mount("/dashboard", app.Handler())
+//
+// Other routers pass the full path; use MountUnder to strip the prefix.
+func (a *App) Handler() http.Handler {
+	if !a.mounted {
+		a.Mount()
+	}
+	return a
+}
+
+// MountUnder returns the app as an http.Handler serving its routes below
+// prefix, for embedding an app-directory-driven section in an existing
+// service. The prefix is stripped before routing, so app/page.templ serves
+// /dashboard and app/api/users/route.go serves /dashboard/api/users.
+// Requests outside the prefix get a 404.
+//
+// c.BasePath returns the prefix, and Redirect adds it to absolute paths.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/", legacyAPI)
+//	mux.Handle("/dashboard/", app.MountUnder("/dashboard"))
+func (a *App) MountUnder(prefix string) http.Handler {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		return a.Handler()
+	}
+	h := a.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok || rest != "" && rest[0] != '/' {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			rest = "/"
+		}
+
+		r2 := r.WithContext(context.WithValue(r.Context(), basePathKey{}, basePath(r.Context())+prefix))
+		u := *r.URL
+		u.Path, u.RawPath = rest, ""
+		r2.URL = &u
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// basePathKey is the request context key of the prefix an app is mounted
+// under with MountUnder.
+type basePathKey struct{}
+
+// basePath returns the prefix of the MountUnder handlers of ctx.
+func basePath(ctx context.Context) string {
+	prefix, _ := ctx.Value(basePathKey{}).(string)
+	return prefix
+}
+
+// BasePath returns the prefix the app is mounted under with MountUnder,
+// like "/dashboard", or "" when it serves the root. Links to the app's
+// own routes start with it:
+//
+//	<a href={ templ.SafeURL(c.BasePath() + "/settings") }>Settings</a>
+func (c *Context) BasePath() string {
+	return basePath(c.Request.Context())
+}
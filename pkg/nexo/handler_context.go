@@ -0,0 +1,47 @@
+package nexo
+
+import "context"
+
+// ---------- context.Context Handlers ----------
+
+// WithContext adapts a handler taking the request's context.Context first
+// to a HandlerFunc, so it can pass ctx to database and HTTP calls without
+// calling c.Context() each time. ctx is canceled when the client goes away
+// or the request times out.
+//
+// Generated route registrations use WithContext for route.go handlers with
+// the signature func(ctx context.Context, c *nexo.Context) error.
+//
+// Example:
+//
+//	func Get(ctx context.Context, c *nexo.Context) error {
+//	    users, err := db.ListUsers(ctx)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(200, users)
+//	}
+func WithContext(h func(context.Context, *Context) error) HandlerFunc {
+	return func(c *Context) error {
+		return h(c.Context(), c)
+	}
+}
+
+// WithBodyContext is WithBody for handlers taking the request's
+// context.Context first, like
+// func Post(ctx context.Context, c *nexo.Context, body PostBody) error.
+func WithBodyContext[T any](h func(context.Context, *Context, T) error) HandlerFunc {
+	return WithBody(func(c *Context, body T) error {
+		return h(c.Context(), c, body)
+	})
+}
+
+// WithLoaderContext adapts a page loader taking the request's
+// context.Context first, like
+// func Loader(ctx context.Context, c *nexo.Context) (Data, error), to a
+// plain loader.
+func WithLoaderContext[T any](l func(context.Context, *Context) (T, error)) func(*Context) (T, error) {
+	return func(c *Context) (T, error) {
+		return l(c.Context(), c)
+	}
+}
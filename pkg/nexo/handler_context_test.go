@@ -0,0 +1,55 @@
+package nexo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithContext(t *testing.T) {
+	app := New()
+	app.Get("/users", WithContext(func(ctx context.Context, c *Context) error {
+		if ctx != c.Context() {
+			t.Error("ctx is not the request context")
+		}
+		return c.String(http.StatusOK, "users")
+	}))
+	app.Post("/users", WithBodyContext(func(ctx context.Context, c *Context, body testBody) error {
+		if ctx != c.Context() {
+			t.Error("ctx is not the request context")
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"name": body.Name})
+	}))
+	app.Mount()
+
+	tests := []struct {
+		method, body string
+		wantStatus   int
+	}{
+		{http.MethodGet, "", http.StatusOK},
+		{http.MethodPost, `{"name":"a"}`, http.StatusCreated},
+		{http.MethodPost, `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, "/users", strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.body, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestWithLoaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loader := WithLoaderContext(func(ctx context.Context, c *Context) (string, error) {
+		return "", ctx.Err()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	if _, err := loader(NewContext(httptest.NewRecorder(), req)); err != context.Canceled {
+		t.Errorf("loader() error = %v, want the request's context.Canceled", err)
+	}
+}
@@ -0,0 +1,88 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMountUnder(t *testing.T) {
+	newApp := func() *App {
+		app := New()
+		app.DisableLogger()
+		app.Get("/", func(c *Context) error {
+			return c.String(http.StatusOK, "home "+c.BasePath())
+		})
+		app.Get("/users/{id}", func(c *Context) error {
+			return c.String(http.StatusOK, "user "+c.Param("id")+" at "+c.Path())
+		})
+		app.Get("/old", func(c *Context) error {
+			return c.Redirect("/users/1")
+		})
+		return app
+	}
+
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("legacy"))
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/", legacy)
+	dashboard := newApp().MountUnder("/dashboard/")
+	mux.Handle("/dashboard/", dashboard)
+	mux.Handle("/dashboard", dashboard)
+
+	chiMounted := chi.NewRouter()
+	chiMounted.Get("/", legacy)
+	chiMounted.Mount("/dashboard", newApp().Handler())
+
+	chiPrefixed := chi.NewRouter()
+	chiPrefixed.Get("/", legacy)
+	chiPrefixed.Mount("/dashboard", newApp().MountUnder("dashboard"))
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		path     string
+		code     int
+		body     string
+		location string
+	}{
+		{"mux root", mux, "/", http.StatusOK, "legacy", ""},
+		{"mux home", mux, "/dashboard", http.StatusOK, "home /dashboard", ""},
+		{"mux home slash", mux, "/dashboard/", http.StatusOK, "home /dashboard", ""},
+		{"mux param", mux, "/dashboard/users/7", http.StatusOK, "user 7 at /users/7", ""},
+		{"mux redirect", mux, "/dashboard/old", http.StatusFound, "", "/dashboard/users/1"},
+		{"mux not found", mux, "/dashboard/nope", http.StatusNotFound, "", ""},
+		{"chi root", chiMounted, "/", http.StatusOK, "legacy", ""},
+		{"chi param", chiMounted, "/dashboard/users/7", http.StatusOK, "user 7 at /dashboard/users/7", ""},
+		{"chi redirect", chiMounted, "/dashboard/old", http.StatusFound, "", "/users/1"},
+		{"chi prefixed home", chiPrefixed, "/dashboard", http.StatusOK, "home /dashboard", ""},
+		{"chi prefixed param", chiPrefixed, "/dashboard/users/7", http.StatusOK, "user 7 at /users/7", ""},
+		{"chi prefixed redirect", chiPrefixed, "/dashboard/old", http.StatusFound, "", "/dashboard/users/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+			if got := w.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+		})
+	}
+
+	// Paths that only share the prefix's characters aren't the app's
+	w := httptest.NewRecorder()
+	newApp().MountUnder("/dashboard").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboards", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("/dashboards status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,294 @@
+package nexo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// HeadConfig sets <head> defaults for every page, under head: in nexo.yaml:
+//
+//	head:
+//	  title: Acme
+//	  title_template: "%s | Acme"
+//	  description: Acme makes everything.
+//	  meta:
+//	    theme-color: "#0f172a"
+//	  links:
+//	    - rel: icon
+//	      href: /static/favicon.svg
+//
+// Title, TitleTemplate and Description are the root metadata of pages
+// rendered with RenderPage; Meta and Links start every request's Head.
+type HeadConfig struct {
+	Title         string            `mapstructure:"title"`
+	TitleTemplate string            `mapstructure:"title_template"`
+	Description   string            `mapstructure:"description"`
+	Meta          map[string]string `mapstructure:"meta"`
+	Links         []HeadLink        `mapstructure:"links"`
+}
+
+// HeadLink is a <link> tag in HeadConfig.
+type HeadLink struct {
+	Rel  string `mapstructure:"rel"`
+	Href string `mapstructure:"href"`
+}
+
+// Head collects the <head> tags of a response. Get the request's Head with
+// c.Head; its tags are rendered by MetadataTags, or injected before </head>
+// when the layout doesn't render MetadataTags.
+type Head struct {
+	title    string
+	tags     []headTag
+	rendered bool
+}
+
+// headTag is a rendered tag; key identifies tags that replace each other.
+type headTag struct {
+	key  string
+	html string
+}
+
+// SetTitle sets the page title, overriding page metadata. The title
+// template of the page's layouts still applies.
+func (h *Head) SetTitle(title string) *Head {
+	h.title = title
+	return h
+}
+
+// Title returns the title set with SetTitle.
+func (h *Head) Title() string {
+	return h.title
+}
+
+// AddMeta adds <meta name="name" content="content">, replacing an earlier
+// tag with the same name, including one from page metadata.
+func (h *Head) AddMeta(name, content string) *Head {
+	h.set("name:"+name, `<meta name="`+html.EscapeString(name)+`" content="`+html.EscapeString(content)+`">`)
+	return h
+}
+
+// AddProperty adds <meta property="property" content="content">, as used by
+// Open Graph, replacing an earlier tag with the same property.
+func (h *Head) AddProperty(property, content string) *Head {
+	h.set("property:"+property, `<meta property="`+html.EscapeString(property)+`" content="`+html.EscapeString(content)+`">`)
+	return h
+}
+
+// AddLink adds <link rel="rel" href="href">.
+func (h *Head) AddLink(rel, href string) *Head {
+	h.set("", `<link rel="`+html.EscapeString(rel)+`" href="`+html.EscapeString(href)+`">`)
+	return h
+}
+
+// AddJSONLD adds a <script type="application/ld+json"> block with v encoded
+// as JSON, for structured data.
+//
+// Example:
+//
+//	err := c.Head().AddJSONLD(map[string]any{
+//	    "@context": "https://schema.org",
+//	    "@type":    "Article",
+//	    "headline": post.Title,
+//	})
+func (h *Head) AddJSONLD(v any) error {
+	// json.Marshal escapes <, > and &, so the data can't close the script
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.set("", `<script type="application/ld+json">`+string(data)+`</script>`)
+	return nil
+}
+
+// set adds a tag, replacing the tag with the same non-empty key.
+func (h *Head) set(key, tag string) {
+	if key != "" {
+		for i := range h.tags {
+			if h.tags[i].key == key {
+				h.tags[i].html = tag
+				return
+			}
+		}
+	}
+	h.tags = append(h.tags, headTag{key: key, html: tag})
+}
+
+// has reports whether a tag with key was added.
+func (h *Head) has(key string) bool {
+	for _, t := range h.tags {
+		if t.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- Context Head ----------
+
+// Head returns the response's <head> builder, starting from the meta and
+// link defaults of the app config. Use it in handlers and loaders:
+//
+//	func Loader(c *nexo.Context) (Data, error) {
+//	    post, err := posts.Find(c.Param("slug"))
+//	    ...
+//	    c.Head().SetTitle(post.Title).AddMeta("description", post.Summary)
+//	    return Data{Post: post}, nil
+//	}
+func (c *Context) Head() *Head {
+	if c.head == nil {
+		c.head = &Head{}
+		if cfg := c.headConfig; cfg != nil {
+			names := make([]string, 0, len(cfg.Meta))
+			for name := range cfg.Meta {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				c.head.AddMeta(name, cfg.Meta[name])
+			}
+			for _, l := range cfg.Links {
+				c.head.AddLink(l.Rel, l.Href)
+			}
+		}
+	}
+	return c.head
+}
+
+// defaultMetadata returns the root page metadata from the app config.
+func (c *Context) defaultMetadata() Metadata {
+	if c.headConfig == nil {
+		return Metadata{}
+	}
+	return Metadata{
+		Title:         c.headConfig.Title,
+		TitleTemplate: c.headConfig.TitleTemplate,
+		Description:   c.headConfig.Description,
+	}
+}
+
+// hasHeadDefaults reports whether the app config adds tags to every Head.
+func (c *Context) hasHeadDefaults() bool {
+	return c.headConfig != nil && (len(c.headConfig.Meta) > 0 || len(c.headConfig.Links) > 0)
+}
+
+// headKey is the context key for the Head of a rendered response.
+type headKey struct{}
+
+// headFromContext returns the Head carried by ctx, or nil.
+func headFromContext(ctx context.Context) *Head {
+	h, _ := ctx.Value(headKey{}).(*Head)
+	return h
+}
+
+// attachHead carries the response's Head in the request context, so
+// MetadataTags and head injection can render it.
+func (c *Context) attachHead() {
+	if c.headAttached || (c.head == nil && !c.hasHeadDefaults()) {
+		return
+	}
+	c.headAttached = true
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), headKey{}, c.Head()))
+}
+
+// renderHead writes the <meta>, <link> and script tags for ctx: the page
+// metadata, then the Head's tags, which replace metadata tags of the same
+// name. The <title> is left to the caller.
+func renderHead(ctx context.Context, b *strings.Builder) {
+	md := GetMetadata(ctx)
+	h := headFromContext(ctx)
+	if h == nil {
+		h = &Head{}
+	}
+
+	meta := func(attr, name, content string) {
+		if !h.has(attr + ":" + name) {
+			writeMeta(b, attr, name, content)
+		}
+	}
+	meta("name", "description", md.Description)
+	meta("name", "keywords", strings.Join(md.Keywords, ", "))
+	meta("name", "robots", md.Robots)
+	if md.Canonical != "" {
+		b.WriteString(`<link rel="canonical" href="` + html.EscapeString(md.Canonical) + `">`)
+	}
+
+	og := md.OpenGraph
+	meta("property", "og:title", orDefault(og.Title, md.Title))
+	meta("property", "og:description", orDefault(og.Description, md.Description))
+	meta("property", "og:type", og.Type)
+	meta("property", "og:url", og.URL)
+	meta("property", "og:site_name", og.SiteName)
+	for _, img := range og.Images {
+		writeMeta(b, "property", "og:image", img)
+	}
+
+	for _, t := range h.tags {
+		b.WriteString(t.html)
+	}
+	h.rendered = true
+}
+
+// headCloseTag is where head injection inserts the tags.
+var headCloseTag = []byte("</head>")
+
+// headWriter injects the response's head tags before </head>, for layouts
+// that don't render MetadataTags themselves.
+type headWriter struct {
+	w       io.Writer
+	ctx     context.Context
+	pending []byte // tail that may be the start of </head>
+	done    bool
+}
+
+// Write passes p through, holding back a possible partial </head>.
+func (hw *headWriter) Write(p []byte) (int, error) {
+	if hw.done {
+		return hw.w.Write(p)
+	}
+
+	buf := append(hw.pending, p...)
+	if i := bytes.Index(buf, headCloseTag); i >= 0 {
+		hw.done = true
+		hw.pending = nil
+		if _, err := hw.w.Write(buf[:i]); err != nil {
+			return 0, err
+		}
+		if h := headFromContext(hw.ctx); h != nil && !h.rendered {
+			var b strings.Builder
+			renderHead(hw.ctx, &b)
+			if _, err := io.WriteString(hw.w, b.String()); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := hw.w.Write(buf[i:]); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	keep := len(headCloseTag) - 1
+	if len(buf) <= keep {
+		hw.pending = buf
+		return len(p), nil
+	}
+	if _, err := hw.w.Write(buf[:len(buf)-keep]); err != nil {
+		return 0, err
+	}
+	hw.pending = append(hw.pending[:0:0], buf[len(buf)-keep:]...)
+	return len(p), nil
+}
+
+// flush writes any held-back bytes.
+func (hw *headWriter) flush() error {
+	if len(hw.pending) == 0 {
+		return nil
+	}
+	_, err := hw.w.Write(hw.pending)
+	hw.pending = nil
+	return err
+}
@@ -0,0 +1,187 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+// htmlLayout renders a full document, with MetadataTags in <head> when tags is set.
+func htmlLayout(tags bool) LayoutFunc {
+	return func(title string, children templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<html><head>")
+			if tags {
+				if err := MetadataTags().Render(ctx, w); err != nil {
+					return err
+				}
+			} else {
+				_, _ = io.WriteString(w, "<title>"+title+"</title>")
+			}
+			_, _ = io.WriteString(w, "</head><body>")
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</body></html>")
+			return err
+		})
+	}
+}
+
+func TestHead_Builder(t *testing.T) {
+	h := &Head{}
+	h.SetTitle("Post").
+		AddMeta("description", "first").
+		AddMeta("description", "second").
+		AddProperty("og:type", "article").
+		AddLink("icon", "/favicon.svg")
+	if err := h.AddJSONLD(map[string]string{"@type": "Article", "headline": "</script>"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, tag := range h.tags {
+		got = append(got, tag.html)
+	}
+	want := []string{
+		`<meta name="description" content="second">`,
+		`<meta property="og:type" content="article">`,
+		`<link rel="icon" href="/favicon.svg">`,
+		`<script type="application/ld+json">{"@type":"Article","headline":"\u003c/script\u003e"}</script>`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tags =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if h.Title() != "Post" {
+		t.Errorf("Title() = %q, want Post", h.Title())
+	}
+}
+
+func TestContext_Head(t *testing.T) {
+	config := &HeadConfig{
+		Title:         "Acme",
+		TitleTemplate: "%s | Acme",
+		Description:   "Acme makes everything",
+		Meta:          map[string]string{"theme-color": "#000"},
+		Links:         []HeadLink{{Rel: "icon", Href: "/favicon.svg"}},
+	}
+	page := templ.Raw("<p>page</p>")
+
+	tests := []struct {
+		name    string
+		tags    bool
+		handler func(c *Context)
+		want    string
+	}{
+		{
+			name: "config defaults with MetadataTags",
+			tags: true,
+			want: `<html><head><title>Acme</title>` +
+				`<meta name="description" content="Acme makes everything">` +
+				`<meta property="og:title" content="Acme">` +
+				`<meta property="og:description" content="Acme makes everything">` +
+				`<meta name="theme-color" content="#000">` +
+				`<link rel="icon" href="/favicon.svg">` +
+				`</head><body><p>page</p></body></html>`,
+		},
+		{
+			name: "handler overrides",
+			tags: true,
+			handler: func(c *Context) {
+				c.Head().SetTitle("Post").AddMeta("description", "A post")
+			},
+			want: `<html><head><title>Post | Acme</title>` +
+				`<meta property="og:title" content="Post | Acme">` +
+				`<meta property="og:description" content="Acme makes everything">` +
+				`<meta name="theme-color" content="#000">` +
+				`<link rel="icon" href="/favicon.svg">` +
+				`<meta name="description" content="A post">` +
+				`</head><body><p>page</p></body></html>`,
+		},
+		{
+			name: "injected without MetadataTags",
+			handler: func(c *Context) {
+				c.Head().SetTitle("Post")
+			},
+			want: `<html><head><title>Post | Acme</title>` +
+				`<meta name="description" content="Acme makes everything">` +
+				`<meta property="og:title" content="Post | Acme">` +
+				`<meta property="og:description" content="Acme makes everything">` +
+				`<meta name="theme-color" content="#000">` +
+				`<link rel="icon" href="/favicon.svg">` +
+				`</head><body><p>page</p></body></html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			c.headConfig = config
+			if tt.handler != nil {
+				tt.handler(c)
+			}
+
+			if err := RenderPage(c, http.StatusOK, page, LayoutSegment{Layout: htmlLayout(tt.tags)}); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_Render_InjectsHead(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Head().AddMeta("robots", "noindex")
+
+	if err := c.Render(http.StatusOK, templ.Raw("<html><head><title>Plain</title></head><body></body></html>")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<html><head><title>Plain</title><meta name="robots" content="noindex"></head><body></body></html>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestHeadWriter(t *testing.T) {
+	ctx := context.WithValue(context.Background(), headKey{}, (&Head{}).AddMeta("a", "b"))
+
+	// </head> split across writes is still found
+	chunks := []string{"<html><head><title>x</title></he", "ad><body>", "</body></html>"}
+
+	var b strings.Builder
+	hw := &headWriter{w: &b, ctx: ctx}
+	for _, chunk := range chunks {
+		n, err := hw.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if err := hw.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<html><head><title>x</title><meta name="a" content="b"></head><body></body></html>`
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Fragments without </head> pass through untouched
+	b.Reset()
+	hw = &headWriter{w: &b, ctx: ctx}
+	_, _ = hw.Write([]byte("<li>a</li>"))
+	_, _ = hw.Write([]byte("<li>b</li>"))
+	_ = hw.flush()
+	if got := b.String(); got != "<li>a</li><li>b</li>" {
+		t.Errorf("got %q", got)
+	}
+}
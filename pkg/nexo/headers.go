@@ -0,0 +1,114 @@
+package nexo
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// HeaderRule sets response headers on the paths matching Source, under
+// headers: in nexo.yaml:
+//
+//	headers:
+//	  - source: /*
+//	    headers:
+//	      X-Frame-Options: DENY
+//	  - source: /static/*
+//	    headers:
+//	      Cache-Control: public, max-age=31536000, immutable
+//	  - source: /admin/*
+//	    headers:
+//	      X-Robots-Tag: noindex
+type HeaderRule struct {
+	// Source is the path pattern, in route syntax: {name} matches one
+	// segment and a trailing * the rest of the path, including none, so
+	// /docs/* matches /docs too.
+	Source string `mapstructure:"source"`
+
+	// Headers are the headers to set. Values can use the parameters of
+	// Source, like {slug}; an empty value removes a header set by an
+	// earlier rule.
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// UseHeaders sets the response headers of rules in place of the headers:
+// rules of the config, before those added with WithHeaders. The
+// RegisterRoutes generated by nexo generate calls it with the rules of
+// nexo.yaml, so the app applies them without the file; it warns when the
+// file's rules changed since. Invalid rules are logged and ignored.
+func (a *App) UseHeaders(rules ...HeaderRule) {
+	if len(a.config.Headers) > 0 && !sameHeaderRules(a.config.Headers, rules) {
+		log.Printf("nexo: warning: the headers: rules of nexo.yaml differ from the generated ones; run nexo generate to apply them")
+	}
+	headers, err := newHeaderPolicy(slices.Concat(rules, a.headerRules))
+	if err != nil {
+		log.Printf("nexo: %v; header rules are ignored", err)
+	}
+	a.headers = headers
+}
+
+// sameHeaderRules reports whether a and b set the same headers on the same
+// paths, whatever the case of the header names.
+func sameHeaderRules(a, b []HeaderRule) bool {
+	canonical := func(headers map[string]string) map[string]string {
+		m := make(map[string]string, len(headers))
+		for key, value := range headers {
+			m[http.CanonicalHeaderKey(key)] = value
+		}
+		return m
+	}
+	return slices.EqualFunc(a, b, func(x, y HeaderRule) bool {
+		return x.Source == y.Source && maps.Equal(canonical(x.Headers), canonical(y.Headers))
+	})
+}
+
+// headerPolicy holds the compiled header rules of an app.
+type headerPolicy struct {
+	rules []headerRule
+}
+
+// headerRule is a compiled HeaderRule.
+type headerRule struct {
+	source  pathPattern
+	headers map[string]string
+}
+
+// newHeaderPolicy compiles rules. It returns nil when there are none.
+func newHeaderPolicy(rules []HeaderRule) (*headerPolicy, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	p := &headerPolicy{}
+	for _, rule := range rules {
+		source, err := compilePathRule(rule.Source)
+		if err != nil {
+			return nil, fmt.Errorf("headers: %w", err)
+		}
+		p.rules = append(p.rules, headerRule{source: source, headers: rule.Headers})
+	}
+	return p, nil
+}
+
+// apply sets the headers of the rules matching path on h, in rule order,
+// so later rules override earlier ones. Handlers run after and can
+// override them too.
+func (p *headerPolicy) apply(h http.Header, path string) {
+	if p == nil {
+		return
+	}
+	for _, rule := range p.rules {
+		params, ok := rule.source.match(path)
+		if !ok {
+			continue
+		}
+		for key, value := range rule.headers {
+			if value == "" {
+				h.Del(key)
+				continue
+			}
+			h.Set(key, expandParams(value, params))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package nexo
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeaderRules(t *testing.T) {
+	app := New(WithHeaders(
+		HeaderRule{Source: "/*", Headers: map[string]string{"X-Frame-Options": "DENY", "X-Robots-Tag": "noindex"}},
+		HeaderRule{Source: "/blog/*", Headers: map[string]string{"X-Robots-Tag": ""}},
+		HeaderRule{Source: "/blog/{slug}", Headers: map[string]string{"Link": "</blog/{slug}>; rel=canonical"}},
+		HeaderRule{Source: "/api", Headers: map[string]string{"Cache-Control": "no-store"}},
+	))
+	app.Get("/api", func(c *Context) error { return c.NoContent() })
+	app.Get("/api/users", func(c *Context) error { return c.NoContent() })
+	app.Get("/blog/{slug}", func(c *Context) error { return c.NoContent() })
+	app.Get("/override", func(c *Context) error {
+		c.SetHeader("X-Frame-Options", "SAMEORIGIN")
+		return c.NoContent()
+	})
+	app.Mount()
+
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{"/api", map[string]string{"Cache-Control": "no-store", "X-Frame-Options": "DENY", "X-Robots-Tag": "noindex"}},
+		{"/api/users", map[string]string{"Cache-Control": "", "X-Frame-Options": "DENY"}},
+		{"/blog", map[string]string{"X-Robots-Tag": "", "Link": ""}},
+		{"/blog/hello", map[string]string{"X-Robots-Tag": "", "Link": "</blog/hello>; rel=canonical"}},
+		{"/blog/hello/comments", map[string]string{"X-Robots-Tag": "", "Link": ""}},
+		{"/override", map[string]string{"X-Frame-Options": "SAMEORIGIN"}},
+		{"/missing", map[string]string{"X-Frame-Options": "DENY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			for key, want := range tt.want {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewHeaderPolicy_Invalid(t *testing.T) {
+	for _, source := range []string{"api", "/api*", "/*/users"} {
+		if _, err := newHeaderPolicy([]HeaderRule{{Source: source}}); err == nil {
+			t.Errorf("newHeaderPolicy(%q) = nil error", source)
+		}
+	}
+}
+
+func TestLoadConfig_Headers(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "headers:\n  - source: /static/*\n    headers:\n      Cache-Control: public, max-age=31536000\n"
+	if err := os.WriteFile(filepath.Join(dir, "nexo.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := New(WithConfig(config))
+	app.Get("/static/app.css", func(c *Context) error { return c.NoContent() })
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app.css", nil))
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=31536000" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+}
+
+func TestUseHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	generated := []HeaderRule{{Source: "/*", Headers: map[string]string{"X-Frame-Options": "DENY"}}}
+	tests := []struct {
+		name   string
+		config []HeaderRule
+		want   string
+		warn   bool
+	}{
+		{"without nexo.yaml", nil, "DENY", false},
+		{"same rules", []HeaderRule{{Source: "/*", Headers: map[string]string{"x-frame-options": "DENY"}}}, "DENY", false},
+		{"changed rules", []HeaderRule{{Source: "/*", Headers: map[string]string{"x-frame-options": "SAMEORIGIN"}}}, "DENY", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			config := DefaultConfig()
+			config.Headers = tt.config
+			app := New(WithConfig(config), WithHeaders(HeaderRule{Source: "/api/*", Headers: map[string]string{"Cache-Control": "no-store"}}))
+			app.UseHeaders(generated...)
+			app.Get("/api/users", func(c *Context) error { return c.NoContent() })
+			app.Mount()
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+			if got := w.Header().Get("X-Frame-Options"); got != tt.want {
+				t.Errorf("X-Frame-Options = %q, want %q", got, tt.want)
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want the WithHeaders rule", got)
+			}
+			if got := strings.Contains(buf.String(), "run nexo generate"); got != tt.warn {
+				t.Errorf("warning = %v, want %v: %s", got, tt.warn, buf.String())
+			}
+		})
+	}
+}
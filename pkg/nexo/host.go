@@ -0,0 +1,182 @@
+package nexo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// hostPattern matches request hosts by label. A pattern label is either a
+// literal ("admin"), a parameter that captures one label ("{tenant}"), or
+// "*", which matches one or more labels:
+//
+//	admin.example.com    only admin.example.com
+//	{tenant}.example.com acme.example.com, with tenant=acme
+//	admin.*              admin.example.com, admin.localhost, ...
+type hostPattern struct {
+	labels []string
+}
+
+// compileHostPattern parses a host pattern. Ports are ignored and matching
+// is case-insensitive.
+func compileHostPattern(pattern string) hostPattern {
+	return hostPattern{labels: strings.Split(strings.ToLower(stripPort(pattern)), ".")}
+}
+
+// match reports whether host matches the pattern, with the values of its
+// {name} labels.
+func (p hostPattern) match(host string) (map[string]string, bool) {
+	labels := strings.Split(strings.ToLower(stripPort(host)), ".")
+	params := make(map[string]string)
+	if !matchHostLabels(p.labels, labels, params) {
+		return nil, false
+	}
+	return params, true
+}
+
+// matchHostLabels matches host labels against pattern labels, collecting
+// parameter values in params.
+func matchHostLabels(pattern, labels []string, params map[string]string) bool {
+	if len(pattern) == 0 {
+		return len(labels) == 0
+	}
+	if len(labels) == 0 {
+		return false
+	}
+
+	switch p := pattern[0]; {
+	case p == "*":
+		// Match as few labels as possible, then one more each time
+		for n := 1; n <= len(labels); n++ {
+			if matchHostLabels(pattern[1:], labels[n:], params) {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}"):
+		if labels[0] == "" || !matchHostLabels(pattern[1:], labels[1:], params) {
+			return false
+		}
+		params[p[1:len(p)-1]] = labels[0]
+		return true
+	default:
+		return p == labels[0] && matchHostLabels(pattern[1:], labels[1:], params)
+	}
+}
+
+// before reports whether p is tried before q: comparing labels from the
+// left, a literal label beats a parameter and a parameter beats "*". So
+// admin.* is tried before {tenant}.example.com.
+func (p hostPattern) before(q hostPattern) bool {
+	for i := 0; i < len(p.labels) && i < len(q.labels); i++ {
+		if a, b := hostLabelRank(p.labels[i]), hostLabelRank(q.labels[i]); a != b {
+			return a < b
+		}
+	}
+	return len(p.labels) > len(q.labels)
+}
+
+// hostLabelRank orders pattern labels from most to least specific.
+func hostLabelRank(label string) int {
+	switch {
+	case label == "*":
+		return 2
+	case strings.HasPrefix(label, "{"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// stripPort returns host without its port.
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// hostRouter serves the routes registered for a host pattern.
+type hostRouter struct {
+	pattern hostPattern
+	mux     *chi.Mux
+}
+
+// hostParamsKey is the request context key of the parameters extracted
+// from the host.
+type hostParamsKey struct{}
+
+// mountHosts mounts the host-scoped routes on a router per host pattern,
+// most specific pattern first.
+func (rt *RouteTree) mountHosts(routes []*Route, globalMiddlewares []MiddlewareFunc) {
+	rt.hosts = nil
+	byPattern := make(map[string]*hostRouter)
+	for _, route := range routes {
+		if route.Host == "" {
+			continue
+		}
+		hr, ok := byPattern[route.Host]
+		if !ok {
+			hr = &hostRouter{pattern: compileHostPattern(route.Host), mux: chi.NewRouter()}
+			byPattern[route.Host] = hr
+			rt.hosts = append(rt.hosts, hr)
+		}
+		rt.mountRoute(hr.mux, route, globalMiddlewares)
+	}
+	sort.SliceStable(rt.hosts, func(i, j int) bool {
+		return rt.hosts[i].pattern.before(rt.hosts[j].pattern)
+	})
+}
+
+// routerFor returns the router that serves r: the router of the first host
+// pattern that matches r.Host and has a route for the request, or fallback.
+// Host parameters are added to the returned request's context.
+func (rt *RouteTree) routerFor(r *http.Request, fallback http.Handler) (http.Handler, *http.Request) {
+	if len(rt.hosts) == 0 {
+		return fallback, r
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	for _, hr := range rt.hosts {
+		params, ok := hr.pattern.match(r.Host)
+		if !ok || !hr.mux.Match(chi.NewRouteContext(), r.Method, path) {
+			continue
+		}
+		if len(params) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), hostParamsKey{}, params))
+		}
+		return hr.mux, r
+	}
+	return fallback, r
+}
+
+// HostParam returns a parameter extracted from the request host by the
+// route's host pattern, like "tenant" for App.Host("{tenant}.example.com").
+func (c *Context) HostParam(name string) string {
+	params, _ := c.Request.Context().Value(hostParamsKey{}).(map[string]string)
+	return params[name]
+}
+
+// Host returns a route group whose routes only match requests for hosts
+// that match pattern. Routes without a host serve every other request.
+//
+//	admin := app.Host("admin.*")
+//	admin.Get("/", dashboard)
+//
+//	tenants := app.Host("{tenant}.example.com")
+//	tenants.Get("/", func(c *nexo.Context) error {
+//		return c.String(200, "Hello, "+c.HostParam("tenant"))
+//	})
+func (a *App) Host(pattern string) *RouteGroup {
+	return &RouteGroup{
+		app:         a,
+		host:        pattern,
+		middlewares: make([]MiddlewareFunc, 0),
+	}
+}
@@ -0,0 +1,92 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+		params        map[string]string
+	}{
+		{"admin.example.com", "admin.example.com", true, nil},
+		{"admin.example.com", "Admin.Example.com:8080", true, nil},
+		{"admin.example.com", "www.example.com", false, nil},
+		{"admin.*", "admin.example.com", true, nil},
+		{"admin.*", "admin.localhost", true, nil},
+		{"admin.*", "admin", false, nil},
+		{"{tenant}.example.com", "acme.example.com", true, map[string]string{"tenant": "acme"}},
+		{"{tenant}.example.com", "example.com", false, nil},
+		{"{tenant}.example.com", "a.b.example.com", false, nil},
+		{"{tenant}.*", "acme.localhost:3000", true, map[string]string{"tenant": "acme"}},
+		{"api.{region}.example.com", "api.eu.example.com", true, map[string]string{"region": "eu"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.host, func(t *testing.T) {
+			params, ok := compileHostPattern(tt.pattern).match(tt.host)
+			if ok != tt.want {
+				t.Fatalf("match = %v, want %v", ok, tt.want)
+			}
+			for name, value := range tt.params {
+				if params[name] != value {
+					t.Errorf("param %s = %q, want %q", name, params[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestAppHost(t *testing.T) {
+	app := New()
+	app.Get("/", func(c *Context) error { return c.String(http.StatusOK, "site") })
+	app.Get("/about", func(c *Context) error { return c.String(http.StatusOK, "about") })
+
+	admin := app.Host("admin.*")
+	admin.Get("/", func(c *Context) error { return c.String(http.StatusOK, "admin") })
+
+	tenants := app.Host("{tenant}.example.com")
+	tenants.Group("/api", func(g *RouteGroup) {
+		g.Get("/me", func(c *Context) error { return c.String(http.StatusOK, "tenant "+c.HostParam("tenant")) })
+	})
+	tenants.Get("/", func(c *Context) error { return c.String(http.StatusOK, "home of "+c.HostParam("tenant")) })
+	app.Mount()
+
+	tests := []struct {
+		host, path string
+		status     int
+		body       string
+	}{
+		{"example.com", "/", http.StatusOK, "site"},
+		{"admin.example.com", "/", http.StatusOK, "admin"},
+		{"admin.localhost:3000", "/", http.StatusOK, "admin"},
+		{"acme.example.com", "/", http.StatusOK, "home of acme"},
+		{"acme.example.com", "/api/me", http.StatusOK, "tenant acme"},
+		{"acme.example.com", "/about", http.StatusOK, "about"},
+		{"example.com", "/api/me", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+
+	var hosts []string
+	for _, r := range app.RouteTable().Routes {
+		hosts = append(hosts, r.Host)
+	}
+	if len(hosts) != 5 {
+		t.Errorf("route table has %d routes, want 5", len(hosts))
+	}
+}
@@ -0,0 +1,350 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// HTMX response headers set by the HX helpers.
+const (
+	hxRedirect           = "HX-Redirect"
+	hxLocation           = "HX-Location"
+	hxRefresh            = "HX-Refresh"
+	hxPushURL            = "HX-Push-Url"
+	hxReplaceURL         = "HX-Replace-Url"
+	hxRetarget           = "HX-Retarget"
+	hxReswap             = "HX-Reswap"
+	hxReselect           = "HX-Reselect"
+	hxTrigger            = "HX-Trigger"
+	hxTriggerAfterSwap   = "HX-Trigger-After-Swap"
+	hxTriggerAfterSettle = "HX-Trigger-After-Settle"
+)
+
+// ---------- HTMX Request Helpers ----------
+
+// IsHTMXBoosted reports whether the request comes from an hx-boost link or form.
+// Boosted requests swap the whole body, so they expect the full page.
+func (c *Context) IsHTMXBoosted() bool {
+	return c.Request.Header.Get("HX-Boosted") == "true"
+}
+
+// IsHTMXHistoryRestore reports whether HTMX is restoring a page missing from
+// its history cache. It expects the full page.
+func (c *Context) IsHTMXHistoryRestore() bool {
+	return c.Request.Header.Get("HX-History-Restore-Request") == "true"
+}
+
+// IsHTMXPartial reports whether the request is an HTMX request that swaps
+// part of the page, i.e. neither boosted nor a history restore. These are the
+// requests RenderPartial answers with the partial component.
+func (c *Context) IsHTMXPartial() bool {
+	return c.IsHTMX() && !c.IsHTMXBoosted() && !c.IsHTMXHistoryRestore()
+}
+
+// HXTarget returns the id of the request's target element, or "".
+func (c *Context) HXTarget() string {
+	return c.Request.Header.Get("HX-Target")
+}
+
+// HXTriggerName returns the name of the element that triggered the request, or "".
+func (c *Context) HXTriggerName() string {
+	return c.Request.Header.Get("HX-Trigger-Name")
+}
+
+// HXCurrentURL returns the browser URL when the request was made, or "".
+func (c *Context) HXCurrentURL() string {
+	return c.Request.Header.Get("HX-Current-URL")
+}
+
+// HXPrompt returns the user's response to an hx-prompt, or "".
+func (c *Context) HXPrompt() string {
+	return c.Request.Header.Get("HX-Prompt")
+}
+
+// ---------- HTMX Response Helpers ----------
+
+// HXRedirect makes HTMX do a full page load of url. Plain requests get a
+// regular redirect, so handlers can serve both.
+//
+// Example:
+//
+//	func Post(c *nexo.Context) error {
+//	    // save...
+//	    return c.HXRedirect("/dashboard")
+//	}
+func (c *Context) HXRedirect(url string) error {
+	if !c.IsHTMX() {
+		return c.Redirect(url, http.StatusSeeOther)
+	}
+	c.SetHeader(hxRedirect, url)
+	return c.emptyOK()
+}
+
+// HXLocation makes HTMX load url without a full page reload, as if an
+// hx-boost link was followed. Plain requests get a regular redirect.
+func (c *Context) HXLocation(url string) error {
+	if !c.IsHTMX() {
+		return c.Redirect(url, http.StatusSeeOther)
+	}
+	c.SetHeader(hxLocation, url)
+	return c.emptyOK()
+}
+
+// HXRefresh makes HTMX do a full refresh of the current page.
+func (c *Context) HXRefresh() error {
+	c.SetHeader(hxRefresh, "true")
+	return c.emptyOK()
+}
+
+// emptyOK sends an empty 200 OK response for the HX headers to act on.
+func (c *Context) emptyOK() error {
+	c.Response.WriteHeader(http.StatusOK)
+	c.written = true
+	c.status = http.StatusOK
+	return nil
+}
+
+// HXPushURL pushes url into the browser history.
+func (c *Context) HXPushURL(url string) *Context {
+	c.SetHeader(hxPushURL, url)
+	return c
+}
+
+// HXReplaceURL replaces the current URL in the browser location bar.
+func (c *Context) HXReplaceURL(url string) *Context {
+	c.SetHeader(hxReplaceURL, url)
+	return c
+}
+
+// HXRetarget swaps the response into the element matching selector instead
+// of the request's hx-target, e.g. to show a validation error elsewhere.
+//
+// Example:
+//
+//	if err != nil {
+//	    c.HXRetarget("#errors").HXReswap("innerHTML")
+//	    return c.Render(422, components.Error(err))
+//	}
+func (c *Context) HXRetarget(selector string) *Context {
+	c.SetHeader(hxRetarget, selector)
+	return c
+}
+
+// HXReswap overrides the request's hx-swap strategy, e.g. "outerHTML".
+func (c *Context) HXReswap(strategy string) *Context {
+	c.SetHeader(hxReswap, strategy)
+	return c
+}
+
+// HXReselect swaps only the part of the response matching selector.
+func (c *Context) HXReselect(selector string) *Context {
+	c.SetHeader(hxReselect, selector)
+	return c
+}
+
+// HXTrigger triggers client-side events as soon as the response is received.
+// Calls accumulate, so several helpers can each trigger their own event.
+//
+// Example:
+//
+//	c.HXTrigger("cart-updated")
+//	c.HXTriggerDetail("notify", map[string]string{"message": "Added to cart"})
+func (c *Context) HXTrigger(events ...string) *Context {
+	for _, e := range events {
+		c.addHXTrigger(hxTrigger, e, nil)
+	}
+	return c
+}
+
+// HXTriggerDetail triggers a client-side event carrying detail, which is
+// encoded as JSON and available as event.detail.
+func (c *Context) HXTriggerDetail(event string, detail any) *Context {
+	c.addHXTrigger(hxTrigger, event, detail)
+	return c
+}
+
+// HXTriggerAfterSwap triggers a client-side event after the swap step.
+// detail may be nil.
+func (c *Context) HXTriggerAfterSwap(event string, detail any) *Context {
+	c.addHXTrigger(hxTriggerAfterSwap, event, detail)
+	return c
+}
+
+// HXTriggerAfterSettle triggers a client-side event after the settle step.
+// detail may be nil.
+func (c *Context) HXTriggerAfterSettle(event string, detail any) *Context {
+	c.addHXTrigger(hxTriggerAfterSettle, event, detail)
+	return c
+}
+
+// hxEvent is an event triggered with an HX-Trigger header.
+type hxEvent struct {
+	name   string
+	detail any
+}
+
+// addHXTrigger records an event for header and rewrites the header. Events
+// without details are sent as a comma-separated list, otherwise as a JSON
+// object keyed by event name.
+func (c *Context) addHXTrigger(header, event string, detail any) {
+	if c.hxTriggers == nil {
+		c.hxTriggers = make(map[string][]hxEvent)
+	}
+	events := c.hxTriggers[header]
+	replaced := false
+	for i := range events {
+		if events[i].name == event {
+			events[i].detail = detail
+			replaced = true
+		}
+	}
+	if !replaced {
+		events = append(events, hxEvent{name: event, detail: detail})
+	}
+	c.hxTriggers[header] = events
+
+	c.SetHeader(header, encodeHXEvents(events))
+}
+
+// encodeHXEvents encodes events for an HX-Trigger header.
+func encodeHXEvents(events []hxEvent) string {
+	names := make([]string, 0, len(events))
+	plain := true
+	for _, e := range events {
+		names = append(names, e.name)
+		if e.detail != nil {
+			plain = false
+		}
+	}
+	if plain {
+		return strings.Join(names, ", ")
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, e := range events {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(e.name)
+		detail, err := json.Marshal(e.detail)
+		if err != nil {
+			detail = []byte("null")
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(detail)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ---------- HTMX Partial Rendering ----------
+
+// RenderPartial renders partial for HTMX requests that swap part of the page
+// and full otherwise, so one handler serves both the page and its fragment.
+// Boosted and history-restore requests get the full page.
+//
+// Example:
+//
+//	func Get(c *nexo.Context) error {
+//	    tasks := store.List()
+//	    return c.RenderPartial(200, pages.Tasks(tasks), components.TaskList(tasks))
+//	}
+func (c *Context) RenderPartial(status int, full, partial templ.Component) error {
+	c.Response.Header().Add("Vary", "HX-Request")
+	if c.IsHTMXPartial() {
+		return c.Render(status, partial)
+	}
+	return c.Render(status, full)
+}
+
+// RenderFragments renders only the templ.Fragment blocks of component with
+// the given ids, so a page can serve its own partials without a separate
+// component:
+//
+//	templ Tasks(tasks []Task) {
+//	    @layouts.Main("Tasks") {
+//	        @templ.Fragment("list") {
+//	            <ul id="list">...</ul>
+//	        }
+//	    }
+//	}
+//
+//	if c.IsHTMXPartial() {
+//	    return c.RenderFragments(200, pages.Tasks(tasks), "list")
+//	}
+//	return c.Render(200, pages.Tasks(tasks))
+func (c *Context) RenderFragments(status int, component templ.Component, ids ...any) error {
+	ctx := c.renderContext()
+	c.Response.Header().Add("Vary", "HX-Request")
+	c.SetHeader("Content-Type", "text/html; charset=utf-8")
+	c.Response.WriteHeader(status)
+	c.written = true
+	c.status = status
+	if err := templ.RenderFragments(ctx, c.Response, component, ids...); err != nil {
+		return err
+	}
+	return c.writeOOB(ctx)
+}
+
+// ---------- HTMX Out-of-Band Swaps ----------
+
+// OOB queues an out-of-band swap: component replaces the contents of the
+// element matching target when the response is swapped in. Queued swaps are
+// written after the main content by Render, RenderPartial and RenderFragments.
+//
+// Example:
+//
+//	func Post(c *nexo.Context) error {
+//	    item := cart.Add(c.FormValue("id"))
+//	    c.OOB("#cart-count", components.CartCount(cart.Len()))
+//	    return c.Render(200, components.CartItem(item))
+//	}
+func (c *Context) OOB(target string, component templ.Component) *Context {
+	return c.OOBSwap("innerHTML", target, component)
+}
+
+// OOBSwap queues an out-of-band swap with the given strategy, e.g.
+// "beforeend" to append to a list. See OOB.
+func (c *Context) OOBSwap(strategy, target string, component templ.Component) *Context {
+	c.oob = append(c.oob, OOBSwap(strategy, target, component))
+	return c
+}
+
+// writeOOB writes the queued out-of-band swaps to the response.
+func (c *Context) writeOOB(ctx context.Context) error {
+	for _, comp := range c.oob {
+		if err := comp.Render(ctx, c.Response); err != nil {
+			return err
+		}
+	}
+	c.oob = nil
+	return nil
+}
+
+// OOBSwap wraps component for an out-of-band swap into the element matching
+// target, for use directly in templ:
+//
+//	@nexo.OOBSwap("innerHTML", "#cart-count", CartCount(n))
+//
+// The wrapper is a <div>, so it can't carry table rows; render those with
+// hx-swap-oob on the <tr> itself.
+func OOBSwap(strategy, target string, component templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, `<div hx-swap-oob="`+html.EscapeString(strategy+":"+target)+`">`); err != nil {
+			return err
+		}
+		if err := component.Render(ctx, w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, `</div>`)
+		return err
+	})
+}
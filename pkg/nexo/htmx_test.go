@@ -0,0 +1,222 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+// htmxRequest returns a GET request with the given HTMX headers.
+func htmxRequest(headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestContext_IsHTMXPartial(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"plain request", nil, false},
+		{"htmx request", map[string]string{"HX-Request": "true"}, true},
+		{"boosted", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, false},
+		{"history restore", map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewContext(httptest.NewRecorder(), htmxRequest(tt.headers))
+			if got := c.IsHTMXPartial(); got != tt.want {
+				t.Errorf("IsHTMXPartial() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_HXRedirect(t *testing.T) {
+	t.Run("htmx request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, htmxRequest(map[string]string{"HX-Request": "true"}))
+
+		if err := c.HXRedirect("/dashboard"); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", w.Code)
+		}
+		if got := w.Header().Get("HX-Redirect"); got != "/dashboard" {
+			t.Errorf("HX-Redirect = %q, want /dashboard", got)
+		}
+		if !c.Written() {
+			t.Error("Written() = false after HXRedirect")
+		}
+	})
+
+	t.Run("plain request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, htmxRequest(nil))
+
+		if err := c.HXRedirect("/dashboard"); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusSeeOther {
+			t.Errorf("status = %d, want 303", w.Code)
+		}
+		if got := w.Header().Get("Location"); got != "/dashboard" {
+			t.Errorf("Location = %q, want /dashboard", got)
+		}
+	})
+}
+
+func TestContext_HXTrigger(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Context)
+		want string
+	}{
+		{
+			name: "single event",
+			call: func(c *Context) { c.HXTrigger("saved") },
+			want: "saved",
+		},
+		{
+			name: "accumulated events",
+			call: func(c *Context) { c.HXTrigger("saved").HXTrigger("refresh", "saved") },
+			want: "saved, refresh",
+		},
+		{
+			name: "event with detail",
+			call: func(c *Context) {
+				c.HXTrigger("saved")
+				c.HXTriggerDetail("notify", map[string]string{"message": "Done"})
+			},
+			want: `{"saved":null,"notify":{"message":"Done"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, htmxRequest(nil))
+			tt.call(c)
+			if got := w.Header().Get("HX-Trigger"); got != tt.want {
+				t.Errorf("HX-Trigger = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_HXTriggerAfterSettle(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, htmxRequest(nil))
+	c.HXTriggerAfterSettle("focus", nil).HXTriggerAfterSwap("highlight", 3)
+
+	if got := w.Header().Get("HX-Trigger-After-Settle"); got != "focus" {
+		t.Errorf("HX-Trigger-After-Settle = %q, want focus", got)
+	}
+	if got := w.Header().Get("HX-Trigger-After-Swap"); got != `{"highlight":3}` {
+		t.Errorf("HX-Trigger-After-Swap = %q", got)
+	}
+	if got := w.Header().Get("HX-Trigger"); got != "" {
+		t.Errorf("HX-Trigger = %q, want empty", got)
+	}
+}
+
+func TestContext_HXRetarget(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, htmxRequest(nil))
+	c.HXRetarget("#errors").HXReswap("innerHTML").HXPushURL("/tasks?page=2")
+
+	for header, want := range map[string]string{
+		"HX-Retarget": "#errors",
+		"HX-Reswap":   "innerHTML",
+		"HX-Push-Url": "/tasks?page=2",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestContext_RenderPartial(t *testing.T) {
+	full := templ.Raw("<html><ul>tasks</ul></html>")
+	partial := templ.Raw("<ul>tasks</ul>")
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"plain request", nil, "<html><ul>tasks</ul></html>"},
+		{"htmx request", map[string]string{"HX-Request": "true"}, "<ul>tasks</ul>"},
+		{"boosted", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "<html><ul>tasks</ul></html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, htmxRequest(tt.headers))
+			if err := c.RenderPartial(http.StatusOK, full, partial); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if got := w.Header().Get("Vary"); got != "HX-Request" {
+				t.Errorf("Vary = %q, want HX-Request", got)
+			}
+		})
+	}
+}
+
+func TestContext_RenderFragments(t *testing.T) {
+	list := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "<ul>tasks</ul>")
+		return err
+	})
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "<html>"); err != nil {
+			return err
+		}
+		if err := templ.Fragment("list").Render(templ.WithChildren(ctx, list), w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "</html>")
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, htmxRequest(map[string]string{"HX-Request": "true"}))
+	if err := c.RenderFragments(http.StatusOK, page, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Body.String(); got != "<ul>tasks</ul>" {
+		t.Errorf("body = %q, want only the fragment", got)
+	}
+}
+
+func TestContext_OOB(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, htmxRequest(map[string]string{"HX-Request": "true"}))
+	c.OOB("#cart-count", templ.Raw("3"))
+	c.OOBSwap("beforeend", "#toasts", templ.Raw("<p>Added</p>"))
+
+	if err := c.Render(http.StatusOK, templ.Raw("<li>item</li>")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<li>item</li>` +
+		`<div hx-swap-oob="innerHTML:#cart-count">3</div>` +
+		`<div hx-swap-oob="beforeend:#toasts"><p>Added</p></div>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
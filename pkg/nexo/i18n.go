@@ -0,0 +1,100 @@
+package nexo
+
+import (
+	"net/http"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+)
+
+// ---------- Context Internationalization ----------
+
+// Locale returns the request's locale. A locale-prefixed route (/es/about)
+// or SetLocale decides it; otherwise it is negotiated from the
+// Accept-Language header against the app's catalogs (see WithI18n). Without
+// catalogs it returns "".
+func (c *Context) Locale() string {
+	if c.locale == "" && c.i18n != nil {
+		c.locale = c.i18n.Match(c.Request.Header.Get("Accept-Language"))
+	}
+	return c.locale
+}
+
+// SetLocale overrides the request's locale, e.g. from a user preference.
+func (c *Context) SetLocale(locale string) {
+	c.locale = locale
+	c.localized = false
+}
+
+// T translates key into the request's locale. Arguments are applied with
+// fmt.Sprintf. Without catalogs, or for a missing key, the key is returned.
+//
+// Example:
+//
+//	return c.String(200, c.T("orders.created", order.ID))
+func (c *Context) T(key string, args ...any) string {
+	if c.i18n == nil {
+		return i18n.T(c.Request.Context(), key, args...)
+	}
+	return c.i18n.T(c.Locale(), key, args...)
+}
+
+// Localizer returns a translator for the request's locale, or nil without
+// catalogs. Templ components get it through i18n.T(ctx, key).
+func (c *Context) Localizer() *i18n.Localizer {
+	if c.i18n == nil {
+		return nil
+	}
+	return c.i18n.Localizer(c.Locale())
+}
+
+// localize attaches the request's Localizer to the request context once, so
+// templ components rendered with c.Context() can translate.
+func (c *Context) localize() {
+	if c.i18n == nil || c.localized {
+		return
+	}
+	c.localized = true
+	c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), c.Localizer()))
+}
+
+// ---------- Locale Routing ----------
+
+// LocalizeRoutes registers a locale-prefixed copy of the GET routes with the
+// given patterns for every catalog locale, so /about is also served at
+// /en/about and /es/about with the locale set from the prefix. It has no
+// effect unless locale routing is enabled with WithLocaleRouting. Generated
+// code calls it for every page when the project has a locales/ directory.
+func (a *App) LocalizeRoutes(patterns ...string) {
+	bundle := a.routeTree.i18n
+	if bundle == nil || !a.localeRouting {
+		return
+	}
+
+	wanted := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		wanted[p] = true
+	}
+
+	for _, route := range a.routeTree.routes {
+		if route.Method != http.MethodGet || route.Locale != "" || !wanted[route.Pattern] {
+			continue
+		}
+		for _, locale := range bundle.Locales() {
+			localized := *route
+			localized.Pattern = localePattern(locale, route.Pattern)
+			localized.Locale = locale
+			if !route.PriorityOverride {
+				localized.Priority = CalculatePriority(localized.Pattern)
+			}
+			a.routeTree.AddRoute(&localized)
+		}
+	}
+}
+
+// localePattern prefixes pattern with a locale segment.
+func localePattern(locale, pattern string) string {
+	if pattern == "/" {
+		return "/" + locale
+	}
+	return "/" + locale + pattern
+}
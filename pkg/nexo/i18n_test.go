@@ -0,0 +1,127 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+)
+
+func newTestI18nApp(opts ...Option) *App {
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("en", map[string]string{"title": "Welcome", "hello": "Hello, %s!"})
+	bundle.AddMessages("es", map[string]string{"title": "Bienvenido", "hello": "¡Hola, %s!"})
+	return New(append([]Option{WithI18n(bundle)}, opts...)...)
+}
+
+func TestContext_T(t *testing.T) {
+	app := newTestI18nApp()
+	app.Get("/", func(c *Context) error {
+		return c.String(http.StatusOK, c.Locale()+": "+c.T("hello", "Ana"))
+	})
+	app.Mount()
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"es-MX,es;q=0.9", "es: ¡Hola, Ana!"},
+		{"fr", "en: Hello, Ana!"},
+		{"", "en: Hello, Ana!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_T_WithoutCatalogs(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := c.T("title"); got != "title" {
+		t.Errorf("T() = %q, want the key", got)
+	}
+	if c.Locale() != "" || c.Localizer() != nil {
+		t.Errorf("Locale() = %q, Localizer() = %v; want empty without catalogs", c.Locale(), c.Localizer())
+	}
+}
+
+func TestContext_SetLocale_Templ(t *testing.T) {
+	app := newTestI18nApp()
+	title := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, i18n.T(ctx, "title"))
+		return err
+	})
+	app.Get("/", func(c *Context) error {
+		_ = c.Context() // attach the negotiated locale first
+		c.SetLocale("es")
+		return c.Render(http.StatusOK, title)
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Body.String() != "Bienvenido" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "Bienvenido")
+	}
+}
+
+func TestApp_LocalizeRoutes(t *testing.T) {
+	handler := func(c *Context) error {
+		return c.String(http.StatusOK, c.T("title"))
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		app := newTestI18nApp(WithLocaleRouting(true))
+		app.Get("/", handler)
+		app.Get("/about", handler)
+		app.Post("/about", handler)
+		app.LocalizeRoutes("/", "/about")
+		app.Mount()
+
+		tests := []struct {
+			method, path, want string
+			status             int
+		}{
+			{http.MethodGet, "/es", "Bienvenido", http.StatusOK},
+			{http.MethodGet, "/es/about", "Bienvenido", http.StatusOK},
+			{http.MethodGet, "/en/about", "Welcome", http.StatusOK},
+			{http.MethodGet, "/about", "Welcome", http.StatusOK},
+			{http.MethodPost, "/es/about", "", http.StatusMethodNotAllowed},
+		}
+		for _, tt := range tests {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Accept-Language", "en")
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status || (tt.want != "" && w.Body.String() != tt.want) {
+				t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		app := newTestI18nApp()
+		app.Get("/about", handler)
+		app.LocalizeRoutes("/about")
+
+		if n := len(app.RouteTree().Routes()); n != 1 {
+			t.Errorf("registered %d routes, want 1 without locale routing", n)
+		}
+	})
+}
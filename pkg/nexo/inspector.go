@@ -0,0 +1,259 @@
+package nexo
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RouteTable is the live route table of an app, as served by the dev mode
+// route inspector at /_nexo/routes.
+type RouteTable struct {
+	Routes     []RouteTableEntry      `json:"routes"`
+	Middleware []MiddlewareTableEntry `json:"middleware"`
+	Global     []string               `json:"global_middleware"`
+	Proxy      *ProxyTableEntry       `json:"proxy,omitempty"`
+	Match      *RouteMatch            `json:"match,omitempty"`
+}
+
+// RouteTableEntry is a registered route in matching order.
+type RouteTableEntry struct {
+	Method           string `json:"method"`
+	Pattern          string `json:"pattern"`
+	File             string `json:"file,omitempty"`
+	Priority         int    `json:"priority"`
+	PriorityOverride bool   `json:"priority_override,omitempty"`
+	Locale           string `json:"locale,omitempty"`
+	Host             string `json:"host,omitempty"`
+
+	// Middleware is the route's full middleware chain in the order it
+	// runs: global, then path-based, then route-specific.
+	Middleware []string `json:"middleware"`
+}
+
+// MiddlewareTableEntry is path-based middleware from a middleware.go file
+// or RouteTree.AddMiddleware.
+type MiddlewareTableEntry struct {
+	Path       string   `json:"path"`
+	Scope      string   `json:"scope,omitempty"`
+	Middleware []string `json:"middleware"`
+}
+
+// ProxyTableEntry describes the app's proxy.
+type ProxyTableEntry struct {
+	Matchers []string `json:"matchers,omitempty"`
+}
+
+// RouteMatch is the result of looking up a request path in the router.
+type RouteMatch struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Pattern string `json:"pattern,omitempty"` // Matched pattern, empty when nothing matched
+
+	// Allowed lists the methods the path matches for, so a 405 can be told
+	// apart from a 404.
+	Allowed []string `json:"allowed,omitempty"`
+	Proxied bool     `json:"proxied,omitempty"` // The proxy runs for the path
+}
+
+// RouteTable returns the routes, middleware and proxy registered with the
+// app.
+func (a *App) RouteTable() RouteTable {
+	rt := a.routeTree
+	table := RouteTable{
+		Routes:     []RouteTableEntry{},
+		Middleware: []MiddlewareTableEntry{},
+		Global:     funcNames(a.middlewares),
+	}
+
+	for _, r := range rt.Routes() {
+		chain := append([]MiddlewareFunc{}, a.middlewares...)
+		chain = append(chain, rt.routeMiddlewareChain(r)...)
+		chain = append(chain, r.Middlewares...)
+		table.Routes = append(table.Routes, RouteTableEntry{
+			Method:           r.Method,
+			Pattern:          r.Pattern,
+			File:             r.FilePath,
+			Priority:         r.Priority,
+			PriorityOverride: r.PriorityOverride,
+			Locale:           r.Locale,
+			Host:             r.Host,
+			Middleware:       funcNames(chain),
+		})
+	}
+
+	paths := make([]string, 0, len(rt.middlewares))
+	for path := range rt.middlewares {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		table.Middleware = append(table.Middleware, MiddlewareTableEntry{
+			Path:       orDefault(path, "/"),
+			Scope:      rt.middlewareScopes[path],
+			Middleware: funcNames(middlewareFuncs(rt.middlewares[path], nil)),
+		})
+	}
+
+	if rt.HasProxy() {
+		table.Proxy = &ProxyTableEntry{}
+		if rt.proxyConfig != nil {
+			table.Proxy.Matchers = rt.proxyConfig.Matcher
+		}
+	}
+	return table
+}
+
+// MatchRoute looks up method and path in the mounted router, the way a
+// request would be routed.
+func (a *App) MatchRoute(method, path string) RouteMatch {
+	m := RouteMatch{Method: method, Path: path}
+	m.Pattern = a.router.Find(chi.NewRouteContext(), method, path)
+	for _, candidate := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions} {
+		if a.router.Match(chi.NewRouteContext(), candidate, path) {
+			m.Allowed = append(m.Allowed, candidate)
+		}
+	}
+	if a.routeTree.HasProxy() {
+		cfg := a.routeTree.ProxyConfiguration()
+		m.Proxied = cfg == nil || cfg.Matches(path)
+	}
+	return m
+}
+
+// funcNameSuffixRe matches the suffix Go gives closures, e.g. ".func1".
+var funcNameSuffixRe = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcNames returns readable names of middleware functions, like
+// api.Middleware or nexo.Logger.
+func funcNames(mws []MiddlewareFunc) []string {
+	names := make([]string, 0, len(mws))
+	for _, mw := range mws {
+		name := "unknown"
+		if fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()); fn != nil {
+			name = fn.Name()
+			name = name[strings.LastIndex(name, "/")+1:]
+			name = funcNameSuffixRe.ReplaceAllString(name, "")
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// mountInspector registers the route inspector in dev mode: the route
+// table as JSON at /_nexo/routes and an HTML page at /_nexo. Both are
+// registered on the router directly, so app middleware like auth doesn't
+// hide them.
+func (a *App) mountInspector() {
+	if a.Mode() != ModeDevelopment || !a.config.Dev.Inspector || a.hasRoute(http.MethodGet, "/_nexo/routes") {
+		return
+	}
+	a.router.Get("/_nexo/routes", a.handleRouteTable)
+	a.router.Get("/_nexo", a.handleInspector)
+}
+
+// handleRouteTable serves the route table. With ?path=/users/42 it also
+// reports how that path is routed, for ?method= (default GET).
+func (a *App) handleRouteTable(w http.ResponseWriter, r *http.Request) {
+	table := a.RouteTable()
+	if path := r.URL.Query().Get("path"); path != "" {
+		method := strings.ToUpper(orDefault(r.URL.Query().Get("method"), http.MethodGet))
+		match := a.MatchRoute(method, path)
+		table.Match = &match
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(table)
+}
+
+// handleInspector serves the HTML route inspector.
+func (a *App) handleInspector(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write([]byte(inspectorHTML))
+}
+
+// inspectorHTML is the route inspector page. It renders the JSON from
+// /_nexo/routes and tests paths against the router.
+const inspectorHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Nexo Routes</title>
+<style>
+  body { font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; margin: 2rem; color: #1f2937; }
+  h1 { font-size: 1.25rem; } h2 { font-size: 1rem; margin-top: 2rem; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: .35rem .6rem; border-bottom: 1px solid #e5e7eb; vertical-align: top; }
+  th { color: #6b7280; font-weight: normal; }
+  input, select, button { font: inherit; padding: .3rem .5rem; }
+  .muted { color: #6b7280; } .ok { color: #15803d; } .miss { color: #b91c1c; }
+</style>
+</head>
+<body>
+<h1>Nexo Routes</h1>
+<form id="match">
+  <select name="method"><option>GET</option><option>POST</option><option>PUT</option><option>PATCH</option><option>DELETE</option><option>HEAD</option><option>OPTIONS</option></select>
+  <input name="path" placeholder="/users/42" size="40">
+  <button>Test path</button>
+  <span id="result"></span>
+</form>
+<p><input id="filter" placeholder="Filter routes" size="40"></p>
+<table>
+  <thead><tr><th>Method</th><th>Pattern</th><th>Priority</th><th>Middleware</th><th>File</th></tr></thead>
+  <tbody id="routes"></tbody>
+</table>
+<h2>Middleware</h2>
+<table>
+  <thead><tr><th>Path</th><th>Scope</th><th>Middleware</th></tr></thead>
+  <tbody id="middleware"></tbody>
+</table>
+<h2>Proxy</h2>
+<p id="proxy" class="muted"></p>
+<script>
+const esc = s => String(s ?? "").replace(/[&<>"]/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c]));
+let table;
+function render() {
+  const q = document.getElementById("filter").value.toLowerCase();
+  document.getElementById("routes").innerHTML = table.routes
+    .filter(r => (r.method + " " + (r.host || "") + r.pattern + " " + (r.file || "")).toLowerCase().includes(q))
+    .map(r => "<tr><td>" + esc(r.method) + "</td><td>" + (r.host ? "<span class=muted>" + esc(r.host) + "</span>" : "") + esc(r.pattern) + (r.locale ? " <span class=muted>(" + esc(r.locale) + ")</span>" : "") +
+      "</td><td>" + r.priority + (r.priority_override ? "*" : "") + "</td><td>" + esc(r.middleware.join(" → ")) +
+      "</td><td class=muted>" + esc(r.file) + "</td></tr>").join("");
+  document.getElementById("middleware").innerHTML = table.middleware
+    .map(m => "<tr><td>" + esc(m.path) + "</td><td>" + esc(m.scope) + "</td><td>" + esc(m.middleware.join(" → ")) + "</td></tr>").join("") ||
+    "<tr><td colspan=3 class=muted>No path-based middleware</td></tr>";
+  document.getElementById("proxy").textContent = !table.proxy ? "No proxy" :
+    "Proxy runs on " + (table.proxy.matchers && table.proxy.matchers.length ? table.proxy.matchers.join(", ") : "all paths");
+}
+fetch("/_nexo/routes").then(r => r.json()).then(t => { table = t; render(); });
+document.getElementById("filter").addEventListener("input", render);
+document.getElementById("match").addEventListener("submit", e => {
+  e.preventDefault();
+  const f = new FormData(e.target);
+  fetch("/_nexo/routes?" + new URLSearchParams(f)).then(r => r.json()).then(t => {
+    const m = t.match, out = document.getElementById("result");
+    if (m.pattern) {
+      out.className = "ok"; out.textContent = "→ " + m.pattern;
+    } else if (m.allowed && m.allowed.length) {
+      out.className = "miss"; out.textContent = "405: path only matches " + m.allowed.join(", ");
+    } else {
+      out.className = "miss"; out.textContent = "404: no route matches";
+    }
+    if (m.proxied) out.textContent += " (proxy runs first)";
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,110 @@
+package nexo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func inspectorTestMiddleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error { return next(c) }
+}
+
+func newInspectorTestApp() *App {
+	app := New()
+	app.Use(inspectorTestMiddleware)
+	app.RouteTree().AddMiddleware("/api", "", inspectorTestMiddleware)
+	app.Get("/api/users/{id}", func(c *Context) error { return nil })
+	app.Post("/api/users", func(c *Context) error { return nil })
+	app.Get("/about", func(c *Context) error { return nil })
+	app.Mount()
+	return app
+}
+
+func TestRouteInspector(t *testing.T) {
+	t.Setenv("NEXO_DEV", "true")
+	app := newInspectorTestApp()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantPattern string
+		wantAllowed []string
+	}{
+		{"match", "?path=/api/users/42", "/api/users/{id}", []string{"GET"}},
+		{"wrong method", "?path=/api/users&method=get", "", []string{"POST"}},
+		{"no route", "?path=/missing", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_nexo/routes"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d", w.Code)
+			}
+
+			var table RouteTable
+			if err := json.Unmarshal(w.Body.Bytes(), &table); err != nil {
+				t.Fatal(err)
+			}
+			if len(table.Routes) != 3 {
+				t.Errorf("routes = %+v, want 3", table.Routes)
+			}
+			if table.Match == nil || table.Match.Pattern != tt.wantPattern || !slices.Equal(table.Match.Allowed, tt.wantAllowed) {
+				t.Errorf("match = %+v, want pattern %q allowed %v", table.Match, tt.wantPattern, tt.wantAllowed)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_nexo", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/_nexo/routes") {
+		t.Errorf("inspector page = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouteTable(t *testing.T) {
+	table := newInspectorTestApp().RouteTable()
+
+	chains := make(map[string][]string)
+	for _, r := range table.Routes {
+		chains[r.Method+" "+r.Pattern] = r.Middleware
+	}
+	mw := "nexo.inspectorTestMiddleware"
+	if got := chains["GET /api/users/{id}"]; !slices.Equal(got, []string{mw, mw}) {
+		t.Errorf("GET /api/users/{id} middleware = %v", got)
+	}
+	if got := chains["GET /about"]; !slices.Equal(got, []string{mw}) {
+		t.Errorf("GET /about middleware = %v", got)
+	}
+	if len(table.Middleware) != 1 || table.Middleware[0].Path != "/api" {
+		t.Errorf("middleware = %+v", table.Middleware)
+	}
+	if table.Proxy != nil {
+		t.Errorf("proxy = %+v, want nil", table.Proxy)
+	}
+}
+
+func TestRouteInspector_DisabledOutsideDevMode(t *testing.T) {
+	t.Setenv("NEXO_DEV", "")
+	t.Setenv("GO_ENV", "")
+	app := newInspectorTestApp()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_nexo/routes", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+
+	t.Setenv("NEXO_DEV", "true")
+	app = New(WithInspector(false))
+	app.Mount()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_nexo/routes", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status with WithInspector(false) = %d, want 404", w.Code)
+	}
+}
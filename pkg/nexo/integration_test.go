@@ -88,7 +88,10 @@ func TestApp_ListenAndShutdown(t *testing.T) {
 		t.Errorf("expected body to contain 'ok', got %s", body)
 	}
 
-	// Shutdown gracefully
+	// Shutdown gracefully. Close the client's idle connections first: one
+	// dialed while another was returned to the pool never sends a request,
+	// and Shutdown waits up to 5s for such new connections.
+	http.DefaultClient.CloseIdleConnections()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
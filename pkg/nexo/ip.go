@@ -0,0 +1,233 @@
+package nexo
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ---------- Trusted Proxies ----------
+
+// defaultForwardedHeaders are the headers ClientIP reads the client IP
+// from, in order.
+var defaultForwardedHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// TrustedProxiesConfig configures which forwarding headers ClientIP
+// honors, under trusted_proxies: in nexo.yaml.
+type TrustedProxiesConfig struct {
+	// Proxies lists the IPs and CIDRs of the proxies in front of the app,
+	// like 10.0.0.0/8. Forwarding headers are honored only from them, so
+	// clients can't spoof their IP. Without proxies or headers set, every
+	// client is trusted.
+	Proxies []string `mapstructure:"proxies"`
+
+	// Headers carry the client IP, checked in order (default:
+	// X-Forwarded-For, X-Real-IP). Forwarded (RFC 7239) is supported too.
+	Headers []string `mapstructure:"headers"`
+}
+
+// WithTrustedProxies honors forwarding headers only from the given
+// proxies' IPs and CIDRs (see TrustedProxiesConfig). Call it without
+// proxies when clients connect directly, so ClientIP ignores the headers.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithTrustedProxies("10.0.0.0/8", "127.0.0.1"))
+func WithTrustedProxies(proxies ...string) Option {
+	return func(a *App) {
+		a.config.TrustedProxies.Proxies = append([]string{}, proxies...)
+	}
+}
+
+// proxyTrust resolves client IPs through the trusted proxies.
+type proxyTrust struct {
+	proxies []*net.IPNet
+	headers []string
+}
+
+// newProxyTrust returns the proxy trust of config, or nil when it's unset
+// and every client is trusted.
+func newProxyTrust(config TrustedProxiesConfig) (*proxyTrust, error) {
+	if config.Proxies == nil && config.Headers == nil {
+		return nil, nil
+	}
+	pt := &proxyTrust{headers: config.Headers}
+	if pt.headers == nil {
+		pt.headers = defaultForwardedHeaders
+	}
+	for _, entry := range config.Proxies {
+		n, err := parseIPNet(entry)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxies: %w", err)
+		}
+		pt.proxies = append(pt.proxies, n)
+	}
+	return pt, nil
+}
+
+// trusts reports whether ip is a trusted proxy.
+func (pt *proxyTrust) trusts(ip net.IP) bool {
+	for _, n := range pt.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP of the client r came from, without a port. The
+// forwarded addresses are walked back from the connection's while they're
+// trusted proxies, so entries a client prepends are ignored.
+func (pt *proxyTrust) clientIP(r *http.Request) string {
+	remote := stripPort(r.RemoteAddr)
+	ip := net.ParseIP(remote)
+	if ip == nil || !pt.trusts(ip) {
+		return remote
+	}
+	for _, name := range pt.headers {
+		chain := forwardedChain(r.Header, name)
+		for i := len(chain) - 1; i >= 0; i-- {
+			hop := net.ParseIP(chain[i])
+			if hop == nil {
+				break
+			}
+			if i == 0 || !pt.trusts(hop) {
+				return hop.String()
+			}
+		}
+	}
+	return remote
+}
+
+// forwardedChain returns the addresses of a forwarding header, client
+// first.
+func forwardedChain(h http.Header, name string) []string {
+	var chain []string
+	for _, value := range h.Values(name) {
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if strings.EqualFold(name, "Forwarded") {
+				// for=192.0.2.60;proto=http, for="[2001:db8::1]:4711"
+				entry = forwardedFor(entry)
+			}
+			if entry == "" {
+				continue
+			}
+			chain = append(chain, strings.Trim(stripPort(entry), "[]"))
+		}
+	}
+	return chain
+}
+
+// forwardedFor returns the for= parameter of a Forwarded element.
+func forwardedFor(element string) string {
+	for _, param := range strings.Split(element, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(key, "for") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// parseIPNet parses an IP, as a single-address network, or a CIDR.
+func parseIPNet(entry string) (*net.IPNet, error) {
+	entry = strings.TrimSpace(entry)
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP or CIDR %q", entry)
+		}
+		bits := 8 * len(ip.To16())
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, n, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP or CIDR %q", entry)
+	}
+	return n, nil
+}
+
+// ---------- IP Filter ----------
+
+// IPFilterConfig holds configuration for the IP filter middleware.
+type IPFilterConfig struct {
+	// Allow lists the IPs and CIDRs let through; others are denied. Empty
+	// allows every IP not denied.
+	Allow []string
+
+	// Deny lists IPs and CIDRs that are denied, even when allowed.
+	Deny []string
+
+	// DeniedHandler responds to denied requests. Default is a 403 JSON
+	// error.
+	DeniedHandler HandlerFunc
+}
+
+// IPFilter returns a middleware that lets through only clients whose IP
+// is in one of the given IPs and CIDRs. The IP is the connection's, or with
+// trusted proxies configured (see WithTrustedProxies), the one ClientIP
+// returns, so clients can't pick it with forwarding headers.
+//
+// Example:
+//
+//	// app/admin/middleware.go
+//	func Middleware() nexo.MiddlewareFunc {
+//	    return nexo.IPFilter("10.0.0.0/8", "192.168.1.20")
+//	}
+func IPFilter(allow ...string) MiddlewareFunc {
+	return IPFilterWithConfig(IPFilterConfig{Allow: allow})
+}
+
+// IPFilterWithConfig returns an IP filter middleware with custom
+// configuration. It panics on an invalid IP or CIDR, so a typo doesn't
+// leave a prefix open.
+func IPFilterWithConfig(config IPFilterConfig) MiddlewareFunc {
+	parse := func(entries []string) []*net.IPNet {
+		nets := make([]*net.IPNet, 0, len(entries))
+		for _, entry := range entries {
+			n, err := parseIPNet(entry)
+			if err != nil {
+				panic("nexo: IPFilter: " + err.Error())
+			}
+			nets = append(nets, n)
+		}
+		return nets
+	}
+	allow, deny := parse(config.Allow), parse(config.Deny)
+	contains := func(nets []*net.IPNet, ip net.IP) bool {
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	denied := config.DeniedHandler
+	if denied == nil {
+		denied = func(c *Context) error {
+			return c.Error(http.StatusForbidden, "forbidden")
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			// Without trusted proxies, any client could send the forwarding
+			// headers ClientIP reads
+			remote := c.Request.RemoteAddr
+			if c.proxyTrust != nil {
+				remote = c.ClientIP()
+			}
+			ip := net.ParseIP(stripPort(remote))
+			if ip == nil || contains(deny, ip) || (len(allow) > 0 && !contains(allow, ip)) {
+				return denied(c)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP_TrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TrustedProxiesConfig
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "untrusted client can't spoof",
+			config:  TrustedProxiesConfig{Proxies: []string{"10.0.0.0/8"}},
+			remote:  "203.0.113.7:5123",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "trusted proxy",
+			config:  TrustedProxiesConfig{Proxies: []string{"10.0.0.0/8"}},
+			remote:  "10.0.0.5:80",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.9"},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "prepended entries are ignored",
+			config:  TrustedProxiesConfig{Proxies: []string{"10.0.0.0/8"}},
+			remote:  "10.0.0.5:80",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.0.0.6"},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "only the configured headers",
+			config:  TrustedProxiesConfig{Proxies: []string{"10.0.0.5"}, Headers: []string{"X-Real-IP"}},
+			remote:  "10.0.0.5:80",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "198.51.100.9"},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "forwarded",
+			config:  TrustedProxiesConfig{Proxies: []string{"::1"}, Headers: []string{"Forwarded"}},
+			remote:  "[::1]:80",
+			headers: map[string]string{"Forwarded": `for="[2001:db8::1]:4711";proto=https`},
+			want:    "2001:db8::1",
+		},
+		{
+			name:    "no proxies",
+			config:  TrustedProxiesConfig{Proxies: []string{}},
+			remote:  "10.0.0.5:80",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:    "10.0.0.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trust, err := newProxyTrust(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			c := NewContext(httptest.NewRecorder(), req)
+			c.proxyTrust = trust
+			if got := c.ClientIP(); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := newProxyTrust(TrustedProxiesConfig{Proxies: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("newProxyTrust() accepted an invalid CIDR")
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	app := New(WithTrustedProxies("127.0.0.1"))
+	app.DisableLogger()
+	app.Group("/admin", func(g *RouteGroup) {
+		g.Use(IPFilterWithConfig(IPFilterConfig{
+			Allow: []string{"10.0.0.0/8", "192.168.1.20"},
+			Deny:  []string{"10.0.0.66"},
+		}))
+		g.Get("/stats", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	})
+	app.Mount()
+
+	tests := []struct {
+		remote    string
+		forwarded string
+		want      int
+	}{
+		{"10.1.2.3:1000", "", http.StatusOK},
+		{"192.168.1.20:1000", "", http.StatusOK},
+		{"192.168.1.21:1000", "", http.StatusForbidden},
+		{"10.0.0.66:1000", "", http.StatusForbidden},
+		{"203.0.113.7:1000", "10.1.2.3", http.StatusForbidden}, // untrusted header
+		{"127.0.0.1:1000", "10.1.2.3", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+		req.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s (forwarded %q): status = %d, want %d", tt.remote, tt.forwarded, w.Code, tt.want)
+		}
+	}
+
+	// Without trusted proxies, forwarding headers are ignored
+	direct := New()
+	direct.DisableLogger()
+	direct.Use(IPFilter("10.0.0.0/8"))
+	direct.Get("/admin", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	direct.Mount()
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = "203.0.113.7:1000"
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+	w := httptest.NewRecorder()
+	direct.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("spoofed X-Forwarded-For without trusted proxies: status = %d, want 403", w.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("IPFilter() accepted an invalid CIDR")
+		}
+	}()
+	IPFilter("10.0.0.0/8", "not-an-ip")
+}
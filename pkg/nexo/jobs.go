@@ -0,0 +1,420 @@
+package nexo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is returned by Jobs.Get for unknown or expired jobs.
+var ErrJobNotFound = errors.New("nexo: job not found")
+
+// Job polling limits.
+const (
+	// jobMaxWait caps the ?wait of a long poll.
+	jobMaxWait = 30 * time.Second
+
+	// jobPollInterval is how often a long poll rereads the job, to see
+	// updates made by other instances.
+	jobPollInterval = time.Second
+)
+
+// JobStatus is the state of a background job.
+type JobStatus string
+
+// Job statuses.
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobsConfig configures background jobs.
+type JobsConfig struct {
+	// Workers is how many jobs run at once; others wait as pending
+	// (default: 10).
+	Workers int
+
+	// TTL is how long a job's status is kept after its last update
+	// (default: 24h).
+	TTL time.Duration
+
+	// Cache stores job statuses (default: the app's cache backend). With a
+	// shared cache like Redis, any instance can answer a status poll.
+	Cache Cache
+}
+
+// Job is the status of a background job, as served by its status endpoint.
+type Job struct {
+	// ID identifies the job in its status URL.
+	ID string `json:"id"`
+
+	// Name is the kind of job, like "report.export".
+	Name string `json:"name"`
+
+	// Status is the state of the job.
+	Status JobStatus `json:"status"`
+
+	// Progress is the percentage done, as reported with JobProgress.
+	Progress int `json:"progress,omitempty"`
+
+	// Result is the JSON-encoded result of a succeeded job.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is the error of a failed job.
+	Error string `json:"error,omitempty"`
+
+	// CreatedAt is when the job was accepted.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the status last changed.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// JobFunc runs a background job and returns its result, which is stored
+// as JSON. ctx outlives the request that started the job.
+type JobFunc func(ctx context.Context) (any, error)
+
+// Jobs runs work in the background and serves its status over HTTP: a
+// handler accepts a request with 202 Accepted and a Location header, and
+// the client polls that URL until the job is done. Statuses are kept in
+// the cache backend for TTL.
+type Jobs struct {
+	config JobsConfig
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	base    string                   // URL path of the status endpoint
+	waiters map[string]chan struct{} // closed when the job changes
+}
+
+// NewJobs creates a background job runner.
+//
+// Example:
+//
+//	jobs := nexo.NewJobs(nexo.JobsConfig{Workers: 4})
+//	jobs.Register(app, "/api/jobs")
+//
+//	app.Post("/api/reports", func(c *nexo.Context) error {
+//	    return jobs.Accept(c, "report.export", func(ctx context.Context) (any, error) {
+//	        return reports.Export(ctx)
+//	    })
+//	})
+func NewJobs(config JobsConfig) *Jobs {
+	if config.Workers <= 0 {
+		config.Workers = 10
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return &Jobs{
+		config:  config,
+		sem:     make(chan struct{}, config.Workers),
+		base:    "/api/jobs",
+		waiters: make(map[string]chan struct{}),
+	}
+}
+
+// Register serves the status of jobs at pattern/{id}, and points the
+// Location header of accepted jobs there.
+func (j *Jobs) Register(r Registrar, pattern string) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	_, full := r.register(http.MethodGet, pattern+"/{id}", j.Handler())
+	j.mu.Lock()
+	j.base = strings.TrimSuffix(full, "/{id}")
+	j.mu.Unlock()
+}
+
+// Handler returns the status endpoint, which serves the job named by the
+// id route parameter. Unfinished jobs get 202 Accepted with a Retry-After
+// header, finished ones 200 OK. With ?wait=10s the request is held until
+// the job changes or the wait (at most 30s) runs out, so clients can long
+// poll instead of polling on an interval.
+func (j *Jobs) Handler() HandlerFunc {
+	return func(c *Context) error {
+		cache := j.cache(c)
+		id := c.Param("id")
+		job, err := j.get(c.Context(), cache, id)
+		if err != nil {
+			return j.notFound(err)
+		}
+
+		if wait, err := time.ParseDuration(c.Query("wait")); err == nil && wait > 0 && !job.Done() {
+			if wait > jobMaxWait {
+				wait = jobMaxWait
+			}
+			job, err = j.wait(c.Context(), cache, job, wait)
+			if err != nil {
+				return j.notFound(err)
+			}
+		}
+
+		c.SetHeader("Cache-Control", "no-store")
+		if !job.Done() {
+			c.SetHeader("Retry-After", "1")
+			return c.JSON(http.StatusAccepted, job)
+		}
+		return c.JSON(http.StatusOK, job)
+	}
+}
+
+// Accept starts fn in the background and responds 202 Accepted with the
+// job's status, and its status URL in the Location header.
+func (j *Jobs) Accept(c *Context, name string, fn JobFunc) error {
+	job, err := j.Enqueue(c, name, fn)
+	if err != nil {
+		return err
+	}
+	c.SetHeader("Location", j.Location(job.ID))
+	c.SetHeader("Retry-After", "1")
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// Enqueue starts fn in the background and returns its pending status.
+// Use it when the handler responds itself; Accept responds with the
+// status.
+func (j *Jobs) Enqueue(c *Context, name string, fn JobFunc) (*Job, error) {
+	cache := j.cache(c)
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &Job{ID: id, Name: name, Status: JobPending, CreatedAt: now, UpdatedAt: now}
+	if err := j.save(c.Context(), cache, job); err != nil {
+		return nil, fmt.Errorf("nexo: saving job: %w", err)
+	}
+
+	ctx := context.WithoutCancel(c.Context())
+	j.wg.Add(1)
+	go j.run(ctx, cache, *job, fn)
+	return job, nil
+}
+
+// Location returns the status URL of the job id.
+func (j *Jobs) Location(id string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.base + "/" + id
+}
+
+// Get returns the status of the job id, or ErrJobNotFound.
+func (j *Jobs) Get(c *Context, id string) (*Job, error) {
+	return j.get(c.Context(), j.cache(c), id)
+}
+
+// Close waits for the running jobs to finish, or for ctx to be done.
+func (j *Jobs) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run runs fn once a worker is free, recording its status as it goes.
+func (j *Jobs) run(ctx context.Context, cache Cache, job Job, fn JobFunc) {
+	defer j.wg.Done()
+	j.sem <- struct{}{}
+	defer func() { <-j.sem }()
+
+	p := &jobProgress{jobs: j, cache: cache, job: &job}
+	p.mu.Lock()
+	job.Status = JobRunning
+	j.update(ctx, cache, &job)
+	p.mu.Unlock()
+
+	result, err := runJob(context.WithValue(ctx, jobContextKey{}, p), fn)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case err != nil:
+		job.Status = JobFailed
+		job.Error = err.Error()
+	default:
+		data, merr := json.Marshal(result)
+		if merr != nil {
+			job.Status = JobFailed
+			job.Error = "encoding result: " + merr.Error()
+			break
+		}
+		job.Status = JobSucceeded
+		job.Progress = 100
+		if string(data) != "null" {
+			job.Result = data
+		}
+	}
+	j.update(ctx, cache, &job)
+}
+
+// runJob calls fn, turning a panic into an error.
+func runJob(ctx context.Context, fn JobFunc) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// update stores a changed job and wakes its long polls.
+func (j *Jobs) update(ctx context.Context, cache Cache, job *Job) {
+	job.UpdatedAt = time.Now()
+	_ = j.save(ctx, cache, job)
+	j.mu.Lock()
+	if ch, ok := j.waiters[job.ID]; ok {
+		close(ch)
+		delete(j.waiters, job.ID)
+	}
+	j.mu.Unlock()
+}
+
+// wait returns the job once it changes from job, or when wait runs out.
+func (j *Jobs) wait(ctx context.Context, cache Cache, job *Job, wait time.Duration) (*Job, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	defer func() {
+		j.mu.Lock()
+		delete(j.waiters, job.ID)
+		j.mu.Unlock()
+	}()
+
+	for {
+		j.mu.Lock()
+		ch, ok := j.waiters[job.ID]
+		if !ok {
+			ch = make(chan struct{})
+			j.waiters[job.ID] = ch
+		}
+		j.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return job, nil
+		case <-timer.C:
+			return job, nil
+		case <-ch:
+		case <-ticker.C:
+		}
+		current, err := j.get(ctx, cache, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != job.Status || current.Progress != job.Progress {
+			return current, nil
+		}
+	}
+}
+
+// cache returns the cache storing the statuses.
+func (j *Jobs) cache(c *Context) Cache {
+	if j.config.Cache != nil {
+		return j.config.Cache
+	}
+	return c.Cache()
+}
+
+// get loads the job id.
+func (j *Jobs) get(ctx context.Context, cache Cache, id string) (*Job, error) {
+	if !validJobID(id) {
+		return nil, ErrJobNotFound
+	}
+	job, ok, err := CacheGet[*Job](ctx, cache, "job:"+id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// save stores job for TTL.
+func (j *Jobs) save(ctx context.Context, cache Cache, job *Job) error {
+	return CacheSet(ctx, cache, "job:"+job.ID, job, j.config.TTL)
+}
+
+// notFound maps a lookup error to the status endpoint's response.
+func (j *Jobs) notFound(err error) error {
+	if errors.Is(err, ErrJobNotFound) {
+		return NotFound("job not found")
+	}
+	return err
+}
+
+// jobContextKey is the context key for the progress of a running job.
+type jobContextKey struct{}
+
+// jobProgress records the progress of a running job.
+type jobProgress struct {
+	jobs  *Jobs
+	cache Cache
+
+	mu  sync.Mutex
+	job *Job
+}
+
+// JobProgress records the percentage done of the job running with ctx, for
+// its status endpoint. Outside a job it does nothing.
+//
+// Example:
+//
+//	for i, row := range rows {
+//	    export(row)
+//	    nexo.JobProgress(ctx, i*100/len(rows))
+//	}
+func JobProgress(ctx context.Context, percent int) {
+	p, ok := ctx.Value(jobContextKey{}).(*jobProgress)
+	if !ok {
+		return
+	}
+	percent = max(0, min(percent, 99))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.job.Progress == percent || p.job.Done() {
+		return
+	}
+	p.job.Progress = percent
+	p.jobs.update(ctx, p.cache, p.job)
+}
+
+// newJobID returns a random job ID.
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validJobID reports whether id looks like an ID from newJobID.
+func validJobID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
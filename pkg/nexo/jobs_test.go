@@ -0,0 +1,118 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJobs(t *testing.T) {
+	jobs := NewJobs(JobsConfig{Cache: NewMemoryCache(0)})
+	release := make(chan struct{})
+	app := New()
+	jobs.Register(app, "/api/jobs")
+	app.Post("/api/reports", func(c *Context) error {
+		return jobs.Accept(c, "report.export", func(ctx context.Context) (any, error) {
+			JobProgress(ctx, 50)
+			<-release
+			return map[string]int{"rows": 3}, nil
+		})
+	})
+	app.Post("/api/broken", func(c *Context) error {
+		return jobs.Accept(c, "broken", func(ctx context.Context) (any, error) {
+			return nil, errors.New("disk full")
+		})
+	})
+	app.Mount()
+
+	get := func(target string) (*httptest.ResponseRecorder, Job) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		var job Job
+		_ = json.Unmarshal(w.Body.Bytes(), &job)
+		return w, job
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/api/reports", nil))
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusAccepted || len(location) != len("/api/jobs/")+32 {
+		t.Fatalf("Accept() = %d, Location %q", w.Code, location)
+	}
+
+	// Long poll until the job reports its progress
+	w, job := get(location + "?wait=5s")
+	for range 3 {
+		if job.Progress == 50 {
+			break
+		}
+		w, job = get(location + "?wait=5s")
+	}
+	if w.Code != http.StatusAccepted || job.Status != JobRunning || w.Header().Get("Retry-After") == "" {
+		t.Errorf("running job = %d %+v", w.Code, job)
+	}
+
+	close(release)
+	if err := jobs.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w, job = get(location)
+	if w.Code != http.StatusOK || job.Status != JobSucceeded || string(job.Result) != `{"rows":3}` || job.Progress != 100 {
+		t.Errorf("finished job = %d %+v", w.Code, job)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/api/broken", nil))
+	_ = jobs.Close(context.Background())
+	w, job = get(w.Header().Get("Location"))
+	if w.Code != http.StatusOK || job.Status != JobFailed || job.Error != "disk full" {
+		t.Errorf("failed job = %d %+v", w.Code, job)
+	}
+
+	for _, target := range []string{"/api/jobs/0123456789abcdef0123456789abcdef", "/api/jobs/nope"} {
+		if w, _ := get(target); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", target, w.Code)
+		}
+	}
+}
+
+func TestJobs_Workers(t *testing.T) {
+	jobs := NewJobs(JobsConfig{Workers: 1, Cache: NewMemoryCache(0)})
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+
+	release := make(chan struct{})
+	first, err := jobs.Enqueue(c, "first", func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ := jobs.Get(c, first.ID)
+		if job.Status == JobRunning || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	second, _ := jobs.Enqueue(c, "second", func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	if job, _ := jobs.Get(c, second.ID); job.Status != JobPending {
+		t.Errorf("second job = %s while the only worker is busy, want pending", job.Status)
+	}
+
+	close(release)
+	_ = jobs.Close(context.Background())
+	if job, _ := jobs.Get(c, second.ID); job.Status != JobFailed || job.Error != "panic: boom" {
+		t.Errorf("panicking job = %+v", job)
+	}
+	if jobs.Location(first.ID) != "/api/jobs/"+first.ID {
+		t.Errorf("Location() = %q", jobs.Location(first.ID))
+	}
+}
@@ -0,0 +1,95 @@
+package nexo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONCodec encodes and decodes JSON for c.JSON, c.Bind and SSE JSON events.
+// Implement it to plug in a faster library such as sonic or go-json:
+//
+//	type sonicCodec struct{}
+//
+//	func (sonicCodec) Encode(w io.Writer, v any) error { return sonic.ConfigStd.NewEncoder(w).Encode(v) }
+//	func (sonicCodec) Decode(r io.Reader, v any) error { return sonic.ConfigStd.NewDecoder(r).Decode(v) }
+//
+//	app := nexo.New(nexo.WithJSONCodec(sonicCodec{}))
+type JSONCodec interface {
+	// Encode writes the JSON encoding of v to w.
+	Encode(w io.Writer, v any) error
+
+	// Decode reads the next JSON value from r into v.
+	Decode(r io.Reader, v any) error
+}
+
+// StdJSONCodec is the default codec. It uses encoding/json and streams
+// directly to the ResponseWriter without an intermediate buffer.
+type StdJSONCodec struct{}
+
+// Encode writes the JSON encoding of v to w.
+func (StdJSONCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads the next JSON value from r into v.
+func (StdJSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// PooledJSONCodec uses encoding/json with pooled buffers and encoders.
+// The whole value is encoded before anything is written, so an encoding
+// error never leaves a partial body, and the response is written in a
+// single call.
+type PooledJSONCodec struct{}
+
+// maxPooledBufferSize bounds the buffers kept by PooledJSONCodec.
+const maxPooledBufferSize = 64 << 10
+
+// pooledEncoder is an encoder bound to its buffer, so both are reused together.
+type pooledEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var encoderPool = sync.Pool{
+	New: func() any {
+		pe := new(pooledEncoder)
+		pe.enc = json.NewEncoder(&pe.buf)
+		return pe
+	},
+}
+
+// Encode writes the JSON encoding of v to w.
+func (PooledJSONCodec) Encode(w io.Writer, v any) error {
+	pe := encoderPool.Get().(*pooledEncoder)
+	defer func() {
+		if pe.buf.Cap() <= maxPooledBufferSize {
+			pe.buf.Reset()
+			encoderPool.Put(pe)
+		}
+	}()
+
+	if err := pe.enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(pe.buf.Bytes())
+	return err
+}
+
+// Decode reads the next JSON value from r into v.
+func (PooledJSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// defaultJSONCodec is used when no codec has been configured.
+var defaultJSONCodec JSONCodec = StdJSONCodec{}
+
+// jsonCodec returns the codec configured for the Context.
+func (c *Context) jsonCodec() JSONCodec {
+	if c.codec != nil {
+		return c.codec
+	}
+	return defaultJSONCodec
+}
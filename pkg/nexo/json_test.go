@@ -0,0 +1,114 @@
+package nexo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONCodecs(t *testing.T) {
+	codecs := map[string]JSONCodec{
+		"std":    StdJSONCodec{},
+		"pooled": PooledJSONCodec{},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := codec.Encode(&buf, map[string]string{"name": "nexo"}); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got, want := buf.String(), "{\"name\":\"nexo\"}\n"; got != want {
+				t.Errorf("Encode() = %q, want %q", got, want)
+			}
+
+			var decoded map[string]string
+			if err := codec.Decode(&buf, &decoded); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded["name"] != "nexo" {
+				t.Errorf("Decode() = %v", decoded)
+			}
+		})
+	}
+}
+
+func TestPooledJSONCodec_ErrorWritesNothing(t *testing.T) {
+	var buf bytes.Buffer
+	err := PooledJSONCodec{}.Encode(&buf, map[string]any{"bad": make(chan int)})
+	if err == nil {
+		t.Fatal("expected encoding error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q on error, want nothing", buf.String())
+	}
+}
+
+// upperCodec is a test codec that marks its output.
+type upperCodec struct{ decoded *bool }
+
+func (upperCodec) Encode(w io.Writer, v any) error {
+	_, err := io.WriteString(w, "CUSTOM")
+	return err
+}
+
+func (c upperCodec) Decode(r io.Reader, v any) error {
+	*c.decoded = true
+	return errors.New("custom decode")
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	decoded := false
+	app := New(WithJSONCodec(upperCodec{decoded: &decoded}))
+	app.Post("/echo", func(c *Context) error {
+		var v map[string]any
+		if err := c.Bind(&v); err != nil {
+			return c.JSON(http.StatusOK, nil)
+		}
+		return nil
+	})
+	app.Mount()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{}`))
+	app.ServeHTTP(rec, req)
+
+	if !decoded {
+		t.Error("Bind did not use the configured codec")
+	}
+	if got := rec.Body.String(); got != "CUSTOM" {
+		t.Errorf("body = %q, want %q", got, "CUSTOM")
+	}
+}
+
+type benchPayload struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Tags  []string `json:"tags"`
+}
+
+func benchmarkJSONCodec(b *testing.B, codec JSONCodec) {
+	payload := benchPayload{ID: 1, Name: "Alice", Email: "alice@example.com", Tags: []string{"admin", "ops"}}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := codec.Encode(io.Discard, &payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStdJSONCodec_Encode(b *testing.B) {
+	benchmarkJSONCodec(b, StdJSONCodec{})
+}
+
+func BenchmarkPooledJSONCodec_Encode(b *testing.B) {
+	benchmarkJSONCodec(b, PooledJSONCodec{})
+}
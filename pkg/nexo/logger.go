@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -92,20 +94,41 @@ type RequestLoggerConfig struct {
 	// MaxErrorLength is the maximum length for error messages in logs.
 	// Messages longer than this are truncated. Default: 100.
 	MaxErrorLength int
+
+	// Sampling
+	SampleRates map[string]float64 // Fraction of requests logged per status class: "2xx" to "5xx" (default: all)
+
+	// Verbose output, on lines below the request
+	ShowHeaders     bool // Show request headers (default: false)
+	LogRequestBody  bool // Show the request body as the app read it (default: false)
+	LogResponseBody bool // Show the response body (default: false)
+	MaxBodySize     int  // Bytes of each body shown (default: 1024)
+
+	// Redaction
+	RedactHeaders []string // Headers shown as [REDACTED] (default: DefaultRedactHeaders)
+	RedactFields  []string // JSON and form fields shown as [REDACTED] in bodies (default: DefaultRedactFields)
 }
 
 // DefaultRequestLoggerConfig returns sensible defaults for the request logger.
 func DefaultRequestLoggerConfig() RequestLoggerConfig {
+	return requestLoggerConfig(configuredMode())
+}
+
+// requestLoggerConfig returns the defaults of the request logger in mode
+// m, or with no mode set when m is "".
+func requestLoggerConfig(m Mode) RequestLoggerConfig {
 	level := LogLevelInfo
 
 	// Check environment variable for log level
 	if envLevel := os.Getenv("NEXO_LOG_LEVEL"); envLevel != "" {
 		level = ParseLogLevel(envLevel)
 	} else {
-		// Auto-detect dev vs prod mode
-		if os.Getenv("NEXO_DEV") == "true" || os.Getenv("GO_ENV") == "development" {
+		// Verbose in development, quiet in tests and production. Without
+		// a mode set, log at info.
+		switch m {
+		case ModeDevelopment:
 			level = LogLevelDebug
-		} else if os.Getenv("GO_ENV") == "production" {
+		case ModeTest, ModeProduction:
 			level = LogLevelWarn
 		}
 	}
@@ -128,12 +151,24 @@ func DefaultRequestLoggerConfig() RequestLoggerConfig {
 type RequestLogger struct {
 	config RequestLoggerConfig
 
+	// mu guards config.Level, which SetLevel changes while requests are
+	// logged
+	mu sync.RWMutex
+
 	// Color functions
 	methodColors map[string]func(a ...interface{}) string
 	statusColors map[int]func(a ...interface{}) string
 	dim          func(a ...interface{}) string
 	cyan         func(a ...interface{}) string
 	yellow       func(a ...interface{}) string
+
+	// Redaction, from config.RedactHeaders and config.RedactFields
+	redactHeaders map[string]bool
+	redactJSON    *regexp.Regexp
+	redactForm    *regexp.Regexp
+
+	// trust resolves client IPs through the app's trusted proxies
+	trust *proxyTrust
 }
 
 // NewRequestLogger creates a new request logger with the given configuration.
@@ -169,6 +204,8 @@ func NewRequestLogger(config RequestLoggerConfig) *RequestLogger {
 	rl.cyan = color.New(color.FgCyan).SprintFunc()
 	rl.yellow = color.New(color.FgYellow).SprintFunc()
 
+	rl.initRedaction()
+
 	return rl
 }
 
@@ -194,10 +231,25 @@ func (rl *RequestLogger) getStatusColor(status int) func(a ...interface{}) strin
 	}
 }
 
+// SetLevel changes the log level. It is safe to call while requests are
+// logged, e.g. when the config is reloaded.
+func (rl *RequestLogger) SetLevel(level LogLevel) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.config.Level = level
+}
+
+// Level returns the log level.
+func (rl *RequestLogger) Level() LogLevel {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.config.Level
+}
+
 // ShouldLog determines if a request should be logged based on configuration.
 func (rl *RequestLogger) ShouldLog(path string, status int) bool {
 	// Check level
-	switch rl.config.Level {
+	switch rl.Level() {
 	case LogLevelOff:
 		return false
 	case LogLevelError:
@@ -351,16 +403,21 @@ func (rl *RequestLogger) formatError(err error) string {
 
 // ProxyAction represents the action taken by the proxy.
 type ProxyAction struct {
-	Type   string // "continue", "rewrite", "redirect", "response"
+	Type   string // "continue", "rewrite", "redirect", "response", "forward"
 	Target string // URL for rewrite/redirect
 }
 
 // Log logs a request with the given parameters.
 func (rl *RequestLogger) Log(r *http.Request, status int, size int64, latency time.Duration, proxyAction *ProxyAction, err error) {
+	rl.log(r, status, size, latency, proxyAction, err, nil)
+}
+
+// log logs a request, with the bodies captured for it (nil without).
+func (rl *RequestLogger) log(r *http.Request, status int, size int64, latency time.Duration, proxyAction *ProxyAction, err error, bodies *loggedBodies) {
 	path := r.URL.Path
 
 	// Check if we should log this request
-	if !rl.ShouldLog(path, status) {
+	if !rl.ShouldLog(path, status) || !rl.sampled(status) {
 		return
 	}
 
@@ -418,12 +475,15 @@ func (rl *RequestLogger) Log(r *http.Request, status int, size int64, latency ti
 		case "rewrite":
 			msg.WriteString(" ")
 			msg.WriteString(rl.cyan("[rewrite]"))
+		case "forward":
+			msg.WriteString(" ")
+			msg.WriteString(rl.cyan(fmt.Sprintf("[forward → %s]", proxyAction.Target)))
 		}
 	}
 
 	// Client IP (optional)
 	if rl.config.ShowIP {
-		ip := getClientIP(r)
+		ip := requestIP(r, rl.trust)
 		msg.WriteString(" ")
 		msg.WriteString(rl.dim(fmt.Sprintf("[%s]", ip)))
 	}
@@ -447,10 +507,22 @@ func (rl *RequestLogger) Log(r *http.Request, status int, size int64, latency ti
 		}
 	}
 
+	// Headers and bodies (optional)
+	msg.WriteString(rl.details(r, bodies))
+
 	// Print the log message
 	log.Println(msg.String())
 }
 
+// requestIP returns the client IP of r through trust, or from its
+// forwarding headers without trusted proxies.
+func requestIP(r *http.Request, trust *proxyTrust) string {
+	if trust != nil {
+		return trust.clientIP(r)
+	}
+	return getClientIP(r)
+}
+
 // getClientIP extracts the client IP from the request.
 func getClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header first
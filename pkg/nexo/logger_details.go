@@ -0,0 +1,212 @@
+package nexo
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultRedactHeaders are the request headers the request logger redacts
+// unless RequestLoggerConfig.RedactHeaders is set.
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// DefaultRedactFields are the body fields the request logger redacts unless
+// RequestLoggerConfig.RedactFields is set.
+var DefaultRedactFields = []string{"password", "secret", "token", "access_token", "refresh_token", "api_key", "client_secret"}
+
+// redacted replaces redacted values in logs.
+const redacted = "[REDACTED]"
+
+// defaultMaxBodySize is the default of RequestLoggerConfig.MaxBodySize.
+const defaultMaxBodySize = 1024
+
+// initRedaction prepares the redaction of headers and body fields. Nil
+// lists get the defaults; empty ones redact nothing.
+func (rl *RequestLogger) initRedaction() {
+	headers := rl.config.RedactHeaders
+	if headers == nil {
+		headers = DefaultRedactHeaders
+	}
+	rl.redactHeaders = make(map[string]bool, len(headers))
+	for _, h := range headers {
+		rl.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+
+	fields := rl.config.RedactFields
+	if fields == nil {
+		fields = DefaultRedactFields
+	}
+	if len(fields) == 0 {
+		return
+	}
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	names := strings.Join(quoted, "|")
+	// "field": "value" or "field": 123 in JSON, at any depth
+	rl.redactJSON = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	// field=value in form bodies
+	rl.redactForm = regexp.MustCompile(`(?i)((?:^|&)(?:` + names + `)=)[^&]*`)
+}
+
+// ---------- Sampling ----------
+
+// sampled reports whether a request with status is logged under the
+// configured sample rates.
+func (rl *RequestLogger) sampled(status int) bool {
+	rate, ok := rl.config.SampleRates[fmt.Sprintf("%dxx", status/100)]
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// ---------- Bodies ----------
+
+// loggedBodies holds the bodies captured for a request's log line.
+type loggedBodies struct {
+	request  *cappedBuffer
+	response *cappedBuffer
+	header   http.Header // response headers, for the response body's type
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the
+// rest.
+type cappedBuffer struct {
+	data  []byte
+	max   int
+	total int64
+}
+
+func (b *cappedBuffer) write(p []byte) {
+	b.total += int64(len(p))
+	if room := b.max - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(room, len(p))]...)
+	}
+}
+
+// bodyCapture records the request body as the app reads it.
+type bodyCapture struct {
+	io.ReadCloser
+	buf *cappedBuffer
+}
+
+func (b *bodyCapture) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.write(p[:n])
+	return n, err
+}
+
+// capture starts capturing the bodies the config logs. It returns a copy
+// of r whose body is captured, and nil bodies when none is logged.
+func (rl *RequestLogger) capture(r *http.Request, rw *responseWriter) (*http.Request, *loggedBodies) {
+	if !rl.config.LogRequestBody && !rl.config.LogResponseBody {
+		return r, nil
+	}
+	max := rl.config.MaxBodySize
+	if max <= 0 {
+		max = defaultMaxBodySize
+	}
+	bodies := &loggedBodies{header: rw.Header()}
+	if rl.config.LogRequestBody && r.Body != nil && r.Body != http.NoBody {
+		bodies.request = &cappedBuffer{max: max}
+		r = r.WithContext(r.Context())
+		r.Body = &bodyCapture{ReadCloser: r.Body, buf: bodies.request}
+	}
+	if rl.config.LogResponseBody {
+		bodies.response = &cappedBuffer{max: max}
+		rw.body = bodies.response
+	}
+	return r, bodies
+}
+
+// ---------- Details ----------
+
+// details returns the lines logged below a request: its headers and
+// bodies, redacted.
+func (rl *RequestLogger) details(r *http.Request, bodies *loggedBodies) string {
+	var b strings.Builder
+	if rl.config.ShowHeaders {
+		b.WriteString("\n    " + rl.dim("headers:") + " " + rl.formatHeaders(r.Header))
+	}
+	if bodies != nil && bodies.request != nil && bodies.request.total > 0 {
+		b.WriteString("\n    " + rl.dim("request:") + " " + rl.formatBody(bodies.request, r.Header.Get("Content-Type")))
+	}
+	if bodies != nil && bodies.response != nil && bodies.response.total > 0 {
+		b.WriteString("\n    " + rl.dim("response:") + " " + rl.formatBody(bodies.response, bodies.header.Get("Content-Type")))
+	}
+	return b.String()
+}
+
+// formatHeaders renders headers sorted by name, with redacted values
+// replaced.
+func (rl *RequestLogger) formatHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := strings.Join(h[name], ", ")
+		if rl.redactHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		parts[i] = name + "=" + value
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatBody renders a captured body of contentType with redacted fields
+// replaced. Binary bodies are summarized by size.
+func (rl *RequestLogger) formatBody(buf *cappedBuffer, contentType string) string {
+	data := buf.data
+	truncated := buf.total > int64(len(data))
+	if truncated {
+		// Don't cut a character in half
+		for len(data) > 0 && !utf8.Valid(data) && len(buf.data)-len(data) < utf8.UTFMax {
+			data = data[:len(data)-1]
+		}
+	}
+	if !isTextBody(contentType, data) {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "" {
+			mediaType = "binary"
+		}
+		return fmt.Sprintf("[%s, %s]", mediaType, rl.formatSize(buf.total))
+	}
+
+	body := strings.Join(strings.Fields(string(data)), " ")
+	if rl.redactJSON != nil {
+		body = rl.redactJSON.ReplaceAllString(body, `${1}"`+redacted+`"`)
+		body = rl.redactForm.ReplaceAllString(body, "${1}"+redacted)
+	}
+	if truncated {
+		body += fmt.Sprintf("... (%s)", rl.formatSize(buf.total))
+	}
+	return body
+}
+
+// isTextBody reports whether a body of contentType is text worth logging.
+func isTextBody(contentType string, data []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "":
+		return utf8.Valid(data)
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded",
+		mediaType == "application/javascript":
+		return true
+	}
+	return false
+}
@@ -664,3 +664,104 @@ func TestRequestLogger_Log_SmallJSONIsLogged(t *testing.T) {
 		t.Error("Log output should contain small JSON error message")
 	}
 }
+
+func TestRequestLogger_SampleRates(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	rl := NewRequestLogger(RequestLoggerConfig{
+		DisableColors: true,
+		Level:         LogLevelInfo,
+		SampleRates:   map[string]float64{"2xx": 0, "5xx": 1},
+	})
+
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{200, false},
+		{204, false},
+		{404, true}, // no rate: all logged
+		{500, true},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		rl.Log(httptest.NewRequest(http.MethodGet, "/api/users", nil), tt.status, 0, time.Millisecond, nil, nil)
+		if got := buf.Len() > 0; got != tt.want {
+			t.Errorf("status %d logged = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLogger_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	app := New()
+	app.SetLogger(RequestLoggerConfig{
+		DisableColors:   true,
+		Level:           LogLevelInfo,
+		ShowHeaders:     true,
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		MaxBodySize:     64,
+		RedactFields:    []string{"password", "card"},
+	})
+	app.Post("/login", func(c *Context) error {
+		body, err := c.RawBody()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(body), "hunter2") {
+			t.Errorf("handler body = %s, want it unredacted", body)
+		}
+		return c.JSON(http.StatusOK, map[string]string{"token": "abc", "note": strings.Repeat("x", 100)})
+	})
+	app.Post("/form", func(c *Context) error {
+		_ = c.FormValue("user")
+		return c.Blob(http.StatusOK, "image/png", make([]byte, 2048))
+	})
+	app.Mount()
+
+	t.Run("json", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user": "ada", "password": "hunter2", "card": 4242}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret-token")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		output := buf.String()
+		for _, want := range []string{
+			"Authorization=[REDACTED]",
+			"Content-Type=application/json",
+			`request: {"user": "ada", "password": "[REDACTED]", "card": "[REDACTED]"}`,
+			`response: {"note":"xxx`,
+			"... (",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("output doesn't contain %q:\n%s", want, output)
+			}
+		}
+		for _, leaked := range []string{"hunter2", "4242", "secret-token"} {
+			if strings.Contains(output, leaked) {
+				t.Errorf("output contains %q:\n%s", leaked, output)
+			}
+		}
+	})
+
+	t.Run("form and binary", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader("user=ada&password=hunter2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		output := buf.String()
+		for _, want := range []string{"request: user=ada&password=[REDACTED]", "response: [image/png, 2.0KB]"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("output doesn't contain %q:\n%s", want, output)
+			}
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package nexo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// DefaultMaintenanceFile is the file `nexo maintenance on` writes. While it
+// exists the app serves the maintenance page, without a restart.
+const DefaultMaintenanceFile = ".nexo/maintenance.json"
+
+// maintenanceFileInterval is how often the app checks the maintenance file.
+const maintenanceFileInterval = time.Second
+
+// MaintenanceConfig configures maintenance mode, under maintenance: in
+// nexo.yaml.
+type MaintenanceConfig struct {
+	// Enabled starts the app in maintenance mode. NEXO_MAINTENANCE=on does
+	// the same, so a hosting provider can toggle it with an env var.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Allow lists client IPs and CIDRs, like 10.0.0.0/8, that bypass
+	// maintenance mode.
+	Allow []string `mapstructure:"allow"`
+
+	// Paths lists path prefixes served during maintenance, like health
+	// checks (default: /health, /healthz).
+	Paths []string `mapstructure:"paths"`
+
+	// RetryAfter is sent in the Retry-After header (default: 1h).
+	RetryAfter time.Duration `mapstructure:"retry_after"`
+
+	// File turns maintenance mode on while it exists (default:
+	// .nexo/maintenance.json).
+	File string `mapstructure:"file"`
+}
+
+// MaintenanceState is the content of the maintenance file.
+type MaintenanceState struct {
+	// Allow lists client IPs and CIDRs that bypass maintenance mode, on top
+	// of MaintenanceConfig.Allow.
+	Allow []string `json:"allow,omitempty"`
+
+	// RetryAfter overrides MaintenanceConfig.RetryAfter, in seconds.
+	RetryAfter int `json:"retry_after,omitempty"`
+
+	// Since is when maintenance mode was turned on.
+	Since time.Time `json:"since,omitzero"`
+}
+
+// maintenance holds the maintenance mode of an app.
+type maintenance struct {
+	mu         sync.Mutex
+	enabled    bool
+	allow      []*net.IPNet
+	paths      []string
+	retryAfter time.Duration
+	page       templ.Component
+
+	file       string
+	checked    time.Time // last check of the file
+	fileState  *MaintenanceState
+	fileAllow  []*net.IPNet
+	fileExists bool
+}
+
+// newMaintenance reads the maintenance settings of config and the
+// NEXO_MAINTENANCE environment variable.
+func newMaintenance(config MaintenanceConfig) *maintenance {
+	m := &maintenance{
+		enabled:    config.Enabled,
+		allow:      parseAllowlist(config.Allow),
+		paths:      config.Paths,
+		retryAfter: config.RetryAfter,
+		file:       orDefault(config.File, DefaultMaintenanceFile),
+	}
+	if m.paths == nil {
+		m.paths = []string{"/health", "/healthz"}
+	}
+	if m.retryAfter <= 0 {
+		m.retryAfter = time.Hour
+	}
+	switch strings.ToLower(os.Getenv("NEXO_MAINTENANCE")) {
+	case "on", "true", "1":
+		m.enabled = true
+	}
+	return m
+}
+
+// SetMaintenance turns maintenance mode on or off. While it is on, every
+// request except those from allowlisted IPs or CIDRs gets a 503 with the
+// maintenance page, before the proxy and routing. The allowlist replaces
+// the configured one when given.
+//
+// Example:
+//
+//	app.SetMaintenance(true, "203.0.113.7", "10.0.0.0/8")
+func (a *App) SetMaintenance(enabled bool, allowlist ...string) {
+	m := a.maintenance
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enabled = enabled
+	if len(allowlist) > 0 {
+		m.allow = parseAllowlist(allowlist)
+	}
+}
+
+// SetMaintenancePage sets the page served during maintenance. The file-based
+// router registers the Maintenance component of app/maintenance.templ.
+func (a *App) SetMaintenancePage(page templ.Component) {
+	a.maintenance.mu.Lock()
+	defer a.maintenance.mu.Unlock()
+	a.maintenance.page = page
+}
+
+// InMaintenance reports whether maintenance mode is on, with SetMaintenance,
+// the config or the maintenance file.
+func (a *App) InMaintenance() bool {
+	enabled, _, _, _ := a.maintenance.state()
+	return enabled
+}
+
+// state returns whether maintenance mode is on, the allowlist, the
+// Retry-After delay and the page, reading the maintenance file at most once
+// a second.
+func (m *maintenance) state() (bool, []*net.IPNet, time.Duration, templ.Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now := time.Now(); now.Sub(m.checked) >= maintenanceFileInterval {
+		m.checked = now
+		m.readFile()
+	}
+	if !m.enabled && !m.fileExists {
+		return false, nil, 0, nil
+	}
+
+	allow, retryAfter := m.allow, m.retryAfter
+	if m.fileExists {
+		allow = append(append([]*net.IPNet(nil), allow...), m.fileAllow...)
+		if m.fileState.RetryAfter > 0 {
+			retryAfter = time.Duration(m.fileState.RetryAfter) * time.Second
+		}
+	}
+	return true, allow, retryAfter, m.page
+}
+
+// readFile loads the maintenance file. A file that can't be parsed still
+// turns maintenance mode on, so a bad edit fails closed.
+func (m *maintenance) readFile() {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("nexo: maintenance file: %v", err)
+		}
+		m.fileExists, m.fileState, m.fileAllow = false, nil, nil
+		return
+	}
+	state := &MaintenanceState{}
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			log.Printf("nexo: maintenance file %s: %v", m.file, err)
+		}
+	}
+	m.fileExists, m.fileState, m.fileAllow = true, state, parseAllowlist(state.Allow)
+}
+
+// serve writes the maintenance response and returns true when maintenance
+// mode is on and the request isn't allowed through.
+func (m *maintenance) serve(w http.ResponseWriter, r *http.Request, trust *proxyTrust) bool {
+	enabled, allow, retryAfter, page := m.state()
+	if !enabled {
+		return false
+	}
+	for _, prefix := range m.paths {
+		if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, strings.TrimSuffix(prefix, "/")+"/") {
+			return false
+		}
+	}
+
+	c := acquireContext(w, r)
+	c.proxyTrust = trust
+	defer releaseContext(c)
+	if ip := net.ParseIP(stripPort(c.ClientIP())); ip != nil {
+		for _, n := range allow {
+			if n.Contains(ip) {
+				return false
+			}
+		}
+	}
+
+	c.SetHeader("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	c.SetHeader("Cache-Control", "no-store")
+	const status = http.StatusServiceUnavailable
+	switch {
+	case strings.Contains(c.Header("Accept"), "application/json") && !strings.Contains(c.Header("Accept"), "text/html"):
+		_ = c.JSON(status, map[string]any{
+			"error": map[string]any{"code": status, "message": "Service is under maintenance. Please try again later."},
+		})
+	case page != nil:
+		_ = c.Render(status, page)
+	default:
+		_ = c.HTML(status, defaultMaintenancePage)
+	}
+	return true
+}
+
+// parseAllowlist parses IPs and CIDRs, skipping invalid entries.
+func parseAllowlist(entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		n, err := parseIPNet(entry)
+		if err != nil {
+			log.Printf("nexo: maintenance allowlist: %v", err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// defaultMaintenancePage is served without a maintenance.templ.
+const defaultMaintenancePage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Down for maintenance</title>
+	<style>
+		body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; color: #333; display: grid; place-items: center; min-height: 100vh; margin: 0; }
+		main { text-align: center; padding: 2rem; }
+	</style>
+</head>
+<body>
+	<main>
+		<h1>Down for maintenance</h1>
+		<p>We're performing scheduled maintenance. Please check back soon.</p>
+	</main>
+</body>
+</html>
+`
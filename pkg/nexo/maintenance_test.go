@@ -0,0 +1,132 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+func newMaintenanceApp(t *testing.T, config MaintenanceConfig) *App {
+	t.Helper()
+	t.Setenv("NEXO_MAINTENANCE", "")
+	if config.File == "" {
+		config.File = filepath.Join(t.TempDir(), "maintenance.json")
+	}
+	cfg := DefaultConfig()
+	cfg.Maintenance = config
+	app := New(WithConfig(cfg), WithLogger(false))
+	app.Get("/", func(c *Context) error { return c.String(http.StatusOK, "home") })
+	app.Get("/health", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	app.Mount()
+	return app
+}
+
+func TestSetMaintenance(t *testing.T) {
+	app := newMaintenanceApp(t, MaintenanceConfig{RetryAfter: 30 * time.Minute})
+	app.SetMaintenance(true, "203.0.113.7", "10.0.0.0/8", "not-an-ip")
+
+	tests := []struct {
+		name       string
+		path       string
+		ip         string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "blocked", path: "/", ip: "198.51.100.1", wantStatus: http.StatusServiceUnavailable, wantBody: "Down for maintenance"},
+		{name: "blocked JSON", path: "/", ip: "198.51.100.1", accept: "application/json", wantStatus: http.StatusServiceUnavailable, wantBody: `"code":503`},
+		{name: "allowed IP", path: "/", ip: "203.0.113.7", wantStatus: http.StatusOK, wantBody: "home"},
+		{name: "allowed CIDR", path: "/", ip: "10.1.2.3", wantStatus: http.StatusOK, wantBody: "home"},
+		{name: "health check", path: "/health", ip: "198.51.100.1", wantStatus: http.StatusOK, wantBody: "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Forwarded-For", tt.ip)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("got %d %q, want %d containing %q", w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "1800" {
+				t.Errorf("Retry-After = %q, want 1800", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+
+	app.SetMaintenance(false)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || app.InMaintenance() {
+		t.Errorf("status = %d after turning maintenance mode off", w.Code)
+	}
+}
+
+func TestSetMaintenancePage(t *testing.T) {
+	app := newMaintenanceApp(t, MaintenanceConfig{Enabled: true})
+	app.SetMaintenancePage(templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "<h1>Back soon</h1>")
+		return err
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "<h1>Back soon</h1>" {
+		t.Errorf("got %d %q, want the maintenance page", w.Code, w.Body.String())
+	}
+}
+
+func TestMaintenanceFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.json")
+	app := newMaintenanceApp(t, MaintenanceConfig{File: file})
+
+	get := func() *httptest.ResponseRecorder {
+		app.maintenance.checked = time.Time{} // don't wait for the next check
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.10:4321"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK {
+		t.Fatalf("status = %d without a maintenance file", w.Code)
+	}
+	if err := os.WriteFile(file, []byte(`{"retry_after": 60}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("got %d, Retry-After %q with a maintenance file", w.Code, w.Header().Get("Retry-After"))
+	}
+	if err := os.WriteFile(file, []byte(`{"allow": ["192.0.2.0/24"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(); w.Code != http.StatusOK {
+		t.Errorf("status = %d for an IP allowed by the file", w.Code)
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(); w.Code != http.StatusOK || app.InMaintenance() {
+		t.Errorf("status = %d after removing the maintenance file", w.Code)
+	}
+}
+
+func TestMaintenanceEnv(t *testing.T) {
+	t.Setenv("NEXO_MAINTENANCE", "on")
+	if m := newMaintenance(MaintenanceConfig{}); !m.enabled {
+		t.Error("NEXO_MAINTENANCE=on doesn't turn maintenance mode on")
+	}
+}
@@ -0,0 +1,231 @@
+package nexo
+
+import (
+	"context"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Metadata describes a page for the document <head>: its title,
+// description and Open Graph tags. Pages and layouts declare it at package
+// level, and nested segments are merged from the root layout down to the page:
+//
+//	// app/layout.templ
+//	var Metadata = nexo.Metadata{
+//	    TitleTemplate: "%s | Acme",
+//	    Description:   "Acme makes everything.",
+//	}
+//
+//	// app/about/page.templ
+//	var Metadata = nexo.Metadata{Title: "About"} // rendered as "About | Acme"
+//
+// For metadata that depends on the request, declare
+//
+//	func GenerateMetadata(c *nexo.Context) (nexo.Metadata, error)
+//
+// instead. Layouts read the result with GetMetadata or render it with
+// MetadataTags.
+type Metadata struct {
+	// Title is the page title. The nearest ancestor's TitleTemplate is
+	// applied to it.
+	Title string
+
+	// TitleTemplate formats the titles of descendant segments; "%s" is
+	// replaced by the title.
+	TitleTemplate string
+
+	Description string
+	Keywords    []string
+	Canonical   string
+	Robots      string
+
+	OpenGraph OpenGraph
+}
+
+// OpenGraph holds the og:* tags of a page. Title and Description default to
+// the page's own.
+type OpenGraph struct {
+	Title       string
+	Description string
+	Type        string
+	URL         string
+	SiteName    string
+	Images      []string
+}
+
+// Merge returns m overridden by the non-empty fields of child, as when a
+// page's metadata is applied over its layout's.
+func (m Metadata) Merge(child Metadata) Metadata {
+	if child.Title != "" {
+		m.Title = child.Title
+		if m.TitleTemplate != "" {
+			m.Title = strings.Replace(m.TitleTemplate, "%s", child.Title, 1)
+		}
+	}
+	if child.TitleTemplate != "" {
+		m.TitleTemplate = child.TitleTemplate
+	}
+	m.Description = orDefault(child.Description, m.Description)
+	if len(child.Keywords) > 0 {
+		m.Keywords = child.Keywords
+	}
+	m.Canonical = orDefault(child.Canonical, m.Canonical)
+	m.Robots = orDefault(child.Robots, m.Robots)
+
+	og := child.OpenGraph
+	m.OpenGraph.Title = orDefault(og.Title, m.OpenGraph.Title)
+	m.OpenGraph.Description = orDefault(og.Description, m.OpenGraph.Description)
+	m.OpenGraph.Type = orDefault(og.Type, m.OpenGraph.Type)
+	m.OpenGraph.URL = orDefault(og.URL, m.OpenGraph.URL)
+	m.OpenGraph.SiteName = orDefault(og.SiteName, m.OpenGraph.SiteName)
+	if len(og.Images) > 0 {
+		m.OpenGraph.Images = og.Images
+	}
+	return m
+}
+
+// orDefault returns s, or def when s is empty.
+func orDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
+
+// metadataKey is the context key for a rendered page's Metadata.
+type metadataKey struct{}
+
+// WithMetadata returns a copy of ctx carrying md.
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// GetMetadata returns the metadata of the page being rendered. Use it in
+// layouts:
+//
+//	<title>{ nexo.GetMetadata(ctx).Title }</title>
+func GetMetadata(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// MetadataTags renders the <title>, <meta> and <link> tags of the page being
+// rendered: its metadata and the tags added with c.Head. Place it in the
+// root layout's <head>:
+//
+//	<head>
+//	    @nexo.MetadataTags()
+//	</head>
+//
+// Layouts without it get the tags other than <title> injected before </head>.
+func MetadataTags() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		title := GetMetadata(ctx).Title
+		if h := headFromContext(ctx); h != nil && title == "" {
+			title = h.title
+		}
+
+		var b strings.Builder
+		if title != "" {
+			b.WriteString("<title>" + html.EscapeString(title) + "</title>")
+		}
+		renderHead(ctx, &b)
+
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+}
+
+// writeMeta writes a <meta> tag unless content is empty.
+func writeMeta(b *strings.Builder, attr, name, content string) {
+	if content == "" {
+		return
+	}
+	b.WriteString(`<meta ` + attr + `="` + name + `" content="` + html.EscapeString(content) + `">`)
+}
+
+// ---------- Nested Layouts ----------
+
+// LayoutSegment is one level of a page's layout tree: the layout and metadata
+// declared by a directory between the app root and the page.
+type LayoutSegment struct {
+	// Layout wraps everything below this segment (nil for none).
+	Layout LayoutFunc
+
+	// Metadata is the segment's static metadata (nil for none).
+	Metadata *Metadata
+
+	// GenerateMetadata computes the segment's metadata per request, after
+	// Metadata is applied (nil for none).
+	GenerateMetadata func(c *Context) (Metadata, error)
+
+	// Slots are the parallel route slots Layout renders with Slot, keyed
+	// by the name of their @slot directory (nil for none).
+	Slots Slots
+}
+
+// RenderPage renders page inside the layouts of segments, given from the
+// root down: the root layout wraps the section layout, which wraps the page.
+// The segments' metadata is merged in the same order, over the head defaults
+// of the app config and under a title set with c.Head, and made available to
+// the page and its layouts through GetMetadata. Generated route files call
+// it for pages that don't render a layout themselves.
+//
+// Example:
+//
+//	return nexo.RenderPage(c, 200, dashboard.Page(),
+//	    nexo.LayoutSegment{Layout: nexo.TemplLayout(app.Layout), Metadata: &app.Metadata},
+//	    nexo.LayoutSegment{Layout: nexo.TemplLayout(dashboard.Layout)},
+//	)
+func RenderPage(c *Context, status int, page templ.Component, segments ...LayoutSegment) error {
+	md := c.defaultMetadata()
+	if c.Request.Context().Value(metadataKey{}) != nil {
+		md = GetMetadata(c.Request.Context())
+	}
+	for _, seg := range segments {
+		if seg.Metadata != nil {
+			md = md.Merge(*seg.Metadata)
+		}
+		if seg.GenerateMetadata != nil {
+			generated, err := seg.GenerateMetadata(c)
+			if err != nil {
+				return err
+			}
+			md = md.Merge(generated)
+		}
+	}
+	if title := c.Head().Title(); title != "" {
+		md = md.Merge(Metadata{Title: title})
+	}
+	c.Request = c.Request.WithContext(WithMetadata(c.Request.Context(), md))
+
+	comp := page
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].Layout != nil {
+			comp = segments[i].Layout(md.Title, comp)
+			if len(segments[i].Slots) > 0 {
+				comp = withSlots(comp, segments[i].Slots)
+			}
+		}
+	}
+	return c.Render(status, comp)
+}
+
+// TemplLayout adapts a templ layout that takes the page title and renders
+// { children... }, such as templ Layout(title string), to a LayoutFunc.
+func TemplLayout(layout func(title string) templ.Component) LayoutFunc {
+	return func(title string, children templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			return layout(title).Render(templ.WithChildren(ctx, children), w)
+		})
+	}
+}
+
+// TemplLayoutNoTitle adapts a templ layout without parameters, such as
+// templ Layout(), to a LayoutFunc. It reads the title with GetMetadata.
+func TemplLayoutNoTitle(layout func() templ.Component) LayoutFunc {
+	return TemplLayout(func(string) templ.Component { return layout() })
+}
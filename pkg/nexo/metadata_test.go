@@ -0,0 +1,163 @@
+package nexo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestMetadata_Merge(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent Metadata
+		child  Metadata
+		want   Metadata
+	}{
+		{
+			name:   "child overrides",
+			parent: Metadata{Title: "Acme", Description: "Root"},
+			child:  Metadata{Title: "About", Keywords: []string{"acme"}},
+			want:   Metadata{Title: "About", Description: "Root", Keywords: []string{"acme"}},
+		},
+		{
+			name:   "title template",
+			parent: Metadata{Title: "Acme", TitleTemplate: "%s | Acme"},
+			child:  Metadata{Title: "About"},
+			want:   Metadata{Title: "About | Acme", TitleTemplate: "%s | Acme"},
+		},
+		{
+			name:   "child template applies below the child",
+			parent: Metadata{TitleTemplate: "%s | Acme"},
+			child:  Metadata{Title: "Docs", TitleTemplate: "%s | Docs"},
+			want:   Metadata{Title: "Docs | Acme", TitleTemplate: "%s | Docs"},
+		},
+		{
+			name:   "open graph merges by field",
+			parent: Metadata{OpenGraph: OpenGraph{SiteName: "Acme", Type: "website"}},
+			child:  Metadata{OpenGraph: OpenGraph{Type: "article", Images: []string{"/og.png"}}},
+			want:   Metadata{OpenGraph: OpenGraph{SiteName: "Acme", Type: "article", Images: []string{"/og.png"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parent.Merge(tt.child); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataTags(t *testing.T) {
+	ctx := WithMetadata(context.Background(), Metadata{
+		Title:       "About | Acme",
+		Description: `Say "hi"`,
+		Canonical:   "https://acme.test/about",
+		OpenGraph:   OpenGraph{Images: []string{"/og.png"}},
+	})
+
+	var b strings.Builder
+	if err := MetadataTags().Render(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<title>About | Acme</title>` +
+		`<meta name="description" content="Say &#34;hi&#34;">` +
+		`<link rel="canonical" href="https://acme.test/about">` +
+		`<meta property="og:title" content="About | Acme">` +
+		`<meta property="og:description" content="Say &#34;hi&#34;">` +
+		`<meta property="og:image" content="/og.png">`
+	if got := b.String(); got != want {
+		t.Errorf("MetadataTags() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// testLayout wraps children in <name title="...">.
+func testLayout(name string) LayoutFunc {
+	return func(title string, children templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<"+name+` title="`+title+`" meta="`+GetMetadata(ctx).Description+`">`)
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</"+name+">")
+			return err
+		})
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	root := &Metadata{TitleTemplate: "%s | Acme", Description: "Root"}
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "<p>"+GetMetadata(ctx).Title+"</p>")
+		return err
+	})
+
+	t.Run("nested layouts", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+
+		err := RenderPage(c, http.StatusOK, page,
+			LayoutSegment{Layout: testLayout("root"), Metadata: root},
+			LayoutSegment{Layout: testLayout("section")},
+			LayoutSegment{GenerateMetadata: func(c *Context) (Metadata, error) {
+				return Metadata{Title: "Dashboard", Description: "Stats"}, nil
+			}},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `<root title="Dashboard | Acme" meta="Stats">` +
+			`<section title="Dashboard | Acme" meta="Stats">` +
+			`<p>Dashboard | Acme</p>` +
+			`</section></root>`
+		if got := w.Body.String(); got != want {
+			t.Errorf("body = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("generate metadata error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+
+		errNotFound := NotFound("no such post")
+		err := RenderPage(c, http.StatusOK, page, LayoutSegment{
+			GenerateMetadata: func(c *Context) (Metadata, error) { return Metadata{}, errNotFound },
+		})
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("err = %v, want %v", err, errNotFound)
+		}
+		if c.Written() {
+			t.Error("response written despite the error")
+		}
+	})
+}
+
+func TestTemplLayout(t *testing.T) {
+	layout := TemplLayout(func(title string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<main>"+title+":")
+			if err := templ.GetChildren(ctx).Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</main>")
+			return err
+		})
+	})
+
+	var b strings.Builder
+	if err := layout("Home", templ.Raw("<p>hi</p>")).Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "<main>Home:<p>hi</p></main>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package nexo
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -174,27 +175,39 @@ func LoggerWithConfig(config LoggerConfig) MiddlewareFunc {
 
 // ---------- Recover Middleware ----------
 
-// Recover returns a middleware that recovers from panics.
+// Recover returns a middleware that recovers from panics. In development
+// (see IsDevMode) it responds with a panic report; see RecoverConfig.
 func Recover() MiddlewareFunc {
-	return RecoverWithConfig(RecoverConfig{})
+	return RecoverWithConfig(RecoverConfig{
+		StackTrace:    IsDevMode(),
+		LogStackTrace: true,
+	})
 }
 
 // RecoverConfig holds configuration for the recover middleware.
 type RecoverConfig struct {
-	// StackTrace enables printing stack traces. Default is true in development.
+	// StackTrace responds to panics with a report instead of the JSON
+	// error: an HTML page for browsers with the panic, its stack trace split
+	// into app and framework frames, source around app frames and the
+	// request, or the panic and stack as JSON for other clients. Default is
+	// true in development. Never enable it in production.
 	StackTrace bool
 
 	// LogStackTrace logs the stack trace. Default is true.
 	LogStackTrace bool
 
+	// EditorURL links stack frames in the panic report to an editor, with
+	// {file} and {line} replaced. Default is "vscode://file/{file}:{line}".
+	EditorURL string
+
 	// ErrorHandler is a custom error handler for panics.
 	ErrorHandler func(c *Context, err any)
 }
 
 // RecoverWithConfig returns a recover middleware with custom configuration.
 func RecoverWithConfig(config RecoverConfig) MiddlewareFunc {
-	if config.ErrorHandler == nil {
-		config.ErrorHandler = defaultPanicHandler
+	if config.EditorURL == "" {
+		config.EditorURL = "vscode://file/{file}:{line}"
 	}
 
 	return func(next HandlerFunc) HandlerFunc {
@@ -205,7 +218,20 @@ func RecoverWithConfig(config RecoverConfig) MiddlewareFunc {
 						log.Printf("[PANIC] %v\n%s", r, debug.Stack())
 					}
 
-					config.ErrorHandler(c, r)
+					switch {
+					case config.ErrorHandler != nil:
+						config.ErrorHandler(c, r)
+					case config.StackTrace:
+						// A buffered response is replaced by the report
+						if c.Buffered() && c.buffer.discard() {
+							c.written = false
+						}
+						if !c.Written() {
+							writePanicReport(c, newPanicReport(c, r, panicStack(config.EditorURL)))
+						}
+					default:
+						defaultPanicHandler(c, r)
+					}
 					returnErr = NewHTTPError(http.StatusInternalServerError, "internal server error")
 				}
 			}()
@@ -335,6 +361,12 @@ func CORSWithConfig(config CORSConfig) MiddlewareFunc {
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
+			// The route's policy ran first (see App.SetRouteCORS)
+			if c.corsHandled {
+				return next(c)
+			}
+			c.corsHandled = true
+
 			origin := c.Header("Origin")
 
 			// Check if origin is allowed
@@ -369,7 +401,10 @@ func CORSWithConfig(config CORSConfig) MiddlewareFunc {
 
 // ---------- Timeout Middleware ----------
 
-// Timeout returns a middleware that sets a request timeout.
+// Timeout returns a middleware that sets a request timeout. The request
+// context (c.Context()) is canceled at the deadline, so database queries and
+// outbound calls made with it stop, and the client gets a 504 if the handler
+// hasn't responded by then.
 func Timeout(d time.Duration) MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
@@ -377,21 +412,29 @@ func Timeout(d time.Duration) MiddlewareFunc {
 				return next(c)
 			}
 
+			parent := c.Request
+			ctx, cancel := context.WithTimeout(parent.Context(), d)
+			defer cancel()
+			c.Request = parent.WithContext(ctx)
+
 			// Channel for handler result
 			done := make(chan error, 1)
 
-			// Timer for timeout
-			timer := time.NewTimer(d)
-			defer timer.Stop()
-
 			go func() {
 				done <- next(c)
 			}()
 
 			select {
 			case err := <-done:
+				c.Request = parent
 				return err
-			case <-timer.C:
+			case <-ctx.Done():
+				// The handler goroutine may still be using c
+				c.Retain()
+				if err := parent.Context().Err(); err != nil {
+					// The client went away or an outer deadline passed
+					return err
+				}
 				if !c.Written() {
 					return c.Error(http.StatusGatewayTimeout, "request timeout")
 				}
@@ -443,6 +486,24 @@ func BasicAuthWithConfig(config BasicAuthConfig) MiddlewareFunc {
 	}
 }
 
+// ---------- RequireAuth Middleware ----------
+
+// RequireAuth returns a middleware that rejects requests without an
+// authenticated principal with 401 Unauthorized. It relies on earlier
+// auth middleware calling Context.SetPrincipal; handlers opt in with
+//
+//	// nexo:route auth=required
+func RequireAuth() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Principal() == nil {
+				return Unauthorized("authentication required")
+			}
+			return next(c)
+		}
+	}
+}
+
 // ---------- Gzip Middleware ----------
 
 // Note: Gzip compression would require wrapping the response writer.
@@ -468,52 +529,157 @@ func Compress() MiddlewareFunc {
 	}
 }
 
-// ---------- RateLimiter Middleware (Simple) ----------
+// ---------- Buffer Middleware ----------
+
+// Buffer returns a middleware that buffers responses (see Context.Buffer),
+// so later middleware can set headers such as ETag or Content-Encoding after
+// the handler has written. Handlers can still stream by calling c.Flush.
+//
+// Example:
+//
+//	app.Use(nexo.Buffer())
+func Buffer() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Buffer()
+			return next(c)
+		}
+	}
+}
 
-// Note: This is a simple in-memory rate limiter.
-// For production, use a distributed rate limiter like Redis.
+// ---------- RateLimiter Middleware ----------
 
 // RateLimiterConfig holds configuration for rate limiting.
 type RateLimiterConfig struct {
 	// Max requests per window
 	Max int
 
-	// Window duration
+	// Window duration. A client's window starts with its first request.
 	Window time.Duration
+
+	// KeyFunc identifies the client. Default is the client IP.
+	KeyFunc func(c *Context) string
+
+	// Cache stores the request counters. Default is the app's cache (see
+	// WithCache), so with Redis the limit holds across all instances.
+	Cache Cache
+
+	// Prefix namespaces the counters. Default is "ratelimit:<max>/<window>:",
+	// so limiters with different limits don't share counters.
+	Prefix string
 }
 
-// RateLimiter returns a simple rate limiting middleware.
-// Note: This is per-process and not suitable for distributed systems.
+// RateLimiter returns a rate limiting middleware that allows max requests
+// per window per client IP.
 func RateLimiter(max int, window time.Duration) MiddlewareFunc {
-	requests := make(map[string][]time.Time)
+	return RateLimiterWithConfig(RateLimiterConfig{Max: max, Window: window})
+}
+
+// RateLimiterWithConfig returns a rate limiting middleware with custom
+// configuration. Requests over the limit get 429 Too Many Requests with a
+// Retry-After header. When the cache fails, requests are let through.
+func RateLimiterWithConfig(config RateLimiterConfig) MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *Context) string { return c.ClientIP() }
+	}
+	if config.Prefix == "" {
+		config.Prefix = fmt.Sprintf("ratelimit:%d/%s:", config.Max, config.Window)
+	}
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
-			ip := c.ClientIP()
-			now := time.Now()
-			windowStart := now.Add(-window)
-
-			// Clean old requests
-			var validRequests []time.Time
-			for _, t := range requests[ip] {
-				if t.After(windowStart) {
-					validRequests = append(validRequests, t)
-				}
-			}
-			requests[ip] = validRequests
+			return limitRate(c, next, config)
+		}
+	}
+}
 
-			// Check rate limit
-			if len(validRequests) >= max {
-				c.SetHeader("Retry-After", strconv.Itoa(int(window.Seconds())))
-				return c.Error(http.StatusTooManyRequests, "rate limit exceeded")
+// RateLimiterFromConfig returns a rate limiting middleware using the limit
+// name from rate_limits: in nexo.yaml. The limit is read on every request,
+// so a reloaded config changes it while the app runs (see
+// App.WatchConfig). Without the limit, requests are let through.
+//
+// Example:
+//
+//	# nexo.yaml
+//	rate_limits:
+//	  api: { max: 100, window: 1m }
+//
+//	app.Use(nexo.RateLimiterFromConfig("api"))
+func RateLimiterFromConfig(name string) MiddlewareFunc {
+	prefix := "ratelimit:" + name + ":"
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			limit, ok := c.Config().RateLimits[strings.ToLower(name)]
+			if !ok || limit.Max <= 0 || limit.Window <= 0 {
+				return next(c)
 			}
+			return limitRate(c, next, RateLimiterConfig{
+				Max:     limit.Max,
+				Window:  limit.Window,
+				KeyFunc: func(c *Context) string { return c.ClientIP() },
+				Prefix:  prefix,
+			})
+		}
+	}
+}
 
-			// Add current request
-			requests[ip] = append(requests[ip], now)
+// limitRate counts the request against config's limit and calls next
+// unless the limit is exceeded.
+func limitRate(c *Context, next HandlerFunc, config RateLimiterConfig) error {
+	cache := config.Cache
+	if cache == nil {
+		cache = c.Cache()
+	}
+	key := config.Prefix + config.KeyFunc(c)
 
-			return next(c)
+	count, err := incrCounter(c.Context(), cache, key, config.Window)
+	if err != nil {
+		log.Printf("nexo: rate limiter: %v", err)
+		return next(c)
+	}
+
+	remaining := max(config.Max-int(count), 0)
+	c.SetHeader("X-RateLimit-Limit", strconv.Itoa(config.Max))
+	c.SetHeader("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+	if count > int64(config.Max) {
+		retry := config.Window
+		if ttl, ok, err := cache.TTL(c.Context(), key); err == nil && ok && ttl > 0 {
+			retry = ttl
+		}
+		seconds := int((retry + time.Second - 1) / time.Second)
+		c.SetHeader("Retry-After", strconv.Itoa(seconds))
+		c.SetHeader("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(retry).Unix(), 10))
+		return c.Error(http.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	return next(c)
+}
+
+// incrCounter increments the counter at key, which expires ttl after its
+// first increment. Caches that aren't CacheCounters are updated with a
+// non-atomic read and write.
+func incrCounter(ctx context.Context, cache Cache, key string, ttl time.Duration) (int64, error) {
+	if counter, ok := cache.(CacheCounter); ok {
+		return counter.Incr(ctx, key, ttl)
+	}
+
+	data, ok, err := cache.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	n := int64(1)
+	if ok {
+		if n, err = strconv.ParseInt(string(data), 10, 64); err != nil {
+			return 0, fmt.Errorf("%s is not a counter", key)
+		}
+		n++
+		// Keep the window's expiry
+		if left, ok, err := cache.TTL(ctx, key); err == nil && ok && left > 0 {
+			ttl = left
 		}
 	}
+	return n, cache.Set(ctx, key, []byte(strconv.FormatInt(n, 10)), ttl)
 }
 
 // ---------- Secure Headers Middleware ----------
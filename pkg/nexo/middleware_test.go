@@ -2,6 +2,8 @@ package nexo
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -260,6 +262,56 @@ func TestTimeout_TimesOut(t *testing.T) {
 	}
 }
 
+func TestTimeout_CancelsContext(t *testing.T) {
+	ctxErr := make(chan error, 1)
+	handler := func(c *Context) error {
+		if _, ok := c.Deadline(); !ok {
+			t.Error("request context has no deadline")
+		}
+		<-c.Context().Done()
+		ctxErr <- c.Context().Err()
+		return c.Context().Err()
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	c := NewContext(w, req)
+
+	_ = Timeout(20 * time.Millisecond)(handler)(c)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", w.Code)
+	}
+	select {
+	case err := <-ctxErr:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("handler saw %v, want deadline exceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not canceled")
+	}
+}
+
+func TestTimeout_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	c := NewContext(w, req)
+
+	handler := func(c *Context) error {
+		cancel()
+		<-c.Context().Done()
+		time.Sleep(10 * time.Millisecond) // let Timeout see the cancellation first
+		return nil
+	}
+	err := Timeout(time.Minute)(handler)(c)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if c.Written() {
+		t.Errorf("wrote a %d response to a client that went away", w.Code)
+	}
+}
+
 func TestBasicAuth_Valid(t *testing.T) {
 	handler := func(c *Context) error {
 		username := c.Get("username")
@@ -289,6 +341,25 @@ func TestBasicAuth_Valid(t *testing.T) {
 	}
 }
 
+func TestRequireAuth(t *testing.T) {
+	handler := RequireAuth()(func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	var httpErr *HTTPError
+	if err := handler(c); !errors.As(err, &httpErr) || httpErr.Code != http.StatusUnauthorized {
+		t.Errorf("RequireAuth() without a principal error = %v, want 401", err)
+	}
+
+	w := httptest.NewRecorder()
+	c = NewContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	c.SetPrincipal("admin")
+	if err := handler(c); err != nil || w.Code != http.StatusOK {
+		t.Errorf("RequireAuth() with a principal = %d, %v; want 200", w.Code, err)
+	}
+}
+
 func TestBasicAuth_Invalid(t *testing.T) {
 	handler := func(c *Context) error {
 		t.Error("Handler should not be called for invalid auth")
@@ -0,0 +1,101 @@
+package nexo
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Mode is the environment an app runs in. It switches the defaults that
+// differ between development and production in one place: debug error
+// pages (Recover), re-reading content on every request (MarkdownPage),
+// the route inspector, log verbosity and caching of static files.
+type Mode string
+
+const (
+	// ModeDevelopment shows panic reports and the route inspector, re-reads
+	// content files on every request, logs at debug level and disables
+	// caching of static files.
+	ModeDevelopment Mode = "development"
+
+	// ModeTest behaves like production, but logs only warnings and errors
+	// and skips .env.local.
+	ModeTest Mode = "test"
+
+	// ModeProduction is the default.
+	ModeProduction Mode = "production"
+)
+
+// ParseMode returns the mode named s: "development" (or "dev"), "test" or
+// "production" (or "prod"). Any other name is production.
+func ParseMode(s string) Mode {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "development", "dev":
+		return ModeDevelopment
+	case "test", "testing":
+		return ModeTest
+	default:
+		return ModeProduction
+	}
+}
+
+// mode is the mode set with SetMode.
+var mode atomic.Pointer[Mode]
+
+// SetMode sets the mode of the process, overriding the environment. Apps
+// default to it, unless created with WithMode.
+func SetMode(m Mode) {
+	mode.Store(&m)
+}
+
+// CurrentMode returns the mode of the process set with SetMode, or else
+// the one in the environment: NEXO_ENV, then NEXO_DEV=true for development,
+// then GO_ENV. Without any, it is production.
+func CurrentMode() Mode {
+	if m := configuredMode(); m != "" {
+		return m
+	}
+	return ModeProduction
+}
+
+// configuredMode returns the mode set in code or the environment, or ""
+// when none is.
+func configuredMode() Mode {
+	if m := mode.Load(); m != nil {
+		return *m
+	}
+	if env := os.Getenv("NEXO_ENV"); env != "" {
+		return ParseMode(env)
+	}
+	if os.Getenv("NEXO_DEV") == "true" {
+		return ModeDevelopment
+	}
+	if env := os.Getenv("GO_ENV"); env != "" {
+		return ParseMode(env)
+	}
+	return ""
+}
+
+// IsDevMode reports whether the process runs in development mode (see
+// CurrentMode).
+func IsDevMode() bool {
+	return CurrentMode() == ModeDevelopment
+}
+
+// Mode returns the mode the app runs in: the one given to WithMode, or
+// else the process's (see CurrentMode).
+func (a *App) Mode() Mode {
+	if m := a.configuredMode(); m != "" {
+		return m
+	}
+	return ModeProduction
+}
+
+// configuredMode returns the app's mode, or "" when neither the app nor
+// the process has one set.
+func (a *App) configuredMode() Mode {
+	if a.mode != "" {
+		return a.mode
+	}
+	return configuredMode()
+}
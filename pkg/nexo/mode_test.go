@@ -0,0 +1,86 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCurrentMode(t *testing.T) {
+	tests := []struct {
+		name                string
+		nexoEnv, dev, goEnv string
+		want                Mode
+		wantConfigured      bool
+	}{
+		{"nothing set", "", "", "", ModeProduction, false},
+		{"NEXO_ENV", "development", "", "", ModeDevelopment, true},
+		{"NEXO_ENV short name", "dev", "", "", ModeDevelopment, true},
+		{"NEXO_ENV test", "test", "", "production", ModeTest, true},
+		{"NEXO_DEV", "", "true", "", ModeDevelopment, true},
+		{"NEXO_DEV beats GO_ENV", "", "true", "production", ModeDevelopment, true},
+		{"GO_ENV", "", "", "development", ModeDevelopment, true},
+		{"unknown mode", "staging", "", "", ModeProduction, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEXO_ENV", tt.nexoEnv)
+			t.Setenv("NEXO_DEV", tt.dev)
+			t.Setenv("GO_ENV", tt.goEnv)
+			if got := CurrentMode(); got != tt.want {
+				t.Errorf("CurrentMode() = %q, want %q", got, tt.want)
+			}
+			if got := configuredMode() != ""; got != tt.wantConfigured {
+				t.Errorf("configured = %v, want %v", got, tt.wantConfigured)
+			}
+		})
+	}
+}
+
+func TestWithMode(t *testing.T) {
+	t.Setenv("NEXO_ENV", "production")
+	t.Cleanup(func() { mode.Store(nil) })
+
+	app := New(WithMode(ModeDevelopment), WithEnvFiles(false))
+	if app.Mode() != ModeDevelopment {
+		t.Fatalf("Mode() = %q, want development", app.Mode())
+	}
+
+	// The mode is the app's: other apps and the process keep NEXO_ENV's
+	other := New(WithEnvFiles(false))
+	if other.Mode() != ModeProduction || CurrentMode() != ModeProduction {
+		t.Errorf("after WithMode(ModeDevelopment): other app = %q, process = %q, want production", other.Mode(), CurrentMode())
+	}
+	if other.logger.config.Level != LogLevelWarn {
+		t.Errorf("other app's logger level = %v, want warn", other.logger.config.Level)
+	}
+	if app.logger.config.Level != LogLevelDebug {
+		t.Errorf("logger level = %v, want debug", app.logger.config.Level)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.Static("/static", dir)
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app.css", nil))
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("static Cache-Control = %q, want no-cache in development", got)
+	}
+
+	SetMode(ModeTest)
+	if IsDevMode() || CurrentMode() != ModeTest || other.Mode() != ModeTest {
+		t.Errorf("CurrentMode() = %q, other app = %q after SetMode(ModeTest)", CurrentMode(), other.Mode())
+	}
+	if app.Mode() != ModeDevelopment {
+		t.Errorf("Mode() = %q after SetMode(ModeTest), want WithMode's development", app.Mode())
+	}
+	if level := DefaultRequestLoggerConfig().Level; level != LogLevelWarn {
+		t.Errorf("test mode logger level = %v, want warn", level)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -62,6 +65,9 @@ type OpenAPIGenerator struct {
 	appDir  string
 	config  OpenAPIConfig
 	scanner *Scanner
+
+	// operations are the typed handlers registered with Handle
+	operations []typedOperation
 }
 
 // ExtendedRouteInfo includes schema information extracted from handlers.
@@ -72,6 +78,35 @@ type ExtendedRouteInfo struct {
 	Tags        []string
 }
 
+// RouteDoc documents the handlers of a route directory in the OpenAPI
+// spec, keeping the docs next to them. A route.go returns it from a
+// Describe function, or from DescribeGet, DescribePost and so on for one
+// handler:
+//
+//	func Describe() nexo.RouteDoc {
+//	    return nexo.RouteDoc{
+//	        Summary:  "Create a user",
+//	        Tags:     []string{"users"},
+//	        Request:  CreateUserInput{},
+//	        Response: User{},
+//	    }
+//	}
+//
+// The scanner reads the literal without running the function, so fields
+// must be literals and Request and Response values of types declared in
+// the route package. Summary and Description win over the handler's doc
+// comment and Tags over the tag derived from the path.
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Request and Response are values of the request body and response
+	// types, like CreateUserInput{} or []User{}.
+	Request  any
+	Response any
+}
+
 // NewOpenAPIGenerator creates a new OpenAPI generator.
 func NewOpenAPIGenerator(appDir string, config OpenAPIConfig) *OpenAPIGenerator {
 	// Set defaults
@@ -148,7 +183,11 @@ func (g *OpenAPIGenerator) Generate() (*openapi3.T, error) {
 	// Build paths
 	for pattern, routesForPath := range pathRoutes {
 		pathItem := g.buildPathItem(routesForPath)
-		doc.Paths.Set(pattern, pathItem)
+		doc.Paths.Set(openAPIPath(pattern), pathItem)
+	}
+
+	if err := addTypedOperations(doc, g.operations); err != nil {
+		return nil, fmt.Errorf("failed to describe typed handlers: %w", err)
 	}
 
 	return doc, nil
@@ -193,6 +232,13 @@ func (g *OpenAPIGenerator) scanExtendedRouteInfo() ([]ExtendedRouteInfo, error)
 		ext.Summary = summary
 		ext.Description = description
 		ext.Tags = []string{g.deriveTag(route.FilePath)}
+		if doc := route.Doc; doc != nil {
+			ext.Summary = orDefault(doc.Summary, ext.Summary)
+			ext.Description = orDefault(doc.Description, ext.Description)
+			if len(doc.Tags) > 0 {
+				ext.Tags = doc.Tags
+			}
+		}
 
 		extended = append(extended, ext)
 	}
@@ -386,20 +432,127 @@ func (g *OpenAPIGenerator) buildOperation(route ExtendedRouteInfo) *openapi3.Ope
 
 	// Add request body for POST/PUT/PATCH
 	if route.Method == "POST" || route.Method == "PUT" || route.Method == "PATCH" {
+		schema := &openapi3.Schema{Type: &openapi3.Types{"object"}}
+		if route.Doc != nil && route.Doc.Request != "" {
+			schema = g.modelSchema(route.FilePath, route.Doc.Request)
+		}
 		op.RequestBody = &openapi3.RequestBodyRef{
 			Value: &openapi3.RequestBody{
 				Description: "Request body",
 				Required:    true,
-				Content: openapi3.NewContentWithJSONSchema(&openapi3.Schema{
-					Type: &openapi3.Types{"object"},
-				}),
+				Content:     openapi3.NewContentWithJSONSchema(schema),
 			},
 		}
 	}
 
+	if route.Doc != nil && route.Doc.Response != "" {
+		op.Responses.Value("200").Value.WithJSONSchema(g.modelSchema(route.FilePath, route.Doc.Response))
+	}
+
 	return op
 }
 
+// modelSchema returns the schema of typ, a request or response type of a
+// RouteDoc, as written in the route file at filePath. Structs declared in
+// the route package are described by their exported fields; other named
+// types are plain objects.
+func (g *OpenAPIGenerator) modelSchema(filePath, typ string) *openapi3.Schema {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return openapi3.NewObjectSchema()
+	}
+	structs := make(map[string]*ast.StructType)
+	fset := token.NewFileSet()
+	pkgs, _ := filepath.Glob(filepath.Join(filepath.Dir(filePath), "*.go"))
+	for _, path := range pkgs {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					structs[ts.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+	return exprSchema(expr, structs, 0)
+}
+
+// exprSchema returns the schema of the type expression expr. Structs are
+// inlined, up to a few levels deep.
+func exprSchema(expr ast.Expr, structs map[string]*ast.StructType, depth int) *openapi3.Schema {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return exprSchema(x.X, structs, depth)
+	case *ast.ArrayType:
+		if ident, ok := x.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return openapi3.NewBytesSchema()
+		}
+		return openapi3.NewArraySchema().WithItems(exprSchema(x.Elt, structs, depth))
+	case *ast.MapType:
+		return openapi3.NewObjectSchema().WithAdditionalProperties(exprSchema(x.Value, structs, depth))
+	case *ast.SelectorExpr:
+		if ident, ok := x.X.(*ast.Ident); ok && ident.Name == "time" && x.Sel.Name == "Time" {
+			return openapi3.NewDateTimeSchema()
+		}
+		if ident, ok := x.X.(*ast.Ident); ok && ident.Name == "uuid" && x.Sel.Name == "UUID" {
+			return openapi3.NewUUIDSchema()
+		}
+	case *ast.Ident:
+		switch x.Name {
+		case "string":
+			return openapi3.NewStringSchema()
+		case "bool":
+			return openapi3.NewBoolSchema()
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return openapi3.NewIntegerSchema()
+		case "float32", "float64":
+			return openapi3.NewFloat64Schema()
+		case "any":
+			return openapi3.NewSchema()
+		}
+		if st, ok := structs[x.Name]; ok && depth < 5 {
+			return structSchema(st, structs, depth+1)
+		}
+	}
+	return openapi3.NewObjectSchema()
+}
+
+// structSchema returns the schema of st by JSON field name. Fields with a
+// validate:"required" tag are required.
+func structSchema(st *ast.StructType, structs map[string]*ast.StructType, depth int) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+			if jsonName == "-" {
+				continue
+			}
+			jsonName = orDefault(jsonName, name.Name)
+			schema.WithProperty(jsonName, exprSchema(field.Type, structs, depth))
+			if slices.Contains(strings.Split(tag.Get("validate"), ","), "required") {
+				schema.Required = append(schema.Required, jsonName)
+			}
+		}
+	}
+	return schema
+}
+
 // buildParameters extracts path parameters from a pattern.
 // Example: /users/{id} -> [Parameter{name: "id", in: "path"}]
 func (g *OpenAPIGenerator) buildParameters(pattern string) openapi3.Parameters {
@@ -408,10 +561,7 @@ func (g *OpenAPIGenerator) buildParameters(pattern string) openapi3.Parameters {
 	// Find all {param} patterns
 	segments := strings.Split(pattern, "/")
 	for _, seg := range segments {
-		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
-			paramName := strings.TrimPrefix(seg, "{")
-			paramName = strings.TrimSuffix(paramName, "}")
-
+		if paramName, typ, ok := routeParam(seg); ok {
 			// Skip catch-all parameters (*)
 			if paramName == "*" || strings.Contains(paramName, "...") {
 				continue
@@ -423,9 +573,7 @@ func (g *OpenAPIGenerator) buildParameters(pattern string) openapi3.Parameters {
 				Required:    true,
 				Description: fmt.Sprintf("%s parameter", paramName),
 				Schema: &openapi3.SchemaRef{
-					Value: &openapi3.Schema{
-						Type: &openapi3.Types{"string"},
-					},
+					Value: paramSchema(typ),
 				},
 			}
 
@@ -436,6 +584,33 @@ func (g *OpenAPIGenerator) buildParameters(pattern string) openapi3.Parameters {
 	return params
 }
 
+// openAPIPath returns the OpenAPI path of a route pattern, without the
+// types of its parameters: /users/{id:int} is /users/{id}.
+func openAPIPath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if name, _, ok := routeParam(seg); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// paramSchema returns the schema of a path parameter of type typ: an
+// integer for {id:int}, a UUID for {id:uuid}, a pattern for chi's
+// {slug:[a-z-]+} and a plain string otherwise.
+func paramSchema(typ string) *openapi3.Schema {
+	switch typ {
+	case "":
+		return openapi3.NewStringSchema()
+	case "int":
+		return openapi3.NewIntegerSchema()
+	case "uuid":
+		return openapi3.NewUUIDSchema()
+	}
+	return openapi3.NewStringSchema().WithPattern("^" + typ + "$")
+}
+
 // WriteToFile writes the spec to a file.
 func (g *OpenAPIGenerator) WriteToFile(filepath, format string) error {
 	var data []byte
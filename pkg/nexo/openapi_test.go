@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -134,6 +136,115 @@ func Get(c *nexo.Context) error {
 	}
 }
 
+func TestOpenAPIGenerator_TypedParameters(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	for _, dir := range []string{"users/[id:int]", "orders/[id:uuid]", "tags/[slug:re([a-z]+)]"} {
+		if err := os.MkdirAll(filepath.Join(appDir, "api", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Get(c *nexo.Context) error { return nil }\n"
+		if err := os.WriteFile(filepath.Join(appDir, "api", dir, "route.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc, err := NewOpenAPIGenerator(appDir, OpenAPIConfig{Title: "Test API", Version: "1.0.0"}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schemas []string
+	for path, item := range doc.Paths.Map() {
+		if strings.Contains(path, ":") {
+			t.Errorf("path %s has a parameter type", path)
+		}
+		schema := item.Get.Parameters[0].Value.Schema.Value
+		switch {
+		case schema.Type.Is("integer"):
+			schemas = append(schemas, "int")
+		case schema.Format == "uuid":
+			schemas = append(schemas, "uuid")
+		case schema.Pattern == "^[a-z]+$":
+			schemas = append(schemas, "pattern")
+		}
+	}
+	slices.Sort(schemas)
+	if !slices.Equal(schemas, []string{"int", "pattern", "uuid"}) {
+		t.Errorf("parameter schemas = %v, want int, pattern and uuid", schemas)
+	}
+}
+
+func TestOpenAPIGenerator_RouteDoc(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app", "api", "users")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `package users
+
+import (
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+type CreateUserInput struct {
+	Name  string ` + "`json:\"name\" validate:\"required,max=100\"`" + `
+	Email string ` + "`json:\"email,omitempty\"`" + `
+	admin bool
+}
+
+type User struct {
+	ID      int       ` + "`json:\"id\"`" + `
+	Name    string    ` + "`json:\"name\"`" + `
+	Created time.Time ` + "`json:\"created\"`" + `
+	Secret  string    ` + "`json:\"-\"`" + `
+}
+
+func Describe() nexo.RouteDoc {
+	return nexo.RouteDoc{Summary: "List users", Tags: []string{"people"}, Response: []User{}}
+}
+
+func DescribePost() nexo.RouteDoc {
+	return nexo.RouteDoc{Summary: "Create a user", Request: CreateUserInput{}, Response: &User{}}
+}
+
+// Get is documented by Describe instead.
+func Get(c *nexo.Context) error { return nil }
+
+func Post(c *nexo.Context) error { return nil }
+`
+	if err := os.WriteFile(filepath.Join(dir, "route.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := NewOpenAPIGenerator(filepath.Dir(filepath.Dir(dir)), OpenAPIConfig{Title: "Test API"}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := doc.Paths.Value("/api/users")
+	if item == nil || item.Get == nil || item.Post == nil {
+		t.Fatalf("paths = %v", doc.Paths.InMatchingOrder())
+	}
+	if item.Get.Summary != "List users" || !slices.Equal(item.Get.Tags, []string{"people"}) {
+		t.Errorf("GET summary = %q, tags = %v", item.Get.Summary, item.Get.Tags)
+	}
+	list := item.Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	if !list.Type.Is("array") || !list.Items.Value.Properties["created"].Value.Type.Is("string") {
+		t.Errorf("GET response schema = %+v", list)
+	}
+
+	if item.Post.Summary != "Create a user" || !slices.Equal(item.Post.Tags, []string{"users"}) {
+		t.Errorf("POST summary = %q, tags = %v", item.Post.Summary, item.Post.Tags)
+	}
+	input := item.Post.RequestBody.Value.Content.Get("application/json").Schema.Value
+	if len(input.Properties) != 2 || !slices.Equal(input.Required, []string{"name"}) {
+		t.Errorf("POST request schema properties = %v, required = %v", input.Properties, input.Required)
+	}
+	user := item.Post.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	if _, ok := user.Properties["Secret"]; ok || !user.Properties["id"].Value.Type.Is("integer") {
+		t.Errorf("POST response schema properties = %v", user.Properties)
+	}
+}
+
 func TestOpenAPIGenerator_MultipleMethods(t *testing.T) {
 	// Create temp app directory
 	tmpDir := t.TempDir()
@@ -1,5 +1,16 @@
 package nexo
 
+import (
+	"net/http"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+	"github.com/abdul-hamid-achik/nexo/pkg/events"
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+	"github.com/abdul-hamid-achik/nexo/pkg/markdown"
+	"github.com/abdul-hamid-achik/nexo/pkg/storage"
+)
+
 // Option is a functional option for configuring the App.
 type Option func(*App)
 
@@ -38,6 +49,39 @@ func WithAddress(addr string) Option {
 	}
 }
 
+// WithServerConfig sets the timeouts and limits of the HTTP server started
+// by Listen, replacing the server: section of nexo.yaml (see ServerConfig).
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithServerConfig(nexo.ServerConfig{
+//	    ReadHeaderTimeout: 2 * time.Second,
+//	    MaxHeaderBytes:    64 << 10,
+//	}))
+func WithServerConfig(config ServerConfig) Option {
+	return func(a *App) {
+		a.config.Server = config
+	}
+}
+
+// WithServer adds a hook that configures the http.Server started by Listen,
+// after ServerConfig is applied. Use it for settings without a config key,
+// like ConnState, BaseContext, ConnContext, ErrorLog or TLSConfig.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithServer(func(s *http.Server) {
+//	    s.BaseContext = func(net.Listener) context.Context { return appCtx }
+//	    s.ConnState = func(conn net.Conn, state http.ConnState) {
+//	        connections.WithLabelValues(state.String()).Inc()
+//	    }
+//	}))
+func WithServer(configure func(*http.Server)) Option {
+	return func(a *App) {
+		a.serverHooks = append(a.serverHooks, configure)
+	}
+}
+
 // WithAppDir sets the app directory.
 func WithAppDir(dir string) Option {
 	return func(a *App) {
@@ -88,3 +132,335 @@ func WithHotReload(enabled bool) Option {
 		a.config.Dev.HotReload = enabled
 	}
 }
+
+// WithMode sets the mode the app runs in, overriding NEXO_ENV (see Mode).
+// It applies to this app only: middleware created outside it, like
+// Recover, reads the process's mode (see SetMode).
+func WithMode(m Mode) Option {
+	return func(a *App) {
+		a.mode = m
+	}
+}
+
+// WithInspector enables or disables the route inspector served at /_nexo
+// in dev mode.
+func WithInspector(enabled bool) Option {
+	return func(a *App) {
+		a.config.Dev.Inspector = enabled
+	}
+}
+
+// WithDebug serves net/http/pprof and expvar under /_debug (see
+// DebugConfig). The endpoints expose internals of the running process, so
+// guard them in production with middleware like BasicAuth, or with
+// debug.username and debug.password in nexo.yaml.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithDebug(nexo.BasicAuth(func(user, pass string) bool {
+//	    return user == "ops" && pass == os.Getenv("DEBUG_PASSWORD")
+//	})))
+func WithDebug(middleware ...MiddlewareFunc) Option {
+	return func(a *App) {
+		a.config.Debug.Enabled = true
+		a.debugMiddleware = middleware
+	}
+}
+
+// WithAdmin serves the admin dashboard under /_admin (see AdminConfig): the
+// routes, request counts and latencies, recent errors, cache and circuit
+// breaker stats, and the panels added with AdminPanel. Like WithDebug, it
+// exposes internals of the app, so guard it with middleware or with
+// admin.username and admin.password in nexo.yaml; unguarded, it isn't
+// served in production.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithAdmin(nexo.BasicAuth(func(user, pass string) bool {
+//	    return user == "ops" && pass == os.Getenv("ADMIN_PASSWORD")
+//	})))
+func WithAdmin(middleware ...MiddlewareFunc) Option {
+	return func(a *App) {
+		a.config.Admin.Enabled = true
+		a.adminMiddleware = middleware
+	}
+}
+
+// WithAPIDocs serves interactive API docs under /docs (see APIDocsConfig):
+// Swagger UI or Stoplight Elements for the app's OpenAPI spec, with the
+// live route table. Set api_docs.modes in nexo.yaml to serve them only in
+// some modes, like development.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithAPIDocs())
+func WithAPIDocs(middleware ...MiddlewareFunc) Option {
+	return func(a *App) {
+		a.config.APIDocs.Enabled = true
+		a.apiDocsMiddleware = middleware
+	}
+}
+
+// WithHeaders adds header rules after those under headers: in nexo.yaml,
+// so they override them (see HeaderRule).
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithHeaders(nexo.HeaderRule{
+//	    Source:  "/api/*",
+//	    Headers: map[string]string{"Cache-Control": "no-store"},
+//	}))
+func WithHeaders(rules ...HeaderRule) Option {
+	return func(a *App) {
+		a.headerRules = append(a.headerRules, rules...)
+	}
+}
+
+// WithRedirects adds redirect rules after those under redirects: in
+// nexo.yaml (see RedirectRule).
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithRedirects(nexo.RedirectRule{
+//	    Source:      "/blog/{slug}",
+//	    Destination: "/posts/{slug}",
+//	    Permanent:   true,
+//	}))
+func WithRedirects(rules ...RedirectRule) Option {
+	return func(a *App) {
+		a.config.Redirects = append(a.config.Redirects, rules...)
+	}
+}
+
+// WithRewrites adds rewrite rules after those under rewrites: in
+// nexo.yaml (see RewriteRule).
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithRewrites(nexo.RewriteRule{
+//	    Source:      "/docs/*",
+//	    Destination: "/content/docs/*",
+//	}))
+func WithRewrites(rules ...RewriteRule) Option {
+	return func(a *App) {
+		a.config.Rewrites = append(a.config.Rewrites, rules...)
+	}
+}
+
+// WithBatch serves the batch endpoint at /api/_batch (see BatchConfig),
+// which runs an array of requests through the app and responds with their
+// statuses, headers and bodies. Each request shares the batch request's
+// headers, so the auth middleware of the routes it calls sees the same
+// Authorization header and cookies. The middleware run before the
+// endpoint itself.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithBatch(nexo.RateLimiter(10, time.Minute)))
+func WithBatch(middleware ...MiddlewareFunc) Option {
+	return func(a *App) {
+		a.config.Batch.Enabled = true
+		a.batchMiddleware = middleware
+	}
+}
+
+// WithFlags sets the feature flag store used by c.Flag and flags.Enabled in
+// templ components, replacing the one configured under flags: in nexo.yaml.
+// Use it to read flags from a remote service.
+//
+// Example:
+//
+//	store := flags.NewStore(30*time.Second,
+//	    flags.NewFileProvider("flags.json"),
+//	    flags.ProviderFunc(launchDarkly.Flags),
+//	)
+//	app := nexo.New(nexo.WithFlags(store))
+func WithFlags(store *flags.Store) Option {
+	return func(a *App) {
+		a.routeTree.flags = store
+	}
+}
+
+// WithFlagKey sets the function returning the key feature flags are
+// evaluated for, which targeted and percentage flags match against. The
+// default is the client IP and User-Agent.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithFlagKey(func(c *nexo.Context) string {
+//	    if user, ok := c.Principal().(*User); ok {
+//	        return "user:" + user.ID
+//	    }
+//	    return c.ClientIP()
+//	}))
+func WithFlagKey(key func(c *Context) string) Option {
+	return func(a *App) {
+		a.routeTree.flagKey = key
+	}
+}
+
+// WithEvents sets the event bus c.Emit sends events to, replacing the one
+// configured under events: in nexo.yaml. Use it for a bus with a durable
+// queue or dead-letter store.
+//
+// Example:
+//
+//	dead, _ := events.NewFileDeadLetters("data/dead-letters")
+//	bus := events.New(events.Config{DeadLetters: dead, Queue: jobQueue{}})
+//	app := nexo.New(nexo.WithEvents(bus))
+func WithEvents(bus *events.Bus) Option {
+	return func(a *App) {
+		a.routeTree.events = bus
+	}
+}
+
+// WithStorage sets the object storage returned by c.Storage, replacing the
+// one configured under storage: in nexo.yaml.
+//
+// Example:
+//
+//	store, _ := storage.NewS3(storage.S3Config{Bucket: "media", ...})
+//	app := nexo.New(nexo.WithStorage(store))
+func WithStorage(driver storage.Driver) Option {
+	return func(a *App) {
+		a.routeTree.storage = driver
+	}
+}
+
+// WithTenantResolver turns on multi-tenancy, resolving the tenant of each
+// request with resolver instead of the tenancy config's resolvers.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithTenantResolver(nexo.FirstTenant(
+//	    nexo.TenantFromSubdomain("example.com"),
+//	    nexo.TenantFromHeader("X-Tenant-ID"),
+//	)))
+func WithTenantResolver(resolver TenantResolver) Option {
+	return func(a *App) {
+		a.tenantResolver = resolver
+	}
+}
+
+// WithTenantLookup loads resolved tenants with lookup, e.g. from the
+// database, instead of the tenants listed in the tenancy config. Requests
+// naming a tenant lookup doesn't know have no tenant.
+func WithTenantLookup(lookup TenantLookup) Option {
+	return func(a *App) {
+		a.tenantLookup = lookup
+	}
+}
+
+// WithI18n sets the message catalogs used by c.T, c.Locale and i18n.T in
+// templ components.
+//
+// Example:
+//
+//	bundle := i18n.NewBundle("en")
+//	if err := bundle.LoadDir("locales"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	app := nexo.New(nexo.WithI18n(bundle))
+func WithI18n(bundle *i18n.Bundle) Option {
+	return func(a *App) {
+		a.routeTree.i18n = bundle
+	}
+}
+
+// WithLocaleRouting enables or disables locale-prefixed page routes
+// (/es/about) for the locales loaded with WithI18n. See App.LocalizeRoutes.
+func WithLocaleRouting(enabled bool) Option {
+	return func(a *App) {
+		a.localeRouting = enabled
+	}
+}
+
+// WithSecret sets the key that signs flash cookies (see Context.Flash). Use
+// the same secret on every instance of the app; without one a random key is
+// generated at startup.
+func WithSecret(secret []byte) Option {
+	return func(a *App) {
+		a.routeTree.secret = secret
+	}
+}
+
+// WithCache sets the cache backend shared by the response cache, the rate
+// limiter and c.Cache(), instead of the one configured under cache: in
+// nexo.yaml.
+//
+// Example:
+//
+//	cache, err := nexo.NewRedisCache(os.Getenv("REDIS_URL"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app := nexo.New(nexo.WithCache(cache))
+func WithCache(cache Cache) Option {
+	return func(a *App) {
+		a.routeTree.cache = cache
+	}
+}
+
+// WithJSONCodec sets the JSON codec used by c.JSON, c.Bind and SSE JSON
+// events. The default is StdJSONCodec.
+func WithJSONCodec(codec JSONCodec) Option {
+	return func(a *App) {
+		a.routeTree.jsonCodec = codec
+	}
+}
+
+// WithBodyLimit sets the largest request body RawBody, Bind and URL-encoded
+// form parsing will buffer; larger bodies are rejected with 413. The default
+// is DefaultBodyLimit, and a negative limit disables the check.
+func WithBodyLimit(n int64) Option {
+	return func(a *App) {
+		a.routeTree.bodyLimit = n
+	}
+}
+
+// WithSitemap serves /sitemap.xml listing the pages added to App.Sitemap,
+// prefixed with baseURL. An empty baseURL uses the request's host.
+func WithSitemap(baseURL string) Option {
+	return func(a *App) {
+		a.config.Sitemap.Enabled = true
+		a.config.Sitemap.BaseURL = baseURL
+	}
+}
+
+// WithRobots serves /robots.txt with rules, or allowing every crawler when
+// none are given. It links the sitemap when one is served.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithRobots(nexo.RobotsRule{
+//	    UserAgent: "*",
+//	    Disallow:  []string{"/admin"},
+//	}))
+func WithRobots(rules ...RobotsRule) Option {
+	return func(a *App) {
+		a.config.Robots.Enabled = true
+		a.config.Robots.Rules = rules
+	}
+}
+
+// WithAssetManifest sets the manifest resolving c.Asset and nexo.Asset,
+// instead of reading js.manifest from disk (e.g. one embedded in the binary).
+func WithAssetManifest(m *bundler.Manifest) Option {
+	return func(a *App) {
+		a.routeTree.assets = m
+	}
+}
+
+// WithMarkdown sets the pipeline that renders content/*.md pages, to replace
+// the converter or highlight code blocks.
+//
+// Example:
+//
+//	app := nexo.New(nexo.WithMarkdown(markdown.New(
+//	    markdown.WithHighlighter(chromaHighlighter),
+//	)))
+func WithMarkdown(p *markdown.Pipeline) Option {
+	return func(a *App) {
+		a.markdown = p
+	}
+}
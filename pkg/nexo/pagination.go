@@ -0,0 +1,270 @@
+package nexo
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Pagination defaults.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PaginationConfig configures PaginationWithConfig.
+type PaginationConfig struct {
+	// DefaultLimit is the page size when the request sets none
+	// (default: DefaultPageLimit).
+	DefaultLimit int
+
+	// MaxLimit caps the page size a request can ask for
+	// (default: MaxPageLimit).
+	MaxLimit int
+}
+
+// Pagination is the page a list request asks for, parsed from the page,
+// limit and cursor query parameters.
+type Pagination struct {
+	// Page is the 1-based page number.
+	Page int
+
+	// Limit is the page size.
+	Limit int
+
+	// Offset is the number of items before the page, for OFFSET clauses.
+	Offset int
+
+	// Cursor is the opaque position to continue after, for cursor-based
+	// lists. Empty for the first page.
+	Cursor string
+}
+
+// Pagination parses the page, limit and cursor query parameters with the
+// default limits. See PaginationWithConfig.
+//
+// Example:
+//
+//	func Get(c *nexo.Context) error {
+//	    p := c.Pagination()
+//	    users, total, err := db.ListUsers(c.Context(), p.Limit, p.Offset)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return nexo.SendPage(c, nexo.NewPage(users, p, total))
+//	}
+func (c *Context) Pagination() Pagination {
+	return c.PaginationWithConfig(PaginationConfig{})
+}
+
+// PaginationWithConfig parses the page, limit and cursor query parameters.
+// Missing or invalid values fall back to the first page and the default
+// limit, limits above config.MaxLimit are capped, and so are pages whose
+// offset wouldn't fit in an int.
+func (c *Context) PaginationWithConfig(config PaginationConfig) Pagination {
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = DefaultPageLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = MaxPageLimit
+	}
+	config.DefaultLimit = min(config.DefaultLimit, config.MaxLimit)
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", config.DefaultLimit)
+	if limit < 1 {
+		limit = config.DefaultLimit
+	}
+	limit = min(limit, config.MaxLimit)
+	// Capped so the offset doesn't overflow
+	page = min(page, math.MaxInt/limit)
+	return Pagination{
+		Page:   page,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		Cursor: c.Query("cursor"),
+	}
+}
+
+// Page is the response envelope of a list endpoint. Build it with NewPage
+// or NewCursorPage, and send it with SendPage.
+type Page[T any] struct {
+	// Items are the page's items, never null in JSON.
+	Items []T `json:"items"`
+
+	// Page is the 1-based page number (offset pages only).
+	Page int `json:"page,omitempty"`
+
+	// Limit is the page size.
+	Limit int `json:"limit"`
+
+	// Total is the number of items in the whole list (offset pages only).
+	Total int `json:"total,omitempty"`
+
+	// TotalPages is the number of pages (offset pages only).
+	TotalPages int `json:"total_pages,omitempty"`
+
+	// NextCursor continues the list after this page (cursor pages only).
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// HasMore reports whether a next page exists.
+	HasMore bool `json:"has_more"`
+}
+
+// NewPage returns the page p of a list of total items.
+func NewPage[T any](items []T, p Pagination, total int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	pages := 0
+	if p.Limit > 0 {
+		pages = (total + p.Limit - 1) / p.Limit
+	}
+	return Page[T]{
+		Items:      items,
+		Page:       p.Page,
+		Limit:      p.Limit,
+		Total:      total,
+		TotalPages: pages,
+		HasMore:    p.Page < pages,
+	}
+}
+
+// NewCursorPage returns a page of a cursor-based list. next is the cursor
+// of the following page, or empty on the last page.
+func NewCursorPage[T any](items []T, p Pagination, next string) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items:      items,
+		Limit:      p.Limit,
+		NextCursor: next,
+		HasMore:    next != "",
+	}
+}
+
+// SendPage responds with page as JSON, with a Link header (RFC 8288,
+// formerly RFC 5988) pointing at the first, previous, next and last pages,
+// and an X-Total-Count header for offset pages.
+func SendPage[T any](c *Context, page Page[T]) error {
+	if links := c.pageLinks(page.Page, page.Limit, page.TotalPages, page.NextCursor); links != "" {
+		c.SetHeader("Link", links)
+	}
+	if page.Page > 0 {
+		c.SetHeader("X-Total-Count", strconv.Itoa(page.Total))
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// pageLinks returns the Link header of a page. Offset pages (page > 0)
+// link to first, prev, next and last; cursor pages link to next.
+func (c *Context) pageLinks(page, limit, pages int, next string) string {
+	var links []string
+	link := func(rel string, set map[string]string) {
+		query := c.Request.URL.Query()
+		query.Del("page")
+		query.Del("cursor")
+		query.Set("limit", strconv.Itoa(limit))
+		for k, v := range set {
+			query.Set(k, v)
+		}
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, "<"+u.String()+`>; rel="`+rel+`"`)
+	}
+	pageLink := func(rel string, n int) {
+		link(rel, map[string]string{"page": strconv.Itoa(n)})
+	}
+
+	switch {
+	case page > 0:
+		last := max(pages, 1)
+		pageLink("first", 1)
+		if page > 1 {
+			pageLink("prev", min(page-1, last))
+		}
+		if page < pages {
+			pageLink("next", page+1)
+		}
+		pageLink("last", last)
+	case next != "":
+		link("next", map[string]string{"cursor": next})
+	}
+	return strings.Join(links, ", ")
+}
+
+// SortField is a field of a sort order.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Sort is a sort order, most significant field first.
+type Sort []SortField
+
+// SQL returns the sort order as the body of an ORDER BY clause, like
+// "created_at DESC, name ASC". Fields come from Context.Sort's allowlist,
+// so they are safe to interpolate.
+func (s Sort) SQL() string {
+	parts := make([]string, len(s))
+	for i, f := range s {
+		dir := "ASC"
+		if f.Desc {
+			dir = "DESC"
+		}
+		parts[i] = f.Field + " " + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Sort parses the sort query parameter, a comma-separated list of fields
+// where a leading "-" sorts descending, like sort=-created_at,name. Fields
+// outside allowed fail with 400. Without a sort parameter it returns nil,
+// so the handler picks its default order.
+func (c *Context) Sort(allowed ...string) (Sort, error) {
+	raw := c.Query("sort")
+	if raw == "" {
+		return nil, nil
+	}
+	var sort Sort
+	for part := range strings.SplitSeq(raw, ",") {
+		part = strings.TrimSpace(part)
+		field, desc := strings.CutPrefix(part, "-")
+		field = strings.TrimPrefix(field, "+")
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(allowed, field) {
+			return nil, BadRequest("cannot sort by " + strconv.Quote(field))
+		}
+		sort = append(sort, SortField{Field: field, Desc: desc})
+	}
+	return sort, nil
+}
+
+// Filters returns the filter[field]=value query parameters, like
+// filter[status]=active, keyed by field. Fields outside allowed fail with
+// 400.
+func (c *Context) Filters(allowed ...string) (map[string]string, error) {
+	filters := make(map[string]string)
+	for key, values := range c.queryValues() {
+		name, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		field, ok := strings.CutSuffix(name, "]")
+		if !ok || !slices.Contains(allowed, field) {
+			return nil, BadRequest("cannot filter by " + strconv.Quote(field))
+		}
+		if len(values) > 0 && values[0] != "" {
+			filters[field] = values[0]
+		}
+	}
+	return filters, nil
+}
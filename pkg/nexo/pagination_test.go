@@ -0,0 +1,178 @@
+package nexo
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestContext_Pagination(t *testing.T) {
+	tests := []struct {
+		query  string
+		config PaginationConfig
+		want   Pagination
+	}{
+		{"", PaginationConfig{}, Pagination{Page: 1, Limit: 20}},
+		{"page=3&limit=10", PaginationConfig{}, Pagination{Page: 3, Limit: 10, Offset: 20}},
+		{"page=0&limit=-5", PaginationConfig{}, Pagination{Page: 1, Limit: 20}},
+		{"page=abc&limit=xyz", PaginationConfig{}, Pagination{Page: 1, Limit: 20}},
+		{"limit=1000", PaginationConfig{}, Pagination{Page: 1, Limit: 100}},
+		{"limit=80", PaginationConfig{DefaultLimit: 10, MaxLimit: 50}, Pagination{Page: 1, Limit: 50}},
+		{"", PaginationConfig{DefaultLimit: 10, MaxLimit: 50}, Pagination{Page: 1, Limit: 10}},
+		{"cursor=abc", PaginationConfig{}, Pagination{Page: 1, Limit: 20, Cursor: "abc"}},
+		{"page=" + strconv.Itoa(math.MaxInt) + "&limit=10", PaginationConfig{}, Pagination{Page: math.MaxInt / 10, Limit: 10, Offset: (math.MaxInt/10 - 1) * 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/users?"+tt.query, nil))
+			if got := c.PaginationWithConfig(tt.config); got != tt.want {
+				t.Errorf("PaginationWithConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		page      func(c *Context) Page[string]
+		wantLinks string
+		wantTotal string
+	}{
+		{
+			name:   "middle page",
+			target: "/users?page=2&limit=2&q=a",
+			page: func(c *Context) Page[string] {
+				return NewPage([]string{"c", "d"}, c.Pagination(), 5)
+			},
+			wantLinks: `</users?limit=2&page=1&q=a>; rel="first", </users?limit=2&page=1&q=a>; rel="prev", ` +
+				`</users?limit=2&page=3&q=a>; rel="next", </users?limit=2&page=3&q=a>; rel="last"`,
+			wantTotal: "5",
+		},
+		{
+			name:   "only page",
+			target: "/users",
+			page: func(c *Context) Page[string] {
+				return NewPage[string](nil, c.Pagination(), 0)
+			},
+			wantLinks: `</users?limit=20&page=1>; rel="first", </users?limit=20&page=1>; rel="last"`,
+			wantTotal: "0",
+		},
+		{
+			name:   "cursor page",
+			target: "/events?cursor=abc",
+			page: func(c *Context) Page[string] {
+				return NewCursorPage([]string{"e"}, c.Pagination(), "def")
+			},
+			wantLinks: `</events?cursor=def&limit=20>; rel="next"`,
+		},
+		{
+			name:   "last cursor page",
+			target: "/events?cursor=def",
+			page: func(c *Context) Page[string] {
+				return NewCursorPage([]string{"f"}, c.Pagination(), "")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, httptest.NewRequest("GET", tt.target, nil))
+			page := tt.page(c)
+			if err := SendPage(c, page); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLinks {
+				t.Errorf("Link =\n%s\nwant\n%s", got, tt.wantLinks)
+			}
+			if got := w.Header().Get("X-Total-Count"); got != tt.wantTotal {
+				t.Errorf("X-Total-Count = %q, want %q", got, tt.wantTotal)
+			}
+			var body Page[string]
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body, page) {
+				t.Errorf("body = %+v, want %+v", body, page)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	page := NewPage([]int{1, 2}, Pagination{Page: 1, Limit: 2}, 3)
+	if page.TotalPages != 2 || !page.HasMore {
+		t.Errorf("NewPage() = %+v, want 2 pages with more", page)
+	}
+	page = NewPage([]int{3}, Pagination{Page: 2, Limit: 2}, 3)
+	if page.HasMore {
+		t.Errorf("last page HasMore = true")
+	}
+}
+
+func TestContext_Sort(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    Sort
+		wantSQL string
+		wantErr bool
+	}{
+		{"", nil, "", false},
+		{"sort=-created_at,name", Sort{{"created_at", true}, {"name", false}}, "created_at DESC, name ASC", false},
+		{"sort=name,,", Sort{{"name", false}}, "name ASC", false},
+		{"sort=password", nil, "", true},
+		{"sort=name%3Bdrop%20table%20users", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/users?"+tt.query, nil))
+			got, err := c.Sort("name", "created_at")
+			if tt.wantErr {
+				if e, ok := IsHTTPError(err); !ok || e.Code != http.StatusBadRequest {
+					t.Errorf("Sort() error = %v, want 400", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || got.SQL() != tt.wantSQL {
+				t.Errorf("Sort() = %v (%q), want %v (%q)", got, got.SQL(), tt.want, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestContext_Filters(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"filter[status]=active&filter[role]=admin&page=2", map[string]string{"status": "active", "role": "admin"}, false},
+		{"filter[status]=", map[string]string{}, false},
+		{"filter[password]=x", nil, true},
+		{"filter[status=x", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/users?"+tt.query, nil))
+			got, err := c.Filters("status", "role")
+			if tt.wantErr {
+				if e, ok := IsHTTPError(err); !ok || e.Code != http.StatusBadRequest {
+					t.Errorf("Filters() error = %v, want 400", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filters() = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package nexo
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// panicReport describes a recovered panic for the development error page.
+type panicReport struct {
+	Value   string       `json:"panic"`
+	Frames  []stackFrame `json:"stack"`
+	Method  string       `json:"-"`
+	URL     string       `json:"-"`
+	Pattern string       `json:"-"`
+	Params  [][2]string  `json:"-"`
+	Query   [][2]string  `json:"-"`
+	Headers [][2]string  `json:"-"`
+}
+
+// stackFrame is a frame of a panic's stack trace.
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+
+	// App is false for frames in the Go runtime, the standard library,
+	// chi and nexo itself.
+	App bool `json:"app"`
+
+	Link   template.URL `json:"-"` // Editor URL of File:Line
+	Source []sourceLine `json:"-"` // Lines around Line, for app frames
+}
+
+// sourceLine is a line of a source snippet.
+type sourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// frameworkPrefixes are the function name prefixes of frames that aren't
+// app code.
+var frameworkPrefixes = []string{
+	"runtime.",
+	"net/http.",
+	"github.com/go-chi/",
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo.",
+}
+
+// panicStack returns the stack of the panic being recovered, starting at
+// the frame that panicked. It must be called from the deferred function
+// that recovers.
+func panicStack(editorURL string) []stackFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []stackFrame
+	panicking := false
+	goroot := "" // GOROOT/src/, to tell standard library frames apart
+	for {
+		f, more := frames.Next()
+		if !panicking {
+			// Skip the recover machinery up to runtime.gopanic
+			if f.Function == "runtime.gopanic" {
+				panicking = true
+				goroot = f.File[:strings.LastIndex(f.File, "/runtime/")+1]
+			}
+		} else {
+			frame := stackFrame{Function: f.Function, File: f.File, Line: f.Line, App: true}
+			for _, prefix := range frameworkPrefixes {
+				if strings.HasPrefix(f.Function, prefix) {
+					frame.App = false
+					break
+				}
+			}
+			if goroot != "" && strings.HasPrefix(f.File, goroot) {
+				frame.App = false
+			}
+			if editorURL != "" {
+				frame.Link = template.URL(strings.NewReplacer("{file}", f.File, "{line}", fmt.Sprint(f.Line)).Replace(editorURL))
+			}
+			if frame.App {
+				frame.Source = sourceSnippet(f.File, f.Line, 5)
+			}
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// sourceSnippet returns the lines of file within context lines of line, or
+// nil when the file can't be read.
+func sourceSnippet(file string, line, context int) []sourceLine {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []sourceLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= line+context; n++ {
+		if n >= line-context {
+			lines = append(lines, sourceLine{Number: n, Text: scanner.Text(), Current: n == line})
+		}
+	}
+	return lines
+}
+
+// newPanicReport builds the report of the panic value recovered while
+// serving c, with its stack.
+func newPanicReport(c *Context, value any, stack []stackFrame) *panicReport {
+	r := c.Request
+	report := &panicReport{
+		Value:  fmt.Sprint(value),
+		Frames: stack,
+		Method: r.Method,
+		URL:    r.URL.String(),
+	}
+	if err, ok := value.(error); ok {
+		report.Value = err.Error()
+	}
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		report.Pattern = rctx.RoutePattern()
+		for i, key := range rctx.URLParams.Keys {
+			report.Params = append(report.Params, [2]string{key, rctx.URLParams.Values[i]})
+		}
+	}
+	for key, values := range r.URL.Query() {
+		report.Query = append(report.Query, [2]string{key, strings.Join(values, ", ")})
+	}
+	for key, values := range r.Header {
+		value := strings.Join(values, ", ")
+		switch key {
+		case "Authorization", "Cookie", "Proxy-Authorization":
+			value = "[redacted]"
+		}
+		report.Headers = append(report.Headers, [2]string{key, value})
+	}
+	for _, pairs := range [][][2]string{report.Query, report.Headers} {
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	}
+	return report
+}
+
+// AppFrames returns the frames in app code.
+func (r *panicReport) AppFrames() []stackFrame {
+	var frames []stackFrame
+	for _, f := range r.Frames {
+		if f.App {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// FrameworkFrames returns the frames outside app code.
+func (r *panicReport) FrameworkFrames() []stackFrame {
+	var frames []stackFrame
+	for _, f := range r.Frames {
+		if !f.App {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// Origin returns the frame the panic happened in: the innermost app frame,
+// or the innermost frame when no app code is on the stack.
+func (r *panicReport) Origin() *stackFrame {
+	if frames := r.AppFrames(); len(frames) > 0 {
+		return &frames[0]
+	}
+	if len(r.Frames) > 0 {
+		return &r.Frames[0]
+	}
+	return nil
+}
+
+// writePanicReport responds with the report as an HTML page to browsers
+// and as JSON to everything else.
+func writePanicReport(c *Context, report *panicReport) {
+	if !strings.Contains(c.Request.Header.Get("Accept"), "text/html") {
+		_ = c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": map[string]any{
+				"code":    http.StatusInternalServerError,
+				"message": "internal server error",
+				"panic":   report.Value,
+				"stack":   report.Frames,
+			},
+		})
+		return
+	}
+
+	var b strings.Builder
+	if err := panicPageTemplate.Execute(&b, report); err != nil {
+		_ = c.Error(http.StatusInternalServerError, "internal server error")
+		return
+	}
+	_ = c.HTML(http.StatusInternalServerError, b.String())
+}
+
+// panicPageTemplate renders a panicReport as the development error page.
+var panicPageTemplate = template.Must(template.New("panic").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>panic: {{.Value}}</title>
+<style>
+  body { font: 14px/1.5 system-ui, sans-serif; margin: 0; color: #1f2937; background: #f9fafb; }
+  header { background: #b91c1c; color: #fff; padding: 1.5rem 2rem; }
+  header h1 { font-size: 1.25rem; margin: 0 0 .25rem; white-space: pre-wrap; word-break: break-word; }
+  header p { margin: 0; opacity: .85; }
+  main { padding: 1rem 2rem 3rem; max-width: 72rem; }
+  h2 { font-size: 1rem; margin: 2rem 0 .5rem; }
+  code, pre, .mono { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 13px; }
+  a { color: inherit; }
+  .frame { background: #fff; border: 1px solid #e5e7eb; border-radius: 6px; margin: .5rem 0; }
+  .frame summary { padding: .5rem .75rem; cursor: pointer; }
+  .frame .fn { font-weight: 600; }
+  .frame .loc { color: #6b7280; }
+  pre { margin: 0; padding: .5rem 0; overflow-x: auto; border-top: 1px solid #e5e7eb; }
+  pre span { display: block; padding: 0 .75rem; }
+  pre span.current { background: #fee2e2; }
+  pre i { display: inline-block; width: 3rem; color: #9ca3af; font-style: normal; user-select: none; }
+  table { border-collapse: collapse; width: 100%; background: #fff; border: 1px solid #e5e7eb; }
+  th, td { text-align: left; padding: .35rem .75rem; border-bottom: 1px solid #e5e7eb; vertical-align: top; }
+  th { width: 14rem; font-weight: normal; color: #6b7280; }
+  .muted { color: #6b7280; }
+</style>
+</head>
+<body>
+<header>
+  <h1>panic: {{.Value}}</h1>
+  <p class="mono">{{.Method}} {{.URL}}{{with .Origin}} &middot; {{.File}}:{{.Line}}{{end}}</p>
+</header>
+<main>
+  <h2>Stack</h2>
+  {{range $i, $f := .AppFrames}}
+  <details class="frame"{{if eq $i 0}} open{{end}}>
+    <summary><span class="fn mono">{{$f.Function}}</span><br><span class="loc mono">{{if $f.Link}}<a href="{{$f.Link}}">{{$f.File}}:{{$f.Line}}</a>{{else}}{{$f.File}}:{{$f.Line}}{{end}}</span></summary>
+    {{with $f.Source}}<pre>{{range .}}<span{{if .Current}} class="current"{{end}}><i>{{.Number}}</i>{{.Text}}</span>{{end}}</pre>{{end}}
+  </details>
+  {{else}}
+  <p class="muted">No app code on the stack.</p>
+  {{end}}
+  {{with .FrameworkFrames}}
+  <details class="frame">
+    <summary class="muted">{{len .}} framework and runtime frames</summary>
+    <pre>{{range .}}<span>{{.Function}}</span><span class="muted"><i></i>{{if .Link}}<a href="{{.Link}}">{{.File}}:{{.Line}}</a>{{else}}{{.File}}:{{.Line}}{{end}}</span>{{end}}</pre>
+  </details>
+  {{end}}
+
+  <h2>Request</h2>
+  <table>
+    <tr><th>Method</th><td class="mono">{{.Method}}</td></tr>
+    <tr><th>URL</th><td class="mono">{{.URL}}</td></tr>
+    {{with .Pattern}}<tr><th>Route</th><td class="mono">{{.}}</td></tr>{{end}}
+  </table>
+  {{with .Params}}
+  <h2>Route Parameters</h2>
+  <table>{{range .}}<tr><th class="mono">{{index . 0}}</th><td class="mono">{{index . 1}}</td></tr>{{end}}</table>
+  {{end}}
+  {{with .Query}}
+  <h2>Query</h2>
+  <table>{{range .}}<tr><th class="mono">{{index . 0}}</th><td class="mono">{{index . 1}}</td></tr>{{end}}</table>
+  {{end}}
+  <h2>Headers</h2>
+  <table>{{range .Headers}}<tr><th class="mono">{{index . 0}}</th><td class="mono">{{index . 1}}</td></tr>{{end}}</table>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,97 @@
+package nexo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicApp(config RecoverConfig) *App {
+	app := New()
+	app.Use(RecoverWithConfig(config))
+	app.Get("/users/{id}", func(c *Context) error {
+		panic("boom")
+	})
+	app.Mount()
+	return app
+}
+
+func TestRecoverPanicReport_HTML(t *testing.T) {
+	app := panicApp(RecoverConfig{StackTrace: true, EditorURL: "idea://open?file={file}&line={line}"})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?tab=posts", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"panic: boom",
+		"panic_test.go",
+		"/users/{id}",
+		"tab",
+		`href="idea://open?file=`,
+		"framework and runtime frames",
+		"[redacted]",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(body, "secret-token") {
+		t.Error("report leaks the Authorization header")
+	}
+}
+
+func TestRecoverPanicReport_JSON(t *testing.T) {
+	app := panicApp(RecoverConfig{StackTrace: true})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	var body struct {
+		Error struct {
+			Panic string       `json:"panic"`
+			Stack []stackFrame `json:"stack"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON %q: %v", w.Body.String(), err)
+	}
+	if body.Error.Panic != "boom" || len(body.Error.Stack) == 0 {
+		t.Fatalf("body = %+v", body.Error)
+	}
+	if top := body.Error.Stack[0]; !strings.HasSuffix(top.File, "panic_test.go") {
+		t.Errorf("innermost frame = %+v, want the panicking handler", top)
+	}
+	for _, f := range body.Error.Stack {
+		if strings.HasPrefix(f.Function, "net/http.") && f.App {
+			t.Errorf("frame %s marked as app code", f.Function)
+		}
+	}
+}
+
+func TestRecoverPanicReport_Production(t *testing.T) {
+	app := panicApp(RecoverConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, "boom") || strings.Contains(body, "stack") {
+		t.Errorf("production response leaks the panic: %s", body)
+	}
+}
@@ -0,0 +1,143 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// ---------- Parallel Routes ----------
+
+// Slots maps parallel route slot names to the components rendered into
+// them, e.g. the page in app/dashboard/@team for the "team" slot of
+// app/dashboard/layout.templ.
+type Slots map[string]templ.Component
+
+// slotsKey is the context key for the parallel route slots of the layouts
+// being rendered.
+type slotsKey struct{}
+
+// Slot renders the parallel route slot name in a layout: the page in the
+// @name directory next to the layout that matches the current URL, or the
+// slot's default.templ. It renders nothing when the slot has no content.
+//
+//	templ Layout() {
+//	    <main>{ children... }</main>
+//	    <aside>@nexo.Slot("analytics")</aside>
+//	}
+func Slot(name string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		slot, ok := slotsFromContext(ctx)[name]
+		if !ok || slot == nil {
+			return nil
+		}
+		return slot.Render(ctx, w)
+	})
+}
+
+// HasSlot reports whether the parallel route slot name has content for the
+// current request, so a layout can leave out the markup around an empty
+// slot.
+func HasSlot(ctx context.Context, name string) bool {
+	slot, ok := slotsFromContext(ctx)[name]
+	return ok && slot != nil
+}
+
+// slotsFromContext returns the slots available to the layout being rendered.
+func slotsFromContext(ctx context.Context) Slots {
+	slots, _ := ctx.Value(slotsKey{}).(Slots)
+	return slots
+}
+
+// withSlots makes slots available to Slot while comp renders. Slots of an
+// outer layout stay available unless an inner one reuses their name.
+func withSlots(comp templ.Component, slots Slots) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		merged := make(Slots)
+		for name, slot := range slotsFromContext(ctx) {
+			merged[name] = slot
+		}
+		for name, slot := range slots {
+			merged[name] = slot
+		}
+		return comp.Render(context.WithValue(ctx, slotsKey{}, merged), w)
+	})
+}
+
+// ---------- Intercepting Routes ----------
+
+// InterceptRoute makes the GET route at pattern render handler instead of
+// its own page when it is requested by an HTMX partial request from a page
+// under from, e.g. to show /photos/{id} in a modal over /feed. Full page
+// loads, boosted links and history restores still get the route's own
+// page. Generated route files call it for intercepting directories like
+// app/feed/(..)photos/[id]. It reports whether a route matched.
+func (a *App) InterceptRoute(pattern, from string, handler HandlerFunc) bool {
+	return a.routeTree.Intercept(http.MethodGet, pattern, from, handler)
+}
+
+// Intercept wraps the handlers of the routes matching method and pattern so
+// HTMX partial requests made from a page under from are answered by
+// handler. An empty method matches every method. It reports whether any
+// route matched.
+func (rt *RouteTree) Intercept(method, pattern, from string, handler HandlerFunc) bool {
+	found := false
+	for _, route := range rt.routes {
+		if route.Pattern != pattern || (method != "" && route.Method != method) {
+			continue
+		}
+		next := route.Handler
+		route.Handler = func(c *Context) error {
+			c.Response.Header().Add("Vary", "HX-Request, HX-Current-URL")
+			if c.navigatingFrom(from) {
+				return handler(c)
+			}
+			return next(c)
+		}
+		found = true
+	}
+	return found
+}
+
+// navigatingFrom reports whether the request is an HTMX partial request
+// made from a page whose path is under the route pattern from.
+func (c *Context) navigatingFrom(from string) bool {
+	if !c.IsHTMXPartial() {
+		return false
+	}
+	current, err := url.Parse(c.HXCurrentURL())
+	if err != nil || c.HXCurrentURL() == "" {
+		return false
+	}
+	return patternCoversPath(from, current.Path)
+}
+
+// patternCoversPath reports whether path is the route pattern or below it.
+// {param} matches any one segment and * or {slug...} the rest of the path.
+func patternCoversPath(pattern, path string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if patternSegs[0] == "" {
+		return true
+	}
+	for i, seg := range patternSegs {
+		if _, ok := scanner.CatchAllName(seg); ok {
+			return true
+		}
+		if i >= len(pathSegs) || pathSegs[i] == "" {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			continue
+		}
+		if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package nexo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+// textComponent renders s.
+func textComponent(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+// slotLayout renders its children, then the slots it is given, noting
+// empty ones.
+func slotLayout(name string, slots ...string) LayoutFunc {
+	return func(title string, children templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<"+name+">")
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			for _, slot := range slots {
+				if !HasSlot(ctx, slot) {
+					_, _ = io.WriteString(w, "[no "+slot+"]")
+					continue
+				}
+				if err := Slot(slot).Render(ctx, w); err != nil {
+					return err
+				}
+			}
+			_, err := io.WriteString(w, "</"+name+">")
+			return err
+		})
+	}
+}
+
+func TestRenderPageSlots(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+
+	err := RenderPage(c, http.StatusOK, textComponent("<p>page</p>"),
+		LayoutSegment{
+			Layout: slotLayout("root", "modal"),
+			Slots:  Slots{"modal": textComponent("<dialog></dialog>")},
+		},
+		LayoutSegment{
+			Layout: slotLayout("dashboard", "team", "analytics", "missing"),
+			Slots: Slots{
+				"team":      textComponent("<team/>"),
+				"analytics": textComponent("<analytics/>"),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "<root><dashboard><p>page</p><team/><analytics/>[no missing]</dashboard><dialog></dialog></root>"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestInterceptRoute(t *testing.T) {
+	app := New()
+	app.Get("/photos/{id}", func(c *Context) error { return c.String(http.StatusOK, "page") })
+	if !app.InterceptRoute("/photos/{id}", "/feed", func(c *Context) error { return c.String(http.StatusOK, "modal") }) {
+		t.Fatal("InterceptRoute() found no route")
+	}
+	if app.InterceptRoute("/missing", "/feed", func(c *Context) error { return nil }) {
+		t.Error("InterceptRoute() matched a missing route")
+	}
+	app.Mount()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"full page load", nil, "page"},
+		{"partial from feed", map[string]string{"HX-Request": "true", "HX-Current-URL": "http://localhost/feed"}, "modal"},
+		{"partial from below feed", map[string]string{"HX-Request": "true", "HX-Current-URL": "http://localhost/feed/popular?page=2"}, "modal"},
+		{"partial from elsewhere", map[string]string{"HX-Request": "true", "HX-Current-URL": "http://localhost/feeds"}, "page"},
+		{"boosted from feed", map[string]string{"HX-Request": "true", "HX-Boosted": "true", "HX-Current-URL": "http://localhost/feed"}, "page"},
+		{"partial without current URL", map[string]string{"HX-Request": "true"}, "page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/photos/1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternCoversPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/", "/anything", true},
+		{"/feed", "/feed", true},
+		{"/feed", "/feed/", true},
+		{"/feed", "/feed/popular", true},
+		{"/feed", "/feeds", false},
+		{"/feed", "/", false},
+		{"/users/{id}", "/users/42/photos", true},
+		{"/users/{id}", "/users", false},
+		{"/docs/*", "/docs/a/b", true},
+		{"/docs/{slug...}", "/docs/a/b", true},
+	}
+	for _, tt := range tests {
+		if got := patternCoversPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("patternCoversPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package nexo
+
+import (
+	"go/ast"
+	"sort"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// ParsePriorityDirective returns the priority set by a "// nexo:priority N"
+// line in a doc comment, and whether one was found.
+func ParsePriorityDirective(doc *ast.CommentGroup) (int, bool) {
+	return scanner.PriorityDirective(doc)
+}
+
+// HandlerPriority returns the priority override for a handler in a route.go
+// file. A directive on the handler wins over one in the file's package doc
+// comment, which applies to every handler in the file:
+//
+//	// nexo:priority 80
+//	func GET(c *nexo.Context) error { ... }
+func HandlerPriority(file *ast.File, fn *ast.FuncDecl) (int, bool) {
+	return scanner.HandlerPriority(file, fn)
+}
+
+// SetPriority overrides the priority of the routes matching method and
+// pattern, e.g. to let a static route win over a catch-all. An empty method
+// matches every method. It reports whether any route matched.
+func (rt *RouteTree) SetPriority(method, pattern string, priority int) bool {
+	found := false
+	for _, route := range rt.routes {
+		if route.Pattern != pattern || (method != "" && route.Method != method) {
+			continue
+		}
+		route.Priority = priority
+		route.PriorityOverride = true
+		found = true
+	}
+	return found
+}
+
+// routeBefore reports whether a route with priority pi, pattern a and method
+// ma is ordered before one with pj, b and mb. Ties on priority go to the
+// longer (more specific) pattern, then to pattern and method order, so the
+// result never depends on discovery order.
+func routeBefore(pi int, a, ma string, pj int, b, mb string) bool {
+	if pi != pj {
+		return pi > pj
+	}
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	if a != b {
+		return a < b
+	}
+	return ma < mb
+}
+
+// SortRouteInfo sorts routes into the order they are matched in.
+func SortRouteInfo(routes []RouteInfo) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routeBefore(routes[i].Priority, routes[i].Pattern, routes[i].Method,
+			routes[j].Priority, routes[j].Pattern, routes[j].Method)
+	})
+}
@@ -0,0 +1,169 @@
+package nexo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePriorityDirective(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		want   int
+		wantOK bool
+	}{
+		{"spaced", []string{"// Get returns the changelog.", "// nexo:priority 80"}, 80, true},
+		{"directive style", []string{"//nexo:priority 120"}, 120, true},
+		{"negative", []string{"// nexo:priority -1"}, -1, true},
+		{"not a number", []string{"// nexo:priority high"}, 0, false},
+		{"prose mention", []string{"// see nexo:priority 80 in the docs"}, 0, false},
+		{"no comment", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc *ast.CommentGroup
+			if tt.lines != nil {
+				doc = &ast.CommentGroup{}
+				for _, l := range tt.lines {
+					doc.List = append(doc.List, &ast.Comment{Text: l})
+				}
+			}
+
+			got, ok := ParsePriorityDirective(doc)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParsePriorityDirective() = %d, %v; want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHandlerPriority(t *testing.T) {
+	src := `// nexo:priority 60
+package docs
+
+// nexo:priority 120
+func Get(c *nexo.Context) error { return nil }
+
+func Post(c *nexo.Context) error { return nil }
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "route.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"Get": 120, "Post": 60}
+	for _, decl := range file.Decls {
+		fn := decl.(*ast.FuncDecl)
+		got, ok := HandlerPriority(file, fn)
+		if !ok || got != want[fn.Name.Name] {
+			t.Errorf("HandlerPriority(%s) = %d, %v; want %d, true", fn.Name.Name, got, ok, want[fn.Name.Name])
+		}
+	}
+}
+
+func TestRouteTree_SetPriority(t *testing.T) {
+	tree := NewRouteTree()
+	tree.AddRoute(&Route{Pattern: "/docs/*", Method: http.MethodGet, Priority: CalculatePriority("/docs/*")})
+	tree.AddRoute(&Route{Pattern: "/docs/changelog", Method: http.MethodGet, Priority: 5})
+
+	if tree.SetPriority(http.MethodPost, "/docs/changelog", 200) {
+		t.Error("SetPriority() = true for an unregistered method")
+	}
+	if !tree.SetPriority(http.MethodGet, "/docs/changelog", 200) {
+		t.Fatal("SetPriority() = false for a registered route")
+	}
+
+	routes := tree.Routes()
+	if routes[0].Pattern != "/docs/changelog" || routes[0].Priority != 200 || !routes[0].PriorityOverride {
+		t.Errorf("first route = %s (priority %d, override %v); want /docs/changelog (200, true)",
+			routes[0].Pattern, routes[0].Priority, routes[0].PriorityOverride)
+	}
+}
+
+func TestRouteTree_Routes_Deterministic(t *testing.T) {
+	want := []string{"GET /a/b", "POST /a/b", "GET /a/c", "GET /b"}
+
+	// Same routes, different registration order
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}} {
+		all := []*Route{
+			{Pattern: "/a/b", Method: http.MethodGet, Priority: 100},
+			{Pattern: "/a/b", Method: http.MethodPost, Priority: 100},
+			{Pattern: "/a/c", Method: http.MethodGet, Priority: 100},
+			{Pattern: "/b", Method: http.MethodGet, Priority: 100},
+		}
+		tree := NewRouteTree()
+		for _, i := range order {
+			tree.AddRoute(all[i])
+		}
+
+		for i, r := range tree.Routes() {
+			if got := r.Method + " " + r.Pattern; got != want[i] {
+				t.Errorf("order %v: routes[%d] = %s, want %s", order, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestApp_RegisterRouteWithConfig_Priority(t *testing.T) {
+	app := New()
+	app.RegisterRouteWithConfig(http.MethodGet, "/docs/*", func(c *Context) error { return nil }, RouteConfig{Priority: 150})
+	app.RegisterRouteWithConfig(http.MethodGet, "/docs/{slug}", func(c *Context) error { return nil }, RouteConfig{})
+
+	routes := app.RouteTree().Routes()
+	if routes[0].Pattern != "/docs/*" || !routes[0].PriorityOverride {
+		t.Errorf("first route = %s (override %v), want /docs/* (true)", routes[0].Pattern, routes[0].PriorityOverride)
+	}
+	if routes[1].PriorityOverride {
+		t.Error("route without a configured priority marked as overridden")
+	}
+}
+
+func TestScanner_ScanRouteInfo_PriorityDirective(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	dir := filepath.Join(appDir, "docs", "changelog")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := `package changelog
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// nexo:priority 120
+func Get(c *nexo.Context) error { return nil }
+`
+	if err := os.WriteFile(filepath.Join(dir, "route.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := NewScanner(appDir).ScanRouteInfo()
+	if err != nil {
+		t.Fatalf("ScanRouteInfo() error = %v", err)
+	}
+	if len(routes) != 1 || routes[0].Priority != 120 || !routes[0].PriorityOverride {
+		t.Errorf("ScanRouteInfo() = %+v, want one route with priority 120 (override)", routes)
+	}
+}
+
+func TestSortRouteInfo(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Pattern: "/docs/*", Priority: 5},
+		{Method: "POST", Pattern: "/users", Priority: 100},
+		{Method: "GET", Pattern: "/users/{id}", Priority: 50},
+		{Method: "GET", Pattern: "/users", Priority: 100},
+		{Method: "GET", Pattern: "/docs/changelog", Priority: 120, PriorityOverride: true},
+	}
+	SortRouteInfo(routes)
+
+	want := []string{"GET /docs/changelog", "GET /users", "POST /users", "GET /users/{id}", "GET /docs/*"}
+	for i, r := range routes {
+		if got := r.Method + " " + r.Pattern; got != want[i] {
+			t.Errorf("routes[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+}
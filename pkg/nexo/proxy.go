@@ -1,7 +1,10 @@
 package nexo
 
 import (
+	"fmt"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -23,10 +26,12 @@ const (
 	proxyActionRewrite
 	// proxyActionResponse sends a response directly, bypassing routing.
 	proxyActionResponse
+	// proxyActionForward streams the request to an upstream origin.
+	proxyActionForward
 )
 
 // ProxyResult represents the result of a proxy function execution.
-// Use the helper functions Continue(), Redirect(), Rewrite(), and Response() to create results.
+// Use the helper functions Continue(), Redirect(), Rewrite(), Response(), and Forward() to create results.
 type ProxyResult struct {
 	action      proxyAction
 	url         string
@@ -156,6 +161,28 @@ func ResponseHTML(statusCode int, html string) *ProxyResult {
 	}
 }
 
+// Forward returns a ProxyResult that streams the request to another origin
+// and relays its response, bypassing routing. The request path and query are
+// appended to upstreamURL. Headers (including X-Forwarded-*), trailers,
+// streaming responses and WebSocket upgrades are passed through.
+// Headers added with WithHeader are set on the upstream request.
+//
+// Example:
+//
+//	func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) {
+//	    // Serve the legacy API from the old backend
+//	    if strings.HasPrefix(c.Path(), "/api/v1/") {
+//	        return nexo.Forward("https://legacy.example.com"), nil
+//	    }
+//	    return nexo.Continue(), nil
+//	}
+func Forward(upstreamURL string) *ProxyResult {
+	return &ProxyResult{
+		action: proxyActionForward,
+		url:    upstreamURL,
+	}
+}
+
 // WithHeader adds a header to a redirect or response result.
 //
 // Example:
@@ -195,6 +222,19 @@ func (pc *ProxyConfig) Compile() error {
 	return nil
 }
 
+// Patterns returns the regular expressions the matcher patterns compile
+// to, for matching paths outside the app, like at the edge.
+func (pc *ProxyConfig) Patterns() ([]string, error) {
+	if err := pc.Compile(); err != nil {
+		return nil, err
+	}
+	patterns := make([]string, len(pc.compiledMatchers))
+	for i, re := range pc.compiledMatchers {
+		patterns[i] = re.String()
+	}
+	return patterns, nil
+}
+
 // Matches returns true if the path matches any of the configured patterns.
 // If no matchers are configured, returns true (matches all paths).
 func (pc *ProxyConfig) Matches(path string) bool {
@@ -425,11 +465,60 @@ func executeProxy(c *Context, proxy ProxyFunc, config *ProxyConfig) ProxyExecuti
 			Action:           &ProxyAction{Type: "response", Target: ""},
 			StatusCode:       result.statusCode,
 		}
+
+	case proxyActionForward:
+		return forwardRequest(c, result)
 	}
 
 	return ProxyExecutionResult{ContinueToRouter: true}
 }
 
+// forwardRequest streams the request to the upstream in result and writes
+// the upstream response. Upstream failures produce a 502 Bad Gateway.
+func forwardRequest(c *Context, result *ProxyResult) ProxyExecutionResult {
+	action := &ProxyAction{Type: "forward", Target: result.url}
+
+	target, err := url.Parse(result.url)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return ProxyExecutionResult{
+			ContinueToRouter: false,
+			Action:           action,
+			Error:            fmt.Errorf("invalid forward URL %q", result.url),
+			StatusCode:       http.StatusInternalServerError,
+		}
+	}
+
+	var upstreamErr error
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			for key, values := range result.headers {
+				pr.Out.Header[key] = values
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			upstreamErr = err
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	rp.ServeHTTP(c.Response, c.Request)
+
+	status := 0
+	if sw, ok := c.Response.(interface{ Status() int }); ok {
+		status = sw.Status()
+	}
+	if upstreamErr != nil {
+		status = http.StatusBadGateway
+	}
+
+	return ProxyExecutionResult{
+		ContinueToRouter: false,
+		Action:           action,
+		StatusCode:       status,
+	}
+}
+
 // ProxyInfo holds information about a discovered proxy for CLI display.
 type ProxyInfo struct {
 	FilePath string
@@ -1,9 +1,14 @@
 package nexo
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 // ---------- ProxyResult Helper Tests ----------
@@ -753,3 +758,168 @@ func TestIsRegexSpecial(t *testing.T) {
 		})
 	}
 }
+
+// ---------- Forward Tests ----------
+
+func TestForward(t *testing.T) {
+	result := Forward("https://upstream.example.com")
+
+	if result.action != proxyActionForward {
+		t.Errorf("expected action proxyActionForward, got %v", result.action)
+	}
+	if result.url != "https://upstream.example.com" {
+		t.Errorf("expected url https://upstream.example.com, got %s", result.url)
+	}
+}
+
+func TestExecuteProxyForward(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("X-Upstream-Path", r.URL.RequestURI())
+		w.Header().Set("X-Upstream-Auth", r.Header.Get("X-Internal-Auth"))
+		w.Header().Set("X-Upstream-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, "from upstream")
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer upstream.Close()
+
+	app := New()
+	_ = app.SetProxy(func(c *Context) (*ProxyResult, error) {
+		return Forward(upstream.URL+"/base").WithHeader("X-Internal-Auth", "token"), nil
+	}, nil)
+	app.Mount()
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/users?page=2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", resp.StatusCode)
+	}
+	if string(body) != "from upstream" {
+		t.Errorf("expected upstream body, got %q", body)
+	}
+	if got := resp.Header.Get("X-Upstream-Path"); got != "/base/users?page=2" {
+		t.Errorf("expected upstream path /base/users?page=2, got %q", got)
+	}
+	if got := resp.Header.Get("X-Upstream-Auth"); got != "token" {
+		t.Errorf("expected header to be forwarded upstream, got %q", got)
+	}
+	if got := resp.Header.Get("X-Upstream-Forwarded-Host"); got == "" {
+		t.Error("expected X-Forwarded-Host to be set")
+	}
+	if got := resp.Trailer.Get("X-Checksum"); got != "abc" {
+		t.Errorf("expected trailer X-Checksum=abc, got %q", got)
+	}
+}
+
+func TestExecuteProxyForwardWebSocket(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "expected upgrade", http.StatusBadRequest)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = buf.Flush()
+
+		// Echo one line back
+		line, _ := buf.ReadString('\n')
+		_, _ = buf.WriteString("echo: " + line)
+		_ = buf.Flush()
+	}))
+	t.Cleanup(upstream.Close)
+
+	app := New()
+	_ = app.SetProxy(func(c *Context) (*ProxyResult, error) {
+		return Forward(upstream.URL), nil
+	}, nil)
+	app.Mount()
+
+	// The hijacked tunnel outlives server.Close, so wait for the app to
+	// finish serving it before the test ends.
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		app.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("proxy handler still running after the client closed the tunnel")
+		}
+	})
+
+	_, _ = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read upgrade response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+
+	_, _ = io.WriteString(conn, "hello\n")
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read echo: %v", err)
+	}
+	if line != "echo: hello\n" {
+		t.Errorf("expected echo through tunnel, got %q", line)
+	}
+}
+
+func TestExecuteProxyForwardErrors(t *testing.T) {
+	t.Run("invalid url", func(t *testing.T) {
+		ctx := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		result := executeProxy(ctx, func(c *Context) (*ProxyResult, error) {
+			return Forward("not a url"), nil
+		}, nil)
+
+		if result.Error == nil {
+			t.Error("expected error for invalid forward URL")
+		}
+		if result.ContinueToRouter {
+			t.Error("expected ContinueToRouter to be false")
+		}
+	})
+
+	t.Run("upstream unreachable", func(t *testing.T) {
+		upstream := httptest.NewServer(http.NotFoundHandler())
+		upstreamURL := upstream.URL
+		upstream.Close()
+
+		w := httptest.NewRecorder()
+		ctx := NewContext(w, httptest.NewRequest("GET", "/", nil))
+		result := executeProxy(ctx, func(c *Context) (*ProxyResult, error) {
+			return Forward(upstreamURL), nil
+		}, nil)
+
+		if result.StatusCode != http.StatusBadGateway || w.Code != http.StatusBadGateway {
+			t.Errorf("expected 502, got result=%d recorder=%d", result.StatusCode, w.Code)
+		}
+		if result.Action == nil || result.Action.Type != "forward" {
+			t.Errorf("expected forward action, got %+v", result.Action)
+		}
+	})
+}
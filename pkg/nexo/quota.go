@@ -0,0 +1,275 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- Quotas ----------
+
+// Quota is how many requests a principal (user, API key, ...) may make.
+// A zero limit is unlimited.
+type Quota struct {
+	PerMinute int `json:"per_minute" mapstructure:"per_minute"`
+	PerDay    int `json:"per_day" mapstructure:"per_day"`
+}
+
+// quotaWindow is a limit of a Quota over one window.
+type quotaWindow struct {
+	name   string
+	limit  int
+	window time.Duration
+}
+
+// windows returns the limited windows of q, shortest first.
+func (q Quota) windows() []quotaWindow {
+	var ws []quotaWindow
+	if q.PerMinute > 0 {
+		ws = append(ws, quotaWindow{"minute", q.PerMinute, time.Minute})
+	}
+	if q.PerDay > 0 {
+		ws = append(ws, quotaWindow{"day", q.PerDay, 24 * time.Hour})
+	}
+	return ws
+}
+
+// QuotaUsage is a principal's quota and the requests counted against it.
+type QuotaUsage struct {
+	Principal string `json:"principal"`
+	Quota     Quota  `json:"quota"`
+
+	// Custom is true when the quota was set with SetQuota rather than
+	// being the default.
+	Custom bool `json:"custom"`
+
+	// Minute and Day count the requests of the current windows. Each
+	// window starts with its first request.
+	Minute int64 `json:"minute"`
+	Day    int64 `json:"day"`
+}
+
+// Quotas holds the quotas of principals and counts their requests in a
+// cache, so with Redis the quotas and counts are shared by every instance
+// of the app. Quotas set at runtime outlive restarts as long as the cache
+// keeps them.
+type Quotas struct {
+	cache  Cache
+	def    Quota
+	prefix string
+}
+
+// NewQuotas returns quotas stored in cache, where principals without a
+// quota of their own get def.
+//
+// Example:
+//
+//	quotas := nexo.NewQuotas(app.Cache(), nexo.Quota{PerMinute: 60, PerDay: 10000})
+//	app.Use(nexo.Throttle(quotas))
+func NewQuotas(cache Cache, def Quota) *Quotas {
+	return &Quotas{cache: cache, def: def, prefix: "quota:"}
+}
+
+// Default returns the quota of principals without one of their own.
+func (q *Quotas) Default() Quota {
+	return q.def
+}
+
+// Quota returns the quota of principal, and whether it was set with
+// SetQuota.
+func (q *Quotas) Quota(ctx context.Context, principal string) (Quota, bool, error) {
+	quota, ok, err := CacheGet[Quota](ctx, q.cache, q.prefix+principal+":quota")
+	if err != nil || !ok {
+		return q.def, false, err
+	}
+	return quota, true, nil
+}
+
+// SetQuota gives principal a quota of its own, like a higher one for a
+// paying customer. It applies from the next request.
+func (q *Quotas) SetQuota(ctx context.Context, principal string, quota Quota) error {
+	return CacheSet(ctx, q.cache, q.prefix+principal+":quota", quota, 0)
+}
+
+// DeleteQuota puts principal back on the default quota.
+func (q *Quotas) DeleteQuota(ctx context.Context, principal string) error {
+	return q.cache.Delete(ctx, q.prefix+principal+":quota")
+}
+
+// Usage returns the quota of principal and the requests counted against
+// it.
+func (q *Quotas) Usage(ctx context.Context, principal string) (QuotaUsage, error) {
+	quota, custom, err := q.Quota(ctx, principal)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+	usage := QuotaUsage{Principal: principal, Quota: quota, Custom: custom}
+	if usage.Minute, err = q.count(ctx, principal, "minute"); err != nil {
+		return QuotaUsage{}, err
+	}
+	if usage.Day, err = q.count(ctx, principal, "day"); err != nil {
+		return QuotaUsage{}, err
+	}
+	return usage, nil
+}
+
+// ResetUsage clears the requests counted against principal's quota.
+func (q *Quotas) ResetUsage(ctx context.Context, principal string) error {
+	for _, name := range []string{"minute", "day"} {
+		if err := q.cache.Delete(ctx, q.counterKey(principal, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// counterKey returns the key of principal's counter for a window.
+func (q *Quotas) counterKey(principal, window string) string {
+	return q.prefix + principal + ":" + window
+}
+
+// count returns principal's counter for a window.
+func (q *Quotas) count(ctx context.Context, principal, window string) (int64, error) {
+	key := q.counterKey(principal, window)
+	data, ok, err := q.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a counter", key)
+	}
+	return n, nil
+}
+
+// Routes registers a JSON API to manage the quotas on g. Put it behind
+// authentication:
+//
+//	GET    /{principal}        usage and quota
+//	PUT    /{principal}        set the quota: {"per_minute": 600, "per_day": 0}
+//	DELETE /{principal}        back to the default quota
+//	DELETE /{principal}/usage  clear the counted requests
+//
+// Example:
+//
+//	app.Group("/_admin/quotas", func(g *nexo.RouteGroup) {
+//	    g.Use(requireAdmin)
+//	    quotas.Routes(g)
+//	})
+func (q *Quotas) Routes(g *RouteGroup) {
+	usage := func(c *Context) error {
+		u, err := q.Usage(c.Context(), c.Param("principal"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, u)
+	}
+	g.Get("/{principal}", usage)
+	g.Put("/{principal}", func(c *Context) error {
+		var quota Quota
+		if err := c.Bind(&quota); err != nil {
+			return err
+		}
+		if quota.PerMinute < 0 || quota.PerDay < 0 {
+			return c.Error(http.StatusBadRequest, "quota limits can't be negative")
+		}
+		if err := q.SetQuota(c.Context(), c.Param("principal"), quota); err != nil {
+			return err
+		}
+		return usage(c)
+	})
+	g.Delete("/{principal}", func(c *Context) error {
+		if err := q.DeleteQuota(c.Context(), c.Param("principal")); err != nil {
+			return err
+		}
+		return usage(c)
+	})
+	g.Delete("/{principal}/usage", func(c *Context) error {
+		if err := q.ResetUsage(c.Context(), c.Param("principal")); err != nil {
+			return err
+		}
+		return usage(c)
+	})
+}
+
+// ---------- Throttle Middleware ----------
+
+// ThrottleConfig holds configuration for the throttle middleware.
+type ThrottleConfig struct {
+	// Quotas holds the quotas and counters (required).
+	Quotas *Quotas
+
+	// KeyFunc identifies the principal. Default is the principal set with
+	// SetPrincipal when it is a string or fmt.Stringer, then the BasicAuth
+	// user. Requests without one aren't throttled; limit them by IP with
+	// RateLimiter.
+	KeyFunc func(c *Context) string
+}
+
+// Throttle returns a middleware that limits each authenticated principal
+// to its quota. Put it after the middleware that authenticates.
+func Throttle(quotas *Quotas) MiddlewareFunc {
+	return ThrottleWithConfig(ThrottleConfig{Quotas: quotas})
+}
+
+// ThrottleWithConfig returns a throttle middleware with custom
+// configuration. Requests over a quota get 429 Too Many Requests with a
+// Retry-After header, and every response gets X-RateLimit headers for the
+// window closest to its limit. When the cache fails, requests are let
+// through.
+func ThrottleWithConfig(config ThrottleConfig) MiddlewareFunc {
+	if config.Quotas == nil {
+		panic("nexo: Throttle requires Quotas")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultAuditActor
+	}
+	q := config.Quotas
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			principal := config.KeyFunc(c)
+			if principal == "" {
+				return next(c)
+			}
+			quota, _, err := q.Quota(c.Context(), principal)
+			if err != nil {
+				log.Printf("nexo: throttle: %v", err)
+				return next(c)
+			}
+
+			remaining := -1
+			for _, w := range quota.windows() {
+				key := q.counterKey(principal, w.name)
+				count, err := incrCounter(c.Context(), q.cache, key, w.window)
+				if err != nil {
+					log.Printf("nexo: throttle: %v", err)
+					return next(c)
+				}
+				left := max(w.limit-int(count), 0)
+				exceeded := count > int64(w.limit)
+				if remaining >= 0 && left >= remaining && !exceeded {
+					continue
+				}
+				remaining = left
+				c.SetHeader("X-RateLimit-Limit", strconv.Itoa(w.limit))
+				c.SetHeader("X-RateLimit-Remaining", strconv.Itoa(left))
+
+				if exceeded {
+					retry := w.window
+					if ttl, ok, err := q.cache.TTL(c.Context(), key); err == nil && ok && ttl > 0 {
+						retry = ttl
+					}
+					seconds := int((retry + time.Second - 1) / time.Second)
+					c.SetHeader("Retry-After", strconv.Itoa(seconds))
+					c.SetHeader("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(retry).Unix(), 10))
+					return c.Error(http.StatusTooManyRequests, "quota exceeded")
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package nexo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestThrottle(t *testing.T) {
+	quotas := NewQuotas(NewMemoryCache(0), Quota{PerMinute: 2, PerDay: 3})
+	ctx := context.Background()
+	if err := quotas.SetQuota(ctx, "pro", Quota{PerMinute: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := ThrottleWithConfig(ThrottleConfig{
+		Quotas:  quotas,
+		KeyFunc: func(c *Context) string { return c.Header("X-API-Key") },
+	})(func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		c := NewContext(w, req)
+		if err := handler(c); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	tests := []struct {
+		key       string
+		want      int
+		remaining string
+	}{
+		{"free", http.StatusOK, "1"},
+		{"free", http.StatusOK, "0"},
+		{"free", http.StatusTooManyRequests, "0"},
+		{"other", http.StatusOK, "1"},
+		{"pro", http.StatusOK, "4"},
+		{"", http.StatusOK, ""}, // anonymous
+	}
+	for i, tt := range tests {
+		w := do(tt.key)
+		if w.Code != tt.want {
+			t.Errorf("request %d (%s): status = %d, want %d", i, tt.key, w.Code, tt.want)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.remaining {
+			t.Errorf("request %d (%s): remaining = %q, want %q", i, tt.key, got, tt.remaining)
+		}
+	}
+	if w := do("free"); w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+
+	// The day's quota runs out once the minute's is reset
+	if err := quotas.ResetUsage(ctx, "other"); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := quotas.Usage(ctx, "free")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Minute != 4 || usage.Day != 2 || usage.Custom {
+		t.Errorf("Usage(free) = %+v", usage)
+	}
+	for range 3 {
+		do("other")
+		quotas.cache.Delete(ctx, quotas.counterKey("other", "minute"))
+	}
+	if w := do("other"); w.Code != http.StatusTooManyRequests || w.Header().Get("X-RateLimit-Limit") != "3" {
+		t.Errorf("over the day's quota: status = %d, limit = %q", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestQuotas_Routes(t *testing.T) {
+	quotas := NewQuotas(NewMemoryCache(0), Quota{PerMinute: 60})
+	app := New()
+	app.DisableLogger()
+	app.Group("/quotas", quotas.Routes)
+	app.Mount()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, "/quotas/acme", `{"per_minute": 600, "per_day": 100000}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"per_minute":600`) {
+		t.Fatalf("PUT: %d %s", w.Code, w.Body)
+	}
+	if q, custom, _ := quotas.Quota(context.Background(), "acme"); !custom || q.PerDay != 100000 {
+		t.Errorf("Quota(acme) = %+v, %v", q, custom)
+	}
+	if w := do(http.MethodPut, "/quotas/acme", `{"per_minute": -1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("negative quota: status = %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/quotas/acme", ""); !strings.Contains(w.Body.String(), `"custom":false`) {
+		t.Errorf("DELETE: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, "/quotas/acme", ""); !strings.Contains(w.Body.String(), `"per_minute":60`) {
+		t.Errorf("GET: %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,84 @@
+package nexo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DefaultBodyLimit is the largest request body RawBody buffers when the app
+// sets no limit with WithBodyLimit.
+const DefaultBodyLimit int64 = 10 << 20
+
+// ---------- Raw Body ----------
+
+// RawBody returns the request body, reading it on the first call. The bytes
+// are kept, and c.Request.Body is rewound to their start on every call, so
+// Bind, form parsing, signature checks and handlers reading c.Request.Body
+// directly all see the whole body, in any order. c.Request.GetBody returns
+// a fresh copy too, for requests that are forwarded or retried.
+//
+// At most the body limit (see WithBodyLimit) is buffered; a larger body is
+// rejected with a 413 HTTPError wrapping an *http.MaxBytesError.
+func (c *Context) RawBody() ([]byte, error) {
+	if c.rawBody == nil {
+		data, err := c.readBody()
+		if err != nil {
+			return nil, err
+		}
+		c.rawBody = data
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(c.rawBody))
+	c.Request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(c.rawBody)), nil
+	}
+	return c.rawBody, nil
+}
+
+// readBody reads and closes the request body, up to the body limit.
+func (c *Context) readBody() ([]byte, error) {
+	body := c.Request.Body
+	if body == nil || body == http.NoBody {
+		return []byte{}, nil
+	}
+	defer body.Close()
+
+	limit := c.bodyLimit
+	if limit == 0 {
+		limit = DefaultBodyLimit
+	}
+	if limit < 0 {
+		return io.ReadAll(body)
+	}
+	if c.Request.ContentLength > limit {
+		return nil, bodyTooLarge(limit)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, bodyTooLarge(limit)
+	}
+	return data, nil
+}
+
+// bodyReadError turns an error reading the body into a 400 with message,
+// keeping HTTPErrors such as the 413 of an oversized body.
+func bodyReadError(err error, message string) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+	return NewHTTPErrorWithCause(http.StatusBadRequest, message, err)
+}
+
+// bodyTooLarge returns the error for a body over limit bytes.
+func bodyTooLarge(limit int64) error {
+	return NewHTTPErrorWithCause(http.StatusRequestEntityTooLarge, "request body too large", &http.MaxBytesError{Limit: limit})
+}
@@ -0,0 +1,141 @@
+package nexo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext_RawBody(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" form:"name"`
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		read        func(c *Context) error
+	}{
+		{"bind then raw", "application/json", `{"name":"ada"}`, func(c *Context) error {
+			var p payload
+			if err := c.Bind(&p); err != nil || p.Name != "ada" {
+				return errors.New("bind failed")
+			}
+			return nil
+		}},
+		{"raw then bind", "application/json", `{"name":"ada"}`, func(c *Context) error {
+			if _, err := c.RawBody(); err != nil {
+				return err
+			}
+			var p payload
+			if err := c.Bind(&p); err != nil || p.Name != "ada" {
+				return errors.New("bind failed")
+			}
+			return nil
+		}},
+		{"form then raw", "application/x-www-form-urlencoded", "name=ada", func(c *Context) error {
+			var p payload
+			if err := c.BindForm(&p); err != nil || p.Name != "ada" {
+				return errors.New("bind form failed")
+			}
+			return nil
+		}},
+		{"request body then raw", "text/plain", "hello", func(c *Context) error {
+			if _, err := c.RawBody(); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(c.Request.Body)
+			if err != nil || string(data) != "hello" {
+				return errors.New("request body not rewound")
+			}
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			c := NewContext(httptest.NewRecorder(), req)
+
+			if err := tt.read(c); err != nil {
+				t.Fatal(err)
+			}
+			raw, err := c.RawBody()
+			if err != nil || string(raw) != tt.body {
+				t.Errorf("RawBody() = %q, %v; want %q", raw, err, tt.body)
+			}
+			data, _ := io.ReadAll(c.Request.Body)
+			if string(data) != tt.body {
+				t.Errorf("Request.Body = %q after RawBody, want %q", data, tt.body)
+			}
+			body, err := c.Request.GetBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := io.ReadAll(body); string(data) != tt.body {
+				t.Errorf("GetBody() = %q, want %q", data, tt.body)
+			}
+		})
+	}
+}
+
+func TestContext_RawBodyLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int64
+		body  string
+		want  int
+	}{
+		{"within limit", 8, "12345678", 0},
+		{"over limit", 8, "123456789", http.StatusRequestEntityTooLarge},
+		{"no limit", -1, strings.Repeat("x", 64), 0},
+		{"default limit", 0, "small", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.ContentLength = -1 // make RawBody read the body to find its size
+			c := NewContext(httptest.NewRecorder(), req)
+			c.bodyLimit = tt.limit
+
+			raw, err := c.RawBody()
+			if tt.want == 0 {
+				if err != nil || string(raw) != tt.body {
+					t.Errorf("RawBody() = %q, %v; want %q", raw, err, tt.body)
+				}
+				return
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.Code != tt.want {
+				t.Fatalf("RawBody() error = %v, want %d", err, tt.want)
+			}
+			var tooLarge *http.MaxBytesError
+			if !errors.As(err, &tooLarge) || tooLarge.Limit != tt.limit {
+				t.Errorf("RawBody() error = %v, want a MaxBytesError with limit %d", err, tt.limit)
+			}
+		})
+	}
+}
+
+func TestWithBodyLimit(t *testing.T) {
+	app := New(WithBodyLimit(4))
+	app.Post("/", func(c *Context) error {
+		var v map[string]any
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
@@ -0,0 +1,365 @@
+package nexo
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RedirectRule redirects the paths matching Source, under redirects: in
+// nexo.yaml, so URL migrations don't need a proxy.go:
+//
+//	redirects:
+//	  - source: /blog/{slug}
+//	    destination: /posts/{slug}
+//	    permanent: true
+//	  - source: /docs/v1/*
+//	    destination: https://v1.docs.example.com/*
+type RedirectRule struct {
+	// Source is the path pattern, as in HeaderRule.
+	Source string `mapstructure:"source"`
+
+	// Destination is the path or URL to redirect to. It can use the
+	// parameters of Source, like {slug}, and * for the rest matched by a
+	// trailing *. The query of the request is kept.
+	Destination string `mapstructure:"destination"`
+
+	// Permanent redirects with 308 Permanent Redirect instead of
+	// 307 Temporary Redirect.
+	Permanent bool `mapstructure:"permanent"`
+
+	// Has and Missing are conditions the request must and must not meet.
+	Has     []RuleCondition `mapstructure:"has"`
+	Missing []RuleCondition `mapstructure:"missing"`
+}
+
+// RewriteRule serves the paths matching Source from Destination, under
+// rewrites: in nexo.yaml. The client URL stays the same:
+//
+//	rewrites:
+//	  - source: /about
+//	    destination: /pages/about
+//	  - source: /
+//	    destination: /dashboard
+//	    has:
+//	      - type: cookie
+//	        key: session
+type RewriteRule struct {
+	// Source is the path pattern, as in HeaderRule.
+	Source string `mapstructure:"source"`
+
+	// Destination is the path to route the request to, with the
+	// parameters of Source, as in RedirectRule. To serve another origin,
+	// use Forward in proxy.go.
+	Destination string `mapstructure:"destination"`
+
+	// Has and Missing are conditions the request must and must not meet.
+	Has     []RuleCondition `mapstructure:"has"`
+	Missing []RuleCondition `mapstructure:"missing"`
+}
+
+// RuleCondition matches a part of the request, for the has and missing
+// conditions of redirects and rewrites.
+type RuleCondition struct {
+	// Type is the part of the request: header, cookie, query or host.
+	Type string `mapstructure:"type"`
+
+	// Key is the header, cookie or query parameter name. Hosts have none.
+	Key string `mapstructure:"key"`
+
+	// Value is a regular expression the whole value must match. Empty
+	// matches any value, so the condition only needs the key.
+	Value string `mapstructure:"value"`
+}
+
+// routingRules holds the compiled redirects and rewrites of an app.
+type routingRules struct {
+	redirects []routingRule
+	rewrites  []routingRule
+}
+
+// routingRule is a compiled redirect or rewrite.
+type routingRule struct {
+	source      pathPattern
+	destination string
+	status      int
+	has         []ruleCondition
+	missing     []ruleCondition
+}
+
+// ruleCondition is a RuleCondition with its value compiled.
+type ruleCondition struct {
+	typ   string
+	key   string
+	value *regexp.Regexp
+}
+
+// newRoutingRules compiles redirects and rewrites. It returns nil when
+// there are none.
+func newRoutingRules(redirects []RedirectRule, rewrites []RewriteRule) (*routingRules, error) {
+	if len(redirects) == 0 && len(rewrites) == 0 {
+		return nil, nil
+	}
+	rules := &routingRules{}
+	for _, redirect := range redirects {
+		status := http.StatusTemporaryRedirect
+		if redirect.Permanent {
+			status = http.StatusPermanentRedirect
+		}
+		rule, err := newRoutingRule("redirects", redirect.Source, redirect.Destination, redirect.Has, redirect.Missing)
+		if err != nil {
+			return nil, err
+		}
+		rule.status = status
+		rules.redirects = append(rules.redirects, rule)
+	}
+	for _, rewrite := range rewrites {
+		if !strings.HasPrefix(rewrite.Destination, "/") {
+			return nil, fmt.Errorf("rewrites: destination %q must be a path", rewrite.Destination)
+		}
+		rule, err := newRoutingRule("rewrites", rewrite.Source, rewrite.Destination, rewrite.Has, rewrite.Missing)
+		if err != nil {
+			return nil, err
+		}
+		rules.rewrites = append(rules.rewrites, rule)
+	}
+	return rules, nil
+}
+
+// newRoutingRule compiles a rule of section.
+func newRoutingRule(section, source, destination string, has, missing []RuleCondition) (routingRule, error) {
+	pattern, err := compilePathRule(source)
+	if err != nil {
+		return routingRule{}, fmt.Errorf("%s: %w", section, err)
+	}
+	if destination == "" {
+		return routingRule{}, fmt.Errorf("%s: source %q has no destination", section, source)
+	}
+	rule := routingRule{source: pattern, destination: destination}
+	if rule.has, err = compileConditions(section, has); err != nil {
+		return routingRule{}, err
+	}
+	if rule.missing, err = compileConditions(section, missing); err != nil {
+		return routingRule{}, err
+	}
+	return rule, nil
+}
+
+// compileConditions compiles the has or missing conditions of a rule.
+func compileConditions(section string, conditions []RuleCondition) ([]ruleCondition, error) {
+	compiled := make([]ruleCondition, 0, len(conditions))
+	for _, condition := range conditions {
+		typ := strings.ToLower(condition.Type)
+		switch typ {
+		case "header", "cookie", "query":
+			if condition.Key == "" {
+				return nil, fmt.Errorf("%s: %s condition has no key", section, typ)
+			}
+		case "host":
+		default:
+			return nil, fmt.Errorf("%s: unknown condition type %q", section, condition.Type)
+		}
+		c := ruleCondition{typ: typ, key: condition.Key}
+		if condition.Value != "" {
+			re, err := regexp.Compile("^(?:" + condition.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("%s: condition value: %w", section, err)
+			}
+			c.value = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// redirect returns the URL and status of the first redirect matching r.
+func (rr *routingRules) redirect(r *http.Request) (string, int, bool) {
+	if rr == nil {
+		return "", 0, false
+	}
+	for _, rule := range rr.redirects {
+		if target, ok := rule.apply(r); ok {
+			return target, rule.status, true
+		}
+	}
+	return "", 0, false
+}
+
+// rewrite returns the path and query of the first rewrite matching r.
+func (rr *routingRules) rewrite(r *http.Request) (*url.URL, bool) {
+	if rr == nil {
+		return nil, false
+	}
+	for _, rule := range rr.rewrites {
+		if target, ok := rule.apply(r); ok {
+			u, err := url.Parse(target)
+			if err != nil {
+				return nil, false
+			}
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// apply returns the destination of the rule for r, with the parameters of
+// the source and the query of r, if the rule matches.
+func (rule routingRule) apply(r *http.Request) (string, bool) {
+	params, ok := rule.source.match(r.URL.Path)
+	if !ok {
+		return "", false
+	}
+	for _, c := range rule.has {
+		if !c.matches(r) {
+			return "", false
+		}
+	}
+	for _, c := range rule.missing {
+		if c.matches(r) {
+			return "", false
+		}
+	}
+
+	destination := expandParams(rule.destination, params)
+	if rest, ok := params["*"]; ok {
+		if rest == "" {
+			destination = strings.ReplaceAll(destination, "/*", "")
+		}
+		destination = strings.ReplaceAll(destination, "*", rest)
+		if destination == "" {
+			destination = "/"
+		}
+	}
+	if strings.HasPrefix(rule.destination, "/") && !isLocalPath(destination) {
+		// A parameter turned the path into a protocol-relative URL, like
+		// //evil.com or /\evil.com, which would send the client to another
+		// host.
+		return "", false
+	}
+	if r.URL.RawQuery == "" {
+		return destination, true
+	}
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination, true
+	}
+	query := u.Query()
+	for key, values := range r.URL.Query() {
+		if !query.Has(key) {
+			query[key] = values
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), true
+}
+
+// isLocalPath reports whether path stays on the same host when used as a
+// URL: it starts with a single / not followed by another / or \.
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "/\\")
+}
+
+// matches reports whether r meets the condition.
+func (c ruleCondition) matches(r *http.Request) bool {
+	var value string
+	switch c.typ {
+	case "header":
+		values := r.Header.Values(c.key)
+		if len(values) == 0 {
+			return false
+		}
+		value = values[0]
+	case "cookie":
+		cookie, err := r.Cookie(c.key)
+		if err != nil {
+			return false
+		}
+		value = cookie.Value
+	case "query":
+		query := r.URL.Query()
+		if !query.Has(c.key) {
+			return false
+		}
+		value = query.Get(c.key)
+	case "host":
+		value = r.Host
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			value = host
+		}
+	}
+	return c.value == nil || c.value.MatchString(value)
+}
+
+// pathPattern is a path pattern of the headers, redirects and rewrites
+// rules, split into segments.
+type pathPattern struct {
+	segments []string
+	wildcard bool
+}
+
+// compilePathRule compiles the source pattern of a rule: {name} matches
+// one segment and a trailing * the rest of the path, including none.
+func compilePathRule(source string) (pathPattern, error) {
+	if !strings.HasPrefix(source, "/") {
+		return pathPattern{}, fmt.Errorf("source %q must start with /", source)
+	}
+	var p pathPattern
+	trimmed := strings.Trim(source, "/")
+	if rest, ok := strings.CutSuffix(trimmed, "*"); ok {
+		if rest != "" && !strings.HasSuffix(rest, "/") {
+			return pathPattern{}, fmt.Errorf("source %q: * must be a whole segment", source)
+		}
+		p.wildcard = true
+		trimmed = strings.TrimSuffix(rest, "/")
+	}
+	if trimmed != "" {
+		p.segments = strings.Split(trimmed, "/")
+	}
+	for _, segment := range p.segments {
+		if strings.Contains(segment, "*") {
+			return pathPattern{}, fmt.Errorf("source %q: * is only allowed at the end", source)
+		}
+	}
+	return p, nil
+}
+
+// match reports whether path matches the pattern, with the values of its
+// parameters. The rest matched by a trailing * is the "*" parameter.
+func (p pathPattern) match(path string) (map[string]string, bool) {
+	var segments []string
+	if path = strings.Trim(path, "/"); path != "" {
+		segments = strings.Split(path, "/")
+	}
+	if len(segments) < len(p.segments) || (!p.wildcard && len(segments) != len(p.segments)) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, want := range p.segments {
+		if strings.HasPrefix(want, "{") && strings.HasSuffix(want, "}") {
+			params[want[1:len(want)-1]] = segments[i]
+			continue
+		}
+		if want != segments[i] {
+			return nil, false
+		}
+	}
+	if p.wildcard {
+		rest := slices.DeleteFunc(slices.Clone(segments[len(p.segments):]), func(s string) bool { return s == "" })
+		params["*"] = strings.Join(rest, "/")
+	}
+	return params, true
+}
+
+// expandParams replaces the {name} parameters in s.
+func expandParams(s string, params map[string]string) string {
+	for name, value := range params {
+		if name != "*" {
+			s = strings.ReplaceAll(s, "{"+name+"}", value)
+		}
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedirectsAndRewrites(t *testing.T) {
+	app := New(
+		WithRedirects(
+			RedirectRule{Source: "/blog/{slug}", Destination: "/posts/{slug}", Permanent: true},
+			RedirectRule{Source: "/docs/v1/*", Destination: "https://v1.example.com/*"},
+			RedirectRule{Source: "/beta", Destination: "/", Missing: []RuleCondition{{Type: "cookie", Key: "beta", Value: "on|yes"}}},
+			RedirectRule{Source: "/mobile", Destination: "/m", Has: []RuleCondition{{Type: "header", Key: "User-Agent", Value: ".*Mobile.*"}}},
+			RedirectRule{Source: "/legacy/*", Destination: "/*"},
+		),
+		WithRewrites(
+			RewriteRule{Source: "/", Destination: "/dashboard", Has: []RuleCondition{{Type: "cookie", Key: "session"}}},
+			RewriteRule{Source: "/about", Destination: "/pages/about?lang=en"},
+			RewriteRule{Source: "/shop/*", Destination: "/store/*", Has: []RuleCondition{{Type: "host", Value: `shop\.example\.com`}}},
+		),
+	)
+	echo := func(c *Context) error { return c.String(http.StatusOK, c.Path()+"?"+c.Request.URL.RawQuery) }
+	for _, path := range []string{"/", "/dashboard", "/beta", "/mobile", "/pages/about", "/store/*", "/shop/*"} {
+		app.Get(path, echo)
+	}
+	app.Mount()
+
+	tests := []struct {
+		name     string
+		target   string
+		host     string
+		cookie   string
+		agent    string
+		status   int
+		location string
+		body     string
+	}{
+		{name: "redirect with param", target: "/blog/hello?ref=x", status: 308, location: "/posts/hello?ref=x"},
+		{name: "redirect with wildcard", target: "/docs/v1/api/users", status: 307, location: "https://v1.example.com/api/users"},
+		{name: "redirect wildcard root", target: "/docs/v1", status: 307, location: "https://v1.example.com"},
+		{name: "missing condition", target: "/beta", status: 307, location: "/"},
+		{name: "missing condition met", target: "/beta", cookie: "beta=on", status: 200, body: `/beta?`},
+		{name: "has header", target: "/mobile", agent: "Foo Mobile Safari", status: 307, location: "/m"},
+		{name: "has header unmet", target: "/mobile", agent: "Desktop", status: 200},
+		{name: "wildcard with empty segment", target: "/legacy//evil.com", status: 307, location: "/evil.com"},
+		{name: "wildcard with encoded slash", target: "/legacy/%2Fevil.com", status: 307, location: "/evil.com"},
+		{name: "wildcard with backslash", target: "/legacy/%5Cevil.com", status: 404},
+		{name: "rewrite with cookie", target: "/", cookie: "session=abc", status: 200, body: `/dashboard?`},
+		{name: "rewrite without cookie", target: "/", status: 200, body: `/?`},
+		{name: "rewrite merges query", target: "/about?lang=es&page=2", status: 200, body: `/pages/about?lang=en&page=2`},
+		{name: "rewrite by host", target: "/shop/cart", host: "shop.example.com:8080", status: 200, body: `/store/cart?`},
+		{name: "rewrite other host", target: "/shop/cart", status: 200, body: `/shop/cart?`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.cookie != "" {
+				r.Header.Set("Cookie", tt.cookie)
+			}
+			if tt.agent != "" {
+				r.Header.Set("User-Agent", tt.agent)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got := w.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestNewRoutingRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		redirects []RedirectRule
+		rewrites  []RewriteRule
+	}{
+		{"no destination", []RedirectRule{{Source: "/a"}}, nil},
+		{"bad source", []RedirectRule{{Source: "a", Destination: "/b"}}, nil},
+		{"external rewrite", nil, []RewriteRule{{Source: "/a", Destination: "https://example.com"}}},
+		{"unknown condition", []RedirectRule{{Source: "/a", Destination: "/b", Has: []RuleCondition{{Type: "ip"}}}}, nil},
+		{"condition without key", nil, []RewriteRule{{Source: "/a", Destination: "/b", Has: []RuleCondition{{Type: "query"}}}}},
+		{"bad regexp", []RedirectRule{{Source: "/a", Destination: "/b", Has: []RuleCondition{{Type: "host", Value: "("}}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newRoutingRules(tt.redirects, tt.rewrites); err == nil {
+				t.Error("newRoutingRules() = nil error")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Redirects(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `redirects:
+  - source: /old
+    destination: /new
+    permanent: true
+rewrites:
+  - source: /
+    destination: /home
+    has:
+      - type: query
+        key: preview
+`
+	if err := os.WriteFile(filepath.Join(dir, "nexo.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Redirects) != 1 || !config.Redirects[0].Permanent || len(config.Rewrites) != 1 ||
+		config.Rewrites[0].Has[0] != (RuleCondition{Type: "query", Key: "preview"}) {
+		t.Errorf("Redirects = %+v, Rewrites = %+v", config.Redirects, config.Rewrites)
+	}
+}
@@ -0,0 +1,225 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ---------- Config Reload ----------
+
+// configReloadDelay is how long the watcher waits for writes to settle
+// before reloading, as editors save a file in several steps.
+const configReloadDelay = 100 * time.Millisecond
+
+// liveConfig holds the app's current config, which UpdateConfig swaps
+// while requests read it.
+type liveConfig struct {
+	current atomic.Pointer[Config]
+
+	// mu serializes updates and guards hooks and watcher
+	mu      sync.Mutex
+	hooks   []func(old, new *Config)
+	watcher *fsnotify.Watcher
+}
+
+// newLiveConfig creates a liveConfig starting at config.
+func newLiveConfig(config *Config) *liveConfig {
+	lc := &liveConfig{}
+	lc.current.Store(config)
+	return lc
+}
+
+// Config returns the app's current config. Handlers read their settings
+// from it with typed accessors instead of reading environment variables:
+//
+//	key := c.Config().String("stripe.public_key", "")
+//	perPage := c.Config().Int("catalog.per_page", 20)
+//
+// The config is shared by all requests and must not be modified.
+func (c *Context) Config() *Config {
+	if c.config == nil {
+		return DefaultConfig()
+	}
+	return c.config.current.Load()
+}
+
+// OnConfigChange registers fn to run after the config is reloaded, with
+// the config before and after. Hooks run one at a time, in order, and must
+// not call UpdateConfig.
+//
+// Example:
+//
+//	app.OnConfigChange(func(old, new *nexo.Config) {
+//	    if new.Int("workers", 4) != old.Int("workers", 4) {
+//	        pool.Resize(new.Int("workers", 4))
+//	    }
+//	})
+func (a *App) OnConfigChange(fn func(old, new *Config)) {
+	lc := a.routeTree.config
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.hooks = append(lc.hooks, fn)
+}
+
+// UpdateConfig applies the settings of config that can change while the
+// app runs: log.level, flags.set, rate_limits and settings. Other changes
+// take effect on the next start. The OnConfigChange hooks run after the
+// update.
+func (a *App) UpdateConfig(config *Config) {
+	lc := a.routeTree.config
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	old := lc.current.Load()
+	next := *old
+	next.Log = config.Log
+	next.Flags.Set = config.Flags.Set
+	next.RateLimits = config.RateLimits
+	next.Settings = config.Settings
+	if !reflect.DeepEqual(withoutLiveSettings(old), withoutLiveSettings(config)) {
+		log.Printf("nexo: config changes other than log.level, flags.set, rate_limits and settings apply on restart")
+	}
+	lc.current.Store(&next)
+
+	if next.Log.Level != old.Log.Level {
+		a.applyLogLevel(next.Log)
+	}
+	if !reflect.DeepEqual(next.Flags.Set, old.Flags.Set) && a.routeTree.flags != nil {
+		if err := a.routeTree.flags.Load(context.Background()); err != nil {
+			log.Printf("nexo: reloading flags: %v", err)
+		}
+	}
+	for _, hook := range lc.hooks {
+		hook(old, &next)
+	}
+}
+
+// withoutLiveSettings returns a copy of config without the settings
+// UpdateConfig applies, for comparing the rest.
+func withoutLiveSettings(config *Config) Config {
+	c := *config
+	c.Log = LogConfig{}
+	c.Flags.Set = nil
+	c.RateLimits = nil
+	c.Settings = nil
+	return c
+}
+
+// applyLogLevel sets the request logger's level to that of config, unless
+// NEXO_LOG_LEVEL sets it. Without a level, the mode's default is used.
+func (a *App) applyLogLevel(config LogConfig) {
+	if a.logger == nil || os.Getenv("NEXO_LOG_LEVEL") != "" {
+		return
+	}
+	level := requestLoggerConfig(a.configuredMode()).Level
+	if config.Level != "" {
+		level = ParseLogLevel(config.Level)
+	}
+	a.logger.SetLevel(level)
+}
+
+// WatchConfig reloads nexo.yaml from the directory path (as LoadConfig
+// does) whenever it changes and applies it with UpdateConfig. A file that
+// fails to load is logged and the current config kept. Watching stops on
+// shutdown.
+//
+// Example:
+//
+//	config, err := nexo.LoadConfig("")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app := nexo.New(nexo.WithConfig(config))
+//	if err := app.WatchConfig(""); err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *App) WatchConfig(path string) error {
+	dir := path
+	if dir == "" {
+		dir = "."
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watch config: %w", err)
+	}
+	// Watch the directory, as editors replace the file when saving
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watch config: %w", err)
+	}
+
+	lc := a.routeTree.config
+	lc.mu.Lock()
+	if lc.watcher != nil {
+		lc.watcher.Close()
+	}
+	lc.watcher = watcher
+	lc.mu.Unlock()
+
+	go a.watchConfig(watcher, path)
+	return nil
+}
+
+// watchConfig reloads the config on the watcher's events until it is
+// closed.
+func (a *App) watchConfig(watcher *fsnotify.Watcher, path string) {
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+			if !isConfigFile(event.Name) || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(configReloadDelay, func() {
+				config, err := LoadConfig(path)
+				if err != nil {
+					log.Printf("nexo: %v; keeping the current config", err)
+					return
+				}
+				a.UpdateConfig(config)
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("nexo: watching config: %v", err)
+		}
+	}
+}
+
+// isConfigFile reports whether name is a nexo config file, like nexo.yaml.
+func isConfigFile(name string) bool {
+	base := filepath.Base(name)
+	ext := filepath.Ext(base)
+	return ext != "" && strings.TrimSuffix(base, ext) == "nexo"
+}
+
+// stopWatchingConfig stops WatchConfig.
+func (a *App) stopWatchingConfig() {
+	lc := a.routeTree.config
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.watcher != nil {
+		lc.watcher.Close()
+		lc.watcher = nil
+	}
+}
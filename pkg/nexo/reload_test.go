@@ -0,0 +1,145 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigSettings(t *testing.T) {
+	t.Setenv("TEST_STRIPE_KEY", "sk_test_123")
+	config := &Config{Settings: map[string]any{
+		"stripe":   map[string]any{"secret_key": "${TEST_STRIPE_KEY}", "retries": 3},
+		"perpage":  "25",
+		"beta":     true,
+		"timeout":  "30s",
+		"admins":   []any{"ana", "bo"},
+		"origins":  "a.com, b.com",
+		"greeting": "hi",
+	}}
+
+	if got := config.String("stripe.secret_key", ""); got != "sk_test_123" {
+		t.Errorf("String = %q, want the expanded variable", got)
+	}
+	if got := config.String("missing", "def"); got != "def" {
+		t.Errorf("String = %q, want def", got)
+	}
+	if got := config.Int("Stripe.Retries", 0); got != 3 {
+		t.Errorf("Int = %d, want 3", got)
+	}
+	if got := config.Int("perPage", 10); got != 25 {
+		t.Errorf("Int = %d, want 25", got)
+	}
+	if got := config.Int("greeting", 10); got != 10 {
+		t.Errorf("Int = %d, want the default", got)
+	}
+	if !config.Bool("beta", false) {
+		t.Error("Bool = false, want true")
+	}
+	if got := config.Duration("timeout", time.Second); got != 30*time.Second {
+		t.Errorf("Duration = %v, want 30s", got)
+	}
+	if got := config.Strings("admins"); !reflect.DeepEqual(got, []string{"ana", "bo"}) {
+		t.Errorf("Strings = %v", got)
+	}
+	if got := config.Strings("origins"); !reflect.DeepEqual(got, []string{"a.com", "b.com"}) {
+		t.Errorf("Strings = %v", got)
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = "3000"
+	config.Settings = map[string]any{"greeting": "hello"}
+	config.RateLimits = map[string]RateLimitConfig{"api": {Max: 1, Window: time.Minute}}
+	app := New(WithConfig(config))
+
+	var hooked []string
+	app.OnConfigChange(func(old, new *Config) {
+		hooked = append(hooked, old.String("greeting", "")+" -> "+new.String("greeting", ""))
+	})
+	app.Get("/", RateLimiterFromConfig("api")(func(c *Context) error {
+		if c.Flag("beta") {
+			return c.String(http.StatusOK, c.Config().String("greeting", "")+" beta")
+		}
+		return c.String(http.StatusOK, c.Config().String("greeting", ""))
+	}))
+	app.Mount()
+
+	get := func() (int, string) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code, w.Body.String()
+	}
+	if code, body := get(); code != http.StatusOK || body != "hello" {
+		t.Fatalf("got %d %q, want 200 hello", code, body)
+	}
+	if code, _ := get(); code != http.StatusTooManyRequests {
+		t.Fatalf("got %d, want the limit of 1 to apply", code)
+	}
+
+	updated := DefaultConfig()
+	updated.Port = "4000"
+	updated.Log.Level = "error"
+	updated.Settings = map[string]any{"greeting": "hola"}
+	updated.RateLimits = map[string]RateLimitConfig{"api": {Max: 5, Window: time.Minute}}
+	updated.Flags.Set = map[string]any{"beta": true}
+	app.UpdateConfig(updated)
+
+	if code, body := get(); code != http.StatusOK || body != "hola beta" {
+		t.Errorf("got %d %q, want 200 \"hola beta\"", code, body)
+	}
+	if !reflect.DeepEqual(hooked, []string{"hello -> hola"}) {
+		t.Errorf("hooks ran with %v", hooked)
+	}
+	if os.Getenv("NEXO_LOG_LEVEL") == "" && app.logger.Level() != LogLevelError {
+		t.Errorf("log level = %v, want error", app.logger.Level())
+	}
+	// Structural settings apply on restart
+	if app.Config().Port != "3000" {
+		t.Errorf("port = %s, want the running port", app.Config().Port)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "nexo.yaml")
+	if err := os.WriteFile(file, []byte("settings:\n  greeting: hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := New(WithConfig(config))
+	changed := make(chan *Config, 1)
+	app.OnConfigChange(func(_, new *Config) {
+		select {
+		case changed <- new:
+		default:
+		}
+	})
+	if err := app.WatchConfig(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer app.stopWatchingConfig()
+
+	if err := os.WriteFile(file, []byte("settings:\n  greeting: hola\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case new := <-changed:
+		if got := new.String("greeting", ""); got != "hola" {
+			t.Errorf("greeting = %q, want hola", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+	if got := app.Config().String("greeting", ""); got != "hola" {
+		t.Errorf("app config greeting = %q, want hola", got)
+	}
+}
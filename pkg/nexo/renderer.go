@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sort"
 
 	"github.com/a-h/templ"
 )
@@ -40,6 +41,29 @@ type PageHandler struct {
 	Title     string
 }
 
+// Load runs a page loader unless the request context is already done, and
+// drops its result when the context is canceled while it runs, so a page
+// isn't rendered for a client that went away or past the route's timeout.
+// Generated route files call it for pages with a loader.
+//
+// Example:
+//
+//	data, err := nexo.Load(c, dashboard.Loader)
+//	if err != nil {
+//	    return err
+//	}
+func Load[T any](c *Context, loader func(*Context) (T, error)) (T, error) {
+	var zero T
+	if err := c.Context().Err(); err != nil {
+		return zero, err
+	}
+	data, err := loader(c)
+	if ctxErr := c.Context().Err(); ctxErr != nil {
+		return zero, ctxErr
+	}
+	return data, err
+}
+
 // NewRenderer creates a new Renderer.
 func NewRenderer() *Renderer {
 	return &Renderer{
@@ -85,6 +109,26 @@ func (r *Renderer) GetLayout(path string) LayoutFunc {
 	return bestLayout
 }
 
+// GetLayouts returns every layout whose prefix matches path, root first,
+// for nesting: the root layout wraps the section layout, which wraps the page.
+func (r *Renderer) GetLayouts(path string) []LayoutFunc {
+	prefixes := make([]string, 0, len(r.layouts))
+	for prefix := range r.layouts {
+		if matchesPrefix(path, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) < len(prefixes[j])
+	})
+
+	layouts := make([]LayoutFunc, len(prefixes))
+	for i, prefix := range prefixes {
+		layouts[i] = r.layouts[prefix]
+	}
+	return layouts
+}
+
 // GetErrorComponent returns the most specific error component for a path.
 func (r *Renderer) GetErrorComponent(path string) ErrorComponent {
 	var bestMatch string
@@ -120,23 +164,20 @@ func matchesPrefix(path, prefix string) bool {
 
 // Render renders a templ component as the response.
 func (r *Renderer) Render(c *Context, status int, comp templ.Component) error {
+	ctx := c.renderContext()
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.Response.WriteHeader(status)
-	return comp.Render(c.Context(), c.Response)
+	return c.renderComponent(ctx, comp)
 }
 
-// RenderWithLayout renders a component wrapped in the appropriate layout.
+// RenderWithLayout renders a component wrapped in every layout matching the
+// request path, most specific innermost.
 func (r *Renderer) RenderWithLayout(c *Context, status int, title string, comp templ.Component) error {
-	layout := r.GetLayout(c.Path())
-
-	var finalComp templ.Component
-	if layout != nil {
-		finalComp = layout(title, comp)
-	} else {
-		finalComp = comp
+	layouts := r.GetLayouts(c.Path())
+	for i := len(layouts) - 1; i >= 0; i-- {
+		comp = layouts[i](title, comp)
 	}
-
-	return r.Render(c, status, finalComp)
+	return r.Render(c, status, comp)
 }
 
 // RenderError renders an error using the appropriate error component.
@@ -168,9 +209,10 @@ func (r *Renderer) RenderNotFound(c *Context) error {
 
 // TemplComponent is a helper to render templ components directly from handlers.
 func TemplComponent(c *Context, status int, comp templ.Component) error {
+	ctx := c.renderContext()
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.Response.WriteHeader(status)
-	return comp.Render(c.Context(), c.Response)
+	return c.renderComponent(ctx, comp)
 }
 
 // TemplWithLayout renders a component with the given layout.
@@ -182,9 +224,10 @@ func TemplWithLayout(c *Context, status int, layout LayoutFunc, title string, co
 		finalComp = comp
 	}
 
+	ctx := c.renderContext()
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.Response.WriteHeader(status)
-	return finalComp.Render(c.Context(), c.Response)
+	return c.renderComponent(ctx, finalComp)
 }
 
 // WrapLayout is a helper to create a layout wrapper component.
@@ -214,6 +257,7 @@ func NewStreamingRenderer() *StreamingRenderer {
 
 // RenderStreaming renders a component with streaming support (chunked transfer).
 func (sr *StreamingRenderer) RenderStreaming(c *Context, comp templ.Component) error {
+	ctx := c.renderContext()
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.SetHeader("Transfer-Encoding", "chunked")
 	c.Response.WriteHeader(http.StatusOK)
@@ -223,5 +267,5 @@ func (sr *StreamingRenderer) RenderStreaming(c *Context, comp templ.Component) e
 		defer flusher.Flush()
 	}
 
-	return comp.Render(c.Context(), c.Response)
+	return comp.Render(ctx, c.Response)
 }
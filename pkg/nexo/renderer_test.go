@@ -305,6 +305,29 @@ func TestRenderer_RenderWithLayout(t *testing.T) {
 		}
 	})
 
+	t.Run("nested layouts", func(t *testing.T) {
+		r := NewRenderer()
+		r.SetLayout("/", mockLayout)
+		r.SetLayout("/dashboard", func(title string, children templ.Component) templ.Component {
+			return templ.Join(templ.Raw("<nav>"+title+"</nav>"), children)
+		})
+		r.SetLayout("/blog", func(title string, children templ.Component) templ.Component {
+			return templ.Raw("<blog>")
+		})
+
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/dashboard/stats", nil))
+
+		if err := r.RenderWithLayout(c, http.StatusOK, "Stats", mockComponent{content: "<p>Content</p>"}); err != nil {
+			t.Fatalf("RenderWithLayout() error = %v", err)
+		}
+
+		want := "<html><head><title>Stats</title></head><body><nav>Stats</nav><p>Content</p></body></html>"
+		if body := w.Body.String(); body != want {
+			t.Errorf("body = %q, want %q", body, want)
+		}
+	})
+
 	t.Run("without layout", func(t *testing.T) {
 		r := NewRenderer()
 		// No layout registered
@@ -540,3 +563,49 @@ func TestStreamingRenderer_RenderStreaming(t *testing.T) {
 		t.Errorf("body = %q, want %q", body, "<div>Streaming Content</div>")
 	}
 }
+
+func TestLoad(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		cancelRun bool // cancel the request while the loader runs
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "loads", ctx: context.Background(), wantCalls: 1},
+		{name: "canceled before", ctx: canceled, wantCalls: 0, wantErr: context.Canceled},
+		{name: "canceled during", ctx: context.Background(), cancelRun: true, wantCalls: 1, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(tt.ctx)
+			defer cancel()
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			c := NewContext(httptest.NewRecorder(), req)
+
+			calls := 0
+			data, err := Load(c, func(c *Context) (string, error) {
+				calls++
+				if tt.cancelRun {
+					cancel()
+				}
+				return "data", nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("loader ran %d times, want %d", calls, tt.wantCalls)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && data != "data" {
+				t.Errorf("data = %q, want %q", data, "data")
+			}
+			if tt.wantErr != nil && data != "" {
+				t.Errorf("data = %q after cancellation, want zero value", data)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type responseWriter struct {
 	status      int
 	size        int64
 	wroteHeader bool
+	body        *cappedBuffer // captures the body for the request log (optional)
 }
 
 // newResponseWriter creates a new responseWriter that wraps the given http.ResponseWriter.
@@ -39,6 +40,9 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	}
 	n, err := rw.ResponseWriter.Write(b)
 	rw.size += int64(n)
+	if rw.body != nil {
+		rw.body.write(b[:n])
+	}
 	return n, err
 }
 
@@ -59,10 +63,7 @@ func (rw *responseWriter) Written() bool {
 
 // Hijack implements the http.Hijacker interface for WebSocket support.
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
-		return hijacker.Hijack()
-	}
-	return nil, nil, http.ErrNotSupported
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
 }
 
 // Flush implements the http.Flusher interface for streaming support.
@@ -72,6 +73,17 @@ func (rw *responseWriter) Flush() {
 	}
 }
 
+// FlushError flushes the underlying writer, reporting http.ErrNotSupported
+// if it can't flush. Used by http.ResponseController.
+func (rw *responseWriter) FlushError() error {
+	return http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Push implements the http.Pusher interface for HTTP/2 server push.
 func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
 	if pusher, ok := rw.ResponseWriter.(http.Pusher); ok {
@@ -0,0 +1,297 @@
+package nexo
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// cacheTagsKey is the store key of the cache tags of a response.
+const cacheTagsKey = "nexo.cacheTags"
+
+// CacheTag tags the response being rendered, so RevalidateTag can drop it
+// from the response cache (see CacheResponse).
+//
+// Example:
+//
+//	func Get(c *nexo.Context) error {
+//	    c.CacheTag("posts", "post:"+c.Param("slug"))
+//	    ...
+//	}
+func (c *Context) CacheTag(tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	c.Set(cacheTagsKey, append(c.cacheTags(), tags...))
+}
+
+// cacheTags returns the tags added with CacheTag.
+func (c *Context) cacheTags() []string {
+	tags, _ := c.Get(cacheTagsKey).([]string)
+	return tags
+}
+
+// RevalidatePath drops the cached responses of a path from the app's cache,
+// so the next request re-renders it. See RevalidatePath.
+func (c *Context) RevalidatePath(path string) error {
+	return RevalidatePath(c.Context(), c.Cache(), path)
+}
+
+// RevalidateTag drops the cached responses tagged with any of tags from the
+// app's cache. See RevalidateTag.
+func (c *Context) RevalidateTag(tags ...string) error {
+	return RevalidateTag(c.Context(), c.Cache(), tags...)
+}
+
+// RevalidatePath drops the cached responses of path from cache. path is
+// either a URL path, like /blog/hello, or a route pattern in file-system or
+// chi form, like /blog/[slug] or /blog/{slug}, which drops the responses of
+// every URL of the route.
+func RevalidatePath(ctx context.Context, cache Cache, path string) error {
+	pattern := fileRouteToPattern(path)
+	if strings.ContainsAny(pattern, "{*") {
+		return markRevalidated(ctx, cache, "route:"+normalizeRoutePattern(pattern))
+	}
+	return markRevalidated(ctx, cache, "path:"+cleanRevalidatePath(pattern))
+}
+
+// RevalidateTag drops the cached responses tagged with any of tags (see
+// Context.CacheTag and CacheResponseConfig.Tags) from cache.
+func RevalidateTag(ctx context.Context, cache Cache, tags ...string) error {
+	for _, tag := range tags {
+		if err := markRevalidated(ctx, cache, "tag:"+tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markRevalidated records that the responses identified by target are
+// stale from now on. Cached responses stored before the mark are ignored,
+// which works with any Cache, as none can list keys.
+func markRevalidated(ctx context.Context, cache Cache, target string) error {
+	return cache.Set(ctx, "revalidate:"+target, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), 0)
+}
+
+// revalidatedSince reports whether the path, route or a tag of a cached
+// response was revalidated after it was stored.
+func revalidatedSince(ctx context.Context, cache Cache, entry cachedResponse) bool {
+	targets := []string{"path:" + cleanRevalidatePath(entry.Path)}
+	if entry.Route != "" {
+		targets = append(targets, "route:"+normalizeRoutePattern(entry.Route))
+	}
+	for _, tag := range entry.Tags {
+		targets = append(targets, "tag:"+tag)
+	}
+	return revalidatedAfter(ctx, cache, targets, entry.StoredAt)
+}
+
+// revalidatedAfter reports whether any of targets was marked revalidated at
+// or after storedAt (UnixNano).
+func revalidatedAfter(ctx context.Context, cache Cache, targets []string, storedAt int64) bool {
+	for _, target := range targets {
+		data, ok, err := cache.Get(ctx, "revalidate:"+target)
+		if err != nil || !ok {
+			continue
+		}
+		if at, err := strconv.ParseInt(string(data), 10, 64); err == nil && at >= storedAt {
+			return true
+		}
+	}
+	return false
+}
+
+// fileRouteToPattern converts the file-system segments of a route, like
+// [slug], [...slug] and (group), to a chi pattern.
+func fileRouteToPattern(path string) string {
+	segments := strings.Split(path, "/")
+	kept := segments[:0]
+	for _, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, "(") && strings.HasSuffix(seg, ")"):
+			continue
+		case strings.HasPrefix(seg, "[[...") || strings.HasPrefix(seg, "[..."):
+			seg = "*"
+		case strings.HasPrefix(seg, "[") && strings.HasSuffix(seg, "]"):
+			seg = "{" + strings.Trim(seg, "[]") + "}"
+		}
+		kept = append(kept, seg)
+	}
+	return strings.Join(kept, "/")
+}
+
+// routeParamRe matches the parameters of a chi pattern.
+var routeParamRe = regexp.MustCompile(`\{[^}]*\}`)
+
+// catchAllRe matches the {slug...} or {slug...?} catch-all of a route
+// pattern.
+var catchAllRe = regexp.MustCompile(`\{[^}]*\.\.\.\??\}$`)
+
+// normalizeRoutePattern drops parameter names, so /blog/{slug} and
+// /blog/{id} are the same route, as are /docs/{slug...} and /docs/*.
+func normalizeRoutePattern(pattern string) string {
+	pattern = catchAllRe.ReplaceAllString(cleanRevalidatePath(pattern), "*")
+	return cleanRevalidatePath(routeParamRe.ReplaceAllString(pattern, "{}"))
+}
+
+// cleanRevalidatePath drops the query and trailing slash of a path.
+func cleanRevalidatePath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return orDefault(path, "/")
+}
+
+// ---------- Revalidation Endpoint ----------
+
+// RevalidateConfig configures the on-demand revalidation endpoint, under
+// revalidate: in nexo.yaml.
+type RevalidateConfig struct {
+	// Path is where the endpoint is served (default: /_nexo/revalidate).
+	Path string `mapstructure:"path"`
+
+	// Secret authenticates requests to the endpoint. Empty reads
+	// NEXO_REVALIDATE_SECRET; without a secret the endpoint isn't served.
+	Secret string `mapstructure:"secret"`
+}
+
+// revalidateRequest is the JSON body of a revalidation request.
+type revalidateRequest struct {
+	Path  string   `json:"path"`
+	Paths []string `json:"paths"`
+	Tag   string   `json:"tag"`
+	Tags  []string `json:"tags"`
+}
+
+// mountRevalidate registers the revalidation endpoint when a secret is
+// configured, so CMS webhooks can drop cached pages:
+//
+//	POST /_nexo/revalidate?path=/blog/[slug]&tag=posts
+//	Authorization: Bearer <secret>
+//
+// It is registered on the router directly, so app middleware like auth
+// doesn't reject the webhook.
+func (a *App) mountRevalidate() {
+	cfg := a.config.Revalidate
+	secret := orDefault(cfg.Secret, os.Getenv("NEXO_REVALIDATE_SECRET"))
+	path := orDefault(cfg.Path, "/_nexo/revalidate")
+	if secret == "" || a.hasRoute(http.MethodPost, path) {
+		return
+	}
+	a.router.Post(path, func(w http.ResponseWriter, r *http.Request) {
+		a.handleRevalidate(w, r, secret)
+	})
+}
+
+// handleRevalidate revalidates the paths and tags of a request given as
+// query parameters (path, tag) or as a JSON body.
+func (a *App) handleRevalidate(w http.ResponseWriter, r *http.Request, secret string) {
+	writeJSON := func(status int, v any) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	fail := func(status int, message string) {
+		writeJSON(status, map[string]any{"error": map[string]any{"code": status, "message": message}})
+	}
+
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token = orDefault(token, r.URL.Query().Get("secret"))
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		fail(http.StatusUnauthorized, "invalid revalidation secret")
+		return
+	}
+
+	query := r.URL.Query()
+	paths, tags := query["path"], query["tag"]
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var body revalidateRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			fail(http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if body.Path != "" {
+			paths = append(paths, body.Path)
+		}
+		if body.Tag != "" {
+			tags = append(tags, body.Tag)
+		}
+		paths = append(paths, body.Paths...)
+		tags = append(tags, body.Tags...)
+	}
+	if len(paths) == 0 && len(tags) == 0 {
+		fail(http.StatusBadRequest, "give a path or tag to revalidate")
+		return
+	}
+
+	for _, path := range paths {
+		if err := RevalidatePath(r.Context(), a.routeTree.cache, path); err != nil {
+			fail(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := RevalidateTag(r.Context(), a.routeTree.cache, tags...); err != nil {
+		fail(http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(http.StatusOK, map[string]any{
+		"revalidated": true,
+		"paths":       append([]string{}, paths...),
+		"tags":        append([]string{}, tags...),
+		"now":         time.Now().UnixMilli(),
+	})
+}
+
+// detached returns a copy of c for work that outlives the request, like
+// re-rendering a stale response in the background. Its request isn't
+// canceled when the original one ends, and it holds a copy of the chi
+// route context, which chi reuses for later requests.
+func (c *Context) detached() *Context {
+	ctx := context.WithoutCancel(c.Request.Context())
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		routing := chi.NewRouteContext()
+		routing.RoutePatterns = append(routing.RoutePatterns, rctx.RoutePatterns...)
+		routing.URLParams.Keys = append(routing.URLParams.Keys, rctx.URLParams.Keys...)
+		routing.URLParams.Values = append(routing.URLParams.Values, rctx.URLParams.Values...)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routing)
+	}
+	d := NewContext(nil, c.Request.Clone(ctx))
+	d.inherit(c)
+	d.locale = c.locale
+	d.tenant, d.tenantErr, d.tenantResolved = c.tenant, c.tenantErr, c.tenantResolved
+	for key, value := range c.params {
+		d.SetParam(key, value)
+	}
+	return d
+}
+
+// inherit gives c the app's services of parent, the ones
+// RouteTree.initContext sets up.
+func (c *Context) inherit(parent *Context) {
+	c.codec = parent.codec
+	c.i18n = parent.i18n
+	c.secret = parent.secret
+	c.headConfig = parent.headConfig
+	c.assets = parent.assets
+	c.cache = parent.cache
+	c.validator = parent.validator
+	c.errors = parent.errors
+	c.proxyTrust = parent.proxyTrust
+	c.flags = parent.flags
+	c.flagKey = parent.flagKey
+	c.events = parent.events
+	c.storage = parent.storage
+	c.tenancy = parent.tenancy
+	c.config = parent.config
+	c.bodyLimit = parent.bodyLimit
+}
@@ -0,0 +1,236 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+)
+
+// newRevalidateApp returns an app caching /blog/{slug}, /docs/{slug...} and
+// /about, and a counter of handler runs. Callers mount it.
+func newRevalidateApp(t *testing.T) (*App, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	app := New(WithCache(NewMemoryCache(0)))
+	app.Group("", func(g *RouteGroup) {
+		g.Use(CacheResponseWithConfig(CacheResponseConfig{TTL: time.Minute, Tags: []string{"site"}}))
+		g.Get("/blog/{slug}", func(c *Context) error {
+			calls.Add(1)
+			c.CacheTag("posts", "post:"+c.Param("slug"))
+			return c.String(http.StatusOK, "post "+c.Param("slug"))
+		})
+		g.Get("/docs/{slug...}", func(c *Context) error {
+			calls.Add(1)
+			return c.String(http.StatusOK, "doc "+c.Param("slug"))
+		})
+		g.Get("/about", func(c *Context) error {
+			calls.Add(1)
+			return c.String(http.StatusOK, "about")
+		})
+	})
+	return app, &calls
+}
+
+func getCache(app *App, path string) string {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Header().Get("X-Cache")
+}
+
+func TestRevalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		revalidate func(app *App) error
+		// X-Cache of the second request of each path
+		want map[string]string
+	}{
+		{
+			name:       "path",
+			revalidate: func(app *App) error { return RevalidatePath(t.Context(), app.Cache(), "/blog/one/") },
+			want:       map[string]string{"/blog/one": "MISS", "/blog/two": "HIT", "/about": "HIT"},
+		},
+		{
+			name:       "file route pattern",
+			revalidate: func(app *App) error { return RevalidatePath(t.Context(), app.Cache(), "/(marketing)/blog/[slug]") },
+			want:       map[string]string{"/blog/one": "MISS", "/blog/two": "MISS", "/about": "HIT"},
+		},
+		{
+			name:       "chi route pattern",
+			revalidate: func(app *App) error { return RevalidatePath(t.Context(), app.Cache(), "/blog/{id}") },
+			want:       map[string]string{"/blog/one": "MISS", "/blog/two": "MISS", "/about": "HIT"},
+		},
+		{
+			name:       "catch-all route pattern",
+			revalidate: func(app *App) error { return RevalidatePath(t.Context(), app.Cache(), "/docs/{slug...}") },
+			want:       map[string]string{"/docs/a/b": "MISS", "/blog/one": "HIT"},
+		},
+		{
+			name:       "handler tag",
+			revalidate: func(app *App) error { return RevalidateTag(t.Context(), app.Cache(), "post:two") },
+			want:       map[string]string{"/blog/one": "HIT", "/blog/two": "MISS", "/about": "HIT"},
+		},
+		{
+			name:       "config tag",
+			revalidate: func(app *App) error { return RevalidateTag(t.Context(), app.Cache(), "site") },
+			want:       map[string]string{"/blog/one": "MISS", "/blog/two": "MISS", "/about": "MISS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newRevalidateApp(t)
+			app.Mount()
+			for path := range tt.want {
+				getCache(app, path)
+			}
+			if err := tt.revalidate(app); err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tt.want {
+				if got := getCache(app, path); got != want {
+					t.Errorf("%s: X-Cache = %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContext_RevalidateTag(t *testing.T) {
+	app, calls := newRevalidateApp(t)
+	app.Post("/publish", func(c *Context) error {
+		if err := c.RevalidateTag("posts"); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Mount()
+
+	getCache(app, "/blog/one")
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/publish", nil))
+	if got := getCache(app, "/blog/one"); got != "MISS" {
+		t.Errorf("X-Cache = %q after RevalidateTag, want MISS", got)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestRevalidateEndpoint(t *testing.T) {
+	t.Setenv("NEXO_REVALIDATE_SECRET", "s3cret")
+	app, _ := newRevalidateApp(t)
+	app.Mount()
+
+	tests := []struct {
+		name        string
+		target      string
+		auth        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantMiss    []string
+	}{
+		{name: "no secret", target: "/_nexo/revalidate?tag=posts", wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret", target: "/_nexo/revalidate?tag=posts", auth: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "nothing to revalidate", target: "/_nexo/revalidate", auth: "Bearer s3cret", wantStatus: http.StatusBadRequest},
+		{name: "query secret and path", target: "/_nexo/revalidate?secret=s3cret&path=/about", wantStatus: http.StatusOK, wantMiss: []string{"/about"}},
+		{
+			name:        "JSON body",
+			target:      "/_nexo/revalidate",
+			auth:        "Bearer s3cret",
+			contentType: "application/json",
+			body:        `{"paths":["/blog/[slug]"],"tag":"site"}`,
+			wantStatus:  http.StatusOK,
+			wantMiss:    []string{"/blog/one", "/about"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getCache(app, "/blog/one")
+			getCache(app, "/about")
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(w.Body.String(), `"revalidated":true`) {
+				t.Errorf("body = %s", w.Body.String())
+			}
+			for _, path := range tt.wantMiss {
+				if got := getCache(app, path); got != "MISS" {
+					t.Errorf("%s: X-Cache = %q, want MISS", path, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRevalidateEndpoint_NoSecret(t *testing.T) {
+	t.Setenv("NEXO_REVALIDATE_SECRET", "")
+	app, _ := newRevalidateApp(t)
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_nexo/revalidate?tag=posts", nil))
+	if w.Code == http.StatusOK {
+		t.Error("revalidation endpoint served without a secret")
+	}
+}
+
+func TestCacheResponse_StaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int32
+	store := flags.NewStore(0, flags.ProviderFunc(func(context.Context) (map[string]flags.Flag, error) {
+		return map[string]flags.Flag{"beta": {Enabled: true}}, nil
+	}))
+	app := New(WithCache(NewMemoryCache(0)), WithFlags(store), WithTenantResolver(TenantFromHeader("X-Tenant-ID")))
+	app.Use(CacheResponseWithConfig(CacheResponseConfig{TTL: 200 * time.Millisecond, StaleWhileRevalidate: time.Minute}))
+	app.Get("/feed", func(c *Context) error {
+		n := version.Add(1)
+		var tenant string
+		if t := c.Tenant(); t != nil {
+			tenant = t.ID
+		}
+		return c.String(http.StatusOK, fmt.Sprintf("%s %t %s", tenant, c.Flag("beta"), strings.Repeat("v", int(n))))
+	})
+	app.Mount()
+
+	get := func() (string, string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+		req.Header.Set("X-Tenant-ID", "acme")
+		app.ServeHTTP(w, req)
+		return w.Header().Get("X-Cache"), w.Body.String()
+	}
+
+	if state, body := get(); state != "MISS" || body != "acme true v" {
+		t.Fatalf("first request: %s %q", state, body)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if state, body := get(); state != "STALE" || body != "acme true v" {
+		t.Fatalf("expired request: %s %q, want STALE %q", state, body, "acme true v")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for version.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // let the refresh store its response
+	if state, body := get(); state != "HIT" || body != "acme true vv" {
+		t.Errorf("after refresh: %s %q, want HIT %q", state, body, "acme true vv")
+	}
+}
@@ -0,0 +1,313 @@
+package nexo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouteConfig holds per-route timeout, retry and circuit-breaker settings.
+// Declare it in a route.go file and the generator applies it to every
+// handler in that file:
+//
+//	var RouteConfig = nexo.RouteConfig{
+//	    Timeout:    5 * time.Second,
+//	    MaxRetries: 2,
+//	    CircuitBreaker: &nexo.CircuitBreakerConfig{
+//	        FailureThreshold: 5,
+//	        ResetTimeout:     30 * time.Second,
+//	    },
+//	}
+type RouteConfig struct {
+	// Timeout bounds the handler's run time, including retries.
+	// The request context carries the deadline. Zero means no timeout.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a handler is re-run after it fails with a
+	// 5xx error before writing a response. Zero disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay between retries. Default is no delay.
+	RetryBackoff time.Duration
+
+	// CircuitBreaker enables a circuit breaker for the route (optional).
+	CircuitBreaker *CircuitBreakerConfig
+
+	// Priority overrides the route's calculated priority when non-zero.
+	// Higher priorities are matched first (static routes default to 100).
+	Priority int
+
+	// Coalesce deduplicates concurrent identical GET requests, so the
+	// handler runs once for all of them (see Coalesce).
+	Coalesce bool
+
+	// SkipMiddleware names inherited path middleware the route opts out
+	// of, like "/api" for the middleware.go of app/api or "auth" for
+	// middleware used with nexo:use. OnlyMiddleware names the inherited
+	// middleware the route keeps instead (see RouteTree.SkipMiddleware).
+	SkipMiddleware []string
+	OnlyMiddleware []string
+}
+
+// CircuitBreakerConfig configures a route circuit breaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit.
+	// Default is 5.
+	FailureThreshold int
+
+	// ResetTimeout is how long the circuit stays open before a trial request
+	// is allowed through. Default is 30 seconds.
+	ResetTimeout time.Duration
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests with 503 Service Unavailable.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerStats is a snapshot of a route circuit breaker.
+type CircuitBreakerStats struct {
+	Route     string       `json:"route"` // "METHOD /pattern"
+	State     CircuitState `json:"-"`
+	StateName string       `json:"state"`
+	Failures  int          `json:"consecutive_failures"`
+	Requests  uint64       `json:"requests"`
+	Rejected  uint64       `json:"rejected"`
+	Trips     uint64       `json:"trips"`
+}
+
+// CircuitBreaker tracks failures of a route and short-circuits requests
+// while the route is failing.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trialing bool
+	requests uint64
+	rejected uint64
+	trips    uint64
+	now      func() time.Time
+}
+
+// NewCircuitBreaker creates a CircuitBreaker, applying defaults to config.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.ResetTimeout <= 0 {
+		config.ResetTimeout = 30 * time.Second
+	}
+	return &CircuitBreaker{config: config, now: time.Now}
+}
+
+// Allow reports whether a request may proceed. Every allowed request must be
+// followed by a call to Record.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.requests++
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.config.ResetTimeout {
+		cb.state = CircuitHalfOpen
+	}
+
+	switch cb.state {
+	case CircuitOpen:
+		cb.rejected++
+		return false
+	case CircuitHalfOpen:
+		if cb.trialing {
+			cb.rejected++
+			return false
+		}
+		cb.trialing = true
+	}
+	return true
+}
+
+// Record records the outcome of an allowed request.
+func (cb *CircuitBreaker) Record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trialing = false
+
+	if success {
+		cb.failures = 0
+		cb.state = CircuitClosed
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.config.FailureThreshold {
+		if cb.state != CircuitOpen {
+			cb.trips++
+		}
+		cb.state = CircuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// State returns the current state.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Stats returns a snapshot of the breaker's counters.
+func (cb *CircuitBreaker) Stats() CircuitBreakerStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return CircuitBreakerStats{
+		State:     cb.state,
+		StateName: cb.state.String(),
+		Failures:  cb.failures,
+		Requests:  cb.requests,
+		Rejected:  cb.rejected,
+		Trips:     cb.trips,
+	}
+}
+
+// withRouteConfig wraps a route handler with the route's timeout, retry and
+// circuit-breaker settings. Order: breaker → timeout → retries → handler.
+func withRouteConfig(route *Route, h HandlerFunc) HandlerFunc {
+	config := route.Config
+	if config == nil {
+		return h
+	}
+
+	if config.MaxRetries > 0 {
+		h = retryHandler(h, config.MaxRetries, config.RetryBackoff)
+	}
+	if config.Timeout > 0 {
+		h = Timeout(config.Timeout)(h)
+	}
+	if config.CircuitBreaker != nil {
+		if route.breaker == nil {
+			route.breaker = NewCircuitBreaker(*config.CircuitBreaker)
+		}
+		h = breakerHandler(h, route.breaker)
+	}
+	if config.Coalesce {
+		h = Coalesce()(h)
+	}
+	return h
+}
+
+// retryHandler re-runs next when it fails with a server error before a
+// response has been written.
+func retryHandler(next HandlerFunc, maxRetries int, backoff time.Duration) HandlerFunc {
+	return func(c *Context) error {
+		var err error
+		for attempt := 0; ; attempt++ {
+			err = next(c)
+			if err == nil || c.Written() || !isServerError(err) || attempt >= maxRetries {
+				return err
+			}
+			if backoff > 0 {
+				select {
+				case <-time.After(backoff):
+				case <-c.Context().Done():
+					return err
+				}
+			}
+		}
+	}
+}
+
+// breakerHandler rejects requests with 503 while cb is open and records
+// the outcome of every request it lets through.
+func breakerHandler(next HandlerFunc, cb *CircuitBreaker) HandlerFunc {
+	return func(c *Context) error {
+		if !cb.Allow() {
+			c.SetHeader("Retry-After", fmt.Sprintf("%d", int(cb.config.ResetTimeout.Seconds())))
+			return NewHTTPError(http.StatusServiceUnavailable, "service unavailable")
+		}
+
+		err := next(c)
+		failed := (err != nil && isServerError(err)) || c.StatusCode() >= http.StatusInternalServerError
+		cb.Record(!failed)
+		return err
+	}
+}
+
+// isServerError reports whether err maps to a 5xx response.
+func isServerError(err error) bool {
+	if httpErr, ok := IsHTTPError(err); ok {
+		return httpErr.Code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// CircuitBreakerStats returns a snapshot of every route circuit breaker,
+// sorted by route.
+func (rt *RouteTree) CircuitBreakerStats() []CircuitBreakerStats {
+	var stats []CircuitBreakerStats
+	for _, route := range rt.routes {
+		if route.breaker == nil {
+			continue
+		}
+		s := route.breaker.Stats()
+		s.Route = route.Method + " " + route.Pattern
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Route < stats[j].Route
+	})
+	return stats
+}
+
+// CircuitBreakerMetrics returns a handler that exposes route circuit-breaker
+// state in the Prometheus text format. State is 0 (closed), 1 (open) or
+// 2 (half-open).
+//
+// Example:
+//
+//	app.Get("/metrics/circuit-breakers", nexo.CircuitBreakerMetrics(app.RouteTree()))
+func CircuitBreakerMetrics(rt *RouteTree) HandlerFunc {
+	return func(c *Context) error {
+		var b []byte
+		b = append(b, "# HELP nexo_circuit_breaker_state Circuit breaker state (0=closed, 1=open, 2=half-open).\n"...)
+		b = append(b, "# TYPE nexo_circuit_breaker_state gauge\n"...)
+		stats := rt.CircuitBreakerStats()
+		for _, s := range stats {
+			b = fmt.Appendf(b, "nexo_circuit_breaker_state{route=%q} %d\n", s.Route, s.State)
+		}
+		b = append(b, "# HELP nexo_circuit_breaker_rejected_total Requests rejected by an open circuit.\n"...)
+		b = append(b, "# TYPE nexo_circuit_breaker_rejected_total counter\n"...)
+		for _, s := range stats {
+			b = fmt.Appendf(b, "nexo_circuit_breaker_rejected_total{route=%q} %d\n", s.Route, s.Rejected)
+		}
+		b = append(b, "# HELP nexo_circuit_breaker_trips_total Times the circuit has opened.\n"...)
+		b = append(b, "# TYPE nexo_circuit_breaker_trips_total counter\n"...)
+		for _, s := range stats {
+			b = fmt.Appendf(b, "nexo_circuit_breaker_trips_total{route=%q} %d\n", s.Route, s.Trips)
+		}
+		return c.Blob(http.StatusOK, "text/plain; version=0.0.4", b)
+	}
+}
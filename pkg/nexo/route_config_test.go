@@ -0,0 +1,202 @@
+package nexo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, ResetTimeout: time.Minute})
+	cb.now = func() time.Time { return now }
+
+	// Closed: failures below threshold keep the circuit closed
+	if !cb.Allow() {
+		t.Fatal("closed circuit should allow requests")
+	}
+	cb.Record(false)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("state = %v, want closed", cb.State())
+	}
+
+	// Threshold reached: circuit opens
+	cb.Allow()
+	cb.Record(false)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("state = %v, want open", cb.State())
+	}
+	if cb.Allow() {
+		t.Fatal("open circuit should reject requests")
+	}
+
+	// After the reset timeout a single trial request is allowed
+	now = now.Add(time.Minute)
+	if !cb.Allow() {
+		t.Fatal("expected trial request after reset timeout")
+	}
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("state = %v, want half-open", cb.State())
+	}
+	if cb.Allow() {
+		t.Fatal("half-open circuit should allow only one trial request")
+	}
+
+	// Failed trial re-opens the circuit
+	cb.Record(false)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("state = %v, want open after failed trial", cb.State())
+	}
+
+	// Successful trial closes it
+	now = now.Add(time.Minute)
+	cb.Allow()
+	cb.Record(true)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("state = %v, want closed after successful trial", cb.State())
+	}
+
+	stats := cb.Stats()
+	if stats.Trips != 2 {
+		t.Errorf("Trips = %d, want 2", stats.Trips)
+	}
+	if stats.Rejected != 2 {
+		t.Errorf("Rejected = %d, want 2", stats.Rejected)
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	tests := map[CircuitState]string{
+		CircuitClosed:   "closed",
+		CircuitOpen:     "open",
+		CircuitHalfOpen: "half-open",
+	}
+	for state, want := range tests {
+		if got := state.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestRouteConfig_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"server error is retried", errors.New("boom"), 3},
+		{"5xx HTTPError is retried", NewHTTPError(http.StatusBadGateway, "upstream"), 3},
+		{"client error is not retried", BadRequest("bad"), 1},
+		{"success is not retried", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			app := New()
+			app.RegisterRouteWithConfig(http.MethodGet, "/flaky", func(c *Context) error {
+				calls++
+				return tt.err
+			}, RouteConfig{MaxRetries: 2})
+			app.Mount()
+
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/flaky", nil))
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_RetryStopsAfterSuccess(t *testing.T) {
+	calls := 0
+	app := New()
+	app.RegisterRouteWithConfig(http.MethodGet, "/flaky", func(c *Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("transient")
+		}
+		return c.String(http.StatusOK, "ok")
+	}, RouteConfig{MaxRetries: 5})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/flaky", nil))
+
+	if w.Code != http.StatusOK || calls != 2 {
+		t.Errorf("status = %d, calls = %d; want 200, 2", w.Code, calls)
+	}
+}
+
+func TestRouteConfig_Timeout(t *testing.T) {
+	app := New()
+	app.RegisterRouteWithConfig(http.MethodGet, "/slow", func(c *Context) error {
+		<-c.Context().Done()
+		return c.Context().Err()
+	}, RouteConfig{Timeout: 20 * time.Millisecond})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", w.Code)
+	}
+}
+
+func TestRouteConfig_CircuitBreaker(t *testing.T) {
+	app := New()
+	app.RegisterRouteWithConfig(http.MethodGet, "/down", func(c *Context) error {
+		return errors.New("down")
+	}, RouteConfig{CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, ResetTimeout: time.Minute}})
+	app.Get("/plain", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	app.Mount()
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/down", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("request %d: status = %d, want 500", i, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/down", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 from open circuit", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+	}
+
+	stats := app.RouteTree().CircuitBreakerStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for 1 route, got %d", len(stats))
+	}
+	if stats[0].Route != "GET /down" || stats[0].State != CircuitOpen || stats[0].Rejected != 1 {
+		t.Errorf("unexpected stats: %+v", stats[0])
+	}
+
+	// Metrics endpoint
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if err := CircuitBreakerMetrics(app.RouteTree())(NewContext(w, r)); err != nil {
+		t.Fatalf("metrics handler error = %v", err)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`nexo_circuit_breaker_state{route="GET /down"} 1`,
+		`nexo_circuit_breaker_rejected_total{route="GET /down"} 1`,
+		`nexo_circuit_breaker_trips_total{route="GET /down"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
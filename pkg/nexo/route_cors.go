@@ -0,0 +1,75 @@
+package nexo
+
+import (
+	"net/http"
+	"slices"
+)
+
+// SetRouteCORS applies a CORS policy to the routes of pattern, like one
+// declared with var CORS = nexo.CORSConfig{...} in a route.go. See
+// RouteTree.SetCORS.
+//
+// Example:
+//
+//	app.SetRouteCORS("/api/public", nexo.CORSConfig{
+//	    AllowOrigins: []string{"*"},
+//	})
+func (a *App) SetRouteCORS(pattern string, config CORSConfig) bool {
+	return a.routeTree.SetCORS(pattern, config)
+}
+
+// SetCORS applies a CORS policy to the routes of pattern, so public and
+// internal routes can have different policies. The policy runs before the
+// app's middleware and replaces its CORS middleware for these routes.
+// Preflight requests are answered with an OPTIONS route, unless the
+// pattern has one.
+//
+// Empty fields of config get defaults: every origin, the methods of the
+// pattern's routes and the headers of DefaultCORSConfig. It reports
+// whether any route matched; call it after registering the routes.
+func (rt *RouteTree) SetCORS(pattern string, config CORSConfig) bool {
+	var routes []*Route
+	var methods []string
+	for _, route := range rt.routes {
+		if route.Pattern == pattern && route.Host == "" {
+			routes = append(routes, route)
+			methods = append(methods, route.Method)
+		}
+	}
+	if len(routes) == 0 {
+		return false
+	}
+
+	if !slices.Contains(methods, http.MethodOptions) {
+		first := routes[0]
+		preflight := &Route{
+			Method:           http.MethodOptions,
+			Pattern:          pattern,
+			Handler:          func(c *Context) error { return c.NoContent() },
+			FilePath:         first.FilePath,
+			Scope:            first.Scope,
+			Priority:         first.Priority,
+			PriorityOverride: first.PriorityOverride,
+			CatchAllParam:    first.CatchAllParam,
+		}
+		rt.AddRoute(preflight)
+		routes = append(routes, preflight)
+		methods = append(methods, http.MethodOptions)
+	}
+
+	defaults := DefaultCORSConfig()
+	if len(config.AllowOrigins) == 0 {
+		config.AllowOrigins = defaults.AllowOrigins
+	}
+	if len(config.AllowMethods) == 0 {
+		slices.Sort(methods)
+		config.AllowMethods = methods
+	}
+	if len(config.AllowHeaders) == 0 {
+		config.AllowHeaders = defaults.AllowHeaders
+	}
+	for _, route := range routes {
+		route.CORS = &config
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetRouteCORS(t *testing.T) {
+	app := New()
+	app.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{http.MethodGet},
+		AllowCredentials: true,
+	}))
+	ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+	app.Get("/api/public", ok)
+	app.Post("/api/public", ok)
+	app.Get("/api/internal", ok)
+
+	if !app.SetRouteCORS("/api/public", CORSConfig{}) {
+		t.Fatal("SetRouteCORS() matched no route")
+	}
+	if app.SetRouteCORS("/api/missing", CORSConfig{}) {
+		t.Error("SetRouteCORS() matched a missing route")
+	}
+	app.Mount()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+		wantCreds   string
+	}{
+		{
+			name:        "route policy answers preflight",
+			method:      http.MethodOptions,
+			path:        "/api/public",
+			origin:      "https://other.example.com",
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://other.example.com",
+			wantMethods: "GET, OPTIONS, POST",
+		},
+		{
+			name:       "route policy replaces the app's",
+			method:     http.MethodGet,
+			path:       "/api/public",
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://app.example.com",
+		},
+		{
+			name:       "app policy on other routes",
+			method:     http.MethodGet,
+			path:       "/api/internal",
+			origin:     "https://other.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "app policy allows its origin",
+			method:     http.MethodGet,
+			path:       "/api/internal",
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://app.example.com",
+			wantCreds:  "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCreds {
+				t.Errorf("Allow-Credentials = %q, want %q", got, tt.wantCreds)
+			}
+		})
+	}
+}
@@ -1,10 +1,20 @@
 package nexo
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
-
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/bundler"
+	"github.com/abdul-hamid-achik/nexo/pkg/events"
+	"github.com/abdul-hamid-achik/nexo/pkg/flags"
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+	"github.com/abdul-hamid-achik/nexo/pkg/storage"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -37,28 +47,74 @@ type Route struct {
 	// Static: 100, Dynamic: 50, CatchAll: 10, OptionalCatchAll: 5
 	Priority int
 
+	// Locale is the locale of a locale-prefixed copy of a route (see LocalizeRoutes)
+	Locale string
+
+	// Host is the host pattern the route is limited to (see App.Host).
+	// Routes without a host match any host.
+	Host string
+
+	// PriorityOverride is true when Priority was set explicitly (RouteConfig,
+	// a nexo:priority directive or SetPriority) rather than calculated.
+	PriorityOverride bool
+
 	// CatchAllParam is the parameter name for catch-all routes (e.g., "slug" for [...slug]).
 	// Chi stores catch-all as "*", so we need to map it to the original param name.
+	// Patterns ending in a named catch-all, like /docs/{slug...}, don't need it.
 	CatchAllParam string
 
 	// Middlewares specific to this route
 	Middlewares []MiddlewareFunc
+
+	// SkipMiddleware names path middleware the route opts out of, and
+	// OnlyMiddleware the path middleware it keeps, skipping the others
+	// (see RouteTree.SkipMiddleware).
+	SkipMiddleware []string
+	OnlyMiddleware []string
+
+	// Config holds timeout, retry and circuit-breaker settings (optional)
+	Config *RouteConfig
+
+	// CORS is the route's CORS policy (see App.SetRouteCORS). It runs
+	// before the app's middleware and replaces its CORS middleware.
+	CORS *CORSConfig
+
+	// breaker is the route's circuit breaker (created on mount when configured)
+	breaker *CircuitBreaker
 }
 
 // RouteTree holds all discovered routes and middleware.
 type RouteTree struct {
 	routes           []*Route
-	middlewares      map[string][]MiddlewareFunc // path -> middlewares
+	middlewares      map[string][]pathMiddleware // path -> middlewares
 	middlewareScopes map[string]string           // path -> filesystem scope for route groups
 	proxy            ProxyFunc                   // proxy function (from app/proxy.go)
 	proxyConfig      *ProxyConfig                // proxy configuration (optional)
+	jsonCodec        JSONCodec                   // JSON codec for request contexts (optional)
+	i18n             *i18n.Bundle                // message catalogs for request contexts (optional)
+	secret           []byte                      // key that signs flash cookies (optional)
+	head             *HeadConfig                 // <head> defaults for request contexts (optional)
+	assets           *bundler.Manifest           // asset manifest for request contexts (optional)
+	cache            Cache                       // cache backend for request contexts (optional)
+	validator        *StructValidator            // validation rules for request contexts
+	errors           *ErrorCatalog               // error definitions for request contexts
+	proxyTrust       *proxyTrust                 // trusted proxies for request contexts (optional)
+	flags            *flags.Store                // feature flags for request contexts (optional)
+	flagKey          func(*Context) string       // key feature flags are evaluated for (optional)
+	events           *events.Bus                 // event bus for request contexts (optional)
+	storage          storage.Driver              // object storage for request contexts (optional)
+	tenancy          *tenancy                    // tenant resolution for request contexts (optional)
+	config           *liveConfig                 // current config for request contexts (optional)
+	bodyLimit        int64                       // RawBody size limit for request contexts (optional)
+	hosts            []*hostRouter               // routers of host-scoped routes (built on mount)
+	stats            *routeStats                 // request counters of the admin dashboard (optional)
 }
 
 // NewRouteTree creates a new RouteTree.
 func NewRouteTree() *RouteTree {
 	return &RouteTree{
 		routes:           make([]*Route, 0),
-		middlewares:      make(map[string][]MiddlewareFunc),
+		middlewares:      make(map[string][]pathMiddleware),
 		middlewareScopes: make(map[string]string),
 	}
 }
@@ -76,13 +132,75 @@ func (rt *RouteTree) AddRoute(route *Route) {
 //   - path: The URL path prefix (e.g., "/api", "" for root)
 //   - scope: The filesystem scope preserving route groups (e.g., "(dashboard)", "api")
 //   - mw: The middleware function
+//
+// Routes skip it by its path, like "/api" ("/" for root middleware).
 func (rt *RouteTree) AddMiddleware(path, scope string, mw MiddlewareFunc) {
-	rt.middlewares[path] = append(rt.middlewares[path], mw)
+	rt.AddNamedMiddleware(path, scope, orDefault(path, "/"), mw)
+}
+
+// AddNamedMiddleware adds middleware for a path prefix like AddMiddleware,
+// under a name routes skip it by. Generated code uses it for middleware
+// used with nexo:use, named after the registry.
+func (rt *RouteTree) AddNamedMiddleware(path, scope, name string, mw MiddlewareFunc) {
+	rt.middlewares[path] = append(rt.middlewares[path], pathMiddleware{name: name, mw: mw})
 	if scope != "" {
 		rt.middlewareScopes[path] = scope
 	}
 }
 
+// pathMiddleware is a middleware of a path prefix, with the name routes
+// skip it by.
+type pathMiddleware struct {
+	name string
+	mw   MiddlewareFunc
+}
+
+// SkipMiddleware makes the routes matching method and pattern skip the
+// path middleware with the given names, like the auth middleware of /api
+// for a login route below it. An empty method matches every method. It
+// reports whether any route matched.
+//
+// Path middleware is named by its path (see AddMiddleware) or by the name
+// it was added with (see AddNamedMiddleware). The app's global middleware
+// and the route's own always run.
+func (rt *RouteTree) SkipMiddleware(method, pattern string, names ...string) bool {
+	return rt.updateRoutes(method, pattern, func(route *Route) {
+		route.SkipMiddleware = append(route.SkipMiddleware, names...)
+	})
+}
+
+// OnlyMiddleware makes the routes matching method and pattern skip every
+// path middleware except those with the given names. See SkipMiddleware.
+func (rt *RouteTree) OnlyMiddleware(method, pattern string, names ...string) bool {
+	return rt.updateRoutes(method, pattern, func(route *Route) {
+		route.OnlyMiddleware = append(route.OnlyMiddleware, names...)
+	})
+}
+
+// updateRoutes calls update for the routes matching method and pattern,
+// and reports whether any matched. An empty method matches every method.
+func (rt *RouteTree) updateRoutes(method, pattern string, update func(*Route)) bool {
+	found := false
+	for _, route := range rt.routes {
+		if route.Pattern != pattern || (method != "" && route.Method != method) {
+			continue
+		}
+		update(route)
+		found = true
+	}
+	return found
+}
+
+// AddRouteMiddleware adds middleware to the routes matching method and
+// pattern. It runs after the path middleware, right before the handler.
+// Generated code uses it for the options of nexo:route directives. An empty
+// method matches every method. It reports whether any route matched.
+func (rt *RouteTree) AddRouteMiddleware(method, pattern string, mws ...MiddlewareFunc) bool {
+	return rt.updateRoutes(method, pattern, func(route *Route) {
+		route.Middlewares = append(route.Middlewares, mws...)
+	})
+}
+
 // SetProxy sets the proxy function and optional configuration.
 func (rt *RouteTree) SetProxy(proxy ProxyFunc, config *ProxyConfig) error {
 	rt.proxy = proxy
@@ -113,18 +231,15 @@ func (rt *RouteTree) ProxyConfiguration() *ProxyConfig {
 	return rt.proxyConfig
 }
 
-// Routes returns all registered routes (sorted by priority).
+// Routes returns all registered routes in matching order: by priority,
+// then by pattern length, pattern and method.
 func (rt *RouteTree) Routes() []*Route {
 	sorted := make([]*Route, len(rt.routes))
 	copy(sorted, rt.routes)
 
-	sort.Slice(sorted, func(i, j int) bool {
-		// Higher priority first
-		if sorted[i].Priority != sorted[j].Priority {
-			return sorted[i].Priority > sorted[j].Priority
-		}
-		// Then by pattern length (more specific first)
-		return len(sorted[i].Pattern) > len(sorted[j].Pattern)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return routeBefore(sorted[i].Priority, sorted[i].Pattern, sorted[i].Method,
+			sorted[j].Priority, sorted[j].Pattern, sorted[j].Method)
 	})
 
 	return sorted
@@ -138,7 +253,40 @@ func (rt *RouteTree) Routes() []*Route {
 //   - pattern: The URL pattern (e.g., "/api/users", "/apps")
 //   - routeScope: The filesystem scope of the route (e.g., "(dashboard)/apps", "api/users")
 func (rt *RouteTree) GetMiddlewareChain(pattern string, routeScope string) []MiddlewareFunc {
-	var chain []MiddlewareFunc
+	return middlewareFuncs(rt.pathMiddlewareChain(pattern, routeScope), nil)
+}
+
+// routeMiddlewareChain returns the path middleware of route, without the
+// middleware it skips.
+func (rt *RouteTree) routeMiddlewareChain(route *Route) []MiddlewareFunc {
+	return middlewareFuncs(rt.pathMiddlewareChain(route.Pattern, route.Scope), route)
+}
+
+// middlewareFuncs returns the functions of chain, leaving out the
+// middleware route skips when route isn't nil.
+func middlewareFuncs(chain []pathMiddleware, route *Route) []MiddlewareFunc {
+	mws := make([]MiddlewareFunc, 0, len(chain))
+	for _, pm := range chain {
+		if route != nil && route.skips(pm.name) {
+			continue
+		}
+		mws = append(mws, pm.mw)
+	}
+	return mws
+}
+
+// skips reports whether the route skips the path middleware named name.
+func (r *Route) skips(name string) bool {
+	if slices.Contains(r.SkipMiddleware, name) {
+		return true
+	}
+	return len(r.OnlyMiddleware) > 0 && !slices.Contains(r.OnlyMiddleware, name)
+}
+
+// pathMiddlewareChain returns the path middleware for pattern, from the
+// root down.
+func (rt *RouteTree) pathMiddlewareChain(pattern string, routeScope string) []pathMiddleware {
+	var chain []pathMiddleware
 
 	// First, check for root-level middleware (empty string or "/" key)
 	for _, rootKey := range []string{"", "/"} {
@@ -173,75 +321,234 @@ func (rt *RouteTree) GetMiddlewareChain(pattern string, routeScope string) []Mid
 	return chain
 }
 
-// Mount registers all routes with the chi router.
+// Mount registers all routes with the chi router. Routes with a Host are
+// mounted on a router per host pattern instead (see App.Host).
 func (rt *RouteTree) Mount(router chi.Router, globalMiddlewares []MiddlewareFunc) {
 	routes := rt.Routes()
 
 	for _, route := range routes {
-		// Build middleware chain: global -> path-based -> route-specific
-		middlewares := append([]MiddlewareFunc{}, globalMiddlewares...)
-		middlewares = append(middlewares, rt.GetMiddlewareChain(route.Pattern, route.Scope)...)
-		middlewares = append(middlewares, route.Middlewares...)
+		if route.Host == "" {
+			rt.mountRoute(router, route, globalMiddlewares)
+		}
+	}
+	rt.mountHosts(routes, globalMiddlewares)
+}
+
+// mountRoute registers a route with router.
+func (rt *RouteTree) mountRoute(router chi.Router, route *Route, globalMiddlewares []MiddlewareFunc) {
+	// Build middleware chain: global -> path-based -> route-specific
+	middlewares := append([]MiddlewareFunc{}, globalMiddlewares...)
+	middlewares = append(middlewares, rt.routeMiddlewareChain(route)...)
+	middlewares = append(middlewares, route.Middlewares...)
+	if route.CORS != nil {
+		middlewares = append([]MiddlewareFunc{CORSWithConfig(*route.CORS)}, middlewares...)
+	}
 
-		handler := rt.wrapHandler(route, middlewares)
+	handler := rt.wrapHandler(route, middlewares)
 
+	// An optional catch-all also serves its parent path, unless another
+	// route does
+	patterns := []string{chiPattern(route.Pattern)}
+	if parent, ok := optionalCatchAllParent(route.Pattern); ok && !rt.hasPattern(route.Method, route.Host, parent) {
+		patterns = append(patterns, chiPattern(parent))
+	}
+	for _, pattern := range patterns {
 		switch route.Method {
 		case http.MethodGet:
-			router.Get(route.Pattern, handler)
+			router.Get(pattern, handler)
 		case http.MethodPost:
-			router.Post(route.Pattern, handler)
+			router.Post(pattern, handler)
 		case http.MethodPut:
-			router.Put(route.Pattern, handler)
+			router.Put(pattern, handler)
 		case http.MethodPatch:
-			router.Patch(route.Pattern, handler)
+			router.Patch(pattern, handler)
 		case http.MethodDelete:
-			router.Delete(route.Pattern, handler)
+			router.Delete(pattern, handler)
 		case http.MethodHead:
-			router.Head(route.Pattern, handler)
+			router.Head(pattern, handler)
 		case http.MethodOptions:
-			router.Options(route.Pattern, handler)
+			router.Options(pattern, handler)
+		}
+	}
+}
+
+// paramTypes are the regexps of typed route parameters, like {id:int}
+// from an app/users/[id:int] directory.
+var paramTypes = map[string]string{
+	"int":  `[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// chiPattern returns pattern with its typed parameters turned into chi
+// regexp parameters, so /users/{id:int} doesn't match /users/abc and the
+// request falls through to a 404, and a {slug...} or {slug...?} catch-all
+// turned into chi's *. Other parameters, including chi's own {slug:[a-z-]+}, are kept.
+func chiPattern(pattern string) string {
+	if !strings.ContainsAny(pattern, ":.") {
+		return pattern
+	}
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if _, ok := scanner.CatchAllName(seg); ok {
+			segments[i] = "*"
+			continue
+		}
+		name, typ, ok := routeParam(seg)
+		if expr, known := paramTypes[typ]; ok && known {
+			segments[i] = "{" + name + ":" + expr + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// routeParam splits a {name} or {name:type} segment of a route pattern.
+// A {slug...} catch-all isn't one.
+func routeParam(seg string) (name, typ string, ok bool) {
+	if _, catchAll := scanner.CatchAllName(seg); catchAll || !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+		return "", "", false
+	}
+	name, typ, _ = strings.Cut(seg[1:len(seg)-1], ":")
+	return name, typ, true
+}
+
+// optionalCatchAllParent returns the parent path that the optional
+// {slug...?} catch-all of pattern also matches, /shop for /shop/{slug...?}.
+func optionalCatchAllParent(pattern string) (string, bool) {
+	i := strings.LastIndex(pattern, "/")
+	if i < 0 || !scanner.IsOptionalCatchAll(pattern[i+1:]) {
+		return "", false
+	}
+	return orDefault(pattern[:i], "/"), true
+}
+
+// hasPattern reports whether a route with method, host and pattern is
+// registered, so an optional catch-all leaves its parent path to a page or
+// route of its own.
+func (rt *RouteTree) hasPattern(method, host, pattern string) bool {
+	for _, r := range rt.routes {
+		if r.Method == method && r.Host == host && r.Pattern == pattern {
+			return true
 		}
 	}
+	return false
+}
+
+// catchAllParam returns the name of the {slug...} catch-all of pattern, if
+// it has one.
+func catchAllParam(pattern string) string {
+	name, _ := scanner.CatchAllName(pattern[strings.LastIndex(pattern, "/")+1:])
+	return name
 }
 
 // wrapHandler converts a HandlerFunc with middleware chain to http.HandlerFunc.
+// The chain is built once per route and Contexts are recycled between requests.
 func (rt *RouteTree) wrapHandler(route *Route, middlewares []MiddlewareFunc) http.HandlerFunc {
+	// Route config wraps the handler only, so middleware runs once per request
+	h := withRouteConfig(route, route.Handler)
+
+	// Build the middleware chain (apply in reverse order)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	catchAll := route.CatchAllParam
+	if catchAll == "" {
+		catchAll = catchAllParam(route.Pattern)
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := NewContext(w, r)
+		ctx := acquireContext(w, r)
+		rt.initContext(ctx)
+		ctx.locale = route.Locale
+		defer releaseContext(ctx)
 
 		// For catch-all routes, map the "*" param to the original param name
-		if route.CatchAllParam != "" {
+		if catchAll != "" {
 			if wildcardValue := chi.URLParam(r, "*"); wildcardValue != "" {
-				ctx.SetParam(route.CatchAllParam, wildcardValue)
+				ctx.SetParam(catchAll, wildcardValue)
 			}
 		}
 
-		// Build the middleware chain (apply in reverse order)
-		h := route.Handler
-		for i := len(middlewares) - 1; i >= 0; i-- {
-			h = middlewares[i](h)
+		var start time.Time
+		if rt.stats != nil {
+			start = time.Now()
 		}
 
 		// Execute the handler chain
-		if err := h(ctx); err != nil {
+		err := h(ctx)
+		if err != nil {
 			handleError(ctx, err)
 		}
+		ctx.commitBuffer()
+
+		if rt.stats != nil {
+			rt.stats.record(route, ctx, err, time.Since(start))
+		}
 	}
 }
 
+// initContext gives a request context the app's services.
+func (rt *RouteTree) initContext(c *Context) {
+	c.codec = rt.jsonCodec
+	c.i18n = rt.i18n
+	c.secret = rt.secret
+	c.headConfig = rt.head
+	c.assets = rt.assets
+	c.cache = rt.cache
+	c.validator = rt.validator
+	c.errors = rt.errors
+	c.proxyTrust = rt.proxyTrust
+	c.flags = rt.flags
+	c.flagKey = rt.flagKey
+	c.events = rt.events
+	c.storage = rt.storage
+	c.tenancy = rt.tenancy
+	c.config = rt.config
+	c.bodyLimit = rt.bodyLimit
+}
+
 // handleError handles errors returned by handlers.
 func handleError(c *Context, err error) {
+	// A buffered response that hasn't been sent is replaced by the error
+	if c.Buffered() && c.buffer.discard() {
+		c.written = false
+	}
+
 	// Don't write if response already sent
 	if c.Written() {
 		return
 	}
 
+	// Invalid input gets a problem response listing the fields
+	if errs, ok := asValidationErrors(err); ok {
+		writeValidationErrors(c, errs)
+		return
+	}
+
+	// Catalog errors get a problem response with their code
+	var catalogErr *CatalogError
+	if errors.As(err, &catalogErr) {
+		writeCatalogError(c, catalogErr)
+		return
+	}
+
 	// Check if it's an HTTPError
 	if httpErr, ok := IsHTTPError(err); ok {
 		_ = c.Error(httpErr.Code, httpErr.Message)
 		return
 	}
 
+	// Handlers that return the request context's error timed out or lost
+	// their client
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		_ = c.Error(http.StatusGatewayTimeout, "request timeout")
+		return
+	case errors.Is(err, context.Canceled):
+		_ = c.Error(StatusClientClosedRequest, "client closed request")
+		return
+	}
+
 	// Default to internal server error
 	_ = c.Error(http.StatusInternalServerError, "internal server error")
 }
@@ -249,26 +556,7 @@ func handleError(c *Context, err error) {
 // CalculatePriority calculates the priority for a route pattern.
 // Static routes have highest priority, catch-all lowest.
 func CalculatePriority(pattern string) int {
-	priority := 100
-
-	segments := strings.Split(pattern, "/")
-	for _, seg := range segments {
-		if seg == "" {
-			continue
-		}
-
-		// Catch-all (lowest priority)
-		if seg == "*" {
-			return 5
-		}
-
-		// Dynamic segment
-		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
-			priority = min(priority, 50)
-		}
-	}
-
-	return priority
+	return scanner.CalculatePriority(pattern)
 }
 
 // min returns the smaller of two ints.
@@ -1,8 +1,12 @@
 package nexo
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -220,6 +224,96 @@ func TestRouteTree_Mount_WithMiddleware(t *testing.T) {
 	}
 }
 
+func TestRouteTree_AddRouteMiddleware(t *testing.T) {
+	tree := NewRouteTree()
+	tree.AddMiddleware("/api", "api", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetPrincipal("admin")
+			return next(c)
+		}
+	})
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		tree.AddRoute(&Route{
+			Pattern:  "/api/reports",
+			Method:   method,
+			Handler:  func(c *Context) error { return c.String(http.StatusOK, "ok") },
+			Scope:    "api/reports",
+			Priority: 100,
+		})
+	}
+
+	if tree.AddRouteMiddleware(http.MethodGet, "/api/users", RequireAuth()) {
+		t.Error("AddRouteMiddleware() = true for an unregistered route")
+	}
+	if !tree.AddRouteMiddleware(http.MethodPost, "/api/reports", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			// Runs after the path middleware
+			c.SetHeader("X-Principal", fmt.Sprint(c.Principal()))
+			return next(c)
+		}
+	}) {
+		t.Fatal("AddRouteMiddleware() = false for a registered route")
+	}
+
+	router := chi.NewRouter()
+	tree.Mount(router, nil)
+
+	for method, want := range map[string]string{http.MethodPost: "admin", http.MethodGet: ""} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/api/reports", nil))
+		if got := w.Header().Get("X-Principal"); got != want {
+			t.Errorf("%s X-Principal = %q, want %q", method, got, want)
+		}
+	}
+}
+
+func TestRouteTree_SkipMiddleware(t *testing.T) {
+	tree := NewRouteTree()
+	header := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				c.AddHeader("X-Middleware", name)
+				return next(c)
+			}
+		}
+	}
+	tree.AddMiddleware("", "", header("root"))
+	tree.AddMiddleware("/api", "api", header("api"))
+	tree.AddNamedMiddleware("/api", "api", "auth", header("auth"))
+	for _, pattern := range []string{"/api/users", "/api/auth/login", "/api/health"} {
+		tree.AddRoute(&Route{
+			Pattern:  pattern,
+			Method:   http.MethodGet,
+			Handler:  func(c *Context) error { return c.String(http.StatusOK, "ok") },
+			Scope:    strings.TrimPrefix(pattern, "/"),
+			Priority: 100,
+		})
+	}
+
+	if tree.SkipMiddleware(http.MethodGet, "/api/orders", "auth") {
+		t.Error("SkipMiddleware() = true for an unregistered route")
+	}
+	if !tree.SkipMiddleware("", "/api/auth/login", "auth") || !tree.OnlyMiddleware(http.MethodGet, "/api/health", "/") {
+		t.Fatal("SkipMiddleware() = false for a registered route")
+	}
+
+	router := chi.NewRouter()
+	tree.Mount(router, nil)
+
+	tests := map[string][]string{
+		"/api/users":      {"root", "api", "auth"},
+		"/api/auth/login": {"root", "api"},
+		"/api/health":     {"root"},
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := w.Header().Values("X-Middleware"); !slices.Equal(got, want) {
+			t.Errorf("%s ran %v, want %v", path, got, want)
+		}
+	}
+}
+
 func TestRouteTree_HandleError(t *testing.T) {
 	tree := NewRouteTree()
 
@@ -456,3 +550,161 @@ func TestGetMiddlewareChain_NestedWithRouteGroup(t *testing.T) {
 		t.Errorf("expected 2 middleware for api/users, got %d", len(chain))
 	}
 }
+
+// BenchmarkRouteTree_ServeHTTP measures a routed request through the
+// middleware chain under parallel load, similar to a wrk run.
+func BenchmarkRouteTree_ServeHTTP(b *testing.B) {
+	tree := NewRouteTree()
+	tree.AddRoute(&Route{
+		Pattern: "/users/{id}",
+		Method:  http.MethodGet,
+		Handler: func(c *Context) error {
+			c.Set("id", c.Param("id"))
+			return c.NoContent()
+		},
+	})
+	router := chi.NewRouter()
+	tree.Mount(router, []MiddlewareFunc{RequestID()})
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		req := httptest.NewRequest(http.MethodGet, "/users/42?page=2", nil)
+		for pb.Next() {
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	})
+}
+
+func TestHandleError_ContextErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "deadline", err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout},
+		{name: "wrapped deadline", err: fmt.Errorf("query users: %w", context.DeadlineExceeded), wantStatus: http.StatusGatewayTimeout},
+		{name: "canceled", err: context.Canceled, wantStatus: StatusClientClosedRequest},
+		{name: "HTTP error wins", err: NewHTTPErrorWithCause(http.StatusBadGateway, "upstream", context.DeadlineExceeded), wantStatus: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handleError(NewContext(w, httptest.NewRequest(http.MethodGet, "/", nil)), tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRouteTree_Mount_CatchAllParam(t *testing.T) {
+	app := New()
+	app.Get("/docs/{slug...}", func(c *Context) error {
+		return c.String(http.StatusOK, c.Param("slug")+" "+strings.Join(c.ParamAll("slug"), ","))
+	})
+	app.Get("/files/{id:int}/{path...}", func(c *Context) error {
+		return c.String(http.StatusOK, fmt.Sprint(c.ParamInt("id", -1), " ", c.Param("path")))
+	})
+	app.Mount()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/docs/guide/intro", http.StatusOK, "guide/intro guide,intro"},
+		{"/docs/faq", http.StatusOK, "faq faq"},
+		{"/files/7/a/b.txt", http.StatusOK, "7 a/b.txt"},
+		{"/files/x/a/b.txt", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRouteTree_Mount_OptionalCatchAll(t *testing.T) {
+	app := New()
+	handler := func(c *Context) error {
+		return c.String(http.StatusOK, fmt.Sprintf("%q %d", c.Param("slug"), len(c.ParamAll("slug"))))
+	}
+	app.Get("/shop/{slug...?}", handler)
+	app.Get("/docs/{slug...}", handler)
+	app.Get("/{slug...?}", handler)
+	app.Get("/", func(c *Context) error { return c.String(http.StatusOK, "home") })
+	app.Mount()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/shop", http.StatusOK, `"" 0`},
+		{"/shop/", http.StatusOK, `"" 0`},
+		{"/shop/a", http.StatusOK, `"a" 1`},
+		{"/shop/a/b", http.StatusOK, `"a/b" 2`},
+		{"/docs/a/b", http.StatusOK, `"a/b" 2`},
+		{"/docs", http.StatusOK, `"docs" 1`},
+		{"/", http.StatusOK, "home"},
+		{"/about/team", http.StatusOK, `"about/team" 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRouteTree_Mount_TypedParams(t *testing.T) {
+	app := New()
+	app.Get("/users/{id:int}", func(c *Context) error {
+		return c.String(http.StatusOK, fmt.Sprint("user ", c.ParamInt("id", -1)))
+	})
+	app.Get("/orders/{id:uuid}", func(c *Context) error {
+		return c.String(http.StatusOK, "order "+c.ParamUUID("id").String())
+	})
+	app.Get("/tags/{slug:[a-z]+}", func(c *Context) error {
+		return c.String(http.StatusOK, "tag "+c.Param("slug"))
+	})
+	app.Mount()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/users/42", http.StatusOK, "user 42"},
+		{"/users/abc", http.StatusNotFound, ""},
+		{"/orders/6ba7b810-9dad-11d1-80b4-00c04fd430c8", http.StatusOK, "order 6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"/orders/42", http.StatusNotFound, ""},
+		{"/tags/go", http.StatusOK, "tag go"},
+		{"/tags/Go1", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body, tt.body)
+			}
+		})
+	}
+}
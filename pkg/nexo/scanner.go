@@ -2,28 +2,28 @@ package nexo
 
 import (
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/markdown"
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
 )
 
-// Scanner scans the app directory for routes and middleware.
+// Scanner scans the app directory for routes and middleware. It adapts
+// the manifest built by pkg/scanner, which holds the scanning logic shared
+// with the code generators and the CLI, to the router's types.
 type Scanner struct {
 	appDir  string
-	fset    *token.FileSet
 	verbose bool
+	cache   *scanner.Cache
 }
 
 // NewScanner creates a new Scanner for the given app directory.
 func NewScanner(appDir string) *Scanner {
 	return &Scanner{
 		appDir:  appDir,
-		fset:    token.NewFileSet(),
 		verbose: false,
 	}
 }
@@ -33,362 +33,85 @@ func (s *Scanner) SetVerbose(v bool) {
 	s.verbose = v
 }
 
-// Regular expressions for matching route segment patterns
-// Using Next.js-style bracket convention:
-//   - [param]       -> dynamic segment
-//   - [...param]    -> catch-all segment
-//   - [[...param]]  -> optional catch-all segment
-//   - (group)       -> route group (doesn't affect URL)
-var (
-	// [param] - dynamic segment
-	// Matches: [id], [userId], [post_id]
-	dynamicSegmentRe = regexp.MustCompile(`^\[([a-zA-Z_][a-zA-Z0-9_]*)\]$`)
-
-	// [...param] - catch-all segment
-	// Matches: [...slug], [...path], [...segments]
-	catchAllSegmentRe = regexp.MustCompile(`^\[\.\.\.([a-zA-Z_][a-zA-Z0-9_]*)\]$`)
-
-	// [[...param]] - optional catch-all segment
-	// Matches: [[...slug]], [[...path]]
-	optionalCatchAllRe = regexp.MustCompile(`^\[\[\.\.\.([a-zA-Z_][a-zA-Z0-9_]*)\]\]$`)
-
-	// (group) - route group (doesn't affect URL)
-	// Matches: (admin), (auth), (dashboard)
-	routeGroupRe = regexp.MustCompile(`^\(([a-zA-Z_][a-zA-Z0-9_]*)\)$`)
-)
-
-// knownPrivateFolders contains folder prefixes that are private (not routable)
-// following Next.js conventions
-var knownPrivateFolders = []string{
-	"_components",
-	"_lib",
-	"_utils",
-	"_helpers",
-	"_private",
-	"_shared",
+// SetCache makes the scanner reuse the results of files unchanged since an
+// earlier scan (see scanner.OpenCache). A nil cache parses every file.
+func (s *Scanner) SetCache(c *scanner.Cache) {
+	s.cache = c
 }
 
-// isPrivateFolder checks if a directory should be skipped during scanning.
-// Returns true for known private folders (_components, _lib, etc.)
-// but NOT for dynamic route directories ([id], [...slug], [[...cat]], (admin)).
-func isPrivateFolder(name, _ string) bool {
-	// Check if it's a known private folder (exact match)
-	for _, private := range knownPrivateFolders {
-		if name == private {
-			return true
-		}
-	}
-
-	// Dynamic routes ([id]), catch-all ([...slug]), optional catch-all ([[...cat]]),
-	// and route groups (group) are NOT private - they are routable
-	// The regex patterns will handle these in pathToRoute()
-
-	return false
+// Manifest scans the app directory and returns its manifest.
+func (s *Scanner) Manifest() (*scanner.RouteManifest, error) {
+	sc := scanner.NewScanner(s.appDir)
+	sc.SetVerbose(s.verbose)
+	sc.SetCache(s.cache)
+	m, err := sc.Manifest()
+	// A cache that can't be saved only costs the next scan its speed
+	_ = s.cache.Save()
+	return m, err
 }
 
-// HTTP method to function name mapping
-var httpMethods = map[string]string{
-	"Get":     http.MethodGet,
-	"Post":    http.MethodPost,
-	"Put":     http.MethodPut,
-	"Patch":   http.MethodPatch,
-	"Delete":  http.MethodDelete,
-	"Head":    http.MethodHead,
-	"Options": http.MethodOptions,
+// IsRouteFile reports whether name is a file that declares route handlers:
+// route.go, or a per-method file like get.go or post.go. A route directory
+// can split its handlers across per-method files, and may mix them with a
+// route.go holding shared code; all of them register under one pattern.
+func IsRouteFile(name string) bool {
+	return scanner.IsRouteFile(name)
 }
 
 // Scan walks the app directory and registers routes with the RouteTree.
 func (s *Scanner) Scan(tree *RouteTree) error {
-	// Check if app directory exists
-	if _, err := os.Stat(s.appDir); os.IsNotExist(err) {
-		// Not an error if app dir doesn't exist - just no routes
-		return nil
-	}
-
-	return filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip hidden files and directories
-		if strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip private folders
-		if info.IsDir() && isPrivateFolder(info.Name(), path) {
-			return filepath.SkipDir
-		}
-
-		// Skip non-routing files
-		if info.IsDir() {
-			return nil
-		}
-
-		// Process routing files
-		switch info.Name() {
-		case "route.go":
-			return s.registerAPIRoute(tree, path)
-		case "middleware.go":
-			return s.registerMiddleware(tree, path)
-			// Future: page.templ, layout.templ, etc.
-		}
-
-		return nil
-	})
-}
-
-// registerAPIRoute discovers and registers handlers from a route.go file.
-func (s *Scanner) registerAPIRoute(tree *RouteTree, filePath string) error {
-	// Parse the Go file
-	file, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
+	m, err := s.Manifest()
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+		return err
 	}
 
-	// Get the URL route pattern from the file path
-	pattern := s.pathToRoute(filePath)
-
-	// Get the filesystem scope (preserves route groups for middleware matching)
-	scope := s.pathToScope(filePath)
-
-	// Find all exported functions that match HTTP method names
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok {
-			continue
-		}
-
-		// Check if it's an exported function
-		if !fn.Name.IsExported() {
-			continue
-		}
-
-		// Check if the function name matches an HTTP method
-		method, ok := httpMethods[fn.Name.Name]
-		if !ok {
-			continue
-		}
-
-		// Validate the function signature: func(c *nexo.Context) error
-		if !s.isValidHandlerSignature(fn) {
-			if s.verbose {
-				fmt.Printf("  Warning: %s.%s has invalid signature, skipping\n", filePath, fn.Name.Name)
-			}
-			continue
+	// A route or middleware file that doesn't parse would silently lose
+	// its handlers
+	for _, w := range m.Warnings {
+		if name := filepath.Base(w.FilePath); IsRouteFile(name) || name == "middleware.go" {
+			return fmt.Errorf("%s: %s", w.FilePath, w.Message)
 		}
+	}
 
-		// Create a handler that will be replaced at runtime
-		// For now, we register a placeholder that the plugin system will replace
+	for _, r := range m.Routes {
+		// Register a placeholder that the plugin system will replace
 		route := &Route{
-			Pattern:  pattern,
-			Method:   method,
-			FilePath: filePath,
-			Scope:    scope,
-			Priority: CalculatePriority(pattern),
-			Handler:  s.createPlaceholderHandler(filePath, fn.Name.Name),
+			Pattern:          r.Pattern,
+			Method:           r.Method,
+			FilePath:         r.FilePath,
+			Scope:            r.Scope,
+			Priority:         r.Priority,
+			PriorityOverride: r.PriorityOverride,
+			Handler:          s.createPlaceholderHandler(r.FilePath, r.Handler),
+		}
+		if r.Options != nil {
+			route.SkipMiddleware = r.Options.Skip
+			route.OnlyMiddleware = r.Options.Only
 		}
-
 		tree.AddRoute(route)
 
 		if s.verbose {
-			fmt.Printf("  Registered: %s %s (scope: %s, file: %s)\n", method, pattern, scope, filePath)
+			fmt.Printf("  Registered: %s %s (scope: %s, file: %s)\n", r.Method, r.Pattern, r.Scope, r.FilePath)
 		}
 	}
 
-	return nil
-}
-
-// registerMiddleware discovers and registers middleware from a middleware.go file.
-func (s *Scanner) registerMiddleware(tree *RouteTree, filePath string) error {
-	// Parse the Go file
-	file, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
-	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", filePath, err)
-	}
-
-	// Get the URL path prefix (without route groups)
-	pathPrefix := s.pathToRoute(filePath)
-	if pathPrefix == "/" {
-		pathPrefix = ""
-	}
-
-	// Get the filesystem scope (preserves route groups for middleware matching)
-	scope := s.pathToScope(filePath)
-
-	// Look for the Middleware function
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok {
-			continue
-		}
-
-		// Look for Middleware function
-		if fn.Name.Name != "Middleware" {
-			continue
-		}
-
-		// Validate signature
-		if !s.isValidMiddlewareSignature(fn) {
-			if s.verbose {
-				fmt.Printf("  Warning: %s.Middleware has invalid signature, skipping\n", filePath)
-			}
-			continue
+	for _, mw := range m.Middlewares {
+		pathPrefix := mw.URLPattern
+		if pathPrefix == "/" {
+			pathPrefix = ""
 		}
 
 		// Register middleware with scope for proper route group isolation
-		tree.AddMiddleware(pathPrefix, scope, s.createPlaceholderMiddleware(filePath))
+		tree.AddNamedMiddleware(pathPrefix, mw.Scope, scanner.MiddlewareName(mw), s.createPlaceholderMiddleware(mw.FilePath))
 
 		if s.verbose {
-			fmt.Printf("  Registered middleware: %s (scope: %s, file: %s)\n", pathPrefix, scope, filePath)
+			fmt.Printf("  Registered middleware: %s (scope: %s, file: %s)\n", pathPrefix, mw.Scope, mw.FilePath)
 		}
 	}
 
 	return nil
 }
 
-// pathToRoute converts a file path to a route pattern.
-// Example: app/users/[id]/route.go -> /users/{id}
-// Example: app/docs/[...slug]/route.go -> /docs/*
-// Example: app/(admin)/settings/route.go -> /settings
-func (s *Scanner) pathToRoute(filePath string) string {
-	// Get path relative to app directory
-	rel, err := filepath.Rel(s.appDir, filepath.Dir(filePath))
-	if err != nil || rel == "." {
-		return "/"
-	}
-
-	segments := strings.Split(rel, string(filepath.Separator))
-	routeSegments := make([]string, 0, len(segments))
-
-	for _, seg := range segments {
-		// Skip route groups (group) - they don't affect the URL
-		if routeGroupRe.MatchString(seg) {
-			continue
-		}
-
-		// Handle optional catch-all [[...param]]
-		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
-			continue
-		}
-
-		// Handle catch-all [...param]
-		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
-			continue
-		}
-
-		// Handle dynamic segment [param]
-		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "{"+matches[1]+"}")
-			continue
-		}
-
-		routeSegments = append(routeSegments, seg)
-	}
-
-	if len(routeSegments) == 0 {
-		return "/"
-	}
-
-	return "/" + strings.Join(routeSegments, "/")
-}
-
-// pathToScope converts a file path to a middleware scope.
-// Unlike pathToRoute, this preserves route group markers like "(dashboard)".
-// This is used for matching middleware to routes within the same route group.
-//
-// Example: app/(dashboard)/apps/middleware.go -> "(dashboard)/apps"
-// Example: app/api/users/route.go -> "api/users"
-// Example: app/middleware.go -> ""
-func (s *Scanner) pathToScope(filePath string) string {
-	rel, err := filepath.Rel(s.appDir, filepath.Dir(filePath))
-	if err != nil || rel == "." {
-		return ""
-	}
-
-	// Normalize path separators to forward slashes
-	return strings.ReplaceAll(rel, string(filepath.Separator), "/")
-}
-
-// isValidHandlerSignature checks if a function has the signature:
-// func(c *nexo.Context) error
-func (s *Scanner) isValidHandlerSignature(fn *ast.FuncDecl) bool {
-	// Must have exactly one parameter
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	// Parameter must be a pointer type
-	param := fn.Type.Params.List[0]
-	starExpr, ok := param.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	// Check if it's a selector (package.Type) or ident (Type)
-	switch x := starExpr.X.(type) {
-	case *ast.SelectorExpr:
-		// nexo.Context or similar
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name == "nexo" && x.Sel.Name == "Context" {
-				goto checkReturn
-			}
-		}
-	case *ast.Ident:
-		// Just Context (same package import)
-		if x.Name == "Context" {
-			goto checkReturn
-		}
-	}
-	return false
-
-checkReturn:
-	// Must have exactly one return value
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	// Return must be error type
-	result := fn.Type.Results.List[0]
-	if ident, ok := result.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
-	}
-
-	return false
-}
-
-// isValidMiddlewareSignature checks if a function has the signature:
-// func() nexo.MiddlewareFunc
-func (s *Scanner) isValidMiddlewareSignature(fn *ast.FuncDecl) bool {
-	// Must have no parameters
-	if fn.Type.Params != nil && len(fn.Type.Params.List) > 0 {
-		return false
-	}
-
-	// Must have one return value
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	// Check return type is MiddlewareFunc
-	result := fn.Type.Results.List[0]
-	switch x := result.Type.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			return ident.Name == "nexo" && x.Sel.Name == "MiddlewareFunc"
-		}
-	case *ast.Ident:
-		return x.Name == "MiddlewareFunc"
-	}
-
-	return false
-}
-
 // createPlaceholderHandler creates a placeholder handler that returns an error.
 // This will be replaced by the actual handler at runtime using the plugin system
 // or code generation.
@@ -415,16 +138,27 @@ func (s *Scanner) createPlaceholderMiddleware(filePath string) MiddlewareFunc {
 
 // GetRouteInfo returns information about discovered routes (for CLI display).
 type RouteInfo struct {
-	Method   string
-	Pattern  string
-	FilePath string
-	Priority int
+	Method           string
+	Pattern          string
+	FilePath         string
+	Priority         int
+	PriorityOverride bool // Priority comes from a nexo:priority directive
+
+	// Middleware names the inherited path middleware the route runs, from
+	// the root down, and Skipped those its nexo:route skip or only option
+	// leaves out
+	Middleware []string
+	Skipped    []string
+
+	// Doc documents the route, from a Describe function in its route file
+	Doc *scanner.RouteDoc `json:",omitempty"`
 }
 
 // MiddlewareInfo holds information about discovered middleware (for CLI display).
 type MiddlewareInfo struct {
 	Path     string
 	FilePath string
+	Name     string // Registry name, for middleware used with nexo:use
 }
 
 // PageInfo holds information about a discovered page.templ file.
@@ -442,520 +176,165 @@ type LayoutInfo struct {
 
 // ScanRouteInfo scans and returns route info without registering handlers.
 func (s *Scanner) ScanRouteInfo() ([]RouteInfo, error) {
-	var routes []RouteInfo
-
-	if _, err := os.Stat(s.appDir); os.IsNotExist(err) {
-		return routes, nil
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
 	}
 
-	err := filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if info.IsDir() && isPrivateFolder(info.Name(), path) {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() || info.Name() != "route.go" {
-			return nil
-		}
-
-		file, err := parser.ParseFile(s.fset, path, nil, parser.ParseComments)
+	var routes []RouteInfo
+	for _, r := range m.Routes {
+		inherited := m.InheritedMiddleware(r)
+		kept, skipped, err := r.Options.FilterMiddleware(inherited)
 		if err != nil {
-			return nil // Skip files that can't be parsed
-		}
-
-		pattern := s.pathToRoute(path)
-
-		for _, decl := range file.Decls {
-			fn, ok := decl.(*ast.FuncDecl)
-			if !ok || !fn.Name.IsExported() {
-				continue
-			}
-
-			method, ok := httpMethods[fn.Name.Name]
-			if !ok {
-				continue
-			}
-
-			if s.isValidHandlerSignature(fn) {
-				routes = append(routes, RouteInfo{
-					Method:   method,
-					Pattern:  pattern,
-					FilePath: path,
-					Priority: CalculatePriority(pattern),
-				})
-			}
-		}
-
-		return nil
-	})
-
-	return routes, err
+			kept, skipped = inherited, nil
+		}
+		routes = append(routes, RouteInfo{
+			Method:           r.Method,
+			Pattern:          r.Pattern,
+			FilePath:         r.FilePath,
+			Priority:         r.Priority,
+			PriorityOverride: r.PriorityOverride,
+			Middleware:       kept,
+			Skipped:          skipped,
+			Doc:              r.Doc,
+		})
+	}
+	return routes, nil
 }
 
 // ScanMiddlewareInfo scans and returns middleware info without registering handlers.
 func (s *Scanner) ScanMiddlewareInfo() ([]MiddlewareInfo, error) {
-	var middlewares []MiddlewareInfo
-
-	if _, err := os.Stat(s.appDir); os.IsNotExist(err) {
-		return middlewares, nil
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
 	}
 
-	err := filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if info.IsDir() && isPrivateFolder(info.Name(), path) {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() || info.Name() != "middleware.go" {
-			return nil
-		}
-
-		file, err := parser.ParseFile(s.fset, path, nil, parser.ParseComments)
-		if err != nil {
-			return nil // Skip files that can't be parsed
-		}
-
-		pathPrefix := s.pathToRoute(path)
-
-		for _, decl := range file.Decls {
-			fn, ok := decl.(*ast.FuncDecl)
-			if !ok {
-				continue
-			}
-
-			if fn.Name.Name != "Middleware" {
-				continue
-			}
-
-			if s.isValidMiddlewareSignature(fn) {
-				middlewares = append(middlewares, MiddlewareInfo{
-					Path:     pathPrefix,
-					FilePath: path,
-				})
-			}
-		}
-
-		return nil
-	})
-
-	return middlewares, err
+	var middlewares []MiddlewareInfo
+	for _, mw := range m.Middlewares {
+		middlewares = append(middlewares, MiddlewareInfo{
+			Path:     mw.URLPattern,
+			FilePath: mw.FilePath,
+			Name:     mw.Name,
+		})
+	}
+	return middlewares, nil
 }
 
 // ScanProxyInfo scans for proxy.go in the app directory root and returns info.
 func (s *Scanner) ScanProxyInfo() (*ProxyInfo, error) {
 	proxyPath := filepath.Join(s.appDir, "proxy.go")
-
-	// Check if proxy.go exists
 	if _, err := os.Stat(proxyPath); os.IsNotExist(err) {
 		return &ProxyInfo{HasProxy: false}, nil
 	}
 
-	// Parse the file
-	file, err := parser.ParseFile(s.fset, proxyPath, nil, parser.ParseComments)
+	m, err := s.Manifest()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse %s: %w", proxyPath, err)
+		return nil, err
 	}
-
-	info := &ProxyInfo{
-		FilePath: proxyPath,
-		HasProxy: false,
+	if w, ok := m.FileWarning(proxyPath); ok {
+		return nil, fmt.Errorf("%s: %s", proxyPath, w.Message)
 	}
 
-	// Look for Proxy function and ProxyConfig variable
-	for _, decl := range file.Decls {
-		switch d := decl.(type) {
-		case *ast.FuncDecl:
-			if d.Name.Name == "Proxy" && s.isValidProxySignature(d) {
-				info.HasProxy = true
-			}
-		case *ast.GenDecl:
-			// Look for ProxyConfig variable to extract matchers
-			if d.Tok == token.VAR {
-				for _, spec := range d.Specs {
-					vs, ok := spec.(*ast.ValueSpec)
-					if !ok {
-						continue
-					}
-					for _, name := range vs.Names {
-						if name.Name == "ProxyConfig" {
-							// Try to extract Matcher strings from the composite literal
-							info.Matchers = s.extractMatchersFromSpec(vs)
-						}
-					}
-				}
-			}
-		}
+	info := &ProxyInfo{FilePath: proxyPath}
+	if m.Proxy != nil {
+		info.HasProxy = m.Proxy.HasProxy
+		info.Matchers = m.Proxy.Matchers
 	}
-
 	return info, nil
 }
 
-// isValidProxySignature checks if a function has the signature:
-// func(c *nexo.Context) (*nexo.ProxyResult, error)
-func (s *Scanner) isValidProxySignature(fn *ast.FuncDecl) bool {
-	// Must have exactly one parameter
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	// Parameter must be a pointer type (*Context)
-	param := fn.Type.Params.List[0]
-	starExpr, ok := param.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	// Check if it's a selector (package.Type) or ident (Type)
-	switch x := starExpr.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name == "nexo" && x.Sel.Name == "Context" {
-				goto checkReturn
-			}
-		}
-	case *ast.Ident:
-		if x.Name == "Context" {
-			goto checkReturn
-		}
-	}
-	return false
-
-checkReturn:
-	// Must have exactly two return values
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 2 {
-		return false
-	}
-
-	// First return must be *ProxyResult
-	result0 := fn.Type.Results.List[0]
-	starResult, ok := result0.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	switch x := starResult.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name != "nexo" || x.Sel.Name != "ProxyResult" {
-				return false
-			}
-		} else {
-			return false
-		}
-	case *ast.Ident:
-		if x.Name != "ProxyResult" {
-			return false
-		}
-	default:
-		return false
-	}
-
-	// Second return must be error
-	result1 := fn.Type.Results.List[1]
-	if ident, ok := result1.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
-	}
-
-	return false
-}
-
-// extractMatchersFromSpec extracts matcher strings from a ProxyConfig variable declaration.
-func (s *Scanner) extractMatchersFromSpec(vs *ast.ValueSpec) []string {
-	var matchers []string
-
-	if len(vs.Values) == 0 {
-		return matchers
-	}
-
-	// Look for composite literal
-	compLit, ok := vs.Values[0].(*ast.CompositeLit)
-	if !ok {
-		// Could be address of composite literal
-		if unary, ok := vs.Values[0].(*ast.UnaryExpr); ok && unary.Op == token.AND {
-			compLit, ok = unary.X.(*ast.CompositeLit)
-			if !ok {
-				return matchers
-			}
-		} else {
-			return matchers
-		}
+// ScanPageInfo scans and returns page info for all page.templ files and for
+// the Markdown pages in the content directory next to the app directory.
+// Pages in parallel route slots (@team) and intercepting directories
+// ((..)photos) render into other pages, so they are left out.
+func (s *Scanner) ScanPageInfo() ([]PageInfo, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
 	}
 
-	// Look through the elements for Matcher field
-	for _, elt := range compLit.Elts {
-		kv, ok := elt.(*ast.KeyValueExpr)
-		if !ok {
-			continue
-		}
-
-		key, ok := kv.Key.(*ast.Ident)
-		if !ok || key.Name != "Matcher" {
-			continue
-		}
-
-		// Matcher should be a slice of strings
-		sliceLit, ok := kv.Value.(*ast.CompositeLit)
-		if !ok {
+	var pages []PageInfo
+	for _, p := range m.Pages {
+		if p.Slot != "" || p.InterceptFrom != "" {
 			continue
 		}
-
-		for _, elt := range sliceLit.Elts {
-			if lit, ok := elt.(*ast.BasicLit); ok && lit.Kind == token.STRING {
-				// Remove quotes from string
-				val := strings.Trim(lit.Value, `"'`+"`")
-				matchers = append(matchers, val)
-			}
-		}
+		pages = append(pages, PageInfo{
+			Pattern:  p.URLPattern,
+			FilePath: p.FilePath,
+			Title:    p.Title,
+		})
 	}
 
-	return matchers
+	content, err := s.scanContentPages()
+	return append(pages, content...), err
 }
 
-// ScanPageInfo scans and returns page info for all page.templ files.
-func (s *Scanner) ScanPageInfo() ([]PageInfo, error) {
-	var pages []PageInfo
-
-	if _, err := os.Stat(s.appDir); os.IsNotExist(err) {
-		return pages, nil
+// scanContentPages returns the Markdown pages in the content directory next
+// to the app directory, titled by their front matter.
+func (s *Scanner) scanContentPages() ([]PageInfo, error) {
+	contentDir := filepath.Join(filepath.Dir(s.appDir), "content")
+	if _, err := os.Stat(contentDir); os.IsNotExist(err) {
+		return nil, nil
 	}
 
-	err := filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
+	var pages []PageInfo
+	err := filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-
-		if strings.HasPrefix(info.Name(), ".") {
+		if strings.HasPrefix(info.Name(), ".") || strings.HasPrefix(info.Name(), "_") {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-
-		if info.IsDir() && isPrivateFolder(info.Name(), path) {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() || info.Name() != "page.templ" {
+		if info.IsDir() || filepath.Ext(path) != ".md" {
 			return nil
 		}
 
-		// Get route pattern from file path
-		pattern := s.pathToPageRoute(path)
-
-		// Derive title from the directory name
-		title := s.derivePageTitle(path)
-
-		// Validate the page has a Page() function
-		if s.hasValidPageFunction(path) {
-			pages = append(pages, PageInfo{
-				Pattern:  pattern,
-				FilePath: path,
-				Title:    title,
-			})
-
-			if s.verbose {
-				fmt.Printf("  Found page: %s (%s) - %s\n", pattern, title, path)
-			}
-		}
-
-		return nil
-	})
-
-	return pages, err
-}
-
-// ScanLayoutInfo scans and returns layout info for all layout.templ files.
-func (s *Scanner) ScanLayoutInfo() ([]LayoutInfo, error) {
-	var layouts []LayoutInfo
-
-	if _, err := os.Stat(s.appDir); os.IsNotExist(err) {
-		return layouts, nil
-	}
-
-	err := filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
+		rel, err := filepath.Rel(contentDir, path)
 		if err != nil {
 			return err
 		}
-
-		if strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
 		}
-
-		if info.IsDir() && isPrivateFolder(info.Name(), path) {
-			return filepath.SkipDir
+		fm, _, err := markdown.ParseFrontMatter(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
 
-		if info.IsDir() || info.Name() != "layout.templ" {
-			return nil
+		pattern := markdown.ContentPattern(filepath.ToSlash(rel))
+		title := fm.Title
+		if title == "" {
+			title = toTitleCase(strings.TrimSuffix(info.Name(), ".md"))
 		}
+		pages = append(pages, PageInfo{Pattern: pattern, FilePath: path, Title: title})
 
-		// Get path prefix from file location
-		pathPrefix := s.pathToLayoutPrefix(path)
-
-		// Validate the layout has a Layout() function with children support
-		if s.hasValidLayoutFunction(path) {
-			layouts = append(layouts, LayoutInfo{
-				PathPrefix: pathPrefix,
-				FilePath:   path,
-			})
-
-			if s.verbose {
-				fmt.Printf("  Found layout: %s (%s)\n", pathPrefix, path)
-			}
+		if s.verbose {
+			fmt.Printf("  Found content page: %s (%s) - %s\n", pattern, title, path)
 		}
-
 		return nil
 	})
-
-	return layouts, err
-}
-
-// pathToPageRoute converts a page.templ file path to a route pattern.
-// Example: app/about/page.templ -> /about
-// Example: app/page.templ -> /
-// Example: app/users/[id]/page.templ -> /users/{id}
-func (s *Scanner) pathToPageRoute(filePath string) string {
-	// Get path relative to app directory
-	rel, err := filepath.Rel(s.appDir, filepath.Dir(filePath))
-	if err != nil || rel == "." {
-		return "/"
-	}
-
-	segments := strings.Split(rel, string(filepath.Separator))
-	routeSegments := make([]string, 0, len(segments))
-
-	for _, seg := range segments {
-		// Skip route groups (group) - they don't affect the URL
-		if routeGroupRe.MatchString(seg) {
-			continue
-		}
-
-		// Skip "api" directory - pages shouldn't be under api
-		if seg == "api" {
-			continue
-		}
-
-		// Handle optional catch-all [[...param]]
-		if matches := optionalCatchAllRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
-			continue
-		}
-
-		// Handle catch-all (__param)
-		if matches := catchAllSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			routeSegments = append(routeSegments, "*")
-			continue
-		}
-
-		// Handle dynamic segment (_param) - but not known private folders
-		if matches := dynamicSegmentRe.FindStringSubmatch(seg); len(matches) > 1 {
-			// Check it's not a known private folder
-			isPrivate := false
-			for _, private := range knownPrivateFolders {
-				if seg == private {
-					isPrivate = true
-					break
-				}
-			}
-			if !isPrivate {
-				routeSegments = append(routeSegments, "{"+matches[1]+"}")
-				continue
-			}
-		}
-
-		routeSegments = append(routeSegments, seg)
-	}
-
-	if len(routeSegments) == 0 {
-		return "/"
-	}
-
-	return "/" + strings.Join(routeSegments, "/")
-}
-
-// pathToLayoutPrefix converts a layout.templ file path to a path prefix.
-// Example: app/layout.templ -> /
-// Example: app/dashboard/layout.templ -> /dashboard
-// Example: app/(admin)/layout.templ -> /
-func (s *Scanner) pathToLayoutPrefix(filePath string) string {
-	// Get path relative to app directory
-	rel, err := filepath.Rel(s.appDir, filepath.Dir(filePath))
-	if err != nil || rel == "." {
-		return "/"
-	}
-
-	segments := strings.Split(rel, string(filepath.Separator))
-	routeSegments := make([]string, 0, len(segments))
-
-	for _, seg := range segments {
-		// Skip route groups (group) - they don't affect the URL
-		if routeGroupRe.MatchString(seg) {
-			continue
-		}
-
-		// Skip "api" directory
-		if seg == "api" {
-			continue
-		}
-
-		routeSegments = append(routeSegments, seg)
-	}
-
-	if len(routeSegments) == 0 {
-		return "/"
-	}
-
-	return "/" + strings.Join(routeSegments, "/")
+	return pages, err
 }
 
-// derivePageTitle derives a page title from the file path.
-// Example: app/about/page.templ -> "About"
-// Example: app/user-profile/page.templ -> "User Profile"
-// Example: app/page.templ -> "Home"
-func (s *Scanner) derivePageTitle(filePath string) string {
-	// Get the directory name
-	dir := filepath.Dir(filePath)
-	dirName := filepath.Base(dir)
-
-	// Root page
-	if dirName == "app" || dirName == "." {
-		return "Home"
+// ScanLayoutInfo scans and returns layout info for all layout.templ files.
+func (s *Scanner) ScanLayoutInfo() ([]LayoutInfo, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
 	}
 
-	// Skip route groups - use parent directory
-	if routeGroupRe.MatchString(dirName) {
-		parent := filepath.Dir(dir)
-		dirName = filepath.Base(parent)
-		if dirName == "app" || dirName == "." {
-			return "Home"
-		}
+	var layouts []LayoutInfo
+	for _, l := range m.Layouts {
+		layouts = append(layouts, LayoutInfo{
+			PathPrefix: l.PathPrefix,
+			FilePath:   l.FilePath,
+		})
 	}
-
-	// Convert to title case
-	return toTitleCase(dirName)
+	return layouts, nil
 }
 
 // toTitleCase converts a slug to title case.
@@ -977,39 +356,3 @@ func toTitleCase(s string) string {
 
 	return strings.Join(words, " ")
 }
-
-// templPageSignatureRe matches templ Page() or templ Page(params...)
-var templPageSignatureRe = regexp.MustCompile(`templ\s+Page\s*\(`)
-
-// hasValidPageFunction checks if a page.templ file has a valid Page() function.
-// A valid page must export a templ Page() component (with or without parameters).
-func (s *Scanner) hasValidPageFunction(filePath string) bool {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return false
-	}
-
-	// Look for "templ Page(" in the file content (supports both Page() and Page(params))
-	// This is a simple check - templ files aren't valid Go files so we can't use AST
-	contentStr := string(content)
-	return templPageSignatureRe.MatchString(contentStr)
-}
-
-// hasValidLayoutFunction checks if a layout.templ file has a valid Layout() function.
-// A valid layout must export a templ Layout(title string) component with { children... }.
-func (s *Scanner) hasValidLayoutFunction(filePath string) bool {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return false
-	}
-
-	contentStr := string(content)
-
-	// Check for Layout function
-	hasLayout := strings.Contains(contentStr, "templ Layout(")
-
-	// Check for children support
-	hasChildren := strings.Contains(contentStr, "{ children... }")
-
-	return hasLayout && hasChildren
-}
@@ -1,103 +1,12 @@
 package nexo
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
 	"testing"
 )
 
-func TestScanner_PathToRoute(t *testing.T) {
-	tests := []struct {
-		name     string
-		appDir   string
-		filePath string
-		want     string
-	}{
-		{
-			name:     "root route",
-			appDir:   "app",
-			filePath: "app/route.go",
-			want:     "/",
-		},
-		{
-			name:     "simple nested route",
-			appDir:   "app",
-			filePath: "app/users/route.go",
-			want:     "/users",
-		},
-		{
-			name:     "deeply nested route",
-			appDir:   "app",
-			filePath: "app/api/users/profile/route.go",
-			want:     "/api/users/profile",
-		},
-		{
-			name:     "dynamic segment",
-			appDir:   "app",
-			filePath: "app/users/[id]/route.go",
-			want:     "/users/{id}",
-		},
-		{
-			name:     "multiple dynamic segments",
-			appDir:   "app",
-			filePath: "app/orgs/[orgId]/teams/[teamId]/route.go",
-			want:     "/orgs/{orgId}/teams/{teamId}",
-		},
-		{
-			name:     "catch-all segment",
-			appDir:   "app",
-			filePath: "app/docs/[...slug]/route.go",
-			want:     "/docs/*",
-		},
-		{
-			name:     "optional catch-all",
-			appDir:   "app",
-			filePath: "app/shop/[[...categories]]/route.go",
-			want:     "/shop/*",
-		},
-		{
-			name:     "route group",
-			appDir:   "app",
-			filePath: "app/(auth)/login/route.go",
-			want:     "/login",
-		},
-		{
-			name:     "multiple route groups",
-			appDir:   "app",
-			filePath: "app/(marketing)/(landing)/about/route.go",
-			want:     "/about",
-		},
-		{
-			name:     "route group with dynamic segment",
-			appDir:   "app",
-			filePath: "app/(api)/users/[id]/route.go",
-			want:     "/users/{id}",
-		},
-		{
-			name:     "complex nested path",
-			appDir:   "app",
-			filePath: "app/(admin)/dashboard/users/[userId]/posts/[postId]/route.go",
-			want:     "/dashboard/users/{userId}/posts/{postId}",
-		},
-		{
-			name:     "api route",
-			appDir:   "app",
-			filePath: "app/api/health/route.go",
-			want:     "/api/health",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := NewScanner(tt.appDir)
-			got := s.pathToRoute(tt.filePath)
-			if got != tt.want {
-				t.Errorf("pathToRoute() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestScanner_Scan_BasicRoute(t *testing.T) {
 	// Create temp directory structure
 	tmpDir := t.TempDir()
@@ -361,6 +270,42 @@ func delete(c *nexo.Context) error {
 	}
 }
 
+func TestScanner_Scan_BodyHandler(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	testDir := filepath.Join(appDir, "users")
+
+	if err := os.MkdirAll(testDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	routeContent := `package users
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+type PostBody struct {
+	Name string
+}
+
+func Post(c *nexo.Context, body PostBody) error {
+	return nil
+}
+`
+	if err := os.WriteFile(filepath.Join(testDir, "route.go"), []byte(routeContent), 0644); err != nil {
+		t.Fatalf("Failed to write route.go: %v", err)
+	}
+
+	tree := NewRouteTree()
+	if err := NewScanner(appDir).Scan(tree); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	routes := tree.Routes()
+	if len(routes) != 1 || routes[0].Method != "POST" {
+		t.Fatalf("Expected POST body handler to be registered, got %d routes", len(routes))
+	}
+}
+
 func TestScanner_Scan_NonExistentDir(t *testing.T) {
 	scanner := NewScanner("/nonexistent/path")
 	tree := NewRouteTree()
@@ -427,6 +372,63 @@ func Delete(c *nexo.Context) error {
 	}
 }
 
+func TestScanner_ScanRouteInfo_MethodFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	usersDir := filepath.Join(tmpDir, "app", "users", "[id]")
+	if err := os.MkdirAll(usersDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	files := map[string]string{
+		"route.go":  "package id\n\nfunc helper() {}\n",
+		"get.go":    "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+		"delete.go": "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Delete(c *nexo.Context) error { return nil }\n",
+		"util.go":   "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Post(c *nexo.Context) error { return nil }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(usersDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	routes, err := NewScanner(filepath.Join(tmpDir, "app")).ScanRouteInfo()
+	if err != nil {
+		t.Fatalf("ScanRouteInfo failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, r := range routes {
+		if r.Pattern != "/users/{id}" {
+			t.Errorf("Expected pattern '/users/{id}', got '%s'", r.Pattern)
+		}
+		got[r.Method] = filepath.Base(r.FilePath)
+	}
+	want := map[string]string{"GET": "get.go", "DELETE": "delete.go"}
+	if !maps.Equal(got, want) {
+		t.Errorf("ScanRouteInfo() methods = %v, want %v", got, want)
+	}
+}
+
+func TestIsRouteFile(t *testing.T) {
+	tests := map[string]bool{
+		"route.go":      true,
+		"get.go":        true,
+		"post.go":       true,
+		"options.go":    true,
+		"Get.go":        false,
+		"get_test.go":   false,
+		"middleware.go": false,
+		"connect.go":    false,
+		".go":           false,
+		"get.templ":     false,
+	}
+	for name, want := range tests {
+		if got := IsRouteFile(name); got != want {
+			t.Errorf("IsRouteFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
 func TestCalculatePriority(t *testing.T) {
 	tests := []struct {
 		pattern  string
@@ -1003,148 +1005,6 @@ func TestScanner_SetVerbose(t *testing.T) {
 
 // ---------- Page Scanning Tests ----------
 
-func TestScanner_PathToPageRoute(t *testing.T) {
-	tests := []struct {
-		name     string
-		filePath string
-		want     string
-	}{
-		{
-			name:     "root page",
-			filePath: "app/page.templ",
-			want:     "/",
-		},
-		{
-			name:     "simple page",
-			filePath: "app/about/page.templ",
-			want:     "/about",
-		},
-		{
-			name:     "nested page",
-			filePath: "app/dashboard/settings/page.templ",
-			want:     "/dashboard/settings",
-		},
-		{
-			name:     "dynamic segment",
-			filePath: "app/users/[id]/page.templ",
-			want:     "/users/{id}",
-		},
-		{
-			name:     "catch-all",
-			filePath: "app/docs/[...slug]/page.templ",
-			want:     "/docs/*",
-		},
-		{
-			name:     "optional catch-all",
-			filePath: "app/shop/[[...categories]]/page.templ",
-			want:     "/shop/*",
-		},
-		{
-			name:     "route group",
-			filePath: "app/(marketing)/about/page.templ",
-			want:     "/about",
-		},
-		{
-			name:     "skips api directory",
-			filePath: "app/api/users/page.templ",
-			want:     "/users",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := NewScanner("app")
-			got := s.pathToPageRoute(tt.filePath)
-			if got != tt.want {
-				t.Errorf("pathToPageRoute() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestScanner_PathToLayoutPrefix(t *testing.T) {
-	tests := []struct {
-		name     string
-		filePath string
-		want     string
-	}{
-		{
-			name:     "root layout",
-			filePath: "app/layout.templ",
-			want:     "/",
-		},
-		{
-			name:     "nested layout",
-			filePath: "app/dashboard/layout.templ",
-			want:     "/dashboard",
-		},
-		{
-			name:     "route group layout",
-			filePath: "app/(admin)/layout.templ",
-			want:     "/",
-		},
-		{
-			name:     "route group with nested",
-			filePath: "app/(dashboard)/settings/layout.templ",
-			want:     "/settings",
-		},
-		{
-			name:     "deeply nested",
-			filePath: "app/admin/settings/layout.templ",
-			want:     "/admin/settings",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := NewScanner("app")
-			got := s.pathToLayoutPrefix(tt.filePath)
-			if got != tt.want {
-				t.Errorf("pathToLayoutPrefix() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestScanner_DerivePageTitle(t *testing.T) {
-	tests := []struct {
-		name     string
-		filePath string
-		want     string
-	}{
-		{
-			name:     "root page",
-			filePath: "app/page.templ",
-			want:     "Home",
-		},
-		{
-			name:     "simple page",
-			filePath: "app/about/page.templ",
-			want:     "About",
-		},
-		{
-			name:     "hyphenated",
-			filePath: "app/user-profile/page.templ",
-			want:     "User Profile",
-		},
-		{
-			name:     "underscored",
-			filePath: "app/my_dashboard/page.templ",
-			want:     "My Dashboard",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := NewScanner("app")
-			got := s.derivePageTitle(tt.filePath)
-			if got != tt.want {
-				t.Errorf("derivePageTitle() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestToTitleCase(t *testing.T) {
 	tests := []struct {
 		input string
@@ -1206,6 +1066,42 @@ templ Page() {
 	}
 }
 
+func TestScanner_ScanPageInfo_ContentPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	blogDir := filepath.Join(tmpDir, "content", "blog")
+
+	if err := os.MkdirAll(blogDir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(blogDir, "hello-world.md"), []byte("# Hello\n"), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(blogDir, "index.md"), []byte("---\ntitle: The Blog\n---\n"), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	scanner := NewScanner(appDir)
+	pages, err := scanner.ScanPageInfo()
+	if err != nil {
+		t.Fatalf("ScanPageInfo failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, p := range pages {
+		got[p.Pattern] = p.Title
+	}
+	want := map[string]string{"/blog": "The Blog", "/blog/hello-world": "Hello World"}
+	if len(got) != len(want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+	for pattern, title := range want {
+		if got[pattern] != title {
+			t.Errorf("page %s title = %q, want %q", pattern, got[pattern], title)
+		}
+	}
+}
+
 func TestScanner_ScanPageInfo_InvalidPage(t *testing.T) {
 	tmpDir := t.TempDir()
 	appDir := filepath.Join(tmpDir, "app")
@@ -1547,8 +1443,8 @@ templ Page(slug []string) {
 	}
 
 	if len(pages) > 0 {
-		if pages[0].Pattern != "/docs/*" {
-			t.Errorf("expected pattern /docs/*, got %s", pages[0].Pattern)
+		if pages[0].Pattern != "/docs/{slug...}" {
+			t.Errorf("expected pattern /docs/{slug...}, got %s", pages[0].Pattern)
 		}
 	}
 }
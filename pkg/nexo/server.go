@@ -0,0 +1,76 @@
+package nexo
+
+import (
+	"net/http"
+	"time"
+)
+
+// Default http.Server settings, used for the ServerConfig fields left zero.
+const (
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 15 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultShutdownTimeout   = 10 * time.Second
+)
+
+// ServerConfig tunes the http.Server started by Listen, under server: in
+// nexo.yaml. Zero fields keep the defaults.
+type ServerConfig struct {
+	// ReadTimeout limits reading a whole request, body included
+	// (default: 15s).
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+
+	// ReadHeaderTimeout limits reading the request headers, which stops
+	// slowloris clients (default: 5s).
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+
+	// WriteTimeout limits writing the response (default: 15s). Raise it
+	// for slow downloads; streaming handlers can extend their own deadline
+	// with http.ResponseController.
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	// IdleTimeout is how long a keep-alive connection waits for its next
+	// request (default: 60s).
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+
+	// MaxHeaderBytes caps the size of the request headers
+	// (default: http.DefaultMaxHeaderBytes, 1 MB).
+	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+
+	// ShutdownTimeout is how long Listen waits for in-flight requests after
+	// SIGINT or SIGTERM (default: 10s).
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// newServer returns the http.Server that serves the app at address, with
+// the server config applied and then the WithServer hooks.
+func (a *App) newServer(address string) *http.Server {
+	cfg := a.config.Server
+	server := &http.Server{
+		Addr:              address,
+		Handler:           a,
+		ReadTimeout:       orDefaultDuration(cfg.ReadTimeout, DefaultReadTimeout),
+		ReadHeaderTimeout: orDefaultDuration(cfg.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		WriteTimeout:      orDefaultDuration(cfg.WriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       orDefaultDuration(cfg.IdleTimeout, DefaultIdleTimeout),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+	for _, configure := range a.serverHooks {
+		configure(server)
+	}
+	return server
+}
+
+// shutdownTimeout returns how long Listen waits for in-flight requests.
+func (a *App) shutdownTimeout() time.Duration {
+	return orDefaultDuration(a.config.Server.ShutdownTimeout, DefaultShutdownTimeout)
+}
+
+// orDefaultDuration returns d, or def when d isn't positive.
+func orDefaultDuration(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,97 @@
+package nexo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_NewServer(t *testing.T) {
+	type ctxKey struct{}
+	var states []http.ConnState
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(t *testing.T, s *http.Server)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, s *http.Server) {
+				if s.ReadTimeout != DefaultReadTimeout || s.ReadHeaderTimeout != DefaultReadHeaderTimeout ||
+					s.WriteTimeout != DefaultWriteTimeout || s.IdleTimeout != DefaultIdleTimeout || s.MaxHeaderBytes != 0 {
+					t.Errorf("server = %+v, want the defaults", s)
+				}
+			},
+		},
+		{
+			name: "config",
+			opts: []Option{WithServerConfig(ServerConfig{ReadHeaderTimeout: 2 * time.Second, MaxHeaderBytes: 64 << 10})},
+			check: func(t *testing.T, s *http.Server) {
+				if s.ReadHeaderTimeout != 2*time.Second || s.MaxHeaderBytes != 64<<10 || s.IdleTimeout != DefaultIdleTimeout {
+					t.Errorf("server = %+v", s)
+				}
+			},
+		},
+		{
+			name: "hooks",
+			opts: []Option{
+				WithServerConfig(ServerConfig{WriteTimeout: time.Minute}),
+				WithServer(func(s *http.Server) {
+					s.BaseContext = func(net.Listener) context.Context {
+						return context.WithValue(context.Background(), ctxKey{}, "app")
+					}
+				}),
+				WithServer(func(s *http.Server) {
+					s.ConnState = func(_ net.Conn, state http.ConnState) { states = append(states, state) }
+					s.WriteTimeout *= 2
+				}),
+			},
+			check: func(t *testing.T, s *http.Server) {
+				if s.WriteTimeout != 2*time.Minute {
+					t.Errorf("WriteTimeout = %v, want hooks to run after the config", s.WriteTimeout)
+				}
+				if s.BaseContext == nil || s.BaseContext(nil).Value(ctxKey{}) != "app" {
+					t.Error("BaseContext hook not applied")
+				}
+				s.ConnState(nil, http.StateNew)
+				if len(states) != 1 {
+					t.Error("ConnState hook not applied")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New(tt.opts...)
+			s := app.newServer(":0")
+			if s.Addr != ":0" || s.Handler != app {
+				t.Fatalf("server = %+v", s)
+			}
+			tt.check(t, s)
+		})
+	}
+}
+
+func TestLoadConfig_Server(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "server:\n  read_header_timeout: 3s\n  idle_timeout: 2m\n  max_header_bytes: 32768\n  shutdown_timeout: 30s\n"
+	if err := os.WriteFile(filepath.Join(dir, "nexo.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ServerConfig{ReadHeaderTimeout: 3 * time.Second, IdleTimeout: 2 * time.Minute, MaxHeaderBytes: 32768, ShutdownTimeout: 30 * time.Second}
+	if config.Server != want {
+		t.Errorf("Server = %+v, want %+v", config.Server, want)
+	}
+	if got := New(WithConfig(config)).shutdownTimeout(); got != 30*time.Second {
+		t.Errorf("shutdownTimeout() = %v, want 30s", got)
+	}
+}
@@ -0,0 +1,339 @@
+package nexo
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+)
+
+// SitemapConfig configures the /sitemap.xml handler, under sitemap: in
+// nexo.yaml.
+type SitemapConfig struct {
+	// Enabled serves the sitemap of the pages registered with App.Sitemap.
+	Enabled bool `mapstructure:"enabled"`
+
+	// BaseURL prefixes every page, e.g. "https://acme.com". Without it the
+	// scheme and host of the request are used.
+	BaseURL string `mapstructure:"base_url"`
+
+	// Path is where the sitemap is served (default: /sitemap.xml).
+	Path string `mapstructure:"path"`
+}
+
+// RobotsConfig configures the /robots.txt handler, under robots: in nexo.yaml.
+type RobotsConfig struct {
+	// Enabled serves robots.txt.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Rules are the robots.txt groups. Without rules every crawler is
+	// allowed everywhere.
+	Rules []RobotsRule `mapstructure:"rules"`
+}
+
+// RobotsRule is a group of robots.txt directives for one user agent.
+type RobotsRule struct {
+	UserAgent string   `mapstructure:"user_agent"`
+	Allow     []string `mapstructure:"allow"`
+	Disallow  []string `mapstructure:"disallow"`
+}
+
+// StaticParamsFunc lists the parameter values of a dynamic page, one map per
+// URL, for the sitemap. Page packages declare it as GenerateStaticParams:
+//
+//	// app/posts/[slug]/params.go
+//	func GenerateStaticParams(ctx context.Context) ([]map[string]string, error) {
+//	    posts, err := db.ListPosts(ctx)
+//	    ...
+//	    for _, p := range posts {
+//	        params = append(params, map[string]string{"slug": p.Slug})
+//	    }
+//	    return params, nil
+//	}
+type StaticParamsFunc func(ctx context.Context) ([]map[string]string, error)
+
+// SitemapOptions annotates a page in the sitemap. Generated route files set
+// them from a nexo:sitemap directive in page.templ:
+//
+//	// nexo:sitemap changefreq=weekly priority=0.8
+type SitemapOptions struct {
+	// ChangeFreq is always, hourly, daily, weekly, monthly, yearly or never.
+	ChangeFreq string
+
+	// Priority is the page's priority from 0.0 to 1.0 (0 omits it).
+	Priority float64
+
+	// LastMod is when the page last changed (zero omits it).
+	LastMod time.Time
+
+	// Params lists the URLs of a dynamic page. Dynamic pages without it
+	// are left out.
+	Params StaticParamsFunc
+}
+
+// SitemapEntry is a URL in the sitemap.
+type SitemapEntry struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// Sitemap enumerates the pages of an app for sitemap.xml. It is safe for
+// concurrent use.
+type Sitemap struct {
+	mu    sync.RWMutex
+	pages []sitemapPage
+}
+
+// sitemapPage is a page pattern added to a Sitemap.
+type sitemapPage struct {
+	pattern string
+	opts    SitemapOptions
+}
+
+// NewSitemap creates an empty Sitemap.
+func NewSitemap() *Sitemap {
+	return &Sitemap{}
+}
+
+// Add adds the page with pattern. Dynamic patterns (/posts/{slug}) are
+// expanded with opts.Params and skipped without it.
+func (s *Sitemap) Add(pattern string, opts SitemapOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = append(s.pages, sitemapPage{pattern: pattern, opts: opts})
+}
+
+// Entries returns the sitemap's URLs, prefixed with baseURL and sorted.
+func (s *Sitemap) Entries(ctx context.Context, baseURL string) ([]SitemapEntry, error) {
+	s.mu.RLock()
+	pages := append([]sitemapPage(nil), s.pages...)
+	s.mu.RUnlock()
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	seen := make(map[string]bool)
+	var entries []SitemapEntry
+	add := func(path string, opts SitemapOptions) {
+		loc := baseURL + path
+		if seen[loc] {
+			return
+		}
+		seen[loc] = true
+		entries = append(entries, SitemapEntry{
+			Loc:        loc,
+			LastMod:    opts.LastMod,
+			ChangeFreq: opts.ChangeFreq,
+			Priority:   opts.Priority,
+		})
+	}
+
+	for _, p := range pages {
+		if !isDynamicPattern(p.pattern) {
+			add(p.pattern, p.opts)
+			continue
+		}
+		if p.opts.Params == nil {
+			continue
+		}
+		params, err := p.opts.Params(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sitemap: %s: %w", p.pattern, err)
+		}
+		for _, values := range params {
+			path, ok := expandPattern(p.pattern, values)
+			if ok {
+				add(path, p.opts)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Loc < entries[j].Loc })
+	return entries, nil
+}
+
+// WriteXML writes the sitemap as sitemap.xml.
+func (s *Sitemap) WriteXML(ctx context.Context, w io.Writer, baseURL string) error {
+	entries, err := s.Entries(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, e := range entries {
+		b.WriteString("  <url><loc>")
+		_ = xml.EscapeText(&b, []byte(e.Loc))
+		b.WriteString("</loc>")
+		if !e.LastMod.IsZero() {
+			b.WriteString("<lastmod>" + e.LastMod.UTC().Format("2006-01-02") + "</lastmod>")
+		}
+		if e.ChangeFreq != "" {
+			b.WriteString("<changefreq>")
+			_ = xml.EscapeText(&b, []byte(e.ChangeFreq))
+			b.WriteString("</changefreq>")
+		}
+		if e.Priority > 0 {
+			b.WriteString("<priority>" + strconv.FormatFloat(e.Priority, 'f', 1, 64) + "</priority>")
+		}
+		b.WriteString("</url>\n")
+	}
+	b.WriteString("</urlset>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// Handler returns a handler serving the sitemap. An empty baseURL uses the
+// request's scheme and host.
+func (s *Sitemap) Handler(baseURL string) HandlerFunc {
+	return func(c *Context) error {
+		base := baseURL
+		if base == "" {
+			base = requestOrigin(c.Request)
+		}
+		var b strings.Builder
+		if err := s.WriteXML(c.Request.Context(), &b, base); err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "application/xml; charset=utf-8", []byte(b.String()))
+	}
+}
+
+// isDynamicPattern reports whether pattern has parameters.
+func isDynamicPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "{*")
+}
+
+// expandPattern fills the parameters of pattern from values. A {slug...}
+// catch-all takes the slug value and a * the value whose name isn't used by
+// a {param}; their slashes are kept. An optional {slug...?} without a value
+// expands to the parent path. It reports false when a parameter has no
+// value.
+func expandPattern(pattern string, values map[string]string) (string, bool) {
+	used := make(map[string]bool)
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if name, _, ok := routeParam(seg); ok {
+			v, ok := values[name]
+			if !ok || v == "" {
+				return "", false
+			}
+			used[name] = true
+			segments[i] = url.PathEscape(v)
+		}
+	}
+	for i, seg := range segments {
+		catchAll, ok := scanner.CatchAllName(seg)
+		if !ok {
+			continue
+		}
+		rest := values[catchAll]
+		if catchAll == "" {
+			for name, v := range values {
+				if !used[name] {
+					rest = v
+					break
+				}
+			}
+		}
+		if rest == "" && scanner.IsOptionalCatchAll(seg) {
+			return orDefault(strings.Join(segments[:i], "/"), "/"), true
+		}
+		parts := strings.Split(strings.Trim(rest, "/"), "/")
+		for j, p := range parts {
+			parts[j] = url.PathEscape(p)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// requestOrigin returns the scheme and host of r, e.g. "https://acme.com".
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// RobotsTxt renders robots.txt for rules, with a Sitemap line when
+// sitemapURL is set.
+func RobotsTxt(rules []RobotsRule, sitemapURL string) string {
+	if len(rules) == 0 {
+		rules = []RobotsRule{{UserAgent: "*", Allow: []string{"/"}}}
+	}
+
+	var b strings.Builder
+	for i, r := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("User-agent: " + orDefault(r.UserAgent, "*") + "\n")
+		for _, p := range r.Allow {
+			b.WriteString("Allow: " + p + "\n")
+		}
+		for _, p := range r.Disallow {
+			b.WriteString("Disallow: " + p + "\n")
+		}
+	}
+	if sitemapURL != "" {
+		b.WriteString("\nSitemap: " + sitemapURL + "\n")
+	}
+	return b.String()
+}
+
+// ---------- App Sitemap ----------
+
+// Sitemap returns the app's sitemap. Generated route files add every page
+// to it; it is served when enabled with WithSitemap or sitemap.enabled.
+func (a *App) Sitemap() *Sitemap {
+	if a.sitemap == nil {
+		a.sitemap = NewSitemap()
+	}
+	return a.sitemap
+}
+
+// mountSEO registers the sitemap and robots.txt handlers enabled in the
+// config, unless the app already has routes for them.
+func (a *App) mountSEO() {
+	sitemapPath := orDefault(a.config.Sitemap.Path, "/sitemap.xml")
+
+	if a.config.Sitemap.Enabled && !a.hasRoute(http.MethodGet, sitemapPath) {
+		a.Get(sitemapPath, a.Sitemap().Handler(a.config.Sitemap.BaseURL))
+	}
+
+	if a.config.Robots.Enabled && !a.hasRoute(http.MethodGet, "/robots.txt") {
+		rules := a.config.Robots.Rules
+		sitemap := a.config.Sitemap
+		a.Get("/robots.txt", func(c *Context) error {
+			var sitemapURL string
+			if sitemap.Enabled {
+				sitemapURL = strings.TrimSuffix(orDefault(sitemap.BaseURL, requestOrigin(c.Request)), "/") + sitemapPath
+			}
+			return c.String(http.StatusOK, RobotsTxt(rules, sitemapURL))
+		})
+	}
+}
+
+// hasRoute reports whether a route with method and pattern is registered
+// for every host.
+func (a *App) hasRoute(method, pattern string) bool {
+	for _, r := range a.routeTree.routes {
+		if r.Method == method && r.Pattern == pattern && r.Host == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package nexo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		values  map[string]string
+		want    string
+		ok      bool
+	}{
+		{"/posts/{slug}", map[string]string{"slug": "hello world"}, "/posts/hello%20world", true},
+		{"/{org}/{repo}", map[string]string{"org": "acme", "repo": "nexo"}, "/acme/nexo", true},
+		{"/docs/*", map[string]string{"path": "guide/intro"}, "/docs/guide/intro", true},
+		{"/{lang}/docs/{slug...}", map[string]string{"slug": "guide/intro", "lang": "en"}, "/en/docs/guide/intro", true},
+		{"/shop/{slug...?}", map[string]string{"slug": "a/b"}, "/shop/a/b", true},
+		{"/shop/{category}/{slug...?}", map[string]string{"category": "toys"}, "/shop/toys", true},
+		{"/posts/{slug}", map[string]string{"id": "1"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, ok := expandPattern(tt.pattern, tt.values)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("expandPattern() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSitemap_WriteXML(t *testing.T) {
+	s := NewSitemap()
+	s.Add("/", SitemapOptions{ChangeFreq: "daily", Priority: 1})
+	s.Add("/about", SitemapOptions{LastMod: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)})
+	s.Add("/posts/{slug}", SitemapOptions{
+		Priority: 0.6,
+		Params: func(ctx context.Context) ([]map[string]string, error) {
+			return []map[string]string{{"slug": "b"}, {"slug": "a"}}, nil
+		},
+	})
+	s.Add("/users/{id}", SitemapOptions{}) // dynamic without Params: skipped
+
+	var b strings.Builder
+	if err := s.WriteXML(context.Background(), &b, "https://acme.test/"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://acme.test/</loc><changefreq>daily</changefreq><priority>1.0</priority></url>
+  <url><loc>https://acme.test/about</loc><lastmod>2026-03-01</lastmod></url>
+  <url><loc>https://acme.test/posts/a</loc><priority>0.6</priority></url>
+  <url><loc>https://acme.test/posts/b</loc><priority>0.6</priority></url>
+</urlset>
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteXML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSitemap_ParamsError(t *testing.T) {
+	errDB := errors.New("db down")
+	s := NewSitemap()
+	s.Add("/posts/{slug}", SitemapOptions{
+		Params: func(ctx context.Context) ([]map[string]string, error) { return nil, errDB },
+	})
+
+	if _, err := s.Entries(context.Background(), ""); !errors.Is(err, errDB) {
+		t.Errorf("err = %v, want %v", err, errDB)
+	}
+}
+
+func TestRobotsTxt(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []RobotsRule
+		sitemap string
+		want    string
+	}{
+		{
+			name: "default",
+			want: "User-agent: *\nAllow: /\n",
+		},
+		{
+			name: "rules and sitemap",
+			rules: []RobotsRule{
+				{UserAgent: "*", Disallow: []string{"/admin", "/api"}},
+				{UserAgent: "GPTBot", Disallow: []string{"/"}},
+			},
+			sitemap: "https://acme.test/sitemap.xml",
+			want: "User-agent: *\nDisallow: /admin\nDisallow: /api\n\n" +
+				"User-agent: GPTBot\nDisallow: /\n\n" +
+				"Sitemap: https://acme.test/sitemap.xml\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RobotsTxt(tt.rules, tt.sitemap); got != tt.want {
+				t.Errorf("RobotsTxt() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_SitemapAndRobots(t *testing.T) {
+	app := New(WithSitemap(""), WithRobots())
+	app.Sitemap().Add("/about", SitemapOptions{})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://acme.test/sitemap.xml", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<loc>http://acme.test/about</loc>") {
+		t.Errorf("sitemap.xml = %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://acme.test/robots.txt", nil))
+	want := "User-agent: *\nAllow: /\n\nSitemap: http://acme.test/sitemap.xml\n"
+	if w.Body.String() != want {
+		t.Errorf("robots.txt = %q, want %q", w.Body.String(), want)
+	}
+}
@@ -1,9 +1,10 @@
 package nexo
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // SSEWriter provides methods for streaming Server-Sent Events.
@@ -30,6 +31,7 @@ type SSEWriter struct {
 	w       http.ResponseWriter
 	flusher http.Flusher
 	closed  bool
+	codec   JSONCodec
 }
 
 // Send sends an SSE event with an optional event type.
@@ -80,11 +82,16 @@ func (s *SSEWriter) SendJSON(event string, data any) error {
 		return fmt.Errorf("sse: connection closed")
 	}
 
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
+	codec := s.codec
+	if codec == nil {
+		codec = defaultJSONCodec
+	}
+
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, data); err != nil {
 		return fmt.Errorf("sse: failed to marshal JSON: %w", err)
 	}
-	return s.Send(event, string(jsonBytes))
+	return s.Send(event, strings.TrimRight(buf.String(), "\n"))
 }
 
 // SendComment sends an SSE comment.
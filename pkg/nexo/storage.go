@@ -0,0 +1,178 @@
+package nexo
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/storage"
+)
+
+// ErrNoStorage is returned by the storage helpers of Context when the app
+// has no object storage.
+var ErrNoStorage = errors.New("nexo: no object storage (set storage.driver in nexo.yaml or use WithStorage)")
+
+// StorageConfig configures the app's object storage, under storage: in
+// nexo.yaml. Environment variables in the credentials are expanded, e.g.
+// ${AWS_SECRET_ACCESS_KEY}.
+type StorageConfig struct {
+	// Driver is "local", "s3" or "gcs" (default: no storage).
+	Driver string `mapstructure:"driver"`
+
+	// Dir is the directory of the local driver (default: "storage").
+	Dir string `mapstructure:"dir"`
+
+	// BaseURL is where the local driver serves signed URLs (default:
+	// "/storage").
+	BaseURL string `mapstructure:"base_url"`
+
+	// Secret signs the local driver's URLs (default: random per process).
+	Secret string `mapstructure:"secret"`
+
+	// Bucket, Region and Endpoint locate the bucket of the s3 and gcs
+	// drivers. Endpoint selects an S3-compatible service like MinIO or R2.
+	Bucket   string `mapstructure:"bucket"`
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint"`
+
+	// AccessKey and SecretKey are the bucket credentials (HMAC keys for
+	// gcs).
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+
+	// PathStyle addresses the bucket in the URL path, as MinIO needs.
+	PathStyle bool `mapstructure:"path_style"`
+}
+
+// openAppStorage opens the object storage configured for the app. It
+// returns nil when none is configured or the configuration is invalid.
+func openAppStorage(config StorageConfig) storage.Driver {
+	if config.Driver == "" {
+		return nil
+	}
+	driver, err := storage.Open(storage.Config{
+		Driver:    config.Driver,
+		Dir:       config.Dir,
+		BaseURL:   config.BaseURL,
+		Secret:    os.ExpandEnv(config.Secret),
+		Bucket:    config.Bucket,
+		Region:    config.Region,
+		Endpoint:  config.Endpoint,
+		AccessKey: os.ExpandEnv(config.AccessKey),
+		SecretKey: os.ExpandEnv(config.SecretKey),
+		PathStyle: config.PathStyle,
+	})
+	if err != nil {
+		log.Printf("nexo: %v; object storage is off", err)
+		return nil
+	}
+	return driver
+}
+
+// mountStorage serves the signed URLs of a local driver. It is registered
+// on the router directly, since the URLs carry their own authorization.
+func (a *App) mountStorage() {
+	local, ok := a.routeTree.storage.(*storage.Local)
+	if !ok {
+		return
+	}
+	a.router.Handle(local.BasePath()+"/*", local)
+}
+
+// Storage returns the app's object storage (see WithStorage), or nil.
+func (a *App) Storage() storage.Driver {
+	return a.routeTree.storage
+}
+
+// Storage returns the app's object storage, or nil when the app has none.
+func (c *Context) Storage() storage.Driver {
+	return c.storage
+}
+
+// StoreFile streams the uploaded file of a multipart form field to the
+// object storage at key.
+//
+// Example:
+//
+//	obj, err := c.StoreFile("avatar", "avatars/"+user.ID+".png")
+func (c *Context) StoreFile(field, key string) (*storage.Object, error) {
+	if c.storage == nil {
+		return nil, ErrNoStorage
+	}
+	file, header, err := c.Request.FormFile(field)
+	if err != nil {
+		return nil, NewHTTPErrorWithCause(http.StatusBadRequest, "missing file "+field, err)
+	}
+	defer file.Close()
+	return c.storage.Put(c.Context(), key, file, storage.PutOptions{
+		ContentType: header.Header.Get("Content-Type"),
+	})
+}
+
+// StoreBody streams the request body to the object storage at key, with
+// the request's Content-Type, e.g. for a PUT of a raw file.
+func (c *Context) StoreBody(key string) (*storage.Object, error) {
+	if c.storage == nil {
+		return nil, ErrNoStorage
+	}
+	if c.Request.Body == nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "empty request body")
+	}
+	return c.storage.Put(c.Context(), key, c.Request.Body, storage.PutOptions{
+		ContentType: c.Header("Content-Type"),
+	})
+}
+
+// SendObject streams the object at key from the object storage as the
+// response, with a 404 when it doesn't exist. Objects of the local driver
+// are served with range and conditional request support.
+func (c *Context) SendObject(key string) error {
+	if c.storage == nil {
+		return ErrNoStorage
+	}
+	body, obj, err := c.storage.Get(c.Context(), key)
+	if errors.Is(err, storage.ErrNotExist) {
+		return NewHTTPErrorWithCause(http.StatusNotFound, "not found", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	c.SetHeader("Content-Type", obj.ContentType)
+	if obj.ETag != "" {
+		c.SetHeader("ETag", obj.ETag)
+	}
+	c.written = true
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(c.Response, c.Request, key, obj.ModTime, seeker)
+		return nil
+	}
+	if obj.Size >= 0 {
+		c.SetHeader("Content-Length", strconv.FormatInt(obj.Size, 10))
+	}
+	if !obj.ModTime.IsZero() {
+		c.SetHeader("Last-Modified", obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+	c.Response.WriteHeader(http.StatusOK)
+	c.status = http.StatusOK
+	_, err = io.Copy(c.Response, body)
+	return err
+}
+
+// RedirectToObject redirects to a signed URL of the object at key, valid
+// for expires, so the client downloads it from the storage directly.
+func (c *Context) RedirectToObject(key string, expires time.Duration) error {
+	if c.storage == nil {
+		return ErrNoStorage
+	}
+	url, err := c.storage.SignedURL(c.Context(), key, storage.SignOptions{Expires: expires})
+	if err != nil {
+		return err
+	}
+	return c.Redirect(url, http.StatusFound)
+}
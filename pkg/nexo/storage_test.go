@@ -0,0 +1,114 @@
+package nexo
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/storage"
+)
+
+func TestContext_Storage(t *testing.T) {
+	local, err := storage.NewLocal(t.TempDir(), "/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := New(WithStorage(local))
+	app.Post("/avatars/{id}", func(c *Context) error {
+		obj, err := c.StoreFile("avatar", "avatars/"+c.Param("id")+".png")
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, obj)
+	})
+	app.Put("/raw/{name}", func(c *Context) error {
+		if _, err := c.StoreBody("raw/" + c.Param("name")); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Get("/avatars/{id}", func(c *Context) error {
+		return c.SendObject("avatars/" + c.Param("id") + ".png")
+	})
+	app.Get("/avatars/{id}/url", func(c *Context) error {
+		return c.RedirectToObject("avatars/"+c.Param("id")+".png", 0)
+	})
+	app.Mount()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("avatar", "me.png")
+	fw.Write([]byte("png bytes"))
+	mw.Close()
+	r := httptest.NewRequest("POST", "/avatars/42", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"key":"avatars/42.png"`) {
+		t.Fatalf("StoreFile: %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("PUT", "/raw/notes.txt", strings.NewReader("notes")))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("StoreBody: %d %s", w.Code, w.Body)
+	}
+	if obj, err := local.Stat(t.Context(), "raw/notes.txt"); err != nil || obj.Size != 5 {
+		t.Errorf("stored body = %+v, %v", obj, err)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/avatars/42", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "png bytes" || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("SendObject: %d %q %v", w.Code, w.Body, w.Header())
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/avatars/7", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("SendObject of a missing object: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/avatars/42/url", nil))
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusFound || !strings.HasPrefix(location, "/files/avatars/42.png?") {
+		t.Fatalf("RedirectToObject: %d %q", w.Code, location)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", location, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "png bytes" {
+		t.Errorf("signed URL: %d %q", w.Code, w.Body)
+	}
+}
+
+func TestContext_StorageNone(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if c.Storage() != nil {
+		t.Fatal("Storage() is set without a configured driver")
+	}
+	if err := c.SendObject("a.txt"); !errors.Is(err, ErrNoStorage) {
+		t.Errorf("SendObject() error = %v, want ErrNoStorage", err)
+	}
+}
+
+func TestOpenAppStorage(t *testing.T) {
+	t.Setenv("TEST_STORAGE_SECRET", "s3cr3t")
+	tests := []struct {
+		config StorageConfig
+		want   bool
+	}{
+		{StorageConfig{}, false},
+		{StorageConfig{Driver: "local", Dir: t.TempDir()}, true},
+		{StorageConfig{Driver: "s3", Bucket: "media", AccessKey: "key", SecretKey: "${TEST_STORAGE_SECRET}"}, true},
+		{StorageConfig{Driver: "s3", Bucket: "media"}, false},
+	}
+	for _, tt := range tests {
+		if got := openAppStorage(tt.config) != nil; got != tt.want {
+			t.Errorf("openAppStorage(%+v) opened = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
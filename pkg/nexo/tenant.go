@@ -0,0 +1,391 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------- Tenancy ----------
+
+// Tenant is the customer a request belongs to in a multi-tenant app.
+type Tenant struct {
+	// ID identifies the tenant, like "acme".
+	ID string
+
+	// Settings overrides app settings for the tenant, from tenancy.tenants
+	// in nexo.yaml or a TenantLookup. Read them with String, Int and Bool.
+	Settings map[string]any
+
+	// Data holds what a TenantLookup loaded, e.g. the tenant's row.
+	Data any
+}
+
+// Setting returns the tenant's setting key. Keys are case-insensitive and
+// dots reach into nested settings, like "theme.color".
+func (t *Tenant) Setting(key string) (any, bool) {
+	if t == nil {
+		return nil, false
+	}
+	return lookupSetting(t.Settings, key)
+}
+
+// String returns the setting key as a string, or def if it is unset.
+func (t *Tenant) String(key, def string) string {
+	if v, ok := t.Setting(key); ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return def
+}
+
+// Int returns the setting key as an int, or def if it is unset or not a
+// number.
+func (t *Tenant) Int(key string, def int) int {
+	v, _ := t.Setting(key)
+	return settingInt(v, def)
+}
+
+// Bool returns the setting key as a bool, or def if it is unset or not a
+// boolean.
+func (t *Tenant) Bool(key string, def bool) bool {
+	v, _ := t.Setting(key)
+	return settingBool(v, def)
+}
+
+// TenantResolver returns the ID of the tenant a request belongs to, or ""
+// when it names none.
+type TenantResolver func(c *Context) string
+
+// TenantLookup loads the tenant with the given ID, e.g. from the database.
+// It returns nil for unknown tenants.
+type TenantLookup func(ctx context.Context, id string) (*Tenant, error)
+
+// TenantFromSubdomain resolves the tenant from the subdomain of domain, so
+// acme.example.com is tenant "acme" for domain "example.com". Requests to
+// domain itself, www and other domains name no tenant.
+func TenantFromSubdomain(domain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(domain, "."))
+	return func(c *Context) string {
+		host := strings.ToLower(stripPort(c.Request.Host))
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || sub == "www" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// TenantFromHostParam resolves the tenant from a parameter of the route's
+// host pattern, like "tenant" for App.Host("{tenant}.example.com").
+func TenantFromHostParam(name string) TenantResolver {
+	return func(c *Context) string {
+		return c.HostParam(name)
+	}
+}
+
+// TenantFromHeader resolves the tenant from a request header, like
+// X-Tenant-ID.
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *Context) string {
+		return strings.TrimSpace(c.Header(name))
+	}
+}
+
+// TenantFromPath resolves the tenant from a route parameter, like
+// "tenant" for routes under app/t/[tenant].
+func TenantFromPath(param string) TenantResolver {
+	return func(c *Context) string {
+		return c.Param(param)
+	}
+}
+
+// FirstTenant tries resolvers in order and returns the first tenant found.
+func FirstTenant(resolvers ...TenantResolver) TenantResolver {
+	return func(c *Context) string {
+		for _, resolve := range resolvers {
+			if id := resolve(c); id != "" {
+				return id
+			}
+		}
+		return ""
+	}
+}
+
+// TenancyConfig configures multi-tenancy, under tenancy: in nexo.yaml.
+// Tenancy is on when Resolve lists a resolver or WithTenantResolver is
+// used.
+type TenancyConfig struct {
+	// Resolve lists where the tenant is read from, tried in order:
+	// "subdomain" (of Domain), "host" (the Param host parameter),
+	// "header" (Header) and "path" (the Param route parameter).
+	Resolve []string `mapstructure:"resolve"`
+
+	// Domain is the app's domain, whose subdomains are tenants.
+	Domain string `mapstructure:"domain"`
+
+	// Header names the tenant (default: X-Tenant-ID).
+	Header string `mapstructure:"header"`
+
+	// Param is the host or route parameter naming the tenant (default:
+	// tenant).
+	Param string `mapstructure:"param"`
+
+	// Required answers requests without a known tenant with 404.
+	// RequireTenant does the same for some routes only.
+	Required bool `mapstructure:"required"`
+
+	// Tenants lists the known tenants with their settings. When set,
+	// other tenant IDs are unknown.
+	Tenants map[string]map[string]any `mapstructure:"tenants"`
+}
+
+// tenancy resolves the tenants of an app's requests.
+type tenancy struct {
+	resolver TenantResolver
+	lookup   TenantLookup
+	required bool
+
+	mu       sync.Mutex
+	services map[reflect.Type]*tenantServices
+}
+
+// tenantServices caches the instances of a service provided per tenant.
+type tenantServices struct {
+	create    func(ctx context.Context, t *Tenant) (any, error)
+	mu        sync.Mutex
+	instances map[string]any
+}
+
+// newTenancy builds the app's tenancy from its config and options, or
+// returns nil when tenancy is off.
+func newTenancy(config TenancyConfig, resolver TenantResolver, lookup TenantLookup) (*tenancy, error) {
+	if resolver == nil {
+		param := orDefault(config.Param, "tenant")
+		var resolvers []TenantResolver
+		for _, name := range config.Resolve {
+			switch name {
+			case "subdomain":
+				if config.Domain == "" {
+					return nil, fmt.Errorf("tenancy: the subdomain resolver needs tenancy.domain")
+				}
+				resolvers = append(resolvers, TenantFromSubdomain(config.Domain))
+			case "host":
+				resolvers = append(resolvers, TenantFromHostParam(param))
+			case "header":
+				resolvers = append(resolvers, TenantFromHeader(orDefault(config.Header, "X-Tenant-ID")))
+			case "path":
+				resolvers = append(resolvers, TenantFromPath(param))
+			default:
+				return nil, fmt.Errorf("tenancy: unknown resolver %q", name)
+			}
+		}
+		if len(resolvers) == 0 {
+			return nil, nil
+		}
+		resolver = FirstTenant(resolvers...)
+	}
+
+	if lookup == nil && len(config.Tenants) > 0 {
+		tenants := config.Tenants
+		lookup = func(_ context.Context, id string) (*Tenant, error) {
+			settings, ok := tenants[strings.ToLower(id)]
+			if !ok {
+				return nil, nil
+			}
+			return &Tenant{ID: id, Settings: settings}, nil
+		}
+	}
+	return &tenancy{resolver: resolver, lookup: lookup, required: config.Required}, nil
+}
+
+// tenantContextKey is the request context key of the tenant.
+type tenantContextKey struct{}
+
+// Tenant returns the tenant of the request, or nil when the request names
+// none, names an unknown tenant, or tenancy is off. The tenant is resolved
+// on the first call and added to the request context (see
+// TenantFromContext).
+//
+// Example:
+//
+//	func Get(c *nexo.Context) error {
+//	    tenant := c.Tenant()
+//	    return c.Render(200, Home(tenant.String("name", tenant.ID)))
+//	}
+func (c *Context) Tenant() *Tenant {
+	t, _ := c.resolveTenant()
+	return t
+}
+
+// resolveTenant resolves the request's tenant once.
+func (c *Context) resolveTenant() (*Tenant, error) {
+	if c.tenancy == nil || c.tenantResolved {
+		return c.tenant, c.tenantErr
+	}
+	c.tenantResolved = true
+
+	id := c.tenancy.resolver(c)
+	if id == "" {
+		return nil, nil
+	}
+	t := &Tenant{ID: id}
+	if c.tenancy.lookup != nil {
+		t, c.tenantErr = c.tenancy.lookup(c.Context(), id)
+		if c.tenantErr != nil || t == nil {
+			return nil, c.tenantErr
+		}
+	}
+	c.tenant = t
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), tenantContextKey{}, t))
+	return t, nil
+}
+
+// TenantFromContext returns the tenant of the request ctx belongs to, for
+// repositories and other code that gets a context.Context but no Context.
+//
+// Example:
+//
+//	func (r *Orders) List(ctx context.Context) ([]Order, error) {
+//	    tenant, _ := nexo.TenantFromContext(ctx)
+//	    return r.query(ctx, "SELECT * FROM orders WHERE tenant_id = $1", tenant.ID)
+//	}
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantContextKey{}).(*Tenant)
+	return t, ok
+}
+
+// tenantMiddleware resolves the tenant before any other middleware, so
+// they all see it. Lookup errors fail the request, and with
+// TenancyConfig.Required so do requests without a known tenant.
+func tenantMiddleware(required bool) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			t, err := c.resolveTenant()
+			if err != nil {
+				return err
+			}
+			if t == nil && required {
+				return NotFound("unknown tenant")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireTenant returns a middleware answering requests without a known
+// tenant with 404, for apps where only some routes are tenant-scoped.
+//
+// Example:
+//
+//	// app/dashboard/middleware.go
+//	func Middleware(next nexo.HandlerFunc) nexo.HandlerFunc {
+//	    return nexo.RequireTenant()(next)
+//	}
+func RequireTenant() MiddlewareFunc {
+	return tenantMiddleware(true)
+}
+
+// ---------- Tenant-Scoped Helpers ----------
+
+// TenantCache returns the app's cache with keys scoped to the request's
+// tenant, so tenants never read each other's entries. Without a tenant it
+// is the app's cache.
+func (c *Context) TenantCache() Cache {
+	t := c.Tenant()
+	if t == nil {
+		return c.Cache()
+	}
+	return &tenantCache{cache: c.Cache(), prefix: "tenant:" + t.ID + ":"}
+}
+
+// tenantCache prefixes the keys of a cache with a tenant.
+type tenantCache struct {
+	cache  Cache
+	prefix string
+}
+
+func (tc *tenantCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return tc.cache.Get(ctx, tc.prefix+key)
+}
+
+func (tc *tenantCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return tc.cache.Set(ctx, tc.prefix+key, value, ttl)
+}
+
+func (tc *tenantCache) Delete(ctx context.Context, key string) error {
+	return tc.cache.Delete(ctx, tc.prefix+key)
+}
+
+func (tc *tenantCache) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	return tc.cache.TTL(ctx, tc.prefix+key)
+}
+
+// Incr implements CacheCounter.
+func (tc *tenantCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrCounter(ctx, tc.cache, tc.prefix+key, ttl)
+}
+
+// ProvideTenant registers a service created once per tenant, like a
+// database handle of the tenant's own database or schema. Handlers get the
+// request tenant's instance with TenantService. Call it before the app
+// serves requests.
+//
+// Example:
+//
+//	nexo.ProvideTenant(app, func(ctx context.Context, t *nexo.Tenant) (*sql.DB, error) {
+//	    return sql.Open("postgres", t.String("database_url", ""))
+//	})
+//
+//	db, err := nexo.TenantService[*sql.DB](c)
+func ProvideTenant[T any](a *App, create func(ctx context.Context, t *Tenant) (T, error)) {
+	tn := a.routeTree.tenancy
+	if tn == nil {
+		panic("nexo: ProvideTenant needs tenancy (tenancy: in nexo.yaml or WithTenantResolver)")
+	}
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	if tn.services == nil {
+		tn.services = make(map[reflect.Type]*tenantServices)
+	}
+	tn.services[reflect.TypeFor[T]()] = &tenantServices{
+		create: func(ctx context.Context, t *Tenant) (any, error) {
+			return create(ctx, t)
+		},
+		instances: make(map[string]any),
+	}
+}
+
+// TenantService returns the request tenant's instance of the service T
+// registered with ProvideTenant, creating it on first use.
+func TenantService[T any](c *Context) (T, error) {
+	var zero T
+	t, err := c.resolveTenant()
+	if err != nil {
+		return zero, err
+	}
+	if t == nil {
+		return zero, NotFound("unknown tenant")
+	}
+
+	c.tenancy.mu.Lock()
+	svc, ok := c.tenancy.services[reflect.TypeFor[T]()]
+	c.tenancy.mu.Unlock()
+	if !ok {
+		return zero, fmt.Errorf("no tenant provider for %s (register it with nexo.ProvideTenant)", reflect.TypeFor[T]())
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if v, ok := svc.instances[t.ID]; ok {
+		return v.(T), nil
+	}
+	v, err := svc.create(context.WithoutCancel(c.Context()), t)
+	if err != nil {
+		return zero, err
+	}
+	svc.instances[t.ID] = v
+	return v.(T), nil
+}
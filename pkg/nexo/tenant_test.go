@@ -0,0 +1,158 @@
+package nexo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver TenantResolver
+		host     string
+		header   string
+		want     string
+	}{
+		{"subdomain", TenantFromSubdomain("example.com"), "acme.example.com:3000", "", "acme"},
+		{"apex domain", TenantFromSubdomain("example.com"), "example.com", "", ""},
+		{"www", TenantFromSubdomain("example.com"), "www.example.com", "", ""},
+		{"nested subdomain", TenantFromSubdomain("example.com"), "a.b.example.com", "", ""},
+		{"other domain", TenantFromSubdomain("example.com"), "acme.example.org", "", ""},
+		{"header", TenantFromHeader("X-Tenant-ID"), "example.com", " globex ", "globex"},
+		{"first", FirstTenant(TenantFromSubdomain("example.com"), TenantFromHeader("X-Tenant-ID")), "example.com", "globex", "globex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			req.Header.Set("X-Tenant-ID", tt.header)
+			c := NewContext(httptest.NewRecorder(), req)
+			if got := tt.resolver(c); got != tt.want {
+				t.Errorf("resolved %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenancy(t *testing.T) {
+	config := DefaultConfig()
+	config.Tenancy = TenancyConfig{
+		Resolve:  []string{"subdomain", "path"},
+		Domain:   "example.com",
+		Required: true,
+		Tenants: map[string]map[string]any{
+			"acme":   {"name": "Acme Corp", "limits": map[string]any{"seats": 25}},
+			"globex": {},
+		},
+	}
+	app := New(WithConfig(config))
+
+	// Middleware added with Use runs after the tenant is resolved
+	var seenByMiddleware string
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			seenByMiddleware = c.Tenant().ID
+			return next(c)
+		}
+	})
+	handler := func(c *Context) error {
+		tenant, _ := TenantFromContext(c.Context())
+		return c.String(http.StatusOK, fmt.Sprintf("%s %s %d", tenant.ID,
+			c.Tenant().String("name", tenant.ID), c.Tenant().Int("limits.seats", 5)))
+	}
+	app.Get("/", handler)
+	app.Get("/t/{tenant}/home", handler)
+	app.Mount()
+
+	tests := []struct {
+		host, path string
+		code       int
+		body       string
+	}{
+		{"acme.example.com", "/", http.StatusOK, "acme Acme Corp 25"},
+		{"globex.example.com", "/", http.StatusOK, "globex globex 5"},
+		{"example.com", "/t/acme/home", http.StatusOK, "acme Acme Corp 25"},
+		{"initech.example.com", "/", http.StatusNotFound, ""},
+		{"example.com", "/", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host+tt.path, func(t *testing.T) {
+			seenByMiddleware = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.code == http.StatusOK && (w.Body.String() != tt.body || seenByMiddleware == "") {
+				t.Errorf("body = %q (middleware saw %q), want %q", w.Body.String(), seenByMiddleware, tt.body)
+			}
+		})
+	}
+}
+
+func TestTenantScoping(t *testing.T) {
+	var opened []string
+	app := New(WithTenantResolver(TenantFromHeader("X-Tenant-ID")))
+	type tenantDB struct{ name string }
+	ProvideTenant(app, func(_ context.Context, t *Tenant) (*tenantDB, error) {
+		opened = append(opened, t.ID)
+		return &tenantDB{name: "db_" + t.ID}, nil
+	})
+
+	renders := 0
+	app.Get("/page", CacheResponse(time.Minute)(func(c *Context) error {
+		renders++
+		return c.String(http.StatusOK, "page of "+c.Tenant().ID)
+	}))
+	app.Get("/visits", func(c *Context) error {
+		db, err := TenantService[*tenantDB](c)
+		if err != nil {
+			return err
+		}
+		n, err := incrCounter(c.Context(), c.TenantCache(), "visits", time.Minute)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, fmt.Sprintf("%s %d", db.name, n))
+	})
+	app.Mount()
+
+	get := func(tenant, path string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Cached responses aren't shared between tenants
+	if got := get("acme", "/page"); got != "page of acme" {
+		t.Errorf("acme got %q", got)
+	}
+	if got := get("globex", "/page"); got != "page of globex" {
+		t.Errorf("globex got %q, a response cached for another tenant", got)
+	}
+	if get("acme", "/page"); renders != 2 {
+		t.Errorf("rendered %d times, want 2", renders)
+	}
+
+	// Cache keys and services are per tenant
+	for _, tt := range []struct{ tenant, want string }{
+		{"acme", "db_acme 1"},
+		{"acme", "db_acme 2"},
+		{"globex", "db_globex 1"},
+	} {
+		if got := get(tt.tenant, "/visits"); got != tt.want {
+			t.Errorf("%s got %q, want %q", tt.tenant, got, tt.want)
+		}
+	}
+	if len(opened) != 2 {
+		t.Errorf("opened %v, want one instance per tenant", opened)
+	}
+}
@@ -0,0 +1,276 @@
+package nexo
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+// ---------- Typed Handlers ----------
+
+// Registrar is where Handle registers typed handlers: an App or a
+// RouteGroup.
+type Registrar interface {
+	// register adds a route and returns its app and full pattern.
+	register(method, pattern string, handler HandlerFunc) (*App, string)
+}
+
+func (a *App) register(method, pattern string, handler HandlerFunc) (*App, string) {
+	a.RegisterRoute(method, pattern, handler)
+	return a, pattern
+}
+
+func (g *RouteGroup) register(method, pattern string, handler HandlerFunc) (*App, string) {
+	g.app.routeTree.AddRoute(&Route{
+		Method:      method,
+		Pattern:     g.prefix + pattern,
+		Handler:     handler,
+		Priority:    CalculatePriority(g.prefix + pattern),
+		Host:        g.host,
+		Middlewares: g.middlewares,
+	})
+	return g.app, g.prefix + pattern
+}
+
+// HandleConfig describes a typed handler.
+type HandleConfig struct {
+	// Summary, Description and Tags document the operation in the OpenAPI
+	// spec served by ServeOpenAPI.
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Status is the status of successful responses (default: 201 Created
+	// for POST, 200 OK otherwise). With 204 No Content, the response isn't
+	// written.
+	Status int
+}
+
+// typedOperation is a typed handler recorded for the OpenAPI spec.
+type typedOperation struct {
+	method  string
+	pattern string
+	req     reflect.Type
+	resp    reflect.Type
+	config  HandleConfig
+}
+
+// Handle registers a typed handler on an App or RouteGroup: the request is
+// decoded into TReq and the TResp returned is encoded as JSON, and both
+// types are documented in the OpenAPI spec served by ServeOpenAPI. It's
+// meant for routes registered from code, like a library mounting its own
+// API, and works alongside file-based routes.
+//
+// For GET, HEAD, DELETE and OPTIONS, TReq is bound from the query string
+// (see Context.BindQuery); for other methods, from the JSON body, decoded
+// and validated like WithBody does. Fields tagged `path` are bound from
+// the route's parameters in both cases. An error returned by h is handled
+// like a handler's, so return an HTTPError to choose the status.
+//
+// ctx is the request's context with the principal and bearer token (see
+// PrincipalFromContext).
+//
+// Example:
+//
+//	type GetUserRequest struct {
+//	    ID     string `path:"id"`
+//	    Expand bool   `query:"expand"`
+//	}
+//
+//	nexo.Handle(app, "GET", "/users/{id}", func(ctx context.Context, req GetUserRequest) (User, error) {
+//	    user, err := users.Find(ctx, req.ID)
+//	    if errors.Is(err, sql.ErrNoRows) {
+//	        return User{}, nexo.NewHTTPError(404, "user not found")
+//	    }
+//	    return user, err
+//	})
+func Handle[TReq, TResp any](r Registrar, method, pattern string, h func(context.Context, TReq) (TResp, error)) {
+	HandleWithConfig(r, method, pattern, h, HandleConfig{})
+}
+
+// HandleWithConfig registers a typed handler with a custom status and
+// OpenAPI documentation. See Handle.
+//
+// Example:
+//
+//	nexo.HandleWithConfig(app, "POST", "/users", createUser, nexo.HandleConfig{
+//	    Summary: "Create a user",
+//	    Tags:    []string{"users"},
+//	})
+func HandleWithConfig[TReq, TResp any](r Registrar, method, pattern string, h func(context.Context, TReq) (TResp, error), config HandleConfig) {
+	method = strings.ToUpper(method)
+	if config.Status == 0 {
+		config.Status = http.StatusOK
+		if method == http.MethodPost {
+			config.Status = http.StatusCreated
+		}
+	}
+	hasBody := typedRequestHasBody(method)
+	isStruct := reflect.TypeFor[TReq]().Kind() == reflect.Struct
+
+	app, full := r.register(method, pattern, func(c *Context) error {
+		var req TReq
+		if hasBody {
+			if err := c.Bind(&req); err != nil {
+				return err
+			}
+			if err := validateBody(&req); err != nil {
+				return NewHTTPErrorWithCause(http.StatusBadRequest, err.Error(), err)
+			}
+		} else if isStruct {
+			if err := c.BindQuery(&req); err != nil {
+				return err
+			}
+		}
+		if isStruct {
+			if err := c.BindPath(&req); err != nil {
+				return err
+			}
+		}
+
+		resp, err := h(c.ResolverContext(), req)
+		if err != nil {
+			return err
+		}
+		if config.Status == http.StatusNoContent {
+			return c.NoContent()
+		}
+		return c.JSON(config.Status, resp)
+	})
+
+	app.operations = append(app.operations, typedOperation{
+		method:  method,
+		pattern: full,
+		req:     reflect.TypeFor[TReq](),
+		resp:    reflect.TypeFor[TResp](),
+		config:  config,
+	})
+}
+
+// typedRequestHasBody reports whether typed handlers of method decode their
+// request from the body.
+func typedRequestHasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// addTypedOperations adds the operations of typed handlers to doc, next to
+// those of file-based routes.
+func addTypedOperations(doc *openapi3.T, ops []typedOperation) error {
+	for _, op := range ops {
+		operation, err := op.build()
+		if err != nil {
+			return err
+		}
+		path := openAPIPath(op.pattern)
+		item := doc.Paths.Value(path)
+		if item == nil {
+			item = &openapi3.PathItem{}
+			doc.Paths.Set(path, item)
+		}
+		item.SetOperation(op.method, operation)
+	}
+	return nil
+}
+
+// build returns the OpenAPI operation of a typed handler.
+func (op typedOperation) build() (*openapi3.Operation, error) {
+	operation := &openapi3.Operation{
+		Summary:     op.config.Summary,
+		Description: op.config.Description,
+		Tags:        op.config.Tags,
+		Responses:   openapi3.NewResponsesWithCapacity(2),
+	}
+
+	// Path parameters are typed by the request's `path` fields
+	path := map[string]reflect.StructField{}
+	query := map[string]reflect.StructField{}
+	if op.req.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(op.req) {
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			if name, _, _ := strings.Cut(field.Tag.Get("path"), ","); name != "" && name != "-" {
+				path[name] = field
+			} else if key, ok := bindKey(field, "query"); ok && !typedRequestHasBody(op.method) {
+				query[key] = field
+			}
+		}
+	}
+	for _, seg := range strings.Split(op.pattern, "/") {
+		name, typ, ok := routeParam(seg)
+		if !ok || name == "*" || strings.Contains(name, "...") {
+			continue
+		}
+		schema := paramSchema(typ)
+		if field, ok := path[name]; ok {
+			ref, err := typedSchema(field.Type, false)
+			if err != nil {
+				return nil, err
+			}
+			schema = ref.Value
+		}
+		operation.AddParameter(openapi3.NewPathParameter(name).WithSchema(schema))
+	}
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		ref, err := typedSchema(query[name].Type, false)
+		if err != nil {
+			return nil, err
+		}
+		operation.AddParameter(openapi3.NewQueryParameter(name).WithSchema(ref.Value))
+	}
+
+	if typedRequestHasBody(op.method) {
+		ref, err := typedSchema(op.req, true)
+		if err != nil {
+			return nil, err
+		}
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(ref)}
+		operation.AddResponse(http.StatusBadRequest, openapi3.NewResponse().WithDescription("Bad Request"))
+	}
+
+	response := openapi3.NewResponse().WithDescription(http.StatusText(op.config.Status))
+	if op.config.Status != http.StatusNoContent {
+		ref, err := typedSchema(op.resp, false)
+		if err != nil {
+			return nil, err
+		}
+		response.WithJSONSchemaRef(ref)
+	}
+	operation.AddResponse(op.config.Status, response)
+	return operation, nil
+}
+
+// typedSchema returns the JSON schema of t, inlined. Fields bound from
+// the path are left out of request bodies.
+func typedSchema(t reflect.Type, body bool) (*openapi3.SchemaRef, error) {
+	gen := openapi3gen.NewGenerator(openapi3gen.SchemaCustomizer(
+		func(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+			if p, _, _ := strings.Cut(tag.Get("path"), ","); body && p != "" && p != "-" {
+				return &openapi3gen.ExcludeSchemaSentinel{}
+			}
+			return nil
+		}))
+	ref, err := gen.GenerateSchemaRef(t)
+	if err != nil {
+		return nil, err
+	}
+	// The generator names schemas after their Go types, which the spec
+	// doesn't define
+	for ref := range gen.SchemaRefs {
+		ref.Ref = ""
+	}
+	ref.Ref = ""
+	return ref, nil
+}
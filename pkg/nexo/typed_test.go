@@ -0,0 +1,182 @@
+package nexo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type getTypedUser struct {
+	ID     int  `path:"id"`
+	Expand bool `query:"expand"`
+}
+
+type createTypedUser struct {
+	Team string `path:"team" json:"-"`
+	Name string `json:"name"`
+}
+
+func (b createTypedUser) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func typedApp(t *testing.T) *App {
+	t.Helper()
+	app := New()
+	Handle(app, "GET", "/users/{id}", func(ctx context.Context, req getTypedUser) (typedUser, error) {
+		if req.ID == 404 {
+			return typedUser{}, NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		name := "Ada"
+		if req.Expand {
+			name = "Ada Lovelace"
+		}
+		return typedUser{ID: req.ID, Name: name}, nil
+	})
+	app.Group("/teams", func(g *RouteGroup) {
+		HandleWithConfig(g, "POST", "/{team}/users", func(ctx context.Context, req createTypedUser) (typedUser, error) {
+			return typedUser{ID: 1, Name: req.Team + "/" + req.Name}, nil
+		}, HandleConfig{Summary: "Create a user", Tags: []string{"users"}})
+		HandleWithConfig(g, "DELETE", "/{team}", func(ctx context.Context, req struct{}) (struct{}, error) {
+			return struct{}{}, nil
+		}, HandleConfig{Status: http.StatusNoContent})
+	})
+	app.ServeOpenAPI(OpenAPIOptions{Title: "Typed"})
+	app.Mount()
+	return app
+}
+
+func TestHandle(t *testing.T) {
+	app := typedApp(t)
+	tests := []struct {
+		method, target, body string
+		status               int
+		want                 string
+	}{
+		{"GET", "/users/7", "", http.StatusOK, `{"id":7,"name":"Ada"}`},
+		{"GET", "/users/7?expand=true", "", http.StatusOK, `{"id":7,"name":"Ada Lovelace"}`},
+		{"GET", "/users/404", "", http.StatusNotFound, "user not found"},
+		{"GET", "/users/abc", "", http.StatusBadRequest, ""},
+		{"POST", "/teams/core/users", `{"name":"Grace"}`, http.StatusCreated, `{"id":1,"name":"core/Grace"}`},
+		{"POST", "/teams/core/users", `{}`, http.StatusBadRequest, "name is required"},
+		{"DELETE", "/teams/core", "", http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("body = %q, want %q", w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandle_Principal(t *testing.T) {
+	app := New()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetPrincipal("ada")
+			return next(c)
+		}
+	})
+	Handle(app, "GET", "/me", func(ctx context.Context, req struct{}) (string, error) {
+		principal, _ := PrincipalFromContext(ctx)
+		return principal.(string), nil
+	})
+	app.Mount()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+	if strings.TrimSpace(w.Body.String()) != `"ada"` {
+		t.Errorf("body = %q, want the principal", w.Body)
+	}
+}
+
+func TestHandle_OpenAPI(t *testing.T) {
+	app := typedApp(t)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Summary    string   `json:"summary"`
+			Tags       []string `json:"tags"`
+			Parameters []struct {
+				Name   string `json:"name"`
+				In     string `json:"in"`
+				Schema struct {
+					Type string `json:"type"`
+				} `json:"schema"`
+			} `json:"parameters"`
+			RequestBody *struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]any `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]any `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	get := spec.Paths["/users/{id}"]["get"]
+	if len(get.Parameters) != 2 ||
+		get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" || get.Parameters[0].Schema.Type != "integer" ||
+		get.Parameters[1].Name != "expand" || get.Parameters[1].In != "query" || get.Parameters[1].Schema.Type != "boolean" {
+		t.Errorf("GET parameters = %+v", get.Parameters)
+	}
+	if props := get.Responses["200"].Content["application/json"].Schema.Properties; props["id"] == nil || props["name"] == nil {
+		t.Errorf("GET response properties = %v", props)
+	}
+
+	post := spec.Paths["/teams/{team}/users"]["post"]
+	if post.Summary != "Create a user" || len(post.Tags) != 1 {
+		t.Errorf("POST summary = %q, tags = %v", post.Summary, post.Tags)
+	}
+	if post.RequestBody == nil {
+		t.Fatal("POST has no request body")
+	}
+	if props := post.RequestBody.Content["application/json"].Schema.Properties; len(props) != 1 || props["name"] == nil {
+		t.Errorf("POST body properties = %v, want only name", props)
+	}
+	if _, ok := post.Responses["201"]; !ok {
+		t.Errorf("POST responses = %v, want 201", post.Responses)
+	}
+
+	del := spec.Paths["/teams/{team}"]["delete"]
+	if resp, ok := del.Responses["204"]; !ok || resp.Content != nil {
+		t.Errorf("DELETE responses = %+v, want 204 without content", del.Responses)
+	}
+}
@@ -0,0 +1,556 @@
+package nexo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUploadNotFound is returned by Uploads.Get for unknown or expired uploads.
+var ErrUploadNotFound = errors.New("nexo: upload not found")
+
+// tusVersion is the version of the tus resumable upload protocol Uploads speaks.
+const tusVersion = "1.0.0"
+
+// uploadSweepInterval is how often the upload handler removes expired uploads.
+const uploadSweepInterval = time.Hour
+
+// UploadConfig configures resumable uploads.
+type UploadConfig struct {
+	// Dir holds the uploads (default: nexo-uploads in os.TempDir())
+	Dir string
+
+	// MaxSize is the largest upload accepted, in bytes (optional, no limit when 0)
+	MaxSize int64
+
+	// TTL is how long an upload is kept after its last write (default: 24h)
+	TTL time.Duration
+
+	// OnProgress is called as each chunk is written, with the upload's
+	// offset updated (optional)
+	OnProgress func(c *Context, u *Upload)
+
+	// OnComplete is called once the last byte is written. The upload stays
+	// on disk until its TTL expires, so move it or call Uploads.Remove when
+	// done. A returned error is the response of the final request (optional)
+	OnComplete func(c *Context, u *Upload) error
+}
+
+// Upload is a resumable upload stored on disk.
+type Upload struct {
+	// ID identifies the upload in its URL.
+	ID string `json:"id"`
+
+	// Size is the total length of the upload in bytes.
+	Size int64 `json:"size"`
+
+	// Offset is the number of bytes received so far.
+	Offset int64 `json:"-"`
+
+	// Metadata holds the key-value pairs sent in Upload-Metadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Path is the file holding the received bytes.
+	Path string `json:"-"`
+
+	// CreatedAt is when the upload was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the last bytes were written.
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Done reports whether every byte of the upload has been received.
+func (u *Upload) Done() bool {
+	return u.Offset >= u.Size
+}
+
+// Open opens the received bytes for reading.
+func (u *Upload) Open() (*os.File, error) {
+	return os.Open(u.Path)
+}
+
+// Uploads serves resumable uploads with the tus protocol
+// (https://tus.io/protocols/resumable-upload), so clients such as tus-js-client
+// and Uppy can upload large files in chunks and resume after a dropped
+// connection. Received bytes are appended to a file in Dir; uploads that
+// aren't written to for TTL are removed.
+type Uploads struct {
+	config UploadConfig
+
+	mu        sync.Mutex
+	writing   map[string]bool // uploads with a PATCH in progress
+	lastSweep time.Time
+}
+
+// NewUploads creates a resumable upload store.
+//
+// Example:
+//
+//	uploads := nexo.NewUploads(nexo.UploadConfig{
+//	    MaxSize: 5 << 30,
+//	    OnComplete: func(c *nexo.Context, u *nexo.Upload) error {
+//	        return media.Import(c.Context(), u.Path, u.Metadata["filename"])
+//	    },
+//	})
+//	uploads.Register(app, "/uploads")
+func NewUploads(config UploadConfig) *Uploads {
+	if config.Dir == "" {
+		config.Dir = filepath.Join(os.TempDir(), "nexo-uploads")
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return &Uploads{config: config, writing: make(map[string]bool)}
+}
+
+// Register serves the uploads at pattern: POST and OPTIONS on pattern,
+// HEAD, PATCH and DELETE on pattern/{id}.
+func (u *Uploads) Register(r Registrar, pattern string) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	h := u.Handler()
+	for _, method := range []string{http.MethodPost, http.MethodOptions} {
+		r.register(method, orDefault(pattern, "/"), h)
+	}
+	for _, method := range []string{http.MethodHead, http.MethodPatch, http.MethodDelete} {
+		r.register(method, pattern+"/{id}", h)
+	}
+}
+
+// Handler returns the handler of the upload endpoint. POST and OPTIONS
+// requests address the collection; HEAD, PATCH and DELETE requests address
+// an upload by the last segment of their path. Register mounts it on the
+// right patterns.
+func (u *Uploads) Handler() HandlerFunc {
+	return func(c *Context) error {
+		c.SetHeader("Tus-Resumable", tusVersion)
+		if c.Method() == http.MethodOptions {
+			return u.options(c)
+		}
+		if v := c.Header("Tus-Resumable"); v != "" && v != tusVersion {
+			c.SetHeader("Tus-Version", tusVersion)
+			return NewHTTPError(http.StatusPreconditionFailed, "unsupported tus version")
+		}
+
+		id := path.Base(c.Path())
+		switch c.Method() {
+		case http.MethodPost:
+			return u.create(c)
+		case http.MethodHead:
+			return u.head(c, id)
+		case http.MethodPatch:
+			return u.patch(c, id)
+		case http.MethodDelete:
+			if err := u.Remove(id); err != nil {
+				return uploadError(err)
+			}
+			return c.NoContent()
+		default:
+			return NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+// Get returns the upload with the given ID.
+func (u *Uploads) Get(id string) (*Upload, error) {
+	if !validUploadID(id) {
+		return nil, ErrUploadNotFound
+	}
+	data, err := os.ReadFile(u.infoPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var up Upload
+	if err := json.Unmarshal(data, &up); err != nil {
+		return nil, fmt.Errorf("nexo: upload %s: %w", id, err)
+	}
+	up.Path = u.dataPath(id)
+	info, err := os.Stat(up.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	up.Offset = info.Size()
+	up.UpdatedAt = info.ModTime()
+	return &up, nil
+}
+
+// Remove deletes an upload and its bytes.
+func (u *Uploads) Remove(id string) error {
+	if !validUploadID(id) {
+		return ErrUploadNotFound
+	}
+	err := os.Remove(u.infoPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrUploadNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(u.dataPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Cleanup removes uploads that haven't been written to for TTL and returns
+// how many were removed. The handler runs it hourly; call it from a
+// scheduled job to clean up more often.
+func (u *Uploads) Cleanup() (int, error) {
+	entries, err := os.ReadDir(u.config.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-u.config.TTL)
+	removed := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !validUploadID(id) || u.isWriting(id) {
+			continue
+		}
+		up, err := u.Get(id)
+		if err == nil && up.UpdatedAt.After(cutoff) {
+			continue
+		}
+		if err := u.Remove(id); err != nil && !errors.Is(err, ErrUploadNotFound) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// options describes the protocol support.
+func (u *Uploads) options(c *Context) error {
+	c.SetHeader("Tus-Version", tusVersion)
+	c.SetHeader("Tus-Extension", "creation,creation-with-upload,termination,expiration")
+	if u.config.MaxSize > 0 {
+		c.SetHeader("Tus-Max-Size", strconv.FormatInt(u.config.MaxSize, 10))
+	}
+	return c.NoContent()
+}
+
+// create starts an upload, writing the request body as its first chunk.
+func (u *Uploads) create(c *Context) error {
+	u.sweep()
+
+	size, err := strconv.ParseInt(c.Header("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return BadRequest("invalid Upload-Length")
+	}
+	if u.config.MaxSize > 0 && size > u.config.MaxSize {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, "upload too large")
+	}
+	metadata, err := parseUploadMetadata(c.Header("Upload-Metadata"))
+	if err != nil {
+		return BadRequest("invalid Upload-Metadata")
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return err
+	}
+	up := &Upload{ID: id, Size: size, Metadata: metadata, CreatedAt: time.Now().UTC()}
+	if err := u.save(up); err != nil {
+		return err
+	}
+
+	c.SetHeader("Location", strings.TrimSuffix(c.Path(), "/")+"/"+id)
+	if c.Header("Content-Type") == "application/offset+octet-stream" {
+		if err := u.write(c, up); err != nil {
+			return err
+		}
+	}
+	c.SetHeader("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.SetHeader("Upload-Expires", up.UpdatedAt.Add(u.config.TTL).UTC().Format(http.TimeFormat))
+	if err := u.complete(c, up); err != nil {
+		return err
+	}
+	c.Response.WriteHeader(http.StatusCreated)
+	c.written = true
+	c.status = http.StatusCreated
+	return nil
+}
+
+// head reports how much of an upload has been received.
+func (u *Uploads) head(c *Context, id string) error {
+	up, err := u.Get(id)
+	if err != nil {
+		return uploadError(err)
+	}
+	c.SetHeader("Cache-Control", "no-store")
+	c.SetHeader("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.SetHeader("Upload-Length", strconv.FormatInt(up.Size, 10))
+	if len(up.Metadata) > 0 {
+		c.SetHeader("Upload-Metadata", formatUploadMetadata(up.Metadata))
+	}
+	c.SetHeader("Upload-Expires", up.UpdatedAt.Add(u.config.TTL).UTC().Format(http.TimeFormat))
+	return c.emptyOK()
+}
+
+// patch appends a chunk at the offset the client sent.
+func (u *Uploads) patch(c *Context, id string) error {
+	if c.Header("Content-Type") != "application/offset+octet-stream" {
+		return NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+	}
+	offset, err := strconv.ParseInt(c.Header("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return BadRequest("invalid Upload-Offset")
+	}
+	if !u.startWriting(id) {
+		return NewHTTPError(http.StatusConflict, "upload is being written by another request")
+	}
+	defer u.stopWriting(id)
+
+	up, err := u.Get(id)
+	if err != nil {
+		return uploadError(err)
+	}
+	if offset != up.Offset {
+		c.SetHeader("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+		return NewHTTPError(http.StatusConflict, "Upload-Offset does not match the upload")
+	}
+	if up.Done() {
+		// A retried last chunk: OnComplete ran for the write that finished
+		c.SetHeader("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+		return c.NoContent()
+	}
+	if err := u.write(c, up); err != nil {
+		return err
+	}
+	c.SetHeader("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.SetHeader("Upload-Expires", up.UpdatedAt.Add(u.config.TTL).UTC().Format(http.TimeFormat))
+	if err := u.complete(c, up); err != nil {
+		return err
+	}
+	return c.NoContent()
+}
+
+// write appends the request body to the upload, up to its size. Bytes
+// received before a dropped connection are kept, so the client can resume.
+func (u *Uploads) write(c *Context, up *Upload) error {
+	f, err := os.OpenFile(up.Path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := &uploadProgress{Writer: f, c: c, up: up, onProgress: u.config.OnProgress}
+	_, err = io.Copy(w, io.LimitReader(c.Request.Body, up.Size-up.Offset))
+	up.UpdatedAt = time.Now()
+	if err != nil {
+		return bodyReadError(err, "upload interrupted")
+	}
+	return nil
+}
+
+// complete runs OnComplete once the last byte of up has been written. It's
+// called only by the request that wrote it.
+func (u *Uploads) complete(c *Context, up *Upload) error {
+	if !up.Done() || u.config.OnComplete == nil {
+		return nil
+	}
+	return u.config.OnComplete(c, up)
+}
+
+// save creates the files of a new upload.
+func (u *Uploads) save(up *Upload) error {
+	if err := os.MkdirAll(u.config.Dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(up)
+	if err != nil {
+		return err
+	}
+	up.Path = u.dataPath(up.ID)
+	if err := os.WriteFile(up.Path, nil, 0o600); err != nil {
+		return err
+	}
+	up.UpdatedAt = time.Now()
+	return os.WriteFile(u.infoPath(up.ID), data, 0o600)
+}
+
+// sweep runs Cleanup if it hasn't run for uploadSweepInterval.
+func (u *Uploads) sweep() {
+	u.mu.Lock()
+	due := time.Since(u.lastSweep) >= uploadSweepInterval
+	if due {
+		u.lastSweep = time.Now()
+	}
+	u.mu.Unlock()
+	if due {
+		_, _ = u.Cleanup()
+	}
+}
+
+func (u *Uploads) startWriting(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.writing[id] {
+		return false
+	}
+	u.writing[id] = true
+	return true
+}
+
+func (u *Uploads) stopWriting(id string) {
+	u.mu.Lock()
+	delete(u.writing, id)
+	u.mu.Unlock()
+}
+
+func (u *Uploads) isWriting(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.writing[id]
+}
+
+func (u *Uploads) dataPath(id string) string {
+	return filepath.Join(u.config.Dir, id+".bin")
+}
+
+func (u *Uploads) infoPath(id string) string {
+	return filepath.Join(u.config.Dir, id+".json")
+}
+
+// uploadError maps ErrUploadNotFound to a 404.
+func uploadError(err error) error {
+	if errors.Is(err, ErrUploadNotFound) {
+		return NewHTTPErrorWithCause(http.StatusNotFound, "upload not found", err)
+	}
+	return err
+}
+
+// newUploadID returns a random upload ID.
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validUploadID reports whether id could have been made by newUploadID, so
+// IDs from URLs can't address files outside Dir.
+func validUploadID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// parseUploadMetadata parses an Upload-Metadata header: comma-separated
+// pairs of a key and an optional base64 value.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for pair := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+// formatUploadMetadata formats metadata as an Upload-Metadata header.
+func formatUploadMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key
+		if value := metadata[key]; value != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// uploadProgress advances an upload's offset as bytes are written and
+// reports each write to onProgress.
+type uploadProgress struct {
+	io.Writer
+	c          *Context
+	up         *Upload
+	onProgress func(c *Context, u *Upload)
+}
+
+func (w *uploadProgress) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.up.Offset += int64(n)
+	if n > 0 && w.onProgress != nil {
+		w.onProgress(w.c, w.up)
+	}
+	return n, err
+}
+
+// ---------- Upload Progress ----------
+
+// OnUploadProgress calls fn as the request body is read, with the bytes
+// read so far and the Content-Length (-1 when unknown). Call it before the
+// body is read, e.g. before FormFile or BindForm, to report the progress of
+// a plain multipart upload.
+//
+// Example:
+//
+//	c.OnUploadProgress(func(read, total int64) {
+//	    progress.Publish(c.Query("upload"), read, total)
+//	})
+//	file, err := c.FormFile("video")
+func (c *Context) OnUploadProgress(fn func(read, total int64)) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return
+	}
+	c.Request.Body = &progressReader{ReadCloser: c.Request.Body, total: c.Request.ContentLength, fn: fn}
+}
+
+// progressReader reports the bytes read from a request body.
+type progressReader struct {
+	io.ReadCloser
+	read  int64
+	total int64
+	fn    func(read, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.fn(r.read, r.total)
+	}
+	return n, err
+}
@@ -0,0 +1,209 @@
+package nexo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func uploadRequest(app *App, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Tus-Resumable", "1.0.0")
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestUploads(t *testing.T) {
+	var progress []int64
+	var completed *Upload
+	var completions int
+	uploads := NewUploads(UploadConfig{
+		Dir:     t.TempDir(),
+		MaxSize: 64,
+		OnProgress: func(c *Context, u *Upload) {
+			progress = append(progress, u.Offset)
+		},
+		OnComplete: func(c *Context, u *Upload) error {
+			completed = u
+			completions++
+			return nil
+		},
+	})
+	app := New()
+	uploads.Register(app, "/uploads")
+	app.Mount()
+
+	octets := map[string]string{"Content-Type": "application/offset+octet-stream"}
+
+	w := uploadRequest(app, "OPTIONS", "/uploads", "", nil)
+	if w.Code != http.StatusNoContent || w.Header().Get("Tus-Max-Size") != "64" {
+		t.Fatalf("OPTIONS = %d, headers %v", w.Code, w.Header())
+	}
+
+	w = uploadRequest(app, "POST", "/uploads", "", map[string]string{"Upload-Length": "100"})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST over MaxSize = %d, want 413", w.Code)
+	}
+
+	w = uploadRequest(app, "POST", "/uploads", "", map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": "filename aGVsbG8udHh0,private",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST = %d: %s", w.Code, w.Body)
+	}
+	location := w.Header().Get("Location")
+	id := path.Base(location)
+	if !strings.HasPrefix(location, "/uploads/") || w.Header().Get("Upload-Offset") != "0" {
+		t.Fatalf("POST headers = %v", w.Header())
+	}
+
+	w = uploadRequest(app, "PATCH", location, "hello ", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "0",
+	})
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("PATCH = %d, offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	if completed != nil {
+		t.Error("OnComplete ran before the last byte")
+	}
+
+	w = uploadRequest(app, "PATCH", location, "world", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "2",
+	})
+	if w.Code != http.StatusConflict || w.Header().Get("Upload-Offset") != "6" {
+		t.Errorf("PATCH at wrong offset = %d, offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+
+	w = uploadRequest(app, "PATCH", location, "world", octets)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("PATCH without offset = %d, want 400", w.Code)
+	}
+
+	w = uploadRequest(app, "HEAD", location, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "6" || w.Header().Get("Upload-Length") != "11" {
+		t.Errorf("HEAD = %d, headers %v", w.Code, w.Header())
+	}
+	if got := w.Header().Get("Upload-Metadata"); got != "filename aGVsbG8udHh0,private" {
+		t.Errorf("HEAD Upload-Metadata = %q", got)
+	}
+
+	w = uploadRequest(app, "PATCH", location, "world", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "6",
+	})
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "11" {
+		t.Fatalf("last PATCH = %d, offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	if completed == nil || completed.ID != id || completed.Metadata["filename"] != "hello.txt" {
+		t.Fatalf("OnComplete got %+v", completed)
+	}
+	f, err := completed.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "hello world" {
+		t.Errorf("upload = %q, want %q", data, "hello world")
+	}
+	if len(progress) != 2 || progress[1] != 11 {
+		t.Errorf("progress = %v, want [6 11]", progress)
+	}
+
+	// A client retrying the last PATCH doesn't complete the upload again
+	w = uploadRequest(app, "PATCH", location, "", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "11",
+	})
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "11" {
+		t.Errorf("retried PATCH = %d, offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	if completions != 1 {
+		t.Errorf("OnComplete ran %d times, want 1", completions)
+	}
+
+	w = uploadRequest(app, "DELETE", location, "", nil)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d", w.Code)
+	}
+	for _, target := range []string{location, "/uploads/../../etc"} {
+		if w := uploadRequest(app, "HEAD", target, "", nil); w.Code != http.StatusNotFound {
+			t.Errorf("HEAD %s = %d, want 404", target, w.Code)
+		}
+	}
+}
+
+func TestUploads_CreateWithUpload(t *testing.T) {
+	uploads := NewUploads(UploadConfig{Dir: t.TempDir()})
+	app := New()
+	uploads.Register(app, "/uploads")
+	app.Mount()
+
+	w := uploadRequest(app, "POST", "/uploads", "abc", map[string]string{
+		"Upload-Length": "5",
+		"Content-Type":  "application/offset+octet-stream",
+	})
+	if w.Code != http.StatusCreated || w.Header().Get("Upload-Offset") != "3" {
+		t.Fatalf("POST = %d, offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	up, err := uploads.Get(path.Base(w.Header().Get("Location")))
+	if err != nil || up.Offset != 3 || up.Done() {
+		t.Errorf("Get() = %+v, %v", up, err)
+	}
+}
+
+func TestUploads_Cleanup(t *testing.T) {
+	uploads := NewUploads(UploadConfig{Dir: t.TempDir(), TTL: time.Hour})
+	app := New()
+	uploads.Register(app, "/uploads")
+	app.Mount()
+
+	var ids []string
+	for range 2 {
+		w := uploadRequest(app, "POST", "/uploads", "", map[string]string{"Upload-Length": "10"})
+		ids = append(ids, path.Base(w.Header().Get("Location")))
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(uploads.dataPath(ids[0]), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := uploads.Cleanup()
+	if err != nil || removed != 1 {
+		t.Fatalf("Cleanup() = %d, %v; want 1", removed, err)
+	}
+	if _, err := uploads.Get(ids[0]); err != ErrUploadNotFound {
+		t.Errorf("expired upload: Get() error = %v", err)
+	}
+	if _, err := uploads.Get(ids[1]); err != nil {
+		t.Errorf("fresh upload: Get() error = %v", err)
+	}
+}
+
+func TestContext_OnUploadProgress(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 10)))
+	c := NewContext(httptest.NewRecorder(), req)
+
+	var read, total int64
+	c.OnUploadProgress(func(r, t int64) {
+		read, total = r, t
+	})
+	if _, err := io.ReadAll(c.Request.Body); err != nil {
+		t.Fatal(err)
+	}
+	if read != 10 || total != 10 {
+		t.Errorf("progress = %d/%d, want 10/10", read, total)
+	}
+}
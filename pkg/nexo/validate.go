@@ -0,0 +1,543 @@
+package nexo
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidationRule reports whether a field value passes a rule. param is the
+// text after "=" in the tag, like "3" in `validate:"min=3"`. Pointers are
+// dereferenced before the rule runs, except for the required rule.
+//
+// Example:
+//
+//	app.RegisterValidation("slug", func(v reflect.Value, _ string) bool {
+//	    return slugPattern.MatchString(v.String())
+//	}, "{field} must be lowercase letters, digits and dashes")
+type ValidationRule func(value reflect.Value, param string) bool
+
+// FieldError describes a field that failed a validation rule.
+type FieldError struct {
+	// Field is the name of the field in the request, like "email" or
+	// "address.city", taken from its json (or query, form, path) tag.
+	Field string `json:"field"`
+
+	// Rule is the rule that failed, like "required".
+	Rule string `json:"rule"`
+
+	// Param is the rule's parameter, like "3" for min=3.
+	Param string `json:"param,omitempty"`
+
+	// Message is the error message, translated into the request's locale.
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists the fields that failed validation. A handler that
+// returns it (as Bind* do) responds with a 422 problem response listing
+// the fields.
+type ValidationErrors []FieldError
+
+// Error implements the error interface.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// StructValidator checks structs against their `validate` tags, like
+// `validate:"required,max=100"`. Rules are separated by commas and run in
+// order; omitempty skips the remaining rules of an empty field. Nested
+// structs, and slices of them, are validated too.
+//
+// Built-in rules:
+//
+//	required          not the zero value (a non-nil pointer)
+//	omitempty         skip the other rules when empty
+//	min=n, max=n      length of strings, slices and maps; value of numbers
+//	len=n             exact length, or value
+//	gt, gte, lt, lte  like min and max, exclusive or inclusive
+//	oneof=a b c       one of the space-separated values
+//	email, url, uuid  well-formed address, absolute URL or UUID
+//	alpha, alphanum, numeric
+//
+// Each App has a StructValidator with the built-in rules; add rules with
+// App.RegisterValidation. A StructValidator is safe for concurrent use.
+type StructValidator struct {
+	mu       sync.RWMutex
+	rules    map[string]ValidationRule
+	messages map[string]string
+}
+
+// NewStructValidator returns a StructValidator with the built-in rules.
+func NewStructValidator() *StructValidator {
+	v := &StructValidator{rules: make(map[string]ValidationRule), messages: make(map[string]string)}
+	for name, rule := range builtinRules {
+		v.rules[name] = rule
+	}
+	for key, msg := range defaultValidationMessages {
+		v.messages[key] = msg
+	}
+	return v
+}
+
+// Register adds a rule, replacing a built-in rule of the same name. message
+// is the default error message; {field} and {param} are replaced by the
+// field name and the rule's parameter. Translate it with the catalog key
+// "validation.<name>".
+func (v *StructValidator) Register(name string, rule ValidationRule, message string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+	v.messages[name] = message
+}
+
+// Validate checks the struct s, or the struct s points to, and returns
+// ValidationErrors when a field fails. Messages are the default English
+// messages; c.Validate translates them into the request's locale.
+func (v *StructValidator) Validate(s any) error {
+	return v.validate(s, "", nil)
+}
+
+// defaultValidator validates for contexts not created by an App.
+var defaultValidator = NewStructValidator()
+
+// Validate checks s against its `validate` tags with the built-in rules.
+// See StructValidator.
+func Validate(s any) error {
+	return defaultValidator.Validate(s)
+}
+
+// RegisterValidation adds a validation rule for c.Validate and Bind*. See
+// StructValidator.Register.
+func (a *App) RegisterValidation(name string, rule ValidationRule, message string) {
+	a.routeTree.validator.Register(name, rule, message)
+}
+
+// Validate checks s against its `validate` tags with the app's rules, and
+// returns ValidationErrors with messages in the request's locale when a
+// field fails. The catalog key of a rule's message is "validation.<rule>"
+// (min, max and len use "validation.<rule>.string" for strings and
+// "validation.<rule>.items" for slices and maps).
+//
+// Bind, BindQuery, BindForm and BindPath call it after binding, so
+// handlers usually only need to return their error.
+func (c *Context) Validate(s any) error {
+	return c.validate(s, "")
+}
+
+// validate checks the fields of s bound from source; see belongsTo.
+func (c *Context) validate(s any, source string) error {
+	v := c.validator
+	if v == nil {
+		v = defaultValidator
+	}
+	return v.validate(s, source, func(key string) (string, bool) {
+		msg := c.T("validation." + key)
+		return msg, msg != "validation."+key
+	})
+}
+
+// validate checks s. Fields bound by another source than source are
+// skipped, and translate returns the message of a key when a catalog has
+// it.
+func (v *StructValidator) validate(s any, source string, translate func(key string) (string, bool)) error {
+	rv := reflect.ValueOf(s)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("nexo: validate: want a struct, got %T", s)
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	w := &validation{v: v, source: source, translate: translate}
+	if err := w.structFields(rv, ""); err != nil {
+		return err
+	}
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+	return nil
+}
+
+// validation collects the errors of one Validate call.
+type validation struct {
+	v         *StructValidator
+	source    string
+	translate func(key string) (string, bool)
+	errs      ValidationErrors
+}
+
+// structFields validates the fields of the struct rv; prefix is the name of
+// the enclosing field, like "address.".
+func (w *validation) structFields(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() || !belongsTo(field, w.source) {
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		name, ok := bindKey(field, orDefault(w.source, "json"))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+
+		// Embedded structs without a tag of their own are flattened
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			if err := w.structFields(fv, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag != "" {
+			if err := w.field(fv, prefix+name, tag); err != nil {
+				return err
+			}
+		}
+		if err := w.nested(fv, prefix+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nested validates struct fields and slices of structs.
+func (w *validation) nested(fv reflect.Value, name string) error {
+	fv = indirect(fv)
+	if !fv.IsValid() || isLeafType(fv.Type()) {
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		return w.structFields(fv, name+".")
+	case reflect.Slice, reflect.Array:
+		for i := range fv.Len() {
+			elem := indirect(fv.Index(i))
+			if elem.IsValid() && elem.Kind() == reflect.Struct && !isLeafType(elem.Type()) {
+				if err := w.structFields(elem, fmt.Sprintf("%s[%d].", name, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// field runs the rules of tag on fv and records the first that fails.
+func (w *validation) field(fv reflect.Value, name, tag string) error {
+	for _, spec := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		switch rule {
+		case "":
+			continue
+		case "omitempty":
+			if isEmptyValue(fv) {
+				return nil
+			}
+			continue
+		case "required":
+			if isEmptyValue(fv) {
+				w.fail(fv, name, rule, param)
+				return nil
+			}
+			continue
+		}
+
+		check, ok := w.v.rules[rule]
+		if !ok {
+			return fmt.Errorf("nexo: validate: unknown rule %q on field %s", rule, name)
+		}
+		value := indirect(fv)
+		if !value.IsValid() {
+			continue // a nil pointer only fails required
+		}
+		if !check(value, param) {
+			w.fail(value, name, rule, param)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fail records a failed rule with its message.
+func (w *validation) fail(fv reflect.Value, name, rule, param string) {
+	key := rule
+	if _, sized := sizeRules[rule]; sized {
+		switch indirect(fv).Kind() {
+		case reflect.String:
+			key += ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key += ".items"
+		}
+	}
+
+	msg, ok := "", false
+	if w.translate != nil {
+		msg, ok = w.translate(key)
+		if !ok && key != rule {
+			msg, ok = w.translate(rule)
+		}
+	}
+	if !ok {
+		msg, ok = w.v.messages[key]
+	}
+	if !ok {
+		msg, ok = w.v.messages[rule]
+	}
+	if !ok {
+		msg = "{field} is invalid"
+	}
+	msg = strings.NewReplacer("{field}", name, "{param}", param).Replace(msg)
+	w.errs = append(w.errs, FieldError{Field: name, Rule: rule, Param: param, Message: msg})
+}
+
+// belongsTo reports whether a field is bound from source. Fields tagged for
+// another source only (like `path:"id"` when binding the query) are checked
+// when that source is bound. All fields belong to the empty source.
+func belongsTo(field reflect.StructField, source string) bool {
+	if source == "" {
+		return true
+	}
+	tagged := false
+	for _, s := range [...]string{"query", "form", "path"} {
+		if _, ok := field.Tag.Lookup(s); ok {
+			if s == source {
+				return true
+			}
+			tagged = true
+		}
+	}
+	if source == "json" {
+		_, ok := field.Tag.Lookup("json")
+		return ok || !tagged
+	}
+	return !tagged
+}
+
+// isLeafType reports whether values of t are validated as a whole, not
+// field by field.
+func isLeafType(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// indirect dereferences pointers, returning the zero Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmptyValue reports whether v is nil, the zero value, or empty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// ---------- Built-in Rules ----------
+
+// sizeRules compare lengths of strings, slices and maps, and have separate
+// messages for them.
+var sizeRules = map[string]struct{}{
+	"min": {}, "max": {}, "len": {}, "gt": {}, "gte": {}, "lt": {}, "lte": {},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var builtinRules = map[string]ValidationRule{
+	"min": compareRule(func(n, p float64) bool { return n >= p }),
+	"max": compareRule(func(n, p float64) bool { return n <= p }),
+	"len": compareRule(func(n, p float64) bool { return n == p }),
+	"gt":  compareRule(func(n, p float64) bool { return n > p }),
+	"gte": compareRule(func(n, p float64) bool { return n >= p }),
+	"lt":  compareRule(func(n, p float64) bool { return n < p }),
+	"lte": compareRule(func(n, p float64) bool { return n <= p }),
+	"oneof": func(v reflect.Value, param string) bool {
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(param) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	},
+	"email": stringRule(func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	}),
+	"url": stringRule(func(s string) bool {
+		u, err := url.ParseRequestURI(s)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}),
+	"uuid": func(v reflect.Value, _ string) bool {
+		if t, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, err := t.MarshalText()
+			return err == nil && uuidPattern.Match(text)
+		}
+		return v.Kind() == reflect.String && uuidPattern.MatchString(v.String())
+	},
+	"alpha":    stringRule(allRunes(unicode.IsLetter)),
+	"alphanum": stringRule(allRunes(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })),
+	"numeric": stringRule(func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	}),
+}
+
+// compareRule compares the length of strings, slices and maps, or the value
+// of numbers, with the rule's numeric parameter.
+func compareRule(cmp func(n, param float64) bool) ValidationRule {
+	return func(v reflect.Value, param string) bool {
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false
+		}
+		var n float64
+		switch v.Kind() {
+		case reflect.String:
+			n = float64(utf8.RuneCountInString(v.String()))
+		case reflect.Slice, reflect.Array, reflect.Map:
+			n = float64(v.Len())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		default:
+			return false
+		}
+		return cmp(n, p)
+	}
+}
+
+// stringRule checks string fields; other kinds fail.
+func stringRule(check func(string) bool) ValidationRule {
+	return func(v reflect.Value, _ string) bool {
+		return v.Kind() == reflect.String && check(v.String())
+	}
+}
+
+// allRunes reports whether a non-empty string has only runes passing is.
+func allRunes(is func(rune) bool) func(string) bool {
+	return func(s string) bool {
+		if s == "" {
+			return false
+		}
+		for _, r := range s {
+			if !is(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// defaultValidationMessages are the English messages of the built-in rules.
+var defaultValidationMessages = map[string]string{
+	"required":   "{field} is required",
+	"min":        "{field} must be at least {param}",
+	"min.string": "{field} must be at least {param} characters long",
+	"min.items":  "{field} must have at least {param} items",
+	"max":        "{field} must be at most {param}",
+	"max.string": "{field} must be at most {param} characters long",
+	"max.items":  "{field} must have at most {param} items",
+	"len":        "{field} must be {param}",
+	"len.string": "{field} must be {param} characters long",
+	"len.items":  "{field} must have {param} items",
+	"gt":         "{field} must be greater than {param}",
+	"gt.string":  "{field} must be longer than {param} characters",
+	"gt.items":   "{field} must have more than {param} items",
+	"gte":        "{field} must be at least {param}",
+	"gte.string": "{field} must be at least {param} characters long",
+	"gte.items":  "{field} must have at least {param} items",
+	"lt":         "{field} must be less than {param}",
+	"lt.string":  "{field} must be shorter than {param} characters",
+	"lt.items":   "{field} must have fewer than {param} items",
+	"lte":        "{field} must be at most {param}",
+	"lte.string": "{field} must be at most {param} characters long",
+	"lte.items":  "{field} must have at most {param} items",
+	"oneof":      "{field} must be one of: {param}",
+	"email":      "{field} must be a valid email address",
+	"url":        "{field} must be a valid URL",
+	"uuid":       "{field} must be a valid UUID",
+	"alpha":      "{field} must contain only letters",
+	"alphanum":   "{field} must contain only letters and digits",
+	"numeric":    "{field} must be a number",
+}
+
+// defaultValidationDetail is the detail of validation problem responses,
+// translated with the catalog key "validation.failed".
+const defaultValidationDetail = "The request has invalid fields."
+
+// ---------- Problem Responses ----------
+
+// validationProblem is the RFC 9457 problem response for ValidationErrors.
+type validationProblem struct {
+	Type   string           `json:"type"`
+	Title  string           `json:"title"`
+	Status int              `json:"status"`
+	Detail string           `json:"detail"`
+	Errors ValidationErrors `json:"errors"`
+}
+
+// writeValidationErrors responds with a 422 problem listing the failed
+// fields.
+func writeValidationErrors(c *Context, errs ValidationErrors) {
+	detail := defaultValidationDetail
+	if msg := c.T("validation.failed"); msg != "validation.failed" {
+		detail = msg
+	}
+	const status = http.StatusUnprocessableEntity
+	c.SetHeader("Content-Type", "application/problem+json")
+	c.Response.WriteHeader(status)
+	c.written = true
+	c.status = status
+	_ = c.jsonCodec().Encode(c.Response, validationProblem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Errors: errs,
+	})
+}
+
+// asValidationErrors returns the ValidationErrors in err's chain.
+func asValidationErrors(err error) (ValidationErrors, bool) {
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs, true
+	}
+	return nil, false
+}
@@ -0,0 +1,241 @@
+package nexo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/i18n"
+)
+
+func TestValidate_Rules(t *testing.T) {
+	type address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type input struct {
+		Name    string    `json:"name" validate:"required,min=2,max=5"`
+		Email   string    `json:"email" validate:"omitempty,email"`
+		Age     int       `json:"age" validate:"gte=18,lt=130"`
+		Role    string    `json:"role" validate:"oneof=admin member"`
+		Site    string    `json:"site" validate:"omitempty,url"`
+		ID      string    `json:"id" validate:"omitempty,uuid"`
+		Code    string    `json:"code" validate:"omitempty,alphanum,len=4"`
+		Tags    []string  `json:"tags" validate:"max=2"`
+		Nick    *string   `json:"nick" validate:"omitempty,alpha"`
+		Address address   `json:"address"`
+		Others  []address `json:"others"`
+	}
+	valid := input{Name: "ada", Age: 36, Role: "admin", Address: address{City: "London"}}
+	bad := "x1"
+
+	tests := []struct {
+		name   string
+		modify func(*input)
+		want   []string // field:rule
+	}{
+		{name: "valid", modify: func(*input) {}},
+		{name: "required", modify: func(in *input) { in.Name = "" }, want: []string{"name:required"}},
+		{name: "length", modify: func(in *input) { in.Name = "adalovelace" }, want: []string{"name:max"}},
+		{name: "email", modify: func(in *input) { in.Email = "ada@" }, want: []string{"email:email"}},
+		{name: "number range", modify: func(in *input) { in.Age = 17 }, want: []string{"age:gte"}},
+		{name: "oneof", modify: func(in *input) { in.Role = "root" }, want: []string{"role:oneof"}},
+		{name: "url", modify: func(in *input) { in.Site = "example.com" }, want: []string{"site:url"}},
+		{name: "uuid", modify: func(in *input) { in.ID = "123" }, want: []string{"id:uuid"}},
+		{name: "first failing rule", modify: func(in *input) { in.Code = "ab-d" }, want: []string{"code:alphanum"}},
+		{name: "items", modify: func(in *input) { in.Tags = []string{"a", "b", "c"} }, want: []string{"tags:max"}},
+		{name: "pointer", modify: func(in *input) { in.Nick = &bad }, want: []string{"nick:alpha"}},
+		{name: "nested", modify: func(in *input) { in.Address.City = "" }, want: []string{"address.city:required"}},
+		{name: "slice of structs", modify: func(in *input) { in.Others = []address{{City: "Paris"}, {}} }, want: []string{"others[1].city:required"}},
+		{
+			name:   "several fields",
+			modify: func(in *input) { in.Name, in.Age = "", 200 },
+			want:   []string{"name:required", "age:lt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid
+			tt.modify(&in)
+			err := Validate(&in)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v, want ValidationErrors", err)
+			}
+			var got []string
+			for _, fe := range errs {
+				got = append(got, fe.Field+":"+fe.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_Messages(t *testing.T) {
+	type input struct {
+		Name string   `json:"name" validate:"min=3"`
+		Tags []string `json:"tags" validate:"min=1"`
+	}
+	err := Validate(input{Name: "a"})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Validate() = %v", err)
+	}
+	want := []string{"name must be at least 3 characters long", "tags must have at least 1 items"}
+	for i, fe := range errs {
+		if fe.Message != want[i] {
+			t.Errorf("message = %q, want %q", fe.Message, want[i])
+		}
+	}
+}
+
+func TestValidate_UnknownRule(t *testing.T) {
+	err := Validate(struct {
+		Name string `validate:"shiny"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), `unknown rule "shiny"`) {
+		t.Errorf("Validate() = %v", err)
+	}
+	if _, ok := asValidationErrors(err); ok {
+		t.Error("unknown rule reported as invalid input")
+	}
+}
+
+// problem decodes a validation problem response.
+func problem(t *testing.T, w *httptest.ResponseRecorder) validationProblem {
+	t.Helper()
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var p validationProblem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestBind_ValidationProblem(t *testing.T) {
+	type signup struct {
+		Email string `json:"email" validate:"required,email"`
+		Plan  string `json:"plan" validate:"plan"`
+	}
+	app := New()
+	app.RegisterValidation("plan", func(v reflect.Value, _ string) bool {
+		return strings.HasPrefix(v.String(), "plan_")
+	}, "{field} is not a plan")
+	app.Post("/signup", func(c *Context) error {
+		var in signup
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Mount()
+
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"email":"nope","plan":"gold"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	p := problem(t, w)
+	want := ValidationErrors{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		{Field: "plan", Rule: "plan", Message: "plan is not a plan"},
+	}
+	if p.Status != 422 || !reflect.DeepEqual(p.Errors, want) {
+		t.Errorf("problem = %+v, want errors %+v", p, want)
+	}
+
+	// Custom rules are registered per app
+	if err := Validate(signup{Email: "a@b.co", Plan: "gold"}); err == nil || !strings.Contains(err.Error(), "unknown rule") {
+		t.Errorf("Validate() with another app's rule = %v", err)
+	}
+}
+
+func TestBind_ValidationSources(t *testing.T) {
+	type params struct {
+		ID   int    `path:"id" validate:"min=1"`
+		Sort string `query:"sort" validate:"required,oneof=asc desc"`
+	}
+	app := New()
+	app.Get("/items/{id}", func(c *Context) error {
+		var p params
+		// BindPath leaves the query field to BindQuery
+		if err := c.BindPath(&p); err != nil {
+			return err
+		}
+		if err := c.BindQuery(&p); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+	app.Mount()
+
+	tests := []struct {
+		target    string
+		wantField string
+	}{
+		{target: "/items/3?sort=asc"},
+		{target: "/items/0?sort=asc", wantField: "id"},
+		{target: "/items/3", wantField: "sort"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if tt.wantField == "" {
+				if w.Code != http.StatusNoContent {
+					t.Errorf("status = %d: %s", w.Code, w.Body.String())
+				}
+				return
+			}
+			if p := problem(t, w); len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField {
+				t.Errorf("errors = %+v, want field %s", p.Errors, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestContext_ValidateTranslated(t *testing.T) {
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("es", map[string]string{
+		"validation.required": "{field} es obligatorio",
+		"validation.failed":   "La solicitud tiene campos inválidos.",
+	})
+	app := New(WithI18n(bundle))
+	app.Post("/", func(c *Context) error {
+		var in struct {
+			Name string `json:"name" validate:"required"`
+			Age  int    `json:"age" validate:"min=18"`
+		}
+		return c.Bind(&in)
+	})
+	app.Mount()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age":3}`))
+	req.Header.Set("Accept-Language", "es")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	p := problem(t, w)
+	if p.Detail != "La solicitud tiene campos inválidos." {
+		t.Errorf("detail = %q", p.Detail)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Message != "name es obligatorio" || p.Errors[1].Message != "age must be at least 18" {
+		t.Errorf("errors = %+v, want a translated required message and the default min message", p.Errors)
+	}
+}
@@ -0,0 +1,175 @@
+package nexo
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// apiVersionKey is the store key of the negotiated API version.
+const apiVersionKey = "nexo.apiVersion"
+
+// versionSegmentRe matches version path segments like v1, v2 or v2.1.
+var versionSegmentRe = regexp.MustCompile(`^v\d+(\.\d+)*$`)
+
+// PatternVersion returns the version segment of a route pattern, like "v1"
+// for /api/v1/users, or "" when the pattern isn't versioned.
+func PatternVersion(pattern string) string {
+	for _, seg := range strings.Split(pattern, "/") {
+		if versionSegmentRe.MatchString(seg) {
+			return seg
+		}
+	}
+	return ""
+}
+
+// Deprecation describes a deprecated API version. Responses for it carry
+// Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers.
+type Deprecation struct {
+	// Date is when the version was deprecated. Zero sends "Deprecation: true".
+	Date time.Time
+
+	// Sunset is when the version stops being served (optional).
+	Sunset time.Time
+
+	// Link points to migration docs (optional).
+	Link string
+}
+
+// VersionConfig holds configuration for the API version middleware.
+type VersionConfig struct {
+	// Versions lists the supported versions, oldest first, e.g.
+	// []string{"v1", "v2"}. Requests for other versions get a 400.
+	Versions []string
+
+	// Default is the version of requests that don't ask for one.
+	// Default is the last of Versions.
+	Default string
+
+	// Header is the request header that selects a version. Default is
+	// "API-Version". The negotiated version is echoed in the response.
+	Header string
+
+	// MediaType is the vendor media type of Accept based negotiation: with
+	// "application/vnd.acme", "Accept: application/vnd.acme.v2+json"
+	// selects v2. Empty disables it.
+	MediaType string
+
+	// QueryParam is the query parameter that selects a version, like
+	// "version". Empty disables it.
+	QueryParam string
+
+	// Deprecated maps deprecated versions to their deprecation details.
+	Deprecated map[string]Deprecation
+}
+
+// APIVersion returns a middleware that negotiates the API version of each
+// request. The version comes from, in order: a version segment in the route
+// pattern (/api/v1/users), the version header, the Accept header's vendor
+// media type, the query parameter, and finally the default. Handlers read
+// it with c.APIVersion().
+func APIVersion(config VersionConfig) MiddlewareFunc {
+	if config.Header == "" {
+		config.Header = "API-Version"
+	}
+	if config.Default == "" && len(config.Versions) > 0 {
+		config.Default = config.Versions[len(config.Versions)-1]
+	}
+	supported := make(map[string]bool, len(config.Versions))
+	for _, v := range config.Versions {
+		supported[v] = true
+	}
+	vary := config.Header
+	if config.MediaType != "" {
+		vary += ", Accept"
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			version := requestVersion(c, config)
+			if version == "" {
+				version = config.Default
+			}
+			if len(supported) > 0 && !supported[version] {
+				return BadRequest(fmt.Sprintf("unsupported API version %q (supported: %s)", version, strings.Join(config.Versions, ", ")))
+			}
+
+			c.Set(apiVersionKey, version)
+			c.SetHeader(config.Header, version)
+			c.AddHeader("Vary", vary)
+			if d, ok := config.Deprecated[version]; ok {
+				setDeprecationHeaders(c, d)
+			}
+			return next(c)
+		}
+	}
+}
+
+// requestVersion returns the version a request asks for, or "".
+func requestVersion(c *Context, config VersionConfig) string {
+	if rctx := chi.RouteContext(c.Request.Context()); rctx != nil {
+		if v := PatternVersion(rctx.RoutePattern()); v != "" {
+			return v
+		}
+	}
+	if v := c.Header(config.Header); v != "" {
+		return normalizeVersion(v)
+	}
+	if config.MediaType != "" {
+		prefix := config.MediaType + "."
+		for _, accept := range strings.Split(c.Header("Accept"), ",") {
+			mediaType, _, _ := strings.Cut(strings.TrimSpace(accept), ";")
+			if rest, ok := strings.CutPrefix(mediaType, prefix); ok {
+				v, _, _ := strings.Cut(rest, "+")
+				return normalizeVersion(v)
+			}
+		}
+	}
+	if config.QueryParam != "" {
+		if v := c.Query(config.QueryParam); v != "" {
+			return normalizeVersion(v)
+		}
+	}
+	return ""
+}
+
+// normalizeVersion accepts versions with or without the "v" prefix.
+func normalizeVersion(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v != "" && v[0] != 'v' {
+		v = "v" + v
+	}
+	return v
+}
+
+// setDeprecationHeaders announces that the requested version is deprecated.
+func setDeprecationHeaders(c *Context, d Deprecation) {
+	if d.Date.IsZero() {
+		c.SetHeader("Deprecation", "true")
+	} else {
+		c.SetHeader("Deprecation", fmt.Sprintf("@%d", d.Date.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		c.SetHeader("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.AddHeader("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+	}
+}
+
+// APIVersion returns the API version negotiated by the APIVersion
+// middleware, or the version segment of the route pattern when the
+// middleware isn't used.
+func (c *Context) APIVersion() string {
+	if v := c.GetString(apiVersionKey); v != "" {
+		return v
+	}
+	if rctx := chi.RouteContext(c.Request.Context()); rctx != nil {
+		return PatternVersion(rctx.RoutePattern())
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package nexo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPatternVersion(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/users":      "v1",
+		"/api/v2.1/users":    "v2.1",
+		"/api/users":         "",
+		"/api/vault/{id}":    "",
+		"/v3":                "v3",
+		"/api/{version}/foo": "",
+	}
+	for pattern, want := range tests {
+		if got := PatternVersion(pattern); got != want {
+			t.Errorf("PatternVersion(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestAPIVersion(t *testing.T) {
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	app := New()
+	app.Use(APIVersion(VersionConfig{
+		Versions:   []string{"v1", "v2"},
+		MediaType:  "application/vnd.acme",
+		QueryParam: "version",
+		Deprecated: map[string]Deprecation{
+			"v1": {Sunset: sunset, Link: "https://example.com/migrate"},
+		},
+	}))
+	version := func(c *Context) error { return c.String(http.StatusOK, c.APIVersion()) }
+	app.Get("/users", version)
+	app.Get("/api/v1/users", version)
+	app.Mount()
+
+	tests := []struct {
+		name       string
+		path       string
+		header     [2]string
+		status     int
+		want       string
+		deprecated bool
+	}{
+		{"default is latest", "/users", [2]string{}, http.StatusOK, "v2", false},
+		{"header", "/users", [2]string{"API-Version", "1"}, http.StatusOK, "v1", true},
+		{"accept media type", "/users", [2]string{"Accept", "application/vnd.acme.v1+json"}, http.StatusOK, "v1", true},
+		{"query", "/users?version=v1", [2]string{}, http.StatusOK, "v1", true},
+		{"path segment", "/api/v1/users", [2]string{"API-Version", "v2"}, http.StatusOK, "v1", true},
+		{"unsupported", "/users", [2]string{"API-Version", "v9"}, http.StatusBadRequest, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header[0] != "" {
+				req.Header.Set(tt.header[0], tt.header[1])
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if w.Body.String() != tt.want || w.Header().Get("API-Version") != tt.want {
+				t.Errorf("version = %q (header %q), want %q", w.Body.String(), w.Header().Get("API-Version"), tt.want)
+			}
+			if got := w.Header().Get("Deprecation") != ""; got != tt.deprecated {
+				t.Errorf("Deprecation header set = %v, want %v", got, tt.deprecated)
+			}
+			if tt.deprecated {
+				if got := w.Header().Get("Sunset"); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+					t.Errorf("Sunset = %q", got)
+				}
+				if got := w.Header().Get("Link"); got != `<https://example.com/migrate>; rel="deprecation"` {
+					t.Errorf("Link = %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package nexo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------- Idempotency ----------
+
+// Once runs fn once per key within ttl, across every instance sharing the
+// app's cache, and reports whether it ran. When fn fails the key is
+// released, so a retry runs it again. Use it to make handlers of retried
+// deliveries, like webhooks or payment callbacks, safe to repeat:
+//
+//	ran, err := c.Once("invoice:"+event.ID, 24*time.Hour, func() error {
+//	    return billing.MarkPaid(c.Context(), invoiceID)
+//	})
+func (c *Context) Once(key string, ttl time.Duration, fn func() error) (bool, error) {
+	key = "once:" + key
+	n, err := incrCounter(c.Context(), c.Cache(), key, ttl)
+	if err != nil {
+		return false, err
+	}
+	if n > 1 {
+		return false, nil
+	}
+	if err := fn(); err != nil {
+		if derr := c.Cache().Delete(context.WithoutCancel(c.Context()), key); derr != nil {
+			log.Printf("nexo: once: %v", derr)
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+// ---------- Webhook Middleware ----------
+
+// ErrWebhookSignature is returned by WebhookVerifiers for deliveries whose
+// signature is missing or wrong.
+var ErrWebhookSignature = errors.New("invalid webhook signature")
+
+// ErrWebhookExpired is returned by WebhookVerifiers for deliveries signed
+// outside their tolerance, which are likely replays.
+var ErrWebhookExpired = errors.New("webhook timestamp outside tolerance")
+
+// WebhookVerifier checks that a webhook delivery was sent by its provider.
+type WebhookVerifier interface {
+	// Verify checks the signature of a delivery with the given body.
+	Verify(r *http.Request, body []byte) error
+}
+
+// WebhookVerifierFunc adapts a function to a WebhookVerifier.
+type WebhookVerifierFunc func(r *http.Request, body []byte) error
+
+// Verify implements WebhookVerifier.
+func (f WebhookVerifierFunc) Verify(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+// WebhookConfig holds configuration for the webhook middleware.
+type WebhookConfig struct {
+	// Verifier checks the signature of deliveries. Required.
+	Verifier WebhookVerifier
+
+	// EventID returns the ID deliveries are deduplicated by; "" skips
+	// deduplication. Default reads the Webhook-Id, X-GitHub-Delivery,
+	// X-Shopify-Webhook-Id and Idempotency-Key headers, then the "id" of a
+	// JSON body, which covers Stripe.
+	EventID func(c *Context, body []byte) string
+
+	// DedupTTL is how long delivered IDs are remembered. Default is 24
+	// hours; negative disables deduplication.
+	DedupTTL time.Duration
+
+	// MaxBody bounds the size of deliveries. Default is 1 MB.
+	MaxBody int64
+}
+
+// Webhook returns a middleware for routes receiving webhooks: it keeps the
+// raw body for signature checks (see RawBody), rejects deliveries that
+// fail verifier with 401, and answers redeliveries of an event that was
+// already handled with 200 without running the handler again.
+//
+// Example:
+//
+//	// app/webhooks/stripe/middleware.go
+//	func Middleware(next nexo.HandlerFunc) nexo.HandlerFunc {
+//	    return nexo.Webhook(nexo.StripeSignature(os.Getenv("STRIPE_WEBHOOK_SECRET")))(next)
+//	}
+func Webhook(verifier WebhookVerifier) MiddlewareFunc {
+	return WebhookWithConfig(WebhookConfig{Verifier: verifier})
+}
+
+// WebhookWithConfig returns a webhook middleware with custom configuration.
+func WebhookWithConfig(config WebhookConfig) MiddlewareFunc {
+	if config.Verifier == nil {
+		panic("nexo: WebhookConfig.Verifier is required")
+	}
+	if config.EventID == nil {
+		config.EventID = defaultWebhookEventID
+	}
+	if config.DedupTTL == 0 {
+		config.DedupTTL = 24 * time.Hour
+	}
+	if config.MaxBody <= 0 {
+		config.MaxBody = 1 << 20
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Request.Body = http.MaxBytesReader(c.Response, c.Request.Body, config.MaxBody)
+			body, err := c.RawBody()
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return NewHTTPError(http.StatusRequestEntityTooLarge, "webhook too large")
+				}
+				return BadRequest("failed to read webhook")
+			}
+			if err := config.Verifier.Verify(c.Request, body); err != nil {
+				return NewHTTPErrorWithCause(http.StatusUnauthorized, err.Error(), err)
+			}
+
+			id := config.EventID(c, body)
+			if id == "" || config.DedupTTL < 0 {
+				return next(c)
+			}
+			ran, err := c.Once("webhook:"+c.Path()+":"+id, config.DedupTTL, func() error {
+				return next(c)
+			})
+			if err != nil {
+				return err
+			}
+			if !ran {
+				return c.JSON(http.StatusOK, map[string]string{"status": "duplicate"})
+			}
+			return nil
+		}
+	}
+}
+
+// defaultWebhookEventID reads the event ID of common providers.
+func defaultWebhookEventID(c *Context, body []byte) string {
+	for _, header := range []string{"Webhook-Id", "X-GitHub-Delivery", "X-Shopify-Webhook-Id", "Idempotency-Key"} {
+		if id := c.Header(header); id != "" {
+			return id
+		}
+	}
+	var event struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(body, &event) == nil {
+		return event.ID
+	}
+	return ""
+}
+
+// ---------- Webhook Verifiers ----------
+
+// defaultWebhookTolerance is how old a signed timestamp may be.
+const defaultWebhookTolerance = 5 * time.Minute
+
+// StripeSignature verifies the Stripe-Signature header of Stripe webhooks
+// with the endpoint's signing secret (whsec_...), rejecting deliveries
+// signed more than 5 minutes ago.
+func StripeSignature(secret string) WebhookVerifier {
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(r.Header.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if err := checkWebhookTimestamp(timestamp, defaultWebhookTolerance); err != nil {
+			return err
+		}
+		expected := webhookMAC(sha256.New, []byte(secret), timestamp+".", body)
+		for _, sig := range signatures {
+			if hmacEqualHex(sig, expected) {
+				return nil
+			}
+		}
+		return ErrWebhookSignature
+	})
+}
+
+// GitHubSignature verifies the X-Hub-Signature-256 header of GitHub
+// webhooks with the webhook's secret.
+func GitHubSignature(secret string) WebhookVerifier {
+	return HMACSignature("X-Hub-Signature-256", "sha256=", secret)
+}
+
+// SlackSignature verifies the X-Slack-Signature header of Slack requests
+// with the app's signing secret, rejecting requests signed more than 5
+// minutes ago.
+func SlackSignature(secret string) WebhookVerifier {
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+		if err := checkWebhookTimestamp(timestamp, defaultWebhookTolerance); err != nil {
+			return err
+		}
+		sig, ok := strings.CutPrefix(r.Header.Get("X-Slack-Signature"), "v0=")
+		if !ok || !hmacEqualHex(sig, webhookMAC(sha256.New, []byte(secret), "v0:"+timestamp+":", body)) {
+			return ErrWebhookSignature
+		}
+		return nil
+	})
+}
+
+// ShopifySignature verifies the X-Shopify-Hmac-Sha256 header of Shopify
+// webhooks with the app's client secret.
+func ShopifySignature(secret string) WebhookVerifier {
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		sig, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Shopify-Hmac-Sha256"))
+		if err != nil || !hmac.Equal(sig, webhookMAC(sha256.New, []byte(secret), "", body)) {
+			return ErrWebhookSignature
+		}
+		return nil
+	})
+}
+
+// StandardSignature verifies webhooks following the Standard Webhooks spec
+// (webhook-id, webhook-timestamp and webhook-signature headers), as sent by
+// Svix and the providers built on it, rejecting deliveries signed more than
+// 5 minutes ago. secret is the base64 secret, with or without its whsec_
+// prefix.
+func StandardSignature(secret string) WebhookVerifier {
+	secret = strings.TrimPrefix(secret, "whsec_")
+	key, keyErr := base64.StdEncoding.DecodeString(secret)
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		if keyErr != nil {
+			return fmt.Errorf("invalid webhook secret: %w", keyErr)
+		}
+		id, timestamp := r.Header.Get("Webhook-Id"), r.Header.Get("Webhook-Timestamp")
+		if err := checkWebhookTimestamp(timestamp, defaultWebhookTolerance); err != nil {
+			return err
+		}
+		expected := webhookMAC(sha256.New, key, id+"."+timestamp+".", body)
+		for _, sig := range strings.Fields(r.Header.Get("Webhook-Signature")) {
+			version, value, _ := strings.Cut(sig, ",")
+			if version != "v1" {
+				continue
+			}
+			if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && hmac.Equal(decoded, expected) {
+				return nil
+			}
+		}
+		return ErrWebhookSignature
+	})
+}
+
+// HMACSignature verifies a header holding the hex HMAC-SHA256 of the body
+// after prefix, like "sha256=3f1c…", for providers without a dedicated
+// verifier. An empty prefix expects the bare digest.
+func HMACSignature(header, prefix, secret string) WebhookVerifier {
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		sig, ok := strings.CutPrefix(r.Header.Get(header), prefix)
+		if !ok || !hmacEqualHex(sig, webhookMAC(sha256.New, []byte(secret), "", body)) {
+			return ErrWebhookSignature
+		}
+		return nil
+	})
+}
+
+// HMACSHA1Signature is HMACSignature for providers still signing with
+// HMAC-SHA1.
+func HMACSHA1Signature(header, prefix, secret string) WebhookVerifier {
+	return requireWebhookSecret(secret, func(r *http.Request, body []byte) error {
+		sig, ok := strings.CutPrefix(r.Header.Get(header), prefix)
+		if !ok || !hmacEqualHex(sig, webhookMAC(sha1.New, []byte(secret), "", body)) {
+			return ErrWebhookSignature
+		}
+		return nil
+	})
+}
+
+// requireWebhookSecret returns verify, or a verifier rejecting every
+// delivery when secret is empty: anyone can sign with an empty key, as
+// when the secret's environment variable isn't set.
+func requireWebhookSecret(secret string, verify WebhookVerifierFunc) WebhookVerifier {
+	if secret == "" {
+		return WebhookVerifierFunc(func(*http.Request, []byte) error {
+			return ErrWebhookSignature
+		})
+	}
+	return verify
+}
+
+// webhookMAC returns the HMAC of prefix followed by body.
+func webhookMAC(h func() hash.Hash, key []byte, prefix string, body []byte) []byte {
+	mac := hmac.New(h, key)
+	mac.Write([]byte(prefix))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// hmacEqualHex compares a hex signature with an expected MAC in constant
+// time.
+func hmacEqualHex(sig string, expected []byte) bool {
+	decoded, err := hex.DecodeString(strings.TrimSpace(sig))
+	return err == nil && hmac.Equal(decoded, expected)
+}
+
+// checkWebhookTimestamp checks that a Unix timestamp is within tolerance
+// of now, which stops replays of captured deliveries.
+func checkWebhookTimestamp(timestamp string, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrWebhookExpired
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package nexo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hexMAC(key []byte, s string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookVerifiers(t *testing.T) {
+	body := `{"id":"evt_1","type":"invoice.paid"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	svixKey := []byte("standard-secret")
+	svixSecret := "whsec_" + base64.StdEncoding.EncodeToString(svixKey)
+	svixSig := func(ts string) string {
+		mac := hmac.New(sha256.New, svixKey)
+		mac.Write([]byte("msg_1." + ts + "." + body))
+		return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	shopifySig := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name     string
+		verifier WebhookVerifier
+		header   map[string]string
+		want     error
+	}{
+		{"stripe", StripeSignature("whsec_s"), map[string]string{
+			"Stripe-Signature": "t=" + now + ",v1=" + hexMAC([]byte("whsec_s"), now+"."+body),
+		}, nil},
+		{"stripe rolled secret", StripeSignature("whsec_s"), map[string]string{
+			"Stripe-Signature": "t=" + now + ",v1=" + hexMAC([]byte("whsec_old"), now+"."+body) + ",v1=" + hexMAC([]byte("whsec_s"), now+"."+body),
+		}, nil},
+		{"stripe wrong secret", StripeSignature("whsec_s"), map[string]string{
+			"Stripe-Signature": "t=" + now + ",v1=" + hexMAC([]byte("whsec_x"), now+"."+body),
+		}, ErrWebhookSignature},
+		{"stripe replay", StripeSignature("whsec_s"), map[string]string{
+			"Stripe-Signature": "t=" + old + ",v1=" + hexMAC([]byte("whsec_s"), old+"."+body),
+		}, ErrWebhookExpired},
+		{"stripe missing", StripeSignature("whsec_s"), nil, ErrWebhookSignature},
+		{"github", GitHubSignature("gh"), map[string]string{
+			"X-Hub-Signature-256": "sha256=" + hexMAC([]byte("gh"), body),
+		}, nil},
+		{"github without prefix", GitHubSignature("gh"), map[string]string{
+			"X-Hub-Signature-256": hexMAC([]byte("gh"), body),
+		}, ErrWebhookSignature},
+		{"slack", SlackSignature("sl"), map[string]string{
+			"X-Slack-Request-Timestamp": now,
+			"X-Slack-Signature":         "v0=" + hexMAC([]byte("sl"), "v0:"+now+":"+body),
+		}, nil},
+		{"slack replay", SlackSignature("sl"), map[string]string{
+			"X-Slack-Request-Timestamp": old,
+			"X-Slack-Signature":         "v0=" + hexMAC([]byte("sl"), "v0:"+old+":"+body),
+		}, ErrWebhookExpired},
+		{"shopify", ShopifySignature("sh"), map[string]string{
+			"X-Shopify-Hmac-Sha256": shopifySig("sh"),
+		}, nil},
+		{"shopify wrong secret", ShopifySignature("sh"), map[string]string{
+			"X-Shopify-Hmac-Sha256": shopifySig("other"),
+		}, ErrWebhookSignature},
+		{"standard", StandardSignature(svixSecret), map[string]string{
+			"Webhook-Id": "msg_1", "Webhook-Timestamp": now, "Webhook-Signature": "v1,bm90LWl0 " + svixSig(now),
+		}, nil},
+		{"standard replay", StandardSignature(svixSecret), map[string]string{
+			"Webhook-Id": "msg_1", "Webhook-Timestamp": old, "Webhook-Signature": svixSig(old),
+		}, ErrWebhookExpired},
+		{"hmac", HMACSignature("X-Signature", "", "k"), map[string]string{
+			"X-Signature": hexMAC([]byte("k"), body),
+		}, nil},
+		{"stripe empty secret", StripeSignature(""), map[string]string{
+			"Stripe-Signature": "t=" + now + ",v1=" + hexMAC(nil, now+"."+body),
+		}, ErrWebhookSignature},
+		{"github empty secret", GitHubSignature(""), map[string]string{
+			"X-Hub-Signature-256": "sha256=" + hexMAC(nil, body),
+		}, ErrWebhookSignature},
+		{"slack empty secret", SlackSignature(""), map[string]string{
+			"X-Slack-Request-Timestamp": now,
+			"X-Slack-Signature":         "v0=" + hexMAC(nil, "v0:"+now+":"+body),
+		}, ErrWebhookSignature},
+		{"shopify empty secret", ShopifySignature(""), map[string]string{
+			"X-Shopify-Hmac-Sha256": shopifySig(""),
+		}, ErrWebhookSignature},
+		{"standard empty secret", StandardSignature("whsec_"), map[string]string{
+			"Webhook-Id": "msg_1", "Webhook-Timestamp": now, "Webhook-Signature": "v1," + base64.StdEncoding.EncodeToString(webhookMAC(sha256.New, nil, "msg_1."+now+".", []byte(body))),
+		}, ErrWebhookSignature},
+		{"hmac-sha1 empty secret", HMACSHA1Signature("X-Signature", "", ""), map[string]string{
+			"X-Signature": hex.EncodeToString(webhookMAC(sha1.New, nil, "", []byte(body))),
+		}, ErrWebhookSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			if err := tt.verifier.Verify(req, []byte(body)); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	secret := "gh"
+	fail := false
+	var handled []string
+	app := New()
+	app.Post("/webhooks/github", Webhook(GitHubSignature(secret))(func(c *Context) error {
+		var event struct {
+			Action string `json:"action"`
+		}
+		if err := c.Bind(&event); err != nil {
+			return err
+		}
+		raw, _ := c.RawBody()
+		if !strings.Contains(string(raw), event.Action) {
+			t.Errorf("RawBody() = %q after Bind", raw)
+		}
+		if fail {
+			return errors.New("database down")
+		}
+		handled = append(handled, event.Action)
+		return c.NoContent()
+	}))
+	app.Mount()
+
+	send := func(delivery, body string, sig string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+sig)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+	body := `{"action":"opened"}`
+
+	if code := send("d1", body, hexMAC([]byte("wrong"), body)); code != http.StatusUnauthorized {
+		t.Errorf("forged delivery got %d, want 401", code)
+	}
+
+	// A failed delivery is handled again when the provider retries it
+	fail = true
+	if code := send("d1", body, hexMAC([]byte(secret), body)); code != http.StatusInternalServerError {
+		t.Errorf("failing handler got %d, want 500", code)
+	}
+	fail = false
+	if code := send("d1", body, hexMAC([]byte(secret), body)); code != http.StatusNoContent {
+		t.Errorf("retried delivery got %d, want 204", code)
+	}
+
+	// A delivered event is acknowledged without running the handler again
+	if code := send("d1", body, hexMAC([]byte(secret), body)); code != http.StatusOK {
+		t.Errorf("duplicate delivery got %d, want 200", code)
+	}
+	if len(handled) != 1 {
+		t.Errorf("handled %v, want the event once", handled)
+	}
+}
+
+func TestContext_Once(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	key := "charge:" + t.Name()
+
+	if ran, err := c.Once(key, time.Minute, func() error { return errors.New("declined") }); !ran || err == nil {
+		t.Errorf("failing Once() = %v, %v", ran, err)
+	}
+	runs := 0
+	for range 3 {
+		if _, err := c.Once(key, time.Minute, func() error { runs++; return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("fn ran %d times, want 1 after the failed attempt", runs)
+	}
+}
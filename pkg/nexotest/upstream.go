@@ -0,0 +1,347 @@
+// Package nexotest provides utilities for testing Nexo applications.
+//
+// An Upstream is a scripted origin server for testing proxies that Forward
+// requests: it replies with canned responses, in order, can add latency or
+// fail like a broken origin, and records what it received so tests can
+// assert on the forwarded path and headers.
+//
+//	upstream := nexotest.NewUpstream(t)
+//	upstream.Handle("GET /users/{id}", nexotest.JSON(200, `{"id":"1"}`))
+//
+//	app := nexo.New()
+//	app.SetProxy(func(c *nexo.Context) (*nexo.ProxyResult, error) {
+//	    return nexo.Forward(upstream.URL), nil
+//	}, nil)
+//	app.Mount()
+//
+//	w := httptest.NewRecorder()
+//	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+//
+//	req := upstream.LastRequest()
+//	req.AssertForwarded(t, nexotest.Forwarded{Host: "example.com", Proto: "http"})
+package nexotest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fault is a way for an Upstream to fail a request.
+type Fault int
+
+const (
+	// FaultNone replies normally.
+	FaultNone Fault = iota
+
+	// FaultReset closes the connection without replying, so a proxy
+	// answers 502 Bad Gateway.
+	FaultReset
+
+	// FaultTruncate sends the headers and part of the body, then closes the
+	// connection.
+	FaultTruncate
+
+	// FaultHang never replies, until the request is canceled or the
+	// upstream closed, to test timeouts.
+	FaultHang
+)
+
+// Reply is a scripted response of an Upstream.
+type Reply struct {
+	Status int // default: 200
+	Header http.Header
+	Body   string
+
+	// Delay is waited before replying, in addition to the upstream's
+	// latency.
+	Delay time.Duration
+
+	Fault Fault
+}
+
+// Text returns a text/plain reply.
+func Text(status int, body string) Reply {
+	return Reply{
+		Status: status,
+		Header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:   body,
+	}
+}
+
+// JSON returns an application/json reply.
+func JSON(status int, body string) Reply {
+	return Reply{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   body,
+	}
+}
+
+// Request is a request an Upstream received.
+type Request struct {
+	Method string
+	URL    *url.URL // path and query, as forwarded
+	Host   string
+	Header http.Header
+	Body   []byte
+}
+
+// Upstream is a scripted origin server. It's safe for concurrent use.
+type Upstream struct {
+	*httptest.Server
+
+	t        testing.TB
+	mux      *http.ServeMux
+	closed   chan struct{}
+	closeOne sync.Once
+
+	mu       sync.Mutex
+	fallback Reply
+	latency  time.Duration
+	requests []Request
+}
+
+// NewUpstream starts an upstream, closed when the test ends. Requests
+// matching no pattern registered with Handle get 404 Not Found, or the
+// reply set with Default.
+func NewUpstream(t testing.TB) *Upstream {
+	u := &Upstream{
+		t:        t,
+		mux:      http.NewServeMux(),
+		closed:   make(chan struct{}),
+		fallback: Text(http.StatusNotFound, "no upstream route"),
+	}
+	u.Server = httptest.NewServer(http.HandlerFunc(u.serve))
+	t.Cleanup(u.Close)
+	return u
+}
+
+// Handle scripts the replies to requests matching pattern, in the syntax of
+// http.ServeMux, like "GET /users/{id}". Each request gets the next reply;
+// the last one repeats.
+//
+// Example:
+//
+//	// Fail twice, then recover
+//	upstream.Handle("GET /health",
+//	    nexotest.Reply{Fault: nexotest.FaultReset},
+//	    nexotest.Text(503, "starting"),
+//	    nexotest.Text(200, "ok"),
+//	)
+func (u *Upstream) Handle(pattern string, replies ...Reply) {
+	if len(replies) == 0 {
+		u.t.Fatalf("nexotest: Handle(%q) without replies", pattern)
+	}
+	var (
+		mu   sync.Mutex
+		next int
+	)
+	u.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		reply := replies[next]
+		if next < len(replies)-1 {
+			next++
+		}
+		mu.Unlock()
+		u.reply(w, r, reply)
+	})
+}
+
+// Default sets the reply to requests matching no pattern.
+func (u *Upstream) Default(reply Reply) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.fallback = reply
+}
+
+// SetLatency delays every reply by d.
+func (u *Upstream) SetLatency(d time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.latency = d
+}
+
+// Close releases hanging requests and shuts the upstream down.
+func (u *Upstream) Close() {
+	u.closeOne.Do(func() {
+		close(u.closed)
+		u.Server.Close()
+	})
+}
+
+// serve records r and replies as scripted.
+func (u *Upstream) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	u.mu.Lock()
+	u.requests = append(u.requests, Request{
+		Method: r.Method,
+		URL:    &url.URL{Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery},
+		Host:   r.Host,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	latency := u.latency
+	u.mu.Unlock()
+
+	if !u.wait(r.Context(), latency) {
+		return
+	}
+	if _, pattern := u.mux.Handler(r); pattern == "" {
+		u.mu.Lock()
+		reply := u.fallback
+		u.mu.Unlock()
+		u.reply(w, r, reply)
+		return
+	}
+	u.mux.ServeHTTP(w, r)
+}
+
+// reply writes reply to w.
+func (u *Upstream) reply(w http.ResponseWriter, r *http.Request, reply Reply) {
+	if !u.wait(r.Context(), reply.Delay) {
+		return
+	}
+
+	switch reply.Fault {
+	case FaultReset:
+		if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+			conn.Close()
+		}
+		return
+	case FaultHang:
+		select {
+		case <-r.Context().Done():
+		case <-u.closed:
+		}
+		return
+	}
+
+	for key, values := range reply.Header {
+		w.Header()[key] = values
+	}
+	body := reply.Body
+	if reply.Fault == FaultTruncate {
+		// Promise more than is sent; the server closes the connection
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)+1))
+		body = body[:len(body)/2]
+	}
+	status := reply.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+}
+
+// wait waits for d, and reports whether the request is still wanted.
+func (u *Upstream) wait(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+	case <-u.closed:
+	}
+	return false
+}
+
+// Requests returns the requests received so far, in order.
+func (u *Upstream) Requests() []Request {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]Request(nil), u.requests...)
+}
+
+// LastRequest returns the last request received, failing the test when
+// there's none.
+func (u *Upstream) LastRequest() Request {
+	u.t.Helper()
+	requests := u.Requests()
+	if len(requests) == 0 {
+		u.t.Fatal("nexotest: the upstream received no request")
+	}
+	return requests[len(requests)-1]
+}
+
+// AssertRequests fails the test unless the upstream received n requests.
+func (u *Upstream) AssertRequests(n int) {
+	u.t.Helper()
+	if got := len(u.Requests()); got != n {
+		u.t.Errorf("upstream received %d requests, want %d", got, n)
+	}
+}
+
+// AssertPath fails the test unless the request was forwarded to path, with
+// its query when it has one, like "/base/users?page=2".
+func (r Request) AssertPath(t testing.TB, path string) {
+	t.Helper()
+	if got := r.URL.RequestURI(); got != path {
+		t.Errorf("upstream request to %s, want %s", got, path)
+	}
+}
+
+// AssertHeader fails the test unless the request has the header name with
+// the value want.
+func (r Request) AssertHeader(t testing.TB, name, want string) {
+	t.Helper()
+	if _, ok := r.Header[http.CanonicalHeaderKey(name)]; !ok {
+		t.Errorf("upstream request has no %s header, want %q", name, want)
+	} else if got := r.Header.Get(name); got != want {
+		t.Errorf("upstream request header %s = %q, want %q", name, got, want)
+	}
+}
+
+// AssertNoHeader fails the test when the request has the header name, like
+// a credential the proxy must strip.
+func (r Request) AssertNoHeader(t testing.TB, name string) {
+	t.Helper()
+	if values, ok := r.Header[http.CanonicalHeaderKey(name)]; ok {
+		t.Errorf("upstream request header %s = %q, want none", name, values)
+	}
+}
+
+// Forwarded is the X-Forwarded-* headers of a forwarded request. Empty
+// fields aren't checked.
+type Forwarded struct {
+	For   string // X-Forwarded-For, the client IPs
+	Host  string // X-Forwarded-Host, the host the client requested
+	Proto string // X-Forwarded-Proto, http or https
+}
+
+// AssertForwarded fails the test unless the request has the X-Forwarded-*
+// headers of want.
+func (r Request) AssertForwarded(t testing.TB, want Forwarded) {
+	t.Helper()
+	for _, h := range []struct{ name, want string }{
+		{"X-Forwarded-For", want.For},
+		{"X-Forwarded-Host", want.Host},
+		{"X-Forwarded-Proto", want.Proto},
+	} {
+		if h.want != "" {
+			r.AssertHeader(t, h.name, h.want)
+		}
+	}
+}
+
+// AssertRedirect fails the test unless w is a redirect with status to
+// location, like a proxy's Redirect result.
+func AssertRedirect(t testing.TB, w *httptest.ResponseRecorder, status int, location string) {
+	t.Helper()
+	if w.Code != status {
+		t.Errorf("status = %d, want %d", w.Code, status)
+	}
+	if got := w.Header().Get("Location"); got != location {
+		t.Errorf("Location = %q, want %q", got, location)
+	}
+}
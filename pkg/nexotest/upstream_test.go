@@ -0,0 +1,124 @@
+package nexotest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+// forwardingApp returns an app forwarding every request to upstream,
+// except /old, which it redirects.
+func forwardingApp(t *testing.T, upstream string) *nexo.App {
+	app := nexo.New()
+	err := app.SetProxy(func(c *nexo.Context) (*nexo.ProxyResult, error) {
+		if c.Path() == "/old" {
+			return nexo.Redirect("/new", http.StatusMovedPermanently), nil
+		}
+		return nexo.Forward(upstream+"/base").WithHeader("X-Internal-Auth", "token"), nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Mount()
+	return app
+}
+
+func TestUpstream_Forward(t *testing.T) {
+	upstream := NewUpstream(t)
+	upstream.Handle("POST /base/users", JSON(http.StatusCreated, `{"id":"1"}`))
+	app := forwardingApp(t, upstream.URL)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users?notify=1", strings.NewReader(`{"name":"Ada"}`))
+	r.Header.Set("Authorization", "Bearer secret")
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id":"1"}` {
+		t.Fatalf("response = %d %q, want the upstream's", w.Code, w.Body)
+	}
+	upstream.AssertRequests(1)
+	req := upstream.LastRequest()
+	req.AssertPath(t, "/base/users?notify=1")
+	req.AssertHeader(t, "X-Internal-Auth", "token")
+	req.AssertHeader(t, "Authorization", "Bearer secret")
+	req.AssertNoHeader(t, "Cookie")
+	req.AssertForwarded(t, Forwarded{For: "192.0.2.1", Host: "example.com", Proto: "http"})
+	if string(req.Body) != `{"name":"Ada"}` {
+		t.Errorf("body = %q", req.Body)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/old", nil))
+	AssertRedirect(t, w, http.StatusMovedPermanently, "/new")
+	upstream.AssertRequests(1)
+}
+
+func TestUpstream_Script(t *testing.T) {
+	upstream := NewUpstream(t)
+	upstream.Handle("GET /base/health",
+		Reply{Fault: FaultReset},
+		Text(http.StatusServiceUnavailable, "starting"),
+		Text(http.StatusOK, "ok"),
+	)
+	app := forwardingApp(t, upstream.URL)
+
+	for _, want := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if w.Code != want {
+			t.Errorf("status = %d, want %d", w.Code, want)
+		}
+	}
+
+	// Unscripted requests get the default reply
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unscripted status = %d, want 404", w.Code)
+	}
+	upstream.Default(Text(http.StatusTeapot, "teapot"))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/health", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("default status = %d, want 418", w.Code)
+	}
+}
+
+func TestUpstream_Faults(t *testing.T) {
+	upstream := NewUpstream(t)
+	upstream.Handle("GET /truncated", Reply{Body: "0123456789", Fault: FaultTruncate})
+	upstream.Handle("GET /hang", Reply{Fault: FaultHang})
+	upstream.Handle("GET /slow", Reply{Body: "slow", Delay: 50 * time.Millisecond})
+
+	resp, err := http.Get(upstream.URL + "/truncated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err == nil || string(body) != "01234" {
+		t.Errorf("truncated body = %q, %v; want 01234 and an error", body, err)
+	}
+
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	if _, err := client.Get(upstream.URL + "/hang"); err == nil {
+		t.Error("hanging request succeeded")
+	}
+
+	upstream.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	resp, err = http.Get(upstream.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("slow reply took %v, want latency and delay of 100ms", elapsed)
+	}
+	upstream.AssertRequests(3)
+}
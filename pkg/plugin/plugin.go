@@ -0,0 +1,141 @@
+// Package plugin provides the extension points for the Nexo generator and CLI.
+//
+// Plugins are compiled into a custom nexo binary and register themselves from
+// an init function, the same way database/sql drivers do:
+//
+//	package graphql
+//
+//	func init() {
+//	    plugin.Register(&Plugin{})
+//	}
+//
+// A project enables registered plugins by name in nexo.yaml:
+//
+//	plugins:
+//	  - graphql
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+	"github.com/spf13/cobra"
+)
+
+// Plugin extends the generator pipeline and the CLI.
+// Embed Base to implement only the hooks you need.
+type Plugin interface {
+	// Name returns the name used to enable the plugin in nexo.yaml.
+	Name() string
+
+	// OnScan is called for every file in the app directory that is not a
+	// built-in routing file (route.go, page.templ, ...). It can add routes,
+	// middleware or warnings to result to implement new file conventions.
+	OnScan(file scanner.ScannedFile, result *scanner.ScanResult) error
+
+	// OnGenerate is called with each generated file before it is written
+	// and returns the content to write.
+	OnGenerate(path string, content []byte) ([]byte, error)
+
+	// ExtraCommands returns additional commands to add to the nexo CLI.
+	ExtraCommands() []*cobra.Command
+}
+
+// Base is a no-op implementation of every Plugin hook except Name.
+type Base struct{}
+
+// OnScan implements Plugin.
+func (Base) OnScan(scanner.ScannedFile, *scanner.ScanResult) error { return nil }
+
+// OnGenerate implements Plugin.
+func (Base) OnGenerate(_ string, content []byte) ([]byte, error) { return content, nil }
+
+// ExtraCommands implements Plugin.
+func (Base) ExtraCommands() []*cobra.Command { return nil }
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Plugin)
+)
+
+// Register makes a plugin available by name.
+// It panics if p is nil or a plugin with the same name is already registered.
+func Register(p Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if p == nil {
+		panic("plugin: Register plugin is nil")
+	}
+	name := p.Name()
+	if _, dup := registry[name]; dup {
+		panic("plugin: Register called twice for plugin " + name)
+	}
+	registry[name] = p
+}
+
+// Get returns the registered plugin with the given name.
+func Get(name string) (Plugin, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Names returns the sorted names of all registered plugins.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the registered plugins for the given names, in order.
+// It returns an error naming the first plugin that is not registered.
+func Load(names []string) ([]Plugin, error) {
+	plugins := make([]Plugin, 0, len(names))
+	for _, name := range names {
+		p, ok := Get(name)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q is not registered (available: %v)", name, Names())
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// Apply wires the OnScan and OnGenerate hooks of plugins into cfg.
+func Apply(cfg *scanner.GeneratorConfig, plugins []Plugin) {
+	for _, p := range plugins {
+		cfg.ScanHooks = append(cfg.ScanHooks, func(file scanner.ScannedFile, result *scanner.ScanResult) error {
+			if err := p.OnScan(file, result); err != nil {
+				return fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+			return nil
+		})
+		cfg.PostProcessors = append(cfg.PostProcessors, func(path string, content []byte) ([]byte, error) {
+			out, err := p.OnGenerate(path, content)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+			return out, nil
+		})
+	}
+}
+
+// Commands returns the extra CLI commands of all plugins.
+func Commands(plugins []Plugin) []*cobra.Command {
+	var cmds []*cobra.Command
+	for _, p := range plugins {
+		cmds = append(cmds, p.ExtraCommands()...)
+	}
+	return cmds
+}
@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/scanner"
+	"github.com/spf13/cobra"
+)
+
+// graphqlPlugin turns app/**/graphql.go files into POST routes.
+type graphqlPlugin struct {
+	Base
+	name string
+}
+
+func (p *graphqlPlugin) Name() string { return p.name }
+
+func (p *graphqlPlugin) OnScan(file scanner.ScannedFile, result *scanner.ScanResult) error {
+	if filepath.Base(file.FilePath) != "graphql.go" {
+		return nil
+	}
+	result.Routes = append(result.Routes, scanner.RouteFile{
+		FilePath:     file.FilePath,
+		RelativePath: file.RelativePath,
+		Segments:     file.Segments,
+		URLPattern:   file.URLPattern,
+		Scope:        file.Scope,
+		Handlers: []scanner.Handler{{
+			Name:   "Post",
+			Method: "POST",
+			Source: "{\n\treturn c.JSON(200, nil)\n}",
+		}},
+	})
+	return nil
+}
+
+func (p *graphqlPlugin) OnGenerate(path string, content []byte) ([]byte, error) {
+	return append(content, []byte("// processed by "+p.name+"\n")...), nil
+}
+
+func (p *graphqlPlugin) ExtraCommands() []*cobra.Command {
+	return []*cobra.Command{{Use: p.name}}
+}
+
+type failingPlugin struct {
+	Base
+}
+
+func (failingPlugin) Name() string { return "failing" }
+
+func (failingPlugin) OnScan(scanner.ScannedFile, *scanner.ScanResult) error {
+	return errors.New("cannot scan")
+}
+
+func TestRegister(t *testing.T) {
+	p := &graphqlPlugin{name: "test-register"}
+	Register(p)
+
+	got, ok := Get("test-register")
+	if !ok || got != p {
+		t.Fatalf("Get() = %v, %v; want registered plugin", got, ok)
+	}
+
+	found := false
+	for _, name := range Names() {
+		if name == "test-register" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Names() = %v, missing test-register", Names())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	Register(&graphqlPlugin{name: "test-register"})
+}
+
+func TestLoad(t *testing.T) {
+	Register(&graphqlPlugin{name: "test-load"})
+
+	plugins, err := Load([]string{"test-load"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(plugins) != 1 || plugins[0].Name() != "test-load" {
+		t.Errorf("Load() = %v", plugins)
+	}
+
+	if _, err := Load([]string{"does-not-exist"}); err == nil {
+		t.Error("expected error for unregistered plugin")
+	}
+
+	plugins, err = Load(nil)
+	if err != nil || len(plugins) != 0 {
+		t.Errorf("Load(nil) = %v, %v; want empty", plugins, err)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	cmds := Commands([]Plugin{&graphqlPlugin{name: "gql"}, failingPlugin{}})
+	if len(cmds) != 1 || cmds[0].Use != "gql" {
+		t.Errorf("Commands() = %v, want [gql]", cmds)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	outputDir := filepath.Join(tmpDir, ".nexo", "generated")
+
+	if err := os.MkdirAll(filepath.Join(appDir, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(appDir, "api", "graphql.go"), []byte("package api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("hooks are applied", func(t *testing.T) {
+		cfg := scanner.GeneratorConfig{ModuleName: "example.com/app", AppDir: appDir, OutputDir: outputDir}
+		Apply(&cfg, []Plugin{&graphqlPlugin{name: "gql"}})
+
+		result, err := scanner.NewGenerator(cfg).Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(result.ScanResult.Routes) != 1 || result.ScanResult.Routes[0].URLPattern != "/api" {
+			t.Fatalf("expected plugin route for /api, got %+v", result.ScanResult.Routes)
+		}
+
+		for _, f := range result.GeneratedFiles {
+			content, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(content), "// processed by gql\n") {
+				t.Errorf("%s was not post-processed", f)
+			}
+		}
+	})
+
+	t.Run("scan errors become warnings", func(t *testing.T) {
+		cfg := scanner.GeneratorConfig{ModuleName: "example.com/app", AppDir: appDir, OutputDir: outputDir}
+		Apply(&cfg, []Plugin{failingPlugin{}})
+
+		result, err := scanner.NewGenerator(cfg).Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(result.ScanResult.Warnings) != 1 || !strings.Contains(result.ScanResult.Warnings[0].Message, "plugin failing") {
+			t.Errorf("expected plugin warning, got %+v", result.ScanResult.Warnings)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/abdul-hamid-achik/nexo/internal/version"
+)
+
+// CacheFile is the path of the scan cache, relative to the project root.
+const CacheFile = ".nexo/cache.json"
+
+// cacheFormat is bumped when the cached facts change shape, so caches
+// written by an older scanner are discarded.
+const cacheFormat = 7
+
+// Cache is a persistent store of what the scanner learned from each file,
+// keyed by the file's path and validated by its modification time, size
+// and content hash. Repeat scans reuse the results of unchanged files
+// instead of parsing them again. A cache written by another Nexo version
+// is discarded.
+//
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	path string
+
+	mu    sync.Mutex
+	data  cacheData
+	dirty bool
+}
+
+type cacheData struct {
+	Version string                `json:"version"`
+	Files   map[string]cacheEntry `json:"files"`
+}
+
+type cacheEntry struct {
+	ModTime int64           `json:"mtime"` // UnixNano
+	Size    int64           `json:"size"`
+	Hash    string          `json:"hash"` // sha256 of the content
+	Facts   json.RawMessage `json:"facts"`
+}
+
+// cacheVersion identifies the Nexo version and cache format a cache was
+// written by.
+func cacheVersion() string {
+	return fmt.Sprintf("%s/%d", version.GetVersion(), cacheFormat)
+}
+
+// OpenCache loads the cache at path. A missing, unreadable or outdated
+// cache starts empty.
+func OpenCache(path string) *Cache {
+	c := &Cache{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &c.data)
+	}
+	if c.data.Version != cacheVersion() || c.data.Files == nil {
+		c.data = cacheData{Version: cacheVersion(), Files: make(map[string]cacheEntry)}
+	}
+	return c
+}
+
+// Save writes the cache if a scan changed it, dropping the entries of
+// files that no longer exist.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for key := range c.data.Files {
+		if _, err := os.Stat(cacheKeyPath(key)); os.IsNotExist(err) {
+			delete(c.data.Files, key)
+		}
+	}
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// cacheKey is the key of the facts of kind about the file at path; one
+// file may be read for several kinds of facts.
+func cacheKey(kind, path string) string {
+	return kind + ":" + path
+}
+
+// cacheKeyPath returns the file path of a cache key.
+func cacheKeyPath(key string) string {
+	_, path, _ := strings.Cut(key, ":")
+	return path
+}
+
+// cachedFacts returns the facts of kind about the file at path: those in
+// c when the file is unchanged, otherwise what parse returns for its
+// content, which is then cached. Parse errors aren't cached.
+func cachedFacts[T any](c *Cache, kind, path string, parse func(src []byte) (T, error)) (T, error) {
+	if c == nil {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			var zero T
+			return zero, fmt.Errorf("failed to read file: %w", err)
+		}
+		return parse(src)
+	}
+
+	key := cacheKey(kind, path)
+	info, err := os.Stat(path)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to read file: %w", err)
+	}
+	c.mu.Lock()
+	entry, ok := c.data.Files[key]
+	c.mu.Unlock()
+
+	var facts T
+	if ok && entry.ModTime == info.ModTime().UnixNano() && entry.Size == info.Size() {
+		if json.Unmarshal(entry.Facts, &facts) == nil {
+			return facts, nil
+		}
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return facts, fmt.Errorf("failed to read file: %w", err)
+	}
+	sum := sha256.Sum256(src)
+	hash := hex.EncodeToString(sum[:])
+
+	// Touched but unchanged, like after a checkout
+	if ok && entry.Hash == hash && json.Unmarshal(entry.Facts, &facts) == nil {
+		entry.ModTime, entry.Size = info.ModTime().UnixNano(), info.Size()
+		c.put(key, entry)
+		return facts, nil
+	}
+
+	facts, err = parse(src)
+	if err != nil {
+		return facts, err
+	}
+	raw, err := json.Marshal(facts)
+	if err != nil {
+		return facts, nil
+	}
+	c.put(key, cacheEntry{ModTime: info.ModTime().UnixNano(), Size: info.Size(), Hash: hash, Facts: raw})
+	return facts, nil
+}
+
+func (c *Cache) put(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Files[key] = entry
+	c.dirty = true
+}
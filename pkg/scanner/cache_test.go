@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/nexo/internal/version"
+)
+
+func TestScan_Cache(t *testing.T) {
+	dir := t.TempDir()
+	appDir := filepath.Join(dir, "app")
+	cachePath := filepath.Join(dir, CacheFile)
+	routeFile := filepath.Join(appDir, "users", "route.go")
+	if err := os.MkdirAll(filepath.Dir(routeFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mtime := time.Now().Add(-time.Hour)
+	write := func(handler string) {
+		t.Helper()
+		src := "package users\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc " + handler + "(c *nexo.Context) error { return nil }\n"
+		if err := os.WriteFile(routeFile, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(routeFile, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scan := func(cache *Cache) string {
+		t.Helper()
+		s := NewScanner(appDir)
+		s.SetCache(cache)
+		result, err := s.Scan()
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if len(result.Routes) != 1 || len(result.Routes[0].Handlers) != 1 {
+			t.Fatalf("Scan() routes = %+v", result.Routes)
+		}
+		h := result.Routes[0].Handlers[0]
+		if h.FilePath != routeFile {
+			t.Errorf("FilePath = %q, want %q", h.FilePath, routeFile)
+		}
+		return h.Method
+	}
+
+	write("Put")
+	if got := scan(OpenCache(cachePath)); got != "PUT" {
+		t.Fatalf("first scan found %s, want PUT", got)
+	}
+
+	// Same size and modification time: the file isn't read again
+	write("Get")
+	if got := scan(OpenCache(cachePath)); got != "PUT" {
+		t.Errorf("scan of an unchanged file found %s, want the cached PUT", got)
+	}
+
+	// Touched files are compared by content
+	mtime = mtime.Add(time.Minute)
+	write("Get")
+	if got := scan(OpenCache(cachePath)); got != "GET" {
+		t.Errorf("scan of a changed file found %s, want GET", got)
+	}
+
+	// A cache written by another version is discarded
+	write("Put")
+	original := version.Version
+	version.Version = "v0.0.0-other"
+	defer func() { version.Version = original }()
+	if got := scan(OpenCache(cachePath)); got != "PUT" {
+		t.Errorf("scan with an outdated cache found %s, want PUT", got)
+	}
+}
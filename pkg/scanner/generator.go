@@ -3,11 +3,13 @@ package scanner
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"text/template"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+	"github.com/abdul-hamid-achik/nexo/pkg/gosource"
 )
 
 // GeneratorConfig holds configuration for code generation.
@@ -18,6 +20,15 @@ type GeneratorConfig struct {
 	AppDir string
 	// OutputDir is where to write generated files (default: .nexo/generated)
 	OutputDir string
+	// ScanHooks are called for non-routing files in the app directory
+	ScanHooks []ScanHook
+	// PostProcessors transform each generated file before it is written
+	PostProcessors []PostProcessor
+	// FS is the filesystem generated files are written to (default: genfs.Disk)
+	FS genfs.WriteFS
+	// Cache holds the results of earlier scans, reused for unchanged
+	// files (optional)
+	Cache *Cache
 }
 
 // Generator generates valid Go code from scan results.
@@ -33,6 +44,7 @@ func NewGenerator(config GeneratorConfig) *Generator {
 	if config.AppDir == "" {
 		config.AppDir = "app"
 	}
+	config.FS = genfs.Or(config.FS)
 	return &Generator{config: config}
 }
 
@@ -40,13 +52,19 @@ func NewGenerator(config GeneratorConfig) *Generator {
 func (g *Generator) Generate() (*GenerateResult, error) {
 	// Scan the app directory
 	scanner := NewScanner(g.config.AppDir)
+	for _, hook := range g.config.ScanHooks {
+		scanner.AddHook(hook)
+	}
+	scanner.SetCache(g.config.Cache)
 	scanResult, err := scanner.Scan()
 	if err != nil {
 		return nil, fmt.Errorf("scan failed: %w", err)
 	}
+	// A cache that can't be saved only costs the next scan its speed
+	_ = g.config.Cache.Save()
 
 	// Create output directory
-	if err := os.MkdirAll(g.config.OutputDir, 0755); err != nil {
+	if err := g.config.FS.MkdirAll(g.config.OutputDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create output dir: %w", err)
 	}
 
@@ -98,27 +116,43 @@ type middlewareEntry struct {
 	FilePath   string
 }
 
+// middlewareFuncName returns the name of the generated function of mw. The
+// names of named middleware are kept apart from a directory's own.
+func middlewareFuncName(mw MiddlewareFile) string {
+	return "Middleware" + MakeHandlerName(mw.URLPattern, mw.Name)
+}
+
 // generateRoutesFile generates the routes.go file with all handlers.
 func (g *Generator) generateRoutesFile(result *ScanResult, outputPath string) error {
 	// Collect all routes
 	var routes []routeEntry
 	for _, rf := range result.Routes {
 		for _, h := range rf.Handlers {
+			if !inlinable(h) {
+				continue
+			}
 			routes = append(routes, routeEntry{
 				Pattern:     rf.URLPattern,
 				Method:      h.Method,
 				HandlerName: MakeHandlerName(rf.URLPattern, h.Method),
-				FilePath:    rf.FilePath,
+				FilePath:    handlerFile(rf, h),
 				Scope:       rf.Scope,
-				Priority:    calculatePriority(rf.URLPattern),
+				Priority:    handlerPriority(rf.URLPattern, h),
 				Source:      h.Source,
 			})
 		}
 	}
 
-	// Sort by priority (higher first)
-	sort.Slice(routes, func(i, j int) bool {
-		return routes[i].Priority > routes[j].Priority
+	// Sort by priority (higher first), then by pattern and method so the
+	// output doesn't depend on discovery order
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Priority != routes[j].Priority {
+			return routes[i].Priority > routes[j].Priority
+		}
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
 	})
 
 	// Collect middleware
@@ -127,7 +161,7 @@ func (g *Generator) generateRoutesFile(result *ScanResult, outputPath string) er
 		middlewares = append(middlewares, middlewareEntry{
 			PathPrefix: mw.URLPattern,
 			Scope:      mw.Scope,
-			FuncName:   "Middleware" + MakeHandlerName(mw.URLPattern, ""),
+			FuncName:   middlewareFuncName(mw),
 			FilePath:   mw.FilePath,
 		})
 	}
@@ -144,13 +178,14 @@ func (g *Generator) generateRoutesFile(result *ScanResult, outputPath string) er
 		return err
 	}
 
-	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+	return g.writeFile(outputPath, buf.Bytes())
 }
 
 // generateRegisterFile generates the register.go file.
 func (g *Generator) generateRegisterFile(result *ScanResult, outputPath string) error {
 	// Build route registrations using generated handler names (no imports needed)
 	var registrations []string
+	needsTime := false
 	for _, rf := range result.Routes {
 		// Check for catch-all param name
 		catchAllParam := ""
@@ -162,24 +197,40 @@ func (g *Generator) generateRegisterFile(result *ScanResult, outputPath string)
 		}
 
 		for _, h := range rf.Handlers {
+			if !inlinable(h) {
+				continue
+			}
 			handlerName := MakeHandlerName(rf.URLPattern, h.Method)
 			reg := fmt.Sprintf(`tree.AddRoute(&nexo.Route{
-		Pattern:       "%s",
-		Method:        "%s",
-		Handler:       %s,
-		FilePath:      "%s",
-		Scope:         "%s",
-		Priority:      %d,
-		CatchAllParam: "%s",
+		Pattern:          "%s",
+		Method:           "%s",
+		Handler:          %s,
+		FilePath:         "%s",
+		Scope:            "%s",
+		Priority:         %d,
+		PriorityOverride: %t,
+		CatchAllParam:    "%s",
 	})`,
 				rf.URLPattern,
 				h.Method,
 				handlerName,
-				rf.FilePath,
+				handlerFile(rf, h),
 				rf.Scope,
-				calculatePriority(rf.URLPattern),
+				handlerPriority(rf.URLPattern, h),
+				h.HasPriority,
 				catchAllParam,
 			)
+			// Options of a nexo:route directive run as route middleware
+			if mws := h.Options.Middleware(); len(mws) > 0 {
+				reg = strings.TrimSuffix(reg, "\n\t})") + fmt.Sprintf("\n\t\tMiddlewares:      []nexo.MiddlewareFunc{%s},\n\t})", strings.Join(mws, ", "))
+				needsTime = needsTime || h.Options.NeedsTime()
+			}
+			if h.Options != nil && len(h.Options.Skip) > 0 {
+				reg = strings.TrimSuffix(reg, "\n\t})") + fmt.Sprintf("\n\t\tSkipMiddleware:   %#v,\n\t})", h.Options.Skip)
+			}
+			if h.Options != nil && len(h.Options.Only) > 0 {
+				reg = strings.TrimSuffix(reg, "\n\t})") + fmt.Sprintf("\n\t\tOnlyMiddleware:   %#v,\n\t})", h.Options.Only)
+			}
 			registrations = append(registrations, reg)
 		}
 	}
@@ -187,12 +238,20 @@ func (g *Generator) generateRegisterFile(result *ScanResult, outputPath string)
 	// Build middleware registrations
 	var mwRegistrations []string
 	for _, mw := range result.Middlewares {
-		funcName := "Middleware" + MakeHandlerName(mw.URLPattern, "")
+		funcName := middlewareFuncName(mw)
 		reg := fmt.Sprintf(`tree.AddMiddleware("%s", "%s", %s())`,
 			mw.URLPattern,
 			mw.Scope,
 			funcName,
 		)
+		if mw.Name != "" {
+			reg = fmt.Sprintf(`tree.AddNamedMiddleware("%s", "%s", "%s", %s())`,
+				mw.URLPattern,
+				mw.Scope,
+				mw.Name,
+				funcName,
+			)
+		}
 		mwRegistrations = append(mwRegistrations, reg)
 	}
 
@@ -203,24 +262,60 @@ func (g *Generator) generateRegisterFile(result *ScanResult, outputPath string)
 		"Registrations":   registrations,
 		"MwRegistrations": mwRegistrations,
 		"HasRoutes":       len(result.Routes) > 0 || len(result.Middlewares) > 0,
+		"NeedsTime":       needsTime,
 	})
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+	return g.writeFile(outputPath, buf.Bytes())
 }
 
-// calculatePriority calculates route priority (higher = more specific)
-func calculatePriority(pattern string) int {
-	priority := 100
-	if strings.Contains(pattern, "{") {
-		priority -= 50 // Dynamic routes lower priority
+// writeFile runs the configured post-processors over content, formats the
+// result with gosource.Format and writes it.
+func (g *Generator) writeFile(outputPath string, content []byte) error {
+	for _, pp := range g.config.PostProcessors {
+		var err error
+		content, err = pp(outputPath, content)
+		if err != nil {
+			return fmt.Errorf("post-processing %s: %w", filepath.Base(outputPath), err)
+		}
 	}
-	if strings.Contains(pattern, "*") {
-		priority -= 90 // Catch-all lowest priority
+
+	if filepath.Ext(outputPath) == ".go" {
+		var err error
+		if content, err = gosource.Format(outputPath, content); err != nil {
+			return err
+		}
 	}
-	return priority
+
+	return g.config.FS.WriteFile(outputPath, content, 0644)
+}
+
+// handlerFile returns the file declaring h, or the route's file for
+// handlers that do not record one.
+func handlerFile(rf RouteFile, h Handler) string {
+	if h.FilePath != "" {
+		return h.FilePath
+	}
+	return rf.FilePath
+}
+
+// inlinable reports whether the body of h can be copied into routes.go.
+// Body and injected handlers need their route package to decode or resolve
+// their extra parameters, and handlers taking a context.Context refer to
+// it, so they're left out.
+func inlinable(h Handler) bool {
+	return h.Kind == HandlerPlain && !h.Context
+}
+
+// handlerPriority returns the handler's nexo:priority override, or the
+// priority calculated from the pattern.
+func handlerPriority(pattern string, h Handler) int {
+	if h.HasPriority {
+		return h.Priority
+	}
+	return CalculatePriority(pattern)
 }
 
 const routesTemplate = `// Code generated by nexo. DO NOT EDIT.
@@ -270,6 +365,10 @@ const registerTemplate = `// Code generated by nexo. DO NOT EDIT.
 package generated
 
 import (
+{{- if .NeedsTime}}
+	"time"
+
+{{end}}
 	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
 )
 
@@ -292,20 +391,9 @@ func RegisterRoutes(tree *nexo.RouteTree) {
 }
 `
 
-// GetModuleName reads the module name from go.mod
+// GetModuleName returns the import path of the working directory: the
+// path of the closest go.mod's module, plus the directory's path in it, so
+// an app below the root of a monorepo module imports its packages right.
 func GetModuleName() (string, error) {
-	content, err := os.ReadFile("go.mod")
-	if err != nil {
-		return "", err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimPrefix(line, "module "), nil
-		}
-	}
-
-	return "", fmt.Errorf("module name not found in go.mod")
+	return ImportPath(".")
 }
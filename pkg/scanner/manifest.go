@@ -0,0 +1,274 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/nexo/internal/version"
+	"github.com/abdul-hamid-achik/nexo/pkg/genfs"
+)
+
+// ManifestVersion is the version of the routes manifest format. It is
+// bumped when fields are removed or change meaning; new fields may be
+// added within a version.
+const ManifestVersion = 1
+
+// ManifestFile is the conventional path of the routes manifest, relative to
+// the project root.
+const ManifestFile = "routes.manifest.json"
+
+// RouteManifest is everything one scan of an app directory found, in the
+// shape its consumers need: the router registers its routes and
+// middleware, the generators render them and the CLI lists them. Each
+// handler of a route directory is a separate entry with its priority
+// already resolved.
+//
+// Encoded as JSON, it is the routes manifest read by tools outside Nexo,
+// like edge proxies, API gateways and docs sites:
+//
+//	{
+//	  "version": 1,
+//	  "nexo": "v1.4.0",
+//	  "app_dir": "app",
+//	  "routes": [
+//	    {"method": "GET", "pattern": "/api/users/{id}", "file": "app/api/users/[id]/route.go", ...}
+//	  ],
+//	  "middleware": [...],
+//	  "pages": [...],
+//	  "layouts": [...],
+//	  "loaders": [...],
+//	  "proxy": {"file": "app/proxy.go", "matchers": ["/api/*"]},
+//	  "assets": {"js/entry.js": "/static/js/entry-5FQXK2VN.js"}
+//	}
+type RouteManifest struct {
+	// Version is the manifest format version (ManifestVersion)
+	Version int `json:"version"`
+	// Nexo is the version of Nexo that wrote the manifest
+	Nexo string `json:"nexo"`
+	// AppDir is the scanned app directory
+	AppDir string `json:"app_dir"`
+	// Routes are the discovered handlers, in the order of their files
+	Routes []ManifestRoute `json:"routes"`
+	// Middlewares are the discovered middleware files
+	Middlewares []MiddlewareFile `json:"middleware"`
+	// Pages are the discovered page files
+	Pages []PageFile `json:"pages"`
+	// Layouts are the discovered layout files
+	Layouts []LayoutFile `json:"layouts"`
+	// Loaders are the discovered loader files
+	Loaders []LoaderFile `json:"loaders"`
+	// Proxy is the discovered proxy file (if any)
+	Proxy *ProxyFile `json:"proxy"`
+	// Assets maps the stable names of bundled assets to their URLs. The
+	// scanner leaves it empty; callers that know the asset manifest fill
+	// it in
+	Assets map[string]string `json:"assets,omitempty"`
+	// Warnings are non-fatal issues encountered during scanning
+	Warnings []Warning `json:"-"`
+	// Conflicts are route conflicts detected
+	Conflicts []Conflict `json:"-"`
+}
+
+// ManifestRoute is a handler registered under a URL pattern.
+type ManifestRoute struct {
+	// Method is the HTTP method (e.g., "GET")
+	Method string `json:"method"`
+	// Pattern is the URL pattern (e.g., "/users/{id}")
+	Pattern string `json:"pattern"`
+	// Scope is the middleware scope (preserves groups)
+	Scope string `json:"scope"`
+	// FilePath is the path to the file declaring the handler
+	FilePath string `json:"file"`
+	// Package is the Go package name of the route
+	Package string `json:"-"`
+	// Handler is the function name (e.g., "Get")
+	Handler string `json:"handler"`
+	// Kind is the signature the handler is declared with
+	Kind HandlerKind `json:"kind"`
+	// Context reports whether the handler takes a context.Context first
+	Context bool `json:"context,omitempty"`
+	// Priority is the route priority, higher first
+	Priority int `json:"priority"`
+	// PriorityOverride reports whether Priority comes from a nexo:priority
+	// directive rather than the pattern
+	PriorityOverride bool `json:"priority_override,omitempty"`
+	// CatchAllParam is the name of the route's catch-all parameter, if any
+	CatchAllParam string `json:"catch_all,omitempty"`
+	// Options are the options of the handler's nexo:route directive, if any
+	Options *RouteOptions `json:"options,omitempty"`
+	// Doc documents the handler, from a Describe function, if any
+	Doc *RouteDoc `json:"doc,omitempty"`
+}
+
+// Manifest scans the app directory and returns its manifest.
+func (s *Scanner) Manifest() (*RouteManifest, error) {
+	result, err := s.Scan()
+	if err != nil {
+		return nil, err
+	}
+	return NewManifest(s.appDir, result), nil
+}
+
+// NewManifest builds the manifest of the app directory appDir from the
+// result of scanning it.
+func NewManifest(appDir string, result *ScanResult) *RouteManifest {
+	m := &RouteManifest{
+		Version:     ManifestVersion,
+		Nexo:        version.GetVersion(),
+		AppDir:      appDir,
+		Middlewares: result.Middlewares,
+		Pages:       result.Pages,
+		Layouts:     result.Layouts,
+		Loaders:     result.Loaders,
+		Proxy:       result.Proxy,
+		Warnings:    result.Warnings,
+		Conflicts:   result.Conflicts,
+	}
+
+	for _, rf := range result.Routes {
+		catchAll := ""
+		for _, seg := range rf.Segments {
+			if seg.Type == SegmentCatchAll || seg.Type == SegmentOptionalCatchAll {
+				catchAll = seg.Name
+				break
+			}
+		}
+		for _, h := range rf.Handlers {
+			m.Routes = append(m.Routes, ManifestRoute{
+				Method:           h.Method,
+				Pattern:          rf.URLPattern,
+				Scope:            rf.Scope,
+				FilePath:         handlerFile(rf, h),
+				Package:          rf.Package,
+				Handler:          h.Name,
+				Kind:             h.Kind,
+				Context:          h.Context,
+				Priority:         handlerPriority(rf.URLPattern, h),
+				PriorityOverride: h.HasPriority,
+				CatchAllParam:    catchAll,
+				Options:          h.Options,
+				Doc:              rf.HandlerDoc(h),
+			})
+		}
+	}
+
+	// A skip or only option naming middleware the route doesn't inherit
+	// would silently run it
+	m.Warnings = slices.Clip(m.Warnings)
+	for _, r := range m.Routes {
+		if _, _, err := r.Options.FilterMiddleware(m.InheritedMiddleware(r)); err != nil {
+			m.Warnings = append(m.Warnings, Warning{
+				FilePath: r.FilePath,
+				Message:  fmt.Sprintf("%s: %v", r.Handler, err),
+			})
+		}
+	}
+	return m
+}
+
+// FileWarning returns the warning recorded for the file at path, if any.
+func (m *RouteManifest) FileWarning(path string) (Warning, bool) {
+	for _, w := range m.Warnings {
+		if w.FilePath == path {
+			return w, true
+		}
+	}
+	return Warning{}, false
+}
+
+// MiddlewareName returns the name routes use to skip mw: its registry name
+// for middleware used with nexo:use, or else the path it applies to.
+func MiddlewareName(mw MiddlewareFile) string {
+	if mw.Name != "" {
+		return mw.Name
+	}
+	if mw.URLPattern == "" {
+		return "/"
+	}
+	return mw.URLPattern
+}
+
+// InheritedMiddleware returns the names of the middleware r runs, from the
+// root down, before its skip or only option applies (see
+// RouteOptions.FilterMiddleware).
+func (m *RouteManifest) InheritedMiddleware(r ManifestRoute) []string {
+	var applied []MiddlewareFile
+	for _, mw := range m.Middlewares {
+		prefix := strings.TrimSuffix(mw.URLPattern, "/")
+		if prefix != "" && r.Pattern != prefix && !strings.HasPrefix(r.Pattern, prefix+"/") {
+			continue
+		}
+		if mw.Scope != "" && !strings.HasPrefix(r.Scope, mw.Scope) {
+			continue
+		}
+		applied = append(applied, mw)
+	}
+	sort.SliceStable(applied, func(i, j int) bool {
+		return strings.Count(strings.TrimSuffix(applied[i].URLPattern, "/"), "/") <
+			strings.Count(strings.TrimSuffix(applied[j].URLPattern, "/"), "/")
+	})
+	names := make([]string, 0, len(applied))
+	for _, mw := range applied {
+		if name := MiddlewareName(mw); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Marshal encodes the manifest as indented JSON with forward slashes in
+// file paths, so it reads the same on every OS.
+func (m *RouteManifest) Marshal() ([]byte, error) {
+	out := *m
+	out.AppDir = filepath.ToSlash(m.AppDir)
+	out.Routes = slashed(m.Routes, func(r *ManifestRoute) *string { return &r.FilePath })
+	out.Middlewares = slashed(m.Middlewares, func(mw *MiddlewareFile) *string { return &mw.FilePath })
+	out.Pages = slashed(m.Pages, func(p *PageFile) *string { return &p.FilePath })
+	out.Layouts = slashed(m.Layouts, func(l *LayoutFile) *string { return &l.FilePath })
+	out.Loaders = slashed(m.Loaders, func(l *LoaderFile) *string { return &l.FilePath })
+	if m.Proxy != nil {
+		proxy := *m.Proxy
+		proxy.FilePath = filepath.ToSlash(proxy.FilePath)
+		out.Proxy = &proxy
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteFile writes the manifest to name in fsys, leaving the file alone
+// when its content is already up to date.
+func (m *RouteManifest) WriteFile(fsys genfs.WriteFS, name string) error {
+	fsys = genfs.Or(fsys)
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	if old, err := fsys.ReadFile(name); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	return fsys.WriteFile(name, data, 0644)
+}
+
+// slashed returns a copy of items, never nil, with the path each holds
+// converted to forward slashes.
+func slashed[T any](items []T, path func(*T) *string) []T {
+	out := make([]T, len(items))
+	copy(out, items)
+	for i := range out {
+		p := path(&out[i])
+		*p = filepath.ToSlash(*p)
+	}
+	return out
+}
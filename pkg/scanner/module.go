@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Module is the Go module a directory belongs to.
+type Module struct {
+	Path string // module path from go.mod, e.g. "example.com/web"
+	Dir  string // absolute directory of go.mod
+}
+
+// FindModule returns the module of dir: the closest go.mod at or above it.
+// In a monorepo with one go.mod at the root, every app below it belongs to
+// that module; in a go.work workspace, each app has its own.
+func FindModule(dir string) (Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Module{}, err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			path := modulePath(data)
+			if path == "" {
+				return Module{}, fmt.Errorf("module name not found in %s", filepath.Join(d, "go.mod"))
+			}
+			return Module{Path: path, Dir: d}, nil
+		}
+		if !os.IsNotExist(err) {
+			return Module{}, err
+		}
+		if filepath.Dir(d) == d {
+			return Module{}, fmt.Errorf("no go.mod found in %s or any parent directory", abs)
+		}
+	}
+}
+
+// ImportPath returns the import path of the package in dir.
+func (m Module) ImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(m.Dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside module %s", dir, m.Path)
+	}
+	if rel == "." {
+		return m.Path, nil
+	}
+	return m.Path + "/" + filepath.ToSlash(rel), nil
+}
+
+// ImportPath returns the import path of the package in dir, resolved
+// through the module dir belongs to.
+func ImportPath(dir string) (string, error) {
+	m, err := FindModule(dir)
+	if err != nil {
+		return "", err
+	}
+	return m.ImportPath(dir)
+}
+
+// CheckAppModule returns an error when appDir belongs to another module
+// than the working directory, like an app of a go.work workspace scanned
+// from the workspace root. Generated code imports the app's packages
+// relative to the working directory, so nexo must run from the app's
+// module (see the --app flag).
+func CheckAppModule(appDir string) error {
+	app, err := FindModule(appDir)
+	if err != nil {
+		return nil // Without a module there's nothing to import from
+	}
+	wd, err := FindModule(".")
+	if err != nil || wd.Dir != app.Dir {
+		return fmt.Errorf("%s belongs to module %s in %s; run nexo there or pass --app", appDir, app.Path, app.Dir)
+	}
+	return nil
+}
+
+// WorkspaceModules returns the directories of the modules listed by the
+// use directives of the go.work in dir, relative to dir. It returns nil
+// when dir has no go.work.
+func WorkspaceModules(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	inUse := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "":
+		case inUse && line == ")":
+			inUse = false
+		case inUse:
+			dirs = append(dirs, strings.Trim(line, `"`))
+		case line == "use (":
+			inUse = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+	return dirs, sc.Err()
+}
+
+// modulePath returns the path of the module directive of a go.mod.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "module "); ok {
+			if i := strings.Index(rest, "//"); i >= 0 {
+				rest = rest[:i]
+			}
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	root, _ := filepath.EvalSymlinks(t.TempDir())
+	writeFiles(t, root, map[string]string{
+		"go.mod":                    "module example.com/mono // the monorepo\n\ngo 1.25\n",
+		"go.work":                   "go 1.25\n\nuse (\n\t.\n\t./services/billing // payments\n)\nuse ./tools\n",
+		"apps/web/app/page.templ":   "",
+		"services/billing/go.mod":   "module \"example.com/billing\"\n",
+		"services/billing/app/x.go": "package app\n",
+	})
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: ".", want: "example.com/mono"},
+		{dir: "apps/web/app", want: "example.com/mono/apps/web/app"},
+		{dir: "apps/web/app/[id]", want: "example.com/mono/apps/web/app/[id]"},
+		{dir: "services/billing", want: "example.com/billing"},
+		{dir: "services/billing/app", want: "example.com/billing/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			got, err := ImportPath(filepath.Join(root, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ImportPath(%s) = %s, want %s", tt.dir, got, tt.want)
+			}
+		})
+	}
+
+	modules, err := WorkspaceModules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{".", "./services/billing", "./tools"}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("WorkspaceModules = %v, want %v", modules, want)
+	}
+
+	t.Chdir(filepath.Join(root, "apps", "web"))
+	if got, _ := GetModuleName(); got != "example.com/mono/apps/web" {
+		t.Errorf("GetModuleName in apps/web = %s", got)
+	}
+	if err := CheckAppModule("app"); err != nil {
+		t.Errorf("CheckAppModule(app) = %v", err)
+	}
+
+	t.Chdir(root)
+	err = CheckAppModule("services/billing/app")
+	if err == nil || !strings.Contains(err.Error(), "example.com/billing") {
+		t.Errorf("CheckAppModule of another module = %v, want an error naming it", err)
+	}
+}
@@ -1,15 +1,17 @@
 package scanner
 
 import (
+	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
 // Next.js-style pattern matchers
 var (
-	// [id] - dynamic segment
-	// Matches: [id], [userId], [post_id]
-	dynamicSegmentRe = regexp.MustCompile(`^\[([a-zA-Z_][a-zA-Z0-9_]*)\]$`)
+	// [id] - dynamic segment, optionally typed
+	// Matches: [id], [userId], [post_id], [id:int], [slug:re(\w+)]
+	dynamicSegmentRe = regexp.MustCompile(`^\[([a-zA-Z_][a-zA-Z0-9_]*)(?::(.+))?\]$`)
 
 	// [...slug] - catch-all segment
 	// Matches: [...slug], [...path], [...segments]
@@ -22,6 +24,14 @@ var (
 	// (group) - route group (doesn't affect URL)
 	// Matches: (admin), (auth), (dashboard)
 	routeGroupRe = regexp.MustCompile(`^\(([a-zA-Z_][a-zA-Z0-9_]*)\)$`)
+
+	// @slot - parallel route slot (doesn't affect URL)
+	// Matches: @modal, @team, @analytics
+	slotSegmentRe = regexp.MustCompile(`^@([a-zA-Z_][a-zA-Z0-9_]*)$`)
+
+	// (.)segment - intercepting route marker before a segment
+	// Matches: (.)photo, (..)photo, (..)(..)photo, (...)photo
+	interceptMarkerRe = regexp.MustCompile(`^(\(\.\.\.\)|\(\.\)|(?:\(\.\.\))+)`)
 )
 
 // knownPrivateFolders contains folder names that should be skipped
@@ -32,14 +42,24 @@ var knownPrivateFolders = map[string]bool{
 	"_helpers":     true,
 	"_private":     true,
 	"_shared":      true,
+	"_middleware":  true,
 	"node_modules": true,
 	".git":         true,
 	".nexo":        true,
 }
 
 // ParseSegment parses a directory name into a Segment.
-// Supports Next.js-style naming: [id], [...slug], [[...slug]], (group).
+// Supports Next.js-style naming: [id], [...slug], [[...slug]], (group),
+// @slot, and intercepting markers like (.)photo or (..)[id].
 func ParseSegment(name string) Segment {
+	// Intercepting route: (..)photo is parsed as photo with a marker
+	if marker := interceptMarkerRe.FindString(name); marker != "" && len(marker) < len(name) {
+		seg := ParseSegment(name[len(marker):])
+		seg.Raw = name
+		seg.Intercept = marker
+		return seg
+	}
+
 	seg := Segment{Raw: name}
 
 	// Optional catch-all: [[...slug]]
@@ -56,9 +76,10 @@ func ParseSegment(name string) Segment {
 		return seg
 	}
 
-	// Dynamic: [id]
+	// Dynamic: [id], or typed: [id:int]
 	if matches := dynamicSegmentRe.FindStringSubmatch(name); len(matches) > 1 {
 		seg.Name = matches[1]
+		seg.Constraint = matches[2]
 		seg.Type = SegmentDynamic
 		return seg
 	}
@@ -70,12 +91,39 @@ func ParseSegment(name string) Segment {
 		return seg
 	}
 
+	// Parallel route slot: @modal
+	if matches := slotSegmentRe.FindStringSubmatch(name); len(matches) > 1 {
+		seg.Name = matches[1]
+		seg.Type = SegmentSlot
+		return seg
+	}
+
 	// Static segment
 	seg.Name = name
 	seg.Type = SegmentStatic
 	return seg
 }
 
+// ParsePath parses a directory path relative to the app directory into
+// segments.
+func ParsePath(relDir string) []Segment {
+	if relDir == "." || relDir == "" {
+		return nil
+	}
+
+	parts := strings.Split(relDir, string(filepath.Separator))
+	segments := make([]Segment, 0, len(parts))
+
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		segments = append(segments, ParseSegment(part))
+	}
+
+	return segments
+}
+
 // IsPrivateFolder checks if a directory should be skipped during scanning.
 func IsPrivateFolder(name string) bool {
 	// Hidden directories
@@ -87,18 +135,23 @@ func IsPrivateFolder(name string) bool {
 }
 
 // BuildURLPattern builds a URL pattern from segments.
-// Groups are excluded from the URL.
+// Groups and slots are excluded from the URL. An intercepting segment is
+// resolved relative to the segments before it: (.) keeps them, (..) drops
+// one, (..)(..) drops two and (...) starts again from the root.
 func BuildURLPattern(segments []Segment) string {
 	var parts []string
 	for _, seg := range segments {
+		if seg.Intercept != "" {
+			parts = parts[:interceptBase(len(parts), seg.Intercept)]
+		}
 		switch seg.Type {
-		case SegmentGroup:
-			// Groups don't affect the URL
+		case SegmentGroup, SegmentSlot:
+			// Groups and slots don't affect the URL
 			continue
 		case SegmentDynamic:
-			parts = append(parts, "{"+seg.Name+"}")
+			parts = append(parts, ParamPattern(seg.Name, seg.Constraint))
 		case SegmentCatchAll, SegmentOptionalCatchAll:
-			parts = append(parts, "*")
+			parts = append(parts, CatchAllPattern(seg.Name, seg.Type == SegmentOptionalCatchAll))
 		case SegmentStatic:
 			parts = append(parts, seg.Name)
 		}
@@ -110,6 +163,196 @@ func BuildURLPattern(segments []Segment) string {
 	return "/" + strings.Join(parts, "/")
 }
 
+// Parameter types of typed dynamic segments, like [id:int].
+const (
+	// ParamInt matches digits and is read with Context.ParamInt.
+	ParamInt = "int"
+	// ParamUUID matches a UUID and is read with Context.ParamUUID.
+	ParamUUID = "uuid"
+)
+
+// ValidateConstraint checks the constraint of a typed dynamic segment: a
+// parameter type, int or uuid, or re(regexp), like [slug:re([a-z0-9-]+)].
+// The empty constraint of [id] is valid.
+func ValidateConstraint(constraint string) error {
+	switch constraint {
+	case "", ParamInt, ParamUUID:
+		return nil
+	}
+	expr, ok := regexpConstraint(constraint)
+	if !ok {
+		return fmt.Errorf("unknown parameter type %q: want int, uuid or re(regexp)", constraint)
+	}
+	if strings.Contains(expr, "/") {
+		return fmt.Errorf("parameter regexp %q can't match /", expr)
+	}
+	if _, err := regexp.Compile(expr); err != nil {
+		return fmt.Errorf("invalid parameter regexp: %w", err)
+	}
+	return nil
+}
+
+// ParamPattern returns the route pattern segment of a dynamic parameter:
+// {id} without a constraint, {id:int} or {id:uuid} for a type, which the
+// router turns into a regexp, and {slug:\w+} for re(\w+).
+func ParamPattern(name, constraint string) string {
+	if constraint == "" {
+		return "{" + name + "}"
+	}
+	if expr, ok := regexpConstraint(constraint); ok {
+		return "{" + name + ":" + expr + "}"
+	}
+	return "{" + name + ":" + constraint + "}"
+}
+
+// CatchAllPattern returns the route pattern segment of a named catch-all,
+// {slug...}, or {slug...?} when optional. The router matches it like * and
+// sets the slug parameter to the rest of the path, so c.Param and
+// c.ParamAll read it by name. An optional catch-all also matches the
+// parent path, /shop for /shop/{slug...?}, with an empty slug.
+func CatchAllPattern(name string, optional bool) string {
+	if optional {
+		return "{" + name + "...?}"
+	}
+	return "{" + name + "...}"
+}
+
+// CatchAllName reports whether seg is a catch-all route pattern segment,
+// *, {slug...} or {slug...?}, with its parameter name, which is empty for *.
+func CatchAllName(seg string) (string, bool) {
+	if seg == "*" {
+		return "", true
+	}
+	name, ok := strings.CutSuffix(seg, "...}")
+	if !ok {
+		name, ok = strings.CutSuffix(seg, "...?}")
+	}
+	if !ok || !strings.HasPrefix(name, "{") || len(name) == 1 {
+		return "", false
+	}
+	return name[1:], true
+}
+
+// IsOptionalCatchAll reports whether seg is an optional catch-all route
+// pattern segment, {slug...?}.
+func IsOptionalCatchAll(seg string) bool {
+	_, ok := CatchAllName(seg)
+	return ok && strings.HasSuffix(seg, "...?}")
+}
+
+// ParamType returns the Go-facing type of a constraint: int or uuid, or ""
+// for strings.
+func ParamType(constraint string) string {
+	switch constraint {
+	case ParamInt, ParamUUID:
+		return constraint
+	}
+	return ""
+}
+
+// regexpConstraint returns the regexp of a re(regexp) constraint.
+func regexpConstraint(constraint string) (string, bool) {
+	expr, ok := strings.CutPrefix(constraint, "re(")
+	if !ok || !strings.HasSuffix(expr, ")") {
+		return "", false
+	}
+	return strings.TrimSuffix(expr, ")"), true
+}
+
+// CalculatePriority returns the priority of a route pattern; higher is
+// more specific. Static routes come before dynamic ones, which come before
+// catch-alls.
+func CalculatePriority(pattern string) int {
+	priority := 100
+
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg == "" {
+			continue
+		}
+
+		// Catch-all (lowest priority)
+		if _, ok := CatchAllName(seg); ok {
+			return 5
+		}
+
+		// Dynamic segment
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			priority = min(priority, 50)
+		}
+	}
+
+	return priority
+}
+
+// BuildPagePattern builds the URL pattern of a page or layout from
+// segments. It is BuildURLPattern without the api directory, which holds
+// API routes rather than pages.
+func BuildPagePattern(segments []Segment) string {
+	return BuildURLPattern(withoutAPI(segments))
+}
+
+// PageTitle derives a page title from the last segment that names a
+// page: route groups, slots and the api directory are skipped, and the
+// root is "Home".
+func PageTitle(segments []Segment) string {
+	segments = withoutAPI(segments)
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if seg.Type == SegmentGroup || seg.Type == SegmentSlot {
+			continue
+		}
+		return toTitleCase(seg.Name)
+	}
+	return "Home"
+}
+
+// withoutAPI returns segments without static api segments.
+func withoutAPI(segments []Segment) []Segment {
+	var kept []Segment
+	for _, seg := range segments {
+		if seg.Type == SegmentStatic && seg.Name == "api" {
+			continue
+		}
+		kept = append(kept, seg)
+	}
+	return kept
+}
+
+// interceptBase returns how many of n URL parts an intercepting segment
+// with marker keeps.
+func interceptBase(n int, marker string) int {
+	switch marker {
+	case "(.)":
+		return n
+	case "(...)":
+		return 0
+	}
+	return max(n-strings.Count(marker, "(..)"), 0)
+}
+
+// InterceptFrom returns the URL pattern of the page an intercepting route
+// intercepts navigation from: the segments before the first intercepting
+// segment. It returns "" when segments have no intercepting segment.
+func InterceptFrom(segments []Segment) string {
+	for i, seg := range segments {
+		if seg.Intercept != "" {
+			return BuildURLPattern(segments[:i])
+		}
+	}
+	return ""
+}
+
+// SlotName returns the name of the innermost parallel route slot in
+// segments, or "" when there is none.
+func SlotName(segments []Segment) string {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].Type == SegmentSlot {
+			return segments[i].Name
+		}
+	}
+	return ""
+}
+
 // BuildScope builds a middleware scope from segments.
 // Unlike URL pattern, this preserves group names for middleware matching.
 func BuildScope(segments []Segment) string {
@@ -134,15 +377,15 @@ func MakeHandlerName(pattern, method string) string {
 		if part == "" {
 			continue
 		}
-		// Handle {param}
-		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
-			param := strings.TrimPrefix(strings.TrimSuffix(part, "}"), "{")
-			result.WriteString(toPascalCase(param))
+		// Handle * and {param...}
+		if _, ok := CatchAllName(part); ok {
+			result.WriteString("Wildcard")
 			continue
 		}
-		// Handle *
-		if part == "*" {
-			result.WriteString("Wildcard")
+		// Handle {param} and {param:int}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			param, _, _ := strings.Cut(part[1:len(part)-1], ":")
+			result.WriteString(toPascalCase(param))
 			continue
 		}
 		// Static part
@@ -192,6 +435,9 @@ func MakeImportAlias(segments []Segment) string {
 		case SegmentGroup:
 			// Include group name in alias for uniqueness
 			parts = append(parts, seg.Name)
+		case SegmentSlot:
+			// Keep app/@team apart from app/team
+			parts = append(parts, "slot_"+seg.Name)
 		case SegmentDynamic, SegmentCatchAll, SegmentOptionalCatchAll:
 			parts = append(parts, seg.Name)
 		case SegmentStatic:
@@ -221,6 +467,7 @@ func ExtractParams(segments []Segment) []Param {
 		case SegmentDynamic:
 			params = append(params, Param{
 				Name:       seg.Name,
+				Type:       ParamType(seg.Constraint),
 				IsCatchAll: false,
 				IsOptional: false,
 			})
@@ -270,7 +517,12 @@ func toPascalCase(s string) string {
 
 // sanitizePackageName converts a directory name to a valid Go package name.
 func sanitizePackageName(name string) string {
-	// Remove brackets and parentheses
+	// Remove intercepting markers, slot prefixes, brackets and parentheses
+	name = interceptMarkerRe.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "@")
+	if matches := dynamicSegmentRe.FindStringSubmatch(name); len(matches) > 2 {
+		name = matches[1]
+	}
 	name = strings.ReplaceAll(name, "[", "")
 	name = strings.ReplaceAll(name, "]", "")
 	name = strings.ReplaceAll(name, "(", "")
@@ -297,6 +549,8 @@ func IsNextJSStyle(name string) bool {
 	return dynamicSegmentRe.MatchString(name) ||
 		catchAllSegmentRe.MatchString(name) ||
 		optionalCatchAllRe.MatchString(name) ||
-		routeGroupRe.MatchString(name)
+		routeGroupRe.MatchString(name) ||
+		slotSegmentRe.MatchString(name) ||
+		interceptMarkerRe.MatchString(name)
 }
 
@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -14,10 +16,17 @@ func TestParseSegment(t *testing.T) {
 		// Next.js-style patterns
 		{"dynamic bracket", "[id]", SegmentDynamic, "id"},
 		{"dynamic bracket underscore", "[user_id]", SegmentDynamic, "user_id"},
+		{"typed dynamic", "[id:int]", SegmentDynamic, "id"},
+		{"regexp dynamic", `[slug:re(\w+)]`, SegmentDynamic, "slug"},
 		{"catch-all", "[...slug]", SegmentCatchAll, "slug"},
 		{"optional catch-all", "[[...slug]]", SegmentOptionalCatchAll, "slug"},
 		{"route group", "(admin)", SegmentGroup, "admin"},
 		{"route group underscore", "(auth_group)", SegmentGroup, "auth_group"},
+		{"parallel slot", "@modal", SegmentSlot, "modal"},
+		{"intercept same level", "(.)photo", SegmentStatic, "photo"},
+		{"intercept parent dynamic", "(..)[id]", SegmentDynamic, "id"},
+		{"intercept two levels", "(..)(..)photo", SegmentStatic, "photo"},
+		{"intercept root", "(...)photo", SegmentStatic, "photo"},
 
 		// Static segments
 		{"static simple", "users", SegmentStatic, "users"},
@@ -87,7 +96,7 @@ func TestBuildURLPattern(t *testing.T) {
 				{Raw: "docs", Name: "docs", Type: SegmentStatic},
 				{Raw: "[...slug]", Name: "slug", Type: SegmentCatchAll},
 			},
-			want: "/docs/*",
+			want: "/docs/{slug...}",
 		},
 		{
 			name: "group excluded",
@@ -109,6 +118,35 @@ func TestBuildURLPattern(t *testing.T) {
 			},
 			want: "/api/users/{userId}/posts/{postId}",
 		},
+		{
+			name: "slot excluded",
+			segments: []Segment{
+				{Raw: "dashboard", Name: "dashboard", Type: SegmentStatic},
+				{Raw: "@team", Name: "team", Type: SegmentSlot},
+				{Raw: "members", Name: "members", Type: SegmentStatic},
+			},
+			want: "/dashboard/members",
+		},
+		{
+			name:     "intercept same level",
+			segments: []Segment{ParseSegment("feed"), ParseSegment("(.)photo"), ParseSegment("[id]")},
+			want:     "/feed/photo/{id}",
+		},
+		{
+			name:     "intercept parent",
+			segments: []Segment{ParseSegment("feed"), ParseSegment("@modal"), ParseSegment("(..)photo"), ParseSegment("[id]")},
+			want:     "/photo/{id}",
+		},
+		{
+			name:     "intercept two levels",
+			segments: []Segment{ParseSegment("a"), ParseSegment("b"), ParseSegment("(..)(..)photo")},
+			want:     "/photo",
+		},
+		{
+			name:     "intercept root",
+			segments: []Segment{ParseSegment("a"), ParseSegment("(group)"), ParseSegment("b"), ParseSegment("(...)photo")},
+			want:     "/photo",
+		},
 	}
 
 	for _, tt := range tests {
@@ -171,7 +209,9 @@ func TestMakeHandlerName(t *testing.T) {
 		{"/api/users", "POST", "ApiUsersPost"},
 		{"/api/users/{id}", "GET", "ApiUsersIdGet"},
 		{"/api/users/{id}", "DELETE", "ApiUsersIdDelete"},
+		{"/api/users/{id:int}", "GET", "ApiUsersIdGet"},
 		{"/docs/*", "GET", "DocsWildcardGet"},
+		{"/docs/{slug...}", "GET", "DocsWildcardGet"},
 		{"/api/users/{userId}/posts/{postId}", "PUT", "ApiUsersUseridPostsPostidPut"},
 	}
 
@@ -185,6 +225,73 @@ func TestMakeHandlerName(t *testing.T) {
 	}
 }
 
+func TestCatchAllName(t *testing.T) {
+	tests := []struct {
+		seg  string
+		name string
+		ok   bool
+	}{
+		{"*", "", true},
+		{CatchAllPattern("slug", false), "slug", true},
+		{CatchAllPattern("slug", true), "slug", true},
+		{"{slug}", "", false},
+		{"{...}", "", false},
+		{"docs", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := CatchAllName(tt.seg)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("CatchAllName(%q) = %q, %v, want %q, %v", tt.seg, name, ok, tt.name, tt.ok)
+		}
+	}
+	if got := CalculatePriority("/docs/{slug...}"); got != CalculatePriority("/docs/*") {
+		t.Errorf("CalculatePriority(/docs/{slug...}) = %d", got)
+	}
+	if IsOptionalCatchAll("{slug...}") || !IsOptionalCatchAll("{slug...?}") {
+		t.Error("IsOptionalCatchAll() doesn't tell {slug...} from {slug...?}")
+	}
+}
+
+func TestParamConstraints(t *testing.T) {
+	tests := []struct {
+		dir     string
+		pattern string
+		param   Param
+		pkg     string
+		wantErr bool
+	}{
+		{dir: "[id]", pattern: "/{id}", param: Param{Name: "id"}, pkg: "id"},
+		{dir: "[id:int]", pattern: "/{id:int}", param: Param{Name: "id", Type: "int"}, pkg: "id"},
+		{dir: "[id:uuid]", pattern: "/{id:uuid}", param: Param{Name: "id", Type: "uuid"}, pkg: "id"},
+		{dir: `[slug:re([a-z0-9-]+)]`, pattern: "/{slug:[a-z0-9-]+}", param: Param{Name: "slug"}, pkg: "slug"},
+		{dir: "[id:float]", wantErr: true},
+		{dir: "[id:re(a/b)]", wantErr: true},
+		{dir: "[id:re([)]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			seg := ParseSegment(tt.dir)
+			err := ValidateConstraint(seg.Constraint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateConstraint(%q) = %v, wantErr %v", seg.Constraint, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			segments := []Segment{seg}
+			if got := BuildURLPattern(segments); got != tt.pattern {
+				t.Errorf("BuildURLPattern() = %q, want %q", got, tt.pattern)
+			}
+			if got := ExtractParams(segments); len(got) != 1 || got[0] != tt.param {
+				t.Errorf("ExtractParams() = %+v, want %+v", got, tt.param)
+			}
+			if got := MakePackageName(segments); got != tt.pkg {
+				t.Errorf("MakePackageName() = %q, want %q", got, tt.pkg)
+			}
+		})
+	}
+}
+
 func TestMakePackageName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -388,6 +495,8 @@ func TestIsNextJSStyle(t *testing.T) {
 		{"[...slug]", true},
 		{"[[...slug]]", true},
 		{"(admin)", true},
+		{"@modal", true},
+		{"(..)photo", true},
 		{"_id", false},
 		{"__slug", false},
 		{"users", false},
@@ -403,3 +512,253 @@ func TestIsNextJSStyle(t *testing.T) {
 	}
 }
 
+func TestInterceptFromAndSlotName(t *testing.T) {
+	tests := []struct {
+		dir      string
+		wantFrom string
+		wantSlot string
+	}{
+		{"feed/photo/[id]", "", ""},
+		{"feed/(..)photo/[id]", "/feed", ""},
+		{"feed/@modal/(..)photo/[id]", "/feed", "modal"},
+		{"(.)photo", "/", ""},
+		{"dashboard/@team/members", "", "team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			var segments []Segment
+			for _, part := range strings.Split(tt.dir, "/") {
+				segments = append(segments, ParseSegment(part))
+			}
+			if got := InterceptFrom(segments); got != tt.wantFrom {
+				t.Errorf("InterceptFrom() = %q, want %q", got, tt.wantFrom)
+			}
+			if got := SlotName(segments); got != tt.wantSlot {
+				t.Errorf("SlotName() = %q, want %q", got, tt.wantSlot)
+			}
+		})
+	}
+}
+
+func TestParsePath_URLPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		appDir   string
+		filePath string
+		want     string
+	}{
+		{
+			name:     "root route",
+			appDir:   "app",
+			filePath: "app/route.go",
+			want:     "/",
+		},
+		{
+			name:     "simple nested route",
+			appDir:   "app",
+			filePath: "app/users/route.go",
+			want:     "/users",
+		},
+		{
+			name:     "deeply nested route",
+			appDir:   "app",
+			filePath: "app/api/users/profile/route.go",
+			want:     "/api/users/profile",
+		},
+		{
+			name:     "dynamic segment",
+			appDir:   "app",
+			filePath: "app/users/[id]/route.go",
+			want:     "/users/{id}",
+		},
+		{
+			name:     "multiple dynamic segments",
+			appDir:   "app",
+			filePath: "app/orgs/[orgId]/teams/[teamId]/route.go",
+			want:     "/orgs/{orgId}/teams/{teamId}",
+		},
+		{
+			name:     "catch-all segment",
+			appDir:   "app",
+			filePath: "app/docs/[...slug]/route.go",
+			want:     "/docs/{slug...}",
+		},
+		{
+			name:     "optional catch-all",
+			appDir:   "app",
+			filePath: "app/shop/[[...categories]]/route.go",
+			want:     "/shop/{categories...?}",
+		},
+		{
+			name:     "route group",
+			appDir:   "app",
+			filePath: "app/(auth)/login/route.go",
+			want:     "/login",
+		},
+		{
+			name:     "multiple route groups",
+			appDir:   "app",
+			filePath: "app/(marketing)/(landing)/about/route.go",
+			want:     "/about",
+		},
+		{
+			name:     "route group with dynamic segment",
+			appDir:   "app",
+			filePath: "app/(api)/users/[id]/route.go",
+			want:     "/users/{id}",
+		},
+		{
+			name:     "complex nested path",
+			appDir:   "app",
+			filePath: "app/(admin)/dashboard/users/[userId]/posts/[postId]/route.go",
+			want:     "/dashboard/users/{userId}/posts/{postId}",
+		},
+		{
+			name:     "api route",
+			appDir:   "app",
+			filePath: "app/api/health/route.go",
+			want:     "/api/health",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildURLPattern(ParsePath(relDir(t, tt.appDir, tt.filePath)))
+			if got != tt.want {
+				t.Errorf("BuildURLPattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPagePattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     string
+	}{
+		{
+			name:     "root page",
+			filePath: "app/page.templ",
+			want:     "/",
+		},
+		{
+			name:     "simple page",
+			filePath: "app/about/page.templ",
+			want:     "/about",
+		},
+		{
+			name:     "nested page",
+			filePath: "app/dashboard/settings/page.templ",
+			want:     "/dashboard/settings",
+		},
+		{
+			name:     "dynamic segment",
+			filePath: "app/users/[id]/page.templ",
+			want:     "/users/{id}",
+		},
+		{
+			name:     "catch-all",
+			filePath: "app/docs/[...slug]/page.templ",
+			want:     "/docs/{slug...}",
+		},
+		{
+			name:     "optional catch-all",
+			filePath: "app/shop/[[...categories]]/page.templ",
+			want:     "/shop/{categories...?}",
+		},
+		{
+			name:     "route group",
+			filePath: "app/(marketing)/about/page.templ",
+			want:     "/about",
+		},
+		{
+			name:     "skips api directory",
+			filePath: "app/api/users/page.templ",
+			want:     "/users",
+		},
+		{
+			name:     "root layout",
+			filePath: "app/layout.templ",
+			want:     "/",
+		},
+		{
+			name:     "nested layout",
+			filePath: "app/dashboard/layout.templ",
+			want:     "/dashboard",
+		},
+		{
+			name:     "route group layout",
+			filePath: "app/(admin)/layout.templ",
+			want:     "/",
+		},
+		{
+			name:     "route group with nested",
+			filePath: "app/(dashboard)/settings/layout.templ",
+			want:     "/settings",
+		},
+		{
+			name:     "deeply nested",
+			filePath: "app/admin/settings/layout.templ",
+			want:     "/admin/settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildPagePattern(ParsePath(relDir(t, "app", tt.filePath)))
+			if got != tt.want {
+				t.Errorf("BuildPagePattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     string
+	}{
+		{
+			name:     "root page",
+			filePath: "app/page.templ",
+			want:     "Home",
+		},
+		{
+			name:     "simple page",
+			filePath: "app/about/page.templ",
+			want:     "About",
+		},
+		{
+			name:     "hyphenated",
+			filePath: "app/user-profile/page.templ",
+			want:     "User Profile",
+		},
+		{
+			name:     "underscored",
+			filePath: "app/my_dashboard/page.templ",
+			want:     "My Dashboard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PageTitle(ParsePath(relDir(t, "app", tt.filePath)))
+			if got != tt.want {
+				t.Errorf("PageTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// relDir returns the directory of filePath relative to appDir.
+func relDir(t *testing.T, appDir, filePath string) string {
+	t.Helper()
+	rel, err := filepath.Rel(appDir, filepath.Dir(filepath.FromSlash(filePath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
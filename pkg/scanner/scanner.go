@@ -5,10 +5,10 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -17,6 +17,8 @@ type Scanner struct {
 	appDir  string
 	fset    *token.FileSet
 	verbose bool
+	hooks   []ScanHook
+	cache   *Cache
 }
 
 // NewScanner creates a new Scanner for the given app directory.
@@ -33,15 +35,16 @@ func (s *Scanner) SetVerbose(v bool) {
 	s.verbose = v
 }
 
-// HTTP method to function name mapping
-var httpMethods = map[string]string{
-	"Get":     http.MethodGet,
-	"Post":    http.MethodPost,
-	"Put":     http.MethodPut,
-	"Patch":   http.MethodPatch,
-	"Delete":  http.MethodDelete,
-	"Head":    http.MethodHead,
-	"Options": http.MethodOptions,
+// SetCache makes the scanner reuse, and record, the results of files
+// unchanged since an earlier scan. A nil cache parses every file.
+func (s *Scanner) SetCache(c *Cache) {
+	s.cache = c
+}
+
+// AddHook registers a hook that is called for every non-routing file found
+// during Scan. Hooks run in the order they were added.
+func (s *Scanner) AddHook(h ScanHook) {
+	s.hooks = append(s.hooks, h)
 }
 
 // Scan walks the app directory and discovers all routing files.
@@ -55,6 +58,9 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 	// Track discovered patterns for conflict detection
 	routePatterns := make(map[string]string) // pattern+method -> filePath
+	routeDirs := make(map[string]int)        // dir -> index in result.Routes
+	var uses []dirUses                       // nexo:use directives, by directory
+	useDirs := make(map[string]int)          // dir -> index in uses
 
 	err := filepath.Walk(s.appDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -66,6 +72,16 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 			if IsPrivateFolder(info.Name()) {
 				return filepath.SkipDir
 			}
+			// A typed parameter with a bad constraint would never match
+			if seg := ParseSegment(info.Name()); seg.Type == SegmentDynamic {
+				if err := ValidateConstraint(seg.Constraint); err != nil {
+					result.Warnings = append(result.Warnings, Warning{
+						FilePath: path,
+						Message:  fmt.Sprintf("%s: %v; directory skipped", info.Name(), err),
+					})
+					return filepath.SkipDir
+				}
+			}
 			return nil
 		}
 
@@ -76,11 +92,30 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 		}
 
 		dir := filepath.Dir(relPath)
-		segments := s.parsePathSegments(dir)
+		segments := ParsePath(dir)
+
+		// Any Go file of a directory may name the middleware it uses. Parse
+		// errors are reported by the scan of the file itself
+		if name := info.Name(); strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			if names, err := s.scanUses(path); err == nil && len(names) > 0 {
+				i, ok := useDirs[dir]
+				if !ok {
+					i = len(uses)
+					useDirs[dir] = i
+					uses = append(uses, dirUses{dir: dir, file: path, segments: segments})
+				}
+				u := &uses[i]
+				for _, name := range names {
+					if !slices.Contains(u.names, name) {
+						u.names = append(u.names, name)
+					}
+				}
+			}
+		}
 
 		// Process routing files
-		switch info.Name() {
-		case "route.go":
+		switch name := info.Name(); {
+		case IsRouteFile(name):
 			route, err := s.scanRouteFile(path, relPath, segments)
 			if err != nil {
 				result.Warnings = append(result.Warnings, Warning{
@@ -104,10 +139,24 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 						routePatterns[key] = path
 					}
 				}
-				result.Routes = append(result.Routes, *route)
+
+				// route.go and per-method files (get.go, post.go) in one
+				// directory make up a single route
+				if i, ok := routeDirs[dir]; ok {
+					result.Routes[i].Handlers = append(result.Routes[i].Handlers, route.Handlers...)
+					for name, doc := range route.Docs {
+						if result.Routes[i].Docs == nil {
+							result.Routes[i].Docs = make(map[string]RouteDoc)
+						}
+						result.Routes[i].Docs[name] = doc
+					}
+				} else if len(route.Handlers) > 0 {
+					routeDirs[dir] = len(result.Routes)
+					result.Routes = append(result.Routes, *route)
+				}
 			}
 
-		case "middleware.go":
+		case name == "middleware.go":
 			mw, err := s.scanMiddlewareFile(path, relPath, segments)
 			if err != nil {
 				result.Warnings = append(result.Warnings, Warning{
@@ -120,7 +169,7 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 				result.Middlewares = append(result.Middlewares, *mw)
 			}
 
-		case "page.templ":
+		case name == "page.templ":
 			page, err := s.scanPageFile(path, relPath, segments)
 			if err != nil {
 				result.Warnings = append(result.Warnings, Warning{
@@ -133,7 +182,7 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 				result.Pages = append(result.Pages, *page)
 			}
 
-		case "layout.templ":
+		case name == "layout.templ":
 			layout, err := s.scanLayoutFile(path, relPath, segments)
 			if err != nil {
 				result.Warnings = append(result.Warnings, Warning{
@@ -146,7 +195,7 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 				result.Layouts = append(result.Layouts, *layout)
 			}
 
-		case "loader.go":
+		case name == "loader.go":
 			loader, err := s.scanLoaderFile(path, relPath, segments)
 			if err != nil {
 				result.Warnings = append(result.Warnings, Warning{
@@ -159,7 +208,7 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 				result.Loaders = append(result.Loaders, *loader)
 			}
 
-		case "proxy.go":
+		case name == "proxy.go":
 			// Only scan proxy.go in app root
 			if dir == "." {
 				proxy, err := s.scanProxyFile(path)
@@ -174,168 +223,325 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 					result.Proxy = proxy
 				}
 			}
+
+		default:
+			if len(s.hooks) == 0 {
+				return nil
+			}
+			file := ScannedFile{
+				FilePath:     path,
+				RelativePath: relPath,
+				Segments:     segments,
+				URLPattern:   BuildURLPattern(segments),
+				Scope:        BuildScope(segments),
+			}
+			for _, hook := range s.hooks {
+				if err := hook(file, result); err != nil {
+					result.Warnings = append(result.Warnings, Warning{
+						FilePath: path,
+						Message:  err.Error(),
+					})
+				}
+			}
 		}
 
 		return nil
 	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	s.useNamedMiddleware(result, uses)
+	return result, nil
 }
 
-// parsePathSegments parses a relative directory path into segments.
-func (s *Scanner) parsePathSegments(relDir string) []Segment {
-	if relDir == "." || relDir == "" {
-		return nil
+// dirUses is the named middleware a directory uses.
+type dirUses struct {
+	dir      string    // directory relative to the app directory
+	file     string    // first file with a nexo:use directive
+	segments []Segment // parsed segments of dir
+	names    []string  // middleware names, in order
+}
+
+// scanUses returns the middleware names of the nexo:use directives in the
+// package doc comment of a Go file.
+func (s *Scanner) scanUses(filePath string) ([]string, error) {
+	return cachedFacts(s.cache, "uses", filePath, func(content []byte) ([]string, error) {
+		file, err := parser.ParseFile(s.fset, filePath, content, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse: %w", err)
+		}
+		return UseDirective(file.Doc), nil
+	})
+}
+
+// useNamedMiddleware adds the named middleware each directory uses to
+// result. It runs before the directory's own middleware.go, in the order
+// of the nexo:use directive. Unknown names are reported as warnings.
+func (s *Scanner) useNamedMiddleware(result *ScanResult, uses []dirUses) {
+	if len(uses) == 0 {
+		return
 	}
 
-	parts := strings.Split(relDir, string(filepath.Separator))
-	segments := make([]Segment, 0, len(parts))
+	registry := make(map[string]*MiddlewareFile)
+	used := make(map[string][]MiddlewareFile) // dir -> named middleware
+	for _, u := range uses {
+		for _, name := range u.names {
+			mw, ok := registry[name]
+			if !ok {
+				var err error
+				mw, err = s.namedMiddleware(name)
+				if err != nil {
+					result.Warnings = append(result.Warnings, Warning{
+						FilePath: u.file,
+						Message:  err.Error(),
+					})
+					continue
+				}
+				registry[name] = mw
+			}
+
+			named := *mw
+			named.Segments = u.segments
+			named.URLPattern = BuildURLPattern(u.segments)
+			named.Scope = BuildScope(u.segments)
+			used[u.dir] = append(used[u.dir], named)
 
-	for _, part := range parts {
-		if part == "" {
-			continue
+			if s.verbose {
+				fmt.Printf("  Using middleware: %s for %s (scope: %s)\n", name, named.URLPattern, named.Scope)
+			}
 		}
-		segments = append(segments, ParseSegment(part))
 	}
 
-	return segments
+	var middlewares []MiddlewareFile
+	for _, mw := range result.Middlewares {
+		dir := filepath.Dir(mw.RelativePath)
+		middlewares = append(middlewares, used[dir]...)
+		delete(used, dir)
+		middlewares = append(middlewares, mw)
+	}
+	for _, u := range uses {
+		middlewares = append(middlewares, used[u.dir]...)
+		delete(used, u.dir)
+	}
+	result.Middlewares = middlewares
 }
 
-// scanRouteFile scans a route.go file for handlers.
-func (s *Scanner) scanRouteFile(filePath, relPath string, segments []Segment) (*RouteFile, error) {
-	// Read file content for source extraction
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+// namedMiddleware scans the middleware registered as name, at
+// app/_middleware/<name>/middleware.go.
+func (s *Scanner) namedMiddleware(name string) (*MiddlewareFile, error) {
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid middleware name %q", name)
+	}
+	relPath := filepath.Join(MiddlewareRegistryDir, name, "middleware.go")
+	filePath := filepath.Join(s.appDir, relPath)
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("unknown middleware %q: %s not found", name, filepath.ToSlash(filepath.Join(filepath.Base(s.appDir), relPath)))
 	}
 
-	// Parse the Go file
-	file, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
+	mw, err := s.scanMiddlewareFile(filePath, relPath, ParsePath(filepath.Dir(relPath)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse: %w", err)
+		return nil, fmt.Errorf("middleware %q: %w", name, err)
 	}
-
-	route := &RouteFile{
-		FilePath:     filePath,
-		RelativePath: relPath,
-		Segments:     segments,
-		URLPattern:   BuildURLPattern(segments),
-		Scope:        BuildScope(segments),
-		Package:      MakePackageName(segments),
+	if mw == nil {
+		return nil, fmt.Errorf("middleware %q: %s has no Middleware function", name, filepath.ToSlash(filepath.Join(filepath.Base(s.appDir), relPath)))
 	}
+	mw.Name = name
+	return mw, nil
+}
 
-	// Find handler functions
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok || !fn.Name.IsExported() {
-			continue
-		}
+// routeFacts is what the scanner caches about a route file.
+type routeFacts struct {
+	Handlers []Handler
+	Docs     map[string]RouteDoc
+}
 
-		method, ok := httpMethods[fn.Name.Name]
-		if !ok {
-			continue
+// scanRouteFile scans a route file for handlers.
+func (s *Scanner) scanRouteFile(filePath, relPath string, segments []Segment) (*RouteFile, error) {
+	facts, err := cachedFacts(s.cache, "route", filePath, func(content []byte) (routeFacts, error) {
+		var facts routeFacts
+
+		// Parse the Go file
+		file, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
+		if err != nil {
+			return facts, fmt.Errorf("failed to parse: %w", err)
 		}
 
-		if !isValidHandlerSignature(fn) {
-			if s.verbose {
-				fmt.Printf("  Warning: %s.%s has invalid signature, skipping\n", filePath, fn.Name.Name)
+		// Find handler functions
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() {
+				continue
 			}
-			continue
-		}
 
-		// Extract function body source code
-		var source string
-		if fn.Body != nil {
-			start := s.fset.Position(fn.Body.Pos()).Offset
-			end := s.fset.Position(fn.Body.End()).Offset
-			if start >= 0 && end <= len(content) && start < end {
-				source = string(content[start:end])
+			if handler, ok := DescribeFunc(fn.Name.Name); ok {
+				doc, err := DescribeDoc(fn)
+				if err != nil {
+					return facts, err
+				}
+				if facts.Docs == nil {
+					facts.Docs = make(map[string]RouteDoc)
+				}
+				facts.Docs[handler] = doc
+				continue
 			}
-		}
 
-		route.Handlers = append(route.Handlers, Handler{
-			Name:   fn.Name.Name,
-			Method: method,
-			Source: source,
-		})
+			method, ok := HandlerMethod(fn.Name.Name)
+			if !ok {
+				continue
+			}
 
-		if s.verbose {
-			fmt.Printf("  Found handler: %s %s in %s\n", method, route.URLPattern, filePath)
+			kind, ok := HandlerSignature(fn)
+			if !ok {
+				if s.verbose {
+					fmt.Printf("  Warning: %s.%s has invalid signature, skipping\n", filePath, fn.Name.Name)
+				}
+				continue
+			}
+
+			// Extract function body source code
+			var source string
+			if fn.Body != nil {
+				start := s.fset.Position(fn.Body.Pos()).Offset
+				end := s.fset.Position(fn.Body.End()).Offset
+				if start >= 0 && end <= len(content) && start < end {
+					source = string(content[start:end])
+				}
+			}
+
+			priority, hasPriority := HandlerPriority(file, fn)
+			opts, err := RouteDirective(fn.Doc)
+			if err != nil {
+				return facts, fmt.Errorf("%s: %w", fn.Name.Name, err)
+			}
+
+			methods := []string{method}
+			if opts != nil && len(opts.Methods) > 0 {
+				methods = opts.Methods
+			}
+			for _, method := range methods {
+				facts.Handlers = append(facts.Handlers, Handler{
+					Name:        fn.Name.Name,
+					Method:      method,
+					Kind:        kind,
+					Context:     TakesContext(fn),
+					Source:      source,
+					Priority:    priority,
+					HasPriority: hasPriority,
+					Options:     opts,
+				})
+			}
 		}
+		return facts, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(route.Handlers) == 0 {
+	if len(facts.Handlers) == 0 && len(facts.Docs) == 0 {
 		return nil, nil
 	}
 
+	route := &RouteFile{
+		FilePath:     filePath,
+		RelativePath: relPath,
+		Segments:     segments,
+		URLPattern:   BuildURLPattern(segments),
+		Scope:        BuildScope(segments),
+		Package:      MakePackageName(segments),
+		Docs:         facts.Docs,
+	}
+	for _, h := range facts.Handlers {
+		h.FilePath = filePath
+		route.Handlers = append(route.Handlers, h)
+
+		if s.verbose {
+			fmt.Printf("  Found handler: %s %s in %s\n", h.Method, route.URLPattern, filePath)
+		}
+	}
+
 	return route, nil
 }
 
+// middlewareFacts is what the scanner caches about a middleware.go file.
+type middlewareFacts struct {
+	Found bool           // declares a valid Middleware function
+	Kind  MiddlewareKind // the signature it is declared with
+}
+
 // scanMiddlewareFile scans a middleware.go file.
 func (s *Scanner) scanMiddlewareFile(filePath, relPath string, segments []Segment) (*MiddlewareFile, error) {
-	file, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse: %w", err)
-	}
-
-	// Look for Middleware function
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok {
-			continue
+	// The kind of the file's Middleware function, if it declares one
+	facts, err := cachedFacts(s.cache, "middleware", filePath, func(content []byte) (middlewareFacts, error) {
+		file, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
+		if err != nil {
+			return middlewareFacts{}, fmt.Errorf("failed to parse: %w", err)
 		}
 
-		if fn.Name.Name != "Middleware" {
-			continue
-		}
+		// Look for Middleware function
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
 
-		if !isValidMiddlewareSignature(fn) {
-			if s.verbose {
-				fmt.Printf("  Warning: %s.Middleware has invalid signature, skipping\n", filePath)
+			if fn.Name.Name != "Middleware" {
+				continue
 			}
-			continue
-		}
 
-		mw := &MiddlewareFile{
-			FilePath:     filePath,
-			RelativePath: relPath,
-			Segments:     segments,
-			URLPattern:   BuildURLPattern(segments),
-			Scope:        BuildScope(segments),
-			Package:      MakePackageName(segments),
+			kind, ok := MiddlewareSignature(fn)
+			if !ok {
+				if s.verbose {
+					fmt.Printf("  Warning: %s.Middleware has invalid signature, skipping\n", filePath)
+				}
+				continue
+			}
+			return middlewareFacts{Found: true, Kind: kind}, nil
 		}
+		return middlewareFacts{}, nil
+	})
+	if err != nil || !facts.Found {
+		return nil, err
+	}
 
-		if s.verbose {
-			fmt.Printf("  Found middleware: %s (scope: %s)\n", mw.URLPattern, mw.Scope)
-		}
+	mw := &MiddlewareFile{
+		FilePath:     filePath,
+		RelativePath: relPath,
+		Segments:     segments,
+		URLPattern:   BuildURLPattern(segments),
+		Scope:        BuildScope(segments),
+		Package:      MakePackageName(segments),
+		Kind:         facts.Kind,
+	}
 
-		return mw, nil
+	if s.verbose {
+		fmt.Printf("  Found middleware: %s (scope: %s)\n", mw.URLPattern, mw.Scope)
 	}
 
-	return nil, nil
+	return mw, nil
 }
 
 // scanPageFile scans a page.templ file.
 func (s *Scanner) scanPageFile(filePath, relPath string, segments []Segment) (*PageFile, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-
 	// Check for Page() function
-	if !templPageSignatureRe.MatchString(string(content)) {
-		return nil, nil
+	found, err := cachedFacts(s.cache, "page", filePath, func(content []byte) (bool, error) {
+		return templPageSignatureRe.Match(content), nil
+	})
+	if err != nil || !found {
+		return nil, err
 	}
 
 	page := &PageFile{
-		FilePath:     filePath,
-		RelativePath: relPath,
-		Segments:     segments,
-		URLPattern:   BuildURLPattern(segments),
-		Title:        derivePageTitle(segments),
-		Package:      MakePackageName(segments),
-		Params:       ExtractParams(segments),
-		HasParams:    len(ExtractParams(segments)) > 0,
+		FilePath:      filePath,
+		RelativePath:  relPath,
+		Segments:      segments,
+		URLPattern:    BuildPagePattern(segments),
+		Title:         PageTitle(segments),
+		Package:       MakePackageName(segments),
+		Params:        ExtractParams(segments),
+		HasParams:     len(ExtractParams(segments)) > 0,
+		Slot:          SlotName(segments),
+		InterceptFrom: InterceptFrom(segments),
 	}
 
 	if s.verbose {
@@ -347,25 +553,21 @@ func (s *Scanner) scanPageFile(filePath, relPath string, segments []Segment) (*P
 
 // scanLayoutFile scans a layout.templ file.
 func (s *Scanner) scanLayoutFile(filePath, relPath string, segments []Segment) (*LayoutFile, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	contentStr := string(content)
-
 	// Check for Layout function with children support
-	hasLayout := strings.Contains(contentStr, "templ Layout(")
-	hasChildren := strings.Contains(contentStr, "{ children... }")
-
-	if !hasLayout || !hasChildren {
-		return nil, nil
+	found, err := cachedFacts(s.cache, "layout", filePath, func(content []byte) (bool, error) {
+		contentStr := string(content)
+		hasLayout := strings.Contains(contentStr, "templ Layout(")
+		hasChildren := strings.Contains(contentStr, "{ children... }")
+		return hasLayout && hasChildren, nil
+	})
+	if err != nil || !found {
+		return nil, err
 	}
 
 	layout := &LayoutFile{
 		FilePath:     filePath,
 		RelativePath: relPath,
-		PathPrefix:   BuildURLPattern(segments),
+		PathPrefix:   BuildPagePattern(segments),
 		Package:      MakePackageName(segments),
 	}
 
@@ -376,190 +578,117 @@ func (s *Scanner) scanLayoutFile(filePath, relPath string, segments []Segment) (
 	return layout, nil
 }
 
+// loaderFacts is what the scanner caches about a loader.go file.
+type loaderFacts struct {
+	Found    bool   // declares a Load function
+	DataType string // the data type Load returns
+}
+
 // scanLoaderFile scans a loader.go file.
 func (s *Scanner) scanLoaderFile(filePath, relPath string, segments []Segment) (*LoaderFile, error) {
-	file, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse: %w", err)
-	}
-
-	// Look for Load function
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok {
-			continue
+	facts, err := cachedFacts(s.cache, "loader", filePath, func(content []byte) (loaderFacts, error) {
+		file, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
+		if err != nil {
+			return loaderFacts{}, fmt.Errorf("failed to parse: %w", err)
 		}
 
-		if fn.Name.Name != "Load" {
-			continue
-		}
+		// Look for Load function
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
 
-		// Extract return type for data type name
-		dataType := extractLoaderDataType(fn)
-		if dataType == "" {
-			dataType = "LoaderData"
-		}
+			if fn.Name.Name != "Load" {
+				continue
+			}
 
-		loader := &LoaderFile{
-			FilePath:     filePath,
-			RelativePath: relPath,
-			URLPattern:   BuildURLPattern(segments),
-			DataType:     dataType,
-			Package:      MakePackageName(segments),
+			// Extract return type for data type name
+			dataType := extractLoaderDataType(fn)
+			if dataType == "" {
+				dataType = "LoaderData"
+			}
+			return loaderFacts{Found: true, DataType: dataType}, nil
 		}
+		return loaderFacts{}, nil
+	})
+	if err != nil || !facts.Found {
+		return nil, err
+	}
 
-		if s.verbose {
-			fmt.Printf("  Found loader: %s -> %s\n", loader.URLPattern, loader.DataType)
-		}
+	loader := &LoaderFile{
+		FilePath:     filePath,
+		RelativePath: relPath,
+		URLPattern:   BuildURLPattern(segments),
+		DataType:     facts.DataType,
+		Package:      MakePackageName(segments),
+	}
 
-		return loader, nil
+	if s.verbose {
+		fmt.Printf("  Found loader: %s -> %s\n", loader.URLPattern, loader.DataType)
 	}
 
-	return nil, nil
+	return loader, nil
+}
+
+// proxyFacts is what the scanner caches about a proxy.go file.
+type proxyFacts struct {
+	HasProxy bool     // declares a valid Proxy function
+	Matchers []string // the matchers of its ProxyConfig
 }
 
 // scanProxyFile scans a proxy.go file.
 func (s *Scanner) scanProxyFile(filePath string) (*ProxyFile, error) {
-	file, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse: %w", err)
-	}
-
-	proxy := &ProxyFile{
-		FilePath: filePath,
-		Package:  "app",
-	}
+	facts, err := cachedFacts(s.cache, "proxy", filePath, func(content []byte) (proxyFacts, error) {
+		var facts proxyFacts
+		file, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
+		if err != nil {
+			return facts, fmt.Errorf("failed to parse: %w", err)
+		}
 
-	// Look for Proxy function and extract matchers from ProxyConfig
-	for _, decl := range file.Decls {
-		switch d := decl.(type) {
-		case *ast.FuncDecl:
-			if d.Name.Name == "Proxy" {
-				// Proxy function found
-				if s.verbose {
-					fmt.Printf("  Found proxy function in %s\n", filePath)
-				}
-			}
-		case *ast.GenDecl:
-			if d.Tok == token.VAR {
-				for _, spec := range d.Specs {
-					vs, ok := spec.(*ast.ValueSpec)
-					if !ok {
-						continue
+		// Look for Proxy function and extract matchers from ProxyConfig
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Name.Name == "Proxy" && IsProxyFunc(d) {
+					facts.HasProxy = true
+					if s.verbose {
+						fmt.Printf("  Found proxy function in %s\n", filePath)
 					}
-					for _, name := range vs.Names {
-						if name.Name == "ProxyConfig" {
-							proxy.Matchers = extractMatchersFromSpec(vs)
+				}
+			case *ast.GenDecl:
+				if d.Tok == token.VAR {
+					for _, spec := range d.Specs {
+						vs, ok := spec.(*ast.ValueSpec)
+						if !ok {
+							continue
+						}
+						for _, name := range vs.Names {
+							if name.Name == "ProxyConfig" {
+								facts.Matchers = extractMatchersFromSpec(vs)
+							}
 						}
 					}
 				}
 			}
 		}
+		return facts, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return proxy, nil
-}
-
-// isValidHandlerSignature checks if a function has the signature:
-// func(c *nexo.Context) error
-func isValidHandlerSignature(fn *ast.FuncDecl) bool {
-	// Must have exactly one parameter
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
-		return false
-	}
-
-	// Parameter must be a pointer type
-	param := fn.Type.Params.List[0]
-	starExpr, ok := param.Type.(*ast.StarExpr)
-	if !ok {
-		return false
-	}
-
-	// Check if it's Context
-	switch x := starExpr.X.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			if ident.Name == "nexo" && x.Sel.Name == "Context" {
-				goto checkReturn
-			}
-		}
-	case *ast.Ident:
-		if x.Name == "Context" {
-			goto checkReturn
-		}
-	}
-	return false
-
-checkReturn:
-	// Must have exactly one return value
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	// Return must be error type
-	result := fn.Type.Results.List[0]
-	if ident, ok := result.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
-	}
-
-	return false
-}
-
-// isValidMiddlewareSignature checks if a function has the signature:
-// func() nexo.MiddlewareFunc
-func isValidMiddlewareSignature(fn *ast.FuncDecl) bool {
-	// Must have no parameters
-	if fn.Type.Params != nil && len(fn.Type.Params.List) > 0 {
-		return false
-	}
-
-	// Must have one return value
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
-		return false
-	}
-
-	// Check return type is MiddlewareFunc
-	result := fn.Type.Results.List[0]
-	switch x := result.Type.(type) {
-	case *ast.SelectorExpr:
-		if ident, ok := x.X.(*ast.Ident); ok {
-			return ident.Name == "nexo" && x.Sel.Name == "MiddlewareFunc"
-		}
-	case *ast.Ident:
-		return x.Name == "MiddlewareFunc"
-	}
-
-	return false
+	return &ProxyFile{
+		FilePath: filePath,
+		Matchers: facts.Matchers,
+		HasProxy: facts.HasProxy,
+		Package:  "app",
+	}, nil
 }
 
 // templPageSignatureRe matches templ Page() or templ Page(params...)
 var templPageSignatureRe = regexp.MustCompile(`templ\s+Page\s*\(`)
 
-// derivePageTitle derives a page title from segments.
-func derivePageTitle(segments []Segment) string {
-	if len(segments) == 0 {
-		return "Home"
-	}
-
-	// Use the last non-group segment
-	for i := len(segments) - 1; i >= 0; i-- {
-		seg := segments[i]
-		if seg.Type == SegmentGroup {
-			continue
-		}
-
-		// Convert to title case
-		name := seg.Name
-		if seg.Type == SegmentDynamic || seg.Type == SegmentCatchAll || seg.Type == SegmentOptionalCatchAll {
-			name = seg.Name
-		}
-
-		return toTitleCase(name)
-	}
-
-	return "Home"
-}
-
 // toTitleCase converts a string to title case for display.
 func toTitleCase(s string) string {
 	if s == "" {
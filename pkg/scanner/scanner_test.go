@@ -0,0 +1,393 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScan_MethodFiles(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	dir := filepath.Join(appDir, "users", "[id]")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"get.go":     "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+		"put.go":     "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Put(c *nexo.Context) error { return nil }\n",
+		"helpers.go": "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Delete(c *nexo.Context) error { return nil }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := NewScanner(appDir).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Routes) != 1 {
+		t.Fatalf("Scan() found %d routes, want 1 merged route", len(result.Routes))
+	}
+
+	route := result.Routes[0]
+	if route.URLPattern != "/users/{id}" {
+		t.Errorf("URLPattern = %q, want /users/{id}", route.URLPattern)
+	}
+	got := make(map[string]string)
+	for _, h := range route.Handlers {
+		got[h.Method] = filepath.Base(h.FilePath)
+	}
+	if len(got) != 2 || got["GET"] != "get.go" || got["PUT"] != "put.go" {
+		t.Errorf("handlers = %v, want GET in get.go and PUT in put.go", got)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("unexpected conflicts: %+v", result.Conflicts)
+	}
+}
+
+func TestManifest_RouteDocs(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	dir := filepath.Join(appDir, "users")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"get.go":  "package users\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Get(c *nexo.Context) error { return nil }\n",
+		"post.go": "package users\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Post(c *nexo.Context) error { return nil }\n",
+		"route.go": `package users
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Describe() nexo.RouteDoc {
+	return nexo.RouteDoc{Summary: "List users", Tags: []string{"users"}, Response: []User{}}
+}
+
+func DescribePost() nexo.RouteDoc {
+	return nexo.RouteDoc{Summary: "Create a user", Request: CreateUserInput{}, Response: User{}}
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := NewScanner(appDir).Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	want := map[string]*RouteDoc{
+		"GET":  {Summary: "List users", Tags: []string{"users"}, Response: "[]User"},
+		"POST": {Summary: "Create a user", Request: "CreateUserInput", Response: "User"},
+	}
+	if len(m.Routes) != len(want) {
+		t.Fatalf("Manifest() routes = %+v", m.Routes)
+	}
+	for _, r := range m.Routes {
+		if !reflect.DeepEqual(r.Doc, want[r.Method]) {
+			t.Errorf("%s Doc = %+v, want %+v", r.Method, r.Doc, want[r.Method])
+		}
+	}
+}
+
+func TestScan_TypedParams(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	route := "package id\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Get(c *nexo.Context) error { return nil }\n"
+	for _, dir := range []string{"users/[id:int]", "posts/[id:float]"} {
+		if err := os.MkdirAll(filepath.Join(appDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(appDir, dir, "route.go"), []byte(route), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := NewScanner(appDir).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Routes) != 1 || result.Routes[0].URLPattern != "/users/{id:int}" {
+		t.Fatalf("Scan() routes = %+v, want /users/{id:int} only", result.Routes)
+	}
+	if params := ExtractParams(result.Routes[0].Segments); len(params) != 1 || params[0].Type != ParamInt {
+		t.Errorf("Params = %+v, want id of type int", params)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, `unknown parameter type "float"`) {
+		t.Errorf("Scan() warnings = %+v, want one for [id:float]", result.Warnings)
+	}
+}
+
+func TestScan_SlotAndInterceptPages(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	pages := []string{
+		"feed",
+		"feed/@modal/(..)photos/[id]",
+		"photos/[id]",
+		"dashboard/@team",
+	}
+	for _, dir := range pages {
+		full := filepath.Join(appDir, filepath.FromSlash(dir))
+		if err := os.MkdirAll(full, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(full, "page.templ"), []byte("package p\n\ntempl Page() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := NewScanner(appDir).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	got := make(map[string]PageFile)
+	for _, page := range result.Pages {
+		got[filepath.ToSlash(filepath.Dir(page.RelativePath))] = page
+	}
+	intercept := got["feed/@modal/(..)photos/[id]"]
+	if intercept.URLPattern != "/photos/{id}" || intercept.InterceptFrom != "/feed" || intercept.Slot != "modal" {
+		t.Errorf("intercepting page = %+v", intercept)
+	}
+	if page := got["photos/[id]"]; page.InterceptFrom != "" || page.Slot != "" {
+		t.Errorf("plain page = %+v", page)
+	}
+	slot := got["dashboard/@team"]
+	if slot.URLPattern != "/dashboard" || slot.Slot != "team" || slot.Package != "team" || slot.Title != "Dashboard" {
+		t.Errorf("slot page = %+v", slot)
+	}
+}
+
+func TestScanner_Manifest(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	files := map[string]string{
+		"users/route.go": `package users
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+type CreateUser struct{ Name string }
+
+func Get(c *nexo.Context) error { return nil }
+
+func Post(c *nexo.Context, body CreateUser) error { return nil }
+
+func Delete(c *nexo.Context, db *Store) error { return nil }
+
+func Put(name string) error { return nil }
+`,
+		"orders/route.go": `package orders
+
+import (
+	"context"
+
+	"github.com/abdul-hamid-achik/nexo/pkg/nexo"
+)
+
+type CreateOrder struct{ Item string }
+
+func Get(ctx context.Context, c *nexo.Context) error { return nil }
+
+func Post(ctx context.Context, c *nexo.Context, body CreateOrder) error { return nil }
+`,
+		"docs/[...slug]/route.go": `package slug
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+// nexo:priority 80
+func Get(c *nexo.Context) error { return nil }
+`,
+		"middleware.go": `package app
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }
+`,
+		"proxy.go": `package app
+
+import "github.com/abdul-hamid-achik/nexo/pkg/nexo"
+
+func Proxy(c *nexo.Context) (*nexo.ProxyResult, error) { return nil, nil }
+
+var ProxyConfig = &nexo.ProxyConfig{Matcher: []string{"/api/*"}}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(appDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := NewScanner(appDir).Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+
+	got := make(map[string]ManifestRoute)
+	for _, r := range m.Routes {
+		got[r.Method+" "+r.Pattern] = r
+	}
+	want := map[string]ManifestRoute{
+		"GET /users":          {Kind: HandlerPlain, Priority: 100},
+		"POST /users":         {Kind: HandlerBody, Priority: 100},
+		"DELETE /users":       {Kind: HandlerInjected, Priority: 100},
+		"GET /orders":         {Kind: HandlerPlain, Context: true, Priority: 100},
+		"POST /orders":        {Kind: HandlerBody, Context: true, Priority: 100},
+		"GET /docs/{slug...}": {Kind: HandlerPlain, Priority: 80, PriorityOverride: true, CatchAllParam: "slug"},
+	}
+	if len(got) != len(want) {
+		t.Errorf("Manifest() routes = %+v", m.Routes)
+	}
+	for key, w := range want {
+		r, ok := got[key]
+		if !ok {
+			t.Errorf("route %s not found", key)
+			continue
+		}
+		if r.Kind != w.Kind || r.Context != w.Context || r.Priority != w.Priority || r.PriorityOverride != w.PriorityOverride || r.CatchAllParam != w.CatchAllParam {
+			t.Errorf("route %s = %+v, want kind %s (context %v), priority %d (override %v), catch-all %q", key, r, w.Kind, w.Context, w.Priority, w.PriorityOverride, w.CatchAllParam)
+		}
+	}
+
+	if len(m.Middlewares) != 1 || m.Middlewares[0].Kind != MiddlewareDirect {
+		t.Errorf("Manifest() middlewares = %+v", m.Middlewares)
+	}
+	if m.Proxy == nil || !m.Proxy.HasProxy || len(m.Proxy.Matchers) != 1 || m.Proxy.Matchers[0] != "/api/*" {
+		t.Errorf("Manifest() proxy = %+v", m.Proxy)
+	}
+}
+
+func TestRouteManifest_InheritedMiddleware(t *testing.T) {
+	result := &ScanResult{
+		Middlewares: []MiddlewareFile{
+			{URLPattern: "/api/admin", Scope: "api/admin"},
+			{URLPattern: "/", Scope: ""},
+			{URLPattern: "/api", Scope: "api", Name: "auth"},
+			{URLPattern: "/reports", Scope: "(admin)/reports"},
+		},
+		Routes: []RouteFile{
+			{URLPattern: "/api/admin/users", Scope: "api/admin/users", Handlers: []Handler{{Name: "Get", Method: "GET"}}},
+			{URLPattern: "/api/login", Scope: "api/login", FilePath: "app/api/login/route.go", Handlers: []Handler{
+				{Name: "Post", Method: "POST", Options: &RouteOptions{Skip: []string{"/api/admin"}}},
+			}},
+			{URLPattern: "/reports", Scope: "(public)/reports", Handlers: []Handler{{Name: "Get", Method: "GET"}}},
+		},
+	}
+	m := NewManifest("app", result)
+
+	want := [][]string{{"/", "auth", "/api/admin"}, {"/", "auth"}, {"/"}}
+	for i, r := range m.Routes {
+		if got := m.InheritedMiddleware(r); !slices.Equal(got, want[i]) {
+			t.Errorf("InheritedMiddleware(%s) = %v, want %v", r.Pattern, got, want[i])
+		}
+	}
+	if len(m.Warnings) != 1 || m.Warnings[0].FilePath != "app/api/login/route.go" || !strings.Contains(m.Warnings[0].Message, `"/api/admin"`) {
+		t.Errorf("warnings = %+v, want one for the login route skipping /api/admin", m.Warnings)
+	}
+}
+
+func TestRouteManifest_WriteFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := &RouteManifest{
+		Version: ManifestVersion,
+		Nexo:    "v1.0.0",
+		AppDir:  "app",
+		Routes: []ManifestRoute{{
+			Method:        "GET",
+			Pattern:       "/docs/*",
+			FilePath:      filepath.Join("app", "docs", "[...slug]", "route.go"),
+			Handler:       "Get",
+			Kind:          HandlerBody,
+			Priority:      5,
+			CatchAllParam: "slug",
+		}},
+	}
+	if err := m.WriteFile(nil, ManifestFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(ManifestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("manifest is not JSON: %v", err)
+	}
+	if got["version"] != float64(ManifestVersion) {
+		t.Errorf("version = %v, want %d", got["version"], ManifestVersion)
+	}
+	// Empty sections are lists, not null
+	if pages, ok := got["pages"].([]any); !ok || len(pages) != 0 {
+		t.Errorf("pages = %v, want []", got["pages"])
+	}
+	route := got["routes"].([]any)[0].(map[string]any)
+	if route["file"] != "app/docs/[...slug]/route.go" || route["kind"] != "body" || route["catch_all"] != "slug" {
+		t.Errorf("route = %v", route)
+	}
+
+	var back RouteManifest
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if back.Routes[0].Kind != HandlerBody {
+		t.Errorf("decoded kind = %s, want body", back.Routes[0].Kind)
+	}
+}
+
+func TestScan_NamedMiddleware(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "app")
+	mw := func(pkg string) string {
+		return "package " + pkg + "\n\nimport \"github.com/abdul-hamid-achik/nexo/pkg/nexo\"\n\nfunc Middleware(next nexo.HandlerFunc) nexo.HandlerFunc { return next }\n"
+	}
+	files := map[string]string{
+		"_middleware/auth/middleware.go":      mw("auth"),
+		"_middleware/ratelimit/middleware.go": mw("ratelimit"),
+		"api/middleware.go":                   "// nexo:use ratelimit auth\n" + mw("api"),
+		"(admin)/reports/doc.go":              "// nexo:use auth\npackage reports\n",
+		"(admin)/reports/route.go":            "// nexo:use audit\npackage reports\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(appDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := NewScanner(appDir).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	var got []string
+	for _, m := range result.Middlewares {
+		got = append(got, m.URLPattern+" "+m.Scope+" "+m.Name+" "+filepath.ToSlash(m.RelativePath))
+	}
+	want := []string{
+		"/api api ratelimit _middleware/ratelimit/middleware.go",
+		"/api api auth _middleware/auth/middleware.go",
+		"/api api  api/middleware.go",
+		"/reports (admin)/reports auth _middleware/auth/middleware.go",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Scan() middlewares =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, `unknown middleware "audit"`) {
+		t.Errorf("Scan() warnings = %+v, want one for the unknown audit middleware", result.Warnings)
+	}
+}
@@ -0,0 +1,660 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTP method to function name mapping
+var httpMethods = map[string]string{
+	"Get":     http.MethodGet,
+	"Post":    http.MethodPost,
+	"Put":     http.MethodPut,
+	"Patch":   http.MethodPatch,
+	"Delete":  http.MethodDelete,
+	"Head":    http.MethodHead,
+	"Options": http.MethodOptions,
+}
+
+// HandlerMethod returns the HTTP method served by a handler function named
+// name (Get, Post, ...), and whether name is a handler name at all.
+func HandlerMethod(name string) (string, bool) {
+	method, ok := httpMethods[name]
+	return method, ok
+}
+
+// IsRouteFile reports whether name is a file that declares route handlers:
+// route.go, or a per-method file like get.go or post.go. A route directory
+// can split its handlers across per-method files, and may mix them with a
+// route.go holding shared code; all of them register under one pattern.
+func IsRouteFile(name string) bool {
+	if name == "route.go" {
+		return true
+	}
+	method, ok := strings.CutSuffix(name, ".go")
+	if !ok || method == "" {
+		return false
+	}
+	_, ok = httpMethods[strings.ToUpper(method[:1])+method[1:]]
+	return ok && method == strings.ToLower(method)
+}
+
+// HandlerKind is the signature a route handler is declared with.
+type HandlerKind int
+
+const (
+	// HandlerPlain is func(c *nexo.Context) error
+	HandlerPlain HandlerKind = iota
+	// HandlerBody is func(c *nexo.Context, body T) error, where T is a type
+	// declared in the route package
+	HandlerBody
+	// HandlerInjected is func(c *nexo.Context, deps...) error, where deps
+	// are resolved from the app container
+	HandlerInjected
+)
+
+// String returns the name of the handler kind.
+func (k HandlerKind) String() string {
+	switch k {
+	case HandlerBody:
+		return "body"
+	case HandlerInjected:
+		return "injected"
+	}
+	return "plain"
+}
+
+// MarshalText encodes the kind as its name.
+func (k HandlerKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind from its name.
+func (k *HandlerKind) UnmarshalText(text []byte) error {
+	for _, kind := range []HandlerKind{HandlerPlain, HandlerBody, HandlerInjected} {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown handler kind %q", text)
+}
+
+// HandlerSignature classifies the signature of fn as a route handler. It
+// returns false when fn can't be registered as a handler. A handler of any
+// kind may take a context.Context first (see TakesContext).
+func HandlerSignature(fn *ast.FuncDecl) (HandlerKind, bool) {
+	fn = WithoutContext(fn)
+	switch {
+	case isValidHandlerSignature(fn):
+		return HandlerPlain, true
+	case BodyType(fn) != "":
+		return HandlerBody, true
+	case isInjectedHandlerSignature(fn):
+		return HandlerInjected, true
+	}
+	return 0, false
+}
+
+// BodyType returns T when fn has the signature
+// func(c *nexo.Context, body T) error, where T is a type declared in the
+// route package, and "" otherwise. A leading context.Context is allowed.
+func BodyType(fn *ast.FuncDecl) string {
+	fn = WithoutContext(fn)
+	if fn.Type.Params == nil || len(fn.Type.Params.List) != 2 || len(fn.Type.Params.List[0].Names) > 1 {
+		return ""
+	}
+
+	ident, ok := fn.Type.Params.List[1].Type.(*ast.Ident)
+	if !ok || !ident.IsExported() || !hasContextParam(fn) {
+		return ""
+	}
+	return ident.Name
+}
+
+// TakesContext reports whether the first parameter of fn is a single
+// context.Context, as in func(ctx context.Context, c *nexo.Context) error.
+// Such handlers and loaders get the request's context.
+func TakesContext(fn *ast.FuncDecl) bool {
+	if fn.Type.Params == nil || len(fn.Type.Params.List) == 0 || len(fn.Type.Params.List[0].Names) > 1 {
+		return false
+	}
+	sel, ok := fn.Type.Params.List[0].Type.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context" && sel.Sel.Name == "Context"
+}
+
+// WithoutContext returns fn without its leading context.Context parameter,
+// or fn itself when it doesn't take one.
+func WithoutContext(fn *ast.FuncDecl) *ast.FuncDecl {
+	if !TakesContext(fn) {
+		return fn
+	}
+	return &ast.FuncDecl{Name: fn.Name, Type: &ast.FuncType{
+		Params:  &ast.FieldList{List: fn.Type.Params.List[1:]},
+		Results: fn.Type.Results,
+	}}
+}
+
+// isInjectedHandlerSignature checks if a function has the signature:
+// func(c *nexo.Context, deps...) error
+// Predeclared types such as string are not valid dependencies.
+func isInjectedHandlerSignature(fn *ast.FuncDecl) bool {
+	if fn.Type.Params == nil || len(fn.Type.Params.List) < 2 || len(fn.Type.Params.List[0].Names) > 1 {
+		return false
+	}
+
+	for _, field := range fn.Type.Params.List[1:] {
+		if ident, ok := field.Type.(*ast.Ident); ok && !ident.IsExported() {
+			return false
+		}
+	}
+	return hasContextParam(fn)
+}
+
+// hasContextParam reports whether fn takes a *nexo.Context first and
+// returns an error, whatever its other parameters.
+func hasContextParam(fn *ast.FuncDecl) bool {
+	ctxOnly := &ast.FuncDecl{Type: &ast.FuncType{
+		Params:  &ast.FieldList{List: fn.Type.Params.List[:1]},
+		Results: fn.Type.Results,
+	}}
+	return isValidHandlerSignature(ctxOnly)
+}
+
+// isValidHandlerSignature checks if a function has the signature:
+// func(c *nexo.Context) error
+func isValidHandlerSignature(fn *ast.FuncDecl) bool {
+	// Must have exactly one parameter
+	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
+		return false
+	}
+
+	// Parameter must be a pointer to Context
+	if !isContextPointer(fn.Type.Params.List[0].Type) {
+		return false
+	}
+
+	// Must have exactly one return value
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+		return false
+	}
+
+	// Return must be error type
+	return isErrorType(fn.Type.Results.List[0].Type)
+}
+
+// MiddlewareKind is the signature a middleware.go Middleware function is
+// declared with.
+type MiddlewareKind int
+
+const (
+	// MiddlewareDirect is func(next nexo.HandlerFunc) nexo.HandlerFunc, as
+	// written by nexo generate middleware
+	MiddlewareDirect MiddlewareKind = iota
+	// MiddlewareFactory is func() nexo.MiddlewareFunc
+	MiddlewareFactory
+)
+
+// String returns the name of the middleware kind.
+func (k MiddlewareKind) String() string {
+	if k == MiddlewareFactory {
+		return "factory"
+	}
+	return "direct"
+}
+
+// MarshalText encodes the kind as its name.
+func (k MiddlewareKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind from its name.
+func (k *MiddlewareKind) UnmarshalText(text []byte) error {
+	for _, kind := range []MiddlewareKind{MiddlewareDirect, MiddlewareFactory} {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown middleware kind %q", text)
+}
+
+// MiddlewareSignature classifies the signature of fn as a middleware.go
+// Middleware function. It returns false when fn can't be used as one.
+func MiddlewareSignature(fn *ast.FuncDecl) (MiddlewareKind, bool) {
+	// Must have one return value
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+		return 0, false
+	}
+	result := fn.Type.Results.List[0].Type
+
+	if fn.Type.Params == nil || len(fn.Type.Params.List) == 0 {
+		return MiddlewareFactory, isNexoType(result, "MiddlewareFunc")
+	}
+	params := fn.Type.Params.List
+	if len(params) == 1 && len(params[0].Names) <= 1 && isNexoType(params[0].Type, "HandlerFunc") {
+		return MiddlewareDirect, isNexoType(result, "HandlerFunc")
+	}
+	return 0, false
+}
+
+// IsProxyFunc reports whether fn has the signature of a proxy.go Proxy
+// function: func(c *nexo.Context) (*nexo.ProxyResult, error)
+func IsProxyFunc(fn *ast.FuncDecl) bool {
+	// Must have exactly one parameter
+	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
+		return false
+	}
+
+	// Parameter must be a pointer to Context
+	if !isContextPointer(fn.Type.Params.List[0].Type) {
+		return false
+	}
+
+	// Must have exactly two return values
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 2 {
+		return false
+	}
+
+	// First return must be *ProxyResult
+	star, ok := fn.Type.Results.List[0].Type.(*ast.StarExpr)
+	if !ok || !isNexoType(star.X, "ProxyResult") {
+		return false
+	}
+
+	// Second return must be error
+	return isErrorType(fn.Type.Results.List[1].Type)
+}
+
+// DescribeFunc reports whether name is a function documenting route
+// handlers (see RouteDoc), with the name of the handler it documents: ""
+// for Describe and Get for DescribeGet.
+func DescribeFunc(name string) (string, bool) {
+	handler, ok := strings.CutPrefix(name, "Describe")
+	if !ok || handler == "" {
+		return "", ok
+	}
+	_, ok = HandlerMethod(handler)
+	return handler, ok
+}
+
+// DescribeDoc returns the docs a Describe function returns. It must be
+// declared as func() nexo.RouteDoc and return a literal, so the scanner
+// can read it without running it: string fields take string literals,
+// Tags a []string literal, and Request and Response a value of their
+// type, like User{}, &User{} or []User{}.
+func DescribeDoc(fn *ast.FuncDecl) (RouteDoc, error) {
+	var doc RouteDoc
+	results := fn.Type.Results
+	if fn.Recv != nil || fn.Type.Params.NumFields() != 0 || results.NumFields() != 1 || !isNexoType(results.List[0].Type, "RouteDoc") {
+		return doc, fmt.Errorf("%s must be declared as func() nexo.RouteDoc", fn.Name.Name)
+	}
+	var lit *ast.CompositeLit
+	if fn.Body != nil && len(fn.Body.List) == 1 {
+		if ret, ok := fn.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			lit, _ = ret.Results[0].(*ast.CompositeLit)
+		}
+	}
+	if lit == nil {
+		return doc, fmt.Errorf("%s must return a nexo.RouteDoc literal", fn.Name.Name)
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		key, isIdent := kv.Key.(*ast.Ident)
+		if !ok || !isIdent {
+			return doc, fmt.Errorf("%s: RouteDoc fields must be named", fn.Name.Name)
+		}
+		var err error
+		switch key.Name {
+		case "Summary":
+			doc.Summary, err = stringLit(kv.Value)
+		case "Description":
+			doc.Description, err = stringLit(kv.Value)
+		case "Tags":
+			doc.Tags, err = stringsLit(kv.Value)
+		case "Request":
+			doc.Request, err = typeOfValue(kv.Value)
+		case "Response":
+			doc.Response, err = typeOfValue(kv.Value)
+		default:
+			err = fmt.Errorf("unknown field")
+		}
+		if err != nil {
+			return doc, fmt.Errorf("%s: RouteDoc.%s: %w", fn.Name.Name, key.Name, err)
+		}
+	}
+	return doc, nil
+}
+
+// stringLit returns the value of a string literal.
+func stringLit(expr ast.Expr) (string, error) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", fmt.Errorf("want a string literal")
+	}
+	return strconv.Unquote(lit.Value)
+}
+
+// stringsLit returns the values of a []string literal.
+func stringsLit(expr ast.Expr) ([]string, error) {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, fmt.Errorf("want a []string literal")
+	}
+	values := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		s, err := stringLit(elt)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+// typeOfValue returns the type of a T{}, &T{} or []T{} literal.
+func typeOfValue(expr ast.Expr) (string, error) {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok || lit.Type == nil {
+		return "", fmt.Errorf("want a value like T{}")
+	}
+	return types.ExprString(lit.Type), nil
+}
+
+// isContextPointer reports whether expr is *nexo.Context, or *Context
+// within the nexo package.
+func isContextPointer(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isNexoType(star.X, "Context")
+}
+
+// isNexoType reports whether expr is nexo.name, or name within the nexo
+// package.
+func isNexoType(expr ast.Expr, name string) bool {
+	switch x := expr.(type) {
+	case *ast.SelectorExpr:
+		ident, ok := x.X.(*ast.Ident)
+		return ok && ident.Name == "nexo" && x.Sel.Name == name
+	case *ast.Ident:
+		return x.Name == name
+	}
+	return false
+}
+
+// isErrorType reports whether expr is the error type.
+func isErrorType(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+// priorityDirectiveRe matches "// nexo:priority 80" and "//nexo:priority 80".
+var priorityDirectiveRe = regexp.MustCompile(`^//\s*nexo:priority\s+(-?\d+)\s*$`)
+
+// PriorityDirective returns the route priority set by a nexo:priority line
+// in a doc comment, and whether one was found.
+func PriorityDirective(doc *ast.CommentGroup) (int, bool) {
+	if doc == nil {
+		return 0, false
+	}
+	for _, c := range doc.List {
+		if m := priorityDirectiveRe.FindStringSubmatch(c.Text); m != nil {
+			if p, err := strconv.Atoi(m[1]); err == nil {
+				return p, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// routeDirectiveRe matches "// nexo:route cache=60s auth=required".
+var routeDirectiveRe = regexp.MustCompile(`^//\s*nexo:route\s+(.+)$`)
+
+// RouteDirective returns the options set by the nexo:route lines of a
+// handler's doc comment, or nil when there are none. Options are
+// space-separated key=value pairs:
+//
+//	methods=GET,HEAD  serve these methods instead of the handler's own
+//	cache=60s         cache responses for a duration
+//	auth=required     require an authenticated principal (or "none")
+//	skip=auth,/api    opt out of inherited middleware
+//	only=/api         keep only these inherited middleware
+func RouteDirective(doc *ast.CommentGroup) (*RouteOptions, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	var opts *RouteOptions
+	for _, c := range doc.List {
+		m := routeDirectiveRe.FindStringSubmatch(c.Text)
+		if m == nil {
+			continue
+		}
+		if opts == nil {
+			opts = &RouteOptions{}
+		}
+		for _, field := range strings.Fields(m[1]) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || value == "" {
+				return nil, fmt.Errorf("nexo:route option %q is not key=value", field)
+			}
+			switch key {
+			case "methods":
+				for _, name := range strings.Split(value, ",") {
+					method, ok := "", false
+					if name != "" {
+						method, ok = HandlerMethod(strings.ToUpper(name[:1]) + strings.ToLower(name[1:]))
+					}
+					if !ok {
+						return nil, fmt.Errorf("nexo:route: unknown method %q", name)
+					}
+					if !slices.Contains(opts.Methods, method) {
+						opts.Methods = append(opts.Methods, method)
+					}
+				}
+			case "cache":
+				d, err := time.ParseDuration(value)
+				if err != nil || d <= 0 {
+					return nil, fmt.Errorf("nexo:route: invalid cache duration %q", value)
+				}
+				opts.Cache = d
+			case "auth":
+				switch value {
+				case "required":
+					opts.Auth = true
+				case "none":
+					opts.Auth = false
+				default:
+					return nil, fmt.Errorf("nexo:route: auth must be required or none, not %q", value)
+				}
+			case "skip", "only":
+				names := &opts.Skip
+				if key == "only" {
+					names = &opts.Only
+				}
+				for _, name := range strings.Split(value, ",") {
+					if name == "" {
+						return nil, fmt.Errorf("nexo:route: empty middleware name in %s=%s", key, value)
+					}
+					if !slices.Contains(*names, name) {
+						*names = append(*names, name)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("nexo:route: unknown option %q", key)
+			}
+		}
+	}
+	if opts != nil && len(opts.Skip) > 0 && len(opts.Only) > 0 {
+		return nil, fmt.Errorf("nexo:route: skip and only can't be combined")
+	}
+	return opts, nil
+}
+
+// FilterMiddleware splits the names of the middleware a route inherits,
+// in order, into those that run and those the route's skip or only option
+// leaves out. It fails when the option names middleware the route doesn't
+// inherit, which is usually a typo or a moved directory.
+func (o *RouteOptions) FilterMiddleware(inherited []string) (kept, skipped []string, err error) {
+	if o == nil || (len(o.Skip) == 0 && len(o.Only) == 0) {
+		return inherited, nil, nil
+	}
+	for _, name := range append(o.Skip, o.Only...) {
+		if !slices.Contains(inherited, name) {
+			return nil, nil, fmt.Errorf("nexo:route: no inherited middleware %q (inherited: %s)", name, middlewareList(inherited))
+		}
+	}
+	for _, name := range inherited {
+		if slices.Contains(o.Skip, name) || (len(o.Only) > 0 && !slices.Contains(o.Only, name)) {
+			skipped = append(skipped, name)
+		} else {
+			kept = append(kept, name)
+		}
+	}
+	return kept, skipped, nil
+}
+
+// middlewareList returns names for an error message.
+func middlewareList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// routeOptionsJSON is the JSON form of RouteOptions, with the cache
+// duration written like "1m0s".
+type routeOptionsJSON struct {
+	Methods []string `json:"methods,omitempty"`
+	Cache   string   `json:"cache,omitempty"`
+	Auth    bool     `json:"auth,omitempty"`
+	Skip    []string `json:"skip,omitempty"`
+	Only    []string `json:"only,omitempty"`
+}
+
+// MarshalJSON encodes the options with a readable cache duration.
+func (o RouteOptions) MarshalJSON() ([]byte, error) {
+	out := routeOptionsJSON{Methods: o.Methods, Auth: o.Auth, Skip: o.Skip, Only: o.Only}
+	if o.Cache > 0 {
+		out.Cache = o.Cache.String()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes options encoded by MarshalJSON.
+func (o *RouteOptions) UnmarshalJSON(data []byte) error {
+	var in routeOptionsJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*o = RouteOptions{Methods: in.Methods, Auth: in.Auth, Skip: in.Skip, Only: in.Only}
+	if in.Cache != "" {
+		d, err := time.ParseDuration(in.Cache)
+		if err != nil {
+			return err
+		}
+		o.Cache = d
+	}
+	return nil
+}
+
+// Middleware returns the Go expressions of the middleware that apply o to
+// a route, for generated code: authentication first, then the response
+// cache.
+func (o *RouteOptions) Middleware() []string {
+	if o == nil {
+		return nil
+	}
+	var mws []string
+	if o.Auth {
+		mws = append(mws, "nexo.RequireAuth()")
+	}
+	if o.Cache > 0 {
+		mws = append(mws, "nexo.CacheResponse("+durationExpr(o.Cache)+")")
+	}
+	return mws
+}
+
+// NeedsTime reports whether the middleware expressions of o use the time
+// package.
+func (o *RouteOptions) NeedsTime() bool {
+	return o != nil && o.Cache > 0
+}
+
+// durationExpr returns a Go expression for d, like 90 * time.Second.
+func durationExpr(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	} {
+		if d%unit.d == 0 {
+			return strconv.FormatInt(int64(d/unit.d), 10) + " * " + unit.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
+
+// HandlerPriority returns the priority override for a handler declared in
+// file. A directive on the handler wins over one in the file's package doc
+// comment, which applies to every handler in the file.
+func HandlerPriority(file *ast.File, fn *ast.FuncDecl) (int, bool) {
+	if p, ok := PriorityDirective(fn.Doc); ok {
+		return p, true
+	}
+	return PriorityDirective(file.Doc)
+}
+
+// MiddlewareRegistryDir is the directory of the app holding named
+// middleware, one app/_middleware/<name>/middleware.go per name.
+const MiddlewareRegistryDir = "_middleware"
+
+// useDirectiveRe matches "// nexo:use auth ratelimit" and
+// "//nexo:use auth, ratelimit".
+var useDirectiveRe = regexp.MustCompile(`^//\s*nexo:use\s+(.+)$`)
+
+// UseDirective returns the names listed by the nexo:use lines of a package
+// doc comment, in order and without duplicates. A directory whose package
+// says
+//
+//	// nexo:use auth ratelimit
+//
+// runs the named middleware of app/_middleware/auth and
+// app/_middleware/ratelimit for every route below it.
+func UseDirective(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var names []string
+	for _, c := range doc.List {
+		m := useDirectiveRe.FindStringSubmatch(c.Text)
+		if m == nil {
+			continue
+		}
+		for _, name := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRouteDirective(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		want    *RouteOptions
+		wantErr bool
+	}{
+		{"all options", []string{"// Get lists the posts.", "// nexo:route methods=GET,head cache=60s auth=required"},
+			&RouteOptions{Methods: []string{"GET", "HEAD"}, Cache: time.Minute, Auth: true}, false},
+		{"directive style", []string{"//nexo:route cache=5m"}, &RouteOptions{Cache: 5 * time.Minute}, false},
+		{"several lines", []string{"// nexo:route auth=required", "// nexo:route auth=none cache=1h"}, &RouteOptions{Cache: time.Hour}, false},
+		{"no directive", []string{"// Get lists the posts."}, nil, false},
+		{"no comment", nil, nil, false},
+		{"unknown option", []string{"// nexo:route timeout=5s"}, nil, true},
+		{"unknown method", []string{"// nexo:route methods=GET,FETCH"}, nil, true},
+		{"bad duration", []string{"// nexo:route cache=soon"}, nil, true},
+		{"bad auth", []string{"// nexo:route auth=maybe"}, nil, true},
+		{"not key=value", []string{"// nexo:route cache"}, nil, true},
+		{"skip", []string{"// nexo:route skip=auth,/api", "// nexo:route skip=auth"}, &RouteOptions{Skip: []string{"auth", "/api"}}, false},
+		{"only", []string{"// nexo:route only=/"}, &RouteOptions{Only: []string{"/"}}, false},
+		{"skip and only", []string{"// nexo:route skip=auth only=/"}, nil, true},
+		{"empty name", []string{"// nexo:route skip=auth,"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc *ast.CommentGroup
+			if tt.lines != nil {
+				doc = &ast.CommentGroup{}
+				for _, l := range tt.lines {
+					doc.List = append(doc.List, &ast.Comment{Text: l})
+				}
+			}
+
+			got, err := RouteDirective(doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RouteDirective() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RouteDirective() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteOptions_FilterMiddleware(t *testing.T) {
+	inherited := []string{"/", "auth", "/api"}
+	tests := []struct {
+		name          string
+		opts          *RouteOptions
+		kept, skipped []string
+		wantErr       bool
+	}{
+		{"no options", nil, inherited, nil, false},
+		{"skip", &RouteOptions{Skip: []string{"auth"}}, []string{"/", "/api"}, []string{"auth"}, false},
+		{"only", &RouteOptions{Only: []string{"/"}}, []string{"/"}, []string{"auth", "/api"}, false},
+		{"not inherited", &RouteOptions{Skip: []string{"ratelimit"}}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, skipped, err := tt.opts.FilterMiddleware(inherited)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterMiddleware() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(kept, tt.kept) || !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("FilterMiddleware() = %v, %v; want %v, %v", kept, skipped, tt.kept, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestRouteOptions_Middleware(t *testing.T) {
+	opts := &RouteOptions{Cache: 90 * time.Second, Auth: true}
+	want := []string{"nexo.RequireAuth()", "nexo.CacheResponse(90 * time.Second)"}
+	if got := opts.Middleware(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Middleware() = %v, want %v", got, want)
+	}
+	if !opts.NeedsTime() {
+		t.Error("NeedsTime() = false with a cache duration")
+	}
+
+	var none *RouteOptions
+	if got := none.Middleware(); got != nil {
+		t.Errorf("Middleware() of nil options = %v", got)
+	}
+}
+
+func TestDescribeDoc(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    RouteDoc
+		wantErr bool
+	}{
+		{"all fields", `func Describe() nexo.RouteDoc {
+	return nexo.RouteDoc{
+		Summary:     "Create a user",
+		Description: ` + "`Creates a user.`" + `,
+		Tags:        []string{"users", "admin"},
+		Request:     &CreateUserInput{},
+		Response:    []User{},
+	}
+}`, RouteDoc{Summary: "Create a user", Description: "Creates a user.", Tags: []string{"users", "admin"}, Request: "CreateUserInput", Response: "[]User"}, false},
+		{"other package", `func DescribeGet() nexo.RouteDoc { return nexo.RouteDoc{Response: models.User{}} }`, RouteDoc{Response: "models.User"}, false},
+		{"parameters", `func Describe(lang string) nexo.RouteDoc { return nexo.RouteDoc{} }`, RouteDoc{}, true},
+		{"wrong result", `func Describe() RouteInfo { return RouteInfo{} }`, RouteDoc{}, true},
+		{"not a literal", `func Describe() nexo.RouteDoc { return docs }`, RouteDoc{}, true},
+		{"computed summary", `func Describe() nexo.RouteDoc { return nexo.RouteDoc{Summary: name + "s"} }`, RouteDoc{}, true},
+		{"unknown field", `func Describe() nexo.RouteDoc { return nexo.RouteDoc{Deprecated: true} }`, RouteDoc{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), "route.go", "package users\n\n"+tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DescribeDoc(file.Decls[0].(*ast.FuncDecl))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DescribeDoc() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DescribeDoc() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeFunc(t *testing.T) {
+	for name, want := range map[string]string{"Describe": "", "DescribeGet": "Get", "DescribePost": "Post"} {
+		if got, ok := DescribeFunc(name); !ok || got != want {
+			t.Errorf("DescribeFunc(%q) = %q, %v", name, got, ok)
+		}
+	}
+	for _, name := range []string{"Get", "DescribeUsers", "describe"} {
+		if _, ok := DescribeFunc(name); ok {
+			t.Errorf("DescribeFunc(%q) = true", name)
+		}
+	}
+}
@@ -3,6 +3,8 @@
 // and extracts handler information using go/parser.
 package scanner
 
+import "time"
+
 // SegmentType represents the type of a route segment.
 type SegmentType int
 
@@ -17,6 +19,8 @@ const (
 	SegmentOptionalCatchAll
 	// SegmentGroup is a route group that doesn't affect the URL (e.g., (admin))
 	SegmentGroup
+	// SegmentSlot is a parallel route slot that doesn't affect the URL (e.g., @modal)
+	SegmentSlot
 )
 
 // Segment represents a parsed path segment.
@@ -27,11 +31,18 @@ type Segment struct {
 	Name string
 	// Type is the segment type
 	Type SegmentType
+	// Constraint is the type of a typed dynamic segment, like "int" for
+	// [id:int] or "re(\w+)" for [slug:re(\w+)] (see ValidateConstraint)
+	Constraint string
+	// Intercept is the intercepting route marker the name starts with
+	// ("(.)", "(..)", "(..)(..)" or "(...)"), if any
+	Intercept string
 }
 
-// RouteFile represents a discovered route.go file.
+// RouteFile represents a discovered route: a route.go file, per-method
+// files like get.go and post.go, or both, in one directory.
 type RouteFile struct {
-	// FilePath is the absolute path to the route.go file
+	// FilePath is the path to the first route file found in the directory
 	FilePath string
 	// RelativePath is the path relative to app directory
 	RelativePath string
@@ -45,6 +56,21 @@ type RouteFile struct {
 	Handlers []Handler
 	// Package is the Go package name for this route
 	Package string
+	// Docs are the docs of the route's Describe functions, by the name of
+	// the handler they document, or "" for Describe
+	Docs map[string]RouteDoc
+}
+
+// HandlerDoc returns the docs of h: those of its DescribeGet-style
+// function, or else those of Describe, or nil.
+func (rf *RouteFile) HandlerDoc(h Handler) *RouteDoc {
+	if doc, ok := rf.Docs[h.Name]; ok {
+		return &doc
+	}
+	if doc, ok := rf.Docs[""]; ok {
+		return &doc
+	}
+	return nil
 }
 
 // Handler represents a discovered handler function.
@@ -53,90 +79,169 @@ type Handler struct {
 	Name string
 	// Method is the HTTP method (e.g., "GET", "POST")
 	Method string
+	// Kind is the signature the handler is declared with
+	Kind HandlerKind
+	// Context reports whether the handler takes a context.Context first
+	Context bool `json:",omitempty"`
+	// FilePath is the path to the file declaring the handler
+	FilePath string
 	// Source is the extracted function body source code
 	Source string
+	// Priority is the priority set by a nexo:priority directive
+	Priority int
+	// HasPriority reports whether Priority overrides the calculated priority
+	HasPriority bool
+	// Options are the options set by a nexo:route directive, if any
+	Options *RouteOptions `json:",omitempty"`
+}
+
+// RouteDoc documents route handlers for the OpenAPI spec and other docs,
+// from a Describe function next to them:
+//
+//	func Describe() nexo.RouteDoc {
+//		return nexo.RouteDoc{
+//			Summary:  "Create a user",
+//			Tags:     []string{"users"},
+//			Request:  CreateUserInput{},
+//			Response: User{},
+//		}
+//	}
+//
+// Describe documents every handler of the route; DescribeGet, DescribePost
+// and so on document one and win over it.
+type RouteDoc struct {
+	// Summary and Description document the operation
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Tags group the operation in docs
+	Tags []string `json:"tags,omitempty"`
+	// Request and Response are the request body and response types, as
+	// written in the route package (e.g., "CreateUserInput", "[]User")
+	Request  string `json:"request,omitempty"`
+	Response string `json:"response,omitempty"`
+}
+
+// RouteOptions are the per-handler options of a nexo:route directive in
+// the handler's doc comment:
+//
+//	// nexo:route methods=GET,HEAD cache=60s auth=required
+//	func Get(c *nexo.Context) error { ... }
+//
+// Inherited middleware is named by the path of its middleware.go, like
+// "/api", or by its registry name for middleware used with nexo:use.
+type RouteOptions struct {
+	// Methods are the HTTP methods the handler serves, instead of the one
+	// its name implies
+	Methods []string
+	// Cache is how long responses are cached (see nexo.CacheResponse)
+	Cache time.Duration
+	// Auth requires an authenticated principal (see nexo.RequireAuth)
+	Auth bool
+	// Skip names inherited middleware the route opts out of
+	Skip []string
+	// Only names the inherited middleware the route keeps, skipping the
+	// others
+	Only []string
 }
 
 // MiddlewareFile represents a discovered middleware.go file.
 type MiddlewareFile struct {
 	// FilePath is the absolute path to the middleware.go file
-	FilePath string
+	FilePath string `json:"file"`
 	// RelativePath is the path relative to app directory
-	RelativePath string
+	RelativePath string `json:"-"`
 	// Segments are the parsed path segments
-	Segments []Segment
+	Segments []Segment `json:"-"`
 	// URLPattern is the URL prefix this middleware applies to
-	URLPattern string
+	URLPattern string `json:"path"`
 	// Scope is the middleware scope (preserves groups)
-	Scope string
+	Scope string `json:"scope"`
 	// Package is the Go package name
-	Package string
+	Package string `json:"-"`
+	// Kind is the signature the Middleware function is declared with
+	Kind MiddlewareKind `json:"kind"`
+	// Name is set when the directory uses a named middleware from the
+	// registry instead of declaring its own: FilePath, RelativePath and
+	// Package then describe app/_middleware/<Name>/middleware.go, while
+	// Segments, URLPattern and Scope describe the directory using it
+	Name string `json:"name,omitempty"`
 }
 
 // PageFile represents a discovered page.templ file.
 type PageFile struct {
 	// FilePath is the absolute path to the page.templ file
-	FilePath string
+	FilePath string `json:"file"`
 	// RelativePath is the path relative to app directory
-	RelativePath string
+	RelativePath string `json:"-"`
 	// Segments are the parsed path segments
-	Segments []Segment
+	Segments []Segment `json:"-"`
 	// URLPattern is the computed URL pattern
-	URLPattern string
+	URLPattern string `json:"pattern"`
 	// Title is the derived page title
-	Title string
+	Title string `json:"title"`
 	// Package is the Go package name
-	Package string
+	Package string `json:"-"`
 	// HasParams indicates if the page has route parameters
-	HasParams bool
+	HasParams bool `json:"-"`
 	// Params are the route parameters
-	Params []Param
+	Params []Param `json:"params,omitempty"`
+	// Slot is the name of the parallel route slot the page renders into
+	// (e.g., "modal" for app/@modal/page.templ), if any
+	Slot string `json:"slot,omitempty"`
+	// InterceptFrom is the URL pattern the page intercepts navigation from
+	// when it is in an intercepting directory like (..)photos, if any
+	InterceptFrom string `json:"intercept_from,omitempty"`
 }
 
 // LayoutFile represents a discovered layout.templ file.
 type LayoutFile struct {
 	// FilePath is the absolute path to the layout.templ file
-	FilePath string
+	FilePath string `json:"file"`
 	// RelativePath is the path relative to app directory
-	RelativePath string
+	RelativePath string `json:"-"`
 	// PathPrefix is the URL prefix this layout applies to
-	PathPrefix string
+	PathPrefix string `json:"prefix"`
 	// Package is the Go package name
-	Package string
+	Package string `json:"-"`
 }
 
 // LoaderFile represents a discovered loader.go file.
 type LoaderFile struct {
 	// FilePath is the absolute path to the loader.go file
-	FilePath string
+	FilePath string `json:"file"`
 	// RelativePath is the path relative to app directory
-	RelativePath string
+	RelativePath string `json:"-"`
 	// URLPattern is the URL pattern
-	URLPattern string
+	URLPattern string `json:"pattern"`
 	// DataType is the loader's data type name
-	DataType string
+	DataType string `json:"data_type"`
 	// Package is the Go package name
-	Package string
+	Package string `json:"-"`
 }
 
 // ProxyFile represents a discovered proxy.go file.
 type ProxyFile struct {
 	// FilePath is the absolute path to the proxy.go file
-	FilePath string
+	FilePath string `json:"file"`
 	// Matchers are the proxy route matchers
-	Matchers []string
+	Matchers []string `json:"matchers"`
+	// HasProxy reports whether the file declares a Proxy function with a
+	// valid signature
+	HasProxy bool `json:"-"`
 	// Package is the Go package name
-	Package string
+	Package string `json:"-"`
 }
 
 // Param represents a route parameter.
 type Param struct {
 	// Name is the parameter name
-	Name string
+	Name string `json:"name"`
+	// Type is the type of a typed parameter, int or uuid, or "" for a string
+	Type string `json:"type,omitempty"`
 	// IsCatchAll indicates if this is a catch-all parameter
-	IsCatchAll bool
+	IsCatchAll bool `json:"catch_all,omitempty"`
 	// IsOptional indicates if this is an optional catch-all
-	IsOptional bool
+	IsOptional bool `json:"optional,omitempty"`
 }
 
 // ScanResult holds all discovered files from a scan.
@@ -172,3 +277,27 @@ type Conflict struct {
 	File2   string
 	Message string
 }
+
+// ScannedFile describes a file in the app directory that is not one of the
+// built-in routing files. It is passed to scan hooks so they can implement
+// additional file conventions.
+type ScannedFile struct {
+	// FilePath is the path to the file
+	FilePath string
+	// RelativePath is the path relative to app directory
+	RelativePath string
+	// Segments are the parsed path segments of the containing directory
+	Segments []Segment
+	// URLPattern is the URL pattern of the containing directory
+	URLPattern string
+	// Scope is the middleware scope of the containing directory
+	Scope string
+}
+
+// ScanHook is called for every non-routing file discovered during a scan.
+// Hooks may append routes, middleware or warnings to result.
+type ScanHook func(file ScannedFile, result *ScanResult) error
+
+// PostProcessor transforms a generated file before it is written to disk.
+// path is the output path of the file.
+type PostProcessor func(path string, content []byte) ([]byte, error)
@@ -0,0 +1,110 @@
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// runtimeAPIVersion is the path prefix of the Lambda runtime API.
+const runtimeAPIVersion = "/2018-06-01/runtime"
+
+// Start serves the invocations of the Lambda function with h until the
+// runtime API fails. It returns an error outside Lambda.
+func Start(h http.Handler) error {
+	api := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+	if api == "" {
+		return errors.New("serverless: AWS_LAMBDA_RUNTIME_API is not set; not running in Lambda")
+	}
+	rt := &runtime{base: "http://" + api + runtimeAPIVersion, client: &http.Client{}}
+	for {
+		if err := rt.next(h); err != nil {
+			return err
+		}
+	}
+}
+
+// runtime is a client of the Lambda runtime API.
+type runtime struct {
+	base   string
+	client *http.Client
+}
+
+// next waits for the next invocation, serves it with h and posts the
+// response. Errors of the handler are reported to Lambda; errors of the
+// runtime API are returned.
+func (rt *runtime) next(h http.Handler) error {
+	resp, err := rt.client.Get(rt.base + "/invocation/next")
+	if err != nil {
+		return fmt.Errorf("serverless: next invocation: %w", err)
+	}
+	event, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("serverless: next invocation: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("serverless: next invocation: %s", resp.Status)
+	}
+
+	id := resp.Header.Get("Lambda-Runtime-Aws-Request-Id")
+	if trace := resp.Header.Get("Lambda-Runtime-Trace-Id"); trace != "" {
+		_ = os.Setenv("_X_AMZN_TRACE_ID", trace)
+	}
+	ctx := context.Background()
+	if ms, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, time.UnixMilli(ms))
+		defer cancel()
+	}
+
+	out, err := invoke(ctx, h, event)
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "InvocationError"})
+		return rt.post("/invocation/"+id+"/error", body, "InvocationError")
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("serverless: %w", err)
+	}
+	return rt.post("/invocation/"+id+"/response", body, "")
+}
+
+// invoke serves event with h, turning panics into errors.
+func invoke(ctx context.Context, h http.Handler, event []byte) (resp *Response, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return Handle(ctx, h, event)
+}
+
+// post sends an invocation's response or error to the runtime API.
+func (rt *runtime) post(path string, body []byte, errorType string) error {
+	req, err := http.NewRequest(http.MethodPost, rt.base+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("serverless: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if errorType != "" {
+		req.Header.Set("Lambda-Runtime-Function-Error-Type", errorType)
+	}
+	resp, err := rt.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("serverless: post %s: %w", path, err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("serverless: post %s: %s", path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+// Package serverless serves an http.Handler, like a Nexo app, on AWS
+// Lambda behind API Gateway (REST and HTTP APIs) or an Application Load
+// Balancer, without a long-running server.
+//
+// Events are converted to *http.Request and the handler's response back
+// to the event's response format. Start runs the Lambda runtime loop of a
+// custom runtime (provided.al2023), whose executable is named bootstrap:
+//
+//	app := nexo.New()
+//	RegisterRoutes(app)
+//	if serverless.IsLambda() {
+//	    log.Fatal(serverless.Start(app.Handler()))
+//	}
+//
+// App.Listen does the same on its own, so an app deploys unchanged. Build
+// the bundle with nexo build --lambda.
+package serverless
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Request is an API Gateway REST API (payload 1.0), HTTP API (payload 2.0)
+// or Application Load Balancer event.
+type Request struct {
+	// Version is "2.0" for HTTP API payloads.
+	Version string `json:"version,omitempty"`
+
+	// HTTP API
+	RawPath        string   `json:"rawPath,omitempty"`
+	RawQueryString string   `json:"rawQueryString,omitempty"`
+	Cookies        []string `json:"cookies,omitempty"`
+
+	// REST API and load balancer
+	HTTPMethod                      string              `json:"httpMethod,omitempty"`
+	Path                            string              `json:"path,omitempty"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders,omitempty"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters,omitempty"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters,omitempty"`
+
+	Headers         map[string]string `json:"headers,omitempty"`
+	RequestContext  RequestContext    `json:"requestContext"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// RequestContext holds the request metadata of an event.
+type RequestContext struct {
+	RequestID  string `json:"requestId,omitempty"`
+	Stage      string `json:"stage,omitempty"`
+	DomainName string `json:"domainName,omitempty"`
+
+	// Identity is the caller of a REST API.
+	Identity struct {
+		SourceIP string `json:"sourceIp,omitempty"`
+	} `json:"identity"`
+
+	// HTTP is the request of an HTTP API.
+	HTTP struct {
+		Method   string `json:"method,omitempty"`
+		Path     string `json:"path,omitempty"`
+		SourceIP string `json:"sourceIp,omitempty"`
+	} `json:"http"`
+
+	// ELB is set for load balancer events.
+	ELB *struct {
+		TargetGroupArn string `json:"targetGroupArn"`
+	} `json:"elb,omitempty"`
+}
+
+// Source is the kind of an event.
+type Source string
+
+// Event sources.
+const (
+	SourceRESTAPI      Source = "rest-api"
+	SourceHTTPAPI      Source = "http-api"
+	SourceLoadBalancer Source = "alb"
+)
+
+// Source returns the kind of the event.
+func (e *Request) Source() Source {
+	switch {
+	case e.RequestContext.ELB != nil:
+		return SourceLoadBalancer
+	case e.Version == "2.0":
+		return SourceHTTPAPI
+	default:
+		return SourceRESTAPI
+	}
+}
+
+// Response is the response to an event. Only the fields of the event's
+// source are set.
+type Response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// eventKey is the request context key of the event.
+type eventKey struct{}
+
+// EventFromContext returns the event of a request served by Handle, for
+// reading API Gateway metadata like the stage.
+func EventFromContext(ctx context.Context) (*Request, bool) {
+	e, ok := ctx.Value(eventKey{}).(*Request)
+	return e, ok
+}
+
+// IsLambda reports whether the process runs in AWS Lambda.
+func IsLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != ""
+}
+
+// NewHTTPRequest converts an event to an *http.Request with ctx.
+func NewHTTPRequest(ctx context.Context, e *Request) (*http.Request, error) {
+	source := e.Source()
+
+	method, path := e.HTTPMethod, e.Path
+	if source == SourceHTTPAPI {
+		method, path = e.RequestContext.HTTP.Method, e.RawPath
+		if path == "" {
+			path = e.RequestContext.HTTP.Path
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	// REST API paths are decoded, the others are sent as requested
+	u := &url.URL{Path: path}
+	if source != SourceRESTAPI {
+		var err error
+		if u, err = url.Parse(path); err != nil {
+			return nil, fmt.Errorf("serverless: invalid path %q: %w", path, err)
+		}
+	}
+	u.RawQuery = e.rawQuery()
+
+	var body []byte
+	var err error
+	if e.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(e.Body); err != nil {
+			return nil, fmt.Errorf("serverless: invalid base64 body: %w", err)
+		}
+	} else {
+		body = []byte(e.Body)
+	}
+
+	r, err := http.NewRequestWithContext(context.WithValue(ctx, eventKey{}, e), method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("serverless: %w", err)
+	}
+	r.RequestURI = u.RequestURI()
+	for name, values := range e.MultiValueHeaders {
+		for _, v := range values {
+			r.Header.Add(name, v)
+		}
+	}
+	for name, v := range e.Headers {
+		if _, ok := e.MultiValueHeaders[name]; !ok {
+			r.Header.Set(name, v)
+		}
+	}
+	if len(e.Cookies) > 0 {
+		r.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+
+	r.Host = cmp.Or(r.Header.Get("Host"), e.RequestContext.DomainName)
+	r.URL.Host = r.Host
+	r.ContentLength = int64(len(body))
+	r.RemoteAddr = cmp.Or(e.RequestContext.HTTP.SourceIP, e.RequestContext.Identity.SourceIP)
+	if source == SourceLoadBalancer {
+		// The load balancer passes the client in X-Forwarded-For
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			r.RemoteAddr = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	if r.RemoteAddr != "" {
+		r.RemoteAddr = net.JoinHostPort(r.RemoteAddr, "0")
+	}
+	return r, nil
+}
+
+// rawQuery returns the encoded query string of the event. REST API
+// parameters are decoded; load balancer ones are passed as sent.
+func (e *Request) rawQuery() string {
+	if e.Source() == SourceHTTPAPI {
+		return e.RawQueryString
+	}
+
+	params := e.MultiValueQueryStringParameters
+	if len(params) == 0 {
+		params = make(map[string][]string, len(e.QueryStringParameters))
+		for k, v := range e.QueryStringParameters {
+			params[k] = []string{v}
+		}
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	escape := url.QueryEscape
+	if e.Source() == SourceLoadBalancer {
+		escape = func(s string) string { return s }
+	}
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range params[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(escape(k) + "=" + escape(v))
+		}
+	}
+	return b.String()
+}
+
+// Handle serves one event with h and returns the response to it.
+func Handle(ctx context.Context, h http.Handler, event []byte) (*Response, error) {
+	var e Request
+	if err := json.Unmarshal(event, &e); err != nil {
+		return nil, fmt.Errorf("serverless: invalid event: %w", err)
+	}
+	r, err := NewHTTPRequest(ctx, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	w := newResponseWriter()
+	h.ServeHTTP(w, r)
+	return w.response(&e), nil
+}
+
+// responseWriter records the handler's response.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: make(http.Header)}
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// ReadFrom lets io.Copy of file responses write to the buffer directly.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.ReadFrom(r)
+}
+
+// Flush is a no-op: Lambda responses are sent whole.
+func (w *responseWriter) Flush() {}
+
+// response converts the recorded response to the format of e's source.
+func (w *responseWriter) response(e *Request) *Response {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if w.header.Get("Content-Type") == "" && w.body.Len() > 0 {
+		w.header.Set("Content-Type", http.DetectContentType(w.body.Bytes()))
+	}
+
+	resp := &Response{StatusCode: status}
+	if isText(w.header) {
+		resp.Body = w.body.String()
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		resp.IsBase64Encoded = true
+	}
+
+	header := w.header.Clone()
+	switch e.Source() {
+	case SourceHTTPAPI:
+		// Cookies have their own field; other repeated headers are joined
+		resp.Cookies = header.Values("Set-Cookie")
+		header.Del("Set-Cookie")
+		resp.Headers = make(map[string]string, len(header))
+		for name, values := range header {
+			resp.Headers[name] = strings.Join(values, ",")
+		}
+	case SourceLoadBalancer:
+		resp.StatusDescription = strconv.Itoa(status) + " " + http.StatusText(status)
+		if e.MultiValueHeaders != nil {
+			resp.MultiValueHeaders = header
+		} else {
+			resp.Headers = singleValues(header)
+		}
+	default:
+		resp.MultiValueHeaders = header
+	}
+	return resp
+}
+
+// singleValues returns the last value of each header, which is all a load
+// balancer without multi-value headers accepts.
+func singleValues(header http.Header) map[string]string {
+	m := make(map[string]string, len(header))
+	for name, values := range header {
+		m[name] = values[len(values)-1]
+	}
+	return m
+}
+
+// isText reports whether a response with header is sent as text rather
+// than base64.
+func isText(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/javascript",
+		mediaType == "application/x-www-form-urlencoded",
+		mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package serverless
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echo responds with what it received.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+	http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"query":  r.URL.RawQuery,
+		"host":   r.Host,
+		"remote": r.RemoteAddr,
+		"cookie": r.Header.Get("Cookie"),
+		"lang":   r.Header.Get("Accept-Language"),
+		"body":   string(body),
+	})
+})
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		source Source
+		want   map[string]string
+	}{
+		{
+			name: "rest api",
+			event: `{"httpMethod":"POST","path":"/users/a b","headers":{"Host":"api.example.com","Accept-Language":"es"},
+				"multiValueQueryStringParameters":{"tag":["a&b","c"]},"requestContext":{"identity":{"sourceIp":"203.0.113.7"}},
+				"body":"aGVsbG8=","isBase64Encoded":true}`,
+			source: SourceRESTAPI,
+			want: map[string]string{"method": "POST", "path": "/users/a b", "query": "tag=a%26b&tag=c", "host": "api.example.com",
+				"remote": "203.0.113.7:0", "lang": "es", "body": "hello"},
+		},
+		{
+			name: "http api",
+			event: `{"version":"2.0","rawPath":"/users/7","rawQueryString":"q=go%20lang","cookies":["s=1","t=2"],
+				"headers":{"accept-language":"fr"},"requestContext":{"domainName":"id.execute-api.aws","http":{"method":"GET","sourceIp":"2001:db8::1"}}}`,
+			source: SourceHTTPAPI,
+			want: map[string]string{"method": "GET", "path": "/users/7", "query": "q=go%20lang", "host": "id.execute-api.aws",
+				"remote": "[2001:db8::1]:0", "cookie": "s=1; t=2", "lang": "fr"},
+		},
+		{
+			name: "load balancer",
+			event: `{"httpMethod":"PUT","path":"/items","queryStringParameters":{"q":"a%20b"},
+				"multiValueHeaders":{"x-forwarded-for":["198.51.100.1, 10.0.0.1"]},"requestContext":{"elb":{"targetGroupArn":"arn"}},"body":"x"}`,
+			source: SourceLoadBalancer,
+			want:   map[string]string{"method": "PUT", "path": "/items", "query": "q=a%20b", "remote": "198.51.100.1:0", "body": "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e Request
+			if err := json.Unmarshal([]byte(tt.event), &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Source() != tt.source {
+				t.Errorf("source = %s, want %s", e.Source(), tt.source)
+			}
+
+			resp, err := Handle(context.Background(), echo, []byte(tt.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK || resp.IsBase64Encoded {
+				t.Fatalf("response = %+v", resp)
+			}
+			var got map[string]string
+			if err := json.Unmarshal([]byte(resp.Body), &got); err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+
+			switch tt.source {
+			case SourceHTTPAPI:
+				if len(resp.Cookies) != 2 || resp.Headers["Content-Type"] != "application/json" || resp.MultiValueHeaders != nil {
+					t.Errorf("http api response headers = %v, cookies = %v", resp.Headers, resp.Cookies)
+				}
+			case SourceLoadBalancer:
+				if resp.StatusDescription != "200 OK" || len(resp.MultiValueHeaders["Set-Cookie"]) != 2 {
+					t.Errorf("load balancer response = %+v", resp)
+				}
+			default:
+				if len(resp.MultiValueHeaders["Set-Cookie"]) != 2 || resp.Headers != nil {
+					t.Errorf("rest api response headers = %v", resp.MultiValueHeaders)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBinaryResponse(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00")
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e, ok := EventFromContext(r.Context()); !ok || e.RequestContext.Stage != "prod" {
+			t.Errorf("event not in the request context")
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(png)
+	})
+	resp, err := Handle(context.Background(), h, []byte(`{"httpMethod":"GET","path":"/logo.png","requestContext":{"stage":"prod"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusCreated || !resp.IsBase64Encoded || resp.Body != base64.StdEncoding.EncodeToString(png) {
+		t.Errorf("response = %+v, want the image in base64", resp)
+	}
+}
+
+func TestRuntime(t *testing.T) {
+	var posted []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == runtimeAPIVersion+"/invocation/next":
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", "req-1")
+			w.Header().Set("Lambda-Runtime-Deadline-Ms", "4102444800000")
+			_, _ = io.WriteString(w, `{"version":"2.0","rawPath":"/panic","requestContext":{"http":{"method":"GET"}}}`)
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			posted = append(posted, strings.TrimPrefix(r.URL.Path, runtimeAPIVersion)+" "+r.Header.Get("Lambda-Runtime-Function-Error-Type")+" "+string(body))
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	rt := &runtime{base: api.URL + runtimeAPIVersion, client: api.Client()}
+	if err := rt.next(echo); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("request has no deadline")
+		}
+		panic("boom")
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posted) != 2 {
+		t.Fatalf("posted %v, want a response and an error", posted)
+	}
+	if !strings.HasPrefix(posted[0], "/invocation/req-1/response  ") || !strings.Contains(posted[0], `"statusCode":200`) {
+		t.Errorf("response = %s", posted[0])
+	}
+	if !strings.HasPrefix(posted[1], "/invocation/req-1/error InvocationError ") || !strings.Contains(posted[1], "boom") {
+		t.Errorf("error = %s", posted[1])
+	}
+}
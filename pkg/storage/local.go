@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local stores objects as files in a directory. It serves them itself as
+// an http.Handler, which only answers requests with a valid signed URL, so
+// development and single-server apps get the same signed URL flow as a
+// bucket.
+type Local struct {
+	dir     string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocal creates a Local driver for dir, serving objects at baseURL
+// (default: "/storage"). Signed URLs are signed with secret; an empty
+// secret uses a random one.
+func NewLocal(dir, baseURL string, secret []byte) (*Local, error) {
+	if dir == "" {
+		dir = "storage"
+	}
+	if baseURL == "" {
+		baseURL = "/storage"
+	}
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return &Local{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+		now:     time.Now,
+	}, nil
+}
+
+// BasePath returns the URL path the objects are served at, like "/storage".
+func (l *Local) BasePath() string {
+	u, err := url.Parse(l.baseURL)
+	if err != nil {
+		return l.baseURL
+	}
+	return u.Path
+}
+
+// Put writes the object to a temporary file and renames it into place, so
+// readers never see a partial object.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error) {
+	name, key, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, contextReader{ctx, r}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		return nil, err
+	}
+	return l.Stat(ctx, key)
+}
+
+// Get opens the object's file.
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, *Object, error) {
+	name, key, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotExist
+	}
+	return f, localObject(key, info), nil
+}
+
+// Stat describes the object's file.
+func (l *Local) Stat(_ context.Context, key string) (*Object, error) {
+	name, key, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return localObject(key, info), nil
+}
+
+// Delete removes the object's file.
+func (l *Local) Delete(_ context.Context, key string) error {
+	name, _, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns a URL under the base URL carrying its expiry and an
+// HMAC of the method, key and expiry.
+func (l *Local) SignedURL(_ context.Context, key string, opts SignOptions) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	opts, err = signOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(opts.Expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	if opts.Method != http.MethodGet {
+		query.Set("method", opts.Method)
+	}
+	query.Set("signature", l.signature(opts.Method, key, expires))
+	return l.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// ServeHTTP serves the objects at their signed URLs: GET and HEAD download
+// an object, PUT uploads one. Requests without a valid, unexpired
+// signature for their method get 403.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, l.BasePath()+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	signed := query.Get("method")
+	if signed == "" {
+		signed = http.MethodGet
+	}
+	if !allowsMethod(signed, r.Method) || !l.verify(signed, key, query) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rc, obj, err := l.Get(r.Context(), key)
+		if errors.Is(err, ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "storage error", http.StatusInternalServerError)
+			return
+		}
+		defer rc.Close()
+		w.Header().Set("Content-Type", obj.ContentType)
+		http.ServeContent(w, r, path.Base(key), obj.ModTime, rc.(io.ReadSeeker))
+	case http.MethodPut:
+		if _, err := l.Put(r.Context(), key, r.Body, PutOptions{}); err != nil {
+			http.Error(w, "storage error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify checks the signature and expiry of a URL signed for method.
+func (l *Local) verify(method, key string, query url.Values) bool {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > unix {
+		return false
+	}
+	want := l.signature(method, key, expires)
+	return hmac.Equal([]byte(query.Get("signature")), []byte(want))
+}
+
+// allowsMethod reports whether a URL signed for signed allows a request
+// with method. GET URLs allow HEAD requests too.
+func allowsMethod(signed, method string) bool {
+	return signed == method || (signed == http.MethodGet && method == http.MethodHead)
+}
+
+// signature returns the HMAC of a signed URL.
+func (l *Local) signature(method, key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path returns the file of the object at key.
+func (l *Local) path(key string) (string, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), key, nil
+}
+
+// localObject describes the file of an object.
+func localObject(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentType(key, ""),
+		ModTime:     info.ModTime(),
+	}
+}
+
+// contentType returns ct, or the media type of key's extension.
+func contentType(key, ct string) string {
+	if ct != "" {
+		return ct
+	}
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// contextReader stops reading once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
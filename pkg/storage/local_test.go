@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	local, err := NewLocal(t.TempDir(), "/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := local.Put(ctx, "avatars/42.png", strings.NewReader("png"), PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Key != "avatars/42.png" || obj.Size != 3 || obj.ContentType != "image/png" {
+		t.Errorf("Put() = %+v", obj)
+	}
+
+	rc, obj, err := local.Get(ctx, "/avatars/42.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "png" || obj.Size != 3 {
+		t.Errorf("Get() = %q, %+v", data, obj)
+	}
+
+	if err := local.Delete(ctx, "avatars/42.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Stat(ctx, "avatars/42.png"); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Stat() after Delete error = %v, want ErrNotExist", err)
+	}
+	if err := local.Delete(ctx, "avatars/42.png"); err != nil {
+		t.Errorf("Delete() of a missing object = %v", err)
+	}
+
+	for _, key := range []string{"", "../escape", "a/../../b", "a//b"} {
+		if _, err := local.Put(ctx, key, strings.NewReader("x"), PutOptions{}); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid key error", key)
+		}
+	}
+}
+
+func TestLocal_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	local, err := NewLocal(t.TempDir(), "/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Put(ctx, "docs/a b.txt", strings.NewReader("hello"), PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	get, _ := local.SignedURL(ctx, "docs/a b.txt", SignOptions{})
+	put, _ := local.SignedURL(ctx, "docs/new.txt", SignOptions{Method: "PUT"})
+	if !strings.HasPrefix(get, "/files/docs/a%20b.txt?") {
+		t.Errorf("SignedURL() = %q", get)
+	}
+	tampered := strings.Replace(get, "a%20b.txt", "other.txt", 1)
+	u, _ := url.Parse(get)
+	q := u.Query()
+	q.Set("expires", "1")
+	expired := u.EscapedPath() + "?" + q.Encode()
+
+	tests := []struct {
+		name, method, target, body string
+		status                     int
+		want                       string
+	}{
+		{"get", "GET", get, "", http.StatusOK, "hello"},
+		{"head with get URL", "HEAD", get, "", http.StatusOK, ""},
+		{"put with get URL", "PUT", get, "x", http.StatusForbidden, ""},
+		{"tampered key", "GET", tampered, "", http.StatusForbidden, ""},
+		{"expired", "GET", expired, "", http.StatusForbidden, ""},
+		{"unsigned", "GET", "/files/docs/a%20b.txt", "", http.StatusForbidden, ""},
+		{"put", "PUT", put, "uploaded", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			local.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.want != "" && w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body, tt.want)
+			}
+		})
+	}
+
+	if obj, err := local.Stat(ctx, "docs/new.txt"); err != nil || obj.Size != int64(len("uploaded")) {
+		t.Errorf("Stat() after signed PUT = %+v, %v", obj, err)
+	}
+
+	local.now = func() time.Time { return time.Now().Add(time.Hour) }
+	w := httptest.NewRecorder()
+	local.ServeHTTP(w, httptest.NewRequest("GET", get, nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status after expiry = %d, want 403", w.Code)
+	}
+}
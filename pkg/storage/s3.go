@@ -0,0 +1,460 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3PartSize is the size of the parts of multipart uploads. Objects up to
+// this size are uploaded with one request.
+const s3PartSize = 8 << 20
+
+// unsignedPayload skips hashing request bodies, so they can be streamed.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config configures an S3 driver.
+type S3Config struct {
+	// Bucket is the bucket name (required).
+	Bucket string
+
+	// Region is the bucket's region (default: us-east-1).
+	Region string
+
+	// Endpoint is the URL of an S3-compatible service (default: AWS S3 in
+	// Region).
+	Endpoint string
+
+	// AccessKey and SecretKey are the credentials (required).
+	AccessKey string
+	SecretKey string
+
+	// PathStyle addresses the bucket in the URL path instead of the host.
+	PathStyle bool
+
+	// Client sends the requests (default: http.DefaultClient).
+	Client *http.Client
+}
+
+// S3 stores objects in an Amazon S3 bucket or a bucket of an S3-compatible
+// service. It speaks the S3 REST API directly, signing requests with AWS
+// Signature Version 4. Objects larger than 8 MB are streamed in parts with
+// a multipart upload.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	partSize int
+	now      func() time.Time
+}
+
+// NewS3 creates an S3 driver.
+func NewS3(config S3Config) (*S3, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("storage: s3: no bucket")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("storage: s3: no credentials (set access_key and secret_key)")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: s3: invalid endpoint %q", config.Endpoint)
+	}
+	return &S3{config: config, endpoint: endpoint, partSize: s3PartSize, now: time.Now}, nil
+}
+
+// NewGCS creates a driver for a Google Cloud Storage bucket. It uses the
+// S3-compatible XML API of GCS, authenticated with the HMAC keys of a
+// service account.
+func NewGCS(bucket, accessKey, secretKey string) (*S3, error) {
+	return NewS3(S3Config{
+		Bucket:    bucket,
+		Region:    "auto",
+		Endpoint:  "https://storage.googleapis.com",
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PathStyle: true,
+	})
+}
+
+// Put uploads the object. Up to 8 MB is buffered to find out whether it
+// fits one request; larger objects are uploaded in 8 MB parts.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", contentType(key, opts.ContentType))
+	if opts.CacheControl != "" {
+		header.Set("Cache-Control", opts.CacheControl)
+	}
+
+	first := make([]byte, s.partSize)
+	n, err := io.ReadFull(r, first)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		resp, err := s.do(ctx, http.MethodPut, key, nil, header, bytes.NewReader(first[:n]), int64(n))
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return &Object{
+			Key:         key,
+			Size:        int64(n),
+			ContentType: header.Get("Content-Type"),
+			ModTime:     s.now(),
+			ETag:        resp.Header.Get("ETag"),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.putMultipart(ctx, key, header, io.MultiReader(bytes.NewReader(first), r))
+}
+
+// putMultipart uploads the object in parts.
+func (s *S3) putMultipart(ctx context.Context, key string, header http.Header, r io.Reader) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = decodeXML(resp, &initiated)
+	if err != nil {
+		return nil, err
+	}
+	uploadID := initiated.UploadID
+
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	var parts []part
+	var size int64
+	buf := make([]byte, s.partSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 || len(parts) == 0 {
+			query := url.Values{"partNumber": {strconv.Itoa(len(parts) + 1)}, "uploadId": {uploadID}}
+			resp, err := s.do(ctx, http.MethodPut, key, query, nil, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				s.abort(key, uploadID)
+				return nil, err
+			}
+			resp.Body.Close()
+			parts = append(parts, part{len(parts) + 1, resp.Header.Get("ETag")})
+			size += int64(n)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			s.abort(key, uploadID)
+			return nil, readErr
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return nil, err
+	}
+	resp, err = s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		s.abort(key, uploadID)
+		return nil, err
+	}
+	var completed struct {
+		ETag string `xml:"ETag"`
+	}
+	if err := decodeXML(resp, &completed); err != nil {
+		return nil, err
+	}
+	return &Object{
+		Key:         key,
+		Size:        size,
+		ContentType: header.Get("Content-Type"),
+		ModTime:     s.now(),
+		ETag:        completed.ETag,
+	}, nil
+}
+
+// abort cancels a multipart upload, so its parts aren't billed.
+func (s *S3) abort(key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if resp, err := s.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil, 0); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Get downloads the object.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s3Object(key, resp), nil
+}
+
+// Stat describes the object with a HEAD request.
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s3Object(key, resp), nil
+}
+
+// Delete removes the object.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil, 0)
+	if errors.Is(err, ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignedURL returns a presigned URL (AWS Signature Version 4 in the query).
+func (s *S3) SignedURL(_ context.Context, key string, opts SignOptions) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	opts, err = signOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	u := s.objectURL(key)
+	now := s.now().UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(opts.Expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		opts.Method,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+// do sends a signed request for the object at key and returns the response
+// of a successful one. Error responses are turned into errors, and 404s
+// into ErrNotExist.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	u := s.objectURL(key)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = size
+	s.sign(req, u)
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3: %w", err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && !query.Has("uploadId") {
+		return nil, ErrNotExist
+	}
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&s3Err)
+	if s3Err.Code == "" {
+		s3Err.Code = resp.Status
+	}
+	return nil, fmt.Errorf("storage: s3: %s %s: %s %s", method, key, s3Err.Code, s3Err.Message)
+}
+
+// sign adds the Authorization header of AWS Signature Version 4.
+func (s *S3) sign(req *http.Request, u *url.URL) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	names := []string{"host"}
+	values := map[string]string{"host": u.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			names = append(names, lower)
+			values[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		u.EscapedPath(),
+		u.RawQuery,
+		headers.String(),
+		signed,
+		unsignedPayload,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, s.scope(now), signed, s.signature(now, canonical)))
+}
+
+// signature signs a canonical request made at now.
+func (s *S3) signature(now time.Time, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + s.scope(now) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+// scope is the credential scope of requests made at now.
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+// objectURL returns the URL of the object at key.
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	escaped := "/" + escapeKey(key)
+	if s.config.PathStyle {
+		escaped = "/" + escapeKey(s.config.Bucket) + escaped
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path, _ = url.PathUnescape(u.Path + escaped)
+	u.RawPath = s.endpoint.EscapedPath() + escaped
+	return &u
+}
+
+// s3Object describes the object of a GET or HEAD response.
+func s3Object(key string, resp *http.Response) *Object {
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ModTime:     modTime,
+		ETag:        resp.Header.Get("ETag"),
+	}
+}
+
+// decodeXML decodes and closes an XML response body.
+func decodeXML(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("storage: s3: invalid response: %w", err)
+	}
+	return nil
+}
+
+// canonicalQuery encodes query with sorted keys and RFC 3986 escaping, as
+// Signature Version 4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			pairs = append(pairs, uriEscape(k)+"="+uriEscape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapeKey escapes each segment of key.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = uriEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEscape percent-encodes everything but the unreserved characters of
+// RFC 3986.
+func uriEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,181 @@
+// Package storage stores files as objects in a bucket: a local directory,
+// Amazon S3 or an S3-compatible service (MinIO, Cloudflare R2, DigitalOcean
+// Spaces), or Google Cloud Storage. Features that keep files use a Driver,
+// so the app moves from disk to a bucket by changing its config.
+//
+//	store, err := storage.Open(storage.Config{
+//	    Driver:    "s3",
+//	    Bucket:    "media",
+//	    Region:    "eu-west-1",
+//	    AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+//	    SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//	})
+//	obj, err := store.Put(ctx, "avatars/42.png", file, storage.PutOptions{ContentType: "image/png"})
+//	url, err := store.SignedURL(ctx, "avatars/42.png", storage.SignOptions{Expires: time.Hour})
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is returned for keys that have no object.
+var ErrNotExist = errors.New("storage: object does not exist")
+
+// DefaultSignExpiry is how long signed URLs stay valid when SignOptions
+// sets no expiry.
+const DefaultSignExpiry = 15 * time.Minute
+
+// Object describes a stored object.
+type Object struct {
+	// Key is the object's path in the bucket, like "avatars/42.png".
+	Key string `json:"key"`
+
+	// Size is the length in bytes.
+	Size int64 `json:"size"`
+
+	// ContentType is the media type the object is served with.
+	ContentType string `json:"content_type,omitempty"`
+
+	// ModTime is when the object was last written.
+	ModTime time.Time `json:"mod_time"`
+
+	// ETag identifies the object's content, when the driver provides it.
+	ETag string `json:"etag,omitempty"`
+}
+
+// PutOptions configures a Put.
+type PutOptions struct {
+	// ContentType is the media type to serve the object with. When empty
+	// it is guessed from the key's extension, as the local driver always
+	// does.
+	ContentType string
+
+	// CacheControl is the Cache-Control header to serve the object with
+	// (remote drivers only).
+	CacheControl string
+}
+
+// SignOptions configures a signed URL.
+type SignOptions struct {
+	// Method is the request the URL allows: GET (default), HEAD or PUT.
+	// A PUT URL lets a browser upload straight to the bucket.
+	Method string
+
+	// Expires is how long the URL stays valid (default: DefaultSignExpiry).
+	Expires time.Duration
+}
+
+// Driver stores objects. Implementations are safe for concurrent use.
+type Driver interface {
+	// Put writes the object at key, streaming it from r, and replaces any
+	// object already there.
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error)
+
+	// Get opens the object at key. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Stat describes the object at key.
+	Stat(ctx context.Context, key string) (*Object, error)
+
+	// Delete removes the object at key. Deleting a missing object is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL that allows one kind of request for the
+	// object at key, without credentials, until it expires.
+	SignedURL(ctx context.Context, key string, opts SignOptions) (string, error)
+}
+
+// Config selects and configures a driver.
+type Config struct {
+	// Driver is "local", "s3" or "gcs".
+	Driver string
+
+	// Dir is the directory of the local driver (default: "storage").
+	Dir string
+
+	// BaseURL is where the local driver's objects are served, used in its
+	// signed URLs (default: "/storage").
+	BaseURL string
+
+	// Secret signs the local driver's URLs. Empty uses a random secret,
+	// so signed URLs stop working when the process restarts.
+	Secret string
+
+	// Bucket is the bucket of the s3 and gcs drivers.
+	Bucket string
+
+	// Region is the bucket's region (default: us-east-1 for s3, auto for
+	// gcs).
+	Region string
+
+	// Endpoint is the URL of an S3-compatible service, like
+	// https://<account>.r2.cloudflarestorage.com (default: AWS S3).
+	Endpoint string
+
+	// AccessKey and SecretKey are the credentials of the s3 and gcs
+	// drivers. gcs takes HMAC keys of a service account.
+	AccessKey string
+	SecretKey string
+
+	// PathStyle addresses the bucket in the URL path instead of the host
+	// name, as MinIO needs.
+	PathStyle bool
+}
+
+// Open creates the driver selected by config.
+func Open(config Config) (Driver, error) {
+	switch config.Driver {
+	case "local":
+		return NewLocal(config.Dir, config.BaseURL, []byte(config.Secret))
+	case "s3":
+		return NewS3(S3Config{
+			Bucket:    config.Bucket,
+			Region:    config.Region,
+			Endpoint:  config.Endpoint,
+			AccessKey: config.AccessKey,
+			SecretKey: config.SecretKey,
+			PathStyle: config.PathStyle,
+		})
+	case "gcs":
+		return NewGCS(config.Bucket, config.AccessKey, config.SecretKey)
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q (use local, s3 or gcs)", config.Driver)
+	}
+}
+
+// signOptions fills in the defaults of opts.
+func signOptions(opts SignOptions) (SignOptions, error) {
+	opts.Method = strings.ToUpper(opts.Method)
+	switch opts.Method {
+	case "":
+		opts.Method = http.MethodGet
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+	default:
+		return opts, fmt.Errorf("storage: can't sign %s requests (use GET, HEAD or PUT)", opts.Method)
+	}
+	if opts.Expires <= 0 {
+		opts.Expires = DefaultSignExpiry
+	}
+	return opts, nil
+}
+
+// cleanKey validates key and strips its leading slash.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", errors.New("storage: empty key")
+	}
+	for seg := range strings.SplitSeq(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return key, nil
+}
@@ -2,6 +2,9 @@
 package tools
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,12 +21,87 @@ const (
 
 	// DefaultCacheDir is the default cache directory for tools
 	DefaultCacheDir = ".cache/nexo/bin"
+
+	// tailwindReleaseURL is where Tailwind standalone releases are downloaded from
+	tailwindReleaseURL = "https://github.com/tailwindlabs/tailwindcss/releases/download"
 )
 
+// ErrTailwindNotInstalled is returned when the Tailwind binary is missing
+// and cannot be downloaded.
+var ErrTailwindNotInstalled = errors.New("tailwind not installed")
+
+// ChecksumError is returned when a downloaded Tailwind binary does not match
+// its expected SHA-256 checksum. The binary is discarded.
+type ChecksumError struct {
+	File     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %s, got %s", e.File, e.Expected, e.Actual)
+}
+
+// DownloadError is returned when the Tailwind binary cannot be downloaded.
+type DownloadError struct {
+	URL        string
+	StatusCode int // 0 when the request itself failed
+	Err        error
+}
+
+func (e *DownloadError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("failed to download Tailwind from %s: HTTP %d", e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("failed to download Tailwind from %s: %v", e.URL, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// BuildError is returned when a Tailwind build fails. Stderr holds the
+// compiler's diagnostics.
+type BuildError struct {
+	Input  string
+	Output string
+	Stderr string
+	Err    error
+}
+
+func (e *BuildError) Error() string {
+	msg := fmt.Sprintf("tailwind build %s -> %s failed: %v", e.Input, e.Output, e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += "\n" + stderr
+	}
+	return msg
+}
+
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// TailwindOptions configures a TailwindCLI. The zero value uses
+// TailwindVersion from the shared cache directory.
+type TailwindOptions struct {
+	// Version pins the Tailwind release, e.g. "4.1.4" (default: TailwindVersion).
+	Version string
+
+	// SHA256 is the expected checksum of the platform binary. Without it the
+	// binary is verified against the release's sha256sums.txt when published.
+	SHA256 string
+
+	// CacheDir is where binaries are downloaded (default: ~/.cache/nexo/bin).
+	// Point it inside the project to keep a per-project copy.
+	CacheDir string
+
+	// Binary uses an existing Tailwind executable instead of downloading one.
+	Binary string
+}
+
 // TailwindCLI manages the Tailwind CSS standalone binary
 type TailwindCLI struct {
 	version  string
 	cacheDir string
+	sha256   string
+	binary   string
+	baseURL  string
 }
 
 // NewTailwindCLI creates a new TailwindCLI manager
@@ -36,6 +114,7 @@ func NewTailwindCLI() *TailwindCLI {
 	return &TailwindCLI{
 		version:  TailwindVersion,
 		cacheDir: filepath.Join(homeDir, DefaultCacheDir),
+		baseURL:  tailwindReleaseURL,
 	}
 }
 
@@ -44,13 +123,33 @@ func NewTailwindCLIWithCacheDir(cacheDir string) *TailwindCLI {
 	return &TailwindCLI{
 		version:  TailwindVersion,
 		cacheDir: cacheDir,
+		baseURL:  tailwindReleaseURL,
 	}
 }
 
-// BinaryPath returns the path to the Tailwind binary
+// NewTailwindCLIWithOptions creates a TailwindCLI pinned to opts.Version
+func NewTailwindCLIWithOptions(opts TailwindOptions) *TailwindCLI {
+	t := NewTailwindCLI()
+	if opts.Version != "" {
+		t.version = strings.TrimPrefix(opts.Version, "v")
+	}
+	if opts.CacheDir != "" {
+		t.cacheDir = opts.CacheDir
+	}
+	t.sha256 = strings.ToLower(opts.SHA256)
+	t.binary = opts.Binary
+	return t
+}
+
+// BinaryPath returns the path to the Tailwind binary. Cached binaries are
+// named after their version, so projects pinning different versions can
+// share a cache directory.
 func (t *TailwindCLI) BinaryPath() string {
-	binaryName := t.platformBinaryName()
-	return filepath.Join(t.cacheDir, binaryName)
+	if t.binary != "" {
+		return t.binary
+	}
+	name := strings.Replace(t.platformBinaryName(), "tailwindcss-", "tailwindcss-v"+t.version+"-", 1)
+	return filepath.Join(t.cacheDir, name)
 }
 
 // IsInstalled checks if Tailwind is already installed
@@ -64,72 +163,73 @@ func (t *TailwindCLI) IsInstalled() bool {
 	return info.Mode()&0111 != 0
 }
 
-// EnsureInstalled downloads Tailwind if not already present
+// EnsureInstalled downloads Tailwind if not already present. When the
+// download fails, a tailwindcss executable on PATH is used as a fallback;
+// a checksum mismatch is never ignored.
 func (t *TailwindCLI) EnsureInstalled() error {
 	if t.IsInstalled() {
 		return nil
 	}
+	if t.binary != "" {
+		return fmt.Errorf("%w: %s", ErrTailwindNotInstalled, t.binary)
+	}
 
 	// Create cache directory
 	if err := os.MkdirAll(t.cacheDir, 0755); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	return t.downloadBinary()
+	err := t.downloadBinary()
+	var dlErr *DownloadError
+	if errors.As(err, &dlErr) {
+		if path, lookErr := exec.LookPath("tailwindcss"); lookErr == nil {
+			t.binary = path
+			return nil
+		}
+		return fmt.Errorf("%w: %w", ErrTailwindNotInstalled, err)
+	}
+	return err
 }
 
-// Build runs Tailwind to compile CSS
+// Build runs Tailwind to compile minified CSS
 func (t *TailwindCLI) Build(input, output string) error {
-	if err := t.EnsureInstalled(); err != nil {
-		return err
-	}
-
-	// Ensure output directory exists
-	outputDir := filepath.Dir(output)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
+	return t.BuildContext(context.Background(), input, output)
+}
 
-	// Get current working directory for --cwd flag
-	// This ensures @source directives in input.css resolve correctly
-	cwd, err := os.Getwd()
+// BuildContext runs Tailwind to compile minified CSS, streaming its output
+// to the terminal. A failed build returns a *BuildError.
+func (t *TailwindCLI) BuildContext(ctx context.Context, input, output string) error {
+	var stderr bytes.Buffer
+	cmd, err := t.command(ctx, input, output, "--minify")
 	if err != nil {
-		cwd = "."
+		return err
 	}
-
-	cmd := exec.Command(t.BinaryPath(), "-i", input, "-o", output, "--minify", "--cwd", cwd)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return &BuildError{Input: input, Output: output, Stderr: stderr.String(), Err: err}
+	}
+	return nil
 }
 
 // BuildWithOutput runs Tailwind and captures output
 func (t *TailwindCLI) BuildWithOutput(input, output string) (string, error) {
-	if err := t.EnsureInstalled(); err != nil {
+	cmd, err := t.command(context.Background(), input, output, "--minify")
+	if err != nil {
 		return "", err
 	}
 
-	// Ensure output directory exists
-	outputDir := filepath.Dir(output)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Get current working directory for --cwd flag
-	cwd, err := os.Getwd()
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		cwd = "."
+		return string(out), &BuildError{Input: input, Output: output, Stderr: string(out), Err: err}
 	}
-
-	cmd := exec.Command(t.BinaryPath(), "-i", input, "-o", output, "--minify", "--cwd", cwd)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+	return string(out), nil
 }
 
-// Watch runs Tailwind in watch mode and returns the process.
-// It first runs an initial build to ensure CSS is up-to-date, then starts watching.
-func (t *TailwindCLI) Watch(input, output string) (*exec.Cmd, error) {
+// command prepares a Tailwind invocation compiling input to output,
+// installing the binary and creating the output directory first.
+func (t *TailwindCLI) command(ctx context.Context, input, output string, args ...string) (*exec.Cmd, error) {
 	if err := t.EnsureInstalled(); err != nil {
 		return nil, err
 	}
@@ -147,17 +247,31 @@ func (t *TailwindCLI) Watch(input, output string) (*exec.Cmd, error) {
 		cwd = "."
 	}
 
+	args = append([]string{"-i", input, "-o", output, "--cwd", cwd}, args...)
+	return exec.CommandContext(ctx, t.BinaryPath(), args...), nil
+}
+
+// Watch runs Tailwind in watch mode and returns the process.
+// It first runs an initial build to ensure CSS is up-to-date, then starts watching.
+func (t *TailwindCLI) Watch(input, output string) (*exec.Cmd, error) {
 	// Run initial build first to ensure CSS is up-to-date before starting watch
 	// This fixes the issue where the watcher doesn't produce output until a file changes
-	buildCmd := exec.Command(t.BinaryPath(), "-i", input, "-o", output, "--cwd", cwd)
+	buildCmd, err := t.command(context.Background(), input, output)
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
 	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	buildCmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 	if err := buildCmd.Run(); err != nil {
-		return nil, fmt.Errorf("initial CSS build failed: %w", err)
+		return nil, &BuildError{Input: input, Output: output, Stderr: stderr.String(), Err: err}
 	}
 
 	// Now start watch mode
-	cmd := exec.Command(t.BinaryPath(), "-i", input, "-o", output, "--watch", "--cwd", cwd)
+	cmd, err := t.command(context.Background(), input, output, "--watch")
+	if err != nil {
+		return nil, err
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -168,7 +282,8 @@ func (t *TailwindCLI) Watch(input, output string) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
-// downloadBinary downloads the Tailwind binary for the current platform
+// downloadBinary downloads the Tailwind binary for the current platform and
+// verifies its checksum before moving it into place
 func (t *TailwindCLI) downloadBinary() error {
 	url := t.downloadURL()
 	destPath := t.BinaryPath()
@@ -176,39 +291,101 @@ func (t *TailwindCLI) downloadBinary() error {
 	// Download the binary
 	resp, err := http.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to download Tailwind: %w", err)
+		return &DownloadError{URL: url, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download Tailwind: HTTP %d", resp.StatusCode)
+		return &DownloadError{URL: url, StatusCode: resp.StatusCode}
 	}
 
-	// Create the destination file
-	f, err := os.Create(destPath)
+	// Download next to the destination so a failed or unverified download
+	// never leaves a usable binary behind
+	f, err := os.CreateTemp(t.cacheDir, ".tailwindcss-*")
 	if err != nil {
 		return fmt.Errorf("failed to create binary file: %w", err)
 	}
-	defer func() { _ = f.Close() }()
+	tmpPath := f.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
 
-	// Copy the content
 	if _, err := io.Copy(f, resp.Body); err != nil {
-		_ = os.Remove(destPath)
+		_ = f.Close()
+		return &DownloadError{URL: url, Err: err}
+	}
+	if err := f.Close(); err != nil {
 		return fmt.Errorf("failed to write binary: %w", err)
 	}
 
+	if err := t.verifyChecksum(tmpPath); err != nil {
+		return err
+	}
+
 	// Make it executable
-	if err := os.Chmod(destPath, 0755); err != nil {
+	if err := os.Chmod(tmpPath, 0755); err != nil {
 		return fmt.Errorf("failed to make binary executable: %w", err)
 	}
 
+	return os.Rename(tmpPath, destPath)
+}
+
+// verifyChecksum compares the downloaded binary at path against the pinned
+// checksum, or against the release's sha256sums.txt. Releases without a
+// checksums file are accepted when no checksum is pinned.
+func (t *TailwindCLI) verifyChecksum(path string) error {
+	expected := t.sha256
+	if expected == "" {
+		sums, err := t.releaseChecksums()
+		if err != nil {
+			return err
+		}
+		expected = sums[t.platformBinaryName()]
+		if expected == "" {
+			return nil
+		}
+	}
+
+	actual, err := calculateSHA256(path)
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	if actual != expected {
+		return &ChecksumError{File: t.platformBinaryName(), Expected: expected, Actual: actual}
+	}
 	return nil
 }
 
+// releaseChecksums downloads the release's sha256sums.txt. A release
+// without one yields no checksums.
+func (t *TailwindCLI) releaseChecksums() (map[string]string, error) {
+	url := t.releaseURL() + "sha256sums.txt"
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, &DownloadError{URL: url, Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &DownloadError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &DownloadError{URL: url, Err: err}
+	}
+	return parseChecksums(body), nil
+}
+
+// releaseURL returns the base download URL of the pinned release
+func (t *TailwindCLI) releaseURL() string {
+	return t.baseURL + "/v" + t.version + "/"
+}
+
 // downloadURL returns the download URL for the current platform
 func (t *TailwindCLI) downloadURL() string {
-	base := "https://github.com/tailwindlabs/tailwindcss/releases/download/v" + t.version + "/"
-	return base + t.platformBinaryName()
+	return t.releaseURL() + t.platformBinaryName()
 }
 
 // platformBinaryName returns the binary name for the current platform
@@ -290,7 +467,7 @@ func NeedsInitialBuild() bool {
 // GetTailwindVersion attempts to get the version of an installed Tailwind binary
 func (t *TailwindCLI) GetTailwindVersion() (string, error) {
 	if !t.IsInstalled() {
-		return "", fmt.Errorf("tailwind not installed")
+		return "", ErrTailwindNotInstalled
 	}
 
 	cmd := exec.Command(t.BinaryPath(), "--version")
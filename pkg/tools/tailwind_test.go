@@ -1,6 +1,11 @@
 package tools
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -314,3 +319,202 @@ func TestTailwindCLI_EnsureInstalled_CreatesCacheDir(t *testing.T) {
 	// Instead, just verify the method doesn't panic with non-existent dir
 	_ = tw
 }
+
+func TestNewTailwindCLIWithOptions(t *testing.T) {
+	tw := NewTailwindCLIWithOptions(TailwindOptions{
+		Version:  "v4.1.4",
+		SHA256:   "ABC123",
+		CacheDir: "/project/.nexo/bin",
+	})
+
+	if tw.Version() != "4.1.4" {
+		t.Errorf("Version() = %q, want 4.1.4", tw.Version())
+	}
+	if tw.sha256 != "abc123" {
+		t.Errorf("sha256 = %q, want abc123", tw.sha256)
+	}
+	if !strings.HasPrefix(tw.BinaryPath(), "/project/.nexo/bin/tailwindcss-v4.1.4-") {
+		t.Errorf("BinaryPath() = %q, want a versioned binary in the project cache", tw.BinaryPath())
+	}
+	if !strings.Contains(tw.downloadURL(), "/v4.1.4/tailwindcss-") {
+		t.Errorf("downloadURL() = %q, want the pinned release", tw.downloadURL())
+	}
+
+	binary := filepath.Join(t.TempDir(), "tailwindcss")
+	tw = NewTailwindCLIWithOptions(TailwindOptions{Binary: binary})
+	if tw.BinaryPath() != binary {
+		t.Errorf("BinaryPath() = %q, want the configured binary", tw.BinaryPath())
+	}
+	if err := tw.EnsureInstalled(); !errors.Is(err, ErrTailwindNotInstalled) {
+		t.Errorf("EnsureInstalled() = %v, want ErrTailwindNotInstalled", err)
+	}
+}
+
+// tailwindRelease serves a fake Tailwind release containing binary, with a
+// sha256sums.txt listing sums when it is set.
+func tailwindRelease(t *testing.T, tw *TailwindCLI, binary []byte, sums string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v"+tw.version+"/"+tw.platformBinaryName(), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(binary)
+	})
+	if sums != "" {
+		mux.HandleFunc("/v"+tw.version+"/sha256sums.txt", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(sums))
+		})
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	tw.baseURL = srv.URL
+}
+
+func TestTailwindCLI_EnsureInstalled_Download(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho tailwindcss\n")
+	sum := sha256.Sum256(binary)
+	good := hex.EncodeToString(sum[:])
+	bad := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name    string
+		pinned  string
+		sums    func(name string) string
+		wantErr bool
+	}{
+		{name: "pinned checksum", pinned: good},
+		{name: "pinned checksum mismatch", pinned: bad, wantErr: true},
+		{name: "release checksums", sums: func(name string) string { return good + "  ./" + name + "\n" }},
+		{name: "release checksum mismatch", sums: func(name string) string { return bad + "  " + name + "\n" }, wantErr: true},
+		{name: "release without checksums"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := NewTailwindCLIWithOptions(TailwindOptions{SHA256: tt.pinned, CacheDir: t.TempDir()})
+			var sums string
+			if tt.sums != nil {
+				sums = tt.sums(tw.platformBinaryName())
+			}
+			tailwindRelease(t, tw, binary, sums)
+
+			err := tw.EnsureInstalled()
+			if tt.wantErr {
+				var csErr *ChecksumError
+				if !errors.As(err, &csErr) {
+					t.Fatalf("EnsureInstalled() = %v, want *ChecksumError", err)
+				}
+				if csErr.Actual != good {
+					t.Errorf("Actual = %q, want %q", csErr.Actual, good)
+				}
+				if _, statErr := os.Stat(tw.BinaryPath()); !os.IsNotExist(statErr) {
+					t.Error("binary with a bad checksum should be removed")
+				}
+				entries, _ := os.ReadDir(tw.CacheDir())
+				if len(entries) != 0 {
+					t.Errorf("cache dir has leftover files: %v", entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureInstalled() = %v", err)
+			}
+			got, _ := os.ReadFile(tw.BinaryPath())
+			if string(got) != string(binary) {
+				t.Errorf("binary = %q, want %q", got, binary)
+			}
+			if runtime.GOOS != "windows" && !tw.IsInstalled() {
+				t.Error("downloaded binary should be executable")
+			}
+		})
+	}
+}
+
+func TestTailwindCLI_EnsureInstalled_DownloadError(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	tw := NewTailwindCLIWithOptions(TailwindOptions{Version: "0.0.0", CacheDir: t.TempDir()})
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	tw.baseURL = srv.URL
+
+	err := tw.EnsureInstalled()
+	if !errors.Is(err, ErrTailwindNotInstalled) {
+		t.Errorf("EnsureInstalled() = %v, want ErrTailwindNotInstalled", err)
+	}
+	var dlErr *DownloadError
+	if !errors.As(err, &dlErr) || dlErr.StatusCode != http.StatusNotFound {
+		t.Errorf("EnsureInstalled() = %v, want a 404 *DownloadError", err)
+	}
+}
+
+func TestTailwindCLI_EnsureInstalled_PathFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script binary")
+	}
+
+	pathDir := t.TempDir()
+	fallback := filepath.Join(pathDir, "tailwindcss")
+	if err := os.WriteFile(fallback, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", pathDir)
+
+	tw := NewTailwindCLIWithOptions(TailwindOptions{CacheDir: t.TempDir()})
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	tw.baseURL = srv.URL
+
+	if err := tw.EnsureInstalled(); err != nil {
+		t.Fatalf("EnsureInstalled() = %v", err)
+	}
+	if tw.BinaryPath() != fallback {
+		t.Errorf("BinaryPath() = %q, want %q", tw.BinaryPath(), fallback)
+	}
+}
+
+func TestTailwindCLI_BuildError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script binary")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tailwindcss")
+	script := "#!/bin/sh\necho 'Error: Cannot apply unknown utility class: bg-nope' >&2\nexit 1\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tw := NewTailwindCLIWithOptions(TailwindOptions{Binary: binary})
+	output := filepath.Join(dir, "css", "output.css")
+	_, err := tw.BuildWithOutput("styles/input.css", output)
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("BuildWithOutput() = %v, want *BuildError", err)
+	}
+	if buildErr.Input != "styles/input.css" || buildErr.Output != output {
+		t.Errorf("BuildError paths = %q -> %q", buildErr.Input, buildErr.Output)
+	}
+	if !strings.Contains(buildErr.Stderr, "unknown utility class") {
+		t.Errorf("Stderr = %q, want the compiler diagnostics", buildErr.Stderr)
+	}
+	if !strings.Contains(err.Error(), "unknown utility class") {
+		t.Errorf("Error() = %q, want the compiler diagnostics", err.Error())
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := parseChecksums([]byte("ABC  ./tailwindcss-linux-x64\ndef *tailwindcss-windows-x64.exe\n\nbroken\n"))
+
+	want := map[string]string{
+		"tailwindcss-linux-x64":       "abc",
+		"tailwindcss-windows-x64.exe": "def",
+	}
+	if len(sums) != len(want) {
+		t.Fatalf("parseChecksums() = %v, want %v", sums, want)
+	}
+	for name, sum := range want {
+		if sums[name] != sum {
+			t.Errorf("sums[%q] = %q, want %q", name, sums[name], sum)
+		}
+	}
+}
@@ -471,6 +471,12 @@ func (u *Updater) downloadChecksums(asset *Asset) (map[string]string, error) {
 		return nil, err
 	}
 
+	return parseChecksums(body), nil
+}
+
+// parseChecksums parses a sha256sum listing into a map of file name to
+// checksum. Names are taken without a leading "./" or "*" (binary mode).
+func parseChecksums(body []byte) map[string]string {
 	checksums := make(map[string]string)
 	lines := strings.Split(string(body), "\n")
 	for _, line := range lines {
@@ -481,11 +487,12 @@ func (u *Updater) downloadChecksums(asset *Asset) (map[string]string, error) {
 		// Format: "sha256sum  filename" (two spaces)
 		parts := strings.Fields(line)
 		if len(parts) >= 2 {
-			checksums[parts[1]] = parts[0]
+			name := strings.TrimPrefix(strings.TrimPrefix(parts[1], "*"), "./")
+			checksums[name] = strings.ToLower(parts[0])
 		}
 	}
 
-	return checksums, nil
+	return checksums
 }
 
 // calculateSHA256 calculates the SHA256 checksum of a file